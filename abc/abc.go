@@ -0,0 +1,324 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Abstract Base Classes module
+//
+// ABCMeta is the metaclass of abstract base classes.  Classes can be
+// registered with an ABC as virtual subclasses so that isinstance and
+// issubclass recognise them without them inheriting from the ABC, or
+// recognised by the __subclasshook__ of the ABC.  Classes which don't
+// override all the abstract methods they inherit can't be instantiated.
+
+package abc
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const abcmeta_doc = `Metaclass for defining Abstract Base Classes (ABCs).
+
+Use this metaclass to create an ABC.  An ABC can be subclassed
+directly, and then acts as a mix-in class.  You can also register
+unrelated concrete classes (even built-in classes) and unrelated
+ABCs as 'virtual subclasses' -- these and their descendants will
+be considered subclasses of the registering ABC by the built-in
+issubclass() function, but the registering ABC won't show up in
+their MRO (Method Resolution Order) nor will method
+implementations defined by the registering ABC be callable (not
+even via super()).`
+
+var ABCMeta = py.TypeType.NewType("ABCMeta", abcmeta_doc, nil, nil)
+
+var (
+	// Virtual subclasses registered with each ABC
+	registry = map[*py.Type][]*py.Type{}
+
+	// Classes which inherit directly from each ABC
+	subclasses = map[*py.Type][]*py.Type{}
+)
+
+// IsABC returns true if t is an abstract base class
+func IsABC(t *py.Type) bool {
+	return t.Type().IsSubtype(ABCMeta)
+}
+
+// Records t as a subclass of any ABCs in its bases
+func addSubclass(t *py.Type) {
+	for _, baseObj := range t.Bases {
+		if base, ok := baseObj.(*py.Type); ok && IsABC(base) {
+			subclasses[base] = append(subclasses[base], t)
+		}
+	}
+}
+
+// Sets __abstractmethods__ of t to the names of the abstract methods
+// it defines or inherits without overriding, marking t as abstract if
+// there are any
+func computeAbstractMethods(t *py.Type) error {
+	var abstracts []py.Object
+	for name, value := range t.Dict {
+		abstract, err := py.IsAbstract(value)
+		if err != nil {
+			return err
+		}
+		if abstract {
+			abstracts = append(abstracts, py.String(name))
+		}
+	}
+	for _, base := range t.Bases {
+		names, err := py.GetAttrString(base, "__abstractmethods__")
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				continue
+			}
+			return err
+		}
+		items, err := py.SequenceList(names)
+		if err != nil {
+			return err
+		}
+		for _, nameObj := range items.Items {
+			name, err := py.AttributeName(nameObj)
+			if err != nil {
+				return err
+			}
+			value := t.Lookup(name)
+			if value == nil {
+				continue
+			}
+			abstract, err := py.IsAbstract(value)
+			if err != nil {
+				return err
+			}
+			if abstract {
+				abstracts = append(abstracts, py.String(name))
+			}
+		}
+	}
+	set, err := py.NewFrozenSetFromItems(abstracts)
+	if err != nil {
+		return err
+	}
+	t.Dict["__abstractmethods__"] = set
+	if len(abstracts) > 0 {
+		t.Flags |= py.TPFLAGS_IS_ABSTRACT
+	} else {
+		t.Flags &^= py.TPFLAGS_IS_ABSTRACT
+	}
+	return nil
+}
+
+// ABCMetaNew makes a new class with ABCMeta as its metaclass
+func ABCMetaNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	res, err := py.TypeNew(metatype, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := res.(*py.Type); ok {
+		err = InitABC(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// InitABC finishes making t, a class with ABCMeta as its metaclass
+// which was made without ABCMetaNew, eg by py.NewClass
+func InitABC(t *py.Type) error {
+	err := computeAbstractMethods(t)
+	if err != nil {
+		return err
+	}
+	addSubclass(t)
+	return nil
+}
+
+// NewABC makes a new abstract base class implemented in Go
+//
+// It can't be instantiated but python classes can inherit from it
+func NewABC(name, doc string, base *py.Type) *py.Type {
+	t := base.NewType(name, doc, nil, nil)
+	t.New = nil
+	t.ObjectType = ABCMeta
+	addSubclass(t)
+	return t
+}
+
+// Calls the __subclasshook__ of cls with sub, returning
+// NotImplemented if it doesn't decide whether sub is a subclass
+func subclassHook(cls, sub *py.Type) (py.Object, error) {
+	hook, err := py.GetAttrString(cls, "__subclasshook__")
+	if err != nil {
+		if py.IsException(py.AttributeError, err) {
+			return py.NotImplemented, nil
+		}
+		return nil, err
+	}
+	res, err := py.Call(hook, py.Tuple{sub}, nil)
+	if err != nil || res == py.NotImplemented {
+		return res, err
+	}
+	return py.MakeBool(res)
+}
+
+// IsSubclass returns true if sub is a subclass of cls, either
+// directly, because the __subclasshook__ of cls says so or because
+// sub or one of its bases has been registered with cls or one of its
+// subclasses
+func IsSubclass(sub, cls *py.Type) (bool, error) {
+	if !IsABC(cls) {
+		return sub.IsSubtype(cls), nil
+	}
+	ok, err := subclassHook(cls, sub)
+	if err != nil {
+		return false, err
+	}
+	if ok != py.NotImplemented {
+		return ok == py.True, nil
+	}
+	if sub.IsSubtype(cls) {
+		return true, nil
+	}
+	for _, registered := range registry[cls] {
+		ok, err := IsSubclass(sub, registered)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	for _, subclass := range subclasses[cls] {
+		ok, err := IsSubclass(sub, subclass)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// Register registers subclass as a virtual subclass of the ABC cls
+func Register(cls, subclass *py.Type) error {
+	ok, err := IsSubclass(subclass, cls)
+	if err != nil || ok {
+		return err // Already a subclass
+	}
+	// Subtle: test for cycles *after* testing for "already a
+	// subclass"; this is for sys.maxsize.
+	ok, err = IsSubclass(cls, subclass)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return py.ExceptionNewf(py.RuntimeError, "Refusing to create an inheritance cycle")
+	}
+	registry[cls] = append(registry[cls], subclass)
+	return nil
+}
+
+// Returns the argument as a class or raises a TypeError
+func classArg(arg py.Object, message string) (*py.Type, error) {
+	t, ok := arg.(*py.Type)
+	if !ok || !t.Type().IsSubtype(py.TypeType) {
+		return nil, py.ExceptionNewf(py.TypeError, message)
+	}
+	return t, nil
+}
+
+const register_doc = `register(subclass) -> subclass
+
+Register a virtual subclass of an ABC.
+
+Returns the subclass, to allow usage as a class decorator.`
+
+func abcmeta_register(self py.Object, arg py.Object) (py.Object, error) {
+	subclass, err := classArg(arg, "Can only register classes")
+	if err != nil {
+		return nil, err
+	}
+	err = Register(self.(*py.Type), subclass)
+	if err != nil {
+		return nil, err
+	}
+	return subclass, nil
+}
+
+const instancecheck_doc = `__instancecheck__(instance) -> bool
+
+Override for isinstance(instance, cls).`
+
+func abcmeta_instancecheck(self py.Object, instance py.Object) (py.Object, error) {
+	ok, err := IsSubclass(instance.Type(), self.(*py.Type))
+	return py.NewBool(ok), err
+}
+
+const subclasscheck_doc = `__subclasscheck__(subclass) -> bool
+
+Override for issubclass(subclass, cls).`
+
+func abcmeta_subclasscheck(self py.Object, arg py.Object) (py.Object, error) {
+	subclass, err := classArg(arg, "issubclass() arg 1 must be a class")
+	if err != nil {
+		return nil, err
+	}
+	ok, err := IsSubclass(subclass, self.(*py.Type))
+	return py.NewBool(ok), err
+}
+
+const abstractmethod_doc = `A decorator indicating abstract methods.
+
+Requires that the metaclass is ABCMeta or derived from it.  A
+class that has a metaclass derived from ABCMeta cannot be
+instantiated unless all of its abstract methods are overridden.
+The abstract methods can be called using any of the normal
+'super' call mechanisms.  abstractmethod() may be used to declare
+abstract methods for properties and descriptors.`
+
+func abc_abstractmethod(self py.Object, funcobj py.Object) (py.Object, error) {
+	_, err := py.SetAttrString(funcobj, "__isabstractmethod__", py.True)
+	if err != nil {
+		return nil, err
+	}
+	return funcobj, nil
+}
+
+const abc_class_doc = `Helper class that provides a standard way to create an ABC using
+inheritance.`
+
+// Makes the ABC class which abstract base classes can inherit from
+// instead of using ABCMeta as their metaclass
+func newABCClass() *py.Type {
+	dict := py.StringDict{
+		"__module__":   py.String("abc"),
+		"__qualname__": py.String("ABC"),
+		"__doc__":      py.String(abc_class_doc),
+	}
+	cls, err := ABCMetaNew(ABCMeta, py.Tuple{py.String("ABC"), py.Tuple{py.ObjectType}, dict}, nil)
+	if err != nil {
+		panic(err)
+	}
+	return cls.(*py.Type)
+}
+
+const abc_doc = `Abstract Base Classes (ABCs) according to PEP 3119.`
+
+// Initialise the module
+func init() {
+	ABCMeta.New = ABCMetaNew
+	ABCMeta.Dict["register"] = py.MustNewMethod("register", abcmeta_register, 0, register_doc)
+	ABCMeta.Dict["__instancecheck__"] = py.MustNewMethod("__instancecheck__", abcmeta_instancecheck, 0, instancecheck_doc)
+	ABCMeta.Dict["__subclasscheck__"] = py.MustNewMethod("__subclasscheck__", abcmeta_subclasscheck, 0, subclasscheck_doc)
+
+	methods := []*py.Method{
+		py.MustNewMethod("abstractmethod", abc_abstractmethod, 0, abstractmethod_doc),
+	}
+	globals := py.StringDict{
+		"ABCMeta": ABCMeta,
+		"ABC":     newABCClass(),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "abc",
+		Doc:     abc_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
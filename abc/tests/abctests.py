@@ -0,0 +1,194 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from abc import ABCMeta, ABC, abstractmethod
+from libtest import *
+
+doc="ABCMeta metaclass"
+class A(metaclass=ABCMeta):
+    pass
+assert type(A) is ABCMeta
+a = A()
+assert isinstance(a, A)
+
+doc="register"
+class B:
+    pass
+assert not isinstance(B(), A)
+assert not issubclass(B, A)
+assert A.register(B) is B
+assert isinstance(B(), A)
+assert issubclass(B, A)
+assert not issubclass(A, B)
+
+doc="register as decorator"
+@A.register
+class C:
+    pass
+assert issubclass(C, A)
+
+doc="subclasses of registered classes"
+class D(B):
+    pass
+assert issubclass(D, A)
+assert isinstance(D(), A)
+
+doc="registered builtins"
+A.register(int)
+assert isinstance(1, A)
+assert not isinstance("x", A)
+assert isinstance("x", (A, str))
+assert issubclass(bool, A)
+assert isinstance(True, A)
+assert issubclass(bool, int) and isinstance(False, int)
+
+doc="subclasses of ABCs"
+class E(A):
+    pass
+class F:
+    pass
+E.register(F)
+assert issubclass(F, E)
+assert issubclass(F, A)
+assert not issubclass(B, E)
+
+doc="register errors"
+try:
+    A.register(1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+class G(metaclass=ABCMeta):
+    pass
+class H(G):
+    pass
+try:
+    H.register(G)
+except RuntimeError:
+    pass
+else:
+    assert False, "RuntimeError not raised"
+
+doc="ABC"
+class I(ABC):
+    pass
+assert type(I) is ABCMeta
+assert isinstance(I(), ABC)
+assert I.__abstractmethods__ == frozenset()
+
+doc="abstract methods"
+class Shape(ABC):
+    @abstractmethod
+    def area(self):
+        return 0
+    @abstractmethod
+    def name(self):
+        pass
+    def describe(self):
+        return "%s %s" % (self.name(), self.area())
+assert Shape.area.__isabstractmethod__
+assert Shape.__abstractmethods__ == {"area", "name"}
+try:
+    Shape()
+except TypeError as e:
+    assert str(e) == "Can't instantiate abstract class Shape with abstract methods area, name", str(e)
+else:
+    assert False, "TypeError not raised"
+class Partial(Shape):
+    def name(self):
+        return "partial"
+assert Partial.__abstractmethods__ == {"area"}
+assertRaises(TypeError, Partial)
+try:
+    Partial()
+except TypeError as e:
+    assert str(e) == "Can't instantiate abstract class Partial with abstract method area", str(e)
+class Square(Partial):
+    def area(self):
+        return super().area() + 4
+assert Square.__abstractmethods__ == frozenset()
+assert Square().describe() == "partial 4"
+
+doc="abstract properties, classmethods and staticmethods"
+class Abstracts(ABC):
+    @property
+    @abstractmethod
+    def prop(self):
+        pass
+    @classmethod
+    @abstractmethod
+    def cm(cls):
+        pass
+    @staticmethod
+    @abstractmethod
+    def sm():
+        pass
+assert Abstracts.__abstractmethods__ == {"prop", "cm", "sm"}
+assert Abstracts.__dict__["prop"].__isabstractmethod__
+assert not property(lambda self: 1).__isabstractmethod__
+class Concrete(Abstracts):
+    prop = 1
+    @classmethod
+    def cm(cls):
+        return cls
+    @staticmethod
+    def sm():
+        return 2
+assert Concrete().prop == 1
+assert Concrete.cm() is Concrete
+
+doc="abstract methods need ABCMeta"
+class NotABC:
+    @abstractmethod
+    def f(self):
+        pass
+NotABC()
+
+doc="__subclasshook__"
+class Sized(ABC):
+    @abstractmethod
+    def __len__(self):
+        return 0
+    @classmethod
+    def __subclasshook__(cls, C):
+        if cls is Sized:
+            if any("__len__" in B.__dict__ for B in C.__mro__):
+                return True
+        return NotImplemented
+class HasLen:
+    def __len__(self):
+        return 1
+class NoLen:
+    pass
+assert issubclass(HasLen, Sized)
+assert isinstance(HasLen(), Sized)
+assert not issubclass(NoLen, Sized)
+assert not isinstance(NoLen(), Sized)
+class NotSized(HasLen):
+    pass
+assert issubclass(NotSized, Sized)
+class Never(ABC):
+    @classmethod
+    def __subclasshook__(cls, C):
+        return False
+class Sub(Never):
+    pass
+assert not issubclass(Sub, Never)
+assert object.__subclasshook__() is NotImplemented
+
+doc="__instancecheck__ and __subclasscheck__"
+class Meta(type):
+    def __instancecheck__(cls, instance):
+        return instance == "yes"
+    def __subclasscheck__(cls, subclass):
+        return subclass is int
+class Checked(metaclass=Meta):
+    pass
+assert isinstance("yes", Checked)
+assert not isinstance("no", Checked)
+assert issubclass(int, Checked)
+assert not issubclass(str, Checked)
+
+doc="finished"
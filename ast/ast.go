@@ -33,6 +33,8 @@ type Ast interface {
 	py.Object
 	GetLineno() int
 	GetColOffset() int
+	GetEndLineno() int
+	GetEndColOffset() int
 }
 
 // All ModBase nodes implement the Mod interface
@@ -59,14 +61,27 @@ type Slicer interface {
 	sliceNode()
 }
 
+// All PatternBase nodes implement the Pattern interface
+type Pattern interface {
+	Ast
+	patternNode()
+}
+
 // Position in the parse tree
+//
+// The end of the node is just past its last character.  It is zero if
+// it isn't known, eg for nodes made outside the parser.
 type Pos struct {
-	Lineno    int
-	ColOffset int
+	Lineno       int
+	ColOffset    int
+	EndLineno    int
+	EndColOffset int
 }
 
-func (o *Pos) GetLineno() int    { return o.Lineno }
-func (o *Pos) GetColOffset() int { return o.ColOffset }
+func (o *Pos) GetLineno() int       { return o.Lineno }
+func (o *Pos) GetColOffset() int    { return o.ColOffset }
+func (o *Pos) GetEndLineno() int    { return o.EndLineno }
+func (o *Pos) GetEndColOffset() int { return o.EndColOffset }
 
 // Base AST node
 type AST struct {
@@ -138,6 +153,7 @@ const (
 	BitXor
 	BitAnd
 	FloorDiv
+	MatMult
 )
 
 func (o OperatorNumber) String() string {
@@ -166,6 +182,8 @@ func (o OperatorNumber) String() string {
 		return "BitAnd()"
 	case FloorDiv:
 		return "FloorDiv()"
+	case MatMult:
+		return "MatMult()"
 	}
 	return fmt.Sprintf("UnknownOperatorNumber(%d)", o)
 }
@@ -283,6 +301,15 @@ type FunctionDef struct {
 	Returns       Expr
 }
 
+type AsyncFunctionDef struct {
+	StmtBase
+	Name          Identifier
+	Args          *Arguments
+	Body          []Stmt
+	DecoratorList []Expr
+	Returns       Expr
+}
+
 type ClassDef struct {
 	StmtBase
 	Name          Identifier
@@ -317,6 +344,14 @@ type AugAssign struct {
 	Value  Expr
 }
 
+type AnnAssign struct {
+	StmtBase
+	Target     Expr
+	Annotation Expr
+	Value      Expr
+	Simple     int
+}
+
 type For struct {
 	StmtBase
 	Target Expr
@@ -325,6 +360,14 @@ type For struct {
 	Orelse []Stmt
 }
 
+type AsyncFor struct {
+	StmtBase
+	Target Expr
+	Iter   Expr
+	Body   []Stmt
+	Orelse []Stmt
+}
+
 type While struct {
 	StmtBase
 	Test   Expr
@@ -345,6 +388,12 @@ type With struct {
 	Body  []Stmt
 }
 
+type AsyncWith struct {
+	StmtBase
+	Items []*WithItem
+	Body  []Stmt
+}
+
 type Raise struct {
 	StmtBase
 	Exc   Expr
@@ -404,6 +453,12 @@ type Continue struct {
 	StmtBase
 }
 
+type Match struct {
+	StmtBase
+	Subject Expr
+	Cases   []*MatchCase
+}
+
 // ------------------------------------------------------------
 // Expr nodes
 // ------------------------------------------------------------
@@ -418,6 +473,13 @@ type BoolOp struct {
 	Values []Expr
 }
 
+// NamedExpr is an assignment expression (target := value)
+type NamedExpr struct {
+	ExprBase
+	Target Expr
+	Value  Expr
+}
+
 type BinOp struct {
 	ExprBase
 	Left  Expr
@@ -444,6 +506,8 @@ type IfExp struct {
 	Orelse Expr
 }
 
+// Dict is a dictionary display.  A nil key marks a **mapping
+// unpacking of the corresponding value.
 type Dict struct {
 	ExprBase
 	Keys   []Expr
@@ -480,6 +544,11 @@ type GeneratorExp struct {
 	Generators []Comprehension
 }
 
+type Await struct {
+	ExprBase
+	Value Expr
+}
+
 type Yield struct {
 	ExprBase
 	Value Expr
@@ -498,6 +567,9 @@ type Compare struct {
 	Comparators []Expr
 }
 
+// Call is a function call.  A single trailing *args and **kwargs are
+// stored in Starargs and Kwargs.  Any other unpackings are stored as
+// Starred items in Args and as Keywords with an empty Arg.
 type Call struct {
 	ExprBase
 	Func     Expr
@@ -517,6 +589,18 @@ type Str struct {
 	S py.String
 }
 
+type FormattedValue struct {
+	ExprBase
+	Value      Expr
+	Conversion int
+	FormatSpec Expr
+}
+
+type JoinedStr struct {
+	ExprBase
+	Values []Expr
+}
+
 type Bytes struct {
 	ExprBase
 	S py.Bytes
@@ -640,6 +724,65 @@ type Index struct {
 	Value Expr
 }
 
+// ------------------------------------------------------------
+// Pattern nodes
+// ------------------------------------------------------------
+
+type PatternBase struct {
+	Pos
+}
+
+func (o *PatternBase) patternNode() {}
+
+type MatchValue struct {
+	PatternBase
+	Value Expr
+}
+
+type MatchSingleton struct {
+	PatternBase
+	Value Singleton
+}
+
+type MatchSequence struct {
+	PatternBase
+	Patterns []Pattern
+}
+
+// MatchMapping matches the Keys of a mapping against Patterns and
+// binds any remaining items to Rest if it is set
+type MatchMapping struct {
+	PatternBase
+	Keys     []Expr
+	Patterns []Pattern
+	Rest     Identifier
+}
+
+type MatchClass struct {
+	PatternBase
+	Cls         Expr
+	Patterns    []Pattern
+	KwdAttrs    []Identifier
+	KwdPatterns []Pattern
+}
+
+type MatchStar struct {
+	PatternBase
+	Name Identifier
+}
+
+// MatchAs with neither Pattern nor Name set is the wildcard pattern _
+type MatchAs struct {
+	PatternBase
+	Pattern Pattern
+	Name    Identifier
+}
+
+type MatchOr struct {
+	PatternBase
+	Patterns []Pattern
+}
+
 type Comprehension struct {
 	Target Expr
 	Iter   Expr
@@ -659,12 +802,13 @@ type ExceptHandler struct {
 
 type Arguments struct {
 	Pos
-	Args       []*Arg
-	Vararg     *Arg
-	Kwonlyargs []*Arg
-	KwDefaults []Expr
-	Kwarg      *Arg
-	Defaults   []Expr
+	Posonlyargs []*Arg
+	Args        []*Arg
+	Vararg      *Arg
+	Kwonlyargs  []*Arg
+	KwDefaults  []Expr
+	Kwarg       *Arg
+	Defaults    []Expr
 }
 
 type Arg struct {
@@ -691,6 +835,13 @@ type WithItem struct {
 	OptionalVars Expr
 }
 
+type MatchCase struct {
+	Pos
+	Pattern Pattern
+	Guard   Expr
+	Body    []Stmt
+}
+
 // Check interfaces
 
 var _ Ast = (*AST)(nil)
@@ -705,15 +856,19 @@ var _ Mod = (*Suite)(nil)
 // Stmt
 var _ Stmt = (*StmtBase)(nil)
 var _ Stmt = (*FunctionDef)(nil)
+var _ Stmt = (*AsyncFunctionDef)(nil)
 var _ Stmt = (*ClassDef)(nil)
 var _ Stmt = (*Return)(nil)
 var _ Stmt = (*Delete)(nil)
 var _ Stmt = (*Assign)(nil)
 var _ Stmt = (*AugAssign)(nil)
+var _ Stmt = (*AnnAssign)(nil)
 var _ Stmt = (*For)(nil)
+var _ Stmt = (*AsyncFor)(nil)
 var _ Stmt = (*While)(nil)
 var _ Stmt = (*If)(nil)
 var _ Stmt = (*With)(nil)
+var _ Stmt = (*AsyncWith)(nil)
 var _ Stmt = (*Raise)(nil)
 var _ Stmt = (*Try)(nil)
 var _ Stmt = (*Assert)(nil)
@@ -725,10 +880,12 @@ var _ Stmt = (*ExprStmt)(nil)
 var _ Stmt = (*Pass)(nil)
 var _ Stmt = (*Break)(nil)
 var _ Stmt = (*Continue)(nil)
+var _ Stmt = (*Match)(nil)
 
 // Expr
 var _ Expr = (*ExprBase)(nil)
 var _ Expr = (*BoolOp)(nil)
+var _ Expr = (*NamedExpr)(nil)
 var _ Expr = (*BinOp)(nil)
 var _ Expr = (*UnaryOp)(nil)
 var _ Expr = (*Lambda)(nil)
@@ -739,12 +896,15 @@ var _ Expr = (*ListComp)(nil)
 var _ Expr = (*SetComp)(nil)
 var _ Expr = (*DictComp)(nil)
 var _ Expr = (*GeneratorExp)(nil)
+var _ Expr = (*Await)(nil)
 var _ Expr = (*Yield)(nil)
 var _ Expr = (*YieldFrom)(nil)
 var _ Expr = (*Compare)(nil)
 var _ Expr = (*Call)(nil)
 var _ Expr = (*Num)(nil)
 var _ Expr = (*Str)(nil)
+var _ Expr = (*FormattedValue)(nil)
+var _ Expr = (*JoinedStr)(nil)
 var _ Expr = (*Bytes)(nil)
 var _ Expr = (*NameConstant)(nil)
 var _ Expr = (*Ellipsis)(nil)
@@ -761,6 +921,17 @@ var _ Slicer = (*Slice)(nil)
 var _ Slicer = (*ExtSlice)(nil)
 var _ Slicer = (*Index)(nil)
 
+// Pattern
+var _ Pattern = (*PatternBase)(nil)
+var _ Pattern = (*MatchValue)(nil)
+var _ Pattern = (*MatchSingleton)(nil)
+var _ Pattern = (*MatchSequence)(nil)
+var _ Pattern = (*MatchMapping)(nil)
+var _ Pattern = (*MatchClass)(nil)
+var _ Pattern = (*MatchStar)(nil)
+var _ Pattern = (*MatchAs)(nil)
+var _ Pattern = (*MatchOr)(nil)
+
 // Misc
 var _ Ast = (*ExceptHandler)(nil)
 var _ Ast = (*Arguments)(nil)
@@ -768,6 +939,7 @@ var _ Ast = (*Arg)(nil)
 var _ Ast = (*Keyword)(nil)
 var _ Ast = (*Alias)(nil)
 var _ Ast = (*WithItem)(nil)
+var _ Ast = (*MatchCase)(nil)
 
 // Python types
 var ASTType = py.ObjectType.NewTypeFlags("AST", "AST Node", nil, nil, py.ObjectType.Flags|py.TPFLAGS_BASE_EXC_SUBCLASS)
@@ -782,15 +954,19 @@ var SuiteType = ModBaseType.NewType("Suite", "Suite Node", nil, nil)
 // Stmt
 var StmtBaseType = ASTType.NewType("Stmt", "Stmt Node", nil, nil)
 var FunctionDefType = StmtBaseType.NewType("FunctionDef", "FunctionDef Node", nil, nil)
+var AsyncFunctionDefType = StmtBaseType.NewType("AsyncFunctionDef", "AsyncFunctionDef Node", nil, nil)
 var ClassDefType = StmtBaseType.NewType("ClassDef", "ClassDef Node", nil, nil)
 var ReturnType = StmtBaseType.NewType("Return", "Return Node", nil, nil)
 var DeleteType = StmtBaseType.NewType("Delete", "Delete Node", nil, nil)
 var AssignType = StmtBaseType.NewType("Assign", "Assign Node", nil, nil)
 var AugAssignType = StmtBaseType.NewType("AugAssign", "AugAssign Node", nil, nil)
+var AnnAssignType = StmtBaseType.NewType("AnnAssign", "AnnAssign Node", nil, nil)
 var ForType = StmtBaseType.NewType("For", "For Node", nil, nil)
+var AsyncForType = StmtBaseType.NewType("AsyncFor", "AsyncFor Node", nil, nil)
 var WhileType = StmtBaseType.NewType("While", "While Node", nil, nil)
 var IfType = StmtBaseType.NewType("If", "If Node", nil, nil)
 var WithType = StmtBaseType.NewType("With", "With Node", nil, nil)
+var AsyncWithType = StmtBaseType.NewType("AsyncWith", "AsyncWith Node", nil, nil)
 var RaiseType = StmtBaseType.NewType("Raise", "Raise Node", nil, nil)
 var TryType = StmtBaseType.NewType("Try", "Try Node", nil, nil)
 var AssertType = StmtBaseType.NewType("Assert", "Assert Node", nil, nil)
@@ -802,10 +978,12 @@ var ExprStmtType = StmtBaseType.NewType("ExprStmt", "ExprStmt Node", nil, nil)
 var PassType = StmtBaseType.NewType("Pass", "Pass Node", nil, nil)
 var BreakType = StmtBaseType.NewType("Break", "Break Node", nil, nil)
 var ContinueType = StmtBaseType.NewType("Continue", "Continue Node", nil, nil)
+var MatchType = StmtBaseType.NewType("Match", "Match Node", nil, nil)
 
 // Expr
 var ExprBaseType = ASTType.NewType("Expr", "Expr Node", nil, nil)
 var BoolOpType = ExprBaseType.NewType("BoolOp", "BoolOp Node", nil, nil)
+var NamedExprType = ExprBaseType.NewType("NamedExpr", "NamedExpr Node", nil, nil)
 var BinOpType = ExprBaseType.NewType("BinOp", "BinOp Node", nil, nil)
 var UnaryOpType = ExprBaseType.NewType("UnaryOp", "UnaryOp Node", nil, nil)
 var LambdaType = ExprBaseType.NewType("Lambda", "Lambda Node", nil, nil)
@@ -816,12 +994,15 @@ var ListCompType = ExprBaseType.NewType("ListComp", "ListComp Node", nil, nil)
 var SetCompType = ExprBaseType.NewType("SetComp", "SetComp Node", nil, nil)
 var DictCompType = ExprBaseType.NewType("DictComp", "DictComp Node", nil, nil)
 var GeneratorExpType = ExprBaseType.NewType("GeneratorExp", "GeneratorExp Node", nil, nil)
+var AwaitType = ExprBaseType.NewType("Await", "Await Node", nil, nil)
 var YieldType = ExprBaseType.NewType("Yield", "Yield Node", nil, nil)
 var YieldFromType = ExprBaseType.NewType("YieldFrom", "YieldFrom Node", nil, nil)
 var CompareType = ExprBaseType.NewType("Compare", "Compare Node", nil, nil)
 var CallType = ExprBaseType.NewType("Call", "Call Node", nil, nil)
 var NumType = ExprBaseType.NewType("Num", "Num Node", nil, nil)
 var StrType = ExprBaseType.NewType("Str", "Str Node", nil, nil)
+var FormattedValueType = ExprBaseType.NewType("FormattedValue", "FormattedValue Node", nil, nil)
+var JoinedStrType = ExprBaseType.NewType("JoinedStr", "JoinedStr Node", nil, nil)
 var BytesType = ExprBaseType.NewType("Bytes", "Bytes Node", nil, nil)
 var NameConstantType = ExprBaseType.NewType("NameConstant", "NameConstant Node", nil, nil)
 var EllipsisType = ExprBaseType.NewType("Ellipsis", "Ellipsis Node", nil, nil)
@@ -838,6 +1019,17 @@ var SliceType = SliceBaseType.NewType("Slice", "Slice Node", nil, nil)
 var ExtSliceType = SliceBaseType.NewType("ExtSlice", "ExtSlice Node", nil, nil)
 var IndexType = SliceBaseType.NewType("Index", "Index Node", nil, nil)
 
+// Pattern
+var PatternBaseType = ASTType.NewType("Pattern", "Pattern Node", nil, nil)
+var MatchValueType = PatternBaseType.NewType("MatchValue", "MatchValue Node", nil, nil)
+var MatchSingletonType = PatternBaseType.NewType("MatchSingleton", "MatchSingleton Node", nil, nil)
+var MatchSequenceType = PatternBaseType.NewType("MatchSequence", "MatchSequence Node", nil, nil)
+var MatchMappingType = PatternBaseType.NewType("MatchMapping", "MatchMapping Node", nil, nil)
+var MatchClassType = PatternBaseType.NewType("MatchClass", "MatchClass Node", nil, nil)
+var MatchStarType = PatternBaseType.NewType("MatchStar", "MatchStar Node", nil, nil)
+var MatchAsType = PatternBaseType.NewType("MatchAs", "MatchAs Node", nil, nil)
+var MatchOrType = PatternBaseType.NewType("MatchOr", "MatchOr Node", nil, nil)
+
 // Misc
 var ExceptHandlerType = ASTType.NewType("ExceptHandler", "ExceptHandler Node", nil, nil)
 var ArgumentsType = ASTType.NewType("Arguments", "Arguments Node", nil, nil)
@@ -845,70 +1037,90 @@ var ArgType = ASTType.NewType("Arg", "Arg Node", nil, nil)
 var KeywordType = ASTType.NewType("Keyword", "Keyword Node", nil, nil)
 var AliasType = ASTType.NewType("Alias", "Alias Node", nil, nil)
 var WithItemType = ASTType.NewType("WithItem", "WithItem Node", nil, nil)
+var MatchCaseType = ASTType.NewType("MatchCase", "MatchCase Node", nil, nil)
 
 // Python type definitions
-func (o *AST) Type() *py.Type           { return ASTType }
-func (o *ModBase) Type() *py.Type       { return ModBaseType }
-func (o *Module) Type() *py.Type        { return ModuleType }
-func (o *Interactive) Type() *py.Type   { return InteractiveType }
-func (o *Expression) Type() *py.Type    { return ExpressionType }
-func (o *Suite) Type() *py.Type         { return SuiteType }
-func (o *StmtBase) Type() *py.Type      { return StmtBaseType }
-func (o *FunctionDef) Type() *py.Type   { return FunctionDefType }
-func (o *ClassDef) Type() *py.Type      { return ClassDefType }
-func (o *Return) Type() *py.Type        { return ReturnType }
-func (o *Delete) Type() *py.Type        { return DeleteType }
-func (o *Assign) Type() *py.Type        { return AssignType }
-func (o *AugAssign) Type() *py.Type     { return AugAssignType }
-func (o *For) Type() *py.Type           { return ForType }
-func (o *While) Type() *py.Type         { return WhileType }
-func (o *If) Type() *py.Type            { return IfType }
-func (o *With) Type() *py.Type          { return WithType }
-func (o *Raise) Type() *py.Type         { return RaiseType }
-func (o *Try) Type() *py.Type           { return TryType }
-func (o *Assert) Type() *py.Type        { return AssertType }
-func (o *Import) Type() *py.Type        { return ImportType }
-func (o *ImportFrom) Type() *py.Type    { return ImportFromType }
-func (o *Global) Type() *py.Type        { return GlobalType }
-func (o *Nonlocal) Type() *py.Type      { return NonlocalType }
-func (o *ExprStmt) Type() *py.Type      { return ExprStmtType }
-func (o *Pass) Type() *py.Type          { return PassType }
-func (o *Break) Type() *py.Type         { return BreakType }
-func (o *Continue) Type() *py.Type      { return ContinueType }
-func (o *ExprBase) Type() *py.Type      { return ExprBaseType }
-func (o *BoolOp) Type() *py.Type        { return BoolOpType }
-func (o *BinOp) Type() *py.Type         { return BinOpType }
-func (o *UnaryOp) Type() *py.Type       { return UnaryOpType }
-func (o *Lambda) Type() *py.Type        { return LambdaType }
-func (o *IfExp) Type() *py.Type         { return IfExpType }
-func (o *Dict) Type() *py.Type          { return DictType }
-func (o *Set) Type() *py.Type           { return SetType }
-func (o *ListComp) Type() *py.Type      { return ListCompType }
-func (o *SetComp) Type() *py.Type       { return SetCompType }
-func (o *DictComp) Type() *py.Type      { return DictCompType }
-func (o *GeneratorExp) Type() *py.Type  { return GeneratorExpType }
-func (o *Yield) Type() *py.Type         { return YieldType }
-func (o *YieldFrom) Type() *py.Type     { return YieldFromType }
-func (o *Compare) Type() *py.Type       { return CompareType }
-func (o *Call) Type() *py.Type          { return CallType }
-func (o *Num) Type() *py.Type           { return NumType }
-func (o *Str) Type() *py.Type           { return StrType }
-func (o *Bytes) Type() *py.Type         { return BytesType }
-func (o *NameConstant) Type() *py.Type  { return NameConstantType }
-func (o *Ellipsis) Type() *py.Type      { return EllipsisType }
-func (o *Attribute) Type() *py.Type     { return AttributeType }
-func (o *Subscript) Type() *py.Type     { return SubscriptType }
-func (o *Starred) Type() *py.Type       { return StarredType }
-func (o *Name) Type() *py.Type          { return NameType }
-func (o *List) Type() *py.Type          { return ListType }
-func (o *Tuple) Type() *py.Type         { return TupleType }
-func (o *SliceBase) Type() *py.Type     { return SliceBaseType }
-func (o *Slice) Type() *py.Type         { return SliceType }
-func (o *ExtSlice) Type() *py.Type      { return ExtSliceType }
-func (o *Index) Type() *py.Type         { return IndexType }
-func (o *ExceptHandler) Type() *py.Type { return ExceptHandlerType }
-func (o *Arguments) Type() *py.Type     { return ArgumentsType }
-func (o *Arg) Type() *py.Type           { return ArgType }
-func (o *Keyword) Type() *py.Type       { return KeywordType }
-func (o *Alias) Type() *py.Type         { return AliasType }
-func (o *WithItem) Type() *py.Type      { return WithItemType }
+func (o *AST) Type() *py.Type              { return ASTType }
+func (o *ModBase) Type() *py.Type          { return ModBaseType }
+func (o *Module) Type() *py.Type           { return ModuleType }
+func (o *Interactive) Type() *py.Type      { return InteractiveType }
+func (o *Expression) Type() *py.Type       { return ExpressionType }
+func (o *Suite) Type() *py.Type            { return SuiteType }
+func (o *StmtBase) Type() *py.Type         { return StmtBaseType }
+func (o *FunctionDef) Type() *py.Type      { return FunctionDefType }
+func (o *AsyncFunctionDef) Type() *py.Type { return AsyncFunctionDefType }
+func (o *ClassDef) Type() *py.Type         { return ClassDefType }
+func (o *Return) Type() *py.Type           { return ReturnType }
+func (o *Delete) Type() *py.Type           { return DeleteType }
+func (o *Assign) Type() *py.Type           { return AssignType }
+func (o *AugAssign) Type() *py.Type        { return AugAssignType }
+func (o *AnnAssign) Type() *py.Type        { return AnnAssignType }
+func (o *For) Type() *py.Type              { return ForType }
+func (o *AsyncFor) Type() *py.Type         { return AsyncForType }
+func (o *While) Type() *py.Type            { return WhileType }
+func (o *If) Type() *py.Type               { return IfType }
+func (o *With) Type() *py.Type             { return WithType }
+func (o *AsyncWith) Type() *py.Type        { return AsyncWithType }
+func (o *Raise) Type() *py.Type            { return RaiseType }
+func (o *Try) Type() *py.Type              { return TryType }
+func (o *Assert) Type() *py.Type           { return AssertType }
+func (o *Import) Type() *py.Type           { return ImportType }
+func (o *ImportFrom) Type() *py.Type       { return ImportFromType }
+func (o *Global) Type() *py.Type           { return GlobalType }
+func (o *Nonlocal) Type() *py.Type         { return NonlocalType }
+func (o *ExprStmt) Type() *py.Type         { return ExprStmtType }
+func (o *Pass) Type() *py.Type             { return PassType }
+func (o *Break) Type() *py.Type            { return BreakType }
+func (o *Continue) Type() *py.Type         { return ContinueType }
+func (o *Match) Type() *py.Type            { return MatchType }
+func (o *ExprBase) Type() *py.Type         { return ExprBaseType }
+func (o *BoolOp) Type() *py.Type           { return BoolOpType }
+func (o *NamedExpr) Type() *py.Type        { return NamedExprType }
+func (o *BinOp) Type() *py.Type            { return BinOpType }
+func (o *UnaryOp) Type() *py.Type          { return UnaryOpType }
+func (o *Lambda) Type() *py.Type           { return LambdaType }
+func (o *IfExp) Type() *py.Type            { return IfExpType }
+func (o *Dict) Type() *py.Type             { return DictType }
+func (o *Set) Type() *py.Type              { return SetType }
+func (o *ListComp) Type() *py.Type         { return ListCompType }
+func (o *SetComp) Type() *py.Type          { return SetCompType }
+func (o *DictComp) Type() *py.Type         { return DictCompType }
+func (o *GeneratorExp) Type() *py.Type     { return GeneratorExpType }
+func (o *Await) Type() *py.Type            { return AwaitType }
+func (o *Yield) Type() *py.Type            { return YieldType }
+func (o *YieldFrom) Type() *py.Type        { return YieldFromType }
+func (o *Compare) Type() *py.Type          { return CompareType }
+func (o *Call) Type() *py.Type             { return CallType }
+func (o *Num) Type() *py.Type              { return NumType }
+func (o *Str) Type() *py.Type              { return StrType }
+func (o *FormattedValue) Type() *py.Type   { return FormattedValueType }
+func (o *JoinedStr) Type() *py.Type        { return JoinedStrType }
+func (o *Bytes) Type() *py.Type            { return BytesType }
+func (o *NameConstant) Type() *py.Type     { return NameConstantType }
+func (o *Ellipsis) Type() *py.Type         { return EllipsisType }
+func (o *Attribute) Type() *py.Type        { return AttributeType }
+func (o *Subscript) Type() *py.Type        { return SubscriptType }
+func (o *Starred) Type() *py.Type          { return StarredType }
+func (o *Name) Type() *py.Type             { return NameType }
+func (o *List) Type() *py.Type             { return ListType }
+func (o *Tuple) Type() *py.Type            { return TupleType }
+func (o *SliceBase) Type() *py.Type        { return SliceBaseType }
+func (o *Slice) Type() *py.Type            { return SliceType }
+func (o *ExtSlice) Type() *py.Type         { return ExtSliceType }
+func (o *Index) Type() *py.Type            { return IndexType }
+func (o *PatternBase) Type() *py.Type      { return PatternBaseType }
+func (o *MatchValue) Type() *py.Type       { return MatchValueType }
+func (o *MatchSingleton) Type() *py.Type   { return MatchSingletonType }
+func (o *MatchSequence) Type() *py.Type    { return MatchSequenceType }
+func (o *MatchMapping) Type() *py.Type     { return MatchMappingType }
+func (o *MatchClass) Type() *py.Type       { return MatchClassType }
+func (o *MatchStar) Type() *py.Type        { return MatchStarType }
+func (o *MatchAs) Type() *py.Type          { return MatchAsType }
+func (o *MatchOr) Type() *py.Type          { return MatchOrType }
+func (o *ExceptHandler) Type() *py.Type    { return ExceptHandlerType }
+func (o *Arguments) Type() *py.Type        { return ArgumentsType }
+func (o *Arg) Type() *py.Type              { return ArgType }
+func (o *Keyword) Type() *py.Type          { return KeywordType }
+func (o *Alias) Type() *py.Type            { return AliasType }
+func (o *WithItem) Type() *py.Type         { return WithItemType }
+func (o *MatchCase) Type() *py.Type        { return MatchCaseType }
@@ -30,6 +30,8 @@ func dumpItem(v interface{}) string {
 		return dump(x, "keyword")
 	case *WithItem:
 		return dump(x, "withitem")
+	case *MatchCase:
+		return dump(x, "match_case")
 	case *Arguments:
 		if x == nil {
 			return "None"
@@ -44,6 +46,7 @@ func dumpItem(v interface{}) string {
 	case StmtBase:
 	case ExprBase:
 	case SliceBase:
+	case PatternBase:
 	case Pos:
 	case *Alias:
 		return dump(v, "alias")
@@ -61,6 +64,31 @@ func dumpItem(v interface{}) string {
 	return fmt.Sprintf("%v", v)
 }
 
+// FieldName returns the python name of the field of a node from the
+// name of the Go struct field
+func FieldName(name string) string {
+	fname := strings.ToLower(name)
+	switch fname {
+	case "exprtype":
+		fname = "type"
+	case "contextexpr":
+		fname = "context_expr"
+	case "optionalvars":
+		fname = "optional_vars"
+	case "kwdefaults":
+		fname = "kw_defaults"
+	case "decoratorlist":
+		fname = "decorator_list"
+	case "formatspec":
+		fname = "format_spec"
+	case "kwdattrs":
+		fname = "kwd_attrs"
+	case "kwdpatterns":
+		fname = "kwd_patterns"
+	}
+	return fname
+}
+
 // Dump ast as a string with name
 func dump(ast interface{}, name string) string {
 	astValue := reflect.Indirect(reflect.ValueOf(ast))
@@ -69,21 +97,10 @@ func dump(ast interface{}, name string) string {
 	for i := 0; i < astType.NumField(); i++ {
 		fieldType := astType.Field(i)
 		fieldValue := astValue.Field(i)
-		fname := strings.ToLower(fieldType.Name)
-		switch fname {
-		case "stmtbase", "exprbase", "modbase", "slicebase", "pos":
+		if fieldType.Anonymous {
 			continue
-		case "exprtype":
-			fname = "type"
-		case "contextexpr":
-			fname = "context_expr"
-		case "optionalvars":
-			fname = "optional_vars"
-		case "kwdefaults":
-			fname = "kw_defaults"
-		case "decoratorlist":
-			fname = "decorator_list"
 		}
+		fname := FieldName(fieldType.Name)
 		if fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().Kind() != reflect.Uint8 {
 			strs := make([]string, fieldValue.Len())
 			for i := 0; i < fieldValue.Len(); i++ {
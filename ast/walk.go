@@ -49,6 +49,13 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		}
 	}
 
+	// walkPatterns walks all the patterns in the slice passed in
+	walkPatterns := func(patterns []Pattern) {
+		for _, pattern := range patterns {
+			walk(pattern)
+		}
+	}
+
 	switch node := ast.(type) {
 
 	// Module nodes
@@ -84,6 +91,19 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		walkExprs(node.DecoratorList)
 		walk(node.Returns)
 
+	case *AsyncFunctionDef:
+		// Name          Identifier
+		// Args          *Arguments
+		// Body          []Stmt
+		// DecoratorList []Expr
+		// Returns       Expr
+		if node.Args != nil {
+			walk(node.Args)
+		}
+		walkStmts(node.Body)
+		walkExprs(node.DecoratorList)
+		walk(node.Returns)
+
 	case *ClassDef:
 		// Name          Identifier
 		// Bases         []Expr
@@ -122,6 +142,15 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		walk(node.Target)
 		walk(node.Value)
 
+	case *AnnAssign:
+		// Target     Expr
+		// Annotation Expr
+		// Value      Expr
+		// Simple     int
+		walk(node.Target)
+		walk(node.Annotation)
+		walk(node.Value)
+
 	case *For:
 		// Target Expr
 		// Iter   Expr
@@ -132,6 +161,16 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		walkStmts(node.Body)
 		walkStmts(node.Orelse)
 
+	case *AsyncFor:
+		// Target Expr
+		// Iter   Expr
+		// Body   []Stmt
+		// Orelse []Stmt
+		walk(node.Target)
+		walk(node.Iter)
+		walkStmts(node.Body)
+		walkStmts(node.Orelse)
+
 	case *While:
 		// Test   Expr
 		// Body   []Stmt
@@ -156,6 +195,14 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		}
 		walkStmts(node.Body)
 
+	case *AsyncWith:
+		// Items []*WithItem
+		// Body  []Stmt
+		for _, wi := range node.Items {
+			walk(wi)
+		}
+		walkStmts(node.Body)
+
 	case *Raise:
 		// Exc   Expr
 		// Cause Expr
@@ -210,6 +257,14 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 
 	case *Continue:
 
+	case *Match:
+		// Subject Expr
+		// Cases   []*MatchCase
+		walk(node.Subject)
+		for _, c := range node.Cases {
+			walk(c)
+		}
+
 	// Expr nodes
 
 	case *BoolOp:
@@ -217,6 +272,12 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		// Values []Expr
 		walkExprs(node.Values)
 
+	case *NamedExpr:
+		// Target Expr
+		// Value  Expr
+		walk(node.Target)
+		walk(node.Value)
+
 	case *BinOp:
 		// Left  Expr
 		// Op    OperatorNumber
@@ -289,6 +350,10 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		// Value Expr
 		walk(node.Value)
 
+	case *Await:
+		// Value Expr
+		walk(node.Value)
+
 	case *Compare:
 		// Left        Expr
 		// Ops         []CmpOp
@@ -316,6 +381,14 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 	case *Str:
 		// S py.String
 
+	case *FormattedValue:
+		walk(node.Value)
+		// Conversion int
+		walk(node.FormatSpec)
+
+	case *JoinedStr:
+		walkExprs(node.Values)
+
 	case *Bytes:
 		// S py.Bytes
 
@@ -376,6 +449,47 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		// Value Expr
 		walk(node.Value)
 
+	// Pattern nodes
+
+	case *MatchValue:
+		// Value Expr
+		walk(node.Value)
+
+	case *MatchSingleton:
+		// Value Singleton
+
+	case *MatchSequence:
+		// Patterns []Pattern
+		walkPatterns(node.Patterns)
+
+	case *MatchMapping:
+		// Keys     []Expr
+		// Patterns []Pattern
+		// Rest     Identifier
+		walkExprs(node.Keys)
+		walkPatterns(node.Patterns)
+
+	case *MatchClass:
+		// Cls         Expr
+		// Patterns    []Pattern
+		// KwdAttrs    []Identifier
+		// KwdPatterns []Pattern
+		walk(node.Cls)
+		walkPatterns(node.Patterns)
+		walkPatterns(node.KwdPatterns)
+
+	case *MatchStar:
+		// Name Identifier
+
+	case *MatchAs:
+		// Pattern Pattern
+		// Name    Identifier
+		walk(node.Pattern)
+
+	case *MatchOr:
+		// Patterns []Pattern
+		walkPatterns(node.Patterns)
+
 	// Misc nodes
 
 	case *ExceptHandler:
@@ -386,12 +500,16 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		walkStmts(node.Body)
 
 	case *Arguments:
-		// Args       []*Arg
-		// Vararg     *Arg
-		// Kwonlyargs []*Arg
-		// KwDefaults []Expr
-		// Kwarg      *Arg
-		// Defaults   []Expr
+		// Posonlyargs []*Arg
+		// Args        []*Arg
+		// Vararg      *Arg
+		// Kwonlyargs  []*Arg
+		// KwDefaults  []Expr
+		// Kwarg       *Arg
+		// Defaults    []Expr
+		for _, arg := range node.Posonlyargs {
+			walk(arg)
+		}
 		for _, arg := range node.Args {
 			walk(arg)
 		}
@@ -429,6 +547,14 @@ func Walk(ast Ast, Visit func(Ast) bool) {
 		walk(node.ContextExpr)
 		walk(node.OptionalVars)
 
+	case *MatchCase:
+		// Pattern Pattern
+		// Guard   Expr
+		// Body    []Stmt
+		walk(node.Pattern)
+		walk(node.Guard)
+		walkStmts(node.Body)
+
 	default:
 		panic(fmt.Sprintf("Unknown ast node %T, %#v", node, node))
 	}
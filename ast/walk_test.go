@@ -28,15 +28,19 @@ func TestWalk(t *testing.T) {
 		{&Expression{}, []string{"*ast.Expression"}},
 		{&Suite{}, []string{"*ast.Suite"}},
 		{&FunctionDef{}, []string{"*ast.FunctionDef"}},
+		{&AsyncFunctionDef{}, []string{"*ast.AsyncFunctionDef"}},
 		{&ClassDef{}, []string{"*ast.ClassDef"}},
 		{&Return{}, []string{"*ast.Return"}},
 		{&Delete{}, []string{"*ast.Delete"}},
 		{&Assign{}, []string{"*ast.Assign"}},
 		{&AugAssign{}, []string{"*ast.AugAssign"}},
+		{&AnnAssign{}, []string{"*ast.AnnAssign"}},
 		{&For{}, []string{"*ast.For"}},
+		{&AsyncFor{}, []string{"*ast.AsyncFor"}},
 		{&While{}, []string{"*ast.While"}},
 		{&If{}, []string{"*ast.If"}},
 		{&With{}, []string{"*ast.With"}},
+		{&AsyncWith{}, []string{"*ast.AsyncWith"}},
 		{&Raise{}, []string{"*ast.Raise"}},
 		{&Try{}, []string{"*ast.Try"}},
 		{&Assert{}, []string{"*ast.Assert"}},
@@ -48,7 +52,9 @@ func TestWalk(t *testing.T) {
 		{&Pass{}, []string{"*ast.Pass"}},
 		{&Break{}, []string{"*ast.Break"}},
 		{&Continue{}, []string{"*ast.Continue"}},
+		{&Match{}, []string{"*ast.Match"}},
 		{&BoolOp{}, []string{"*ast.BoolOp"}},
+		{&NamedExpr{}, []string{"*ast.NamedExpr"}},
 		{&BinOp{}, []string{"*ast.BinOp"}},
 		{&UnaryOp{}, []string{"*ast.UnaryOp"}},
 		{&Lambda{}, []string{"*ast.Lambda"}},
@@ -61,10 +67,14 @@ func TestWalk(t *testing.T) {
 		{&GeneratorExp{}, []string{"*ast.GeneratorExp"}},
 		{&Yield{}, []string{"*ast.Yield"}},
 		{&YieldFrom{}, []string{"*ast.YieldFrom"}},
+		{&Await{}, []string{"*ast.Await"}},
 		{&Compare{}, []string{"*ast.Compare"}},
 		{&Call{}, []string{"*ast.Call"}},
 		{&Num{}, []string{"*ast.Num"}},
 		{&Str{}, []string{"*ast.Str"}},
+		{&FormattedValue{}, []string{"*ast.FormattedValue"}},
+		{&JoinedStr{}, []string{"*ast.JoinedStr"}},
+		{&JoinedStr{Values: []Expr{&Str{}, &FormattedValue{Value: &Num{}, FormatSpec: &JoinedStr{}}}}, []string{"*ast.JoinedStr", "*ast.Str", "*ast.FormattedValue", "*ast.Num", "*ast.JoinedStr"}},
 		{&Bytes{}, []string{"*ast.Bytes"}},
 		{&NameConstant{}, []string{"*ast.NameConstant"}},
 		{&Ellipsis{}, []string{"*ast.Ellipsis"}},
@@ -77,12 +87,21 @@ func TestWalk(t *testing.T) {
 		{&Slice{}, []string{"*ast.Slice"}},
 		{&ExtSlice{}, []string{"*ast.ExtSlice"}},
 		{&Index{}, []string{"*ast.Index"}},
+		{&MatchValue{}, []string{"*ast.MatchValue"}},
+		{&MatchSingleton{}, []string{"*ast.MatchSingleton"}},
+		{&MatchSequence{}, []string{"*ast.MatchSequence"}},
+		{&MatchMapping{}, []string{"*ast.MatchMapping"}},
+		{&MatchClass{}, []string{"*ast.MatchClass"}},
+		{&MatchStar{}, []string{"*ast.MatchStar"}},
+		{&MatchAs{}, []string{"*ast.MatchAs"}},
+		{&MatchOr{}, []string{"*ast.MatchOr"}},
 		{&ExceptHandler{}, []string{"*ast.ExceptHandler"}},
 		{&Arguments{}, []string{"*ast.Arguments"}},
 		{&Arg{}, []string{"*ast.Arg"}},
 		{&Keyword{}, []string{"*ast.Keyword"}},
 		{&Alias{}, []string{"*ast.Alias"}},
 		{&WithItem{}, []string{"*ast.WithItem"}},
+		{&MatchCase{}, []string{"*ast.MatchCase"}},
 
 		// Excercise the walk* closures
 		{&Module{Body: []Stmt{&Pass{}}}, []string{"*ast.Module", "*ast.Pass"}},
@@ -90,6 +109,7 @@ func TestWalk(t *testing.T) {
 		{&Expression{Body: &Num{}}, []string{"*ast.Expression", "*ast.Num"}},
 		{&Attribute{Value: &Num{}}, []string{"*ast.Attribute", "*ast.Num"}},
 		{&List{Elts: []Expr{&Num{}, &Str{}}}, []string{"*ast.List", "*ast.Num", "*ast.Str"}},
+		{&Match{Subject: &Name{}, Cases: []*MatchCase{{Pattern: &MatchOr{Patterns: []Pattern{&MatchValue{Value: &Num{}}, &MatchAs{}}}, Body: []Stmt{&Pass{}}}}}, []string{"*ast.Match", "*ast.Name", "*ast.MatchCase", "*ast.MatchOr", "*ast.MatchValue", "*ast.Num", "*ast.MatchAs", "*ast.Pass"}},
 		{&ListComp{Elt: &Num{}, Generators: []Comprehension{{Target: &Num{}, Iter: &Str{}, Ifs: []Expr{&Num{}, &Str{}}}}}, []string{"*ast.ListComp", "*ast.Num", "*ast.Num", "*ast.Str", "*ast.Num", "*ast.Str"}},
 	} {
 		out = nil
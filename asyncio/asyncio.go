@@ -0,0 +1,549 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Asyncio module
+//
+// A minimal asyncio which runs coroutines on a simple single threaded
+// round robin event loop.
+//
+// Tasks communicate with the loop by what they yield when they
+// suspend:
+//
+//   - a sleep iterator means reschedule the task after the delay
+//   - a Task means reschedule the task once that Task is done
+//   - None means reschedule the task straight away
+
+package asyncio
+
+import (
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// InvalidStateError is raised when a Task is in the wrong state for
+// the operation
+var InvalidStateError = py.ExceptionType.NewType("InvalidStateError", "The operation is not allowed in this state.", nil, nil)
+
+// The event loop currently running or nil if there isn't one
+var running *eventLoop
+
+// A task waiting to be woken at a given time
+type sleeper struct {
+	wake time.Time
+	task *Task
+}
+
+// A simple round robin event loop
+type eventLoop struct {
+	ready    []*Task   // tasks ready to run in the order they will run
+	sleeping []sleeper // sleeping tasks ordered by wake time
+	tasks    []*Task   // all the tasks made by this loop
+}
+
+// Returns the running loop or raises RuntimeError if there isn't one
+func getRunningLoop() (*eventLoop, error) {
+	if running == nil {
+		return nil, py.ExceptionNewf(py.RuntimeError, "no running event loop")
+	}
+	return running, nil
+}
+
+// Makes a new task for coro and schedules it
+func (loop *eventLoop) createTask(coro *py.Coroutine) *Task {
+	t := &Task{coro: coro}
+	loop.tasks = append(loop.tasks, t)
+	loop.ready = append(loop.ready, t)
+	return t
+}
+
+// Schedules t to be run after delay
+func (loop *eventLoop) sleep(t *Task, delay time.Duration) {
+	wake := time.Now().Add(delay)
+	i := len(loop.sleeping)
+	for i > 0 && loop.sleeping[i-1].wake.After(wake) {
+		i--
+	}
+	loop.sleeping = append(loop.sleeping, sleeper{})
+	copy(loop.sleeping[i+1:], loop.sleeping[i:])
+	loop.sleeping[i] = sleeper{wake: wake, task: t}
+}
+
+// Moves any sleeping tasks which are due onto the ready queue
+//
+// If there are no ready tasks then this waits for the first sleeper
+func (loop *eventLoop) wake() {
+	if len(loop.ready) == 0 && len(loop.sleeping) > 0 {
+		time.Sleep(time.Until(loop.sleeping[0].wake))
+	}
+	now := time.Now()
+	for len(loop.sleeping) > 0 && !loop.sleeping[0].wake.After(now) {
+		loop.ready = append(loop.ready, loop.sleeping[0].task)
+		loop.sleeping = loop.sleeping[1:]
+	}
+}
+
+// Runs t until it next suspends, then schedules it according to what
+// it suspended with
+func (loop *eventLoop) step(t *Task) {
+	var res py.Object
+	var err error
+	if t.pending != nil {
+		exc := t.pending
+		t.pending = nil
+		res, err = t.coro.Throw(py.Tuple{exc}, nil)
+	} else {
+		res, err = t.coro.Send(py.None)
+	}
+	if err != nil {
+		if py.IsException(py.StopIteration, err) {
+			loop.finish(t, py.StopIterationValue(err), nil)
+		} else {
+			loop.finish(t, nil, err)
+		}
+		return
+	}
+	switch x := res.(type) {
+	case *sleepIter:
+		loop.sleep(t, x.delay)
+	case *Task:
+		if x == t {
+			t.pending = py.ExceptionNewf(py.RuntimeError, "Task cannot await on itself")
+			loop.ready = append(loop.ready, t)
+		} else if x.done {
+			loop.ready = append(loop.ready, t)
+		} else {
+			x.waiters = append(x.waiters, t)
+		}
+	case py.NoneType:
+		loop.ready = append(loop.ready, t)
+	default:
+		repr, _ := py.ReprAsString(res)
+		t.pending = py.ExceptionNewf(py.RuntimeError, "Task got bad yield: %s", repr)
+		loop.ready = append(loop.ready, t)
+	}
+}
+
+// Marks t as done and wakes anything waiting for it
+func (loop *eventLoop) finish(t *Task, result py.Object, err error) {
+	t.done = true
+	t.result = result
+	t.err = err
+	loop.ready = append(loop.ready, t.waiters...)
+	t.waiters = nil
+}
+
+// Runs the loop until main is done
+func (loop *eventLoop) run(main *Task) error {
+	for !main.done {
+		loop.wake()
+		if len(loop.ready) == 0 {
+			return py.ExceptionNewf(py.RuntimeError, "event loop stopped before the main task completed")
+		}
+		t := loop.ready[0]
+		loop.ready = loop.ready[1:]
+		loop.step(t)
+	}
+	return nil
+}
+
+// Closes the coroutines of any tasks which didn't finish
+func (loop *eventLoop) close() {
+	for _, t := range loop.tasks {
+		if !t.done {
+			_, _ = t.coro.Close()
+			t.done = true
+			t.err = py.ExceptionNewf(InvalidStateError, "Task was closed before it finished")
+		}
+	}
+}
+
+// Make a StopIteration carrying value
+func stopIteration(value py.Object) error {
+	exc, err := py.ExceptionNew(py.StopIteration, py.Tuple{value}, nil)
+	if err != nil {
+		return err
+	}
+	return exc.(*py.Exception)
+}
+
+// Returns the exception instance from err
+func exceptionValue(err error) py.Object {
+	switch x := err.(type) {
+	case py.ExceptionInfo:
+		return x.Value
+	case *py.ExceptionInfo:
+		return x.Value
+	}
+	return py.MakeException(err)
+}
+
+// TaskType is the type of Task objects
+var TaskType = py.NewType("Task", "A coroutine scheduled to run on the event loop.")
+
+// Task is a coroutine being run by the event loop
+type Task struct {
+	coro    *py.Coroutine
+	done    bool
+	result  py.Object
+	err     error
+	pending *py.Exception // to be thrown into coro when next run
+	waiters []*Task       // tasks to wake when this is done
+}
+
+// Type of this Task object
+func (t *Task) Type() *py.Type {
+	return TaskType
+}
+
+func (t *Task) M__await__() (py.Object, error) {
+	return &taskWaiter{task: t}, nil
+}
+
+func (t *Task) M__repr__() (py.Object, error) {
+	if !t.done {
+		return py.String("<Task pending>"), nil
+	}
+	if t.err != nil {
+		repr, err := py.ReprAsString(exceptionValue(t.err))
+		if err != nil {
+			return nil, err
+		}
+		return py.String("<Task finished exception=" + repr + ">"), nil
+	}
+	repr, err := py.ReprAsString(t.result)
+	if err != nil {
+		return nil, err
+	}
+	return py.String("<Task finished result=" + repr + ">"), nil
+}
+
+func init() {
+	TaskType.Dict["done"] = py.MustNewMethod("done", func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*Task).done), nil
+	}, 0, "done() -> True if the task is done.")
+	TaskType.Dict["result"] = py.MustNewMethod("result", func(self py.Object) (py.Object, error) {
+		t := self.(*Task)
+		if !t.done {
+			return nil, py.ExceptionNewf(InvalidStateError, "Result is not set.")
+		}
+		if t.err != nil {
+			return nil, t.err
+		}
+		return t.result, nil
+	}, 0, "result() -> the result of the task, raising its exception if it had one.")
+	TaskType.Dict["exception"] = py.MustNewMethod("exception", func(self py.Object) (py.Object, error) {
+		t := self.(*Task)
+		if !t.done {
+			return nil, py.ExceptionNewf(InvalidStateError, "Exception is not set.")
+		}
+		if t.err != nil {
+			return exceptionValue(t.err), nil
+		}
+		return py.None, nil
+	}, 0, "exception() -> the exception raised by the task or None.")
+}
+
+// The iterator which awaiting a Task drives
+type taskWaiter struct {
+	task *Task
+}
+
+var taskWaiterType = py.NewType("task_waiter", "")
+
+// Type of this object
+func (it *taskWaiter) Type() *py.Type {
+	return taskWaiterType
+}
+
+func (it *taskWaiter) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *taskWaiter) M__next__() (py.Object, error) {
+	t := it.task
+	if !t.done {
+		return t, nil
+	}
+	if t.err != nil {
+		return nil, t.err
+	}
+	return nil, stopIteration(t.result)
+}
+
+func (it *taskWaiter) Send(value py.Object) (py.Object, error) {
+	return it.M__next__()
+}
+
+// The awaitable returned by sleep
+type sleepAwaitable struct {
+	delay  time.Duration
+	result py.Object
+}
+
+var sleepAwaitableType = py.NewType("sleep", "")
+
+// Type of this object
+func (s *sleepAwaitable) Type() *py.Type {
+	return sleepAwaitableType
+}
+
+func (s *sleepAwaitable) M__await__() (py.Object, error) {
+	return &sleepIter{delay: s.delay, result: s.result}, nil
+}
+
+// The iterator which awaiting sleep drives. This yields itself to
+// tell the loop how long to sleep for then returns the result.
+type sleepIter struct {
+	delay   time.Duration
+	result  py.Object
+	yielded bool
+}
+
+var sleepIterType = py.NewType("sleep_iterator", "")
+
+// Type of this object
+func (it *sleepIter) Type() *py.Type {
+	return sleepIterType
+}
+
+func (it *sleepIter) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *sleepIter) M__next__() (py.Object, error) {
+	if !it.yielded {
+		it.yielded = true
+		return it, nil
+	}
+	return nil, stopIteration(it.result)
+}
+
+func (it *sleepIter) Send(value py.Object) (py.Object, error) {
+	return it.M__next__()
+}
+
+// The awaitable returned by gather
+type gatherAwaitable struct {
+	children []*Task
+}
+
+var gatherAwaitableType = py.NewType("gather", "")
+
+// Type of this object
+func (g *gatherAwaitable) Type() *py.Type {
+	return gatherAwaitableType
+}
+
+func (g *gatherAwaitable) M__await__() (py.Object, error) {
+	return &gatherIter{gather: g}, nil
+}
+
+// The iterator which awaiting gather drives. This yields each
+// unfinished child in turn then returns the list of results.
+type gatherIter struct {
+	gather *gatherAwaitable
+}
+
+var gatherIterType = py.NewType("gather_iterator", "")
+
+// Type of this object
+func (it *gatherIter) Type() *py.Type {
+	return gatherIterType
+}
+
+func (it *gatherIter) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *gatherIter) M__next__() (py.Object, error) {
+	for _, child := range it.gather.children {
+		if !child.done {
+			return child, nil
+		}
+	}
+	results := make([]py.Object, len(it.gather.children))
+	for i, child := range it.gather.children {
+		if child.err != nil {
+			return nil, child.err
+		}
+		results[i] = child.result
+	}
+	return nil, stopIteration(py.NewListFromItems(results))
+}
+
+func (it *gatherIter) Send(value py.Object) (py.Object, error) {
+	return it.M__next__()
+}
+
+// Check interface is satisfied
+var _ py.I__await__ = (*Task)(nil)
+var _ py.I_iterator = (*taskWaiter)(nil)
+var _ py.I_send = (*taskWaiter)(nil)
+var _ py.I__await__ = (*sleepAwaitable)(nil)
+var _ py.I_iterator = (*sleepIter)(nil)
+var _ py.I_send = (*sleepIter)(nil)
+var _ py.I__await__ = (*gatherAwaitable)(nil)
+var _ py.I_iterator = (*gatherIter)(nil)
+var _ py.I_send = (*gatherIter)(nil)
+
+const asyncio_run_doc = `run(main) -> result of main
+
+Run the coroutine main on a new event loop until it is complete and
+return its result. Any other tasks which haven't finished when main
+completes are closed.
+
+This can't be called when another event loop is running.`
+
+func asyncio_run(self py.Object, arg py.Object) (py.Object, error) {
+	if running != nil {
+		return nil, py.ExceptionNewf(py.RuntimeError, "asyncio.run() cannot be called from a running event loop")
+	}
+	coro, ok := arg.(*py.Coroutine)
+	if !ok {
+		repr, err := py.ReprAsString(arg)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "a coroutine was expected, got %s", repr)
+	}
+	loop := &eventLoop{}
+	running = loop
+	defer func() {
+		loop.close()
+		running = nil
+	}()
+	main := loop.createTask(coro)
+	err := loop.run(main)
+	if err != nil {
+		return nil, err
+	}
+	if main.err != nil {
+		return nil, main.err
+	}
+	return main.result, nil
+}
+
+const asyncio_sleep_doc = `sleep(delay, result=None) -> awaitable
+
+Suspend the current task for delay seconds letting other tasks run.
+When awaited this returns result.`
+
+func asyncio_sleep(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var delayObj py.Object
+	var result py.Object = py.None
+	kwlist := []string{"delay", "result"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:sleep", kwlist, &delayObj, &result)
+	if err != nil {
+		return nil, err
+	}
+	delay, err := py.FloatAsFloat64(delayObj)
+	if err != nil {
+		return nil, err
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return &sleepAwaitable{delay: time.Duration(delay * float64(time.Second)), result: result}, nil
+}
+
+// Turns an argument into a Task scheduled on loop
+func ensureTask(loop *eventLoop, arg py.Object) (*Task, error) {
+	switch x := arg.(type) {
+	case *Task:
+		return x, nil
+	case *py.Coroutine:
+		return loop.createTask(x), nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "a Task or coroutine is required, not %s", arg.Type().Name)
+}
+
+const asyncio_gather_doc = `gather(*aws) -> awaitable
+
+Run the coroutines or Tasks in aws concurrently. When awaited this
+returns a list of their results in the order of aws, raising the first
+exception in that order if any of them failed.`
+
+func asyncio_gather(self py.Object, args py.Tuple) (py.Object, error) {
+	loop, err := getRunningLoop()
+	if err != nil {
+		return nil, err
+	}
+	g := &gatherAwaitable{}
+	for _, arg := range args {
+		t, err := ensureTask(loop, arg)
+		if err != nil {
+			return nil, err
+		}
+		g.children = append(g.children, t)
+	}
+	return g, nil
+}
+
+const asyncio_create_task_doc = `create_task(coro) -> Task
+
+Schedule the coroutine coro to run on the running event loop and
+return its Task.`
+
+func asyncio_create_task(self py.Object, arg py.Object) (py.Object, error) {
+	loop, err := getRunningLoop()
+	if err != nil {
+		return nil, err
+	}
+	coro, ok := arg.(*py.Coroutine)
+	if !ok {
+		repr, err := py.ReprAsString(arg)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "a coroutine was expected, got %s", repr)
+	}
+	return loop.createTask(coro), nil
+}
+
+const asyncio_iscoroutine_doc = `iscoroutine(obj) -> bool
+
+Return True if obj is a coroutine object.`
+
+func asyncio_iscoroutine(self py.Object, arg py.Object) (py.Object, error) {
+	_, ok := arg.(*py.Coroutine)
+	return py.NewBool(ok), nil
+}
+
+const asyncio_iscoroutinefunction_doc = `iscoroutinefunction(func) -> bool
+
+Return True if func is a coroutine function (defined with async def).`
+
+func asyncio_iscoroutinefunction(self py.Object, arg py.Object) (py.Object, error) {
+	fn, ok := arg.(*py.Function)
+	return py.NewBool(ok && fn.Code.Flags&py.CO_COROUTINE != 0), nil
+}
+
+const asyncio_doc = `A minimal asyncio.
+
+Coroutines are run concurrently on a simple single threaded event loop
+by run. Awaiting sleep, a Task or the result of gather lets other tasks
+run.`
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("create_task", asyncio_create_task, 0, asyncio_create_task_doc),
+		py.MustNewMethod("gather", asyncio_gather, 0, asyncio_gather_doc),
+		py.MustNewMethod("iscoroutine", asyncio_iscoroutine, 0, asyncio_iscoroutine_doc),
+		py.MustNewMethod("iscoroutinefunction", asyncio_iscoroutinefunction, 0, asyncio_iscoroutinefunction_doc),
+		py.MustNewMethod("run", asyncio_run, 0, asyncio_run_doc),
+		py.MustNewMethod("sleep", asyncio_sleep, 0, asyncio_sleep_doc),
+	}
+	globals := py.StringDict{
+		"InvalidStateError": InvalidStateError,
+		"Task":              TaskType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "asyncio",
+		Doc:     asyncio_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,15 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package asyncio_test
+
+import (
+	"testing"
+
+	"github.com/go-python/gpython/pytest"
+)
+
+func TestAsyncio(t *testing.T) {
+	pytest.RunTests(t, "tests")
+}
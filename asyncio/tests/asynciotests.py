@@ -0,0 +1,200 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import asyncio
+from libtest import *
+
+doc="run"
+async def answer():
+    return 42
+assertEqual(asyncio.run(answer()), 42)
+
+doc="run with awaits"
+async def add(a, b):
+    await asyncio.sleep(0)
+    return a + b
+async def main():
+    x = await add(1, 2)
+    return await add(x, 3)
+assertEqual(asyncio.run(main()), 6)
+
+doc="run propagates exceptions"
+async def fails():
+    await asyncio.sleep(0)
+    raise ValueError("boom")
+assertRaisesText(ValueError, "boom", asyncio.run, fails())
+
+doc="run needs a coroutine"
+assertRaisesText(ValueError, "a coroutine was expected", asyncio.run, 1)
+
+doc="sleep result"
+async def sleeper():
+    return await asyncio.sleep(0.001, "slept")
+assertEqual(asyncio.run(sleeper()), "slept")
+
+doc="tasks interleave"
+events = []
+async def worker(name, n):
+    for i in range(n):
+        events.append((name, i))
+        await asyncio.sleep(0)
+    return name
+async def main():
+    a = asyncio.create_task(worker("a", 3))
+    b = asyncio.create_task(worker("b", 2))
+    assertEqual(a.done(), False)
+    ra = await a
+    rb = await b
+    return ra, rb
+assertEqual(asyncio.run(main()), ("a", "b"))
+assertEqual(events, [("a", 0), ("b", 0), ("a", 1), ("b", 1), ("a", 2)])
+
+doc="sleep ordering"
+events = []
+async def delayed(name, delay):
+    await asyncio.sleep(delay)
+    events.append(name)
+async def main():
+    await asyncio.gather(delayed("slow", 0.02), delayed("fast", 0.01), delayed("now", 0))
+asyncio.run(main())
+assertEqual(events, ["now", "fast", "slow"])
+
+doc="gather"
+async def main():
+    return await asyncio.gather(add(1, 2), add(3, 4), answer())
+assertEqual(asyncio.run(main()), [3, 7, 42])
+
+doc="gather nothing"
+async def main():
+    return await asyncio.gather()
+assertEqual(asyncio.run(main()), [])
+
+doc="gather with tasks"
+async def main():
+    t = asyncio.create_task(add(5, 6))
+    return await asyncio.gather(t, add(1, 1))
+assertEqual(asyncio.run(main()), [11, 2])
+
+doc="gather exception"
+async def main():
+    try:
+        await asyncio.gather(add(1, 2), fails())
+    except ValueError as e:
+        return "caught " + e.args[0]
+assertEqual(asyncio.run(main()), "caught boom")
+
+doc="gather bad argument"
+async def main():
+    asyncio.gather(1)
+assertRaisesText(TypeError, "a Task or coroutine is required", asyncio.run, main())
+
+doc="task result and exception"
+async def main():
+    good = asyncio.create_task(add(1, 2))
+    bad = asyncio.create_task(fails())
+    assertRaises(asyncio.InvalidStateError, good.result)
+    assertRaises(asyncio.InvalidStateError, good.exception)
+    await asyncio.sleep(0.001)
+    assertEqual(good.done(), True)
+    assertEqual(good.result(), 3)
+    assertEqual(good.exception(), None)
+    assertEqual(bad.done(), True)
+    assertRaisesText(ValueError, "boom", bad.result)
+    assertEqual(type(bad.exception()), ValueError)
+    return repr(good)
+assertEqual(asyncio.run(main()), "<Task finished result=3>")
+
+doc="await a task twice"
+async def main():
+    t = asyncio.create_task(add(2, 2))
+    return (await t) + (await t)
+assertEqual(asyncio.run(main()), 8)
+
+doc="task awaiting itself"
+async def main():
+    global me
+    await me
+async def start():
+    global me
+    me = asyncio.create_task(main())
+    await me
+assertRaisesText(RuntimeError, "Task cannot await on itself", asyncio.run, start())
+
+doc="bad yield"
+class BadYield:
+    def __await__(self):
+        yield "rubbish"
+async def main():
+    await BadYield()
+assertRaisesText(RuntimeError, "Task got bad yield", asyncio.run, main())
+
+doc="unfinished tasks are closed"
+state = "running"
+async def forever():
+    global state
+    try:
+        while True:
+            await asyncio.sleep(0)
+    finally:
+        state = "closed"
+async def main():
+    asyncio.create_task(forever())
+    await asyncio.sleep(0)
+asyncio.run(main())
+assertEqual(state, "closed")
+
+doc="no running loop"
+assertRaisesText(RuntimeError, "no running event loop", asyncio.create_task, answer())
+assertRaisesText(RuntimeError, "no running event loop", asyncio.gather)
+
+doc="nested run"
+async def main():
+    coro = answer()
+    try:
+        asyncio.run(coro)
+    finally:
+        coro.close()
+assertRaisesText(RuntimeError, "cannot be called from a running event loop", asyncio.run, main())
+
+doc="iscoroutine"
+c = answer()
+assertEqual(asyncio.iscoroutine(c), True)
+assertEqual(asyncio.iscoroutine(answer), False)
+c.close()
+
+doc="iscoroutinefunction"
+def plain():
+    pass
+assertEqual(asyncio.iscoroutinefunction(answer), True)
+assertEqual(asyncio.iscoroutinefunction(plain), False)
+assertEqual(asyncio.iscoroutinefunction(1), False)
+
+doc="async for and async with under the loop"
+class Ticker:
+    def __init__(self, n):
+        self.i = 0
+        self.n = n
+    def __aiter__(self):
+        return self
+    async def __anext__(self):
+        if self.i >= self.n:
+            raise StopAsyncIteration
+        await asyncio.sleep(0)
+        self.i += 1
+        return self.i
+class Lock:
+    async def __aenter__(self):
+        await asyncio.sleep(0)
+        return self
+    async def __aexit__(self, typ, value, tb):
+        await asyncio.sleep(0)
+async def main():
+    total = 0
+    async with Lock():
+        async for i in Ticker(4):
+            total += i
+    return total
+assertEqual(asyncio.run(main()), 10)
+
+doc="finished"
@@ -0,0 +1,365 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Base64 module
+
+package base64
+
+import (
+	"bytes"
+	"encoding/base32"
+	gobase64 "encoding/base64"
+	"encoding/hex"
+
+	"github.com/go-python/gpython/binascii"
+	"github.com/go-python/gpython/py"
+)
+
+const base64_doc = `Base16, Base32, Base64 (RFC 3548) data encodings`
+
+// The length of the lines written by encodebytes
+const maxLineLength = 76
+
+// Returns the bytes to encode
+func encodeArg(s py.Object) ([]byte, error) {
+	return binascii.DataArg(s, false)
+}
+
+// Returns the bytes to decode which may also be an ASCII str
+func decodeArg(s py.Object) ([]byte, error) {
+	return binascii.DataArg(s, true)
+}
+
+// Replaces the characters in from by the matching ones in to
+func translate(b []byte, from, to []byte) []byte {
+	out := make([]byte, len(b))
+	for i, c := range b {
+		if j := bytes.IndexByte(from, c); j >= 0 {
+			c = to[j]
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// Returns the two alternative characters for + and /
+func altcharsArg(altchars py.Object) ([]byte, error) {
+	if altchars == py.None {
+		return nil, nil
+	}
+	alt, err := decodeArg(altchars)
+	if err != nil {
+		return nil, err
+	}
+	if len(alt) != 2 {
+		return nil, py.ExceptionNewf(py.ValueError, "altchars must be a bytes-like object of length 2, not %d", len(alt))
+	}
+	return alt, nil
+}
+
+// Returns true if b is only base64 characters followed by up to 2
+// padding characters
+func isValidBase64(b []byte) bool {
+	end := len(bytes.TrimRight(b, "="))
+	if len(b)-end > 2 {
+		return false
+	}
+	for _, c := range b[:end] {
+		if bytes.IndexByte([]byte(binascii.Base64Alphabet), c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Decodes base64 in b with the alternative characters alt for + and /
+func b64decode(b, alt []byte, validate bool) (py.Object, error) {
+	if alt != nil {
+		b = translate(b, alt, []byte("+/"))
+	}
+	if validate && !isValidBase64(b) {
+		return nil, py.ExceptionNewf(binascii.Error, "Non-base64 digit found")
+	}
+	out, err := binascii.A2bBase64(b, gobase64.StdEncoding, binascii.Base64Alphabet)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(out), nil
+}
+
+const b64encode_doc = `Encode the bytes-like object s using Base64 and return a bytes object.
+
+Optional altchars should be a byte string of length 2 which specifies an
+alternative alphabet for the '+' and '/' characters.  This allows an
+application to e.g. generate url or filesystem safe Base64 strings.`
+
+func base64_b64encode(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var s, altchars py.Object = nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:b64encode", []string{"s", "altchars"}, &s, &altchars)
+	if err != nil {
+		return nil, err
+	}
+	b, err := encodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	alt, err := altcharsArg(altchars)
+	if err != nil {
+		return nil, err
+	}
+	out := []byte(gobase64.StdEncoding.EncodeToString(b))
+	if alt != nil {
+		out = translate(out, []byte("+/"), alt)
+	}
+	return py.Bytes(out), nil
+}
+
+const b64decode_doc = `Decode the Base64 encoded bytes-like object or ASCII string s.
+
+Optional altchars must be a bytes-like object or ASCII string of length 2
+which specifies the alternative alphabet used instead of the '+' and '/'
+characters.
+
+The result is returned as a bytes object.  A binascii.Error is raised if
+s is incorrectly padded.
+
+If validate is False (the default), characters that are neither in the
+normal base-64 alphabet nor the alternative alphabet are discarded prior
+to the padding check.  If validate is True, these non-alphabet characters
+in the input result in a binascii.Error.`
+
+func base64_b64decode(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var s, altchars, validate py.Object = nil, py.None, py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:b64decode", []string{"s", "altchars", "validate"}, &s, &altchars, &validate)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	alt, err := altcharsArg(altchars)
+	if err != nil {
+		return nil, err
+	}
+	return b64decode(b, alt, py.ObjectIsTrue(validate))
+}
+
+const standard_b64encode_doc = `Encode bytes-like object s using the standard Base64 alphabet.
+
+The result is returned as a bytes object.`
+
+func base64_standard_b64encode(self, s py.Object) (py.Object, error) {
+	b, err := encodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(gobase64.StdEncoding.EncodeToString(b)), nil
+}
+
+const standard_b64decode_doc = `Decode bytes encoded with the standard Base64 alphabet.
+
+Argument s is a bytes-like object or ASCII string to decode.  The result
+is returned as a bytes object.  A binascii.Error is raised if the input
+is incorrectly padded.  Characters that are not in the standard alphabet
+are discarded prior to the padding check.`
+
+func base64_standard_b64decode(self, s py.Object) (py.Object, error) {
+	b, err := decodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return b64decode(b, nil, false)
+}
+
+const urlsafe_b64encode_doc = `Encode bytes using the URL- and filesystem-safe Base64 alphabet.
+
+Argument s is a bytes-like object to encode.  The result is returned as a
+bytes object.  The alphabet uses '-' instead of '+' and '_' instead of
+'/'.`
+
+func base64_urlsafe_b64encode(self, s py.Object) (py.Object, error) {
+	b, err := encodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(gobase64.URLEncoding.EncodeToString(b)), nil
+}
+
+const urlsafe_b64decode_doc = `Decode bytes using the URL- and filesystem-safe Base64 alphabet.
+
+Argument s is a bytes-like object or ASCII string to decode.  The result
+is returned as a bytes object.  A binascii.Error is raised if the input
+is incorrectly padded.  Characters that are not in the URL-safe base-64
+alphabet, and are not a plus '+' or slash '/', are discarded prior to the
+padding check.
+
+The alphabet uses '-' instead of '+' and '_' instead of '/'.`
+
+func base64_urlsafe_b64decode(self, s py.Object) (py.Object, error) {
+	b, err := decodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return b64decode(b, []byte("-_"), false)
+}
+
+const b32encode_doc = `Encode the bytes-like object s using Base32 and return a bytes object.`
+
+func base64_b32encode(self, s py.Object) (py.Object, error) {
+	b, err := encodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(base32.StdEncoding.EncodeToString(b)), nil
+}
+
+const b32decode_doc = `Decode the Base32 encoded bytes-like object or ASCII string s.
+
+Optional casefold is a flag specifying whether a lowercase alphabet is
+acceptable as input.  For security purposes, the default is False.
+
+RFC 3548 allows for optional mapping of the digit 0 (zero) to the
+letter O (oh), and for optional mapping of the digit 1 (one) to
+either the letter I (eye) or letter L (el).  The optional argument
+map01 when not None, specifies which letter the digit 1 should be
+mapped to (when map01 is not None, the digit 0 is always mapped to
+the letter O).  For security purposes the default is None, so that
+0 and 1 are not allowed in the input.
+
+The result is returned as a bytes object.  A binascii.Error is raised if
+the input is incorrectly padded or if there are non-alphabet
+characters present in the input.`
+
+func base64_b32decode(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var s, casefold, map01 py.Object = nil, py.False, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:b32decode", []string{"s", "casefold", "map01"}, &s, &casefold, &map01)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b)%8 != 0 {
+		return nil, py.ExceptionNewf(binascii.Error, "Incorrect padding")
+	}
+	if map01 != py.None {
+		m, err := decodeArg(map01)
+		if err != nil {
+			return nil, err
+		}
+		if len(m) != 1 {
+			return nil, py.ExceptionNewf(py.ValueError, "map01 must be length 1")
+		}
+		b = translate(b, []byte("01"), []byte{'O', m[0]})
+	}
+	if py.ObjectIsTrue(casefold) {
+		b = bytes.ToUpper(b)
+	}
+	out, err := base32.StdEncoding.DecodeString(string(b))
+	if err != nil {
+		return nil, py.ExceptionNewf(binascii.Error, "Non-base32 digit found")
+	}
+	return py.Bytes(out), nil
+}
+
+const b16encode_doc = `Encode the bytes-like object s using Base16 and return a bytes object.`
+
+func base64_b16encode(self, s py.Object) (py.Object, error) {
+	b, err := encodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(bytes.ToUpper([]byte(hex.EncodeToString(b)))), nil
+}
+
+const b16decode_doc = `Decode the Base16 encoded bytes-like object or ASCII string s.
+
+Optional casefold is a flag specifying whether a lowercase alphabet is
+acceptable as input.  For security purposes, the default is False.
+
+The result is returned as a bytes object.  A binascii.Error is raised if
+s is incorrectly padded or if there are non-alphabet characters present
+in the input.`
+
+func base64_b16decode(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var s, casefold py.Object = nil, py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:b16decode", []string{"s", "casefold"}, &s, &casefold)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	if py.ObjectIsTrue(casefold) {
+		b = bytes.ToUpper(b)
+	}
+	for _, c := range b {
+		if !('0' <= c && c <= '9' || 'A' <= c && c <= 'F') {
+			return nil, py.ExceptionNewf(binascii.Error, "Non-base16 digit found")
+		}
+	}
+	if len(b)%2 != 0 {
+		return nil, py.ExceptionNewf(binascii.Error, "Odd-length string")
+	}
+	out, err := hex.DecodeString(string(b))
+	if err != nil {
+		return nil, py.ExceptionNewf(binascii.Error, "Non-base16 digit found")
+	}
+	return py.Bytes(out), nil
+}
+
+const encodebytes_doc = `Encode a bytestring into a bytes object containing multiple lines
+of base-64 data.`
+
+func base64_encodebytes(self, s py.Object) (py.Object, error) {
+	b, err := encodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	chunk := maxLineLength / 4 * 3
+	for i := 0; i < len(b); i += chunk {
+		end := i + chunk
+		if end > len(b) {
+			end = len(b)
+		}
+		out = append(out, gobase64.StdEncoding.EncodeToString(b[i:end])...)
+		out = append(out, '\n')
+	}
+	return py.Bytes(out), nil
+}
+
+const decodebytes_doc = `Decode a bytestring of base-64 data into a bytes object.`
+
+func base64_decodebytes(self, s py.Object) (py.Object, error) {
+	b, err := encodeArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return b64decode(b, nil, false)
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "base64",
+		Doc:  base64_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("b64encode", base64_b64encode, 0, b64encode_doc),
+			py.MustNewMethod("b64decode", base64_b64decode, 0, b64decode_doc),
+			py.MustNewMethod("standard_b64encode", base64_standard_b64encode, 0, standard_b64encode_doc),
+			py.MustNewMethod("standard_b64decode", base64_standard_b64decode, 0, standard_b64decode_doc),
+			py.MustNewMethod("urlsafe_b64encode", base64_urlsafe_b64encode, 0, urlsafe_b64encode_doc),
+			py.MustNewMethod("urlsafe_b64decode", base64_urlsafe_b64decode, 0, urlsafe_b64decode_doc),
+			py.MustNewMethod("b32encode", base64_b32encode, 0, b32encode_doc),
+			py.MustNewMethod("b32decode", base64_b32decode, 0, b32decode_doc),
+			py.MustNewMethod("b16encode", base64_b16encode, 0, b16encode_doc),
+			py.MustNewMethod("b16decode", base64_b16decode, 0, b16decode_doc),
+			py.MustNewMethod("encodebytes", base64_encodebytes, 0, encodebytes_doc),
+			py.MustNewMethod("decodebytes", base64_decodebytes, 0, decodebytes_doc),
+		},
+	})
+}
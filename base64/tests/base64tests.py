@@ -0,0 +1,50 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import base64
+import binascii
+from libtest import *
+
+doc = "b64"
+assert base64.b64encode(b"hello") == b"aGVsbG8="
+assert base64.b64encode(bytearray(b"")) == b""
+assert base64.b64encode(b"\xfb\xff\xfe", altchars=b"-_") == b"-__-"
+assert base64.b64decode(b"aGVsbG8=") == b"hello"
+assert base64.b64decode("aGVsbG8=\n") == b"hello"
+assert base64.b64decode(b"aGVs bG8=") == b"hello"
+assert base64.b64decode(b"-__-", b"-_") == b"\xfb\xff\xfe"
+assertRaises(binascii.Error, base64.b64decode, b"aGVsbG8")
+assertRaises(binascii.Error, base64.b64decode, b"aGVs bG8=", validate=True)
+assert base64.b64decode(b"aGVsbG8=", validate=True) == b"hello"
+assertRaises(TypeError, base64.b64encode, "hello")
+assertRaises(ValueError, base64.b64decode, "h\xe9")
+
+doc = "standard and urlsafe"
+assert base64.standard_b64encode(b"\xfb\xff\xfe") == b"+//+"
+assert base64.standard_b64decode(b"+//+") == b"\xfb\xff\xfe"
+assert base64.urlsafe_b64encode(b"\xfb\xff\xfe") == b"-__-"
+assert base64.urlsafe_b64decode("-__-") == b"\xfb\xff\xfe"
+
+doc = "b32"
+assert base64.b32encode(b"hello") == b"NBSWY3DP"
+assert base64.b32decode(b"NBSWY3DP") == b"hello"
+assert base64.b32decode(b"nbswy3dp", casefold=True) == b"hello"
+assert base64.b32decode("0" * 8, map01=b"L") == b"s\x9c\xe79\xce"
+assertRaises(binascii.Error, base64.b32decode, b"nbswy3dp")
+assertRaises(binascii.Error, base64.b32decode, b"NBSWY3D")
+
+doc = "b16"
+assert base64.b16encode(b"\xab\xcd") == b"ABCD"
+assert base64.b16decode(b"ABCD") == b"\xab\xcd"
+assert base64.b16decode(b"abcd", casefold=True) == b"\xab\xcd"
+assertRaises(binascii.Error, base64.b16decode, b"abcd")
+assertRaises(binascii.Error, base64.b16decode, b"ABC")
+
+doc = "encodebytes"
+encoded = base64.encodebytes(b"x" * 60)
+assert encoded == b"eHh4" * 19 + b"\neHh4\n"
+assert base64.decodebytes(encoded) == b"x" * 60
+assert base64.encodebytes(b"") == b""
+
+doc = "finished"
@@ -0,0 +1,266 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Binascii module
+
+package binascii
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"hash/crc32"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const binascii_doc = `Conversion between binary data and ASCII`
+
+var (
+	Error      = py.ValueError.NewType("Error", "Raised for invalid data.", nil, nil)
+	Incomplete = py.ExceptionType.NewType("Incomplete", "Raised for incomplete data.", nil, nil)
+)
+
+// DataArg returns the bytes of a bytes like object, or of a str of
+// ASCII characters if ascii is set as the a2b functions accept
+func DataArg(data py.Object, ascii bool) ([]byte, error) {
+	switch b := data.(type) {
+	case py.Bytes:
+		return b, nil
+	case *py.ByteArray:
+		return b.Data, nil
+	case py.String:
+		if ascii {
+			for _, r := range b {
+				if r >= 0x80 {
+					return nil, py.ExceptionNewf(py.ValueError, "string argument should contain only ASCII characters")
+				}
+			}
+			return []byte(b), nil
+		}
+	}
+	if ascii {
+		return nil, py.ExceptionNewf(py.TypeError, "argument should be bytes, buffer or ASCII string, not '%s'", data.Type().Name)
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", data.Type().Name)
+}
+
+// Hexlify returns the hex of b with sep between each group of
+// bytesPerSep bytes counting from the right, or the left if
+// bytesPerSep is negative
+func Hexlify(b []byte, sep string, bytesPerSep int) []byte {
+	h := hex.EncodeToString(b)
+	if sep == "" || bytesPerSep == 0 || len(b) == 0 {
+		return []byte(h)
+	}
+	n := bytesPerSep
+	if n < 0 {
+		n = -n
+	}
+	var groups []string
+	if bytesPerSep < 0 {
+		for i := 0; i < len(b); i += n {
+			end := i + n
+			if end > len(b) {
+				end = len(b)
+			}
+			groups = append(groups, h[2*i:2*end])
+		}
+	} else {
+		for i := len(b); i > 0; i -= n {
+			start := i - n
+			if start < 0 {
+				start = 0
+			}
+			groups = append([]string{h[2*start : 2*i]}, groups...)
+		}
+	}
+	return []byte(strings.Join(groups, sep))
+}
+
+const hexlify_doc = `hexlify(data, sep=None, bytes_per_sep=1) -> bytes
+
+Hexadecimal representation of binary data.
+
+  sep
+    An optional single character or byte to separate hex bytes.
+  bytes_per_sep
+    How many bytes between separators.  Positive values count from the
+    right, negative values count from the left.
+
+The return value is a bytes object.  This function is also
+available as "b2a_hex()".`
+
+func binascii_hexlify(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var data, sep, bytesPerSep py.Object = nil, py.None, py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:hexlify", []string{"data", "sep", "bytes_per_sep"}, &data, &sep, &bytesPerSep)
+	if err != nil {
+		return nil, err
+	}
+	b, err := DataArg(data, false)
+	if err != nil {
+		return nil, err
+	}
+	sepStr := ""
+	if sep != py.None {
+		s, err := DataArg(sep, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(s) != 1 {
+			return nil, py.ExceptionNewf(py.ValueError, "sep must be length 1.")
+		}
+		sepStr = string(s)
+	}
+	n, err := py.IndexInt(bytesPerSep)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(Hexlify(b, sepStr, n)), nil
+}
+
+const unhexlify_doc = `unhexlify(hexstr) -> bytes
+
+Binary data of hexadecimal representation.
+
+hexstr must contain an even number of hex digits (upper or lower case).
+This function is also available as "a2b_hex()".`
+
+func binascii_unhexlify(self, hexstr py.Object) (py.Object, error) {
+	b, err := DataArg(hexstr, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(b)%2 != 0 {
+		return nil, py.ExceptionNewf(Error, "Odd-length string")
+	}
+	out, err := hex.DecodeString(string(b))
+	if err != nil {
+		return nil, py.ExceptionNewf(Error, "Non-hexadecimal digit found")
+	}
+	return py.Bytes(out), nil
+}
+
+const crc32_doc = `crc32(data, crc=0) -> int
+
+Compute CRC-32 incrementally.`
+
+func binascii_crc32(self py.Object, args py.Tuple) (py.Object, error) {
+	var data, crc py.Object = nil, py.Int(0)
+	err := py.UnpackTuple(args, nil, "crc32", 1, 2, &data, &crc)
+	if err != nil {
+		return nil, err
+	}
+	b, err := DataArg(data, false)
+	if err != nil {
+		return nil, err
+	}
+	// Only the low 32 bits of the starting value are used
+	low, err := py.And(crc, py.Int(0xffffffff))
+	if err != nil {
+		return nil, err
+	}
+	value, err := py.IndexInt(low)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(crc32.Update(uint32(value), crc32.IEEETable, b)), nil
+}
+
+const b2a_base64_doc = `b2a_base64(data, *, newline=True) -> bytes
+
+Base64-code line of data.`
+
+func binascii_b2a_base64(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var data, newline py.Object = nil, py.True
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:b2a_base64", []string{"data", "newline"}, &data, &newline)
+	if err != nil {
+		return nil, err
+	}
+	b, err := DataArg(data, false)
+	if err != nil {
+		return nil, err
+	}
+	out := []byte(base64.StdEncoding.EncodeToString(b))
+	if py.ObjectIsTrue(newline) {
+		out = append(out, '\n')
+	}
+	return py.Bytes(out), nil
+}
+
+// A2bBase64 decodes base64 discarding any characters which aren't in
+// the alphabet of enc, as well as any after the padding
+func A2bBase64(b []byte, enc *base64.Encoding, alphabet string) ([]byte, error) {
+	clean := make([]byte, 0, len(b))
+	quadPos, padding := 0, 0
+	for _, c := range b {
+		if c == '=' {
+			padding++
+			if quadPos >= 2 && quadPos+padding >= 4 {
+				quadPos = 0
+				break
+			}
+			continue
+		}
+		if strings.IndexByte(alphabet, c) < 0 {
+			continue
+		}
+		padding = 0
+		clean = append(clean, c)
+		quadPos = (quadPos + 1) % 4
+	}
+	switch quadPos {
+	case 0:
+	case 1:
+		return nil, py.ExceptionNewf(Error, "Invalid base64-encoded string: number of data characters (%d) cannot be 1 more than a multiple of 4", len(clean))
+	default:
+		return nil, py.ExceptionNewf(Error, "Incorrect padding")
+	}
+	out, err := enc.WithPadding(base64.NoPadding).DecodeString(string(clean))
+	if err != nil {
+		return nil, py.ExceptionNewf(Error, "%v", err)
+	}
+	return out, nil
+}
+
+// The characters of the standard base64 alphabet
+const Base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+const a2b_base64_doc = `a2b_base64(data) -> bytes
+
+Decode a line of base64 data.`
+
+func binascii_a2b_base64(self, data py.Object) (py.Object, error) {
+	b, err := DataArg(data, true)
+	if err != nil {
+		return nil, err
+	}
+	out, err := A2bBase64(b, base64.StdEncoding, Base64Alphabet)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(out), nil
+}
+
+func init() {
+	hexlify := py.MustNewMethod("hexlify", binascii_hexlify, 0, hexlify_doc)
+	unhexlify := py.MustNewMethod("unhexlify", binascii_unhexlify, 0, unhexlify_doc)
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "binascii",
+		Doc:  binascii_doc,
+		Methods: []*py.Method{
+			hexlify,
+			unhexlify,
+			py.MustNewMethod("crc32", binascii_crc32, 0, crc32_doc),
+			py.MustNewMethod("b2a_base64", binascii_b2a_base64, 0, b2a_base64_doc),
+			py.MustNewMethod("a2b_base64", binascii_a2b_base64, 0, a2b_base64_doc),
+		},
+		Globals: py.StringDict{
+			"Error":      Error,
+			"Incomplete": Incomplete,
+			"b2a_hex":    hexlify,
+			"a2b_hex":    unhexlify,
+		},
+	})
+}
@@ -0,0 +1,37 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import binascii
+from libtest import *
+
+doc = "hexlify"
+assert binascii.hexlify(b"\x01\xab\xff") == b"01abff"
+assert binascii.b2a_hex(bytearray(b"\x00")) == b"00"
+assert binascii.hexlify(b"\x01\x02\x03\x04\x05", "-", 2) == b"01-0203-0405"
+assert binascii.hexlify(b"\x01\x02\x03\x04\x05", b":", -2) == b"0102:0304:05"
+assert binascii.hexlify(b"") == b""
+assertRaises(TypeError, binascii.hexlify, "abc")
+
+doc = "unhexlify"
+assert binascii.unhexlify(b"01abff") == b"\x01\xab\xff"
+assert binascii.a2b_hex("01ABFF") == b"\x01\xab\xff"
+assertRaises(binascii.Error, binascii.unhexlify, b"abc")
+assertRaises(binascii.Error, binascii.unhexlify, b"zz")
+assert issubclass(binascii.Error, ValueError)
+
+doc = "crc32"
+assert binascii.crc32(b"hello") == 907060870
+assert binascii.crc32(b"world", binascii.crc32(b"hello ")) == 222957957
+assert binascii.crc32(b"a", -1) == 3310005809
+assert binascii.crc32(b"") == 0
+
+doc = "base64"
+assert binascii.b2a_base64(b"hello") == b"aGVsbG8=\n"
+assert binascii.b2a_base64(b"hello", newline=False) == b"aGVsbG8="
+assert binascii.a2b_base64(b"aGVsbG8=") == b"hello"
+assert binascii.a2b_base64("aGVs\nbG8=rubbish") == b"hello"
+assertRaises(binascii.Error, binascii.a2b_base64, b"aGVsbG8")
+assertRaises(binascii.Error, binascii.a2b_base64, b"a")
+
+doc = "finished"
@@ -8,10 +8,12 @@ package builtin
 import (
 	"fmt"
 	"math/big"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/go-python/gpython/compile"
 	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/pyast"
 )
 
 const builtin_doc = `Built-in functions, exceptions, and other objects.
@@ -28,24 +30,25 @@ func init() {
 		py.MustNewMethod("any", builtin_any, 0, any_doc),
 		py.MustNewMethod("ascii", builtin_ascii, 0, ascii_doc),
 		py.MustNewMethod("bin", builtin_bin, 0, bin_doc),
+		py.MustNewMethod("breakpoint", builtin_breakpoint, 0, breakpoint_doc),
 		// py.MustNewMethod("callable", builtin_callable, 0, callable_doc),
 		py.MustNewMethod("chr", builtin_chr, 0, chr_doc),
 		py.MustNewMethod("compile", builtin_compile, 0, compile_doc),
 		py.MustNewMethod("delattr", builtin_delattr, 0, delattr_doc),
-		// py.MustNewMethod("dir", builtin_dir, 0, dir_doc),
+		py.MustNewMethod("dir", builtin_dir, 0, dir_doc),
 		py.MustNewMethod("divmod", builtin_divmod, 0, divmod_doc),
 		py.MustNewMethod("eval", py.InternalMethodEval, 0, eval_doc),
 		py.MustNewMethod("exec", py.InternalMethodExec, 0, exec_doc),
-		// py.MustNewMethod("format", builtin_format, 0, format_doc),
+		py.MustNewMethod("format", builtin_format, 0, format_doc),
 		py.MustNewMethod("getattr", builtin_getattr, 0, getattr_doc),
 		py.MustNewMethod("globals", py.InternalMethodGlobals, 0, globals_doc),
 		py.MustNewMethod("hasattr", builtin_hasattr, 0, hasattr_doc),
-		// py.MustNewMethod("hash", builtin_hash, 0, hash_doc),
+		py.MustNewMethod("hash", builtin_hash, 0, hash_doc),
 		py.MustNewMethod("hex", builtin_hex, 0, hex_doc),
 		// py.MustNewMethod("id", builtin_id, 0, id_doc),
-		// py.MustNewMethod("input", builtin_input, 0, input_doc),
+		py.MustNewMethod("input", builtin_input, 0, input_doc),
 		py.MustNewMethod("isinstance", builtin_isinstance, 0, isinstance_doc),
-		// py.MustNewMethod("issubclass", builtin_issubclass, 0, issubclass_doc),
+		py.MustNewMethod("issubclass", builtin_issubclass, 0, issubclass_doc),
 		py.MustNewMethod("iter", builtin_iter, 0, iter_doc),
 		py.MustNewMethod("len", builtin_len, 0, len_doc),
 		py.MustNewMethod("locals", py.InternalMethodLocals, 0, locals_doc),
@@ -53,7 +56,7 @@ func init() {
 		py.MustNewMethod("min", builtin_min, 0, min_doc),
 		py.MustNewMethod("next", builtin_next, 0, next_doc),
 		py.MustNewMethod("open", builtin_open, 0, open_doc),
-		// py.MustNewMethod("oct", builtin_oct, 0, oct_doc),
+		py.MustNewMethod("oct", builtin_oct, 0, oct_doc),
 		py.MustNewMethod("ord", builtin_ord, 0, ord_doc),
 		py.MustNewMethod("pow", builtin_pow, 0, pow_doc),
 		py.MustNewMethod("print", builtin_print, 0, print_doc),
@@ -71,18 +74,18 @@ func init() {
 		"True":     py.True,
 		"bool":     py.BoolType,
 		// "memoryview":     py.MemoryViewType,
-		// "bytearray":      py.ByteArrayType,
+		"bytearray":   py.ByteArrayType,
 		"bytes":       py.BytesType,
 		"classmethod": py.ClassMethodType,
 		"complex":     py.ComplexType,
-		"dict":        py.StringDictType, // FIXME
+		"dict":        py.DictType,
 		"enumerate":   py.EnumerateType,
 		// "filter":         py.FilterType,
 		"float":     py.FloatType,
 		"frozenset": py.FrozenSetType,
-		// "property":       py.PropertyType,
-		"int":  py.IntType, // FIXME LongType?
-		"list": py.ListType,
+		"property":  py.PropertyType,
+		"int":       py.IntType, // FIXME LongType?
+		"list":      py.ListType,
 		// "map":            py.MapType,
 		"object": py.ObjectType,
 		"range":  py.RangeType,
@@ -91,10 +94,10 @@ func init() {
 		"slice":        py.SliceType,
 		"staticmethod": py.StaticMethodType,
 		"str":          py.StringType,
-		// "super":          py.SuperType,
-		"tuple": py.TupleType,
-		"type":  py.TypeType,
-		"zip":   py.ZipType,
+		"super":        py.SuperType,
+		"tuple":        py.TupleType,
+		"type":         py.TypeType,
+		"zip":          py.ZipType,
 
 		// Exceptions
 		"ArithmeticError":           py.ArithmeticError,
@@ -139,10 +142,12 @@ func init() {
 		"PendingDeprecationWarning": py.PendingDeprecationWarning,
 		"PermissionError":           py.PermissionError,
 		"ProcessLookupError":        py.ProcessLookupError,
+		"RecursionError":            py.RecursionError,
 		"ReferenceError":            py.ReferenceError,
 		"ResourceWarning":           py.ResourceWarning,
 		"RuntimeError":              py.RuntimeError,
 		"RuntimeWarning":            py.RuntimeWarning,
+		"StopAsyncIteration":        py.StopAsyncIteration,
 		"StopIteration":             py.StopIteration,
 		"SyntaxError":               py.SyntaxError,
 		"SyntaxWarning":             py.SyntaxWarning,
@@ -162,7 +167,12 @@ func init() {
 		"Warning":                   py.Warning,
 		"ZeroDivisionError":         py.ZeroDivisionError,
 	}
-	py.NewModule("builtins", builtin_doc, methods, globals)
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "builtins",
+		Doc:     builtin_doc,
+		Methods: methods,
+		Globals: globals,
+	})
 }
 
 const print_doc = `print(value, ..., sep=' ', end='\\n', file=sys.stdout, flush=False)
@@ -179,7 +189,7 @@ func builtin_print(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Obje
 		sepObj py.Object = py.String(" ")
 		endObj py.Object = py.String("\n")
 		file   py.Object = py.MustGetModule("sys").Globals["stdout"]
-		flush  py.Object
+		flush  py.Object = py.False
 	)
 	kwlist := []string{"sep", "end", "file", "flush"}
 	err := py.ParseTupleAndKeywords(nil, kwargs, "|ssOO:print", kwlist, &sepObj, &endObj, &file, &flush)
@@ -228,6 +238,77 @@ func builtin_print(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Obje
 	return py.None, nil
 }
 
+const input_doc = `input([prompt]) -> string
+
+Read a string from standard input.  The trailing newline is stripped.
+If the user hits EOF (Unix: Ctl-D, Windows: Ctl-Z+Return), raise EOFError.
+The prompt string, if given, is printed to standard output without a
+trailing newline before reading.`
+
+// Calls the method name of obj with args
+func callMethod(obj py.Object, name string, args py.Tuple) (py.Object, error) {
+	method, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(method, args, nil)
+}
+
+// Returns the attribute sys.name which must be set
+func sysStream(name string) (py.Object, error) {
+	sys, err := py.GetModule("sys")
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := sys.Globals[name]
+	if !ok || stream == py.None {
+		return nil, py.ExceptionNewf(py.RuntimeError, "input(): lost sys.%s", name)
+	}
+	return stream, nil
+}
+
+func builtin_input(self py.Object, args py.Tuple) (py.Object, error) {
+	var prompt py.Object
+	err := py.UnpackTuple(args, nil, "input", 0, 1, &prompt)
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := sysStream("stdin")
+	if err != nil {
+		return nil, err
+	}
+	if prompt != nil {
+		stdout, err := sysStream("stdout")
+		if err != nil {
+			return nil, err
+		}
+		prompt, err = py.Str(prompt)
+		if err != nil {
+			return nil, err
+		}
+		_, err = callMethod(stdout, "write", py.Tuple{prompt})
+		if err != nil {
+			return nil, err
+		}
+		_, err = callMethod(stdout, "flush", nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	line, err := callMethod(stdin, "readline", nil)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := line.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "object.readline() returned non-string")
+	}
+	if s == "" {
+		return nil, py.ExceptionNewf(py.EOFError, "EOF when reading a line")
+	}
+	return py.String(strings.TrimSuffix(string(s), "\n")), nil
+}
+
 const repr_doc = `repr(object) -> string
 
 Return the canonical string representation of the object.
@@ -352,6 +433,25 @@ func builtin_bin(self, o py.Object) (py.Object, error) {
 	return py.String(out), nil
 }
 
+const breakpoint_doc = `breakpoint(*args, **kws)
+
+Call sys.breakpointhook(*args, **kws).  sys.breakpointhook() must accept
+whatever arguments are passed.
+
+By default, this drops you into the pdb debugger.`
+
+func builtin_breakpoint(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	sys, err := py.GetModule("sys")
+	if err != nil {
+		return nil, err
+	}
+	hook, ok := sys.Globals["breakpointhook"]
+	if !ok {
+		return nil, py.ExceptionNewf(py.RuntimeError, "lost sys.breakpointhook")
+	}
+	return py.Call(hook, args, kwargs)
+}
+
 const round_doc = `round(number[, ndigits]) -> number
 
 Round a number to a given precision in decimal digits (default 0 digits).
@@ -360,7 +460,7 @@ same type as the number. ndigits may be negative.`
 
 func builtin_round(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
 	var number, ndigits py.Object
-	ndigits = py.Int(0)
+	ndigits = py.None
 	// var kwlist = []string{"number", "ndigits"}
 	// FIXME py.ParseTupleAndKeywords(args, kwargs, "O|O:round", kwlist, &number, &ndigits)
 	err := py.UnpackTuple(args, nil, "round", 1, 2, &number, &ndigits)
@@ -368,12 +468,19 @@ func builtin_round(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Obje
 		return nil, err
 	}
 
-	numberRounder, ok := number.(py.I__round__)
-	if !ok {
-		return nil, py.ExceptionNewf(py.TypeError, "type %s doesn't define __round__ method", number.Type().Name)
+	if I, ok := number.(py.I__round__); ok {
+		return I.M__round__(ndigits)
+	}
+	// Python classes are called without ndigits if it wasn't supplied
+	if len(args) < 2 {
+		if res, ok, err := py.TypeCall0(number, "__round__"); ok {
+			return res, err
+		}
+	} else if res, ok, err := py.TypeCall1(number, "__round__", ndigits); ok {
+		return res, err
 	}
 
-	return numberRounder.M__round__(ndigits)
+	return nil, py.ExceptionNewf(py.TypeError, "type %s doesn't define __round__ method", number.Type().Name)
 }
 
 const build_class_doc = `__build_class__(func, name, *bases, metaclass=None, **kwds) -> class
@@ -382,11 +489,8 @@ Internal helper function used by the class statement.`
 
 func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
 	// fmt.Printf("__build_class__(self=%#v, args=%#v, kwargs=%#v\n", self, args, kwargs)
-	var prep, cell, cls py.Object
+	var meta, prep, nsObj, cell, cls py.Object
 	var mkw, ns py.StringDict
-	var meta, winner *py.Type
-	var isclass bool
-	var err error
 
 	if len(args) < 2 {
 		return nil, py.ExceptionNewf(py.TypeError, "__build_class__: not enough arguments")
@@ -398,20 +502,20 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 		return nil, py.ExceptionNewf(py.TypeError, "__build__class__: func must be a function")
 	}
 
-	name := args[1].(py.String)
+	name, ok := args[1].(py.String)
 	if !ok {
 		return nil, py.ExceptionNewf(py.TypeError, "__build_class__: name is not a string")
 	}
-	bases := args[2:]
+	origBases := args[2:].Copy()
+	bases, err := updateBases(origBases)
+	if err != nil {
+		return nil, err
+	}
 
 	if kwargs != nil {
-		mkw = kwargs.Copy()      // Don't modify kwds passed in!
-		meta := mkw["metaclass"] // _PyDict_GetItemId(mkw, &PyId_metaclass)
-		if meta != nil {
-			delete(mkw, "metaclass")
-			// metaclass is explicitly given, check if it's indeed a class
-			_, isclass = meta.(*py.Type)
-		}
+		mkw = kwargs.Copy()     // Don't modify kwds passed in!
+		meta = mkw["metaclass"] // _PyDict_GetItemId(mkw, &PyId_metaclass)
+		delete(mkw, "metaclass")
 	}
 	if meta == nil {
 		// if there are no bases, use type:
@@ -421,54 +525,97 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 			// else get the type of the first base
 			meta = bases[0].Type()
 		}
-		isclass = true // meta is really a class
 	}
 
-	if isclass {
-		// meta is really a class, so check for a more derived
-		// metaclass, or possible metaclass conflicts:
-		winner, err = meta.CalculateMetaclass(bases)
+	// If meta is really a class, check for a more derived
+	// metaclass, or possible metaclass conflicts.  Otherwise meta
+	// is some other callable which is used as it is.
+	if metaType, ok := meta.(*py.Type); ok && metaType.Type().IsSubtype(py.TypeType) {
+		meta, err = metaType.CalculateMetaclass(bases)
 		if err != nil {
 			return nil, err
 		}
-		if winner != meta {
-			meta = winner
-		}
 	}
-	// else: meta is not a class, so we cannot do the metaclass
-	// calculation, so we will use the explicitly given object as it is
-	prep = meta.Type().Dict["___prepare__"] // FIXME should be using _PyObject_GetAttr
-	if prep == nil {
-		ns = py.NewStringDict()
+
+	// Make the namespace with __prepare__ if the metaclass has one
+	prep, err = py.GetAttrString(meta, "__prepare__")
+	if err != nil {
+		if !py.IsException(py.AttributeError, err) {
+			return nil, err
+		}
+		nsObj = py.NewDict()
 	} else {
-		nsObj, err := py.Call(prep, py.Tuple{name, bases}, mkw)
+		nsObj, err = py.Call(prep, py.Tuple{name, bases}, mkw)
 		if err != nil {
 			return nil, err
 		}
-		ns = nsObj.(py.StringDict)
 	}
+	ns, err = py.DictAsNamespace(nsObj)
+	if err != nil {
+		return nil, py.ExceptionNewf(py.TypeError, "__prepare__() must return a dict of strings, not %s", nsObj.Type().Name)
+	}
+	if !py.Is(bases, origBases) {
+		ns["__orig_bases__"] = origBases
+	}
+
 	// fmt.Printf("Calling %v with %v and %v\n", fn.Name, fn.Globals, ns)
 	// fmt.Printf("Code = %#v\n", fn.Code)
 	cell, err = py.VmRun(fn.Globals, ns, fn.Code, fn.Closure)
 	if err != nil {
 		return nil, err
 	}
+	// The names of the body come in the order they were first
+	// used, which is the order they were defined in for the
+	// metaclass to see
+	if d, ok := nsObj.(*py.Dict); ok {
+		d.SetNamespaceOrder(fn.Code.Names)
+	}
 
 	// fmt.Printf("result = %#v err = %s\n", cell, err)
-	// fmt.Printf("locals = %#v\n", locals)
 	// fmt.Printf("ns = %#v\n", ns)
-	if cell != nil {
-		// fmt.Printf("Calling %v\n", meta)
-		cls, err = py.Call(meta, py.Tuple{name, bases, ns}, mkw)
-		if err != nil {
-			return nil, err
+	cls, err = py.Call(meta, py.Tuple{name, bases, nsObj}, mkw)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := cell.(*py.Cell); ok {
+		c.Set(cls)
+	}
+	return cls, nil
+}
+
+// Replaces the bases which aren't classes with the bases their
+// __mro_entries__ method returns, as described in PEP 560
+func updateBases(bases py.Tuple) (py.Tuple, error) {
+	var newBases py.Tuple
+	for i, base := range bases {
+		if t, ok := base.(*py.Type); !ok || !t.Type().IsSubtype(py.TypeType) {
+			meth, err := py.GetAttrString(base, "__mro_entries__")
+			if err == nil {
+				entries, err := py.Call(meth, py.Tuple{bases}, nil)
+				if err != nil {
+					return nil, err
+				}
+				entriesTuple, ok := entries.(py.Tuple)
+				if !ok {
+					return nil, py.ExceptionNewf(py.TypeError, "__mro_entries__ must return a tuple")
+				}
+				if newBases == nil {
+					newBases = append(py.Tuple{}, bases[:i]...)
+				}
+				newBases = append(newBases, entriesTuple...)
+				continue
+			} else if !py.IsException(py.AttributeError, err) {
+				return nil, err
+			}
 		}
-		if c, ok := cell.(*py.Cell); ok {
-			c.Set(cls)
+		if newBases != nil {
+			newBases = append(newBases, base)
 		}
 	}
-	// fmt.Printf("Globals = %v, Locals = %v\n", fn.Globals, ns)
-	return cls, nil
+	if newBases == nil {
+		return bases, nil
+	}
+	return newBases, nil
 }
 
 const next_doc = `next(iterator[, default])
@@ -509,10 +656,39 @@ fromlist is not empty.  Level is used to determine whether to perform
 absolute or relative imports. 0 is absolute while a positive number
 is the number of parent directories to search relative to the current module.`
 
-const open_doc = `open(name[, mode[, buffering]]) -> file object
+const open_doc = `open(file, mode='r', buffering=-1, encoding=None,
+     errors=None, newline=None, closefd=True, opener=None) -> file object
+
+Open file and return a stream.  Raise OSError upon failure.
+
+file is the name of the file to be opened or an integer file
+descriptor of the file to be wrapped.
+
+mode is an optional string made of 'r' (read, the default), 'w'
+(write, truncating the file first), 'x' (create a new file and open
+it for writing), 'a' (write, appending to the end of the file), 'b'
+(binary mode), 't' (text mode, the default) and '+' (update, reading
+and writing).
+
+buffering is 0 to switch buffering off (only in binary mode), 1 to
+select line buffering (only in text mode) or larger to give the size
+of the buffer.  By default files are buffered and interactive text
+files are line buffered.
+
+encoding is the name of the encoding used to decode or encode a
+text file.  utf-8, ascii and latin-1 are supported and utf-8 is the
+default.  errors is 'strict' (the default), 'replace' or 'ignore' to
+say how encoding errors are handled.
+
+newline controls universal newlines in text mode.  It can be None,
+'', '\\n', '\\r', and '\\r\\n'.  With None lines read may end with
+'\\n', '\\r' or '\\r\\n' and these are translated into '\\n', and '\\n'
+is written as is.  With '' lines end in the same way but aren't
+translated.  Otherwise lines read end with the given string and '\\n'
+is written as the given string.
 
-Open a file using the file() type, returns a file object.  This is the
-preferred way to open a file.  See file.__doc__ for further information.`
+If closefd is False the file descriptor is kept open when the file is
+closed.  It must be True when a file name is given.`
 
 func builtin_open(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
 	kwlist := []string{
@@ -537,7 +713,7 @@ func builtin_open(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Objec
 		opener    py.Object = py.None
 	)
 
-	err := py.ParseTupleAndKeywords(args, kwargs, "s|sizzzpO:open", kwlist,
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|sizzzpO:open", kwlist,
 		&filename,
 		&mode,
 		&buffering,
@@ -550,25 +726,42 @@ func builtin_open(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Objec
 		return nil, err
 	}
 
-	if encoding != py.None && encoding.(py.String) != py.String("utf-8") {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "encoding not implemented yet")
+	if opener != py.None {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "opener not implemented yet")
 	}
 
-	if errors != py.None {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "errors not implemented yet")
-	}
+	return py.Open(filename,
+		string(mode.(py.String)),
+		int(buffering.(py.Int)),
+		encoding,
+		errors,
+		newline,
+		bool(closefd.(py.Bool)))
+}
 
-	if newline != py.None {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "newline not implemented yet")
-	}
+const oct_doc = `oct(number) -> string
 
-	if opener != py.None {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "opener not implemented yet")
+Return the octal representation of an integer.
+
+   >>> oct(342391)
+   '0o1234567'
+`
+
+func builtin_oct(self, o py.Object) (py.Object, error) {
+	bigint, ok := py.ConvertToBigInt(o)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", o.Type().Name)
 	}
 
-	return py.OpenFile(string(filename.(py.String)),
-		string(mode.(py.String)),
-		int(buffering.(py.Int)))
+	value := (*big.Int)(bigint)
+	var out string
+	if value.Sign() < 0 {
+		value = new(big.Int).Abs(value)
+		out = fmt.Sprintf("-0o%o", value)
+	} else {
+		out = fmt.Sprintf("0o%o", value)
+	}
+	return py.String(out), nil
 }
 
 const ord_doc = `ord(c) -> integer
@@ -589,14 +782,11 @@ func builtin_ord(self, obj py.Object) (py.Object, error) {
 		if size == runeSize && rune != utf8.RuneError {
 			return py.Int(rune), nil
 		}
-	//case py.ByteArray:
-	// XXX Hopefully this is temporary
-	// FIXME implement
-	// size = PyByteArray_GET_SIZE(obj)
-	// if size == 1 {
-	// 	ord = (long)((char) * PyByteArray_AS_STRING(obj))
-	// 	return PyLong_FromLong(ord)
-	// }
+	case *py.ByteArray:
+		size = len(x.Data)
+		if size == 1 {
+			return py.Int(x.Data[0]), nil
+		}
 	default:
 		return nil, py.ExceptionNewf(py.TypeError, "ord() expected string of length 1, but %s found", obj.Type().Name)
 	}
@@ -604,6 +794,22 @@ func builtin_ord(self, obj py.Object) (py.Object, error) {
 	return nil, py.ExceptionNewf(py.TypeError, "ord() expected a character, but string of length %d found", size)
 }
 
+const format_doc = `format(value[, format_spec]) -> string
+
+Returns value.__format__(format_spec)
+format_spec defaults to ""`
+
+func builtin_format(self py.Object, args py.Tuple) (py.Object, error) {
+	var value py.Object
+	var formatSpec py.Object = py.String("")
+
+	err := py.UnpackTuple(args, nil, "format", 1, 2, &value, &formatSpec)
+	if err != nil {
+		return nil, err
+	}
+	return py.Format(value, formatSpec)
+}
+
 const getattr_doc = `getattr(object, name[, default]) -> value
 
 Get a named attribute from an object; getattr(x, 'y') is equivalent to x.y.
@@ -665,15 +871,22 @@ func builtin_setattr(self py.Object, args py.Tuple) (py.Object, error) {
 
 // Reads the source as a string
 func source_as_string(cmd py.Object, funcname, what string /*, PyCompilerFlags *cf */) (string, error) {
-	// FIXME only understands strings, not bytes etc at the moment
-	if str, ok := cmd.(py.String); ok {
+	var str string
+	switch x := cmd.(type) {
+	case py.String:
 		// FIXME cf->cf_flags |= PyCF_IGNORE_COOKIE;
-		return string(str), nil
+		str = string(x)
+	case py.Bytes:
+		str = string(x)
+	case *py.ByteArray:
+		str = string(x.Data)
+	default:
+		return "", py.ExceptionNewf(py.TypeError, "%s() arg 1 must be a %s object", funcname, what)
+	}
+	if strings.IndexByte(str, 0) >= 0 {
+		return "", py.ExceptionNewf(py.SyntaxError, "source code string cannot contain null bytes")
 	}
-	// } else if (!PyObject_CheckReadBuffer(cmd)) {
-	return "", py.ExceptionNewf(py.TypeError, "%s() arg 1 must be a %s object", funcname, what)
-	// } else if (PyObject_AsReadBuffer(cmd, (const void **)&str, &size) < 0) {
-	// 	return nil;
+	return str, nil
 }
 
 const delattr_doc = `Deletes the named attribute from the given object.
@@ -744,53 +957,101 @@ func builtin_compile(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Ob
 	// }
 	// XXX Warn if (supplied_flags & PyCF_MASK_OBSOLETE) != 0?
 
+	err = py.Audit("compile", cmd, filename)
+	if err != nil {
+		return nil, err
+	}
+
 	optimize := int(optimizeInt.(py.Int))
 	if optimize < -1 || optimize > 2 {
 		return nil, py.ExceptionNewf(py.ValueError, "compile(): invalid optimize value")
 	}
+	// -1 means the interpreter's optimization level
+	if optimize >= 0 {
+		oldOptimize := py.Optimize
+		py.Optimize = optimize
+		defer func() {
+			py.Optimize = oldOptimize
+		}()
+	}
 
 	if dont_inherit.(py.Int) != 0 {
 		// PyEval_MergeCompilerFlags(&cf)
 	}
 
-	// switch string(startstr.(py.String)) {
-	// case "exec":
-	// 	mode = 0
-	// case "eval":
-	// 	mode = 1
-	// case "single":
-	// 	mode = 2
-	// default:
-	// 	return nil, py.ExceptionNewf(py.ValueError, "compile() arg 3 must be 'exec', 'eval' or 'single'")
-	// }
+	switch string(startstr.(py.String)) {
+	case "exec", "eval", "single":
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "compile() mode must be 'exec', 'eval' or 'single'")
+	}
 
-	// is_ast = PyAST_Check(cmd)
-	// if is_ast {
-	// 	if supplied_flags & PyCF_ONLY_AST {
-	// 		result = cmd
-	// 	} else {
-
-	// 		arena := PyArena_New()
-	// 		mod := PyAST_obj2mod(cmd, arena, mode)
-	// 		PyAST_Validate(mod)
-	// 		result = PyAST_CompileObject(mod, filename, &cf, optimize, arena)
-	// 		PyArena_Free(arena)
-	// 	}
-	// } else {
+	onlyAst := int(supplied_flags.(py.Int))&pyast.PyCF_ONLY_AST != 0
+	if pyast.IsNode(cmd) {
+		if onlyAst {
+			return cmd, nil
+		}
+		mod, err := pyast.ObjectToMod(cmd, string(startstr.(py.String)))
+		if err != nil {
+			return nil, err
+		}
+		return compile.CompileAst(mod, string(filename.(py.String)), int(supplied_flags.(py.Int)), dont_inherit.(py.Int) != 0)
+	}
 	str, err := source_as_string(cmd, "compile", "string, bytes or AST" /*, &cf*/)
 	if err != nil {
 		return nil, err
 	}
+	if onlyAst {
+		return pyast.Parse(str, string(filename.(py.String)), string(startstr.(py.String)))
+	}
 	// result = py.CompileStringExFlags(str, filename, start[mode], &cf, optimize)
 	result, err = compile.Compile(str, string(filename.(py.String)), string(startstr.(py.String)), int(supplied_flags.(py.Int)), dont_inherit.(py.Int) != 0)
 	if err != nil {
 		return nil, err
 	}
-	// }
 
 	return result, nil
 }
 
+const dir_doc = `dir([object]) -> list of strings
+
+If called without an argument, return the names in the current scope.
+Else, return an alphabetized list of names comprising (some of) the attributes
+of the given object, and of attributes reachable from it.
+If the object supplies a method named __dir__, it will be used; otherwise
+the default dir() logic is used and returns:
+  for a module object: the module's attributes.
+  for a class object:  its attributes, and recursively the attributes
+    of its bases.
+  for any other object: its attributes, its class's attributes, and
+    recursively the attributes of its class's base classes.`
+
+func builtin_dir(self py.Object, args py.Tuple) (py.Object, error) {
+	var object py.Object
+	err := py.UnpackTuple(args, nil, "dir", 0, 1, &object)
+	if err != nil {
+		return nil, err
+	}
+	if object != nil {
+		return py.Dir(object)
+	}
+	// Names in the current scope
+	names := py.NewList()
+	if frame := py.CurrentFrame; frame != nil {
+		err = py.Iterate(frame.GetLocals(), func(name py.Object) bool {
+			names.Append(name)
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = py.SortInPlace(names, nil, "dir")
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
 const divmod_doc = `divmod(x, y) -> (quotient, remainder)
 
 Return the tuple ((x-x%y)/y, x%y).  Invariant: div*y + mod == x.`
@@ -826,6 +1087,19 @@ object.
 The globals and locals are dictionaries, defaulting to the current
 globals and locals.  If only globals is given, locals defaults to it.`
 
+const hash_doc = `hash(object) -> integer
+
+Return a hash value for the object.  Two objects with the same value have
+the same hash value.  The reverse is not necessarily true, but likely.`
+
+func builtin_hash(self, v py.Object) (py.Object, error) {
+	h, err := py.Hash(v)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(h), nil
+}
+
 const hex_doc = `hex(number) -> string
 
 Return the hexadecimal representation of an integer.
@@ -881,22 +1155,95 @@ check against. This is equivalent to isinstance(x, A) or isinstance(x, B)
 or ... etc.
 `
 
+// Calls the special method name with arg if it is defined on the
+// metaclass of class
+//
+// Returns ok false if it isn't defined
+func callCheck(class *py.Type, name string, arg py.Object) (res bool, ok bool, err error) {
+	check := class.Type().NativeGetAttrOrNil(name)
+	if check == nil {
+		return false, false, nil
+	}
+	if I, ok := check.(py.I__get__); ok {
+		check, err = I.M__get__(class, class.Type())
+		if err != nil {
+			return false, true, err
+		}
+	}
+	result, err := py.Call(check, py.Tuple{arg}, nil)
+	if err != nil {
+		return false, true, err
+	}
+	return py.ObjectIsTrue(result), true, nil
+}
+
 func isinstance(obj py.Object, classOrTuple py.Object) (py.Bool, error) {
 	switch classOrTuple.(type) {
 	case py.Tuple:
 		var class_tuple = classOrTuple.(py.Tuple)
 		for idx := range class_tuple {
-			res, _ := isinstance(obj, class_tuple[idx])
-			if res {
-				return res, nil
+			res, err := isinstance(obj, class_tuple[idx])
+			if err != nil || res {
+				return res, err
 			}
 		}
 		return false, nil
 	default:
-		if classOrTuple.Type().ObjectType != py.TypeType {
+		class, ok := classOrTuple.(*py.Type)
+		if !ok || !class.Type().IsSubtype(py.TypeType) {
 			return false, py.ExceptionNewf(py.TypeError, "isinstance() arg 2 must be a type or tuple of types")
 		}
-		return obj.Type() == classOrTuple, nil
+		if obj.Type() == class {
+			return true, nil
+		}
+		if res, ok, err := callCheck(class, "__instancecheck__", obj); ok {
+			return py.Bool(res), err
+		}
+		return py.Bool(obj.Type().IsSubtype(class)), nil
+	}
+}
+
+const issubclass_doc = `issubclass(C, B) -> bool
+
+Return whether class C is a subclass (i.e., a derived class) of class B.
+When using a tuple as the second argument issubclass(X, (A, B, ...)),
+is a shortcut for issubclass(X, A) or issubclass(X, B) or ... (etc.).
+`
+
+func builtin_issubclass(self py.Object, args py.Tuple) (py.Object, error) {
+	var derived py.Object
+	var classOrTuple py.Object
+	err := py.UnpackTuple(args, nil, "issubclass", 2, 2, &derived, &classOrTuple)
+	if err != nil {
+		return nil, err
+	}
+
+	return issubclass(derived, classOrTuple)
+}
+
+func issubclass(derived py.Object, classOrTuple py.Object) (py.Bool, error) {
+	switch classOrTuple.(type) {
+	case py.Tuple:
+		for _, class := range classOrTuple.(py.Tuple) {
+			res, err := issubclass(derived, class)
+			if err != nil || res {
+				return res, err
+			}
+		}
+		return false, nil
+	default:
+		class, ok := classOrTuple.(*py.Type)
+		if !ok || !class.Type().IsSubtype(py.TypeType) {
+			return false, py.ExceptionNewf(py.TypeError, "issubclass() arg 2 must be a class or tuple of classes")
+		}
+		if res, ok, err := callCheck(class, "__subclasscheck__", derived); ok {
+			return py.Bool(res), err
+		}
+		derivedClass, ok := derived.(*py.Type)
+		if !ok || !derivedClass.Type().IsSubtype(py.TypeType) {
+			return false, py.ExceptionNewf(py.TypeError, "issubclass() arg 1 must be a class")
+		}
+		return py.Bool(derivedClass.IsSubtype(class)), nil
 	}
 }
 
@@ -988,15 +1335,15 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 	var cmp func(a py.Object, b py.Object) (py.Object, error)
 	if name == "min" {
 		format = "|$OO:min"
-		cmp = py.Le
+		cmp = py.Lt
 	} else if name == "max" {
 		format = "|$OO:max"
-		cmp = py.Ge
+		cmp = py.Gt
 	}
 	var defaultValue py.Object
 	var keyFunc py.Object
 	var maxVal, maxItem py.Object
-	var kf *py.Function
+	var kf py.Object
 
 	if positional > 1 {
 		values = args
@@ -1014,22 +1361,10 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 		keyFunc = nil
 	}
 	if keyFunc != nil {
-		var ok bool
-		kf, ok = keyFunc.(*py.Function)
-		if !ok {
-			return nil, py.ExceptionNewf(py.TypeError, "'%s' object is not callable", keyFunc.Type())
-		}
-	}
-	if defaultValue != nil {
-		maxItem = defaultValue
-		if keyFunc != nil {
-			maxVal, err = py.Call(kf, py.Tuple{defaultValue}, nil)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			maxVal = defaultValue
+		if _, ok := keyFunc.(py.I__call__); !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "'%s' object is not callable", keyFunc.Type().Name)
 		}
+		kf = keyFunc
 	}
 	iter, err := py.Iter(values)
 	if err != nil {
@@ -1044,39 +1379,34 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 			}
 			return nil, err
 		}
-		if maxVal == nil {
-			if keyFunc != nil {
-				maxVal, err = py.Call(kf, py.Tuple{item}, nil)
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				maxVal = item
-			}
-			maxItem = item
-		} else {
-			var compareVal py.Object
-			if keyFunc != nil {
-				compareVal, err = py.Call(kf, py.Tuple{item}, nil)
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				compareVal = item
-			}
-			changed, err := cmp(compareVal, maxVal)
+		compareVal := item
+		if keyFunc != nil {
+			compareVal, err = py.Call(kf, py.Tuple{item}, nil)
 			if err != nil {
 				return nil, err
 			}
-			if changed == py.True {
-				maxVal = compareVal
-				maxItem = item
-			}
 		}
-
+		if maxVal == nil {
+			maxVal = compareVal
+			maxItem = item
+			continue
+		}
+		// Only replace on a strict comparison so the first of
+		// several equal items is returned
+		changed, err := cmp(compareVal, maxVal)
+		if err != nil {
+			return nil, err
+		}
+		if py.ObjectIsTrue(changed) {
+			maxVal = compareVal
+			maxItem = item
+		}
 	}
 
 	if maxItem == nil {
+		if defaultValue != nil {
+			return defaultValue, nil
+		}
 		return nil, py.ExceptionNewf(py.ValueError, "%s() arg is an empty sequence", name)
 	}
 
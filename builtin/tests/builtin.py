@@ -25,6 +25,7 @@ doc="ascii"
 assert ascii('hello world') == "'hello world'"
 assert ascii('안녕 세상') == "'\\uc548\\ub155 \\uc138\\uc0c1'"
 assert ascii(chr(0x10001)) == "'\\U00010001'"
+assert ascii('café') == "'caf\\xe9'"
 assert ascii('안녕 gpython') == "'\\uc548\\ub155 gpython'"
 
 doc="bin"
@@ -39,6 +40,10 @@ assert bin(2**32) == '0b100000000000000000000000000000000'
 assert bin(2**32-1) == '0b11111111111111111111111111111111'
 assert bin(-(2**32)) == '-0b100000000000000000000000000000000'
 assert bin(-(2**32-1)) == '-0b11111111111111111111111111111111'
+x = 0xdeadbeefcafebabe0123456789abcdef0011223344556677fedcba9876543210
+assert bin(x) == '0b1101111010101101101111101110111111001010111111101011101010111110000000010010001101000101011001111000100110101011110011011110111100000000000100010010001000110011010001000101010101100110011101111111111011011100101110101001100001110110010101000011001000010000'
+assert bin(-x) == '-0b1101111010101101101111101110111111001010111111101011101010111110000000010010001101000101011001111000100110101011110011011110111100000000000100010010001000110011010001000101010101100110011101111111111011011100101110101001100001110110010101000011001000010000'
+assertRaises(TypeError, bin, 1.0)
 
 doc="chr"
 assert chr(65) == "A"
@@ -48,7 +53,85 @@ assert chr(0x263A) == "☺"
 doc="compile"
 code = compile("pass", "<string>", "exec")
 assert code is not None
-# FIXME
+assert code.co_filename == "<string>"
+assert eval(compile("1+2", "f", "eval")) == 3
+assert eval(compile(b"4", "f", "eval")) == 4
+assert eval(compile(source="5", filename="f", mode="eval", dont_inherit=True)) == 5
+glob = {}
+exec(compile("if True:\n    a = 1", "f", "single"), glob)
+assert glob["a"] == 1
+exec(compile("b = 2", "f", "single"), glob)
+assert glob["b"] == 2
+assertRaises(ValueError, compile, "1", "f", "bogus")
+assertRaises(SyntaxError, compile, "1\0", "f", "eval")
+assertRaises(SyntaxError, compile, "1 +", "f", "eval")
+try:
+    compile("x = (", "<test>", "exec")
+except SyntaxError as e:
+    assert type(e.msg) is str and e.msg == e.args[0]
+    assert e.filename == "<test>"
+    assert e.lineno == 1
+    assert e.offset == 6
+    assert e.text == "x = (\n"
+    assert e.end_lineno == 1
+    assert e.end_offset == 6
+else:
+    assert False, "SyntaxError not raised"
+try:
+    compile("def f(:\n  pass", "<test>", "exec")
+except SyntaxError as e:
+    assert e.msg == "invalid syntax"
+    assert (e.filename, e.lineno, e.offset, e.text, e.end_lineno, e.end_offset) == ("<test>", 1, 7, "def f(:\n", 1, 8)
+else:
+    assert False, "SyntaxError not raised"
+try:
+    compile("x = 1\nreturn 2", "<test>", "exec")
+except SyntaxError as e:
+    assert e.msg == "'return' outside function"
+    assert (e.lineno, e.offset, e.end_lineno, e.end_offset) == (2, 1, 2, 9)
+else:
+    assert False, "SyntaxError not raised"
+e = SyntaxError("bad", ("f.py", 3, 2, "a b\n"))
+assert (e.msg, e.filename, e.lineno, e.offset, e.text, e.end_lineno, e.end_offset) == ("bad", "f.py", 3, 2, "a b\n", None, None)
+e = SyntaxError("bad")
+assert (e.msg, e.filename, e.lineno) == ("bad", None, None)
+assertRaises(TypeError, compile, 1, "f", "eval")
+assertRaises(ValueError, compile, "1", "f", "eval", optimize=3)
+assert __debug__ is True
+assert eval(compile("__debug__", "f", "eval", optimize=0)) is True
+assert eval(compile("__debug__", "f", "eval", optimize=1)) is False
+exec(compile("assert False", "f", "exec", optimize=1))
+assertRaises(AssertionError, exec, compile("assert False", "f", "exec", optimize=0))
+exec(compile("def f():\n    'doc'\n", "f", "exec", optimize=1), glob)
+assert glob["f"].__doc__ == "doc"
+exec(compile("def f():\n    'doc'\n", "f", "exec", optimize=2), glob)
+assert glob["f"].__doc__ is None
+
+doc="dir"
+def f():
+    b = 1
+    a = 2
+    return dir()
+assert f() == ["a", "b"]
+class A:
+    x = 1
+    def method(self):
+        pass
+class B(A):
+    y = 2
+names = dir(B)
+assert "x" in names and "y" in names and "method" in names, names
+b = B()
+b.z = 3
+names = dir(b)
+assert "x" in names and "y" in names and "z" in names, names
+assert names == sorted(names)
+class D:
+    def __dir__(self):
+        return ["b", "a"]
+assert dir(D()) == ["a", "b"]
+assert "__add__" in dir(1)
+assert "append" in dir([])
 
 doc="divmod"
 assert divmod(34,7) == (4, 6)
@@ -68,6 +151,39 @@ glob = {"a":100}
 assert exec("b = a+100", glob) == None
 assert glob["b"] == 200
 
+doc="format"
+assert format(42) == "42"
+assert format(42, "") == "42"
+assert format(42, ">5") == "   42"
+assert format(-42, "08,") == "-000,042"
+assert format(255, "#x") == "0xff"
+assert format(1<<64, ",") == "18,446,744,073,709,551,616"
+assert format(3.14159, ".2f") == "3.14"
+assert format(1234.5, ".2") == "1.2e+03"
+assert format(0.25, ".1%") == "25.0%"
+assert format("abc", "*^7") == "**abc**"
+assert format(True) == "True"
+assert format(True, "d") == "1"
+assert format(None) == "None"
+try:
+    format(None, "x")
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    format("abc", "d")
+except ValueError as e:
+    assert e.args[0] == "Unknown format code 'd' for object of type 'str'"
+else:
+    assert False, "ValueError not raised"
+try:
+    format(1, ".2d")
+except ValueError as e:
+    assert e.args[0] == "Precision not allowed in integer format specifier"
+else:
+    assert False, "ValueError not raised"
+
 doc="getattr"
 class C:
     def __init__(self):
@@ -125,6 +241,12 @@ try:
 except ValueError:
     ok = True
 assert ok, "ValueError not raised"
+v = min([20], default=10)
+assert v == 20
+v = min([1, 1.0])
+assert type(v) == int
+v = min([(1, "b"), (1, "a"), (2, "a")])
+assert v == (1, "a")
 
 doc="max"
 values = (1,2,3)
@@ -152,6 +274,34 @@ try:
 except ValueError:
     ok = True
 assert ok, "ValueError not raised"
+v = max([1], default=10)
+assert v == 1
+v = max([1.0, 1])
+assert type(v) == float
+
+doc="hash"
+assert hash(1) == hash(1.0) == hash(1+0j) == hash(True)
+assert hash("a") == hash("a")
+assert hash((1, "a")) == hash((1.0, "a"))
+assertRaises(TypeError, hash, [1])
+class Unhashable:
+    def __eq__(self, other):
+        return self is other
+assertRaises(TypeError, hash, Unhashable())
+class Hashable:
+    def __eq__(self, other):
+        return self is other
+    def __hash__(self):
+        return 42
+assert hash(Hashable()) == 42
+class MinusOne:
+    def __hash__(self):
+        return -1
+assert hash(MinusOne()) == -2
+class Plain:
+    pass
+p = Plain()
+assert hash(p) == hash(p)
 
 doc="hex"
 assert hex( 0)=="0x0",    "hex(0)"
@@ -166,6 +316,9 @@ assert hex( 1<<128) ==  "0x100000000000000000000000000000000", "hex(1<<128)"
 assert hex(-1<<128) == "-0x100000000000000000000000000000000", "hex(-1<<128)"
 assertRaises(TypeError, hex, 10.0) ## TypeError: 'float' object cannot be interpreted as an integer
 assertRaises(TypeError, hex, float(0)) ## TypeError: 'float' object cannot be interpreted as an integer
+x = 0xdeadbeefcafebabe0123456789abcdef0011223344556677fedcba9876543210
+assert hex(x) == '0xdeadbeefcafebabe0123456789abcdef0011223344556677fedcba9876543210'
+assert hex(-x) == '-0xdeadbeefcafebabe0123456789abcdef0011223344556677fedcba9876543210'
 
 doc="isinstance"
 class A:
@@ -175,6 +328,39 @@ assert isinstance(1, (str, tuple, int))
 assert isinstance(a, (str, (tuple, (A, ))))
 assertRaises(TypeError, isinstance, 1, (A, ), "foo")
 assertRaises(TypeError, isinstance, 1, [A, "foo"])
+assertRaises(TypeError, isinstance, 1, a)
+assertRaises(TypeError, isinstance, 1, 1)
+class B(A):
+    pass
+b = B()
+assert isinstance(b, A)
+assert isinstance(b, B)
+assert not isinstance(a, B)
+assert isinstance(b, object)
+class Meta(type):
+    def __instancecheck__(cls, instance):
+        return instance == 42
+class C(metaclass=Meta):
+    pass
+assert isinstance(42, C)
+assert not isinstance(43, C)
+
+doc="issubclass"
+assert issubclass(B, A)
+assert issubclass(A, A)
+assert not issubclass(A, B)
+assert issubclass(B, (int, A))
+assert not issubclass(B, (int, str))
+assert issubclass(A, object)
+assertRaises(TypeError, issubclass, a, A)
+assertRaises(TypeError, issubclass, A, a)
+class SubMeta(type):
+    def __subclasscheck__(cls, sub):
+        return sub is int
+class D(metaclass=SubMeta):
+    pass
+assert issubclass(int, D)
+assert not issubclass(str, D)
 
 doc="iter"
 cnt = 0
@@ -264,6 +450,20 @@ except ValueError:
     ok = True
 assert ok, "ValueError not raised"
 
+doc="oct"
+assert oct(0) == '0o0'
+assert oct(1) == '0o1'
+assert oct(-1) == '-0o1'
+assert oct(8) == '0o10'
+assert oct(-342391) == '-0o1234567'
+assert oct(True) == '0o1'
+assert oct(2**64) == '0o2000000000000000000000'
+assert oct(-2**64) == '-0o2000000000000000000000'
+x = 0xdeadbeefcafebabe0123456789abcdef0011223344556677fedcba9876543210
+assert oct(x) == '0o15725557567712775352760022150531704653633674000422106321052546357773345651416625031020'
+assert oct(-x) == '-0o15725557567712775352760022150531704653633674000422106321052546357773345651416625031020'
+assertRaises(TypeError, oct, 1.0)
+
 doc="ord"
 assert 65 == ord("A")
 assert 163 == ord("£")
@@ -336,6 +536,26 @@ with open("testfile", "r") as f:
 
 doc="round"
 assert round(1.1) == 1.0
+assert round(1.1) == 1 and type(round(1.1)) is int
+assert round(2.5) == 2
+assert round(3.5) == 4
+assert round(-2.5) == -2
+assert round(1.25, 1) == 1.2
+assert round(1250.0, -2) == 1200.0
+assert type(round(1.5, 0)) is float
+assert round(5) == 5
+assert round(12345, -2) == 12300
+class R:
+    def __round__(self, ndigits=None):
+        return ndigits
+assert round(R()) is None
+assert round(R(), 3) == 3
+try:
+    round("x")
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
 
 doc="setattr"
 class C: pass
@@ -0,0 +1,415 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Cmath module -- mathematical functions for complex numbers
+package cmath
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	EDOM   = py.ExceptionNewf(py.ValueError, "math domain error")
+	ERANGE = py.ExceptionNewf(py.OverflowError, "math range error")
+)
+
+// Returns whether both parts of z are finite
+func isFinite(z complex128) bool {
+	return !cmplx.IsInf(z) && !cmplx.IsNaN(z)
+}
+
+// Converts a python object into a complex128
+//
+// Accepts complex numbers, real numbers and objects with __complex__
+// or __float__ methods.
+func toComplex(obj py.Object) (complex128, error) {
+	if _, ok := obj.(py.I__complex__); ok {
+		c, err := py.MakeComplex(obj)
+		if err != nil {
+			return 0, err
+		}
+		if c, ok := c.(py.Complex); ok {
+			return complex128(c), nil
+		}
+	}
+	res, ok, err := py.TypeCall0(obj, "__complex__")
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		c, ok := res.(py.Complex)
+		if !ok {
+			return 0, py.ExceptionNewf(py.TypeError, "__complex__ should return a complex object")
+		}
+		return complex128(c), nil
+	}
+	res, ok, err = py.TypeCall0(obj, "__float__")
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		obj = res
+	}
+	f, err := py.FloatAsFloat64(obj)
+	if err != nil {
+		return 0, py.ExceptionNewf(py.TypeError, "must be real number, not %s", obj.Type().Name)
+	}
+	return complex(f, 0), nil
+}
+
+// Checks the result r of a function applied to z
+//
+// A non finite result from a finite input is an error, an overflow if
+// canOverflow is set and r is infinite, otherwise a domain error.
+func check(z, r complex128, canOverflow bool) (py.Object, error) {
+	if isFinite(z) && !isFinite(r) {
+		if canOverflow && !cmplx.IsNaN(r) {
+			return nil, ERANGE
+		}
+		return nil, EDOM
+	}
+	return py.Complex(r), nil
+}
+
+// Applies fn to the complex argument arg
+func cmath_1(arg py.Object, fn func(complex128) complex128, canOverflow bool) (py.Object, error) {
+	z, err := toComplex(arg)
+	if err != nil {
+		return nil, err
+	}
+	return check(z, fn(z), canOverflow)
+}
+
+func cmath_acos(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Acos, false)
+}
+
+const cmath_acos_doc = `acos(x)
+
+Return the arc cosine of x.`
+
+func cmath_acosh(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Acosh, false)
+}
+
+const cmath_acosh_doc = `acosh(x)
+
+Return the inverse hyperbolic cosine of x.`
+
+func cmath_asin(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Asin, false)
+}
+
+const cmath_asin_doc = `asin(x)
+
+Return the arc sine of x.`
+
+func cmath_asinh(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Asinh, false)
+}
+
+const cmath_asinh_doc = `asinh(x)
+
+Return the inverse hyperbolic sine of x.`
+
+func cmath_atan(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Atan, false)
+}
+
+const cmath_atan_doc = `atan(x)
+
+Return the arc tangent of x.`
+
+func cmath_atanh(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Atanh, false)
+}
+
+const cmath_atanh_doc = `atanh(x)
+
+Return the inverse hyperbolic tangent of x.`
+
+func cmath_cos(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Cos, true)
+}
+
+const cmath_cos_doc = `cos(x)
+
+Return the cosine of x.`
+
+func cmath_cosh(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Cosh, true)
+}
+
+const cmath_cosh_doc = `cosh(x)
+
+Return the hyperbolic cosine of x.`
+
+func cmath_exp(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Exp, true)
+}
+
+const cmath_exp_doc = `exp(x)
+
+Return the exponential value e**x.`
+
+func cmath_log(self py.Object, args py.Tuple) (py.Object, error) {
+	var arg, base py.Object
+	err := py.UnpackTuple(args, nil, "log", 1, 2, &arg, &base)
+	if err != nil {
+		return nil, err
+	}
+	z, err := toComplex(arg)
+	if err != nil {
+		return nil, err
+	}
+	r, err := check(z, cmplx.Log(z), false)
+	if err != nil || base == nil {
+		return r, err
+	}
+	b, err := toComplex(base)
+	if err != nil {
+		return nil, err
+	}
+	d, err := check(b, cmplx.Log(b), false)
+	if err != nil {
+		return nil, err
+	}
+	return py.TrueDiv(r, d)
+}
+
+const cmath_log_doc = `log(x[, base]) -> the logarithm of x to the given base.
+
+If the base not specified, returns the natural logarithm (base e) of x.`
+
+func cmath_log10(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Log10, false)
+}
+
+const cmath_log10_doc = `log10(x)
+
+Return the base-10 logarithm of x.`
+
+func cmath_sin(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Sin, true)
+}
+
+const cmath_sin_doc = `sin(x)
+
+Return the sine of x.`
+
+func cmath_sinh(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Sinh, true)
+}
+
+const cmath_sinh_doc = `sinh(x)
+
+Return the hyperbolic sine of x.`
+
+func cmath_sqrt(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Sqrt, false)
+}
+
+const cmath_sqrt_doc = `sqrt(x)
+
+Return the square root of x.`
+
+func cmath_tan(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Tan, true)
+}
+
+const cmath_tan_doc = `tan(x)
+
+Return the tangent of x.`
+
+func cmath_tanh(self py.Object, arg py.Object) (py.Object, error) {
+	return cmath_1(arg, cmplx.Tanh, true)
+}
+
+const cmath_tanh_doc = `tanh(x)
+
+Return the hyperbolic tangent of x.`
+
+func cmath_phase(self py.Object, arg py.Object) (py.Object, error) {
+	z, err := toComplex(arg)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(cmplx.Phase(z)), nil
+}
+
+const cmath_phase_doc = `phase(z) -> float
+
+Return argument, also known as the phase angle, of a complex.`
+
+func cmath_polar(self py.Object, arg py.Object) (py.Object, error) {
+	z, err := toComplex(arg)
+	if err != nil {
+		return nil, err
+	}
+	r, phi := cmplx.Polar(z)
+	if isFinite(z) && math.IsInf(r, 0) {
+		return nil, ERANGE
+	}
+	return py.Tuple{py.Float(r), py.Float(phi)}, nil
+}
+
+const cmath_polar_doc = `polar(z) -> r: float, phi: float
+
+Convert a complex from rectangular coordinates to polar coordinates.
+
+r is the distance from 0 and phi the phase angle.`
+
+func cmath_rect(self py.Object, args py.Tuple) (py.Object, error) {
+	var rObj, phiObj py.Object
+	err := py.UnpackTuple(args, nil, "rect", 2, 2, &rObj, &phiObj)
+	if err != nil {
+		return nil, err
+	}
+	r, err := py.FloatAsFloat64(rObj)
+	if err != nil {
+		return nil, err
+	}
+	phi, err := py.FloatAsFloat64(phiObj)
+	if err != nil {
+		return nil, err
+	}
+	if phi == 0 {
+		// Avoid the nan from inf * sin(0) when r is infinite
+		return py.Complex(complex(r, phi)), nil
+	}
+	return check(complex(r, phi), cmplx.Rect(r, phi), true)
+}
+
+const cmath_rect_doc = `rect(r, phi) -> z: complex
+
+Convert from polar coordinates to rectangular coordinates.`
+
+func cmath_isfinite(self py.Object, arg py.Object) (py.Object, error) {
+	z, err := toComplex(arg)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(isFinite(z)), nil
+}
+
+const cmath_isfinite_doc = `isfinite(z) -> bool
+
+Return True if both the real and imaginary parts of z are finite, else False.`
+
+func cmath_isinf(self py.Object, arg py.Object) (py.Object, error) {
+	z, err := toComplex(arg)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(cmplx.IsInf(z)), nil
+}
+
+const cmath_isinf_doc = `isinf(z) -> bool
+
+Checks if the real or imaginary part of z is infinite.`
+
+func cmath_isnan(self py.Object, arg py.Object) (py.Object, error) {
+	z, err := toComplex(arg)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(math.IsNaN(real(z)) || math.IsNaN(imag(z))), nil
+}
+
+const cmath_isnan_doc = `isnan(z) -> bool
+
+Checks if the real or imaginary part of z is not a number (NaN).`
+
+func cmath_isclose(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var aObj, bObj py.Object
+	var relTolObj py.Object = py.Float(1e-09)
+	var absTolObj py.Object = py.Float(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|dd:isclose", []string{"a", "b", "rel_tol", "abs_tol"}, &aObj, &bObj, &relTolObj, &absTolObj)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toComplex(aObj)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toComplex(bObj)
+	if err != nil {
+		return nil, err
+	}
+	relTol, absTol := float64(relTolObj.(py.Float)), float64(absTolObj.(py.Float))
+	if relTol < 0 || absTol < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "tolerances must be non-negative")
+	}
+	if a == b {
+		return py.True, nil
+	}
+	if cmplx.IsInf(a) || cmplx.IsInf(b) {
+		return py.False, nil
+	}
+	diff := cmplx.Abs(b - a)
+	return py.NewBool(diff <= relTol*cmplx.Abs(b) || diff <= relTol*cmplx.Abs(a) || diff <= absTol), nil
+}
+
+const cmath_isclose_doc = `isclose(a, b, rel_tol=1e-09, abs_tol=0.0) -> bool
+
+Determine whether two complex numbers are close in value.
+
+   rel_tol
+       maximum difference for being considered "close", relative to the
+       magnitude of the input values
+   abs_tol
+       maximum difference for being considered "close", regardless of the
+       magnitude of the input values
+
+Return True if a is close in value to b, and False otherwise.`
+
+const cmath_doc = `This module is always available. It provides access to mathematical
+functions for complex numbers.`
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("acos", cmath_acos, 0, cmath_acos_doc),
+		py.MustNewMethod("acosh", cmath_acosh, 0, cmath_acosh_doc),
+		py.MustNewMethod("asin", cmath_asin, 0, cmath_asin_doc),
+		py.MustNewMethod("asinh", cmath_asinh, 0, cmath_asinh_doc),
+		py.MustNewMethod("atan", cmath_atan, 0, cmath_atan_doc),
+		py.MustNewMethod("atanh", cmath_atanh, 0, cmath_atanh_doc),
+		py.MustNewMethod("cos", cmath_cos, 0, cmath_cos_doc),
+		py.MustNewMethod("cosh", cmath_cosh, 0, cmath_cosh_doc),
+		py.MustNewMethod("exp", cmath_exp, 0, cmath_exp_doc),
+		py.MustNewMethod("isclose", cmath_isclose, 0, cmath_isclose_doc),
+		py.MustNewMethod("isfinite", cmath_isfinite, 0, cmath_isfinite_doc),
+		py.MustNewMethod("isinf", cmath_isinf, 0, cmath_isinf_doc),
+		py.MustNewMethod("isnan", cmath_isnan, 0, cmath_isnan_doc),
+		py.MustNewMethod("log", cmath_log, 0, cmath_log_doc),
+		py.MustNewMethod("log10", cmath_log10, 0, cmath_log10_doc),
+		py.MustNewMethod("phase", cmath_phase, 0, cmath_phase_doc),
+		py.MustNewMethod("polar", cmath_polar, 0, cmath_polar_doc),
+		py.MustNewMethod("rect", cmath_rect, 0, cmath_rect_doc),
+		py.MustNewMethod("sin", cmath_sin, 0, cmath_sin_doc),
+		py.MustNewMethod("sinh", cmath_sinh, 0, cmath_sinh_doc),
+		py.MustNewMethod("sqrt", cmath_sqrt, 0, cmath_sqrt_doc),
+		py.MustNewMethod("tan", cmath_tan, 0, cmath_tan_doc),
+		py.MustNewMethod("tanh", cmath_tanh, 0, cmath_tanh_doc),
+	}
+	globals := py.StringDict{
+		"pi":   py.Float(math.Pi),
+		"e":    py.Float(math.E),
+		"tau":  py.Float(2 * math.Pi),
+		"inf":  py.Float(math.Inf(1)),
+		"infj": py.Complex(complex(0, math.Inf(1))),
+		"nan":  py.Float(math.NaN()),
+		"nanj": py.Complex(complex(0, math.NaN())),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "cmath",
+		Doc:     cmath_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
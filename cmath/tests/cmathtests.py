@@ -0,0 +1,98 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import cmath
+from libtest import assertRaises
+
+def close(a, b):
+    return cmath.isclose(a, b, rel_tol=1e-12, abs_tol=1e-15)
+
+doc="constants"
+assert cmath.pi == 3.141592653589793
+assert cmath.e == 2.718281828459045
+assert cmath.tau == 2*cmath.pi
+assert cmath.inf == float("inf")
+assert cmath.isnan(cmath.nan)
+assert cmath.infj == complex(0, float("inf"))
+assert cmath.isnan(cmath.nanj)
+
+doc="sqrt"
+assert cmath.sqrt(-1) == 1j
+assert cmath.sqrt(4) == 2+0j
+assert cmath.sqrt(-4+0j) == 2j
+assert close(cmath.sqrt(1j), 0.7071067811865476+0.7071067811865476j)
+assert type(cmath.sqrt(4)) == complex
+
+doc="exp log"
+assert cmath.exp(0) == 1
+assert close(cmath.exp(1j*cmath.pi), -1)
+assert close(cmath.log(cmath.e), 1)
+assert close(cmath.log(-1), cmath.pi*1j)
+assert close(cmath.log(8, 2), 3)
+assert close(cmath.log(1j, 1j), 1)
+assert close(cmath.log10(1000), 3)
+assert close(cmath.log10(-10), 1+1.3643763538418414j)
+assertRaises(ValueError, cmath.log, 0)
+assertRaises(ValueError, cmath.log10, 0j)
+assertRaises(OverflowError, cmath.exp, 1000)
+
+doc="phase polar rect"
+assert cmath.phase(-1) == cmath.pi
+assert cmath.phase(complex(-1.0, -0.0)) == -cmath.pi
+assert cmath.phase(1j) == cmath.pi/2
+assert cmath.phase(2) == 0.0
+assert cmath.polar(1j) == (1.0, cmath.pi/2)
+assert cmath.polar(-2) == (2.0, cmath.pi)
+assert cmath.rect(2, 0) == 2+0j
+assert close(cmath.rect(1, cmath.pi/2), 1j)
+assert close(cmath.rect(*cmath.polar(3+4j)), 3+4j)
+
+doc="trig"
+assert close(cmath.sin(1j), 1.1752011936438014j)
+assert close(cmath.cos(1j), 1.5430806348152437)
+assert close(cmath.tan(1+1j), 0.2717525853195118+1.0839233273386946j)
+assert close(cmath.asin(2), 1.5707963267948966+1.3169578969248166j)
+assert close(cmath.acos(2), -1.3169578969248166j) or close(cmath.acos(2), 1.3169578969248166j)
+assert close(cmath.atan(1j/2), 0.5493061443340549j)
+for z in [0.5+0.25j, -0.3+1.5j, 2-3j]:
+    assert close(cmath.sin(cmath.asin(z)), z)
+    assert close(cmath.cos(cmath.acos(z)), z)
+    assert close(cmath.tan(cmath.atan(z)), z)
+    assert close(cmath.sinh(cmath.asinh(z)), z)
+    assert close(cmath.cosh(cmath.acosh(z)), z)
+    assert close(cmath.tanh(cmath.atanh(z)), z)
+assertRaises(ValueError, cmath.atanh, 1)
+assertRaises(OverflowError, cmath.cosh, 1000)
+
+doc="classification"
+assert cmath.isfinite(1+2j)
+assert not cmath.isfinite(complex(float("inf"), 0))
+assert cmath.isinf(complex(1, float("-inf")))
+assert not cmath.isinf(1+2j)
+assert cmath.isnan(complex(float("nan"), 1))
+assert not cmath.isnan(1j)
+
+doc="isclose"
+assert cmath.isclose(1+1j, 1+1j)
+assert cmath.isclose(1+1j, 1+1.0000000001j)
+assert not cmath.isclose(1+1j, 1+1.001j)
+assert cmath.isclose(1j, 1.001j, rel_tol=0.01)
+assert cmath.isclose(0j, 1e-10j, abs_tol=1e-9)
+assert not cmath.isclose(cmath.infj, 1j)
+assertRaises(ValueError, cmath.isclose, 1, 1, rel_tol=-1)
+
+doc="arguments"
+class C:
+    def __complex__(self):
+        return 4j
+assert cmath.sqrt(C()) == cmath.sqrt(4j)
+class F:
+    def __float__(self):
+        return 4.0
+assert cmath.sqrt(F()) == 2
+assert cmath.sqrt(4.0) == 2
+assert cmath.sqrt(2**100) == 2**50
+assertRaises(TypeError, cmath.sqrt, "4")
+
+doc="finished"
@@ -0,0 +1,231 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Codecs module
+//
+// The codecs themselves are registered in the py package with
+// py.RegisterCodec so str.encode() and bytes.decode() can use them.
+
+package codecs
+
+import (
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+const codecs_doc = `codecs -- Python Codec Registry, API and helpers.
+
+Only the built in codecs utf-8, ascii, latin-1, utf-16 and utf-32 with
+the strict, ignore and replace error handlers are available.`
+
+var CodecInfoType = py.NewTypeX("CodecInfo", codec_info_doc, nil, nil)
+
+const codec_info_doc = `Codec details when looking up the codec registry
+
+name is the name of the codec.  encode(input, errors='strict') and
+decode(input, errors='strict') return the output and the length of
+the input consumed.`
+
+// CodecInfo describes a codec
+type CodecInfo struct {
+	codec *py.Codec
+}
+
+// Type of this object
+func (c *CodecInfo) Type() *py.Type {
+	return CodecInfoType
+}
+
+func (c *CodecInfo) M__repr__() (py.Object, error) {
+	return py.String("<codecs.CodecInfo object for encoding " + c.codec.Name + ">"), nil
+}
+
+// Returns the codec for the encoding argument
+func codecArg(encoding py.Object) (*py.Codec, error) {
+	name, ok := encoding.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "encoding must be str, not %s", encoding.Type().Name)
+	}
+	return py.LookupCodec(string(name))
+}
+
+// Returns the error handler for the errors argument
+func errorsArg(errors py.Object) (string, error) {
+	name, ok := errors.(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "errors must be str, not %s", errors.Type().Name)
+	}
+	switch name {
+	case "strict", "replace", "ignore":
+		return string(name), nil
+	}
+	return "", py.ExceptionNewf(py.LookupError, "unknown error handler name '%s'", name)
+}
+
+// Encodes the str obj with the codec
+func encode(codec *py.Codec, obj, errors py.Object) (py.Bytes, error) {
+	s, ok := obj.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "encode() argument 1 must be str, not %s", obj.Type().Name)
+	}
+	e, err := errorsArg(errors)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(string(s), e)
+}
+
+// Decodes the bytes like obj with the codec
+func decode(codec *py.Codec, obj, errors py.Object) (py.String, int, error) {
+	var b []byte
+	switch x := obj.(type) {
+	case py.Bytes:
+		b = x
+	case *py.ByteArray:
+		b = x.Data
+	default:
+		return "", 0, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", obj.Type().Name)
+	}
+	e, err := errorsArg(errors)
+	if err != nil {
+		return "", 0, err
+	}
+	s, err := codec.Decode(b, e)
+	if err != nil {
+		return "", 0, err
+	}
+	return py.String(s), len(b), nil
+}
+
+func init() {
+	CodecInfoType.Dict["encode"] = py.MustNewMethod("encode", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var input, errors py.Object = nil, py.String("strict")
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:encode", []string{"input", "errors"}, &input, &errors)
+		if err != nil {
+			return nil, err
+		}
+		b, err := encode(self.(*CodecInfo).codec, input, errors)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{b, py.Int(utf8.RuneCountInString(string(input.(py.String))))}, nil
+	}, 0, `encode(input, errors='strict') -> (bytes, length consumed)`)
+	CodecInfoType.Dict["decode"] = py.MustNewMethod("decode", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var input, errors py.Object = nil, py.String("strict")
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:decode", []string{"input", "errors"}, &input, &errors)
+		if err != nil {
+			return nil, err
+		}
+		s, n, err := decode(self.(*CodecInfo).codec, input, errors)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{s, py.Int(n)}, nil
+	}, 0, `decode(input, errors='strict') -> (str, length consumed)`)
+	CodecInfoType.Dict["name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*CodecInfo).codec.Name), nil
+		},
+	}
+}
+
+const encode_doc = `encode(obj, encoding='utf-8', errors='strict') -> bytes
+
+Encodes obj using the codec registered for encoding.
+
+The default encoding is 'utf-8'.  errors may be given to set a
+different error handling scheme.  Default is 'strict' meaning that
+encoding errors raise a ValueError.  Other possible values are
+'ignore' and 'replace'.`
+
+func codecs_encode(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj, encoding, errors py.Object = nil, py.String("utf-8"), py.String("strict")
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:encode", []string{"obj", "encoding", "errors"}, &obj, &encoding, &errors)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := codecArg(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return encode(codec, obj, errors)
+}
+
+const decode_doc = `decode(obj, encoding='utf-8', errors='strict') -> str
+
+Decodes obj using the codec registered for encoding.
+
+Default encoding is 'utf-8'.  errors may be given to set a
+different error handling scheme.  Default is 'strict' meaning that
+encoding errors raise a ValueError.  Other possible values are
+'ignore' and 'replace'.`
+
+func codecs_decode(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj, encoding, errors py.Object = nil, py.String("utf-8"), py.String("strict")
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:decode", []string{"obj", "encoding", "errors"}, &obj, &encoding, &errors)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := codecArg(encoding)
+	if err != nil {
+		return nil, err
+	}
+	s, _, err := decode(codec, obj, errors)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+const lookup_doc = `lookup(encoding) -> CodecInfo
+
+Looks up a codec tuple in the Python codec registry and returns
+a CodecInfo object.`
+
+func codecs_lookup(self, encoding py.Object) (py.Object, error) {
+	codec, err := codecArg(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &CodecInfo{codec: codec}, nil
+}
+
+// Makes getencoder or getdecoder which return the method called name
+// of the CodecInfo for the encoding
+func getter(name, doc string) *py.Method {
+	return py.MustNewMethod("get"+name+"r", func(self, encoding py.Object) (py.Object, error) {
+		info, err := codecs_lookup(nil, encoding)
+		if err != nil {
+			return nil, err
+		}
+		return py.GetAttrString(info, name)
+	}, 0, doc)
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "codecs",
+		Doc:  codecs_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("encode", codecs_encode, 0, encode_doc),
+			py.MustNewMethod("decode", codecs_decode, 0, decode_doc),
+			py.MustNewMethod("lookup", codecs_lookup, 0, lookup_doc),
+			getter("encode", "getencoder(encoding) -> encoder\n\nLook up the codec for the given encoding and return its encoder function."),
+			getter("decode", "getdecoder(encoding) -> decoder\n\nLook up the codec for the given encoding and return its decoder function."),
+		},
+		Globals: py.StringDict{
+			"CodecInfo":    CodecInfoType,
+			"BOM_UTF8":     py.Bytes("\xef\xbb\xbf"),
+			"BOM_UTF16_LE": py.Bytes("\xff\xfe"),
+			"BOM_UTF16_BE": py.Bytes("\xfe\xff"),
+			"BOM_UTF16":    py.Bytes("\xff\xfe"),
+			"BOM_LE":       py.Bytes("\xff\xfe"),
+			"BOM_BE":       py.Bytes("\xfe\xff"),
+			"BOM_UTF32_LE": py.Bytes("\xff\xfe\x00\x00"),
+			"BOM_UTF32_BE": py.Bytes("\x00\x00\xfe\xff"),
+			"BOM_UTF32":    py.Bytes("\xff\xfe\x00\x00"),
+		},
+	})
+}
@@ -0,0 +1,40 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import codecs
+from libtest import *
+
+doc = "encode and decode"
+assert codecs.encode("héllo") == b"h\xc3\xa9llo"
+assert codecs.encode("héllo", "latin-1") == b"h\xe9llo"
+assert codecs.encode("héllo", "ascii", "replace") == b"h?llo"
+assert codecs.encode("hi", encoding="utf-16-be") == b"\x00h\x00i"
+assert codecs.decode(b"h\xc3\xa9llo") == "héllo"
+assert codecs.decode(b"h\xe9llo", "ascii", "ignore") == "hllo"
+assert codecs.decode(bytearray(b"\xff\xfeh\x00"), "utf-16") == "h"
+assertRaises(UnicodeEncodeError, codecs.encode, "héllo", "ascii")
+assertRaises(UnicodeDecodeError, codecs.decode, b"\xff", "utf-8")
+assertRaises(LookupError, codecs.encode, "hi", "no-such-codec")
+assertRaises(LookupError, codecs.encode, "hé", "ascii", "bogus")
+assertRaises(TypeError, codecs.encode, b"hi")
+assertRaises(TypeError, codecs.decode, "hi")
+
+doc = "lookup"
+info = codecs.lookup("UTF8")
+assert info.name == "utf-8"
+assert info.encode("héllo") == (b"h\xc3\xa9llo", 5)
+assert info.decode(b"h\xc3\xa9llo") == ("héllo", 6)
+assert codecs.lookup("UTF_16LE").name == "utf-16-le"
+assert codecs.lookup("utf-16").encode("h") == (b"\xff\xfeh\x00", 1)
+assertRaises(LookupError, codecs.lookup, "nope")
+assert codecs.getencoder("ascii")("hé", "replace") == (b"h?", 2)
+assert codecs.getdecoder("utf-32-le")(b"h\x00\x00\x00") == ("h", 4)
+
+doc = "BOM"
+assert codecs.BOM_UTF8 == b"\xef\xbb\xbf"
+assert codecs.BOM_UTF16_LE == b"\xff\xfe"
+assert codecs.BOM_UTF16_BE == b"\xfe\xff"
+assert codecs.BOM_UTF32_BE == b"\x00\x00\xfe\xff"
+
+doc = "finished"
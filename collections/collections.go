@@ -0,0 +1,43 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Collections module
+
+package collections
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const collections_doc = `This module implements specialized container datatypes providing
+alternatives to Python's general purpose built-in containers, dict,
+list, set, and tuple.
+
+* namedtuple   factory function for creating tuple subclasses with named fields
+* deque        list-like container with fast appends and pops on either end
+* Counter      dict subclass for counting hashable objects
+* OrderedDict  dict subclass that remembers the order entries were added
+* defaultdict  dict subclass that calls a factory function to supply missing values`
+
+// Initialise the module
+func init() {
+	for _, t := range []*py.Type{DequeType, OrderedDictType, DefaultDictType, CounterType} {
+		t.Dict["__module__"] = py.String("collections")
+	}
+	methods := []*py.Method{
+		py.MustNewMethod("namedtuple", collections_namedtuple, 0, namedtuple_doc),
+	}
+	globals := py.StringDict{
+		"Counter":     CounterType,
+		"OrderedDict": OrderedDictType,
+		"defaultdict": DefaultDictType,
+		"deque":       DequeType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "collections",
+		Doc:     collections_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,668 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Deque
+//
+// The items are kept in a ring buffer so adding and removing at
+// either end is O(1).
+
+package collections
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+const deque_doc = `deque([iterable[, maxlen]]) --> deque object
+
+A list-like sequence optimized for data accesses near its endpoints.`
+
+// DequeType is the type of deque objects
+var DequeType = py.ObjectType.NewTypeFlags("deque", deque_doc, DequeNew, DequeInit, py.ObjectType.Flags|py.TPFLAGS_SUBCLASS_NEW)
+
+var DequeIteratorType = py.NewType("_deque_iterator", "")
+
+// Deque is a double ended queue
+type Deque struct {
+	Base   *py.Type
+	Dict   py.StringDict
+	buf    []py.Object // ring buffer
+	head   int         // index in buf of the first item
+	n      int         // number of items
+	maxlen int         // maximum number of items or -1 for no limit
+	state  int         // changed by every mutation to detect it during iteration
+}
+
+// Type of this object
+func (d *Deque) Type() *py.Type {
+	return d.Base
+}
+
+// GetDict returns the attributes of the deque
+func (d *Deque) GetDict() py.StringDict {
+	return d.Dict
+}
+
+// NewDeque makes an empty deque with at most maxlen items, or any
+// number if maxlen is negative
+func NewDeque(maxlen int) *Deque {
+	return newDeque(DequeType, maxlen)
+}
+
+// Makes an empty deque of type t
+func newDeque(t *py.Type, maxlen int) *Deque {
+	return &Deque{Base: t, Dict: py.NewStringDict(), maxlen: maxlen}
+}
+
+// DequeNew makes an empty deque
+func DequeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newDeque(metatype, -1), nil
+}
+
+// DequeInit sets the maxlen of the deque and fills it from iterable
+func DequeInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	d := self.(*Deque)
+	var iterable py.Object = py.None
+	var maxlen py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:deque", []string{"iterable", "maxlen"}, &iterable, &maxlen)
+	if err != nil {
+		return err
+	}
+	d.maxlen = -1
+	if maxlen != py.None {
+		d.maxlen, err = py.IndexInt(maxlen)
+		if err != nil {
+			return err
+		}
+		if d.maxlen < 0 {
+			return py.ExceptionNewf(py.ValueError, "maxlen must be non-negative")
+		}
+	}
+	d.Clear()
+	if iterable != py.None {
+		return d.Extend(iterable)
+	}
+	return nil
+}
+
+// Returns the index in buf of item i
+func (d *Deque) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+// Returns item i
+func (d *Deque) get(i int) py.Object {
+	return d.buf[d.index(i)]
+}
+
+// Makes room for at least one more item
+func (d *Deque) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	size := 2 * len(d.buf)
+	if size == 0 {
+		size = 8
+	}
+	buf := make([]py.Object, size)
+	for i := 0; i < d.n; i++ {
+		buf[i] = d.get(i)
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+// Items returns the items in order
+func (d *Deque) Items() []py.Object {
+	items := make([]py.Object, d.n)
+	for i := range items {
+		items[i] = d.get(i)
+	}
+	return items
+}
+
+// Replaces the items with items
+func (d *Deque) setItems(items []py.Object) {
+	d.state++
+	d.buf = append([]py.Object(nil), items...)
+	d.head = 0
+	d.n = len(items)
+}
+
+// Len returns the number of items
+func (d *Deque) Len() int {
+	return d.n
+}
+
+// Append adds item at the right removing an item from the left if
+// the deque is full
+func (d *Deque) Append(item py.Object) {
+	d.state++
+	if d.maxlen == 0 {
+		return
+	}
+	if d.n == d.maxlen {
+		d.PopLeft()
+	}
+	d.grow()
+	d.buf[d.index(d.n)] = item
+	d.n++
+}
+
+// AppendLeft adds item at the left removing an item from the right if
+// the deque is full
+func (d *Deque) AppendLeft(item py.Object) {
+	d.state++
+	if d.maxlen == 0 {
+		return
+	}
+	if d.n == d.maxlen {
+		d.Pop()
+	}
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = item
+	d.n++
+}
+
+// Pop removes and returns the rightmost item or nil if empty
+func (d *Deque) Pop() py.Object {
+	if d.n == 0 {
+		return nil
+	}
+	d.state++
+	i := d.index(d.n - 1)
+	item := d.buf[i]
+	d.buf[i] = nil
+	d.n--
+	return item
+}
+
+// PopLeft removes and returns the leftmost item or nil if empty
+func (d *Deque) PopLeft() py.Object {
+	if d.n == 0 {
+		return nil
+	}
+	d.state++
+	item := d.buf[d.head]
+	d.buf[d.head] = nil
+	d.head = (d.head + 1) % len(d.buf)
+	d.n--
+	return item
+}
+
+// Clear removes all the items
+func (d *Deque) Clear() {
+	d.state++
+	d.buf = nil
+	d.head = 0
+	d.n = 0
+}
+
+// Extend appends the items of iterable
+func (d *Deque) Extend(iterable py.Object) error {
+	// Read the items first in case iterable is d
+	items, err := py.SequenceTuple(iterable)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		d.Append(item)
+	}
+	return nil
+}
+
+// ExtendLeft appends the items of iterable to the left in turn so
+// they end up reversed
+func (d *Deque) ExtendLeft(iterable py.Object) error {
+	items, err := py.SequenceTuple(iterable)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		d.AppendLeft(item)
+	}
+	return nil
+}
+
+// Rotate moves n items from the right to the left, or from the left
+// to the right if n is negative
+func (d *Deque) Rotate(n int) {
+	if d.n <= 1 {
+		return
+	}
+	n %= d.n
+	if n < 0 {
+		n += d.n
+	}
+	if n == 0 {
+		return
+	}
+	items := d.Items()
+	d.setItems(append(items[d.n-n:], items[:d.n-n]...))
+}
+
+// Returns the index of item i which may be negative to count from the
+// right
+func (d *Deque) itemIndex(key py.Object) (int, error) {
+	i, err := py.IndexInt(key)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		i += d.n
+	}
+	if i < 0 || i >= d.n {
+		return 0, py.ExceptionNewf(py.IndexError, "deque index out of range")
+	}
+	return i, nil
+}
+
+// Returns the index of the first item equal to value between start
+// and stop or -1 if there isn't one
+func (d *Deque) find(value py.Object, start, stop int) (int, error) {
+	state := d.state
+	for i := start; i < stop && i < d.n; i++ {
+		eq, err := py.Eq(d.get(i), value)
+		if err != nil {
+			return 0, err
+		}
+		if d.state != state {
+			return 0, py.ExceptionNewf(py.RuntimeError, "deque mutated during iteration")
+		}
+		if py.ObjectIsTrue(eq) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func (d *Deque) M__len__() (py.Object, error) {
+	return py.Int(d.n), nil
+}
+
+func (d *Deque) M__hash__() (py.Object, error) {
+	return nil, py.ExceptionNewf(py.TypeError, "unhashable type: '%s'", d.Base.Name)
+}
+
+func (d *Deque) M__bool__() (py.Object, error) {
+	return py.NewBool(d.n > 0), nil
+}
+
+func (d *Deque) M__getitem__(key py.Object) (py.Object, error) {
+	i, err := d.itemIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	return d.get(i), nil
+}
+
+func (d *Deque) M__setitem__(key, value py.Object) (py.Object, error) {
+	i, err := d.itemIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	d.buf[d.index(i)] = value
+	return py.None, nil
+}
+
+func (d *Deque) M__delitem__(key py.Object) (py.Object, error) {
+	i, err := d.itemIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	items := d.Items()
+	d.setItems(append(items[:i], items[i+1:]...))
+	return py.None, nil
+}
+
+func (d *Deque) M__contains__(value py.Object) (py.Object, error) {
+	i, err := d.find(value, 0, d.n)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(i >= 0), nil
+}
+
+func (d *Deque) M__iter__() (py.Object, error) {
+	return &DequeIterator{deque: d, state: d.state, step: 1}, nil
+}
+
+func (d *Deque) M__reversed__() (py.Object, error) {
+	return &DequeIterator{deque: d, state: d.state, i: d.n - 1, step: -1}, nil
+}
+
+func (d *Deque) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString(d.Base.Name + "([")
+	for i, item := range d.Items() {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		if item == py.Object(d) {
+			out.WriteString("[...]")
+			continue
+		}
+		repr, err := py.ReprAsString(item)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(repr)
+	}
+	out.WriteString("]")
+	if d.maxlen >= 0 {
+		fmt.Fprintf(&out, ", maxlen=%d", d.maxlen)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+// Copy returns a shallow copy of the deque
+func (d *Deque) Copy() *Deque {
+	c := newDeque(d.Base, d.maxlen)
+	c.setItems(d.Items())
+	return c
+}
+
+func (d *Deque) M__copy__() (py.Object, error) {
+	return d.Copy(), nil
+}
+
+func (d *Deque) M__add__(other py.Object) (py.Object, error) {
+	o, ok := other.(*Deque)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	c := d.Copy()
+	for _, item := range o.Items() {
+		c.Append(item)
+	}
+	return c, nil
+}
+
+func (d *Deque) M__iadd__(other py.Object) (py.Object, error) {
+	err := d.Extend(other)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Repeats the items of d n times in place
+func (d *Deque) repeat(n py.Object) error {
+	times, err := py.IndexInt(n)
+	if err != nil {
+		return err
+	}
+	items := d.Items()
+	d.Clear()
+	for i := 0; i < times; i++ {
+		for _, item := range items {
+			d.Append(item)
+		}
+	}
+	return nil
+}
+
+func (d *Deque) M__mul__(other py.Object) (py.Object, error) {
+	if _, ok := other.(py.I__index__); !ok {
+		return py.NotImplemented, nil
+	}
+	c := d.Copy()
+	return c, c.repeat(other)
+}
+
+func (d *Deque) M__rmul__(other py.Object) (py.Object, error) {
+	return d.M__mul__(other)
+}
+
+func (d *Deque) M__imul__(other py.Object) (py.Object, error) {
+	if _, ok := other.(py.I__index__); !ok {
+		return py.NotImplemented, nil
+	}
+	return d, d.repeat(other)
+}
+
+// Compares the items of deques in turn with op, or their lengths if
+// one is a prefix of the other
+func (d *Deque) compare(other py.Object, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	o, ok := other.(*Deque)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	a, b := d.Items(), o.Items()
+	for i := 0; i < len(a) && i < len(b); i++ {
+		eq, err := py.Eq(a[i], b[i])
+		if err != nil {
+			return nil, err
+		}
+		if !py.ObjectIsTrue(eq) {
+			return op(a[i], b[i])
+		}
+	}
+	return op(py.Int(len(a)), py.Int(len(b)))
+}
+
+func (d *Deque) M__eq__(other py.Object) (py.Object, error) {
+	return d.compare(other, py.Eq)
+}
+
+func (d *Deque) M__ne__(other py.Object) (py.Object, error) {
+	return d.compare(other, py.Ne)
+}
+
+func (d *Deque) M__lt__(other py.Object) (py.Object, error) {
+	return d.compare(other, py.Lt)
+}
+
+func (d *Deque) M__le__(other py.Object) (py.Object, error) {
+	return d.compare(other, py.Le)
+}
+
+func (d *Deque) M__gt__(other py.Object) (py.Object, error) {
+	return d.compare(other, py.Gt)
+}
+
+func (d *Deque) M__ge__(other py.Object) (py.Object, error) {
+	return d.compare(other, py.Ge)
+}
+
+// DequeIterator iterates over a deque
+type DequeIterator struct {
+	deque *Deque
+	state int
+	i     int
+	step  int
+}
+
+// Type of this object
+func (it *DequeIterator) Type() *py.Type {
+	return DequeIteratorType
+}
+
+func (it *DequeIterator) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *DequeIterator) M__next__() (py.Object, error) {
+	d := it.deque
+	if d.state != it.state {
+		return nil, py.ExceptionNewf(py.RuntimeError, "deque mutated during iteration")
+	}
+	if it.i < 0 || it.i >= d.n {
+		return nil, py.StopIteration
+	}
+	item := d.get(it.i)
+	it.i += it.step
+	return item, nil
+}
+
+func init() {
+	DequeType.Dict["append"] = py.MustNewMethod("append", func(self, item py.Object) (py.Object, error) {
+		self.(*Deque).Append(item)
+		return py.None, nil
+	}, 0, "Add an element to the right side of the deque.")
+	DequeType.Dict["appendleft"] = py.MustNewMethod("appendleft", func(self, item py.Object) (py.Object, error) {
+		self.(*Deque).AppendLeft(item)
+		return py.None, nil
+	}, 0, "Add an element to the left side of the deque.")
+	DequeType.Dict["pop"] = py.MustNewMethod("pop", func(self py.Object) (py.Object, error) {
+		item := self.(*Deque).Pop()
+		if item == nil {
+			return nil, py.ExceptionNewf(py.IndexError, "pop from an empty deque")
+		}
+		return item, nil
+	}, 0, "Remove and return the rightmost element.")
+	DequeType.Dict["popleft"] = py.MustNewMethod("popleft", func(self py.Object) (py.Object, error) {
+		item := self.(*Deque).PopLeft()
+		if item == nil {
+			return nil, py.ExceptionNewf(py.IndexError, "pop from an empty deque")
+		}
+		return item, nil
+	}, 0, "Remove and return the leftmost element.")
+	DequeType.Dict["extend"] = py.MustNewMethod("extend", func(self, iterable py.Object) (py.Object, error) {
+		return py.None, self.(*Deque).Extend(iterable)
+	}, 0, "Extend the right side of the deque with elements from the iterable")
+	DequeType.Dict["extendleft"] = py.MustNewMethod("extendleft", func(self, iterable py.Object) (py.Object, error) {
+		return py.None, self.(*Deque).ExtendLeft(iterable)
+	}, 0, "Extend the left side of the deque with elements from the iterable")
+	DequeType.Dict["rotate"] = py.MustNewMethod("rotate", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var n py.Object = py.Int(1)
+		err := py.UnpackTuple(args, nil, "rotate", 0, 1, &n)
+		if err != nil {
+			return nil, err
+		}
+		steps, err := py.IndexInt(n)
+		if err != nil {
+			return nil, err
+		}
+		self.(*Deque).Rotate(steps)
+		return py.None, nil
+	}, 0, "Rotate the deque n steps to the right (default n=1).  If n is negative, rotates left.")
+	DequeType.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object) (py.Object, error) {
+		self.(*Deque).Clear()
+		return py.None, nil
+	}, 0, "Remove all elements from the deque.")
+	DequeType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*Deque).Copy(), nil
+	}, 0, "Return a shallow copy of a deque.")
+	DequeType.Dict["count"] = py.MustNewMethod("count", func(self, value py.Object) (py.Object, error) {
+		d := self.(*Deque)
+		count := 0
+		for i := 0; ; i++ {
+			var err error
+			i, err = d.find(value, i, d.n)
+			if err != nil {
+				return nil, err
+			}
+			if i < 0 {
+				return py.Int(count), nil
+			}
+			count++
+		}
+	}, 0, "D.count(value) -> integer -- return number of occurrences of value")
+	DequeType.Dict["index"] = py.MustNewMethod("index", func(self py.Object, args py.Tuple) (py.Object, error) {
+		d := self.(*Deque)
+		var value py.Object
+		var startObj, stopObj py.Object = py.Int(0), py.Int(d.n)
+		err := py.UnpackTuple(args, nil, "index", 1, 3, &value, &startObj, &stopObj)
+		if err != nil {
+			return nil, err
+		}
+		var bounds [2]int
+		for j, o := range []py.Object{startObj, stopObj} {
+			bound, err := py.IndexInt(o)
+			if err != nil {
+				return nil, err
+			}
+			if bound < 0 {
+				bound += d.n
+				if bound < 0 {
+					bound = 0
+				}
+			}
+			bounds[j] = bound
+		}
+		i, err := d.find(value, bounds[0], bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "deque.index(x): x not in deque")
+		}
+		return py.Int(i), nil
+	}, 0, "D.index(value, [start, [stop]]) -> integer -- return first index of value.\nRaises ValueError if the value is not present.")
+	DequeType.Dict["insert"] = py.MustNewMethod("insert", func(self py.Object, args py.Tuple) (py.Object, error) {
+		d := self.(*Deque)
+		var index, value py.Object
+		err := py.UnpackTuple(args, nil, "insert", 2, 2, &index, &value)
+		if err != nil {
+			return nil, err
+		}
+		i, err := py.IndexInt(index)
+		if err != nil {
+			return nil, err
+		}
+		if d.maxlen >= 0 && d.n >= d.maxlen {
+			return nil, py.ExceptionNewf(py.IndexError, "deque already at its maximum size")
+		}
+		if i < 0 {
+			i += d.n
+			if i < 0 {
+				i = 0
+			}
+		}
+		if i > d.n {
+			i = d.n
+		}
+		items := d.Items()
+		items = append(items[:i], append([]py.Object{value}, items[i:]...)...)
+		d.setItems(items)
+		return py.None, nil
+	}, 0, "D.insert(index, object) -- insert object before index")
+	DequeType.Dict["remove"] = py.MustNewMethod("remove", func(self, value py.Object) (py.Object, error) {
+		d := self.(*Deque)
+		i, err := d.find(value, 0, d.n)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "deque.remove(x): x not in deque")
+		}
+		_, err = d.M__delitem__(py.Int(i))
+		return py.None, err
+	}, 0, "D.remove(value) -- remove first occurrence of value.")
+	DequeType.Dict["reverse"] = py.MustNewMethod("reverse", func(self py.Object) (py.Object, error) {
+		d := self.(*Deque)
+		items := d.Items()
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		d.setItems(items)
+		return py.None, nil
+	}, 0, "D.reverse() -- reverse *IN PLACE*")
+	DequeType.Dict["maxlen"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			d := self.(*Deque)
+			if d.maxlen < 0 {
+				return py.None, nil
+			}
+			return py.Int(d.maxlen), nil
+		},
+		Doc: "maximum size of a deque or None if unbounded",
+	}
+}
+
+// Check interfaces
+var _ py.I__len__ = (*Deque)(nil)
+var _ py.I__getitem__ = (*Deque)(nil)
+var _ py.I__setitem__ = (*Deque)(nil)
+var _ py.I__delitem__ = (*Deque)(nil)
+var _ py.I__contains__ = (*Deque)(nil)
+var _ py.I__iter__ = (*Deque)(nil)
+var _ py.I__reversed__ = (*Deque)(nil)
+var _ py.I__eq__ = (*Deque)(nil)
+var _ py.I_iterator = (*DequeIterator)(nil)
@@ -0,0 +1,817 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// OrderedDict, defaultdict and Counter
+//
+// These are subclasses of dict.  They embed a *py.Dict so the methods
+// of dict work on them as well.
+
+package collections
+
+import (
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+const dictFlags = py.TPFLAGS_BASETYPE | py.TPFLAGS_SUBCLASS_NEW | py.TPFLAGS_DICT_SUBCLASS
+
+const ordered_dict_doc = `Dictionary that remembers insertion order`
+
+const default_dict_doc = `defaultdict(default_factory[, ...]) --> dict with default factory
+
+The default factory is called without arguments to produce
+a new value when a key is not present, in __getitem__ only.
+A defaultdict compares equal to a dict with the same items.
+All remaining arguments are treated the same as if they were
+passed to the dict constructor, including keyword arguments.`
+
+const counter_doc = `Dict subclass for counting hashable items.  Sometimes called a bag
+or multiset.  Elements are stored as dictionary keys and their counts
+are stored as dictionary values.`
+
+var (
+	OrderedDictType = py.DictType.NewTypeFlags("OrderedDict", ordered_dict_doc, OrderedDictNew, OrderedDictInit, py.DictType.Flags|dictFlags)
+	DefaultDictType = py.DictType.NewTypeFlags("defaultdict", default_dict_doc, DefaultDictNew, DefaultDictInit, py.DictType.Flags|dictFlags)
+	CounterType     = py.DictType.NewTypeFlags("Counter", counter_doc, CounterNew, CounterInit, py.DictType.Flags|dictFlags)
+)
+
+// The part shared by the subclasses of dict
+type dictBase struct {
+	*py.Dict
+	Base  *py.Type
+	Attrs py.StringDict
+}
+
+// Makes an empty dictBase of type t
+func newDictBase(t *py.Type) dictBase {
+	return dictBase{Dict: py.NewDict(), Base: t, Attrs: py.NewStringDict()}
+}
+
+// Type of this object
+func (d *dictBase) Type() *py.Type {
+	return d.Base
+}
+
+// GetDict returns the attributes of the dictionary
+func (d *dictBase) GetDict() py.StringDict {
+	return d.Attrs
+}
+
+func (d *dictBase) M__hash__() (py.Object, error) {
+	return nil, py.ExceptionNewf(py.TypeError, "unhashable type: '%s'", d.Base.Name)
+}
+
+// Returns the items of d in a new dict
+func (d *dictBase) items() *py.Dict {
+	return d.Dict.Copy()
+}
+
+// Copies the items of src into d
+func (d *dictBase) setItems(src *py.Dict) {
+	for _, item := range src.Items() {
+		// The keys were hashed when they were added to src
+		_ = d.Set(item[0], item[1])
+	}
+}
+
+// Returns self[key] calling self.__missing__(key) if key isn't in d
+// and the class defines __missing__
+func getItem(self py.Object, d *py.Dict, key py.Object) (py.Object, error) {
+	value, found, err := d.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return value, nil
+	}
+	if missing, err := py.GetAttrString(self, "__missing__"); err == nil {
+		return py.Call(missing, py.Tuple{key}, nil)
+	}
+	return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+}
+
+// Calls the dict method name on self
+func callDictMethod(self py.Object, name string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return py.DictType.Dict[name].(*py.Method).CallWithKeywords(self, args, kwargs)
+}
+
+// Checks that there is at most one positional argument
+func checkOneArg(args py.Tuple) error {
+	if len(args) > 1 {
+		return py.ExceptionNewf(py.TypeError, "expected at most 1 arguments, got %d", len(args))
+	}
+	return nil
+}
+
+// OrderedDict is a dict with methods to reorder its items
+type OrderedDict struct {
+	dictBase
+}
+
+// NewOrderedDict makes an empty OrderedDict
+func NewOrderedDict() *OrderedDict {
+	return &OrderedDict{newDictBase(OrderedDictType)}
+}
+
+// OrderedDictNew makes an empty OrderedDict
+func OrderedDictNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &OrderedDict{newDictBase(metatype)}, nil
+}
+
+// OrderedDictInit adds the items from a mapping or iterable of pairs
+// and kwargs
+func OrderedDictInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	err := checkOneArg(args)
+	if err != nil {
+		return err
+	}
+	_, err = callDictMethod(self, "update", args, kwargs)
+	return err
+}
+
+// MoveToEnd moves key to the end, or to the beginning if last is false
+func (d *OrderedDict) MoveToEnd(key py.Object, last bool) error {
+	value, found, err := d.Delete(key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+	}
+	if last {
+		return d.Set(key, value)
+	}
+	items := d.items()
+	d.Clear()
+	_ = d.Set(key, value)
+	d.setItems(items)
+	return nil
+}
+
+func (d *OrderedDict) M__getitem__(key py.Object) (py.Object, error) {
+	return getItem(d, d.Dict, key)
+}
+
+func (d *OrderedDict) M__reversed__() (py.Object, error) {
+	keys := d.Keys()
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return py.NewIterator(keys), nil
+}
+
+func (d *OrderedDict) M__repr__() (py.Object, error) {
+	if d.Len() == 0 {
+		return py.String(d.Base.Name + "()"), nil
+	}
+	items := py.NewList()
+	for _, item := range d.Items() {
+		items.Append(item)
+	}
+	repr, err := py.ReprAsString(items)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(d.Base.Name + "(" + repr + ")"), nil
+}
+
+func (d *OrderedDict) M__str__() (py.Object, error) {
+	return d.M__repr__()
+}
+
+// Copy returns a shallow copy of the OrderedDict
+func (d *OrderedDict) Copy() *OrderedDict {
+	c := &OrderedDict{newDictBase(d.Base)}
+	c.setItems(d.Dict)
+	return c
+}
+
+// Comparing two OrderedDicts is sensitive to the order of their items
+func (d *OrderedDict) M__eq__(other py.Object) (py.Object, error) {
+	o, ok := other.(*OrderedDict)
+	if !ok {
+		return d.Dict.M__eq__(other)
+	}
+	a, b := d.Items(), o.Items()
+	if len(a) != len(b) {
+		return py.False, nil
+	}
+	for i := range a {
+		eq, err := a[i].M__eq__(b[i])
+		if err != nil {
+			return nil, err
+		}
+		if !py.ObjectIsTrue(eq) {
+			return py.False, nil
+		}
+	}
+	return py.True, nil
+}
+
+func (d *OrderedDict) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := d.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.NewBool(!py.ObjectIsTrue(eq)), nil
+}
+
+// DefaultDict is a dict which calls Factory to make the values of
+// missing keys
+type DefaultDict struct {
+	dictBase
+	Factory py.Object
+}
+
+// NewDefaultDict makes an empty defaultdict using factory to make
+// missing values
+func NewDefaultDict(factory py.Object) *DefaultDict {
+	return &DefaultDict{dictBase: newDictBase(DefaultDictType), Factory: factory}
+}
+
+// DefaultDictNew makes an empty defaultdict without a factory
+func DefaultDictNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &DefaultDict{dictBase: newDictBase(metatype), Factory: py.None}, nil
+}
+
+// DefaultDictInit sets the factory from the first argument and adds
+// the items from the rest like dict
+func DefaultDictInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	d := self.(*DefaultDict)
+	if len(args) > 0 {
+		factory := args[0]
+		if _, ok := factory.(py.I__call__); !ok && factory != py.None {
+			return py.ExceptionNewf(py.TypeError, "first argument must be callable or None")
+		}
+		d.Factory = factory
+		args = args[1:]
+	}
+	_, err := callDictMethod(self, "update", args, kwargs)
+	return err
+}
+
+// Missing makes, stores and returns the value for key calling the
+// factory, or returns a KeyError if there isn't one
+func (d *DefaultDict) Missing(key py.Object) (py.Object, error) {
+	if d.Factory == py.None {
+		return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+	}
+	value, err := py.Call(d.Factory, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = d.Set(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (d *DefaultDict) M__getitem__(key py.Object) (py.Object, error) {
+	return getItem(d, d.Dict, key)
+}
+
+func (d *DefaultDict) M__repr__() (py.Object, error) {
+	factory, err := py.ReprAsString(d.Factory)
+	if err != nil {
+		return nil, err
+	}
+	items, err := py.ReprAsString(d.Dict)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(d.Base.Name + "(" + factory + ", " + items + ")"), nil
+}
+
+func (d *DefaultDict) M__str__() (py.Object, error) {
+	return d.M__repr__()
+}
+
+// Copy returns a shallow copy of the defaultdict
+func (d *DefaultDict) Copy() *DefaultDict {
+	c := &DefaultDict{dictBase: newDictBase(d.Base), Factory: d.Factory}
+	c.setItems(d.Dict)
+	return c
+}
+
+// Counter is a dict mapping items to the number of times they occur
+type Counter struct {
+	dictBase
+}
+
+// NewCounter makes an empty Counter
+func NewCounter() *Counter {
+	return &Counter{newDictBase(CounterType)}
+}
+
+// CounterNew makes an empty Counter
+func CounterNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &Counter{newDictBase(metatype)}, nil
+}
+
+// CounterInit counts the items of an iterable or adds the counts of a
+// mapping and kwargs
+func CounterInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	err := checkOneArg(args)
+	if err != nil {
+		return err
+	}
+	var iterable py.Object = py.None
+	if len(args) > 0 {
+		iterable = args[0]
+	}
+	return self.(*Counter).Update(iterable, kwargs, py.Add)
+}
+
+// Returns the count of key which is 0 if it is missing
+func (c *Counter) count(key py.Object) (py.Object, error) {
+	value, found, err := c.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return py.Int(0), nil
+	}
+	return value, nil
+}
+
+// Combines the count of key with n using op
+func (c *Counter) add(key, n py.Object, op func(a, b py.Object) (py.Object, error)) error {
+	count, err := c.count(key)
+	if err != nil {
+		return err
+	}
+	count, err = op(count, n)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, count)
+}
+
+// Combines the counts of the mapping m with op
+func (c *Counter) addMapping(m py.Object, keys py.Object, op func(a, b py.Object) (py.Object, error)) error {
+	keysObj, err := py.Call(keys, nil, nil)
+	if err != nil {
+		return err
+	}
+	var loopErr error
+	err = py.Iterate(keysObj, func(key py.Object) bool {
+		var n py.Object
+		n, loopErr = py.GetItem(m, key)
+		if loopErr == nil {
+			loopErr = c.add(key, n, op)
+		}
+		return loopErr != nil
+	})
+	if err == nil {
+		err = loopErr
+	}
+	return err
+}
+
+// Update combines the counts with those of iterable using op
+//
+// The iterable may be a mapping of items to counts or an iterable of
+// items each counting 1.  The counts of kwargs are added afterwards.
+func (c *Counter) Update(iterable py.Object, kwargs py.StringDict, op func(a, b py.Object) (py.Object, error)) error {
+	if iterable != py.None {
+		if keys, err := py.GetAttrString(iterable, "keys"); err == nil {
+			err = c.addMapping(iterable, keys, op)
+			if err != nil {
+				return err
+			}
+		} else {
+			var loopErr error
+			err := py.Iterate(iterable, func(item py.Object) bool {
+				loopErr = c.add(item, py.Int(1), op)
+				return loopErr != nil
+			})
+			if err == nil {
+				err = loopErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, item := range kwargs.Items() {
+		err := c.add(item[0], item[1], op)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MostCommon returns the (item, count) pairs in descending order of
+// count, items with equal counts staying in insertion order
+func (c *Counter) MostCommon() ([]py.Tuple, error) {
+	items := c.Items()
+	var err error
+	sort.SliceStable(items, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		var gt py.Object
+		gt, err = py.Gt(items[i][1], items[j][1])
+		return err == nil && py.ObjectIsTrue(gt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (c *Counter) M__getitem__(key py.Object) (py.Object, error) {
+	return getItem(c, c.Dict, key)
+}
+
+// Deleting a missing item is not an error
+func (c *Counter) M__delitem__(key py.Object) (py.Object, error) {
+	_, _, err := c.Delete(key)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func (c *Counter) M__repr__() (py.Object, error) {
+	if c.Len() == 0 {
+		return py.String(c.Base.Name + "()"), nil
+	}
+	items, err := c.MostCommon()
+	if err != nil {
+		// Show counts which can't be ordered as they are
+		items = c.Items()
+	}
+	d := py.NewDict()
+	for _, item := range items {
+		_ = d.Set(item[0], item[1])
+	}
+	repr, err := py.ReprAsString(d)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(c.Base.Name + "(" + repr + ")"), nil
+}
+
+func (c *Counter) M__str__() (py.Object, error) {
+	return c.M__repr__()
+}
+
+// Copy returns a shallow copy of the Counter
+func (c *Counter) Copy() *Counter {
+	cc := &Counter{newDictBase(c.Base)}
+	cc.setItems(c.Dict)
+	return cc
+}
+
+// Returns whether x > 0
+func isPositive(x py.Object) (bool, error) {
+	gt, err := py.Gt(x, py.Int(0))
+	if err != nil {
+		return false, err
+	}
+	return py.ObjectIsTrue(gt), nil
+}
+
+// Returns the items of c and then the items only in other
+func (c *Counter) union(other *Counter) []py.Object {
+	keys := c.Keys()
+	for _, key := range other.Keys() {
+		if _, found, _ := c.Get(key); !found {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Returns a new Counter combining the counts of c and other with op
+// and keeping only the positive counts
+func (c *Counter) combine(other py.Object, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	o, ok := other.(*Counter)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	result := NewCounter()
+	for _, key := range c.union(o) {
+		a, err := c.count(key)
+		if err != nil {
+			return nil, err
+		}
+		b, err := o.count(key)
+		if err != nil {
+			return nil, err
+		}
+		n, err := op(a, b)
+		if err != nil {
+			return nil, err
+		}
+		positive, err := isPositive(n)
+		if err != nil {
+			return nil, err
+		}
+		if positive {
+			_ = result.Set(key, n)
+		}
+	}
+	return result, nil
+}
+
+// Combines the counts of other into c with op keeping only the
+// positive counts
+func (c *Counter) combineInPlace(other py.Object, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	result, err := c.combine(other, op)
+	if err != nil || result == py.NotImplemented {
+		return result, err
+	}
+	c.Clear()
+	c.setItems(result.(*Counter).Dict)
+	return c, nil
+}
+
+// Returns the larger of a and b
+func maxCount(a, b py.Object) (py.Object, error) {
+	lt, err := py.Lt(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if py.ObjectIsTrue(lt) {
+		return b, nil
+	}
+	return a, nil
+}
+
+// Returns the smaller of a and b
+func minCount(a, b py.Object) (py.Object, error) {
+	lt, err := py.Lt(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if py.ObjectIsTrue(lt) {
+		return a, nil
+	}
+	return b, nil
+}
+
+func (c *Counter) M__add__(other py.Object) (py.Object, error) {
+	return c.combine(other, py.Add)
+}
+
+func (c *Counter) M__sub__(other py.Object) (py.Object, error) {
+	return c.combine(other, py.Sub)
+}
+
+func (c *Counter) M__or__(other py.Object) (py.Object, error) {
+	return c.combine(other, maxCount)
+}
+
+func (c *Counter) M__and__(other py.Object) (py.Object, error) {
+	return c.combine(other, minCount)
+}
+
+func (c *Counter) M__iadd__(other py.Object) (py.Object, error) {
+	return c.combineInPlace(other, py.Add)
+}
+
+func (c *Counter) M__isub__(other py.Object) (py.Object, error) {
+	return c.combineInPlace(other, py.Sub)
+}
+
+func (c *Counter) M__ior__(other py.Object) (py.Object, error) {
+	return c.combineInPlace(other, maxCount)
+}
+
+func (c *Counter) M__iand__(other py.Object) (py.Object, error) {
+	return c.combineInPlace(other, minCount)
+}
+
+func (c *Counter) M__pos__() (py.Object, error) {
+	return c.combine(NewCounter(), py.Add)
+}
+
+func (c *Counter) M__neg__() (py.Object, error) {
+	return NewCounter().combine(c, py.Sub)
+}
+
+// Returns whether op holds for the counts of every item in c or
+// other, missing items counting as 0
+func (c *Counter) compare(other py.Object, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	o, ok := other.(*Counter)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	for _, key := range c.union(o) {
+		a, err := c.count(key)
+		if err != nil {
+			return nil, err
+		}
+		b, err := o.count(key)
+		if err != nil {
+			return nil, err
+		}
+		res, err := op(a, b)
+		if err != nil {
+			return nil, err
+		}
+		if !py.ObjectIsTrue(res) {
+			return py.False, nil
+		}
+	}
+	return py.True, nil
+}
+
+// Returns the result of compare with op and whether c and other differ
+func (c *Counter) compareStrict(other py.Object, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	res, err := c.compare(other, op)
+	if err != nil || res != py.True {
+		return res, err
+	}
+	return c.M__ne__(other)
+}
+
+func (c *Counter) M__eq__(other py.Object) (py.Object, error) {
+	return c.compare(other, py.Eq)
+}
+
+func (c *Counter) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := c.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.NewBool(!py.ObjectIsTrue(eq)), nil
+}
+
+func (c *Counter) M__le__(other py.Object) (py.Object, error) {
+	return c.compare(other, py.Le)
+}
+
+func (c *Counter) M__lt__(other py.Object) (py.Object, error) {
+	return c.compareStrict(other, py.Le)
+}
+
+func (c *Counter) M__ge__(other py.Object) (py.Object, error) {
+	return c.compare(other, py.Ge)
+}
+
+func (c *Counter) M__gt__(other py.Object) (py.Object, error) {
+	return c.compareStrict(other, py.Ge)
+}
+
+// Writes the methods of OrderedDict
+func init() {
+	OrderedDictType.Dict["move_to_end"] = py.MustNewMethod("move_to_end", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var key py.Object
+		var last py.Object = py.True
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:move_to_end", []string{"key", "last"}, &key, &last)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*OrderedDict).MoveToEnd(key, py.ObjectIsTrue(last))
+	}, 0, "Move an existing element to the end (or beginning if last is false).\n\nRaise KeyError if the element does not exist.")
+	OrderedDictType.Dict["popitem"] = py.MustNewMethod("popitem", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var last py.Object = py.True
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:popitem", []string{"last"}, &last)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(*OrderedDict)
+		keys := d.Keys()
+		if len(keys) == 0 {
+			return nil, py.ExceptionNewf(py.KeyError, "dictionary is empty")
+		}
+		key := keys[0]
+		if py.ObjectIsTrue(last) {
+			key = keys[len(keys)-1]
+		}
+		value, _, err := d.Delete(key)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{key, value}, nil
+	}, 0, "Remove and return a (key, value) pair from the dictionary.\n\nPairs are returned in LIFO order if last is true or FIFO order if false.")
+	OrderedDictType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*OrderedDict).Copy(), nil
+	}, 0, "od.copy() -> a shallow copy of od")
+	OrderedDictType.Dict["__copy__"] = OrderedDictType.Dict["copy"]
+}
+
+// Writes the methods of defaultdict
+func init() {
+	DefaultDictType.Dict["__missing__"] = py.MustNewMethod("__missing__", func(self, key py.Object) (py.Object, error) {
+		return self.(*DefaultDict).Missing(key)
+	}, 0, "__missing__(key) # Called by __getitem__ for missing key; pseudo-code:\n  if self.default_factory is None: raise KeyError((key,))\n  self[key] = value = self.default_factory()\n  return value")
+	DefaultDictType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*DefaultDict).Copy(), nil
+	}, 0, "D.copy() -> a shallow copy of D.")
+	DefaultDictType.Dict["__copy__"] = DefaultDictType.Dict["copy"]
+	DefaultDictType.Dict["default_factory"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*DefaultDict).Factory, nil
+		},
+		Fset: func(self, value py.Object) error {
+			self.(*DefaultDict).Factory = value
+			return nil
+		},
+		Doc: "Factory for default value called by __missing__().",
+	}
+}
+
+// Writes the methods of Counter
+func init() {
+	CounterType.Dict["__missing__"] = py.MustNewMethod("__missing__", func(self, key py.Object) (py.Object, error) {
+		return py.Int(0), nil
+	}, 0, "The count of elements not in the Counter is zero.")
+	CounterType.Dict["most_common"] = py.MustNewMethod("most_common", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var n py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:most_common", []string{"n"}, &n)
+		if err != nil {
+			return nil, err
+		}
+		items, err := self.(*Counter).MostCommon()
+		if err != nil {
+			return nil, err
+		}
+		if n != py.None {
+			limit, err := py.IndexInt(n)
+			if err != nil {
+				return nil, err
+			}
+			if limit < 0 {
+				limit = 0
+			}
+			if limit < len(items) {
+				items = items[:limit]
+			}
+		}
+		result := py.NewListSized(len(items))
+		for i, item := range items {
+			result.Items[i] = item
+		}
+		return result, nil
+	}, 0, "List the n most common elements and their counts from the most\ncommon to the least.  If n is None, then list all element counts.")
+	CounterType.Dict["elements"] = py.MustNewMethod("elements", func(self py.Object) (py.Object, error) {
+		var elements []py.Object
+		for _, item := range self.(*Counter).Items() {
+			n, err := py.IndexInt(item[1])
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < n; i++ {
+				elements = append(elements, item[0])
+			}
+		}
+		return py.NewIterator(elements), nil
+	}, 0, "Iterator over elements repeating each as many times as its count.")
+	CounterType.Dict["update"] = py.MustNewMethod("update", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var iterable py.Object = py.None
+		err := py.UnpackTuple(args, nil, "update", 0, 1, &iterable)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Counter).Update(iterable, kwargs, py.Add)
+	}, 0, "Like dict.update() but add counts instead of replacing them.\n\nSource can be an iterable, a dictionary, or another Counter instance.")
+	CounterType.Dict["subtract"] = py.MustNewMethod("subtract", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var iterable py.Object = py.None
+		err := py.UnpackTuple(args, nil, "subtract", 0, 1, &iterable)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Counter).Update(iterable, kwargs, py.Sub)
+	}, 0, "Like dict.update() but subtracts counts instead of replacing them.\nCounts can be reduced below zero.  Both the inputs and outputs are\nallowed to contain zero and negative counts.\n\nSource can be an iterable, a dictionary, or another Counter instance.")
+	CounterType.Dict["total"] = py.MustNewMethod("total", func(self py.Object) (py.Object, error) {
+		var total py.Object = py.Int(0)
+		for _, count := range self.(*Counter).Values() {
+			var err error
+			total, err = py.Add(total, count)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return total, nil
+	}, 0, "Sum of the counts")
+	CounterType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*Counter).Copy(), nil
+	}, 0, "Return a shallow copy.")
+	CounterType.Dict["__copy__"] = CounterType.Dict["copy"]
+	CounterType.Dict["fromkeys"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("fromkeys", func(self py.Object, args py.Tuple) (py.Object, error) {
+			return nil, py.ExceptionNewf(py.NotImplementedError, "Counter.fromkeys() is undefined.  Use Counter(iterable) instead.")
+		}, 0, ""),
+	}
+}
+
+// Check interfaces
+var _ py.IGetDict = (*OrderedDict)(nil)
+var _ py.I__getitem__ = (*OrderedDict)(nil)
+var _ py.I__reversed__ = (*OrderedDict)(nil)
+var _ py.I__eq__ = (*OrderedDict)(nil)
+var _ py.I__getitem__ = (*DefaultDict)(nil)
+var _ py.I__getitem__ = (*Counter)(nil)
+var _ py.I__delitem__ = (*Counter)(nil)
+var _ py.I__add__ = (*Counter)(nil)
+var _ py.I__iadd__ = (*Counter)(nil)
+var _ py.I__neg__ = (*Counter)(nil)
+var _ py.I__le__ = (*Counter)(nil)
@@ -0,0 +1,427 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Named tuples
+//
+// namedtuple makes a new subclass of tuple whose items can also be
+// read as attributes.  The field names are kept in the _fields of the
+// class so python subclasses of the class work too.
+
+package collections
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/go-python/gpython/py"
+)
+
+const namedtuple_doc = `Returns a new subclass of tuple with named fields.
+
+    >>> Point = namedtuple('Point', ['x', 'y'])
+    >>> Point.__doc__                   # docstring for the new class
+    'Point(x, y)'
+    >>> p = Point(11, y=22)             # instantiate with positional args or keywords
+    >>> p[0] + p[1]                     # indexable like a plain tuple
+    33
+    >>> x, y = p                        # unpack like a regular tuple
+    >>> x, y
+    (11, 22)
+    >>> p.x + p.y                       # fields also accessible by name
+    33
+    >>> d = p._asdict()                 # convert to a dictionary
+    >>> d['x']
+    11
+    >>> Point(**d)                      # convert from a dictionary
+    Point(x=11, y=22)
+    >>> p._replace(x=100)               # _replace() is like str.replace() but targets named fields
+    Point(x=100, y=22)`
+
+// The reserved words which can't be field names
+var keywords = map[string]struct{}{
+	"False": {}, "None": {}, "True": {}, "and": {}, "as": {}, "assert": {},
+	"async": {}, "await": {}, "break": {}, "class": {}, "continue": {},
+	"def": {}, "del": {}, "elif": {}, "else": {}, "except": {},
+	"finally": {}, "for": {}, "from": {}, "global": {}, "if": {},
+	"import": {}, "in": {}, "is": {}, "lambda": {}, "nonlocal": {},
+	"not": {}, "or": {}, "pass": {}, "raise": {}, "return": {}, "try": {},
+	"while": {}, "with": {}, "yield": {},
+}
+
+// Returns true if s is a valid python identifier
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if !(c == '_' || unicode.IsLetter(c) || (i > 0 && unicode.IsDigit(c))) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if s is a reserved word
+func isKeyword(s string) bool {
+	_, ok := keywords[s]
+	return ok
+}
+
+// NamedTuple is an instance of a class made by namedtuple
+type NamedTuple struct {
+	py.Tuple
+	Base *py.Type
+	Dict py.StringDict // only for instances of python subclasses
+}
+
+// Type of this object
+func (t *NamedTuple) Type() *py.Type {
+	return t.Base
+}
+
+// GetDict returns the attributes of the named tuple
+func (t *NamedTuple) GetDict() py.StringDict {
+	return t.Dict
+}
+
+// Returns the field names of the named tuple class t
+func fieldNames(t *py.Type) (py.Tuple, error) {
+	fields, ok := t.Lookup("_fields").(py.Tuple)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "%s._fields must be a tuple", t.Name)
+	}
+	return fields, nil
+}
+
+// Makes an instance of the named tuple class t holding values
+func newNamedTuple(t *py.Type, values py.Tuple) *NamedTuple {
+	nt := &NamedTuple{Tuple: values, Base: t}
+	if t.Flags&py.TPFLAGS_HEAPTYPE != 0 && !t.NoDict {
+		nt.Dict = py.NewStringDict()
+	}
+	return nt
+}
+
+// Returns the quoted names joined in the style of python's missing
+// argument errors, eg 'x', 'y' and 'z'
+func joinNames(names []string) string {
+	for i := range names {
+		names[i] = "'" + names[i] + "'"
+	}
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+}
+
+// NamedTupleNew makes an instance of a named tuple class from the
+// values of its fields given by position or by name
+func NamedTupleNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	fields, err := fieldNames(metatype)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > len(fields) {
+		return nil, py.ExceptionNewf(py.TypeError, "__new__() takes %d positional arguments but %d were given", len(fields)+1, len(args)+1)
+	}
+	values := make(py.Tuple, len(fields))
+	copy(values, args)
+	for name, value := range kwargs {
+		i := -1
+		for j, field := range fields {
+			if string(field.(py.String)) == name {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return nil, py.ExceptionNewf(py.TypeError, "__new__() got an unexpected keyword argument '%s'", name)
+		}
+		if values[i] != nil {
+			return nil, py.ExceptionNewf(py.TypeError, "__new__() got multiple values for argument '%s'", name)
+		}
+		values[i] = value
+	}
+	defaults, _ := metatype.Lookup("_field_defaults").(*py.Dict)
+	var missing []string
+	for i, value := range values {
+		if value != nil {
+			continue
+		}
+		if defaults != nil {
+			value, found, err := defaults.Get(fields[i])
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				values[i] = value
+				continue
+			}
+		}
+		missing = append(missing, string(fields[i].(py.String)))
+	}
+	if len(missing) > 0 {
+		plural := ""
+		if len(missing) > 1 {
+			plural = "s"
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "__new__() missing %d required positional argument%s: %s", len(missing), plural, joinNames(missing))
+	}
+	return newNamedTuple(metatype, values), nil
+}
+
+func (t *NamedTuple) M__repr__() (py.Object, error) {
+	fields, err := fieldNames(t.Base)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteString(t.Base.Name)
+	out.WriteString("(")
+	for i, value := range t.Tuple {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		if i < len(fields) {
+			fmt.Fprintf(&out, "%s=", fields[i])
+		}
+		repr, err := py.ReprAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+func (t *NamedTuple) M__str__() (py.Object, error) {
+	return t.M__repr__()
+}
+
+// Returns the tuple holding the items of other
+func asPlainTuple(other py.Object) py.Object {
+	if nt, ok := other.(*NamedTuple); ok {
+		return nt.Tuple
+	}
+	return other
+}
+
+func (t *NamedTuple) M__eq__(other py.Object) (py.Object, error) {
+	return t.Tuple.M__eq__(asPlainTuple(other))
+}
+
+func (t *NamedTuple) M__ne__(other py.Object) (py.Object, error) {
+	return t.Tuple.M__ne__(asPlainTuple(other))
+}
+
+func (t *NamedTuple) M__add__(other py.Object) (py.Object, error) {
+	return t.Tuple.M__add__(asPlainTuple(other))
+}
+
+func (t *NamedTuple) M__radd__(other py.Object) (py.Object, error) {
+	return t.Tuple.M__radd__(asPlainTuple(other))
+}
+
+// Returns the field names given as a string separated by spaces or
+// commas or as an iterable of strings
+func parseFieldNames(fieldNamesObj py.Object) ([]string, error) {
+	if s, ok := fieldNamesObj.(py.String); ok {
+		return strings.Fields(strings.Replace(string(s), ",", " ", -1)), nil
+	}
+	var names []string
+	var loopErr error
+	err := py.Iterate(fieldNamesObj, func(item py.Object) bool {
+		var name string
+		name, loopErr = py.StrAsString(item)
+		names = append(names, name)
+		return loopErr != nil
+	})
+	if err == nil {
+		err = loopErr
+	}
+	return names, err
+}
+
+// Checks the type and field names, renaming invalid field names if
+// rename is set
+func checkNames(typename string, names []string, rename bool) error {
+	if rename {
+		seen := map[string]struct{}{}
+		for i, name := range names {
+			_, dup := seen[name]
+			if !isIdentifier(name) || isKeyword(name) || strings.HasPrefix(name, "_") || dup {
+				names[i] = fmt.Sprintf("_%d", i)
+			}
+			seen[name] = struct{}{}
+		}
+	}
+	for _, name := range append([]string{typename}, names...) {
+		if !isIdentifier(name) {
+			return py.ExceptionNewf(py.ValueError, "Type names and field names must be valid identifiers: '%s'", name)
+		}
+		if isKeyword(name) {
+			return py.ExceptionNewf(py.ValueError, "Type names and field names cannot be a keyword: '%s'", name)
+		}
+	}
+	seen := map[string]struct{}{}
+	for _, name := range names {
+		if strings.HasPrefix(name, "_") && !rename {
+			return py.ExceptionNewf(py.ValueError, "Field names cannot start with an underscore: '%s'", name)
+		}
+		if _, dup := seen[name]; dup {
+			return py.ExceptionNewf(py.ValueError, "Encountered duplicate field name: '%s'", name)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
+// NewNamedTupleType makes a subclass of tuple called typename with
+// fields called names, the last of which default to the values of
+// defaults
+func NewNamedTupleType(typename string, names []string, defaults py.Tuple, module py.Object) (*py.Type, error) {
+	if len(defaults) > len(names) {
+		return nil, py.ExceptionNewf(py.TypeError, "Got more default values than field names")
+	}
+	doc := fmt.Sprintf("%s(%s)", typename, strings.Join(names, ", "))
+	t := py.TupleType.NewTypeFlags(typename, doc, NamedTupleNew, nil, py.TupleType.Flags|py.TPFLAGS_BASETYPE|py.TPFLAGS_SUBCLASS_NEW|py.TPFLAGS_TUPLE_SUBCLASS)
+	fields := make(py.Tuple, len(names))
+	fieldDefaults := py.NewDict()
+	for i, name := range names {
+		fields[i] = py.String(name)
+		if j := i - (len(names) - len(defaults)); j >= 0 {
+			_ = fieldDefaults.Set(fields[i], defaults[j])
+		}
+		i := i
+		t.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return self.(*NamedTuple).Tuple[i], nil
+			},
+			Doc: fmt.Sprintf("Alias for field number %d", i),
+		}
+	}
+	t.Dict["__doc__"] = py.String(doc)
+	t.Dict["__module__"] = module
+	t.Dict["_fields"] = fields
+	t.Dict["_field_defaults"] = fieldDefaults
+	t.Dict["__match_args__"] = fields
+	for name, method := range namedTupleMethods {
+		t.Dict[name] = method
+	}
+	return t, nil
+}
+
+// Makes a named tuple class
+func collections_namedtuple(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var typenameObj, fieldNamesObj py.Object
+	var rename, defaults, module py.Object = py.False, py.None, py.None
+	err := py.UnpackTuple(args, nil, "namedtuple", 2, 2, &typenameObj, &fieldNamesObj)
+	if err != nil {
+		return nil, err
+	}
+	err = py.ParseTupleAndKeywords(nil, kwargs, "|OOO:namedtuple", []string{"rename", "defaults", "module"}, &rename, &defaults, &module)
+	if err != nil {
+		return nil, err
+	}
+	typename, err := py.StrAsString(typenameObj)
+	if err != nil {
+		return nil, err
+	}
+	names, err := parseFieldNames(fieldNamesObj)
+	if err != nil {
+		return nil, err
+	}
+	err = checkNames(typename, names, py.ObjectIsTrue(rename))
+	if err != nil {
+		return nil, err
+	}
+	var defaultValues py.Tuple
+	if defaults != py.None {
+		defaultValues, err = py.SequenceTuple(defaults)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if module == py.None {
+		module = py.String("__main__")
+	}
+	return NewNamedTupleType(typename, names, defaultValues, module)
+}
+
+// The methods shared by all the named tuple classes
+var namedTupleMethods = py.StringDict{
+	"_make": &py.ClassMethod{
+		Callable: py.MustNewMethod("_make", func(cls, iterable py.Object) (py.Object, error) {
+			t := cls.(*py.Type)
+			fields, err := fieldNames(t)
+			if err != nil {
+				return nil, err
+			}
+			values, err := py.SequenceTuple(iterable)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) != len(fields) {
+				return nil, py.ExceptionNewf(py.TypeError, "Expected %d arguments, got %d", len(fields), len(values))
+			}
+			return newNamedTuple(t, values), nil
+		}, 0, "Make a new object from a sequence or iterable"),
+	},
+	"_replace": py.MustNewMethod("_replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if len(args) > 0 {
+			return nil, py.ExceptionNewf(py.TypeError, "_replace() takes 1 positional argument but %d were given", len(args)+1)
+		}
+		nt := self.(*NamedTuple)
+		fields, err := fieldNames(nt.Base)
+		if err != nil {
+			return nil, err
+		}
+		values := nt.Tuple.Copy()
+		unexpected := kwargs.Copy()
+		for i, field := range fields {
+			name := string(field.(py.String))
+			if value, ok := unexpected[name]; ok && i < len(values) {
+				values[i] = value
+				delete(unexpected, name)
+			}
+		}
+		if len(unexpected) > 0 {
+			repr, err := py.ReprAsString(py.NewListFromItems(unexpected.Keys()))
+			if err != nil {
+				return nil, err
+			}
+			return nil, py.ExceptionNewf(py.ValueError, "Got unexpected field names: %s", repr)
+		}
+		return newNamedTuple(nt.Base, values), nil
+	}, 0, "Return a new named tuple object replacing specified fields with new values"),
+	"_asdict": py.MustNewMethod("_asdict", func(self py.Object) (py.Object, error) {
+		nt := self.(*NamedTuple)
+		fields, err := fieldNames(nt.Base)
+		if err != nil {
+			return nil, err
+		}
+		d := py.NewDict()
+		for i, field := range fields {
+			if i < len(nt.Tuple) {
+				_ = d.Set(field, nt.Tuple[i])
+			}
+		}
+		return d, nil
+	}, 0, "Return a new dict which maps field names to their values."),
+	"__getnewargs__": py.MustNewMethod("__getnewargs__", func(self py.Object) (py.Object, error) {
+		return self.(*NamedTuple).Tuple.Copy(), nil
+	}, 0, "Return self as a plain tuple.  Used by copy and pickle."),
+}
+
+// Check interfaces
+var _ py.IGetDict = (*NamedTuple)(nil)
+var _ py.I__repr__ = (*NamedTuple)(nil)
+var _ py.I__eq__ = (*NamedTuple)(nil)
+var _ py.I__add__ = (*NamedTuple)(nil)
@@ -0,0 +1,411 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import collections
+from collections import OrderedDict, defaultdict, deque, namedtuple, Counter
+from libtest import *
+
+doc = "deque"
+d = deque()
+assert len(d) == 0
+assert not d
+assert repr(d) == "deque([])"
+d = deque([1, 2, 3])
+assert list(d) == [1, 2, 3]
+assert d
+d.append(4)
+d.appendleft(0)
+assert list(d) == [0, 1, 2, 3, 4]
+assert d.pop() == 4
+assert d.popleft() == 0
+assert list(d) == [1, 2, 3]
+d.extend([4, 5])
+d.extendleft([0, -1])
+assert list(d) == [-1, 0, 1, 2, 3, 4, 5]
+assert d[0] == -1
+assert d[-1] == 5
+d[1] = 10
+assert d[1] == 10
+del d[1]
+assert list(d) == [-1, 1, 2, 3, 4, 5]
+assertRaises(IndexError, lambda: d[100])
+assertRaises(IndexError, deque().pop)
+assertRaises(IndexError, deque().popleft)
+assert 3 in d
+assert 30 not in d
+assert repr(d) == "deque([-1, 1, 2, 3, 4, 5])"
+assert d.maxlen is None
+assert isinstance(d, deque)
+assertRaises(TypeError, hash, d)
+
+doc = "deque rotate"
+d = deque([1, 2, 3, 4, 5])
+d.rotate()
+assert list(d) == [5, 1, 2, 3, 4]
+d.rotate(2)
+assert list(d) == [3, 4, 5, 1, 2]
+d.rotate(-3)
+assert list(d) == [1, 2, 3, 4, 5]
+d.rotate(12)
+assert list(d) == [4, 5, 1, 2, 3]
+d = deque()
+d.rotate(3)
+assert list(d) == []
+
+doc = "deque maxlen"
+d = deque([1, 2, 3], maxlen=3)
+assert d.maxlen == 3
+assert repr(d) == "deque([1, 2, 3], maxlen=3)"
+d.append(4)
+assert list(d) == [2, 3, 4]
+d.appendleft(1)
+assert list(d) == [1, 2, 3]
+d.extend([4, 5, 6, 7])
+assert list(d) == [5, 6, 7]
+assertRaises(IndexError, d.insert, 0, 1)
+d = deque(range(10), 2)
+assert list(d) == [8, 9]
+d = deque([1, 2], maxlen=0)
+assert list(d) == []
+assertRaises(ValueError, deque, [], -1)
+
+doc = "deque methods"
+d = deque([1, 2, 1, 3, 1])
+assert d.count(1) == 3
+assert d.count(5) == 0
+assert d.index(1) == 0
+assert d.index(1, 1) == 2
+assert d.index(3) == 3
+assertRaises(ValueError, d.index, 1, 3, 4)
+assertRaises(ValueError, d.index, 5)
+d.remove(1)
+assert list(d) == [2, 1, 3, 1]
+assertRaises(ValueError, d.remove, 5)
+d.insert(1, 10)
+assert list(d) == [2, 10, 1, 3, 1]
+d.insert(-1, 20)
+assert list(d) == [2, 10, 1, 3, 20, 1]
+d.insert(100, 30)
+assert list(d) == [2, 10, 1, 3, 20, 1, 30]
+d.reverse()
+assert list(d) == [30, 1, 20, 3, 1, 10, 2]
+e = d.copy()
+assert e == d
+assert e is not d
+e.clear()
+assert len(e) == 0
+assert len(d) == 7
+assert list(d.__reversed__()) == [2, 10, 1, 3, 20, 1, 30]
+
+doc = "deque operators"
+assert deque([1, 2]) == deque([1, 2])
+assert deque([1, 2]) != deque([1, 3])
+assert deque([1, 2]) < deque([1, 3])
+assert deque([1, 2]) <= deque([1, 2])
+assert deque([2]) > deque([1, 3])
+assert deque([1, 2]) != [1, 2]
+assert list(deque([1]) + deque([2])) == [1, 2]
+assert list(deque([1, 2]) * 2) == [1, 2, 1, 2]
+d = deque([1])
+d += [2, 3]
+assert list(d) == [1, 2, 3]
+d *= 2
+assert list(d) == [1, 2, 3, 1, 2, 3]
+d = deque([1, 2, 3])
+def mutate():
+    for x in d:
+        d.append(x)
+assertRaises(RuntimeError, mutate)
+
+doc = "deque subclass"
+class Stack(deque):
+    def peek(self):
+        return self[-1]
+s = Stack([1, 2])
+s.append(3)
+assert s.peek() == 3
+assert isinstance(s, deque)
+assert repr(s) == "Stack([1, 2, 3])"
+
+doc = "OrderedDict"
+od = OrderedDict()
+assert repr(od) == "OrderedDict()"
+od["b"] = 1
+od["a"] = 2
+od["c"] = 3
+assert list(od) == ["b", "a", "c"]
+assert list(od.keys()) == ["b", "a", "c"]
+assert list(od.values()) == [1, 2, 3]
+assert list(od.items()) == [("b", 1), ("a", 2), ("c", 3)]
+assert repr(od) == "OrderedDict([('b', 1), ('a', 2), ('c', 3)])"
+assert isinstance(od, dict)
+assert isinstance(od, OrderedDict)
+assert od["a"] == 2
+assert od.get("z") is None
+assertRaises(KeyError, lambda: od["z"])
+od.move_to_end("b")
+assert list(od) == ["a", "c", "b"]
+od.move_to_end("b", last=False)
+assert list(od) == ["b", "a", "c"]
+assertRaises(KeyError, od.move_to_end, "z")
+assert od.popitem() == ("c", 3)
+assert od.popitem(last=False) == ("b", 1)
+assert list(od) == ["a"]
+assertRaises(KeyError, OrderedDict().popitem)
+assert list(od.__reversed__()) == ["a"]
+
+doc = "OrderedDict equality"
+a = OrderedDict([("x", 1), ("y", 2)])
+b = OrderedDict([("y", 2), ("x", 1)])
+assert a != b
+assert not (a == b)
+assert a == {"x": 1, "y": 2}
+assert {"y": 2, "x": 1} == b
+assert a == OrderedDict(x=1, y=2)
+c = a.copy()
+assert c == a
+assert type(c) is OrderedDict
+c["z"] = 3
+assert "z" not in a
+assert OrderedDict.fromkeys([1, 2]) == OrderedDict([(1, None), (2, None)])
+assert type(OrderedDict.fromkeys([1])) is OrderedDict
+assertRaises(TypeError, OrderedDict, [], [])
+assertRaises(TypeError, hash, a)
+del a["x"]
+assert list(a) == ["y"]
+
+doc = "defaultdict"
+dd = defaultdict(list)
+dd["a"].append(1)
+dd["a"].append(2)
+dd["b"].append(3)
+assert dd["a"] == [1, 2]
+assert dd == {"a": [1, 2], "b": [3]}
+assert dd.default_factory is list
+assert "c" not in dd
+assert dd.get("c") is None
+assert "c" not in dd
+assert isinstance(dd, dict)
+dd = defaultdict(int, {"x": 1}, y=2)
+dd["z"] += 1
+assert dd == {"x": 1, "y": 2, "z": 1}
+assert repr(defaultdict(int)) == "defaultdict(<class 'int'>, {})"
+dd = defaultdict()
+assert dd.default_factory is None
+assertRaises(KeyError, lambda: dd["a"])
+dd.default_factory = lambda: "default"
+assert dd["a"] == "default"
+assert repr(defaultdict(None, {1: 2})) == "defaultdict(None, {1: 2})"
+assertRaises(TypeError, defaultdict, 1)
+dd = defaultdict(list, a=[1])
+c = dd.copy()
+assert type(c) is defaultdict
+assert c.default_factory is list
+assert c == dd
+c["b"]
+assert "b" not in dd
+assert dd.__missing__("k") == []
+assert dd["k"] == []
+
+class NamedDefault(defaultdict):
+    def __missing__(self, key):
+        return key * 2
+nd = NamedDefault(list)
+assert nd[3] == 6
+assert 3 not in nd
+
+doc = "Counter"
+c = Counter()
+assert repr(c) == "Counter()"
+assert c["missing"] == 0
+assert "missing" not in c
+c = Counter(["a", "b", "a", "c", "a", "b"])
+assert c["a"] == 3
+assert c["b"] == 2
+assert c["c"] == 1
+assert isinstance(c, dict)
+assert repr(c) == "Counter({'a': 3, 'b': 2, 'c': 1})"
+assert c.most_common() == [("a", 3), ("b", 2), ("c", 1)]
+assert c.most_common(2) == [("a", 3), ("b", 2)]
+assert c.most_common(0) == []
+assert sorted(c.elements()) == ["a", "a", "a", "b", "b", "c"]
+assert c.total() == 6
+c.update(["a", "d"])
+assert c["a"] == 4
+assert c["d"] == 1
+c.update({"a": 2}, e=5)
+assert c["a"] == 6
+assert c["e"] == 5
+c.subtract(["a"])
+assert c["a"] == 5
+c.subtract({"b": 4})
+assert c["b"] == -2
+assert sorted(c.elements()) == ["a"] * 5 + ["c", "d"] + ["e"] * 5
+del c["b"]
+del c["missing"]
+assert "b" not in c
+assert Counter(a=2, b=1) == Counter({"a": 2, "b": 1})
+assert Counter(a=1) == Counter(a=1, b=0)
+assert Counter(a=1) != Counter(a=2)
+assert Counter(a=1) == {"a": 1}
+c = Counter(a=1, b=2)
+d = c.copy()
+assert type(d) is Counter
+assert d == c
+assertRaises(NotImplementedError, Counter.fromkeys, ["a"])
+assertRaises(TypeError, hash, c)
+
+doc = "Counter most_common order"
+c = Counter(["x", "y", "y", "z"])
+assert c.most_common() == [("y", 2), ("x", 1), ("z", 1)]
+
+doc = "Counter arithmetic"
+a = Counter(a=3, b=1, c=0)
+b = Counter(a=1, b=2, d=4)
+assert a + b == Counter(a=4, b=3, d=4)
+assert list((a + b).keys()) == ["a", "b", "d"]
+assert a - b == Counter(a=2)
+assert b - a == Counter(b=1, d=4)
+assert a | b == Counter(a=3, b=2, d=4)
+assert a & b == Counter(a=1, b=1)
+assert +Counter(a=1, b=-1, c=0) == Counter(a=1)
+assert -Counter(a=1, b=-1, c=0) == Counter(b=1)
+assert repr(Counter(a=1) - Counter(a=1)) == "Counter()"
+c = Counter(a=1)
+d = c
+c += Counter(a=1, b=-1)
+assert c is d
+assert c == Counter(a=2)
+c -= Counter(a=1)
+assert c == Counter(a=1)
+c |= Counter(b=2)
+assert c == Counter(a=1, b=2)
+c &= Counter(b=1)
+assert c == Counter(b=1)
+assert Counter(a=1) <= Counter(a=1, b=1)
+assert Counter(a=1) < Counter(a=1, b=1)
+assert not (Counter(a=1) < Counter(a=1))
+assert Counter(a=2) >= Counter(a=1)
+assert Counter(a=2) > Counter(a=1)
+assert not (Counter(a=1) > Counter(b=1))
+
+doc = "Counter subclass"
+class MyCounter(Counter):
+    pass
+mc = MyCounter(["a", "a"])
+assert mc["a"] == 2
+assert mc["z"] == 0
+assert repr(mc) == "MyCounter({'a': 2})"
+
+doc = "namedtuple"
+Point = namedtuple("Point", ["x", "y"])
+p = Point(1, 2)
+assert p.x == 1
+assert p.y == 2
+assert p[0] == 1
+assert p[-1] == 2
+assert p[0:1] == (1,)
+assert len(p) == 2
+assert p == (1, 2)
+assert (1, 2) == p
+assert p == Point(x=1, y=2)
+assert p != Point(2, 1)
+assert isinstance(p, tuple)
+assert isinstance(p, Point)
+assert repr(p) == "Point(x=1, y=2)"
+assert str(p) == "Point(x=1, y=2)"
+x, y = p
+assert x == 1 and y == 2
+assert list(p) == [1, 2]
+assert tuple(p) == (1, 2)
+assert hash(p) == hash((1, 2))
+assert {p: "a"}[(1, 2)] == "a"
+assert p + (3,) == (1, 2, 3)
+assert p + p == (1, 2, 1, 2)
+assert (0,) + p == (0, 1, 2)
+assert p * 2 == (1, 2, 1, 2)
+assert Point._fields == ("x", "y")
+assert Point.__doc__ == "Point(x, y)"
+assert Point.x.__doc__ == "Alias for field number 0"
+assert Point.__name__ == "Point"
+assert Point._field_defaults == {}
+assert p._asdict() == {"x": 1, "y": 2}
+assert list(p._asdict()) == ["x", "y"]
+q = p._replace(x=10)
+assert q == Point(10, 2)
+assert type(q) is Point
+assert p == (1, 2)
+assertRaises(ValueError, p._replace, z=1)
+assert Point._make([3, 4]) == Point(3, 4)
+assertRaises(TypeError, Point._make, [1])
+assert Point(**{"x": 5, "y": 6}) == Point(5, 6)
+assertRaises(AttributeError, setattr, p, "x", 3)
+assertRaises(AttributeError, setattr, p, "z", 3)
+
+doc = "namedtuple errors"
+assertRaisesText(TypeError, "missing 1 required positional argument: 'y'", Point, 1)
+assertRaisesText(TypeError, "missing 2 required positional arguments: 'x' and 'y'", Point)
+assertRaisesText(TypeError, "takes 3 positional arguments but 4 were given", Point, 1, 2, 3)
+assertRaisesText(TypeError, "unexpected keyword argument 'z'", Point, 1, 2, z=3)
+assertRaisesText(TypeError, "multiple values for argument 'x'", Point, 1, 2, x=3)
+assertRaises(ValueError, namedtuple, "Bad", ["x", "x"])
+assertRaises(ValueError, namedtuple, "Bad", ["_x"])
+assertRaises(ValueError, namedtuple, "Bad", ["class"])
+assertRaises(ValueError, namedtuple, "Bad", ["1x"])
+assertRaises(ValueError, namedtuple, "1Bad", ["x"])
+assertRaises(TypeError, namedtuple, "Bad", ["x"], defaults=[1, 2])
+
+doc = "namedtuple field names"
+P3 = namedtuple("P3", "x y z")
+assert P3._fields == ("x", "y", "z")
+P3 = namedtuple("P3", "x, y,z")
+assert P3._fields == ("x", "y", "z")
+R = namedtuple("R", ["abc", "def", "ghi", "abc", "_x"], rename=True)
+assert R._fields == ("abc", "_1", "ghi", "_3", "_4")
+
+doc = "namedtuple defaults"
+Node = namedtuple("Node", "value left right", defaults=[None, None])
+assert Node._field_defaults == {"left": None, "right": None}
+n = Node(1)
+assert n == (1, None, None)
+assert Node(1, right=2) == (1, None, 2)
+assertRaises(TypeError, Node)
+Account = namedtuple("Account", "owner balance", module="bank")
+assert Account.__module__ == "bank"
+
+doc = "namedtuple subclass"
+class Point3(namedtuple("Point3", "x y z")):
+    def norm1(self):
+        return abs(self.x) + abs(self.y) + abs(self.z)
+p = Point3(1, -2, 3)
+assert p.norm1() == 6
+assert repr(p) == "Point3(x=1, y=-2, z=3)"
+assert p._replace(x=0).norm1() == 5
+assert type(p._replace(x=0)) is Point3
+p.extra = 1
+assert p.extra == 1
+
+doc = "module"
+assert deque.__module__ == "collections"
+assert Counter.__module__ == "collections"
+assert collections.OrderedDict is OrderedDict
+
+doc = "str of dict subclasses"
+class Out:
+    def __init__(self):
+        self.text = ""
+    def write(self, s):
+        self.text += s
+for d, want in [
+    (OrderedDict([('b', 1), ('a', 2)]), "OrderedDict([('b', 1), ('a', 2)])"),
+    (defaultdict(int, {1: 2}), "defaultdict(<class 'int'>, {1: 2})"),
+    (Counter('aab'), "Counter({'a': 2, 'b': 1})"),
+]:
+    assert str(d) == want, str(d)
+    assert "%s" % (d,) == want
+    out = Out()
+    print(d, file=out)
+    assert out.text == want + "\n", out.text
+
+doc = "finished"
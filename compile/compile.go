@@ -72,7 +72,7 @@ const (
 type compiler struct {
 	Code        *py.Code // code being built up
 	Filename    string
-	Lineno      int // current line number
+	Position    ast.Pos // position in the source being compiled
 	OpCodes     Instructions
 	loops       loopstack
 	SymTable    *symtable.SymTable
@@ -104,10 +104,45 @@ func init() {
 // in addition to any features explicitly specified.
 func Compile(str, filename, mode string, futureFlags int, dont_inherit bool) (py.Object, error) {
 	// Parse Ast
-	Ast, err := parser.ParseString(str, mode)
+	Ast, err := parser.Parse(strings.NewReader(str), filename, mode)
 	if err != nil {
 		return nil, err
 	}
+	code, err := CompileAst(Ast, filename, futureFlags, dont_inherit)
+	if err != nil {
+		addSourceLine(err, str)
+		return nil, err
+	}
+	return code, nil
+}
+
+// Adds the line of str that a SyntaxError from the compiler refers
+// to, as only the parser has the source
+func addSourceLine(err error, str string) {
+	e, ok := err.(*py.Exception)
+	if !ok || e.Dict["text"] != py.String("") {
+		return
+	}
+	lineno, ok := e.Dict["lineno"].(py.Int)
+	lines := strings.Split(str, "\n")
+	if ok && lineno >= 1 && int(lineno) <= len(lines) {
+		e.Dict["text"] = py.String(lines[lineno-1])
+	}
+}
+
+// CompileAst compiles an Ast as returned by parser.Parse into a code
+// object.  The Ast may have been made or changed from Go or converted
+// from the python ast module's nodes, so it should be a Module,
+// Expression or Interactive node for the exec, eval and single modes.
+// The other arguments are as for Compile.
+func CompileAst(Ast ast.Mod, filename string, futureFlags int, dont_inherit bool) (*py.Code, error) {
+	switch Ast.(type) {
+	case *ast.Module, *ast.Expression, *ast.Interactive:
+	case nil:
+		return nil, py.ExceptionNewf(py.TypeError, "expected Module, Expression or Interactive node, got None")
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "expected Module, Expression or Interactive node, got %s", Ast.Type().Name)
+	}
 	// Make symbol table
 	SymTable, err := symtable.NewSymTable(Ast, filename)
 	if err != nil {
@@ -125,7 +160,7 @@ func Compile(str, filename, mode string, futureFlags int, dont_inherit bool) (py
 // Make a new compiler object with empty code object
 func newCompiler(parent *compiler, scopeType compilerScopeType) *compiler {
 	code := &py.Code{
-		Firstlineno: 1,          // FIXME
+		Firstlineno: 1,
 		Name:        "<module>", // FIXME
 	}
 	c := &compiler{
@@ -145,13 +180,22 @@ func newCompiler(parent *compiler, scopeType compilerScopeType) *compiler {
 // Panics abount a syntax error on this ast node
 func (c *compiler) panicSyntaxErrorf(Ast ast.Ast, format string, a ...interface{}) {
 	err := py.ExceptionNewf(py.SyntaxError, format, a...)
-	err = py.MakeSyntaxError(err, c.Filename, Ast.GetLineno(), Ast.GetColOffset(), "")
+	if Ast.GetEndLineno() != 0 {
+		err = py.MakeSyntaxErrorRange(err, c.Filename, Ast.GetLineno(), Ast.GetColOffset(), Ast.GetEndLineno(), Ast.GetEndColOffset(), "")
+	} else {
+		err = py.MakeSyntaxError(err, c.Filename, Ast.GetLineno(), Ast.GetColOffset(), "")
+	}
 	panic(err)
 }
 
-// Sets Lineno from an ast node
-func (c *compiler) SetLineno(node ast.Ast) {
-	c.Lineno = node.GetLineno()
+// Sets Position from an ast node
+func (c *compiler) SetPosition(node ast.Ast) {
+	c.Position = ast.Pos{
+		Lineno:       node.GetLineno(),
+		ColOffset:    node.GetColOffset(),
+		EndLineno:    node.GetEndLineno(),
+		EndColOffset: node.GetEndColOffset(),
+	}
 }
 
 // Create a new compiler object at Ast, using private for name mangling
@@ -195,13 +239,16 @@ func (c *compiler) compileAst(Ast ast.Ast, filename string, futureFlags int, don
 	code.Cellvars = append(code.Cellvars, SymTable.Find(symtable.ScopeCell, 0)...)
 	code.Freevars = append(code.Freevars, SymTable.Find(symtable.ScopeFree, symtable.DefFreeClass)...)
 	code.Flags = c.codeFlags(SymTable) | int32(futureFlags&py.CO_COMPILER_FLAGS_MASK)
+	code.Firstlineno = firstLineno(Ast)
 	valueOnStack := false
-	c.SetLineno(Ast)
+	c.SetPosition(Ast)
 	switch node := Ast.(type) {
 	case *ast.Module:
+		c.setupAnnotations(node.Body)
 		c.Stmts(c.docString(node.Body, false))
 	case *ast.Interactive:
 		c.interactive = true
+		c.setupAnnotations(node.Body)
 		c.Stmts(node.Body)
 	case *ast.Expression:
 		c.Expr(node.Body)
@@ -219,6 +266,10 @@ func (c *compiler) compileAst(Ast ast.Ast, filename string, futureFlags int, don
 		code.Name = string(node.Name)
 		c.setQualname()
 		c.Stmts(c.docString(node.Body, true))
+	case *ast.AsyncFunctionDef:
+		code.Name = string(node.Name)
+		c.setQualname()
+		c.Stmts(c.docString(node.Body, true))
 	case *ast.ClassDef:
 		code.Name = string(node.Name)
 		/* load (global) __name__ ... */
@@ -234,6 +285,7 @@ func (c *compiler) compileAst(Ast ast.Ast, filename string, futureFlags int, don
 		c.NameOp("__qualname__", ast.Store)
 
 		/* compile the body proper */
+		c.setupAnnotations(node.Body)
 		c.Stmts(c.docString(node.Body, false))
 
 		if SymTable.NeedsClassClosure {
@@ -291,13 +343,55 @@ func (c *compiler) compileAst(Ast ast.Ast, filename string, futureFlags int, don
 		}
 		c.Op(vm.RETURN_VALUE)
 	}
+	c.optimize()
 	code.Code = c.OpCodes.Assemble()
 	code.Stacksize = int32(c.OpCodes.StackDepth())
 	code.Nlocals = int32(len(code.Varnames))
-	code.Lnotab = string(c.OpCodes.Lnotab())
+	code.Lnotab = string(c.OpCodes.Lnotab(int(code.Firstlineno)))
+	code.Positions = c.OpCodes.Positions()
 	return nil
 }
 
+// Returns the line number the code for Ast starts on which is the
+// line of the first decorator of a decorated function or class
+func firstLineno(Ast ast.Ast) int32 {
+	var decorators []ast.Expr
+	switch node := Ast.(type) {
+	case *ast.Module, *ast.Interactive, *ast.Expression:
+		return 1
+	case *ast.FunctionDef:
+		decorators = node.DecoratorList
+	case *ast.AsyncFunctionDef:
+		decorators = node.DecoratorList
+	case *ast.ClassDef:
+		decorators = node.DecoratorList
+	}
+	if len(decorators) > 0 {
+		return int32(decorators[0].GetLineno())
+	}
+	return int32(Ast.GetLineno())
+}
+
+// Makes __annotations__ if the module or class body has annotated
+// assignments, not counting those in nested functions and classes
+func (c *compiler) setupAnnotations(body []ast.Stmt) {
+	found := false
+	for _, stmt := range body {
+		ast.Walk(stmt, func(node ast.Ast) bool {
+			switch node.(type) {
+			case *ast.AnnAssign:
+				found = true
+			case *ast.FunctionDef, *ast.AsyncFunctionDef, *ast.ClassDef:
+				return false
+			}
+			return !found
+		})
+	}
+	if found {
+		c.Op(vm.SETUP_ANNOTATIONS)
+	}
+}
+
 // Check for docstring as first Expr in body and remove it and set the
 // first constant if found if fn is set, or set __doc__ if it isn't
 func (c *compiler) docString(body []ast.Stmt, fn bool) []ast.Stmt {
@@ -309,6 +403,9 @@ func (c *compiler) docString(body []ast.Stmt, fn bool) []ast.Stmt {
 			}
 		}
 	}
+	if py.Optimize >= 2 {
+		docstring = nil
+	}
 	if fn {
 		if docstring != nil {
 			c.Const(docstring.S)
@@ -366,7 +463,7 @@ func (c *compiler) Index(Id string, Names *[]string) uint32 {
 	if i >= 0 {
 		return uint32(i)
 	}
-	*Names = append(*Names, Id)
+	*Names = append(*Names, py.InternString(Id))
 	return uint32(len(*Names) - 1)
 }
 
@@ -390,7 +487,7 @@ func (c *compiler) OpArg(Op vm.OpCode, Arg uint32) {
 		panic("OpArg called with an instruction which doesn't take an Arg")
 	}
 	instr := &OpArg{Op: Op, Arg: Arg}
-	instr.SetLineno(c.Lineno)
+	instr.SetPosition(c.Position)
 	c.OpCodes.Add(instr)
 }
 
@@ -400,7 +497,7 @@ func (c *compiler) Op(op vm.OpCode) {
 		panic("Op called with an instruction which takes an Arg")
 	}
 	instr := &Op{Op: op}
-	instr.SetLineno(c.Lineno)
+	instr.SetPosition(c.Position)
 	c.OpCodes.Add(instr)
 }
 
@@ -422,12 +519,12 @@ func (c *compiler) Jump(Op vm.OpCode, Dest *Label) {
 	switch Op {
 	case vm.JUMP_IF_FALSE_OR_POP, vm.JUMP_IF_TRUE_OR_POP, vm.JUMP_ABSOLUTE, vm.POP_JUMP_IF_FALSE, vm.POP_JUMP_IF_TRUE, vm.CONTINUE_LOOP: // Absolute
 		instr = &JumpAbs{OpArg: OpArg{Op: Op}, Dest: Dest}
-	case vm.JUMP_FORWARD, vm.SETUP_WITH, vm.FOR_ITER, vm.SETUP_LOOP, vm.SETUP_EXCEPT, vm.SETUP_FINALLY:
+	case vm.JUMP_FORWARD, vm.SETUP_WITH, vm.SETUP_ASYNC_WITH, vm.FOR_ITER, vm.SETUP_LOOP, vm.SETUP_EXCEPT, vm.SETUP_FINALLY:
 		instr = &JumpRel{OpArg: OpArg{Op: Op}, Dest: Dest}
 	default:
 		panic("Jump called with non jump instruction")
 	}
-	instr.SetLineno(c.Lineno)
+	instr.SetPosition(c.Position)
 	c.OpCodes.Add(instr)
 }
 
@@ -448,6 +545,9 @@ func (c *compiler) getRefType(name string) symtable.Scope {
 
 // makeClosure constructs the function or closure for a func/class/lambda etc
 func (c *compiler) makeClosure(code *py.Code, args uint32, child *compiler, qualname string) {
+	// The argument counts are only known now so map any cells
+	// which are arguments here
+	code.Cell2arg = py.MakeCell2arg(code.Argcount, code.Kwonlyargcount, code.Flags, code.Varnames, code.Cellvars)
 	free := uint32(len(code.Freevars))
 
 	if free == 0 {
@@ -474,6 +574,9 @@ func (c *compiler) makeClosure(code *py.Code, args uint32, child *compiler, qual
 			arg = c.FindId(name, c.Code.Cellvars)
 		} else { /* (reftype == FREE) */
 			arg = c.FindId(name, c.Code.Freevars)
+			if arg >= 0 {
+				arg += len(c.Code.Cellvars)
+			}
 		}
 		if arg < 0 {
 			panic(fmt.Sprintf("compile: makeClosure: lookup %q in %q %v %v\nfreevars of %q: %v\n", name, c.SymTable.Name, reftype, arg, code.Name, code.Freevars))
@@ -499,6 +602,9 @@ func (c *compiler) codeFlags(st *symtable.SymTable) (flags int32) {
 		if st.Generator {
 			flags |= py.CO_GENERATOR
 		}
+		if st.Coroutine {
+			flags |= py.CO_COROUTINE
+		}
 		if st.Varargs {
 			flags |= py.CO_VARARGS
 		}
@@ -555,9 +661,13 @@ func (c *compiler) setQualname() {
 // Compile a function
 func (c *compiler) compileFunc(compilerScope compilerScopeType, Ast ast.Ast, Args *ast.Arguments, DecoratorList []ast.Expr, Returns ast.Expr) {
 	newC := c.newCompilerScope(compilerScope, Ast, "")
-	newC.Code.Argcount = int32(len(Args.Args))
+	newC.Code.Argcount = int32(len(Args.Posonlyargs) + len(Args.Args))
+	newC.Code.Posonlyargcount = int32(len(Args.Posonlyargs))
 	newC.Code.Kwonlyargcount = int32(len(Args.Kwonlyargs))
 
+	// Load decorators onto stack
+	c.Exprs(DecoratorList)
+
 	// Defaults
 	c.Exprs(Args.Defaults)
 
@@ -565,9 +675,14 @@ func (c *compiler) compileFunc(compilerScope compilerScopeType, Ast ast.Ast, Arg
 	if len(Args.KwDefaults) > len(Args.Kwonlyargs) {
 		panic("compile: more KwDefaults than Kwonlyargs")
 	}
+	kwdefaults := uint32(0)
 	for i := range Args.KwDefaults {
+		if Args.KwDefaults[i] == nil {
+			continue
+		}
 		c.LoadConst(py.String(Args.Kwonlyargs[i].Arg))
 		c.Expr(Args.KwDefaults[i])
+		kwdefaults++
 	}
 
 	// Annotations
@@ -580,6 +695,7 @@ func (c *compiler) compileFunc(compilerScope compilerScopeType, Ast ast.Ast, Arg
 			}
 		}
 	}
+	addAnnotation(Args.Posonlyargs...)
 	addAnnotation(Args.Args...)
 	addAnnotation(Args.Vararg)
 	addAnnotation(Args.Kwonlyargs...)
@@ -594,12 +710,8 @@ func (c *compiler) compileFunc(compilerScope compilerScopeType, Ast ast.Ast, Arg
 		c.LoadConst(annotations)
 	}
 
-	// Load decorators onto stack
-	c.Exprs(DecoratorList)
-
 	// Make function or closure, leaving it on the stack
 	posdefaults := uint32(len(Args.Defaults))
-	kwdefaults := uint32(len(Args.KwDefaults))
 	args := uint32(posdefaults + (kwdefaults << 8) + (num_annotations << 16))
 	c.makeClosure(newC.Code, args, newC, newC.qualname)
 
@@ -724,6 +836,150 @@ func (c *compiler) with(node *ast.With, pos int) {
 	c.Op(vm.END_FINALLY)
 }
 
+/*
+   Implements the async with statement.
+
+   The semantics outlined in PEP 492 are as follows:
+
+   async with EXPR as VAR:
+       BLOCK
+
+   It is implemented roughly as:
+
+   context = EXPR
+   exit = context.__aexit__  # not calling it
+   value = await context.__aenter__()
+   try:
+       VAR = value  # if VAR present in the syntax
+       BLOCK
+   finally:
+       if an exception was raised:
+       exc = copy of (exception, instance, traceback)
+       else:
+       exc = (None, None, None)
+       if not (await exit(*exc)):
+           raise
+*/
+func (c *compiler) asyncWith(node *ast.AsyncWith, pos int) {
+	item := node.Items[pos]
+	finally := new(Label)
+
+	/* Evaluate EXPR */
+	c.Expr(item.ContextExpr)
+	c.Op(vm.BEFORE_ASYNC_WITH)
+	c.Op(vm.GET_AWAITABLE)
+	c.LoadConst(py.None)
+	c.Op(vm.YIELD_FROM)
+	c.Jump(vm.SETUP_ASYNC_WITH, finally)
+
+	/* SETUP_ASYNC_WITH pushes a finally block. */
+	c.loops.Push(loop{Type: finallyTryLoop})
+	if item.OptionalVars != nil {
+		c.Expr(item.OptionalVars)
+	} else {
+		/* Discard result from context.__aenter__() */
+		c.Op(vm.POP_TOP)
+	}
+
+	pos++
+	if pos == len(node.Items) {
+		/* BLOCK code */
+		c.Stmts(node.Body)
+	} else {
+		c.asyncWith(node, pos)
+	}
+
+	/* End of try block; start the finally block */
+	c.Op(vm.POP_BLOCK)
+	c.loops.Pop()
+	c.LoadConst(py.None)
+
+	/* Finally block starts; context.__aexit__ is on the stack
+	   under the exception or return information. Call it and
+	   await the result. */
+	c.Label(finally)
+	c.Op(vm.WITH_CLEANUP_START)
+	c.Op(vm.GET_AWAITABLE)
+	c.LoadConst(py.None)
+	c.Op(vm.YIELD_FROM)
+	c.Op(vm.WITH_CLEANUP_FINISH)
+
+	/* Finally block ends. */
+	c.Op(vm.END_FINALLY)
+}
+
+/*
+   Implements the async for statement.
+
+   async for TARGET in ITER:
+       BLOCK
+   else:
+       ELSE
+
+   It is implemented roughly as:
+
+   iter = ITER.__aiter__()
+   while True:
+       try:
+           TARGET = await iter.__anext__()
+       except StopAsyncIteration:
+           break to ELSE
+       BLOCK
+*/
+func (c *compiler) asyncFor(node *ast.AsyncFor) {
+	except := new(Label)
+	tryCleanup := new(Label)
+	afterTry := new(Label)
+	afterLoop := new(Label)
+	afterLoopElse := new(Label)
+	end := new(Label)
+
+	c.Jump(vm.SETUP_LOOP, afterLoop)
+	c.Expr(node.Iter)
+	c.Op(vm.GET_AITER)
+
+	try := c.NewLabel()
+	c.loops.Push(loop{Start: try, End: afterLoop, Type: loopLoop})
+	c.Jump(vm.SETUP_EXCEPT, except)
+	c.loops.Push(loop{Type: exceptLoop})
+	c.Op(vm.GET_ANEXT)
+	c.LoadConst(py.None)
+	c.Op(vm.YIELD_FROM)
+	c.Expr(node.Target)
+	c.Op(vm.POP_BLOCK)
+	c.loops.Pop()
+	c.Jump(vm.JUMP_FORWARD, afterTry)
+
+	c.Label(except)
+	c.Op(vm.DUP_TOP)
+	c.OpName(vm.LOAD_GLOBAL, "StopAsyncIteration")
+	c.OpArg(vm.COMPARE_OP, vm.PyCmp_EXC_MATCH)
+	c.Jump(vm.POP_JUMP_IF_FALSE, tryCleanup)
+
+	c.Op(vm.POP_TOP)
+	c.Op(vm.POP_TOP)
+	c.Op(vm.POP_TOP)
+	c.Op(vm.POP_EXCEPT) // for SETUP_EXCEPT
+	c.Op(vm.POP_BLOCK)  // for SETUP_LOOP
+	c.Jump(vm.JUMP_ABSOLUTE, afterLoopElse)
+
+	c.Label(tryCleanup)
+	c.Op(vm.END_FINALLY)
+
+	c.Label(afterTry)
+	c.Stmts(node.Body)
+	c.Jump(vm.JUMP_ABSOLUTE, try)
+	c.loops.Pop()
+
+	c.Label(afterLoop)
+	c.Jump(vm.JUMP_ABSOLUTE, end)
+
+	c.Label(afterLoopElse)
+	c.Stmts(node.Orelse)
+
+	c.Label(end)
+}
+
 /* Code generated for "try: <body> finally: <finalbody>" is as follows:
 
         SETUP_FINALLY           L
@@ -893,6 +1149,357 @@ func (c *compiler) try(node *ast.Try) {
 	}
 }
 
+// State for compiling the pattern of a case block
+type patternContext struct {
+	failPop          []*Label // failPop[n] pops n items then fails the match
+	onTop            int      // number of items failPop needs to pop
+	stores           []string // names bound so far
+	allowIrrefutable bool     // set if irrefutable patterns are allowed
+}
+
+// Jumps with op to fail the match, popping the items on the stack
+// belonging to the pattern
+func (c *compiler) jumpToFailPop(pc *patternContext, op vm.OpCode) {
+	for len(pc.failPop) <= pc.onTop {
+		pc.failPop = append(pc.failPop, new(Label))
+	}
+	c.Jump(op, pc.failPop[pc.onTop])
+}
+
+// Emits the code to pop the items on failure which jumpToFailPop
+// jumps to
+func (c *compiler) emitFailPop(pc *patternContext) {
+	if len(pc.failPop) == 0 {
+		return
+	}
+	for i := len(pc.failPop) - 1; i > 0; i-- {
+		c.Label(pc.failPop[i])
+		c.Op(vm.POP_TOP)
+	}
+	c.Label(pc.failPop[0])
+	pc.failPop = nil
+}
+
+// Compiles a match statement
+//
+// The subject stays on the stack while the cases are tried.  Each
+// case matches its pattern against a copy, with any failure jumping
+// to the code which pops the items the pattern left on the stack
+// before trying the next case.
+func (c *compiler) match(node *ast.Match) {
+	c.Expr(node.Subject)
+	end := new(Label)
+	for i, mc := range node.Cases {
+		pc := &patternContext{
+			allowIrrefutable: mc.Guard != nil || i == len(node.Cases)-1,
+			onTop:            1,
+		}
+		c.SetPosition(mc.Pattern)
+		c.Op(vm.DUP_TOP)
+		c.pattern(mc.Pattern, pc)
+		if mc.Guard != nil {
+			c.Expr(mc.Guard)
+			c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+		}
+		c.Op(vm.POP_TOP)
+		c.Stmts(mc.Body)
+		c.Jump(vm.JUMP_FORWARD, end)
+		c.emitFailPop(pc)
+	}
+	c.Op(vm.POP_TOP)
+	c.Label(end)
+}
+
+// Compiles a sub-pattern of pattern in which irrefutable patterns are
+// always allowed
+func (c *compiler) subpattern(p ast.Pattern, pc *patternContext) {
+	allowIrrefutable := pc.allowIrrefutable
+	pc.allowIrrefutable = true
+	c.pattern(p, pc)
+	pc.allowIrrefutable = allowIrrefutable
+}
+
+// Pops TOS and stores it in name, or just pops it if name is empty
+func (c *compiler) patternCapture(node ast.Ast, name ast.Identifier, pc *patternContext) {
+	if name == "" {
+		c.Op(vm.POP_TOP)
+	} else {
+		for _, store := range pc.stores {
+			if store == string(name) {
+				c.panicSyntaxErrorf(node, "multiple assignments to name '%s' in pattern", name)
+			}
+		}
+		pc.stores = append(pc.stores, string(name))
+		c.NameOp(string(name), ast.Store)
+	}
+	pc.onTop--
+}
+
+// Compiles a pattern which matches against TOS
+//
+// On success TOS is popped and on failure the code jumps to the
+// failure label of pc.
+func (c *compiler) pattern(p ast.Pattern, pc *patternContext) {
+	c.SetPosition(p)
+	switch node := p.(type) {
+	case *ast.MatchValue:
+		// Value Expr
+		c.Expr(node.Value)
+		c.OpArg(vm.COMPARE_OP, vm.PyCmp_EQ)
+		pc.onTop--
+		c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	case *ast.MatchSingleton:
+		// Value Singleton
+		c.LoadConst(node.Value)
+		c.OpArg(vm.COMPARE_OP, vm.PyCmp_IS)
+		pc.onTop--
+		c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	case *ast.MatchAs:
+		// Pattern Pattern
+		// Name    Identifier
+		if node.Pattern == nil {
+			if !pc.allowIrrefutable {
+				if node.Name == "" {
+					c.panicSyntaxErrorf(node, "wildcard makes remaining patterns unreachable")
+				}
+				c.panicSyntaxErrorf(node, "name capture '%s' makes remaining patterns unreachable", node.Name)
+			}
+			c.patternCapture(node, node.Name, pc)
+			return
+		}
+		c.Op(vm.DUP_TOP)
+		pc.onTop++
+		c.subpattern(node.Pattern, pc)
+		c.patternCapture(node, node.Name, pc)
+	case *ast.MatchStar:
+		// Name Identifier
+		c.patternCapture(node, node.Name, pc)
+	case *ast.MatchOr:
+		// Patterns []Pattern
+		c.patternOr(node, pc)
+	case *ast.MatchSequence:
+		// Patterns []Pattern
+		c.patternSequence(node, pc)
+	case *ast.MatchMapping:
+		// Keys     []Expr
+		// Patterns []Pattern
+		// Rest     Identifier
+		c.patternMapping(node, pc)
+	case *ast.MatchClass:
+		// Cls         Expr
+		// Patterns    []Pattern
+		// KwdAttrs    []Identifier
+		// KwdPatterns []Pattern
+		c.patternClass(node, pc)
+	default:
+		panic(fmt.Sprintf("Unknown Pattern: %v", p))
+	}
+}
+
+// Compiles an or pattern, trying each alternative against a copy of
+// the subject in turn
+func (c *compiler) patternOr(node *ast.MatchOr, pc *patternContext) {
+	end := new(Label)
+	var altStores []string
+	for i, alt := range node.Patterns {
+		altPc := &patternContext{
+			stores:           append([]string(nil), pc.stores...),
+			allowIrrefutable: pc.allowIrrefutable && i == len(node.Patterns)-1,
+			onTop:            1,
+		}
+		c.Op(vm.DUP_TOP)
+		c.pattern(alt, altPc)
+		stores := altPc.stores[len(pc.stores):]
+		if i == 0 {
+			altStores = stores
+		} else if !sameNames(stores, altStores) {
+			c.panicSyntaxErrorf(alt, "alternative patterns bind different names")
+		}
+		c.Jump(vm.JUMP_FORWARD, end)
+		c.emitFailPop(altPc)
+	}
+	// All the alternatives failed
+	c.Op(vm.POP_TOP)
+	pc.onTop--
+	c.jumpToFailPop(pc, vm.JUMP_ABSOLUTE)
+	c.Label(end)
+	c.Op(vm.POP_TOP)
+	pc.stores = append(pc.stores, altStores...)
+}
+
+// Reports whether a and b contain the same names in any order
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	names := make(map[string]bool, len(a))
+	for _, name := range a {
+		names[name] = true
+	}
+	for _, name := range b {
+		if !names[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Compiles a sequence pattern
+func (c *compiler) patternSequence(node *ast.MatchSequence, pc *patternContext) {
+	n := len(node.Patterns)
+	star := -1
+	for i, p := range node.Patterns {
+		if _, ok := p.(*ast.MatchStar); ok {
+			if star >= 0 {
+				c.panicSyntaxErrorf(p, "multiple starred names in sequence pattern")
+			}
+			star = i
+		}
+	}
+	c.Op(vm.MATCH_SEQUENCE)
+	c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	if star < 0 {
+		c.Op(vm.GET_LEN)
+		c.LoadConst(py.Int(n))
+		c.OpArg(vm.COMPARE_OP, vm.PyCmp_EQ)
+		c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	} else if n > 1 {
+		c.Op(vm.GET_LEN)
+		c.LoadConst(py.Int(n - 1))
+		c.OpArg(vm.COMPARE_OP, vm.PyCmp_GE)
+		c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	}
+	if n == 0 {
+		c.Op(vm.POP_TOP)
+		pc.onTop--
+		return
+	}
+	if star < 0 {
+		c.OpArg(vm.UNPACK_SEQUENCE, uint32(n))
+	} else {
+		c.OpArg(vm.UNPACK_EX, uint32(star+((n-star-1)<<8)))
+	}
+	pc.onTop += n - 1
+	for _, p := range node.Patterns {
+		c.subpattern(p, pc)
+	}
+}
+
+// Compiles a mapping pattern
+func (c *compiler) patternMapping(node *ast.MatchMapping, pc *patternContext) {
+	c.Op(vm.MATCH_MAPPING)
+	c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	n := len(node.Keys)
+	if n == 0 && node.Rest == "" {
+		c.Op(vm.POP_TOP)
+		pc.onTop--
+		return
+	}
+	if n == 0 {
+		c.LoadConst(py.Tuple{})
+		c.Op(vm.COPY_DICT_WITHOUT_KEYS)
+		c.Op(vm.ROT_TWO)
+		c.Op(vm.POP_TOP)
+		c.patternCapture(node, node.Rest, pc)
+		return
+	}
+	c.Op(vm.GET_LEN)
+	c.LoadConst(py.Int(n))
+	c.OpArg(vm.COMPARE_OP, vm.PyCmp_GE)
+	c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	seen := py.NewDict()
+	for _, key := range node.Keys {
+		var value py.Object
+		switch x := key.(type) {
+		case *ast.Num:
+			value = x.N
+		case *ast.Str:
+			value = x.S
+		case *ast.Bytes:
+			value = x.S
+		case *ast.NameConstant:
+			value = x.Value
+		case *ast.JoinedStr:
+			c.panicSyntaxErrorf(key, "patterns may only match literals and attribute lookups")
+		}
+		if value != nil {
+			if _, found, _ := seen.Get(value); found {
+				repr, err := py.ReprAsString(value)
+				if err != nil {
+					panic(err)
+				}
+				c.panicSyntaxErrorf(key, "mapping pattern checks duplicate key (%s)", repr)
+			}
+			_ = seen.Set(value, py.None)
+		}
+		c.Expr(key)
+	}
+	c.OpArg(vm.BUILD_TUPLE, uint32(n))
+	c.Op(vm.MATCH_KEYS)
+	pc.onTop += 2
+	c.Op(vm.DUP_TOP)
+	c.LoadConst(py.None)
+	c.OpArg(vm.COMPARE_OP, vm.PyCmp_IS_NOT)
+	c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	// Stack is subject, keys, values
+	c.Op(vm.ROT_THREE)
+	if node.Rest != "" {
+		c.Op(vm.COPY_DICT_WITHOUT_KEYS)
+		c.Op(vm.ROT_TWO)
+		c.Op(vm.POP_TOP)
+		c.Op(vm.ROT_TWO)
+		// Stack is rest, values
+		pc.onTop--
+	} else {
+		c.Op(vm.POP_TOP)
+		c.Op(vm.POP_TOP)
+		// Stack is values
+		pc.onTop -= 2
+	}
+	c.OpArg(vm.UNPACK_SEQUENCE, uint32(n))
+	pc.onTop += n - 1
+	for _, p := range node.Patterns {
+		c.subpattern(p, pc)
+	}
+	if node.Rest != "" {
+		c.patternCapture(node, node.Rest, pc)
+	}
+}
+
+// Compiles a class pattern
+func (c *compiler) patternClass(node *ast.MatchClass, pc *patternContext) {
+	names := make(py.Tuple, len(node.KwdAttrs))
+	for i, attr := range node.KwdAttrs {
+		for _, name := range names[:i] {
+			if name == py.String(attr) {
+				c.panicSyntaxErrorf(node.KwdPatterns[i], "attribute name repeated in class pattern: %s", attr)
+			}
+		}
+		names[i] = py.String(attr)
+	}
+	c.Expr(node.Cls)
+	c.LoadConst(names)
+	c.OpArg(vm.MATCH_CLASS, uint32(len(node.Patterns)))
+	c.Op(vm.DUP_TOP)
+	c.LoadConst(py.None)
+	c.OpArg(vm.COMPARE_OP, vm.PyCmp_IS_NOT)
+	c.jumpToFailPop(pc, vm.POP_JUMP_IF_FALSE)
+	n := len(node.Patterns) + len(node.KwdPatterns)
+	if n == 0 {
+		c.Op(vm.POP_TOP)
+		pc.onTop--
+		return
+	}
+	c.OpArg(vm.UNPACK_SEQUENCE, uint32(n))
+	pc.onTop += n - 1
+	for _, p := range node.Patterns {
+		c.subpattern(p, pc)
+	}
+	for _, p := range node.KwdPatterns {
+		c.subpattern(p, pc)
+	}
+}
+
 /* The IMPORT_NAME opcode was already generated.  This function
    merely needs to bind the result to a name.
 
@@ -973,6 +1580,66 @@ func (c *compiler) importFrom(node *ast.ImportFrom) {
 	c.Op(vm.POP_TOP)
 }
 
+// Compiles an annotated assignment
+//
+// Annotations are only evaluated in modules and classes, where those
+// of simple names are stored in __annotations__.  Without a value the
+// parts of an attribute or subscript target are evaluated but not
+// the target itself.
+func (c *compiler) annAssign(node *ast.AnnAssign) {
+	if node.Value != nil {
+		c.Expr(node.Value)
+		c.Expr(node.Target)
+	}
+	evaluate := c.scopeType == compilerScopeModule || c.scopeType == compilerScopeClass
+	switch target := node.Target.(type) {
+	case *ast.Name:
+		if node.Simple != 0 && evaluate {
+			c.Expr(node.Annotation)
+			c.NameOp("__annotations__", ast.Load)
+			c.LoadConst(py.String(target.Id))
+			c.Op(vm.STORE_SUBSCR)
+			return
+		}
+	case *ast.Attribute:
+		if node.Value == nil {
+			c.exprAndPop(target.Value)
+		}
+	case *ast.Subscript:
+		if node.Value == nil {
+			c.exprAndPop(target.Value)
+			c.sliceAndPop(target.Slice)
+		}
+	}
+	if evaluate {
+		c.exprAndPop(node.Annotation)
+	}
+}
+
+// Evaluates expr if it isn't nil, discarding the result
+func (c *compiler) exprAndPop(expr ast.Expr) {
+	if expr != nil {
+		c.Expr(expr)
+		c.Op(vm.POP_TOP)
+	}
+}
+
+// Evaluates the parts of the slice s, discarding the results
+func (c *compiler) sliceAndPop(s ast.Slicer) {
+	switch node := s.(type) {
+	case *ast.Index:
+		c.exprAndPop(node.Value)
+	case *ast.Slice:
+		c.exprAndPop(node.Lower)
+		c.exprAndPop(node.Upper)
+		c.exprAndPop(node.Step)
+	case *ast.ExtSlice:
+		for _, dim := range node.Dims {
+			c.sliceAndPop(dim)
+		}
+	}
+}
+
 // Compile statements
 func (c *compiler) Stmts(stmts []ast.Stmt) {
 	for _, stmt := range stmts {
@@ -982,7 +1649,7 @@ func (c *compiler) Stmts(stmts []ast.Stmt) {
 
 // Compile statement
 func (c *compiler) Stmt(stmt ast.Stmt) {
-	c.SetLineno(stmt)
+	c.SetPosition(stmt)
 	switch node := stmt.(type) {
 	case *ast.FunctionDef:
 		// Name          Identifier
@@ -992,6 +1659,14 @@ func (c *compiler) Stmt(stmt ast.Stmt) {
 		// Returns       Expr
 		c.compileFunc(compilerScopeFunction, stmt, node.Args, node.DecoratorList, node.Returns)
 		c.NameOp(string(node.Name), ast.Store)
+	case *ast.AsyncFunctionDef:
+		// Name          Identifier
+		// Args          *Arguments
+		// Body          []Stmt
+		// DecoratorList []Expr
+		// Returns       Expr
+		c.compileFunc(compilerScopeFunction, stmt, node.Args, node.DecoratorList, node.Returns)
+		c.NameOp(string(node.Name), ast.Store)
 
 	case *ast.ClassDef:
 		// Name          Identifier
@@ -1026,6 +1701,12 @@ func (c *compiler) Stmt(stmt ast.Stmt) {
 			}
 			c.Expr(target)
 		}
+	case *ast.AnnAssign:
+		// Target     Expr
+		// Annotation Expr
+		// Value      Expr
+		// Simple     int
+		c.annAssign(node)
 	case *ast.AugAssign:
 		// Target Expr
 		// Op     OperatorNumber
@@ -1046,6 +1727,8 @@ func (c *compiler) Stmt(stmt ast.Stmt) {
 			op = vm.INPLACE_SUBTRACT
 		case ast.Mult:
 			op = vm.INPLACE_MULTIPLY
+		case ast.MatMult:
+			op = vm.INPLACE_MATRIX_MULTIPLY
 		case ast.Div:
 			op = vm.INPLACE_TRUE_DIVIDE
 		case ast.Modulo:
@@ -1125,10 +1808,26 @@ func (c *compiler) Stmt(stmt ast.Stmt) {
 		c.Label(orelse)
 		c.Stmts(node.Orelse)
 		c.Label(endif)
+	case *ast.AsyncFor:
+		// Target Expr
+		// Iter   Expr
+		// Body   []Stmt
+		// Orelse []Stmt
+		if !c.SymTable.Coroutine {
+			c.panicSyntaxErrorf(node, "'async for' outside async function")
+		}
+		c.asyncFor(node)
 	case *ast.With:
 		// Items []*WithItem
 		// Body  []Stmt
 		c.with(node, 0)
+	case *ast.AsyncWith:
+		// Items []*WithItem
+		// Body  []Stmt
+		if !c.SymTable.Coroutine {
+			c.panicSyntaxErrorf(node, "'async with' outside async function")
+		}
+		c.asyncWith(node, 0)
 	case *ast.Raise:
 		// Exc   Expr
 		// Cause Expr
@@ -1148,9 +1847,16 @@ func (c *compiler) Stmt(stmt ast.Stmt) {
 		// Orelse    []Stmt
 		// Finalbody []Stmt
 		c.try(node)
+	case *ast.Match:
+		// Subject Expr
+		// Cases   []*MatchCase
+		c.match(node)
 	case *ast.Assert:
 		// Test Expr
 		// Msg  Expr
+		if py.Optimize > 0 {
+			break
+		}
 		label := new(Label)
 		c.Expr(node.Test)
 		c.Jump(vm.POP_JUMP_IF_TRUE, label)
@@ -1347,11 +2053,20 @@ func (c *compiler) NameOp(name string, ctx ast.ExprContext) {
 	if op == 0 {
 		panic("NameOp: Op not set")
 	}
-	c.OpArg(op, c.Index(mangled, dict))
+	i := c.Index(mangled, dict)
+	if dict == &c.Code.Freevars {
+		// Free variables are stored after the cell variables
+		i += uint32(len(c.Code.Cellvars))
+	}
+	c.OpArg(op, i)
 }
 
 // Call a function which is already on the stack with n arguments already on the stack
 func (c *compiler) callHelper(n int, Args []ast.Expr, Keywords []*ast.Keyword, Starargs ast.Expr, Kwargs ast.Expr) {
+	if hasStarred(Args) || hasKwargsUnpacking(Keywords) {
+		c.unpackingCallHelper(n, Args, Keywords)
+		return
+	}
 	args := len(Args) + n
 	for i := range Args {
 		c.Expr(Args[i])
@@ -1390,6 +2105,133 @@ func (c *compiler) callHelper(n int, Args []ast.Expr, Keywords []*ast.Keyword, S
 	c.OpArg(op, uint32(args+kwargs<<8))
 }
 
+// Builds a dict from keys and values
+func (c *compiler) buildMap(keys, values []ast.Expr) {
+	c.OpArg(vm.BUILD_MAP, uint32(len(keys)))
+	for i := range keys {
+		c.Expr(values[i])
+		c.Expr(keys[i])
+		c.Op(vm.STORE_MAP)
+	}
+}
+
+// Returns true if any of exprs is a *ast.Starred
+func hasStarred(exprs []ast.Expr) bool {
+	for _, expr := range exprs {
+		if _, ok := expr.(*ast.Starred); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if any of keywords is a **mapping unpacking
+func hasKwargsUnpacking(keywords []*ast.Keyword) bool {
+	for _, kw := range keywords {
+		if kw.Arg == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Call a function which is already on the stack with n arguments
+// already on the stack using the generalised unpackings of PEP 448.
+//
+// The positional arguments are gathered into a single tuple with
+// BUILD_TUPLE_UNPACK and the keyword arguments into a single dict
+// with BUILD_MAP_UNPACK_WITH_CALL.
+func (c *compiler) unpackingCallHelper(n int, Args []ast.Expr, Keywords []*ast.Keyword) {
+	op := vm.CALL_FUNCTION
+	args := n
+	if hasStarred(Args) {
+		c.starUnpack(Args, vm.BUILD_TUPLE_UNPACK)
+		op = vm.CALL_FUNCTION_VAR
+	} else {
+		c.Exprs(Args)
+		args += len(Args)
+	}
+	kwargs := 0
+	if hasKwargsUnpacking(Keywords) {
+		nsubkwargs := 0
+		for i := 0; i < len(Keywords); {
+			if Keywords[i].Arg == "" {
+				c.Expr(Keywords[i].Value)
+				nsubkwargs++
+				i++
+				continue
+			}
+			j := i
+			for j < len(Keywords) && Keywords[j].Arg != "" {
+				j++
+			}
+			c.OpArg(vm.BUILD_MAP, uint32(j-i))
+			for _, kw := range Keywords[i:j] {
+				c.Expr(kw.Value)
+				c.LoadConst(py.String(kw.Arg))
+				c.Op(vm.STORE_MAP)
+			}
+			nsubkwargs++
+			i = j
+		}
+		// The function is below the mappings and the positional arguments
+		location := args + 1
+		if op == vm.CALL_FUNCTION_VAR {
+			location++
+		}
+		c.OpArg(vm.BUILD_MAP_UNPACK_WITH_CALL, uint32(nsubkwargs|location<<8))
+		if op == vm.CALL_FUNCTION_VAR {
+			op = vm.CALL_FUNCTION_VAR_KW
+		} else {
+			op = vm.CALL_FUNCTION_KW
+		}
+	} else if op == vm.CALL_FUNCTION_VAR && len(Keywords) > 0 {
+		// CALL_FUNCTION_VAR wants the positional arguments on top
+		// of any keyword pairs, so gather the keywords into a
+		// mapping above them instead
+		c.OpArg(vm.BUILD_MAP, uint32(len(Keywords)))
+		for _, kw := range Keywords {
+			c.Expr(kw.Value)
+			c.LoadConst(py.String(kw.Arg))
+			c.Op(vm.STORE_MAP)
+		}
+		op = vm.CALL_FUNCTION_VAR_KW
+	} else {
+		for _, kw := range Keywords {
+			c.LoadConst(py.String(kw.Arg))
+			c.Expr(kw.Value)
+		}
+		kwargs = len(Keywords)
+	}
+	c.OpArg(op, uint32(args+kwargs<<8))
+}
+
+// Builds a tuple, list or set from elts which contains starred items
+// using op, one of the BUILD_XXX_UNPACK opcodes.  Runs of unstarred
+// items are gathered into tuples first.
+func (c *compiler) starUnpack(elts []ast.Expr, op vm.OpCode) {
+	nsubitems, nseen := 0, 0
+	for _, elt := range elts {
+		if starred, ok := elt.(*ast.Starred); ok {
+			if nseen > 0 {
+				c.OpArg(vm.BUILD_TUPLE, uint32(nseen))
+				nseen = 0
+				nsubitems++
+			}
+			c.Expr(starred.Value)
+			nsubitems++
+		} else {
+			c.Expr(elt)
+			nseen++
+		}
+	}
+	if nseen > 0 {
+		c.OpArg(vm.BUILD_TUPLE, uint32(nseen))
+		nsubitems++
+	}
+	c.OpArg(op, uint32(nsubitems))
+}
+
 /* List and set comprehensions and generator expressions work by creating a
 nested function to perform the actual iteration. This means that the
 iteration variables don't leak into the current scope.
@@ -1497,6 +2339,14 @@ func (c *compiler) tupleOrList(op vm.OpCode, ctx ast.ExprContext, elts []ast.Exp
 			c.OpArg(vm.UNPACK_SEQUENCE, uint32(n))
 		}
 	}
+	if ctx == ast.Load && hasStarred(elts) {
+		unpackOp := vm.BUILD_TUPLE_UNPACK
+		if op == vm.BUILD_LIST {
+			unpackOp = vm.BUILD_LIST_UNPACK
+		}
+		c.starUnpack(elts, unpackOp)
+		return
+	}
 	c.Exprs(elts)
 	if ctx == ast.Load {
 		c.OpArg(op, uint32(n))
@@ -1598,7 +2448,14 @@ func (c *compiler) Exprs(exprs []ast.Expr) {
 
 // Compile and expression
 func (c *compiler) Expr(expr ast.Expr) {
-	c.SetLineno(expr)
+	// Restore the position when done so the instructions of the
+	// enclosing node are marked with all of it rather than with its
+	// last subexpression
+	position := c.Position
+	c.SetPosition(expr)
+	defer func() {
+		c.Position = position
+	}()
 	switch node := expr.(type) {
 	case *ast.BoolOp:
 		// Op     BoolOpNumber
@@ -1620,6 +2477,12 @@ func (c *compiler) Expr(expr ast.Expr) {
 			}
 		}
 		c.Label(label)
+	case *ast.NamedExpr:
+		// Target Expr
+		// Value  Expr
+		c.Expr(node.Value)
+		c.Op(vm.DUP_TOP)
+		c.Expr(node.Target)
 	case *ast.BinOp:
 		// Left  Expr
 		// Op    OperatorNumber
@@ -1634,6 +2497,8 @@ func (c *compiler) Expr(expr ast.Expr) {
 			op = vm.BINARY_SUBTRACT
 		case ast.Mult:
 			op = vm.BINARY_MULTIPLY
+		case ast.MatMult:
+			op = vm.BINARY_MATRIX_MULTIPLY
 		case ast.Div:
 			op = vm.BINARY_TRUE_DIVIDE
 		case ast.Modulo:
@@ -1699,14 +2564,41 @@ func (c *compiler) Expr(expr ast.Expr) {
 		if n != len(node.Values) {
 			panic("compile: Dict keys and values differing sizes")
 		}
-		c.OpArg(vm.BUILD_MAP, uint32(n))
-		for i := range node.Keys {
-			c.Expr(node.Values[i])
-			c.Expr(node.Keys[i])
-			c.Op(vm.STORE_MAP)
+		unpacking := false
+		for _, key := range node.Keys {
+			if key == nil {
+				unpacking = true
+			}
+		}
+		if !unpacking {
+			c.buildMap(node.Keys, node.Values)
+			break
+		}
+		// A nil key is a **mapping so build the runs of key, value
+		// pairs and merge them with the mappings
+		nsubitems := 0
+		for i := 0; i < n; {
+			if node.Keys[i] == nil {
+				c.Expr(node.Values[i])
+				nsubitems++
+				i++
+				continue
+			}
+			j := i
+			for j < n && node.Keys[j] != nil {
+				j++
+			}
+			c.buildMap(node.Keys[i:j], node.Values[i:j])
+			nsubitems++
+			i = j
 		}
+		c.OpArg(vm.BUILD_MAP_UNPACK, uint32(nsubitems))
 	case *ast.Set:
 		// Elts []Expr
+		if hasStarred(node.Elts) {
+			c.starUnpack(node.Elts, vm.BUILD_SET_UNPACK)
+			break
+		}
 		c.Exprs(node.Elts)
 		c.OpArg(vm.BUILD_SET, uint32(len(node.Elts)))
 	case *ast.ListComp:
@@ -1731,6 +2623,9 @@ func (c *compiler) Expr(expr ast.Expr) {
 		if c.SymTable.Type != symtable.FunctionBlock {
 			c.panicSyntaxErrorf(node, "'yield' outside function")
 		}
+		if c.SymTable.Coroutine {
+			c.panicSyntaxErrorf(node, "'yield' inside async function")
+		}
 		if node.Value != nil {
 			c.Expr(node.Value)
 		} else {
@@ -1742,8 +2637,23 @@ func (c *compiler) Expr(expr ast.Expr) {
 		if c.SymTable.Type != symtable.FunctionBlock {
 			c.panicSyntaxErrorf(node, "'yield' outside function")
 		}
+		if c.SymTable.Coroutine {
+			c.panicSyntaxErrorf(node, "'yield from' inside async function")
+		}
 		c.Expr(node.Value)
-		c.Op(vm.GET_ITER)
+		c.Op(vm.GET_YIELD_FROM_ITER)
+		c.LoadConst(py.None)
+		c.Op(vm.YIELD_FROM)
+	case *ast.Await:
+		// Value Expr
+		if c.SymTable.Type != symtable.FunctionBlock {
+			c.panicSyntaxErrorf(node, "'await' outside function")
+		}
+		if !c.SymTable.Coroutine {
+			c.panicSyntaxErrorf(node, "'await' outside async function")
+		}
+		c.Expr(node.Value)
+		c.Op(vm.GET_AWAITABLE)
 		c.LoadConst(py.None)
 		c.Op(vm.YIELD_FROM)
 	case *ast.Compare:
@@ -1821,6 +2731,31 @@ func (c *compiler) Expr(expr ast.Expr) {
 	case *ast.Bytes:
 		// S py.Bytes
 		c.LoadConst(node.S)
+	case *ast.JoinedStr:
+		// Values []Expr
+		c.Exprs(node.Values)
+		if len(node.Values) != 1 {
+			c.OpArg(vm.BUILD_STRING, uint32(len(node.Values)))
+		}
+	case *ast.FormattedValue:
+		// Value      Expr
+		// Conversion int
+		// FormatSpec Expr
+		c.Expr(node.Value)
+		var flags uint32
+		switch node.Conversion {
+		case 's':
+			flags = 1
+		case 'r':
+			flags = 2
+		case 'a':
+			flags = 3
+		}
+		if node.FormatSpec != nil {
+			c.Expr(node.FormatSpec)
+			flags |= 0x04
+		}
+		c.OpArg(vm.FORMAT_VALUE, flags)
 	case *ast.NameConstant:
 		// Value Singleton
 		c.LoadConst(node.Value)
@@ -1889,6 +2824,10 @@ func (c *compiler) Expr(expr ast.Expr) {
 	case *ast.Name:
 		// Id  Identifier
 		// Ctx ExprContext
+		if node.Id == "__debug__" && node.Ctx == ast.Load {
+			c.LoadConst(py.NewBool(py.Optimize == 0))
+			break
+		}
 		c.NameOp(string(node.Id), node.Ctx)
 	case *ast.List:
 		// Elts []Expr
@@ -1874,8 +1874,8 @@ var compileTestData = []struct {
 		Nlocals:        0,
 		Stacksize:      1,
 		Flags:          64,
-		Code:           "\x82\x00\x00\x64\x00\x00\x53",
-		Consts:         []py.Object{py.None},
+		Code:           "\x82\x00\x00",
+		Consts:         []py.Object{},
 		Names:          []string{},
 		Varnames:       []string{},
 		Freevars:       []string{},
@@ -1891,8 +1891,8 @@ var compileTestData = []struct {
 		Nlocals:        0,
 		Stacksize:      1,
 		Flags:          64,
-		Code:           "\x65\x00\x00\x82\x01\x00\x64\x00\x00\x53",
-		Consts:         []py.Object{py.None},
+		Code:           "\x65\x00\x00\x82\x01\x00",
+		Consts:         []py.Object{},
 		Names:          []string{"a"},
 		Varnames:       []string{},
 		Freevars:       []string{},
@@ -1908,8 +1908,8 @@ var compileTestData = []struct {
 		Nlocals:        0,
 		Stacksize:      2,
 		Flags:          64,
-		Code:           "\x65\x00\x00\x65\x01\x00\x82\x02\x00\x64\x00\x00\x53",
-		Consts:         []py.Object{py.None},
+		Code:           "\x65\x00\x00\x65\x01\x00\x82\x02\x00",
+		Consts:         []py.Object{},
 		Names:          []string{"a", "b"},
 		Varnames:       []string{},
 		Freevars:       []string{},
@@ -3899,7 +3899,7 @@ var compileTestData = []struct {
 		Nlocals:        0,
 		Stacksize:      11,
 		Flags:          64,
-		Code:           "\x64\x00\x00\x5a\x00\x00\x79\x0a\x00\x65\x01\x00\x82\x01\x00\x57\x6e\x18\x00\x04\x65\x01\x00\x6b\x0a\x00\x72\x2a\x00\x01\x01\x01\x64\x01\x00\x5a\x00\x00\x59\x6e\x01\x00\x58\x65\x00\x00\x73\x37\x00\x74\x02\x00\x82\x01\x00\x64\x02\x00\x53",
+		Code:           "\x64\x00\x00\x5a\x00\x00\x79\x06\x00\x65\x01\x00\x82\x01\x00\x04\x65\x01\x00\x6b\x0a\x00\x72\x26\x00\x01\x01\x01\x64\x01\x00\x5a\x00\x00\x59\x6e\x01\x00\x58\x65\x00\x00\x73\x33\x00\x74\x02\x00\x82\x01\x00\x64\x02\x00\x53",
 		Consts:         []py.Object{py.False, py.True, py.None},
 		Names:          []string{"ok", "SyntaxError", "AssertionError"},
 		Varnames:       []string{},
@@ -3916,7 +3916,7 @@ var compileTestData = []struct {
 		Nlocals:        0,
 		Stacksize:      16,
 		Flags:          64,
-		Code:           "\x64\x00\x00\x5a\x00\x00\x79\x0a\x00\x65\x01\x00\x82\x01\x00\x57\x6e\x2b\x00\x04\x65\x01\x00\x6b\x0a\x00\x72\x3d\x00\x01\x5a\x02\x00\x01\x7a\x0b\x00\x64\x01\x00\x5a\x00\x00\x57\x59\x64\x02\x00\x64\x02\x00\x5a\x02\x00\x5b\x02\x00\x58\x6e\x01\x00\x58\x65\x00\x00\x73\x4a\x00\x74\x03\x00\x82\x01\x00\x64\x02\x00\x53",
+		Code:           "\x64\x00\x00\x5a\x00\x00\x79\x06\x00\x65\x01\x00\x82\x01\x00\x04\x65\x01\x00\x6b\x0a\x00\x72\x39\x00\x01\x5a\x02\x00\x01\x7a\x0b\x00\x64\x01\x00\x5a\x00\x00\x57\x59\x64\x02\x00\x64\x02\x00\x5a\x02\x00\x5b\x02\x00\x58\x6e\x01\x00\x58\x65\x00\x00\x73\x46\x00\x74\x03\x00\x82\x01\x00\x64\x02\x00\x53",
 		Consts:         []py.Object{py.False, py.True, py.None},
 		Names:          []string{"ok", "SyntaxError", "e", "AssertionError"},
 		Varnames:       []string{},
@@ -4248,7 +4248,7 @@ var compileTestData = []struct {
 			Nlocals:        0,
 			Stacksize:      2,
 			Flags:          99,
-			Code:           "\x74\x00\x00\x64\x01\x00\x83\x01\x00\x44\x64\x00\x00\x48\x01\x64\x00\x00\x53",
+			Code:           "\x74\x00\x00\x64\x01\x00\x83\x01\x00\x45\x64\x00\x00\x48\x01\x64\x00\x00\x53",
 			Consts:         []py.Object{py.None, py.Int(10)},
 			Names:          []string{"range"},
 			Varnames:       []string{},
@@ -4356,7 +4356,7 @@ var compileTestData = []struct {
 	}, nil, ""},
 	{"a, *b, *c = t", "exec", nil, py.SyntaxError, "two starred expressions in assignment"},
 	{"a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,a,*a = t", "exec", nil, py.SyntaxError, "too many expressions in star-unpacking assignment"},
-	{"a, b, *c", "exec", nil, py.SyntaxError, "can use starred expression only as assignment target"},
+	{"*a", "exec", nil, py.SyntaxError, "can use starred expression only as assignment target"},
 	{"a, (b, c), d = t", "exec", &py.Code{
 		Argcount:       0,
 		Kwonlyargcount: 0,
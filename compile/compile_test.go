@@ -14,7 +14,10 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/go-python/gpython/ast"
+	"github.com/go-python/gpython/parser"
 	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
 )
 
 func EqString(t *testing.T, name string, a, b string) {
@@ -211,3 +214,34 @@ func TestCompile(t *testing.T) {
 		}
 	}
 }
+
+func TestCompileAst(t *testing.T) {
+	Ast, err := parser.ParseString("a + b", "eval")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// Change the Ast before compiling it
+	Ast.(*ast.Expression).Body.(*ast.BinOp).Op = ast.Mult
+	code, err := CompileAst(Ast.(ast.Mod), "<ast>", 0, true)
+	if err != nil {
+		t.Fatalf("CompileAst failed: %v", err)
+	}
+	EqString(t, "Filename", "<ast>", code.Filename)
+	globals := py.StringDict{"a": py.Int(6), "b": py.Int(7)}
+	res, err := vm.Run(globals, globals, code, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res != py.Int(42) {
+		t.Errorf("want 42 got %v", res)
+	}
+
+	_, err = CompileAst(&ast.Suite{}, "<ast>", 0, true)
+	if exc, ok := err.(*py.Exception); !ok || exc.Type() != py.TypeError {
+		t.Errorf("want TypeError for Suite got %v", err)
+	}
+	_, err = CompileAst(nil, "<ast>", 0, true)
+	if exc, ok := err.(*py.Exception); !ok || exc.Type() != py.TypeError {
+		t.Errorf("want TypeError for nil got %v", err)
+	}
+}
@@ -4,7 +4,11 @@
 
 package compile
 
-import "github.com/go-python/gpython/vm"
+import (
+	"github.com/go-python/gpython/ast"
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
 
 // FIXME detect if label is not in the instruction stream by setting
 // Pos to 0xFFFF say by default, ie we made a label but forgot to add
@@ -91,11 +95,11 @@ func opcodeStackEffect(opcode vm.OpCode, oparg uint32) int {
 		return -1
 	case vm.MAP_ADD:
 		return -2
-	case vm.BINARY_POWER, vm.BINARY_MULTIPLY, vm.BINARY_MODULO, vm.BINARY_ADD, vm.BINARY_SUBTRACT, vm.BINARY_SUBSCR, vm.BINARY_FLOOR_DIVIDE, vm.BINARY_TRUE_DIVIDE:
+	case vm.BINARY_POWER, vm.BINARY_MULTIPLY, vm.BINARY_MATRIX_MULTIPLY, vm.BINARY_MODULO, vm.BINARY_ADD, vm.BINARY_SUBTRACT, vm.BINARY_SUBSCR, vm.BINARY_FLOOR_DIVIDE, vm.BINARY_TRUE_DIVIDE:
 		return -1
 	case vm.INPLACE_FLOOR_DIVIDE, vm.INPLACE_TRUE_DIVIDE:
 		return -1
-	case vm.INPLACE_ADD, vm.INPLACE_SUBTRACT, vm.INPLACE_MULTIPLY, vm.INPLACE_MODULO:
+	case vm.INPLACE_ADD, vm.INPLACE_SUBTRACT, vm.INPLACE_MULTIPLY, vm.INPLACE_MATRIX_MULTIPLY, vm.INPLACE_MODULO:
 		return -1
 	case vm.STORE_SUBSCR:
 		return -3
@@ -109,6 +113,8 @@ func opcodeStackEffect(opcode vm.OpCode, oparg uint32) int {
 		return -1
 	case vm.GET_ITER:
 		return 0
+	case vm.GET_YIELD_FROM_ITER:
+		return 0
 	case vm.PRINT_EXPR:
 		return -1
 	case vm.LOAD_BUILD_CLASS:
@@ -121,10 +127,26 @@ func opcodeStackEffect(opcode vm.OpCode, oparg uint32) int {
 		return 7
 	case vm.WITH_CLEANUP:
 		return -1 /* XXX Sometimes more */
+	case vm.WITH_CLEANUP_START:
+		return 1
+	case vm.WITH_CLEANUP_FINISH:
+		return -1 /* XXX Sometimes more */
+	case vm.GET_AWAITABLE:
+		return 0
+	case vm.SETUP_ASYNC_WITH:
+		return 6
+	case vm.BEFORE_ASYNC_WITH:
+		return 1
+	case vm.GET_AITER:
+		return 0
+	case vm.GET_ANEXT:
+		return 1
 	case vm.RETURN_VALUE:
 		return -1
 	case vm.IMPORT_STAR:
 		return -1
+	case vm.SETUP_ANNOTATIONS:
+		return 0
 	case vm.YIELD_VALUE:
 		return 0
 	case vm.YIELD_FROM:
@@ -161,6 +183,25 @@ func opcodeStackEffect(opcode vm.OpCode, oparg uint32) int {
 		return 1 - int(oparg)
 	case vm.BUILD_MAP:
 		return 1
+	case vm.BUILD_STRING:
+		return 1 - int(oparg)
+	case vm.BUILD_TUPLE_UNPACK, vm.BUILD_LIST_UNPACK, vm.BUILD_SET_UNPACK, vm.BUILD_MAP_UNPACK:
+		return 1 - int(oparg)
+	case vm.BUILD_MAP_UNPACK_WITH_CALL:
+		return 1 - int(oparg&0xFF)
+	case vm.GET_LEN, vm.MATCH_MAPPING, vm.MATCH_SEQUENCE, vm.MATCH_KEYS:
+		return 1
+	case vm.COPY_DICT_WITHOUT_KEYS:
+		return 0
+	case vm.MATCH_CLASS:
+		return -2
+	case vm.FORMAT_VALUE:
+		/* If there's a fmt_spec on the stack, we go from 2->1,
+		   else 1->1. */
+		if oparg&0x04 != 0 {
+			return -1
+		}
+		return 0
 	case vm.LOAD_ATTR:
 		return 0
 	case vm.COMPARE_OP:
@@ -256,6 +297,12 @@ func (is Instructions) stackDepthWalk(baseIs Instructions, seen map[int]bool, st
 		if depth < 0 {
 			panic("Stack depth negative")
 		}
+		if op, ok := instr.(*Op); ok && op.Op == vm.RETURN_VALUE {
+			goto out // remaining code is dead
+		}
+		if op, ok := instr.(*OpArg); ok && op.Op == vm.RAISE_VARARGS {
+			goto out // remaining code is dead
+		}
 		jrel, isJrel := instr.(*JumpRel)
 		jabs, isJabs := instr.(*JumpAbs)
 		if isJrel || isJabs {
@@ -302,8 +349,8 @@ func (is Instructions) StackDepth() int {
 type Instruction interface {
 	Pos() uint32
 	Number() int
-	Lineno() int
-	SetLineno(int)
+	Position() ast.Pos
+	SetPosition(ast.Pos)
 	SetPos(int, uint32) bool
 	Size() uint32
 	Output() []byte
@@ -316,9 +363,9 @@ type Resolver interface {
 
 // Position
 type pos struct {
-	n      uint32
-	p      uint32
-	lineno int
+	n        uint32
+	p        uint32
+	position ast.Pos // where in the source the instruction came from
 }
 
 // Read instruction number
@@ -331,14 +378,14 @@ func (p *pos) Pos() uint32 {
 	return p.p
 }
 
-// Read lineno
-func (p *pos) Lineno() int {
-	return p.lineno
+// Read source position
+func (p *pos) Position() ast.Pos {
+	return p.position
 }
 
-// Set lineno
-func (p *pos) SetLineno(lineno int) {
-	p.lineno = lineno
+// Set source position
+func (p *pos) SetPosition(position ast.Pos) {
+	p.position = position
 }
 
 // Set Position - returns changed
@@ -458,15 +505,15 @@ func (o *JumpRel) Resolve() {
 // Creates the lnotab from the instruction stream
 //
 // See Objects/lnotab_notes.txt for the description of the line number table.
-func (is Instructions) Lnotab() []byte {
+func (is Instructions) Lnotab(firstlineno int) []byte {
 	var lnotab []byte
 	old_offset := uint32(0)
-	old_lineno := 1
+	old_lineno := firstlineno
 	for _, instr := range is {
 		if instr.Size() == 0 {
 			continue
 		}
-		lineno := instr.Lineno()
+		lineno := instr.Position().Lineno
 		offset := instr.Pos()
 		d_lineno := lineno - old_lineno
 		if d_lineno <= 0 {
@@ -492,3 +539,29 @@ func (is Instructions) Lnotab() []byte {
 	}
 	return lnotab
 }
+
+// Creates the table of source positions from the instruction stream
+//
+// There is an entry each time the position changes.
+func (is Instructions) Positions() []py.CodePosition {
+	var positions []py.CodePosition
+	var old ast.Pos
+	for _, instr := range is {
+		if instr.Size() == 0 {
+			continue
+		}
+		position := instr.Position()
+		if len(positions) > 0 && position == old {
+			continue
+		}
+		positions = append(positions, py.CodePosition{
+			Addr:         int32(instr.Pos()),
+			Lineno:       int32(position.Lineno),
+			ColOffset:    int32(position.ColOffset),
+			EndLineno:    int32(position.EndLineno),
+			EndColOffset: int32(position.EndColOffset),
+		})
+		old = position
+	}
+	return positions
+}
@@ -6,7 +6,11 @@ package compile
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
+
+	"github.com/go-python/gpython/ast"
+	"github.com/go-python/gpython/py"
 )
 
 func TestLnotab(t *testing.T) {
@@ -20,28 +24,28 @@ func TestLnotab(t *testing.T) {
 		},
 		{
 			instrs: Instructions{
-				&Op{pos: pos{n: 1, p: 10, lineno: 1}},
-				&Op{pos: pos{n: 0, p: 10, lineno: 0}},
-				&Op{pos: pos{n: 1, p: 102, lineno: 1}},
+				&Op{pos: pos{n: 1, p: 10, position: ast.Pos{Lineno: 1}}},
+				&Op{pos: pos{n: 0, p: 10, position: ast.Pos{Lineno: 0}}},
+				&Op{pos: pos{n: 1, p: 102, position: ast.Pos{Lineno: 1}}},
 			},
 			want: []byte{},
 		},
 		{
 			instrs: Instructions{
-				&Op{pos: pos{n: 1, p: 0, lineno: 1}},
-				&Op{pos: pos{n: 1, p: 1, lineno: 2}},
-				&Op{pos: pos{n: 1, p: 2, lineno: 3}},
+				&Op{pos: pos{n: 1, p: 0, position: ast.Pos{Lineno: 1}}},
+				&Op{pos: pos{n: 1, p: 1, position: ast.Pos{Lineno: 2}}},
+				&Op{pos: pos{n: 1, p: 2, position: ast.Pos{Lineno: 3}}},
 			},
 			want: []byte{1, 1, 1, 1},
 		},
 		{
 			// Example from lnotab.txt
 			instrs: Instructions{
-				&Op{pos: pos{n: 1, p: 0, lineno: 1}},
-				&Op{pos: pos{n: 1, p: 6, lineno: 2}},
-				&Op{pos: pos{n: 1, p: 50, lineno: 7}},
-				&Op{pos: pos{n: 1, p: 350, lineno: 307}},
-				&Op{pos: pos{n: 1, p: 361, lineno: 308}},
+				&Op{pos: pos{n: 1, p: 0, position: ast.Pos{Lineno: 1}}},
+				&Op{pos: pos{n: 1, p: 6, position: ast.Pos{Lineno: 2}}},
+				&Op{pos: pos{n: 1, p: 50, position: ast.Pos{Lineno: 7}}},
+				&Op{pos: pos{n: 1, p: 350, position: ast.Pos{Lineno: 307}}},
+				&Op{pos: pos{n: 1, p: 361, position: ast.Pos{Lineno: 308}}},
 			},
 			want: []byte{
 				6, 1,
@@ -52,9 +56,30 @@ func TestLnotab(t *testing.T) {
 				11, 1},
 		},
 	} {
-		got := test.instrs.Lnotab()
+		got := test.instrs.Lnotab(1)
 		if bytes.Compare(test.want, got) != 0 {
 			t.Errorf("%d: want %d got %d", i, test.want, got)
 		}
 	}
 }
+
+func TestPositions(t *testing.T) {
+	a := ast.Pos{Lineno: 1, ColOffset: 4, EndLineno: 1, EndColOffset: 9}
+	b := ast.Pos{Lineno: 2, ColOffset: 0, EndLineno: 3, EndColOffset: 1}
+	instrs := Instructions{
+		&Op{pos: pos{n: 0, p: 0, position: a}},
+		&Op{pos: pos{n: 1, p: 1, position: a}},
+		&Label{pos: pos{n: 2, p: 2, position: b}},
+		&Op{pos: pos{n: 3, p: 2, position: b}},
+		&Op{pos: pos{n: 4, p: 3, position: a}},
+	}
+	want := []py.CodePosition{
+		{Addr: 0, Lineno: 1, ColOffset: 4, EndLineno: 1, EndColOffset: 9},
+		{Addr: 2, Lineno: 2, ColOffset: 0, EndLineno: 3, EndColOffset: 1},
+		{Addr: 3, Lineno: 1, ColOffset: 4, EndLineno: 1, EndColOffset: 9},
+	}
+	got := instrs.Positions()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
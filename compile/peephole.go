@@ -0,0 +1,554 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Peephole optimizer
+//
+// This works on the instruction stream before it is assembled doing a
+// similar job to Python/peephole.c.  Because labels are instructions
+// in the stream, a pattern of adjacent instructions can never span a
+// jump target.
+
+package compile
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/go-python/gpython/ast"
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+const (
+	// Maximum number of optimizer passes over the instructions
+	maxOptimizePasses = 10
+
+	// Folded strings, bytes and tuples longer than this aren't
+	// made into constants as they may make the code object larger
+	maxFoldedLen = 20
+
+	// Folded ints with more bits than this aren't made into
+	// constants so folding can't take a long time
+	maxFoldedIntBits = 128
+)
+
+// Functions to fold the unary operators
+var unaryFolders = map[vm.OpCode]func(py.Object) (py.Object, error){
+	vm.UNARY_POSITIVE: py.Pos,
+	vm.UNARY_NEGATIVE: py.Neg,
+	vm.UNARY_INVERT:   py.Invert,
+}
+
+// Functions to fold the binary operators
+var binaryFolders = map[vm.OpCode]func(py.Object, py.Object) (py.Object, error){
+	vm.BINARY_POWER: func(a, b py.Object) (py.Object, error) {
+		return py.Pow(a, b, py.None)
+	},
+	vm.BINARY_MULTIPLY:     py.Mul,
+	vm.BINARY_MODULO:       py.Mod,
+	vm.BINARY_ADD:          py.Add,
+	vm.BINARY_SUBTRACT:     py.Sub,
+	vm.BINARY_SUBSCR:       py.GetItem,
+	vm.BINARY_FLOOR_DIVIDE: py.FloorDiv,
+	vm.BINARY_TRUE_DIVIDE:  py.TrueDiv,
+	vm.BINARY_LSHIFT:       py.Lshift,
+	vm.BINARY_RSHIFT:       py.Rshift,
+	vm.BINARY_AND:          py.And,
+	vm.BINARY_XOR:          py.Xor,
+	vm.BINARY_OR:           py.Or,
+}
+
+// Optimizes the instruction stream
+//
+// This folds constant expressions, simplifies jumps to jumps and
+// removes unreachable code, repeating until nothing changes, then
+// removes the constants which are no longer loaded.
+func (c *compiler) optimize() {
+	for pass := 0; pass < maxOptimizePasses; pass++ {
+		changed := c.foldConstants()
+		if c.threadJumps() {
+			changed = true
+		}
+		if c.removeDeadCode() {
+			changed = true
+		}
+		if !changed {
+			break
+		}
+	}
+	c.removeUnusedConsts()
+}
+
+// Removes the constants which aren't loaded, such as the operands of
+// folded expressions and those of unreachable code, renumbering the
+// LOAD_CONST instructions
+func (c *compiler) removeUnusedConsts() {
+	consts := c.Code.Consts
+	used := make([]bool, len(consts))
+	if len(consts) > 0 && (c.scopeType == compilerScopeFunction || c.scopeType == compilerScopeLambda) {
+		// The first constant of a function is its docstring
+		used[0] = true
+	}
+	for _, instr := range c.OpCodes {
+		if op, arg, ok := opcodeOf(instr); ok && op == vm.LOAD_CONST {
+			used[arg] = true
+		}
+	}
+	index := make([]uint32, len(consts))
+	var kept py.Tuple
+	for i, obj := range consts {
+		if used[i] {
+			index[i] = uint32(len(kept))
+			kept = append(kept, obj)
+		}
+	}
+	if len(kept) == len(consts) {
+		return
+	}
+	for _, instr := range c.OpCodes {
+		if instr, ok := instr.(*OpArg); ok && instr.Op == vm.LOAD_CONST {
+			instr.Arg = index[instr.Arg]
+		}
+	}
+	c.Code.Consts = kept
+}
+
+// Returns the opcode and argument of instr or false if it is a label
+//
+// The argument of a jump isn't resolved until assembly so is 0.
+func opcodeOf(instr Instruction) (vm.OpCode, uint32, bool) {
+	switch instr := instr.(type) {
+	case *Op:
+		return instr.Op, 0, true
+	case *OpArg:
+		return instr.Op, instr.Arg, true
+	case *JumpAbs:
+		return instr.Op, 0, true
+	case *JumpRel:
+		return instr.Op, 0, true
+	}
+	return 0, 0, false
+}
+
+// Returns the destination of instr if it is a jump
+func jumpDest(instr Instruction) *Label {
+	switch instr := instr.(type) {
+	case *JumpAbs:
+		return instr.Dest
+	case *JumpRel:
+		return instr.Dest
+	}
+	return nil
+}
+
+// Makes a jump instruction like compiler.Jump does
+func newJump(op vm.OpCode, dest *Label, position ast.Pos) Instruction {
+	var instr Instruction
+	if op == vm.JUMP_FORWARD {
+		instr = &JumpRel{OpArg: OpArg{Op: op}, Dest: dest}
+	} else {
+		instr = &JumpAbs{OpArg: OpArg{Op: op}, Dest: dest}
+	}
+	instr.SetPosition(position)
+	return instr
+}
+
+// Makes an instruction, with an argument if the opcode takes one
+func newOp(op vm.OpCode, arg uint32, position ast.Pos) Instruction {
+	var instr Instruction
+	if op.HAS_ARG() {
+		instr = &OpArg{Op: op, Arg: arg}
+	} else {
+		instr = &Op{Op: op}
+	}
+	instr.SetPosition(position)
+	return instr
+}
+
+// Returns the constant loaded by instr or nil if it isn't a LOAD_CONST
+func (c *compiler) constOf(instr Instruction) py.Object {
+	if op, arg, ok := opcodeOf(instr); ok && op == vm.LOAD_CONST {
+		return c.Code.Consts[arg]
+	}
+	return nil
+}
+
+// Returns the constants loaded by the n instructions before the end
+// of is or nil if they aren't all LOAD_CONST
+func (c *compiler) constsBefore(is Instructions, n int) []py.Object {
+	if n < 0 || len(is) < n+1 {
+		return nil
+	}
+	consts := make([]py.Object, n)
+	for i, instr := range is[len(is)-n-1 : len(is)-1] {
+		consts[i] = c.constOf(instr)
+		if consts[i] == nil {
+			return nil
+		}
+	}
+	return consts
+}
+
+// Folds constant expressions and other simple patterns
+//
+// Each instruction is appended to the output and then the end of the
+// output is rewritten for as long as it matches a pattern, so folded
+// constants can be folded again.
+func (c *compiler) foldConstants() bool {
+	changed := false
+	out := make(Instructions, 0, len(c.OpCodes))
+	for _, instr := range c.OpCodes {
+		out = append(out, instr)
+		for c.foldTail(&out) {
+			changed = true
+		}
+	}
+	c.OpCodes = out
+	return changed
+}
+
+// Rewrites the instructions at the end of *pis if they match a
+// pattern returning true if they did
+func (c *compiler) foldTail(pis *Instructions) bool {
+	is := *pis
+	n := len(is)
+	if n == 0 {
+		return false
+	}
+	last := is[n-1]
+	op, arg, ok := opcodeOf(last)
+	if !ok {
+		return false
+	}
+	position := last.Position()
+	// replace the last n instructions with instrs
+	replace := func(count int, instrs ...Instruction) bool {
+		if len(instrs) > 0 {
+			position := is[n-count].Position()
+			for _, instr := range instrs {
+				instr.SetPosition(position)
+			}
+		}
+		*pis = append(is[:n-count], instrs...)
+		return true
+	}
+	loadConst := func(obj py.Object) Instruction {
+		return newOp(vm.LOAD_CONST, c.Const(obj), position)
+	}
+	var prevOp vm.OpCode
+	var prevArg uint32
+	prevOk := false
+	if n >= 2 {
+		prevOp, prevArg, prevOk = opcodeOf(is[n-2])
+	}
+
+	switch {
+	case unaryFolders[op] != nil:
+		// LOAD_CONST a; UNARY_OP -> LOAD_CONST op(a)
+		if consts := c.constsBefore(is, 1); consts != nil {
+			if result := foldConstant(op, consts); result != nil {
+				return replace(2, loadConst(result))
+			}
+		}
+	case binaryFolders[op] != nil:
+		// LOAD_CONST a; LOAD_CONST b; BINARY_OP -> LOAD_CONST a op b
+		if consts := c.constsBefore(is, 2); consts != nil {
+			if result := foldConstant(op, consts); result != nil {
+				return replace(3, loadConst(result))
+			}
+		}
+	case op == vm.BUILD_TUPLE && arg > 0:
+		// LOAD_CONST a; ... LOAD_CONST z; BUILD_TUPLE n -> LOAD_CONST (a, ..., z)
+		if consts := c.constsBefore(is, int(arg)); consts != nil {
+			return replace(int(arg)+1, loadConst(py.Tuple(consts)))
+		}
+	case op == vm.UNPACK_SEQUENCE && prevOk && (prevOp == vm.BUILD_TUPLE || prevOp == vm.BUILD_LIST) && prevArg == arg:
+		// BUILD_TUPLE n; UNPACK_SEQUENCE n -> rotations for a, b = b, a
+		switch arg {
+		case 1:
+			return replace(2)
+		case 2:
+			return replace(2, newOp(vm.ROT_TWO, 0, position))
+		case 3:
+			return replace(2, newOp(vm.ROT_THREE, 0, position), newOp(vm.ROT_TWO, 0, position))
+		}
+	case (op == vm.GET_ITER || (op == vm.COMPARE_OP && (arg == vm.PyCmp_IN || arg == vm.PyCmp_NOT_IN))) && prevOk && (prevOp == vm.BUILD_LIST || prevOp == vm.BUILD_SET):
+		// A list or set of constants which is only iterated or
+		// tested for membership can be a tuple or frozenset
+		// constant
+		if consts := c.constsBefore(is[:n-1], int(prevArg)); consts != nil {
+			var obj py.Object = py.Tuple(consts)
+			if prevOp == vm.BUILD_SET {
+				if op == vm.GET_ITER {
+					// Iteration order of a set may differ
+					break
+				}
+				set, err := py.NewFrozenSetFromItems(consts)
+				if err != nil {
+					break
+				}
+				obj = set
+			}
+			return replace(int(prevArg)+2, loadConst(obj), newOp(op, arg, position))
+		}
+	case op == vm.UNARY_NOT && prevOk && prevOp == vm.COMPARE_OP:
+		// COMPARE_OP is; UNARY_NOT -> COMPARE_OP is not
+		var inverted uint32
+		switch prevArg {
+		case vm.PyCmp_IS:
+			inverted = vm.PyCmp_IS_NOT
+		case vm.PyCmp_IS_NOT:
+			inverted = vm.PyCmp_IS
+		case vm.PyCmp_IN:
+			inverted = vm.PyCmp_NOT_IN
+		case vm.PyCmp_NOT_IN:
+			inverted = vm.PyCmp_IN
+		default:
+			return false
+		}
+		return replace(2, newOp(vm.COMPARE_OP, inverted, position))
+	case (op == vm.POP_JUMP_IF_FALSE || op == vm.POP_JUMP_IF_TRUE) && prevOk && prevOp == vm.UNARY_NOT:
+		// UNARY_NOT; POP_JUMP_IF_FALSE -> POP_JUMP_IF_TRUE
+		inverted := vm.POP_JUMP_IF_TRUE
+		if op == vm.POP_JUMP_IF_TRUE {
+			inverted = vm.POP_JUMP_IF_FALSE
+		}
+		return replace(2, newJump(inverted, jumpDest(last), position))
+	case op == vm.POP_JUMP_IF_FALSE:
+		// LOAD_CONST true; POP_JUMP_IF_FALSE -> nothing as in while True:
+		if consts := c.constsBefore(is, 1); consts != nil {
+			if truth, err := py.MakeBool(consts[0]); err == nil && truth == py.True {
+				return replace(2)
+			}
+		}
+	}
+	return false
+}
+
+// Returns the result of applying op to consts or nil if it can't or
+// shouldn't be folded
+func foldConstant(op vm.OpCode, consts []py.Object) py.Object {
+	for _, obj := range consts {
+		if !isFoldable(obj) {
+			return nil
+		}
+	}
+	var result py.Object
+	var err error
+	if len(consts) == 1 {
+		result, err = unaryFolders[op](consts[0])
+	} else {
+		a, b := consts[0], consts[1]
+		if !foldIsSmall(op, a, b) {
+			return nil
+		}
+		result, err = binaryFolders[op](a, b)
+	}
+	if err != nil || !isFoldable(result) || foldedSize(result) > maxFoldedLen {
+		return nil
+	}
+	if bits, ok := intBits(result); ok && bits > maxFoldedIntBits {
+		return nil
+	}
+	// Don't fold to a negative zero as Const would merge it with a
+	// positive zero
+	switch x := result.(type) {
+	case py.Float:
+		if isNegativeZero(float64(x)) {
+			return nil
+		}
+	case py.Complex:
+		if isNegativeZero(real(x)) || isNegativeZero(imag(x)) {
+			return nil
+		}
+	}
+	return result
+}
+
+// Returns true if x is -0.0
+func isNegativeZero(x float64) bool {
+	return x == 0 && math.Signbit(x)
+}
+
+// Returns true if obj is a constant which can be used in folding
+func isFoldable(obj py.Object) bool {
+	switch x := obj.(type) {
+	case py.Int, *py.BigInt, py.Float, py.Complex, py.Bool, py.String, py.Bytes, py.NoneType:
+		return true
+	case py.Tuple:
+		for _, item := range x {
+			if !isFoldable(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Returns the length of a folded sequence or 0
+func foldedSize(obj py.Object) int {
+	switch x := obj.(type) {
+	case py.String:
+		return len(x)
+	case py.Bytes:
+		return len(x)
+	case py.Tuple:
+		return len(x)
+	}
+	return 0
+}
+
+// Returns the number of bits in the magnitude of obj if it is an int
+func intBits(obj py.Object) (int, bool) {
+	switch x := obj.(type) {
+	case py.Int:
+		if x < 0 {
+			x = -x
+		}
+		return bits.Len64(uint64(x)), true
+	case *py.BigInt:
+		return (*big.Int)(x).BitLen(), true
+	case py.Bool:
+		return 1, true
+	}
+	return 0, false
+}
+
+// Returns false if folding a op b could take a long time or make a
+// huge result which is checked before doing the operation
+func foldIsSmall(op vm.OpCode, a, b py.Object) bool {
+	aBits, aIsInt := intBits(a)
+	bBits, bIsInt := intBits(b)
+	switch op {
+	case vm.BINARY_MODULO:
+		// Leave string formatting to run time
+		switch a.(type) {
+		case py.String, py.Bytes:
+			return false
+		}
+	case vm.BINARY_POWER:
+		if aIsInt && bIsInt {
+			if bBits > 32 {
+				return aBits <= 1
+			}
+			n, err := py.MakeGoInt(b)
+			return err == nil && (n <= 0 || aBits*n <= maxFoldedIntBits)
+		}
+	case vm.BINARY_MULTIPLY:
+		if aIsInt && bIsInt {
+			return aBits+bBits <= maxFoldedIntBits
+		}
+		// Repeating a sequence
+		size, n := foldedSize(a), b
+		if aIsInt {
+			size, n = foldedSize(b), a
+		}
+		if _, ok := intBits(n); ok {
+			count, err := py.MakeGoInt(n)
+			return err == nil && count*size <= maxFoldedLen
+		}
+	case vm.BINARY_LSHIFT:
+		if aIsInt && bIsInt {
+			n, err := py.MakeGoInt(b)
+			return err == nil && aBits+n <= maxFoldedIntBits
+		}
+	}
+	return true
+}
+
+// Simplifies jumps whose destination is another jump or a return
+func (c *compiler) threadJumps() bool {
+	changed := false
+	labels := make(map[*Label]int, len(c.OpCodes))
+	for i, instr := range c.OpCodes {
+		if label, ok := instr.(*Label); ok {
+			labels[label] = i
+		}
+	}
+	// Returns the index of the first non label instruction at or
+	// after dest or -1
+	target := func(dest *Label) int {
+		for i := labels[dest]; i < len(c.OpCodes); i++ {
+			if _, ok := c.OpCodes[i].(*Label); !ok {
+				return i
+			}
+		}
+		return -1
+	}
+	var out Instructions
+	for i, instr := range c.OpCodes {
+		dest := jumpDest(instr)
+		op, _, _ := opcodeOf(instr)
+		unconditional := op == vm.JUMP_ABSOLUTE || op == vm.JUMP_FORWARD
+		if dest == nil || !(unconditional || op == vm.POP_JUMP_IF_FALSE || op == vm.POP_JUMP_IF_TRUE || op == vm.JUMP_IF_FALSE_OR_POP || op == vm.JUMP_IF_TRUE_OR_POP) {
+			out = append(out, instr)
+			continue
+		}
+		t := target(dest)
+		if t < 0 {
+			out = append(out, instr)
+			continue
+		}
+		tOp, _, _ := opcodeOf(c.OpCodes[t])
+		tDest := jumpDest(c.OpCodes[t])
+		position := instr.Position()
+		switch {
+		case unconditional && tOp == vm.RETURN_VALUE:
+			out = append(out, newOp(vm.RETURN_VALUE, 0, position))
+			changed = true
+			continue
+		case (tOp == vm.JUMP_ABSOLUTE || tOp == vm.JUMP_FORWARD) && tDest != dest:
+			// Jump to an unconditional jump
+			newOp := op
+			if op == vm.JUMP_FORWARD && labels[tDest] < i {
+				newOp = vm.JUMP_ABSOLUTE
+			}
+			out = append(out, newJump(newOp, tDest, position))
+			changed = true
+			continue
+		case op == tOp && (op == vm.JUMP_IF_FALSE_OR_POP || op == vm.JUMP_IF_TRUE_OR_POP) && tDest != dest:
+			// The second conditional jump is taken too
+			out = append(out, newJump(op, tDest, position))
+			changed = true
+			continue
+		}
+		out = append(out, instr)
+	}
+	c.OpCodes = out
+	return changed
+}
+
+// Removes instructions which can't be reached because they follow a
+// return or raise and aren't a jump target
+func (c *compiler) removeDeadCode() bool {
+	changed := false
+	targets := make(map[*Label]bool)
+	for _, instr := range c.OpCodes {
+		if dest := jumpDest(instr); dest != nil {
+			targets[dest] = true
+		}
+	}
+	out := make(Instructions, 0, len(c.OpCodes))
+	dead := false
+	for _, instr := range c.OpCodes {
+		if label, ok := instr.(*Label); ok && targets[label] {
+			dead = false
+		}
+		if dead {
+			changed = true
+			continue
+		}
+		out = append(out, instr)
+		if op, _, ok := opcodeOf(instr); ok {
+			switch op {
+			case vm.RETURN_VALUE, vm.RAISE_VARARGS:
+				dead = true
+			}
+		}
+	}
+	c.OpCodes = out
+	return changed
+}
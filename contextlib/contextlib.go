@@ -0,0 +1,318 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Contextlib module
+//
+// Utilities for with-statement contexts.  The contextmanager
+// decorator turns a generator function which yields once into a
+// factory of context managers, driving the generator with next() on
+// entry and throw() on an exception in the with block.
+
+package contextlib
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const contextlib_doc = `Utilities for with-statement contexts.  See PEP 343.`
+
+// ------------------------------------------------------------
+// contextmanager
+
+const contextmanager_doc = `@contextmanager decorator.
+
+Typical usage:
+
+    @contextmanager
+    def some_generator(<arguments>):
+        <setup>
+        try:
+            yield <value>
+        finally:
+            <cleanup>
+
+This makes this:
+
+    with some_generator(<arguments>) as <variable>:
+        <body>
+
+equivalent to this:
+
+    <setup>
+    try:
+        <variable> = <value>
+        <body>
+    finally:
+        <cleanup>`
+
+// GeneratorContextManagerFunctionType is the type of the functions
+// returned by the contextmanager decorator
+var GeneratorContextManagerFunctionType = py.NewType("contextmanager", contextmanager_doc)
+
+// GeneratorContextManagerFunction wraps a generator function so that
+// calling it makes a context manager
+type GeneratorContextManagerFunction struct {
+	Func py.Object
+	Dict py.StringDict
+}
+
+// Type of this object
+func (f *GeneratorContextManagerFunction) Type() *py.Type {
+	return GeneratorContextManagerFunctionType
+}
+
+// GetDict returns the attributes copied from the wrapped function
+func (f *GeneratorContextManagerFunction) GetDict() py.StringDict {
+	return f.Dict
+}
+
+// Calls the generator function to make a context manager
+func (f *GeneratorContextManagerFunction) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	gen, err := py.Call(f.Func, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return &GeneratorContextManager{Gen: gen}, nil
+}
+
+// Binds the function to an instance so it can be used as a method
+func (f *GeneratorContextManagerFunction) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, f), nil
+	}
+	return f, nil
+}
+
+func contextlib_contextmanager(self py.Object, fn py.Object) (py.Object, error) {
+	f := &GeneratorContextManagerFunction{
+		Func: fn,
+		Dict: py.NewStringDict(),
+	}
+	// Copy __name__ and __doc__ like functools.wraps
+	for _, name := range []string{"__name__", "__qualname__", "__doc__"} {
+		if value, err := py.GetAttrString(fn, name); err == nil {
+			f.Dict[name] = value
+		}
+	}
+	return f, nil
+}
+
+// GeneratorContextManagerType is the type of the context managers
+// made by functions decorated with contextmanager
+var GeneratorContextManagerType = py.NewType("_GeneratorContextManager", "Helper for @contextmanager decorator.")
+
+// GeneratorContextManager runs a generator as a context manager
+type GeneratorContextManager struct {
+	Gen py.Object
+}
+
+// Type of this object
+func (cm *GeneratorContextManager) Type() *py.Type {
+	return GeneratorContextManagerType
+}
+
+// Runs the generator to its yield returning the yielded value
+func (cm *GeneratorContextManager) M__enter__() (py.Object, error) {
+	res, err := py.Next(cm.Gen)
+	if err != nil {
+		if py.IsException(py.StopIteration, err) {
+			return nil, py.ExceptionNewf(py.RuntimeError, "generator didn't yield")
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// Resumes the generator, throwing the exception from the with block
+// into it if there was one
+//
+// Returns True to suppress the exception if the generator caught it.
+func (cm *GeneratorContextManager) M__exit__(excType, excValue, traceback py.Object) (py.Object, error) {
+	if excType == py.None {
+		_, err := py.Next(cm.Gen)
+		if err == nil {
+			return nil, py.ExceptionNewf(py.RuntimeError, "generator didn't stop")
+		}
+		if py.IsException(py.StopIteration, err) {
+			return py.False, nil
+		}
+		return nil, err
+	}
+	if excValue == py.None {
+		// Need to force instantiation so we can reliably tell
+		// if we get the same exception back
+		var err error
+		excValue, err = py.Call(excType, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	throw, err := py.GetAttrString(cm.Gen, "throw")
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.Call(throw, py.Tuple{excType, excValue, traceback}, nil)
+	if err == nil {
+		return nil, py.ExceptionNewf(py.RuntimeError, "generator didn't stop after throw()")
+	}
+	raised := exceptionValue(err)
+	if py.IsException(py.StopIteration, err) {
+		// Suppress StopIteration unless it was the exception
+		// passed to throw
+		return py.NewBool(raised != excValue), nil
+	}
+	if raised == excValue {
+		// The generator re-raised the exception so don't suppress
+		// it and let the with statement raise it
+		return py.False, nil
+	}
+	return nil, err
+}
+
+// Returns the exception instance from the error err or nil
+func exceptionValue(err error) py.Object {
+	switch e := err.(type) {
+	case py.ExceptionInfo:
+		if exc, ok := e.Value.(*py.Exception); ok {
+			return exc
+		}
+	case *py.ExceptionInfo:
+		if exc, ok := e.Value.(*py.Exception); ok {
+			return exc
+		}
+	case *py.Exception:
+		return e
+	}
+	return nil
+}
+
+// ------------------------------------------------------------
+// closing
+
+const closing_doc = `Context to automatically close something at the end of a block.
+
+Code like this:
+
+    with closing(<module>.open(<arguments>)) as f:
+        <block>
+
+is equivalent to this:
+
+    f = <module>.open(<arguments>)
+    try:
+        <block>
+    finally:
+        f.close()`
+
+// ClosingType is the type of closing context managers
+var ClosingType = py.NewTypeX("closing", closing_doc, ClosingNew, nil)
+
+// Closing calls the close method of Thing on exit
+type Closing struct {
+	Thing py.Object
+}
+
+// Type of this object
+func (c *Closing) Type() *py.Type {
+	return ClosingType
+}
+
+// ClosingNew makes a closing context manager
+func ClosingNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	c := &Closing{}
+	err := py.UnpackTuple(args, kwargs, "closing", 1, 1, &c.Thing)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Closing) M__enter__() (py.Object, error) {
+	return c.Thing, nil
+}
+
+func (c *Closing) M__exit__(excType, excValue, traceback py.Object) (py.Object, error) {
+	closeMethod, err := py.GetAttrString(c.Thing, "close")
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.Call(closeMethod, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return py.False, nil
+}
+
+// ------------------------------------------------------------
+// suppress
+
+const suppress_doc = `Context manager to suppress specified exceptions
+
+After the exception is suppressed, execution proceeds with the next
+statement following the with statement.
+
+     with suppress(FileNotFoundError):
+         os.remove(somefile)
+     # Execution still resumes here if the file was already removed`
+
+// SuppressType is the type of suppress context managers
+var SuppressType = py.NewTypeX("suppress", suppress_doc, SuppressNew, nil)
+
+// Suppress swallows any of Exceptions raised in the with block
+type Suppress struct {
+	Exceptions py.Tuple
+}
+
+// Type of this object
+func (s *Suppress) Type() *py.Type {
+	return SuppressType
+}
+
+// SuppressNew makes a suppress context manager
+func SuppressNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(kwargs) != 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "suppress() takes no keyword arguments")
+	}
+	return &Suppress{Exceptions: args.Copy()}, nil
+}
+
+func (s *Suppress) M__enter__() (py.Object, error) {
+	return py.None, nil
+}
+
+func (s *Suppress) M__exit__(excType, excValue, traceback py.Object) (py.Object, error) {
+	if excType == py.None {
+		return py.False, nil
+	}
+	return py.NewBool(py.ExceptionGivenMatches(excType, s.Exceptions)), nil
+}
+
+// Check interface is satisfied
+var (
+	_ py.I__call__  = (*GeneratorContextManagerFunction)(nil)
+	_ py.I__get__   = (*GeneratorContextManagerFunction)(nil)
+	_ py.IGetDict   = (*GeneratorContextManagerFunction)(nil)
+	_ py.I__enter__ = (*GeneratorContextManager)(nil)
+	_ py.I__exit__  = (*GeneratorContextManager)(nil)
+	_ py.I__enter__ = (*Closing)(nil)
+	_ py.I__exit__  = (*Closing)(nil)
+	_ py.I__enter__ = (*Suppress)(nil)
+	_ py.I__exit__  = (*Suppress)(nil)
+)
+
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("contextmanager", contextlib_contextmanager, 0, contextmanager_doc),
+	}
+	globals := py.StringDict{
+		"closing":  ClosingType,
+		"suppress": SuppressType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "contextlib",
+		Doc:     contextlib_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
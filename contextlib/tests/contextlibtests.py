@@ -0,0 +1,147 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from contextlib import contextmanager, closing, suppress
+from libtest import *
+
+doc="contextmanager"
+log = []
+@contextmanager
+def tag(name):
+    log.append("<" + name + ">")
+    yield name
+    log.append("</" + name + ">")
+with tag("a") as a:
+    log.append(a)
+assertEqual(log, ["<a>", "a", "</a>"])
+assertEqual(tag.__name__, "tag")
+
+doc="contextmanager with exception"
+log = []
+try:
+    with tag("b"):
+        raise ValueError("boom")
+except ValueError as e:
+    assertEqual(e.args[0], "boom")
+else:
+    assert False, "ValueError not raised"
+assertEqual(log, ["<b>"])
+
+doc="contextmanager finally"
+log = []
+@contextmanager
+def cleanup():
+    try:
+        yield
+    finally:
+        log.append("cleanup")
+try:
+    with cleanup():
+        raise KeyError("x")
+except KeyError:
+    log.append("caught")
+assertEqual(log, ["cleanup", "caught"])
+
+doc="contextmanager suppressing"
+@contextmanager
+def ignore(exc):
+    try:
+        yield
+    except exc:
+        log.append("ignored")
+log = []
+with ignore(ValueError):
+    raise ValueError
+log.append("after")
+assertEqual(log, ["ignored", "after"])
+def raise_other():
+    with ignore(KeyError):
+        raise ValueError
+assertRaises(ValueError, raise_other)
+
+doc="contextmanager raising a different exception"
+@contextmanager
+def convert():
+    try:
+        yield
+    except ValueError:
+        raise KeyError("converted")
+try:
+    with convert():
+        raise ValueError
+except KeyError as e:
+    assertEqual(e.args[0], "converted")
+else:
+    assert False, "KeyError not raised"
+
+doc="contextmanager errors"
+@contextmanager
+def no_yield():
+    if False:
+        yield
+def enter_no_yield():
+    with no_yield():
+        pass
+assertRaisesText(RuntimeError, "didn't yield", enter_no_yield)
+@contextmanager
+def two_yields():
+    yield
+    yield
+def enter_two_yields():
+    with two_yields():
+        pass
+assertRaisesText(RuntimeError, "didn't stop", enter_two_yields)
+
+doc="contextmanager arguments and methods"
+@contextmanager
+def add(a, b=1):
+    yield a + b
+with add(1, b=2) as x:
+    assertEqual(x, 3)
+class Resource:
+    def __init__(self):
+        self.log = []
+    @contextmanager
+    def opened(self, name):
+        self.log.append("open " + name)
+        yield self
+        self.log.append("close " + name)
+r = Resource()
+with r.opened("r") as res:
+    assert res is r
+assertEqual(r.log, ["open r", "close r"])
+
+doc="closing"
+class Thing:
+    closed = False
+    def close(self):
+        self.closed = True
+t = Thing()
+with closing(t) as x:
+    assert x is t
+    assert not t.closed
+assert t.closed
+t = Thing()
+try:
+    with closing(t):
+        raise ValueError
+except ValueError:
+    pass
+assert t.closed
+
+doc="suppress"
+with suppress(KeyError):
+    {}["missing"]
+with suppress(ValueError, KeyError):
+    raise KeyError
+with suppress(LookupError):
+    [][1]
+with suppress():
+    pass
+def not_suppressed():
+    with suppress(KeyError):
+        raise ValueError
+assertRaises(ValueError, not_suppressed)
+
+doc="finished"
@@ -0,0 +1,331 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Contextvars module
+//
+// Context variables hold state which is local to a Context.  There
+// is a single current Context which can be changed with Context.run.
+
+package contextvars
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	ContextVarType = py.NewTypeX("ContextVar", `ContextVar(name, *, default) -> a new context variable`, ContextVarNew, nil)
+	TokenType      = py.NewType("Token", `A Token is returned by ContextVar.set to restore the previous value`)
+	ContextType    = py.NewTypeX("Context", `Context() -> an empty context`, ContextNew, nil)
+	missingType    = py.NewType("Token.MISSING", `Marker for a ContextVar which had no value`)
+)
+
+// Missing is Token.MISSING which is used as the old value of a
+// ContextVar which wasn't set
+var Missing = &missing{}
+
+type missing struct{}
+
+// Type of this object
+func (m *missing) Type() *py.Type {
+	return missingType
+}
+
+func (m *missing) M__repr__() (py.Object, error) {
+	return py.String("<Token.MISSING>"), nil
+}
+
+// A Context is a mapping of ContextVars to their values
+type Context struct {
+	Vars    map[*ContextVar]py.Object
+	entered bool
+}
+
+// The currently active context
+var current = NewContext()
+
+// NewContext makes a new empty Context
+func NewContext() *Context {
+	return &Context{Vars: map[*ContextVar]py.Object{}}
+}
+
+// Type of this Context object
+func (ctx *Context) Type() *py.Type {
+	return ContextType
+}
+
+// ContextNew makes a new empty context
+func ContextNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "Context", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return NewContext(), nil
+}
+
+// Copy returns a shallow copy of the Context
+func (ctx *Context) Copy() *Context {
+	newCtx := NewContext()
+	for v, value := range ctx.Vars {
+		newCtx.Vars[v] = value
+	}
+	return newCtx
+}
+
+// Run calls fn with the Context as the current context, restoring
+// the previous context afterwards
+func (ctx *Context) Run(fn py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if ctx.entered {
+		return nil, py.ExceptionNewf(py.RuntimeError, "cannot enter context: %s is already entered", ctx.String())
+	}
+	ctx.entered = true
+	old := current
+	current = ctx
+	defer func() {
+		current = old
+		ctx.entered = false
+	}()
+	return py.Call(fn, args, kwargs)
+}
+
+// String returns a description of the Context for error messages
+func (ctx *Context) String() string {
+	return "<Context object>"
+}
+
+// Returns the ContextVar passed in or a TypeError
+func contextVar(key py.Object) (*ContextVar, error) {
+	v, ok := key.(*ContextVar)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "a ContextVar key was expected, got %s", key.Type().Name)
+	}
+	return v, nil
+}
+
+func (ctx *Context) M__getitem__(key py.Object) (py.Object, error) {
+	v, err := contextVar(key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := ctx.Vars[v]
+	if !ok {
+		return nil, py.ExceptionNewf(py.KeyError, "%s", v.Name)
+	}
+	return value, nil
+}
+
+func (ctx *Context) M__contains__(key py.Object) (py.Object, error) {
+	v, err := contextVar(key)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := ctx.Vars[v]
+	return py.NewBool(ok), nil
+}
+
+func (ctx *Context) M__len__() (py.Object, error) {
+	return py.Int(len(ctx.Vars)), nil
+}
+
+func (ctx *Context) M__iter__() (py.Object, error) {
+	keys := make(py.Tuple, 0, len(ctx.Vars))
+	for v := range ctx.Vars {
+		keys = append(keys, v)
+	}
+	return py.NewIterator(keys), nil
+}
+
+// A ContextVar is a variable whose value is stored in the current
+// Context
+type ContextVar struct {
+	Name    string
+	Default py.Object // nil if no default
+}
+
+// Type of this ContextVar object
+func (v *ContextVar) Type() *py.Type {
+	return ContextVarType
+}
+
+// ContextVarNew makes a new ContextVar
+func ContextVarNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name, def py.Object
+	if len(args) > 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "ContextVar() takes exactly 1 positional argument (%d given)", len(args))
+	}
+	err := py.ParseTupleAndKeywords(args, kwargs, "U|O:ContextVar", []string{"name", "default"}, &name, &def)
+	if err != nil {
+		return nil, err
+	}
+	return &ContextVar{Name: string(name.(py.String)), Default: def}, nil
+}
+
+// Get returns the value of the ContextVar in the current context
+//
+// If it isn't set then def is returned if not nil, otherwise the
+// default of the ContextVar, otherwise a LookupError is raised
+func (v *ContextVar) Get(def py.Object) (py.Object, error) {
+	if value, ok := current.Vars[v]; ok {
+		return value, nil
+	}
+	if def != nil {
+		return def, nil
+	}
+	if v.Default != nil {
+		return v.Default, nil
+	}
+	return nil, py.ExceptionNewf(py.LookupError, "%s", v.Name)
+}
+
+// Set sets the value of the ContextVar in the current context,
+// returning a Token which can be used to restore the old value
+func (v *ContextVar) Set(value py.Object) *Token {
+	old, ok := current.Vars[v]
+	if !ok {
+		old = Missing
+	}
+	current.Vars[v] = value
+	return &Token{Var: v, OldValue: old, ctx: current}
+}
+
+// Reset restores the ContextVar to the value it had before the Set
+// which made the token
+func (v *ContextVar) Reset(token *Token) error {
+	if token.used {
+		return py.ExceptionNewf(py.RuntimeError, "Token has already been used once")
+	}
+	if token.Var != v {
+		return py.ExceptionNewf(py.ValueError, "Token was created by a different ContextVar")
+	}
+	if token.ctx != current {
+		return py.ExceptionNewf(py.ValueError, "Token was created in a different Context")
+	}
+	token.used = true
+	if token.OldValue == Missing {
+		delete(current.Vars, v)
+	} else {
+		current.Vars[v] = token.OldValue
+	}
+	return nil
+}
+
+func (v *ContextVar) M__repr__() (py.Object, error) {
+	return py.String("<ContextVar name='" + v.Name + "'>"), nil
+}
+
+// A Token records the previous value of a ContextVar
+type Token struct {
+	Var      *ContextVar
+	OldValue py.Object // Missing if the var wasn't set
+	ctx      *Context
+	used     bool
+}
+
+// Type of this Token object
+func (t *Token) Type() *py.Type {
+	return TokenType
+}
+
+func (t *Token) M__repr__() (py.Object, error) {
+	used := ""
+	if t.used {
+		used = " used"
+	}
+	return py.String("<Token" + used + " var=<ContextVar name='" + t.Var.Name + "'>>"), nil
+}
+
+// Check interface is satisfied
+var _ py.I__getitem__ = (*Context)(nil)
+var _ py.I__contains__ = (*Context)(nil)
+var _ py.I__len__ = (*Context)(nil)
+var _ py.I__iter__ = (*Context)(nil)
+var _ py.I__repr__ = (*ContextVar)(nil)
+var _ py.I__repr__ = (*Token)(nil)
+
+const copy_context_doc = `copy_context() -> a copy of the current Context`
+
+func contextvars_copy_context(self py.Object) (py.Object, error) {
+	return current.Copy(), nil
+}
+
+const contextvars_doc = `Context Variables`
+
+// Initialise the module
+func init() {
+	ContextVarType.Dict["name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*ContextVar).Name), nil
+		},
+	}
+	ContextVarType.Dict["get"] = py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var def py.Object
+		err := py.UnpackTuple(args, nil, "get", 0, 1, &def)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*ContextVar).Get(def)
+	}, 0, "get([default]) -> the value of the variable in the current context")
+	ContextVarType.Dict["set"] = py.MustNewMethod("set", func(self py.Object, value py.Object) (py.Object, error) {
+		return self.(*ContextVar).Set(value), nil
+	}, 0, "set(value) -> Token\n\nSet the value of the variable in the current context.")
+	ContextVarType.Dict["reset"] = py.MustNewMethod("reset", func(self py.Object, arg py.Object) (py.Object, error) {
+		token, ok := arg.(*Token)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "expected an instance of Token, got %s", arg.Type().Name)
+		}
+		return py.None, self.(*ContextVar).Reset(token)
+	}, 0, "reset(token)\n\nReset the variable to the value it had before the set which made token.")
+
+	TokenType.Dict["MISSING"] = Missing
+	TokenType.Dict["var"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Token).Var, nil
+		},
+	}
+	TokenType.Dict["old_value"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Token).OldValue, nil
+		},
+	}
+
+	ContextType.Dict["run"] = py.MustNewMethod("run", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if len(args) < 1 {
+			return nil, py.ExceptionNewf(py.TypeError, "run() missing 1 required positional argument")
+		}
+		return self.(*Context).Run(args[0], args[1:], kwargs)
+	}, 0, "run(callable, *args, **kwargs)\n\nCall callable in this context.")
+	ContextType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*Context).Copy(), nil
+	}, 0, "copy() -> a shallow copy of the context")
+	ContextType.Dict["get"] = py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key py.Object
+		var def py.Object = py.None
+		err := py.UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		v, err := contextVar(key)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := self.(*Context).Vars[v]; ok {
+			return value, nil
+		}
+		return def, nil
+	}, 0, "get(var[, default]) -> the value of var in the context, else default")
+
+	methods := []*py.Method{
+		py.MustNewMethod("copy_context", contextvars_copy_context, 0, copy_context_doc),
+	}
+	globals := py.StringDict{
+		"Context":    ContextType,
+		"ContextVar": ContextVarType,
+		"Token":      TokenType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "contextvars",
+		Doc:     contextvars_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
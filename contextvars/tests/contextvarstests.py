@@ -0,0 +1,133 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import contextvars
+from contextvars import ContextVar, Token, copy_context
+from libtest import *
+
+doc="get with no value"
+v = ContextVar("v")
+assert v.name == "v"
+try:
+    v.get()
+except LookupError:
+    pass
+else:
+    assert False, "LookupError not raised"
+assert v.get(42) == 42
+assert v.get(None) is None
+
+doc="default"
+d = ContextVar("d", default=1)
+assert d.get() == 1
+assert d.get(2) == 2
+try:
+    ContextVar("d", 1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="set and reset"
+t1 = v.set(1)
+assert type(t1) is Token
+assert t1.var is v
+assert t1.old_value is Token.MISSING
+assert v.get() == 1
+assert v.get(42) == 1
+t2 = v.set(2)
+assert t2.old_value == 1
+assert v.get() == 2
+v.reset(t2)
+assert v.get() == 1
+v.reset(t1)
+try:
+    v.get()
+except LookupError:
+    pass
+else:
+    assert False, "LookupError not raised"
+
+doc="reset errors"
+t = v.set(3)
+v.reset(t)
+try:
+    v.reset(t)
+except RuntimeError:
+    pass
+else:
+    assert False, "RuntimeError not raised"
+t = d.set(3)
+try:
+    v.reset(t)
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+d.reset(t)
+assert d.get() == 1
+try:
+    v.reset(1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="copy_context and run"
+v.set("outer")
+ctx = copy_context()
+assert v in ctx
+assert ctx[v] == "outer"
+assert ctx.get(v) == "outer"
+assert ctx.get(d) is None
+assert ctx.get(d, 5) == 5
+assert d not in ctx
+def f(x, y=0):
+    assert v.get() == "outer"
+    v.set("inner")
+    assert v.get() == "inner"
+    return x + y
+assert ctx.run(f, 1, y=2) == 3
+assert v.get() == "outer"
+assert ctx[v] == "inner"
+try:
+    ctx[d]
+except KeyError:
+    pass
+else:
+    assert False, "KeyError not raised"
+
+doc="token from another context"
+t = v.set("x")
+def g():
+    try:
+        v.reset(t)
+    except ValueError:
+        pass
+    else:
+        assert False, "ValueError not raised"
+copy_context().run(g)
+v.reset(t)
+assert v.get() == "outer"
+
+doc="empty Context"
+ctx = contextvars.Context()
+assert len(ctx) == 0
+def h():
+    assert v.get("missing") == "missing"
+    v.set(1)
+ctx.run(h)
+assert len(ctx) == 1
+keys = list(ctx)
+assert len(keys) == 1 and keys[0] is v
+def nested():
+    ctx.run(h)
+try:
+    ctx.run(nested)
+except RuntimeError:
+    pass
+else:
+    assert False, "RuntimeError not raised"
+
+doc="finished"
@@ -0,0 +1,606 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CProfile module
+//
+// The profiler uses the profile events the vm sends for sys.setprofile
+// to count the calls of each function and the time spent in it.  The
+// statistics are printed in the same table as pstats prints them.
+// Saving the statistics to a file isn't supported.
+
+package cprofile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+const cprofile_doc = `Fast profiler for Python code.
+
+Profile counts the calls of each function and the time spent in it.
+run() and runctx() profile a statement and print the statistics.`
+
+var ProfileType = py.NewTypeX("Profile", profile_doc, ProfileNew, nil)
+
+const profile_doc = `Profile(timer=None, timeunit=None, subcalls=True, builtins=True)
+
+Builds a profiler object using the specified timer function.
+The default timer is a fast built-in one based on real time.
+For custom timer functions returning integers, timeunit can
+be a float specifying a scale (i.e. how long each integer unit
+is, in seconds).`
+
+// The default timer measures from here
+var timerStart = time.Now()
+
+// Stats are the statistics for one function
+type Stats struct {
+	Filename  string  // file of the function or "~" for built in ones
+	Lineno    int     // first line of the function or 0 for built in ones
+	Name      string  // name of the function
+	Calls     int     // number of calls
+	PrimCalls int     // number of calls which weren't recursive
+	Tottime   float64 // time spent in the function but not in the functions it called
+	Cumtime   float64 // time spent in the function including the functions it called
+	depth     int     // number of calls of the function in progress
+}
+
+// StdName returns the name of the function as pstats shows it
+func (s *Stats) StdName() string {
+	if s.Filename == "~" && s.Lineno == 0 {
+		if strings.HasPrefix(s.Name, "<") && strings.HasSuffix(s.Name, ">") {
+			return "{" + s.Name[1:len(s.Name)-1] + "}"
+		}
+		return s.Name
+	}
+	return fmt.Sprintf("%s:%d(%s)", s.Filename, s.Lineno, s.Name)
+}
+
+// A call in progress
+type call struct {
+	stats   *Stats
+	frame   *py.Frame // the frame of a python function
+	fn      py.Object // a built in function
+	start   float64   // time the call started
+	subtime float64   // time spent in the functions it called
+}
+
+// Profile is a profiler
+type Profile struct {
+	timer    py.Object // timer function or nil for the default
+	timeunit float64   // seconds per unit of timer or 0
+	builtins bool      // whether to profile built in functions
+	stats    map[interface{}]*Stats
+	calls    []*call
+	enabled  bool
+}
+
+// Type of this object
+func (p *Profile) Type() *py.Type {
+	return ProfileType
+}
+
+// NewProfile makes a profiler using timer which may be nil to measure
+// real time.  Calls of built in functions are profiled if builtins is
+// set.
+func NewProfile(timer py.Object, timeunit float64, builtins bool) *Profile {
+	return &Profile{
+		timer:    timer,
+		timeunit: timeunit,
+		builtins: builtins,
+		stats:    make(map[interface{}]*Stats),
+	}
+}
+
+// ProfileNew makes a Profile object from Python
+func ProfileNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var timer, timeunit, subcalls, builtins py.Object = py.None, py.None, py.True, py.True
+	kwlist := []string{"timer", "timeunit", "subcalls", "builtins"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOO:Profile", kwlist, &timer, &timeunit, &subcalls, &builtins)
+	if err != nil {
+		return nil, err
+	}
+	if timer == py.None {
+		timer = nil
+	}
+	unit := 0.0
+	if timeunit != py.None {
+		unit, err = py.FloatAsFloat64(timeunit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewProfile(timer, unit, py.ObjectIsTrue(builtins)), nil
+}
+
+// Returns the time now in seconds
+func (p *Profile) now() (float64, error) {
+	if p.timer == nil {
+		return time.Since(timerStart).Seconds(), nil
+	}
+	res, err := py.Call(p.timer, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	t, err := py.FloatAsFloat64(res)
+	if err != nil {
+		return 0, err
+	}
+	if p.timeunit != 0 {
+		t *= p.timeunit
+	}
+	return t, nil
+}
+
+// Returns the stats for the key, making them if necessary
+func (p *Profile) getStats(key interface{}, filename string, lineno int, name string) *Stats {
+	s, ok := p.stats[key]
+	if !ok {
+		s = &Stats{Filename: filename, Lineno: lineno, Name: name}
+		p.stats[key] = s
+	}
+	return s
+}
+
+// Returns the name of a built in function as pstats shows it
+func builtinName(fn py.Object) string {
+	switch f := fn.(type) {
+	case *py.Method:
+		return fmt.Sprintf("<built-in method %s>", f.Name)
+	case *py.BoundMethod:
+		if m, ok := f.Method.(*py.Method); ok {
+			return fmt.Sprintf("<method '%s' of '%s' objects>", m.Name, f.Self.Type().Name)
+		}
+	}
+	return fmt.Sprintf("<built-in method %s>", fn.Type().Name)
+}
+
+// Starts a call of the function with stats s
+func (p *Profile) push(s *Stats, frame *py.Frame, fn py.Object) error {
+	start, err := p.now()
+	if err != nil {
+		return err
+	}
+	s.depth++
+	p.calls = append(p.calls, &call{stats: s, frame: frame, fn: fn, start: start})
+	return nil
+}
+
+// Finishes the innermost call at time now
+func (p *Profile) pop(now float64) {
+	c := p.calls[len(p.calls)-1]
+	p.calls = p.calls[:len(p.calls)-1]
+	elapsed := now - c.start
+	s := c.stats
+	s.depth--
+	s.Calls++
+	s.Tottime += elapsed - c.subtime
+	if s.depth == 0 {
+		// Only the outermost of recursive calls counts to the
+		// cumulative time
+		s.PrimCalls++
+		s.Cumtime += elapsed
+	}
+	if len(p.calls) > 0 {
+		p.calls[len(p.calls)-1].subtime += elapsed
+	}
+}
+
+// Returns whether the innermost call is of frame or fn
+func (p *Profile) top(frame *py.Frame, fn py.Object) bool {
+	if len(p.calls) == 0 {
+		return false
+	}
+	c := p.calls[len(p.calls)-1]
+	return c.frame == frame && c.fn == fn
+}
+
+// Called by the vm for each profile event.  Returns from calls which
+// started before profiling was enabled are ignored.
+func (p *Profile) dispatch(frame *py.Frame, event string, arg py.Object) error {
+	switch event {
+	case "call":
+		code := frame.Code
+		return p.push(p.getStats(code, code.Filename, int(code.Firstlineno), code.Name), frame, nil)
+	case "c_call":
+		if p.builtins {
+			name := builtinName(arg)
+			return p.push(p.getStats(name, "~", 0, name), frame, arg)
+		}
+	case "return":
+		if p.top(frame, nil) {
+			now, err := p.now()
+			if err != nil {
+				return err
+			}
+			p.pop(now)
+		}
+	case "c_return", "c_exception":
+		if p.builtins && p.top(frame, arg) {
+			now, err := p.now()
+			if err != nil {
+				return err
+			}
+			p.pop(now)
+		}
+	}
+	return nil
+}
+
+// Enable starts profiling
+func (p *Profile) Enable() {
+	vm.SetProfile(p.dispatch)
+	p.enabled = true
+}
+
+// Disable stops profiling, finishing the calls in progress
+func (p *Profile) Disable() error {
+	if !p.enabled {
+		return nil
+	}
+	vm.SetProfile(nil)
+	p.enabled = false
+	now, err := p.now()
+	if err != nil {
+		return err
+	}
+	for len(p.calls) > 0 {
+		p.pop(now)
+	}
+	return nil
+}
+
+// Clear removes all the statistics
+func (p *Profile) Clear() {
+	p.stats = make(map[interface{}]*Stats)
+	p.calls = nil
+}
+
+// How the statistics can be sorted, with the name of each for the
+// "Ordered by" line
+var sortKeys = map[string]struct {
+	description string
+	less        func(a, b *Stats) bool
+}{
+	"calls":      {"call count", func(a, b *Stats) bool { return a.Calls > b.Calls }},
+	"cumulative": {"cumulative time", func(a, b *Stats) bool { return a.Cumtime > b.Cumtime }},
+	"filename":   {"file name", func(a, b *Stats) bool { return a.Filename < b.Filename }},
+	"line":       {"line number", func(a, b *Stats) bool { return a.Lineno < b.Lineno }},
+	"name":       {"function name", func(a, b *Stats) bool { return a.Name < b.Name }},
+	"nfl": {"name/file/line", func(a, b *Stats) bool {
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Lineno < b.Lineno
+	}},
+	"pcalls":  {"primitive call count", func(a, b *Stats) bool { return a.PrimCalls > b.PrimCalls }},
+	"stdname": {"standard name", func(a, b *Stats) bool { return a.StdName() < b.StdName() }},
+	"time":    {"internal time", func(a, b *Stats) bool { return a.Tottime > b.Tottime }},
+}
+
+// Other names for the sort keys
+var sortAliases = map[string]string{
+	"ncalls":  "calls",
+	"cumtime": "cumulative",
+	"file":    "filename",
+	"module":  "filename",
+	"tottime": "time",
+	"-1":      "stdname",
+	"0":       "calls",
+	"1":       "time",
+	"2":       "cumulative",
+}
+
+// Returns the name of the sort key for the sort argument
+func sortArg(sortBy py.Object) (string, error) {
+	var key string
+	switch s := sortBy.(type) {
+	case py.String:
+		key = string(s)
+	case py.Int:
+		key = fmt.Sprint(int(s))
+	default:
+		return "", py.ExceptionNewf(py.TypeError, "sort must be a str or int, not %s", sortBy.Type().Name)
+	}
+	if alias, ok := sortAliases[key]; ok {
+		key = alias
+	}
+	if _, ok := sortKeys[key]; !ok {
+		return "", py.ExceptionNewf(py.KeyError, "Unknown sort key %s", key)
+	}
+	return key, nil
+}
+
+// Sorted returns the statistics sorted by the sort key, with equal
+// ones in standard name order
+func (p *Profile) Sorted(key string) []*Stats {
+	stats := make([]*Stats, 0, len(p.stats))
+	for _, s := range p.stats {
+		stats = append(stats, s)
+	}
+	less := sortKeys[key].less
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.StdName() < b.StdName()
+	})
+	return stats
+}
+
+// Format returns the statistics as a table sorted by the sort key
+func (p *Profile) Format(key string) string {
+	var out strings.Builder
+	stats := p.Sorted(key)
+	calls, primCalls, total := 0, 0, 0.0
+	for _, s := range stats {
+		calls += s.Calls
+		primCalls += s.PrimCalls
+		total += s.Tottime
+	}
+	fmt.Fprintf(&out, "         %d function calls", calls)
+	if calls != primCalls {
+		fmt.Fprintf(&out, " (%d primitive calls)", primCalls)
+	}
+	fmt.Fprintf(&out, " in %.3f seconds\n\n", total)
+	fmt.Fprintf(&out, "   Ordered by: %s\n\n", sortKeys[key].description)
+	out.WriteString("   ncalls  tottime  percall  cumtime  percall filename:lineno(function)\n")
+	perCall := func(t float64, n int) string {
+		if n == 0 {
+			return "        "
+		}
+		return fmt.Sprintf("%8.3f", t/float64(n))
+	}
+	for _, s := range stats {
+		ncalls := fmt.Sprint(s.Calls)
+		if s.Calls != s.PrimCalls {
+			ncalls += fmt.Sprintf("/%d", s.PrimCalls)
+		}
+		fmt.Fprintf(&out, "%9s %8.3f %s %8.3f %s %s\n", ncalls, s.Tottime, perCall(s.Tottime, s.Calls), s.Cumtime, perCall(s.Cumtime, s.PrimCalls), s.StdName())
+	}
+	out.WriteString("\n\n")
+	return out.String()
+}
+
+// Writes s to sys.stdout
+func write(s string) error {
+	sys, err := py.GetModule("sys")
+	if err != nil {
+		return err
+	}
+	write, err := py.GetAttrString(sys.Globals["stdout"], "write")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(write, py.Tuple{py.String(s)}, nil)
+	return err
+}
+
+// Runs fn with profiling enabled
+func (p *Profile) runcall(fn py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	p.Enable()
+	res, err := py.Call(fn, args, kwargs)
+	derr := p.Disable()
+	if err == nil {
+		err = derr
+	}
+	return res, err
+}
+
+// Runs the source cmd in globals and locals with profiling enabled
+func (p *Profile) runctx(cmd, globals, locals py.Object) error {
+	source, err := py.StrAsString(cmd)
+	if err != nil {
+		return err
+	}
+	g, err := namespaceArg(globals, "globals")
+	if err != nil {
+		return err
+	}
+	l, err := namespaceArg(locals, "locals")
+	if err != nil {
+		return err
+	}
+	code, err := py.Compile(source, "<string>", "exec", 0, true)
+	if err != nil {
+		return err
+	}
+	p.Enable()
+	_, err = py.VmRun(g, l, code.(*py.Code), nil)
+	derr := p.Disable()
+	if err == nil {
+		err = derr
+	}
+	return err
+}
+
+// Returns the namespace for the globals or locals argument of runctx
+func namespaceArg(ns py.Object, name string) (py.StringDict, error) {
+	d, err := py.DictAsNamespace(ns)
+	if err != nil {
+		return nil, py.ExceptionNewf(py.TypeError, "runctx() %s must be a dict, not %s", name, ns.Type().Name)
+	}
+	return d, nil
+}
+
+// Returns the globals of the code calling the profiler which run()
+// runs the statement in
+func callerGlobals() (py.StringDict, error) {
+	if py.CurrentFrame == nil {
+		return nil, py.ExceptionNewf(py.SystemError, "run() called without a frame")
+	}
+	return py.CurrentFrame.Globals, nil
+}
+
+func init() {
+	ProfileType.Dict["enable"] = py.MustNewMethod("enable", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var subcalls, builtins py.Object = py.True, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:enable", []string{"subcalls", "builtins"}, &subcalls, &builtins)
+		if err != nil {
+			return nil, err
+		}
+		p := self.(*Profile)
+		if builtins != py.None {
+			p.builtins = py.ObjectIsTrue(builtins)
+		}
+		p.Enable()
+		return py.None, nil
+	}, 0, `enable(subcalls=True, builtins=True)
+
+Start collecting profiling information.
+If 'builtins' is true, records the time spent in
+built-in functions separately from their caller.`)
+	ProfileType.Dict["disable"] = py.MustNewMethod("disable", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*Profile).Disable()
+	}, 0, `disable()
+
+Stop collecting profiling information.`)
+	ProfileType.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object) (py.Object, error) {
+		self.(*Profile).Clear()
+		return py.None, nil
+	}, 0, `clear()
+
+Clear all profiling information collected so far.`)
+	ProfileType.Dict["runcall"] = py.MustNewMethod("runcall", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if len(args) < 1 {
+			return nil, py.ExceptionNewf(py.TypeError, "runcall() missing 1 required positional argument: 'func'")
+		}
+		return self.(*Profile).runcall(args[0], args[1:], kwargs)
+	}, 0, `runcall(func, *args, **kwargs) -> result of func
+
+Profile a call of func(*args, **kwargs).`)
+	ProfileType.Dict["run"] = py.MustNewMethod("run", func(self, cmd py.Object) (py.Object, error) {
+		globals, err := callerGlobals()
+		if err != nil {
+			return nil, err
+		}
+		return self, self.(*Profile).runctx(cmd, globals, globals)
+	}, 0, `run(cmd) -> self
+
+Profile the statements in cmd run in the globals of the caller.`)
+	ProfileType.Dict["runctx"] = py.MustNewMethod("runctx", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var cmd, globals, locals py.Object
+		err := py.UnpackTuple(args, nil, "runctx", 3, 3, &cmd, &globals, &locals)
+		if err != nil {
+			return nil, err
+		}
+		return self, self.(*Profile).runctx(cmd, globals, locals)
+	}, 0, `runctx(cmd, globals, locals) -> self
+
+Profile the statements in cmd run in globals and locals.`)
+	ProfileType.Dict["print_stats"] = py.MustNewMethod("print_stats", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var sortBy py.Object = py.Int(-1)
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:print_stats", []string{"sort"}, &sortBy)
+		if err != nil {
+			return nil, err
+		}
+		key, err := sortArg(sortBy)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, write(self.(*Profile).Format(key))
+	}, 0, `print_stats(sort=-1)
+
+Print the statistics sorted by sort which is one of "calls",
+"cumulative", "filename", "line", "name", "nfl", "pcalls", "stdname"
+or "time".  The default is "stdname".`)
+	ProfileType.Dict["__enter__"] = py.MustNewMethod("__enter__", func(self py.Object) (py.Object, error) {
+		self.(*Profile).Enable()
+		return self, nil
+	}, 0, "")
+	ProfileType.Dict["__exit__"] = py.MustNewMethod("__exit__", func(self py.Object, args py.Tuple) (py.Object, error) {
+		return py.None, self.(*Profile).Disable()
+	}, 0, "")
+	ProfileType.Dict["stats"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			p := self.(*Profile)
+			d := py.NewDictSized(len(p.stats))
+			for _, s := range p.Sorted("stdname") {
+				key := py.Tuple{py.String(s.Filename), py.Int(s.Lineno), py.String(s.Name)}
+				err := d.Set(key, py.Tuple{py.Int(s.PrimCalls), py.Int(s.Calls), py.Float(s.Tottime), py.Float(s.Cumtime), py.NewDict()})
+				if err != nil {
+					return nil, err
+				}
+			}
+			return d, nil
+		},
+	}
+}
+
+// Profiles the statement and prints the statistics
+func run(cmd, globals, locals, filename, sortBy py.Object) (py.Object, error) {
+	if filename != py.None {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "saving the profile to a file isn't supported")
+	}
+	key, err := sortArg(sortBy)
+	if err != nil {
+		return nil, err
+	}
+	p := NewProfile(nil, 0, true)
+	err = p.runctx(cmd, globals, locals)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, write(p.Format(key))
+}
+
+const run_doc = `run(statement, filename=None, sort=-1)
+
+Run statement under profiler, printing the statistics sorted by sort.
+
+The statement is run in the globals of the caller.  Saving the
+statistics to filename isn't supported.`
+
+func cprofile_run(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var statement, filename, sortBy py.Object = nil, py.None, py.Int(-1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:run", []string{"statement", "filename", "sort"}, &statement, &filename, &sortBy)
+	if err != nil {
+		return nil, err
+	}
+	globals, err := callerGlobals()
+	if err != nil {
+		return nil, err
+	}
+	return run(statement, globals, globals, filename, sortBy)
+}
+
+const runctx_doc = `runctx(statement, globals, locals, filename=None, sort=-1)
+
+Run statement under profiler with the given globals and locals,
+printing the statistics sorted by sort.`
+
+func cprofile_runctx(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var statement, globals, locals, filename, sortBy py.Object = nil, nil, nil, py.None, py.Int(-1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO|OO:runctx", []string{"statement", "globals", "locals", "filename", "sort"}, &statement, &globals, &locals, &filename, &sortBy)
+	if err != nil {
+		return nil, err
+	}
+	return run(statement, globals, locals, filename, sortBy)
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "cProfile",
+		Doc:  cprofile_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("run", cprofile_run, 0, run_doc),
+			py.MustNewMethod("runctx", cprofile_runctx, 0, runctx_doc),
+		},
+		Globals: py.StringDict{
+			"Profile": ProfileType,
+		},
+	})
+}
@@ -0,0 +1,132 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import cProfile
+import sys
+from libtest import *
+
+class Output:
+    def __init__(self):
+        self.text = ""
+    def write(self, s):
+        self.text += s
+
+def capture(fn, *args, **kwargs):
+    out = Output()
+    sys.stdout = out
+    try:
+        fn(*args, **kwargs)
+    finally:
+        sys.stdout = sys.__stdout__
+    return out.text
+
+def fib(n):
+    if n < 2:
+        return n
+    return fib(n-1) + fib(n-2)
+
+def work():
+    for s in ["a", "bc"]:
+        len(s)
+    return fib(5)
+
+def stats_by_name(pr):
+    """Returns the (primitive calls, calls, tottime, cumtime) by function name"""
+    return {name: value[:4] for (filename, lineno, name), value in pr.stats.items()}
+
+doc="runcall"
+pr = cProfile.Profile()
+assertEqual(pr.runcall(work), 5)
+stats = stats_by_name(pr)
+assertEqual(stats["work"][:2], (1, 1))
+assertEqual(stats["fib"][:2], (1, 15))
+assertEqual(stats["<built-in method len>"][:2], (2, 2))
+assert sys.getprofile() is None
+
+doc="timer"
+ticks = [0]
+def timer():
+    ticks[0] += 1
+    return ticks[0]
+pr = cProfile.Profile(timer)
+pr.runcall(fib, 2)
+stats = stats_by_name(pr)
+# fib(2) calls fib(1) and fib(0) which each take one tick
+assertEqual(stats["fib"], (1, 3, 5.0, 5.0))
+pr = cProfile.Profile(timer, 0.5)
+pr.runcall(fib, 1)
+assertEqual(stats_by_name(pr)["fib"], (1, 1, 0.5, 0.5))
+
+doc="builtins"
+pr = cProfile.Profile(builtins=False)
+pr.runcall(work)
+assert "<built-in method len>" not in stats_by_name(pr)
+
+doc="enable and disable"
+pr = cProfile.Profile()
+pr.enable()
+fib(3)
+pr.disable()
+fib(3)
+stats = stats_by_name(pr)
+assertEqual(stats["fib"][:2], (1, 5))
+assertEqual(stats["<method 'disable' of 'Profile' objects>"][:2], (1, 1))
+pr.clear()
+assertEqual(pr.stats, {})
+
+doc="context manager"
+with cProfile.Profile() as pr:
+    fib(2)
+assertEqual(stats_by_name(pr)["fib"][:2], (1, 3))
+assert sys.getprofile() is None
+
+doc="exception"
+def raises():
+    raise ValueError("profiled")
+pr = cProfile.Profile()
+assertRaises(ValueError, pr.runcall, raises)
+assertEqual(stats_by_name(pr)["raises"][:2], (1, 1))
+assert sys.getprofile() is None
+
+doc="print_stats"
+ticks = [0]
+pr = cProfile.Profile(timer)
+pr.runcall(work)
+text = capture(pr.print_stats)
+lines = text.split("\n")
+assertEqual(lines[0], "         18 function calls (4 primitive calls) in %.3f seconds" % sum(v[2] for v in pr.stats.values()))
+assertEqual(lines[2], "   Ordered by: standard name")
+assertEqual(lines[4], "   ncalls  tottime  percall  cumtime  percall filename:lineno(function)")
+assert lines[5].startswith("     15/1 "), lines[5]
+assert lines[5].endswith(":24(fib)"), lines[5]
+assert lines[6].endswith(":29(work)"), lines[6]
+assert lines[7].endswith(" {built-in method len}"), lines[7]
+assertEqual(lines[8:], ["", "", ""])
+
+text = capture(pr.print_stats, sort="calls")
+lines = text.split("\n")
+assertEqual(lines[2], "   Ordered by: call count")
+assert lines[5].endswith("(fib)")
+assert lines[6].endswith("{built-in method len}")
+text = capture(pr.print_stats, "cumulative")
+assertEqual(text.split("\n")[2], "   Ordered by: cumulative time")
+assertEqual(capture(pr.print_stats, 2), text)
+assertRaises(KeyError, pr.print_stats, "nonsense")
+
+doc="run"
+text = capture(cProfile.run, "work()")
+assert ":29(work)" in text
+assert "<string>:1(<module>)" in text
+text = capture(cProfile.runctx, "f(2)", {"f": fib}, {}, sort="name")
+assert "   Ordered by: function name" in text
+assert ":24(fib)" in text
+assertRaises(NotImplementedError, cProfile.run, "work()", "profile.out")
+pr = cProfile.Profile()
+assert pr.run("work()") is pr
+assertEqual(stats_by_name(pr)["work"][:2], (1, 1))
+namespace = {"f": fib}
+assert pr.runctx("x = f(4)", namespace, namespace) is pr
+assertEqual(namespace["x"], 3)
+
+doc="finished"
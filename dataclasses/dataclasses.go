@@ -0,0 +1,727 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Dataclasses module
+//
+// The dataclass decorator reads the fields of a class from its
+// __annotations__ and those of the data classes it inherits from, and
+// adds the methods asked for which aren't already defined.
+
+package dataclasses
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const module_doc = `Data classes: classes made mostly to hold data, with the special
+methods they need generated from their annotated fields.`
+
+// FrozenInstanceError is raised on assigning to a field of a frozen
+// data class
+var FrozenInstanceError = py.AttributeError.NewType("FrozenInstanceError", "", nil, nil)
+
+var ParamsType = py.NewType("_DataclassParams", "")
+
+// Params are the arguments dataclass was called with, kept in the
+// __dataclass_params__ of the class
+type Params struct {
+	Init       bool
+	Repr       bool
+	Eq         bool
+	Order      bool
+	UnsafeHash bool
+	Frozen     bool
+}
+
+// Type of this object
+func (p *Params) Type() *py.Type {
+	return ParamsType
+}
+
+func (p *Params) M__repr__() (py.Object, error) {
+	b := func(x bool) string {
+		if x {
+			return "True"
+		}
+		return "False"
+	}
+	return py.String(fmt.Sprintf("_DataclassParams(init=%s,repr=%s,eq=%s,order=%s,unsafe_hash=%s,frozen=%s)",
+		b(p.Init), b(p.Repr), b(p.Eq), b(p.Order), b(p.UnsafeHash), b(p.Frozen))), nil
+}
+
+const dataclass_doc = `dataclass(cls=None, /, *, init=True, repr=True, eq=True, order=False, unsafe_hash=False, frozen=False)
+
+Returns the same class as was passed in, with dunder methods
+added based on the fields defined in the class.
+
+Examines PEP 526 __annotations__ to determine fields.
+
+If init is true, an __init__() method is added to the class. If
+repr is true, a __repr__() method is added. If order is true, rich
+comparison dunder methods are added. If unsafe_hash is true, a
+__hash__() method function is added. If frozen is true, fields may
+not be assigned to after instance creation.`
+
+func dataclasses_dataclass(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var cls py.Object = py.None
+	err := py.UnpackTuple(args, nil, "dataclass", 0, 1, &cls)
+	if err != nil {
+		return nil, err
+	}
+	var init, repr, eq, order, unsafeHash, frozen py.Object = py.True, py.True, py.True, py.False, py.False, py.False
+	kwlist := []string{"init", "repr", "eq", "order", "unsafe_hash", "frozen"}
+	err = py.ParseTupleAndKeywords(nil, kwargs, "|$OOOOOO:dataclass", kwlist, &init, &repr, &eq, &order, &unsafeHash, &frozen)
+	if err != nil {
+		return nil, err
+	}
+	params := &Params{
+		Init:       py.ObjectIsTrue(init),
+		Repr:       py.ObjectIsTrue(repr),
+		Eq:         py.ObjectIsTrue(eq),
+		Order:      py.ObjectIsTrue(order),
+		UnsafeHash: py.ObjectIsTrue(unsafeHash),
+		Frozen:     py.ObjectIsTrue(frozen),
+	}
+	if cls != py.None {
+		return processClass(cls, params)
+	}
+	// Called with arguments so return the decorator
+	return py.MustNewMethod("wrap", func(self, cls py.Object) (py.Object, error) {
+		return processClass(cls, params)
+	}, 0, ""), nil
+}
+
+// Returns true if the annotation marks a class variable rather than a
+// field.  Annotations given as strings are recognised by name.
+func isClassVar(annotation py.Object) bool {
+	if s, ok := annotation.(py.String); ok {
+		s := strings.TrimPrefix(strings.TrimSpace(string(s)), "typing.")
+		return s == "ClassVar" || strings.HasPrefix(s, "ClassVar[")
+	}
+	typing, err := py.GetModule("typing")
+	if err != nil {
+		return false
+	}
+	classVar, ok := typing.Globals["ClassVar"]
+	if !ok {
+		return false
+	}
+	if annotation == classVar {
+		return true
+	}
+	origin, err := py.GetAttrString(annotation, "__origin__")
+	return err == nil && origin == classVar
+}
+
+// Returns true if the annotation marks an InitVar
+func isInitVar(annotation py.Object) bool {
+	if s, ok := annotation.(py.String); ok {
+		s := strings.TrimPrefix(strings.TrimSpace(string(s)), "dataclasses.")
+		return s == "InitVar" || strings.HasPrefix(s, "InitVar[")
+	}
+	_, ok := annotation.(*InitVar)
+	return ok
+}
+
+// Returns true if the class attribute name is set on cls itself
+func hasOwn(cls *py.Type, name string) bool {
+	_, ok := cls.Dict[name]
+	return ok
+}
+
+// Makes the field called name with the annotation typ from the class
+// attribute of the same name
+func getField(cls *py.Type, name string, typ py.Object) (*Field, error) {
+	var f *Field
+	switch x := cls.Dict[name].(type) {
+	case *Field:
+		f = x
+	case nil:
+		f = newField(MISSING, MISSING, true, true, py.None, true, py.None)
+	default:
+		f = newField(x, MISSING, true, true, py.None, true, py.None)
+	}
+	f.name = py.String(name)
+	f.typ = typ
+	switch {
+	case isClassVar(typ):
+		f.kind = classVarKind
+	case isInitVar(typ):
+		f.kind = initVarKind
+	}
+	if f.kind != fieldKind && f.defaultFactory != MISSING {
+		return nil, py.ExceptionNewf(py.TypeError, "field %s cannot have a default factory", name)
+	}
+	if f.kind == fieldKind {
+		switch f.defaultValue.(type) {
+		case *py.List, *py.Dict, *py.Set:
+			return nil, py.ExceptionNewf(py.ValueError, "mutable default <class '%s'> for field %s is not allowed: use default_factory", f.defaultValue.Type().Name, name)
+		}
+	}
+	return f, nil
+}
+
+// Returns the fields of the data class cls in order, or nil if it
+// isn't one
+func classFields(cls *py.Type) []*Field {
+	dict, ok := cls.Lookup("__dataclass_fields__").(*py.Dict)
+	if !ok {
+		return nil
+	}
+	var fields []*Field
+	for _, value := range dict.Values() {
+		if f, ok := value.(*Field); ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Sets the attribute name of cls to value if it isn't already set on
+// cls itself, returning true if it was set
+func setNew(cls *py.Type, name string, value py.Object) (bool, error) {
+	if hasOwn(cls, name) {
+		return false, nil
+	}
+	_, err := py.SetAttrString(cls, name, value)
+	return err == nil, err
+}
+
+// Makes the class cls a data class with params
+func processClass(clsObj py.Object, params *Params) (py.Object, error) {
+	cls, ok := clsObj.(*py.Type)
+	if !ok || !cls.Type().IsSubtype(py.TypeType) {
+		return nil, py.ExceptionNewf(py.TypeError, "dataclass() should be called on a class, not '%s'", clsObj.Type().Name)
+	}
+
+	// Collect the fields of the base classes, most distant first
+	// so nearer ones override them
+	fields := py.NewDict()
+	hasDataclassBases := false
+	anyFrozenBase := false
+	allFrozenBases := true
+	for i := len(cls.Mro) - 1; i > 0; i-- {
+		base, ok := cls.Mro[i].(*py.Type)
+		if !ok {
+			continue
+		}
+		baseFields, ok := base.Dict["__dataclass_fields__"].(*py.Dict)
+		if !ok {
+			continue
+		}
+		hasDataclassBases = true
+		for _, item := range baseFields.Items() {
+			err := fields.Set(item[0], item[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if baseParams, ok := base.Dict["__dataclass_params__"].(*Params); ok && baseParams.Frozen {
+			anyFrozenBase = true
+		} else {
+			allFrozenBases = false
+		}
+	}
+	if hasDataclassBases {
+		if anyFrozenBase && !params.Frozen {
+			return nil, py.ExceptionNewf(py.TypeError, "cannot inherit non-frozen dataclass from a frozen one")
+		}
+		if !allFrozenBases && params.Frozen {
+			return nil, py.ExceptionNewf(py.TypeError, "cannot inherit frozen dataclass from a non-frozen one")
+		}
+	}
+
+	// Add the fields of this class from its annotations
+	annotations, _ := cls.Dict["__annotations__"].(*py.Dict)
+	if annotations == nil {
+		annotations = py.NewDict()
+	}
+	for _, item := range annotations.Items() {
+		name, ok := item[0].(py.String)
+		if !ok {
+			continue
+		}
+		f, err := getField(cls, string(name), item[1])
+		if err != nil {
+			return nil, err
+		}
+		err = fields.Set(name, f)
+		if err != nil {
+			return nil, err
+		}
+		// Replace a field() in the class with its default
+		if _, ok := cls.Dict[string(name)].(*Field); ok {
+			if f.defaultValue == MISSING {
+				delete(cls.Dict, string(name))
+			} else {
+				cls.Dict[string(name)] = f.defaultValue
+			}
+			cls.Modified()
+		}
+	}
+	for name, value := range cls.Dict {
+		if _, ok := value.(*Field); ok {
+			if _, found, _ := annotations.Get(py.String(name)); !found {
+				return nil, py.ExceptionNewf(py.TypeError, "'%s' is a field but has no type annotation", name)
+			}
+		}
+	}
+	cls.Dict["__dataclass_fields__"] = fields
+	cls.Dict["__dataclass_params__"] = params
+
+	// The fields which are arguments of __init__ or attributes
+	var all []*Field
+	for _, value := range fields.Values() {
+		if f := value.(*Field); f.kind != classVarKind {
+			all = append(all, f)
+		}
+	}
+
+	classHash, hasHash := cls.Dict["__hash__"]
+	hasExplicitHash := hasHash && !(classHash == py.None && hasOwn(cls, "__eq__"))
+
+	if params.Order && !params.Eq {
+		return nil, py.ExceptionNewf(py.ValueError, "eq must be true if order is true")
+	}
+	if params.Init {
+		init, err := initMethod(cls, all, params.Frozen)
+		if err != nil {
+			return nil, err
+		}
+		_, err = setNew(cls, "__init__", init)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.Repr {
+		_, err := setNew(cls, "__repr__", reprMethod(cls, all))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.Eq {
+		_, err := setNew(cls, "__eq__", compareMethod(cls, "__eq__", all, py.Eq))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.Order {
+		for _, op := range []struct {
+			name string
+			fn   func(a, b py.Object) (py.Object, error)
+		}{
+			{"__lt__", py.Lt},
+			{"__le__", py.Le},
+			{"__gt__", py.Gt},
+			{"__ge__", py.Ge},
+		} {
+			set, err := setNew(cls, op.name, compareMethod(cls, op.name, all, op.fn))
+			if err != nil {
+				return nil, err
+			}
+			if !set {
+				return nil, py.ExceptionNewf(py.TypeError, "Cannot overwrite attribute %s in class %s. Consider using functools.total_ordering", op.name, cls.Name)
+			}
+		}
+	}
+	if params.Frozen {
+		setattr, delattr := frozenMethods(cls, all)
+		for _, method := range []*Method{setattr, delattr} {
+			set, err := setNew(cls, method.name, method)
+			if err != nil {
+				return nil, err
+			}
+			if !set {
+				return nil, py.ExceptionNewf(py.TypeError, "Cannot overwrite attribute %s in class %s", method.name, cls.Name)
+			}
+		}
+	}
+
+	// Decide what to do about __hash__ as CPython does
+	switch {
+	case params.UnsafeHash:
+		if hasExplicitHash {
+			return nil, py.ExceptionNewf(py.TypeError, "Cannot overwrite attribute __hash__ in class %s", cls.Name)
+		}
+		cls.Dict["__hash__"] = hashMethod(cls, all)
+	case params.Eq && params.Frozen && !hasExplicitHash:
+		cls.Dict["__hash__"] = hashMethod(cls, all)
+	case params.Eq && !hasExplicitHash:
+		cls.Dict["__hash__"] = py.None
+	}
+	cls.Modified()
+
+	if doc, ok := cls.Dict["__doc__"]; !ok || doc == py.None {
+		doc, err := signature(cls, all)
+		if err != nil {
+			return nil, err
+		}
+		cls.Dict["__doc__"] = py.String(doc)
+	}
+	if !hasOwn(cls, "__match_args__") {
+		var matchArgs py.Tuple
+		for _, f := range all {
+			if f.init && f.kind == fieldKind {
+				matchArgs = append(matchArgs, f.name)
+			}
+		}
+		cls.Dict["__match_args__"] = matchArgs
+	}
+	return cls, nil
+}
+
+// Returns the signature of the class for its __doc__, eg
+// "C(x: int, y: str = 'a')"
+func signature(cls *py.Type, fields []*Field) (string, error) {
+	var out bytes.Buffer
+	out.WriteString(cls.Name)
+	out.WriteString("(")
+	first := true
+	for _, f := range fields {
+		if !f.init {
+			continue
+		}
+		if !first {
+			out.WriteString(", ")
+		}
+		first = false
+		typ, err := typeName(f.typ)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&out, "%s: %s", f.Name(), typ)
+		switch {
+		case f.defaultFactory != MISSING:
+			out.WriteString(" = <factory>")
+		case f.defaultValue != MISSING:
+			repr, err := py.ReprAsString(f.defaultValue)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&out, " = %s", repr)
+		}
+	}
+	out.WriteString(")")
+	return out.String(), nil
+}
+
+// Returns the data class of obj, which may be an instance or the
+// class itself, or nil if it isn't one
+func dataclassOf(obj py.Object) *py.Type {
+	cls, ok := obj.(*py.Type)
+	if !ok || !cls.Type().IsSubtype(py.TypeType) {
+		cls = obj.Type()
+	}
+	if _, ok := cls.Lookup("__dataclass_fields__").(*py.Dict); !ok {
+		return nil
+	}
+	return cls
+}
+
+// Returns true if obj is an instance of a data class, not the class
+func isDataclassInstance(obj py.Object) bool {
+	if cls, ok := obj.(*py.Type); ok && cls.Type().IsSubtype(py.TypeType) {
+		return false
+	}
+	return dataclassOf(obj) != nil
+}
+
+const fields_doc = `Return a tuple describing the fields of this dataclass.
+
+Accepts a dataclass or an instance of one. Tuple elements are of
+type Field.`
+
+func dataclasses_fields(self, obj py.Object) (py.Object, error) {
+	cls := dataclassOf(obj)
+	if cls == nil {
+		return nil, py.ExceptionNewf(py.TypeError, "must be called with a dataclass type or instance")
+	}
+	var res py.Tuple
+	for _, f := range classFields(cls) {
+		if f.kind == fieldKind {
+			res = append(res, f)
+		}
+	}
+	if res == nil {
+		res = py.Tuple{}
+	}
+	return res, nil
+}
+
+const is_dataclass_doc = `Returns True if obj is a dataclass or an instance of a
+dataclass.`
+
+func dataclasses_is_dataclass(self, obj py.Object) (py.Object, error) {
+	return py.NewBool(dataclassOf(obj) != nil), nil
+}
+
+// Returns obj with the data class instances in it converted with fn,
+// recursing into lists, tuples and dicts
+func convert(obj py.Object, fn func(obj py.Object) (py.Object, error)) (py.Object, error) {
+	if isDataclassInstance(obj) {
+		return fn(obj)
+	}
+	convertAll := func(items py.Tuple) (py.Tuple, error) {
+		res := make(py.Tuple, len(items))
+		for i, item := range items {
+			var err error
+			res[i], err = convert(item, fn)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	switch x := obj.(type) {
+	case py.Tuple:
+		return convertAll(x)
+	case *py.List:
+		items, err := convertAll(x.Items)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewListFromItems(items), nil
+	case *py.Dict:
+		res := py.NewDict()
+		for _, item := range x.Items() {
+			pair, err := convertAll(item)
+			if err != nil {
+				return nil, err
+			}
+			err = res.Set(pair[0], pair[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	// Named tuples and other subclasses of tuple, list and dict
+	// are remade as their own type
+	t := obj.Type()
+	switch {
+	case t.IsSubtype(py.TupleType):
+		items, err := py.SequenceTuple(obj)
+		if err != nil {
+			return nil, err
+		}
+		items, err = convertAll(items)
+		if err != nil {
+			return nil, err
+		}
+		if t.Lookup("_fields") != nil {
+			return py.Call(t, items, nil)
+		}
+		return py.Call(t, py.Tuple{items}, nil)
+	case t.IsSubtype(py.ListType):
+		items, err := py.SequenceTuple(obj)
+		if err != nil {
+			return nil, err
+		}
+		items, err = convertAll(items)
+		if err != nil {
+			return nil, err
+		}
+		return py.Call(t, py.Tuple{items}, nil)
+	}
+	return obj, nil
+}
+
+const asdict_doc = `Return the fields of a dataclass instance as a new dictionary mapping
+field names to field values.
+
+Example usage:
+
+  @dataclass
+  class C:
+      x: int
+      y: int
+
+  c = C(1, 2)
+  assert asdict(c) == {'x': 1, 'y': 2}
+
+If given, 'dict_factory' will be used instead of built-in dict.
+The function applies recursively to field values that are
+dataclass instances. This will also look into built-in containers:
+tuples, lists, and dicts.`
+
+func dataclasses_asdict(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	var dictFactory py.Object = py.DictType
+	err := py.UnpackTuple(args, nil, "asdict", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	err = py.ParseTupleAndKeywords(nil, kwargs, "|$O:asdict", []string{"dict_factory"}, &dictFactory)
+	if err != nil {
+		return nil, err
+	}
+	if !isDataclassInstance(obj) {
+		return nil, py.ExceptionNewf(py.TypeError, "asdict() should be called on dataclass instances")
+	}
+	var asdict func(obj py.Object) (py.Object, error)
+	asdict = func(obj py.Object) (py.Object, error) {
+		items := py.NewList()
+		for _, f := range classFields(dataclassOf(obj)) {
+			if f.kind != fieldKind {
+				continue
+			}
+			value, err := py.GetAttrString(obj, f.Name())
+			if err != nil {
+				return nil, err
+			}
+			value, err = convert(value, asdict)
+			if err != nil {
+				return nil, err
+			}
+			items.Append(py.Tuple{f.name, value})
+		}
+		return py.Call(dictFactory, py.Tuple{items}, nil)
+	}
+	return asdict(obj)
+}
+
+const astuple_doc = `Return the fields of a dataclass instance as a new tuple of field values.
+
+Example usage::
+
+  @dataclass
+  class C:
+      x: int
+      y: int
+
+  c = C(1, 2)
+  assert astuple(c) == (1, 2)
+
+If given, 'tuple_factory' will be used instead of built-in tuple.
+The function applies recursively to field values that are
+dataclass instances. This will also look into built-in containers:
+tuples, lists, and dicts.`
+
+func dataclasses_astuple(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	var tupleFactory py.Object = py.TupleType
+	err := py.UnpackTuple(args, nil, "astuple", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	err = py.ParseTupleAndKeywords(nil, kwargs, "|$O:astuple", []string{"tuple_factory"}, &tupleFactory)
+	if err != nil {
+		return nil, err
+	}
+	if !isDataclassInstance(obj) {
+		return nil, py.ExceptionNewf(py.TypeError, "astuple() should be called on dataclass instances")
+	}
+	var astuple func(obj py.Object) (py.Object, error)
+	astuple = func(obj py.Object) (py.Object, error) {
+		items := py.NewList()
+		for _, f := range classFields(dataclassOf(obj)) {
+			if f.kind != fieldKind {
+				continue
+			}
+			value, err := py.GetAttrString(obj, f.Name())
+			if err != nil {
+				return nil, err
+			}
+			value, err = convert(value, astuple)
+			if err != nil {
+				return nil, err
+			}
+			items.Append(value)
+		}
+		return py.Call(tupleFactory, py.Tuple{items}, nil)
+	}
+	return astuple(obj)
+}
+
+const replace_doc = `Return a new object replacing specified fields with new values.
+
+This is especially useful for frozen classes.  Example usage:
+
+  @dataclass(frozen=True)
+  class C:
+      x: int
+      y: int
+
+  c = C(1, 2)
+  c1 = replace(c, x=3)
+  assert c1.x == 3 and c1.y == 2`
+
+func dataclasses_replace(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	err := py.UnpackTuple(args, nil, "replace", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if !isDataclassInstance(obj) {
+		return nil, py.ExceptionNewf(py.TypeError, "replace() should be called on dataclass instances")
+	}
+	changes := kwargs.Copy()
+	for _, f := range classFields(dataclassOf(obj)) {
+		name := f.Name()
+		if f.kind == classVarKind {
+			continue
+		}
+		if !f.init {
+			if _, ok := changes[name]; ok {
+				return nil, py.ExceptionNewf(py.ValueError, "field %s is declared with init=False, it cannot be specified with replace()", name)
+			}
+			continue
+		}
+		if _, ok := changes[name]; ok {
+			continue
+		}
+		if f.kind == initVarKind {
+			if !f.hasDefault() {
+				return nil, py.ExceptionNewf(py.ValueError, "InitVar '%s' must be specified with replace()", name)
+			}
+			continue
+		}
+		changes[name], err = py.GetAttrString(obj, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.Call(obj.Type(), nil, changes)
+}
+
+func init() {
+	for name, fget := range map[string]func(p *Params) bool{
+		"init":        func(p *Params) bool { return p.Init },
+		"repr":        func(p *Params) bool { return p.Repr },
+		"eq":          func(p *Params) bool { return p.Eq },
+		"order":       func(p *Params) bool { return p.Order },
+		"unsafe_hash": func(p *Params) bool { return p.UnsafeHash },
+		"frozen":      func(p *Params) bool { return p.Frozen },
+	} {
+		fget := fget
+		ParamsType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return py.NewBool(fget(self.(*Params))), nil
+			},
+		}
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "dataclasses",
+		Doc:  module_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("dataclass", dataclasses_dataclass, 0, dataclass_doc),
+			py.MustNewMethod("field", dataclasses_field, 0, field_doc),
+			py.MustNewMethod("fields", dataclasses_fields, 0, fields_doc),
+			py.MustNewMethod("is_dataclass", dataclasses_is_dataclass, 0, is_dataclass_doc),
+			py.MustNewMethod("asdict", dataclasses_asdict, 0, asdict_doc),
+			py.MustNewMethod("astuple", dataclasses_astuple, 0, astuple_doc),
+			py.MustNewMethod("replace", dataclasses_replace, 0, replace_doc),
+		},
+		Globals: py.StringDict{
+			"MISSING":             MISSING,
+			"Field":               FieldType,
+			"InitVar":             InitVarMarker,
+			"FrozenInstanceError": FrozenInstanceError,
+		},
+	})
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Fields of data classes
+
+package dataclasses
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	FieldType   = py.NewType("Field", "")
+	MissingType = py.NewType("_MISSING_TYPE", "")
+	InitVarType = py.NewType("InitVar", "")
+)
+
+// Missing is the type of MISSING
+type Missing struct{}
+
+// Type of this object
+func (m *Missing) Type() *py.Type {
+	return MissingType
+}
+
+// MISSING marks a field with no default or default factory
+var MISSING = &Missing{}
+
+// The kinds of field
+const (
+	fieldKind    = iota // a normal field
+	classVarKind        // a class variable annotated with ClassVar
+	initVarKind         // an argument to __init__ annotated with InitVar
+)
+
+var fieldKindNames = []string{"_FIELD", "_FIELD_CLASSVAR", "_FIELD_INITVAR"}
+
+// Field describes a field of a data class
+type Field struct {
+	name           py.Object
+	typ            py.Object
+	defaultValue   py.Object
+	defaultFactory py.Object
+	init           bool
+	repr           bool
+	hash           py.Object // None to use compare
+	compare        bool
+	metadata       py.Object
+	kind           int
+}
+
+// Type of this object
+func (f *Field) Type() *py.Type {
+	return FieldType
+}
+
+// Returns the name of the field
+func (f *Field) Name() string {
+	if name, ok := f.name.(py.String); ok {
+		return string(name)
+	}
+	return ""
+}
+
+// Returns true if the field has a default value or factory
+func (f *Field) hasDefault() bool {
+	return f.defaultValue != MISSING || f.defaultFactory != MISSING
+}
+
+// Returns true if the field is part of the hash of instances
+func (f *Field) hashed() bool {
+	if f.hash == py.None {
+		return f.compare
+	}
+	return py.ObjectIsTrue(f.hash)
+}
+
+func (f *Field) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("Field(")
+	for i, item := range []struct {
+		name  string
+		value py.Object
+	}{
+		{"name", f.name},
+		{"type", f.typ},
+		{"default", f.defaultValue},
+		{"default_factory", f.defaultFactory},
+		{"init", py.NewBool(f.init)},
+		{"repr", py.NewBool(f.repr)},
+		{"hash", f.hash},
+		{"compare", py.NewBool(f.compare)},
+		{"metadata", f.metadata},
+	} {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		repr, err := py.ReprAsString(item.value)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&out, "%s=%s", item.name, repr)
+	}
+	fmt.Fprintf(&out, ",_field_type=%s)", fieldKindNames[f.kind])
+	return py.String(out.String()), nil
+}
+
+const field_doc = `Return an object to identify dataclass fields.
+
+default is the default value of the field.  default_factory is a
+0-argument function called to initialize a field's value.  If init
+is True, the field will be a parameter to the class's __init__()
+function.  If repr is True, the field will be included in the
+object's repr().  If hash is True, the field will be included in
+the object's hash().  If compare is True, the field will be used
+in comparison functions.  metadata, if specified, must be a
+mapping which is stored but not otherwise examined by dataclass.
+
+It is an error to specify both default and default_factory.`
+
+func dataclasses_field(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var defaultValue, defaultFactory py.Object = MISSING, MISSING
+	var init, repr, hash, compare, metadata py.Object = py.True, py.True, py.None, py.True, py.None
+	kwlist := []string{"default", "default_factory", "init", "repr", "hash", "compare", "metadata"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|$OOOOOOO:field", kwlist, &defaultValue, &defaultFactory, &init, &repr, &hash, &compare, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	if defaultValue != MISSING && defaultFactory != MISSING {
+		return nil, py.ExceptionNewf(py.ValueError, "cannot specify both default and default_factory")
+	}
+	return newField(defaultValue, defaultFactory, py.ObjectIsTrue(init), py.ObjectIsTrue(repr), hash, py.ObjectIsTrue(compare), metadata), nil
+}
+
+// Makes a field whose name and type are set when its class is made a
+// data class
+func newField(defaultValue, defaultFactory py.Object, init, repr bool, hash py.Object, compare bool, metadata py.Object) *Field {
+	if metadata == py.None {
+		metadata = py.NewDict()
+	}
+	return &Field{
+		name:           py.None,
+		typ:            py.None,
+		defaultValue:   defaultValue,
+		defaultFactory: defaultFactory,
+		init:           init,
+		repr:           repr,
+		hash:           hash,
+		compare:        compare,
+		metadata:       metadata,
+	}
+}
+
+// InitVar is the type of an annotation marking an argument of
+// __init__ which is passed on to __post_init__ rather than stored
+type InitVar struct {
+	typ py.Object // nil for InitVar itself
+}
+
+// Type of this object
+func (v *InitVar) Type() *py.Type {
+	return InitVarType
+}
+
+// InitVarMarker is InitVar which makes InitVar[type] when subscripted
+var InitVarMarker = &InitVar{}
+
+func (v *InitVar) M__getitem__(key py.Object) (py.Object, error) {
+	return &InitVar{typ: key}, nil
+}
+
+func (v *InitVar) M__repr__() (py.Object, error) {
+	if v.typ == nil {
+		return py.String("dataclasses.InitVar"), nil
+	}
+	name, err := typeName(v.typ)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("dataclasses.InitVar[%s]", name)), nil
+}
+
+// Returns the name of a type for use in a signature, or its repr if
+// it isn't a type
+func typeName(t py.Object) (string, error) {
+	if cls, ok := t.(*py.Type); ok && cls.Type().IsSubtype(py.TypeType) {
+		return cls.Name, nil
+	}
+	return py.ReprAsString(t)
+}
+
+func init() {
+	for name, fget := range map[string]func(f *Field) py.Object{
+		"name":            func(f *Field) py.Object { return f.name },
+		"type":            func(f *Field) py.Object { return f.typ },
+		"default":         func(f *Field) py.Object { return f.defaultValue },
+		"default_factory": func(f *Field) py.Object { return f.defaultFactory },
+		"init":            func(f *Field) py.Object { return py.NewBool(f.init) },
+		"repr":            func(f *Field) py.Object { return py.NewBool(f.repr) },
+		"hash":            func(f *Field) py.Object { return f.hash },
+		"compare":         func(f *Field) py.Object { return py.NewBool(f.compare) },
+		"metadata":        func(f *Field) py.Object { return f.metadata },
+	} {
+		fget := fget
+		FieldType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return fget(self.(*Field)), nil
+			},
+		}
+	}
+	InitVarType.Dict["type"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			if typ := self.(*InitVar).typ; typ != nil {
+				return typ, nil
+			}
+			return py.None, nil
+		},
+	}
+}
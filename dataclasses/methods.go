@@ -0,0 +1,374 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Methods made for data classes
+//
+// Rather than generating python source for the methods as CPython
+// does, each method is a go function closed over the fields of the
+// class it was made for.
+
+package dataclasses
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+var MethodType = py.NewType("dataclass_method", "Method made by dataclass")
+
+// Method is a method made by dataclass, which is called with the
+// instance as the first argument
+type Method struct {
+	name  string
+	owner *py.Type
+	fn    func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error)
+}
+
+// Type of this object
+func (m *Method) Type() *py.Type {
+	return MethodType
+}
+
+// Binds the method to an instance
+func (m *Method) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, m), nil
+	}
+	return m, nil
+}
+
+func (m *Method) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "%s() missing 1 required positional argument: 'self'", m.name)
+	}
+	return m.fn(args[0], args[1:], kwargs)
+}
+
+func (m *Method) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<dataclass method %s.%s>", m.owner.Name, m.name)), nil
+}
+
+// Makes a method called name for the class cls which takes no
+// arguments other than the instance
+func newMethod0(cls *py.Type, name string, fn func(self py.Object) (py.Object, error)) *Method {
+	return &Method{name: name, owner: cls, fn: func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		err := py.UnpackTuple(args, kwargs, name, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return fn(self)
+	}}
+}
+
+// Makes a method called name for the class cls which takes one
+// argument other than the instance
+func newMethod1(cls *py.Type, name string, fn func(self, arg py.Object) (py.Object, error)) *Method {
+	return &Method{name: name, owner: cls, fn: func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var arg py.Object
+		err := py.UnpackTuple(args, kwargs, name, 1, 1, &arg)
+		if err != nil {
+			return nil, err
+		}
+		return fn(self, arg)
+	}}
+}
+
+// Returns the quoted names joined in the style of python's missing
+// argument errors, eg 'x', 'y' and 'z'
+func joinNames(names []string) string {
+	for i := range names {
+		names[i] = "'" + names[i] + "'"
+	}
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+}
+
+// Sets the attribute name of obj to value without calling the
+// __setattr__ of its class, as object.__setattr__ does
+func objectSetAttr(obj py.Object, name string, value py.Object) error {
+	if descr := obj.Type().NativeGetAttrOrNil(name); descr != nil {
+		if I, ok := descr.(py.I__set__); ok {
+			_, err := I.M__set__(obj, value)
+			return err
+		}
+	}
+	if I, ok := obj.(py.IGetDict); ok {
+		if dict := I.GetDict(); dict != nil {
+			dict[name] = value
+			return nil
+		}
+	}
+	return py.ExceptionNewf(py.AttributeError, "'%s' object has no attribute '%s'", obj.Type().Name, name)
+}
+
+// Deletes the attribute name of obj without calling the __delattr__
+// of its class, as object.__delattr__ does
+func objectDelAttr(obj py.Object, name string) error {
+	if descr := obj.Type().NativeGetAttrOrNil(name); descr != nil {
+		if I, ok := descr.(py.I__delete__); ok {
+			_, err := I.M__delete__(obj)
+			return err
+		}
+	}
+	if I, ok := obj.(py.IGetDict); ok {
+		if dict := I.GetDict(); dict != nil {
+			if _, found := dict[name]; found {
+				delete(dict, name)
+				return nil
+			}
+		}
+	}
+	return py.ExceptionNewf(py.AttributeError, "'%s' object has no attribute '%s'", obj.Type().Name, name)
+}
+
+// Makes __init__ which sets the fields from its arguments or their
+// defaults then calls __post_init__ with the InitVar arguments if the
+// class has one
+func initMethod(cls *py.Type, fields []*Field, frozen bool) (*Method, error) {
+	var params []*Field
+	seenDefault := false
+	for _, f := range fields {
+		if !f.init {
+			continue
+		}
+		if f.hasDefault() {
+			seenDefault = true
+		} else if seenDefault {
+			return nil, py.ExceptionNewf(py.TypeError, "non-default argument '%s' follows default argument", f.Name())
+		}
+		params = append(params, f)
+	}
+	hasPostInit := cls.Lookup("__post_init__") != nil
+	setAttr := py.SetAttrString
+	if frozen {
+		setAttr = func(obj py.Object, name string, value py.Object) (py.Object, error) {
+			return py.None, objectSetAttr(obj, name, value)
+		}
+	}
+	return &Method{name: "__init__", owner: cls, fn: func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if len(args) > len(params) {
+			return nil, py.ExceptionNewf(py.TypeError, "__init__() takes %d positional arguments but %d were given", len(params)+1, len(args)+1)
+		}
+		values := make(map[*Field]py.Object, len(params))
+		for i, arg := range args {
+			values[params[i]] = arg
+		}
+		for name, value := range kwargs {
+			var param *Field
+			for _, f := range params {
+				if f.Name() == name {
+					param = f
+					break
+				}
+			}
+			if param == nil {
+				return nil, py.ExceptionNewf(py.TypeError, "__init__() got an unexpected keyword argument '%s'", name)
+			}
+			if _, found := values[param]; found {
+				return nil, py.ExceptionNewf(py.TypeError, "__init__() got multiple values for argument '%s'", name)
+			}
+			values[param] = value
+		}
+		var missing []string
+		for _, f := range params {
+			if _, found := values[f]; !found && !f.hasDefault() {
+				missing = append(missing, f.Name())
+			}
+		}
+		if len(missing) > 0 {
+			plural := ""
+			if len(missing) > 1 {
+				plural = "s"
+			}
+			return nil, py.ExceptionNewf(py.TypeError, "__init__() missing %d required positional argument%s: %s", len(missing), plural, joinNames(missing))
+		}
+		var initVars py.Tuple
+		for _, f := range fields {
+			value, found := values[f]
+			if !found {
+				switch {
+				case f.defaultFactory != MISSING:
+					var err error
+					value, err = py.Call(f.defaultFactory, nil, nil)
+					if err != nil {
+						return nil, err
+					}
+				case f.init:
+					value = f.defaultValue
+				default:
+					// The class attribute holds the default
+					continue
+				}
+			}
+			if f.kind == initVarKind {
+				initVars = append(initVars, value)
+				continue
+			}
+			_, err := setAttr(self, f.Name(), value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if hasPostInit {
+			postInit, err := py.GetAttrString(self, "__post_init__")
+			if err != nil {
+				return nil, err
+			}
+			_, err = py.Call(postInit, initVars, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return py.None, nil
+	}}, nil
+}
+
+// Returns the values of the fields of obj for which use is true
+func fieldValues(obj py.Object, fields []*Field, use func(f *Field) bool) (py.Tuple, error) {
+	var values py.Tuple
+	for _, f := range fields {
+		if f.kind != fieldKind || !use(f) {
+			continue
+		}
+		value, err := py.GetAttrString(obj, f.Name())
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// The instances being repr-ed, to stop recursive data classes
+// recursing forever
+var inRepr = map[py.Object]struct{}{}
+
+// Makes __repr__ which shows the fields in the style of a call to
+// the class
+func reprMethod(cls *py.Type, fields []*Field) *Method {
+	return newMethod0(cls, "__repr__", func(self py.Object) (py.Object, error) {
+		if _, found := inRepr[self]; found {
+			return py.String("..."), nil
+		}
+		inRepr[self] = struct{}{}
+		defer delete(inRepr, self)
+		var out bytes.Buffer
+		qualname, err := py.GetAttrString(self.Type(), "__qualname__")
+		if err != nil {
+			return nil, err
+		}
+		name, err := py.StrAsString(qualname)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(name)
+		out.WriteString("(")
+		first := true
+		for _, f := range fields {
+			if f.kind != fieldKind || !f.repr {
+				continue
+			}
+			value, err := py.GetAttrString(self, f.Name())
+			if err != nil {
+				return nil, err
+			}
+			repr, err := py.ReprAsString(value)
+			if err != nil {
+				return nil, err
+			}
+			if !first {
+				out.WriteString(", ")
+			}
+			first = false
+			fmt.Fprintf(&out, "%s=%s", f.Name(), repr)
+		}
+		out.WriteString(")")
+		return py.String(out.String()), nil
+	})
+}
+
+// Makes the comparison method name which compares the tuples of the
+// fields of instances of the same class with op
+func compareMethod(cls *py.Type, name string, fields []*Field, op func(a, b py.Object) (py.Object, error)) *Method {
+	compared := func(f *Field) bool { return f.compare }
+	return newMethod1(cls, name, func(self, other py.Object) (py.Object, error) {
+		if other.Type() != self.Type() {
+			return py.NotImplemented, nil
+		}
+		a, err := fieldValues(self, fields, compared)
+		if err != nil {
+			return nil, err
+		}
+		b, err := fieldValues(other, fields, compared)
+		if err != nil {
+			return nil, err
+		}
+		return op(a, b)
+	})
+}
+
+// Makes __hash__ which hashes the tuple of the hashed fields
+func hashMethod(cls *py.Type, fields []*Field) *Method {
+	hashed := func(f *Field) bool { return f.hashed() }
+	return newMethod0(cls, "__hash__", func(self py.Object) (py.Object, error) {
+		values, err := fieldValues(self, fields, hashed)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := py.Hash(values)
+		if err != nil {
+			return nil, err
+		}
+		return py.Int(hash), nil
+	})
+}
+
+// Returns true if name is the name of one of the fields
+func isField(fields []*Field, name string) bool {
+	for _, f := range fields {
+		if f.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Makes __setattr__ and __delattr__ for a frozen class which raise
+// FrozenInstanceError for the fields
+func frozenMethods(cls *py.Type, fields []*Field) (setattr, delattr *Method) {
+	setattr = &Method{name: "__setattr__", owner: cls, fn: func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var nameObj, value py.Object
+		err := py.UnpackTuple(args, kwargs, "__setattr__", 2, 2, &nameObj, &value)
+		if err != nil {
+			return nil, err
+		}
+		name, err := py.AttributeName(nameObj)
+		if err != nil {
+			return nil, err
+		}
+		if self.Type() == cls || isField(fields, name) {
+			return nil, py.ExceptionNewf(FrozenInstanceError, "cannot assign to field '%s'", name)
+		}
+		return py.None, objectSetAttr(self, name, value)
+	}}
+	delattr = newMethod1(cls, "__delattr__", func(self, nameObj py.Object) (py.Object, error) {
+		name, err := py.AttributeName(nameObj)
+		if err != nil {
+			return nil, err
+		}
+		if self.Type() == cls || isField(fields, name) {
+			return nil, py.ExceptionNewf(FrozenInstanceError, "cannot delete field '%s'", name)
+		}
+		return py.None, objectDelAttr(self, name)
+	})
+	return setattr, delattr
+}
@@ -0,0 +1,261 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import dataclasses
+from dataclasses import dataclass, field, fields, asdict, astuple, replace, is_dataclass, InitVar, FrozenInstanceError, MISSING
+from libtest import *
+
+doc = "init and repr"
+@dataclass
+class Point:
+    x: int
+    y: int = 0
+p = Point(1, 2)
+assert p.x == 1 and p.y == 2
+assert Point(1).y == 0
+assert Point(y=3, x=4) == Point(4, 3)
+assert repr(p) == "Point(x=1, y=2)"
+assert Point.__doc__ == "Point(x: int, y: int = 0)"
+assert Point.__match_args__ == ("x", "y")
+assertRaises(TypeError, Point)
+assertRaises(TypeError, Point, 1, 2, 3)
+assertRaises(TypeError, Point, 1, z=3)
+assertRaises(TypeError, Point, 1, x=3)
+try:
+    Point()
+except TypeError as e:
+    assert str(e) == "__init__() missing 1 required positional argument: 'x'", str(e)
+else:
+    assert False, "TypeError not raised"
+
+doc = "decorator with arguments"
+@dataclass(repr=False, eq=False)
+class Plain:
+    a: str
+assert Plain("x").a == "x"
+assert not repr(Plain("x")).startswith("Plain(")
+assert Plain("x") != Plain("x")
+
+doc = "user methods are kept"
+@dataclass
+class Own:
+    a: int
+    def __repr__(self):
+        return "own"
+assert repr(Own(1)) == "own"
+
+doc = "eq and hash"
+assert Point(1, 2) == Point(1, 2)
+assert Point(1, 2) != Point(2, 1)
+assert Point(1, 2) != (1, 2)
+assert Point.__hash__ is None
+assertRaises(TypeError, hash, Point(1, 2))
+
+doc = "order"
+@dataclass(order=True)
+class Version:
+    major: int
+    minor: int = 0
+assert Version(1, 2) < Version(1, 3)
+assert Version(2) > Version(1, 9)
+assert Version(1, 2) <= Version(1, 2)
+assert Version(1, 2) >= Version(1, 1)
+assert sorted([Version(2), Version(1, 5), Version(1)]) == [Version(1), Version(1, 5), Version(2)]
+assertRaises(TypeError, lambda: Version(1) < 1)
+try:
+    @dataclass(order=True, eq=False)
+    class Bad:
+        a: int
+except ValueError as e:
+    assert str(e) == "eq must be true if order is true"
+else:
+    assert False, "ValueError not raised"
+try:
+    @dataclass(order=True)
+    class Bad:
+        a: int
+        def __lt__(self, other):
+            return True
+except TypeError as e:
+    assert str(e) == "Cannot overwrite attribute __lt__ in class Bad. Consider using functools.total_ordering"
+else:
+    assert False, "TypeError not raised"
+
+doc = "field"
+@dataclass
+class Box:
+    items: list = field(default_factory=list)
+    label: str = field(default="box", repr=False)
+    count: int = field(default=0, compare=False)
+a = Box()
+b = Box()
+a.items.append(1)
+assert b.items == []
+assert repr(a) == "Box(items=[1], count=0)"
+assert Box([1], count=5) == Box([1], count=6)
+assert Box.label == "box"
+assert not hasattr(Box, "items")
+assert Box.__doc__ == "Box(items: list = <factory>, label: str = 'box', count: int = 0)"
+assertRaises(ValueError, field, default=1, default_factory=list)
+try:
+    @dataclass
+    class Bad:
+        items: list = []
+except ValueError as e:
+    assert str(e) == "mutable default <class 'list'> for field items is not allowed: use default_factory", str(e)
+else:
+    assert False, "ValueError not raised"
+try:
+    @dataclass
+    class Bad:
+        a: int = 1
+        b: int
+except TypeError as e:
+    assert str(e) == "non-default argument 'b' follows default argument"
+else:
+    assert False, "TypeError not raised"
+
+doc = "init=False"
+@dataclass
+class Counter:
+    name: str
+    total: int = field(default=0, init=False)
+c = Counter("c")
+assert c.total == 0
+assertRaises(TypeError, Counter, "c", 1)
+
+doc = "fields"
+fs = fields(Box)
+assert [f.name for f in fs] == ["items", "label", "count"]
+assert fs[0].type is list
+assert fs[0].default is MISSING
+assert fs[0].default_factory is list
+assert fs[1].default == "box"
+assert fs[1].repr == False
+assert fs[2].compare == False
+assert fs[0].metadata == {}
+assert fields(Box()) == fs
+assert isinstance(fs[0], dataclasses.Field)
+assert repr(fs[1]).startswith("Field(name='label',type=<class 'str'>,default='box',")
+assertRaises(TypeError, fields, 1)
+assertRaises(TypeError, fields, int)
+assert is_dataclass(Box) and is_dataclass(Box())
+assert not is_dataclass(1) and not is_dataclass(int)
+
+doc = "class variables"
+@dataclass
+class WithClassVar:
+    x: int
+    count: "ClassVar[int]" = 0
+assert [f.name for f in fields(WithClassVar)] == ["x"]
+assert WithClassVar(1).count == 0
+assert repr(WithClassVar(1)) == "WithClassVar(x=1)"
+
+doc = "InitVar and __post_init__"
+@dataclass
+class Scaled:
+    value: int
+    scale: InitVar[int] = 1
+    def __post_init__(self, scale):
+        self.value *= scale
+assert Scaled(2).value == 2
+assert Scaled(2, 3).value == 6
+assert [f.name for f in fields(Scaled)] == ["value"]
+assert repr(InitVar[int]) == "dataclasses.InitVar[int]"
+assert not hasattr(Scaled(1), "scale") or Scaled(1).scale == 1
+
+doc = "frozen"
+@dataclass(frozen=True)
+class Frozen:
+    x: int
+    y: int = 0
+f = Frozen(1)
+try:
+    f.x = 2
+except FrozenInstanceError as e:
+    assert str(e) == "cannot assign to field 'x'"
+else:
+    assert False, "FrozenInstanceError not raised"
+try:
+    del f.y
+except FrozenInstanceError:
+    pass
+else:
+    assert False, "FrozenInstanceError not raised"
+assert issubclass(FrozenInstanceError, AttributeError)
+assert f.x == 1
+assert hash(Frozen(1, 2)) == hash(Frozen(1, 2))
+assert {Frozen(1): "a"}[Frozen(1)] == "a"
+try:
+    @dataclass
+    class Thawed(Frozen):
+        z: int = 0
+except TypeError as e:
+    assert str(e) == "cannot inherit non-frozen dataclass from a frozen one"
+else:
+    assert False, "TypeError not raised"
+
+doc = "unsafe_hash"
+@dataclass(unsafe_hash=True)
+class Hashed:
+    a: int
+    b: int = field(default=0, hash=False)
+assert hash(Hashed(1, 2)) == hash(Hashed(1, 3))
+
+doc = "inheritance"
+@dataclass
+class Base:
+    x: int
+    y: int = 1
+@dataclass
+class Derived(Base):
+    z: int = 2
+    x: int = 0
+d = Derived()
+assert repr(d) == "Derived(x=0, y=1, z=2)"
+assert [f.name for f in fields(Derived)] == ["x", "y", "z"]
+assert Derived(5, 6, 7) == Derived(5, 6, 7)
+assert Derived(1) != Base(1)
+
+doc = "asdict and astuple"
+@dataclass
+class Line:
+    start: Point
+    end: Point
+    tags: list = field(default_factory=list)
+line = Line(Point(0, 0), Point(1, 1), [Point(2, 2)])
+assert asdict(line) == {"start": {"x": 0, "y": 0}, "end": {"x": 1, "y": 1}, "tags": [{"x": 2, "y": 2}]}
+assert astuple(line) == ((0, 0), (1, 1), [(2, 2)])
+assert list(asdict(Point(1, 2)).keys()) == ["x", "y"]
+assert asdict(Point(1, 2), dict_factory=list) == [("x", 1), ("y", 2)]
+assert astuple(Point(1, 2), tuple_factory=list) == [1, 2]
+assertRaises(TypeError, asdict, 1)
+assertRaises(TypeError, asdict, Point)
+assertRaises(TypeError, astuple, (1, 2))
+
+doc = "replace"
+p = Point(1, 2)
+q = replace(p, y=5)
+assert q == Point(1, 5) and p == Point(1, 2)
+assert replace(Frozen(1), x=3) == Frozen(3)
+try:
+    replace(Counter("c"), total=1)
+except ValueError as e:
+    assert str(e) == "field total is declared with init=False, it cannot be specified with replace()"
+else:
+    assert False, "ValueError not raised"
+assertRaises(TypeError, replace, p, z=1)
+assertRaises(TypeError, replace, 1)
+
+doc = "missing annotation"
+try:
+    @dataclass
+    class Bad:
+        x = field()
+except TypeError as e:
+    assert str(e) == "'x' is a field but has no type annotation"
+else:
+    assert False, "TypeError not raised"
+
+doc = "finished"
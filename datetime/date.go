@@ -0,0 +1,367 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+var DateType = py.ObjectType.NewTypeFlags("date", date_doc, DateNew, nil, py.ObjectType.Flags|subclassFlags)
+
+const date_doc = `date(year, month, day) --> date object`
+
+// Date is a date in the proleptic gregorian calendar
+type Date struct {
+	object
+	year  int
+	month int
+	day   int
+}
+
+// dateLike is implemented by date and datetime
+type dateLike interface {
+	py.Object
+	date() *Date
+}
+
+// Returns the date part of the object
+func (d *Date) date() *Date {
+	return d
+}
+
+// NewDate returns a date checking the fields are valid
+func NewDate(year, month, day int) (*Date, error) {
+	err := checkDate(year, month, day)
+	if err != nil {
+		return nil, err
+	}
+	return &Date{object: newObject(DateType), year: year, month: month, day: day}, nil
+}
+
+// Makes a date of class cls which may be a python subclass
+func makeDate(cls *py.Type, year, month, day int) (py.Object, error) {
+	if cls == DateType {
+		return NewDate(year, month, day)
+	}
+	if cls.IsSubtype(DateTimeType) {
+		return makeDateTime(cls, year, month, day, 0, 0, 0, 0, nil, 0)
+	}
+	return py.Call(cls, py.Tuple{py.Int(year), py.Int(month), py.Int(day)}, nil)
+}
+
+// DateNew makes a date from year, month and day
+func DateNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var yearObj, monthObj, dayObj py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO:date", []string{"year", "month", "day"}, &yearObj, &monthObj, &dayObj)
+	if err != nil {
+		return nil, err
+	}
+	var year, month, day int
+	err = getInts([]py.Object{yearObj, monthObj, dayObj}, &year, &month, &day)
+	if err != nil {
+		return nil, err
+	}
+	err = checkDate(year, month, day)
+	if err != nil {
+		return nil, err
+	}
+	return &Date{object: newObject(metatype), year: year, month: month, day: day}, nil
+}
+
+// Type of this object
+func (d *Date) Type() *py.Type {
+	return d.Base
+}
+
+// Returns the go time of the start of the day in UTC
+func (d *Date) goTime() time.Time {
+	return time.Date(d.year, time.Month(d.month), d.day, 0, 0, 0, 0, time.UTC)
+}
+
+// Returns the ordinal of the date
+func (d *Date) toOrdinal() int {
+	return toOrdinal(d.year, d.month, d.day)
+}
+
+// Returns the day of the week with Monday as 0
+func (d *Date) weekday() int {
+	return (int(d.goTime().Weekday()) + 6) % 7
+}
+
+func (d *Date) isoformat() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.year, d.month, d.day)
+}
+
+func (d *Date) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("%s(%d, %d, %d)", typeName(d.Base), d.year, d.month, d.day)), nil
+}
+
+func (d *Date) M__str__() (py.Object, error) {
+	return py.String(d.isoformat()), nil
+}
+
+func (d *Date) M__format__(formatSpec py.Object) (py.Object, error) {
+	return formatObject(d, formatSpec)
+}
+
+func (d *Date) M__hash__() (py.Object, error) {
+	return py.Tuple{py.Int(d.year), py.Int(d.month), py.Int(d.day)}.M__hash__()
+}
+
+// Returns the date days after d
+func (d *Date) addDays(days int) (py.Object, error) {
+	year, month, day, err := fromOrdinal(d.toOrdinal() + days)
+	if err != nil {
+		return nil, err
+	}
+	return makeDate(resultType(d.Base, DateType), year, month, day)
+}
+
+func (d *Date) M__add__(other py.Object) (py.Object, error) {
+	if td, ok := other.(*TimeDelta); ok {
+		return d.addDays(td.days)
+	}
+	return py.NotImplemented, nil
+}
+
+func (d *Date) M__radd__(other py.Object) (py.Object, error) {
+	return d.M__add__(other)
+}
+
+func (d *Date) M__sub__(other py.Object) (py.Object, error) {
+	switch b := other.(type) {
+	case *TimeDelta:
+		return d.addDays(-b.days)
+	case *Date:
+		return NewTimeDelta(int64(d.toOrdinal()-b.toOrdinal()) * usPerDay), nil
+	}
+	return py.NotImplemented, nil
+}
+
+// Compares d with other returning -1, 0, 1 or false if other isn't a date
+func (d *Date) compare(other py.Object) (int, bool) {
+	b, ok := other.(*Date)
+	if !ok {
+		return 0, false
+	}
+	return compareInts([]int{d.year, d.month, d.day}, []int{b.year, b.month, b.day}), true
+}
+
+// Compares a and b lexicographically
+func compareInts(a, b []int) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+func (d *Date) M__eq__(other py.Object) (py.Object, error) {
+	c, ok := d.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c == 0), nil
+}
+
+func (d *Date) M__ne__(other py.Object) (py.Object, error) {
+	c, ok := d.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c != 0), nil
+}
+
+func (d *Date) M__lt__(other py.Object) (py.Object, error) {
+	c, ok := d.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c < 0), nil
+}
+
+func (d *Date) M__le__(other py.Object) (py.Object, error) {
+	c, ok := d.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c <= 0), nil
+}
+
+func (d *Date) M__gt__(other py.Object) (py.Object, error) {
+	c, ok := d.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c > 0), nil
+}
+
+func (d *Date) M__ge__(other py.Object) (py.Object, error) {
+	c, ok := d.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c >= 0), nil
+}
+
+// Returns the date of the ISO year, week and weekday
+func fromISOCalendar(year, week, weekday int) (int, int, int, error) {
+	if year < MINYEAR || year > MAXYEAR {
+		return 0, 0, 0, py.ExceptionNewf(py.ValueError, "Year is out of range: %d", year)
+	}
+	if weekday < 1 || weekday > 7 {
+		return 0, 0, 0, py.ExceptionNewf(py.ValueError, "Invalid day: %d (range is [1, 7])", weekday)
+	}
+	if week < 1 || week > 53 {
+		return 0, 0, 0, py.ExceptionNewf(py.ValueError, "Invalid week: %d", week)
+	}
+	// Week 1 is the week containing the 4th of January
+	jan4 := toOrdinal(year, 1, 4)
+	monday := jan4 - (int(ordinalTime(jan4).Weekday())+6)%7
+	n := monday + (week-1)*7 + weekday - 1
+	if isoYear, _ := ordinalTime(n).ISOWeek(); week == 53 && isoYear != year {
+		return 0, 0, 0, py.ExceptionNewf(py.ValueError, "Invalid week: %d", week)
+	}
+	return fromOrdinal(n)
+}
+
+// Returns the local date and time of the unix timestamp ts
+func fromTimestamp(ts py.Object, loc *time.Location) (time.Time, error) {
+	f, err := py.FloatAsFloat64(ts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	us, err := roundFloat(f * usPerSecond)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, rem := us/usPerSecond, us%usPerSecond
+	if rem < 0 {
+		sec, rem = sec-1, rem+usPerSecond
+	}
+	return time.Unix(sec, rem*1000).In(loc), nil
+}
+
+func init() {
+	DateType.Dict["year"] = intProperty(func(self py.Object) int { return self.(dateLike).date().year }, "")
+	DateType.Dict["month"] = intProperty(func(self py.Object) int { return self.(dateLike).date().month }, "")
+	DateType.Dict["day"] = intProperty(func(self py.Object) int { return self.(dateLike).date().day }, "")
+	DateType.Dict["today"] = classMethod("today", func(cls py.Object) (py.Object, error) {
+		now := time.Now()
+		if cls.(*py.Type).IsSubtype(DateTimeType) {
+			return makeDateTimeFromGo(cls.(*py.Type), now, nil)
+		}
+		return makeDate(cls.(*py.Type), now.Year(), int(now.Month()), now.Day())
+	}, "Current date or datetime:  same as self.__class__.fromtimestamp(time.time()).")
+	DateType.Dict["fromtimestamp"] = classMethod("fromtimestamp", func(cls, ts py.Object) (py.Object, error) {
+		t, err := fromTimestamp(ts, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		return makeDate(cls.(*py.Type), t.Year(), int(t.Month()), t.Day())
+	}, "Create a date from a POSIX timestamp.\n\nThe timestamp is a number, e.g. created via time.time(), that is interpreted\nas local time.")
+	DateType.Dict["fromordinal"] = classMethod("fromordinal", func(cls, n py.Object) (py.Object, error) {
+		ordinal, err := getInt(n)
+		if err != nil {
+			return nil, err
+		}
+		if ordinal < 1 {
+			return nil, py.ExceptionNewf(py.ValueError, "ordinal must be >= 1")
+		}
+		year, month, day, err := fromOrdinal(ordinal)
+		if err != nil {
+			return nil, err
+		}
+		return makeDate(cls.(*py.Type), year, month, day)
+	}, "int -> date corresponding to a proleptic Gregorian ordinal.")
+	DateType.Dict["fromisoformat"] = classMethod("fromisoformat", func(cls, s py.Object) (py.Object, error) {
+		str, ok := s.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "fromisoformat: argument must be str")
+		}
+		year, month, day, err := parseISODate(string(str))
+		if err != nil {
+			return nil, err
+		}
+		return makeDate(cls.(*py.Type), year, month, day)
+	}, "str -> Construct a date from a string in ISO 8601 format.")
+	DateType.Dict["fromisocalendar"] = classMethod("fromisocalendar", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var yearObj, weekObj, dayObj py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "OOO:fromisocalendar", []string{"year", "week", "day"}, &yearObj, &weekObj, &dayObj)
+		if err != nil {
+			return nil, err
+		}
+		var isoYear, week, weekday int
+		err = getInts([]py.Object{yearObj, weekObj, dayObj}, &isoYear, &week, &weekday)
+		if err != nil {
+			return nil, err
+		}
+		year, month, day, err := fromISOCalendar(isoYear, week, weekday)
+		if err != nil {
+			return nil, err
+		}
+		return makeDate(cls.(*py.Type), year, month, day)
+	}, "int, int, int -> Construct a date from the ISO year, week number and weekday.\n\nThis is the inverse of the date.isocalendar() function")
+	addMethods(DateType,
+		py.MustNewMethod("replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			d := self.(*Date)
+			var yearObj, monthObj, dayObj py.Object
+			err := py.ParseTupleAndKeywords(args, kwargs, "|OOO:replace", []string{"year", "month", "day"}, &yearObj, &monthObj, &dayObj)
+			if err != nil {
+				return nil, err
+			}
+			year, month, day := d.year, d.month, d.day
+			err = getInts([]py.Object{yearObj, monthObj, dayObj}, &year, &month, &day)
+			if err != nil {
+				return nil, err
+			}
+			err = checkDate(year, month, day)
+			if err != nil {
+				return nil, err
+			}
+			return makeDate(resultType(d.Base, DateType), year, month, day)
+		}, 0, "Return date with new specified fields."),
+		py.MustNewMethod("toordinal", func(self py.Object) (py.Object, error) {
+			return py.Int(self.(dateLike).date().toOrdinal()), nil
+		}, 0, "Return proleptic Gregorian ordinal.  January 1 of year 1 is day 1."),
+		py.MustNewMethod("weekday", func(self py.Object) (py.Object, error) {
+			return py.Int(self.(dateLike).date().weekday()), nil
+		}, 0, "Return the day of the week represented by the date.\nMonday == 0 ... Sunday == 6"),
+		py.MustNewMethod("isoweekday", func(self py.Object) (py.Object, error) {
+			return py.Int(self.(dateLike).date().weekday() + 1), nil
+		}, 0, "Return the day of the week represented by the date.\nMonday == 1 ... Sunday == 7"),
+		py.MustNewMethod("isocalendar", func(self py.Object) (py.Object, error) {
+			d := self.(dateLike).date()
+			year, week := d.goTime().ISOWeek()
+			return py.Tuple{py.Int(year), py.Int(week), py.Int(d.weekday() + 1)}, nil
+		}, 0, "Return a 3-tuple containing ISO year, week number, and weekday."),
+		py.MustNewMethod("isoformat", func(self py.Object) (py.Object, error) {
+			return py.String(self.(*Date).isoformat()), nil
+		}, 0, "Return string in ISO 8601 format, YYYY-MM-DD."),
+		py.MustNewMethod("ctime", func(self py.Object) (py.Object, error) {
+			s, err := strftime(self.(fielder).fields(), "%c")
+			if err != nil {
+				return nil, err
+			}
+			return py.String(s), nil
+		}, 0, "Return ctime() style string."),
+		py.MustNewMethod("strftime", func(self, format py.Object) (py.Object, error) {
+			return callStrftime(self, format)
+		}, 0, "format -> strftime() style string."),
+		py.MustNewMethod("__format__", func(self, formatSpec py.Object) (py.Object, error) {
+			return formatObject(self, formatSpec)
+		}, 0, "Formats self with strftime."),
+	)
+	DateType.Dict["min"] = &Date{object: newObject(DateType), year: MINYEAR, month: 1, day: 1}
+	DateType.Dict["max"] = &Date{object: newObject(DateType), year: MAXYEAR, month: 12, day: 31}
+	DateType.Dict["resolution"] = NewTimeDelta(usPerDay)
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Datetime module
+//
+// The calendar arithmetic is done by go's time package in UTC with
+// python's tzinfo objects providing the offsets.
+package datetime
+
+import (
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+const (
+	MINYEAR = 1
+	MAXYEAR = 9999
+)
+
+// The unix time of 0001-01-01 which is ordinal 1
+var unixOrdinal1 = time.Date(MINYEAR, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+// object holds the type of a datetime object and the instance
+// dictionary of python subclasses
+type object struct {
+	Base *py.Type
+	Dict py.StringDict
+}
+
+// Makes the object part of an instance of t
+func newObject(t *py.Type) object {
+	o := object{Base: t}
+	if t.Flags&py.TPFLAGS_HEAPTYPE != 0 && !t.NoDict {
+		o.Dict = py.NewStringDict()
+	}
+	return o
+}
+
+// Get the instance dictionary if any
+func (o *object) GetDict() py.StringDict {
+	return o.Dict
+}
+
+// Returns the name of t as used in reprs, eg datetime.date
+func typeName(t *py.Type) string {
+	if t.Flags&py.TPFLAGS_HEAPTYPE != 0 {
+		return t.Name
+	}
+	return "datetime." + t.Name
+}
+
+// The flags for the datetime types which can be subclassed
+const subclassFlags = py.TPFLAGS_BASETYPE | py.TPFLAGS_SUBCLASS_NEW
+
+// Returns the type of the results of arithmetic on an instance of
+// t, which is t itself for python subclasses and base otherwise
+func resultType(t *py.Type, base *py.Type) *py.Type {
+	if t.Flags&py.TPFLAGS_HEAPTYPE != 0 {
+		return t
+	}
+	return base
+}
+
+// Compares a and b returning -1, 0 or 1
+func compareInt64s(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Returns the result of the comparison op given the result c of a compare
+func compareResult(op string, c int) py.Object {
+	switch op {
+	case "==":
+		return py.NewBool(c == 0)
+	case "!=":
+		return py.NewBool(c != 0)
+	case "<":
+		return py.NewBool(c < 0)
+	case "<=":
+		return py.NewBool(c <= 0)
+	case ">":
+		return py.NewBool(c > 0)
+	}
+	return py.NewBool(c >= 0)
+}
+
+// Returns the result of comparing objects which are never equal and
+// which can't be ordered
+func mixedCompare(op string, msg string) (py.Object, error) {
+	switch op {
+	case "==":
+		return py.False, nil
+	case "!=":
+		return py.True, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, msg)
+}
+
+// Returns obj as an int refusing floats
+func getInt(obj py.Object) (int, error) {
+	if _, ok := obj.(py.Float); ok {
+		return 0, py.ExceptionNewf(py.TypeError, "'float' object cannot be interpreted as an integer")
+	}
+	return py.IndexInt(obj)
+}
+
+// Reads the int arguments into results leaving the defaults for
+// those which are missing
+func getInts(args []py.Object, results ...*int) error {
+	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
+		x, err := getInt(arg)
+		if err != nil {
+			return err
+		}
+		*results[i] = x
+	}
+	return nil
+}
+
+// Pops the keyword only argument fold from kwargs
+func popFold(kwargs py.StringDict) (py.StringDict, int, error) {
+	obj, ok := kwargs["fold"]
+	if !ok {
+		return kwargs, 0, nil
+	}
+	rest := py.NewStringDict()
+	for k, v := range kwargs {
+		if k != "fold" {
+			rest[k] = v
+		}
+	}
+	fold, err := getInt(obj)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fold != 0 && fold != 1 {
+		return nil, 0, py.ExceptionNewf(py.ValueError, "fold must be either 0 or 1")
+	}
+	return rest, fold, nil
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+var daysInMonthTable = [13]int{0, 31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+func daysInMonth(year, month int) int {
+	if month == 2 && isLeap(year) {
+		return 29
+	}
+	return daysInMonthTable[month]
+}
+
+// Checks the date is valid
+func checkDate(year, month, day int) error {
+	if year < MINYEAR || year > MAXYEAR {
+		return py.ExceptionNewf(py.ValueError, "year %d is out of range", year)
+	}
+	if month < 1 || month > 12 {
+		return py.ExceptionNewf(py.ValueError, "month must be in 1..12")
+	}
+	if day < 1 || day > daysInMonth(year, month) {
+		return py.ExceptionNewf(py.ValueError, "day is out of range for month")
+	}
+	return nil
+}
+
+// Checks the time is valid
+func checkTime(hour, minute, second, microsecond int) error {
+	if hour < 0 || hour > 23 {
+		return py.ExceptionNewf(py.ValueError, "hour must be in 0..23")
+	}
+	if minute < 0 || minute > 59 {
+		return py.ExceptionNewf(py.ValueError, "minute must be in 0..59")
+	}
+	if second < 0 || second > 59 {
+		return py.ExceptionNewf(py.ValueError, "second must be in 0..59")
+	}
+	if microsecond < 0 || microsecond > 999999 {
+		return py.ExceptionNewf(py.ValueError, "microsecond must be in 0..999999")
+	}
+	return nil
+}
+
+// Checks tzinfo is None or a tzinfo returning nil for None
+func checkTzInfo(tzinfo py.Object) (py.Object, error) {
+	if tzinfo == nil || tzinfo == py.None {
+		return nil, nil
+	}
+	if !tzinfo.Type().IsSubtype(TzInfoType) {
+		return nil, py.ExceptionNewf(py.TypeError, "tzinfo argument must be None or of a tzinfo subclass, not type '%s'", tzinfo.Type().Name)
+	}
+	return tzinfo, nil
+}
+
+// Returns the proleptic gregorian ordinal of the date where
+// 0001-01-01 is day 1
+func toOrdinal(year, month, day int) int {
+	return int((time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Unix()-unixOrdinal1)/86400) + 1
+}
+
+// Returns the go time in UTC of the start of the day with ordinal n
+func ordinalTime(n int) time.Time {
+	return time.Unix(unixOrdinal1+int64(n-1)*86400, 0).UTC()
+}
+
+// Returns the date with the ordinal n
+func fromOrdinal(n int) (year, month, day int, err error) {
+	if n < 1 || n > toOrdinal(MAXYEAR, 12, 31) {
+		return 0, 0, 0, py.ExceptionNewf(py.OverflowError, "date value out of range")
+	}
+	y, m, d := ordinalTime(n).Date()
+	return y, int(m), d, nil
+}
+
+// Returns the utcoffset, dst or tzname of tzinfo for dt
+//
+// dt is None or the object to pass to tzinfo
+func callTzInfo(tzinfo py.Object, method string, dt py.Object) (py.Object, error) {
+	if tzinfo == nil {
+		return py.None, nil
+	}
+	fn, err := py.GetAttrString(tzinfo, method)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, py.Tuple{dt}, nil)
+}
+
+// Calls utcoffset or dst on tzinfo returning the offset or nil if it
+// was None
+func tzOffset(tzinfo py.Object, method string, dt py.Object) (*TimeDelta, error) {
+	res, err := callTzInfo(tzinfo, method, dt)
+	if err != nil {
+		return nil, err
+	}
+	if res == py.None {
+		return nil, nil
+	}
+	td, ok := res.(*TimeDelta)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "tzinfo.%s() must return None or timedelta, not '%s'", method, res.Type().Name)
+	}
+	if td.days < -1 || td.days > 0 || (td.days == -1 && td.seconds == 0 && td.microseconds == 0) {
+		return nil, py.ExceptionNewf(py.ValueError, "offset must be a timedelta strictly between -timedelta(hours=24) and timedelta(hours=24), not %s.", mustRepr(td))
+	}
+	return td, nil
+}
+
+// As tzOffset but returns the offset as a python object
+func tzOffsetObject(tzinfo py.Object, method string, dt py.Object) (py.Object, error) {
+	td, err := tzOffset(tzinfo, method, dt)
+	if err != nil {
+		return nil, err
+	}
+	if td == nil {
+		return py.None, nil
+	}
+	return td, nil
+}
+
+// Calls tzname on tzinfo
+func tzName(tzinfo py.Object, dt py.Object) (py.Object, error) {
+	res, err := callTzInfo(tzinfo, "tzname", dt)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := res.(py.String); !ok && res != py.None {
+		return nil, py.ExceptionNewf(py.TypeError, "tzinfo.tzname() must return None or a string, not '%s'", res.Type().Name)
+	}
+	return res, nil
+}
+
+// Returns the repr of a datetime object which can't fail
+func mustRepr(obj py.Object) string {
+	s, err := py.ReprAsString(obj)
+	if err != nil {
+		return "?"
+	}
+	return s
+}
+
+// Adds the methods to the dictionary of t
+func addMethods(t *py.Type, methods ...*py.Method) {
+	for _, m := range methods {
+		t.Dict[m.Name] = m
+	}
+}
+
+// Returns the property returning the int from fn
+func intProperty(fn func(self py.Object) int, doc string) *py.Property {
+	return &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(fn(self)), nil
+		},
+		Doc: doc,
+	}
+}
+
+// Returns a class method which calls fn with the class
+func classMethod(name string, fn interface{}, doc string) *py.ClassMethod {
+	return &py.ClassMethod{Callable: py.MustNewMethod(name, fn, 0, doc)}
+}
+
+const module_doc = `Fast implementation of the datetime type.`
+
+// Initialise the module
+func init() {
+	for _, t := range []*py.Type{DateType, DateTimeType, TimeType, TimeDeltaType, TzInfoType, TimeZoneType} {
+		t.Dict["__module__"] = py.String("datetime")
+	}
+	globals := py.StringDict{
+		"MINYEAR":   py.Int(MINYEAR),
+		"MAXYEAR":   py.Int(MAXYEAR),
+		"date":      DateType,
+		"datetime":  DateTimeType,
+		"time":      TimeType,
+		"timedelta": TimeDeltaType,
+		"timezone":  TimeZoneType,
+		"tzinfo":    TzInfoType,
+		"UTC":       UTC,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "datetime",
+		Doc:     module_doc,
+		Globals: globals,
+	})
+}
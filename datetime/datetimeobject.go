@@ -0,0 +1,556 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+var DateTimeType = DateType.NewTypeFlags("datetime", datetime_doc, DateTimeNew, nil, py.ObjectType.Flags|subclassFlags)
+
+const datetime_doc = `datetime(year, month, day[, hour[, minute[, second[, microsecond[,tzinfo]]]]])
+
+The year, month and day arguments are required. tzinfo may be None, or an
+instance of a tzinfo subclass. The remaining arguments may be ints.`
+
+// The number of days which can be added to any datetime without
+// leaving the supported range
+const maxDateDays = 3652059
+
+// DateTime is a date and a time of day with an optional tzinfo
+type DateTime struct {
+	Date
+	hour        int
+	minute      int
+	second      int
+	microsecond int
+	tzinfo      py.Object // nil for None
+	fold        int
+}
+
+// NewDateTime returns a datetime checking the fields are valid
+func NewDateTime(year, month, day, hour, minute, second, microsecond int, tzinfo py.Object, fold int) (*DateTime, error) {
+	err := checkDate(year, month, day)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTime(hour, minute, second, microsecond)
+	if err != nil {
+		return nil, err
+	}
+	return newDateTime(DateTimeType, year, month, day, hour, minute, second, microsecond, tzinfo, fold), nil
+}
+
+// Makes a datetime of type t without checking the fields
+func newDateTime(t *py.Type, year, month, day, hour, minute, second, microsecond int, tzinfo py.Object, fold int) *DateTime {
+	return &DateTime{
+		Date:        Date{object: newObject(t), year: year, month: month, day: day},
+		hour:        hour,
+		minute:      minute,
+		second:      second,
+		microsecond: microsecond,
+		tzinfo:      tzinfo,
+		fold:        fold,
+	}
+}
+
+// Makes a datetime of class cls which may be a python subclass
+func makeDateTime(cls *py.Type, year, month, day, hour, minute, second, microsecond int, tzinfo py.Object, fold int) (py.Object, error) {
+	if cls == DateTimeType {
+		return NewDateTime(year, month, day, hour, minute, second, microsecond, tzinfo, fold)
+	}
+	var kwargs py.StringDict
+	if fold != 0 {
+		kwargs = py.StringDict{"fold": py.Int(fold)}
+	}
+	return py.Call(cls, py.Tuple{py.Int(year), py.Int(month), py.Int(day), py.Int(hour), py.Int(minute), py.Int(second), py.Int(microsecond), noneIfNil(tzinfo)}, kwargs)
+}
+
+// Makes a datetime of class cls from the wall clock of the go time t
+func makeDateTimeFromGo(cls *py.Type, t time.Time, tzinfo py.Object) (py.Object, error) {
+	return makeDateTime(cls, t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000, tzinfo, 0)
+}
+
+// Converts a datetime with the wall clock of the UTC time t into the
+// time zone tz using tz.fromutc
+func fromUTC(cls *py.Type, t time.Time, tz py.Object) (py.Object, error) {
+	tz, err := checkTzInfo(tz)
+	if err != nil {
+		return nil, err
+	}
+	if tz == nil {
+		return makeDateTimeFromGo(cls, t.Local(), nil)
+	}
+	t = t.UTC()
+	utc, err := makeDateTimeFromGo(cls, t, tz)
+	if err != nil {
+		return nil, err
+	}
+	fn, err := py.GetAttrString(tz, "fromutc")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, py.Tuple{utc}, nil)
+}
+
+// DateTimeNew makes a datetime from its fields
+func DateTimeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	kwargs, fold, err := popFold(kwargs)
+	if err != nil {
+		return nil, err
+	}
+	var yearObj, monthObj, dayObj, hourObj, minuteObj, secondObj, microsecondObj, tzinfo py.Object
+	kwlist := []string{"year", "month", "day", "hour", "minute", "second", "microsecond", "tzinfo"}
+	err = py.ParseTupleAndKeywords(args, kwargs, "OOO|OOOOO:datetime", kwlist, &yearObj, &monthObj, &dayObj, &hourObj, &minuteObj, &secondObj, &microsecondObj, &tzinfo)
+	if err != nil {
+		return nil, err
+	}
+	var year, month, day, hour, minute, second, microsecond int
+	err = getInts([]py.Object{yearObj, monthObj, dayObj, hourObj, minuteObj, secondObj, microsecondObj}, &year, &month, &day, &hour, &minute, &second, &microsecond)
+	if err != nil {
+		return nil, err
+	}
+	err = checkDate(year, month, day)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTime(hour, minute, second, microsecond)
+	if err != nil {
+		return nil, err
+	}
+	tzinfo, err = checkTzInfo(tzinfo)
+	if err != nil {
+		return nil, err
+	}
+	return newDateTime(metatype, year, month, day, hour, minute, second, microsecond, tzinfo, fold), nil
+}
+
+// Returns the time part of the datetime with the tzinfo
+func (dt *DateTime) time() *Time {
+	return &Time{object: newObject(TimeType), hour: dt.hour, minute: dt.minute, second: dt.second, microsecond: dt.microsecond, tzinfo: dt.tzinfo, fold: dt.fold}
+}
+
+// Returns the go time of the wall clock of dt in loc
+func (dt *DateTime) goTime(loc *time.Location) time.Time {
+	return time.Date(dt.year, time.Month(dt.month), dt.day, dt.hour, dt.minute, dt.second, dt.microsecond*1000, loc)
+}
+
+// Returns the wall clock time in microseconds since 0001-01-01
+func (dt *DateTime) us() int64 {
+	return int64(dt.toOrdinal()-1)*usPerDay + dt.time().us()
+}
+
+// Returns the datetime us microseconds later with the same tzinfo
+func (dt *DateTime) addUs(us int64) (*DateTime, error) {
+	total := dt.us() + us
+	if total < 0 {
+		return nil, py.ExceptionNewf(py.OverflowError, "date value out of range")
+	}
+	days, rem := total/usPerDay, total%usPerDay
+	year, month, day, err := fromOrdinal(int(days) + 1)
+	if err != nil {
+		return nil, err
+	}
+	secs := int(rem / usPerSecond)
+	return newDateTime(DateTimeType, year, month, day, secs/3600, secs/60%60, secs%60, int(rem%usPerSecond), dt.tzinfo, 0), nil
+}
+
+// Returns dt as the type of the results of arithmetic on it
+func (dt *DateTime) result(res *DateTime) (py.Object, error) {
+	if dt.Base.Flags&py.TPFLAGS_HEAPTYPE == 0 {
+		return res, nil
+	}
+	return makeDateTime(dt.Base, res.year, res.month, res.day, res.hour, res.minute, res.second, res.microsecond, res.tzinfo, res.fold)
+}
+
+// Returns the utcoffset of dt
+func (dt *DateTime) utcoffset() (*TimeDelta, error) {
+	return tzOffset(dt.tzinfo, "utcoffset", dt)
+}
+
+// Returns the time in microseconds adjusted to UTC if it has an offset
+func (dt *DateTime) utcUs() (int64, *TimeDelta, error) {
+	offset, err := dt.utcoffset()
+	if err != nil {
+		return 0, nil, err
+	}
+	us := dt.us()
+	if offset != nil {
+		us -= offset.us()
+	}
+	return us, offset, nil
+}
+
+// Returns the ISO format of dt
+func (dt *DateTime) isoformat(sep string, timespec string) (string, error) {
+	s, err := formatTime(dt.hour, dt.minute, dt.second, dt.microsecond, timespec)
+	if err != nil {
+		return "", err
+	}
+	offset, err := dt.utcoffset()
+	if err != nil {
+		return "", err
+	}
+	return dt.Date.isoformat() + sep + s + formatOffset(offset, ":"), nil
+}
+
+// Returns the go time of the instant dt using the local time zone if
+// it is naive
+func (dt *DateTime) instant() (time.Time, error) {
+	us, offset, err := dt.utcUs()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if offset == nil {
+		return dt.goTime(time.Local), nil
+	}
+	return ordinalTime(1).Add(time.Duration(us%usPerDay)*time.Microsecond).AddDate(0, 0, int(us/usPerDay)), nil
+}
+
+func (dt *DateTime) M__repr__() (py.Object, error) {
+	s := fmt.Sprintf("%d, %d, %d, %d, %d", dt.year, dt.month, dt.day, dt.hour, dt.minute)
+	switch {
+	case dt.microsecond != 0:
+		s += fmt.Sprintf(", %d, %d", dt.second, dt.microsecond)
+	case dt.second != 0:
+		s += fmt.Sprintf(", %d", dt.second)
+	}
+	if dt.tzinfo != nil {
+		s += ", tzinfo=" + mustRepr(dt.tzinfo)
+	}
+	if dt.fold != 0 {
+		s += ", fold=1"
+	}
+	return py.String(typeName(dt.Base) + "(" + s + ")"), nil
+}
+
+func (dt *DateTime) M__str__() (py.Object, error) {
+	s, err := dt.isoformat(" ", "auto")
+	if err != nil {
+		return nil, err
+	}
+	return py.String(s), nil
+}
+
+func (dt *DateTime) M__format__(formatSpec py.Object) (py.Object, error) {
+	return formatObject(dt, formatSpec)
+}
+
+func (dt *DateTime) M__hash__() (py.Object, error) {
+	us, _, err := dt.utcUs()
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(us).M__hash__()
+}
+
+func (dt *DateTime) M__add__(other py.Object) (py.Object, error) {
+	td, ok := other.(*TimeDelta)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if td.days > maxDateDays || td.days < -maxDateDays {
+		return nil, py.ExceptionNewf(py.OverflowError, "date value out of range")
+	}
+	res, err := dt.addUs(td.us())
+	if err != nil {
+		return nil, err
+	}
+	return dt.result(res)
+}
+
+func (dt *DateTime) M__radd__(other py.Object) (py.Object, error) {
+	return dt.M__add__(other)
+}
+
+func (dt *DateTime) M__sub__(other py.Object) (py.Object, error) {
+	switch b := other.(type) {
+	case *TimeDelta:
+		if b.days > maxDateDays || b.days < -maxDateDays {
+			return nil, py.ExceptionNewf(py.OverflowError, "date value out of range")
+		}
+		res, err := dt.addUs(-b.us())
+		if err != nil {
+			return nil, err
+		}
+		return dt.result(res)
+	case *DateTime:
+		if dt.tzinfo == b.tzinfo {
+			return NewTimeDelta(dt.us() - b.us()), nil
+		}
+		a, aOffset, err := dt.utcUs()
+		if err != nil {
+			return nil, err
+		}
+		c, bOffset, err := b.utcUs()
+		if err != nil {
+			return nil, err
+		}
+		if (aOffset == nil) != (bOffset == nil) {
+			return nil, py.ExceptionNewf(py.TypeError, "can't subtract offset-naive and offset-aware datetimes")
+		}
+		return NewTimeDelta(a - c), nil
+	}
+	return py.NotImplemented, nil
+}
+
+// Compares dt with other using op
+func (dt *DateTime) richCompare(other py.Object, op string) (py.Object, error) {
+	var b *DateTime
+	switch x := other.(type) {
+	case *DateTime:
+		b = x
+	case *Date:
+		return mixedCompare(op, "can't compare datetime.datetime to datetime.date")
+	default:
+		return py.NotImplemented, nil
+	}
+	if dt.tzinfo == b.tzinfo {
+		return compareResult(op, compareInt64s(dt.us(), b.us())), nil
+	}
+	a, aOffset, err := dt.utcUs()
+	if err != nil {
+		return nil, err
+	}
+	c, bOffset, err := b.utcUs()
+	if err != nil {
+		return nil, err
+	}
+	if (aOffset == nil) != (bOffset == nil) {
+		return mixedCompare(op, "can't compare offset-naive and offset-aware datetimes")
+	}
+	return compareResult(op, compareInt64s(a, c)), nil
+}
+
+func (dt *DateTime) M__eq__(other py.Object) (py.Object, error) {
+	return dt.richCompare(other, "==")
+}
+
+func (dt *DateTime) M__ne__(other py.Object) (py.Object, error) {
+	return dt.richCompare(other, "!=")
+}
+
+func (dt *DateTime) M__lt__(other py.Object) (py.Object, error) {
+	return dt.richCompare(other, "<")
+}
+
+func (dt *DateTime) M__le__(other py.Object) (py.Object, error) {
+	return dt.richCompare(other, "<=")
+}
+
+func (dt *DateTime) M__gt__(other py.Object) (py.Object, error) {
+	return dt.richCompare(other, ">")
+}
+
+func (dt *DateTime) M__ge__(other py.Object) (py.Object, error) {
+	return dt.richCompare(other, ">=")
+}
+
+// Returns the local time zone at the instant t as a timezone
+func localTimeZone(t time.Time) (*TimeZone, error) {
+	name, offset := t.Local().Zone()
+	return NewTimeZone(NewTimeDelta(int64(offset)*usPerSecond), py.String(name))
+}
+
+// Implements datetime.astimezone
+func (dt *DateTime) astimezone(tz py.Object) (py.Object, error) {
+	if tz == nil || tz == py.None {
+		t, err := dt.instant()
+		if err != nil {
+			return nil, err
+		}
+		tz, err = localTimeZone(t)
+		if err != nil {
+			return nil, err
+		}
+	} else if !tz.Type().IsSubtype(TzInfoType) {
+		return nil, py.ExceptionNewf(py.TypeError, "astimezone() argument 1 must be datetime.tzinfo, not %s", tz.Type().Name)
+	}
+	if dt.tzinfo == tz {
+		return dt, nil
+	}
+	t, err := dt.instant()
+	if err != nil {
+		return nil, err
+	}
+	return fromUTC(resultType(dt.Base, DateTimeType), t, tz)
+}
+
+// Returns the datetime from ts and an optional tz
+func dateTimeFromTimestamp(cls *py.Type, ts, tz py.Object) (py.Object, error) {
+	t, err := fromTimestamp(ts, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	return fromUTC(cls, t, tz)
+}
+
+func init() {
+	DateTimeType.Dict["hour"] = intProperty(func(self py.Object) int { return self.(*DateTime).hour }, "")
+	DateTimeType.Dict["minute"] = intProperty(func(self py.Object) int { return self.(*DateTime).minute }, "")
+	DateTimeType.Dict["second"] = intProperty(func(self py.Object) int { return self.(*DateTime).second }, "")
+	DateTimeType.Dict["microsecond"] = intProperty(func(self py.Object) int { return self.(*DateTime).microsecond }, "")
+	DateTimeType.Dict["fold"] = intProperty(func(self py.Object) int { return self.(*DateTime).fold }, "")
+	DateTimeType.Dict["tzinfo"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return noneIfNil(self.(*DateTime).tzinfo), nil
+		},
+	}
+	DateTimeType.Dict["now"] = classMethod("now", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var tz py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:now", []string{"tz"}, &tz)
+		if err != nil {
+			return nil, err
+		}
+		return fromUTC(cls.(*py.Type), time.Now(), tz)
+	}, "Returns new datetime object representing current time local to tz.\n\n  tz\n    Timezone object.\n\nIf no tz is specified, uses local timezone.")
+	DateTimeType.Dict["utcnow"] = classMethod("utcnow", func(cls py.Object) (py.Object, error) {
+		return makeDateTimeFromGo(cls.(*py.Type), time.Now().UTC(), nil)
+	}, "Return a new datetime representing UTC day and time.")
+	DateTimeType.Dict["fromtimestamp"] = classMethod("fromtimestamp", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var ts, tz py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:fromtimestamp", []string{"timestamp", "tz"}, &ts, &tz)
+		if err != nil {
+			return nil, err
+		}
+		return dateTimeFromTimestamp(cls.(*py.Type), ts, tz)
+	}, "timestamp[, tz] -> tz's local time from POSIX timestamp.")
+	DateTimeType.Dict["utcfromtimestamp"] = classMethod("utcfromtimestamp", func(cls, ts py.Object) (py.Object, error) {
+		t, err := fromTimestamp(ts, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		return makeDateTimeFromGo(cls.(*py.Type), t, nil)
+	}, "Construct a naive UTC datetime from a POSIX timestamp.")
+	DateTimeType.Dict["combine"] = classMethod("combine", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var dateObj, timeObj, tzinfo py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:combine", []string{"date", "time", "tzinfo"}, &dateObj, &timeObj, &tzinfo)
+		if err != nil {
+			return nil, err
+		}
+		d, ok := dateObj.(dateLike)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "combine() argument 1 must be datetime.date, not %s", dateObj.Type().Name)
+		}
+		t, ok := timeObj.(*Time)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "combine() argument 2 must be datetime.time, not %s", timeObj.Type().Name)
+		}
+		tz := t.tzinfo
+		if tzinfo != nil {
+			tz, err = checkTzInfo(tzinfo)
+			if err != nil {
+				return nil, err
+			}
+		}
+		date := d.date()
+		return makeDateTime(cls.(*py.Type), date.year, date.month, date.day, t.hour, t.minute, t.second, t.microsecond, tz, t.fold)
+	}, "date, time -> datetime with same date and time fields")
+	DateTimeType.Dict["fromisoformat"] = classMethod("fromisoformat", func(cls, s py.Object) (py.Object, error) {
+		str, ok := s.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "fromisoformat: argument must be str")
+		}
+		f, err := parseISODateTime(string(str))
+		if err != nil {
+			return nil, err
+		}
+		return makeDateTime(cls.(*py.Type), f.year, f.month, f.day, f.hour, f.minute, f.second, f.microsecond, f.tzinfo, 0)
+	}, "string -> datetime from a string in most ISO 8601 formats")
+	DateTimeType.Dict["strptime"] = classMethod("strptime", func(cls py.Object, args py.Tuple) (py.Object, error) {
+		var s, format py.Object
+		err := py.UnpackTuple(args, nil, "strptime", 2, 2, &s, &format)
+		if err != nil {
+			return nil, err
+		}
+		str, ok := s.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "strptime() argument 1 must be str, not %s", s.Type().Name)
+		}
+		formatStr, ok := format.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "strptime() argument 2 must be str, not %s", format.Type().Name)
+		}
+		f, err := strptime(string(str), string(formatStr))
+		if err != nil {
+			return nil, err
+		}
+		return makeDateTime(cls.(*py.Type), f.year, f.month, f.day, f.hour, f.minute, f.second, f.microsecond, f.tzinfo, 0)
+	}, "string, format -> new datetime parsed from a string (like time.strptime()).")
+	addMethods(DateTimeType,
+		py.MustNewMethod("date", func(self py.Object) (py.Object, error) {
+			dt := self.(*DateTime)
+			return NewDate(dt.year, dt.month, dt.day)
+		}, 0, "Return date object with same year, month and day."),
+		py.MustNewMethod("time", func(self py.Object) (py.Object, error) {
+			t := self.(*DateTime).time()
+			t.tzinfo = nil
+			return t, nil
+		}, 0, "Return time object with same time but with tzinfo=None."),
+		py.MustNewMethod("timetz", func(self py.Object) (py.Object, error) {
+			return self.(*DateTime).time(), nil
+		}, 0, "Return time object with same time and tzinfo."),
+		py.MustNewMethod("replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			dt := self.(*DateTime)
+			d := *dt.Date.date()
+			t := dt.time()
+			tzinfo, fold, err := replaceTime("replace", args, kwargs, []string{"year", "month", "day"}, []*int{&d.year, &d.month, &d.day}, t)
+			if err != nil {
+				return nil, err
+			}
+			err = checkDate(d.year, d.month, d.day)
+			if err != nil {
+				return nil, err
+			}
+			return makeDateTime(resultType(dt.Base, DateTimeType), d.year, d.month, d.day, t.hour, t.minute, t.second, t.microsecond, tzinfo, fold)
+		}, 0, "Return datetime with new specified fields."),
+		py.MustNewMethod("astimezone", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var tz py.Object
+			err := py.ParseTupleAndKeywords(args, kwargs, "|O:astimezone", []string{"tz"}, &tz)
+			if err != nil {
+				return nil, err
+			}
+			return self.(*DateTime).astimezone(tz)
+		}, 0, "tz -> convert to local time in new timezone tz\n"),
+		py.MustNewMethod("utcoffset", func(self py.Object) (py.Object, error) {
+			return tzOffsetObject(self.(*DateTime).tzinfo, "utcoffset", self)
+		}, 0, "Return self.tzinfo.utcoffset(self)."),
+		py.MustNewMethod("dst", func(self py.Object) (py.Object, error) {
+			return tzOffsetObject(self.(*DateTime).tzinfo, "dst", self)
+		}, 0, "Return self.tzinfo.dst(self)."),
+		py.MustNewMethod("tzname", func(self py.Object) (py.Object, error) {
+			return tzName(self.(*DateTime).tzinfo, self)
+		}, 0, "Return self.tzinfo.tzname(self)."),
+		py.MustNewMethod("timestamp", func(self py.Object) (py.Object, error) {
+			t, err := self.(*DateTime).instant()
+			if err != nil {
+				return nil, err
+			}
+			return py.Float(float64(t.Unix()) + float64(t.Nanosecond())/1e9), nil
+		}, 0, "Return POSIX timestamp as float."),
+		py.MustNewMethod("isoformat", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var sep py.Object = py.String("T")
+			timespec, err := getTimespec("isoformat", args, kwargs, []string{"sep"}, &sep)
+			if err != nil {
+				return nil, err
+			}
+			sepStr, ok := sep.(py.String)
+			if !ok || len([]rune(string(sepStr))) != 1 {
+				return nil, py.ExceptionNewf(py.TypeError, "isoformat() argument 1 must be a unicode character, not %s", sep.Type().Name)
+			}
+			s, err := self.(*DateTime).isoformat(string(sepStr), timespec)
+			if err != nil {
+				return nil, err
+			}
+			return py.String(s), nil
+		}, 0, "[sep] -> string in ISO 8601 format, YYYY-MM-DDT[HH[:MM[:SS[.mmm[uuu]]]]][+HH:MM].\nsep is used to separate the year from the time, and defaults to 'T'.\nThe optional argument timespec specifies the number of additional terms\nof the time to include. Valid options are 'auto', 'hours', 'minutes',\n'seconds', 'milliseconds' and 'microseconds'.\n"),
+	)
+	DateTimeType.Dict["min"] = newDateTime(DateTimeType, MINYEAR, 1, 1, 0, 0, 0, 0, nil, 0)
+	DateTimeType.Dict["max"] = newDateTime(DateTimeType, MAXYEAR, 12, 31, 23, 59, 59, 999999, nil, 0)
+	DateTimeType.Dict["resolution"] = NewTimeDelta(1)
+}
@@ -0,0 +1,702 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Formatting and parsing of dates and times
+
+package datetime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// fields are the parts of a date or time used for formatting
+type fields struct {
+	year, month, day                  int
+	hour, minute, second, microsecond int
+	tzinfo                            py.Object // nil for None
+	tzarg                             py.Object // passed to the tzinfo methods
+}
+
+// fielder is implemented by the objects which can be formatted
+type fielder interface {
+	fields() fields
+}
+
+func (d *Date) fields() fields {
+	return fields{year: d.year, month: d.month, day: d.day, tzarg: py.None}
+}
+
+func (dt *DateTime) fields() fields {
+	return fields{
+		year: dt.year, month: dt.month, day: dt.day,
+		hour: dt.hour, minute: dt.minute, second: dt.second, microsecond: dt.microsecond,
+		tzinfo: dt.tzinfo, tzarg: dt,
+	}
+}
+
+func (t *Time) fields() fields {
+	return fields{
+		year: 1900, month: 1, day: 1,
+		hour: t.hour, minute: t.minute, second: t.second, microsecond: t.microsecond,
+		tzinfo: t.tzinfo, tzarg: py.None,
+	}
+}
+
+var (
+	dayNames   = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	monthNames = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+)
+
+// Formats the offset as +HH:MM[:SS[.ffffff]] or "" if it is nil
+func formatOffset(offset *TimeDelta, sep string) string {
+	if offset == nil {
+		return ""
+	}
+	us := offset.us()
+	sign := "+"
+	if us < 0 {
+		sign = "-"
+		us = -us
+	}
+	secs := us / usPerSecond
+	s := fmt.Sprintf("%s%02d%s%02d", sign, secs/3600, sep, secs/60%60)
+	if secs%60 != 0 || us%usPerSecond != 0 {
+		s += fmt.Sprintf("%s%02d", sep, secs%60)
+	}
+	if us%usPerSecond != 0 {
+		s += fmt.Sprintf(".%06d", us%usPerSecond)
+	}
+	return s
+}
+
+// Formats the time in ISO format to the precision given by timespec
+func formatTime(hour, minute, second, microsecond int, timespec string) (string, error) {
+	if timespec == "auto" {
+		timespec = "seconds"
+		if microsecond != 0 {
+			timespec = "microseconds"
+		}
+	}
+	switch timespec {
+	case "hours":
+		return fmt.Sprintf("%02d", hour), nil
+	case "minutes":
+		return fmt.Sprintf("%02d:%02d", hour, minute), nil
+	case "seconds":
+		return fmt.Sprintf("%02d:%02d:%02d", hour, minute, second), nil
+	case "milliseconds":
+		return fmt.Sprintf("%02d:%02d:%02d.%03d", hour, minute, second, microsecond/1000), nil
+	case "microseconds":
+		return fmt.Sprintf("%02d:%02d:%02d.%06d", hour, minute, second, microsecond), nil
+	}
+	return "", py.ExceptionNewf(py.ValueError, "Unknown timespec value")
+}
+
+// Formats f according to the strftime format
+func strftime(f fields, format string) (string, error) {
+	t := time.Date(f.year, time.Month(f.month), f.day, f.hour, f.minute, f.second, 0, time.UTC)
+	weekday := (int(t.Weekday()) + 6) % 7 // Monday is 0
+	yday := t.YearDay() - 1
+	var out strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i+1 >= len(format) {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'a':
+			out.WriteString(dayNames[weekday][:3])
+		case 'A':
+			out.WriteString(dayNames[weekday])
+		case 'w':
+			fmt.Fprintf(&out, "%d", (weekday+1)%7)
+		case 'u':
+			fmt.Fprintf(&out, "%d", weekday+1)
+		case 'd':
+			fmt.Fprintf(&out, "%02d", f.day)
+		case 'e':
+			fmt.Fprintf(&out, "%2d", f.day)
+		case 'b', 'h':
+			out.WriteString(monthNames[f.month-1][:3])
+		case 'B':
+			out.WriteString(monthNames[f.month-1])
+		case 'm':
+			fmt.Fprintf(&out, "%02d", f.month)
+		case 'y':
+			fmt.Fprintf(&out, "%02d", f.year%100)
+		case 'Y':
+			fmt.Fprintf(&out, "%d", f.year)
+		case 'C':
+			fmt.Fprintf(&out, "%02d", f.year/100)
+		case 'G':
+			year, _ := t.ISOWeek()
+			fmt.Fprintf(&out, "%d", year)
+		case 'V':
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&out, "%02d", week)
+		case 'H':
+			fmt.Fprintf(&out, "%02d", f.hour)
+		case 'I':
+			fmt.Fprintf(&out, "%02d", (f.hour+11)%12+1)
+		case 'p':
+			if f.hour < 12 {
+				out.WriteString("AM")
+			} else {
+				out.WriteString("PM")
+			}
+		case 'M':
+			fmt.Fprintf(&out, "%02d", f.minute)
+		case 'S':
+			fmt.Fprintf(&out, "%02d", f.second)
+		case 'f':
+			fmt.Fprintf(&out, "%06d", f.microsecond)
+		case 'j':
+			fmt.Fprintf(&out, "%03d", yday+1)
+		case 'U':
+			fmt.Fprintf(&out, "%02d", (yday+7-(weekday+1)%7)/7)
+		case 'W':
+			fmt.Fprintf(&out, "%02d", (yday+7-weekday)/7)
+		case 'c':
+			s, _ := strftime(f, "%a %b %e %H:%M:%S %Y")
+			out.WriteString(s)
+		case 'x':
+			s, _ := strftime(f, "%m/%d/%y")
+			out.WriteString(s)
+		case 'X':
+			s, _ := strftime(f, "%H:%M:%S")
+			out.WriteString(s)
+		case 'z':
+			offset, err := tzOffset(f.tzinfo, "utcoffset", f.tzarg)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(formatOffset(offset, ""))
+		case 'Z':
+			name, err := tzName(f.tzinfo, f.tzarg)
+			if err != nil {
+				return "", err
+			}
+			if name != py.None {
+				out.WriteString(string(name.(py.String)))
+			}
+		case '%':
+			out.WriteByte('%')
+		default:
+			out.WriteByte('%')
+			out.WriteByte(format[i])
+		}
+	}
+	return out.String(), nil
+}
+
+// Strftime formats t according to the strftime format for the
+// packages which format Go times as python does
+//
+// t is treated as a naive time so %z and %Z are empty.
+func Strftime(t time.Time, format string) (string, error) {
+	return strftime(fields{
+		year: t.Year(), month: int(t.Month()), day: t.Day(),
+		hour: t.Hour(), minute: t.Minute(), second: t.Second(), microsecond: t.Nanosecond() / 1000,
+		tzarg: py.None,
+	}, format)
+}
+
+// Implements the strftime method of date, datetime and time
+func callStrftime(self, format py.Object) (py.Object, error) {
+	s, ok := format.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "strftime() argument 1 must be str, not %s", format.Type().Name)
+	}
+	res, err := strftime(self.(fielder).fields(), string(s))
+	if err != nil {
+		return nil, err
+	}
+	return py.String(res), nil
+}
+
+// Implements the __format__ method of date, datetime and time
+func formatObject(self, formatSpec py.Object) (py.Object, error) {
+	s, ok := formatSpec.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "__format__() argument 1 must be str, not %s", formatSpec.Type().Name)
+	}
+	if s == "" {
+		return py.Str(self)
+	}
+	fn, err := py.GetAttrString(self, "strftime")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, py.Tuple{s}, nil)
+}
+
+// Returns the integer nearest to x rounding halves to even
+func roundFloat(x float64) (int64, error) {
+	x = math.RoundToEven(x)
+	if math.IsNaN(x) {
+		return 0, py.ExceptionNewf(py.ValueError, "Invalid value NaN (not a number)")
+	}
+	if x >= math.MaxInt64 || x < math.MinInt64 {
+		return 0, py.ExceptionNewf(py.OverflowError, "timestamp out of range for platform time_t")
+	}
+	return int64(x), nil
+}
+
+// Returns the error for a string fromisoformat can't parse
+func isoError(s string) error {
+	return py.ExceptionNewf(py.ValueError, "Invalid isoformat string: %s", mustRepr(py.String(s)))
+}
+
+// Parses n digits from the start of s
+func parseDigits(s string, n int) (int, string, bool) {
+	if len(s) < n {
+		return 0, s, false
+	}
+	x := 0
+	for i := 0; i < n; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, s, false
+		}
+		x = x*10 + int(s[i]-'0')
+	}
+	return x, s[n:], true
+}
+
+// Parses an ISO date returning the rest of the string
+//
+// It accepts YYYY-MM-DD, YYYYMMDD, YYYY-Www-D and YYYYWwwD
+func parseISODatePrefix(s string) (year, month, day int, rest string, err error) {
+	year, rest, ok := parseDigits(s, 4)
+	if !ok {
+		return 0, 0, 0, "", isoError(s)
+	}
+	extended := strings.HasPrefix(rest, "-")
+	if extended {
+		rest = rest[1:]
+	}
+	if strings.HasPrefix(rest, "W") {
+		week, r, ok := parseDigits(rest[1:], 2)
+		if !ok {
+			return 0, 0, 0, "", isoError(s)
+		}
+		if extended {
+			if !strings.HasPrefix(r, "-") {
+				return 0, 0, 0, "", isoError(s)
+			}
+			r = r[1:]
+		}
+		weekday, r, ok := parseDigits(r, 1)
+		if !ok {
+			return 0, 0, 0, "", isoError(s)
+		}
+		year, month, day, err = fromISOCalendar(year, week, weekday)
+		return year, month, day, r, err
+	}
+	month, rest, ok = parseDigits(rest, 2)
+	if !ok {
+		return 0, 0, 0, "", isoError(s)
+	}
+	if extended {
+		if !strings.HasPrefix(rest, "-") {
+			return 0, 0, 0, "", isoError(s)
+		}
+		rest = rest[1:]
+	}
+	day, rest, ok = parseDigits(rest, 2)
+	if !ok {
+		return 0, 0, 0, "", isoError(s)
+	}
+	return year, month, day, rest, checkDate(year, month, day)
+}
+
+// Parses a date in ISO 8601 format
+func parseISODate(s string) (year, month, day int, err error) {
+	year, month, day, rest, err := parseISODatePrefix(s)
+	if err == nil && rest != "" {
+		err = isoError(s)
+	}
+	return year, month, day, err
+}
+
+// Parses HH[:MM[:SS[.fff[fff]]]] or its compact form returning the
+// rest of the string
+func parseISOClock(s string) (hour, minute, second, microsecond int, rest string, ok bool) {
+	hour, rest, ok = parseDigits(s, 2)
+	if !ok {
+		return
+	}
+	extended := strings.HasPrefix(rest, ":")
+	parts := []*int{&minute, &second}
+	for _, part := range parts {
+		r := rest
+		if extended {
+			if !strings.HasPrefix(r, ":") {
+				break
+			}
+			r = r[1:]
+		}
+		var x int
+		x, r, ok = parseDigits(r, 2)
+		if !ok {
+			if extended {
+				return
+			}
+			ok = true
+			break
+		}
+		*part = x
+		rest = r
+	}
+	if strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, ",") {
+		rest = rest[1:]
+		n := 0
+		for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+			if n < 6 {
+				microsecond = microsecond*10 + int(rest[n]-'0')
+			}
+			n++
+		}
+		if n == 0 {
+			return 0, 0, 0, 0, "", false
+		}
+		for i := n; i < 6; i++ {
+			microsecond *= 10
+		}
+		rest = rest[n:]
+	}
+	return hour, minute, second, microsecond, rest, true
+}
+
+// Parses a UTC offset Z or +HH[:MM[:SS[.ffffff]]] returning nil for ""
+func parseISOOffset(s string) (py.Object, bool) {
+	switch {
+	case s == "":
+		return nil, true
+	case s == "Z":
+		return UTC, true
+	case s[0] != '+' && s[0] != '-':
+		return nil, false
+	}
+	hour, minute, second, microsecond, rest, ok := parseISOClock(s[1:])
+	if !ok || rest != "" || hour > 23 || minute > 59 || second > 59 {
+		return nil, false
+	}
+	us := int64(hour*3600+minute*60+second)*usPerSecond + int64(microsecond)
+	if s[0] == '-' {
+		us = -us
+	}
+	tz, err := NewTimeZone(NewTimeDelta(us), nil)
+	if err != nil {
+		return nil, false
+	}
+	return tz, true
+}
+
+// Parses a time in ISO 8601 format with an optional UTC offset
+func parseISOTime(s string) (hour, minute, second, microsecond int, tzinfo py.Object, err error) {
+	hour, minute, second, microsecond, rest, ok := parseISOClock(s)
+	if ok {
+		tzinfo, ok = parseISOOffset(rest)
+	}
+	if !ok {
+		return 0, 0, 0, 0, nil, isoError(s)
+	}
+	err = checkTime(hour, minute, second, microsecond)
+	return hour, minute, second, microsecond, tzinfo, err
+}
+
+// Parses a datetime in ISO 8601 format
+func parseISODateTime(s string) (f fields, err error) {
+	f.year, f.month, f.day, s, err = parseISODatePrefix(s)
+	if err != nil {
+		return f, err
+	}
+	if s == "" {
+		return f, nil
+	}
+	// The separator may be any single character
+	_, size := firstRune(s)
+	f.hour, f.minute, f.second, f.microsecond, f.tzinfo, err = parseISOTime(s[size:])
+	return f, err
+}
+
+// Returns the first rune of s and its size
+func firstRune(s string) (rune, int) {
+	for _, r := range s {
+		return r, len(string(r))
+	}
+	return 0, 0
+}
+
+// Matches one of names case insensitively at the start of s
+// returning its index
+func matchName(s string, names []string) (int, string, bool) {
+	best, bestLen := -1, 0
+	for i, name := range names {
+		if len(name) > bestLen && len(s) >= len(name) && strings.EqualFold(s[:len(name)], name) {
+			best, bestLen = i, len(name)
+		}
+	}
+	return best, s[bestLen:], best >= 0
+}
+
+// Matches between 1 and max digits at the start of s
+func matchDigits(s string, max int) (int, string, bool) {
+	n := 0
+	for n < len(s) && n < max && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n == 0 {
+		return 0, s, false
+	}
+	x, _ := strconv.Atoi(s[:n])
+	return x, s[n:], true
+}
+
+// Returns the abbreviated names
+func abbreviations(names []string) []string {
+	abbrs := make([]string, len(names))
+	for i, name := range names {
+		abbrs[i] = name[:3]
+	}
+	return abbrs
+}
+
+// The parsed parts of a strptime
+type parsed struct {
+	fields
+	hour12    int // hour from %I
+	pm        int // -1 if AM, 1 if PM and 0 if not given
+	julian    int // day of the year from %j or 0
+	weekday   int // Monday is 0 or -1
+	week      int // week of the year or -1
+	weekStart int // the weekday weeks start on for week
+	isoYear   int // from %G or -1
+	isoWeek   int // from %V or -1
+	hasHour12 bool
+}
+
+// Parses s according to the strptime format into p returning the
+// rest of s
+func (p *parsed) parse(s, format string) (string, bool, error) {
+	var ok bool
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			// Whitespace matches any amount of whitespace
+			n := 0
+			for n < len(s) && strings.IndexByte(" \t\n\r\f\v", s[n]) >= 0 {
+				n++
+			}
+			s = s[n:]
+			continue
+		}
+		if c != '%' {
+			if s == "" || s[0] != c {
+				return s, false, nil
+			}
+			s = s[1:]
+			continue
+		}
+		i++
+		if i >= len(format) {
+			return s, false, py.ExceptionNewf(py.ValueError, "stray %% in format '%s'", format)
+		}
+		switch format[i] {
+		case 'Y':
+			p.year, s, ok = parseDigits(s, 4)
+		case 'y':
+			var y int
+			y, s, ok = parseDigits(s, 2)
+			if y < 69 {
+				y += 2000
+			} else {
+				y += 1900
+			}
+			p.year = y
+		case 'm':
+			p.month, s, ok = matchDigits(s, 2)
+			ok = ok && p.month >= 1 && p.month <= 12
+		case 'd':
+			p.day, s, ok = matchDigits(s, 2)
+			ok = ok && p.day >= 1 && p.day <= 31
+		case 'H':
+			p.hour, s, ok = matchDigits(s, 2)
+			ok = ok && p.hour <= 23
+		case 'I':
+			p.hour12, s, ok = matchDigits(s, 2)
+			ok = ok && p.hour12 >= 1 && p.hour12 <= 12
+			p.hasHour12 = true
+		case 'M':
+			p.minute, s, ok = matchDigits(s, 2)
+			ok = ok && p.minute <= 59
+		case 'S':
+			p.second, s, ok = matchDigits(s, 2)
+			ok = ok && p.second <= 61
+		case 'f':
+			n := 0
+			for n < len(s) && n < 6 && s[n] >= '0' && s[n] <= '9' {
+				n++
+			}
+			ok = n > 0
+			if ok {
+				p.microsecond, _ = strconv.Atoi(s[:n] + strings.Repeat("0", 6-n))
+				s = s[n:]
+			}
+		case 'p':
+			var i int
+			i, s, ok = matchName(s, []string{"AM", "PM"})
+			p.pm = 2*i - 1
+		case 'a':
+			p.weekday, s, ok = matchName(s, abbreviations(dayNames))
+		case 'A':
+			p.weekday, s, ok = matchName(s, dayNames)
+		case 'w':
+			var w int
+			w, s, ok = matchDigits(s, 1)
+			ok = ok && w <= 6
+			p.weekday = (w + 6) % 7
+		case 'u':
+			var u int
+			u, s, ok = matchDigits(s, 1)
+			ok = ok && u >= 1 && u <= 7
+			p.weekday = u - 1
+		case 'b', 'h':
+			p.month, s, ok = matchName(s, abbreviations(monthNames))
+			p.month++
+		case 'B':
+			p.month, s, ok = matchName(s, monthNames)
+			p.month++
+		case 'j':
+			p.julian, s, ok = matchDigits(s, 3)
+			ok = ok && p.julian >= 1 && p.julian <= 366
+		case 'U', 'W':
+			p.week, s, ok = matchDigits(s, 2)
+			ok = ok && p.week <= 53
+			p.weekStart = 0
+			if format[i] == 'U' {
+				p.weekStart = 6
+			}
+		case 'G':
+			p.isoYear, s, ok = parseDigits(s, 4)
+		case 'V':
+			p.isoWeek, s, ok = matchDigits(s, 2)
+			ok = ok && p.isoWeek >= 1 && p.isoWeek <= 53
+		case 'z':
+			s, ok = p.parseOffset(s)
+		case 'Z':
+			n := 0
+			for n < len(s) && (s[n] >= 'A' && s[n] <= 'Z' || s[n] >= 'a' && s[n] <= 'z') {
+				n++
+			}
+			ok = n > 0
+			s = s[n:]
+		case 'c', 'x', 'X':
+			sub := map[byte]string{'c': "%a %b %d %H:%M:%S %Y", 'x': "%m/%d/%y", 'X': "%H:%M:%S"}[format[i]]
+			var err error
+			s, ok, err = p.parse(s, sub)
+			if err != nil {
+				return s, false, err
+			}
+		case '%':
+			ok = strings.HasPrefix(s, "%")
+			if ok {
+				s = s[1:]
+			}
+		default:
+			return s, false, py.ExceptionNewf(py.ValueError, "'%c' is a bad directive in format '%s'", format[i], format)
+		}
+		if !ok {
+			return s, false, nil
+		}
+	}
+	return s, true, nil
+}
+
+// Parses the %z offset into the tzinfo
+func (p *parsed) parseOffset(s string) (string, bool) {
+	if strings.HasPrefix(s, "Z") {
+		p.tzinfo = UTC
+		return s[1:], true
+	}
+	if s == "" || (s[0] != '+' && s[0] != '-') {
+		return s, false
+	}
+	n := 1
+	for n < len(s) && (s[n] >= '0' && s[n] <= '9' || s[n] == ':' || s[n] == '.') {
+		n++
+	}
+	hour, minute, second, microsecond, rest, ok := parseISOClock(s[1:n])
+	if !ok || n < 5 || hour > 23 || minute > 59 || second > 59 {
+		return s, false
+	}
+	us := int64(hour*3600+minute*60+second)*usPerSecond + int64(microsecond)
+	if s[0] == '-' {
+		us = -us
+	}
+	tz, err := NewTimeZone(NewTimeDelta(us), nil)
+	if err != nil {
+		return s, false
+	}
+	p.tzinfo = tz
+	return rest + s[n:], true
+}
+
+// Parses s according to the format like time.strptime
+func strptime(s, format string) (fields, error) {
+	p := parsed{fields: fields{year: 1900, month: 1, day: 1}, weekday: -1, week: -1, isoYear: -1, isoWeek: -1}
+	rest, ok, err := p.parse(s, format)
+	if err != nil {
+		return p.fields, err
+	}
+	if !ok {
+		return p.fields, py.ExceptionNewf(py.ValueError, "time data %s does not match format %s", mustRepr(py.String(s)), mustRepr(py.String(format)))
+	}
+	if rest != "" {
+		return p.fields, py.ExceptionNewf(py.ValueError, "unconverted data remains: %s", rest)
+	}
+	if p.hasHour12 {
+		p.hour = p.hour12 % 12
+		if p.pm == 1 {
+			p.hour += 12
+		}
+	}
+	if p.second > 59 {
+		p.second = 59
+	}
+	switch {
+	case p.isoYear >= 0 || p.isoWeek >= 0:
+		if p.isoYear < 0 || p.isoWeek < 0 || p.weekday < 0 {
+			return p.fields, py.ExceptionNewf(py.ValueError, "ISO year directive '%%G' must be used with the ISO week directive '%%V' and a weekday directive ('%%A', '%%a', '%%w', or '%%u').")
+		}
+		p.year, p.month, p.day, err = fromISOCalendar(p.isoYear, p.isoWeek, p.weekday+1)
+		if err != nil {
+			return p.fields, err
+		}
+	case p.julian > 0:
+		p.year, p.month, p.day, err = fromOrdinal(toOrdinal(p.year, 1, 1) + p.julian - 1)
+		if err != nil {
+			return p.fields, err
+		}
+	case p.week >= 0 && p.weekday >= 0:
+		// Day 0 of week 1 is the first weekStart day of the year
+		jan1 := toOrdinal(p.year, 1, 1)
+		jan1Weekday := (int(ordinalTime(jan1).Weekday()) + 6) % 7
+		first := jan1 + (p.weekStart-jan1Weekday+7)%7
+		n := first + (p.week-1)*7 + (p.weekday-p.weekStart+7)%7
+		p.year, p.month, p.day, err = fromOrdinal(n)
+		if err != nil {
+			return p.fields, err
+		}
+	}
+	return p.fields, checkDate(p.year, p.month, p.day)
+}
@@ -0,0 +1,243 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import datetime
+from datetime import date, time, timedelta, timezone, tzinfo
+from libtest import *
+
+doc = "module"
+assert datetime.MINYEAR == 1
+assert datetime.MAXYEAR == 9999
+assert datetime.datetime.__module__ == "datetime"
+
+doc = "timedelta constructor"
+td = timedelta(days=1, hours=2, minutes=3, seconds=4, milliseconds=5, microseconds=6, weeks=1)
+assert td.days == 8
+assert td.seconds == 2*3600 + 3*60 + 4
+assert td.microseconds == 5006
+assert timedelta() == timedelta(0)
+assert timedelta(seconds=-1).days == -1
+assert timedelta(seconds=-1).seconds == 86399
+assert timedelta(hours=1.5) == timedelta(minutes=90)
+assert timedelta(microseconds=0.5) == timedelta(0)
+assert timedelta(microseconds=1.5) == timedelta(microseconds=2)
+assert timedelta(days=0.5) == timedelta(hours=12)
+assert repr(timedelta(0)) == "datetime.timedelta(0)"
+assert repr(timedelta(days=1, seconds=2)) == "datetime.timedelta(days=1, seconds=2)"
+assert repr(timedelta(microseconds=-1)) == "datetime.timedelta(days=-1, seconds=86399, microseconds=999999)"
+assert str(timedelta(days=1, seconds=5)) == "1 day, 0:00:05"
+assert str(timedelta(days=-2, hours=1)) == "-2 days, 1:00:00"
+assert str(timedelta(hours=25, microseconds=7)) == "1 day, 1:00:00.000007"
+assertRaisesText(OverflowError, "days=1000000000; must have magnitude <= 999999999", timedelta, 10**9)
+assertRaises(TypeError, timedelta, "1")
+
+doc = "timedelta arithmetic"
+h = timedelta(hours=1)
+assert h + h == timedelta(hours=2)
+assert h - 2*h == -h
+assert h * 2.5 == timedelta(minutes=150)
+assert 3 * h == timedelta(hours=3)
+assert h / 2 == timedelta(minutes=30)
+assert h / timedelta(minutes=20) == 3.0
+assert h // 7 == timedelta(microseconds=514285714)
+assert h // timedelta(minutes=25) == 2
+assert h % timedelta(minutes=25) == timedelta(minutes=10)
+assert divmod(5*h, -2*h) == (-3, -h)
+assert divmod(5*h, 2*h) == (2, h)
+assert abs(-h) == h
+assert +h == h
+assert not timedelta(0)
+assert h
+assert h.total_seconds() == 3600.0
+assert h < 2*h and 2*h > h and h <= h and h >= h and h != 2*h
+assert (h == 1) == False
+assert hash(h) == hash(timedelta(seconds=3600))
+assert timedelta.max == timedelta(days=999999999, seconds=86399, microseconds=999999)
+assert timedelta.min == timedelta(-999999999)
+assert timedelta.resolution == timedelta(microseconds=1)
+assertRaises(ZeroDivisionError, lambda: h // 0)
+assertRaises(TypeError, lambda: h < 1)
+assertRaises(OverflowError, lambda: timedelta.max + h)
+
+doc = "date"
+d = date(2020, 2, 28)
+assert repr(d) == "datetime.date(2020, 2, 28)"
+assert str(d) == "2020-02-28"
+assert d.year == 2020 and d.month == 2 and d.day == 28
+assert d + timedelta(2) == date(2020, 3, 1)
+assert timedelta(2) + d == date(2020, 3, 1)
+assert d - timedelta(days=59) == date(2019, 12, 31)
+assert d - date(2020, 1, 1) == timedelta(58)
+assert d + timedelta(hours=23) == d
+assert d.weekday() == 4
+assert d.isoweekday() == 5
+assert tuple(d.isocalendar()) == (2020, 9, 5)
+assert d.toordinal() == 737483
+assert date.fromordinal(737483) == d
+assert d.replace(day=1) == date(2020, 2, 1)
+assert d.isoformat() == "2020-02-28"
+assert d.ctime() == "Fri Feb 28 00:00:00 2020"
+assert d.strftime("%Y/%m/%d %a %j") == "2020/02/28 Fri 059"
+assert format(d, "%d.%m") == "28.02"
+assert format(d, "") == "2020-02-28"
+assert d < date(2021, 1, 1) and d == date(2020, 2, 28) and d != date(2020, 2, 29)
+assert hash(d) == hash(date(2020, 2, 28))
+assert date.fromisoformat("2020-02-28") == d
+assert date.fromisocalendar(2020, 9, 5) == d
+assert date.min == date(1, 1, 1)
+assert date.max == date(9999, 12, 31)
+assert date.resolution == timedelta(1)
+assert isinstance(date.today(), date)
+assert date.fromtimestamp(0) in (date(1969, 12, 31), date(1970, 1, 1), date(1970, 1, 2))
+assertRaisesText(ValueError, "day is out of range for month", date, 2019, 2, 29)
+assertRaisesText(ValueError, "month must be in 1..12", date, 2019, 13, 1)
+assertRaisesText(ValueError, "year 0 is out of range", date, 0, 1, 1)
+assertRaisesText(TypeError, "'float' object cannot be interpreted as an integer", date, 2020.0, 1, 1)
+assertRaisesText(ValueError, "Invalid isoformat string: '2020-1-1'", date.fromisoformat, "2020-1-1")
+assertRaisesText(ValueError, "Invalid day: 8 (range is [1, 7])", date.fromisocalendar, 2020, 1, 8)
+assertRaises(OverflowError, lambda: date.max + timedelta(1))
+assertRaises(TypeError, lambda: d < 1)
+
+doc = "time"
+t = time(12, 30, 15, 500, tzinfo=timezone.utc)
+assert repr(t) == "datetime.time(12, 30, 15, 500, tzinfo=datetime.timezone.utc)"
+assert repr(time(12, 30)) == "datetime.time(12, 30)"
+assert repr(time(1, fold=1)) == "datetime.time(1, 0, fold=1)"
+assert str(t) == "12:30:15.000500+00:00"
+assert t.isoformat(timespec="minutes") == "12:30+00:00"
+assert t.isoformat(timespec="milliseconds") == "12:30:15.000+00:00"
+assert t.utcoffset() == timedelta(0)
+assert t.tzname() == "UTC"
+assert t.dst() is None
+assert t.hour == 12 and t.minute == 30 and t.second == 15 and t.microsecond == 500
+assert t.tzinfo is timezone.utc
+assert time(1).tzinfo is None
+assert time.fromisoformat("10:20:30.123+01:00") == time(9, 20, 30, 123000, tzinfo=timezone.utc)
+assert t.replace(hour=1, tzinfo=None) == time(1, 30, 15, 500)
+assert time(1, 2, 3).strftime("%H:%M:%S") == "01:02:03"
+assert format(time(1, 2), "%I %p") == "01 AM"
+assert time(1) < time(2)
+assert time() == time(0, 0)
+assert time.min == time(0) and time.max == time(23, 59, 59, 999999)
+assert time(0)
+assertRaisesText(ValueError, "hour must be in 0..23", time, 24)
+assertRaisesText(ValueError, "fold must be either 0 or 1", time, fold=2)
+assertRaisesText(TypeError, "can't compare offset-naive and offset-aware times", lambda: t < time(1))
+assertRaisesText(ValueError, "Unknown timespec value", t.isoformat, timespec="x")
+
+doc = "timezone"
+est = timezone(timedelta(hours=-5), "EST")
+assert repr(est) == "datetime.timezone(datetime.timedelta(days=-1, seconds=68400), 'EST')"
+assert repr(timezone.utc) == "datetime.timezone.utc"
+assert timezone(timedelta(0)) is timezone.utc
+assert datetime.UTC is timezone.utc
+assert str(timezone(timedelta(hours=-5, minutes=-30))) == "UTC-05:30"
+assert str(timezone.max) == "UTC+23:59"
+assert str(timezone.min) == "UTC-23:59"
+assert est.utcoffset(None) == timedelta(hours=-5)
+assert est.dst(None) is None
+assert est.tzname(None) == "EST"
+assert est == timezone(timedelta(hours=-5))
+assert hash(est) == hash(timezone(timedelta(hours=-5)))
+assert isinstance(est, tzinfo)
+assertRaisesText(ValueError, "offset must be a timedelta strictly between -timedelta(hours=24) and timedelta(hours=24), not datetime.timedelta(days=1).", timezone, timedelta(hours=24))
+assertRaises(TypeError, timezone, 5)
+assertRaises(TypeError, est.utcoffset, 5)
+assertRaises(NotImplementedError, tzinfo().utcoffset, None)
+
+doc = "datetime"
+dt = datetime.datetime
+x = dt(2021, 3, 4, 5, 6, 7, 8)
+assert repr(x) == "datetime.datetime(2021, 3, 4, 5, 6, 7, 8)"
+assert str(x) == "2021-03-04 05:06:07.000008"
+assert x.isoformat() == "2021-03-04T05:06:07.000008"
+assert x.isoformat(" ", "seconds") == "2021-03-04 05:06:07"
+assert x.date() == date(2021, 3, 4)
+assert x.time() == time(5, 6, 7, 8)
+assert x + timedelta(days=400) == dt(2022, 4, 8, 5, 6, 7, 8)
+assert x - dt(2021, 1, 1) == timedelta(days=62, seconds=5*3600+6*60+7, microseconds=8)
+assert x.replace(year=2000) == dt(2000, 3, 4, 5, 6, 7, 8)
+assert isinstance(x, date)
+assert x.tzinfo is None and x.utcoffset() is None
+assert dt.combine(date(2020, 1, 1), time(1, 2)) == dt(2020, 1, 1, 1, 2)
+assert dt.fromisoformat("2021-03-04T05:06:07.250+01:30") == dt(2021, 3, 4, 3, 36, 7, 250000, tzinfo=timezone.utc)
+assert dt.fromisoformat("2021-03-04") == dt(2021, 3, 4)
+assert dt.min == dt(1, 1, 1)
+assert dt.max == dt(9999, 12, 31, 23, 59, 59, 999999)
+assert dt.resolution == timedelta(microseconds=1)
+assert hash(x) == hash(dt(2021, 3, 4, 5, 6, 7, 8))
+assert x.toordinal() == date(2021, 3, 4).toordinal()
+assert x.strftime("%c|%x|%X|%I %p|%y|%f|%z|%%") == "Thu Mar  4 05:06:07 2021|03/04/21|05:06:07|05 AM|21|000008||%"
+assert format(x, "%Y") == "2021"
+assert str(dt(2020, 1, 1, 12) + timedelta(microseconds=-1)) == "2020-01-01 11:59:59.999999"
+assert isinstance(dt.now(), dt)
+assert dt.now(timezone.utc).tzinfo is timezone.utc
+assertRaisesText(TypeError, "can't compare datetime.datetime to datetime.date", lambda: x < date(2020, 1, 1))
+assert (x == date(2021, 3, 4)) == False
+
+doc = "aware datetime"
+y = dt(2021, 3, 4, 5, 6, 7, tzinfo=timezone(timedelta(hours=2)))
+assert repr(y) == "datetime.datetime(2021, 3, 4, 5, 6, 7, tzinfo=datetime.timezone(datetime.timedelta(seconds=7200)))"
+assert str(y) == "2021-03-04 05:06:07+02:00"
+assert y.utcoffset() == timedelta(hours=2)
+assert y.tzname() == "UTC+02:00"
+assert y.astimezone(timezone.utc) == dt(2021, 3, 4, 3, 6, 7, tzinfo=timezone.utc)
+assert str(y.astimezone(timezone.utc)) == "2021-03-04 03:06:07+00:00"
+assert y.timestamp() == 1614827167.0
+assert y == dt(2021, 3, 4, 3, 6, 7, tzinfo=timezone.utc)
+assert y - dt(2021, 3, 4, tzinfo=timezone.utc) == timedelta(hours=3, minutes=6, seconds=7)
+assert y.strftime("%z %Z") == "+0200 UTC+02:00"
+assert dt.fromtimestamp(0, timezone.utc) == dt(1970, 1, 1, tzinfo=timezone.utc)
+assert dt.utcfromtimestamp(86400.5) == dt(1970, 1, 2, 0, 0, 0, 500000)
+assert y.timetz() == time(5, 6, 7, tzinfo=timezone(timedelta(hours=2)))
+assert (x == y) == False
+assertRaisesText(TypeError, "can't compare offset-naive and offset-aware datetimes", lambda: x < y)
+assertRaisesText(TypeError, "can't subtract offset-naive and offset-aware datetimes", lambda: x - y)
+
+doc = "tzinfo subclass"
+class EST(tzinfo):
+    def utcoffset(self, d):
+        return timedelta(hours=-5)
+    def dst(self, d):
+        return timedelta(0)
+    def tzname(self, d):
+        return "EST"
+z = dt(2020, 1, 1, 12, tzinfo=EST())
+assert str(z) == "2020-01-01 12:00:00-05:00"
+assert z.tzname() == "EST"
+assert z.astimezone(timezone.utc) == dt(2020, 1, 1, 17, tzinfo=timezone.utc)
+assert str(dt(2020, 1, 1, 17, tzinfo=timezone.utc).astimezone(EST())) == "2020-01-01 12:00:00-05:00"
+assert format(z, "%H %Z %z") == "12 EST -0500"
+class Bad(tzinfo):
+    def utcoffset(self, d):
+        return 1
+assertRaises(TypeError, dt(2020, 1, 1, tzinfo=Bad()).utcoffset)
+
+doc = "strptime"
+assert dt.strptime("2020-01-01 01:02", "%Y-%m-%d %H:%M") == dt(2020, 1, 1, 1, 2)
+assert dt.strptime("Mar 4 2021 5PM", "%b %d %Y %I%p") == dt(2021, 3, 4, 17)
+assert dt.strptime("2021 100", "%Y %j") == dt(2021, 4, 10)
+assert dt.strptime("12:30 +0530", "%H:%M %z") == dt(1900, 1, 1, 12, 30, tzinfo=timezone(timedelta(hours=5, minutes=30)))
+assertRaisesText(ValueError, "time data 'x' does not match format '%Y'", dt.strptime, "x", "%Y")
+assertRaisesText(ValueError, "unconverted data remains: x", dt.strptime, "2020x", "%Y")
+
+doc = "subclass"
+class MyDate(date):
+    pass
+m = MyDate(2020, 1, 1)
+assert repr(m) == "MyDate(2020, 1, 1)"
+assert type(m + timedelta(1)) == MyDate
+assert type(MyDate.today()) == MyDate
+assert type(MyDate.fromordinal(1)) == MyDate
+m.x = 1
+assert m.x == 1
+class MyDateTime(dt):
+    def hello(self):
+        return self.year
+n = MyDateTime(2020, 1, 2, 3)
+assert n.hello() == 2020
+assert type(n.replace(year=2021)) == MyDateTime
+assert isinstance(n, date)
+
+doc = "finished"
@@ -0,0 +1,318 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+var TimeType = py.ObjectType.NewTypeFlags("time", time_doc, TimeNew, nil, py.ObjectType.Flags|subclassFlags)
+
+const time_doc = `time([hour[, minute[, second[, microsecond[, tzinfo]]]]]) --> a time object
+
+All arguments are optional. tzinfo may be None, or an instance of
+a tzinfo subclass. The remaining arguments may be ints.`
+
+// Time is a time of day with an optional tzinfo
+type Time struct {
+	object
+	hour        int
+	minute      int
+	second      int
+	microsecond int
+	tzinfo      py.Object // nil for None
+	fold        int
+}
+
+// Makes a time of class cls which may be a python subclass
+func makeTime(cls *py.Type, hour, minute, second, microsecond int, tzinfo py.Object, fold int) (py.Object, error) {
+	if cls == TimeType {
+		return &Time{object: newObject(TimeType), hour: hour, minute: minute, second: second, microsecond: microsecond, tzinfo: tzinfo, fold: fold}, nil
+	}
+	var kwargs py.StringDict
+	if fold != 0 {
+		kwargs = py.StringDict{"fold": py.Int(fold)}
+	}
+	return py.Call(cls, py.Tuple{py.Int(hour), py.Int(minute), py.Int(second), py.Int(microsecond), noneIfNil(tzinfo)}, kwargs)
+}
+
+// Returns None for a nil tzinfo
+func noneIfNil(tzinfo py.Object) py.Object {
+	if tzinfo == nil {
+		return py.None
+	}
+	return tzinfo
+}
+
+// TimeNew makes a time from its fields
+func TimeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	kwargs, fold, err := popFold(kwargs)
+	if err != nil {
+		return nil, err
+	}
+	var hourObj, minuteObj, secondObj, microsecondObj, tzinfo py.Object
+	err = py.ParseTupleAndKeywords(args, kwargs, "|OOOOO:time", []string{"hour", "minute", "second", "microsecond", "tzinfo"}, &hourObj, &minuteObj, &secondObj, &microsecondObj, &tzinfo)
+	if err != nil {
+		return nil, err
+	}
+	var hour, minute, second, microsecond int
+	err = getInts([]py.Object{hourObj, minuteObj, secondObj, microsecondObj}, &hour, &minute, &second, &microsecond)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTime(hour, minute, second, microsecond)
+	if err != nil {
+		return nil, err
+	}
+	tzinfo, err = checkTzInfo(tzinfo)
+	if err != nil {
+		return nil, err
+	}
+	return &Time{object: newObject(metatype), hour: hour, minute: minute, second: second, microsecond: microsecond, tzinfo: tzinfo, fold: fold}, nil
+}
+
+// Type of this object
+func (t *Time) Type() *py.Type {
+	return t.Base
+}
+
+// Returns the utcoffset of the time
+func (t *Time) utcoffset() (*TimeDelta, error) {
+	return tzOffset(t.tzinfo, "utcoffset", py.None)
+}
+
+// Returns the time in microseconds since midnight
+func (t *Time) us() int64 {
+	return int64(t.hour*3600+t.minute*60+t.second)*usPerSecond + int64(t.microsecond)
+}
+
+// Returns the ISO format of the time
+func (t *Time) isoformat(timespec string) (string, error) {
+	s, err := formatTime(t.hour, t.minute, t.second, t.microsecond, timespec)
+	if err != nil {
+		return "", err
+	}
+	offset, err := t.utcoffset()
+	if err != nil {
+		return "", err
+	}
+	return s + formatOffset(offset, ":"), nil
+}
+
+func (t *Time) M__repr__() (py.Object, error) {
+	var s string
+	switch {
+	case t.microsecond != 0:
+		s = fmt.Sprintf("%d, %d, %d, %d", t.hour, t.minute, t.second, t.microsecond)
+	case t.second != 0:
+		s = fmt.Sprintf("%d, %d, %d", t.hour, t.minute, t.second)
+	default:
+		s = fmt.Sprintf("%d, %d", t.hour, t.minute)
+	}
+	if t.tzinfo != nil {
+		s += ", tzinfo=" + mustRepr(t.tzinfo)
+	}
+	if t.fold != 0 {
+		s += ", fold=1"
+	}
+	return py.String(typeName(t.Base) + "(" + s + ")"), nil
+}
+
+func (t *Time) M__str__() (py.Object, error) {
+	s, err := t.isoformat("auto")
+	if err != nil {
+		return nil, err
+	}
+	return py.String(s), nil
+}
+
+func (t *Time) M__format__(formatSpec py.Object) (py.Object, error) {
+	return formatObject(t, formatSpec)
+}
+
+func (t *Time) M__hash__() (py.Object, error) {
+	us, _, err := t.utcUs()
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(us).M__hash__()
+}
+
+// Compares t with other using op
+func (t *Time) richCompare(other py.Object, op string) (py.Object, error) {
+	b, ok := other.(*Time)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	a, aOffset, err := t.utcUs()
+	if err != nil {
+		return nil, err
+	}
+	c, bOffset, err := b.utcUs()
+	if err != nil {
+		return nil, err
+	}
+	if (aOffset == nil) != (bOffset == nil) {
+		return mixedCompare(op, "can't compare offset-naive and offset-aware times")
+	}
+	return compareResult(op, compareInt64s(a, c)), nil
+}
+
+// Returns the time in microseconds adjusted to UTC if it has an offset
+func (t *Time) utcUs() (int64, *TimeDelta, error) {
+	offset, err := t.utcoffset()
+	if err != nil {
+		return 0, nil, err
+	}
+	us := t.us()
+	if offset != nil {
+		us -= offset.us()
+	}
+	return us, offset, nil
+}
+
+func (t *Time) M__eq__(other py.Object) (py.Object, error) {
+	return t.richCompare(other, "==")
+}
+
+func (t *Time) M__ne__(other py.Object) (py.Object, error) {
+	return t.richCompare(other, "!=")
+}
+
+func (t *Time) M__lt__(other py.Object) (py.Object, error) {
+	return t.richCompare(other, "<")
+}
+
+func (t *Time) M__le__(other py.Object) (py.Object, error) {
+	return t.richCompare(other, "<=")
+}
+
+func (t *Time) M__gt__(other py.Object) (py.Object, error) {
+	return t.richCompare(other, ">")
+}
+
+func (t *Time) M__ge__(other py.Object) (py.Object, error) {
+	return t.richCompare(other, ">=")
+}
+
+// Returns the timespec argument of isoformat
+func getTimespec(name string, args py.Tuple, kwargs py.StringDict, kwlist []string, results ...*py.Object) (string, error) {
+	var timespec py.Object = py.String("auto")
+	results = append(results, &timespec)
+	kwlist = append(kwlist, "timespec")
+	format := "|" + strings.Repeat("O", len(results)) + ":" + name
+	err := py.ParseTupleAndKeywords(args, kwargs, format, kwlist, results...)
+	if err != nil {
+		return "", err
+	}
+	s, ok := timespec.(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "isoformat() argument 'timespec' must be str, not %s", timespec.Type().Name)
+	}
+	return string(s), nil
+}
+
+// Reads the arguments of replace for the time fields starting with
+// the values from t
+func replaceTime(name string, args py.Tuple, kwargs py.StringDict, kwlist []string, ints []*int, t *Time) (tzinfo py.Object, fold int, err error) {
+	_, hasFold := kwargs["fold"]
+	kwargs, fold, err = popFold(kwargs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !hasFold {
+		fold = t.fold
+	}
+	kwlist = append(kwlist, "hour", "minute", "second", "microsecond", "tzinfo")
+	ints = append(ints, &t.hour, &t.minute, &t.second, &t.microsecond)
+	objs := make([]py.Object, len(kwlist))
+	results := make([]*py.Object, len(kwlist))
+	for i := range objs {
+		results[i] = &objs[i]
+	}
+	err = py.ParseTupleAndKeywords(args, kwargs, "|"+strings.Repeat("O", len(kwlist))+":"+name, kwlist, results...)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = getInts(objs[:len(ints)], ints...)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = checkTime(t.hour, t.minute, t.second, t.microsecond)
+	if err != nil {
+		return nil, 0, err
+	}
+	tzinfo = t.tzinfo
+	if tz := objs[len(ints)]; tz != nil {
+		tzinfo, err = checkTzInfo(tz)
+	}
+	return tzinfo, fold, err
+}
+
+func init() {
+	TimeType.Dict["hour"] = intProperty(func(self py.Object) int { return self.(*Time).hour }, "")
+	TimeType.Dict["minute"] = intProperty(func(self py.Object) int { return self.(*Time).minute }, "")
+	TimeType.Dict["second"] = intProperty(func(self py.Object) int { return self.(*Time).second }, "")
+	TimeType.Dict["microsecond"] = intProperty(func(self py.Object) int { return self.(*Time).microsecond }, "")
+	TimeType.Dict["fold"] = intProperty(func(self py.Object) int { return self.(*Time).fold }, "")
+	TimeType.Dict["tzinfo"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return noneIfNil(self.(*Time).tzinfo), nil
+		},
+	}
+	TimeType.Dict["fromisoformat"] = classMethod("fromisoformat", func(cls, s py.Object) (py.Object, error) {
+		str, ok := s.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "fromisoformat: argument must be str")
+		}
+		hour, minute, second, microsecond, tzinfo, err := parseISOTime(string(str))
+		if err != nil {
+			return nil, py.ExceptionNewf(py.ValueError, "Invalid isoformat string: %s", mustRepr(s))
+		}
+		return makeTime(cls.(*py.Type), hour, minute, second, microsecond, tzinfo, 0)
+	}, "string -> time from a string in ISO 8601 format")
+	addMethods(TimeType,
+		py.MustNewMethod("replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			t := *self.(*Time)
+			tzinfo, fold, err := replaceTime("replace", args, kwargs, nil, nil, &t)
+			if err != nil {
+				return nil, err
+			}
+			return makeTime(resultType(t.Base, TimeType), t.hour, t.minute, t.second, t.microsecond, tzinfo, fold)
+		}, 0, "Return time with new specified fields."),
+		py.MustNewMethod("isoformat", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			timespec, err := getTimespec("isoformat", args, kwargs, nil)
+			if err != nil {
+				return nil, err
+			}
+			s, err := self.(*Time).isoformat(timespec)
+			if err != nil {
+				return nil, err
+			}
+			return py.String(s), nil
+		}, 0, "Return string in ISO 8601 format, [HH[:MM[:SS[.mmm[uuu]]]]][+HH:MM].\n\nThe optional argument timespec specifies the number of additional terms\nof the time to include. Valid options are 'auto', 'hours', 'minutes',\n'seconds', 'milliseconds' and 'microseconds'.\n"),
+		py.MustNewMethod("utcoffset", func(self py.Object) (py.Object, error) {
+			return tzOffsetObject(self.(*Time).tzinfo, "utcoffset", py.None)
+		}, 0, "Return self.tzinfo.utcoffset(self)."),
+		py.MustNewMethod("dst", func(self py.Object) (py.Object, error) {
+			return tzOffsetObject(self.(*Time).tzinfo, "dst", py.None)
+		}, 0, "Return self.tzinfo.dst(self)."),
+		py.MustNewMethod("tzname", func(self py.Object) (py.Object, error) {
+			return tzName(self.(*Time).tzinfo, py.None)
+		}, 0, "Return self.tzinfo.tzname(self)."),
+		py.MustNewMethod("strftime", func(self, format py.Object) (py.Object, error) {
+			return callStrftime(self, format)
+		}, 0, "format -> strftime() style string."),
+		py.MustNewMethod("__format__", func(self, formatSpec py.Object) (py.Object, error) {
+			return formatObject(self, formatSpec)
+		}, 0, "Formats self with strftime."),
+	)
+	TimeType.Dict["min"] = &Time{object: newObject(TimeType)}
+	TimeType.Dict["max"] = &Time{object: newObject(TimeType), hour: 23, minute: 59, second: 59, microsecond: 999999}
+	TimeType.Dict["resolution"] = NewTimeDelta(1)
+}
@@ -0,0 +1,429 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const (
+	usPerSecond  = 1000000
+	usPerDay     = 86400 * usPerSecond
+	maxDeltaDays = 999999999
+)
+
+var TimeDeltaType = py.ObjectType.NewTypeFlags("timedelta", timedelta_doc, TimeDeltaNew, nil, py.ObjectType.Flags|py.TPFLAGS_BASETYPE|py.TPFLAGS_SUBCLASS_NEW)
+
+const timedelta_doc = `Difference between two datetime values.
+
+timedelta(days=0, seconds=0, microseconds=0, milliseconds=0, minutes=0, hours=0, weeks=0)
+
+All arguments are optional and default to 0.
+Arguments may be integers or floats, and may be positive or negative.`
+
+// TimeDelta is a duration normalised so that 0 <= seconds < 86400
+// and 0 <= microseconds < 1000000
+type TimeDelta struct {
+	object
+	days         int
+	seconds      int
+	microseconds int
+}
+
+// Returns a normalised timedelta of type t from the number of microseconds
+func newTimeDelta(t *py.Type, us *big.Int) (*TimeDelta, error) {
+	days, rem := new(big.Int).DivMod(us, big.NewInt(usPerDay), new(big.Int))
+	if !days.IsInt64() || days.Int64() > maxDeltaDays || days.Int64() < -maxDeltaDays {
+		return nil, py.ExceptionNewf(py.OverflowError, "days=%s; must have magnitude <= %d", days, maxDeltaDays)
+	}
+	r := rem.Int64()
+	return &TimeDelta{
+		object:       newObject(t),
+		days:         int(days.Int64()),
+		seconds:      int(r / usPerSecond),
+		microseconds: int(r % usPerSecond),
+	}, nil
+}
+
+// NewTimeDelta returns a timedelta of the given number of microseconds
+func NewTimeDelta(us int64) *TimeDelta {
+	td, err := newTimeDelta(TimeDeltaType, big.NewInt(us))
+	if err != nil {
+		panic(err)
+	}
+	return td
+}
+
+// Microseconds returns the duration in microseconds
+func (td *TimeDelta) Microseconds() *big.Int {
+	us := big.NewInt(int64(td.days))
+	us.Mul(us, big.NewInt(usPerDay))
+	return us.Add(us, big.NewInt(int64(td.seconds)*usPerSecond+int64(td.microseconds)))
+}
+
+// Returns the duration in microseconds as an int64 which is big
+// enough for any timedelta which can be added to a datetime
+func (td *TimeDelta) us() int64 {
+	return int64(td.days)*usPerDay + int64(td.seconds)*usPerSecond + int64(td.microseconds)
+}
+
+// Returns the integer nearest to r rounding halves to even
+func roundHalfEven(r *big.Rat) *big.Int {
+	q, m := new(big.Int).DivMod(r.Num(), r.Denom(), new(big.Int))
+	// Compare twice the remainder with the denominator
+	switch m.Lsh(m, 1).Cmp(r.Denom()) {
+	case 1:
+		q.Add(q, big.NewInt(1))
+	case 0:
+		if q.Bit(0) == 1 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// Returns obj as an exact rational if it is an int or a float
+func getRat(obj py.Object) (*big.Rat, bool, error) {
+	if f, ok := obj.(py.Float); ok {
+		x := float64(f)
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return nil, true, py.ExceptionNewf(py.ValueError, "cannot convert float %s to integer", strings.ToLower(fmt.Sprint(x)))
+		}
+		return new(big.Rat).SetFloat64(x), true, nil
+	}
+	if b, ok := py.ConvertToBigInt(obj); ok {
+		return new(big.Rat).SetInt((*big.Int)(b)), true, nil
+	}
+	return nil, false, nil
+}
+
+// TimeDeltaNew makes a timedelta from the sum of its arguments
+func TimeDeltaNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var days, seconds, microseconds, milliseconds, minutes, hours, weeks py.Object
+	kwlist := []string{"days", "seconds", "microseconds", "milliseconds", "minutes", "hours", "weeks"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOOO:timedelta", kwlist, &days, &seconds, &microseconds, &milliseconds, &minutes, &hours, &weeks)
+	if err != nil {
+		return nil, err
+	}
+	units := []struct {
+		name  string
+		value py.Object
+		us    int64
+	}{
+		{"days", days, usPerDay},
+		{"seconds", seconds, usPerSecond},
+		{"microseconds", microseconds, 1},
+		{"milliseconds", milliseconds, 1000},
+		{"minutes", minutes, 60 * usPerSecond},
+		{"hours", hours, 3600 * usPerSecond},
+		{"weeks", weeks, 7 * usPerDay},
+	}
+	total := new(big.Rat)
+	for _, unit := range units {
+		if unit.value == nil {
+			continue
+		}
+		r, ok, err := getRat(unit.value)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "unsupported type for timedelta %s component: %s", unit.name, unit.value.Type().Name)
+		}
+		total.Add(total, r.Mul(r, new(big.Rat).SetInt64(unit.us)))
+	}
+	return newTimeDelta(metatype, roundHalfEven(total))
+}
+
+// Type of this object
+func (td *TimeDelta) Type() *py.Type {
+	return td.Base
+}
+
+func (td *TimeDelta) M__repr__() (py.Object, error) {
+	var parts []string
+	if td.days != 0 {
+		parts = append(parts, fmt.Sprintf("days=%d", td.days))
+	}
+	if td.seconds != 0 {
+		parts = append(parts, fmt.Sprintf("seconds=%d", td.seconds))
+	}
+	if td.microseconds != 0 {
+		parts = append(parts, fmt.Sprintf("microseconds=%d", td.microseconds))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "0")
+	}
+	return py.String(fmt.Sprintf("%s(%s)", typeName(td.Base), strings.Join(parts, ", "))), nil
+}
+
+func (td *TimeDelta) M__str__() (py.Object, error) {
+	s := fmt.Sprintf("%d:%02d:%02d", td.seconds/3600, td.seconds/60%60, td.seconds%60)
+	if td.microseconds != 0 {
+		s += fmt.Sprintf(".%06d", td.microseconds)
+	}
+	if td.days != 0 {
+		plural := "s"
+		if td.days == 1 || td.days == -1 {
+			plural = ""
+		}
+		s = fmt.Sprintf("%d day%s, %s", td.days, plural, s)
+	}
+	return py.String(s), nil
+}
+
+func (td *TimeDelta) M__hash__() (py.Object, error) {
+	return py.Tuple{py.Int(td.days), py.Int(td.seconds), py.Int(td.microseconds)}.M__hash__()
+}
+
+func (td *TimeDelta) M__bool__() (py.Object, error) {
+	return py.NewBool(td.days != 0 || td.seconds != 0 || td.microseconds != 0), nil
+}
+
+func (td *TimeDelta) M__neg__() (py.Object, error) {
+	return newTimeDelta(TimeDeltaType, new(big.Int).Neg(td.Microseconds()))
+}
+
+func (td *TimeDelta) M__pos__() (py.Object, error) {
+	return newTimeDelta(TimeDeltaType, td.Microseconds())
+}
+
+func (td *TimeDelta) M__abs__() (py.Object, error) {
+	if td.days < 0 {
+		return td.M__neg__()
+	}
+	return td.M__pos__()
+}
+
+func (td *TimeDelta) M__add__(other py.Object) (py.Object, error) {
+	if b, ok := other.(*TimeDelta); ok {
+		return newTimeDelta(TimeDeltaType, new(big.Int).Add(td.Microseconds(), b.Microseconds()))
+	}
+	return py.NotImplemented, nil
+}
+
+func (td *TimeDelta) M__radd__(other py.Object) (py.Object, error) {
+	return td.M__add__(other)
+}
+
+func (td *TimeDelta) M__sub__(other py.Object) (py.Object, error) {
+	if b, ok := other.(*TimeDelta); ok {
+		return newTimeDelta(TimeDeltaType, new(big.Int).Sub(td.Microseconds(), b.Microseconds()))
+	}
+	return py.NotImplemented, nil
+}
+
+func (td *TimeDelta) M__rsub__(other py.Object) (py.Object, error) {
+	if b, ok := other.(*TimeDelta); ok {
+		return b.M__sub__(td)
+	}
+	return py.NotImplemented, nil
+}
+
+func (td *TimeDelta) M__mul__(other py.Object) (py.Object, error) {
+	r, ok, err := getRat(other)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	r.Mul(r, new(big.Rat).SetInt(td.Microseconds()))
+	return newTimeDelta(TimeDeltaType, roundHalfEven(r))
+}
+
+func (td *TimeDelta) M__rmul__(other py.Object) (py.Object, error) {
+	return td.M__mul__(other)
+}
+
+// Returns a ZeroDivisionError if x is zero
+func checkDivisor(x *big.Int) error {
+	if x.Sign() == 0 {
+		return py.ExceptionNewf(py.ZeroDivisionError, "integer division or modulo by zero")
+	}
+	return nil
+}
+
+func (td *TimeDelta) M__truediv__(other py.Object) (py.Object, error) {
+	us := td.Microseconds()
+	if b, ok := other.(*TimeDelta); ok {
+		div := b.Microseconds()
+		if err := checkDivisor(div); err != nil {
+			return nil, err
+		}
+		f, _ := new(big.Rat).SetFrac(us, div).Float64()
+		return py.Float(f), nil
+	}
+	r, ok, err := getRat(other)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if r.Sign() == 0 {
+		return nil, py.ExceptionNewf(py.ZeroDivisionError, "division by zero")
+	}
+	r.Quo(new(big.Rat).SetInt(us), r)
+	return newTimeDelta(TimeDeltaType, roundHalfEven(r))
+}
+
+// Returns the floor quotient and remainder of td and b
+func (td *TimeDelta) divMod(b *TimeDelta) (*big.Int, *big.Int, error) {
+	div := b.Microseconds()
+	if err := checkDivisor(div); err != nil {
+		return nil, nil, err
+	}
+	q, m := new(big.Int).DivMod(td.Microseconds(), div, new(big.Int))
+	// big.Int.DivMod is Euclidean so fix up the floor division
+	if m.Sign() != 0 && div.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
+		m.Add(m, div)
+	}
+	return q, m, nil
+}
+
+func (td *TimeDelta) M__floordiv__(other py.Object) (py.Object, error) {
+	if b, ok := other.(*TimeDelta); ok {
+		q, _, err := td.divMod(b)
+		if err != nil {
+			return nil, err
+		}
+		return (*py.BigInt)(q).MaybeInt(), nil
+	}
+	if _, isFloat := other.(py.Float); isFloat {
+		return py.NotImplemented, nil
+	}
+	b, ok := py.ConvertToBigInt(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	div := (*big.Int)(b)
+	if err := checkDivisor(div); err != nil {
+		return nil, err
+	}
+	q, m := new(big.Int).DivMod(td.Microseconds(), div, new(big.Int))
+	if m.Sign() != 0 && div.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
+	}
+	return newTimeDelta(TimeDeltaType, q)
+}
+
+func (td *TimeDelta) M__mod__(other py.Object) (py.Object, error) {
+	if b, ok := other.(*TimeDelta); ok {
+		_, m, err := td.divMod(b)
+		if err != nil {
+			return nil, err
+		}
+		return newTimeDelta(TimeDeltaType, m)
+	}
+	return py.NotImplemented, nil
+}
+
+func (td *TimeDelta) M__divmod__(other py.Object) (py.Object, py.Object, error) {
+	b, ok := other.(*TimeDelta)
+	if !ok {
+		return py.NotImplemented, py.NotImplemented, nil
+	}
+	q, m, err := td.divMod(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	rem, err := newTimeDelta(TimeDeltaType, m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return (*py.BigInt)(q).MaybeInt(), rem, nil
+}
+
+// Compares td with other returning -1, 0, 1 or false if other isn't a timedelta
+func (td *TimeDelta) compare(other py.Object) (int, bool) {
+	b, ok := other.(*TimeDelta)
+	if !ok {
+		return 0, false
+	}
+	return td.Microseconds().Cmp(b.Microseconds()), true
+}
+
+func (td *TimeDelta) M__eq__(other py.Object) (py.Object, error) {
+	c, ok := td.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c == 0), nil
+}
+
+func (td *TimeDelta) M__ne__(other py.Object) (py.Object, error) {
+	c, ok := td.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c != 0), nil
+}
+
+func (td *TimeDelta) M__lt__(other py.Object) (py.Object, error) {
+	c, ok := td.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c < 0), nil
+}
+
+func (td *TimeDelta) M__le__(other py.Object) (py.Object, error) {
+	c, ok := td.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c <= 0), nil
+}
+
+func (td *TimeDelta) M__gt__(other py.Object) (py.Object, error) {
+	c, ok := td.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c > 0), nil
+}
+
+func (td *TimeDelta) M__ge__(other py.Object) (py.Object, error) {
+	c, ok := td.compare(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(c >= 0), nil
+}
+
+func init() {
+	TimeDeltaType.Dict["days"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*TimeDelta).days), nil
+		},
+		Doc: "Number of days.",
+	}
+	TimeDeltaType.Dict["seconds"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*TimeDelta).seconds), nil
+		},
+		Doc: "Number of seconds (>= 0 and less than 1 day).",
+	}
+	TimeDeltaType.Dict["microseconds"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*TimeDelta).microseconds), nil
+		},
+		Doc: "Number of microseconds (>= 0 and less than 1 second).",
+	}
+	TimeDeltaType.Dict["total_seconds"] = py.MustNewMethod("total_seconds", func(self py.Object) (py.Object, error) {
+		f, _ := new(big.Rat).SetFrac(self.(*TimeDelta).Microseconds(), big.NewInt(usPerSecond)).Float64()
+		return py.Float(f), nil
+	}, 0, "Total seconds in the duration.")
+	TimeDeltaType.Dict["min"] = &TimeDelta{object: newObject(TimeDeltaType), days: -maxDeltaDays}
+	TimeDeltaType.Dict["max"] = &TimeDelta{object: newObject(TimeDeltaType), days: maxDeltaDays, seconds: 86399, microseconds: 999999}
+	TimeDeltaType.Dict["resolution"] = NewTimeDelta(1)
+}
@@ -0,0 +1,221 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+var TzInfoType = py.ObjectType.NewTypeFlags("tzinfo", tzinfo_doc, TzInfoNew, nil, py.ObjectType.Flags|subclassFlags)
+
+const tzinfo_doc = `Abstract base class for time zone info objects.`
+
+var TimeZoneType = TzInfoType.NewTypeFlags("timezone", timezone_doc, nil, nil, py.ObjectType.Flags)
+
+const timezone_doc = `Fixed offset from UTC implementation of tzinfo.`
+
+// UTC is the UTC timezone
+var UTC = &TimeZone{object: newObject(TimeZoneType), offset: NewTimeDelta(0)}
+
+// TzInfo is the base class of time zones
+type TzInfo struct {
+	object
+}
+
+// TzInfoNew makes a tzinfo ignoring any arguments which are for the
+// subclass __init__
+func TzInfoNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &TzInfo{object: newObject(metatype)}, nil
+}
+
+// Type of this object
+func (tz *TzInfo) Type() *py.Type {
+	return tz.Base
+}
+
+// Implements tzinfo.fromutc in terms of utcoffset and dst
+func tzinfoFromUTC(self, dtObj py.Object) (py.Object, error) {
+	dt, ok := dtObj.(*DateTime)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "fromutc: argument must be a datetime")
+	}
+	if dt.tzinfo != self {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: dt.tzinfo is not self")
+	}
+	offset, err := tzOffset(self, "utcoffset", dt)
+	if err != nil {
+		return nil, err
+	}
+	if offset == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: non-None utcoffset() result required")
+	}
+	dst, err := tzOffset(self, "dst", dt)
+	if err != nil {
+		return nil, err
+	}
+	if dst == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: non-None dst() result required")
+	}
+	res, err := dt.addUs(offset.us() - dst.us())
+	if err != nil {
+		return nil, err
+	}
+	dst, err = tzOffset(self, "dst", res)
+	if err != nil {
+		return nil, err
+	}
+	if dst == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: tz.dst() gave inconsistent results; cannot convert")
+	}
+	return res.addUs(dst.us())
+}
+
+// TimeZone is a fixed offset from UTC
+type TimeZone struct {
+	object
+	offset *TimeDelta
+	name   py.Object // nil if not set
+}
+
+// NewTimeZone returns a timezone with the offset and optional name
+func NewTimeZone(offset *TimeDelta, name py.Object) (*TimeZone, error) {
+	if offset.days < -1 || offset.days > 0 || (offset.days == -1 && offset.seconds == 0 && offset.microseconds == 0) {
+		return nil, py.ExceptionNewf(py.ValueError, "offset must be a timedelta strictly between -timedelta(hours=24) and timedelta(hours=24), not %s.", mustRepr(offset))
+	}
+	if name == nil && offset.us() == 0 {
+		return UTC, nil
+	}
+	return &TimeZone{object: newObject(TimeZoneType), offset: offset, name: name}, nil
+}
+
+// TimeZoneNew makes a timezone from an offset and an optional name
+func TimeZoneNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var offsetObj, name py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:timezone", []string{"offset", "name"}, &offsetObj, &name)
+	if err != nil {
+		return nil, err
+	}
+	offset, ok := offsetObj.(*TimeDelta)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "timezone() argument 1 must be datetime.timedelta, not %s", offsetObj.Type().Name)
+	}
+	if name != nil {
+		if _, ok := name.(py.String); !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "timezone() argument 2 must be str, not %s", name.Type().Name)
+		}
+	}
+	return NewTimeZone(offset, name)
+}
+
+// Type of this object
+func (tz *TimeZone) Type() *py.Type {
+	return tz.Base
+}
+
+// Returns the name of the timezone, eg UTC+01:00 if it wasn't given
+func (tz *TimeZone) tzname() py.Object {
+	if tz.name != nil {
+		return tz.name
+	}
+	if tz.offset.us() == 0 {
+		return py.String("UTC")
+	}
+	return py.String("UTC" + formatOffset(tz.offset, ":"))
+}
+
+func (tz *TimeZone) M__repr__() (py.Object, error) {
+	if tz == UTC {
+		return py.String("datetime.timezone.utc"), nil
+	}
+	s := fmt.Sprintf("datetime.timezone(%s", mustRepr(tz.offset))
+	if tz.name != nil {
+		s += ", " + mustRepr(tz.name)
+	}
+	return py.String(s + ")"), nil
+}
+
+func (tz *TimeZone) M__str__() (py.Object, error) {
+	return tz.tzname(), nil
+}
+
+func (tz *TimeZone) M__hash__() (py.Object, error) {
+	return tz.offset.M__hash__()
+}
+
+func (tz *TimeZone) M__eq__(other py.Object) (py.Object, error) {
+	b, ok := other.(*TimeZone)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(tz.offset.us() == b.offset.us()), nil
+}
+
+func (tz *TimeZone) M__ne__(other py.Object) (py.Object, error) {
+	b, ok := other.(*TimeZone)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(tz.offset.us() != b.offset.us()), nil
+}
+
+// Checks the argument of the timezone methods is a datetime or None
+func checkDateTimeArg(method string, dt py.Object) error {
+	if _, ok := dt.(*DateTime); ok || dt == py.None {
+		return nil
+	}
+	return py.ExceptionNewf(py.TypeError, "%s(dt) argument must be a datetime instance or None, not %s", method, dt.Type().Name)
+}
+
+func init() {
+	// Initialised like this to avoid an initialisation loop through UTC
+	TimeZoneType.New = TimeZoneNew
+	addMethods(TzInfoType,
+		py.MustNewMethod("utcoffset", func(self, dt py.Object) (py.Object, error) {
+			return nil, py.ExceptionNewf(py.NotImplementedError, "a tzinfo subclass must implement utcoffset()")
+		}, 0, "datetime -> timedelta showing offset from UTC, negative values indicating West of UTC"),
+		py.MustNewMethod("dst", func(self, dt py.Object) (py.Object, error) {
+			return nil, py.ExceptionNewf(py.NotImplementedError, "a tzinfo subclass must implement dst()")
+		}, 0, "datetime -> DST offset as timedelta positive east of UTC."),
+		py.MustNewMethod("tzname", func(self, dt py.Object) (py.Object, error) {
+			return nil, py.ExceptionNewf(py.NotImplementedError, "a tzinfo subclass must implement tzname()")
+		}, 0, "datetime -> string name of time zone."),
+		py.MustNewMethod("fromutc", tzinfoFromUTC, 0, "datetime in UTC -> datetime in local time."),
+	)
+	addMethods(TimeZoneType,
+		py.MustNewMethod("utcoffset", func(self, dt py.Object) (py.Object, error) {
+			if err := checkDateTimeArg("utcoffset", dt); err != nil {
+				return nil, err
+			}
+			return self.(*TimeZone).offset, nil
+		}, 0, "Return fixed offset."),
+		py.MustNewMethod("dst", func(self, dt py.Object) (py.Object, error) {
+			if err := checkDateTimeArg("dst", dt); err != nil {
+				return nil, err
+			}
+			return py.None, nil
+		}, 0, "Return None."),
+		py.MustNewMethod("tzname", func(self, dt py.Object) (py.Object, error) {
+			if err := checkDateTimeArg("tzname", dt); err != nil {
+				return nil, err
+			}
+			return self.(*TimeZone).tzname(), nil
+		}, 0, "If name is specified when timezone is created, returns the name.  Otherwise returns offset as 'UTC(+|-)HH:MM'."),
+		py.MustNewMethod("fromutc", func(self, dtObj py.Object) (py.Object, error) {
+			dt, ok := dtObj.(*DateTime)
+			if !ok {
+				return nil, py.ExceptionNewf(py.TypeError, "fromutc: argument must be a datetime")
+			}
+			if dt.tzinfo != self {
+				return nil, py.ExceptionNewf(py.ValueError, "fromutc: dt.tzinfo is not self")
+			}
+			return dt.addUs(self.(*TimeZone).offset.us())
+		}, 0, "datetime in UTC -> datetime in local time."),
+	)
+	TimeZoneType.Dict["utc"] = UTC
+	TimeZoneType.Dict["min"] = &TimeZone{object: newObject(TimeZoneType), offset: NewTimeDelta(-(86400*usPerSecond - 60*usPerSecond))}
+	TimeZoneType.Dict["max"] = &TimeZone{object: newObject(TimeZoneType), offset: NewTimeDelta(86400*usPerSecond - 60*usPerSecond)}
+}
@@ -0,0 +1,1332 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Decimal arithmetic
+//
+// The algorithms follow those of the pure Python decimal module in
+// CPython (Lib/_pydecimal.py).
+
+package decimal
+
+import (
+	"math"
+	"math/big"
+	"strings"
+)
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+
+	// Small powers of 10 - never modify these
+	pow10Cache [64]*big.Int
+)
+
+func init() {
+	p := big.NewInt(1)
+	for i := range pow10Cache {
+		pow10Cache[i] = new(big.Int).Set(p)
+		p.Mul(p, bigTen)
+	}
+}
+
+// Returns 10**n which must not be modified
+func pow10(n int) *big.Int {
+	if n < len(pow10Cache) {
+		return pow10Cache[n]
+	}
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// Returns the number of decimal digits in x, counting 0 as 1 digit
+func numDigits(x *big.Int) int {
+	if x.Sign() == 0 {
+		return 1
+	}
+	n := int(float64(x.BitLen()-1)*math.Log10(2)) + 1
+	if new(big.Int).Abs(x).Cmp(pow10(n)) >= 0 {
+		n++
+	}
+	return n
+}
+
+// Returns a finite Decimal
+func newFinite(neg bool, coeff *big.Int, exp int) *Decimal {
+	return &Decimal{neg: neg, coeff: coeff, exp: exp}
+}
+
+// Returns an infinite Decimal
+func newInfinity(neg bool) *Decimal {
+	return &Decimal{neg: neg, form: infForm, coeff: new(big.Int)}
+}
+
+// Returns the Decimal 0 with the sign and exponent given
+func newZero(neg bool, exp int) *Decimal {
+	return newFinite(neg, new(big.Int), exp)
+}
+
+// Returns the Decimal 1 with the sign given
+func newOne(neg bool) *Decimal {
+	return newFinite(neg, big.NewInt(1), 0)
+}
+
+// Returns the coefficient rounded with the last drop digits removed
+// according to the rounding mode, and whether it was inexact
+func roundDigits(coeff *big.Int, drop int, neg bool, mode string) (*big.Int, bool) {
+	if drop <= 0 {
+		return coeff, false
+	}
+	if coeff.Sign() == 0 {
+		return new(big.Int), false
+	}
+	if drop > numDigits(coeff)+1 {
+		// All the digits are dropped and the result only
+		// depends on coeff being non zero and less than half
+		coeff, drop = bigOne, 1
+	}
+	div := pow10(drop)
+	q, r := new(big.Int).QuoRem(coeff, div, new(big.Int))
+	if r.Sign() == 0 {
+		return q, false
+	}
+	var up bool
+	switch mode {
+	case RoundDown:
+	case RoundUp:
+		up = true
+	case RoundCeiling:
+		up = !neg
+	case RoundFloor:
+		up = neg
+	case Round05Up:
+		last := new(big.Int).Rem(q, bigTen).Int64()
+		up = last == 0 || last == 5
+	default:
+		half := new(big.Int).Lsh(r, 1).Cmp(div)
+		switch mode {
+		case RoundHalfUp:
+			up = half >= 0
+		case RoundHalfDown:
+			up = half > 0
+		default:
+			up = half > 0 || (half == 0 && q.Bit(0) == 1)
+		}
+	}
+	if up {
+		q.Add(q, bigOne)
+	}
+	return q, true
+}
+
+// Returns the result of an overflow with the rounding mode given
+func (ctx *Context) overflow(neg bool, mode string) (*Decimal, error) {
+	if err := ctx.Signal(Inexact, "above Emax"); err != nil {
+		return nil, err
+	}
+	if err := ctx.Signal(Rounded, "above Emax"); err != nil {
+		return nil, err
+	}
+	var ans *Decimal
+	switch mode {
+	case RoundHalfUp, RoundHalfEven, RoundHalfDown, RoundUp:
+		ans = newInfinity(neg)
+	case RoundCeiling:
+		if neg {
+			ans = newFinite(neg, new(big.Int).Sub(pow10(ctx.Prec), bigOne), ctx.Etop())
+		} else {
+			ans = newInfinity(neg)
+		}
+	case RoundFloor:
+		if neg {
+			ans = newInfinity(neg)
+		} else {
+			ans = newFinite(neg, new(big.Int).Sub(pow10(ctx.Prec), bigOne), ctx.Etop())
+		}
+	default:
+		ans = newFinite(neg, new(big.Int).Sub(pow10(ctx.Prec), bigOne), ctx.Etop())
+	}
+	if err := ctx.Signal(Overflow, "above Emax"); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+// Rounds d to fit in the context, raising the appropriate signals
+func (ctx *Context) fix(d *Decimal) (*Decimal, error) {
+	return ctx.fixMode(d, ctx.Rounding)
+}
+
+// Rounds d to fit in the context with the rounding mode given
+func (ctx *Context) fixMode(d *Decimal, mode string) (*Decimal, error) {
+	if d.form != finiteForm {
+		return d, nil
+	}
+	etiny, etop := ctx.Etiny(), ctx.Etop()
+	if d.coeff.Sign() == 0 {
+		expMax := ctx.Emax
+		if ctx.Clamp == 1 {
+			expMax = etop
+		}
+		exp := d.exp
+		if exp < etiny {
+			exp = etiny
+		} else if exp > expMax {
+			exp = expMax
+		}
+		if exp != d.exp {
+			if err := ctx.Signal(Clamped, "exponent of a 0 changed to fit bounds"); err != nil {
+				return nil, err
+			}
+			return newZero(d.neg, exp), nil
+		}
+		return d, nil
+	}
+	expMin := numDigits(d.coeff) + d.exp - ctx.Prec
+	if expMin > etop {
+		return ctx.overflow(d.neg, mode)
+	}
+	subnormal := expMin < etiny
+	if subnormal {
+		expMin = etiny
+	}
+	if d.exp < expMin {
+		coeff, inexact := roundDigits(d.coeff, expMin-d.exp, d.neg, mode)
+		if numDigits(coeff) > ctx.Prec {
+			coeff.Quo(coeff, bigTen)
+			expMin++
+		}
+		if expMin > etop {
+			return ctx.overflow(d.neg, mode)
+		}
+		ans := newFinite(d.neg, coeff, expMin)
+		if inexact && subnormal {
+			if err := ctx.Signal(Underflow, "result underflows"); err != nil {
+				return nil, err
+			}
+		}
+		if subnormal {
+			if err := ctx.Signal(Subnormal, "result is subnormal"); err != nil {
+				return nil, err
+			}
+		}
+		if inexact {
+			if err := ctx.Signal(Inexact, "result is inexact"); err != nil {
+				return nil, err
+			}
+		}
+		if err := ctx.Signal(Rounded, "result is rounded"); err != nil {
+			return nil, err
+		}
+		if coeff.Sign() == 0 {
+			if err := ctx.Signal(Clamped, "result clamped to 0"); err != nil {
+				return nil, err
+			}
+		}
+		return ans, nil
+	}
+	if subnormal {
+		if err := ctx.Signal(Subnormal, "result is subnormal"); err != nil {
+			return nil, err
+		}
+	}
+	if ctx.Clamp == 1 && d.exp > etop {
+		if err := ctx.Signal(Clamped, "exponent reduced to fit bounds"); err != nil {
+			return nil, err
+		}
+		coeff := new(big.Int).Mul(d.coeff, pow10(d.exp-etop))
+		return newFinite(d.neg, coeff, etop), nil
+	}
+	return d, nil
+}
+
+// Fixes d which is known to be inexact with the rounding mode given
+func (ctx *Context) fixInexact(d *Decimal, mode string) (*Decimal, error) {
+	d, err := ctx.fixMode(d, mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Signal(Inexact, "result is inexact"); err != nil {
+		return nil, err
+	}
+	if err := ctx.Signal(Rounded, "result is rounded"); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Handles NaN operands returning nil if there aren't any
+func (ctx *Context) nans(operands ...*Decimal) (*Decimal, error) {
+	for _, d := range operands {
+		if d.form == snanForm {
+			if err := ctx.Signal(InvalidOperation, "sNaN"); err != nil {
+				return nil, err
+			}
+			return ctx.fixNaN(&Decimal{neg: d.neg, form: nanForm, coeff: d.coeff})
+		}
+	}
+	for _, d := range operands {
+		if d.form == nanForm {
+			return ctx.fixNaN(d)
+		}
+	}
+	return nil, nil
+}
+
+// Removes the diagnostic info from a NaN if it is too long
+func (ctx *Context) fixNaN(d *Decimal) (*Decimal, error) {
+	if d.coeff.Sign() != 0 && numDigits(d.coeff) > ctx.Prec-ctx.Clamp {
+		return &Decimal{neg: d.neg, form: d.form, coeff: new(big.Int)}, nil
+	}
+	return d, nil
+}
+
+// Returns d with its exponent changed to exp, rounding if necessary,
+// and whether the result was inexact
+func rescale(d *Decimal, exp int, mode string) (*Decimal, bool) {
+	if d.form != finiteForm {
+		return d, false
+	}
+	if d.coeff.Sign() == 0 {
+		return newZero(d.neg, exp), false
+	}
+	if d.exp >= exp {
+		return newFinite(d.neg, new(big.Int).Mul(d.coeff, pow10(d.exp-exp)), exp), false
+	}
+	coeff, inexact := roundDigits(d.coeff, exp-d.exp, d.neg, mode)
+	return newFinite(d.neg, coeff, exp), inexact
+}
+
+// Returns the two finite operands with the same exponent, reducing
+// the smaller one to a single sticky digit if it can't affect the
+// result at precision prec
+func align(a, b *Decimal, prec int) (*big.Int, *big.Int, int) {
+	swapped := false
+	if a.exp < b.exp {
+		a, b = b, a
+		swapped = true
+	}
+	bCoeff, bExp := b.coeff, b.exp
+	exp := a.exp + minInt(-1, numDigits(a.coeff)-prec-2)
+	if numDigits(bCoeff)+bExp-1 < exp {
+		bCoeff, bExp = bigOne, exp
+	}
+	aCoeff := new(big.Int).Mul(a.coeff, pow10(a.exp-bExp))
+	if swapped {
+		return bCoeff, aCoeff, bExp
+	}
+	return aCoeff, bCoeff, bExp
+}
+
+func (ctx *Context) add(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.form == infForm {
+		if b.form == infForm && a.neg != b.neg {
+			return ctx.invalid("-INF + INF")
+		}
+		return a, nil
+	}
+	if b.form == infForm {
+		return b, nil
+	}
+	exp := minInt(a.exp, b.exp)
+	floor := ctx.Rounding == RoundFloor && a.neg != b.neg
+	aZero, bZero := a.coeff.Sign() == 0, b.coeff.Sign() == 0
+	if aZero && bZero {
+		return ctx.fix(newZero((a.neg && b.neg) || floor, exp))
+	}
+	if aZero {
+		exp = maxInt(exp, b.exp-ctx.Prec-1)
+		ans, _ := rescale(b, exp, ctx.Rounding)
+		return ctx.fix(ans)
+	}
+	if bZero {
+		exp = maxInt(exp, a.exp-ctx.Prec-1)
+		ans, _ := rescale(a, exp, ctx.Rounding)
+		return ctx.fix(ans)
+	}
+	x, y, exp := align(a, b, ctx.Prec)
+	if a.neg == b.neg {
+		return ctx.fix(newFinite(a.neg, new(big.Int).Add(x, y), exp))
+	}
+	switch x.Cmp(y) {
+	case 0:
+		return ctx.fix(newZero(floor, exp))
+	case 1:
+		return ctx.fix(newFinite(a.neg, new(big.Int).Sub(x, y), exp))
+	}
+	return ctx.fix(newFinite(b.neg, new(big.Int).Sub(y, x), exp))
+}
+
+func (ctx *Context) sub(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	return ctx.add(a, b.copyNegate())
+}
+
+func (ctx *Context) mul(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	neg := a.neg != b.neg
+	if a.form == infForm || b.form == infForm {
+		if a.isZero() || b.isZero() {
+			return ctx.invalid("(+-)INF * 0")
+		}
+		return newInfinity(neg), nil
+	}
+	exp := a.exp + b.exp
+	if a.isZero() || b.isZero() {
+		return ctx.fix(newZero(neg, exp))
+	}
+	return ctx.fix(newFinite(neg, new(big.Int).Mul(a.coeff, b.coeff), exp))
+}
+
+func (ctx *Context) div(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	neg := a.neg != b.neg
+	if a.form == infForm {
+		if b.form == infForm {
+			return ctx.invalid("(+-)INF/(+-)INF")
+		}
+		return newInfinity(neg), nil
+	}
+	if b.form == infForm {
+		if err := ctx.Signal(Clamped, "Division by infinity"); err != nil {
+			return nil, err
+		}
+		return newZero(neg, ctx.Etiny()), nil
+	}
+	if b.isZero() {
+		if a.isZero() {
+			if err := ctx.Signal(InvalidOperation, "0 / 0"); err != nil {
+				return nil, err
+			}
+			return nan, nil
+		}
+		if err := ctx.Signal(DivisionByZero, "x / 0"); err != nil {
+			return nil, err
+		}
+		return newInfinity(neg), nil
+	}
+	var coeff *big.Int
+	var exp int
+	if a.isZero() {
+		exp = a.exp - b.exp
+		coeff = new(big.Int)
+	} else {
+		// Work out the shift to give prec+1 digits in the quotient
+		shift := numDigits(b.coeff) - numDigits(a.coeff) + ctx.Prec + 1
+		exp = a.exp - b.exp - shift
+		var q, r *big.Int
+		if shift >= 0 {
+			q, r = new(big.Int).QuoRem(new(big.Int).Mul(a.coeff, pow10(shift)), b.coeff, new(big.Int))
+		} else {
+			q, r = new(big.Int).QuoRem(a.coeff, new(big.Int).Mul(b.coeff, pow10(-shift)), new(big.Int))
+		}
+		if r.Sign() != 0 {
+			// Add a sticky digit so rounding is correct
+			if new(big.Int).Rem(q, big.NewInt(5)).Sign() == 0 {
+				q.Add(q, bigOne)
+			}
+		} else {
+			// The result is exact so strip zeros towards the
+			// ideal exponent
+			idealExp := a.exp - b.exp
+			for exp < idealExp {
+				qq, rr := new(big.Int).QuoRem(q, bigTen, new(big.Int))
+				if rr.Sign() != 0 {
+					break
+				}
+				q = qq
+				exp++
+			}
+		}
+		coeff = q
+	}
+	return ctx.fix(newFinite(neg, coeff, exp))
+}
+
+// Divides a by b returning the integer part of the quotient and the
+// remainder with the sign of a
+//
+// Both are finite and b is non zero
+func (ctx *Context) divide(a, b *Decimal) (*Decimal, *Decimal, error) {
+	neg := a.neg != b.neg
+	var expDiff int
+	if b.form == infForm {
+		expDiff = -1
+	} else {
+		expDiff = a.adjusted() - b.adjusted()
+	}
+	idealExp := a.exp
+	if b.form != infForm {
+		idealExp = minInt(a.exp, b.exp)
+	}
+	if a.isZero() || b.form == infForm || expDiff <= -2 {
+		ans, _ := rescale(a, idealExp, ctx.Rounding)
+		return newZero(neg, 0), ans, nil
+	}
+	if expDiff <= ctx.Prec {
+		x, y := a.coeff, b.coeff
+		if a.exp >= b.exp {
+			x = new(big.Int).Mul(x, pow10(a.exp-b.exp))
+		} else {
+			y = new(big.Int).Mul(y, pow10(b.exp-a.exp))
+		}
+		q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+		if q.Cmp(pow10(ctx.Prec)) < 0 {
+			return newFinite(neg, q, 0), newFinite(a.neg, r, idealExp), nil
+		}
+	}
+	// The quotient has too many digits
+	ans, err := ctx.invalid("quotient too large in //, %% or divmod")
+	return ans, ans, err
+}
+
+func (ctx *Context) divInt(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	neg := a.neg != b.neg
+	if a.form == infForm {
+		if b.form == infForm {
+			return ctx.invalid("INF // INF")
+		}
+		return newInfinity(neg), nil
+	}
+	if b.isZero() {
+		if a.isZero() {
+			return ctx.invalid("0 // 0")
+		}
+		if err := ctx.Signal(DivisionByZero, "x // 0"); err != nil {
+			return nil, err
+		}
+		return newInfinity(neg), nil
+	}
+	q, _, err := ctx.divide(a, b)
+	return q, err
+}
+
+func (ctx *Context) rem(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.form == infForm {
+		return ctx.invalid("INF %% x")
+	}
+	if b.isZero() {
+		if a.isZero() {
+			return ctx.invalid("0 %% 0")
+		}
+		return ctx.invalid("x %% 0")
+	}
+	_, r, err := ctx.divide(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.fix(r)
+}
+
+func (ctx *Context) divMod(a, b *Decimal) (*Decimal, *Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, ans, err
+	}
+	neg := a.neg != b.neg
+	if a.form == infForm {
+		if b.form == infForm {
+			ans, err := ctx.invalid("divmod(INF, INF)")
+			return ans, ans, err
+		}
+		ans, err := ctx.invalid("INF %% x")
+		return newInfinity(neg), ans, err
+	}
+	if b.isZero() {
+		if a.isZero() {
+			ans, err := ctx.invalid("divmod(0, 0)")
+			return ans, ans, err
+		}
+		if err := ctx.Signal(DivisionByZero, "x // 0"); err != nil {
+			return nil, nil, err
+		}
+		ans, err := ctx.invalid("x %% 0")
+		return newInfinity(neg), ans, err
+	}
+	q, r, err := ctx.divide(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err = ctx.fix(r)
+	return q, r, err
+}
+
+// Compares two non NaN Decimals returning -1, 0 or 1
+func compare(a, b *Decimal) int {
+	if a.form == infForm || b.form == infForm {
+		if a.form == b.form && a.neg == b.neg {
+			return 0
+		}
+		if a.form == infForm {
+			if a.neg {
+				return -1
+			}
+			return 1
+		}
+		if b.neg {
+			return 1
+		}
+		return -1
+	}
+	aZero, bZero := a.isZero(), b.isZero()
+	if aZero && bZero {
+		return 0
+	}
+	sign := 1
+	switch {
+	case aZero:
+		if b.neg {
+			return 1
+		}
+		return -1
+	case bZero:
+		if a.neg {
+			return -1
+		}
+		return 1
+	case a.neg != b.neg:
+		if a.neg {
+			return -1
+		}
+		return 1
+	case a.neg:
+		sign = -1
+	}
+	aAdj, bAdj := a.adjusted(), b.adjusted()
+	if aAdj != bAdj {
+		if aAdj < bAdj {
+			return -sign
+		}
+		return sign
+	}
+	x, y := a.coeff, b.coeff
+	if a.exp > b.exp {
+		x = new(big.Int).Mul(x, pow10(a.exp-b.exp))
+	} else if b.exp > a.exp {
+		y = new(big.Int).Mul(y, pow10(b.exp-a.exp))
+	}
+	return sign * x.Cmp(y)
+}
+
+func (ctx *Context) compare(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	switch compare(a, b) {
+	case -1:
+		return newOne(true), nil
+	case 1:
+		return newOne(false), nil
+	}
+	return newZero(false, 0), nil
+}
+
+// Compares a and b for max and min breaking ties using the total
+// ordering
+func compareTotal(a, b *Decimal) int {
+	c := compare(a, b)
+	if c != 0 {
+		return c
+	}
+	if a.neg != b.neg {
+		if a.neg {
+			return -1
+		}
+		return 1
+	}
+	if a.exp == b.exp {
+		return 0
+	}
+	if (a.exp > b.exp) != a.neg {
+		return 1
+	}
+	return -1
+}
+
+// Handles the NaNs for max and min where a quiet NaN loses to a number
+func (ctx *Context) maxMinNaNs(a, b *Decimal) (*Decimal, error) {
+	if a.form == snanForm || b.form == snanForm {
+		return ctx.nans(a, b)
+	}
+	aNaN, bNaN := a.form == nanForm, b.form == nanForm
+	switch {
+	case aNaN && bNaN:
+		return ctx.fixNaN(a)
+	case aNaN:
+		return ctx.fix(b)
+	case bNaN:
+		return ctx.fix(a)
+	}
+	return nil, nil
+}
+
+func (ctx *Context) max(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.maxMinNaNs(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	if compareTotal(a, b) < 0 {
+		return ctx.fix(b)
+	}
+	return ctx.fix(a)
+}
+
+func (ctx *Context) min(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.maxMinNaNs(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	if compareTotal(a, b) < 0 {
+		return ctx.fix(a)
+	}
+	return ctx.fix(b)
+}
+
+func (ctx *Context) plus(a *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.isZero() && ctx.Rounding != RoundFloor {
+		a = a.copyAbs()
+	}
+	return ctx.fix(a)
+}
+
+func (ctx *Context) minus(a *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.isZero() && ctx.Rounding != RoundFloor {
+		a = a.copyAbs()
+	} else {
+		a = a.copyNegate()
+	}
+	return ctx.fix(a)
+}
+
+func (ctx *Context) abs(a *Decimal) (*Decimal, error) {
+	if a.neg {
+		return ctx.minus(a)
+	}
+	return ctx.plus(a)
+}
+
+func (ctx *Context) normalize(a *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	d, err := ctx.fix(a)
+	if err != nil || d.form == infForm {
+		return d, err
+	}
+	if d.isZero() {
+		return newZero(d.neg, 0), nil
+	}
+	expMax := ctx.Emax
+	if ctx.Clamp == 1 {
+		expMax = ctx.Etop()
+	}
+	coeff, exp := d.coeff, d.exp
+	for exp < expMax {
+		q, r := new(big.Int).QuoRem(coeff, bigTen, new(big.Int))
+		if r.Sign() != 0 {
+			break
+		}
+		coeff = q
+		exp++
+	}
+	return newFinite(d.neg, coeff, exp), nil
+}
+
+// Rounds a to an integer with the rounding mode given, signalling
+// Inexact and Rounded if exact is set
+func (ctx *Context) toIntegral(a *Decimal, mode string, exact bool) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.form == infForm || a.exp >= 0 {
+		return a, nil
+	}
+	if a.isZero() {
+		return newZero(a.neg, 0), nil
+	}
+	ans, inexact := rescale(a, 0, mode)
+	if exact {
+		if inexact {
+			if err := ctx.Signal(Inexact, "result is inexact"); err != nil {
+				return nil, err
+			}
+		}
+		if err := ctx.Signal(Rounded, "result is rounded"); err != nil {
+			return nil, err
+		}
+	}
+	return ans, nil
+}
+
+func (ctx *Context) toIntegralValue(a *Decimal) (*Decimal, error) {
+	return ctx.toIntegral(a, ctx.Rounding, false)
+}
+
+func (ctx *Context) toIntegralExact(a *Decimal) (*Decimal, error) {
+	return ctx.toIntegral(a, ctx.Rounding, true)
+}
+
+// Rounds a to have the same exponent as b
+func (ctx *Context) quantize(a, b *Decimal, mode string) (*Decimal, error) {
+	if a.form != finiteForm || b.form != finiteForm {
+		if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+			return ans, err
+		}
+		if a.form == infForm && b.form == infForm {
+			return a, nil
+		}
+		return ctx.invalid("quantize with one INF")
+	}
+	if b.exp < ctx.Etiny() || b.exp > ctx.Emax {
+		return ctx.invalid("target exponent out of bounds in quantize")
+	}
+	if a.isZero() {
+		return ctx.fix(newZero(a.neg, b.exp))
+	}
+	adj := a.adjusted()
+	if adj > ctx.Emax {
+		return ctx.invalid("exponent of quantize result too large for current context")
+	}
+	if adj-b.exp+1 > ctx.Prec {
+		return ctx.invalid("quantize result has too many digits for current context")
+	}
+	ans, inexact := rescale(a, b.exp, mode)
+	if ans.adjusted() > ctx.Emax {
+		return ctx.invalid("exponent of quantize result too large for current context")
+	}
+	if numDigits(ans.coeff) > ctx.Prec {
+		return ctx.invalid("quantize result has too many digits for current context")
+	}
+	if !ans.isZero() && ans.adjusted() < ctx.Emin {
+		if err := ctx.Signal(Subnormal, "result is subnormal"); err != nil {
+			return nil, err
+		}
+	}
+	if ans.exp > a.exp {
+		if inexact {
+			if err := ctx.Signal(Inexact, "result is inexact"); err != nil {
+				return nil, err
+			}
+		}
+		if err := ctx.Signal(Rounded, "result is rounded"); err != nil {
+			return nil, err
+		}
+	}
+	return ctx.fix(ans)
+}
+
+func (ctx *Context) quantizeContext(a, b *Decimal) (*Decimal, error) {
+	return ctx.quantize(a, b, ctx.Rounding)
+}
+
+func (ctx *Context) sqrt(a *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.isZero() {
+		return ctx.fix(newZero(a.neg, a.exp>>1))
+	}
+	if a.neg {
+		return ctx.invalid("sqrt(-x), x > 0")
+	}
+	if a.form == infForm {
+		return a, nil
+	}
+
+	// Work with an even exponent and prec+1 digits so the result can
+	// be rounded correctly
+	prec := ctx.Prec + 1
+	e := a.exp >> 1
+	c := a.coeff
+	l := numDigits(c)
+	if a.exp&1 != 0 {
+		c = new(big.Int).Mul(c, bigTen)
+		l = (l >> 1) + 1
+	} else {
+		l = (l + 1) >> 1
+	}
+	shift := prec - l
+	exact := true
+	if shift >= 0 {
+		c = new(big.Int).Mul(c, pow10(2*shift))
+	} else {
+		var r *big.Int
+		c, r = new(big.Int).QuoRem(c, pow10(-2*shift), new(big.Int))
+		exact = r.Sign() == 0
+	}
+	e -= shift
+	n := new(big.Int).Sqrt(c)
+	if exact && new(big.Int).Mul(n, n).Cmp(c) == 0 {
+		// The result is exact so use the ideal exponent
+		if shift >= 0 {
+			n.Quo(n, pow10(shift))
+		} else {
+			n.Mul(n, pow10(-shift))
+		}
+		e += shift
+	} else if new(big.Int).Rem(n, big.NewInt(5)).Sign() == 0 {
+		// Add a sticky digit so rounding is correct
+		n.Add(n, bigOne)
+	}
+	return ctx.fixMode(newFinite(false, n, e), RoundHalfEven)
+}
+
+// Returns whether a finite Decimal is an integer and if so its value
+func (d *Decimal) integer() (*big.Int, bool) {
+	if d.form != finiteForm {
+		return nil, false
+	}
+	if d.exp >= 0 {
+		if d.coeff.Sign() != 0 && d.exp > 1<<20 {
+			return nil, false
+		}
+		n := new(big.Int).Mul(d.coeff, pow10(d.exp))
+		if d.neg {
+			n.Neg(n)
+		}
+		return n, true
+	}
+	if d.isZero() {
+		return new(big.Int), true
+	}
+	if -d.exp > numDigits(d.coeff) {
+		return nil, false
+	}
+	q, r := new(big.Int).QuoRem(d.coeff, pow10(-d.exp), new(big.Int))
+	if r.Sign() != 0 {
+		return nil, false
+	}
+	if d.neg {
+		q.Neg(q)
+	}
+	return q, true
+}
+
+// Returns whether d is a finite integer, which may be too large to
+// compute
+func (d *Decimal) isInteger() bool {
+	if d.form != finiteForm {
+		return false
+	}
+	if d.exp >= 0 || d.isZero() {
+		return true
+	}
+	_, ok := d.integer()
+	return ok
+}
+
+// Returns whether the integer d is odd
+func (d *Decimal) isOdd() bool {
+	if d.exp > 0 {
+		return false
+	}
+	n, ok := d.integer()
+	return ok && n.Bit(0) == 1
+}
+
+// A context with the precision given for intermediate results
+func workContext(prec int) *Context {
+	return &Context{
+		Prec:     prec,
+		Rounding: RoundHalfEven,
+		Emin:     MinEmin,
+		Emax:     MaxEmax,
+		Flags:    newSignalDict(),
+		Traps:    newSignalDict(),
+	}
+}
+
+func (ctx *Context) pow(a, b *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b); ans != nil || err != nil {
+		return ans, err
+	}
+	if b.isZero() {
+		if a.isZero() {
+			return ctx.invalid("0 ** 0")
+		}
+		return newOne(false), nil
+	}
+	neg := false
+	if a.neg {
+		if b.isInteger() {
+			neg = b.isOdd()
+		} else if !a.isZero() {
+			return ctx.invalid("x ** y with x negative and y not an integer")
+		}
+		a = a.copyAbs()
+	}
+	if a.isZero() {
+		if b.neg {
+			return newInfinity(neg), nil
+		}
+		return newZero(neg, 0), nil
+	}
+	if a.form == infForm {
+		if b.neg {
+			return newZero(neg, 0), nil
+		}
+		return newInfinity(neg), nil
+	}
+	one := compare(a, newOne(false))
+	if b.form == infForm {
+		switch {
+		case one == 0:
+			return ctx.fixInexact(newFinite(neg, pow10(ctx.Prec-1), 1-ctx.Prec), ctx.Rounding)
+		case (one > 0) != b.neg:
+			return newInfinity(neg), nil
+		}
+		return newZero(neg, 0), nil
+	}
+
+	// Estimate log10 of the result to catch overflow and underflow
+	la := float64(a.adjusted()) + math.Log10(leading(a))
+	if one == 0 {
+		la = 0
+	}
+	lb := float64(b.adjusted()) + math.Log10(leading(b))
+	est := 0.0
+	if la != 0 {
+		est = math.Copysign(math.Pow(10, lb+math.Log10(math.Abs(la))), la)
+		if b.neg {
+			est = -est
+		}
+	}
+	if est > float64(ctx.Emax)+1 {
+		return ctx.fixInexact(newFinite(neg, bigOne, ctx.Emax+1), ctx.Rounding)
+	}
+	if est < float64(ctx.Etiny())-2 {
+		return ctx.fixInexact(newFinite(neg, bigOne, ctx.Etiny()-2), ctx.Rounding)
+	}
+
+	if n, ok := b.integer(); ok {
+		absN := new(big.Int).Abs(n)
+		// Calculate exactly if the result isn't too long
+		if absN.IsInt64() && absN.Int64()*int64(numDigits(a.coeff)) <= int64(4*ctx.Prec+100) {
+			k := int(absN.Int64())
+			coeff := new(big.Int).Exp(a.coeff, absN, nil)
+			if n.Sign() < 0 {
+				return ctx.div(newOne(neg), newFinite(false, coeff, a.exp*k))
+			}
+			return ctx.fix(newFinite(neg, coeff, a.exp*k))
+		}
+		// Otherwise square and multiply with some guard digits
+		wctx := workContext(ctx.Prec + numDigits(absN) + 5)
+		result := newOne(false)
+		x := a
+		var err error
+		for i := absN.BitLen() - 1; i >= 0; i-- {
+			result, err = wctx.mul(result, result)
+			if err != nil {
+				return nil, err
+			}
+			if absN.Bit(i) == 1 {
+				result, err = wctx.mul(result, x)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if n.Sign() < 0 {
+			result, err = wctx.div(newOne(false), result)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.neg = neg
+		return ctx.fixInexact(result, ctx.Rounding)
+	}
+
+	// a**b = exp(b*ln(a))
+	bits := floatBits(ctx.Prec)
+	t := new(big.Float).SetPrec(bits).Mul(lnFloat(a, bits), toFloat(b, bits))
+	return ctx.fixInexact(fromFloat(expFloat(t, bits), neg, ctx.Prec), ctx.Rounding)
+}
+
+// Returns the leading digits of a finite non zero Decimal as a
+// float64 in [1, 10)
+func leading(d *Decimal) float64 {
+	c := d.coeff
+	n := numDigits(c)
+	if n > 17 {
+		c = new(big.Int).Quo(c, pow10(n-17))
+		n = 17
+	}
+	f, _ := new(big.Float).SetInt(c).Float64()
+	return f / math.Pow(10, float64(n-1))
+}
+
+// Computes a**b % modulo with all three integers
+func (ctx *Context) powMod(a, b, modulo *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a, b, modulo); ans != nil || err != nil {
+		return ans, err
+	}
+	x, aOK := a.integer()
+	y, bOK := b.integer()
+	m, mOK := modulo.integer()
+	if !aOK || !bOK || !mOK {
+		return ctx.invalid("pow() 3rd argument not allowed unless all arguments are integers")
+	}
+	if y.Sign() < 0 {
+		return ctx.invalid("pow() 2nd argument cannot be negative when 3rd argument specified")
+	}
+	if m.Sign() == 0 {
+		return ctx.invalid("pow() 3rd argument cannot be 0")
+	}
+	if numDigits(m) > ctx.Prec {
+		return ctx.invalid("insufficient precision: pow() 3rd argument must not have more than precision digits")
+	}
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return ctx.invalid("at least one of pow() 1st argument and 2nd argument must be nonzero; 0th power is undefined")
+	}
+	neg := x.Sign() < 0 && y.Bit(0) == 1
+	absM := new(big.Int).Abs(m)
+	r := new(big.Int).Exp(new(big.Int).Abs(x), y, absM)
+	return newFinite(neg, r, 0), nil
+}
+
+// Returns the number of bits of precision to use for prec digits
+func floatBits(prec int) uint {
+	return uint(float64(prec+10)*math.Log2(10)) + 64
+}
+
+// Converts a finite Decimal to a big.Float
+func toFloat(d *Decimal, bits uint) *big.Float {
+	f := new(big.Float).SetPrec(bits).SetInt(d.coeff)
+	if d.exp != 0 {
+		p := powTenFloat(abs(d.exp), bits)
+		if d.exp > 0 {
+			f.Mul(f, p)
+		} else {
+			f.Quo(f, p)
+		}
+	}
+	if d.neg {
+		f.Neg(f)
+	}
+	return f
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Returns 10**n as a big.Float
+func powTenFloat(n int, bits uint) *big.Float {
+	if n < 10000 {
+		return new(big.Float).SetPrec(bits).SetInt(pow10(n))
+	}
+	result := new(big.Float).SetPrec(bits).SetInt64(1)
+	x := new(big.Float).SetPrec(bits).SetInt64(10)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, x)
+		}
+		x.Mul(x, x)
+	}
+	return result
+}
+
+// Returns the Decimal closest to f with prec digits plus some guard
+// digits
+func fromFloat(f *big.Float, neg bool, prec int) *Decimal {
+	s := f.Text('e', prec+10)
+	d, ok := parseDecimal(s)
+	if !ok {
+		panic("decimal: bad float conversion " + s)
+	}
+	d.neg = neg != d.neg
+	return d
+}
+
+// Returns atanh(z) for small z
+func atanhFloat(z *big.Float, bits uint) *big.Float {
+	sum := new(big.Float).SetPrec(bits).Set(z)
+	z2 := new(big.Float).SetPrec(bits).Mul(z, z)
+	power := new(big.Float).SetPrec(bits).Set(z)
+	term := new(big.Float).SetPrec(bits)
+	for i := int64(3); ; i += 2 {
+		power.Mul(power, z2)
+		term.Quo(power, new(big.Float).SetInt64(i))
+		if term.Sign() == 0 || term.MantExp(nil) < sum.MantExp(nil)-int(bits)-2 {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	return sum
+}
+
+// Returns ln(x) for x > 0
+func lnBigFloat(x *big.Float, bits uint) *big.Float {
+	one := new(big.Float).SetPrec(bits).SetInt64(1)
+	two := new(big.Float).SetPrec(bits).SetInt64(2)
+	lnAtanh := func(m *big.Float) *big.Float {
+		// ln(m) = 2 atanh((m-1)/(m+1))
+		z := new(big.Float).SetPrec(bits).Sub(m, one)
+		z.Quo(z, new(big.Float).SetPrec(bits).Add(m, one))
+		r := atanhFloat(z, bits)
+		return r.Mul(r, two)
+	}
+	if x.Cmp(new(big.Float).SetFloat64(0.5)) >= 0 && x.Cmp(two) < 0 {
+		return lnAtanh(x)
+	}
+	m := new(big.Float).SetPrec(bits)
+	e := x.MantExp(m)
+	r := lnAtanh(m)
+	ln2 := atanhFloat(new(big.Float).SetPrec(bits).Quo(one, new(big.Float).SetInt64(3)), bits)
+	ln2.Mul(ln2, two)
+	ln2.Mul(ln2, new(big.Float).SetInt64(int64(e)))
+	return r.Add(r, ln2)
+}
+
+// Returns ln(d) for finite d > 0
+func lnFloat(d *Decimal, bits uint) *big.Float {
+	adj := d.adjusted()
+	if adj > -1000 && adj < 1000 {
+		return lnBigFloat(toFloat(d, bits), bits)
+	}
+	// Far from 1 so split off the exponent
+	r := lnBigFloat(new(big.Float).SetPrec(bits).SetInt(d.coeff), bits)
+	ln10 := lnBigFloat(new(big.Float).SetPrec(bits).SetInt64(10), bits)
+	ln10.Mul(ln10, new(big.Float).SetInt64(int64(d.exp)))
+	return r.Add(r, ln10)
+}
+
+// Returns exp(x)
+func expFloat(x *big.Float, bits uint) *big.Float {
+	k := 0
+	if x.Sign() != 0 {
+		k = maxInt(0, x.MantExp(nil)+10)
+	}
+	bits += uint(k) + 10
+	r := new(big.Float).SetPrec(bits).SetMantExp(x, -k)
+	sum := new(big.Float).SetPrec(bits).SetInt64(1)
+	term := new(big.Float).SetPrec(bits).SetInt64(1)
+	for i := int64(1); ; i++ {
+		term.Mul(term, r)
+		term.Quo(term, new(big.Float).SetInt64(i))
+		if term.Sign() == 0 || term.MantExp(nil) < -int(bits) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	for ; k > 0; k-- {
+		sum.Mul(sum, sum)
+	}
+	return sum
+}
+
+// Returns log10 of the size of a finite non zero Decimal approximately
+func approxLog10(d *Decimal) float64 {
+	return float64(d.adjusted()) + math.Log10(leading(d))
+}
+
+func (ctx *Context) exp(a *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.form == infForm {
+		if a.neg {
+			return newZero(false, 0), nil
+		}
+		return a, nil
+	}
+	if a.isZero() {
+		return newOne(false), nil
+	}
+	// exp(a) overflows or underflows if |a| is too big
+	l := approxLog10(a)
+	if l > 20 {
+		if a.neg {
+			return ctx.fixInexact(newFinite(false, bigOne, ctx.Etiny()-2), RoundHalfEven)
+		}
+		return ctx.fixInexact(newFinite(false, bigOne, ctx.Emax+1), RoundHalfEven)
+	}
+	limit := math.Log(10) * (float64(ctx.Emax) + 2)
+	if f := math.Pow(10, l); !a.neg && f > limit {
+		return ctx.fixInexact(newFinite(false, bigOne, ctx.Emax+1), RoundHalfEven)
+	} else if a.neg && f > math.Log(10)*(2-float64(ctx.Etiny())) {
+		return ctx.fixInexact(newFinite(false, bigOne, ctx.Etiny()-2), RoundHalfEven)
+	}
+	bits := floatBits(ctx.Prec)
+	return ctx.fixInexact(fromFloat(expFloat(toFloat(a, bits), bits), false, ctx.Prec), RoundHalfEven)
+}
+
+func (ctx *Context) ln(a *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.isZero() {
+		return newInfinity(true), nil
+	}
+	if a.neg {
+		return ctx.invalid("ln of a negative value")
+	}
+	if a.form == infForm {
+		return a, nil
+	}
+	if compare(a, newOne(false)) == 0 {
+		return newZero(false, 0), nil
+	}
+	bits := floatBits(ctx.Prec)
+	return ctx.fixInexact(fromFloat(lnFloat(a, bits), false, ctx.Prec), RoundHalfEven)
+}
+
+func (ctx *Context) log10(a *Decimal) (*Decimal, error) {
+	if ans, err := ctx.nans(a); ans != nil || err != nil {
+		return ans, err
+	}
+	if a.isZero() {
+		return newInfinity(true), nil
+	}
+	if a.neg {
+		return ctx.invalid("log10 of a negative value")
+	}
+	if a.form == infForm {
+		return a, nil
+	}
+	// Exact powers of 10 have exact results
+	if s := a.coeff.String(); s[0] == '1' && strings.Trim(s[1:], "0") == "" {
+		n := big.NewInt(int64(a.adjusted()))
+		neg := n.Sign() < 0
+		return ctx.fix(newFinite(neg, n.Abs(n), 0))
+	}
+	bits := floatBits(ctx.Prec)
+	r := lnFloat(a, bits)
+	r.Quo(r, lnBigFloat(new(big.Float).SetPrec(bits).SetInt64(10), bits))
+	return ctx.fixInexact(fromFloat(r, false, ctx.Prec), RoundHalfEven)
+}
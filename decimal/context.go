@@ -0,0 +1,621 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Decimal contexts and signals
+
+package decimal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-python/gpython/contextvars"
+	"github.com/go-python/gpython/py"
+)
+
+// Rounding modes
+const (
+	RoundDown     = "ROUND_DOWN"
+	RoundHalfUp   = "ROUND_HALF_UP"
+	RoundHalfEven = "ROUND_HALF_EVEN"
+	RoundCeiling  = "ROUND_CEILING"
+	RoundFloor    = "ROUND_FLOOR"
+	RoundUp       = "ROUND_UP"
+	RoundHalfDown = "ROUND_HALF_DOWN"
+	Round05Up     = "ROUND_05UP"
+)
+
+var roundingModes = []string{RoundDown, RoundHalfUp, RoundHalfEven, RoundCeiling, RoundFloor, RoundUp, RoundHalfDown, Round05Up}
+
+// Limits of the context parameters
+const (
+	MaxPrec  = 999999999999999999
+	MaxEmax  = 999999999999999999
+	MinEmin  = -999999999999999999
+	MinEtiny = MinEmin - (MaxPrec - 1)
+)
+
+// Signals
+//
+// These are raised as exceptions when they are trapped by the
+// context, otherwise they just set a flag in the context.
+var (
+	DecimalException = py.ArithmeticError.NewType("DecimalException", "", nil, nil)
+	Clamped          = DecimalException.NewType("Clamped", "Exponent of a 0 changed to fit bounds.", nil, nil)
+	InvalidOperation = DecimalException.NewType("InvalidOperation", "An invalid operation was performed.", nil, nil)
+	DivisionByZero   = DecimalException.NewType("DivisionByZero", "Division by 0.", nil, nil)
+	Inexact          = DecimalException.NewType("Inexact", "Had to round, losing information.", nil, nil)
+	Rounded          = DecimalException.NewType("Rounded", "Number got rounded (not  necessarily changed during rounding).", nil, nil)
+	Subnormal        = DecimalException.NewType("Subnormal", "Exponent < Emin before rounding.", nil, nil)
+	Overflow         = Inexact.NewType("Overflow", "Numerical overflow.", nil, nil)
+	Underflow        = Inexact.NewType("Underflow", "Numerical underflow with result rounded to 0.", nil, nil)
+	FloatOperation   = DecimalException.NewType("FloatOperation", "Enable stricter semantics for mixing floats and Decimals.", nil, nil)
+)
+
+// The signals in the order they are shown in a Context repr
+var signals = []*py.Type{InvalidOperation, FloatOperation, DivisionByZero, Overflow, Underflow, Subnormal, Inexact, Rounded, Clamped}
+
+func init() {
+	for t, bases := range map[*py.Type]py.Tuple{
+		DivisionByZero: {DecimalException, py.ZeroDivisionError},
+		Overflow:       {Inexact, Rounded},
+		Underflow:      {Inexact, Rounded, Subnormal},
+		FloatOperation: {DecimalException, py.TypeError},
+	} {
+		if err := t.SetBases(bases); err != nil {
+			panic(err)
+		}
+	}
+}
+
+var ContextType = py.NewTypeX("Context", `Context(prec=None, rounding=None, Emin=None, Emax=None, capitals=None, clamp=None, flags=None, traps=None)
+
+The context affects almost all operations and controls rounding,
+Over/Underflow, raising of exceptions and much more.  A new context
+can be constructed as follows:
+
+    >>> c = Context(prec=28, Emin=-425000000, Emax=425000000,
+    ...             rounding=ROUND_HALF_EVEN, capitals=1, clamp=1,
+    ...             traps=[InvalidOperation, DivisionByZero, Overflow],
+    ...             flags=[])
+    >>>`, ContextNew, nil)
+
+// A Context holds the precision, rounding and signal handling used
+// by Decimal arithmetic
+type Context struct {
+	Prec     int
+	Rounding string
+	Emin     int
+	Emax     int
+	Capitals int
+	Clamp    int
+	// Flags and Traps map the signals to True or False
+	Flags *py.Dict
+	Traps *py.Dict
+}
+
+// Type of this Context object
+func (ctx *Context) Type() *py.Type {
+	return ContextType
+}
+
+// Makes a signal dict with the signals in set True and the others False
+func newSignalDict(set ...*py.Type) *py.Dict {
+	d := py.NewDict()
+	for _, sig := range signals {
+		_ = d.Set(sig, py.False)
+	}
+	for _, sig := range set {
+		_ = d.Set(sig, py.True)
+	}
+	return d
+}
+
+// NewContext makes a Context with the default parameters
+func NewContext() *Context {
+	return &Context{
+		Prec:     28,
+		Rounding: RoundHalfEven,
+		Emin:     -999999,
+		Emax:     999999,
+		Capitals: 1,
+		Flags:    newSignalDict(),
+		Traps:    newSignalDict(InvalidOperation, DivisionByZero, Overflow),
+	}
+}
+
+// Copy returns a copy of the Context with its own flags and traps
+func (ctx *Context) Copy() *Context {
+	newCtx := *ctx
+	newCtx.Flags = ctx.Flags.Copy()
+	newCtx.Traps = ctx.Traps.Copy()
+	return &newCtx
+}
+
+// Etiny is the smallest exponent of a subnormal number
+func (ctx *Context) Etiny() int {
+	return ctx.Emin - ctx.Prec + 1
+}
+
+// Etop is the largest exponent of a number with full precision
+func (ctx *Context) Etop() int {
+	return ctx.Emax - ctx.Prec + 1
+}
+
+// Returns whether the signal is set in the signal dict
+func isSet(d *py.Dict, sig *py.Type) bool {
+	v, ok, _ := d.Get(sig)
+	return ok && py.ObjectIsTrue(v)
+}
+
+// Signal sets the flag for sig and returns an exception if it is
+// trapped
+func (ctx *Context) Signal(sig *py.Type, format string, args ...interface{}) error {
+	_ = ctx.Flags.Set(sig, py.True)
+	if isSet(ctx.Traps, sig) {
+		return py.ExceptionNewf(sig, format, args...)
+	}
+	return nil
+}
+
+// Signals an InvalidOperation returning a NaN if it isn't trapped
+func (ctx *Context) invalid(format string, args ...interface{}) (*Decimal, error) {
+	err := ctx.Signal(InvalidOperation, format, args...)
+	if err != nil {
+		return nil, err
+	}
+	return nan, nil
+}
+
+// Parses a signal dict argument which may be a dict or a list of the
+// signals to set
+func signalDictArg(obj py.Object, name string) (*py.Dict, error) {
+	d := newSignalDict()
+	if dict, ok := obj.(*py.Dict); ok {
+		for _, item := range dict.Items() {
+			sig, ok := item[0].(*py.Type)
+			if !ok || !isSignal(sig) {
+				return nil, py.ExceptionNewf(py.KeyError, "invalid signal dict")
+			}
+			_ = d.Set(sig, py.NewBool(py.ObjectIsTrue(item[1])))
+		}
+		return d, nil
+	}
+	var badSignal py.Object
+	err := py.Iterate(obj, func(item py.Object) bool {
+		sig, ok := item.(*py.Type)
+		if !ok || !isSignal(sig) {
+			badSignal = item
+			return true
+		}
+		_ = d.Set(sig, py.True)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if badSignal != nil {
+		return nil, py.ExceptionNewf(py.KeyError, "%s must be a list of signals or a signal dict", name)
+	}
+	return d, nil
+}
+
+// Returns whether sig is one of the signals
+func isSignal(sig *py.Type) bool {
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// Sets an integer parameter checking it is in range
+func intParam(value py.Object, name string, min, max int) (int, error) {
+	i, ok := value.(py.Int)
+	if !ok {
+		return 0, py.ExceptionNewf(py.TypeError, "%s must be an integer", name)
+	}
+	if int(i) < min || int(i) > max {
+		return 0, py.ExceptionNewf(py.ValueError, "valid range for %s is [%d, %d]", name, min, max)
+	}
+	return int(i), nil
+}
+
+// Sets the attribute called name of the Context
+func (ctx *Context) setParam(name string, value py.Object) error {
+	switch name {
+	case "rounding":
+		if s, ok := value.(py.String); ok {
+			for _, mode := range roundingModes {
+				if string(s) == mode {
+					ctx.Rounding = mode
+					return nil
+				}
+			}
+		}
+		return py.ExceptionNewf(py.TypeError, "valid values for rounding are:\n  [ROUND_CEILING, ROUND_FLOOR, ROUND_UP, ROUND_DOWN,\n   ROUND_HALF_UP, ROUND_HALF_DOWN, ROUND_HALF_EVEN,\n   ROUND_05UP]")
+	case "flags", "traps":
+		d, err := signalDictArg(value, name)
+		if err != nil {
+			return err
+		}
+		if name == "flags" {
+			ctx.Flags = d
+		} else {
+			ctx.Traps = d
+		}
+		return nil
+	}
+	var param *int
+	min, max := 0, 1
+	switch name {
+	case "prec":
+		param, min, max = &ctx.Prec, 1, MaxPrec
+	case "Emin":
+		param, min, max = &ctx.Emin, MinEmin, 0
+	case "Emax":
+		param, min, max = &ctx.Emax, 0, MaxEmax
+	case "capitals":
+		param = &ctx.Capitals
+	case "clamp":
+		param = &ctx.Clamp
+	}
+	i, err := intParam(value, name, min, max)
+	if err != nil {
+		return err
+	}
+	*param = i
+	return nil
+}
+
+// The parameters of a Context in constructor order
+var contextParams = []string{"prec", "rounding", "Emin", "Emax", "capitals", "clamp", "flags", "traps"}
+
+// ContextNew makes a new Context from the default context with the
+// parameters given overridden
+func ContextNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	values := make([]py.Object, len(contextParams))
+	results := make([]*py.Object, len(contextParams))
+	for i := range values {
+		results[i] = &values[i]
+	}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOOOO:Context", contextParams, results...)
+	if err != nil {
+		return nil, err
+	}
+	ctx := DefaultContext.Copy()
+	ctx.Flags = newSignalDict()
+	for i, value := range values {
+		if value == nil || value == py.None {
+			continue
+		}
+		err = ctx.setParam(contextParams[i], value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// Returns the names of the signals set in the signal dict
+func signalNames(d *py.Dict) string {
+	var names []string
+	for _, sig := range signals {
+		if isSet(d, sig) {
+			names = append(names, sig.Name)
+		}
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+func (ctx *Context) M__repr__() (py.Object, error) {
+	return py.String("Context(prec=" + strconv.Itoa(ctx.Prec) +
+		", rounding=" + ctx.Rounding +
+		", Emin=" + strconv.Itoa(ctx.Emin) +
+		", Emax=" + strconv.Itoa(ctx.Emax) +
+		", capitals=" + strconv.Itoa(ctx.Capitals) +
+		", clamp=" + strconv.Itoa(ctx.Clamp) +
+		", flags=" + signalNames(ctx.Flags) +
+		", traps=" + signalNames(ctx.Traps) + ")"), nil
+}
+
+// The current context is kept in a context variable, as in CPython,
+// so it is local to a contextvars.Context
+var currentContext = &contextvars.ContextVar{Name: "decimal_context"}
+
+// GetContext returns the current context, making it from
+// DefaultContext if there isn't one yet
+func GetContext() *Context {
+	if ctx, err := currentContext.Get(nil); err == nil {
+		return ctx.(*Context)
+	}
+	ctx := DefaultContext.Copy()
+	SetContext(ctx)
+	return ctx
+}
+
+// SetContext sets the current context
+//
+// The standard contexts are copied first so they are never altered.
+func SetContext(ctx *Context) {
+	if ctx == DefaultContext || ctx == BasicContext || ctx == ExtendedContext {
+		ctx = ctx.Copy()
+		ctx.Flags = newSignalDict()
+	}
+	currentContext.Set(ctx)
+}
+
+// Returns the context argument passed in, or the current context if
+// it is nil or None
+func contextArg(obj py.Object) (*Context, error) {
+	if obj == nil || obj == py.None {
+		return GetContext(), nil
+	}
+	ctx, ok := obj.(*Context)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "optional argument must be a context")
+	}
+	return ctx, nil
+}
+
+// The standard contexts
+var (
+	// DefaultContext is the template for new contexts
+	DefaultContext = NewContext()
+
+	// BasicContext is the General Decimal Arithmetic basic context
+	BasicContext = &Context{
+		Prec:     9,
+		Rounding: RoundHalfUp,
+		Emin:     -999999,
+		Emax:     999999,
+		Capitals: 1,
+		Flags:    newSignalDict(),
+		Traps:    newSignalDict(InvalidOperation, FloatOperation, DivisionByZero, Overflow, Underflow, Clamped),
+	}
+
+	// ExtendedContext is the General Decimal Arithmetic extended
+	// context which traps nothing
+	ExtendedContext = &Context{
+		Prec:     9,
+		Rounding: RoundHalfEven,
+		Emin:     -999999,
+		Emax:     999999,
+		Capitals: 1,
+		Flags:    newSignalDict(),
+		Traps:    newSignalDict(),
+	}
+)
+
+var contextManagerType = py.NewType("_ContextManager", "Context manager class to support localcontext().")
+
+// A contextManager sets the current context in a with statement,
+// restoring the previous one on exit
+type contextManager struct {
+	ctx   *Context
+	saved *Context
+}
+
+// Type of this object
+func (m *contextManager) Type() *py.Type {
+	return contextManagerType
+}
+
+func init() {
+	for _, name := range contextParams {
+		name := name
+		ContextType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				ctx := self.(*Context)
+				switch name {
+				case "prec":
+					return py.Int(ctx.Prec), nil
+				case "rounding":
+					return py.String(ctx.Rounding), nil
+				case "Emin":
+					return py.Int(ctx.Emin), nil
+				case "Emax":
+					return py.Int(ctx.Emax), nil
+				case "capitals":
+					return py.Int(ctx.Capitals), nil
+				case "clamp":
+					return py.Int(ctx.Clamp), nil
+				case "flags":
+					return ctx.Flags, nil
+				}
+				return ctx.Traps, nil
+			},
+			Fset: func(self, value py.Object) error {
+				return self.(*Context).setParam(name, value)
+			},
+		}
+	}
+	ContextType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*Context).Copy(), nil
+	}, 0, "copy() -> Return a duplicate of the context.")
+	ContextType.Dict["clear_flags"] = py.MustNewMethod("clear_flags", func(self py.Object) (py.Object, error) {
+		self.(*Context).Flags = newSignalDict()
+		return py.None, nil
+	}, 0, "clear_flags() -> Reset all flags to False.")
+	ContextType.Dict["clear_traps"] = py.MustNewMethod("clear_traps", func(self py.Object) (py.Object, error) {
+		self.(*Context).Traps = newSignalDict()
+		return py.None, nil
+	}, 0, "clear_traps() -> Set all traps to False.")
+	ContextType.Dict["Etiny"] = py.MustNewMethod("Etiny", func(self py.Object) (py.Object, error) {
+		return py.Int(self.(*Context).Etiny()), nil
+	}, 0, "Etiny() -> Return a value equal to Emin - prec + 1, which is the minimum exponent value for subnormal results.")
+	ContextType.Dict["Etop"] = py.MustNewMethod("Etop", func(self py.Object) (py.Object, error) {
+		return py.Int(self.(*Context).Etop()), nil
+	}, 0, "Etop() -> Return a value equal to Emax - prec + 1.")
+	ContextType.Dict["create_decimal"] = py.MustNewMethod("create_decimal", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var value py.Object = py.String("0")
+		err := py.UnpackTuple(args, nil, "create_decimal", 0, 1, &value)
+		if err != nil {
+			return nil, err
+		}
+		ctx := self.(*Context)
+		d, err := newDecimal(value, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if d.form == nanForm || d.form == snanForm {
+			if numDigits(d.coeff) > ctx.Prec-ctx.Clamp {
+				return ctx.invalid("diagnostic info too long in NaN")
+			}
+			return d, nil
+		}
+		return ctx.fix(d)
+	}, 0, "create_decimal(num) -> Create a new Decimal instance from num, using self as the context.  Unlike the Decimal constructor, this function observes the context limits.")
+	ContextType.Dict["create_decimal_from_float"] = py.MustNewMethod("create_decimal_from_float", func(self py.Object, arg py.Object) (py.Object, error) {
+		f, ok := arg.(py.Float)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "argument must be int or float")
+		}
+		ctx := self.(*Context)
+		return ctx.fix(decimalFromFloat(float64(f)))
+	}, 0, "create_decimal_from_float(f) -> Create a new Decimal instance from float f.  Unlike the Decimal.from_float() class method, this function observes the context limits.")
+
+	// The arithmetic operations of the context
+	unary := map[string]func(ctx *Context, a *Decimal) (*Decimal, error){
+		"abs":               (*Context).abs,
+		"exp":               (*Context).exp,
+		"ln":                (*Context).ln,
+		"log10":             (*Context).log10,
+		"minus":             (*Context).minus,
+		"normalize":         (*Context).normalize,
+		"plus":              (*Context).plus,
+		"sqrt":              (*Context).sqrt,
+		"to_integral_exact": (*Context).toIntegralExact,
+		"to_integral_value": (*Context).toIntegralValue,
+	}
+	for name, fn := range unary {
+		fn := fn
+		ContextType.Dict[name] = py.MustNewMethod(name, func(self py.Object, arg py.Object) (py.Object, error) {
+			a, err := decimalOperand(arg, true)
+			if err != nil {
+				return nil, err
+			}
+			return fn(self.(*Context), a)
+		}, 0, name+"(x) -> Apply the operation to x using this context.")
+	}
+	binary := map[string]func(ctx *Context, a, b *Decimal) (*Decimal, error){
+		"add":        (*Context).add,
+		"compare":    (*Context).compare,
+		"divide":     (*Context).div,
+		"divide_int": (*Context).divInt,
+		"max":        (*Context).max,
+		"min":        (*Context).min,
+		"multiply":   (*Context).mul,
+		"quantize":   (*Context).quantizeContext,
+		"remainder":  (*Context).rem,
+		"subtract":   (*Context).sub,
+	}
+	for name, fn := range binary {
+		fn := fn
+		ContextType.Dict[name] = py.MustNewMethod(name, func(self py.Object, args py.Tuple) (py.Object, error) {
+			var aObj, bObj py.Object
+			err := py.UnpackTuple(args, nil, name, 2, 2, &aObj, &bObj)
+			if err != nil {
+				return nil, err
+			}
+			a, err := decimalOperand(aObj, true)
+			if err != nil {
+				return nil, err
+			}
+			b, err := decimalOperand(bObj, true)
+			if err != nil {
+				return nil, err
+			}
+			return fn(self.(*Context), a, b)
+		}, 0, name+"(x, y) -> Apply the operation to x and y using this context.")
+	}
+	ContextType.Dict["divmod"] = py.MustNewMethod("divmod", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var aObj, bObj py.Object
+		err := py.UnpackTuple(args, nil, "divmod", 2, 2, &aObj, &bObj)
+		if err != nil {
+			return nil, err
+		}
+		a, err := decimalOperand(aObj, true)
+		if err != nil {
+			return nil, err
+		}
+		b, err := decimalOperand(bObj, true)
+		if err != nil {
+			return nil, err
+		}
+		q, r, err := self.(*Context).divMod(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{q, r}, nil
+	}, 0, "divmod(x, y) -> Return quotient and remainder of the division x / y.")
+	ContextType.Dict["power"] = py.MustNewMethod("power", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var aObj, bObj py.Object
+		var modulo py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:power", []string{"a", "b", "modulo"}, &aObj, &bObj, &modulo)
+		if err != nil {
+			return nil, err
+		}
+		a, err := decimalOperand(aObj, true)
+		if err != nil {
+			return nil, err
+		}
+		b, err := decimalOperand(bObj, true)
+		if err != nil {
+			return nil, err
+		}
+		var m *Decimal
+		if modulo != py.None {
+			m, err = decimalOperand(modulo, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return self.(*Context).power(a, b, m)
+	}, 0, "power(a, b, modulo=None) -> Compute a**b.  If modulo is given, compute (a**b) % modulo.")
+
+	contextManagerType.Dict["__enter__"] = py.MustNewMethod("__enter__", func(self py.Object) (py.Object, error) {
+		m := self.(*contextManager)
+		m.saved = GetContext()
+		SetContext(m.ctx)
+		return m.ctx, nil
+	}, 0, "")
+	contextManagerType.Dict["__exit__"] = py.MustNewMethod("__exit__", func(self py.Object, args py.Tuple) (py.Object, error) {
+		SetContext(self.(*contextManager).saved)
+		return py.None, nil
+	}, 0, "")
+}
+
+func decimal_getcontext(self py.Object) (py.Object, error) {
+	return GetContext(), nil
+}
+
+const decimal_getcontext_doc = `getcontext() -> Get the current default context.`
+
+func decimal_setcontext(self py.Object, arg py.Object) (py.Object, error) {
+	ctx, ok := arg.(*Context)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "argument must be a context")
+	}
+	SetContext(ctx)
+	return py.None, nil
+}
+
+const decimal_setcontext_doc = `setcontext(ctx) -> Set a new default context.`
+
+func decimal_localcontext(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var ctxObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:localcontext", []string{"ctx"}, &ctxObj)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := contextArg(ctxObj)
+	if err != nil {
+		return nil, err
+	}
+	return &contextManager{ctx: ctx.Copy()}, nil
+}
+
+const decimal_localcontext_doc = `localcontext(ctx=None) -> Return a context manager that will set the default context to a copy of ctx on entry to the with-statement and restore the previous default context when exiting the with-statement. If no context is specified, a copy of the current default context is used.`
@@ -0,0 +1,1172 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Decimal module
+//
+// Decimal numbers are stored as a sign, a big.Int coefficient and a
+// decimal exponent so they are exact.  The arithmetic is done with
+// the current context which sets the precision, rounding and which
+// signals raise exceptions.
+
+package decimal
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-python/gpython/abc"
+	"github.com/go-python/gpython/collections"
+	"github.com/go-python/gpython/numbers"
+	"github.com/go-python/gpython/py"
+)
+
+var DecimalType = py.NewTypeX("Decimal", `Decimal(value="0", context=None)
+
+Construct a new Decimal object. 'value' can be an integer, string, tuple,
+or another Decimal object. If no value is given, return Decimal('0'). The
+context does not affect the conversion and is only passed to determine if
+the InvalidOperation trap is active.`, DecimalNew, nil)
+
+// The forms a Decimal can take
+const (
+	finiteForm = iota
+	infForm
+	nanForm
+	snanForm
+)
+
+// A python Decimal object
+//
+// The value of a finite Decimal is (-1)**neg * coeff * 10**exp.  The
+// coeff of a NaN is its diagnostic information.
+type Decimal struct {
+	neg   bool
+	form  int
+	coeff *big.Int
+	exp   int
+}
+
+// Type of this Decimal object
+func (d *Decimal) Type() *py.Type {
+	return DecimalType
+}
+
+// A quiet NaN
+var nan = &Decimal{form: nanForm, coeff: new(big.Int)}
+
+// The type of Decimal.as_tuple() results, set in init
+var DecimalTupleType *py.Type
+
+// Returns whether d is zero
+func (d *Decimal) isZero() bool {
+	return d.form == finiteForm && d.coeff.Sign() == 0
+}
+
+// Returns whether d is a quiet or signalling NaN
+func (d *Decimal) isNaN() bool {
+	return d.form == nanForm || d.form == snanForm
+}
+
+// Returns the exponent of the most significant digit of d
+func (d *Decimal) adjusted() int {
+	if d.form != finiteForm {
+		return 0
+	}
+	return d.exp + numDigits(d.coeff) - 1
+}
+
+// Returns a copy of d with a positive sign
+func (d *Decimal) copyAbs() *Decimal {
+	ans := *d
+	ans.neg = false
+	return &ans
+}
+
+// Returns a copy of d with the sign inverted
+func (d *Decimal) copyNegate() *Decimal {
+	ans := *d
+	ans.neg = !d.neg
+	return &ans
+}
+
+// Matches the string forms of a Decimal
+var decimalFormat = regexp.MustCompile(`(?i)^([-+]?)(?:(\d*)(?:\.(\d*))?(?:e([-+]?\d+))?|(inf|infinity)|(s?nan)(\d*))$`)
+
+// Parses a string into a Decimal returning false if it is invalid
+//
+// Underscores are ignored as they are in CPython.
+func parseDecimal(s string) (*Decimal, bool) {
+	s = strings.Replace(strings.TrimSpace(s), "_", "", -1)
+	m := decimalFormat.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	neg := m[1] == "-"
+	switch {
+	case m[5] != "":
+		return &Decimal{neg: neg, form: infForm, coeff: new(big.Int)}, true
+	case m[6] != "":
+		form := nanForm
+		if strings.ToLower(m[6]) == "snan" {
+			form = snanForm
+		}
+		coeff, _ := new(big.Int).SetString("0"+m[7], 10)
+		return &Decimal{neg: neg, form: form, coeff: coeff}, true
+	}
+	intPart, fracPart := m[2], m[3]
+	if intPart == "" && fracPart == "" {
+		return nil, false
+	}
+	coeff, _ := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	exp := 0
+	if m[4] != "" {
+		var err error
+		exp, err = strconv.Atoi(m[4])
+		if err != nil || exp > MaxEmax || exp < MinEtiny {
+			return nil, false
+		}
+	}
+	return newFinite(neg, coeff, exp-len(fracPart)), true
+}
+
+// Makes a Decimal from an integer
+func decimalFromBig(x *big.Int) *Decimal {
+	return newFinite(x.Sign() < 0, new(big.Int).Abs(x), 0)
+}
+
+// Makes a Decimal with the exact value of a float
+func decimalFromFloat(f float64) *Decimal {
+	switch {
+	case math.IsNaN(f):
+		return nan
+	case math.IsInf(f, 0):
+		return newInfinity(f < 0)
+	}
+	neg := math.Signbit(f)
+	r := new(big.Rat).SetFloat64(math.Abs(f))
+	// The denominator is a power of 2, say 2**k, so
+	// n/2**k = n*5**k/10**k
+	k := r.Denom().BitLen() - 1
+	coeff := new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(k)), nil)
+	coeff.Mul(coeff, r.Num())
+	return newFinite(neg, coeff, -k)
+}
+
+// Makes a Decimal from a (sign, digits, exponent) tuple
+func decimalFromTuple(t py.Tuple) (*Decimal, error) {
+	if len(t) != 3 {
+		return nil, py.ExceptionNewf(py.ValueError, "argument must be a sequence of length 3")
+	}
+	sign, ok := t[0].(py.Int)
+	if !ok || (sign != 0 && sign != 1) {
+		return nil, py.ExceptionNewf(py.ValueError, "sign must be an integer with the value 0 or 1")
+	}
+	digits, err := py.SequenceTuple(t[1])
+	if err != nil {
+		return nil, py.ExceptionNewf(py.ValueError, "coefficient must be a tuple of digits")
+	}
+	var s strings.Builder
+	s.WriteByte('0')
+	for _, digit := range digits {
+		i, ok := digit.(py.Int)
+		if !ok || i < 0 || i > 9 {
+			return nil, py.ExceptionNewf(py.ValueError, "coefficient must be a tuple of digits")
+		}
+		s.WriteByte(byte('0' + i))
+	}
+	coeff, _ := new(big.Int).SetString(s.String(), 10)
+	d := &Decimal{neg: sign == 1, coeff: coeff}
+	switch exp := t[2].(type) {
+	case py.String:
+		switch exp {
+		case "F":
+			d.form = infForm
+			d.coeff = new(big.Int)
+		case "n":
+			d.form = nanForm
+		case "N":
+			d.form = snanForm
+		default:
+			return nil, py.ExceptionNewf(py.ValueError, "string argument in the third position must be 'F', 'n' or 'N'")
+		}
+	case py.Int:
+		d.exp = int(exp)
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "exponent must be an integer")
+	}
+	return d, nil
+}
+
+// Makes a Decimal from value signalling InvalidOperation in ctx for
+// invalid strings
+func newDecimal(value py.Object, ctx *Context) (*Decimal, error) {
+	switch x := value.(type) {
+	case *Decimal:
+		return x, nil
+	case py.String:
+		d, ok := parseDecimal(string(x))
+		if !ok {
+			return ctx.invalid("Invalid literal for Decimal: '%s'", string(x))
+		}
+		return d, nil
+	case py.Float:
+		_ = ctx.Flags.Set(FloatOperation, py.True)
+		return decimalFromFloat(float64(x)), nil
+	case py.Tuple:
+		return decimalFromTuple(x)
+	case *py.List:
+		return decimalFromTuple(x.Items)
+	}
+	if value.Type().Flags&py.TPFLAGS_TUPLE_SUBCLASS != 0 {
+		// Such as a DecimalTuple
+		t, err := py.SequenceTuple(value)
+		if err != nil {
+			return nil, err
+		}
+		return decimalFromTuple(t)
+	}
+	return decimalOperand(value, true)
+}
+
+// Converts an integer or a Decimal operand to a Decimal
+//
+// If strict is set other types raise a TypeError, otherwise nil is
+// returned so the caller can return NotImplemented.
+func decimalOperand(obj py.Object, strict bool) (*Decimal, error) {
+	switch x := obj.(type) {
+	case *Decimal:
+		return x, nil
+	case py.Int, *py.BigInt, py.Bool:
+		n, _ := py.ConvertToBigInt(x)
+		return decimalFromBig((*big.Int)(n)), nil
+	}
+	if strict {
+		return nil, py.ExceptionNewf(py.TypeError, "conversion from %s to Decimal is not supported", obj.Type().Name)
+	}
+	return nil, nil
+}
+
+// DecimalNew makes a new Decimal
+func DecimalNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var value py.Object = py.String("0")
+	var ctxObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:Decimal", []string{"value", "context"}, &value, &ctxObj)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := contextArg(ctxObj)
+	if err != nil {
+		return nil, err
+	}
+	return newDecimal(value, ctx)
+}
+
+// Returns a mod m as Python does
+func pmod(a, m int) int {
+	r := a % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+// Converts d to a string in scientific notation, or engineering
+// notation if eng is set, as defined by the General Decimal
+// Arithmetic specification
+func (d *Decimal) toString(eng bool, capitals int) string {
+	sign := ""
+	if d.neg {
+		sign = "-"
+	}
+	switch d.form {
+	case infForm:
+		return sign + "Infinity"
+	case nanForm, snanForm:
+		s := sign + "NaN"
+		if d.form == snanForm {
+			s = sign + "sNaN"
+		}
+		if d.coeff.Sign() != 0 {
+			s += d.coeff.String()
+		}
+		return s
+	}
+	digits := d.coeff.String()
+	leftDigits := d.exp + len(digits)
+	var dotPlace int
+	switch {
+	case d.exp <= 0 && leftDigits > -6:
+		dotPlace = leftDigits
+	case !eng:
+		dotPlace = 1
+	case d.coeff.Sign() == 0:
+		dotPlace = pmod(leftDigits+1, 3) - 1
+	default:
+		dotPlace = pmod(leftDigits-1, 3) + 1
+	}
+	var intPart, fracPart string
+	switch {
+	case dotPlace <= 0:
+		intPart = "0"
+		fracPart = "." + strings.Repeat("0", -dotPlace) + digits
+	case dotPlace >= len(digits):
+		intPart = digits + strings.Repeat("0", dotPlace-len(digits))
+	default:
+		intPart = digits[:dotPlace]
+		fracPart = "." + digits[dotPlace:]
+	}
+	exp := ""
+	if leftDigits != dotPlace {
+		e := "e"
+		if capitals != 0 {
+			e = "E"
+		}
+		exp = e + strconv.FormatInt(int64(leftDigits-dotPlace), 10)
+		if leftDigits-dotPlace > 0 {
+			exp = e + "+" + exp[1:]
+		}
+	}
+	return sign + intPart + fracPart + exp
+}
+
+func (d *Decimal) M__str__() (py.Object, error) {
+	return py.String(d.toString(false, GetContext().Capitals)), nil
+}
+
+func (d *Decimal) M__repr__() (py.Object, error) {
+	return py.String("Decimal('" + d.toString(false, GetContext().Capitals) + "')"), nil
+}
+
+// Rounds a finite d to places significant digits
+func roundSignificant(d *Decimal, places int, mode string) *Decimal {
+	if d.form != finiteForm || d.isZero() {
+		return d
+	}
+	ans, _ := rescale(d, d.adjusted()+1-places, mode)
+	if ans.adjusted() != d.adjusted() {
+		ans, _ = rescale(ans, ans.adjusted()+1-places, mode)
+	}
+	return ans
+}
+
+func (d *Decimal) M__format__(formatSpec py.Object) (py.Object, error) {
+	spec, ok := formatSpec.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "format spec must be str, not %s", formatSpec.Type().Name)
+	}
+	ctx := GetContext()
+	return py.FormatNumber(spec, d.neg, func(typ byte, precision int) (string, error) {
+		switch typ {
+		case 0:
+			typ = 'g'
+			if ctx.Capitals != 0 {
+				typ = 'G'
+			}
+		case 'n':
+			typ = 'g'
+		case 'e', 'E', 'f', 'F', 'g', 'G', '%':
+		default:
+			return "", py.ExceptionNewf(py.ValueError, "Invalid format specifier")
+		}
+		if d.form != finiteForm {
+			s := d.copyAbs().toString(false, 1)
+			if typ == '%' {
+				s += "%"
+			}
+			return s, nil
+		}
+		x := d.copyAbs()
+		if typ == '%' {
+			x.exp += 2
+		}
+		if precision >= 0 {
+			switch typ {
+			case 'e', 'E':
+				x = roundSignificant(x, precision+1, ctx.Rounding)
+			case 'f', 'F', '%':
+				x, _ = rescale(x, -precision, ctx.Rounding)
+			case 'g', 'G':
+				if precision == 0 {
+					precision = 1
+				}
+				if numDigits(x.coeff) > precision {
+					x = roundSignificant(x, precision, ctx.Rounding)
+				}
+			}
+		}
+		if x.isZero() && x.exp > 0 && (typ == 'f' || typ == 'F' || typ == '%') {
+			x, _ = rescale(x, 0, ctx.Rounding)
+		}
+		digits := x.coeff.String()
+		leftDigits := x.exp + len(digits)
+		var dotPlace int
+		switch typ {
+		case 'e', 'E':
+			dotPlace = 1
+			if x.isZero() && precision >= 0 {
+				dotPlace = 1 - precision
+			}
+		case 'f', 'F', '%':
+			dotPlace = leftDigits
+		default:
+			dotPlace = 1
+			if x.exp <= 0 && leftDigits > -6 {
+				dotPlace = leftDigits
+			}
+		}
+		var intPart, fracPart string
+		switch {
+		case dotPlace < 0:
+			intPart = "0"
+			fracPart = strings.Repeat("0", -dotPlace) + digits
+		case dotPlace > len(digits):
+			intPart = digits + strings.Repeat("0", dotPlace-len(digits))
+		default:
+			intPart = digits[:dotPlace]
+			if intPart == "" {
+				intPart = "0"
+			}
+			fracPart = digits[dotPlace:]
+		}
+		s := intPart
+		if fracPart != "" {
+			s += "." + fracPart
+		}
+		exp := leftDigits - dotPlace
+		if exp != 0 || typ == 'e' || typ == 'E' {
+			e := "e"
+			if typ == 'E' || typ == 'G' {
+				e = "E"
+			}
+			if exp >= 0 {
+				e += "+"
+			}
+			s += e + strconv.Itoa(exp)
+		}
+		if typ == '%' {
+			s += "%"
+		}
+		return s, nil
+	})
+}
+
+// Arithmetic
+
+// Runs op with the current context if other is an integer or a
+// Decimal, swapping the arguments if reversed is set
+func (d *Decimal) binaryOp(other py.Object, reversed bool, op func(ctx *Context, a, b *Decimal) (*Decimal, error)) (py.Object, error) {
+	b, err := decimalOperand(other, false)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return py.NotImplemented, nil
+	}
+	a := d
+	if reversed {
+		a, b = b, a
+	}
+	ans, err := op(GetContext(), a, b)
+	if err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+// Runs op with the current context on d
+func (d *Decimal) unaryOp(op func(ctx *Context, a *Decimal) (*Decimal, error)) (py.Object, error) {
+	ans, err := op(GetContext(), d)
+	if err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (d *Decimal) M__neg__() (py.Object, error) {
+	return d.unaryOp((*Context).minus)
+}
+
+func (d *Decimal) M__pos__() (py.Object, error) {
+	return d.unaryOp((*Context).plus)
+}
+
+func (d *Decimal) M__abs__() (py.Object, error) {
+	return d.unaryOp((*Context).abs)
+}
+
+func (d *Decimal) M__add__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, false, (*Context).add)
+}
+
+func (d *Decimal) M__radd__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, true, (*Context).add)
+}
+
+func (d *Decimal) M__sub__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, false, (*Context).sub)
+}
+
+func (d *Decimal) M__rsub__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, true, (*Context).sub)
+}
+
+func (d *Decimal) M__mul__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, false, (*Context).mul)
+}
+
+func (d *Decimal) M__rmul__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, true, (*Context).mul)
+}
+
+func (d *Decimal) M__truediv__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, false, (*Context).div)
+}
+
+func (d *Decimal) M__rtruediv__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, true, (*Context).div)
+}
+
+func (d *Decimal) M__floordiv__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, false, (*Context).divInt)
+}
+
+func (d *Decimal) M__rfloordiv__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, true, (*Context).divInt)
+}
+
+func (d *Decimal) M__mod__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, false, (*Context).rem)
+}
+
+func (d *Decimal) M__rmod__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, true, (*Context).rem)
+}
+
+// Returns divmod(a, b) as a tuple
+func divModTuple(ctx *Context, a, b *Decimal) (py.Object, error) {
+	q, r, err := ctx.divMod(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{q, r}, nil
+}
+
+func (d *Decimal) divMod(other py.Object, reversed bool) (py.Object, py.Object, error) {
+	b, err := decimalOperand(other, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b == nil {
+		return py.NotImplemented, py.NotImplemented, nil
+	}
+	a := d
+	if reversed {
+		a, b = b, a
+	}
+	q, r, err := GetContext().divMod(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return q, r, nil
+}
+
+func (d *Decimal) M__divmod__(other py.Object) (py.Object, py.Object, error) {
+	return d.divMod(other, false)
+}
+
+func (d *Decimal) M__rdivmod__(other py.Object) (py.Object, py.Object, error) {
+	return d.divMod(other, true)
+}
+
+// Raises a to the power b, modulo m if it isn't nil
+func (ctx *Context) power(a, b, m *Decimal) (*Decimal, error) {
+	if m != nil {
+		return ctx.powMod(a, b, m)
+	}
+	return ctx.pow(a, b)
+}
+
+func (d *Decimal) M__pow__(other, modulus py.Object) (py.Object, error) {
+	b, err := decimalOperand(other, false)
+	if err != nil || b == nil {
+		return py.NotImplemented, err
+	}
+	var m *Decimal
+	if modulus != py.None {
+		m, err = decimalOperand(modulus, false)
+		if err != nil || m == nil {
+			return py.NotImplemented, err
+		}
+	}
+	ans, err := GetContext().power(d, b, m)
+	if err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (d *Decimal) M__rpow__(other py.Object) (py.Object, error) {
+	return d.binaryOp(other, true, (*Context).pow)
+}
+
+func (d *Decimal) M__bool__() (py.Object, error) {
+	return py.NewBool(!d.isZero()), nil
+}
+
+func (d *Decimal) M__hash__() (py.Object, error) {
+	switch d.form {
+	case snanForm:
+		return nil, py.ExceptionNewf(py.TypeError, "Cannot hash a signaling NaN value.")
+	case nanForm:
+		return py.Int(0), nil
+	case infForm:
+		if d.neg {
+			return py.Int(-py.HashInf), nil
+		}
+		return py.Int(py.HashInf), nil
+	}
+	// Decimals which are equal to ints, floats and Fractions must
+	// hash the same so hash as a rational, working modulo the hash
+	// modulus to keep the powers of 10 small
+	modulus := big.NewInt(py.HashModulus)
+	p := new(big.Int).Exp(bigTen, big.NewInt(int64(abs(d.exp))), modulus)
+	num := new(big.Int).Set(d.coeff)
+	den := big.NewInt(1)
+	if d.exp >= 0 {
+		num.Mul(num, p)
+	} else {
+		den = p
+	}
+	if d.neg {
+		num.Neg(num)
+	}
+	return py.Int(py.HashRat(num, den)), nil
+}
+
+// Conversions
+
+// Returns d rounded to an integer with the rounding mode given
+func (d *Decimal) toInt(mode string) (py.Object, error) {
+	switch d.form {
+	case nanForm, snanForm:
+		return nil, py.ExceptionNewf(py.ValueError, "cannot convert NaN to integer")
+	case infForm:
+		return nil, py.ExceptionNewf(py.OverflowError, "cannot convert Infinity to integer")
+	}
+	x, _ := rescale(d, 0, mode)
+	if x.exp > 0 {
+		x = newFinite(x.neg, new(big.Int).Mul(x.coeff, pow10(x.exp)), 0)
+	}
+	n := new(big.Int).Set(x.coeff)
+	if x.neg {
+		n.Neg(n)
+	}
+	return (*py.BigInt)(n).MaybeInt(), nil
+}
+
+func (d *Decimal) M__int__() (py.Object, error) {
+	return d.toInt(RoundDown)
+}
+
+func (d *Decimal) M__trunc__() (py.Object, error) {
+	return d.toInt(RoundDown)
+}
+
+func (d *Decimal) M__floor__() (py.Object, error) {
+	return d.toInt(RoundFloor)
+}
+
+func (d *Decimal) M__ceil__() (py.Object, error) {
+	return d.toInt(RoundCeiling)
+}
+
+func (d *Decimal) M__float__() (py.Object, error) {
+	switch d.form {
+	case snanForm:
+		return nil, py.ExceptionNewf(py.ValueError, "cannot convert signaling NaN to float")
+	case nanForm:
+		if d.neg {
+			return py.Float(math.Copysign(math.NaN(), -1)), nil
+		}
+		return py.Float(math.NaN()), nil
+	}
+	f, err := strconv.ParseFloat(d.toString(false, 1), 64)
+	if err != nil && !math.IsInf(f, 0) {
+		return nil, py.ExceptionNewf(py.ValueError, "could not convert %s to float", d.toString(false, 1))
+	}
+	return py.Float(f), nil
+}
+
+func (d *Decimal) M__complex__() (py.Object, error) {
+	f, err := d.M__float__()
+	if err != nil {
+		return nil, err
+	}
+	return py.Complex(complex(float64(f.(py.Float)), 0)), nil
+}
+
+// Rounds to the nearest integer if ndigits is None, otherwise to a
+// Decimal with exponent -ndigits using the rounding of the current
+// context
+func (d *Decimal) M__round__(ndigits py.Object) (py.Object, error) {
+	if ndigits == py.None {
+		return d.toInt(RoundHalfEven)
+	}
+	n, err := py.MakeGoInt(ndigits)
+	if err != nil {
+		return nil, err
+	}
+	ctx := GetContext()
+	ans, err := ctx.quantize(d, newFinite(false, big.NewInt(1), -n), ctx.Rounding)
+	if err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+// Rich comparison
+
+// Converts the other side of a comparison to a Decimal, returning nil
+// if it can't be compared
+//
+// Floats are compared exactly but signal FloatOperation, which only
+// raises an exception for ordering comparisons.
+func comparisonOperand(other py.Object, equality bool) (*Decimal, error) {
+	if f, ok := other.(py.Float); ok {
+		ctx := GetContext()
+		if equality {
+			_ = ctx.Flags.Set(FloatOperation, py.True)
+		} else if err := ctx.Signal(FloatOperation, "strict semantics for mixing floats and Decimals are enabled"); err != nil {
+			return nil, err
+		}
+		return decimalFromFloat(float64(f)), nil
+	}
+	return decimalOperand(other, false)
+}
+
+// Compares d with other passing the result of compare to cmp
+//
+// Comparisons with a NaN are False, or True for !=, and ordering
+// comparisons with a NaN signal InvalidOperation.
+func (d *Decimal) richCompare(other py.Object, equality bool, cmp func(int) bool) (py.Object, error) {
+	b, err := comparisonOperand(other, equality)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return py.NotImplemented, nil
+	}
+	if d.isNaN() || b.isNaN() {
+		if !equality {
+			if err := GetContext().Signal(InvalidOperation, "comparison involving NaN"); err != nil {
+				return nil, err
+			}
+		}
+		return py.NewBool(equality && !cmp(0)), nil
+	}
+	return py.NewBool(cmp(compare(d, b))), nil
+}
+
+func (d *Decimal) M__lt__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, false, func(c int) bool { return c < 0 })
+}
+
+func (d *Decimal) M__le__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, false, func(c int) bool { return c <= 0 })
+}
+
+func (d *Decimal) M__eq__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, true, func(c int) bool { return c == 0 })
+}
+
+func (d *Decimal) M__ne__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, true, func(c int) bool { return c != 0 })
+}
+
+func (d *Decimal) M__gt__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, false, func(c int) bool { return c > 0 })
+}
+
+func (d *Decimal) M__ge__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, false, func(c int) bool { return c >= 0 })
+}
+
+// Returns d as a (sign, digits, exponent) DecimalTuple
+func (d *Decimal) asTuple() (py.Object, error) {
+	var digits py.Tuple
+	if d.form != nanForm && d.form != snanForm || d.coeff.Sign() != 0 {
+		for _, c := range d.coeff.String() {
+			digits = append(digits, py.Int(c-'0'))
+		}
+	}
+	var exp py.Object
+	switch d.form {
+	case infForm:
+		exp = py.String("F")
+	case nanForm:
+		exp = py.String("n")
+	case snanForm:
+		exp = py.String("N")
+	default:
+		exp = py.Int(d.exp)
+	}
+	sign := py.Int(0)
+	if d.neg {
+		sign = 1
+	}
+	return py.Call(DecimalTupleType, py.Tuple{sign, digits, exp}, nil)
+}
+
+// Returns the exact value of a finite d as an integer ratio
+func (d *Decimal) asIntegerRatio() (py.Object, error) {
+	switch d.form {
+	case nanForm, snanForm:
+		return nil, py.ExceptionNewf(py.ValueError, "cannot convert NaN to integer ratio")
+	case infForm:
+		return nil, py.ExceptionNewf(py.OverflowError, "cannot convert Infinity to integer ratio")
+	}
+	n := new(big.Int).Set(d.coeff)
+	den := big.NewInt(1)
+	if d.exp >= 0 {
+		n.Mul(n, pow10(d.exp))
+	} else {
+		den = pow10(-d.exp)
+	}
+	if d.neg {
+		n.Neg(n)
+	}
+	r := new(big.Rat).SetFrac(n, den)
+	return py.Tuple{
+		(*py.BigInt)(new(big.Int).Set(r.Num())).MaybeInt(),
+		(*py.BigInt)(new(big.Int).Set(r.Denom())).MaybeInt(),
+	}, nil
+}
+
+// Parses a rounding mode argument returning def if it is None
+func roundingArg(obj py.Object, def string) (string, error) {
+	if obj == py.None {
+		return def, nil
+	}
+	if s, ok := obj.(py.String); ok {
+		for _, mode := range roundingModes {
+			if string(s) == mode {
+				return mode, nil
+			}
+		}
+	}
+	return "", py.ExceptionNewf(py.TypeError, "valid values for rounding are:\n  [ROUND_CEILING, ROUND_FLOOR, ROUND_UP, ROUND_DOWN,\n   ROUND_HALF_UP, ROUND_HALF_DOWN, ROUND_HALF_EVEN,\n   ROUND_05UP]")
+}
+
+// Check interface is satisfied
+var _ py.I__str__ = (*Decimal)(nil)
+var _ py.I__repr__ = (*Decimal)(nil)
+var _ py.I__format__ = (*Decimal)(nil)
+var _ py.I__bool__ = (*Decimal)(nil)
+var _ py.I__hash__ = (*Decimal)(nil)
+var _ py.I__round__ = (*Decimal)(nil)
+var _ py.I__trunc__ = (*Decimal)(nil)
+var _ py.I__floor__ = (*Decimal)(nil)
+var _ py.I__ceil__ = (*Decimal)(nil)
+var _ py.I__int__ = (*Decimal)(nil)
+var _ py.I__float__ = (*Decimal)(nil)
+var _ py.I__complex__ = (*Decimal)(nil)
+var _ py.I__divmod__ = (*Decimal)(nil)
+var _ py.I__rdivmod__ = (*Decimal)(nil)
+var _ py.I__pow__ = (*Decimal)(nil)
+var _ py.I__rpow__ = (*Decimal)(nil)
+var _ py.I__lt__ = (*Decimal)(nil)
+var _ py.I__eq__ = (*Decimal)(nil)
+
+const decimal_doc = `Decimal fixed point and floating point arithmetic.
+
+This is an implementation of decimal floating point arithmetic based on
+the General Decimal Arithmetic Specification:
+
+    http://speleotrove.com/decimal/decarith.html
+
+Decimal numbers are exact, and the precision, rounding and the
+handling of exceptional conditions are controlled by the current
+context, see getcontext(), setcontext() and localcontext().`
+
+// Initialise the module
+func init() {
+	DecimalType.Dict["__module__"] = py.String("decimal")
+	ContextType.Dict["__module__"] = py.String("decimal")
+
+	var err error
+	DecimalTupleType, err = collections.NewNamedTupleType("DecimalTuple", []string{"sign", "digits", "exponent"}, nil, py.String("decimal"))
+	if err != nil {
+		panic(err)
+	}
+
+	// Methods which take an optional context
+	unary := map[string]func(ctx *Context, a *Decimal) (*Decimal, error){
+		"exp":       (*Context).exp,
+		"ln":        (*Context).ln,
+		"log10":     (*Context).log10,
+		"normalize": (*Context).normalize,
+		"sqrt":      (*Context).sqrt,
+	}
+	for name, fn := range unary {
+		name, fn := name, fn
+		DecimalType.Dict[name] = py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var ctxObj py.Object = py.None
+			err := py.ParseTupleAndKeywords(args, kwargs, "|O:"+name, []string{"context"}, &ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			ctx, err := contextArg(ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			return fn(ctx, self.(*Decimal))
+		}, 0, name+"(context=None) -> Apply the operation using the context given or the current context.")
+	}
+	binary := map[string]func(ctx *Context, a, b *Decimal) (*Decimal, error){
+		"compare": (*Context).compare,
+		"max":     (*Context).max,
+		"min":     (*Context).min,
+	}
+	for name, fn := range binary {
+		name, fn := name, fn
+		DecimalType.Dict[name] = py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var other py.Object
+			var ctxObj py.Object = py.None
+			err := py.ParseTupleAndKeywords(args, kwargs, "O|O:"+name, []string{"other", "context"}, &other, &ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			b, err := decimalOperand(other, true)
+			if err != nil {
+				return nil, err
+			}
+			ctx, err := contextArg(ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			return fn(ctx, self.(*Decimal), b)
+		}, 0, name+"(other, context=None) -> Apply the operation using the context given or the current context.")
+	}
+	toIntegral := func(name string, exact bool) {
+		DecimalType.Dict[name] = py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var roundingObj py.Object = py.None
+			var ctxObj py.Object = py.None
+			err := py.ParseTupleAndKeywords(args, kwargs, "|OO:"+name, []string{"rounding", "context"}, &roundingObj, &ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			ctx, err := contextArg(ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			rounding, err := roundingArg(roundingObj, ctx.Rounding)
+			if err != nil {
+				return nil, err
+			}
+			return ctx.toIntegral(self.(*Decimal), rounding, exact)
+		}, 0, name+"(rounding=None, context=None) -> Round to the nearest integer.")
+	}
+	toIntegral("to_integral", false)
+	toIntegral("to_integral_value", false)
+	toIntegral("to_integral_exact", true)
+	DecimalType.Dict["quantize"] = py.MustNewMethod("quantize", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var expObj py.Object
+		var roundingObj py.Object = py.None
+		var ctxObj py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:quantize", []string{"exp", "rounding", "context"}, &expObj, &roundingObj, &ctxObj)
+		if err != nil {
+			return nil, err
+		}
+		exp, err := decimalOperand(expObj, true)
+		if err != nil {
+			return nil, err
+		}
+		ctx, err := contextArg(ctxObj)
+		if err != nil {
+			return nil, err
+		}
+		rounding, err := roundingArg(roundingObj, ctx.Rounding)
+		if err != nil {
+			return nil, err
+		}
+		return ctx.quantize(self.(*Decimal), exp, rounding)
+	}, 0, `quantize(exp, rounding=None, context=None) -> Return a value equal to the first operand after rounding and having the exponent of the second operand.
+
+    >>> Decimal('1.41421356').quantize(Decimal('1.000'))
+    Decimal('1.414')`)
+	DecimalType.Dict["adjusted"] = py.MustNewMethod("adjusted", func(self py.Object) (py.Object, error) {
+		return py.Int(self.(*Decimal).adjusted()), nil
+	}, 0, "adjusted() -> Return the adjusted exponent of the number.")
+	DecimalType.Dict["as_tuple"] = py.MustNewMethod("as_tuple", func(self py.Object) (py.Object, error) {
+		return self.(*Decimal).asTuple()
+	}, 0, "as_tuple() -> Return a tuple representation of the number.")
+	DecimalType.Dict["as_integer_ratio"] = py.MustNewMethod("as_integer_ratio", func(self py.Object) (py.Object, error) {
+		return self.(*Decimal).asIntegerRatio()
+	}, 0, "as_integer_ratio() -> Return a pair of integers, whose ratio is exactly equal to the original Decimal and with a positive denominator.")
+	DecimalType.Dict["to_eng_string"] = py.MustNewMethod("to_eng_string", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var ctxObj py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:to_eng_string", []string{"context"}, &ctxObj)
+		if err != nil {
+			return nil, err
+		}
+		ctx, err := contextArg(ctxObj)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(self.(*Decimal).toString(true, ctx.Capitals)), nil
+	}, 0, "to_eng_string(context=None) -> Convert to an engineering-type string.")
+
+	predicates := map[string]func(d *Decimal) bool{
+		"is_finite":    func(d *Decimal) bool { return d.form == finiteForm },
+		"is_infinite":  func(d *Decimal) bool { return d.form == infForm },
+		"is_nan":       (*Decimal).isNaN,
+		"is_qnan":      func(d *Decimal) bool { return d.form == nanForm },
+		"is_snan":      func(d *Decimal) bool { return d.form == snanForm },
+		"is_signed":    func(d *Decimal) bool { return d.neg },
+		"is_zero":      (*Decimal).isZero,
+		"is_canonical": func(d *Decimal) bool { return true },
+	}
+	for name, fn := range predicates {
+		fn := fn
+		DecimalType.Dict[name] = py.MustNewMethod(name, func(self py.Object) (py.Object, error) {
+			return py.NewBool(fn(self.(*Decimal))), nil
+		}, 0, name+"() -> Return True or False.")
+	}
+	contextPredicates := map[string]func(d *Decimal, ctx *Context) bool{
+		"is_normal": func(d *Decimal, ctx *Context) bool {
+			return d.form == finiteForm && !d.isZero() && d.adjusted() >= ctx.Emin
+		},
+		"is_subnormal": func(d *Decimal, ctx *Context) bool {
+			return d.form == finiteForm && !d.isZero() && d.adjusted() < ctx.Emin
+		},
+	}
+	for name, fn := range contextPredicates {
+		name, fn := name, fn
+		DecimalType.Dict[name] = py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var ctxObj py.Object = py.None
+			err := py.ParseTupleAndKeywords(args, kwargs, "|O:"+name, []string{"context"}, &ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			ctx, err := contextArg(ctxObj)
+			if err != nil {
+				return nil, err
+			}
+			return py.NewBool(fn(self.(*Decimal), ctx)), nil
+		}, 0, name+"(context=None) -> Return True or False.")
+	}
+
+	DecimalType.Dict["copy_abs"] = py.MustNewMethod("copy_abs", func(self py.Object) (py.Object, error) {
+		return self.(*Decimal).copyAbs(), nil
+	}, 0, "copy_abs() -> Return the absolute value of the argument without rounding.")
+	DecimalType.Dict["copy_negate"] = py.MustNewMethod("copy_negate", func(self py.Object) (py.Object, error) {
+		return self.(*Decimal).copyNegate(), nil
+	}, 0, "copy_negate() -> Return the negation of the argument without rounding.")
+	DecimalType.Dict["copy_sign"] = py.MustNewMethod("copy_sign", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var other py.Object
+		var ctxObj py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:copy_sign", []string{"other", "context"}, &other, &ctxObj)
+		if err != nil {
+			return nil, err
+		}
+		b, err := decimalOperand(other, true)
+		if err != nil {
+			return nil, err
+		}
+		ans := *self.(*Decimal)
+		ans.neg = b.neg
+		return &ans, nil
+	}, 0, "copy_sign(other, context=None) -> Return a copy of the first operand with the sign set to be the same as the sign of the second operand.")
+	DecimalType.Dict["same_quantum"] = py.MustNewMethod("same_quantum", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var other py.Object
+		var ctxObj py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:same_quantum", []string{"other", "context"}, &other, &ctxObj)
+		if err != nil {
+			return nil, err
+		}
+		b, err := decimalOperand(other, true)
+		if err != nil {
+			return nil, err
+		}
+		a := self.(*Decimal)
+		if a.form != finiteForm || b.form != finiteForm {
+			return py.NewBool(a.isNaN() && b.isNaN() || a.form == infForm && b.form == infForm), nil
+		}
+		return py.NewBool(a.exp == b.exp), nil
+	}, 0, "same_quantum(other, context=None) -> Test whether self and other have the same exponent or whether both are NaN.")
+	DecimalType.Dict["conjugate"] = py.MustNewMethod("conjugate", func(self py.Object) (py.Object, error) {
+		return self, nil
+	}, 0, "conjugate() -> Return self.")
+	DecimalType.Dict["real"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self, nil
+		},
+	}
+	DecimalType.Dict["imag"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return newZero(false, 0), nil
+		},
+	}
+	DecimalType.Dict["from_float"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("from_float", func(self py.Object, arg py.Object) (py.Object, error) {
+			if f, ok := arg.(py.Float); ok {
+				return decimalFromFloat(float64(f)), nil
+			}
+			d, err := decimalOperand(arg, false)
+			if err != nil {
+				return nil, err
+			}
+			if d == nil {
+				return nil, py.ExceptionNewf(py.TypeError, "argument must be int or float")
+			}
+			return d, nil
+		}, 0, "from_float(f) -> Class method that converts a float to a decimal number, exactly."),
+	}
+
+	err = abc.Register(numbers.NumberType, DecimalType)
+	if err != nil {
+		panic(err)
+	}
+
+	globals := py.StringDict{
+		"Decimal":          DecimalType,
+		"DecimalTuple":     DecimalTupleType,
+		"Context":          ContextType,
+		"DefaultContext":   DefaultContext,
+		"BasicContext":     BasicContext,
+		"ExtendedContext":  ExtendedContext,
+		"DecimalException": DecimalException,
+		"Clamped":          Clamped,
+		"InvalidOperation": InvalidOperation,
+		"DivisionByZero":   DivisionByZero,
+		"Inexact":          Inexact,
+		"Rounded":          Rounded,
+		"Subnormal":        Subnormal,
+		"Overflow":         Overflow,
+		"Underflow":        Underflow,
+		"FloatOperation":   FloatOperation,
+		"MAX_PREC":         py.Int(MaxPrec),
+		"MAX_EMAX":         py.Int(MaxEmax),
+		"MIN_EMIN":         py.Int(MinEmin),
+		"MIN_ETINY":        py.Int(MinEtiny),
+		"HAVE_CONTEXTVAR":  py.True,
+	}
+	for _, mode := range roundingModes {
+		globals[mode] = py.String(mode)
+	}
+	methods := []*py.Method{
+		py.MustNewMethod("getcontext", decimal_getcontext, 0, decimal_getcontext_doc),
+		py.MustNewMethod("setcontext", decimal_setcontext, 0, decimal_setcontext_doc),
+		py.MustNewMethod("localcontext", decimal_localcontext, 0, decimal_localcontext_doc),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "decimal",
+		Doc:     decimal_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,391 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import math
+import decimal
+from decimal import Decimal as D
+from decimal import getcontext, setcontext, localcontext, Context
+from libtest import *
+
+doc="constructor"
+assert str(D()) == "0"
+assert str(D(10)) == "10"
+assert str(D(-3)) == "-3"
+assert str(D(10**30)) == "1000000000000000000000000000000"
+assert str(D("1.50")) == "1.50"
+assert str(D("  -1.5e3  ")) == "-1.5E+3"
+assert str(D("1_000.5")) == "1000.5"
+assert str(D(".5")) == "0.5"
+assert str(D("5.")) == "5"
+assert str(D("-0")) == "-0"
+assert str(D("inf")) == "Infinity"
+assert str(D("-Infinity")) == "-Infinity"
+assert str(D("nan")) == "NaN"
+assert str(D("sNaN12")) == "sNaN12"
+assert str(D(0.5)) == "0.5"
+assert str(D(0.1)) == "0.1000000000000000055511151231257827021181583404541015625"
+assert str(D(float("-inf"))) == "-Infinity"
+assert str(D((1, (1, 4, 1), -2))) == "-1.41"
+assert str(D((0, (), "F"))) == "Infinity"
+assert str(D([0, [3], 2])) == "3E+2"
+assert str(D(D("2.5"))) == "2.5"
+assert str(D.from_float(0.25)) == "0.25"
+assert str(D.from_float(3)) == "3"
+assert str(D("1__0")) == "10"
+for bad in ["", "abc", "1e", "1.2.3", ".", "0x10", "1e1.5"]:
+    assertRaises(decimal.InvalidOperation, D, bad)
+assertRaises(ValueError, D, (2, (1,), 0))
+assertRaises(ValueError, D, (0, (10,), 0))
+assertRaises(TypeError, D, 1j)
+assert str(D("abc", Context(traps=[]))) == "NaN"
+
+doc="repr and str"
+assert repr(D("1.23")) == "Decimal('1.23')"
+assert repr(D("-inf")) == "Decimal('-Infinity')"
+assert str(D("1e-7")) == "1E-7"
+assert str(D("0.000001")) == "0.000001"
+assert str(D("123e5")) == "1.23E+7"
+assert str(D("0e10")) == "0E+10"
+assert str(D("0.00")) == "0.00"
+assert D("123e5").to_eng_string() == "12.3E+6"
+assert D("1e-7").to_eng_string() == "100E-9"
+assert D("0e10").to_eng_string() == "0.00E+12"
+with localcontext() as ctx:
+    ctx.capitals = 0
+    assert str(D("1e10")) == "1e+10"
+
+doc="arithmetic"
+assert D("1.1") + D("2.2") == D("3.3")
+assert str(D("1.1") + D("2.20")) == "3.30"
+assert str(D("1.30") - D("1.3")) == "0.00"
+assert str(D("1.5") * D("2")) == "3.0"
+assert str(D(1) / D(3)) == "0.3333333333333333333333333333"
+assert str(D(2) / D(3)) == "0.6666666666666666666666666667"
+assert str(D(1) / D(4)) == "0.25"
+assert str(D(100) / D(10)) == "10"
+assert str(D("1E+3") / D(10)) == "1E+2"
+assert str(D("12.0") / D(4)) == "3.0"
+assert str(D(7) // D(2)) == "3"
+assert str(D(-7) // D(2)) == "-3"
+assert str(D(7) % D(2)) == "1"
+assert str(D(-7) % D(2)) == "-1"
+assert str(D("7.5") % D(2)) == "1.5"
+q, r = divmod(D(-7), D(2))
+assert str(q) == "-3" and str(r) == "-1"
+assert str(D(1) + 1) == "2"
+assert str(1 + D(1)) == "2"
+assert str(10 - D(1)) == "9"
+assert str(3 * D("1.5")) == "4.5"
+assert str(1 / D(8)) == "0.125"
+assert str(-D("1.5")) == "-1.5"
+assert str(-D("0")) == "0"
+assert str(+D("-0")) == "0"
+assert str(abs(D("-2.5"))) == "2.5"
+assert str(D("inf") + 1) == "Infinity"
+assert str(D("inf") * -2) == "-Infinity"
+assert str(D(1) / D("inf")) == "0E-1000026"
+assert str(D("nan") + 1) == "NaN"
+assertRaises(decimal.InvalidOperation, lambda: D("inf") - D("inf"))
+assertRaises(decimal.InvalidOperation, lambda: D("snan") + 1)
+assertRaises(TypeError, lambda: D(1) + 1.5)
+assertRaises(TypeError, lambda: 1.5 * D(1))
+assertRaises(decimal.DivisionByZero, lambda: D(1) / 0)
+assertRaises(ZeroDivisionError, lambda: D(1) / 0)
+assertRaises(decimal.InvalidOperation, lambda: D(0) / 0)
+assertRaises(decimal.InvalidOperation, lambda: D(1) % 0)
+x = D(1)
+x += 2
+assert x == 3
+
+doc="rounding to precision"
+assert str(D("1.23456789012345678901234567890") + 0) == "1.234567890123456789012345679"
+assert str(D(10**30) + 1) == "1.000000000000000000000000000E+30"
+assert str(D("123.456").sqrt()) == "11.11107555549866648462149404"
+assert str(D(2).sqrt()) == "1.414213562373095048801688724"
+assert str(D(4).sqrt()) == "2"
+assert str(D("0.25").sqrt()) == "0.5"
+assert str(D(100).sqrt()) == "10"
+assertRaises(decimal.InvalidOperation, D(-1).sqrt)
+
+doc="power"
+assert str(D(2) ** 10) == "1024"
+assert str(D("1.5") ** 2) == "2.25"
+assert str(D("2.0") ** 2) == "4.00"
+assert str(D(2) ** -2) == "0.25"
+assert str(D(10) ** -2) == "0.01"
+assert str(D(-2) ** 3) == "-8"
+assert str(D(2) ** 100) == "1.267650600228229401496703205E+30"
+assert str(D(2) ** D("0.5")) == "1.414213562373095048801688724"
+assert str(D(4) ** D("0.5")) == "2.000000000000000000000000000"
+assert str(D(3) ** D("1.5")) == "5.196152422706631880582339025"
+assert str(2 ** D(3)) == "8"
+assert str(pow(D(3), 4, 5)) == "1"
+assert str(pow(D(-3), 3, 5)) == "-2"
+assertRaises(decimal.InvalidOperation, lambda: D(-2) ** D("0.5"))
+assertRaises(decimal.InvalidOperation, lambda: D(0) ** 0)
+
+doc="exp and logarithms"
+assert str(D(1).exp()) == "2.718281828459045235360287471"
+assert str(D(0).exp()) == "1"
+assert str(D(-1).exp()) == "0.3678794411714423215955237702"
+assert str(D(2).ln()) == "0.6931471805599453094172321215"
+assert str(D(1).ln()) == "0"
+assert str(D(10).ln()) == "2.302585092994045684017991455"
+assert str(D("1.000001").ln()) == "9.999995000003333330833335333E-7"
+assert str(D(100).log10()) == "2"
+assert str(D("0.001").log10()) == "-3"
+assert str(D(2).log10()) == "0.3010299956639811952137388947"
+assert str(D(0).ln()) == "-Infinity"
+assertRaises(decimal.InvalidOperation, D(-1).ln)
+
+doc="comparison"
+assert D(1) == 1
+assert D("1.0") == D(1)
+assert D(1) != D(2)
+assert D(1) < D(2)
+assert D("-1.5") < 0
+assert D(2) >= 2
+assert D("0.5") == 0.5
+assert D("0.1") != 0.1
+assert D("0.1") < 0.2
+assert not (D("nan") == D("nan"))
+assert D("nan") != 1
+assertRaises(decimal.InvalidOperation, lambda: D("nan") < 1)
+assert D("-inf") < D(-10**100) < D("inf")
+assert D(0) == D("-0")
+assert sorted([D(3), D("1.5"), D(-2)]) == [D(-2), D("1.5"), D(3)]
+assert str(D(1).compare(D(2))) == "-1"
+assert str(D(2).compare(2)) == "0"
+assert str(D("nan").compare(1)) == "NaN"
+assert str(D(1).max(D(2))) == "2"
+assert str(D(1).min(D(2))) == "1"
+assert str(D("nan").max(D(2))) == "2"
+assert str(D("2.0").max(D(2))) == "2"
+assert str(D("-2.0").max(D(-2))) == "-2.0"
+
+doc="hash"
+assert hash(D(1)) == hash(1)
+assert hash(D("1.00")) == hash(1)
+assert hash(D(-7)) == hash(-7)
+assert hash(D(10**30)) == hash(10**30)
+assert hash(D("0.5")) == hash(0.5)
+assert hash(D("-2.25")) == hash(-2.25)
+assert hash(D("inf")) == hash(float("inf"))
+assertRaises(TypeError, hash, D("snan"))
+assert {D(1): "a"}[1] == "a"
+
+doc="conversions"
+assert int(D("3.7")) == 3
+assert int(D("-3.7")) == -3
+assert int(D("1e30")) == 10**30
+assert math.floor(D("-3.5")) == -4
+assert math.ceil(D("3.2")) == 4
+assert math.trunc(D("-3.9")) == -3
+assert round(D("2.5")) == 2
+assert round(D("3.5")) == 4
+assert str(round(D("3.14159"), 2)) == "3.14"
+assert str(round(D("1234"), -2)) == "1.2E+3"
+assert float(D("1.5")) == 1.5
+assert float(D("-1e400")) == float("-inf")
+assert math.isnan(float(D("nan")))
+assert complex(D("2.5")) == 2.5+0j
+assertRaises(ValueError, int, D("nan"))
+assertRaises(OverflowError, int, D("inf"))
+assertRaises(ValueError, float, D("snan"))
+assert D("0") or True
+assert not D("0.00")
+assert D("0.01")
+assert D("1.25").as_integer_ratio() == (5, 4)
+assert D("-300").as_integer_ratio() == (-300, 1)
+assertRaises(ValueError, D("nan").as_integer_ratio)
+t = D("-1.25").as_tuple()
+assert t == (1, (1, 2, 5), -2)
+assert t.sign == 1 and t.digits == (1, 2, 5) and t.exponent == -2
+assert D("inf").as_tuple() == (0, (0,), "F")
+assert D("nan").as_tuple() == (0, (), "n")
+assert D(t) == D("-1.25")
+assert D("1.5").real == D("1.5")
+assert D("1.5").imag == 0
+assert D("1.5").conjugate() == D("1.5")
+
+doc="format"
+assert format(D("1234.5678"), ".2f") == "1234.57"
+assert format(D("1234.5678"), ",.2f") == "1,234.57"
+assert format(D("-1234.5"), "") == "-1234.5"
+assert format(D("1.5"), "10") == "       1.5"
+assert format(D("1.5"), "<6") == "1.5   "
+assert format(D("1.5"), "+") == "+1.5"
+assert format(D("12345"), ".2e") == "1.23e+4"
+assert format(D("12345"), "E") == "1.2345E+4"
+assert format(D("0.00012345"), "g") == "0.00012345"
+assert format(D("0.0000012345"), "g") == "0.0000012345"
+assert format(D("1e-7"), "g") == "1e-7"
+assert format(D("123.456"), ".4g") == "123.5"
+assert format(D("0.25"), "%") == "25%"
+assert format(D("0.25"), ".1%") == "25.0%"
+assert format(D("2.5"), ".0f") == "2"
+assert format(D("3.5"), ".0f") == "4"
+assert format(D("inf"), "f") == "Infinity"
+assert format(D("-inf"), ">10") == " -Infinity"
+assert format(D("1.5"), "010.3f") == "000001.500"
+assert "{:.3f}".format(D("2")) == "2.000"
+assertRaises(ValueError, format, D(1), "x")
+
+doc="quantize"
+assert str(D("7.325").quantize(D(".01"))) == "7.32"
+assert str(D("7.325").quantize(D(".01"), rounding=decimal.ROUND_HALF_UP)) == "7.33"
+assert str(D("7.325").quantize(D("1."), rounding=decimal.ROUND_UP)) == "8"
+assert str(D("7.325").quantize(D("1."), decimal.ROUND_DOWN)) == "7"
+assert str(D("1.41421356").quantize(D("1.000"))) == "1.414"
+assert str(D("2").quantize(D("0.01"))) == "2.00"
+assert str(D("-0.5").quantize(D("1"), rounding=decimal.ROUND_CEILING)) == "-0"
+assert str(D("123.456").quantize(D("1e1"))) == "1.2E+2"
+assertRaises(decimal.InvalidOperation, D("1e30").quantize, D("0.01"))
+assertRaises(decimal.InvalidOperation, D("inf").quantize, D(1))
+assertRaises(TypeError, D(1).quantize, D(1), rounding="bad")
+
+doc="rounding modes"
+def q(value, mode):
+    return str(D(value).quantize(D(1), rounding=mode))
+cases = [
+    (decimal.ROUND_UP, ["3", "3", "3", "-3", "-3", "-3"]),
+    (decimal.ROUND_DOWN, ["2", "2", "2", "-2", "-2", "-2"]),
+    (decimal.ROUND_CEILING, ["3", "3", "3", "-2", "-2", "-2"]),
+    (decimal.ROUND_FLOOR, ["2", "2", "2", "-3", "-3", "-3"]),
+    (decimal.ROUND_HALF_UP, ["2", "3", "3", "-2", "-3", "-3"]),
+    (decimal.ROUND_HALF_DOWN, ["2", "2", "3", "-2", "-2", "-3"]),
+    (decimal.ROUND_HALF_EVEN, ["2", "2", "3", "-2", "-2", "-3"]),
+    (decimal.ROUND_05UP, ["2", "2", "2", "-2", "-2", "-2"]),
+]
+for mode, want in cases:
+    got = [q(v, mode) for v in ["2.4", "2.5", "2.6", "-2.4", "-2.5", "-2.6"]]
+    assert got == want, (mode, got)
+assert q("5.5", decimal.ROUND_05UP) == "6"
+assert q("3.5", decimal.ROUND_HALF_EVEN) == "4"
+assert str(D("2.5").to_integral_value()) == "2"
+assert str(D("2.5").to_integral(rounding=decimal.ROUND_HALF_UP)) == "3"
+assert str(D("-2.5").to_integral_exact(decimal.ROUND_FLOOR)) == "-3"
+assert str(D("1e5").to_integral_value()) == "1E+5"
+
+doc="other methods"
+assert D("123.45").adjusted() == 2
+assert D("0.001").adjusted() == -3
+assert str(D("120.00").normalize()) == "1.2E+2"
+assert str(D("0.00").normalize()) == "0"
+assert str(D("-1.5").copy_abs()) == "1.5"
+assert str(D("1.5").copy_negate()) == "-1.5"
+assert str(D("1.5").copy_sign(D(-2))) == "-1.5"
+assert D("1.5").same_quantum(D("2.7"))
+assert not D("1.5").same_quantum(D("2"))
+assert D("nan").is_nan() and D("snan").is_nan()
+assert D("nan").is_qnan() and not D("nan").is_snan()
+assert D("snan").is_snan()
+assert D("inf").is_infinite() and not D("inf").is_finite()
+assert D(1).is_finite()
+assert D("-0").is_signed() and D("-0").is_zero()
+assert D(1).is_normal() and not D(0).is_normal()
+assert D("1e-999999").is_normal() and D("1e-1000000").is_subnormal()
+
+doc="context"
+ctx = getcontext()
+assert ctx.prec == 28
+assert ctx.rounding == decimal.ROUND_HALF_EVEN
+assert ctx.Emin == -999999 and ctx.Emax == 999999
+assert ctx.capitals == 1 and ctx.clamp == 0
+assert ctx.traps[decimal.InvalidOperation]
+assert not ctx.traps[decimal.Inexact]
+assert getcontext() is ctx
+c = Context(prec=5, rounding=decimal.ROUND_DOWN, traps=[])
+assert repr(c) == "Context(prec=5, rounding=ROUND_DOWN, Emin=-999999, Emax=999999, capitals=1, clamp=0, flags=[], traps=[])"
+assert str(c.divide(D(2), D(3))) == "0.66666"
+assert c.flags[decimal.Inexact] and c.flags[decimal.Rounded]
+c.clear_flags()
+assert not c.flags[decimal.Inexact]
+assert str(c.divide(D(1), D(0))) == "Infinity"
+assert c.flags[decimal.DivisionByZero]
+assert str(c.add(1, 2)) == "3"
+assert str(c.power(2, 10)) == "1024"
+assert str(c.power(3, 4, 5)) == "1"
+assert str(c.sqrt(D(2))) == "1.4142"
+assert c.divmod(7, 2) == (3, 1)
+assert str(c.create_decimal("1.234567")) == "1.2345"
+assert str(c.create_decimal_from_float(0.1)) == "0.10000"
+assert c.Etiny() == -1000003 and c.Etop() == 999995
+c2 = c.copy()
+c2.prec = 10
+assert c.prec == 5
+assertRaises(ValueError, setattr, c, "prec", 0)
+assertRaises(TypeError, setattr, c, "rounding", "bad")
+assertRaises(TypeError, Context, prec="x")
+assertRaises(TypeError, setcontext, 1)
+
+doc="localcontext"
+with localcontext() as ctx:
+    ctx.prec = 3
+    assert str(D(1) / D(3)) == "0.333"
+    assert getcontext() is ctx
+assert getcontext().prec == 28
+assert str(D(1) / D(3)) == "0.3333333333333333333333333333"
+with localcontext(Context(prec=2)):
+    assert str(D(2) / D(3)) == "0.67"
+assert getcontext().prec == 28
+saved = getcontext()
+setcontext(Context(prec=4))
+assert str(D(1) / D(7)) == "0.1429"
+setcontext(saved)
+assert getcontext() is saved
+
+doc="signals and traps"
+assert issubclass(decimal.DivisionByZero, ZeroDivisionError)
+assert issubclass(decimal.DivisionByZero, decimal.DecimalException)
+assert issubclass(decimal.DecimalException, ArithmeticError)
+assert issubclass(decimal.Overflow, decimal.Inexact)
+assert issubclass(decimal.Overflow, decimal.Rounded)
+assert issubclass(decimal.Underflow, decimal.Subnormal)
+assert issubclass(decimal.FloatOperation, TypeError)
+with localcontext() as ctx:
+    ctx.traps[decimal.Inexact] = True
+    assertRaises(decimal.Inexact, lambda: D(1) / D(3))
+    assert str(D(1) / D(4)) == "0.25"
+with localcontext() as ctx:
+    assertRaises(decimal.Overflow, lambda: D("1e999999") * 10)
+    ctx.traps[decimal.Overflow] = False
+    assert str(D("1e999999") * 10) == "Infinity"
+    assert ctx.flags[decimal.Overflow]
+    ctx.rounding = decimal.ROUND_DOWN
+    assert str(D("1e999999") * 10) == "9.999999999999999999999999999E+999999"
+with localcontext() as ctx:
+    ctx.prec = 5
+    ctx.Emin = -10
+    assert str(D("1e-12") / 3) == "3.3E-13"
+    assert ctx.flags[decimal.Subnormal] and ctx.flags[decimal.Underflow]
+    assert str(D("1e-20") / 3) == "0E-14"
+    assert ctx.flags[decimal.Clamped]
+with localcontext() as ctx:
+    ctx.traps[decimal.FloatOperation] = True
+    assertRaises(decimal.FloatOperation, lambda: D(1) < 1.5)
+    assert not (D(1) == 1.5)
+with localcontext(decimal.ExtendedContext):
+    assert str(D(0) / 0) == "NaN"
+    assert str(D(1) / 0) == "Infinity"
+with localcontext() as ctx:
+    ctx.clamp = 1
+    ctx.Emax = 10
+    ctx.prec = 3
+    assert str(+D("1e10")) == "1.00E+10"
+
+doc="numbers"
+import numbers
+assert isinstance(D(1), numbers.Number)
+assert not isinstance(D(1), numbers.Real)
+
+doc="module"
+assert decimal.MAX_PREC == 999999999999999999
+assert decimal.MIN_ETINY == -1999999999999999997
+assert decimal.ROUND_HALF_EVEN == "ROUND_HALF_EVEN"
+assert decimal.DefaultContext.prec == 28
+assert decimal.BasicContext.prec == 9
+assert decimal.ExtendedContext.traps[decimal.InvalidOperation] == False
+
+doc="finished"
@@ -0,0 +1,502 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Dis module
+//
+// Disassembler of gpython bytecode into mnemonics.  The work is done
+// by the disassembler in the vm package, this makes it available to
+// python code.
+
+package dis
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+const dis_doc = `Disassembler of Python byte code into mnemonics.`
+
+// Writes s to file or sys.stdout if file is None
+func write(file py.Object, s string) error {
+	if file == py.None {
+		file = py.MustGetModule("sys").Globals["stdout"]
+	}
+	write, err := py.GetAttrString(file, "write")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(write, py.Tuple{py.String(s)}, nil)
+	return err
+}
+
+// Returns the code object of x which may be a function, method,
+// generator or code object, or nil if it doesn't have one
+func codeOf(x py.Object) *py.Code {
+	switch o := x.(type) {
+	case *py.Code:
+		return o
+	case *py.Function:
+		return o.Code
+	case *py.BoundMethod:
+		return codeOf(o.Method)
+	}
+	if code, err := py.GetAttrString(x, "__code__"); err == nil {
+		if co, ok := code.(*py.Code); ok {
+			return co
+		}
+	}
+	if code, err := py.GetAttrString(x, "gi_code"); err == nil {
+		if co, ok := code.(*py.Code); ok {
+			return co
+		}
+	}
+	return nil
+}
+
+// Compiles source code to disassemble, as an expression if possible
+// otherwise as statements
+func compileSource(source string) (*py.Code, error) {
+	obj, err := py.Compile(source, "<dis>", "eval", 0, true)
+	if err != nil {
+		obj, err = py.Compile(source, "<dis>", "exec", 0, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj.(*py.Code), nil
+}
+
+// Returns the code to disassemble for x which may be anything with
+// code or a string of source code or bytes of bytecode
+func toCode(x py.Object) (*py.Code, error) {
+	if co := codeOf(x); co != nil {
+		return co, nil
+	}
+	switch o := x.(type) {
+	case py.String:
+		return compileSource(string(o))
+	case py.Bytes:
+		return &py.Code{Code: string(o)}, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "don't know how to disassemble %s objects", x.Type().Name)
+}
+
+// Disassembles co to file marking the instruction at lasti
+func disassemble(co *py.Code, lasti int32, file py.Object) error {
+	var out bytes.Buffer
+	err := vm.Disassemble(&out, co, lasti)
+	if err != nil {
+		return err
+	}
+	return write(file, out.String())
+}
+
+// Returns the namespace of x if it is a class or a module, or nil
+func namespaceOf(x py.Object) py.StringDict {
+	switch o := x.(type) {
+	case *py.Module:
+		return o.Globals
+	case *py.Type:
+		if o.Type().IsSubtype(py.TypeType) {
+			return o.Dict
+		}
+	}
+	return nil
+}
+
+// Returns whether x is something dis can disassemble from a class or a
+// module
+func hasCode(x py.Object) bool {
+	switch o := x.(type) {
+	case *py.Code, *py.Function, *py.BoundMethod:
+		return true
+	case *py.Type:
+		return o.Type().IsSubtype(py.TypeType)
+	}
+	return false
+}
+
+// Disassembles each function, class and code in the namespace sorted
+// by name
+func disNamespace(ns py.StringDict, file py.Object) error {
+	names := make([]string, 0, len(ns))
+	for name, value := range ns {
+		if hasCode(value) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := write(file, fmt.Sprintf("Disassembly of %s:\n", name))
+		if err != nil {
+			return err
+		}
+		err = dis(ns[name], file)
+		if py.IsException(py.TypeError, err) {
+			msg, _ := py.StrAsString(exceptionValue(err))
+			err = write(file, fmt.Sprintf("Sorry: %s\n", msg))
+		}
+		if err != nil {
+			return err
+		}
+		err = write(file, "\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the exception instance carried by err
+func exceptionValue(err error) py.Object {
+	switch e := err.(type) {
+	case py.ExceptionInfo:
+		return e.Value
+	case *py.ExceptionInfo:
+		return e.Value
+	case *py.Exception:
+		return e
+	}
+	return py.None
+}
+
+// Disassembles x to file
+func dis(x, file py.Object) error {
+	if ns := namespaceOf(x); ns != nil {
+		return disNamespace(ns, file)
+	}
+	co, err := toCode(x)
+	if err != nil {
+		return err
+	}
+	return disassemble(co, -1, file)
+}
+
+// Returns the offset of the instruction executing when the traceback
+// tb was made
+//
+// The vm records the offset of the next instruction in the traceback
+// so this finds the one before it.
+func tracebackInstruction(tb *py.Traceback) int32 {
+	lasti := int32(-1)
+	for _, instr := range vm.GetInstructions(tb.Frame.Code) {
+		if instr.Offset >= tb.Lasti {
+			break
+		}
+		lasti = instr.Offset
+	}
+	return lasti
+}
+
+// Disassembles the last frame of the traceback tb, or of
+// sys.last_traceback if tb is None
+func distb(tb, file py.Object) error {
+	if tb == py.None {
+		tb = py.MustGetModule("sys").Globals["last_traceback"]
+		if tb == nil || tb == py.None {
+			return py.ExceptionNewf(py.RuntimeError, "no last traceback to disassemble")
+		}
+	}
+	traceback, ok := tb.(*py.Traceback)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "distb() expects a traceback, not %s", tb.Type().Name)
+	}
+	for traceback.Next != nil {
+		traceback = traceback.Next
+	}
+	return disassemble(traceback.Frame.Code, tracebackInstruction(traceback), file)
+}
+
+const dis_dis_doc = `Disassemble classes, methods, functions, generators, or code.
+
+With no argument, disassemble the last traceback.`
+
+func dis_dis(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var x, file py.Object = py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:dis", []string{"x", "file"}, &x, &file)
+	if err != nil {
+		return nil, err
+	}
+	if x == py.None {
+		err = distb(py.None, file)
+	} else {
+		err = dis(x, file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+const dis_disassemble_doc = `Disassemble a code object.`
+
+func dis_disassemble(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var co, lasti, file py.Object = nil, py.Int(-1), py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|iO:disassemble", []string{"co", "lasti", "file"}, &co, &lasti, &file)
+	if err != nil {
+		return nil, err
+	}
+	code, ok := co.(*py.Code)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "disassemble() expects a code object, not %s", co.Type().Name)
+	}
+	err = disassemble(code, int32(lasti.(py.Int)), file)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+const dis_distb_doc = `Disassemble a traceback (default: last traceback).`
+
+func dis_distb(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var tb, file py.Object = py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:distb", []string{"tb", "file"}, &tb, &file)
+	if err != nil {
+		return nil, err
+	}
+	err = distb(tb, file)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+const dis_get_instructions_doc = `Iterator for the opcodes in methods, functions or code
+
+Generates a series of Instruction named tuples giving the details of
+each operations in the supplied code.
+
+If *first_line* is not None, it indicates the line number that should
+be reported for the first source line in the disassembled code.
+Otherwise, the source line information (if any) is taken directly from
+the disassembled code object.`
+
+func dis_get_instructions(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var x, firstLine py.Object = nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:get_instructions", []string{"x", "first_line"}, &x, &firstLine)
+	if err != nil {
+		return nil, err
+	}
+	co, err := toCode(x)
+	if err != nil {
+		return nil, err
+	}
+	offset := int32(0)
+	if firstLine != py.None {
+		line, err := py.MakeGoInt(firstLine)
+		if err != nil {
+			return nil, err
+		}
+		offset = int32(line) - co.Firstlineno
+	}
+	instrs := vm.GetInstructions(co)
+	res := py.NewListSized(len(instrs))
+	for i := range instrs {
+		instr := &Instruction{instrs[i]}
+		if instr.StartsLine != 0 {
+			instr.StartsLine += offset
+		}
+		res.Items[i] = instr
+	}
+	return py.NewIterator(res.Items), nil
+}
+
+const dis_findlinestarts_doc = `Find the offsets in a byte code which are start of lines in the source.
+
+Generate pairs (offset, lineno) as described in Python/compile.c.`
+
+func dis_findlinestarts(self, code py.Object) (py.Object, error) {
+	co, ok := code.(*py.Code)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "findlinestarts() expects a code object, not %s", code.Type().Name)
+	}
+	starts := vm.LineStarts(co)
+	offsets := make([]int, 0, len(starts))
+	for offset := range starts {
+		offsets = append(offsets, int(offset))
+	}
+	sort.Ints(offsets)
+	res := py.NewListSized(len(offsets))
+	for i, offset := range offsets {
+		res.Items[i] = py.Tuple{py.Int(offset), py.Int(starts[int32(offset)])}
+	}
+	return py.NewIterator(res.Items), nil
+}
+
+const dis_findlabels_doc = `Detect all offsets in a byte code which are jump targets.
+
+Return the list of offsets.`
+
+func dis_findlabels(self, code py.Object) (py.Object, error) {
+	bytecode, ok := code.(py.Bytes)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "findlabels() expects bytes, not %s", code.Type().Name)
+	}
+	targets := vm.JumpTargets(&py.Code{Code: string(bytecode)})
+	offsets := make([]int, 0, len(targets))
+	for offset := range targets {
+		offsets = append(offsets, int(offset))
+	}
+	sort.Ints(offsets)
+	res := py.NewListSized(len(offsets))
+	for i, offset := range offsets {
+		res.Items[i] = py.Int(offset)
+	}
+	return res, nil
+}
+
+// ------------------------------------------------------------
+// Instruction
+
+// InstructionType is the type of the objects returned by
+// get_instructions
+var InstructionType = py.NewType("Instruction", `Details for a bytecode operation
+
+  opname - human readable name for operation
+  opcode - numeric code for operation
+  arg - numeric argument to operation (if any), otherwise None
+  argval - resolved arg value (if known), otherwise same as arg
+  argrepr - human readable description of operation argument
+  offset - start index of operation within bytecode sequence
+  starts_line - line started by this opcode (if any), otherwise None
+  is_jump_target - True if other code jumps to here, otherwise False`)
+
+// Instruction is a python wrapper for a vm.Instruction
+type Instruction struct {
+	vm.Instruction
+}
+
+// Type of this object
+func (instr *Instruction) Type() *py.Type {
+	return InstructionType
+}
+
+func (instr *Instruction) M__repr__() (py.Object, error) {
+	var arg, startsLine py.Object = py.None, py.None
+	if instr.HasArg {
+		arg = py.Int(instr.Arg)
+	}
+	if instr.StartsLine != 0 {
+		startsLine = py.Int(instr.StartsLine)
+	}
+	return py.String(fmt.Sprintf("Instruction(opname=%s, opcode=%d, arg=%s, argval=%s, argrepr=%s, offset=%d, starts_line=%s, is_jump_target=%s)",
+		py.DebugRepr(py.String(instr.Op.Name())), instr.Op, py.DebugRepr(arg), py.DebugRepr(instr.Argval),
+		py.DebugRepr(py.String(instr.Argrepr)), instr.Offset, py.DebugRepr(startsLine), py.DebugRepr(py.NewBool(instr.IsJumpTarget)))), nil
+}
+
+func (instr *Instruction) M__eq__(other py.Object) (py.Object, error) {
+	b, ok := other.(*Instruction)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if instr.Op != b.Op || instr.HasArg != b.HasArg || instr.Arg != b.Arg || instr.Argrepr != b.Argrepr ||
+		instr.Offset != b.Offset || instr.StartsLine != b.StartsLine || instr.IsJumpTarget != b.IsJumpTarget {
+		return py.False, nil
+	}
+	return py.Eq(instr.Argval, b.Argval)
+}
+
+// Properties
+func init() {
+	for name, get := range map[string]func(instr *Instruction) py.Object{
+		"opname": func(instr *Instruction) py.Object { return py.String(instr.Op.Name()) },
+		"opcode": func(instr *Instruction) py.Object { return py.Int(instr.Op) },
+		"arg": func(instr *Instruction) py.Object {
+			if !instr.HasArg {
+				return py.None
+			}
+			return py.Int(instr.Arg)
+		},
+		"argval":  func(instr *Instruction) py.Object { return instr.Argval },
+		"argrepr": func(instr *Instruction) py.Object { return py.String(instr.Argrepr) },
+		"offset":  func(instr *Instruction) py.Object { return py.Int(instr.Offset) },
+		"starts_line": func(instr *Instruction) py.Object {
+			if instr.StartsLine == 0 {
+				return py.None
+			}
+			return py.Int(instr.StartsLine)
+		},
+		"is_jump_target": func(instr *Instruction) py.Object { return py.NewBool(instr.IsJumpTarget) },
+	} {
+		get := get
+		InstructionType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return get(self.(*Instruction)), nil
+			},
+		}
+	}
+}
+
+// Check interface is satisfied
+var (
+	_ py.I__repr__ = (*Instruction)(nil)
+	_ py.I__eq__   = (*Instruction)(nil)
+)
+
+// Returns a list of the opcodes matching the test
+func opcodes(test func(op vm.OpCode) bool) *py.List {
+	res := py.NewList()
+	for i := 0; i < 256; i++ {
+		if test(vm.OpCode(i)) {
+			res.Append(py.Int(i))
+		}
+	}
+	return res
+}
+
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("dis", dis_dis, 0, dis_dis_doc),
+		py.MustNewMethod("disassemble", dis_disassemble, 0, dis_disassemble_doc),
+		py.MustNewMethod("disco", dis_disassemble, 0, dis_disassemble_doc),
+		py.MustNewMethod("distb", dis_distb, 0, dis_distb_doc),
+		py.MustNewMethod("get_instructions", dis_get_instructions, 0, dis_get_instructions_doc),
+		py.MustNewMethod("findlinestarts", dis_findlinestarts, 0, dis_findlinestarts_doc),
+		py.MustNewMethod("findlabels", dis_findlabels, 0, dis_findlabels_doc),
+	}
+	opname := py.NewListSized(256)
+	opmap := py.NewDict()
+	for i := range opname.Items {
+		op := vm.OpCode(i)
+		name := op.Name()
+		if strings.HasPrefix(name, "OpCode(") {
+			name = fmt.Sprintf("<%d>", i)
+		} else {
+			opmap.M__setitem__(py.String(name), py.Int(i))
+		}
+		opname.Items[i] = py.String(name)
+	}
+	cmpOp := make(py.Tuple, len(vm.CmpOp))
+	for i, op := range vm.CmpOp {
+		cmpOp[i] = py.String(op)
+	}
+	globals := py.StringDict{
+		"Instruction":   InstructionType,
+		"opname":        opname,
+		"opmap":         opmap,
+		"cmp_op":        cmpOp,
+		"hasconst":      opcodes(vm.OpCode.HAS_CONST),
+		"hasname":       opcodes(vm.OpCode.HAS_NAME),
+		"hasjrel":       opcodes(vm.OpCode.HAS_JREL),
+		"hasjabs":       opcodes(vm.OpCode.HAS_JABS),
+		"haslocal":      opcodes(vm.OpCode.HAS_LOCAL),
+		"hascompare":    opcodes(vm.OpCode.HAS_COMPARE),
+		"hasfree":       opcodes(vm.OpCode.HAS_FREE),
+		"hasnargs":      opcodes(vm.OpCode.HAS_NARGS),
+		"HAVE_ARGUMENT": py.Int(vm.HAVE_ARGUMENT),
+		"EXTENDED_ARG":  py.Int(vm.EXTENDED_ARG),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "dis",
+		Doc:     dis_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,118 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import dis
+from libtest import *
+
+class Writer:
+    def __init__(self):
+        self.out = ""
+    def write(self, s):
+        self.out += s
+
+def disassembly(x):
+    w = Writer()
+    dis.dis(x, file=w)
+    return w.out
+
+doc="dis function"
+def f(a):
+    if a:
+        return a + 1
+    return None
+assertEqual(disassembly(f), """\
+ 21           0 LOAD_FAST                0 (a)
+              3 POP_JUMP_IF_FALSE       14
+
+ 22           6 LOAD_FAST                0 (a)
+              9 LOAD_CONST               1 (1)
+             12 BINARY_ADD
+             13 RETURN_VALUE
+
+ 23     >>   14 LOAD_CONST               0 (None)
+             17 RETURN_VALUE
+""")
+
+doc="dis code and source"
+assertEqual(disassembly(f.__code__), disassembly(f))
+assertEqual(disassembly("x + 1"), """\
+  1           0 LOAD_NAME                0 (x)
+              3 LOAD_CONST               0 (1)
+              6 BINARY_ADD
+              7 RETURN_VALUE
+""")
+assertEqual(disassembly(b"\x64\x00\x00\x53"), """\
+              0 LOAD_CONST               0
+              3 RETURN_VALUE
+""")
+
+doc="dis class"
+class C:
+    def m(self):
+        return self
+assertEqual(disassembly(C), """\
+Disassembly of m:
+ 53           0 LOAD_FAST                0 (self)
+              3 RETURN_VALUE
+
+""")
+
+doc="dis bad type"
+try:
+    dis.dis(1)
+except TypeError as e:
+    assertEqual(str(e), "don't know how to disassemble int objects")
+else:
+    assert False, "TypeError not raised"
+
+doc="distb"
+def g():
+    return 1/0
+try:
+    g()
+except ZeroDivisionError as e:
+    w = Writer()
+    dis.distb(e.__traceback__, file=w)
+    assertEqual(w.out, """\
+ 71           0 LOAD_CONST               1 (1)
+              3 LOAD_CONST               2 (0)
+    -->       6 BINARY_TRUE_DIVIDE
+              7 RETURN_VALUE
+""")
+
+doc="get_instructions"
+def h(x):
+    return x
+instrs = list(dis.get_instructions(h))
+assertEqual(len(instrs), 2)
+i = instrs[0]
+assertEqual(i.opname, "LOAD_FAST")
+assertEqual(i.opcode, dis.opmap["LOAD_FAST"])
+assertEqual(i.arg, 0)
+assertEqual(i.argval, "x")
+assertEqual(i.argrepr, "x")
+assertEqual(i.offset, 0)
+assertEqual(i.starts_line, 86)
+assertEqual(i.is_jump_target, False)
+assertEqual(instrs[1].arg, None)
+assertEqual(instrs[1].starts_line, None)
+assertEqual(repr(instrs[1]), "Instruction(opname='RETURN_VALUE', opcode=83, arg=None, argval=None, argrepr='', offset=3, starts_line=None, is_jump_target=False)")
+assertEqual(list(dis.get_instructions(h, first_line=10))[0].starts_line, 11)
+
+doc="findlinestarts and findlabels"
+assertEqual(list(dis.findlinestarts(f.__code__)), [(0, 21), (6, 22), (14, 23)])
+assertEqual(dis.findlabels(f.__code__.co_code), [14])
+
+doc="opcode tables"
+assertEqual(dis.opname[dis.opmap["BINARY_ADD"]], "BINARY_ADD")
+assertEqual(dis.opname[0], "<0>")
+assertEqual(len(dis.opname), 256)
+assertEqual(dis.cmp_op[0], "<")
+assertEqual(dis.HAVE_ARGUMENT, 90)
+assertTrue(dis.opmap["LOAD_CONST"] in dis.hasconst)
+assertTrue(dis.opmap["JUMP_FORWARD"] in dis.hasjrel)
+assertTrue(dis.opmap["JUMP_ABSOLUTE"] in dis.hasjabs)
+assertTrue(dis.opmap["LOAD_FAST"] in dis.haslocal)
+
+doc="finished"
@@ -0,0 +1,283 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Enum module
+//
+// Enum, IntEnum and Flag are python classes made in Go with EnumMeta
+// as their metaclass, so the classes inheriting from them are made by
+// EnumMetaNew and their special methods are found as those of python
+// classes are.  The members are instances of the enum class holding
+// their name and value in _name_ and _value_.
+
+package enum
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const module_doc = `Enumerations: sets of symbolic names bound to unique, constant values.`
+
+var (
+	// EnumMeta is the metaclass of the enum classes
+	EnumMeta *py.Type
+
+	// Enum is the base class of enumerations
+	Enum *py.Type
+
+	// IntEnum is the base class of enumerations whose members are
+	// also ints
+	IntEnum *py.Type
+
+	// Flag is the base class of enumerations whose members can be
+	// combined with the bitwise operators
+	Flag *py.Type
+)
+
+// Returns the _name_ of a member, which is None for the members
+// of a Flag made by combining others
+func memberName(member py.Object) (py.Object, error) {
+	return py.GetAttrString(member, "_name_")
+}
+
+// Returns the _value_ of a member
+func memberValue(member py.Object) (py.Object, error) {
+	return py.GetAttrString(member, "_value_")
+}
+
+// Returns the __name__ of the class of obj
+func className(obj py.Object) string {
+	return obj.Type().Name
+}
+
+const auto_doc = `Instances are replaced with an appropriate value in Enum class suites.`
+
+var AutoType = py.NewType("auto", auto_doc)
+
+// Auto marks a member whose value is made by _generate_next_value_
+type Auto struct{}
+
+// Type of this object
+func (a *Auto) Type() *py.Type {
+	return AutoType
+}
+
+// AutoNew makes a new auto
+func AutoNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "auto", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Auto{}, nil
+}
+
+// The methods of Enum which are also those of IntEnum, as int comes
+// before Enum in its MRO
+func enumMethods() py.StringDict {
+	return py.StringDict{
+		"__repr__": py.NewGoMethod0("__repr__", func(self py.Object) (py.Object, error) {
+			name, err := memberName(self)
+			if err != nil {
+				return nil, err
+			}
+			value, err := memberValue(self)
+			if err != nil {
+				return nil, err
+			}
+			repr, err := py.ReprAsString(value)
+			if err != nil {
+				return nil, err
+			}
+			return py.String(fmt.Sprintf("<%s.%s: %s>", className(self), name, repr)), nil
+		}),
+		"__str__": py.NewGoMethod0("__str__", func(self py.Object) (py.Object, error) {
+			name, err := memberName(self)
+			if err != nil {
+				return nil, err
+			}
+			return py.String(fmt.Sprintf("%s.%s", className(self), name)), nil
+		}),
+		"__format__": py.NewGoMethod1("__format__", func(self, spec py.Object) (py.Object, error) {
+			// Members of enums mixed with another type are
+			// formatted as their value unless __str__ has
+			// been changed
+			cls := self.Type()
+			if memberType := cls.Lookup("_member_type_"); memberType != py.ObjectType && !strOverridden(cls) {
+				value, err := memberValue(self)
+				if err != nil {
+					return nil, err
+				}
+				return py.Format(value, spec)
+			}
+			str, err := py.Str(self)
+			if err != nil {
+				return nil, err
+			}
+			return py.Format(str, spec)
+		}),
+		"__hash__": py.NewGoMethod0("__hash__", func(self py.Object) (py.Object, error) {
+			name, err := memberName(self)
+			if err != nil {
+				return nil, err
+			}
+			hash, err := py.Hash(name)
+			if err != nil {
+				return nil, err
+			}
+			return py.Int(hash), nil
+		}),
+		"__reduce_ex__": py.NewGoMethod1("__reduce_ex__", func(self, protocol py.Object) (py.Object, error) {
+			value, err := memberValue(self)
+			if err != nil {
+				return nil, err
+			}
+			return py.Tuple{self.Type(), py.Tuple{value}}, nil
+		}),
+	}
+}
+
+// The methods of Enum's __str__, set in init
+var enumStrMethods []py.Object
+
+// Returns true if the __str__ of cls isn't one of Enum's
+func strOverridden(cls *py.Type) bool {
+	str := cls.Lookup("__str__")
+	for _, method := range enumStrMethods {
+		if str == method {
+			return false
+		}
+	}
+	return true
+}
+
+const generate_next_value_doc = `Generate the next value when not given.
+
+name: the name of the member
+start: the initial start value or None
+count: the number of existing members
+last_value: the last value assigned or None`
+
+func enum_generate_next_value(self py.Object, args py.Tuple) (py.Object, error) {
+	var name, start, count, lastValues py.Object
+	err := py.UnpackTuple(args, nil, "_generate_next_value_", 4, 4, &name, &start, &count, &lastValues)
+	if err != nil {
+		return nil, err
+	}
+	values, err := py.SequenceTuple(lastValues)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(values) - 1; i >= 0; i-- {
+		next, err := py.Add(values[i], py.Int(1))
+		if err == nil {
+			return next, nil
+		}
+		if !py.IsException(py.TypeError, err) {
+			return nil, err
+		}
+	}
+	return start, nil
+}
+
+func enum_missing(cls, value py.Object) (py.Object, error) {
+	return py.None, nil
+}
+
+const enum_doc = `Generic enumeration.
+
+Derive from this class to define new enumerations.`
+
+func newEnum() *py.Type {
+	dict := enumMethods()
+	dict["name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return memberName(self)
+		},
+		Doc: "The name of the Enum member.",
+	}
+	dict["value"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return memberValue(self)
+		},
+		Doc: "The value of the Enum member.",
+	}
+	dict["_generate_next_value_"] = &py.StaticMethod{
+		Callable: py.MustNewMethod("_generate_next_value_", enum_generate_next_value, 0, generate_next_value_doc),
+		Dict:     py.NewStringDict(),
+	}
+	dict["_missing_"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("_missing_", enum_missing, 0, ""),
+		Dict:     py.NewStringDict(),
+	}
+	cls := py.NewClass(EnumMeta, "enum", "Enum", enum_doc, py.Tuple{py.ObjectType}, dict)
+	setMembers(cls, py.ObjectType)
+	return cls
+}
+
+const int_enum_doc = `Enum where members are also (and must be) ints`
+
+func newIntEnum() *py.Type {
+	dict := enumMethods()
+	for name, method := range mixinMethods(py.IntType) {
+		dict[name] = method
+	}
+	cls := py.NewClass(EnumMeta, "enum", "IntEnum", int_enum_doc, py.Tuple{py.IntType, Enum}, dict)
+	setMembers(cls, py.IntType)
+	return cls
+}
+
+const unique_doc = `Class decorator for enumerations ensuring unique member values.`
+
+func enum_unique(self, enumeration py.Object) (py.Object, error) {
+	cls, ok := enumeration.(*py.Type)
+	if !ok || !cls.Type().IsSubtype(EnumMeta) {
+		return nil, py.ExceptionNewf(py.TypeError, "unique() must be called with an enum class")
+	}
+	_, byName, _ := members(cls)
+	var duplicates []string
+	for _, item := range byName.Items() {
+		name, err := memberName(item[1])
+		if err != nil {
+			return nil, err
+		}
+		if item[0] != name {
+			duplicates = append(duplicates, fmt.Sprintf("%s -> %s", item[0], name))
+		}
+	}
+	if len(duplicates) > 0 {
+		repr, err := py.ReprAsString(cls)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "duplicate values found in %s: %s", repr, strings.Join(duplicates, ", "))
+	}
+	return cls, nil
+}
+
+func init() {
+	AutoType.New = AutoNew
+	EnumMeta = newEnumMeta()
+	Enum = newEnum()
+	IntEnum = newIntEnum()
+	Flag = newFlag()
+	enumStrMethods = []py.Object{Enum.Dict["__str__"], IntEnum.Dict["__str__"], Flag.Dict["__str__"]}
+
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "enum",
+		Doc:  module_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("unique", enum_unique, 0, unique_doc),
+		},
+		Globals: py.StringDict{
+			"EnumMeta": EnumMeta,
+			"EnumType": EnumMeta,
+			"Enum":     Enum,
+			"IntEnum":  IntEnum,
+			"Flag":     Flag,
+			"auto":     AutoType,
+		},
+	})
+}
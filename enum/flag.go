@@ -0,0 +1,326 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Flag and the int methods of IntEnum
+
+package enum
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+// Returns the value of a member of a Flag as an int64
+func flagValue(member py.Object) (int64, error) {
+	value, err := memberValue(member)
+	if err != nil {
+		return 0, err
+	}
+	i, err := py.Index(value)
+	if err != nil {
+		return 0, err
+	}
+	return int64(i), nil
+}
+
+// Returns the members of the Flag cls which make up value, in
+// definition order, and the bits of value which no member covers
+func decompose(cls *py.Type, value int64) ([]py.Object, int64, error) {
+	type flagMember struct {
+		member py.Object
+		value  int64
+		order  int // position in the definition
+	}
+	var found []flagMember
+	notCovered := value
+	list := memberList(cls)
+	for i, member := range list {
+		v, err := flagValue(member)
+		if err != nil {
+			return nil, 0, err
+		}
+		if v != 0 && v&value == v {
+			found = append(found, flagMember{member, v, i})
+			notCovered &^= v
+		}
+	}
+	// Members made by combining others go after those defined
+	_, _, byValue := members(cls)
+	if value >= 0 {
+		for tmp := notCovered; tmp != 0; {
+			flag := int64(1) << uint(63-bits.LeadingZeros64(uint64(tmp)))
+			if member, ok, _ := byValue.Get(py.Int(flag)); ok {
+				found = append(found, flagMember{member, flag, len(list)})
+				notCovered &^= flag
+			}
+			tmp &^= flag
+		}
+	}
+	if len(found) == 0 {
+		if member, ok, _ := byValue.Get(py.Int(value)); ok {
+			found = append(found, flagMember{member, value, len(list)})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].value > found[j].value
+	})
+	if len(found) > 1 && found[0].value == value {
+		// The members make it up so the member with the whole
+		// value isn't needed
+		found = found[1:]
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].order != found[j].order {
+			return found[i].order < found[j].order
+		}
+		return found[i].value < found[j].value
+	})
+	res := make([]py.Object, len(found))
+	for i := range found {
+		res[i] = found[i].member
+	}
+	return res, notCovered, nil
+}
+
+// Returns the names of the members joined with |, using the value of
+// those without a name
+func joinFlags(members []py.Object) (string, error) {
+	var names []string
+	for _, member := range members {
+		name, err := memberName(member)
+		if err != nil {
+			return "", err
+		}
+		if name == py.None {
+			name, err = memberValue(member)
+			if err != nil {
+				return "", err
+			}
+		}
+		s, err := py.StrAsString(name)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, s)
+	}
+	return strings.Join(names, "|"), nil
+}
+
+// Returns the name of a member of a Flag as shown by repr and str,
+// which combines those of its members if it doesn't have one
+func flagName(self py.Object) (string, error) {
+	name, err := memberName(self)
+	if err != nil {
+		return "", err
+	}
+	if name != py.None {
+		return py.StrAsString(name)
+	}
+	value, err := flagValue(self)
+	if err != nil {
+		return "", err
+	}
+	members, _, err := decompose(self.Type(), value)
+	if err != nil {
+		return "", err
+	}
+	return joinFlags(members)
+}
+
+func flag_generate_next_value(self py.Object, args py.Tuple) (py.Object, error) {
+	var name, start, count, lastValues py.Object
+	err := py.UnpackTuple(args, nil, "_generate_next_value_", 4, 4, &name, &start, &count, &lastValues)
+	if err != nil {
+		return nil, err
+	}
+	values, err := py.SequenceTuple(lastValues)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		if start != py.None {
+			return start, nil
+		}
+		return py.Int(1), nil
+	}
+	last := values[len(values)-1]
+	value, err := py.Index(last)
+	if err != nil {
+		repr, reprErr := py.ReprAsString(last)
+		if reprErr != nil {
+			return nil, reprErr
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "Invalid Flag value: %s", repr)
+	}
+	highBit := 63 - bits.LeadingZeros64(uint64(value))
+	return py.Int(1) << uint(highBit+1), nil
+}
+
+// Makes the member of a Flag with a value which combines those of its
+// members
+func flag_missing(clsObj, valueObj py.Object) (py.Object, error) {
+	cls := clsObj.(*py.Type)
+	i, err := py.Index(valueObj)
+	if err != nil {
+		return py.None, nil
+	}
+	value := int64(i)
+	if value < 0 {
+		value = ^value
+	}
+	_, _, byValue := members(cls)
+	member, found, err := byValue.Get(py.Int(value))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		_, extra, err := decompose(cls, value)
+		if err != nil {
+			return nil, err
+		}
+		if extra != 0 {
+			return py.None, nil
+		}
+		member, err = py.ObjectNew(cls, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		dict := member.(*py.Type).Dict
+		dict["_name_"] = py.None
+		dict["_value_"] = py.Int(value)
+		err = byValue.Set(py.Int(value), member)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if int64(i) < 0 {
+		return py.Invert(member)
+	}
+	return member, nil
+}
+
+// Makes a method for the binary operator name which combines the
+// values of two members of the same Flag with op
+func flagOp(name string, op func(a, b int64) int64) *py.GoMethod {
+	return py.NewGoMethod1(name, func(self, other py.Object) (py.Object, error) {
+		if !other.Type().IsSubtype(self.Type()) {
+			return py.NotImplemented, nil
+		}
+		a, err := flagValue(self)
+		if err != nil {
+			return nil, err
+		}
+		b, err := flagValue(other)
+		if err != nil {
+			return nil, err
+		}
+		return py.Call(self.Type(), py.Tuple{py.Int(op(a, b))}, nil)
+	})
+}
+
+const flag_doc = `Support for flags`
+
+func newFlag() *py.Type {
+	dict := enumMethods()
+	dict["__repr__"] = py.NewGoMethod0("__repr__", func(self py.Object) (py.Object, error) {
+		name, err := flagName(self)
+		if err != nil {
+			return nil, err
+		}
+		value, err := memberValue(self)
+		if err != nil {
+			return nil, err
+		}
+		repr, err := py.ReprAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("<%s.%s: %s>", className(self), name, repr)), nil
+	})
+	dict["__str__"] = py.NewGoMethod0("__str__", func(self py.Object) (py.Object, error) {
+		name, err := flagName(self)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("%s.%s", className(self), name)), nil
+	})
+	dict["__bool__"] = py.NewGoMethod0("__bool__", func(self py.Object) (py.Object, error) {
+		value, err := flagValue(self)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(value != 0), nil
+	})
+	dict["__contains__"] = py.NewGoMethod1("__contains__", func(self, other py.Object) (py.Object, error) {
+		if !other.Type().IsSubtype(self.Type()) {
+			return nil, py.ExceptionNewf(py.TypeError, "unsupported operand type(s) for 'in': '%s' and '%s'", other.Type().Name, self.Type().Name)
+		}
+		a, err := flagValue(self)
+		if err != nil {
+			return nil, err
+		}
+		b, err := flagValue(other)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(a&b == b), nil
+	})
+	dict["__iter__"] = py.NewGoMethod0("__iter__", func(self py.Object) (py.Object, error) {
+		// The members with a single bit which make up self in
+		// definition order
+		value, err := flagValue(self)
+		if err != nil {
+			return nil, err
+		}
+		var res []py.Object
+		for _, member := range memberList(self.Type()) {
+			v, err := flagValue(member)
+			if err != nil {
+				return nil, err
+			}
+			if v > 0 && v&(v-1) == 0 && v&value == v {
+				res = append(res, member)
+			}
+		}
+		return py.NewIterator(res), nil
+	})
+	dict["__or__"] = flagOp("__or__", func(a, b int64) int64 { return a | b })
+	dict["__and__"] = flagOp("__and__", func(a, b int64) int64 { return a & b })
+	dict["__xor__"] = flagOp("__xor__", func(a, b int64) int64 { return a ^ b })
+	dict["__invert__"] = py.NewGoMethod0("__invert__", func(self py.Object) (py.Object, error) {
+		// The inverse is made of the members which have none of
+		// the bits of self
+		cls := self.Type()
+		value, err := flagValue(self)
+		if err != nil {
+			return nil, err
+		}
+		var inverted int64
+		for _, member := range memberList(cls) {
+			v, err := flagValue(member)
+			if err != nil {
+				return nil, err
+			}
+			if v&value == 0 {
+				inverted |= v
+			}
+		}
+		return py.Call(cls, py.Tuple{py.Int(inverted)}, nil)
+	})
+	dict["_generate_next_value_"] = &py.StaticMethod{
+		Callable: py.MustNewMethod("_generate_next_value_", flag_generate_next_value, 0, generate_next_value_doc),
+		Dict:     py.NewStringDict(),
+	}
+	dict["_missing_"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("_missing_", flag_missing, 0, ""),
+		Dict:     py.NewStringDict(),
+	}
+	cls := py.NewClass(EnumMeta, "enum", "Flag", flag_doc, py.Tuple{Enum}, dict)
+	setMembers(cls, py.ObjectType)
+	return cls
+}
@@ -0,0 +1,548 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The metaclass of the enum classes
+
+package enum
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const enum_meta_doc = `Metaclass for Enum`
+
+// Gives cls no members yet, with memberType as the type its members
+// are also instances of
+func setMembers(cls *py.Type, memberType *py.Type) {
+	cls.Dict["_member_names_"] = py.NewList()
+	cls.Dict["_member_map_"] = py.NewDict()
+	cls.Dict["_value2member_map_"] = py.NewDict()
+	cls.Dict["_member_type_"] = memberType
+}
+
+// Returns the names of the members of cls in definition order not
+// including aliases, the members of cls by name including aliases and
+// the members by value
+func members(cls *py.Type) (names *py.List, byName, byValue *py.Dict) {
+	names, _ = cls.Dict["_member_names_"].(*py.List)
+	byName, _ = cls.Dict["_member_map_"].(*py.Dict)
+	byValue, _ = cls.Dict["_value2member_map_"].(*py.Dict)
+	if names == nil || byName == nil || byValue == nil {
+		return py.NewList(), py.NewDict(), py.NewDict()
+	}
+	return names, byName, byValue
+}
+
+// Returns the members of cls in definition order not including
+// aliases
+func memberList(cls *py.Type) py.Tuple {
+	names, byName, _ := members(cls)
+	res := make(py.Tuple, 0, len(names.Items))
+	for _, name := range names.Items {
+		if member, found, _ := byName.Get(name); found {
+			res = append(res, member)
+		}
+	}
+	return res
+}
+
+// Returns true if name is of the form __name__
+func isDunder(name string) bool {
+	return len(name) > 4 && strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__") && name[2] != '_' && name[len(name)-3] != '_'
+}
+
+// Returns true if name is of the form _name_
+func isSunder(name string) bool {
+	return len(name) > 2 && name[0] == '_' && name[len(name)-1] == '_' && name[1] != '_' && name[len(name)-2] != '_'
+}
+
+// Returns true if obj is a descriptor, so isn't made a member
+func isDescriptor(obj py.Object) bool {
+	switch obj.(type) {
+	case py.I__get__, py.I__set__, py.I__delete__:
+		return true
+	}
+	t := obj.Type()
+	return t.Lookup("__get__") != nil || t.Lookup("__set__") != nil || t.Lookup("__delete__") != nil
+}
+
+// Returns the type of the members other than the enum class, eg int
+// for IntEnum
+func mixins(bases py.Tuple) (*py.Type, error) {
+	memberType := py.ObjectType
+	for _, baseObj := range bases {
+		base, ok := baseObj.(*py.Type)
+		if !ok {
+			continue
+		}
+		if base.Type().IsSubtype(EnumMeta) {
+			names, _, _ := members(base)
+			if len(names.Items) > 0 {
+				return nil, py.ExceptionNewf(py.TypeError, "Cannot extend enumerations")
+			}
+		}
+		for _, t := range base.Mro {
+			t := t.(*py.Type)
+			if t.Type().IsSubtype(EnumMeta) || t == py.ObjectType {
+				if inherited, ok := t.Dict["_member_type_"].(*py.Type); ok && inherited != py.ObjectType && memberType == py.ObjectType {
+					memberType = inherited
+				}
+				continue
+			}
+			if memberType == py.ObjectType {
+				memberType = t
+			}
+			break
+		}
+	}
+	return memberType, nil
+}
+
+// EnumMetaNew makes a new enum class, making the members from the
+// names of its body which aren't descriptors or special names
+func EnumMetaNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var nameObj, basesObj, nsObj py.Object
+	err := py.ParseTuple(args, "UOO:EnumMeta", &nameObj, &basesObj, &nsObj)
+	if err != nil {
+		return nil, err
+	}
+	bases, ok := basesObj.(py.Tuple)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "EnumMeta() argument 2 must be tuple, not %s", basesObj.Type().Name)
+	}
+	var items []py.Tuple
+	switch ns := nsObj.(type) {
+	case *py.Dict:
+		items = ns.Items()
+	case py.StringDict:
+		items = ns.Items()
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "EnumMeta() argument 3 must be dict, not %s", nsObj.Type().Name)
+	}
+	memberType, err := mixins(bases)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the body into the members and the rest of the class
+	classDict := py.NewStringDict()
+	var names []string
+	var values []py.Object
+	for _, item := range items {
+		key := string(item[0].(py.String))
+		value := item[1]
+		switch {
+		case isDunder(key) || isDescriptor(value):
+			classDict[key] = value
+		case isSunder(key):
+			switch key {
+			case "_order_", "_ignore_", "_generate_next_value_", "_missing_":
+			default:
+				return nil, py.ExceptionNewf(py.ValueError, "_names_ are reserved for future Enum use")
+			}
+			classDict[key] = value
+		case key == "mro":
+			return nil, py.ExceptionNewf(py.ValueError, "Invalid enum member name: %s", key)
+		default:
+			names = append(names, key)
+			values = append(values, value)
+		}
+	}
+	// A __new__ is used to make the members, and lookups use the
+	// __call__ of the metaclass
+	if newMember, ok := classDict["__new__"]; ok {
+		delete(classDict, "__new__")
+		classDict["_new_member_"] = newMember
+	}
+
+	clsObj, err := py.TypeNew(metatype, py.Tuple{nameObj, bases, classDict}, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	cls := clsObj.(*py.Type)
+	setMembers(cls, memberType)
+	if isBuiltinMixin(memberType) {
+		addMixinMethods(cls, memberType)
+	}
+
+	lastValues := py.NewList()
+	for i, name := range names {
+		value := values[i]
+		if _, ok := value.(*Auto); ok {
+			generate, err := py.GetAttrString(cls, "_generate_next_value_")
+			if err != nil {
+				return nil, err
+			}
+			memberNames, _, _ := members(cls)
+			value, err = py.Call(generate, py.Tuple{py.String(name), py.Int(1), py.Int(len(memberNames.Items)), lastValues.Copy()}, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		lastValues.Append(value)
+		err = addMember(cls, memberType, name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Check the members are in the order given by _order_
+	if order, ok := classDict["_order_"]; ok {
+		orderStr, err := py.StrAsString(order)
+		if err != nil {
+			return nil, err
+		}
+		memberNames, _, _ := members(cls)
+		var got []string
+		for _, name := range memberNames.Items {
+			got = append(got, string(name.(py.String)))
+		}
+		if strings.Join(strings.Fields(strings.Replace(orderStr, ",", " ", -1)), " ") != strings.Join(got, " ") {
+			return nil, py.ExceptionNewf(py.TypeError, "member order does not match _order_")
+		}
+	}
+	cls.Modified()
+	return cls, nil
+}
+
+// Makes the member of cls called name from value, or makes name an
+// alias of an existing member with the same value
+func addMember(cls *py.Type, memberType *py.Type, name string, value py.Object) error {
+	args := py.Tuple{value}
+	if tuple, ok := value.(py.Tuple); ok {
+		args = tuple
+	}
+	var member py.Object
+	var err error
+	newMember := cls.Lookup("_new_member_")
+	if newMember != nil {
+		member, err = py.Call(newMember, append(py.Tuple{cls}, args...), nil)
+	} else {
+		member, err = py.ObjectNew(cls, nil, nil)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := memberValue(member); err != nil {
+		if !py.IsException(py.AttributeError, err) {
+			return err
+		}
+		if memberType != py.ObjectType {
+			value, err = py.Call(memberType, args, nil)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = py.SetAttrString(member, "_value_", value)
+		if err != nil {
+			return err
+		}
+	}
+	value, err = memberValue(member)
+	if err != nil {
+		return err
+	}
+	_, err = py.SetAttrString(member, "_name_", py.String(name))
+	if err != nil {
+		return err
+	}
+	if cls.Init != nil {
+		err = cls.Init(member, args, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// A member with the value of an earlier one is an alias of it
+	names, byName, byValue := members(cls)
+	alias := false
+	for _, existing := range byName.Values() {
+		existingValue, err := memberValue(existing)
+		if err != nil {
+			return err
+		}
+		eq, err := py.Eq(existingValue, value)
+		if err != nil {
+			return err
+		}
+		if eq == py.True {
+			member = existing
+			alias = true
+			break
+		}
+	}
+	if !alias {
+		names.Append(py.String(name))
+	}
+	cls.Dict[name] = member
+	err = byName.Set(py.String(name), member)
+	if err != nil {
+		return err
+	}
+	// Unhashable values are only found by looking through the
+	// members
+	err = byValue.Set(value, member)
+	if err != nil && !py.IsException(py.TypeError, err) {
+		return err
+	}
+	return nil
+}
+
+// Returns the member of cls with value
+func lookup(cls *py.Type, value py.Object) (py.Object, error) {
+	if value.Type() == cls {
+		return value, nil
+	}
+	_, byName, byValue := members(cls)
+	member, found, err := byValue.Get(value)
+	if err != nil && !py.IsException(py.TypeError, err) {
+		return nil, err
+	}
+	if found {
+		return member, nil
+	}
+	for _, member := range byName.Values() {
+		memberValue, err := memberValue(member)
+		if err != nil {
+			return nil, err
+		}
+		eq, err := py.Eq(memberValue, value)
+		if err != nil {
+			return nil, err
+		}
+		if eq == py.True {
+			return member, nil
+		}
+	}
+
+	// Give the class a chance to find or make the member
+	missing, err := py.GetAttrString(cls, "_missing_")
+	if err != nil {
+		return nil, err
+	}
+	res, err := py.Call(missing, py.Tuple{value}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.Type().IsSubtype(cls) {
+		return res, nil
+	}
+	if res != py.None {
+		repr, err := py.ReprAsString(res)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "error in %s._missing_: returned %s instead of None or a valid member", cls.Name, repr)
+	}
+	repr, err := py.ReprAsString(value)
+	if err != nil {
+		return nil, err
+	}
+	return nil, py.ExceptionNewf(py.ValueError, "%s is not a valid %s", repr, cls.Name)
+}
+
+// Makes a new enum class called name which inherits from cls with
+// members from names, which may be a string of names, a sequence of
+// names or of (name, value) pairs, or a mapping of names to values
+func create(cls *py.Type, name, names, module, qualname, typ, start py.Object) (py.Object, error) {
+	bases := py.Tuple{cls}
+	if typ != py.None {
+		bases = py.Tuple{typ, cls}
+	}
+	if s, ok := names.(py.String); ok {
+		var list []py.Object
+		for _, field := range strings.Fields(strings.Replace(string(s), ",", " ", -1)) {
+			list = append(list, py.String(field))
+		}
+		names = py.NewListFromItems(list)
+	}
+	var items []py.Tuple
+	switch x := names.(type) {
+	case *py.Dict:
+		items = x.Items()
+	case py.StringDict:
+		items = x.Items()
+	default:
+		seq, err := py.SequenceTuple(names)
+		if err != nil {
+			return nil, err
+		}
+		generate, err := py.GetAttrString(cls, "_generate_next_value_")
+		if err != nil {
+			return nil, err
+		}
+		var lastValues py.Tuple
+		for i, item := range seq {
+			if memberName, ok := item.(py.String); ok {
+				value, err := py.Call(generate, py.Tuple{memberName, start, py.Int(i), lastValues.Copy()}, nil)
+				if err != nil {
+					return nil, err
+				}
+				lastValues = append(lastValues, value)
+				items = append(items, py.Tuple{memberName, value})
+				continue
+			}
+			pair, err := py.SequenceTuple(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(pair) != 2 {
+				return nil, py.ExceptionNewf(py.TypeError, "enum members must be names or (name, value) pairs")
+			}
+			items = append(items, pair)
+		}
+	}
+	ns := py.NewDict()
+	for _, item := range items {
+		if _, ok := item[0].(py.String); !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "enum member names must be strings, not %s", item[0].Type().Name)
+		}
+		err := ns.Set(item[0], item[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if module == py.None {
+		module = py.String("__main__")
+	}
+	if qualname == py.None {
+		qualname = name
+	}
+	_ = ns.Set(py.String("__module__"), module)
+	_ = ns.Set(py.String("__qualname__"), qualname)
+	return py.Call(cls.Type(), py.Tuple{name, bases, ns}, nil)
+}
+
+// Returns self as an enum class
+func enumClass(self py.Object) *py.Type {
+	return self.(*py.Type)
+}
+
+// Returns the member of the enum class self with the value given, or
+// makes a new enum class with the functional API, eg
+// Enum('Color', names='RED GREEN BLUE')
+func enumMetaCall(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	cls := enumClass(self)
+	var value py.Object
+	var names, module, qualname, typ, start py.Object = py.None, py.None, py.None, py.None, py.Int(1)
+	err := py.UnpackTuple(args, nil, "__call__", 1, 2, &value, &names)
+	if err != nil {
+		return nil, err
+	}
+	var kwNames py.Object = py.None
+	err = py.ParseTupleAndKeywords(nil, kwargs, "|$OOOOO:__call__", []string{"names", "module", "qualname", "type", "start"}, &kwNames, &module, &qualname, &typ, &start)
+	if err != nil {
+		return nil, err
+	}
+	if names == py.None {
+		names = kwNames
+	}
+	if names == py.None {
+		return lookup(cls, value)
+	}
+	return create(cls, value, names, module, qualname, typ, start)
+}
+
+func newEnumMeta() *py.Type {
+	dict := py.StringDict{
+		"__call__": &py.GoMethod{Name: "__call__", Fn: enumMetaCall},
+		"__getitem__": py.NewGoMethod1("__getitem__", func(self, name py.Object) (py.Object, error) {
+			_, byName, _ := members(enumClass(self))
+			member, found, err := byName.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{name}}
+			}
+			return member, nil
+		}),
+		"__iter__": py.NewGoMethod0("__iter__", func(self py.Object) (py.Object, error) {
+			return py.Iter(memberList(enumClass(self)))
+		}),
+		"__reversed__": py.NewGoMethod0("__reversed__", func(self py.Object) (py.Object, error) {
+			list := memberList(enumClass(self))
+			res := make(py.Tuple, len(list))
+			for i, member := range list {
+				res[len(list)-1-i] = member
+			}
+			return py.Iter(res)
+		}),
+		"__len__": py.NewGoMethod0("__len__", func(self py.Object) (py.Object, error) {
+			names, _, _ := members(enumClass(self))
+			return py.Int(len(names.Items)), nil
+		}),
+		"__bool__": py.NewGoMethod0("__bool__", func(self py.Object) (py.Object, error) {
+			return py.True, nil
+		}),
+		"__contains__": py.NewGoMethod1("__contains__", func(self, member py.Object) (py.Object, error) {
+			cls := enumClass(self)
+			if !member.Type().IsSubtype(Enum) {
+				return nil, py.ExceptionNewf(py.TypeError, "unsupported operand type(s) for 'in': '%s' and '%s'", member.Type().Name, cls.Type().Name)
+			}
+			if !member.Type().IsSubtype(cls) {
+				return py.False, nil
+			}
+			name, err := memberName(member)
+			if err != nil {
+				return nil, err
+			}
+			_, byName, _ := members(cls)
+			_, found, err := byName.Get(name)
+			return py.NewBool(found), err
+		}),
+		"__repr__": py.NewGoMethod0("__repr__", func(self py.Object) (py.Object, error) {
+			return py.String(fmt.Sprintf("<enum '%s'>", enumClass(self).Name)), nil
+		}),
+		"__setattr__": &py.GoMethod{Name: "__setattr__", Fn: func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var nameObj, value py.Object
+			err := py.UnpackTuple(args, kwargs, "__setattr__", 2, 2, &nameObj, &value)
+			if err != nil {
+				return nil, err
+			}
+			name, err := py.AttributeName(nameObj)
+			if err != nil {
+				return nil, err
+			}
+			cls := enumClass(self)
+			_, byName, _ := members(cls)
+			if _, found, _ := byName.Get(py.String(name)); found {
+				return nil, py.ExceptionNewf(py.AttributeError, "Cannot reassign members.")
+			}
+			return py.GenericSetAttrString(cls, name, value)
+		}},
+		"__delattr__": py.NewGoMethod1("__delattr__", func(self, nameObj py.Object) (py.Object, error) {
+			name, err := py.AttributeName(nameObj)
+			if err != nil {
+				return nil, err
+			}
+			cls := enumClass(self)
+			_, byName, _ := members(cls)
+			if _, found, _ := byName.Get(py.String(name)); found {
+				return nil, py.ExceptionNewf(py.AttributeError, "%s: cannot delete Enum member.", cls.Name)
+			}
+			err = py.GenericDeleteAttrString(cls, name)
+			if err != nil {
+				return nil, err
+			}
+			return py.None, nil
+		}),
+		"__members__": &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				_, byName, _ := members(enumClass(self))
+				return py.NewMappingProxy(byName.Copy()), nil
+			},
+			Doc: `Returns a mapping of member name->value.
+
+This mapping lists all enum members, including aliases. Note that this
+is a read-only view of the internal mapping.`,
+		},
+	}
+	cls := py.NewClass(py.TypeType, "enum", "EnumMeta", enum_meta_doc, py.Tuple{py.TypeType}, dict)
+	cls.New = EnumMetaNew
+	return cls
+}
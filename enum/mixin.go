@@ -0,0 +1,193 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Enums mixed with a builtin type
+//
+// The members of an enum mixed with a builtin type such as int or str
+// are instances of the enum class holding the value made by the
+// builtin type in _value_, as instances of python classes can't hold
+// the value of a builtin themselves.  So the methods of the builtin
+// are given to the enum class as methods which call them on _value_,
+// making the members act as their values.
+
+package enum
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+// Returns true if memberType is a builtin type whose methods the
+// members are given
+func isBuiltinMixin(memberType *py.Type) bool {
+	return memberType != py.ObjectType && memberType.Flags&py.TPFLAGS_HEAPTYPE == 0
+}
+
+// Returns obj or the value of obj if it is a member of an enum mixed
+// with a builtin type
+func mixinValue(obj py.Object) (py.Object, error) {
+	cls := obj.Type()
+	if !cls.Type().IsSubtype(EnumMeta) {
+		return obj, nil
+	}
+	if memberType, ok := cls.Lookup("_member_type_").(*py.Type); !ok || !isBuiltinMixin(memberType) {
+		return obj, nil
+	}
+	return memberValue(obj)
+}
+
+// The methods which make the members of an enum mixed with memberType
+// act as their values
+func mixinMethods(memberType *py.Type) py.StringDict {
+	dict := py.StringDict{}
+	for name, op := range map[string]func(a, b py.Object) (py.Object, error){
+		"add":      py.Add,
+		"sub":      py.Sub,
+		"mul":      py.Mul,
+		"truediv":  py.TrueDiv,
+		"floordiv": py.FloorDiv,
+		"mod":      py.Mod,
+		"lshift":   py.Lshift,
+		"rshift":   py.Rshift,
+		"and":      py.And,
+		"or":       py.Or,
+		"xor":      py.Xor,
+		"pow": func(a, b py.Object) (py.Object, error) {
+			return py.Pow(a, b, py.None)
+		},
+		"eq":      py.Eq,
+		"ne":      py.Ne,
+		"lt":      py.Lt,
+		"le":      py.Le,
+		"gt":      py.Gt,
+		"ge":      py.Ge,
+		"getitem": py.GetItem,
+		"contains": func(a, b py.Object) (py.Object, error) {
+			found, err := py.SequenceContains(a, b)
+			if err != nil {
+				return nil, err
+			}
+			return py.NewBool(found), nil
+		},
+	} {
+		op := op
+		call := func(a, b py.Object) (py.Object, error) {
+			a, err := mixinValue(a)
+			if err != nil {
+				return nil, err
+			}
+			b, err = mixinValue(b)
+			if err != nil {
+				return nil, err
+			}
+			return op(a, b)
+		}
+		dict["__"+name+"__"] = py.NewGoMethod1("__"+name+"__", call)
+		switch name {
+		case "eq", "ne", "lt", "le", "gt", "ge", "getitem", "contains":
+			continue
+		}
+		dict["__r"+name+"__"] = py.NewGoMethod1("__r"+name+"__", func(self, other py.Object) (py.Object, error) {
+			return call(other, self)
+		})
+	}
+	for name, op := range map[string]func(a py.Object) (py.Object, error){
+		"neg":    py.Neg,
+		"pos":    py.Pos,
+		"abs":    py.Abs,
+		"invert": py.Invert,
+		"int":    py.MakeInt,
+		"float":  py.MakeFloat,
+		"index": func(a py.Object) (py.Object, error) {
+			return py.Index(a)
+		},
+		"bool": py.MakeBool,
+		"len":  py.Len,
+		"iter": py.Iter,
+		"hash": func(a py.Object) (py.Object, error) {
+			hash, err := py.Hash(a)
+			if err != nil {
+				return nil, err
+			}
+			return py.Int(hash), nil
+		},
+	} {
+		op := op
+		dict["__"+name+"__"] = py.NewGoMethod0("__"+name+"__", func(self py.Object) (py.Object, error) {
+			value, err := mixinValue(self)
+			if err != nil {
+				return nil, err
+			}
+			return op(value)
+		})
+	}
+
+	// Leave out the container methods a value of memberType hasn't
+	// got so the members aren't containers when it isn't
+	if sample, err := py.Call(memberType, nil, nil); err == nil {
+		if _, ok := sample.(py.I__len__); !ok {
+			delete(dict, "__len__")
+		}
+		if _, ok := sample.(py.I__iter__); !ok {
+			delete(dict, "__iter__")
+		}
+		if _, ok := sample.(py.I__getitem__); !ok {
+			delete(dict, "__getitem__")
+		}
+		if _, ok := sample.(py.I__contains__); !ok {
+			delete(dict, "__contains__")
+		}
+	}
+
+	// The other methods of the builtin, such as str.startswith, are
+	// called on the value
+	for _, baseObj := range memberType.Mro {
+		base := baseObj.(*py.Type)
+		if base == py.ObjectType {
+			break
+		}
+		for name, method := range base.Dict {
+			if m, ok := method.(*py.Method); !ok || m.Flags&(py.METH_CLASS|py.METH_STATIC) != 0 || isDunder(name) {
+				continue
+			}
+			if _, ok := dict[name]; ok {
+				continue
+			}
+			name := name
+			dict[name] = py.NewGoMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+				value, err := mixinValue(self)
+				if err != nil {
+					return nil, err
+				}
+				method, err := py.GetAttrString(value, name)
+				if err != nil {
+					return nil, err
+				}
+				return py.Call(method, args, kwargs)
+			})
+		}
+	}
+	return dict
+}
+
+// Gives cls, an enum class mixed with the builtin memberType, the
+// methods of memberType which aren't overridden by the classes before
+// memberType in its MRO
+func addMixinMethods(cls *py.Type, memberType *py.Type) {
+	for name, method := range mixinMethods(memberType) {
+		overridden := false
+		for _, baseObj := range cls.Mro {
+			base := baseObj.(*py.Type)
+			if base == memberType {
+				break
+			}
+			if _, ok := base.Dict[name]; ok {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			cls.Dict[name] = method
+		}
+	}
+}
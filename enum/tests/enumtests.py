@@ -0,0 +1,299 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import enum
+from enum import Enum, IntEnum, Flag, auto, unique
+from libtest import *
+
+doc = "members"
+class Color(Enum):
+    RED = 1
+    GREEN = 2
+    BLUE = 3
+    def describe(self):
+        return self.name.lower()
+assert Color.RED.name == "RED"
+assert Color.RED.value == 1
+assert isinstance(Color.RED, Color)
+assert type(Color.GREEN) is Color
+assert repr(Color.RED) == "<Color.RED: 1>"
+assert str(Color.BLUE) == "Color.BLUE"
+assert repr(Color) == "<enum 'Color'>"
+assert Color.GREEN.describe() == "green"
+assert isinstance(Color, enum.EnumMeta)
+assert enum.EnumType is enum.EnumMeta
+
+doc = "definition order"
+class Shuffled(Enum):
+    C = 3
+    A = 1
+    B = 2
+assert [m.name for m in Shuffled] == ["C", "A", "B"]
+assert list(Color) == [Color.RED, Color.GREEN, Color.BLUE]
+assert list(Color.__reversed__()) == [Color.BLUE, Color.GREEN, Color.RED]
+assert len(Color) == 3
+assert list(Color.__members__) == ["RED", "GREEN", "BLUE"]
+assert Color.__members__["GREEN"] is Color.GREEN
+
+doc = "lookup"
+assert Color(2) is Color.GREEN
+assert Color["BLUE"] is Color.BLUE
+assert Color(Color.RED) is Color.RED
+assert getattr(Color, "RED") is Color.RED
+try:
+    Color(4)
+except ValueError as e:
+    assert str(e) == "4 is not a valid Color", str(e)
+else:
+    assert False, "ValueError not raised"
+assertRaises(KeyError, lambda: Color["PURPLE"])
+assert Color.RED in Color
+try:
+    1 in Color
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc = "identity and equality"
+assert Color.RED is Color.RED
+assert Color.RED == Color.RED
+assert Color.RED != Color.BLUE
+assert Color.RED != 1
+assert {Color.RED: "r"}[Color.RED] == "r"
+assert hash(Color.RED) == hash(Color.RED)
+assertRaises(TypeError, lambda: Color.RED < Color.BLUE)
+
+doc = "members cannot be changed"
+try:
+    Color.RED = 5
+except AttributeError as e:
+    assert str(e) == "Cannot reassign members.", str(e)
+else:
+    assert False, "AttributeError not raised"
+try:
+    del Color.RED
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+assert Color.RED.value == 1
+try:
+    class MoreColor(Color):
+        PINK = 4
+except TypeError as e:
+    assert str(e) == "Cannot extend enumerations", str(e)
+else:
+    assert False, "TypeError not raised"
+
+doc = "aliases and unique"
+class Shape(Enum):
+    SQUARE = 2
+    DIAMOND = 1
+    CIRCLE = 3
+    ALIAS_FOR_SQUARE = 2
+assert Shape.ALIAS_FOR_SQUARE is Shape.SQUARE
+assert Shape(2) is Shape.SQUARE
+assert list(Shape) == [Shape.SQUARE, Shape.DIAMOND, Shape.CIRCLE]
+assert list(Shape.__members__) == ["SQUARE", "DIAMOND", "CIRCLE", "ALIAS_FOR_SQUARE"]
+try:
+    @unique
+    class Mistake(Enum):
+        ONE = 1
+        TWO = 2
+        THREE = 3
+        FOUR = 3
+except ValueError as e:
+    assert str(e) == "duplicate values found in <enum 'Mistake'>: FOUR -> THREE", str(e)
+else:
+    assert False, "ValueError not raised"
+@unique
+class Fine(Enum):
+    ONE = 1
+    TWO = 2
+assert Fine.TWO.value == 2
+
+doc = "auto"
+class Ordinal(Enum):
+    FIRST = auto()
+    SECOND = auto()
+    TENTH = 10
+    ELEVENTH = auto()
+assert [m.value for m in Ordinal] == [1, 2, 10, 11]
+class Named(Enum):
+    def _generate_next_value_(name, start, count, last_values):
+        return name.lower()
+    NORTH = auto()
+    SOUTH = auto()
+assert Named.NORTH.value == "north"
+assert Named("south") is Named.SOUTH
+
+doc = "methods and descriptors are not members"
+class Planet(Enum):
+    MERCURY = (3.303e+23, 2.4397e6)
+    EARTH = (5.976e+24, 6.37814e6)
+    def __init__(self, mass, radius):
+        self.mass = mass
+        self.radius = radius
+    @property
+    def surface(self):
+        return self.radius * 2
+    @classmethod
+    def biggest(cls):
+        return max(cls, key=lambda p: p.mass)
+assert Planet.EARTH.mass == 5.976e+24
+assert Planet.EARTH.value == (5.976e+24, 6.37814e6)
+assert Planet.MERCURY.surface == 2.4397e6 * 2
+assert Planet.biggest() is Planet.EARTH
+assert len(Planet) == 2
+
+doc = "_missing_"
+class Status(Enum):
+    ON = "on"
+    OFF = "off"
+    @classmethod
+    def _missing_(cls, value):
+        if isinstance(value, str):
+            return cls(value.lower())
+assert Status("ON") is Status.ON
+assertRaises(ValueError, Status, 1)
+
+doc = "invalid names"
+try:
+    class Bad(Enum):
+        _sunder_ = 1
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+
+doc = "IntEnum"
+class Number(IntEnum):
+    ONE = 1
+    TWO = 2
+    THREE = 3
+assert isinstance(Number.ONE, int)
+assert Number.ONE == 1
+assert 2 == Number.TWO
+assert Number.TWO + 1 == 3
+assert 1 + Number.TWO == 3
+assert Number.THREE * 2 == 6
+assert Number.THREE - Number.ONE == 2
+assert Number.ONE < Number.TWO
+assert Number.THREE >= 3
+assert -Number.ONE == -1
+assert Number.TWO | 1 == 3
+assert int(Number.THREE) == 3
+assert [0, 10, 20][Number.TWO] == 20
+assert {1: "one"}[Number.ONE] == "one"
+assert sorted([Number.THREE, Number.ONE]) == [Number.ONE, Number.THREE]
+assert repr(Number.TWO) == "<Number.TWO: 2>"
+assert str(Number.TWO) == "Number.TWO"
+assert format(Number.TWO) == "2"
+assert "{:>3}".format(Number.ONE) == "  1"
+assert Number(3) is Number.THREE
+assertRaises(ValueError, Number, "x")
+
+doc = "Flag"
+class Perm(Flag):
+    R = 4
+    W = 2
+    X = 1
+RW = Perm.R | Perm.W
+assert RW.value == 6
+assert repr(RW) == "<Perm.R|W: 6>", repr(RW)
+assert str(RW) == "Perm.R|W"
+assert Perm.R in RW
+assert Perm.X not in RW
+assert RW & Perm.R is Perm.R
+assert (RW ^ Perm.R) is Perm.W
+assert Perm(6) is RW
+assert Perm.R
+assert not Perm(0)
+assert repr(Perm(0)) == "<Perm.0: 0>"
+assert ~Perm.R == Perm.W | Perm.X
+assert list(Perm) == [Perm.R, Perm.W, Perm.X]
+assertRaises(ValueError, Perm, 8)
+try:
+    Perm.R | 1
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+class Auto(Flag):
+    A = auto()
+    B = auto()
+    C = auto()
+    AB = 3
+assert [m.value for m in Auto] == [1, 2, 4, 3]
+assert Auto.AB is Auto.A | Auto.B
+
+doc = "Flag order"
+class Color(Flag):
+    RED = 1
+    GREEN = 2
+    BLUE = 4
+    WHITE = 7
+assert str(Color.RED | Color.BLUE) == "Color.RED|BLUE"
+assert repr(Color.BLUE | Color.GREEN) == "<Color.GREEN|BLUE: 6>"
+assert str(Color(7)) == "Color.WHITE"
+assert list(Color.RED | Color.BLUE) == [Color.RED, Color.BLUE]
+assert list(Color.WHITE) == [Color.RED, Color.GREEN, Color.BLUE]
+assert list(Color(0)) == []
+assert list(Perm.R | Perm.X) == [Perm.R, Perm.X]
+
+doc = "functional API"
+Animal = Enum("Animal", "ANT BEE CAT")
+assert [m.value for m in Animal] == [1, 2, 3]
+assert Animal.BEE.name == "BEE"
+assert repr(Animal.CAT) == "<Animal.CAT: 3>"
+Letters = Enum("Letters", ["A", "B"], start=10)
+assert Letters.B.value == 11
+Pairs = Enum("Pairs", [("X", "x"), ("Y", "y")])
+assert Pairs("y") is Pairs.Y
+Mapped = IntEnum("Mapped", {"LOW": 1, "HIGH": 9}, module="mymod")
+assert Mapped.HIGH == 9
+assert Mapped.__module__ == "mymod"
+Bits = Flag("Bits", "ONE TWO FOUR")
+assert [m.value for m in Bits] == [1, 2, 4]
+
+doc = "str mixin"
+class Sym(str, Enum):
+    A = "ab"
+    B = "cd"
+assert isinstance(Sym.A, str)
+assert Sym.A.startswith("a") and not Sym.A.startswith("x")
+assert Sym.A.upper() == "AB"
+assert Sym.A + "y" == "aby"
+assert "y" + Sym.A == "yab"
+assert Sym.A * 2 == "abab"
+assert len(Sym.A) == 2
+assert Sym.A[1] == "b"
+assert "a" in Sym.A
+assert list(Sym.B) == ["c", "d"]
+assert Sym.A == "ab" and Sym.A != "cd"
+assert Sym.A < "b" and Sym.B > "b"
+assert {"ab": 1}[Sym.A] == 1
+assert str(Sym.A) == "Sym.A"
+assert Sym("cd") is Sym.B
+
+doc = "int mixin"
+class Num(int, Enum):
+    ONE = 1
+    THREE = 3
+    def bit_length(self):
+        return "overridden"
+assert isinstance(Num.ONE, int)
+assert Num.THREE + 1 == 4 and 1 + Num.THREE == 4
+assert Num.THREE * Num.THREE == 9
+assert -Num.ONE == -1
+assert Num.THREE == 3 and Num.THREE != 1
+assert Num.ONE < 2 and Num.THREE >= 3
+assert ["a", "b", "c", "d"][Num.THREE] == "d"
+assert {3: "x"}[Num.THREE] == "x"
+assert Num.THREE.to_bytes(1, "big") == b"\x03"
+assert Num.ONE.bit_length() == "overridden"
+assertRaises(TypeError, len, Num.ONE)
+
+doc = "finished"
@@ -0,0 +1,617 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Fractions module
+//
+// Fraction is implemented with a big.Rat so is always in lowest
+// terms with a positive denominator.
+
+package fractions
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/go-python/gpython/abc"
+	"github.com/go-python/gpython/numbers"
+	"github.com/go-python/gpython/py"
+)
+
+var FractionType = py.NewTypeX("Fraction", `This class implements rational numbers.
+
+In the two-argument form of the constructor, Fraction(8, 6) will
+produce a rational number equivalent to 4/3. Both arguments must
+be Rational. The numerator defaults to 0 and the denominator
+defaults to 1 so that Fraction(3) == 3 and Fraction() == 0.
+
+Fractions can also be constructed from:
+
+  - numeric strings similar to those accepted by the
+    float constructor (for example, '-2.3' or '1e10')
+
+  - strings of the form '123/456'
+
+  - float instances`, FractionNew, nil)
+
+// A python Fraction object
+type Fraction big.Rat
+
+// Type of this Fraction object
+func (a *Fraction) Type() *py.Type {
+	return FractionType
+}
+
+// NewFraction makes a new Fraction from numerator and denominator
+//
+// The denominator must not be zero
+func NewFraction(numerator, denominator int64) *Fraction {
+	return (*Fraction)(big.NewRat(numerator, denominator))
+}
+
+// Returns the Fraction as a *big.Rat
+func (a *Fraction) rat() *big.Rat {
+	return (*big.Rat)(a)
+}
+
+// Returns the Fraction as a Float
+func (a *Fraction) float() py.Float {
+	f, _ := a.rat().Float64()
+	return py.Float(f)
+}
+
+// Returns a python int from a *big.Int
+func intFromBig(x *big.Int) py.Object {
+	return (*py.BigInt)(x).MaybeInt()
+}
+
+// Convert an Object to a *big.Rat
+//
+// Returns ok as to whether the conversion worked or not.  Only
+// integers and Fractions are converted - floats must be treated
+// separately.
+func convertToRat(other py.Object) (*big.Rat, bool) {
+	switch b := other.(type) {
+	case *Fraction:
+		return b.rat(), true
+	case py.Int, *py.BigInt, py.Bool:
+		x, _ := py.ConvertToBigInt(b)
+		return new(big.Rat).SetInt((*big.Int)(x)), true
+	}
+	return nil, false
+}
+
+// Makes an exact Fraction from a float
+func fractionFromFloat(f py.Float) (*Fraction, error) {
+	if math.IsInf(float64(f), 0) {
+		return nil, py.ExceptionNewf(py.OverflowError, "Cannot convert %v to Fraction.", f)
+	}
+	if math.IsNaN(float64(f)) {
+		return nil, py.ExceptionNewf(py.ValueError, "Cannot convert nan to Fraction.")
+	}
+	return (*Fraction)(new(big.Rat).SetFloat64(float64(f))), nil
+}
+
+// Matches the string forms of a Fraction
+var rationalFormat = regexp.MustCompile(`^([-+]?)(\d*)(?:/(\d+)|(?:\.(\d*))?(?:[eE]([-+]?\d+))?)$`)
+
+// Parses a string into a Fraction
+func fractionFromString(s string) (*Fraction, error) {
+	m := rationalFormat.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || (m[2] == "" && m[4] == "") {
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid literal for Fraction: '%s'", s)
+	}
+	sign, num, denom, decimal, exp := m[1], m[2], m[3], m[4], m[5]
+	n, _ := new(big.Int).SetString("0"+num+decimal, 10)
+	d := big.NewInt(1)
+	if denom != "" {
+		d.SetString(denom, 10)
+		if d.Sign() == 0 {
+			return nil, py.ExceptionNewf(py.ZeroDivisionError, "Fraction(%s, 0)", n)
+		}
+	}
+	ten := big.NewInt(10)
+	d.Mul(d, new(big.Int).Exp(ten, big.NewInt(int64(len(decimal))), nil))
+	if exp != "" {
+		e, ok := new(big.Int).SetString(exp, 10)
+		if !ok || !e.IsInt64() || e.Int64() > math.MaxInt32 || e.Int64() < math.MinInt32 {
+			return nil, py.ExceptionNewf(py.ValueError, "Invalid literal for Fraction: '%s'", s)
+		}
+		if e.Sign() >= 0 {
+			n.Mul(n, new(big.Int).Exp(ten, e, nil))
+		} else {
+			d.Mul(d, new(big.Int).Exp(ten, e.Neg(e), nil))
+		}
+	}
+	if sign == "-" {
+		n.Neg(n)
+	}
+	return (*Fraction)(new(big.Rat).SetFrac(n, d)), nil
+}
+
+// FractionNew makes a new Fraction
+func FractionNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var numerator py.Object = py.Int(0)
+	var denominator py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:Fraction", []string{"numerator", "denominator"}, &numerator, &denominator)
+	if err != nil {
+		return nil, err
+	}
+	if denominator == py.None {
+		if n, ok := convertToRat(numerator); ok {
+			return (*Fraction)(new(big.Rat).Set(n)), nil
+		}
+		switch n := numerator.(type) {
+		case py.Float:
+			return fractionFromFloat(n)
+		case py.String:
+			return fractionFromString(string(n))
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "argument should be a string or a Rational instance")
+	}
+	n, ok := convertToRat(numerator)
+	d, ok2 := convertToRat(denominator)
+	if !ok || !ok2 {
+		return nil, py.ExceptionNewf(py.TypeError, "both arguments should be Rational instances")
+	}
+	if d.Sign() == 0 {
+		return nil, py.ExceptionNewf(py.ZeroDivisionError, "Fraction(%s, 0)", n.RatString())
+	}
+	return (*Fraction)(new(big.Rat).Quo(n, d)), nil
+}
+
+func (a *Fraction) M__str__() (py.Object, error) {
+	return py.String(a.rat().RatString()), nil
+}
+
+func (a *Fraction) M__repr__() (py.Object, error) {
+	return py.String("Fraction(" + a.rat().Num().String() + ", " + a.rat().Denom().String() + ")"), nil
+}
+
+// Arithmetic
+
+// Runs ratOp if other is an integer or a Fraction, otherwise floatOp
+// with the Fraction converted to a float if other is a float or a
+// complex.  The arguments are swapped if reversed is set.
+func (a *Fraction) binaryOp(other py.Object, reversed bool, ratOp func(x, y *big.Rat) (py.Object, error), floatOp func(x, y py.Object) (py.Object, error)) (py.Object, error) {
+	if b, ok := convertToRat(other); ok {
+		if reversed {
+			return ratOp(b, a.rat())
+		}
+		return ratOp(a.rat(), b)
+	}
+	switch other.(type) {
+	case py.Float, py.Complex:
+		if reversed {
+			return floatOp(other, a.float())
+		}
+		return floatOp(a.float(), other)
+	}
+	return py.NotImplemented, nil
+}
+
+// Returns a ZeroDivisionError for x / 0
+func zeroDivision(x *big.Rat) error {
+	return py.ExceptionNewf(py.ZeroDivisionError, "Fraction(%s, 0)", x.RatString())
+}
+
+func ratAdd(x, y *big.Rat) (py.Object, error) {
+	return (*Fraction)(new(big.Rat).Add(x, y)), nil
+}
+
+func ratSub(x, y *big.Rat) (py.Object, error) {
+	return (*Fraction)(new(big.Rat).Sub(x, y)), nil
+}
+
+func ratMul(x, y *big.Rat) (py.Object, error) {
+	return (*Fraction)(new(big.Rat).Mul(x, y)), nil
+}
+
+func ratTrueDiv(x, y *big.Rat) (py.Object, error) {
+	if y.Sign() == 0 {
+		return nil, zeroDivision(x)
+	}
+	return (*Fraction)(new(big.Rat).Quo(x, y)), nil
+}
+
+// Returns floor(x / y) and x - y*floor(x / y)
+func ratDivMod(x, y *big.Rat) (*big.Int, *big.Rat, error) {
+	if y.Sign() == 0 {
+		return nil, nil, zeroDivision(x)
+	}
+	// (a/b) / (c/d) = (a*d) / (b*c)
+	n := new(big.Int).Mul(x.Num(), y.Denom())
+	d := new(big.Int).Mul(x.Denom(), y.Num())
+	q := floorDiv(n, d)
+	r := new(big.Rat).Mul(y, new(big.Rat).SetInt(q))
+	r.Sub(x, r)
+	return q, r, nil
+}
+
+func ratFloorDiv(x, y *big.Rat) (py.Object, error) {
+	q, _, err := ratDivMod(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return intFromBig(q), nil
+}
+
+func ratMod(x, y *big.Rat) (py.Object, error) {
+	_, r, err := ratDivMod(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return (*Fraction)(r), nil
+}
+
+func ratDivModTuple(x, y *big.Rat) (py.Object, error) {
+	q, r, err := ratDivMod(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{intFromBig(q), (*Fraction)(r)}, nil
+}
+
+// Returns floor(n / d) rounding towards negative infinity
+func floorDiv(n, d *big.Int) *big.Int {
+	q, m := new(big.Int).QuoRem(n, d, new(big.Int))
+	if m.Sign() != 0 && (m.Sign() < 0) != (d.Sign() < 0) {
+		q.Sub(q, big.NewInt(1))
+	}
+	return q
+}
+
+func floatPow(x, y py.Object) (py.Object, error) {
+	return py.Pow(x, y, py.None)
+}
+
+func floatDivMod(x, y py.Object) (py.Object, error) {
+	q, r, err := py.DivMod(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{q, r}, nil
+}
+
+// Raises x to the power y
+//
+// If y is an integer the result is an exact Fraction, otherwise it is
+// a float.
+func ratPow(x, y *big.Rat) (py.Object, error) {
+	if !y.IsInt() {
+		fx, _ := x.Float64()
+		fy, _ := y.Float64()
+		return py.Pow(py.Float(fx), py.Float(fy), py.None)
+	}
+	e := y.Num()
+	if !e.IsInt64() {
+		return nil, py.ExceptionNewf(py.OverflowError, "exponent too large")
+	}
+	n := new(big.Int).Set(x.Num())
+	d := new(big.Int).Set(x.Denom())
+	if e.Sign() < 0 {
+		if n.Sign() == 0 {
+			return nil, zeroDivision(big.NewRat(1, 1))
+		}
+		n, d = d, n
+		e = new(big.Int).Neg(e)
+	}
+	n.Exp(n, e, nil)
+	d.Exp(d, e, nil)
+	return (*Fraction)(new(big.Rat).SetFrac(n, d)), nil
+}
+
+func (a *Fraction) M__neg__() (py.Object, error) {
+	return (*Fraction)(new(big.Rat).Neg(a.rat())), nil
+}
+
+func (a *Fraction) M__pos__() (py.Object, error) {
+	return a, nil
+}
+
+func (a *Fraction) M__abs__() (py.Object, error) {
+	return (*Fraction)(new(big.Rat).Abs(a.rat())), nil
+}
+
+func (a *Fraction) M__add__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, false, ratAdd, py.Add)
+}
+
+func (a *Fraction) M__radd__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, true, ratAdd, py.Add)
+}
+
+func (a *Fraction) M__sub__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, false, ratSub, py.Sub)
+}
+
+func (a *Fraction) M__rsub__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, true, ratSub, py.Sub)
+}
+
+func (a *Fraction) M__mul__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, false, ratMul, py.Mul)
+}
+
+func (a *Fraction) M__rmul__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, true, ratMul, py.Mul)
+}
+
+func (a *Fraction) M__truediv__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, false, ratTrueDiv, py.TrueDiv)
+}
+
+func (a *Fraction) M__rtruediv__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, true, ratTrueDiv, py.TrueDiv)
+}
+
+func (a *Fraction) M__floordiv__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, false, ratFloorDiv, py.FloorDiv)
+}
+
+func (a *Fraction) M__rfloordiv__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, true, ratFloorDiv, py.FloorDiv)
+}
+
+func (a *Fraction) M__mod__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, false, ratMod, py.Mod)
+}
+
+func (a *Fraction) M__rmod__(other py.Object) (py.Object, error) {
+	return a.binaryOp(other, true, ratMod, py.Mod)
+}
+
+func (a *Fraction) M__divmod__(other py.Object) (py.Object, py.Object, error) {
+	res, err := a.binaryOp(other, false, ratDivModTuple, floatDivMod)
+	return splitDivMod(res, err)
+}
+
+func (a *Fraction) M__rdivmod__(other py.Object) (py.Object, py.Object, error) {
+	res, err := a.binaryOp(other, true, ratDivModTuple, floatDivMod)
+	return splitDivMod(res, err)
+}
+
+// Splits the result of a divmod binaryOp into its parts
+func splitDivMod(res py.Object, err error) (py.Object, py.Object, error) {
+	if err != nil {
+		return nil, nil, err
+	}
+	if res == py.NotImplemented {
+		return py.NotImplemented, py.NotImplemented, nil
+	}
+	t := res.(py.Tuple)
+	return t[0], t[1], nil
+}
+
+func (a *Fraction) M__pow__(other, modulus py.Object) (py.Object, error) {
+	if modulus != py.None {
+		return py.NotImplemented, nil
+	}
+	return a.binaryOp(other, false, ratPow, floatPow)
+}
+
+func (a *Fraction) M__rpow__(other py.Object) (py.Object, error) {
+	// An integer to the power of a whole Fraction uses integer pow
+	if _, ok := convertToRat(other); ok && a.rat().IsInt() {
+		return py.Pow(other, intFromBig(a.rat().Num()), py.None)
+	}
+	return a.binaryOp(other, true, ratPow, floatPow)
+}
+
+func (a *Fraction) M__bool__() (py.Object, error) {
+	return py.NewBool(a.rat().Sign() != 0), nil
+}
+
+func (a *Fraction) M__hash__() (py.Object, error) {
+	// Fractions which are equal to ints or floats must hash the same
+	return py.Int(py.HashRat(a.rat().Num(), a.rat().Denom())), nil
+}
+
+// Conversions
+
+func (a *Fraction) M__int__() (py.Object, error) {
+	return a.M__trunc__()
+}
+
+func (a *Fraction) M__float__() (py.Object, error) {
+	return a.float(), nil
+}
+
+func (a *Fraction) M__trunc__() (py.Object, error) {
+	return intFromBig(new(big.Int).Quo(a.rat().Num(), a.rat().Denom())), nil
+}
+
+func (a *Fraction) M__floor__() (py.Object, error) {
+	return intFromBig(floorDiv(a.rat().Num(), a.rat().Denom())), nil
+}
+
+func (a *Fraction) M__ceil__() (py.Object, error) {
+	n := new(big.Int).Neg(a.rat().Num())
+	q := floorDiv(n, a.rat().Denom())
+	return intFromBig(q.Neg(q)), nil
+}
+
+// Rounds x to the nearest integer, rounding half to even
+func roundHalfEven(x *big.Rat) *big.Int {
+	q, r := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if x.Sign() < 0 {
+		// Make the remainder positive so q is the floor
+		if r.Sign() != 0 {
+			q.Sub(q, big.NewInt(1))
+			r.Add(r, x.Denom())
+		}
+	}
+	r.Lsh(r, 1)
+	switch r.Cmp(x.Denom()) {
+	case 1:
+		q.Add(q, big.NewInt(1))
+	case 0:
+		if q.Bit(0) == 1 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// Rounds to the nearest integer if ndigits is None, otherwise to
+// a Fraction which is a multiple of 10**-ndigits.  Half way cases
+// are rounded to even.
+func (a *Fraction) M__round__(ndigits py.Object) (py.Object, error) {
+	if ndigits == py.None {
+		return intFromBig(roundHalfEven(a.rat())), nil
+	}
+	digits, err := py.MakeGoInt(ndigits)
+	if err != nil {
+		return nil, err
+	}
+	abs := digits
+	if abs < 0 {
+		abs = -abs
+	}
+	shift := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs)), nil))
+	if digits >= 0 {
+		x := new(big.Rat).Mul(a.rat(), shift)
+		x.SetInt(roundHalfEven(x))
+		return (*Fraction)(x.Quo(x, shift)), nil
+	}
+	x := new(big.Rat).Quo(a.rat(), shift)
+	x.SetInt(roundHalfEven(x))
+	return (*Fraction)(x.Mul(x, shift)), nil
+}
+
+// Rich comparison
+
+// Compares a with other exactly, passing the result of Cmp to cmp
+//
+// If other is a nan or an infinity then op is used to compare it with
+// a float instead.
+func (a *Fraction) compare(other py.Object, op func(a, b py.Object) (py.Object, error), cmp func(int) bool) (py.Object, error) {
+	if b, ok := convertToRat(other); ok {
+		return py.NewBool(cmp(a.rat().Cmp(b))), nil
+	}
+	if b, ok := other.(py.Float); ok {
+		if math.IsInf(float64(b), 0) || math.IsNaN(float64(b)) {
+			return op(py.Float(0), b)
+		}
+		return py.NewBool(cmp(a.rat().Cmp(new(big.Rat).SetFloat64(float64(b))))), nil
+	}
+	return py.NotImplemented, nil
+}
+
+func (a *Fraction) M__lt__(other py.Object) (py.Object, error) {
+	return a.compare(other, py.Lt, func(c int) bool { return c < 0 })
+}
+
+func (a *Fraction) M__le__(other py.Object) (py.Object, error) {
+	return a.compare(other, py.Le, func(c int) bool { return c <= 0 })
+}
+
+func (a *Fraction) M__eq__(other py.Object) (py.Object, error) {
+	return a.compare(other, py.Eq, func(c int) bool { return c == 0 })
+}
+
+func (a *Fraction) M__ne__(other py.Object) (py.Object, error) {
+	return a.compare(other, py.Ne, func(c int) bool { return c != 0 })
+}
+
+func (a *Fraction) M__gt__(other py.Object) (py.Object, error) {
+	return a.compare(other, py.Gt, func(c int) bool { return c > 0 })
+}
+
+func (a *Fraction) M__ge__(other py.Object) (py.Object, error) {
+	return a.compare(other, py.Ge, func(c int) bool { return c >= 0 })
+}
+
+// Returns the closest Fraction to a with denominator at most max
+func (a *Fraction) limitDenominator(max *big.Int) (*Fraction, error) {
+	if max.Cmp(big.NewInt(1)) < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "max_denominator should be at least 1")
+	}
+	if a.rat().Denom().Cmp(max) <= 0 {
+		return a, nil
+	}
+	// Find the best approximations using continued fractions
+	p0, q0 := big.NewInt(0), big.NewInt(1)
+	p1, q1 := big.NewInt(1), big.NewInt(0)
+	n := new(big.Int).Set(a.rat().Num())
+	d := new(big.Int).Set(a.rat().Denom())
+	for {
+		a := floorDiv(n, d)
+		q2 := new(big.Int).Add(q0, new(big.Int).Mul(a, q1))
+		if q2.Cmp(max) > 0 {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, new(big.Int).Add(p0, new(big.Int).Mul(a, p1)), q2
+		n, d = d, new(big.Int).Sub(n, new(big.Int).Mul(a, d))
+	}
+	k := floorDiv(new(big.Int).Sub(max, q0), q1)
+	bound1 := new(big.Rat).SetFrac(new(big.Int).Add(p0, new(big.Int).Mul(k, p1)), new(big.Int).Add(q0, new(big.Int).Mul(k, q1)))
+	bound2 := new(big.Rat).SetFrac(p1, q1)
+	diff1 := new(big.Rat).Abs(new(big.Rat).Sub(bound2, a.rat()))
+	diff2 := new(big.Rat).Abs(new(big.Rat).Sub(bound1, a.rat()))
+	if diff1.Cmp(diff2) <= 0 {
+		return (*Fraction)(bound2), nil
+	}
+	return (*Fraction)(bound1), nil
+}
+
+// Check interface is satisfied
+var _ py.I__str__ = (*Fraction)(nil)
+var _ py.I__repr__ = (*Fraction)(nil)
+var _ py.I__bool__ = (*Fraction)(nil)
+var _ py.I__round__ = (*Fraction)(nil)
+var _ py.I__trunc__ = (*Fraction)(nil)
+var _ py.I__floor__ = (*Fraction)(nil)
+var _ py.I__ceil__ = (*Fraction)(nil)
+var _ py.I__int__ = (*Fraction)(nil)
+var _ py.I__float__ = (*Fraction)(nil)
+var _ py.I__divmod__ = (*Fraction)(nil)
+var _ py.I__rdivmod__ = (*Fraction)(nil)
+var _ py.I__pow__ = (*Fraction)(nil)
+var _ py.I__rpow__ = (*Fraction)(nil)
+var _ py.I__lt__ = (*Fraction)(nil)
+var _ py.I__eq__ = (*Fraction)(nil)
+
+const fractions_doc = `Fraction, infinite-precision, real numbers.`
+
+// Initialise the module
+func init() {
+	FractionType.Dict["numerator"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return intFromBig(new(big.Int).Set(self.(*Fraction).rat().Num())), nil
+		},
+	}
+	FractionType.Dict["denominator"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return intFromBig(new(big.Int).Set(self.(*Fraction).rat().Denom())), nil
+		},
+	}
+	FractionType.Dict["limit_denominator"] = py.MustNewMethod("limit_denominator", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var maxObj py.Object = py.Int(1000000)
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:limit_denominator", []string{"max_denominator"}, &maxObj)
+		if err != nil {
+			return nil, err
+		}
+		max, ok := py.ConvertToBigInt(maxObj)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "max_denominator must be an integer, not %s", maxObj.Type().Name)
+		}
+		return self.(*Fraction).limitDenominator((*big.Int)(max))
+	}, 0, `limit_denominator(max_denominator=1000000) -> closest Fraction with denominator at most max_denominator`)
+
+	err := abc.Register(numbers.RationalType, FractionType)
+	if err != nil {
+		panic(err)
+	}
+
+	globals := py.StringDict{
+		"Fraction": FractionType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "fractions",
+		Doc:     fractions_doc,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,193 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import math
+from fractions import Fraction as F
+from libtest import *
+
+doc="constructor"
+assert F() == 0
+assert F(3) == 3
+assert F(8, 6) == F(4, 3)
+assert F(-8, 6) == F(4, -3)
+assert F(4, -3).numerator == -4
+assert F(4, -3).denominator == 3
+assert F(F(1, 2), F(1, 3)) == F(3, 2)
+assert F(0.5) == F(1, 2)
+assert F(0.1) == F(3602879701896397, 36028797018963968)
+assert F("3/4") == F(3, 4)
+assert F(" -3/4 ") == F(-3, 4)
+assert F("1.25") == F(5, 4)
+assert F("-1.5e2") == -150
+assert F("15e-1") == F(3, 2)
+assert F(".5") == F(1, 2)
+assert F(10**30, 3).numerator == 10**30
+for bad in ["", "1/", "/2", "1.5/2", "abc", "1e", "0x10"]:
+    try:
+        F(bad)
+    except ValueError:
+        pass
+    else:
+        assert False, "ValueError not raised for %r" % bad
+try:
+    F(1, 0)
+except ZeroDivisionError:
+    pass
+else:
+    assert False, "ZeroDivisionError not raised"
+try:
+    F(1.5, 2)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    F(float("inf"))
+except OverflowError:
+    pass
+else:
+    assert False, "OverflowError not raised"
+try:
+    F(float("nan"))
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+
+doc="str and repr"
+assert str(F(3, 4)) == "3/4"
+assert str(F(-6, 3)) == "-2"
+assert repr(F(3, 4)) == "Fraction(3, 4)"
+assert repr(F(2)) == "Fraction(2, 1)"
+
+doc="arithmetic"
+assert F(1, 2) + F(1, 3) == F(5, 6)
+assert F(1, 2) - F(1, 3) == F(1, 6)
+assert F(2, 3) * F(3, 4) == F(1, 2)
+assert F(1, 2) / F(1, 4) == 2
+assert -F(1, 2) == F(-1, 2)
+assert +F(1, 2) == F(1, 2)
+assert abs(F(-1, 2)) == F(1, 2)
+assert F(7, 2) // F(1, 1) == 3
+assert F(-7, 2) // 1 == -4
+assert F(7, 2) % 1 == F(1, 2)
+assert F(-7, 2) % 1 == F(1, 2)
+assert divmod(F(7, 2), F(2, 3)) == (5, F(1, 6))
+assert F(2, 3) ** 2 == F(4, 9)
+assert F(2, 3) ** -2 == F(9, 4)
+assert F(4) ** F(1, 2) == 2.0
+assert not F(0)
+assert F(1, 3)
+try:
+    F(1, 2) / 0
+except ZeroDivisionError:
+    pass
+else:
+    assert False, "ZeroDivisionError not raised"
+try:
+    F(0) ** -1
+except ZeroDivisionError:
+    pass
+else:
+    assert False, "ZeroDivisionError not raised"
+
+doc="mixed arithmetic with int"
+assert F(1, 2) + 1 == F(3, 2)
+assert 1 + F(1, 2) == F(3, 2)
+assert type(1 + F(1, 2)) is F
+assert 1 - F(1, 2) == F(1, 2)
+assert 3 * F(1, 2) == F(3, 2)
+assert 1 / F(1, 2) == 2
+assert type(1 / F(1, 2)) is F
+assert 7 // F(2) == 3
+assert type(7 // F(2)) is int
+assert 7 % F(2) == 1
+assert type(7 % F(2)) is F
+assert divmod(7, F(2)) == (3, 1)
+assert 2 ** F(3) == 8
+assert type(2 ** F(3)) is int
+assert 4 ** F(1, 2) == 2.0
+assert type(4 ** F(1, 2)) is float
+assert True + F(1, 2) == F(3, 2)
+assert (10**30) * F(1, 10**30) == 1
+
+doc="mixed arithmetic with float"
+assert F(1, 2) + 0.25 == 0.75
+assert type(F(1, 2) + 0.25) is float
+assert 0.25 + F(1, 2) == 0.75
+assert type(0.25 + F(1, 2)) is float
+assert 1.0 - F(1, 4) == 0.75
+assert F(1, 2) * 3.0 == 1.5
+assert 1.0 / F(1, 4) == 4.0
+assert F(7, 2) // 1.0 == 3.0
+assert type(F(7, 2) // 1.0) is float
+assert 3.5 % F(1) == 0.5
+assert type(3.5 % F(1)) is float
+assert F(1, 4) ** 0.5 == 0.5
+
+doc="comparisons"
+assert F(1, 2) < F(2, 3)
+assert F(1, 2) <= F(1, 2)
+assert F(2, 3) > F(1, 2)
+assert F(1, 2) >= F(1, 2)
+assert F(1, 2) != F(1, 3)
+assert F(2) == 2
+assert 2 == F(2)
+assert 1 < F(3, 2) < 2
+assert F(1, 2) == 0.5
+assert 0.5 == F(1, 2)
+assert F(1, 3) != 1/3
+assert F(1, 3) < 0.34
+assert 0.3 < F(1, 3)
+assert F(10**20) < float("inf")
+assert not (F(1) == float("nan"))
+assert F(1) != float("nan")
+assert not (F(1) < float("nan"))
+
+doc="conversions"
+assert int(F(7, 2)) == 3
+assert int(F(-7, 2)) == -3
+assert float(F(1, 4)) == 0.25
+assert math.trunc(F(-7, 2)) == -3
+assert math.floor(F(-7, 2)) == -4
+assert math.ceil(F(-7, 2)) == -3
+assert math.floor(F(7, 2)) == 3
+assert math.ceil(F(7, 2)) == 4
+assert type(math.floor(F(7, 2))) is int
+
+doc="round"
+assert round(F(7, 2)) == 4
+assert type(round(F(7, 2))) is int
+assert round(F(5, 2)) == 2
+assert round(F(-5, 2)) == -2
+assert round(F(-7, 2)) == -4
+assert round(F(10, 3)) == 3
+assert round(F(-10, 3)) == -3
+assert round(F(1, 3), 2) == F(33, 100)
+assert type(round(F(1, 3), 2)) is F
+assert round(F(125, 100), 1) == F(6, 5)
+assert round(F(1250), -2) == 1200
+assert round(F(1350), -2) == 1400
+
+doc="hash"
+assert hash(F(2)) == hash(2)
+assert hash(F(-1)) == hash(-1)
+assert hash(F(1, 2)) == hash(0.5)
+assert hash(F(-5, 2)) == hash(-2.5)
+assert hash(F(2**100)) == hash(2**100)
+assert hash(F(1, 3)) == hash(F(2, 6))
+
+doc="limit_denominator"
+assert F(3.141592653589793).limit_denominator(10) == F(22, 7)
+assert F(3.141592653589793).limit_denominator(100) == F(311, 99)
+assert F(4321, 8765).limit_denominator(10000) == F(4321, 8765)
+assert F(1, 3).limit_denominator() == F(1, 3)
+try:
+    F(1, 3).limit_denominator(0)
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+
+doc="finished"
@@ -0,0 +1,513 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Functools module
+
+package functools
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	PartialType    = py.NewTypeX("partial", partial_doc, PartialNew, nil)
+	KeyWrapperType = py.NewType("KeyWrapper", "Object to wrap a comparison function as a key function")
+	OrderingType   = py.NewType("total_ordering_method", "Comparison method made by total_ordering")
+)
+
+// The attributes update_wrapper copies and updates by default
+var (
+	WrapperAssignments = py.Tuple{py.String("__module__"), py.String("__name__"), py.String("__qualname__"), py.String("__doc__"), py.String("__annotations__")}
+	WrapperUpdates     = py.Tuple{py.String("__dict__")}
+)
+
+const partial_doc = `partial(func, *args, **keywords) - new function with partial application
+of the given arguments and keywords.`
+
+// Partial is a callable which calls a function with some arguments
+// already given
+type Partial struct {
+	Func     py.Object
+	Args     py.Tuple
+	Keywords py.StringDict
+	Dict     py.StringDict
+}
+
+// Type of this object
+func (p *Partial) Type() *py.Type {
+	return PartialType
+}
+
+// Get the Dict
+func (p *Partial) GetDict() py.StringDict {
+	return p.Dict
+}
+
+// PartialNew makes a partial object
+func PartialNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "type 'partial' takes at least one argument")
+	}
+	fn := args[0]
+	if _, ok := fn.(py.I__call__); !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "the first argument must be callable")
+	}
+	p := &Partial{
+		Func:     fn,
+		Args:     append(py.Tuple{}, args[1:]...),
+		Keywords: kwargs.Copy(),
+		Dict:     py.NewStringDict(),
+	}
+	// Flatten a partial of a partial
+	if inner, ok := fn.(*Partial); ok && len(inner.Dict) == 0 {
+		p.Func = inner.Func
+		p.Args = append(append(py.Tuple{}, inner.Args...), p.Args...)
+		p.Keywords = inner.Keywords.Copy()
+		for key, value := range kwargs {
+			p.Keywords[key] = value
+		}
+	}
+	return p, nil
+}
+
+func (p *Partial) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	callArgs := append(append(py.Tuple{}, p.Args...), args...)
+	callKwargs := p.Keywords.Copy()
+	for key, value := range kwargs {
+		callKwargs[key] = value
+	}
+	return py.Call(p.Func, callArgs, callKwargs)
+}
+
+func (p *Partial) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("functools.partial(")
+	fn, err := py.ReprAsString(p.Func)
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(fn)
+	for _, arg := range p.Args {
+		repr, err := py.ReprAsString(arg)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(", " + repr)
+	}
+	keys := make([]string, 0, len(p.Keywords))
+	for key := range p.Keywords {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		repr, err := py.ReprAsString(p.Keywords[key])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(", " + key + "=" + repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+func init() {
+	PartialType.Dict["func"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Partial).Func, nil
+		},
+		Doc: "function object to use in future partial calls",
+	}
+	PartialType.Dict["args"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Partial).Args, nil
+		},
+		Doc: "tuple of arguments to future partial calls",
+	}
+	PartialType.Dict["keywords"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Partial).Keywords, nil
+		},
+		Doc: "dictionary of keyword arguments to future partial calls",
+	}
+	PartialType.Dict["__dict__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Partial).Dict, nil
+		},
+	}
+}
+
+const reduce_doc = `reduce(function, sequence[, initial]) -> value
+
+Apply a function of two arguments cumulatively to the items of a sequence,
+from left to right, so as to reduce the sequence to a single value.
+For example, reduce(lambda x, y: x+y, [1, 2, 3, 4, 5]) calculates
+((((1+2)+3)+4)+5).  If initial is present, it is placed before the items
+of the sequence in the calculation, and serves as a default when the
+sequence is empty.`
+
+func functools_reduce(self py.Object, args py.Tuple) (py.Object, error) {
+	var fn, sequence, result py.Object
+	err := py.UnpackTuple(args, nil, "reduce", 2, 3, &fn, &sequence, &result)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(sequence)
+	if err != nil {
+		return nil, py.ExceptionNewf(py.TypeError, "reduce() arg 2 must support iteration")
+	}
+	for {
+		item, err := py.Next(it)
+		if err != nil {
+			if py.IsException(py.StopIteration, err) {
+				break
+			}
+			return nil, err
+		}
+		if result == nil {
+			result = item
+			continue
+		}
+		result, err = py.Call(fn, py.Tuple{result, item}, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if result == nil {
+		return nil, py.ExceptionNewf(py.TypeError, "reduce() of empty sequence with no initial value")
+	}
+	return result, nil
+}
+
+// Copies the attributes assigned from wrapped to wrapper and updates
+// the attributes updated of wrapper with those of wrapped
+func updateWrapper(wrapper, wrapped, assigned, updated py.Object) error {
+	var loopErr error
+	err := py.Iterate(assigned, func(attr py.Object) bool {
+		var value py.Object
+		value, loopErr = py.GetAttr(wrapped, attr)
+		if loopErr != nil {
+			if py.IsException(py.AttributeError, loopErr) {
+				loopErr = nil
+			}
+			return loopErr != nil
+		}
+		_, loopErr = py.SetAttr(wrapper, attr, value)
+		return loopErr != nil
+	})
+	if err == nil {
+		err = loopErr
+	}
+	if err != nil {
+		return err
+	}
+	err = py.Iterate(updated, func(attr py.Object) bool {
+		var dict, update, value py.Object
+		if value, loopErr = py.GetAttr(wrapped, attr); loopErr != nil {
+			value = py.NewStringDict()
+			loopErr = nil
+		}
+		if dict, loopErr = py.GetAttr(wrapper, attr); loopErr != nil {
+			return true
+		}
+		if update, loopErr = py.GetAttrString(dict, "update"); loopErr != nil {
+			return true
+		}
+		_, loopErr = py.Call(update, py.Tuple{value}, nil)
+		return loopErr != nil
+	})
+	if err == nil {
+		err = loopErr
+	}
+	if err != nil {
+		return err
+	}
+	_, err = py.SetAttrString(wrapper, "__wrapped__", wrapped)
+	return err
+}
+
+const update_wrapper_doc = `update_wrapper(wrapper, wrapped, assigned=WRAPPER_ASSIGNMENTS, updated=WRAPPER_UPDATES) -> wrapper
+
+Update a wrapper function to look like the wrapped function.
+
+assigned is a tuple naming the attributes assigned directly from the
+wrapped function to the wrapper function.  updated is a tuple naming
+the attributes of the wrapper that are updated with the corresponding
+attribute from the wrapped function.  The wrapped function is saved
+as the __wrapped__ attribute of the wrapper.`
+
+func functools_update_wrapper(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var wrapper, wrapped py.Object
+	var assigned py.Object = WrapperAssignments
+	var updated py.Object = WrapperUpdates
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|OO:update_wrapper", []string{"wrapper", "wrapped", "assigned", "updated"}, &wrapper, &wrapped, &assigned, &updated)
+	if err != nil {
+		return nil, err
+	}
+	err = updateWrapper(wrapper, wrapped, assigned, updated)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper, nil
+}
+
+var updateWrapperMethod = py.MustNewMethod("update_wrapper", functools_update_wrapper, 0, update_wrapper_doc)
+
+const wraps_doc = `wraps(wrapped, assigned=WRAPPER_ASSIGNMENTS, updated=WRAPPER_UPDATES) -> decorator
+
+Decorator factory to apply update_wrapper() to a wrapper function.
+
+Returns a decorator that invokes update_wrapper() with the decorated
+function as the wrapper argument and the arguments to wraps() as the
+remaining arguments.`
+
+func functools_wraps(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var wrapped py.Object
+	var assigned py.Object = WrapperAssignments
+	var updated py.Object = WrapperUpdates
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:wraps", []string{"wrapped", "assigned", "updated"}, &wrapped, &assigned, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &Partial{
+		Func: updateWrapperMethod,
+		Keywords: py.StringDict{
+			"wrapped":  wrapped,
+			"assigned": assigned,
+			"updated":  updated,
+		},
+		Dict: py.NewStringDict(),
+	}, nil
+}
+
+// KeyWrapper is the key made by cmp_to_key which compares with the
+// comparison function
+type KeyWrapper struct {
+	cmp py.Object
+	obj py.Object // nil for the key function itself
+}
+
+// Type of this object
+func (k *KeyWrapper) Type() *py.Type {
+	return KeyWrapperType
+}
+
+// Makes a key for obj
+func (k *KeyWrapper) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:K", []string{"obj"}, &obj)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyWrapper{cmp: k.cmp, obj: obj}, nil
+}
+
+// Compares k with other using the comparison function, returning the
+// result of op on the comparison and 0
+func (k *KeyWrapper) compare(other py.Object, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	o, ok := other.(*KeyWrapper)
+	if !ok || k.obj == nil || o.obj == nil {
+		return nil, py.ExceptionNewf(py.TypeError, "other argument must be K instance")
+	}
+	result, err := py.Call(k.cmp, py.Tuple{k.obj, o.obj}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return op(result, py.Int(0))
+}
+
+func (k *KeyWrapper) M__lt__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Lt)
+}
+
+func (k *KeyWrapper) M__le__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Le)
+}
+
+func (k *KeyWrapper) M__eq__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Eq)
+}
+
+func (k *KeyWrapper) M__ne__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Ne)
+}
+
+func (k *KeyWrapper) M__gt__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Gt)
+}
+
+func (k *KeyWrapper) M__ge__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Ge)
+}
+
+func init() {
+	KeyWrapperType.Dict["obj"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			obj := self.(*KeyWrapper).obj
+			if obj == nil {
+				return nil, py.ExceptionNewf(py.AttributeError, "obj")
+			}
+			return obj, nil
+		},
+		Fset: func(self, value py.Object) error {
+			self.(*KeyWrapper).obj = value
+			return nil
+		},
+		Doc: "Value wrapped by a key function.",
+	}
+}
+
+const cmp_to_key_doc = `cmp_to_key(mycmp) -> key function
+
+Convert a cmp= function into a key= function.`
+
+func functools_cmp_to_key(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var cmp py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:cmp_to_key", []string{"mycmp"}, &cmp)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyWrapper{cmp: cmp}, nil
+}
+
+// The ways total_ordering makes a comparison from the result r of the
+// root comparison of self and other
+const (
+	notRootAndNe = iota // not r and self != other
+	rootOrEq            // r or self == other
+	notRoot             // not r
+	notRootOrEq         // not r or self == other
+	rootAndNe           // r and self != other
+)
+
+// The comparisons total_ordering makes from each root comparison in
+// order of preference for the root
+var orderingConversions = []struct {
+	root    string
+	derived map[string]int
+}{
+	{"__lt__", map[string]int{"__gt__": notRootAndNe, "__le__": rootOrEq, "__ge__": notRoot}},
+	{"__le__", map[string]int{"__ge__": notRootOrEq, "__lt__": rootAndNe, "__gt__": notRoot}},
+	{"__gt__", map[string]int{"__lt__": notRootAndNe, "__ge__": rootOrEq, "__le__": notRoot}},
+	{"__ge__", map[string]int{"__le__": notRootOrEq, "__gt__": rootAndNe, "__lt__": notRoot}},
+}
+
+// Ordering is a comparison method made by total_ordering from the
+// root comparison method of a class
+type Ordering struct {
+	name string
+	root string
+	kind int
+}
+
+// Type of this object
+func (o *Ordering) Type() *py.Type {
+	return OrderingType
+}
+
+// Binds the method to an instance
+func (o *Ordering) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, o), nil
+	}
+	return o, nil
+}
+
+// Compares self with other
+func (o *Ordering) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var self, other py.Object
+	err := py.UnpackTuple(args, kwargs, o.name, 2, 2, &self, &other)
+	if err != nil {
+		return nil, err
+	}
+	rootMethod := self.Type().Lookup(o.root)
+	if rootMethod == nil {
+		return py.NotImplemented, nil
+	}
+	r, err := py.Call(rootMethod, py.Tuple{self, other}, nil)
+	if err != nil || r == py.NotImplemented {
+		return r, err
+	}
+	if o.kind == notRoot || o.kind == notRootAndNe || o.kind == notRootOrEq {
+		r, err = py.Not(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch o.kind {
+	case notRootAndNe, rootAndNe:
+		if !py.ObjectIsTrue(r) {
+			return r, nil
+		}
+		return py.Ne(self, other)
+	case rootOrEq, notRootOrEq:
+		if py.ObjectIsTrue(r) {
+			return r, nil
+		}
+		return py.Eq(self, other)
+	}
+	return r, nil
+}
+
+const total_ordering_doc = `Class decorator that fills in missing ordering methods`
+
+func functools_total_ordering(self py.Object, args py.Tuple) (py.Object, error) {
+	var clsObj py.Object
+	err := py.UnpackTuple(args, nil, "total_ordering", 1, 1, &clsObj)
+	if err != nil {
+		return nil, err
+	}
+	cls, ok := clsObj.(*py.Type)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "total_ordering() argument must be a class, not '%s'", clsObj.Type().Name)
+	}
+	for _, conversion := range orderingConversions {
+		if cls.Lookup(conversion.root) == nil {
+			continue
+		}
+		for name, kind := range conversion.derived {
+			if cls.Lookup(name) == nil {
+				cls.Dict[name] = &Ordering{name: name, root: conversion.root, kind: kind}
+			}
+		}
+		cls.Modified()
+		return cls, nil
+	}
+	return nil, py.ExceptionNewf(py.ValueError, "must define at least one ordering operation: < > <= >=")
+}
+
+const functools_doc = `Tools for working with functions and callable objects`
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("cmp_to_key", functools_cmp_to_key, 0, cmp_to_key_doc),
+		py.MustNewMethod("lru_cache", functools_lru_cache, 0, lru_cache_doc),
+		py.MustNewMethod("reduce", functools_reduce, 0, reduce_doc),
+		py.MustNewMethod("total_ordering", functools_total_ordering, 0, total_ordering_doc),
+		updateWrapperMethod,
+		py.MustNewMethod("wraps", functools_wraps, 0, wraps_doc),
+	}
+	globals := py.StringDict{
+		"partial":             PartialType,
+		"WRAPPER_ASSIGNMENTS": WrapperAssignments,
+		"WRAPPER_UPDATES":     WrapperUpdates,
+		"_CacheInfo":          CacheInfoType,
+		"_lru_cache_wrapper":  LruCacheWrapperType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "functools",
+		Doc:     functools_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
+
+// Check interfaces
+var _ py.I__call__ = (*Partial)(nil)
+var _ py.IGetDict = (*Partial)(nil)
+var _ py.I__call__ = (*KeyWrapper)(nil)
+var _ py.I__lt__ = (*KeyWrapper)(nil)
@@ -0,0 +1,316 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The lru_cache decorator
+//
+// The cache is a dict from the arguments to a list element so
+// lookups use python hashing and equality while the elements keep
+// the least recently used order.
+
+package functools
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	LruCacheWrapperType = py.NewType("_lru_cache_wrapper", "Least recently used cache wrapper of a function")
+	CacheInfoType       = py.NewTypeX("CacheInfo", "CacheInfo(hits, misses, maxsize, currsize)", CacheInfoNew, nil)
+	lruListElemType     = py.NewType("_lru_list_elem", "")
+)
+
+// Separates the positional and keyword arguments in a cache key
+var kwdMark = py.NewType("_kwd_mark", "")
+
+// An entry in the cache
+type lruEntry struct {
+	key    py.Object
+	result py.Object
+}
+
+// A dict value holding the list element of a cached call
+type lruListElem struct {
+	elem *list.Element
+}
+
+// Type of this object
+func (e *lruListElem) Type() *py.Type {
+	return lruListElemType
+}
+
+// LruCacheWrapper calls a function caching the results
+type LruCacheWrapper struct {
+	fn      py.Object
+	maxsize int // the maximum number of entries or -1 for no limit
+	typed   bool
+	cache   *py.Dict
+	order   *list.List // of *lruEntry, most recently used at the back
+	hits    int
+	misses  int
+	dict    py.StringDict
+}
+
+// Type of this object
+func (w *LruCacheWrapper) Type() *py.Type {
+	return LruCacheWrapperType
+}
+
+// Get the Dict
+func (w *LruCacheWrapper) GetDict() py.StringDict {
+	return w.dict
+}
+
+// NewLruCacheWrapper wraps fn with a cache of maxsize entries, or an
+// unlimited cache if maxsize is negative
+func NewLruCacheWrapper(fn py.Object, maxsize int, typed bool) (*LruCacheWrapper, error) {
+	w := &LruCacheWrapper{
+		fn:      fn,
+		maxsize: maxsize,
+		typed:   typed,
+		cache:   py.NewDict(),
+		order:   list.New(),
+		dict:    py.NewStringDict(),
+	}
+	err := updateWrapper(w, fn, WrapperAssignments, WrapperUpdates)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Returns the cache key for a call
+func (w *LruCacheWrapper) makeKey(args py.Tuple, kwargs py.StringDict) py.Object {
+	key := append(py.Tuple{}, args...)
+	if len(kwargs) > 0 {
+		names := make([]string, 0, len(kwargs))
+		for name := range kwargs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		key = append(key, kwdMark)
+		for _, name := range names {
+			key = append(key, py.String(name), kwargs[name])
+		}
+	}
+	if w.typed {
+		for _, arg := range args {
+			key = append(key, arg.Type())
+		}
+		for _, value := range kwargs {
+			key = append(key, value.Type())
+		}
+	}
+	return key
+}
+
+func (w *LruCacheWrapper) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if w.maxsize == 0 {
+		w.misses++
+		return py.Call(w.fn, args, kwargs)
+	}
+	key := w.makeKey(args, kwargs)
+	value, found, err := w.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		w.hits++
+		elem := value.(*lruListElem).elem
+		w.order.MoveToBack(elem)
+		return elem.Value.(*lruEntry).result, nil
+	}
+	w.misses++
+	result, err := py.Call(w.fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	// A recursive call may have cached the key already
+	if _, found, _ = w.cache.Get(key); found {
+		return result, nil
+	}
+	if w.maxsize > 0 && w.order.Len() >= w.maxsize {
+		oldest := w.order.Front()
+		w.order.Remove(oldest)
+		_, _, err = w.cache.Delete(oldest.Value.(*lruEntry).key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	elem := w.order.PushBack(&lruEntry{key: key, result: result})
+	err = w.cache.Set(key, &lruListElem{elem: elem})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Binds the wrapper to instance so it can decorate methods
+func (w *LruCacheWrapper) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, w), nil
+	}
+	return w, nil
+}
+
+// CacheInfo returns the statistics of the cache
+func (w *LruCacheWrapper) CacheInfo() *CacheInfo {
+	var maxsize py.Object = py.None
+	if w.maxsize >= 0 {
+		maxsize = py.Int(w.maxsize)
+	}
+	return &CacheInfo{py.Int(w.hits), py.Int(w.misses), maxsize, py.Int(w.order.Len())}
+}
+
+// CacheClear empties the cache and resets the statistics
+func (w *LruCacheWrapper) CacheClear() {
+	w.cache.Clear()
+	w.order.Init()
+	w.hits, w.misses = 0, 0
+}
+
+func init() {
+	LruCacheWrapperType.Dict["cache_info"] = py.MustNewMethod("cache_info", func(self py.Object) (py.Object, error) {
+		return self.(*LruCacheWrapper).CacheInfo(), nil
+	}, 0, "Report cache statistics")
+	LruCacheWrapperType.Dict["cache_clear"] = py.MustNewMethod("cache_clear", func(self py.Object) (py.Object, error) {
+		self.(*LruCacheWrapper).CacheClear()
+		return py.None, nil
+	}, 0, "Clear the cache and cache statistics")
+	LruCacheWrapperType.Dict["__dict__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*LruCacheWrapper).dict, nil
+		},
+	}
+}
+
+const lru_cache_doc = `lru_cache(maxsize=128, typed=False) -> decorator
+
+Least-recently-used cache decorator.
+
+If maxsize is set to None, the LRU features are disabled and the cache
+can grow without bound.
+
+If typed is True, arguments of different types will be cached separately.
+For example, f(3.0) and f(3) will be treated as distinct calls with
+distinct results.
+
+Arguments to the cached function must be hashable.
+
+View the cache statistics named tuple (hits, misses, maxsize, currsize)
+with f.cache_info().  Clear the cache and statistics with f.cache_clear().
+Access the underlying function with f.__wrapped__.`
+
+func functools_lru_cache(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var maxsizeObj py.Object = py.Int(128)
+	var typedObj py.Object = py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:lru_cache", []string{"maxsize", "typed"}, &maxsizeObj, &typedObj)
+	if err != nil {
+		return nil, err
+	}
+	typed := py.ObjectIsTrue(typedObj)
+	// Used as @lru_cache without arguments
+	if _, ok := maxsizeObj.(py.I__call__); ok {
+		return NewLruCacheWrapper(maxsizeObj, 128, typed)
+	}
+	maxsize := -1
+	if maxsizeObj != py.None {
+		maxsize, err = py.IndexInt(maxsizeObj)
+		if err != nil {
+			return nil, py.ExceptionNewf(py.TypeError, "Expected maxsize to be an integer or None")
+		}
+		if maxsize < 0 {
+			maxsize = 0
+		}
+	}
+	return py.MustNewMethod("decorating_function", func(self, fn py.Object) (py.Object, error) {
+		return NewLruCacheWrapper(fn, maxsize, typed)
+	}, 0, ""), nil
+}
+
+// CacheInfo is the named tuple of the statistics of an lru_cache
+type CacheInfo struct {
+	Hits, Misses, Maxsize, Currsize py.Object
+}
+
+// Type of this object
+func (c *CacheInfo) Type() *py.Type {
+	return CacheInfoType
+}
+
+// CacheInfoNew makes a CacheInfo
+func CacheInfoNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	c := &CacheInfo{}
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOOO:CacheInfo", []string{"hits", "misses", "maxsize", "currsize"}, &c.Hits, &c.Misses, &c.Maxsize, &c.Currsize)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Returns the fields as a tuple
+func (c *CacheInfo) tuple() py.Tuple {
+	return py.Tuple{c.Hits, c.Misses, c.Maxsize, c.Currsize}
+}
+
+func (c *CacheInfo) M__repr__() (py.Object, error) {
+	var reprs [4]string
+	for i, field := range c.tuple() {
+		repr, err := py.ReprAsString(field)
+		if err != nil {
+			return nil, err
+		}
+		reprs[i] = repr
+	}
+	return py.String(fmt.Sprintf("CacheInfo(hits=%s, misses=%s, maxsize=%s, currsize=%s)", reprs[0], reprs[1], reprs[2], reprs[3])), nil
+}
+
+func (c *CacheInfo) M__len__() (py.Object, error) {
+	return py.Int(4), nil
+}
+
+func (c *CacheInfo) M__getitem__(key py.Object) (py.Object, error) {
+	return c.tuple().M__getitem__(key)
+}
+
+func (c *CacheInfo) M__iter__() (py.Object, error) {
+	return py.NewIterator(c.tuple()), nil
+}
+
+// Returns the tuple to compare c with
+func asTuple(other py.Object) py.Object {
+	if c, ok := other.(*CacheInfo); ok {
+		return c.tuple()
+	}
+	return other
+}
+
+func (c *CacheInfo) M__eq__(other py.Object) (py.Object, error) {
+	return c.tuple().M__eq__(asTuple(other))
+}
+
+func (c *CacheInfo) M__ne__(other py.Object) (py.Object, error) {
+	return c.tuple().M__ne__(asTuple(other))
+}
+
+func init() {
+	for i, name := range []string{"hits", "misses", "maxsize", "currsize"} {
+		i := i
+		CacheInfoType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return self.(*CacheInfo).tuple()[i], nil
+			},
+		}
+	}
+}
+
+// Check interfaces
+var _ py.I__call__ = (*LruCacheWrapper)(nil)
+var _ py.I__get__ = (*LruCacheWrapper)(nil)
+var _ py.IGetDict = (*LruCacheWrapper)(nil)
+var _ py.I__getitem__ = (*CacheInfo)(nil)
+var _ py.I__iter__ = (*CacheInfo)(nil)
@@ -0,0 +1,235 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import functools
+from functools import partial, reduce, lru_cache, wraps, update_wrapper, cmp_to_key
+from libtest import *
+
+doc = "partial"
+def f(a, b, c=3, *args, **kwargs):
+    return (a, b, c, args, kwargs)
+p = partial(f, 1)
+assert p(2) == (1, 2, 3, (), {})
+assert p(2, 4, 5, d=6) == (1, 2, 4, (5,), {"d": 6})
+assert p.func is f
+assert p.args == (1,)
+assert p.keywords == {}
+p = partial(f, 1, c=10)
+assert p(2) == (1, 2, 10, (), {})
+assert p(2, c=20) == (1, 2, 20, (), {})
+assert p.keywords == {"c": 10}
+q = partial(p, 2, d=4)
+assert q.func is f
+assert q.args == (1, 2)
+assert q.keywords == {"c": 10, "d": 4}
+assert q() == (1, 2, 10, (), {"d": 4})
+assert repr(partial(f, 1, c=2)).startswith("functools.partial(<function f")
+assert repr(partial(f, 1, c=2)).endswith(", 1, c=2)")
+p.attr = "set"
+assert p.attr == "set"
+assertRaises(TypeError, partial)
+assertRaises(TypeError, partial, 1)
+assert partial(max, 1)(5) == 5
+
+class Adder:
+    def __init__(self, n):
+        self.n = n
+    def __call__(self, x):
+        return self.n + x
+assert partial(Adder(1))(2) == 3
+
+doc = "reduce"
+assert reduce(lambda x, y: x + y, [1, 2, 3, 4, 5]) == 15
+assert reduce(lambda x, y: x * y, [1, 2, 3, 4], 10) == 240
+assert reduce(lambda x, y: x + y, [], 7) == 7
+assert reduce(lambda x, y: x + y, [7]) == 7
+assertRaises(TypeError, reduce, lambda x, y: x + y, [])
+assertRaises(TypeError, reduce, lambda x, y: x + y, 1)
+assert reduce(lambda x, y: x + y, (i for i in range(4))) == 6
+
+doc = "wraps"
+def decorator(fn):
+    @wraps(fn)
+    def wrapper(*args, **kwargs):
+        "wrapper doc"
+        return fn(*args, **kwargs)
+    return wrapper
+def wrapped(x):
+    "wrapped doc"
+    return x * 2
+wrapped.extra = "extra"
+w = decorator(wrapped)
+assert w(4) == 8
+assert w.__name__ == "wrapped"
+assert w.__qualname__ == "wrapped"
+assert w.__doc__ == "wrapped doc"
+assert w.__module__ == wrapped.__module__
+assert w.__wrapped__ is wrapped
+assert w.extra == "extra"
+assert w.__dict__["extra"] == "extra"
+
+def g():
+    pass
+def h():
+    "h doc"
+assert update_wrapper(g, h, assigned=("__doc__",), updated=()) is g
+assert g.__name__ == "g"
+assert g.__doc__ == "h doc"
+assert g.__wrapped__ is h
+assert "__name__" in functools.WRAPPER_ASSIGNMENTS
+assert functools.WRAPPER_UPDATES == ("__dict__",)
+
+class NoName:
+    pass
+def target():
+    pass
+update_wrapper(target, NoName())
+assert target.__name__ == "target"
+
+doc = "lru_cache"
+calls = []
+@lru_cache(maxsize=2)
+def square(x):
+    calls.append(x)
+    return x * x
+assert square(2) == 4
+assert square(2) == 4
+assert calls == [2]
+assert square(3) == 9
+assert square(4) == 16
+assert square.cache_info() == (1, 3, 2, 2)
+info = square.cache_info()
+assert (info.hits, info.misses, info.maxsize, info.currsize) == (1, 3, 2, 2)
+assert repr(info) == "CacheInfo(hits=1, misses=3, maxsize=2, currsize=2)"
+hits, misses, maxsize, currsize = info
+assert misses == 3
+assert info[2] == 2
+assert len(info) == 4
+assert square(2) == 4
+assert calls == [2, 3, 4, 2]
+assert square(4) == 16
+assert calls == [2, 3, 4, 2]
+square.cache_clear()
+assert square.cache_info() == (0, 0, 2, 0)
+assert square.__name__ == "square"
+assert square.__wrapped__(5) == 25
+
+@lru_cache(maxsize=None)
+def fib(n):
+    if n < 2:
+        return n
+    return fib(n - 1) + fib(n - 2)
+assert fib(50) == 12586269025
+assert fib.cache_info().maxsize is None
+assert fib.cache_info().currsize == 51
+
+@lru_cache()
+def kw(a, b=1):
+    calls.append((a, b))
+    return a + b
+calls = []
+assert kw(1, b=2) == 3
+assert kw(1, b=2) == 3
+assert kw(1, 2) == 3
+assert kw(a=1, b=2) == 3
+assert kw.cache_info().hits == 1
+
+@lru_cache(typed=True)
+def typed(x):
+    return type(x)
+assert typed(1) is int
+assert typed(1.0) is float
+assert typed.cache_info().currsize == 2
+
+@lru_cache(maxsize=0)
+def nocache(x):
+    return x
+nocache(1)
+nocache(1)
+assert nocache.cache_info() == (0, 2, 0, 0)
+
+@lru_cache
+def bare(x):
+    return x + 1
+assert bare(1) == 2
+assert bare.cache_info().maxsize == 128
+
+assertRaises(TypeError, square, [])
+assertRaises(TypeError, lru_cache, "x")
+
+class Cached:
+    def __init__(self):
+        self.count = 0
+    @lru_cache()
+    def method(self, x):
+        self.count += 1
+        return x
+c = Cached()
+assert c.method(1) == 1
+assert c.method(1) == 1
+assert c.count == 1
+
+doc = "cmp_to_key"
+def compare(a, b):
+    if a < b:
+        return -1
+    if a > b:
+        return 1
+    return 0
+def reverse(a, b):
+    return compare(b, a)
+assert sorted([3, 1, 2], key=cmp_to_key(compare)) == [1, 2, 3]
+assert sorted([3, 1, 2], key=cmp_to_key(reverse)) == [3, 2, 1]
+K = cmp_to_key(compare)
+assert K(1) < K(2)
+assert K(2) > K(1)
+assert K(1) == K(1)
+assert K(1) != K(2)
+assert K(1) <= K(1)
+assert K(2) >= K(1)
+assert K(3).obj == 3
+assert min([5, 2, 8], key=cmp_to_key(compare)) == 2
+
+doc = "total_ordering"
+@functools.total_ordering
+class Lt:
+    def __init__(self, v):
+        self.v = v
+    def __lt__(self, other):
+        return self.v < other.v
+    def __eq__(self, other):
+        return self.v == other.v
+assert Lt(1) <= Lt(2)
+assert Lt(2) <= Lt(2)
+assert Lt(3) >= Lt(2)
+assert Lt(3) > Lt(2)
+assert not (Lt(1) > Lt(2))
+assert not (Lt(2) > Lt(2))
+@functools.total_ordering
+class Ge:
+    def __init__(self, v):
+        self.v = v
+    def __ge__(self, other):
+        return self.v >= other.v
+    def __eq__(self, other):
+        return self.v == other.v
+assert Ge(1) < Ge(2)
+assert not (Ge(2) < Ge(2))
+assert Ge(2) <= Ge(2)
+assert Ge(3) > Ge(2)
+assert not (Ge(2) > Ge(2))
+@functools.total_ordering
+class Both:
+    def __lt__(self, other):
+        return "lt"
+    def __gt__(self, other):
+        return "gt"
+assert (Both() > Both()) == "gt"
+def no_ordering():
+    @functools.total_ordering
+    class Bad:
+        pass
+assertRaises(ValueError, no_ordering)
+
+doc = "finished"
@@ -0,0 +1,262 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Gc module
+//
+// Memory is managed by Go's garbage collector, which has no
+// generations and collects reference cycles too, so this module
+// controls when the finalizations of unreachable objects, their
+// __del__ methods and weakref callbacks, are run rather than the
+// collector itself.  See py/finalize.go for how they work.
+
+package gc
+
+import (
+	"sync"
+
+	"github.com/go-python/gpython/py"
+)
+
+const gc_doc = `This module provides access to the garbage collector.
+
+Memory is reclaimed by Go's garbage collector.  This module controls
+when the __del__ methods and weakref callbacks of the objects it has
+found unreachable are run.
+
+enable() -- Run finalizations automatically.
+disable() -- Leave finalizations for collect() to run.
+isenabled() -- Returns true if finalizations are run automatically.
+collect() -- Do a full collection right now.
+get_count() -- Return the current collection counts.
+get_stats() -- Return list of dictionaries containing per-generation stats.
+set_debug() -- Set debugging flags.
+get_debug() -- Get debugging flags.
+set_threshold() -- Set the collection thresholds.
+get_threshold() -- Return the current the collection thresholds.`
+
+// Debugging flags, which are remembered but have no effect
+const (
+	DEBUG_STATS         = 1 << 0
+	DEBUG_COLLECTABLE   = 1 << 1
+	DEBUG_UNCOLLECTABLE = 1 << 2
+	DEBUG_SAVEALL       = 1 << 5
+	DEBUG_LEAK          = DEBUG_COLLECTABLE | DEBUG_UNCOLLECTABLE | DEBUG_SAVEALL
+)
+
+// The number of generations CPython has, which are reported here
+const generations = 3
+
+var (
+	mu          sync.Mutex
+	thresholds  = [generations]int{700, 10, 10}
+	debug       int
+	collections [generations]int // number of collections of each generation
+	collected   [generations]int // number of finalizations run by them
+)
+
+const enable_doc = `enable() -> None
+
+Enable automatic garbage collection.`
+
+func gc_enable(self py.Object) (py.Object, error) {
+	py.EnableFinalization(true)
+	return py.None, nil
+}
+
+const disable_doc = `disable() -> None
+
+Disable automatic garbage collection.`
+
+func gc_disable(self py.Object) (py.Object, error) {
+	py.EnableFinalization(false)
+	return py.None, nil
+}
+
+const isenabled_doc = `isenabled() -> status
+
+Returns true if automatic garbage collection is enabled.`
+
+func gc_isenabled(self py.Object) (py.Object, error) {
+	return py.NewBool(py.FinalizationEnabled()), nil
+}
+
+const collect_doc = `collect([generation]) -> n
+
+With no arguments, run a full collection.  The optional argument
+may be an integer specifying which generation to collect.  A ValueError
+is raised if the generation number is invalid.
+
+The number of objects finalized is returned.`
+
+func gc_collect(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var generationObj py.Object = py.Int(generations - 1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|i:collect", []string{"generation"}, &generationObj)
+	if err != nil {
+		return nil, err
+	}
+	generation := int(generationObj.(py.Int))
+	if generation < 0 || generation >= generations {
+		return nil, py.ExceptionNewf(py.ValueError, "invalid generation")
+	}
+	err = runCallbacks("start", generation, 0)
+	if err != nil {
+		return nil, err
+	}
+	n := py.GarbageCollect()
+	mu.Lock()
+	collections[generation]++
+	collected[generation] += n
+	mu.Unlock()
+	err = runCallbacks("stop", generation, n)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(n), nil
+}
+
+// Calls the functions in gc.callbacks with the phase of the
+// collection and information about it
+func runCallbacks(phase string, generation, n int) error {
+	m, err := py.GetModule("gc")
+	if err != nil {
+		return err
+	}
+	callbacks, ok := m.Globals["callbacks"]
+	if !ok {
+		return nil
+	}
+	info := py.NewStringDict()
+	info["generation"] = py.Int(generation)
+	info["collected"] = py.Int(n)
+	info["uncollectable"] = py.Int(0)
+	// Call a copy as callbacks may change the list
+	fns, err := py.SequenceTuple(callbacks)
+	if err != nil {
+		return err
+	}
+	for _, fn := range fns {
+		_, err = py.Call(fn, py.Tuple{py.String(phase), info}, nil)
+		if err != nil {
+			py.WriteUnraisable(err, fn)
+		}
+	}
+	return nil
+}
+
+const get_count_doc = `get_count() -> (count0, count1, count2)
+
+Return the current collection counts.
+
+Go's collector doesn't count allocations so these are always 0.`
+
+func gc_get_count(self py.Object) (py.Object, error) {
+	return py.Tuple{py.Int(0), py.Int(0), py.Int(0)}, nil
+}
+
+const get_stats_doc = `get_stats() -> [...]
+
+Return a list of dictionaries containing per-generation statistics.`
+
+func gc_get_stats(self py.Object) (py.Object, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	stats := py.NewListSized(generations)
+	for i := range stats.Items {
+		d := py.NewStringDict()
+		d["collections"] = py.Int(collections[i])
+		d["collected"] = py.Int(collected[i])
+		d["uncollectable"] = py.Int(0)
+		stats.Items[i] = d
+	}
+	return stats, nil
+}
+
+const get_threshold_doc = `get_threshold() -> (threshold0, threshold1, threshold2)
+
+Return the current collection thresholds.`
+
+func gc_get_threshold(self py.Object) (py.Object, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return py.Tuple{py.Int(thresholds[0]), py.Int(thresholds[1]), py.Int(thresholds[2])}, nil
+}
+
+const set_threshold_doc = `set_threshold(threshold0, [threshold1, threshold2]) -> None
+
+Sets the collection thresholds.  They are remembered but have no
+effect as Go's collector decides when to collect.`
+
+func gc_set_threshold(self py.Object, args py.Tuple) (py.Object, error) {
+	var t [generations]py.Object
+	err := py.ParseTuple(args, "i|ii:set_threshold", &t[0], &t[1], &t[2])
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, x := range t {
+		if x != nil {
+			thresholds[i] = int(x.(py.Int))
+		}
+	}
+	return py.None, nil
+}
+
+const get_debug_doc = `get_debug() -> flags
+
+Get the garbage collection debugging flags.`
+
+func gc_get_debug(self py.Object) (py.Object, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return py.Int(debug), nil
+}
+
+const set_debug_doc = `set_debug(flags) -> None
+
+Set the garbage collection debugging flags.  They are remembered but
+have no effect.`
+
+func gc_set_debug(self py.Object, arg py.Object) (py.Object, error) {
+	flags, ok := arg.(py.Int)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "an integer is required (got type %s)", arg.Type().Name)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	debug = int(flags)
+	return py.None, nil
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "gc",
+		Doc:  gc_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("enable", gc_enable, 0, enable_doc),
+			py.MustNewMethod("disable", gc_disable, 0, disable_doc),
+			py.MustNewMethod("isenabled", gc_isenabled, 0, isenabled_doc),
+			py.MustNewMethod("collect", gc_collect, 0, collect_doc),
+			py.MustNewMethod("get_count", gc_get_count, 0, get_count_doc),
+			py.MustNewMethod("get_stats", gc_get_stats, 0, get_stats_doc),
+			py.MustNewMethod("get_threshold", gc_get_threshold, 0, get_threshold_doc),
+			py.MustNewMethod("set_threshold", gc_set_threshold, 0, set_threshold_doc),
+			py.MustNewMethod("get_debug", gc_get_debug, 0, get_debug_doc),
+			py.MustNewMethod("set_debug", gc_set_debug, 0, set_debug_doc),
+		},
+		Globals: py.StringDict{
+			"DEBUG_STATS":         py.Int(DEBUG_STATS),
+			"DEBUG_COLLECTABLE":   py.Int(DEBUG_COLLECTABLE),
+			"DEBUG_UNCOLLECTABLE": py.Int(DEBUG_UNCOLLECTABLE),
+			"DEBUG_SAVEALL":       py.Int(DEBUG_SAVEALL),
+			"DEBUG_LEAK":          py.Int(DEBUG_LEAK),
+		},
+		Init: func(ctx *py.Context, m *py.Module) error {
+			// Each context has lists of its own
+			m.Globals["garbage"] = py.NewList()
+			m.Globals["callbacks"] = py.NewList()
+			return nil
+		},
+	})
+}
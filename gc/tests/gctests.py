@@ -0,0 +1,143 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import gc
+import weakref
+from libtest import *
+
+events = []
+
+class D:
+    def __init__(self, name):
+        self.name = name
+    def __del__(self):
+        events.append(self.name)
+
+doc="collect calls __del__"
+d = D("a")
+del d
+assertTrue(gc.collect() >= 1)
+assertEqual(events, ["a"])
+def f():
+    x = D("b")
+f()
+gc.collect()
+assertEqual(events, ["a", "b"])
+events.clear()
+
+doc="collect leaves live objects alone"
+d = D("alive")
+gc.collect()
+assertEqual(events, [])
+del d
+gc.collect()
+assertEqual(events, ["alive"])
+events.clear()
+
+doc="__del__ errors are ignored"
+class Bad:
+    def __del__(self):
+        events.append("bad")
+        raise ValueError("ignored")
+b = Bad()
+del b
+gc.collect()
+assertEqual(events, ["bad"])
+events.clear()
+
+doc="__del__ is called once"
+saved = []
+class Resurrect:
+    def __del__(self):
+        events.append("del")
+        saved.append(self)
+r = Resurrect()
+del r
+gc.collect()
+assertEqual(events, ["del"])
+assertEqual(len(saved), 1)
+saved.clear()
+gc.collect()
+assertEqual(events, ["del"])
+events.clear()
+
+doc="collect calls weakref callbacks"
+class C:
+    pass
+c = C()
+w = weakref.ref(c, lambda ref: events.append("callback"))
+del c
+gc.collect()
+assertEqual(events, ["callback"])
+assertTrue(w() is None)
+events.clear()
+
+doc="weakref callbacks follow __del__"
+d = D("del")
+w = weakref.ref(d, lambda ref: events.append("callback"))
+del d
+gc.collect()
+assertEqual(events, ["del", "callback"])
+events.clear()
+
+doc="collect generation"
+assertEqual(gc.collect(0), 0)
+assertEqual(gc.collect(generation=1), 0)
+assertRaises(ValueError, gc.collect, 3)
+assertRaises(ValueError, gc.collect, -1)
+assertRaises(TypeError, gc.collect, "x")
+
+doc="enable and disable"
+assertTrue(gc.isenabled())
+gc.disable()
+assertFalse(gc.isenabled())
+d = D("disabled")
+del d
+gc.collect()
+assertEqual(events, ["disabled"])
+gc.enable()
+assertTrue(gc.isenabled())
+events.clear()
+
+doc="counts and stats"
+assertEqual(gc.get_count(), (0, 0, 0))
+stats = gc.get_stats()
+assertEqual(len(stats), 3)
+assertEqual(sorted(stats[2]), ["collected", "collections", "uncollectable"])
+assertTrue(stats[2]["collections"] > 0)
+
+doc="thresholds"
+old = gc.get_threshold()
+assertEqual(old, (700, 10, 10))
+gc.set_threshold(100)
+assertEqual(gc.get_threshold(), (100, 10, 10))
+gc.set_threshold(1, 2, 3)
+assertEqual(gc.get_threshold(), (1, 2, 3))
+gc.set_threshold(*old)
+assertRaises(TypeError, gc.set_threshold)
+
+doc="debug"
+assertEqual(gc.get_debug(), 0)
+gc.set_debug(gc.DEBUG_STATS)
+assertEqual(gc.get_debug(), gc.DEBUG_STATS)
+gc.set_debug(0)
+assertEqual(gc.DEBUG_LEAK, gc.DEBUG_COLLECTABLE | gc.DEBUG_UNCOLLECTABLE | gc.DEBUG_SAVEALL)
+assertRaises(TypeError, gc.set_debug, "x")
+
+doc="callbacks"
+assertEqual(gc.garbage, [])
+phases = []
+def callback(phase, info):
+    phases.append((phase, info["generation"]))
+    assertEqual(info["uncollectable"], 0)
+def bad_callback(phase, info):
+    raise ValueError("ignored")
+gc.callbacks.append(callback)
+gc.callbacks.append(bad_callback)
+gc.collect(1)
+gc.callbacks.clear()
+gc.collect()
+assertEqual(phases, [("start", 1), ("stop", 1)])
+
+doc="finished"
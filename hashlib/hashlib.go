@@ -0,0 +1,373 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Hashlib module
+//
+// The hashes are made with Go's crypto packages apart from blake2b
+// and blake2s which are in blake2.go.
+
+package hashlib
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const hashlib_doc = `hashlib module - A common interface to many hash functions.
+
+new(name, data=b'') - returns a new hash object implementing the
+                      given hash function; initializing the hash
+                      using the given binary data.
+
+Named constructor functions are also available, these are faster
+than using new(name):
+
+md5(), sha1(), sha224(), sha256(), sha384(), sha512(), blake2b(), and
+blake2s()
+
+Hash objects have these methods:
+ - update(data): Update the hash object with the bytes in data. Repeated calls
+                 are equivalent to a single call with the concatenation of all
+                 the arguments.
+ - digest():     Return the digest of the bytes passed to the update() method
+                 so far as a bytes object.
+ - hexdigest():  Like digest() except the digest is returned as a string
+                 of double length, containing only hexadecimal digits.
+ - copy():       Return a copy (clone) of the hash object. This can be used to
+                 efficiently compute the digests of datas that share a common
+                 initial substring.`
+
+var (
+	HashType    = py.NewTypeX("HASH", hash_doc, nil, nil)
+	Blake2bType = py.NewTypeX("blake2b", blake2b_doc, Blake2bNew, nil)
+	Blake2sType = py.NewTypeX("blake2s", blake2s_doc, Blake2sNew, nil)
+)
+
+const hash_doc = `A hash is an object used to calculate a checksum of a string of information.
+
+Methods:
+
+update() -- updates the current digest with an additional string
+digest() -- return the current digest value
+hexdigest() -- return the current digest as a string of hexadecimal digits
+copy() -- return a copy of the current hash object
+
+Attributes:
+
+name -- the hash algorithm being used by this object
+digest_size -- number of bytes in this hashes output`
+
+// The constructors of the hashes made with Go's crypto packages
+var constructors = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha224": sha256.New224,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+	"blake2b": func() hash.Hash {
+		return newBlake2b(blake2bSize, nil, nil, nil)
+	},
+	"blake2s": func() hash.Hash {
+		return newBlake2s(blake2sSize, nil, nil, nil)
+	},
+}
+
+// Constructor returns the function making the hash called name, which
+// isn't case sensitive
+func Constructor(name string) (func() hash.Hash, error) {
+	if fn, ok := constructors[strings.ToLower(name)]; ok {
+		return fn, nil
+	}
+	return nil, py.ExceptionNewf(py.ValueError, "unsupported hash type %s", name)
+}
+
+// Names returns the names of the hashes in sorted order
+func Names() []string {
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clone returns a copy of the hash h made by newHash with all the data
+// written to h so far
+func Clone(h hash.Hash, newHash func() hash.Hash) (hash.Hash, error) {
+	if c, ok := h.(interface{ Clone() hash.Hash }); ok {
+		return c.Clone(), nil
+	}
+	m, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, py.ExceptionNewf(py.ValueError, "hash can't be copied")
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return nil, py.ExceptionNewf(py.ValueError, "%v", err)
+	}
+	c := newHash()
+	if err = c.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, py.ExceptionNewf(py.ValueError, "%v", err)
+	}
+	return c, nil
+}
+
+// DataArg returns the bytes of a bytes like object to hash
+func DataArg(data py.Object) ([]byte, error) {
+	switch b := data.(type) {
+	case py.Bytes:
+		return b, nil
+	case *py.ByteArray:
+		return b.Data, nil
+	case py.String:
+		return nil, py.ExceptionNewf(py.TypeError, "Strings must be encoded before hashing")
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "object supporting the buffer API required")
+}
+
+// Hash is a hash object
+type Hash struct {
+	name    string
+	typ     *py.Type
+	hash    hash.Hash
+	newHash func() hash.Hash // makes an empty hash like this one
+}
+
+// Type of this object
+func (h *Hash) Type() *py.Type {
+	return h.typ
+}
+
+// NewHash makes a Hash called name hashing data, which may be nil
+func NewHash(name string, data py.Object) (*Hash, error) {
+	fn, err := Constructor(name)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	typ := HashType
+	switch name {
+	case "blake2b":
+		typ = Blake2bType
+	case "blake2s":
+		typ = Blake2sType
+	}
+	return newHash(name, typ, fn, data)
+}
+
+// Makes a Hash of type typ hashing data
+func newHash(name string, typ *py.Type, fn func() hash.Hash, data py.Object) (*Hash, error) {
+	h := &Hash{
+		name:    name,
+		typ:     typ,
+		hash:    fn(),
+		newHash: fn,
+	}
+	if data != nil {
+		if err := h.Update(data); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Update adds the bytes of data to the hash
+func (h *Hash) Update(data py.Object) error {
+	b, err := DataArg(data)
+	if err != nil {
+		return err
+	}
+	_, _ = h.hash.Write(b)
+	return nil
+}
+
+// Digest returns the digest of the data so far
+func (h *Hash) Digest() []byte {
+	return h.hash.Sum(nil)
+}
+
+// Copy returns a copy of the hash
+func (h *Hash) Copy() (*Hash, error) {
+	c, err := Clone(h.hash, h.newHash)
+	if err != nil {
+		return nil, err
+	}
+	return &Hash{
+		name:    h.name,
+		typ:     h.typ,
+		hash:    c,
+		newHash: h.newHash,
+	}, nil
+}
+
+func (h *Hash) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<%s %s object @ %p>", h.name, h.typ.Name, h)), nil
+}
+
+func init() {
+	for _, t := range []*py.Type{HashType, Blake2bType, Blake2sType} {
+		t.Dict["update"] = py.MustNewMethod("update", func(self, data py.Object) (py.Object, error) {
+			return py.None, self.(*Hash).Update(data)
+		}, 0, "Update this hash object's state with the provided string.")
+		t.Dict["digest"] = py.MustNewMethod("digest", func(self py.Object) (py.Object, error) {
+			return py.Bytes(self.(*Hash).Digest()), nil
+		}, 0, "Return the digest value as a bytes object.")
+		t.Dict["hexdigest"] = py.MustNewMethod("hexdigest", func(self py.Object) (py.Object, error) {
+			return py.String(hex.EncodeToString(self.(*Hash).Digest())), nil
+		}, 0, "Return the digest value as a string of hexadecimal digits.")
+		t.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+			return self.(*Hash).Copy()
+		}, 0, "Return a copy of the hash object.")
+		t.Dict["name"] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return py.String(self.(*Hash).name), nil
+			},
+		}
+		t.Dict["digest_size"] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return py.Int(self.(*Hash).hash.Size()), nil
+			},
+		}
+		t.Dict["block_size"] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return py.Int(self.(*Hash).hash.BlockSize()), nil
+			},
+		}
+	}
+	Blake2bType.Dict["SALT_SIZE"] = py.Int(blake2bSaltSize)
+	Blake2bType.Dict["PERSON_SIZE"] = py.Int(blake2bSaltSize)
+	Blake2bType.Dict["MAX_KEY_SIZE"] = py.Int(blake2bSize)
+	Blake2bType.Dict["MAX_DIGEST_SIZE"] = py.Int(blake2bSize)
+	Blake2sType.Dict["SALT_SIZE"] = py.Int(blake2sSaltSize)
+	Blake2sType.Dict["PERSON_SIZE"] = py.Int(blake2sSaltSize)
+	Blake2sType.Dict["MAX_KEY_SIZE"] = py.Int(blake2sSize)
+	Blake2sType.Dict["MAX_DIGEST_SIZE"] = py.Int(blake2sSize)
+}
+
+const blake2b_doc = `blake2b(data=b'', *, digest_size=64, key=b'', salt=b'', person=b'')
+
+Return a new BLAKE2b hash object.`
+
+const blake2s_doc = `blake2s(data=b'', *, digest_size=32, key=b'', salt=b'', person=b'')
+
+Return a new BLAKE2s hash object.`
+
+// Blake2bNew makes a blake2b hash
+func Blake2bNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newBlake2(metatype, "blake2b", blake2bSize, blake2bSaltSize, newBlake2b, args, kwargs)
+}
+
+// Blake2sNew makes a blake2s hash
+func Blake2sNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newBlake2(metatype, "blake2s", blake2sSize, blake2sSaltSize, newBlake2s, args, kwargs)
+}
+
+// Makes a blake2b or blake2s hash from its arguments
+func newBlake2(metatype *py.Type, name string, maxSize, saltSize int, fn func(size int, key, salt, person []byte) hash.Hash, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var data, digestSize, key, salt, person, usedForSecurity py.Object = nil, py.Int(maxSize), py.Bytes{}, py.Bytes{}, py.Bytes{}, py.True
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOO:"+name, []string{"data", "digest_size", "key", "salt", "person", "usedforsecurity"}, &data, &digestSize, &key, &salt, &person, &usedForSecurity)
+	if err != nil {
+		return nil, err
+	}
+	size, err := py.IndexInt(digestSize)
+	if err != nil {
+		return nil, err
+	}
+	if size < 1 || size > maxSize {
+		return nil, py.ExceptionNewf(py.ValueError, "digest_size must be between 1 and %d bytes", maxSize)
+	}
+	var params [3][]byte
+	for i, obj := range []py.Object{key, salt, person} {
+		if params[i], err = DataArg(obj); err != nil {
+			return nil, err
+		}
+	}
+	if len(params[0]) > maxSize {
+		return nil, py.ExceptionNewf(py.ValueError, "maximum key length is %d bytes", maxSize)
+	}
+	if len(params[1]) > saltSize {
+		return nil, py.ExceptionNewf(py.ValueError, "maximum salt length is %d bytes", saltSize)
+	}
+	if len(params[2]) > saltSize {
+		return nil, py.ExceptionNewf(py.ValueError, "maximum person length is %d bytes", saltSize)
+	}
+	newFn := func() hash.Hash {
+		return fn(size, params[0], params[1], params[2])
+	}
+	return newHash(name, metatype, newFn, data)
+}
+
+const new_doc = `new(name, data=b'') - Return a new hashing object using the named algorithm;
+optionally initialized with data (which must be a bytes-like object).`
+
+func hashlib_new(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name, data, usedForSecurity py.Object = nil, nil, py.True
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:new", []string{"name", "data", "usedforsecurity"}, &name, &data, &usedForSecurity)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := name.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "new() argument 'name' must be str, not %s", name.Type().Name)
+	}
+	return NewHash(string(n), data)
+}
+
+// Makes the constructor function of the hash called name
+func constructor(name string) *py.Method {
+	doc := fmt.Sprintf("Returns a %s hash object; optionally initialized with a string", name)
+	return py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var data, usedForSecurity py.Object = nil, py.True
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:"+name, []string{"string", "usedforsecurity"}, &data, &usedForSecurity)
+		if err != nil {
+			return nil, err
+		}
+		return NewHash(name, data)
+	}, 0, doc)
+}
+
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("new", hashlib_new, 0, new_doc),
+	}
+	names := Names()
+	algorithms := make([]py.Object, len(names))
+	for i, name := range names {
+		algorithms[i] = py.String(name)
+		if name != "blake2b" && name != "blake2s" {
+			methods = append(methods, constructor(name))
+		}
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "hashlib",
+		Doc:     hashlib_doc,
+		Methods: methods,
+		Globals: py.StringDict{
+			"blake2b": Blake2bType,
+			"blake2s": Blake2sType,
+		},
+		Init: func(ctx *py.Context, m *py.Module) error {
+			// Sets so each context has its own
+			for _, name := range []string{"algorithms_guaranteed", "algorithms_available"} {
+				set, err := py.NewSetFromItems(algorithms)
+				if err != nil {
+					return err
+				}
+				m.Globals[name] = set
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,73 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import hashlib
+from libtest import *
+
+doc = "digests"
+assert hashlib.md5(b"abc").hexdigest() == "900150983cd24fb0d6963f7d28e17f72"
+assert hashlib.sha1(b"abc").hexdigest() == "a9993e364706816aba3e25717850c26c9cd0d89d"
+assert hashlib.sha224(b"abc").hexdigest() == "23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7"
+assert hashlib.sha256(b"abc").hexdigest() == "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+assert hashlib.sha384(b"abc").hexdigest() == "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7"
+assert hashlib.sha512(b"abc").hexdigest() == "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"
+assert hashlib.md5().digest() == b"\xd4\x1d\x8c\xd9\x8f\x00\xb2\x04\xe9\x80\t\x98\xec\xf8B~"
+
+doc = "blake2"
+assert hashlib.blake2b(b"abc").hexdigest() == "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+assert hashlib.blake2s(b"abc").hexdigest() == "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982"
+assert hashlib.blake2b(b"y"*256).hexdigest() == "88fae5ed39dccbc801bee57a2bf4f1337e570b2c1a39f4062cfcf5dcb8e2b52fb4b794c857c030e78fa66e9b33568741488506f3ddaf5744ec1504d783b16fbf"
+assert hashlib.blake2b(key=b"key").hexdigest() == "5b3cfd8f422b490b764b55eceb330b500c79cbefa9a928ad00202b8b3c5dd778a81122570434a2e3b8bfd028d105dfefd0a9576e88ed66de742ca9fbb5f8d2b6"
+h = hashlib.blake2b(b"abc", digest_size=16, key=b"k", salt=b"s", person=b"p")
+assert h.hexdigest() == "2384c8bdb172cede006930ab820cbe76"
+assert h.digest_size == 16
+assert h.block_size == 128
+assert h.name == "blake2b"
+h = hashlib.blake2s(b"x"*200, digest_size=20, key=b"key")
+assert h.hexdigest() == "19e7b2485fd6b80bf86f91a19d0b39fed1053ac0"
+assert h.block_size == 64
+assert hashlib.blake2b.SALT_SIZE == 16
+assert hashlib.blake2s.MAX_DIGEST_SIZE == 32
+assertRaises(ValueError, hashlib.blake2b, digest_size=65)
+assertRaises(ValueError, hashlib.blake2s, key=b"k"*33)
+assertRaises(ValueError, hashlib.blake2s, salt=b"s"*9)
+assert isinstance(hashlib.blake2b(), hashlib.blake2b)
+
+doc = "update"
+h = hashlib.sha256()
+h.update(b"a")
+h.update(bytearray(b"b"))
+h.update(b"c")
+assert h.hexdigest() == hashlib.sha256(b"abc").hexdigest()
+assert h.digest() == h.digest()
+assertRaisesText(TypeError, "Strings must be encoded before hashing", h.update, "abc")
+assertRaises(TypeError, hashlib.md5, "abc")
+assert h.name == "sha256"
+assert h.digest_size == 32
+assert h.block_size == 64
+assert hashlib.sha512().digest_size == 64
+assert hashlib.md5(string=b"abc").hexdigest() == "900150983cd24fb0d6963f7d28e17f72"
+
+doc = "copy"
+for name in ("md5", "sha1", "sha224", "sha256", "sha384", "sha512", "blake2b", "blake2s"):
+    h = hashlib.new(name, b"common ")
+    c = h.copy()
+    h.update(b"one")
+    c.update(b"two")
+    assert h.hexdigest() == hashlib.new(name, b"common one").hexdigest(), name
+    assert c.hexdigest() == hashlib.new(name, b"common two").hexdigest(), name
+h = hashlib.blake2b(b"x", digest_size=10, key=b"k")
+c = h.copy()
+assert c.digest_size == 10
+assert c.hexdigest() == h.hexdigest()
+
+doc = "new"
+assert hashlib.new("SHA256", b"abc").hexdigest() == hashlib.sha256(b"abc").hexdigest()
+assert hashlib.new("md5").name == "md5"
+assertRaises(ValueError, hashlib.new, "nosuchhash")
+assert "sha256" in hashlib.algorithms_guaranteed
+assert "blake2s" in hashlib.algorithms_available
+assert "HASH object" in repr(hashlib.md5())
+
+doc = "finished"
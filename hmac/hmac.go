@@ -0,0 +1,335 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Hmac module
+//
+// HMAC is worked out here rather than with crypto/hmac so the inner
+// and outer hashes can be copied by copy().
+
+package hmac
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/go-python/gpython/hashlib"
+	"github.com/go-python/gpython/py"
+)
+
+const hmac_doc = `HMAC (Keyed-Hashing for Message Authentication) Python module.
+
+Implements the HMAC algorithm as described by RFC 2104.`
+
+var HMACType = py.NewTypeX("HMAC", hmac_type_doc, HMACNew, nil)
+
+const hmac_type_doc = `RFC 2104 HMAC class.  Also complies with RFC 4231.
+
+This supports the API for Cryptographic Hash Functions (PEP 247).`
+
+// HMAC is an hmac object
+type HMAC struct {
+	name    string
+	inner   hash.Hash // hashing ipad then the message
+	outer   hash.Hash // which has hashed opad
+	newHash func() hash.Hash
+}
+
+// Type of this object
+func (h *HMAC) Type() *py.Type {
+	return HMACType
+}
+
+// Returns the name of the hash and the function making it for the
+// digestmod argument, which is a name, a hashlib constructor or a
+// module with a new function
+func digestmodArg(digestmod py.Object) (string, func() hash.Hash, error) {
+	var name string
+	switch d := digestmod.(type) {
+	case py.NoneType:
+		return "", nil, py.ExceptionNewf(py.TypeError, "Missing required parameter 'digestmod'.")
+	case py.String:
+		name = string(d)
+	case *py.Method:
+		name = d.Name
+	case *py.Type:
+		name = d.Name
+	default:
+		// A module with new() such as hashlib.sha256's module
+		newFn, err := py.GetAttrString(digestmod, "new")
+		if err != nil {
+			return "", nil, py.ExceptionNewf(py.TypeError, "digestmod must be a hash name, constructor or module")
+		}
+		h, err := py.Call(newFn, nil, nil)
+		if err != nil {
+			return "", nil, err
+		}
+		nameObj, err := py.GetAttrString(h, "name")
+		if err != nil {
+			return "", nil, err
+		}
+		if name, err = py.StrAsString(nameObj); err != nil {
+			return "", nil, err
+		}
+	}
+	fn, err := hashlib.Constructor(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, fn, nil
+}
+
+// New makes an HMAC of the key hashed with newHash
+func New(name string, newHash func() hash.Hash, key []byte) *HMAC {
+	h := &HMAC{
+		name:    name,
+		inner:   newHash(),
+		outer:   newHash(),
+		newHash: newHash,
+	}
+	blockSize := h.inner.BlockSize()
+	if len(key) > blockSize {
+		k := newHash()
+		_, _ = k.Write(key)
+		key = k.Sum(nil)
+	}
+	ipad := make([]byte, blockSize)
+	opad := make([]byte, blockSize)
+	copy(ipad, key)
+	copy(opad, key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	_, _ = h.inner.Write(ipad)
+	_, _ = h.outer.Write(opad)
+	return h
+}
+
+// Digest returns the digest of the message so far
+func (h *HMAC) Digest() ([]byte, error) {
+	outer, err := hashlib.Clone(h.outer, h.newHash)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = outer.Write(h.inner.Sum(nil))
+	return outer.Sum(nil), nil
+}
+
+// Copy returns a copy of the HMAC
+func (h *HMAC) Copy() (*HMAC, error) {
+	inner, err := hashlib.Clone(h.inner, h.newHash)
+	if err != nil {
+		return nil, err
+	}
+	outer, err := hashlib.Clone(h.outer, h.newHash)
+	if err != nil {
+		return nil, err
+	}
+	return &HMAC{
+		name:    h.name,
+		inner:   inner,
+		outer:   outer,
+		newHash: h.newHash,
+	}, nil
+}
+
+func (h *HMAC) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<hmac.HMAC object @ %p>", h)), nil
+}
+
+func init() {
+	HMACType.Dict["update"] = py.MustNewMethod("update", func(self, msg py.Object) (py.Object, error) {
+		b, err := hashlib.DataArg(msg)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = self.(*HMAC).inner.Write(b)
+		return py.None, nil
+	}, 0, `Feed data from msg into this hashing object.`)
+	HMACType.Dict["digest"] = py.MustNewMethod("digest", func(self py.Object) (py.Object, error) {
+		digest, err := self.(*HMAC).Digest()
+		if err != nil {
+			return nil, err
+		}
+		return py.Bytes(digest), nil
+	}, 0, `Return the hash value of this hashing object.
+
+This returns the hmac value as bytes.  The object is
+not altered in any way by this function; you can continue
+updating the object after calling this function.`)
+	HMACType.Dict["hexdigest"] = py.MustNewMethod("hexdigest", func(self py.Object) (py.Object, error) {
+		digest, err := self.(*HMAC).Digest()
+		if err != nil {
+			return nil, err
+		}
+		return py.String(hex.EncodeToString(digest)), nil
+	}, 0, `Like digest(), but returns a string of hexadecimal digits instead.`)
+	HMACType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*HMAC).Copy()
+	}, 0, `Return a separate copy of this hashing object.
+
+An update to this copy won't affect the original object.`)
+	HMACType.Dict["name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String("hmac-" + self.(*HMAC).name), nil
+		},
+	}
+	HMACType.Dict["digest_size"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*HMAC).inner.Size()), nil
+		},
+	}
+	HMACType.Dict["block_size"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*HMAC).inner.BlockSize()), nil
+		},
+	}
+}
+
+const new_doc = `new(key, msg=None, digestmod=None) -> HMAC
+
+Create a new hashing object and return it.
+
+key: bytes or buffer, The starting key for the hash.
+msg: bytes or buffer, Initial input for the hash, or None.
+digestmod: A hash name suitable for hashlib.new(), a hashlib
+           constructor or a module supporting PEP 247.  Required.
+
+You can now feed arbitrary bytes into the object using its update()
+method, and can ask for the hash value at any time by calling its digest()
+or hexdigest() methods.`
+
+func hmac_new(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return HMACNew(HMACType, args, kwargs)
+}
+
+// HMACNew makes an HMAC(key, msg=None, digestmod=None)
+func HMACNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var key, msg, digestmod py.Object = nil, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:new", []string{"key", "msg", "digestmod"}, &key, &msg, &digestmod)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyArg(key)
+	if err != nil {
+		return nil, err
+	}
+	name, fn, err := digestmodArg(digestmod)
+	if err != nil {
+		return nil, err
+	}
+	h := New(name, fn, k)
+	if msg != py.None {
+		b, err := hashlib.DataArg(msg)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = h.inner.Write(b)
+	}
+	return h, nil
+}
+
+// Returns the bytes of the key
+func keyArg(key py.Object) ([]byte, error) {
+	switch key.(type) {
+	case py.Bytes, *py.ByteArray:
+		return hashlib.DataArg(key)
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "key: expected bytes or bytearray, but got '%s'", key.Type().Name)
+}
+
+const digest_doc = `digest(key, msg, digest) -> bytes
+
+Fast inline implementation of HMAC.
+
+key: bytes or buffer, The key for the keyed hash object.
+msg: bytes or buffer, Input message.
+digest: A hash name suitable for hashlib.new() or a hashlib
+        constructor.`
+
+func hmac_digest(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var key, msg, digest py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO:digest", []string{"key", "msg", "digest"}, &key, &msg, &digest)
+	if err != nil {
+		return nil, err
+	}
+	k, err := keyArg(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hashlib.DataArg(msg)
+	if err != nil {
+		return nil, err
+	}
+	name, fn, err := digestmodArg(digest)
+	if err != nil {
+		return nil, err
+	}
+	h := New(name, fn, k)
+	_, _ = h.inner.Write(b)
+	d, err := h.Digest()
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(d), nil
+}
+
+const compare_digest_doc = `compare_digest(a, b) -> bool
+
+Return 'a == b'.
+
+This function uses an approach designed to prevent
+timing analysis, making it appropriate for cryptography.
+
+a and b must both be of the same type: either str (ASCII only),
+or any bytes-like object.`
+
+func hmac_compare_digest(self py.Object, args py.Tuple) (py.Object, error) {
+	var a, b py.Object
+	err := py.UnpackTuple(args, nil, "compare_digest", 2, 2, &a, &b)
+	if err != nil {
+		return nil, err
+	}
+	var x, y []byte
+	if sa, ok := a.(py.String); ok {
+		sb, ok := b.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "unsupported operand types(s) or combination of types: '%s' and '%s'", a.Type().Name, b.Type().Name)
+		}
+		for _, s := range []py.String{sa, sb} {
+			for _, r := range s {
+				if r > 127 {
+					return nil, py.ExceptionNewf(py.TypeError, "comparing strings with non-ASCII characters is not supported")
+				}
+			}
+		}
+		x, y = []byte(sa), []byte(sb)
+	} else {
+		if x, err = hashlib.DataArg(a); err != nil {
+			return nil, py.ExceptionNewf(py.TypeError, "unsupported operand types(s) or combination of types: '%s' and '%s'", a.Type().Name, b.Type().Name)
+		}
+		if y, err = hashlib.DataArg(b); err != nil {
+			return nil, py.ExceptionNewf(py.TypeError, "unsupported operand types(s) or combination of types: '%s' and '%s'", a.Type().Name, b.Type().Name)
+		}
+	}
+	return py.NewBool(subtle.ConstantTimeCompare(x, y) == 1), nil
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "hmac",
+		Doc:  hmac_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("new", hmac_new, 0, new_doc),
+			py.MustNewMethod("digest", hmac_digest, 0, digest_doc),
+			py.MustNewMethod("compare_digest", hmac_compare_digest, 0, compare_digest_doc),
+		},
+		Globals: py.StringDict{
+			"HMAC":        HMACType,
+			"digest_size": py.None,
+		},
+	})
+}
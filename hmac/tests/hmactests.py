@@ -0,0 +1,51 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import hmac
+import hashlib
+from libtest import *
+
+msg = b"The quick brown fox jumps over the lazy dog"
+want = "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+
+doc = "new"
+assert hmac.new(b"key", msg, "sha256").hexdigest() == want
+assert hmac.new(b"key", msg, digestmod=hashlib.sha256).hexdigest() == want
+assert hmac.HMAC(b"key", msg, "sha256").hexdigest() == want
+assert hmac.new(b"key", digestmod="md5").hexdigest() == "63530468a04e386459855da0063b6596"
+assert hmac.new(b"k"*200, b"msg", "sha512").hexdigest() == "b5245971beb52a5a986812c4666a05c735bf5bb7aba32eae2192adad605df4112d6c285d1c46cf81ccb7ab8c2c3b7b3c6793216909b5add05223ed21f24cdb1e"
+assert hmac.new(b"key", b"msg", hashlib.blake2s).hexdigest() == "7732085652cb630ef3c124b68f2470f5f6209189c913dabc862b2b062f0242c8"
+assertRaisesText(TypeError, "digestmod", hmac.new, b"key", msg)
+assertRaises(TypeError, hmac.new, "key", msg, "sha256")
+assertRaises(ValueError, hmac.new, b"key", msg, "nosuchhash")
+
+doc = "update and copy"
+h = hmac.new(b"key", digestmod="sha256")
+assert h.name == "hmac-sha256"
+assert h.digest_size == 32
+assert h.block_size == 64
+h.update(b"The quick brown fox ")
+c = h.copy()
+h.update(b"jumps over the lazy dog")
+assert h.hexdigest() == want
+assert h.digest() == bytes.fromhex(want)
+c.update(b"jumps over the lazy cat")
+assert c.hexdigest() != want
+c.update(b"")
+assert h.hexdigest() == want
+assertRaises(TypeError, h.update, "str")
+
+doc = "digest"
+assert hmac.digest(b"key", msg, "sha256") == bytes.fromhex(want)
+
+doc = "compare_digest"
+assert hmac.compare_digest(b"abc", b"abc")
+assert not hmac.compare_digest(b"abc", b"abd")
+assert not hmac.compare_digest(b"abc", b"ab")
+assert hmac.compare_digest("abc", "abc")
+assert hmac.compare_digest(bytearray(b"x"), b"x")
+assertRaises(TypeError, hmac.compare_digest, "abc", b"abc")
+assertRaises(TypeError, hmac.compare_digest, "\xe9", "\xe9")
+
+doc = "finished"
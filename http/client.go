@@ -0,0 +1,607 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// HTTP modules
+//
+// http.client is a small subset of the python module made with Go's
+// net/http.  The request is sent and the response headers read by
+// request() rather than getresponse() as net/http does both at once,
+// so errors connecting are raised by request().  Headers are stored
+// under the canonical names net/http gives them, eg Content-Type.
+
+package http
+
+import (
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/socket"
+	"github.com/go-python/gpython/vm"
+)
+
+const http_doc = `HTTP modules
+
+Only the http.client module is available.`
+
+const client_doc = `HTTP/1.1 client library
+
+HTTPConnection goes through a number of "states", which define when a client
+may legally make another request or fetch the response for a particular
+request.  Here a request is sent with request() and its response
+returned by getresponse().`
+
+// Default ports
+const (
+	HTTP_PORT  = 80
+	HTTPS_PORT = 443
+)
+
+var (
+	HTTPException           = py.ExceptionType.NewType("HTTPException", "Base class for the exceptions of http.client.", nil, nil)
+	InvalidURL              = HTTPException.NewType("InvalidURL", "Raised for a host or port which can't be parsed.", nil, nil)
+	ImproperConnectionState = HTTPException.NewType("ImproperConnectionState", "Raised when a connection is used in the wrong state.", nil, nil)
+	ResponseNotReady        = ImproperConnectionState.NewType("ResponseNotReady", "Raised by getresponse() when no request has been sent.", nil, nil)
+
+	HTTPConnectionType  = py.NewTypeX("HTTPConnection", http_connection_doc, HTTPConnectionNew, nil)
+	HTTPSConnectionType = HTTPConnectionType.NewType("HTTPSConnection", https_connection_doc, HTTPSConnectionNew, nil)
+	HTTPResponseType    = py.NewTypeX("HTTPResponse", http_response_doc, nil, nil)
+)
+
+const http_connection_doc = `HTTPConnection(host, port=None, timeout=None)
+
+A connection to an HTTP server.  host may include the port as
+"host:port".  timeout is in seconds, None to wait forever.`
+
+const https_connection_doc = `HTTPSConnection(host, port=None, timeout=None)
+
+A connection to an HTTP server using TLS.`
+
+// HTTPConnection is a connection to a server
+type HTTPConnection struct {
+	host     string
+	port     int
+	timeout  float64 // in seconds, negative to block forever
+	https    bool
+	client   *nethttp.Client
+	response *HTTPResponse // from the last request, until getresponse
+}
+
+// Type of this object
+func (c *HTTPConnection) Type() *py.Type {
+	if c.https {
+		return HTTPSConnectionType
+	}
+	return HTTPConnectionType
+}
+
+// HTTPConnectionNew makes an HTTPConnection
+func HTTPConnectionNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newConnection("HTTPConnection", false, args, kwargs)
+}
+
+// HTTPSConnectionNew makes an HTTPSConnection
+func HTTPSConnectionNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newConnection("HTTPSConnection", true, args, kwargs)
+}
+
+// Makes an HTTPConnection from its arguments
+func newConnection(name string, https bool, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var host, port, timeout py.Object = nil, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:"+name, []string{"host", "port", "timeout"}, &host, &port, &timeout)
+	if err != nil {
+		return nil, err
+	}
+	h, ok := host.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "host must be str, not %s", host.Type().Name)
+	}
+	c := &HTTPConnection{
+		host:  string(h),
+		port:  HTTP_PORT,
+		https: https,
+	}
+	if https {
+		c.port = HTTPS_PORT
+	}
+	if port != py.None {
+		if c.port, err = py.IndexInt(port); err != nil {
+			return nil, err
+		}
+	} else if i := strings.LastIndexByte(c.host, ':'); i > strings.LastIndexByte(c.host, ']') {
+		if i < len(c.host)-1 {
+			c.port, err = strconv.Atoi(c.host[i+1:])
+			if err != nil {
+				return nil, py.ExceptionNewf(InvalidURL, "nonnumeric port: '%s'", c.host[i+1:])
+			}
+		}
+		c.host = c.host[:i]
+	}
+	c.host = strings.TrimSuffix(strings.TrimPrefix(c.host, "["), "]")
+	if c.timeout, err = TimeoutArg(timeout); err != nil {
+		return nil, err
+	}
+	c.client = NewClient(c.timeout)
+	// http.client doesn't follow redirects
+	c.client.CheckRedirect = func(req *nethttp.Request, via []*nethttp.Request) error {
+		return nethttp.ErrUseLastResponse
+	}
+	return c, nil
+}
+
+// NewClient makes a client which doesn't share its connections with
+// the other clients, with a timeout in seconds or negative for none
+func NewClient(timeout float64) *nethttp.Client {
+	client := &nethttp.Client{
+		Transport: nethttp.DefaultTransport.(*nethttp.Transport).Clone(),
+	}
+	if timeout >= 0 {
+		client.Timeout = time.Duration(timeout * float64(time.Second))
+	}
+	return client
+}
+
+// TimeoutArg returns a timeout argument in seconds or -1 for None
+func TimeoutArg(timeout py.Object) (float64, error) {
+	if timeout == py.None {
+		return -1, nil
+	}
+	f, err := py.FloatAsFloat64(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 {
+		return 0, py.ExceptionNewf(py.ValueError, "Timeout value out of range")
+	}
+	return f, nil
+}
+
+// Returns the URL of path on the server
+func (c *HTTPConnection) url(path string) string {
+	scheme := "http"
+	if c.https {
+		scheme = "https"
+	}
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	host := c.host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return scheme + "://" + host + ":" + strconv.Itoa(c.port) + path
+}
+
+// BodyBytes returns the body of a request which may be None, str,
+// a bytes like object or a file object
+func BodyBytes(body py.Object) ([]byte, error) {
+	switch b := body.(type) {
+	case py.NoneType:
+		return nil, nil
+	case py.String:
+		return []byte(b), nil
+	case py.Bytes:
+		return b, nil
+	case *py.ByteArray:
+		return b.Data, nil
+	}
+	if read, err := py.GetAttrString(body, "read"); err == nil {
+		data, err := py.Call(read, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return BodyBytes(data)
+	}
+	return py.BytesFromObject(body)
+}
+
+// AddHeaders adds the headers in the mapping to header
+func AddHeaders(header nethttp.Header, headers py.Object) error {
+	items, err := py.GetAttrString(headers, "items")
+	if err != nil {
+		return err
+	}
+	items, err = py.Call(items, nil, nil)
+	if err != nil {
+		return err
+	}
+	var loopErr error
+	err = py.Iterate(items, func(item py.Object) bool {
+		kv, ok := item.(py.Tuple)
+		if !ok || len(kv) != 2 {
+			loopErr = py.ExceptionNewf(py.TypeError, "headers must be a mapping")
+			return true
+		}
+		key, err := py.StrAsString(kv[0])
+		if err != nil {
+			loopErr = err
+			return true
+		}
+		value, err := py.StrAsString(kv[1])
+		if err != nil {
+			loopErr = err
+			return true
+		}
+		header.Set(key, value)
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	return loopErr
+}
+
+// Do sends the request returning its response
+func Do(client *nethttp.Client, req *nethttp.Request) (*HTTPResponse, error) {
+	var resp *nethttp.Response
+	var err error
+	vm.AllowThreads(func() {
+		resp, err = client.Do(req)
+	})
+	if err != nil {
+		return nil, socket.NetError(err)
+	}
+	return NewHTTPResponse(resp), nil
+}
+
+// Request sends a request with the method to the path on the server
+func (c *HTTPConnection) Request(method, path string, body py.Object, headers py.Object) error {
+	data, err := BodyBytes(body)
+	if err != nil {
+		return err
+	}
+	var reader io.Reader
+	if body != py.None {
+		reader = strings.NewReader(string(data))
+	}
+	url := c.url(path)
+	req, err := nethttp.NewRequest(method, url, reader)
+	if err != nil {
+		return py.ExceptionNewf(InvalidURL, "%v", err)
+	}
+	if headers != py.None {
+		if err = AddHeaders(req.Header, headers); err != nil {
+			return err
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	if c.response != nil {
+		_ = c.response.Close()
+		c.response = nil
+	}
+	c.response, err = Do(c.client, req)
+	return err
+}
+
+// Close closes the connection and any response which hasn't been read
+func (c *HTTPConnection) Close() error {
+	if c.response != nil {
+		_ = c.response.Close()
+		c.response = nil
+	}
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+func (c *HTTPConnection) M__repr__() (py.Object, error) {
+	return py.String("<http.client." + c.Type().Name + " object to " + c.host + ":" + strconv.Itoa(c.port) + ">"), nil
+}
+
+func (c *HTTPConnection) M__enter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *HTTPConnection) M__exit__(exc_type, exc_value, traceback py.Object) (py.Object, error) {
+	return py.None, c.Close()
+}
+
+func init() {
+	HTTPConnectionType.Dict["request"] = py.MustNewMethod("request", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var method, url, body, headers py.Object = nil, nil, py.None, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "OO|OO:request", []string{"method", "url", "body", "headers"}, &method, &url, &body, &headers)
+		if err != nil {
+			return nil, err
+		}
+		m, err := py.StrAsString(method)
+		if err != nil {
+			return nil, err
+		}
+		u, err := py.StrAsString(url)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*HTTPConnection).Request(m, u, body, headers)
+	}, 0, `request(method, url, body=None, headers={})
+
+Send a complete request to the server.  body may be str, bytes or a
+file object and headers a mapping of header names to values.`)
+	HTTPConnectionType.Dict["getresponse"] = py.MustNewMethod("getresponse", func(self py.Object) (py.Object, error) {
+		c := self.(*HTTPConnection)
+		if c.response == nil {
+			return nil, py.ExceptionNewf(ResponseNotReady, "Request-sent")
+		}
+		resp := c.response
+		c.response = nil
+		return resp, nil
+	}, 0, `getresponse() -> HTTPResponse
+
+Get the response from the server for the last request.`)
+	HTTPConnectionType.Dict["close"] = py.MustNewMethod("close", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*HTTPConnection).Close()
+	}, 0, `close()
+
+Close the connection to the HTTP server.`)
+	HTTPConnectionType.Dict["set_debuglevel"] = py.MustNewMethod("set_debuglevel", func(self, level py.Object) (py.Object, error) {
+		return py.None, nil
+	}, 0, `set_debuglevel(level)
+
+Accepted for compatibility, nothing is printed.`)
+	HTTPConnectionType.Dict["host"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*HTTPConnection).host), nil
+		},
+	}
+	HTTPConnectionType.Dict["port"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*HTTPConnection).port), nil
+		},
+	}
+	HTTPConnectionType.Dict["timeout"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			timeout := self.(*HTTPConnection).timeout
+			if timeout < 0 {
+				return py.None, nil
+			}
+			return py.Float(timeout), nil
+		},
+	}
+}
+
+// ------------------------------------------------------------
+// HTTPResponse
+
+const http_response_doc = `HTTPResponse
+
+The response to a request returned by HTTPConnection.getresponse()
+and urllib.request.urlopen().  The body is read with read().`
+
+// HTTPResponse is the response to a request
+type HTTPResponse struct {
+	resp   *nethttp.Response
+	url    string
+	closed bool
+}
+
+// Type of this object
+func (r *HTTPResponse) Type() *py.Type {
+	return HTTPResponseType
+}
+
+// NewHTTPResponse makes an HTTPResponse
+func NewHTTPResponse(resp *nethttp.Response) *HTTPResponse {
+	r := &HTTPResponse{
+		resp: resp,
+	}
+	// The URL of the last request if there were redirects
+	if resp.Request != nil {
+		r.url = resp.Request.URL.String()
+	}
+	return r
+}
+
+// Status returns the status code of the response
+func (r *HTTPResponse) Status() int {
+	return r.resp.StatusCode
+}
+
+// Reason returns the reason phrase of the response, eg "Not Found"
+func (r *HTTPResponse) Reason() string {
+	return strings.TrimPrefix(r.resp.Status, strconv.Itoa(r.resp.StatusCode)+" ")
+}
+
+// Headers returns the headers of the response in a dict, joining
+// the values of headers given more than once with ", "
+func (r *HTTPResponse) Headers() *py.Dict {
+	keys := r.headerKeys()
+	headers := py.NewDictSized(len(keys))
+	for _, key := range keys {
+		_ = headers.Set(py.String(key), py.String(strings.Join(r.resp.Header[key], ", ")))
+	}
+	return headers
+}
+
+// Returns the names of the headers in sorted order
+func (r *HTTPResponse) headerKeys() []string {
+	keys := make([]string, 0, len(r.resp.Header))
+	for key := range r.resp.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Read reads up to n bytes of the body, or all of it if n is negative
+func (r *HTTPResponse) Read(n int) (py.Bytes, error) {
+	if r.closed {
+		return py.Bytes{}, nil
+	}
+	var data []byte
+	var err error
+	vm.AllowThreads(func() {
+		if n < 0 {
+			data, err = ioutil.ReadAll(r.resp.Body)
+			return
+		}
+		data = make([]byte, n)
+		n, err = io.ReadFull(r.resp.Body, data)
+		data = data[:n]
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+	})
+	if err != nil {
+		return nil, socket.NetError(err)
+	}
+	return py.Bytes(data), nil
+}
+
+// Close closes the body of the response
+func (r *HTTPResponse) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.resp.Body.Close()
+}
+
+func (r *HTTPResponse) M__repr__() (py.Object, error) {
+	return py.String("<http.client.HTTPResponse " + r.resp.Status + ">"), nil
+}
+
+func (r *HTTPResponse) M__enter__() (py.Object, error) {
+	return r, nil
+}
+
+func (r *HTTPResponse) M__exit__(exc_type, exc_value, traceback py.Object) (py.Object, error) {
+	return py.None, r.Close()
+}
+
+func init() {
+	HTTPResponseType.Dict["read"] = py.MustNewMethod("read", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var amt py.Object = py.None
+		err := py.UnpackTuple(args, nil, "read", 0, 1, &amt)
+		if err != nil {
+			return nil, err
+		}
+		n := -1
+		if amt != py.None {
+			if n, err = py.IndexInt(amt); err != nil {
+				return nil, err
+			}
+		}
+		return self.(*HTTPResponse).Read(n)
+	}, 0, `read(amt=None) -> bytes
+
+Read and return up to amt bytes of the body, or all of it if amt is
+None.  Returns b'' at the end of the body.`)
+	HTTPResponseType.Dict["close"] = py.MustNewMethod("close", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*HTTPResponse).Close()
+	}, 0, `close()
+
+Close the response, discarding any of the body which hasn't been read.`)
+	HTTPResponseType.Dict["isclosed"] = py.MustNewMethod("isclosed", func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*HTTPResponse).closed), nil
+	}, 0, `isclosed() -> bool
+
+Return True if the response has been closed.`)
+	HTTPResponseType.Dict["readable"] = py.MustNewMethod("readable", func(self py.Object) (py.Object, error) {
+		return py.True, nil
+	}, 0, `readable() -> True`)
+	HTTPResponseType.Dict["getheader"] = py.MustNewMethod("getheader", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var name, def py.Object = nil, py.None
+		err := py.UnpackTuple(args, nil, "getheader", 1, 2, &name, &def)
+		if err != nil {
+			return nil, err
+		}
+		key, err := py.StrAsString(name)
+		if err != nil {
+			return nil, err
+		}
+		values, ok := self.(*HTTPResponse).resp.Header[textproto.CanonicalMIMEHeaderKey(key)]
+		if !ok {
+			return def, nil
+		}
+		return py.String(strings.Join(values, ", ")), nil
+	}, 0, `getheader(name, default=None) -> str
+
+Return the value of the header name, or default if there isn't one.
+The name isn't case sensitive and the values of a header given more
+than once are joined with ", ".`)
+	HTTPResponseType.Dict["getheaders"] = py.MustNewMethod("getheaders", func(self py.Object) (py.Object, error) {
+		r := self.(*HTTPResponse)
+		headers := py.NewList()
+		for _, key := range r.headerKeys() {
+			for _, value := range r.resp.Header[key] {
+				headers.Append(py.Tuple{py.String(key), py.String(value)})
+			}
+		}
+		return headers, nil
+	}, 0, `getheaders() -> list of (header, value) tuples`)
+	HTTPResponseType.Dict["getcode"] = py.MustNewMethod("getcode", func(self py.Object) (py.Object, error) {
+		return py.Int(self.(*HTTPResponse).Status()), nil
+	}, 0, `getcode() -> int
+
+Return the status code of the response.`)
+	HTTPResponseType.Dict["geturl"] = py.MustNewMethod("geturl", func(self py.Object) (py.Object, error) {
+		return py.String(self.(*HTTPResponse).url), nil
+	}, 0, `geturl() -> str
+
+Return the URL of the response, which is where any redirects led.`)
+	HTTPResponseType.Dict["info"] = py.MustNewMethod("info", func(self py.Object) (py.Object, error) {
+		return self.(*HTTPResponse).Headers(), nil
+	}, 0, `info() -> dict
+
+Return the headers of the response.`)
+	for name, get := range map[string]func(r *HTTPResponse) py.Object{
+		"status":  func(r *HTTPResponse) py.Object { return py.Int(r.Status()) },
+		"code":    func(r *HTTPResponse) py.Object { return py.Int(r.Status()) },
+		"reason":  func(r *HTTPResponse) py.Object { return py.String(r.Reason()) },
+		"version": func(r *HTTPResponse) py.Object { return py.Int(10*r.resp.ProtoMajor + r.resp.ProtoMinor) },
+		"headers": func(r *HTTPResponse) py.Object { return r.Headers() },
+		"msg":     func(r *HTTPResponse) py.Object { return r.Headers() },
+		"url":     func(r *HTTPResponse) py.Object { return py.String(r.url) },
+		"closed":  func(r *HTTPResponse) py.Object { return py.NewBool(r.closed) },
+	} {
+		get := get
+		HTTPResponseType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return get(self.(*HTTPResponse)), nil
+			},
+		}
+	}
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "http",
+		Doc:  http_doc,
+	})
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "http.client",
+		Doc:  client_doc,
+		Globals: py.StringDict{
+			"HTTP_PORT":               py.Int(HTTP_PORT),
+			"HTTPS_PORT":              py.Int(HTTPS_PORT),
+			"HTTPConnection":          HTTPConnectionType,
+			"HTTPSConnection":         HTTPSConnectionType,
+			"HTTPResponse":            HTTPResponseType,
+			"HTTPException":           HTTPException,
+			"InvalidURL":              InvalidURL,
+			"ImproperConnectionState": ImproperConnectionState,
+			"ResponseNotReady":        ResponseNotReady,
+		},
+		Init: func(ctx *py.Context, m *py.Module) error {
+			// responses maps the status codes to their reasons
+			responses := py.NewDict()
+			for code := 100; code < 600; code++ {
+				if text := nethttp.StatusText(code); text != "" {
+					_ = responses.Set(py.Int(code), py.String(text))
+				}
+			}
+			m.Globals["responses"] = responses
+			return nil
+		},
+	})
+}
@@ -0,0 +1,86 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import http.client
+import os
+from libtest import *
+
+host = os.environ["GPYTHON_TEST_HTTP_HOST"]
+address, port = host.rsplit(":", 1)
+port = int(port)
+
+doc = "package"
+import http
+assert http.client is http.client
+from http import client
+assert client.HTTPConnection is http.client.HTTPConnection
+assert http.client.responses[404] == "Not Found"
+assert http.client.HTTP_PORT == 80
+assert http.client.HTTPS_PORT == 443
+
+doc = "connection"
+conn = http.client.HTTPConnection(host)
+assert conn.host == address
+assert conn.port == port
+assert conn.timeout is None
+conn = http.client.HTTPConnection(address, port, timeout=5)
+assert conn.port == port
+assert conn.timeout == 5.0
+assert http.client.HTTPSConnection("example.com").port == 443
+assert issubclass(http.client.HTTPSConnection, http.client.HTTPConnection)
+assertRaises(http.client.InvalidURL, http.client.HTTPConnection, "example.com:port")
+assertRaises(http.client.ResponseNotReady, conn.getresponse)
+
+doc = "get"
+conn.request("GET", "/echo?a=1", headers={"X-Test": "hello"})
+resp = conn.getresponse()
+assert resp.status == 200
+assert resp.code == 200
+assert resp.getcode() == 200
+assert resp.reason == "OK"
+assert resp.version == 11
+assert resp.getheader("content-type") == "text/plain"
+assert resp.getheader("X-Multi") == "a, b"
+assert resp.getheader("X-Missing") is None
+assert resp.getheader("X-Missing", "default") == "default"
+assert resp.headers["Content-Type"] == "text/plain"
+assert ("X-Multi", "a") in resp.getheaders()
+assert ("X-Multi", "b") in resp.getheaders()
+assert not resp.closed
+assert resp.read(4) == b"GET "
+assert resp.read() == b"/echo?a=1 hello "
+assert resp.read() == b""
+resp.close()
+assert resp.isclosed()
+assertRaises(http.client.ResponseNotReady, conn.getresponse)
+
+doc = "post"
+conn.request("POST", "/echo", body="data")
+with conn.getresponse() as resp:
+    assert resp.read() == b"POST /echo  data"
+conn.request("PUT", "/echo", b"bytes", {"X-Test": "put"})
+assert conn.getresponse().read() == b"PUT /echo put bytes"
+
+doc = "status"
+conn.request("GET", "/missing")
+resp = conn.getresponse()
+assert resp.status == 404
+assert resp.reason == "Not Found"
+assert resp.read() == b"no such page\n"
+conn.request("GET", "/redirect")
+resp = conn.getresponse()
+assert resp.status == 302
+assert resp.getheader("Location") == "/echo"
+conn.close()
+
+doc = "timeout"
+conn = http.client.HTTPConnection(host, timeout=0.05)
+assertRaises(TimeoutError, conn.request, "GET", "/slow")
+conn.close()
+
+doc = "connection refused"
+with http.client.HTTPConnection("127.0.0.1", 1) as conn:
+    assertRaises(ConnectionRefusedError, conn.request, "GET", "/")
+
+doc = "finished"
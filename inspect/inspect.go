@@ -0,0 +1,365 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Inspect module
+//
+// Gets information about live objects such as modules, classes,
+// functions, frames and code objects: their members, signatures and
+// source code, and the frames of the stack.
+
+package inspect
+
+import (
+	"github.com/go-python/gpython/collections"
+	"github.com/go-python/gpython/py"
+)
+
+// The named tuples returned by getframeinfo and by stack and
+// getouterframes, set in init
+var (
+	TracebackType *py.Type
+	FrameInfoType *py.Type
+)
+
+const inspect_doc = `Get useful information from live Python objects.
+
+This module encapsulates the interface provided by the internal special
+attributes (co_*, f_*, func_*, etc.) to provide easier access to them.
+
+Here are some of the useful functions provided by this module:
+
+    ismodule(), isclass(), ismethod(), isfunction(), isgeneratorfunction(),
+        isgenerator(), istraceback(), isframe(), iscode(), isbuiltin(),
+        isroutine() - check object types
+    getmembers() - get members of an object that satisfy a given condition
+
+    getfile(), getsourcefile(), getsource() - find an object's source code
+    getdoc() - get documentation on an object
+    getmodule() - determine the module that an object came from
+
+    currentframe() - get the current stack frame
+    stack(), getouterframes() - get info about frames on the stack
+
+    signature() - get a Signature object for the callable`
+
+// Returns true if obj is a method of a class written in python bound
+// to an instance
+func isMethod(obj py.Object) bool {
+	bm, ok := obj.(*py.BoundMethod)
+	if !ok {
+		return false
+	}
+	_, builtin := bm.Method.(*py.Method)
+	return !builtin
+}
+
+// Returns true if obj is a function or method written in Go
+func isBuiltin(obj py.Object) bool {
+	switch o := obj.(type) {
+	case *py.Method:
+		return true
+	case *py.BoundMethod:
+		_, builtin := o.Method.(*py.Method)
+		return builtin
+	}
+	return false
+}
+
+// Returns the code of a function or of a method bound to one, or nil
+func functionCode(obj py.Object) *py.Code {
+	if bm, ok := obj.(*py.BoundMethod); ok {
+		obj = bm.Method
+	}
+	if fn, ok := obj.(*py.Function); ok {
+		return fn.Code
+	}
+	return nil
+}
+
+// The predicates which test the type of an object
+var predicates = []struct {
+	name string
+	doc  string
+	test func(obj py.Object) bool
+}{
+	{"ismodule", "Return true if the object is a module.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Module)
+		return ok
+	}},
+	{"isclass", "Return true if the object is a class.", isClass},
+	{"ismethod", "Return true if the object is an instance method.", isMethod},
+	{"isfunction", "Return true if the object is a user-defined function.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Function)
+		return ok
+	}},
+	{"isbuiltin", "Return true if the object is a built-in function or method.", isBuiltin},
+	{"isroutine", "Return true if the object is any kind of function or method.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Function)
+		return ok || isMethod(obj) || isBuiltin(obj)
+	}},
+	{"isgeneratorfunction", "Return true if the object is a user-defined generator function.", func(obj py.Object) bool {
+		co := functionCode(obj)
+		return co != nil && co.Flags&py.CO_GENERATOR != 0
+	}},
+	{"iscoroutinefunction", "Return true if the object is a coroutine function.", func(obj py.Object) bool {
+		co := functionCode(obj)
+		return co != nil && co.Flags&py.CO_COROUTINE != 0
+	}},
+	{"isgenerator", "Return true if the object is a generator.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Generator)
+		return ok
+	}},
+	{"iscoroutine", "Return true if the object is a coroutine.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Coroutine)
+		return ok
+	}},
+	{"iscode", "Return true if the object is a code object.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Code)
+		return ok
+	}},
+	{"isframe", "Return true if the object is a frame object.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Frame)
+		return ok
+	}},
+	{"istraceback", "Return true if the object is a traceback.", func(obj py.Object) bool {
+		_, ok := obj.(*py.Traceback)
+		return ok
+	}},
+	{"isabstract", "Return true if the object is an abstract base class (ABC).", func(obj py.Object) bool {
+		return isClass(obj) && obj.(*py.Type).Flags&py.TPFLAGS_IS_ABSTRACT != 0
+	}},
+}
+
+const signature_doc = `signature(obj) -> Signature
+
+Get a signature object for the passed callable.`
+
+func inspect_signature(self py.Object, obj py.Object) (py.Object, error) {
+	return SignatureOf(obj)
+}
+
+const getmembers_doc = `getmembers(object, predicate=None) -> list
+
+Return all members of an object as (name, value) pairs sorted by name.
+Optionally, only return members that satisfy a given predicate.`
+
+func inspect_getmembers(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	var predicate py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:getmembers", []string{"object", "predicate"}, &obj, &predicate)
+	if err != nil {
+		return nil, err
+	}
+	names, err := py.Dir(obj)
+	if err != nil {
+		return nil, err
+	}
+	results := py.NewList()
+	for _, name := range names.Items {
+		key, err := py.AttributeName(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getAttrOrNil(obj, key)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if predicate != py.None {
+			ok, err := py.Call(predicate, py.Tuple{value}, nil)
+			if err != nil {
+				return nil, err
+			}
+			ok, err = py.MakeBool(ok)
+			if err != nil {
+				return nil, err
+			}
+			if ok != py.True {
+				continue
+			}
+		}
+		results.Append(py.Tuple{name, value})
+	}
+	return results, nil
+}
+
+const currentframe_doc = `currentframe() -> frame
+
+Return the frame of the caller or None if this is not possible.`
+
+func inspect_currentframe(self py.Object) (py.Object, error) {
+	if py.CurrentFrame == nil {
+		return py.None, nil
+	}
+	return py.CurrentFrame, nil
+}
+
+// Returns the lines of the context of line lineno of filename as
+// CPython's code_context and the index of the line in them
+func codeContext(filename string, lineno, context int) (py.Object, py.Object) {
+	lines := py.SourceLines(filename)
+	if context <= 0 || lineno < 1 || lineno > len(lines) {
+		return py.None, py.None
+	}
+	start := lineno - 1 - context/2
+	if start > len(lines)-context {
+		start = len(lines) - context
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + context
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return sourceList(lines[start:end]), py.Int(lineno - 1 - start)
+}
+
+// Returns the information about a frame or traceback entry as the
+// items (filename, lineno, function, code_context, index)
+func frameInfo(obj py.Object, context int) (py.Tuple, error) {
+	var frame *py.Frame
+	var lineno int
+	switch o := obj.(type) {
+	case *py.Frame:
+		frame, lineno = o, int(o.LineNumber())
+	case *py.Traceback:
+		frame, lineno = o.Frame, int(o.Lineno)
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "%s is not a frame or traceback object", obj.Type().Name)
+	}
+	filename := frame.Code.Filename
+	lines, index := codeContext(filename, lineno, context)
+	return py.Tuple{py.String(filename), py.Int(lineno), py.String(frame.Code.Name), lines, index}, nil
+}
+
+// Parses the arguments of the functions which take an object and the
+// lines of context to show
+func contextArgs(name string, args py.Tuple, kwargs py.StringDict) (py.Object, int, error) {
+	var obj py.Object
+	var context py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|i:"+name, []string{"frame", "context"}, &obj, &context)
+	if err != nil {
+		return nil, 0, err
+	}
+	return obj, int(context.(py.Int)), nil
+}
+
+const getframeinfo_doc = `getframeinfo(frame, context=1) -> (filename, lineno, function, code_context, index)
+
+Get information about a frame or traceback object.
+
+The code_context is a list of context lines of source around the current
+line and index is the index of the current line in it.`
+
+func inspect_getframeinfo(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	obj, context, err := contextArgs("getframeinfo", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	info, err := frameInfo(obj, context)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(TracebackType, info, nil)
+}
+
+// Returns a list of FrameInfo(frame, *frameInfo(frame)) for frame and
+// the frames it was called from
+func outerFrames(frame *py.Frame, context int) (py.Object, error) {
+	frames := py.NewList()
+	for ; frame != nil; frame = frame.Back {
+		info, err := frameInfo(frame, context)
+		if err != nil {
+			return nil, err
+		}
+		record, err := py.Call(FrameInfoType, append(py.Tuple{frame}, info...), nil)
+		if err != nil {
+			return nil, err
+		}
+		frames.Append(record)
+	}
+	return frames, nil
+}
+
+const getouterframes_doc = `getouterframes(frame, context=1) -> list
+
+Get a list of records for a frame and all higher (calling) frames.
+
+Each record contains a frame object, filename, line number, function
+name, a list of lines of context, and index within the context.`
+
+func inspect_getouterframes(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	obj, context, err := contextArgs("getouterframes", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	frame, ok := obj.(*py.Frame)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "getouterframes() argument must be a frame, not %s", obj.Type().Name)
+	}
+	return outerFrames(frame, context)
+}
+
+const stack_doc = `stack(context=1) -> list
+
+Return a list of records for the stack above the caller's frame.`
+
+func inspect_stack(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var context py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|i:stack", []string{"context"}, &context)
+	if err != nil {
+		return nil, err
+	}
+	return outerFrames(py.CurrentFrame, int(context.(py.Int)))
+}
+
+// Initialise the module
+func init() {
+	var err error
+	TracebackType, err = collections.NewNamedTupleType("Traceback", []string{"filename", "lineno", "function", "code_context", "index"}, nil, py.String("inspect"))
+	if err != nil {
+		panic(err)
+	}
+	FrameInfoType, err = collections.NewNamedTupleType("FrameInfo", []string{"frame", "filename", "lineno", "function", "code_context", "index"}, nil, py.String("inspect"))
+	if err != nil {
+		panic(err)
+	}
+	methods := []*py.Method{
+		py.MustNewMethod("signature", inspect_signature, 0, signature_doc),
+		py.MustNewMethod("getmembers", inspect_getmembers, 0, getmembers_doc),
+		py.MustNewMethod("getdoc", inspect_getdoc, 0, getdoc_doc),
+		py.MustNewMethod("cleandoc", inspect_cleandoc, 0, cleandoc_doc),
+		py.MustNewMethod("getfile", inspect_getfile, 0, getfile_doc),
+		py.MustNewMethod("getsourcefile", inspect_getsourcefile, 0, getsourcefile_doc),
+		py.MustNewMethod("getmodule", inspect_getmodule, 0, getmodule_doc),
+		py.MustNewMethod("getsourcelines", inspect_getsourcelines, 0, getsourcelines_doc),
+		py.MustNewMethod("getsource", inspect_getsource, 0, getsource_doc),
+		py.MustNewMethod("currentframe", inspect_currentframe, 0, currentframe_doc),
+		py.MustNewMethod("getframeinfo", inspect_getframeinfo, 0, getframeinfo_doc),
+		py.MustNewMethod("getouterframes", inspect_getouterframes, 0, getouterframes_doc),
+		py.MustNewMethod("stack", inspect_stack, 0, stack_doc),
+	}
+	for _, p := range predicates {
+		test := p.test
+		methods = append(methods, py.MustNewMethod(p.name, func(self, obj py.Object) (py.Object, error) {
+			return py.NewBool(test(obj)), nil
+		}, 0, p.name+"(object) -> bool\n\n"+p.doc))
+	}
+	globals := py.StringDict{
+		"Signature": SignatureType,
+		"Parameter": ParameterType,
+		"_empty":    EmptyType,
+		"Traceback": TracebackType,
+		"FrameInfo": FrameInfoType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "inspect",
+		Doc:     inspect_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
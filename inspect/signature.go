@@ -0,0 +1,587 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Signature and Parameter objects
+
+package inspect
+
+import (
+	"bytes"
+	"unicode"
+
+	"github.com/go-python/gpython/py"
+)
+
+// EmptyType is Parameter.empty and Signature.empty which mark a
+// parameter without a default or an annotation.  As in CPython the
+// class itself is the marker.
+var EmptyType = py.NewType("_empty", "Marker object for Signature.empty and Parameter.empty.")
+
+var ParameterKindType = py.NewType("_ParameterKind", "The kind of a Parameter.")
+
+var ParameterType = py.NewTypeX("Parameter", `Represents a parameter in a function signature.
+
+Parameter(name, kind, *, default=Parameter.empty, annotation=Parameter.empty)
+
+Has the read only attributes name, kind, default and annotation.`, ParameterNew, nil)
+
+var SignatureType = py.NewTypeX("Signature", `A Signature object represents the overall signature of a function.
+
+Signature(parameters=None, *, return_annotation=Signature.empty)
+
+It stores a Parameter object for each parameter accepted by the
+function, as well as information specific to the function itself.`, SignatureNew, nil)
+
+// ParameterKind says how the argument of a parameter is passed
+type ParameterKind int
+
+// The kinds of parameters in the order they appear in a signature
+const (
+	PositionalOnly ParameterKind = iota
+	PositionalOrKeyword
+	VarPositional
+	KeywordOnly
+	VarKeyword
+)
+
+var parameterKindNames = [...]string{
+	PositionalOnly:      "POSITIONAL_ONLY",
+	PositionalOrKeyword: "POSITIONAL_OR_KEYWORD",
+	VarPositional:       "VAR_POSITIONAL",
+	KeywordOnly:         "KEYWORD_ONLY",
+	VarKeyword:          "VAR_KEYWORD",
+}
+
+// Type of this object
+func (k ParameterKind) Type() *py.Type {
+	return ParameterKindType
+}
+
+func (k ParameterKind) M__str__() (py.Object, error) {
+	return py.String(parameterKindNames[k]), nil
+}
+
+func (k ParameterKind) M__repr__() (py.Object, error) {
+	return k.M__str__()
+}
+
+func (k ParameterKind) M__hash__() (py.Object, error) {
+	return py.Int(k), nil
+}
+
+func (k ParameterKind) M__index__() (py.Int, error) {
+	return py.Int(k), nil
+}
+
+func (k ParameterKind) M__eq__(other py.Object) (py.Object, error) {
+	return py.NewBool(k == other), nil
+}
+
+func (k ParameterKind) M__ne__(other py.Object) (py.Object, error) {
+	return py.NewBool(k != other), nil
+}
+
+func (k ParameterKind) M__lt__(other py.Object) (py.Object, error) {
+	if b, ok := other.(ParameterKind); ok {
+		return py.NewBool(k < b), nil
+	}
+	return py.NotImplemented, nil
+}
+
+func (k ParameterKind) M__gt__(other py.Object) (py.Object, error) {
+	if b, ok := other.(ParameterKind); ok {
+		return py.NewBool(k > b), nil
+	}
+	return py.NotImplemented, nil
+}
+
+// Parameter is a parameter of a Signature
+//
+// Default and Annotation are EmptyType if the parameter hasn't got
+// them.
+type Parameter struct {
+	Name       string
+	Kind       ParameterKind
+	Default    py.Object
+	Annotation py.Object
+}
+
+// Type of this object
+func (p *Parameter) Type() *py.Type {
+	return ParameterType
+}
+
+// Returns true if the identifier s is a valid python name
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		if !(c == '_' || unicode.IsLetter(c) || i > 0 && unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// NewParameter makes a Parameter checking its arguments
+func NewParameter(name string, kind ParameterKind, def, annotation py.Object) (*Parameter, error) {
+	if kind < PositionalOnly || kind > VarKeyword {
+		return nil, py.ExceptionNewf(py.ValueError, "invalid value for 'Parameter.kind' attribute")
+	}
+	if def != EmptyType && (kind == VarPositional || kind == VarKeyword) {
+		return nil, py.ExceptionNewf(py.ValueError, "%s parameters cannot have default values", parameterKindNames[kind])
+	}
+	if !isIdentifier(name) {
+		return nil, py.ExceptionNewf(py.ValueError, "'%s' is not a valid parameter name", name)
+	}
+	return &Parameter{Name: name, Kind: kind, Default: def, Annotation: annotation}, nil
+}
+
+// ParameterNew makes a Parameter from python
+func ParameterNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name, kind py.Object
+	var def, annotation py.Object = EmptyType, EmptyType
+	err := py.ParseTupleAndKeywords(args, kwargs, "UO|OO:Parameter", []string{"name", "kind", "default", "annotation"}, &name, &kind, &def, &annotation)
+	if err != nil {
+		return nil, err
+	}
+	k, ok := kind.(ParameterKind)
+	if !ok {
+		return nil, py.ExceptionNewf(py.ValueError, "invalid value for 'Parameter.kind' attribute")
+	}
+	return NewParameter(string(name.(py.String)), k, def, annotation)
+}
+
+// Returns the annotation as it is shown in a signature
+func formatAnnotation(annotation py.Object) (string, error) {
+	if t, ok := annotation.(*py.Type); ok {
+		qualname := t.Name
+		if name, ok := t.Dict["__qualname__"].(py.String); ok {
+			qualname = string(name)
+		}
+		if module, ok := t.Dict["__module__"].(py.String); ok && module != "builtins" {
+			return string(module) + "." + qualname, nil
+		}
+		return qualname, nil
+	}
+	return py.ReprAsString(annotation)
+}
+
+func (p *Parameter) M__str__() (py.Object, error) {
+	s := p.Name
+	if p.Annotation != EmptyType {
+		annotation, err := formatAnnotation(p.Annotation)
+		if err != nil {
+			return nil, err
+		}
+		s += ": " + annotation
+	}
+	if p.Default != EmptyType {
+		def, err := py.ReprAsString(p.Default)
+		if err != nil {
+			return nil, err
+		}
+		if p.Annotation != EmptyType {
+			s += " = " + def
+		} else {
+			s += "=" + def
+		}
+	}
+	switch p.Kind {
+	case VarPositional:
+		s = "*" + s
+	case VarKeyword:
+		s = "**" + s
+	}
+	return py.String(s), nil
+}
+
+func (p *Parameter) M__repr__() (py.Object, error) {
+	s, err := p.M__str__()
+	if err != nil {
+		return nil, err
+	}
+	return py.String("<Parameter \"" + string(s.(py.String)) + "\">"), nil
+}
+
+func (p *Parameter) M__eq__(other py.Object) (py.Object, error) {
+	b, ok := other.(*Parameter)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if p.Name != b.Name || p.Kind != b.Kind {
+		return py.False, nil
+	}
+	eq, err := py.Eq(p.Default, b.Default)
+	if err != nil || eq != py.True {
+		return eq, err
+	}
+	return py.Eq(p.Annotation, b.Annotation)
+}
+
+func (p *Parameter) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := p.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.Not(eq)
+}
+
+// Signature is the signature of a callable
+//
+// ReturnAnnotation is EmptyType if there isn't one.
+type Signature struct {
+	Parameters       []*Parameter
+	ReturnAnnotation py.Object
+}
+
+// Type of this object
+func (s *Signature) Type() *py.Type {
+	return SignatureType
+}
+
+// NewSignature makes a Signature checking the parameters are in a
+// valid order and have different names
+func NewSignature(params []*Parameter, returnAnnotation py.Object) (*Signature, error) {
+	seen := make(map[string]bool, len(params))
+	kind := PositionalOnly
+	seenDefault := false
+	for _, p := range params {
+		if p.Kind < kind {
+			return nil, py.ExceptionNewf(py.ValueError, "wrong parameter order: %s parameter before %s parameter", parameterKindNames[kind], parameterKindNames[p.Kind])
+		}
+		kind = p.Kind
+		if kind == PositionalOnly || kind == PositionalOrKeyword {
+			if p.Default != EmptyType {
+				seenDefault = true
+			} else if seenDefault {
+				return nil, py.ExceptionNewf(py.ValueError, "non-default argument follows default argument")
+			}
+		}
+		if seen[p.Name] {
+			return nil, py.ExceptionNewf(py.ValueError, "duplicate parameter name: '%s'", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return &Signature{Parameters: params, ReturnAnnotation: returnAnnotation}, nil
+}
+
+// SignatureNew makes a Signature from python
+func SignatureNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var parameters py.Object = py.None
+	var returnAnnotation py.Object = EmptyType
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:Signature", []string{"parameters", "return_annotation"}, &parameters, &returnAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	var params []*Parameter
+	if parameters != py.None {
+		err = py.Iterate(parameters, func(item py.Object) bool {
+			p, ok := item.(*Parameter)
+			if !ok {
+				err = py.ExceptionNewf(py.TypeError, "Signature parameters must be Parameter objects, not %s", item.Type().Name)
+				return true
+			}
+			params = append(params, p)
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewSignature(params, returnAnnotation)
+}
+
+// Returns the signature without its first parameter, which is how a
+// method looks once it is bound
+func (s *Signature) dropFirst() (*Signature, error) {
+	if len(s.Parameters) == 0 || s.Parameters[0].Kind > VarPositional {
+		return nil, py.ExceptionNewf(py.ValueError, "invalid method signature")
+	}
+	if s.Parameters[0].Kind == VarPositional {
+		return s, nil
+	}
+	return &Signature{Parameters: s.Parameters[1:], ReturnAnnotation: s.ReturnAnnotation}, nil
+}
+
+func (s *Signature) M__str__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("(")
+	kind := PositionalOnly
+	for i, p := range s.Parameters {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		if kind == PositionalOnly && p.Kind != PositionalOnly && i > 0 {
+			out.WriteString("/, ")
+		}
+		if p.Kind == KeywordOnly && kind < VarPositional {
+			out.WriteString("*, ")
+		}
+		kind = p.Kind
+		str, err := p.M__str__()
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(string(str.(py.String)))
+	}
+	if kind == PositionalOnly && len(s.Parameters) > 0 {
+		out.WriteString(", /")
+	}
+	out.WriteString(")")
+	if s.ReturnAnnotation != EmptyType {
+		annotation, err := formatAnnotation(s.ReturnAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(" -> " + annotation)
+	}
+	return py.String(out.String()), nil
+}
+
+func (s *Signature) M__repr__() (py.Object, error) {
+	str, err := s.M__str__()
+	if err != nil {
+		return nil, err
+	}
+	return py.String("<Signature " + string(str.(py.String)) + ">"), nil
+}
+
+func (s *Signature) M__eq__(other py.Object) (py.Object, error) {
+	b, ok := other.(*Signature)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if len(s.Parameters) != len(b.Parameters) {
+		return py.False, nil
+	}
+	for i, p := range s.Parameters {
+		eq, err := p.M__eq__(b.Parameters[i])
+		if err != nil || eq != py.True {
+			return eq, err
+		}
+	}
+	return py.Eq(s.ReturnAnnotation, b.ReturnAnnotation)
+}
+
+func (s *Signature) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := s.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.Not(eq)
+}
+
+// Returns the parameters as a read only mapping of name to Parameter
+// in order
+func (s *Signature) parameters() (py.Object, error) {
+	d := py.NewDictSized(len(s.Parameters))
+	for _, p := range s.Parameters {
+		err := d.Set(py.String(p.Name), p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.NewMappingProxy(d), nil
+}
+
+// Returns the Signature of fn from its code object
+func functionSignature(fn *py.Function) (*Signature, error) {
+	co := fn.Code
+	annotation := func(name string) py.Object {
+		if a, ok := fn.Annotations[name]; ok {
+			return a
+		}
+		return EmptyType
+	}
+	var params []*Parameter
+	nargs := int(co.Argcount)
+	firstDefault := nargs - len(fn.Defaults)
+	for i, name := range co.Varnames[:nargs] {
+		p := &Parameter{Name: name, Kind: PositionalOrKeyword, Default: EmptyType, Annotation: annotation(name)}
+		if i < int(co.Posonlyargcount) {
+			p.Kind = PositionalOnly
+		}
+		if i >= firstDefault {
+			p.Default = fn.Defaults[i-firstDefault]
+		}
+		params = append(params, p)
+	}
+	next := nargs + int(co.Kwonlyargcount)
+	if co.Flags&py.CO_VARARGS != 0 {
+		name := co.Varnames[next]
+		params = append(params, &Parameter{Name: name, Kind: VarPositional, Default: EmptyType, Annotation: annotation(name)})
+		next++
+	}
+	for _, name := range co.Varnames[nargs : nargs+int(co.Kwonlyargcount)] {
+		p := &Parameter{Name: name, Kind: KeywordOnly, Default: EmptyType, Annotation: annotation(name)}
+		if def, ok := fn.KwDefaults[name]; ok {
+			p.Default = def
+		}
+		params = append(params, p)
+	}
+	if co.Flags&py.CO_VARKEYWORDS != 0 {
+		name := co.Varnames[next]
+		params = append(params, &Parameter{Name: name, Kind: VarKeyword, Default: EmptyType, Annotation: annotation(name)})
+	}
+	return &Signature{Parameters: params, ReturnAnnotation: annotation("return")}, nil
+}
+
+// Returns the attribute name of obj or nil if it hasn't got it
+func getAttrOrNil(obj py.Object, name string) (py.Object, error) {
+	attr, err := py.GetAttrString(obj, name)
+	if err != nil {
+		if py.IsException(py.AttributeError, err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return attr, nil
+}
+
+// Returns the method name looked up in the class t if it is written
+// in python
+func pythonMethod(t *py.Type, name string) py.Object {
+	switch method := t.Lookup(name).(type) {
+	case *py.Function:
+		return method
+	case *py.StaticMethod:
+		if _, ok := method.Callable.(*py.Function); ok {
+			return method.Callable
+		}
+	}
+	return nil
+}
+
+// Returns true if obj is a class rather than an instance of one
+func isClass(obj py.Object) bool {
+	t, ok := obj.(*py.Type)
+	return ok && t.Type().IsSubtype(py.TypeType)
+}
+
+// Returns true if obj can be called
+func isCallable(obj py.Object) bool {
+	if t, ok := obj.(*py.Type); ok && !isClass(t) {
+		return t.Type().Lookup("__call__") != nil
+	}
+	_, ok := obj.(py.I__call__)
+	return ok
+}
+
+// SignatureOf returns the Signature of the callable obj
+//
+// Wrappers made by functools.wraps are followed to the function they
+// wrap.
+func SignatureOf(obj py.Object) (*Signature, error) {
+	if !isCallable(obj) {
+		repr, err := py.ReprAsString(obj)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "%s is not a callable object", repr)
+	}
+	for depth := 0; ; depth++ {
+		if _, ok := obj.(*py.BoundMethod); ok {
+			break
+		}
+		wrapped, err := getAttrOrNil(obj, "__wrapped__")
+		if err != nil {
+			return nil, err
+		}
+		if wrapped == nil {
+			break
+		}
+		if depth > 100 {
+			return nil, py.ExceptionNewf(py.ValueError, "wrapper loop when unwrapping %s", obj.Type().Name)
+		}
+		obj = wrapped
+	}
+	if sig, err := getAttrOrNil(obj, "__signature__"); err != nil {
+		return nil, err
+	} else if s, ok := sig.(*Signature); ok {
+		return s, nil
+	}
+	switch o := obj.(type) {
+	case *py.BoundMethod:
+		sig, err := SignatureOf(o.Method)
+		if err != nil {
+			return nil, err
+		}
+		return sig.dropFirst()
+	case *py.Function:
+		return functionSignature(o)
+	case *py.Method:
+		return nil, py.ExceptionNewf(py.ValueError, "no signature found for builtin %s", o.Name)
+	case *py.Type:
+		if !isClass(o) {
+			call := pythonMethod(o.Type(), "__call__")
+			if call == nil {
+				return nil, py.ExceptionNewf(py.ValueError, "no signature found for %s object", o.Type().Name)
+			}
+			sig, err := SignatureOf(call)
+			if err != nil {
+				return nil, err
+			}
+			return sig.dropFirst()
+		}
+		for _, name := range []string{"__init__", "__new__"} {
+			if method := pythonMethod(o, name); method != nil {
+				sig, err := SignatureOf(method)
+				if err != nil {
+					return nil, err
+				}
+				return sig.dropFirst()
+			}
+		}
+		if o.Lookup("__init__") == py.ObjectType.Dict["__init__"] && o.Lookup("__new__") == py.ObjectType.Dict["__new__"] {
+			return &Signature{ReturnAnnotation: EmptyType}, nil
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "no signature found for builtin type %s", o.Name)
+	}
+	return nil, py.ExceptionNewf(py.ValueError, "no signature found for %s object", obj.Type().Name)
+}
+
+func init() {
+	ParameterType.Dict["empty"] = EmptyType
+	SignatureType.Dict["empty"] = EmptyType
+	for kind, name := range parameterKindNames {
+		ParameterType.Dict[name] = ParameterKind(kind)
+	}
+	ParameterType.Dict["name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*Parameter).Name), nil
+		},
+	}
+	ParameterType.Dict["kind"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Parameter).Kind, nil
+		},
+	}
+	ParameterType.Dict["default"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Parameter).Default, nil
+		},
+	}
+	ParameterType.Dict["annotation"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Parameter).Annotation, nil
+		},
+	}
+	SignatureType.Dict["parameters"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Signature).parameters()
+		},
+	}
+	SignatureType.Dict["return_annotation"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Signature).ReturnAnnotation, nil
+		},
+	}
+}
+
+// Check interface is satisfied
+var _ py.I__str__ = ParameterKind(0)
+var _ py.I__index__ = ParameterKind(0)
+var _ py.I__eq__ = (*Parameter)(nil)
+var _ py.I__repr__ = (*Parameter)(nil)
+var _ py.I__eq__ = (*Signature)(nil)
+var _ py.I__repr__ = (*Signature)(nil)
@@ -0,0 +1,444 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Finding the source code and documentation of objects
+
+package inspect
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const getdoc_doc = `getdoc(object) -> str or None
+
+Get the documentation string for an object.
+
+All tabs are expanded to spaces.  To clean up docstrings that are
+indented to line up with blocks of code, any whitespace than can be
+uniformly removed from the second line onwards is removed.`
+
+func inspect_getdoc(self py.Object, obj py.Object) (py.Object, error) {
+	doc, err := getAttrOrNil(obj, "__doc__")
+	if err != nil {
+		return nil, err
+	}
+	s, ok := doc.(py.String)
+	if !ok {
+		return py.None, nil
+	}
+	return py.String(CleanDoc(string(s))), nil
+}
+
+const cleandoc_doc = `cleandoc(doc) -> str
+
+Clean up indentation from docstrings.
+
+Any whitespace that can be uniformly removed from the second line
+onwards is removed.`
+
+func inspect_cleandoc(self py.Object, doc py.Object) (py.Object, error) {
+	s, ok := doc.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "cleandoc() argument must be str, not %s", doc.Type().Name)
+	}
+	return py.String(CleanDoc(string(s))), nil
+}
+
+// Returns s with its tabs expanded to every 8th column
+func expandTabs(s string) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var out strings.Builder
+	column := 0
+	for _, c := range s {
+		switch c {
+		case '\t':
+			n := 8 - column%8
+			out.WriteString(strings.Repeat(" ", n))
+			column += n
+		case '\n':
+			out.WriteRune(c)
+			column = 0
+		default:
+			out.WriteRune(c)
+			column++
+		}
+	}
+	return out.String()
+}
+
+// Returns the number of columns line is indented by
+func indentOf(line string) int {
+	line = expandTabs(line)
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// CleanDoc removes the indentation common to all but the first line of doc
+// and the blank lines at its start and end
+func CleanDoc(doc string) string {
+	lines := strings.Split(expandTabs(doc), "\n")
+	margin := -1
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if indent := indentOf(line); margin < 0 || indent < margin {
+			margin = indent
+		}
+	}
+	lines[0] = strings.TrimLeft(lines[0], " ")
+	if margin > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= margin {
+				lines[i] = lines[i][margin:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Returns the module of the context called name or nil
+func moduleNamed(name py.Object) *py.Module {
+	if s, ok := name.(py.String); ok {
+		if m, ok := py.CurrentContext.Modules[string(s)].(*py.Module); ok {
+			return m
+		}
+	}
+	return nil
+}
+
+// Follows the __wrapped__ attributes of functools.wraps from obj to
+// the function it wraps
+func unwrap(obj py.Object) (py.Object, error) {
+	for depth := 0; depth < 100; depth++ {
+		wrapped, err := getAttrOrNil(obj, "__wrapped__")
+		if err != nil || wrapped == nil {
+			return obj, err
+		}
+		obj = wrapped
+	}
+	return nil, py.ExceptionNewf(py.ValueError, "wrapper loop when unwrapping %s", obj.Type().Name)
+}
+
+// Returns the code object which obj is made from or nil
+func codeOf(obj py.Object) *py.Code {
+	switch o := obj.(type) {
+	case *py.BoundMethod:
+		return codeOf(o.Method)
+	case *py.Function:
+		return o.Code
+	case *py.Traceback:
+		return o.Frame.Code
+	case *py.Frame:
+		return o.Code
+	case *py.Code:
+		return o
+	}
+	return nil
+}
+
+// Returns the name of the file obj was defined in
+func getfile(obj py.Object) (string, error) {
+	switch o := obj.(type) {
+	case *py.Module:
+		if file, ok := o.Globals["__file__"].(py.String); ok {
+			return string(file), nil
+		}
+		return "", py.ExceptionNewf(py.TypeError, "<module '%s'> is a built-in module", o.Name)
+	case *py.Type:
+		if isClass(o) {
+			if m := moduleNamed(o.Dict["__module__"]); m != nil {
+				if file, ok := m.Globals["__file__"].(py.String); ok {
+					return string(file), nil
+				}
+			}
+			return "", py.ExceptionNewf(py.TypeError, "<class '%s'> is a built-in class", o.Name)
+		}
+	}
+	if co := codeOf(obj); co != nil {
+		return co.Filename, nil
+	}
+	return "", py.ExceptionNewf(py.TypeError, "module, class, method, function, traceback, frame, or code object was expected, got %s", obj.Type().Name)
+}
+
+const getfile_doc = `getfile(object) -> str
+
+Work out which source or compiled file an object was defined in.`
+
+func inspect_getfile(self py.Object, obj py.Object) (py.Object, error) {
+	file, err := getfile(obj)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(file), nil
+}
+
+const getsourcefile_doc = `getsourcefile(object) -> str or None
+
+Return the filename that can be used to locate an object's source.
+Return None if no way can be identified to get the source.`
+
+func inspect_getsourcefile(self py.Object, obj py.Object) (py.Object, error) {
+	file, err := getfile(obj)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(file, ".py") || py.SourceLines(file) == nil {
+		return py.None, nil
+	}
+	return py.String(file), nil
+}
+
+const getmodule_doc = `getmodule(object) -> module or None
+
+Return the module an object was defined in, or None if not found.`
+
+func inspect_getmodule(self py.Object, obj py.Object) (py.Object, error) {
+	if m, ok := obj.(*py.Module); ok {
+		return m, nil
+	}
+	name, err := getAttrOrNil(obj, "__module__")
+	if err != nil {
+		return nil, err
+	}
+	if m := moduleNamed(name); m != nil {
+		return m, nil
+	}
+	if co := codeOf(obj); co != nil {
+		for _, value := range py.CurrentContext.Modules {
+			if m, ok := value.(*py.Module); ok && m.Globals["__file__"] == py.String(co.Filename) {
+				return m, nil
+			}
+		}
+	}
+	return py.None, nil
+}
+
+// lineScanner follows the brackets and strings of python source a
+// line at a time to find where logical lines end
+type lineScanner struct {
+	depth     int    // number of brackets open
+	quote     string // the quote which ends the string being read or ""
+	continued bool   // set if the line ended with a backslash
+	colon     int    // offset of the last ':' in the line outside brackets or -1
+}
+
+// Returns true if the logical line continues onto the next line
+func (s *lineScanner) open() bool {
+	return s.depth > 0 || s.quote != "" || s.continued
+}
+
+// Reads the next line of source
+func (s *lineScanner) scan(line string) {
+	s.colon = -1
+	s.continued = strings.HasSuffix(line, "\\")
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if s.quote != "" {
+			if c == '\\' {
+				i++
+			} else if strings.HasPrefix(line[i:], s.quote) {
+				i += len(s.quote) - 1
+				s.quote = ""
+			}
+			continue
+		}
+		switch c {
+		case '#':
+			s.continued = false
+			return
+		case '"', '\'':
+			s.quote = string(c)
+			if triple := strings.Repeat(s.quote, 3); strings.HasPrefix(line[i:], triple) {
+				s.quote = triple
+			}
+			i += len(s.quote) - 1
+		case '(', '[', '{':
+			s.depth++
+		case ')', ']', '}':
+			if s.depth > 0 {
+				s.depth--
+			}
+		case ':':
+			if s.depth == 0 {
+				s.colon = i
+			}
+		}
+	}
+	// A string in single quotes ends with its line
+	if len(s.quote) == 1 && !s.continued {
+		s.quote = ""
+	}
+}
+
+// Returns true if stripped, a line with its indentation removed,
+// starts a def or class statement or one of their decorators
+func startsDefinition(stripped string) bool {
+	for _, prefix := range []string{"@", "def ", "class ", "async def "} {
+		if strings.HasPrefix(stripped, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the lines of the block of source starting at lines[start]
+//
+// This is a def or class statement with its decorators and its body,
+// which is the lines after it that are indented further, or else the
+// logical line starting there such as that of a lambda.
+func getBlock(lines []string, start int) []string {
+	var s lineScanner
+	if !startsDefinition(strings.TrimSpace(lines[start])) {
+		end := start
+		for end < len(lines) {
+			s.scan(lines[end])
+			end++
+			if !s.open() {
+				break
+			}
+		}
+		return lines[start:end]
+	}
+	indent := indentOf(lines[start])
+	header := true
+	statement := ""
+	end := start + 1
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		stripped := strings.TrimSpace(line)
+		if !s.open() {
+			if stripped == "" || strings.HasPrefix(stripped, "#") {
+				continue
+			}
+			if !header && indentOf(line) <= indent {
+				break
+			}
+			statement = stripped
+		}
+		s.scan(line)
+		end = i + 1
+		if header && !s.open() && !strings.HasPrefix(statement, "@") {
+			header = false
+			// A def with its body on the same line
+			if s.colon >= 0 {
+				if rest := strings.TrimSpace(line[s.colon+1:]); rest != "" && rest[0] != '#' {
+					break
+				}
+			}
+		}
+	}
+	return lines[start:end]
+}
+
+// Returns the lines with their line endings as a list
+func sourceList(lines []string) *py.List {
+	list := py.NewListSized(len(lines))
+	for i, line := range lines {
+		list.Items[i] = py.String(line + "\n")
+	}
+	return list
+}
+
+// Returns the lines of source of obj and the line number they start
+// at, which is 0 for a module
+func getsourcelines(obj py.Object) ([]string, int, error) {
+	obj, err := unwrap(obj)
+	if err != nil {
+		return nil, 0, err
+	}
+	file, err := getfile(obj)
+	if err != nil {
+		return nil, 0, err
+	}
+	lines := py.SourceLines(file)
+	if lines == nil {
+		return nil, 0, py.ExceptionNewf(py.OSError, "could not get source code")
+	}
+	if _, ok := obj.(*py.Module); ok {
+		return lines, 0, nil
+	}
+	if t, ok := obj.(*py.Type); ok {
+		name := t.Name
+		if qualname, ok := t.Dict["__qualname__"].(py.String); ok {
+			name = string(qualname)
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[i+1:]
+			}
+		}
+		pattern := regexp.MustCompile(`^(\s*)class\s*` + regexp.QuoteMeta(name) + `\b`)
+		start := -1
+		for i, line := range lines {
+			if match := pattern.FindStringSubmatch(line); match != nil {
+				// Prefer a class defined at the top level
+				if match[1] == "" {
+					start = i
+					break
+				}
+				if start < 0 {
+					start = i
+				}
+			}
+		}
+		if start < 0 {
+			return nil, 0, py.ExceptionNewf(py.OSError, "could not find class definition")
+		}
+		return getBlock(lines, start), start + 1, nil
+	}
+	co := codeOf(obj)
+	if co.Name == "<module>" {
+		return lines, 0, nil
+	}
+	start := int(co.Firstlineno) - 1
+	if start < 0 || start >= len(lines) {
+		return nil, 0, py.ExceptionNewf(py.OSError, "lineno is out of bounds")
+	}
+	return getBlock(lines, start), start + 1, nil
+}
+
+const getsourcelines_doc = `getsourcelines(object) -> (lines, lnum)
+
+Return a list of source lines and starting line number for an object.
+
+The argument may be a module, class, method, function, traceback, frame,
+or code object.  The source code is returned as a list of the lines
+corresponding to the object and the line number indicates where in the
+original source file the first line of code was found.  An OSError is
+raised if the source code cannot be retrieved.`
+
+func inspect_getsourcelines(self py.Object, obj py.Object) (py.Object, error) {
+	lines, lnum, err := getsourcelines(obj)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{sourceList(lines), py.Int(lnum)}, nil
+}
+
+const getsource_doc = `getsource(object) -> str
+
+Return the text of the source code for an object.
+
+The argument may be a module, class, method, function, traceback, frame,
+or code object.  The source code is returned as a single string.  An
+OSError is raised if the source code cannot be retrieved.`
+
+func inspect_getsource(self py.Object, obj py.Object) (py.Object, error) {
+	lines, _, err := getsourcelines(obj)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(strings.Join(lines, "\n") + "\n"), nil
+}
@@ -0,0 +1,246 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import inspect
+from libtest import *
+
+doc="signature"
+def f(a, b=1, *args, c, d=2, **kw): pass
+assertEqual(str(inspect.signature(f)), "(a, b=1, *args, c, d=2, **kw)")
+def f(a: int, b: str = "x") -> list: pass
+assertEqual(str(inspect.signature(f)), "(a: int, b: str = 'x') -> list")
+def f(*, key): pass
+assertEqual(str(inspect.signature(f)), "(*, key)")
+def f(): pass
+assertEqual(str(inspect.signature(f)), "()")
+assertEqual(str(inspect.signature(lambda x, y=None: x)), "(x, y=None)")
+
+doc="signature of methods and classes"
+class C:
+    def __init__(self, x, y=0):
+        pass
+    def method(self, a, *rest):
+        pass
+assertEqual(str(inspect.signature(C)), "(x, y=0)")
+assertEqual(str(inspect.signature(C.method)), "(self, a, *rest)")
+assertEqual(str(inspect.signature(C(1).method)), "(a, *rest)")
+class Callable:
+    def __call__(self, n):
+        pass
+assertEqual(str(inspect.signature(Callable())), "(n)")
+class Empty:
+    pass
+assertEqual(str(inspect.signature(Empty)), "()")
+
+doc="signature of wrapped functions"
+def wrapped(p, q=3):
+    pass
+def wrapper(*args, **kwargs):
+    return wrapped(*args, **kwargs)
+wrapper.__wrapped__ = wrapped
+assertEqual(str(inspect.signature(wrapper)), "(p, q=3)")
+
+doc="signature errors"
+assertRaises(ValueError, inspect.signature, len)
+assertRaises(TypeError, inspect.signature, 1)
+
+doc="Parameter and Signature"
+def f(a, b=1, *args, c, **kw): pass
+sig = inspect.signature(f)
+params = sig.parameters
+assertEqual(list(params), ["a", "b", "args", "c", "kw"])
+assertEqual(params["a"].kind, inspect.Parameter.POSITIONAL_OR_KEYWORD)
+assertEqual(params["args"].kind, inspect.Parameter.VAR_POSITIONAL)
+assertEqual(params["c"].kind, inspect.Parameter.KEYWORD_ONLY)
+assertEqual(params["kw"].kind, inspect.Parameter.VAR_KEYWORD)
+assertTrue(params["a"].default is inspect.Parameter.empty)
+assertEqual(params["b"].default, 1)
+assertEqual(params["b"].name, "b")
+assertTrue(sig.return_annotation is inspect.Signature.empty)
+assertEqual(str(params["kw"].kind), "VAR_KEYWORD")
+assertEqual(repr(params["b"]), '<Parameter "b=1">')
+
+p = inspect.Parameter("x", inspect.Parameter.POSITIONAL_ONLY)
+q = inspect.Parameter("y", inspect.Parameter.KEYWORD_ONLY, default=5)
+s = inspect.Signature([p, q])
+assertEqual(str(s), "(x, /, *, y=5)")
+assertEqual(s, inspect.Signature([p, q]))
+assertTrue(s != inspect.Signature([p]))
+assertRaises(ValueError, inspect.Signature, [q, p])
+assertRaises(ValueError, inspect.Signature, [p, p])
+assertRaises(ValueError, inspect.Parameter, "1x", inspect.Parameter.POSITIONAL_ONLY)
+
+doc="predicates"
+def gen():
+    yield 1
+class K:
+    def m(self):
+        pass
+assertTrue(inspect.ismodule(inspect))
+assertFalse(inspect.ismodule(f))
+assertTrue(inspect.isclass(K))
+assertFalse(inspect.isclass(K()))
+assertTrue(inspect.isfunction(f))
+assertFalse(inspect.isfunction(len))
+assertTrue(inspect.ismethod(K().m))
+assertFalse(inspect.ismethod(K.m))
+assertTrue(inspect.isbuiltin(len))
+assertTrue(inspect.isbuiltin([].append))
+assertFalse(inspect.isbuiltin(f))
+assertTrue(inspect.isroutine(f))
+assertTrue(inspect.isroutine(len))
+assertTrue(inspect.isgeneratorfunction(gen))
+assertFalse(inspect.isgeneratorfunction(f))
+assertTrue(inspect.isgenerator(gen()))
+assertTrue(inspect.iscode(f.__code__))
+assertTrue(inspect.isframe(inspect.currentframe()))
+
+doc="getmembers"
+class M:
+    x = 1
+    def y(self):
+        pass
+members = dict(inspect.getmembers(M))
+assertEqual(members["x"], 1)
+assertTrue("y" in members)
+assertTrue("__init__" in members)
+names = [name for name, value in inspect.getmembers(M, inspect.isfunction)]
+assertEqual(names, ["y"])
+members = inspect.getmembers(M)
+assertEqual([name for name, value in members], sorted(name for name, value in members))
+
+doc="getsource of functions"
+def source_function(a,
+                    b):
+    # a comment
+    return (a +
+            b)
+
+# not part of it
+assertEqual(inspect.getsource(source_function), '''def source_function(a,
+                    b):
+    # a comment
+    return (a +
+            b)
+''')
+lines, lnum = inspect.getsourcelines(source_function)
+assertEqual(lines[0], "def source_function(a,\n")
+assertEqual(len(lines), 5)
+assertEqual(inspect.getsourcefile(source_function), __file__)
+assertEqual(inspect.getfile(source_function), __file__)
+
+doc="getsource of decorated and one line functions"
+def decorator(fn):
+    return fn
+@decorator
+def decorated():
+    s = """
+not indented"""
+    return s
+assertEqual(inspect.getsource(decorated), '@decorator\ndef decorated():\n    s = """\nnot indented"""\n    return s\n')
+def one_liner(): return 1
+assertEqual(inspect.getsource(one_liner), "def one_liner(): return 1\n")
+square = lambda x: x*x
+assertEqual(inspect.getsource(square), "square = lambda x: x*x\n")
+
+doc="getsource of classes and methods"
+class Source:
+    "A class"
+    def method(self):
+        return 1
+
+    def other(self):
+        pass
+assertEqual(inspect.getsource(Source), '''class Source:
+    "A class"
+    def method(self):
+        return 1
+
+    def other(self):
+        pass
+''')
+assertEqual(inspect.getsource(Source.method), "    def method(self):\n        return 1\n")
+assertEqual(inspect.getsource(Source().method), "    def method(self):\n        return 1\n")
+
+doc="getsource errors"
+assertRaises(TypeError, inspect.getsource, len)
+assertRaises(TypeError, inspect.getsource, 1)
+assertRaises(TypeError, inspect.getfile, inspect)
+
+doc="getmodule"
+assertTrue(inspect.getmodule(inspect) is inspect)
+assertEqual(inspect.getmodule(1), None)
+assertEqual(inspect.getmodule(f).__name__, __name__)
+
+doc="getdoc and cleandoc"
+def documented():
+    """First line.
+
+    Indented body
+        more indented
+    """
+assertEqual(inspect.getdoc(documented), "First line.\n\nIndented body\n    more indented")
+assertEqual(inspect.cleandoc("  a\n    b\n  c\n\n"), "a\n  b\nc")
+assertEqual(inspect.cleandoc("\n\n    x\n"), "x")
+assertEqual(inspect.getdoc(f), None)
+assertEqual(inspect.getdoc(Source), "A class")
+
+doc="function and method attributes"
+assertEqual(f.__globals__["__name__"], __name__)
+assertEqual(f.__closure__, None)
+def outer():
+    v = 1
+    def inner():
+        return v
+    return inner
+assertEqual(len(outer().__closure__), 1)
+k = K()
+assertTrue(k.m.__self__ is k)
+assertTrue(k.m.__func__ is K.m)
+assertEqual(k.m.__name__, "m")
+assertEqual(len.__name__, "len")
+assertTrue(len.__doc__.startswith("len("))
+assertEqual(repr(len), "<built-in function len>")
+t = ()
+assertTrue(t is t)
+
+doc="currentframe and stack"
+def where():
+    return inspect.currentframe()
+frame = where()
+assertEqual(frame.f_code.co_name, "where")
+assertEqual(frame.f_back.f_code.co_name, "<module>")
+assertTrue(frame.f_globals is globals())
+def caller():
+    local = 42
+    return callee()
+def callee():
+    return inspect.stack()
+stack = caller()
+assertEqual([record[3] for record in stack[:3]], ["callee", "caller", "<module>"])
+assertEqual(stack[1][0].f_locals["local"], 42)
+assertEqual(stack[0][1], __file__)
+assertEqual(stack[0][4], ["    return inspect.stack()\n"])
+assertEqual(stack[0][5], 0)
+assertEqual(stack[0][2], callee.__code__.co_firstlineno + 1)
+record = stack[0]
+assertTrue(isinstance(record, inspect.FrameInfo))
+assertTrue(isinstance(record, tuple))
+assertEqual(record.function, "callee")
+assertEqual(record.filename, __file__)
+assertEqual(record.lineno, callee.__code__.co_firstlineno + 1)
+assertEqual(record.code_context, ["    return inspect.stack()\n"])
+assertEqual(record.index, 0)
+assertEqual(stack[1].frame.f_locals["local"], 42)
+outer = inspect.getouterframes(frame)
+assertEqual([record.function for record in outer[:2]], ["where", "<module>"])
+assertTrue(outer[0].frame is frame)
+info = inspect.getframeinfo(frame)
+assertEqual(info.function, "where")
+assertEqual(info.lineno, where.__code__.co_firstlineno + 1)
+assertEqual(info.code_context, ["    return inspect.currentframe()\n"])
+assertEqual(info.index, 0)
+assertEqual(info.filename, __file__)
+
+doc="finished"
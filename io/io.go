@@ -0,0 +1,607 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// IO module
+//
+// The file objects returned by open() are defined in the py package
+// as they are used by the built in modules.  This module adds the
+// in memory streams StringIO and BytesIO.
+
+package io
+
+import (
+	"io"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const io_doc = `The io module provides the Python interfaces to stream handling. The
+builtin open function is defined in this module.
+
+At the top of the I/O hierarchy is the abstract base class IOBase. It
+defines the basic interface to a stream.
+
+Text I/O is handled by TextIOWrapper for files and by StringIO for
+strings held in memory.  Binary I/O is handled by FileIO, the Buffered*
+classes and BytesIO.`
+
+var (
+	StringIOType = py.TextIOBaseType.NewType("StringIO", "StringIO(initial_value='', newline='\\n') -> Text I/O implementation using an in-memory buffer.", StringIONew, nil)
+	BytesIOType  = py.BufferedIOBaseType.NewType("BytesIO", "BytesIO([initial_bytes]) -> Buffered I/O implementation using an in-memory bytes buffer.", BytesIONew, nil)
+)
+
+var errClosed = py.ExceptionNewf(py.ValueError, "I/O operation on closed file.")
+
+// StringIO is an in memory text stream
+type StringIO struct {
+	buf     []rune
+	pos     int
+	newline py.Object
+	closed  bool
+}
+
+// Type of this object
+func (s *StringIO) Type() *py.Type {
+	return StringIOType
+}
+
+// StringIONew makes a StringIO from its initial value
+func StringIONew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var initial py.Object = py.String("")
+	var newline py.Object = py.String("\n")
+	err := py.ParseTupleAndKeywords(args, kwargs, "|zz:StringIO", []string{"initial_value", "newline"}, &initial, &newline)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := newline.(py.String); ok {
+		switch s {
+		case "", "\n", "\r", "\r\n":
+		default:
+			return nil, py.ExceptionNewf(py.ValueError, "illegal newline value: %s", s)
+		}
+	}
+	s := &StringIO{newline: newline}
+	if initial != py.None {
+		s.buf = s.translate(string(initial.(py.String)))
+	}
+	return s, nil
+}
+
+// Returns the characters of a string written to the StringIO
+// translating newlines as directed by newline
+func (s *StringIO) translate(value string) []rune {
+	switch newline := s.newline.(type) {
+	case py.NoneType:
+		value = strings.Replace(value, "\r\n", "\n", -1)
+		value = strings.Replace(value, "\r", "\n", -1)
+	case py.String:
+		if newline == "\r" || newline == "\r\n" {
+			value = strings.Replace(value, "\n", string(newline), -1)
+		}
+	}
+	return []rune(value)
+}
+
+func (s *StringIO) check() error {
+	if s.closed {
+		return errClosed
+	}
+	return nil
+}
+
+// Write writes value at the position, returning the number of
+// characters written
+func (s *StringIO) Write(value py.Object) (py.Object, error) {
+	str, ok := value.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "string argument expected, got '%s'", value.Type().Name)
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	n := len([]rune(string(str)))
+	s.buf, s.pos = writeAt(s.buf, s.pos, s.translate(string(str)))
+	return py.Int(n), nil
+}
+
+// Writes b at pos in buf padding the gap past the end with zeros,
+// returning the new buf and pos
+func writeAt(buf []rune, pos int, b []rune) ([]rune, int) {
+	for len(buf) < pos {
+		buf = append(buf, 0)
+	}
+	end := pos + len(b)
+	if end > len(buf) {
+		buf = append(buf[:pos], b...)
+	} else {
+		copy(buf[pos:], b)
+	}
+	return buf, end
+}
+
+// Read reads at most n characters or the rest if n is negative
+func (s *StringIO) Read(n int) (py.Object, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	if s.pos >= len(s.buf) {
+		return py.String(""), nil
+	}
+	end := len(s.buf)
+	if n >= 0 && s.pos+n < end {
+		end = s.pos + n
+	}
+	value := string(s.buf[s.pos:end])
+	s.pos = end
+	return py.String(value), nil
+}
+
+// ReadLine reads a line keeping its line ending, reading at most size
+// characters if size isn't negative
+func (s *StringIO) ReadLine(size int) (py.Object, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	if s.pos >= len(s.buf) {
+		return py.String(""), nil
+	}
+	newline, _ := s.newline.(py.String)
+	end := s.pos
+	for end < len(s.buf) && (size < 0 || end-s.pos < size) {
+		r := s.buf[end]
+		end++
+		if r == '\n' && newline != "\r" && newline != "\r\n" {
+			break
+		}
+		if r == '\r' && newline == "\r" {
+			break
+		}
+		if r == '\r' && (newline == "" && s.newline != py.None || newline == "\r\n") {
+			if end < len(s.buf) && s.buf[end] == '\n' {
+				end++
+				break
+			}
+			if newline == "" {
+				break
+			}
+		}
+	}
+	value := string(s.buf[s.pos:end])
+	s.pos = end
+	return py.String(value), nil
+}
+
+// Seek moves the position to pos, which must be 0 unless whence is 0
+func (s *StringIO) Seek(pos, whence int) (py.Object, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	switch whence {
+	case io.SeekStart:
+		if pos < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "Negative seek position %d", pos)
+		}
+		s.pos = pos
+	case io.SeekCurrent, io.SeekEnd:
+		if pos != 0 {
+			return nil, py.ExceptionNewf(py.OSError, "Can't do nonzero cur-relative seeks")
+		}
+		if whence == io.SeekEnd {
+			s.pos = len(s.buf)
+		}
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid whence (%d, should be 0, 1 or 2)", whence)
+	}
+	return py.Int(s.pos), nil
+}
+
+// Truncate cuts the buffer down to size characters, or to the
+// position if size is None
+func (s *StringIO) Truncate(size py.Object) (py.Object, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	n := s.pos
+	if size != py.None {
+		var err error
+		n, err = py.IndexInt(size)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "Negative size value %d", n)
+		}
+	}
+	if n < len(s.buf) {
+		s.buf = s.buf[:n]
+	}
+	return py.Int(n), nil
+}
+
+// GetValue returns the contents of the buffer
+func (s *StringIO) GetValue() (py.Object, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return py.String(string(s.buf)), nil
+}
+
+func (s *StringIO) M__iter__() (py.Object, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *StringIO) M__next__() (py.Object, error) {
+	line, err := s.ReadLine(-1)
+	if err != nil {
+		return nil, err
+	}
+	if line == py.String("") {
+		return nil, py.StopIteration
+	}
+	return line, nil
+}
+
+// BytesIO is an in memory binary stream
+type BytesIO struct {
+	buf    []byte
+	pos    int
+	closed bool
+}
+
+// Type of this object
+func (b *BytesIO) Type() *py.Type {
+	return BytesIOType
+}
+
+// BytesIONew makes a BytesIO from its initial bytes
+func BytesIONew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var initial py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:BytesIO", []string{"initial_bytes"}, &initial)
+	if err != nil {
+		return nil, err
+	}
+	b := &BytesIO{}
+	if initial != py.None {
+		value, ok := initial.(py.Bytes)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", initial.Type().Name)
+		}
+		b.buf = append([]byte{}, value...)
+	}
+	return b, nil
+}
+
+func (b *BytesIO) check() error {
+	if b.closed {
+		return errClosed
+	}
+	return nil
+}
+
+// Write writes value at the position, returning the number of bytes
+// written
+func (b *BytesIO) Write(value py.Object) (py.Object, error) {
+	data, ok := value.(py.Bytes)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", value.Type().Name)
+	}
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	for len(b.buf) < b.pos {
+		b.buf = append(b.buf, 0)
+	}
+	end := b.pos + len(data)
+	if end > len(b.buf) {
+		b.buf = append(b.buf[:b.pos], data...)
+	} else {
+		copy(b.buf[b.pos:], data)
+	}
+	b.pos = end
+	return py.Int(len(data)), nil
+}
+
+// Read reads at most n bytes or the rest if n is negative
+func (b *BytesIO) Read(n int) (py.Object, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	if b.pos >= len(b.buf) {
+		return py.Bytes{}, nil
+	}
+	end := len(b.buf)
+	if n >= 0 && b.pos+n < end {
+		end = b.pos + n
+	}
+	value := append(py.Bytes{}, b.buf[b.pos:end]...)
+	b.pos = end
+	return value, nil
+}
+
+// ReadLine reads a line keeping its line ending, reading at most size
+// bytes if size isn't negative
+func (b *BytesIO) ReadLine(size int) (py.Object, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	end := b.pos
+	for end < len(b.buf) && (size < 0 || end-b.pos < size) {
+		end++
+		if b.buf[end-1] == '\n' {
+			break
+		}
+	}
+	if end <= b.pos {
+		return py.Bytes{}, nil
+	}
+	value := append(py.Bytes{}, b.buf[b.pos:end]...)
+	b.pos = end
+	return value, nil
+}
+
+// Seek moves the position pos bytes from the start, the position or
+// the end as whence is 0, 1 or 2
+func (b *BytesIO) Seek(pos, whence int) (py.Object, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	switch whence {
+	case io.SeekStart:
+		if pos < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "negative seek value %d", pos)
+		}
+	case io.SeekCurrent:
+		pos += b.pos
+	case io.SeekEnd:
+		pos += len(b.buf)
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "invalid whence (%d, should be 0, 1 or 2)", whence)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	b.pos = pos
+	return py.Int(b.pos), nil
+}
+
+// Truncate cuts the buffer down to size bytes, or to the position if
+// size is None
+func (b *BytesIO) Truncate(size py.Object) (py.Object, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	n := b.pos
+	if size != py.None {
+		var err error
+		n, err = py.IndexInt(size)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "negative size value %d", n)
+		}
+	}
+	if n < len(b.buf) {
+		b.buf = b.buf[:n]
+	}
+	return py.Int(n), nil
+}
+
+// GetValue returns the contents of the buffer
+func (b *BytesIO) GetValue() (py.Object, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	return append(py.Bytes{}, b.buf...), nil
+}
+
+func (b *BytesIO) M__iter__() (py.Object, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *BytesIO) M__next__() (py.Object, error) {
+	line, err := b.ReadLine(-1)
+	if err != nil {
+		return nil, err
+	}
+	if len(line.(py.Bytes)) == 0 {
+		return nil, py.StopIteration
+	}
+	return line, nil
+}
+
+// The methods StringIO and BytesIO share
+type memoryStream interface {
+	py.Object
+	check() error
+	Write(value py.Object) (py.Object, error)
+	Read(n int) (py.Object, error)
+	ReadLine(size int) (py.Object, error)
+	Seek(pos, whence int) (py.Object, error)
+	Truncate(size py.Object) (py.Object, error)
+	GetValue() (py.Object, error)
+	close()
+	isClosed() bool
+}
+
+func (s *StringIO) close()         { s.closed = true; s.buf = nil }
+func (s *StringIO) isClosed() bool { return s.closed }
+func (b *BytesIO) close()          { b.closed = true; b.buf = nil }
+func (b *BytesIO) isClosed() bool  { return b.closed }
+
+// Returns the optional size argument of the read methods
+func sizeArg(name string, args py.Tuple) (int, error) {
+	var arg py.Object = py.None
+	err := py.UnpackTuple(args, nil, name, 0, 1, &arg)
+	if err != nil || arg == py.None {
+		return -1, err
+	}
+	return py.IndexInt(arg)
+}
+
+// Returns a method which returns whether the stream is open
+func trueIfOpen(name, doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object) (py.Object, error) {
+		if err := self.(memoryStream).check(); err != nil {
+			return nil, err
+		}
+		return py.True, nil
+	}, 0, doc)
+}
+
+// Adds the methods of memoryStream to t
+func addMethods(t *py.Type) {
+	methods := []*py.Method{
+		py.MustNewMethod("getvalue", func(self py.Object) (py.Object, error) {
+			return self.(memoryStream).GetValue()
+		}, 0, "getvalue() -> the entire contents of the buffer."),
+		py.MustNewMethod("read", func(self py.Object, args py.Tuple) (py.Object, error) {
+			n, err := sizeArg("read", args)
+			if err != nil {
+				return nil, err
+			}
+			return self.(memoryStream).Read(n)
+		}, 0, "read([size]) -> read at most size characters or bytes, returned as a string or bytes.\n\nIf the size argument is negative or omitted, read until EOF is reached."),
+		py.MustNewMethod("readline", func(self py.Object, args py.Tuple) (py.Object, error) {
+			n, err := sizeArg("readline", args)
+			if err != nil {
+				return nil, err
+			}
+			return self.(memoryStream).ReadLine(n)
+		}, 0, "readline([size]) -> next line from the buffer.\n\nRetain newline.  A non-negative size argument limits the maximum\nnumber of characters or bytes to return."),
+		py.MustNewMethod("readlines", func(self py.Object, args py.Tuple) (py.Object, error) {
+			hint, err := sizeArg("readlines", args)
+			if err != nil {
+				return nil, err
+			}
+			if err := self.(memoryStream).check(); err != nil {
+				return nil, err
+			}
+			return py.ReadLines(self, hint)
+		}, 0, "readlines([hint]) -> list of lines from the buffer."),
+		py.MustNewMethod("write", func(self py.Object, value py.Object) (py.Object, error) {
+			return self.(memoryStream).Write(value)
+		}, 0, "write(s) -> write s to the buffer, returning its length."),
+		py.MustNewMethod("writelines", func(self py.Object, lines py.Object) (py.Object, error) {
+			if err := self.(memoryStream).check(); err != nil {
+				return nil, err
+			}
+			return py.WriteLines(self, lines)
+		}, 0, "writelines(lines) -> write each of the lines to the buffer."),
+		py.MustNewMethod("seek", func(self py.Object, args py.Tuple) (py.Object, error) {
+			var pos py.Object
+			var whence py.Object = py.Int(io.SeekStart)
+			err := py.UnpackTuple(args, nil, "seek", 1, 2, &pos, &whence)
+			if err != nil {
+				return nil, err
+			}
+			p, err := py.IndexInt(pos)
+			if err != nil {
+				return nil, err
+			}
+			w, err := py.IndexInt(whence)
+			if err != nil {
+				return nil, err
+			}
+			return self.(memoryStream).Seek(p, w)
+		}, 0, "seek(pos[, whence]) -> change the stream position, returning the new position."),
+		py.MustNewMethod("tell", func(self py.Object) (py.Object, error) {
+			return self.(memoryStream).Seek(0, io.SeekCurrent)
+		}, 0, "tell() -> current stream position."),
+		py.MustNewMethod("truncate", func(self py.Object, args py.Tuple) (py.Object, error) {
+			var size py.Object = py.None
+			err := py.UnpackTuple(args, nil, "truncate", 0, 1, &size)
+			if err != nil {
+				return nil, err
+			}
+			return self.(memoryStream).Truncate(size)
+		}, 0, "truncate([size]) -> truncate the buffer to size, the position by default.\n\nThe position is unchanged.  Returns the new size."),
+		py.MustNewMethod("close", func(self py.Object) (py.Object, error) {
+			self.(memoryStream).close()
+			return py.None, nil
+		}, 0, "close() -> None.  Free the buffer.\n\nOperations on the closed stream raise ValueError."),
+		py.MustNewMethod("flush", func(self py.Object) (py.Object, error) {
+			if err := self.(memoryStream).check(); err != nil {
+				return nil, err
+			}
+			return py.None, nil
+		}, 0, "flush() -> None.  Does nothing."),
+		trueIfOpen("readable", "readable() -> True if the stream is open."),
+		trueIfOpen("writable", "writable() -> True if the stream is open."),
+		trueIfOpen("seekable", "seekable() -> True if the stream is open."),
+		py.MustNewMethod("isatty", func(self py.Object) (py.Object, error) {
+			if err := self.(memoryStream).check(); err != nil {
+				return nil, err
+			}
+			return py.False, nil
+		}, 0, "isatty() -> False."),
+		py.MustNewMethod("__enter__", func(self py.Object) (py.Object, error) {
+			if err := self.(memoryStream).check(); err != nil {
+				return nil, err
+			}
+			return self, nil
+		}, 0, ""),
+		py.MustNewMethod("__exit__", func(self py.Object, args py.Tuple) (py.Object, error) {
+			self.(memoryStream).close()
+			return py.None, nil
+		}, 0, ""),
+	}
+	for _, method := range methods {
+		t.Dict[method.Name] = method
+	}
+	t.Dict["closed"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.NewBool(self.(memoryStream).isClosed()), nil
+		},
+		Doc: "True if the stream is closed.",
+	}
+}
+
+func init() {
+	addMethods(StringIOType)
+	addMethods(BytesIOType)
+	StringIOType.Dict["newlines"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.None, nil
+		},
+	}
+
+	globals := py.StringDict{
+		"DEFAULT_BUFFER_SIZE":  py.Int(py.DefaultBufferSize),
+		"SEEK_SET":             py.Int(io.SeekStart),
+		"SEEK_CUR":             py.Int(io.SeekCurrent),
+		"SEEK_END":             py.Int(io.SeekEnd),
+		"UnsupportedOperation": py.UnsupportedOperation,
+		"IOBase":               py.IOBaseType,
+		"RawIOBase":            py.RawIOBaseType,
+		"BufferedIOBase":       py.BufferedIOBaseType,
+		"TextIOBase":           py.TextIOBaseType,
+		"FileIO":               py.FileIOType,
+		"BufferedReader":       py.BufferedReaderType,
+		"BufferedWriter":       py.BufferedWriterType,
+		"BufferedRandom":       py.BufferedRandomType,
+		"TextIOWrapper":        py.TextIOWrapperType,
+		"StringIO":             StringIOType,
+		"BytesIO":              BytesIOType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "io",
+		Doc:     io_doc,
+		Globals: globals,
+		Init: func(ctx *py.Context, m *py.Module) error {
+			// io.open is the same function as the built in open
+			if open, ok := ctx.Builtins().Globals["open"]; ok {
+				m.Globals["open"] = open
+			}
+			return nil
+		},
+	})
+}
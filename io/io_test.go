@@ -0,0 +1,17 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package io_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-python/gpython/pytest"
+)
+
+func TestIO(t *testing.T) {
+	defer os.Remove("tests/iotests.py.tmp")
+	pytest.RunTests(t, "tests")
+}
@@ -0,0 +1,305 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import io
+from libtest import *
+
+# The file the tests write, removed by the Go test when it finishes
+TMP = __file__ + ".tmp"
+
+doc = "module"
+assert io.open is open
+assert io.DEFAULT_BUFFER_SIZE == 8192
+assert (io.SEEK_SET, io.SEEK_CUR, io.SEEK_END) == (0, 1, 2)
+assert issubclass(io.UnsupportedOperation, OSError)
+assert issubclass(io.UnsupportedOperation, ValueError)
+
+doc = "types"
+with open(TMP, "w") as f:
+    assert type(f) is io.TextIOWrapper
+    assert isinstance(f, io.TextIOBase)
+    assert isinstance(f, io.IOBase)
+    assert f.name == TMP
+    assert f.mode == "w"
+    assert f.encoding in ("UTF-8", "utf-8")
+    assert f.errors == "strict"
+    assert not f.line_buffering
+    assert repr(f).startswith("<_io.TextIOWrapper name=")
+with open(TMP, "rb") as f:
+    assert type(f) is io.BufferedReader
+    assert isinstance(f, io.BufferedIOBase)
+with open(TMP, "wb") as f:
+    assert type(f) is io.BufferedWriter
+with open(TMP, "r+b") as f:
+    assert type(f) is io.BufferedRandom
+with open(TMP, "rb", buffering=0) as f:
+    assert type(f) is io.FileIO
+    assert isinstance(f, io.RawIOBase)
+with open(TMP, "w", buffering=1) as f:
+    assert f.line_buffering
+
+doc = "modes"
+for mode in ("", "rw", "rr", "r++", "rbt", "q", "ra"):
+    assertRaises(ValueError, open, TMP, mode)
+assertRaises(ValueError, open, TMP, "rb", encoding="utf-8")
+assertRaises(ValueError, open, TMP, "rb", errors="strict")
+assertRaises(ValueError, open, TMP, "rb", newline="")
+assertRaises(ValueError, open, TMP, "r", buffering=0)
+assertRaises(ValueError, open, TMP, "r", newline="x")
+assertRaises(LookupError, open, TMP, "r", encoding="no-such-encoding")
+assertRaises(ValueError, open, TMP, "r", closefd=False)
+
+doc = "write and read"
+with open(TMP, "w") as f:
+    assert f.write("hello\n") == 6
+    assert f.write("wörld\n") == 6
+    f.writelines(["a\n", "b\n"])
+with open(TMP) as f:
+    assert f.readable()
+    assert not f.writable()
+    assert f.seekable()
+    assert f.read() == "hello\nwörld\na\nb\n"
+with open(TMP, "rb") as f:
+    assert f.read() == b"hello\nw\xc3\xb6rld\na\nb\n"
+
+doc = "x and a"
+with open(TMP, "a") as f:
+    f.write("c\n")
+with open(TMP) as f:
+    assert f.read().endswith("a\nb\nc\n")
+assertRaises(FileExistsError, open, TMP, "x")
+
+doc = "readline"
+with open(TMP) as f:
+    assert f.readline() == "hello\n"
+    assert f.readline(3) == "wör"
+    assert f.readline() == "ld\n"
+    assert f.readlines() == ["a\n", "b\n", "c\n"]
+    assert f.readline() == ""
+with open(TMP) as f:
+    assert f.readlines(8) == ["hello\n", "wörld\n"]
+with open(TMP) as f:
+    assert [line for line in f] == ["hello\n", "wörld\n", "a\n", "b\n", "c\n"]
+with open(TMP, "rb") as f:
+    assert f.readline() == b"hello\n"
+    assert f.readline(2) == b"w\xc3"
+    assert list(f) == [b"\xb6rld\n", b"a\n", b"b\n", b"c\n"]
+
+doc = "seek and tell"
+with open(TMP, "rb") as f:
+    assert f.read(3) == b"hel"
+    assert f.tell() == 3
+    assert f.seek(1, io.SEEK_CUR) == 4
+    assert f.read(2) == b"o\n"
+    assert f.seek(-2, io.SEEK_END) == f.tell()
+    assert f.read() == b"c\n"
+    assert f.seek(0) == 0
+    assert f.read(5) == b"hello"
+with open(TMP) as f:
+    assert f.read(2) == "he"
+    assert f.tell() == 2
+    assertRaises(io.UnsupportedOperation, f.seek, 1, io.SEEK_CUR)
+    f.seek(0, io.SEEK_END)
+    assert f.read() == ""
+    f.seek(0)
+    assert f.read(5) == "hello"
+with open(TMP, "r+") as f:
+    f.seek(0, io.SEEK_END)
+    f.write("end\n")
+    f.seek(0)
+    assert f.read(5) == "hello"
+    f.seek(5)
+    f.write("!")
+    f.seek(0)
+    assert f.readline() == "hello!wörld\n"
+
+doc = "truncate"
+with open(TMP, "r+b") as f:
+    assert f.truncate(5) == 5
+    assert f.read() == b"hello"
+    f.seek(2)
+    assert f.truncate() == 2
+with open(TMP, "rb") as f:
+    assert f.read() == b"he"
+
+doc = "binary"
+with open(TMP, "wb") as f:
+    assert f.write(b"\x00\x01\xff") == 3
+    assertRaises(TypeError, f.write, "str")
+with open(TMP, "rb") as f:
+    assert f.read(1) == b"\x00"
+    assert f.read(10) == b"\x01\xff"
+    assert f.read(10) == b""
+
+doc = "encodings"
+with open(TMP, "w", encoding="latin-1") as f:
+    assert f.encoding == "latin-1"
+    f.write("caf\xe9")
+    assertRaises(UnicodeEncodeError, f.write, "€")
+with open(TMP, "rb") as f:
+    assert f.read() == b"caf\xe9"
+with open(TMP, encoding="latin-1") as f:
+    assert f.read() == "caf\xe9"
+assertRaises(UnicodeDecodeError, open(TMP).read)
+with open(TMP, errors="replace") as f:
+    assert f.read() == "caf�"
+with open(TMP, errors="ignore") as f:
+    assert f.read() == "caf"
+with open(TMP, "w", encoding="ascii", errors="replace") as f:
+    f.write("caf\xe9")
+with open(TMP, "rb") as f:
+    assert f.read() == b"caf?"
+
+doc = "newlines"
+with open(TMP, "wb") as f:
+    f.write(b"a\nb\r\nc\rd")
+with open(TMP) as f:
+    assert f.read() == "a\nb\nc\nd"
+with open(TMP) as f:
+    assert f.readlines() == ["a\n", "b\n", "c\n", "d"]
+with open(TMP, newline="") as f:
+    assert f.readlines() == ["a\n", "b\r\n", "c\r", "d"]
+with open(TMP, newline="\n") as f:
+    assert f.readlines() == ["a\n", "b\r\n", "c\rd"]
+with open(TMP, newline="\r") as f:
+    assert f.readlines() == ["a\nb\r", "\nc\r", "d"]
+with open(TMP, newline="\r\n") as f:
+    assert f.readlines() == ["a\nb\r\n", "c\rd"]
+with open(TMP, "w", newline="\r\n") as f:
+    f.write("a\nb\n")
+with open(TMP, "rb") as f:
+    assert f.read() == b"a\r\nb\r\n"
+
+doc = "buffering"
+f = open(TMP, "w")
+f.write("buffered")
+with open(TMP) as g:
+    assert g.read() == ""
+f.flush()
+with open(TMP) as g:
+    assert g.read() == "buffered"
+f.close()
+f = open(TMP, "w", buffering=1)
+f.write("line")
+with open(TMP) as g:
+    assert g.read() == ""
+f.write("\n")
+with open(TMP) as g:
+    assert g.read() == "line\n"
+f.close()
+f = open(TMP, "wb", buffering=0)
+f.write(b"raw")
+with open(TMP, "rb") as g:
+    assert g.read() == b"raw"
+f.close()
+
+doc = "unsupported"
+with open(TMP) as f:
+    assertRaises(io.UnsupportedOperation, f.write, "x")
+    assertRaises(io.UnsupportedOperation, f.truncate)
+with open(TMP, "w") as f:
+    assertRaises(io.UnsupportedOperation, f.read)
+    assertRaises(io.UnsupportedOperation, f.readline)
+
+doc = "closed"
+f = open(TMP)
+f.close()
+assert f.closed
+for method in (f.read, f.readline, f.readlines, f.tell, f.flush, f.fileno, f.readable, f.isatty):
+    assertRaises(ValueError, method)
+assertRaises(ValueError, f.seek, 0)
+assertRaises(ValueError, iter, f)
+
+doc = "fileno"
+with open(TMP, "wb") as f:
+    f.write(b"raw")
+with open(TMP, "rb") as f:
+    fd = f.fileno()
+    assert isinstance(fd, int)
+    g = open(fd, "rb", closefd=False)
+    assert g.read() == b"raw"
+    g.close()
+    assert not f.closed
+    f.seek(0)
+    assert f.read() == b"raw"
+    assert not f.isatty()
+
+doc = "StringIO"
+s = io.StringIO()
+assert isinstance(s, io.TextIOBase)
+assert s.write("hello\n") == 6
+s.writelines(["wörld\n", "end"])
+assert s.getvalue() == "hello\nwörld\nend"
+assert s.tell() == 15
+assert s.read() == ""
+assert s.seek(0) == 0
+assert s.read(3) == "hel"
+assert s.readline() == "lo\n"
+assert s.readlines() == ["wörld\n", "end"]
+s.seek(0)
+assert list(s) == ["hello\n", "wörld\n", "end"]
+s.seek(2)
+s.write("LL")
+assert s.getvalue() == "heLLo\nwörld\nend"
+assert s.truncate(5) == 5
+assert s.getvalue() == "heLLo"
+s.seek(7)
+s.write("!")
+assert s.getvalue() == "heLLo\x00\x00!"
+s.seek(0, io.SEEK_END)
+assert s.tell() == 8
+assertRaises(OSError, s.seek, 1, io.SEEK_CUR)
+assertRaises(ValueError, s.seek, -1)
+assertRaises(TypeError, s.write, b"bytes")
+assert s.readable() and s.writable() and s.seekable()
+s.close()
+assert s.closed
+assertRaises(ValueError, s.getvalue)
+assertRaises(ValueError, s.write, "x")
+s.close()
+
+s = io.StringIO("initial\nvalue")
+assert s.tell() == 0
+assert s.readline() == "initial\n"
+with io.StringIO("x") as s:
+    assert s.read() == "x"
+assert s.closed
+
+s = io.StringIO("a\r\nb", newline=None)
+assert s.getvalue() == "a\nb"
+s = io.StringIO(newline="\r\n")
+s.write("a\nb")
+assert s.getvalue() == "a\r\nb"
+s = io.StringIO("a\rb\r\nc", newline="")
+assert s.readlines() == ["a\r", "b\r\n", "c"]
+assertRaises(ValueError, io.StringIO, "", "x")
+
+doc = "BytesIO"
+b = io.BytesIO()
+assert isinstance(b, io.BufferedIOBase)
+assert b.write(b"hello\nworld") == 11
+assert b.getvalue() == b"hello\nworld"
+b.seek(0)
+assert b.readline() == b"hello\n"
+assert b.read(3) == b"wor"
+assert b.seek(-2, io.SEEK_CUR) == 7
+assert b.read() == b"orld"
+assert b.seek(-1, io.SEEK_END) == 10
+b.seek(13)
+b.write(b"!")
+assert b.getvalue() == b"hello\nworld\x00\x00!"
+assert b.truncate(5) == 5
+assert b.getvalue() == b"hello"
+assertRaises(TypeError, b.write, "str")
+b = io.BytesIO(b"a\nb\n")
+assert b.readlines() == [b"a\n", b"b\n"]
+b.seek(0)
+assert list(b) == [b"a\n", b"b\n"]
+with io.BytesIO(b"x") as b:
+    assert b.read() == b"x"
+assert b.closed
+assertRaises(ValueError, b.read)
+assertRaises(TypeError, io.BytesIO, "str")
+
+doc = "finished"
@@ -0,0 +1,299 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Combinatoric iterators
+//
+// These read their iterables into tuples then step an array of
+// indices into them as the pure python equivalents in the itertools
+// documentation do.
+
+package itertools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	ProductType      = py.NewTypeX("product", product_doc, ProductNew, nil)
+	PermutationsType = py.NewTypeX("permutations", permutations_doc, PermutationsNew, nil)
+	CombinationsType = py.NewTypeX("combinations", combinations_doc, CombinationsNew, nil)
+)
+
+const product_doc = `product(*iterables, repeat=1) --> product object
+
+Cartesian product of input iterables.  Equivalent to nested for-loops.
+
+For example, product(A, B) returns the same as:  ((x,y) for x in A for y in B).
+The leftmost iterators are in the outermost for-loop, so the output tuples
+cycle in a manner similar to an odometer (with the rightmost element changing
+on every iteration).
+
+To compute the product of an iterable with itself, specify the number
+of repetitions with the optional repeat keyword argument. For example,
+product(A, repeat=4) means the same as product(A, A, A, A).
+
+product('ab', range(3)) --> ('a',0) ('a',1) ('a',2) ('b',0) ('b',1) ('b',2)
+product((0,1), (0,1), (0,1)) --> (0,0,0) (0,0,1) (0,1,0) (0,1,1) (1,0,0) ...`
+
+// Product is an iterator of the cartesian product of some iterables
+type Product struct {
+	pools   []py.Tuple
+	indices []int // nil before the first item
+	stopped bool
+}
+
+// Type of this object
+func (p *Product) Type() *py.Type {
+	return ProductType
+}
+
+// ProductNew makes a product object
+func ProductNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	repeat := 1
+	for key, value := range kwargs {
+		if key != "repeat" {
+			return nil, py.ExceptionNewf(py.TypeError, "product() got an unexpected keyword argument '%s'", key)
+		}
+		var err error
+		repeat, err = py.IndexInt(value)
+		if err != nil {
+			return nil, err
+		}
+		if repeat < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "repeat argument cannot be negative")
+		}
+	}
+	pools := make([]py.Tuple, len(args))
+	for i, iterable := range args {
+		pool, err := py.SequenceTuple(iterable)
+		if err != nil {
+			return nil, err
+		}
+		pools[i] = pool
+	}
+	p := &Product{}
+	for i := 0; i < repeat; i++ {
+		p.pools = append(p.pools, pools...)
+	}
+	return p, nil
+}
+
+func (p *Product) M__iter__() (py.Object, error) {
+	return p, nil
+}
+
+func (p *Product) M__next__() (py.Object, error) {
+	if p.stopped {
+		return nil, py.StopIteration
+	}
+	if p.indices == nil {
+		p.indices = make([]int, len(p.pools))
+		for _, pool := range p.pools {
+			if len(pool) == 0 {
+				p.stopped = true
+				return nil, py.StopIteration
+			}
+		}
+	} else {
+		// Advance the rightmost index which isn't at the end of its
+		// pool, resetting those to the right of it
+		i := len(p.indices) - 1
+		for ; i >= 0; i-- {
+			p.indices[i]++
+			if p.indices[i] < len(p.pools[i]) {
+				break
+			}
+			p.indices[i] = 0
+		}
+		if i < 0 {
+			p.stopped = true
+			return nil, py.StopIteration
+		}
+	}
+	result := make(py.Tuple, len(p.indices))
+	for i, index := range p.indices {
+		result[i] = p.pools[i][index]
+	}
+	return result, nil
+}
+
+// Parses the iterable and r arguments of permutations and
+// combinations, r defaulting to the length of the pool if r is
+// optional.
+func parsePoolArgs(name string, args py.Tuple, kwargs py.StringDict, optional bool) (py.Tuple, int, error) {
+	var iterable py.Object
+	var r py.Object = py.None
+	format := "OO:" + name
+	if optional {
+		format = "O|O:" + name
+	}
+	err := py.ParseTupleAndKeywords(args, kwargs, format, []string{"iterable", "r"}, &iterable, &r)
+	if err != nil {
+		return nil, 0, err
+	}
+	pool, err := py.SequenceTuple(iterable)
+	if err != nil {
+		return nil, 0, err
+	}
+	n := len(pool)
+	if r != py.None {
+		n, err = py.IndexInt(r)
+		if err != nil {
+			return nil, 0, err
+		}
+		if n < 0 {
+			return nil, 0, py.ExceptionNewf(py.ValueError, "r must be non-negative")
+		}
+	}
+	return pool, n, nil
+}
+
+// Returns the items of pool at indices
+func poolItems(pool py.Tuple, indices []int) py.Tuple {
+	result := make(py.Tuple, len(indices))
+	for i, index := range indices {
+		result[i] = pool[index]
+	}
+	return result
+}
+
+const permutations_doc = `permutations(iterable[, r]) --> permutations object
+
+Return successive r-length permutations of elements in the iterable.
+
+permutations(range(3), 2) --> (0,1), (0,2), (1,0), (1,2), (2,0), (2,1)`
+
+// Permutations is an iterator of the r length permutations of a pool
+type Permutations struct {
+	pool    py.Tuple
+	r       int
+	indices []int
+	cycles  []int
+	started bool
+	stopped bool
+}
+
+// Type of this object
+func (p *Permutations) Type() *py.Type {
+	return PermutationsType
+}
+
+// PermutationsNew makes a permutations object
+func PermutationsNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	pool, r, err := parsePoolArgs("permutations", args, kwargs, true)
+	if err != nil {
+		return nil, err
+	}
+	n := len(pool)
+	p := &Permutations{pool: pool, r: r, stopped: r > n}
+	p.indices = make([]int, n)
+	for i := range p.indices {
+		p.indices[i] = i
+	}
+	if r <= n {
+		p.cycles = make([]int, r)
+		for i := range p.cycles {
+			p.cycles[i] = n - i
+		}
+	}
+	return p, nil
+}
+
+func (p *Permutations) M__iter__() (py.Object, error) {
+	return p, nil
+}
+
+func (p *Permutations) M__next__() (py.Object, error) {
+	if p.stopped {
+		return nil, py.StopIteration
+	}
+	if !p.started {
+		p.started = true
+		return poolItems(p.pool, p.indices[:p.r]), nil
+	}
+	n := len(p.pool)
+	for i := p.r - 1; i >= 0; i-- {
+		p.cycles[i]--
+		if p.cycles[i] == 0 {
+			// Rotate indices[i:] left by one
+			first := p.indices[i]
+			copy(p.indices[i:], p.indices[i+1:])
+			p.indices[n-1] = first
+			p.cycles[i] = n - i
+		} else {
+			j := n - p.cycles[i]
+			p.indices[i], p.indices[j] = p.indices[j], p.indices[i]
+			return poolItems(p.pool, p.indices[:p.r]), nil
+		}
+	}
+	p.stopped = true
+	return nil, py.StopIteration
+}
+
+const combinations_doc = `combinations(iterable, r) --> combinations object
+
+Return successive r-length combinations of elements in the iterable.
+
+combinations(range(4), 3) --> (0,1,2), (0,1,3), (0,2,3), (1,2,3)`
+
+// Combinations is an iterator of the r length combinations of a pool
+type Combinations struct {
+	pool    py.Tuple
+	indices []int
+	started bool
+	stopped bool
+}
+
+// Type of this object
+func (c *Combinations) Type() *py.Type {
+	return CombinationsType
+}
+
+// CombinationsNew makes a combinations object
+func CombinationsNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	pool, r, err := parsePoolArgs("combinations", args, kwargs, false)
+	if err != nil {
+		return nil, err
+	}
+	c := &Combinations{pool: pool, stopped: r > len(pool)}
+	c.indices = make([]int, r)
+	for i := range c.indices {
+		c.indices[i] = i
+	}
+	return c, nil
+}
+
+func (c *Combinations) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *Combinations) M__next__() (py.Object, error) {
+	if c.stopped {
+		return nil, py.StopIteration
+	}
+	if !c.started {
+		c.started = true
+		return poolItems(c.pool, c.indices), nil
+	}
+	// Find the rightmost index which can be increased
+	n, r := len(c.pool), len(c.indices)
+	i := r - 1
+	for i >= 0 && c.indices[i] == i+n-r {
+		i--
+	}
+	if i < 0 {
+		c.stopped = true
+		return nil, py.StopIteration
+	}
+	c.indices[i]++
+	for j := i + 1; j < r; j++ {
+		c.indices[j] = c.indices[j-1] + 1
+	}
+	return poolItems(c.pool, c.indices), nil
+}
+
+// Check interfaces
+var _ py.I_iterator = (*Product)(nil)
+var _ py.I_iterator = (*Permutations)(nil)
+var _ py.I_iterator = (*Combinations)(nil)
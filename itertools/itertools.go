@@ -0,0 +1,795 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Itertools module
+
+package itertools
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	CountType      = py.NewTypeX("count", count_doc, CountNew, nil)
+	CycleType      = py.NewTypeX("cycle", cycle_doc, CycleNew, nil)
+	RepeatType     = py.NewTypeX("repeat", repeat_doc, RepeatNew, nil)
+	ChainType      = py.NewTypeX("chain", chain_doc, ChainNew, nil)
+	IsliceType     = py.NewTypeX("islice", islice_doc, IsliceNew, nil)
+	ZipLongestType = py.NewTypeX("zip_longest", zip_longest_doc, ZipLongestNew, nil)
+	GroupbyType    = py.NewTypeX("groupby", groupby_doc, GroupbyNew, nil)
+	GrouperType    = py.NewType("_grouper", "")
+	AccumulateType = py.NewTypeX("accumulate", accumulate_doc, AccumulateNew, nil)
+	TeeType        = py.NewType("_tee", "Iterator wrapped to make it copyable")
+)
+
+const count_doc = `count(start=0, step=1) --> count object
+
+Return a count object whose .__next__() method returns consecutive values.
+Equivalent to:
+
+    def count(firstval=0, step=1):
+        x = firstval
+        while 1:
+            yield x
+            x += step`
+
+// Count is an iterator of numbers starting at start going up by step
+type Count struct {
+	next py.Object
+	step py.Object
+}
+
+// Type of this object
+func (c *Count) Type() *py.Type {
+	return CountType
+}
+
+// CountNew makes a count object
+func CountNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var start py.Object = py.Int(0)
+	var step py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:count", []string{"start", "step"}, &start, &step)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range []py.Object{start, step} {
+		if !isNumber(arg) {
+			return nil, py.ExceptionNewf(py.TypeError, "a number is required")
+		}
+	}
+	return &Count{next: start, step: step}, nil
+}
+
+// Returns whether o is a number
+func isNumber(o py.Object) bool {
+	switch o.(type) {
+	case py.I__index__, py.I__int__, py.I__float__, py.I__complex__:
+		return true
+	}
+	return false
+}
+
+func (c *Count) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *Count) M__next__() (py.Object, error) {
+	value := c.next
+	next, err := py.Add(value, c.step)
+	if err != nil {
+		return nil, err
+	}
+	c.next = next
+	return value, nil
+}
+
+func (c *Count) M__repr__() (py.Object, error) {
+	start, err := py.ReprAsString(c.next)
+	if err != nil {
+		return nil, err
+	}
+	if one, ok := c.step.(py.Int); ok && one == 1 {
+		return py.String(fmt.Sprintf("count(%s)", start)), nil
+	}
+	step, err := py.ReprAsString(c.step)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("count(%s, %s)", start, step)), nil
+}
+
+const cycle_doc = `cycle(iterable) --> cycle object
+
+Return elements from the iterable until it is exhausted.
+Then repeat the sequence indefinitely.`
+
+// Cycle is an iterator repeating the items of an iterator forever
+type Cycle struct {
+	it        py.Object
+	saved     []py.Object
+	i         int
+	exhausted bool
+}
+
+// Type of this object
+func (c *Cycle) Type() *py.Type {
+	return CycleType
+}
+
+// CycleNew makes a cycle object
+func CycleNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:cycle", []string{"iterable"}, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &Cycle{it: it}, nil
+}
+
+func (c *Cycle) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *Cycle) M__next__() (py.Object, error) {
+	if !c.exhausted {
+		value, err := py.Next(c.it)
+		if err == nil {
+			c.saved = append(c.saved, value)
+			return value, nil
+		}
+		if !py.IsException(py.StopIteration, err) {
+			return nil, err
+		}
+		c.exhausted = true
+	}
+	if len(c.saved) == 0 {
+		return nil, py.StopIteration
+	}
+	value := c.saved[c.i]
+	c.i = (c.i + 1) % len(c.saved)
+	return value, nil
+}
+
+const repeat_doc = `repeat(object [,times]) -> create an iterator which returns the object
+for the specified number of times.  If not specified, returns the object
+endlessly.`
+
+// Repeat is an iterator returning the same object times times or
+// forever if times is negative
+type Repeat struct {
+	object py.Object
+	times  int
+}
+
+// Type of this object
+func (r *Repeat) Type() *py.Type {
+	return RepeatType
+}
+
+// RepeatNew makes a repeat object
+func RepeatNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var object py.Object
+	var times py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:repeat", []string{"object", "times"}, &object, &times)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repeat{object: object, times: -1}
+	if times != py.None {
+		r.times, err = py.IndexInt(times)
+		if err != nil {
+			return nil, err
+		}
+		if r.times < 0 {
+			r.times = 0
+		}
+	}
+	return r, nil
+}
+
+func (r *Repeat) M__iter__() (py.Object, error) {
+	return r, nil
+}
+
+func (r *Repeat) M__next__() (py.Object, error) {
+	if r.times == 0 {
+		return nil, py.StopIteration
+	}
+	if r.times > 0 {
+		r.times--
+	}
+	return r.object, nil
+}
+
+func (r *Repeat) M__repr__() (py.Object, error) {
+	object, err := py.ReprAsString(r.object)
+	if err != nil {
+		return nil, err
+	}
+	if r.times < 0 {
+		return py.String(fmt.Sprintf("repeat(%s)", object)), nil
+	}
+	return py.String(fmt.Sprintf("repeat(%s, %d)", object, r.times)), nil
+}
+
+const chain_doc = `chain(*iterables) --> chain object
+
+Return a chain object whose .__next__() method returns elements from the
+first iterable until it is exhausted, then elements from the next
+iterable, until all of the iterables are exhausted.`
+
+// Chain is an iterator returning the items of each iterable in turn
+type Chain struct {
+	iterables py.Object // iterator of the iterables
+	it        py.Object // the current iterator or nil
+}
+
+// Type of this object
+func (c *Chain) Type() *py.Type {
+	return ChainType
+}
+
+// ChainNew makes a chain object
+func ChainNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(kwargs) != 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "chain() does not take keyword arguments")
+	}
+	return &Chain{iterables: py.NewIterator(args)}, nil
+}
+
+func (c *Chain) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *Chain) M__next__() (py.Object, error) {
+	for {
+		if c.it == nil {
+			iterable, err := py.Next(c.iterables)
+			if err != nil {
+				return nil, err
+			}
+			c.it, err = py.Iter(iterable)
+			if err != nil {
+				return nil, err
+			}
+		}
+		value, err := py.Next(c.it)
+		if err == nil || !py.IsException(py.StopIteration, err) {
+			return value, err
+		}
+		c.it = nil
+	}
+}
+
+const islice_doc = `islice(iterable, stop) --> islice object
+islice(iterable, start, stop[, step]) --> islice object
+
+Return an iterator whose next() method returns selected values from an
+iterable.  If start is specified, will skip all preceding elements;
+otherwise, start defaults to zero.  Step defaults to one.  If
+specified as another value, step determines how many values are
+skipped between successive calls.  Works like a slice() on a list
+but returns an iterator.`
+
+// Islice is an iterator returning a slice of an iterator
+type Islice struct {
+	it   py.Object
+	next int // index of the next item to return
+	stop int // index to stop at or -1 for no limit
+	step int
+	i    int // index of the next item of it
+}
+
+// Type of this object
+func (s *Islice) Type() *py.Type {
+	return IsliceType
+}
+
+// Returns an islice index which is None, meaning def, or a non-negative
+// integer
+func isliceIndex(o py.Object, def int, message string) (int, error) {
+	if o == py.None {
+		return def, nil
+	}
+	i, err := py.IndexInt(o)
+	if err != nil || i < 0 {
+		return 0, py.ExceptionNewf(py.ValueError, "%s", message)
+	}
+	return i, nil
+}
+
+// IsliceNew makes an islice object
+func IsliceNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(kwargs) != 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "islice() does not take keyword arguments")
+	}
+	var iterable, start, stop py.Object
+	var step py.Object = py.None
+	err := py.UnpackTuple(args, nil, "islice", 2, 4, &iterable, &start, &stop, &step)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 2 {
+		start, stop = py.None, start
+	}
+	s := &Islice{}
+	s.stop, err = isliceIndex(stop, -1, "Stop argument for islice() must be None or an integer: 0 <= x <= sys.maxsize.")
+	if err != nil {
+		return nil, err
+	}
+	const message = "Indices for islice() must be None or an integer: 0 <= x <= sys.maxsize."
+	s.next, err = isliceIndex(start, 0, message)
+	if err != nil {
+		return nil, err
+	}
+	s.step, err = isliceIndex(step, 1, "Step for islice() must be a positive integer or None.")
+	if err != nil || s.step == 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "Step for islice() must be a positive integer or None.")
+	}
+	s.it, err = py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Islice) M__iter__() (py.Object, error) {
+	return s, nil
+}
+
+func (s *Islice) M__next__() (py.Object, error) {
+	if s.it == nil {
+		return nil, py.StopIteration
+	}
+	for s.i < s.next {
+		if s.stop >= 0 && s.i >= s.stop {
+			s.it = nil
+			return nil, py.StopIteration
+		}
+		_, err := py.Next(s.it)
+		if err != nil {
+			s.it = nil
+			return nil, err
+		}
+		s.i++
+	}
+	if s.stop >= 0 && s.next >= s.stop {
+		s.it = nil
+		return nil, py.StopIteration
+	}
+	value, err := py.Next(s.it)
+	if err != nil {
+		s.it = nil
+		return nil, err
+	}
+	s.i++
+	s.next += s.step
+	return value, nil
+}
+
+const zip_longest_doc = `zip_longest(iter1 [,iter2 [...]], [fillvalue=None]) --> zip_longest object
+
+Return a zip_longest object whose .__next__() method returns a tuple where
+the i-th element comes from the i-th iterable argument.  The .__next__()
+method continues until the longest iterable in the argument sequence
+is exhausted and then it raises StopIteration.  When the shorter iterables
+are exhausted, the fillvalue is substituted in their place.  The fillvalue
+defaults to None or can be specified by a keyword argument.`
+
+// ZipLongest is an iterator returning tuples of the items of a number
+// of iterators until they are all exhausted
+type ZipLongest struct {
+	its       []py.Object // nil for those exhausted
+	active    int
+	fillvalue py.Object
+}
+
+// Type of this object
+func (z *ZipLongest) Type() *py.Type {
+	return ZipLongestType
+}
+
+// ZipLongestNew makes a zip_longest object
+func ZipLongestNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	z := &ZipLongest{fillvalue: py.None}
+	for key, value := range kwargs {
+		if key != "fillvalue" {
+			return nil, py.ExceptionNewf(py.TypeError, "zip_longest() got an unexpected keyword argument '%s'", key)
+		}
+		z.fillvalue = value
+	}
+	z.its = make([]py.Object, len(args))
+	for i, iterable := range args {
+		it, err := py.Iter(iterable)
+		if err != nil {
+			return nil, py.ExceptionNewf(py.TypeError, "zip_longest argument #%d must support iteration", i+1)
+		}
+		z.its[i] = it
+	}
+	z.active = len(z.its)
+	return z, nil
+}
+
+func (z *ZipLongest) M__iter__() (py.Object, error) {
+	return z, nil
+}
+
+func (z *ZipLongest) M__next__() (py.Object, error) {
+	if z.active == 0 {
+		return nil, py.StopIteration
+	}
+	result := make(py.Tuple, len(z.its))
+	for i, it := range z.its {
+		if it == nil {
+			result[i] = z.fillvalue
+			continue
+		}
+		value, err := py.Next(it)
+		if err != nil {
+			if !py.IsException(py.StopIteration, err) {
+				return nil, err
+			}
+			z.its[i] = nil
+			z.active--
+			if z.active == 0 {
+				return nil, err
+			}
+			value = z.fillvalue
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+const groupby_doc = `groupby(iterable[, keyfunc]) -> create an iterator which returns
+(key, sub-iterator) grouped by each value of key(value).`
+
+// Groupby is an iterator returning the runs of items of an iterator
+// with the same key
+type Groupby struct {
+	it        py.Object
+	keyfunc   py.Object
+	tgtkey    py.Object // key of the current group or nil
+	currkey   py.Object // key of the current item or nil
+	currvalue py.Object // current item or nil if it has been used
+	grouper   *Grouper  // the current group
+}
+
+// Type of this object
+func (g *Groupby) Type() *py.Type {
+	return GroupbyType
+}
+
+// Grouper is an iterator of the items of a group of a groupby
+type Grouper struct {
+	parent *Groupby
+	tgtkey py.Object
+}
+
+// Type of this object
+func (g *Grouper) Type() *py.Type {
+	return GrouperType
+}
+
+// GroupbyNew makes a groupby object
+func GroupbyNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	var key py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:groupby", []string{"iterable", "key"}, &iterable, &key)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &Groupby{it: it, keyfunc: key}, nil
+}
+
+// Reads the next item and its key
+func (g *Groupby) step() error {
+	value, err := py.Next(g.it)
+	if err != nil {
+		return err
+	}
+	key := value
+	if g.keyfunc != py.None {
+		key, err = py.Call(g.keyfunc, py.Tuple{value}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	g.currvalue = value
+	g.currkey = key
+	return nil
+}
+
+// Returns whether the current key is the same as key
+func (g *Groupby) sameKey(key py.Object) (bool, error) {
+	eq, err := py.Eq(key, g.currkey)
+	if err != nil {
+		return false, err
+	}
+	return py.ObjectIsTrue(eq), nil
+}
+
+func (g *Groupby) M__iter__() (py.Object, error) {
+	return g, nil
+}
+
+func (g *Groupby) M__next__() (py.Object, error) {
+	g.grouper = nil
+	// Skip to the next key
+	for {
+		if g.currkey != nil {
+			if g.tgtkey == nil {
+				break
+			}
+			same, err := g.sameKey(g.tgtkey)
+			if err != nil {
+				return nil, err
+			}
+			if !same {
+				break
+			}
+		}
+		if err := g.step(); err != nil {
+			return nil, err
+		}
+	}
+	g.tgtkey = g.currkey
+	g.grouper = &Grouper{parent: g, tgtkey: g.tgtkey}
+	return py.Tuple{g.currkey, g.grouper}, nil
+}
+
+func (g *Grouper) M__iter__() (py.Object, error) {
+	return g, nil
+}
+
+func (g *Grouper) M__next__() (py.Object, error) {
+	parent := g.parent
+	if parent.grouper != g {
+		return nil, py.StopIteration
+	}
+	if parent.currvalue == nil {
+		if err := parent.step(); err != nil {
+			return nil, err
+		}
+	}
+	same, err := parent.sameKey(g.tgtkey)
+	if err != nil {
+		return nil, err
+	}
+	if !same {
+		return nil, py.StopIteration
+	}
+	value := parent.currvalue
+	parent.currvalue = nil
+	return value, nil
+}
+
+const accumulate_doc = `accumulate(iterable[, func, *, initial=None]) --> accumulate object
+
+Return series of accumulated sums (or other binary function results).`
+
+// Accumulate is an iterator returning the running totals of an
+// iterator
+type Accumulate struct {
+	it      py.Object
+	fn      py.Object
+	total   py.Object // nil before the first item
+	initial py.Object // returned first if not None
+}
+
+// Type of this object
+func (a *Accumulate) Type() *py.Type {
+	return AccumulateType
+}
+
+// AccumulateNew makes an accumulate object
+func AccumulateNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	var fn py.Object = py.None
+	var initial py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:accumulate", []string{"iterable", "func", "initial"}, &iterable, &fn, &initial)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &Accumulate{it: it, fn: fn, initial: initial}, nil
+}
+
+func (a *Accumulate) M__iter__() (py.Object, error) {
+	return a, nil
+}
+
+func (a *Accumulate) M__next__() (py.Object, error) {
+	if a.initial != py.None {
+		a.total, a.initial = a.initial, py.None
+		return a.total, nil
+	}
+	value, err := py.Next(a.it)
+	if err != nil {
+		return nil, err
+	}
+	if a.total == nil {
+		a.total = value
+		return value, nil
+	}
+	if a.fn == py.None {
+		value, err = py.Add(a.total, value)
+	} else {
+		value, err = py.Call(a.fn, py.Tuple{a.total, value}, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	a.total = value
+	return value, nil
+}
+
+// A link in the list of the items read by a group of tee iterators
+type teeLink struct {
+	value py.Object
+	next  *teeLink
+}
+
+// The iterator shared by a group of tee iterators
+type teeSource struct {
+	it py.Object
+}
+
+// Tee is one of the independent iterators returned by tee
+type Tee struct {
+	source *teeSource
+	link   *teeLink // the link before the next item
+}
+
+// Type of this object
+func (t *Tee) Type() *py.Type {
+	return TeeType
+}
+
+// Returns a new iterator from the same place as t
+func (t *Tee) copy() *Tee {
+	return &Tee{source: t.source, link: t.link}
+}
+
+func (t *Tee) M__iter__() (py.Object, error) {
+	return t, nil
+}
+
+func (t *Tee) M__next__() (py.Object, error) {
+	if t.link.next == nil {
+		value, err := py.Next(t.source.it)
+		if err != nil {
+			return nil, err
+		}
+		t.link.next = &teeLink{value: value}
+	}
+	t.link = t.link.next
+	return t.link.value, nil
+}
+
+const tee_doc = `tee(iterable, n=2) --> tuple of n independent iterators.`
+
+func itertools_tee(self py.Object, args py.Tuple) (py.Object, error) {
+	var iterable py.Object
+	var n py.Object = py.Int(2)
+	err := py.UnpackTuple(args, nil, "tee", 1, 2, &iterable, &n)
+	if err != nil {
+		return nil, err
+	}
+	count, err := py.IndexInt(n)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "n must be >= 0")
+	}
+	result := make(py.Tuple, count)
+	if count == 0 {
+		return result, nil
+	}
+	// Like CPython a tee passed in is used as the first iterator
+	first, ok := iterable.(*Tee)
+	if !ok {
+		it, err := py.Iter(iterable)
+		if err != nil {
+			return nil, err
+		}
+		first = &Tee{source: &teeSource{it: it}, link: &teeLink{}}
+	}
+	result[0] = first
+	for i := 1; i < count; i++ {
+		result[i] = first.copy()
+	}
+	return result, nil
+}
+
+func init() {
+	ChainType.Dict["from_iterable"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("from_iterable", func(self py.Object, iterable py.Object) (py.Object, error) {
+			iterables, err := py.Iter(iterable)
+			if err != nil {
+				return nil, err
+			}
+			return &Chain{iterables: iterables}, nil
+		}, 0, "chain.from_iterable(iterable) --> chain object\n\nAlternate chain() constructor taking a single iterable argument\nthat evaluates lazily."),
+	}
+	TeeType.Dict["__copy__"] = py.MustNewMethod("__copy__", func(self py.Object) (py.Object, error) {
+		return self.(*Tee).copy(), nil
+	}, 0, "Returns an independent iterator.")
+}
+
+const itertools_doc = `Functional tools for creating and using iterators.
+
+Infinite iterators:
+count(start=0, step=1) --> start, start+step, start+2*step, ...
+cycle(p) --> p0, p1, ... plast, p0, p1, ...
+repeat(elem [,n]) --> elem, elem, elem, ... endlessly or up to n times
+
+Iterators terminating on the shortest input sequence:
+accumulate(p[, func]) --> p0, p0+p1, p0+p1+p2
+chain(p, q, ...) --> p0, p1, ... plast, q0, q1, ...
+chain.from_iterable([p, q, ...]) --> p0, p1, ... plast, q0, q1, ...
+groupby(iterable[, keyfunc]) --> sub-iterators grouped by value of keyfunc(v)
+islice(seq, [start,] stop [, step]) --> elements from
+       seq[start:stop:step]
+tee(it, n=2) --> (it1, it2 , ... itn) splits one iterator into n
+zip_longest(p, q, ...) --> (p[0], q[0]), (p[1], q[1]), ...
+
+Combinatoric generators:
+product(p, q, ... [repeat=1]) --> cartesian product
+permutations(p[, r])
+combinations(p, r)`
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("tee", itertools_tee, 0, tee_doc),
+	}
+	globals := py.StringDict{
+		"accumulate":   AccumulateType,
+		"chain":        ChainType,
+		"combinations": CombinationsType,
+		"count":        CountType,
+		"cycle":        CycleType,
+		"groupby":      GroupbyType,
+		"islice":       IsliceType,
+		"permutations": PermutationsType,
+		"product":      ProductType,
+		"repeat":       RepeatType,
+		"zip_longest":  ZipLongestType,
+		"_grouper":     GrouperType,
+		"_tee":         TeeType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "itertools",
+		Doc:     itertools_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
+
+// Check interfaces
+var _ py.I_iterator = (*Count)(nil)
+var _ py.I_iterator = (*Cycle)(nil)
+var _ py.I_iterator = (*Repeat)(nil)
+var _ py.I_iterator = (*Chain)(nil)
+var _ py.I_iterator = (*Islice)(nil)
+var _ py.I_iterator = (*ZipLongest)(nil)
+var _ py.I_iterator = (*Groupby)(nil)
+var _ py.I_iterator = (*Grouper)(nil)
+var _ py.I_iterator = (*Accumulate)(nil)
+var _ py.I_iterator = (*Tee)(nil)
@@ -0,0 +1,153 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from itertools import *
+from libtest import *
+
+doc = "count"
+c = count()
+assert [next(c) for i in range(3)] == [0, 1, 2]
+assert repr(c) == "count(3)"
+c = count(10, 5)
+assert [next(c) for i in range(3)] == [10, 15, 20]
+assert repr(c) == "count(25, 5)"
+c = count(start=0.5, step=-1)
+assert [next(c) for i in range(3)] == [0.5, -0.5, -1.5]
+assert list(zip(["a", "b", "c"], count(1))) == [("a", 1), ("b", 2), ("c", 3)]
+assertRaises(TypeError, count, "a")
+assert iter(c) is c
+
+doc = "cycle"
+c = cycle(["a", "b"])
+assert [next(c) for i in range(5)] == ["a", "b", "a", "b", "a"]
+assert list(cycle([])) == []
+assertRaises(TypeError, cycle, 1)
+
+doc = "repeat"
+assert list(repeat("x", 3)) == ["x", "x", "x"]
+assert list(repeat("x", -1)) == []
+assert list(repeat(None, times=2)) == [None, None]
+r = repeat(1)
+assert [next(r) for i in range(3)] == [1, 1, 1]
+assert repr(r) == "repeat(1)"
+assert repr(repeat("a", 2)) == "repeat('a', 2)"
+assert [pow(a, b) for a, b in zip(range(4), repeat(2))] == [0, 1, 4, 9]
+
+doc = "chain"
+assert list(chain(["a", "b"], [1, 2], (), range(2))) == ["a", "b", 1, 2, 0, 1]
+assert list(chain()) == []
+assert list(chain.from_iterable([["a", "b"], ["c", "d"]])) == ["a", "b", "c", "d"]
+assertRaises(TypeError, list, chain(1))
+# chain keeps its arguments so they mustn't be overwritten by later calls
+c = chain([1], [2])
+(lambda a, b, c, d: 0)(7, 8, 9, 10)
+assert list(c) == [1, 2]
+
+def gen():
+    yield ["a", "b", "c"]
+    yield ["d"]
+assert list(chain.from_iterable(gen())) == ["a", "b", "c", "d"]
+
+doc = "islice"
+assert list(islice(["a", "b", "c", "d", "e", "f", "g"], 2)) == ["a", "b"]
+assert list(islice(["a", "b", "c", "d", "e", "f", "g"], 2, 4)) == ["c", "d"]
+assert list(islice(["a", "b", "c", "d", "e", "f", "g"], 2, None)) == ["c", "d", "e", "f", "g"]
+assert list(islice(["a", "b", "c", "d", "e", "f", "g"], 0, None, 2)) == ["a", "c", "e", "g"]
+assert list(islice(["a", "b", "c", "d", "e", "f", "g"], 1, 6, 3)) == ["b", "e"]
+assert list(islice(["a", "b", "c"], None)) == ["a", "b", "c"]
+assert list(islice(["a", "b", "c"], 5, 10)) == []
+assert list(islice(count(), 3, 12, 4)) == [3, 7, 11]
+it = iter(range(10))
+assert list(islice(it, 3)) == [0, 1, 2]
+assert next(it) == 3
+assertRaises(ValueError, islice, ["a", "b", "c"], -1)
+assertRaises(ValueError, islice, ["a", "b", "c"], 0, -1)
+assertRaises(ValueError, islice, ["a", "b", "c"], 0, 1, 0)
+assertRaises(ValueError, islice, ["a", "b", "c"], ["x"])
+assertRaises(TypeError, islice, ["a", "b", "c"])
+
+doc = "zip_longest"
+assert list(zip_longest(["a", "b", "c"], ["x"])) == [("a", "x"), ("b", None), ("c", None)]
+assert list(zip_longest(["a", "b"], [1, 2, 3], fillvalue="-")) == [("a", 1), ("b", 2), ("-", 3)]
+assert list(zip_longest()) == []
+assert list(zip_longest([], [])) == []
+assertRaises(TypeError, zip_longest, 1)
+assertRaises(TypeError, zip_longest, "a", foo=1)
+
+doc = "groupby"
+groups = [(k, list(g)) for k, g in groupby(["A", "A", "A", "A", "B", "B", "B", "C", "C", "D", "A", "A", "B", "B", "B"])]
+assert groups == [("A", ["A"] * 4), ("B", ["B"] * 3), ("C", ["C"] * 2), ("D", ["D"]), ("A", ["A"] * 2), ("B", ["B"] * 3)]
+assert [k for k, g in groupby(["A", "A", "A", "A", "B", "B", "B", "C", "C"])] == ["A", "B", "C"]
+groups = [(k, list(g)) for k, g in groupby([1, 3, 2, 4, 5], key=lambda x: x % 2)]
+assert groups == [(1, [1, 3]), (0, [2, 4]), (1, [5])]
+g = groupby(["a", "a", "b", "b"])
+k1, g1 = next(g)
+k2, g2 = next(g)
+assert list(g1) == []
+assert list(g2) == ["b", "b"]
+assert list(groupby([])) == []
+g = groupby(["a", "a", "b"])
+k, group = next(g)
+assert next(group) == "a"
+k, group = next(g)
+assert (k, list(group)) == ("b", ["b"])
+
+doc = "accumulate"
+assert list(accumulate([1, 2, 3, 4])) == [1, 3, 6, 10]
+assert list(accumulate([3, 1, 4, 1, 5], max)) == [3, 3, 4, 4, 5]
+assert list(accumulate([1, 2, 3], lambda a, b: a * b)) == [1, 2, 6]
+assert list(accumulate(["a", "b", "c"])) == ["a", "ab", "abc"]
+assert list(accumulate([])) == []
+assert list(accumulate([1, 2], initial=10)) == [10, 11, 13]
+assert list(accumulate([], initial=10)) == [10]
+
+doc = "tee"
+a, b = tee(range(4))
+assert next(a) == 0
+assert list(b) == [0, 1, 2, 3]
+assert list(a) == [1, 2, 3]
+assert tee(["a", "b", "c"], 0) == ()
+ts = tee(iter(["x", "y", "z"]), 3)
+assert len(ts) == 3
+assert [list(t) for t in ts] == [["x", "y", "z"]] * 3
+a, b = tee(["a", "b", "c"])
+next(a)
+c, d = tee(a)
+assert c is a
+assert list(d) == ["b", "c"]
+assert list(c) == ["b", "c"]
+assert list(a) == []
+assert list(b) == ["a", "b", "c"]
+assert list(b.__copy__()) == []
+assertRaises(ValueError, tee, ["a", "b", "c"], -1)
+
+doc = "product"
+assert list(product(["a", "b"], range(2))) == [("a", 0), ("a", 1), ("b", 0), ("b", 1)]
+assert list(product([0, 1], repeat=2)) == [(0, 0), (0, 1), (1, 0), (1, 1)]
+assert list(product()) == [()]
+assert list(product(["a", "b"], [])) == []
+assert list(product(["a", "b"], repeat=0)) == [()]
+assert len(list(product(["a", "b", "c"], ["d", "e"], ["f", "g", "h"]))) == 18
+assertRaises(ValueError, product, ["a", "b"], repeat=-1)
+assertRaises(TypeError, product, ["a", "b"], foo=1)
+
+doc = "permutations"
+assert list(permutations(range(3))) == [(0, 1, 2), (0, 2, 1), (1, 0, 2), (1, 2, 0), (2, 0, 1), (2, 1, 0)]
+assert list(permutations(["a", "b", "c"], 2)) == [("a", "b"), ("a", "c"), ("b", "a"), ("b", "c"), ("c", "a"), ("c", "b")]
+assert list(permutations(["a", "b", "c"], 0)) == [()]
+assert list(permutations(["a", "b", "c"], 4)) == []
+assert list(permutations([])) == [()]
+assert len(list(permutations(range(5)))) == 120
+assertRaises(ValueError, permutations, ["a", "b", "c"], -1)
+
+doc = "combinations"
+assert list(combinations(["A", "B", "C", "D"], 2)) == [("A", "B"), ("A", "C"), ("A", "D"), ("B", "C"), ("B", "D"), ("C", "D")]
+assert list(combinations(range(4), 3)) == [(0, 1, 2), (0, 1, 3), (0, 2, 3), (1, 2, 3)]
+assert list(combinations(["a", "b", "c"], 0)) == [()]
+assert list(combinations(["a", "b", "c"], 4)) == []
+assert list(combinations(range(4), r=4)) == [(0, 1, 2, 3)]
+assertRaises(TypeError, combinations, ["a", "b", "c"])
+assertRaises(ValueError, combinations, ["a", "b", "c"], -1)
+
+doc = "finished"
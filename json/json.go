@@ -0,0 +1,950 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// JSON module
+
+package json
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// JSONDecodeError is raised when a document can't be decoded
+var JSONDecodeError = py.ValueError.NewType("JSONDecodeError", `Subclass of ValueError with the following additional properties:
+
+msg: The unformatted error message
+doc: The JSON document being parsed
+pos: The start index of doc where parsing failed
+lineno: The line corresponding to pos
+colno: The column corresponding to pos`, nil, nil)
+
+// Returns the python name of the type of o
+func typeName(o py.Object) string {
+	return o.Type().Name
+}
+
+// ------------------------------------------------------------
+// Encoding
+
+// An encoder turns python objects into JSON text
+type encoder struct {
+	skipKeys      bool
+	sortKeys      bool
+	ensureASCII   bool
+	checkCircular bool
+	allowNaN      bool
+	indent        string
+	hasIndent     bool
+	itemSep       string
+	keySep        string
+	defaultFn     py.Object
+	markers       map[uintptr]struct{}
+	out           strings.Builder
+}
+
+// Keyword arguments accepted by dumps and dump
+var encoderKwlist = []string{"skipkeys", "ensure_ascii", "check_circular", "allow_nan", "indent", "separators", "default", "sort_keys"}
+
+// Makes a new encoder from the keyword arguments of the function called name
+func newEncoder(name string, kwargs py.StringDict) (*encoder, error) {
+	var skipKeys py.Object = py.False
+	var ensureASCII py.Object = py.True
+	var checkCircular py.Object = py.True
+	var allowNaN py.Object = py.True
+	var indent py.Object = py.None
+	var separators py.Object = py.None
+	var defaultFn py.Object = py.None
+	var sortKeys py.Object = py.False
+	err := py.ParseTupleAndKeywords(nil, kwargs, "|OOOOOOOO:"+name, encoderKwlist, &skipKeys, &ensureASCII, &checkCircular, &allowNaN, &indent, &separators, &defaultFn, &sortKeys)
+	if err != nil {
+		return nil, err
+	}
+	enc := &encoder{
+		skipKeys:      py.ObjectIsTrue(skipKeys),
+		sortKeys:      py.ObjectIsTrue(sortKeys),
+		ensureASCII:   py.ObjectIsTrue(ensureASCII),
+		checkCircular: py.ObjectIsTrue(checkCircular),
+		allowNaN:      py.ObjectIsTrue(allowNaN),
+		itemSep:       ", ",
+		keySep:        ": ",
+		markers:       make(map[uintptr]struct{}),
+	}
+	switch x := indent.(type) {
+	case py.NoneType:
+	case py.Int:
+		enc.hasIndent = true
+		if x > 0 {
+			enc.indent = strings.Repeat(" ", int(x))
+		}
+	case py.String:
+		enc.hasIndent = true
+		enc.indent = string(x)
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "indent must be None, an int or a str, not %s", typeName(indent))
+	}
+	if enc.hasIndent {
+		enc.itemSep = ","
+	}
+	if separators != py.None {
+		seps, err := py.SequenceTuple(separators)
+		if err != nil {
+			return nil, err
+		}
+		if len(seps) != 2 {
+			return nil, py.ExceptionNewf(py.ValueError, "separators must be an (item_separator, key_separator) tuple")
+		}
+		itemSep, ok1 := seps[0].(py.String)
+		keySep, ok2 := seps[1].(py.String)
+		if !ok1 || !ok2 {
+			return nil, py.ExceptionNewf(py.TypeError, "separators must be strings")
+		}
+		enc.itemSep, enc.keySep = string(itemSep), string(keySep)
+	}
+	if defaultFn != py.None {
+		enc.defaultFn = defaultFn
+	}
+	return enc, nil
+}
+
+// Writes a newline and the indent for level if indenting
+func (enc *encoder) newline(level int) {
+	if enc.hasIndent {
+		enc.out.WriteByte('\n')
+		for i := 0; i < level; i++ {
+			enc.out.WriteString(enc.indent)
+		}
+	}
+}
+
+// Writes s as a quoted JSON string
+func (enc *encoder) encodeString(s string) {
+	enc.out.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '"':
+			enc.out.WriteString(`\"`)
+		case '\\':
+			enc.out.WriteString(`\\`)
+		case '\n':
+			enc.out.WriteString(`\n`)
+		case '\r':
+			enc.out.WriteString(`\r`)
+		case '\t':
+			enc.out.WriteString(`\t`)
+		case '\b':
+			enc.out.WriteString(`\b`)
+		case '\f':
+			enc.out.WriteString(`\f`)
+		default:
+			switch {
+			case c < 0x20 || (enc.ensureASCII && c > 0x7f):
+				if c > 0xffff {
+					r1, r2 := utf16.EncodeRune(c)
+					fmt.Fprintf(&enc.out, `\u%04x\u%04x`, r1, r2)
+				} else {
+					fmt.Fprintf(&enc.out, `\u%04x`, c)
+				}
+			default:
+				enc.out.WriteRune(c)
+			}
+		}
+	}
+	enc.out.WriteByte('"')
+}
+
+// Writes f as a JSON number
+func (enc *encoder) encodeFloat(f py.Float) error {
+	str, err := enc.floatString(f)
+	if err != nil {
+		return err
+	}
+	enc.out.WriteString(str)
+	return nil
+}
+
+// Returns f as a JSON number
+func (enc *encoder) floatString(f py.Float) (string, error) {
+	x := float64(f)
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		if !enc.allowNaN {
+			repr, err := py.ReprAsString(f)
+			if err != nil {
+				return "", err
+			}
+			return "", py.ExceptionNewf(py.ValueError, "Out of range float values are not JSON compliant: %s", repr)
+		}
+		switch {
+		case math.IsNaN(x):
+			return "NaN", nil
+		case x > 0:
+			return "Infinity", nil
+		default:
+			return "-Infinity", nil
+		}
+	}
+	return py.ReprAsString(f)
+}
+
+// Marks a container as being encoded to detect circular references
+//
+// Returns a function to remove the mark
+func (enc *encoder) mark(o py.Object) (func(), error) {
+	if !enc.checkCircular {
+		return func() {}, nil
+	}
+	id := reflect.ValueOf(o).Pointer()
+	if _, found := enc.markers[id]; found {
+		return nil, py.ExceptionNewf(py.ValueError, "Circular reference detected")
+	}
+	enc.markers[id] = struct{}{}
+	return func() { delete(enc.markers, id) }, nil
+}
+
+// Writes the items of a list or tuple as a JSON array
+func (enc *encoder) encodeArray(items []py.Object, level int) error {
+	if len(items) == 0 {
+		enc.out.WriteString("[]")
+		return nil
+	}
+	enc.out.WriteByte('[')
+	for i, item := range items {
+		if i != 0 {
+			enc.out.WriteString(enc.itemSep)
+		}
+		enc.newline(level + 1)
+		err := enc.encode(item, level+1)
+		if err != nil {
+			return err
+		}
+	}
+	enc.newline(level)
+	enc.out.WriteByte(']')
+	return nil
+}
+
+// Converts a dictionary key into the string used for it in a JSON
+// object returning false if it should be skipped
+func (enc *encoder) encodeKey(key py.Object) (string, bool, error) {
+	switch x := key.(type) {
+	case py.String:
+		return string(x), true, nil
+	case py.Bool:
+		if x {
+			return "true", true, nil
+		}
+		return "false", true, nil
+	case py.NoneType:
+		return "null", true, nil
+	case py.Int, *py.BigInt:
+		str, err := py.ReprAsString(x)
+		return str, true, err
+	case py.Float:
+		str, err := enc.floatString(x)
+		return str, true, err
+	}
+	if enc.skipKeys {
+		return "", false, nil
+	}
+	return "", false, py.ExceptionNewf(py.TypeError, "keys must be str, int, float, bool or None, not %s", typeName(key))
+}
+
+// Writes the (key, value) pairs of a dictionary as a JSON object
+//
+// The pairs are written in the order of the dictionary unless
+// sort_keys was set.
+func (enc *encoder) encodeObject(items []py.Tuple, level int) error {
+	if len(items) == 0 {
+		enc.out.WriteString("{}")
+		return nil
+	}
+	if enc.sortKeys {
+		var sortErr error
+		sort.SliceStable(items, func(i, j int) bool {
+			lt, err := py.Lt(items[i][0], items[j][0])
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			return lt == py.True
+		})
+		if sortErr != nil {
+			return sortErr
+		}
+	}
+	enc.out.WriteByte('{')
+	first := true
+	for _, item := range items {
+		key, ok, err := enc.encodeKey(item[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if !first {
+			enc.out.WriteString(enc.itemSep)
+		}
+		first = false
+		enc.newline(level + 1)
+		enc.encodeString(key)
+		enc.out.WriteString(enc.keySep)
+		err = enc.encode(item[1], level+1)
+		if err != nil {
+			return err
+		}
+	}
+	enc.newline(level)
+	enc.out.WriteByte('}')
+	return nil
+}
+
+// Writes o as JSON
+func (enc *encoder) encode(o py.Object, level int) error {
+	switch x := o.(type) {
+	case py.String:
+		enc.encodeString(string(x))
+	case py.NoneType:
+		enc.out.WriteString("null")
+	case py.Bool:
+		if x {
+			enc.out.WriteString("true")
+		} else {
+			enc.out.WriteString("false")
+		}
+	case py.Int, *py.BigInt:
+		repr, err := py.ReprAsString(x)
+		if err != nil {
+			return err
+		}
+		enc.out.WriteString(repr)
+	case py.Float:
+		return enc.encodeFloat(x)
+	case *py.List:
+		unmark, err := enc.mark(x)
+		if err != nil {
+			return err
+		}
+		defer unmark()
+		return enc.encodeArray(x.Items, level)
+	case py.Tuple:
+		return enc.encodeArray(x, level)
+	case py.StringDict:
+		unmark, err := enc.mark(x)
+		if err != nil {
+			return err
+		}
+		defer unmark()
+		return enc.encodeObject(x.Items(), level)
+	case *py.Dict:
+		unmark, err := enc.mark(x)
+		if err != nil {
+			return err
+		}
+		defer unmark()
+		return enc.encodeObject(x.Items(), level)
+	default:
+		if enc.defaultFn == nil {
+			return py.ExceptionNewf(py.TypeError, "Object of type %s is not JSON serializable", typeName(o))
+		}
+		res, err := py.Call(enc.defaultFn, py.Tuple{o}, nil)
+		if err != nil {
+			return err
+		}
+		return enc.encode(res, level)
+	}
+	return nil
+}
+
+const json_dumps_doc = `dumps(obj, *, skipkeys=False, ensure_ascii=True, check_circular=True,
+      allow_nan=True, indent=None, separators=None, default=None,
+      sort_keys=False) -> str
+
+Serialize obj to a JSON formatted str.
+
+If ensure_ascii is false then non-ASCII characters are written as is
+rather than escaped.
+
+If check_circular is false then the check for circular references in
+lists and dicts is skipped.
+
+If allow_nan is false then serializing out of range floats raises a
+ValueError rather than writing NaN, Infinity or -Infinity.
+
+If indent is a non-negative integer or a string then arrays and
+objects are pretty printed with that indent level. An indent of 0 or
+"" only inserts newlines. None is the most compact representation.
+
+If specified, separators should be an (item_separator, key_separator)
+tuple. The default is (', ', ': ') if indent is None and (',', ': ')
+otherwise.
+
+If specified, default should be a function that gets called for
+objects that can't otherwise be serialized. It should return a JSON
+encodable version of the object or raise a TypeError.
+
+Dictionary keys which are int, float, bool or None are converted to
+strings. If skipkeys is true then keys of other types are skipped
+rather than raising a TypeError.
+
+If sort_keys is true then objects are written with their keys in
+sorted order, otherwise in the order of the dictionary.`
+
+func json_dumps(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	err := py.UnpackTuple(args, nil, "dumps", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	return dumps(obj, "dumps", kwargs)
+}
+
+// Serializes obj with the keyword arguments of the function called name
+func dumps(obj py.Object, name string, kwargs py.StringDict) (py.Object, error) {
+	enc, err := newEncoder(name, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = enc.encode(obj, 0)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(enc.out.String()), nil
+}
+
+const json_dump_doc = `dump(obj, fp, *, skipkeys=False, ensure_ascii=True, check_circular=True,
+     allow_nan=True, indent=None, separators=None, default=None,
+     sort_keys=False)
+
+Serialize obj as a JSON formatted stream to fp, a file-like object
+with a write() method. The keyword arguments are as for dumps.`
+
+func json_dump(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj, fp py.Object
+	err := py.UnpackTuple(args, nil, "dump", 2, 2, &obj, &fp)
+	if err != nil {
+		return nil, err
+	}
+	s, err := dumps(obj, "dump", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	write, err := py.GetAttrString(fp, "write")
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.Call(write, py.Tuple{s}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+// ------------------------------------------------------------
+// Decoding
+
+// A decoder turns JSON text into python objects
+type decoder struct {
+	doc             string
+	s               []rune
+	pos             int
+	strict          bool
+	objectHook      py.Object
+	objectPairsHook py.Object
+	parseFloat      py.Object
+	parseInt        py.Object
+	parseConstant   py.Object
+}
+
+// Keyword arguments accepted by loads and load
+var decoderKwlist = []string{"object_hook", "parse_float", "parse_int", "parse_constant", "object_pairs_hook", "strict"}
+
+// Makes a new decoder from the keyword arguments of the function called name
+func newDecoder(name string, kwargs py.StringDict) (*decoder, error) {
+	var objectHook py.Object = py.None
+	var parseFloat py.Object = py.None
+	var parseInt py.Object = py.None
+	var parseConstant py.Object = py.None
+	var objectPairsHook py.Object = py.None
+	var strict py.Object = py.True
+	err := py.ParseTupleAndKeywords(nil, kwargs, "|OOOOOO:"+name, decoderKwlist, &objectHook, &parseFloat, &parseInt, &parseConstant, &objectPairsHook, &strict)
+	if err != nil {
+		return nil, err
+	}
+	dec := &decoder{
+		strict: py.ObjectIsTrue(strict),
+	}
+	for _, hook := range []struct {
+		in  py.Object
+		out *py.Object
+	}{
+		{objectHook, &dec.objectHook},
+		{parseFloat, &dec.parseFloat},
+		{parseInt, &dec.parseInt},
+		{parseConstant, &dec.parseConstant},
+		{objectPairsHook, &dec.objectPairsHook},
+	} {
+		if hook.in != py.None {
+			*hook.out = hook.in
+		}
+	}
+	return dec, nil
+}
+
+// Makes a JSONDecodeError for msg at pos
+func (dec *decoder) error(msg string, pos int) error {
+	lineno := 1
+	colno := pos + 1
+	for i := 0; i < pos && i < len(dec.s); i++ {
+		if dec.s[i] == '\n' {
+			lineno++
+			colno = pos - i
+		}
+	}
+	return &py.Exception{
+		Base: JSONDecodeError,
+		Args: py.Tuple{py.String(fmt.Sprintf("%s: line %d column %d (char %d)", msg, lineno, colno, pos))},
+		Dict: py.StringDict{
+			"msg":    py.String(msg),
+			"doc":    py.String(dec.doc),
+			"pos":    py.Int(pos),
+			"lineno": py.Int(lineno),
+			"colno":  py.Int(colno),
+		},
+	}
+}
+
+// Skips any whitespace
+func (dec *decoder) skipSpace() {
+	for dec.pos < len(dec.s) {
+		switch dec.s[dec.pos] {
+		case ' ', '\t', '\n', '\r':
+			dec.pos++
+		default:
+			return
+		}
+	}
+}
+
+// Returns true and skips over word if it is next in the input
+func (dec *decoder) consume(word string) bool {
+	i := dec.pos
+	for _, c := range word {
+		if i >= len(dec.s) || dec.s[i] != c {
+			return false
+		}
+		i++
+	}
+	dec.pos = i
+	return true
+}
+
+// Decodes the document as a single value surrounded by whitespace
+func (dec *decoder) decode(doc string) (py.Object, error) {
+	dec.doc = doc
+	dec.s = []rune(doc)
+	if len(dec.s) > 0 && dec.s[0] == '\ufeff' {
+		return nil, dec.error("Unexpected UTF-8 BOM (decode using utf-8-sig)", 0)
+	}
+	dec.skipSpace()
+	value, err := dec.value()
+	if err != nil {
+		return nil, err
+	}
+	dec.skipSpace()
+	if dec.pos != len(dec.s) {
+		return nil, dec.error("Extra data", dec.pos)
+	}
+	return value, nil
+}
+
+// Decodes the value at the current position
+func (dec *decoder) value() (py.Object, error) {
+	if dec.pos >= len(dec.s) {
+		return nil, dec.error("Expecting value", dec.pos)
+	}
+	switch c := dec.s[dec.pos]; {
+	case c == '"':
+		s, err := dec.string()
+		if err != nil {
+			return nil, err
+		}
+		return py.String(s), nil
+	case c == '{':
+		return dec.object()
+	case c == '[':
+		return dec.array()
+	case c == '-' || (c >= '0' && c <= '9'):
+		if dec.consume("-Infinity") {
+			return dec.constant("-Infinity")
+		}
+		return dec.number()
+	case dec.consume("null"):
+		return py.None, nil
+	case dec.consume("true"):
+		return py.True, nil
+	case dec.consume("false"):
+		return py.False, nil
+	case dec.consume("NaN"):
+		return dec.constant("NaN")
+	case dec.consume("Infinity"):
+		return dec.constant("Infinity")
+	}
+	return nil, dec.error("Expecting value", dec.pos)
+}
+
+// Decodes one of the constants NaN, Infinity or -Infinity
+func (dec *decoder) constant(name string) (py.Object, error) {
+	if dec.parseConstant != nil {
+		return py.Call(dec.parseConstant, py.Tuple{py.String(name)}, nil)
+	}
+	switch name {
+	case "NaN":
+		return py.Float(math.NaN()), nil
+	case "Infinity":
+		return py.Float(math.Inf(1)), nil
+	}
+	return py.Float(math.Inf(-1)), nil
+}
+
+// Returns true if c is a decimal digit
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Skips over a run of digits returning true if there were any
+func (dec *decoder) digits() bool {
+	start := dec.pos
+	for dec.pos < len(dec.s) && isDigit(dec.s[dec.pos]) {
+		dec.pos++
+	}
+	return dec.pos > start
+}
+
+// Decodes the number at the current position
+func (dec *decoder) number() (py.Object, error) {
+	start := dec.pos
+	if dec.s[dec.pos] == '-' {
+		dec.pos++
+	}
+	if dec.pos < len(dec.s) && dec.s[dec.pos] == '0' {
+		dec.pos++
+	} else if !dec.digits() {
+		return nil, dec.error("Expecting value", start)
+	}
+	isFloat := false
+	if dec.pos+1 < len(dec.s) && dec.s[dec.pos] == '.' && isDigit(dec.s[dec.pos+1]) {
+		isFloat = true
+		dec.pos++
+		dec.digits()
+	}
+	if dec.pos < len(dec.s) && (dec.s[dec.pos] == 'e' || dec.s[dec.pos] == 'E') {
+		save := dec.pos
+		dec.pos++
+		if dec.pos < len(dec.s) && (dec.s[dec.pos] == '+' || dec.s[dec.pos] == '-') {
+			dec.pos++
+		}
+		if dec.digits() {
+			isFloat = true
+		} else {
+			dec.pos = save
+		}
+	}
+	text := string(dec.s[start:dec.pos])
+	if isFloat {
+		if dec.parseFloat != nil {
+			return py.Call(dec.parseFloat, py.Tuple{py.String(text)}, nil)
+		}
+		return py.FloatFromString(text)
+	}
+	if dec.parseInt != nil {
+		return py.Call(dec.parseInt, py.Tuple{py.String(text)}, nil)
+	}
+	return py.IntFromString(text, 10)
+}
+
+// Decodes 4 hex digits at pos returning -1 if they aren't valid
+func (dec *decoder) hex4(pos int) rune {
+	if pos+4 > len(dec.s) {
+		return -1
+	}
+	var r rune
+	for _, c := range dec.s[pos : pos+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return -1
+		}
+		r = r<<4 | c
+	}
+	return r
+}
+
+// Decodes the string at the current position
+func (dec *decoder) string() (string, error) {
+	start := dec.pos
+	dec.pos++
+	var out strings.Builder
+	for {
+		if dec.pos >= len(dec.s) {
+			return "", dec.error("Unterminated string starting at", start)
+		}
+		c := dec.s[dec.pos]
+		switch {
+		case c == '"':
+			dec.pos++
+			return out.String(), nil
+		case c == '\\':
+			dec.pos++
+			if dec.pos >= len(dec.s) {
+				return "", dec.error("Unterminated string starting at", start)
+			}
+			esc := dec.s[dec.pos]
+			switch esc {
+			case '"', '\\', '/':
+				out.WriteRune(esc)
+			case 'b':
+				out.WriteByte('\b')
+			case 'f':
+				out.WriteByte('\f')
+			case 'n':
+				out.WriteByte('\n')
+			case 'r':
+				out.WriteByte('\r')
+			case 't':
+				out.WriteByte('\t')
+			case 'u':
+				r := dec.hex4(dec.pos + 1)
+				if r < 0 {
+					return "", dec.error("Invalid \\uXXXX escape", dec.pos)
+				}
+				dec.pos += 4
+				if utf16.IsSurrogate(r) && dec.pos+2 < len(dec.s) && dec.s[dec.pos+1] == '\\' && dec.s[dec.pos+2] == 'u' {
+					r2 := dec.hex4(dec.pos + 3)
+					if r2 < 0 {
+						return "", dec.error("Invalid \\uXXXX escape", dec.pos+2)
+					}
+					if combined := utf16.DecodeRune(r, r2); combined != utf8.RuneError {
+						r = combined
+						dec.pos += 6
+					}
+				}
+				out.WriteRune(r)
+			default:
+				return "", dec.error("Invalid \\escape", dec.pos-1)
+			}
+			dec.pos++
+		case c < 0x20 && dec.strict:
+			return "", dec.error("Invalid control character at", dec.pos)
+		default:
+			out.WriteRune(c)
+			dec.pos++
+		}
+	}
+}
+
+// Decodes the array at the current position
+func (dec *decoder) array() (py.Object, error) {
+	dec.pos++
+	items := []py.Object{}
+	dec.skipSpace()
+	if dec.pos < len(dec.s) && dec.s[dec.pos] == ']' {
+		dec.pos++
+		return py.NewListFromItems(items), nil
+	}
+	for {
+		dec.skipSpace()
+		item, err := dec.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		dec.skipSpace()
+		if dec.pos < len(dec.s) {
+			switch dec.s[dec.pos] {
+			case ']':
+				dec.pos++
+				return py.NewListFromItems(items), nil
+			case ',':
+				dec.pos++
+				dec.skipSpace()
+				continue
+			}
+		}
+		return nil, dec.error("Expecting ',' delimiter", dec.pos)
+	}
+}
+
+// Decodes the object at the current position
+func (dec *decoder) object() (py.Object, error) {
+	dec.pos++
+	var pairs []py.Object
+	dec.skipSpace()
+	if dec.pos < len(dec.s) && dec.s[dec.pos] == '}' {
+		dec.pos++
+		return dec.makeObject(pairs)
+	}
+	for {
+		if dec.pos >= len(dec.s) || dec.s[dec.pos] != '"' {
+			return nil, dec.error("Expecting property name enclosed in double quotes", dec.pos)
+		}
+		key, err := dec.string()
+		if err != nil {
+			return nil, err
+		}
+		dec.skipSpace()
+		if dec.pos >= len(dec.s) || dec.s[dec.pos] != ':' {
+			return nil, dec.error("Expecting ':' delimiter", dec.pos)
+		}
+		dec.pos++
+		dec.skipSpace()
+		value, err := dec.value()
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, py.Tuple{py.String(key), value})
+		dec.skipSpace()
+		if dec.pos < len(dec.s) {
+			switch dec.s[dec.pos] {
+			case '}':
+				dec.pos++
+				return dec.makeObject(pairs)
+			case ',':
+				dec.pos++
+				dec.skipSpace()
+				continue
+			}
+		}
+		return nil, dec.error("Expecting ',' delimiter", dec.pos)
+	}
+}
+
+// Makes the python object for a decoded JSON object from its
+// (key, value) pairs calling the hooks if set
+func (dec *decoder) makeObject(pairs []py.Object) (py.Object, error) {
+	if dec.objectPairsHook != nil {
+		return py.Call(dec.objectPairsHook, py.Tuple{py.NewListFromItems(pairs)}, nil)
+	}
+	d := py.NewDictSized(len(pairs))
+	for _, pair := range pairs {
+		kv := pair.(py.Tuple)
+		err := d.Set(kv[0], kv[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dec.objectHook != nil {
+		return py.Call(dec.objectHook, py.Tuple{d}, nil)
+	}
+	return d, nil
+}
+
+const json_loads_doc = `loads(s, *, object_hook=None, parse_float=None, parse_int=None,
+      parse_constant=None, object_pairs_hook=None, strict=True) -> object
+
+Deserialize s (a str, bytes or bytearray instance containing a JSON
+document) to a Python object.
+
+If specified, object_hook is called with the dict of every JSON object
+decoded and its return value is used instead of the dict.
+
+If specified, object_pairs_hook is called with a list of (key, value)
+pairs for every JSON object decoded and its return value is used
+instead of the dict. It takes priority over object_hook.
+
+If specified, parse_float, parse_int and parse_constant are called with
+the string of every JSON float, int and -Infinity, Infinity or NaN
+respectively. By default these decode to float and int.
+
+If strict is false then control characters are allowed inside strings.
+
+Raises JSONDecodeError if the document is invalid.`
+
+func json_loads(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var s py.Object
+	err := py.UnpackTuple(args, nil, "loads", 1, 1, &s)
+	if err != nil {
+		return nil, err
+	}
+	return loads(s, "loads", kwargs)
+}
+
+// Deserializes s with the keyword arguments of the function called name
+func loads(s py.Object, name string, kwargs py.StringDict) (py.Object, error) {
+	var doc string
+	switch x := s.(type) {
+	case py.String:
+		doc = string(x)
+	case py.Bytes:
+		doc = string(x)
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "the JSON object must be str, bytes or bytearray, not %s", typeName(s))
+	}
+	dec, err := newDecoder(name, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return dec.decode(doc)
+}
+
+const json_load_doc = `load(fp, *, object_hook=None, parse_float=None, parse_int=None,
+     parse_constant=None, object_pairs_hook=None, strict=True) -> object
+
+Deserialize fp, a file-like object with a read() method containing a
+JSON document, to a Python object. The keyword arguments are as for
+loads.`
+
+func json_load(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var fp py.Object
+	err := py.UnpackTuple(args, nil, "load", 1, 1, &fp)
+	if err != nil {
+		return nil, err
+	}
+	read, err := py.GetAttrString(fp, "read")
+	if err != nil {
+		return nil, err
+	}
+	s, err := py.Call(read, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return loads(s, "load", kwargs)
+}
+
+const json_doc = `JSON (JavaScript Object Notation) encoder and decoder.
+
+dumps and dump serialize Python objects to JSON and loads and load
+deserialize JSON to Python objects. dicts, lists, tuples, str, int,
+float, True, False and None are supported.`
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("dump", json_dump, 0, json_dump_doc),
+		py.MustNewMethod("dumps", json_dumps, 0, json_dumps_doc),
+		py.MustNewMethod("load", json_load, 0, json_load_doc),
+		py.MustNewMethod("loads", json_loads, 0, json_loads_doc),
+	}
+	globals := py.StringDict{
+		"JSONDecodeError": JSONDecodeError,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "json",
+		Doc:     json_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,211 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import json
+from libtest import *
+
+doc="dumps scalars"
+assertEqual(json.dumps(None), "null")
+assertEqual(json.dumps(True), "true")
+assertEqual(json.dumps(False), "false")
+assertEqual(json.dumps(42), "42")
+assertEqual(json.dumps(-7), "-7")
+assertEqual(json.dumps(123456789012345678901234567890), "123456789012345678901234567890")
+assertEqual(json.dumps(1.5), "1.5")
+assertEqual(json.dumps(float("nan")), "NaN")
+assertEqual(json.dumps(float("inf")), "Infinity")
+assertEqual(json.dumps(float("-inf")), "-Infinity")
+assertEqual(json.dumps("hello"), '"hello"')
+
+doc="dumps string escapes"
+assertEqual(json.dumps('a"b\\c'), '"a\\"b\\\\c"')
+assertEqual(json.dumps("\n\r\t\b\f\x01"), '"\\n\\r\\t\\b\\f\\u0001"')
+assertEqual(json.dumps("caf\xe9"), '"caf\\u00e9"')
+assertEqual(json.dumps("\U0001f600"), '"\\ud83d\\ude00"')
+assertEqual(json.dumps("caf\xe9", ensure_ascii=False), '"caf\xe9"')
+assertEqual(json.dumps("\x01", ensure_ascii=False), '"\\u0001"')
+
+doc="dumps containers"
+assertEqual(json.dumps([]), "[]")
+assertEqual(json.dumps({}), "{}")
+assertEqual(json.dumps([1, "a", None]), '[1, "a", null]')
+assertEqual(json.dumps((1, 2)), "[1, 2]")
+assertEqual(json.dumps({"b": 1, "a": [True]}), '{"b": 1, "a": [true]}')
+assertEqual(json.dumps({"a": {"b": {}}}), '{"a": {"b": {}}}')
+
+doc="dumps sort_keys"
+assertEqual(json.dumps({"c": 3, "a": 1, "b": 2}, sort_keys=True), '{"a": 1, "b": 2, "c": 3}')
+
+assertEqual(json.dumps({2: "a", 1: "b"}, sort_keys=True), '{"1": "b", "2": "a"}')
+
+doc="dumps keys"
+assertEqual(json.dumps({1: 2, 1.5: 3, True: 4, None: 5}), '{"1": 4, "1.5": 3, "null": 5}')
+assertRaises(TypeError, json.dumps, {(1, 2): 3})
+assertEqual(json.dumps({(1, 2): 3, "a": 4}, skipkeys=True), '{"a": 4}')
+
+doc="dumps indent"
+assertEqual(json.dumps([1, [2, 3], {}], indent=2), "[\n  1,\n  [\n    2,\n    3\n  ],\n  {}\n]")
+assertEqual(json.dumps({"a": 1, "b": [2]}, indent="\t"), '{\n\t"a": 1,\n\t"b": [\n\t\t2\n\t]\n}')
+assertEqual(json.dumps([1, 2], indent=0), "[\n1,\n2\n]")
+assertEqual(json.dumps([1], indent=None), "[1]")
+assertRaises(TypeError, json.dumps, [1], indent=1.5)
+
+doc="dumps separators"
+assertEqual(json.dumps({"a": [1, 2]}, separators=(",", ":")), '{"a":[1,2]}')
+assertEqual(json.dumps([1, 2], indent=1, separators=(" ,", ":")), "[\n 1 ,\n 2\n]")
+assertRaises(ValueError, json.dumps, [1], separators=(",",))
+
+doc="dumps default"
+class Point:
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+def encode_point(o):
+    if isinstance(o, Point):
+        return [o.x, o.y]
+    raise TypeError("can't encode")
+assertEqual(json.dumps({"p": Point(1, 2)}, default=encode_point), '{"p": [1, 2]}')
+assertRaisesText(TypeError, "can't encode", json.dumps, {1, 2}, default=encode_point)
+assertRaisesText(TypeError, "Object of type Point is not JSON serializable", json.dumps, Point(1, 2))
+assertRaisesText(TypeError, "Object of type bytes is not JSON serializable", json.dumps, b"x")
+
+doc="dumps allow_nan"
+assertRaisesText(ValueError, "Out of range float values are not JSON compliant", json.dumps, float("nan"), allow_nan=False)
+assertEqual(json.dumps(1.5, allow_nan=False), "1.5")
+
+doc="dumps circular references"
+a = [1]
+a.append(a)
+assertRaisesText(ValueError, "Circular reference detected", json.dumps, a)
+d = {}
+d["d"] = d
+assertRaisesText(ValueError, "Circular reference detected", json.dumps, d)
+b = [1]
+assertEqual(json.dumps([b, b]), "[[1], [1]]")
+
+doc="dumps arguments"
+assertRaises(TypeError, json.dumps)
+assertRaises(TypeError, json.dumps, 1, 2)
+assertRaisesText(TypeError, "unexpected keyword argument 'potato'", json.dumps, 1, potato=True)
+
+doc="loads scalars"
+assertEqual(json.loads("null"), None)
+assertEqual(json.loads("true"), True)
+assertEqual(json.loads("false"), False)
+assertEqual(json.loads("42"), 42)
+assertEqual(json.loads("-0"), 0)
+assertEqual(json.loads("123456789012345678901234567890"), 123456789012345678901234567890)
+assertEqual(json.loads("1.5"), 1.5)
+assertEqual(json.loads("-2.5e3"), -2500.0)
+assertEqual(json.loads("1E2"), 100.0)
+assertEqual(type(json.loads("1e2")), float)
+assertEqual(json.loads("Infinity"), float("inf"))
+assertEqual(json.loads("-Infinity"), float("-inf"))
+n = json.loads("NaN")
+assertTrue(n != n)
+assertEqual(json.loads('  "x"  '), "x")
+assertEqual(json.loads(b'[1]'), [1])
+
+doc="loads strings"
+assertEqual(json.loads('"a\\"b\\\\c\\/d"'), 'a"b\\c/d')
+assertEqual(json.loads('"\\n\\r\\t\\b\\f"'), "\n\r\t\b\f")
+assertEqual(json.loads('"caf\\u00e9"'), "caf\xe9")
+assertEqual(json.loads('"caf\\u00E9"'), "caf\xe9")
+assertEqual(json.loads('"\\ud83d\\ude00"'), "\U0001f600")
+assertEqual(json.loads('"caf\xe9"'), "caf\xe9")
+
+doc="loads containers"
+assertEqual(json.loads("[]"), [])
+assertEqual(json.loads("{}"), {})
+assertEqual(json.loads('[1, "a", null, [true]]'), [1, "a", None, [True]])
+assertEqual(json.loads('{"a": 1, "b": {"c": [2]}}'), {"a": 1, "b": {"c": [2]}})
+assertEqual(json.loads(' { "a" : 1 , "b" : 2 } '), {"a": 1, "b": 2})
+assertEqual(json.loads('{"a": 1, "a": 2}'), {"a": 2})
+assertEqual(list(json.loads('{"b": 1, "c": 2, "a": 3}')), ["b", "c", "a"])
+
+doc="loads hooks"
+assertEqual(json.loads('{"a": {"b": 1}}', object_hook=lambda d: len(d)), 1)
+def as_point(d):
+    if "x" in d and "y" in d:
+        return Point(d["x"], d["y"])
+    return d
+p = json.loads('{"x": 1, "y": 2}', object_hook=as_point)
+assertEqual((p.x, p.y), (1, 2))
+assertEqual(json.loads('{"b": 1, "a": 2}', object_pairs_hook=list), [("b", 1), ("a", 2)])
+assertEqual(json.loads('{"b": 1}', object_pairs_hook=list, object_hook=len), [("b", 1)])
+assertEqual(json.loads('[1, 2.5]', parse_int=str, parse_float=str), ["1", "2.5"])
+assertEqual(json.loads('[NaN, -Infinity]', parse_constant=str), ["NaN", "-Infinity"])
+
+doc="loads strict"
+assertRaisesText(json.JSONDecodeError, "Invalid control character at: line 1 column 3 (char 2)", json.loads, '"a\nb"')
+assertEqual(json.loads('"a\nb"', strict=False), "a\nb")
+
+doc="loads errors"
+assertTrue(issubclass(json.JSONDecodeError, ValueError))
+for s, msg in (
+    ("", "Expecting value: line 1 column 1 (char 0)"),
+    ("   ", "Expecting value: line 1 column 4 (char 3)"),
+    ("nul", "Expecting value: line 1 column 1 (char 0)"),
+    ("-", "Expecting value: line 1 column 1 (char 0)"),
+    ("[1,]", "Expecting value: line 1 column 4 (char 3)"),
+    ("[1 2]", "Expecting ',' delimiter: line 1 column 4 (char 3)"),
+    ("[1", "Expecting ',' delimiter: line 1 column 3 (char 2)"),
+    ("{1: 2}", "Expecting property name enclosed in double quotes: line 1 column 2 (char 1)"),
+    ('{"a": 1,}', "Expecting property name enclosed in double quotes: line 1 column 9 (char 8)"),
+    ('{"a" 1}', "Expecting ':' delimiter: line 1 column 6 (char 5)"),
+    ('{"a": 1 "b": 2}', "Expecting ',' delimiter: line 1 column 9 (char 8)"),
+    ('"abc', "Unterminated string starting at: line 1 column 1 (char 0)"),
+    ('"\\x"', "Invalid \\escape: line 1 column 2 (char 1)"),
+    ('"\\u12"', "Invalid \\uXXXX escape: line 1 column 3 (char 2)"),
+    ('"\\ud83d\\u12"', "Invalid \\uXXXX escape: line 1 column 9 (char 8)"),
+    ("1 2", "Extra data: line 1 column 3 (char 2)"),
+    ("01", "Extra data: line 1 column 2 (char 1)"),
+    ("[\n1,\n  x]", "Expecting value: line 3 column 3 (char 7)"),
+    ("\ufeff1", "Unexpected UTF-8 BOM (decode using utf-8-sig): line 1 column 1 (char 0)"),
+):
+    try:
+        json.loads(s)
+    except json.JSONDecodeError as e:
+        assertEqual(e.args[0], msg)
+    else:
+        fail("JSONDecodeError not raised for %r" % s)
+
+doc="JSONDecodeError attributes"
+try:
+    json.loads('[1,\n 2 x]')
+except json.JSONDecodeError as e:
+    assertEqual(e.msg, "Expecting ',' delimiter")
+    assertEqual(e.doc, '[1,\n 2 x]')
+    assertEqual(e.pos, 7)
+    assertEqual(e.lineno, 2)
+    assertEqual(e.colno, 4)
+else:
+    fail("JSONDecodeError not raised")
+
+doc="loads arguments"
+assertRaisesText(TypeError, "the JSON object must be str, bytes or bytearray, not int", json.loads, 1)
+assertRaises(TypeError, json.loads, "1", potato=True)
+
+doc="round trip"
+data = {"name": "gpython", "version": [0, 1], "ratio": 0.25, "ok": True, "none": None, "nested": {"empty": [], "text": "caf\xe9\n"}}
+assertEqual(json.loads(json.dumps(data)), data)
+assertEqual(json.loads(json.dumps(data, indent=4, ensure_ascii=False)), data)
+
+doc="dump and load"
+class File:
+    def __init__(self, data=""):
+        self.data = data
+    def write(self, s):
+        self.data += s
+    def read(self):
+        return self.data
+f = File()
+assertEqual(json.dump({"a": [1, 2]}, f, separators=(",", ":")), None)
+assertEqual(f.data, '{"a":[1,2]}')
+assertEqual(json.load(File('{"a": [1, 2]}')), {"a": [1, 2]})
+assertEqual(json.load(File('{"a": 1}'), object_hook=len), 1)
+assertRaises(json.JSONDecodeError, json.load, File("["))
+assertRaises(AttributeError, json.load, 1)
+
+doc="finished"
@@ -0,0 +1,157 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Filter and Filterer
+
+package logging
+
+import (
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const filterer_doc = `A base class for loggers and handlers which allows them to share
+common code.`
+
+// FiltererClass is the base class of Logger and Handler
+var FiltererClass = py.NewClass(py.TypeType, "logging", "Filterer", filterer_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__init__": py.NewGoMethod0("__init__", filtererInit),
+	"addFilter": py.NewGoMethod1("addFilter", func(self, filter py.Object) (py.Object, error) {
+		filters, err := filterList(self)
+		if err != nil {
+			return nil, err
+		}
+		if i := indexOf(filters, filter); i < 0 {
+			filters.Append(filter)
+		}
+		return py.None, nil
+	}),
+	"removeFilter": py.NewGoMethod1("removeFilter", func(self, filter py.Object) (py.Object, error) {
+		filters, err := filterList(self)
+		if err != nil {
+			return nil, err
+		}
+		if i := indexOf(filters, filter); i >= 0 {
+			filters.Items = append(filters.Items[:i], filters.Items[i+1:]...)
+		}
+		return py.None, nil
+	}),
+	"filter": py.NewGoMethod1("filter", func(self, record py.Object) (py.Object, error) {
+		ok, err := filterRecord(self, record)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(ok), nil
+	}),
+})
+
+func filtererInit(self py.Object) (py.Object, error) {
+	_, err := py.SetAttrString(self, "filters", py.NewList())
+	return py.None, err
+}
+
+// Returns the filters of a Filterer
+func filterList(self py.Object) (*py.List, error) {
+	filters, err := py.GetAttrString(self, "filters")
+	if err != nil {
+		return nil, err
+	}
+	list, ok := filters.(*py.List)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "filters must be a list, not %s", filters.Type().Name)
+	}
+	return list, nil
+}
+
+// Returns the index of obj in list or -1 if it isn't there
+func indexOf(list *py.List, obj py.Object) int {
+	for i, item := range list.Items {
+		if item == obj {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns whether the filters of self let the record through
+//
+// The filters are objects with a filter method or callables.
+func filterRecord(self, record py.Object) (bool, error) {
+	filters, err := filterList(self)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range filters.Copy().Items {
+		var result py.Object
+		if method, err := py.GetAttrString(f, "filter"); err == nil {
+			result, err = py.Call(method, py.Tuple{record}, nil)
+			if err != nil {
+				return false, err
+			}
+		} else if py.IsException(py.AttributeError, err) {
+			result, err = py.Call(f, py.Tuple{record}, nil)
+			if err != nil {
+				return false, err
+			}
+		} else {
+			return false, err
+		}
+		if !py.IsTrue(result) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+const filter_doc = `Filter instances are used to perform arbitrary filtering of LogRecords.
+
+Loggers and Handlers can optionally use Filter instances to filter
+records as desired. The base filter class only allows events which are
+below a certain point in the logger hierarchy. For example, a filter
+initialized with "A.B" will allow events logged by loggers "A.B",
+"A.B.C", "A.B.C.D", "A.B.D" etc. but not "A.BB", "B.A.B" etc. If
+initialized with the empty string, all events are passed.`
+
+// FilterClass passes the records of a logger and its descendants
+var FilterClass = py.NewClass(py.TypeType, "logging", "Filter", filter_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__init__": py.NewGoMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var name py.Object = py.String("")
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:Filter", []string{"name"}, &name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.SetAttrString(self, "name", name); err != nil {
+			return nil, err
+		}
+		n, err := py.Len(name)
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(self, "nlen", n)
+		return py.None, err
+	}),
+	"filter": py.NewGoMethod1("filter", func(self, record py.Object) (py.Object, error) {
+		nameObj, err := py.GetAttrString(self, "name")
+		if err != nil {
+			return nil, err
+		}
+		name, err := py.StrAsString(nameObj)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			return py.True, nil
+		}
+		recordNameObj, err := py.GetAttrString(record, "name")
+		if err != nil {
+			return nil, err
+		}
+		recordName, err := py.StrAsString(recordNameObj)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(recordName == name || strings.HasPrefix(recordName, name+".")), nil
+	}),
+})
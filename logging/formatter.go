@@ -0,0 +1,427 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Formatter
+
+package logging
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/datetime"
+	"github.com/go-python/gpython/py"
+)
+
+// The format used by a Formatter made without one for each style
+var defaultFormats = map[string]string{
+	"%": "%(message)s",
+	"{": "{message}",
+	"$": "${message}",
+}
+
+// The format used by basicConfig for each style
+var basicFormats = map[string]string{
+	"%": BASIC_FORMAT,
+	"{": "{levelname}:{name}:{message}",
+	"$": "${levelname}:${name}:${message}",
+}
+
+// Match a field in a format string of each style, used to check the
+// format has at least one
+var validFields = map[string]*regexp.Regexp{
+	"%": regexp.MustCompile(`%\(\w+\)[#0+ -]*(\*|\d+)?(\.(\*|\d+))?[diouxefgcrsa%]`),
+	"{": regexp.MustCompile(`\{\w+(![rsa])?(:[^{}]*)?\}`),
+	"$": regexp.MustCompile(`\$(\w+|\{\w+\})`),
+}
+
+// The strings in a format of each style which show it uses asctime
+var asctimeSearch = map[string][]string{
+	"%": {"%(asctime)"},
+	"{": {"{asctime"},
+	"$": {"$asctime", "${asctime}"},
+}
+
+const formatter_doc = `Formatter instances are used to convert a LogRecord to text.
+
+Formatters need to know how a LogRecord is constructed. They are
+responsible for converting a LogRecord to (usually) a string which can
+be interpreted by either a human or an external system. The base Formatter
+allows a formatting string to be specified. If none is supplied, the
+style-dependent default value, "%(message)s", "{message}", or
+"${message}", is used.
+
+The Formatter can be initialized with a format string which makes use of
+knowledge of the LogRecord attributes - e.g. the default value mentioned
+above makes use of the fact that the user's message and arguments are pre-
+formatted into a LogRecord's message attribute. Currently, the useful
+attributes in a LogRecord are described by:
+
+%(name)s            Name of the logger (logging channel)
+%(levelno)s         Numeric logging level for the message (DEBUG, INFO,
+                    WARNING, ERROR, CRITICAL)
+%(levelname)s       Text logging level for the message ("DEBUG", "INFO",
+                    "WARNING", "ERROR", "CRITICAL")
+%(pathname)s        Full pathname of the source file where the logging
+                    call was issued (if available)
+%(filename)s        Filename portion of pathname
+%(module)s          Module (name portion of filename)
+%(lineno)d          Source line number where the logging call was issued
+                    (if available)
+%(funcName)s        Function name
+%(created)f         Time when the LogRecord was created (time.time()
+                    return value)
+%(asctime)s         Textual time when the LogRecord was created
+%(msecs)d           Millisecond portion of the creation time
+%(relativeCreated)d Time in milliseconds when the LogRecord was created,
+                    relative to the time the logging module was loaded
+                    (typically at application startup time)
+%(thread)d          Thread ID (if available)
+%(threadName)s      Thread name (if available)
+%(process)d         Process ID (if available)
+%(message)s         The result of record.getMessage(), computed just as
+                    the record is emitted`
+
+// FormatterClass turns records into text
+var FormatterClass = py.NewClass(py.TypeType, "logging", "Formatter", formatter_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"default_time_format": py.String("%Y-%m-%d %H:%M:%S"),
+	"default_msec_format": py.String("%s,%03d"),
+	"__init__":            py.NewGoMethod("__init__", formatterInit),
+	"usesTime":            py.NewGoMethod0("usesTime", formatterUsesTime),
+	"format":              py.NewGoMethod1("format", formatterFormat),
+	"formatMessage":       py.NewGoMethod1("formatMessage", formatterFormatMessage),
+	"formatTime":          py.NewGoMethod("formatTime", formatterFormatTime),
+	"formatException":     py.NewGoMethod1("formatException", formatterFormatException),
+	"formatStack": py.NewGoMethod1("formatStack", func(self, stackInfo py.Object) (py.Object, error) {
+		return stackInfo, nil
+	}),
+})
+
+// The formatter used by handlers without one
+var defaultFormatter = mustCall(FormatterClass)
+
+// Calls fn panicking on error, for making objects at initialisation
+func mustCall(fn py.Object, args ...py.Object) py.Object {
+	res, err := py.Call(fn, py.Tuple(args), nil)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func formatterInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var format, datefmt, style, validate, defaults py.Object = py.None, py.None, py.String("%"), py.True, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOO:Formatter", []string{"fmt", "datefmt", "style", "validate", "defaults"}, &format, &datefmt, &style, &validate, &defaults)
+	if err != nil {
+		return nil, err
+	}
+	styleStr, ok := style.(py.String)
+	if _, known := defaultFormats[string(styleStr)]; !ok || !known {
+		return nil, py.ExceptionNewf(py.ValueError, "Style must be one of: %%,{,$")
+	}
+	if format == py.None {
+		format = py.String(defaultFormats[string(styleStr)])
+	}
+	formatStr, err := py.StrAsString(format)
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(validate) && !validFields[string(styleStr)].MatchString(formatStr) {
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid format '%s' for '%s' style", formatStr, string(styleStr))
+	}
+	attrs := py.StringDict{
+		"_style":    styleStr,
+		"_fmt":      format,
+		"datefmt":   datefmt,
+		"_defaults": defaults,
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Returns the format and style of a formatter
+func formatterStyle(self py.Object) (format, style string, err error) {
+	formatObj, err := py.GetAttrString(self, "_fmt")
+	if err != nil {
+		return "", "", err
+	}
+	format, err = py.StrAsString(formatObj)
+	if err != nil {
+		return "", "", err
+	}
+	styleObj, err := py.GetAttrString(self, "_style")
+	if err != nil {
+		return "", "", err
+	}
+	style, err = py.StrAsString(styleObj)
+	if err != nil {
+		return "", "", err
+	}
+	return format, style, nil
+}
+
+func formatterUsesTime(self py.Object) (py.Object, error) {
+	format, style, err := formatterStyle(self)
+	if err != nil {
+		return nil, err
+	}
+	for _, search := range asctimeSearch[style] {
+		if strings.Contains(format, search) {
+			return py.True, nil
+		}
+	}
+	return py.False, nil
+}
+
+// Substitutes the values in format as string.Template does
+func substitute(format string, values py.StringDict) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '$' || i+1 >= len(format) {
+			out.WriteByte(c)
+			continue
+		}
+		rest := format[i+1:]
+		if rest[0] == '$' {
+			out.WriteByte('$')
+			i++
+			continue
+		}
+		braced := rest[0] == '{'
+		if braced {
+			rest = rest[1:]
+		}
+		end := 0
+		for end < len(rest) && (rest[end] == '_' || rest[end] >= 'a' && rest[end] <= 'z' || rest[end] >= 'A' && rest[end] <= 'Z' || end > 0 && rest[end] >= '0' && rest[end] <= '9') {
+			end++
+		}
+		if end == 0 || (braced && (end >= len(rest) || rest[end] != '}')) {
+			return "", py.ExceptionNewf(py.ValueError, "Invalid placeholder in string")
+		}
+		name := rest[:end]
+		value, ok := values[name]
+		if !ok {
+			return "", &py.Exception{Base: py.KeyError, Args: py.Tuple{py.String(name)}}
+		}
+		str, err := py.Str(value)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(string(str.(py.String)))
+		i += end
+		if braced {
+			i += 2
+		}
+	}
+	return out.String(), nil
+}
+
+func formatterFormatMessage(self, record py.Object) (py.Object, error) {
+	format, style, err := formatterStyle(self)
+	if err != nil {
+		return nil, err
+	}
+	dictObj, err := py.GetAttrString(record, "__dict__")
+	if err != nil {
+		return nil, err
+	}
+	values, err := py.DictAsNamespace(dictObj)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := py.GetAttrString(self, "_defaults")
+	if err != nil {
+		return nil, err
+	}
+	if defaults != py.None {
+		d, err := py.DictAsNamespace(defaults)
+		if err != nil {
+			return nil, err
+		}
+		merged := d.Copy()
+		for key, value := range values {
+			merged[key] = value
+		}
+		values = merged
+	}
+	switch style {
+	case "{":
+		return py.StringFormat(py.String(format), nil, func(key py.String) (py.Object, error) {
+			if value, ok := values[string(key)]; ok {
+				return value, nil
+			}
+			return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+		})
+	case "$":
+		s, err := substitute(format, values)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(s), nil
+	}
+	return py.Mod(py.String(format), values)
+}
+
+func formatterFormatTime(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var record, datefmt py.Object = nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:formatTime", []string{"record", "datefmt"}, &record, &datefmt)
+	if err != nil {
+		return nil, err
+	}
+	createdObj, err := py.GetAttrString(record, "created")
+	if err != nil {
+		return nil, err
+	}
+	created, err := py.FloatAsFloat64(createdObj)
+	if err != nil {
+		return nil, err
+	}
+	secs, frac := math.Modf(created)
+	ct := time.Unix(int64(secs), int64(frac*1e9)).Local()
+	if datefmt != py.None {
+		format, err := py.StrAsString(datefmt)
+		if err != nil {
+			return nil, err
+		}
+		s, err := datetime.Strftime(ct, format)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(s), nil
+	}
+	timeFormat, err := py.GetAttrString(self, "default_time_format")
+	if err != nil {
+		return nil, err
+	}
+	format, err := py.StrAsString(timeFormat)
+	if err != nil {
+		return nil, err
+	}
+	s, err := datetime.Strftime(ct, format)
+	if err != nil {
+		return nil, err
+	}
+	msecFormat, err := py.GetAttrString(self, "default_msec_format")
+	if err != nil {
+		return nil, err
+	}
+	if msecFormat == py.None {
+		return py.String(s), nil
+	}
+	msecs, err := py.GetAttrString(record, "msecs")
+	if err != nil {
+		return nil, err
+	}
+	return py.Mod(msecFormat, py.Tuple{py.String(s), msecs})
+}
+
+// Returns the traceback for exc_info as text
+func formatException(excInfo py.Object) (py.Object, error) {
+	ei, ok := excInfo.(py.Tuple)
+	if !ok || len(ei) != 3 {
+		return nil, py.ExceptionNewf(py.TypeError, "exc_info must be a tuple of (type, value, traceback)")
+	}
+	exc := py.ExceptionInfo{Value: ei[1]}
+	exc.Type, _ = ei[0].(*py.Type)
+	exc.Traceback, _ = ei[2].(*py.Traceback)
+	var buf bytes.Buffer
+	exc.TracebackDump(&buf)
+	return py.String(strings.TrimSuffix(buf.String(), "\n")), nil
+}
+
+func formatterFormatException(self, excInfo py.Object) (py.Object, error) {
+	return formatException(excInfo)
+}
+
+func formatterFormat(self, record py.Object) (py.Object, error) {
+	message, err := py.CallMethodByName(record, "getMessage")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = py.SetAttrString(record, "message", message); err != nil {
+		return nil, err
+	}
+	usesTime, err := py.CallMethodByName(self, "usesTime")
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(usesTime) {
+		datefmt, err := py.GetAttrString(self, "datefmt")
+		if err != nil {
+			return nil, err
+		}
+		asctime, err := py.CallMethodByName(self, "formatTime", record, datefmt)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.SetAttrString(record, "asctime", asctime); err != nil {
+			return nil, err
+		}
+	}
+	res, err := py.CallMethodByName(self, "formatMessage", record)
+	if err != nil {
+		return nil, err
+	}
+	s, err := py.StrAsString(res)
+	if err != nil {
+		return nil, err
+	}
+	excInfo, err := py.GetAttrString(record, "exc_info")
+	if err != nil {
+		return nil, err
+	}
+	excText, err := py.GetAttrString(record, "exc_text")
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(excInfo) && !py.IsTrue(excText) {
+		// Cache the traceback text to avoid converting it multiple
+		// times as it's constant
+		excText, err = py.CallMethodByName(self, "formatException", excInfo)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.SetAttrString(record, "exc_text", excText); err != nil {
+			return nil, err
+		}
+	}
+	appendText := func(text py.Object) error {
+		str, err := py.StrAsString(text)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+		s += str
+		return nil
+	}
+	if py.IsTrue(excText) {
+		if err = appendText(excText); err != nil {
+			return nil, err
+		}
+	}
+	stackInfo, err := py.GetAttrString(record, "stack_info")
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(stackInfo) {
+		stack, err := py.CallMethodByName(self, "formatStack", stackInfo)
+		if err != nil {
+			return nil, err
+		}
+		if err = appendText(stack); err != nil {
+			return nil, err
+		}
+	}
+	return py.String(s), nil
+}
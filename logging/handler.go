@@ -0,0 +1,476 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Handlers
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-python/gpython/py"
+)
+
+const handler_doc = `Handler instances dispatch logging events to specific destinations.
+
+The base handler class. Acts as a placeholder which defines the Handler
+interface. Handlers can optionally use Formatter instances to format
+records as desired. By default, no formatter is specified; in this case,
+the 'raw' message as determined by record.message is logged.`
+
+// HandlerClass is the base class of the handlers
+var HandlerClass = py.NewClass(py.TypeType, "logging", "Handler", handler_doc, py.Tuple{FiltererClass}, py.StringDict{
+	"__init__": py.NewGoMethod("__init__", handlerInit),
+	"setLevel": py.NewGoMethod1("setLevel", func(self, level py.Object) (py.Object, error) {
+		n, err := checkLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(self, "level", py.Int(n))
+		return py.None, err
+	}),
+	"setFormatter": py.NewGoMethod1("setFormatter", func(self, formatter py.Object) (py.Object, error) {
+		_, err := py.SetAttrString(self, "formatter", formatter)
+		return py.None, err
+	}),
+	"get_name": py.NewGoMethod0("get_name", func(self py.Object) (py.Object, error) {
+		return py.GetAttrString(self, "_name")
+	}),
+	"set_name": py.NewGoMethod1("set_name", func(self, name py.Object) (py.Object, error) {
+		_, err := py.SetAttrString(self, "_name", name)
+		return py.None, err
+	}),
+	"format": py.NewGoMethod1("format", handlerFormat),
+	"emit": py.NewGoMethod1("emit", func(self, record py.Object) (py.Object, error) {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "emit must be implemented by Handler subclasses")
+	}),
+	"handle":      py.NewGoMethod1("handle", handlerHandle),
+	"handleError": py.NewGoMethod1("handleError", handlerHandleError),
+	"flush":       py.NewGoMethod0("flush", noop),
+	"close":       py.NewGoMethod0("close", noop),
+	"createLock":  py.NewGoMethod0("createLock", noop),
+	"acquire":     py.NewGoMethod0("acquire", noop),
+	"release":     py.NewGoMethod0("release", noop),
+	"__repr__":    py.NewGoMethod0("__repr__", handlerRepr),
+})
+
+// Does nothing, for the methods which subclasses may override
+func noop(self py.Object) (py.Object, error) {
+	return py.None, nil
+}
+
+func handlerInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var level py.Object = py.Int(NOTSET)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:Handler", []string{"level"}, &level)
+	if err != nil {
+		return nil, err
+	}
+	n, err := checkLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = filtererInit(self); err != nil {
+		return nil, err
+	}
+	attrs := py.StringDict{
+		"_name":     py.None,
+		"level":     py.Int(n),
+		"formatter": py.None,
+		"_closed":   py.False,
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Formats the record with the formatter of the handler or the default
+// one if it doesn't have one
+func handlerFormat(self, record py.Object) (py.Object, error) {
+	formatter, err := py.GetAttrString(self, "formatter")
+	if err != nil {
+		return nil, err
+	}
+	if formatter == py.None {
+		formatter = defaultFormatter
+	}
+	return py.CallMethodByName(formatter, "format", record)
+}
+
+// Emits the record if the filters pass it, returning whether they did
+func handlerHandle(self, record py.Object) (py.Object, error) {
+	ok, err := filterRecord(self, record)
+	if err != nil || !ok {
+		return py.NewBool(ok), err
+	}
+	if _, err = py.CallMethodByName(self, "emit", record); err != nil {
+		return nil, err
+	}
+	return py.True, nil
+}
+
+// Returns the stream sys.name of the current context
+func sysStream(name string) (py.Object, error) {
+	sys, err := py.GetModule("sys")
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := sys.Globals[name]
+	if !ok {
+		return py.None, nil
+	}
+	return stream, nil
+}
+
+// Writes s to the stream
+func write(stream py.Object, s string) error {
+	_, err := py.CallMethodByName(stream, "write", py.String(s))
+	return err
+}
+
+// Writes the exception being handled and the record it happened with
+// to sys.stderr if raiseExceptions is set
+func handlerHandleError(self, record py.Object) (py.Object, error) {
+	if raise := moduleGlobal("raiseExceptions"); raise == nil || !py.IsTrue(raise) {
+		return py.None, nil
+	}
+	stderr, err := sysStream("stderr")
+	if err != nil || stderr == py.None {
+		return py.None, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("--- Logging error ---\n")
+	if exc := py.CurrentFrame.HandledException(); exc != nil {
+		exc.TracebackDump(&buf)
+	}
+	if msg, err := py.GetAttrString(record, "msg"); err == nil {
+		repr, err := py.ReprAsString(msg)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "Message: %s\n", repr)
+	}
+	if args, err := py.GetAttrString(record, "args"); err == nil {
+		str, err := py.StrAsString(args)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "Arguments: %s\n", str)
+	}
+	return py.None, write(stderr, buf.String())
+}
+
+// Calls the handleError method of the handler for an error which
+// happened while emitting the record
+//
+// The error is made the exception being handled while handleError
+// runs, as it would be in the except clause of a handler written in
+// python.
+func handleError(self, record py.Object, err error) error {
+	if py.IsException(py.SystemExit, err) || py.IsException(py.KeyboardInterrupt, err) {
+		return err
+	}
+	frame := py.CurrentFrame
+	if frame == nil {
+		// Called from Go so there is nowhere to put the exception
+		fmt.Fprintf(os.Stderr, "--- Logging error ---\n")
+		py.TracebackDump(err)
+		return nil
+	}
+	exc, ok := err.(py.ExceptionInfo)
+	if !ok {
+		e := py.MakeException(err)
+		tb, _ := e.Traceback.(*py.Traceback)
+		exc = py.ExceptionInfo{Type: e.Base, Value: e, Traceback: tb}
+	}
+	old := frame.Exc
+	frame.Exc = &exc
+	defer func() {
+		frame.Exc = old
+	}()
+	_, err = py.CallMethodByName(self, "handleError", record)
+	return err
+}
+
+func handlerRepr(self py.Object) (py.Object, error) {
+	level, err := py.IntAttr(self, "level")
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("<%s (%s)>", self.Type().Name, LevelName(level))), nil
+}
+
+const stream_handler_doc = `A handler class which writes logging records, appropriately formatted,
+to a stream. Note that this class does not close the stream, as
+sys.stdout or sys.stderr may be used.`
+
+// StreamHandlerClass writes the records to a stream
+var StreamHandlerClass = py.NewClass(py.TypeType, "logging", "StreamHandler", stream_handler_doc, py.Tuple{HandlerClass}, py.StringDict{
+	"terminator": py.String("\n"),
+	"__init__": py.NewGoMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var stream py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:StreamHandler", []string{"stream"}, &stream)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = handlerInit(self, nil, nil); err != nil {
+			return nil, err
+		}
+		if stream == py.None {
+			stream, err = sysStream("stderr")
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = py.SetAttrString(self, "stream", stream)
+		return py.None, err
+	}),
+	"emit":  py.NewGoMethod1("emit", streamHandlerEmit),
+	"flush": py.NewGoMethod0("flush", streamHandlerFlush),
+	"setStream": py.NewGoMethod1("setStream", func(self, stream py.Object) (py.Object, error) {
+		old, err := py.GetAttrString(self, "stream")
+		if err != nil {
+			return nil, err
+		}
+		if stream == old {
+			return py.None, nil
+		}
+		if _, err = py.CallMethodByName(self, "flush"); err != nil {
+			return nil, err
+		}
+		if _, err = py.SetAttrString(self, "stream", stream); err != nil {
+			return nil, err
+		}
+		return old, nil
+	}),
+	"__repr__": py.NewGoMethod0("__repr__", streamHandlerRepr),
+})
+
+// Writes the formatted record and the terminator to the stream
+func streamHandlerEmit(self, record py.Object) (py.Object, error) {
+	err := func() error {
+		msg, err := py.CallMethodByName(self, "format", record)
+		if err != nil {
+			return err
+		}
+		stream, err := py.GetAttrString(self, "stream")
+		if err != nil {
+			return err
+		}
+		terminator, err := py.GetAttrString(self, "terminator")
+		if err != nil {
+			return err
+		}
+		s, err := py.Add(msg, terminator)
+		if err != nil {
+			return err
+		}
+		if _, err = py.CallMethodByName(stream, "write", s); err != nil {
+			return err
+		}
+		_, err = py.CallMethodByName(self, "flush")
+		return err
+	}()
+	if err != nil {
+		return nil, handleError(self, record, err)
+	}
+	return py.None, nil
+}
+
+// Flushes the stream if it can be flushed
+func streamHandlerFlush(self py.Object) (py.Object, error) {
+	stream, err := py.GetAttrString(self, "stream")
+	if err != nil {
+		return nil, err
+	}
+	flush, err := py.GetAttrString(stream, "flush")
+	if err != nil {
+		if py.IsException(py.AttributeError, err) {
+			return py.None, nil
+		}
+		return nil, err
+	}
+	_, err = py.Call(flush, nil, nil)
+	return py.None, err
+}
+
+func streamHandlerRepr(self py.Object) (py.Object, error) {
+	level, err := py.IntAttr(self, "level")
+	if err != nil {
+		return nil, err
+	}
+	stream, err := py.GetAttrString(self, "stream")
+	if err != nil {
+		return nil, err
+	}
+	name := ""
+	if nameObj, err := py.GetAttrString(stream, "name"); err == nil {
+		str, err := py.StrAsString(nameObj)
+		if err != nil {
+			return nil, err
+		}
+		name = str + " "
+	}
+	return py.String(fmt.Sprintf("<%s %s(%s)>", self.Type().Name, name, LevelName(level))), nil
+}
+
+const file_handler_doc = `A handler class which writes formatted logging records to disk files.`
+
+// FileHandlerClass writes the records to a file
+var FileHandlerClass = py.NewClass(py.TypeType, "logging", "FileHandler", file_handler_doc, py.Tuple{StreamHandlerClass}, py.StringDict{
+	"__init__": py.NewGoMethod("__init__", fileHandlerInit),
+	"_open":    py.NewGoMethod0("_open", fileHandlerOpen),
+	"emit": py.NewGoMethod1("emit", func(self, record py.Object) (py.Object, error) {
+		stream, err := py.GetAttrString(self, "stream")
+		if err != nil {
+			return nil, err
+		}
+		if stream == py.None {
+			closed, err := py.GetAttrString(self, "_closed")
+			if err != nil {
+				return nil, err
+			}
+			if py.IsTrue(closed) {
+				return py.None, nil
+			}
+			if stream, err = py.CallMethodByName(self, "_open"); err != nil {
+				return nil, err
+			}
+			if _, err = py.SetAttrString(self, "stream", stream); err != nil {
+				return nil, err
+			}
+		}
+		return streamHandlerEmit(self, record)
+	}),
+	"close": py.NewGoMethod0("close", func(self py.Object) (py.Object, error) {
+		stream, err := py.GetAttrString(self, "stream")
+		if err != nil {
+			return nil, err
+		}
+		if stream != py.None {
+			if _, err = streamHandlerFlush(self); err != nil {
+				return nil, err
+			}
+			if _, err = py.SetAttrString(self, "stream", py.None); err != nil {
+				return nil, err
+			}
+			if _, err = py.CallMethodByName(stream, "close"); err != nil {
+				return nil, err
+			}
+		}
+		_, err = py.SetAttrString(self, "_closed", py.True)
+		return py.None, err
+	}),
+	"__repr__": py.NewGoMethod0("__repr__", func(self py.Object) (py.Object, error) {
+		level, err := py.IntAttr(self, "level")
+		if err != nil {
+			return nil, err
+		}
+		filename, err := py.GetAttrString(self, "baseFilename")
+		if err != nil {
+			return nil, err
+		}
+		name, err := py.StrAsString(filename)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("<%s %s (%s)>", self.Type().Name, name, LevelName(level))), nil
+	}),
+})
+
+func fileHandlerInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var filename, mode, encoding, delay, errors py.Object = nil, py.String("a"), py.None, py.False, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOOO:FileHandler", []string{"filename", "mode", "encoding", "delay", "errors"}, &filename, &mode, &encoding, &delay, &errors)
+	if err != nil {
+		return nil, err
+	}
+	name, err := py.StrAsString(filename)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, py.ExceptionNewf(py.OSError, "%v", err)
+	}
+	attrs := py.StringDict{
+		"baseFilename": py.String(abs),
+		"mode":         mode,
+		"encoding":     encoding,
+		"errors":       errors,
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	if py.IsTrue(delay) {
+		// Open the file when the first record is emitted
+		if _, err = handlerInit(self, nil, nil); err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(self, "stream", py.None)
+		return py.None, err
+	}
+	stream, err := py.CallMethodByName(self, "_open")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = handlerInit(self, nil, nil); err != nil {
+		return nil, err
+	}
+	_, err = py.SetAttrString(self, "stream", stream)
+	return py.None, err
+}
+
+// Opens the file of the handler
+func fileHandlerOpen(self py.Object) (py.Object, error) {
+	var values [4]py.Object
+	for i, name := range []string{"baseFilename", "mode", "encoding", "errors"} {
+		value, err := py.GetAttrString(self, name)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	mode, err := py.StrAsString(values[1])
+	if err != nil {
+		return nil, err
+	}
+	return py.Open(values[0], mode, -1, values[2], values[3], py.None, true)
+}
+
+const null_handler_doc = `This handler does nothing. It's intended to be used to avoid the
+"No handlers could be found for logger XXX" one-off warning. This is
+important for library code, which may contain code to log events. If a user
+of the library does not configure logging, the one-off warning might be
+produced; to avoid this, the library developer simply needs to instantiate
+a NullHandler and add it to the top-level logger of the library module or
+package.`
+
+// NullHandlerClass throws the records away
+var NullHandlerClass = py.NewClass(py.TypeType, "logging", "NullHandler", null_handler_doc, py.Tuple{HandlerClass}, py.StringDict{
+	"handle": py.NewGoMethod1("handle", noopRecord),
+	"emit":   py.NewGoMethod1("emit", noopRecord),
+})
+
+// Does nothing with a record
+func noopRecord(self, record py.Object) (py.Object, error) {
+	return py.None, nil
+}
+
+// stderrHandlerClass is the class of lastResort which writes to
+// whatever sys.stderr is when the record is emitted
+var stderrHandlerClass = py.NewClass(py.TypeType, "logging", "_StderrHandler", "This class is like a StreamHandler using sys.stderr, but always uses\nwhatever sys.stderr is currently set to rather than the value of\nsys.stderr at handler construction time.", py.Tuple{StreamHandlerClass}, py.StringDict{
+	"__init__": py.NewGoMethod("__init__", handlerInit),
+	"stream": &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return sysStream("stderr")
+		},
+	},
+})
@@ -0,0 +1,865 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Loggers and the Manager
+
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+var ManagerType = py.NewType("Manager", `There is [under normal circumstances] just one Manager instance, which
+holds the hierarchy of loggers.`)
+
+// Manager holds the hierarchy of loggers of a Context
+type Manager struct {
+	root        py.Object
+	loggers     map[string]py.Object
+	names       []string // the names of the loggers in the order made
+	loggerClass *py.Type
+	disable     int
+}
+
+// Type of this object
+func (m *Manager) Type() *py.Type {
+	return ManagerType
+}
+
+// Makes a manager with a root logger at level WARNING
+func newManager() (*Manager, error) {
+	root, err := py.Call(RootLoggerClass, py.Tuple{py.Int(WARNING)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		root:        root,
+		loggers:     map[string]py.Object{},
+		loggerClass: LoggerClass,
+	}
+	if _, err = py.SetAttrString(root, "manager", m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Returns the name of a logger
+func loggerName(logger py.Object) (string, error) {
+	name, err := py.GetAttrString(logger, "name")
+	if err != nil {
+		return "", err
+	}
+	return py.StrAsString(name)
+}
+
+// GetLogger returns the logger called name, making it if it doesn't
+// exist
+//
+// A new logger is put into the hierarchy, so its parent is the
+// nearest existing ancestor and it becomes the parent of the existing
+// loggers below it.
+func (m *Manager) GetLogger(nameObj py.Object) (py.Object, error) {
+	name, ok := nameObj.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "A logger name must be a string")
+	}
+	if logger, ok := m.loggers[string(name)]; ok {
+		return logger, nil
+	}
+	logger, err := py.Call(m.loggerClass, py.Tuple{name}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = py.SetAttrString(logger, "manager", m); err != nil {
+		return nil, err
+	}
+	m.loggers[string(name)] = logger
+	m.names = append(m.names, string(name))
+
+	// Find the parent
+	parent := m.root
+	for s := string(name); ; {
+		i := strings.LastIndexByte(s, '.')
+		if i < 0 {
+			break
+		}
+		s = s[:i]
+		if p, ok := m.loggers[s]; ok {
+			parent = p
+			break
+		}
+	}
+	if _, err = py.SetAttrString(logger, "parent", parent); err != nil {
+		return nil, err
+	}
+
+	// Adopt the children
+	prefix := string(name) + "."
+	for _, childName := range m.names {
+		if !strings.HasPrefix(childName, prefix) {
+			continue
+		}
+		child := m.loggers[childName]
+		childParent, err := py.GetAttrString(child, "parent")
+		if err != nil {
+			return nil, err
+		}
+		parentName, err := loggerName(childParent)
+		if err != nil {
+			return nil, err
+		}
+		if childParent == m.root || !strings.HasPrefix(parentName, prefix) {
+			if _, err = py.SetAttrString(child, "parent", logger); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return logger, nil
+}
+
+// Flushes and closes the handlers of all the loggers
+func (m *Manager) shutdown() error {
+	var handlers []py.Object
+	seen := map[py.Object]bool{}
+	loggers := []py.Object{m.root}
+	for _, name := range m.names {
+		loggers = append(loggers, m.loggers[name])
+	}
+	for _, logger := range loggers {
+		list, err := py.GetAttrString(logger, "handlers")
+		if err != nil {
+			return err
+		}
+		items, err := py.SequenceList(list)
+		if err != nil {
+			return err
+		}
+		for _, h := range items.Items {
+			if !seen[h] {
+				seen[h] = true
+				handlers = append(handlers, h)
+			}
+		}
+	}
+	// Close the handlers in the reverse of the order they were added
+	for i := len(handlers) - 1; i >= 0; i-- {
+		h := handlers[i]
+		for _, method := range []string{"flush", "close"} {
+			_, err := py.CallMethodByName(h, method)
+			if err != nil && !py.IsException(py.OSError, err) && !py.IsException(py.ValueError, err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<logging.Manager object at %p>", m)), nil
+}
+
+// Properties
+func init() {
+	ManagerType.Dict["root"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Manager).root, nil
+		},
+	}
+	ManagerType.Dict["disable"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*Manager).disable), nil
+		},
+		Fset: func(self, value py.Object) error {
+			n, err := checkLevel(value)
+			if err != nil {
+				return err
+			}
+			self.(*Manager).disable = n
+			return nil
+		},
+	}
+	ManagerType.Dict["loggerDict"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			m := self.(*Manager)
+			d := py.NewDict()
+			for _, name := range m.names {
+				d.M__setitem__(py.String(name), m.loggers[name])
+			}
+			return d, nil
+		},
+	}
+	ManagerType.Dict["getLogger"] = py.MustNewMethod("getLogger", func(self, name py.Object) (py.Object, error) {
+		return self.(*Manager).GetLogger(name)
+	}, 0, "Get a logger with the specified name (channel name), creating it\nif it doesn't yet exist.")
+}
+
+// Check interface is satisfied
+var _ py.I__repr__ = (*Manager)(nil)
+
+const logger_doc = `Instances of the Logger class represent a single logging channel. A
+"logging channel" indicates an area of an application. Exactly how an
+"area" is defined is up to the application developer. Since an
+application can have any number of areas, logging channels are identified
+by a unique string. Application areas can be nested (e.g. an area
+of "input processing" might include sub-areas "read CSV files", "read
+XLS files" and "read Gnumeric files"). To cater for this natural nesting,
+channel names are organized into a namespace hierarchy where levels are
+separated by periods, much like the Java or Python package namespace. So
+in the instance given above, channel names might be "input" for the upper
+level, and "input.csv", "input.xls" and "input.gnu" for the sub-levels.
+There is no arbitrary limit to the depth of nesting.`
+
+// LoggerClass is the class of the loggers
+var LoggerClass = py.NewClass(py.TypeType, "logging", "Logger", logger_doc, py.Tuple{FiltererClass}, py.StringDict{
+	"__init__":          py.NewGoMethod("__init__", loggerInit),
+	"setLevel":          py.NewGoMethod1("setLevel", loggerSetLevel),
+	"debug":             levelMethod("debug", DEBUG),
+	"info":              levelMethod("info", INFO),
+	"warning":           levelMethod("warning", WARNING),
+	"warn":              levelMethod("warn", WARNING),
+	"error":             levelMethod("error", ERROR),
+	"critical":          levelMethod("critical", CRITICAL),
+	"fatal":             levelMethod("fatal", CRITICAL),
+	"exception":         py.NewGoMethod("exception", loggerException),
+	"log":               py.NewGoMethod("log", loggerLog),
+	"_log":              py.NewGoMethod("_log", logger_log),
+	"findCaller":        py.NewGoMethod("findCaller", loggerFindCaller),
+	"makeRecord":        py.NewGoMethod("makeRecord", loggerMakeRecord),
+	"handle":            py.NewGoMethod1("handle", loggerHandle),
+	"callHandlers":      py.NewGoMethod1("callHandlers", loggerCallHandlers),
+	"addHandler":        py.NewGoMethod1("addHandler", loggerAddHandler),
+	"removeHandler":     py.NewGoMethod1("removeHandler", loggerRemoveHandler),
+	"hasHandlers":       py.NewGoMethod0("hasHandlers", loggerHasHandlers),
+	"getEffectiveLevel": py.NewGoMethod0("getEffectiveLevel", loggerGetEffectiveLevel),
+	"isEnabledFor":      py.NewGoMethod1("isEnabledFor", loggerIsEnabledFor),
+	"getChild":          py.NewGoMethod1("getChild", loggerGetChild),
+	"__repr__":          py.NewGoMethod0("__repr__", loggerRepr),
+})
+
+func loggerInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name, level py.Object = nil, py.Int(NOTSET)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:Logger", []string{"name", "level"}, &name, &level)
+	if err != nil {
+		return nil, err
+	}
+	n, err := checkLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = filtererInit(self); err != nil {
+		return nil, err
+	}
+	attrs := []struct {
+		name  string
+		value py.Object
+	}{
+		{"name", name},
+		{"level", py.Int(n)},
+		{"parent", py.None},
+		{"propagate", py.True},
+		{"handlers", py.NewList()},
+		{"disabled", py.False},
+	}
+	for _, attr := range attrs {
+		if _, err = py.SetAttrString(self, attr.name, attr.value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+func loggerSetLevel(self, level py.Object) (py.Object, error) {
+	n, err := checkLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.SetAttrString(self, "level", py.Int(n))
+	return py.None, err
+}
+
+// Makes the method of Logger which logs at level
+func levelMethod(name string, level int) *py.GoMethod {
+	return py.NewGoMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if len(args) < 1 {
+			return nil, py.ExceptionNewf(py.TypeError, "%s() missing 1 required positional argument: 'msg'", name)
+		}
+		return logIfEnabled(self, py.Int(level), args[0], args[1:], kwargs)
+	})
+}
+
+// Calls self._log if self is enabled for level
+func logIfEnabled(self py.Object, level, msg py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	enabled, err := py.CallMethodByName(self, "isEnabledFor", level)
+	if err != nil {
+		return nil, err
+	}
+	if !py.IsTrue(enabled) {
+		return py.None, nil
+	}
+	log, err := py.GetAttrString(self, "_log")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(log, py.Tuple{level, msg, args}, kwargs)
+}
+
+func loggerException(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "exception() missing 1 required positional argument: 'msg'")
+	}
+	kw := kwargs.Copy()
+	if _, ok := kw["exc_info"]; !ok {
+		kw["exc_info"] = py.True
+	}
+	return logIfEnabled(self, py.Int(ERROR), args[0], args[1:], kw)
+}
+
+func loggerLog(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 2 {
+		return nil, py.ExceptionNewf(py.TypeError, "log() missing required positional arguments: 'level' and 'msg'")
+	}
+	if _, ok := args[0].(py.Int); !ok {
+		if raise := moduleGlobal("raiseExceptions"); raise != nil && py.IsTrue(raise) {
+			return nil, py.ExceptionNewf(py.TypeError, "level must be an integer")
+		}
+		return py.None, nil
+	}
+	return logIfEnabled(self, args[0], args[1], args[2:], kwargs)
+}
+
+// Finds the python frame which made the logging call, stacklevel
+// frames up from the caller of the logging method
+func findCaller(stacklevel int) *py.Frame {
+	f := py.CurrentFrame
+	for ; f != nil && stacklevel > 1; stacklevel-- {
+		if f.Back == nil {
+			break
+		}
+		f = f.Back
+	}
+	return f
+}
+
+// Returns the stack from the outermost frame down to f as text
+func formatStack(f *py.Frame) string {
+	var frames []*py.Frame
+	for ; f != nil; f = f.Back {
+		frames = append(frames, f)
+	}
+	var b strings.Builder
+	b.WriteString("Stack (most recent call last):\n")
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		lineno := int(f.LineNumber())
+		fmt.Fprintf(&b, "  File %q, line %d, in %s\n", f.Code.Filename, lineno, f.Code.Name)
+		if line := py.SourceLine(f.Code.Filename, lineno); line != "" {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Returns the filename, line number, function name and stack of the
+// frame which made the logging call
+func caller(stackInfo bool, stacklevel int) (py.Tuple, error) {
+	f := findCaller(stacklevel)
+	if f == nil {
+		return py.Tuple{py.String("(unknown file)"), py.Int(0), py.String("(unknown function)"), py.None}, nil
+	}
+	var sinfo py.Object = py.None
+	if stackInfo {
+		sinfo = py.String(formatStack(f))
+	}
+	return py.Tuple{py.String(f.Code.Filename), py.Int(f.LineNumber()), py.String(f.Code.Name), sinfo}, nil
+}
+
+func loggerFindCaller(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stackInfo, stacklevel py.Object = py.False, py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:findCaller", []string{"stack_info", "stacklevel"}, &stackInfo, &stacklevel)
+	if err != nil {
+		return nil, err
+	}
+	level, err := py.MakeGoInt(stacklevel)
+	if err != nil {
+		return nil, err
+	}
+	return caller(py.IsTrue(stackInfo), level)
+}
+
+// Turns the exc_info argument of a logging call into a tuple of the
+// type, value and traceback of the exception, or None
+func excInfoTuple(excInfo py.Object) py.Object {
+	if !py.IsTrue(excInfo) {
+		return py.None
+	}
+	switch x := excInfo.(type) {
+	case py.Tuple:
+		return x
+	case *py.Exception:
+		var tb py.Object = py.None
+		if x.Traceback != nil {
+			tb = x.Traceback
+		}
+		return py.Tuple{x.Base, x, tb}
+	}
+	exc := py.CurrentFrame.HandledException()
+	if exc == nil {
+		return py.Tuple{py.None, py.None, py.None}
+	}
+	var tb py.Object = py.None
+	if exc.Traceback != nil {
+		tb = exc.Traceback
+	}
+	return py.Tuple{exc.Type, exc.Value, tb}
+}
+
+// Makes a record and passes it to the handlers
+func logger_log(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var level, msg, logArgs py.Object
+	var excInfo, extra, stackInfo, stacklevel py.Object = py.None, py.None, py.False, py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO|OOOO:_log", []string{"level", "msg", "args", "exc_info", "extra", "stack_info", "stacklevel"}, &level, &msg, &logArgs, &excInfo, &extra, &stackInfo, &stacklevel)
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.MakeGoInt(stacklevel)
+	if err != nil {
+		return nil, err
+	}
+	where, err := caller(py.IsTrue(stackInfo), n)
+	if err != nil {
+		return nil, err
+	}
+	name, err := py.GetAttrString(self, "name")
+	if err != nil {
+		return nil, err
+	}
+	record, err := py.CallMethodByName(self, "makeRecord", name, level, where[0], where[1], msg, logArgs, excInfoTuple(excInfo), where[2], extra, where[3])
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.CallMethodByName(self, "handle", record)
+	return py.None, err
+}
+
+func loggerMakeRecord(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name, level, fn, lno, msg, recordArgs, excInfo py.Object
+	var funcName, extra, sinfo py.Object = py.None, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOOOOOO|OOO:makeRecord", []string{"name", "level", "fn", "lno", "msg", "args", "exc_info", "func", "extra", "sinfo"}, &name, &level, &fn, &lno, &msg, &recordArgs, &excInfo, &funcName, &extra, &sinfo)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := py.Call(recordFactory, py.Tuple{name, level, fn, lno, msg, recordArgs, excInfo, funcName, sinfo}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if extra == py.None {
+		return rv, nil
+	}
+	values, err := py.DictAsNamespace(extra)
+	if err != nil {
+		return nil, err
+	}
+	dictObj, err := py.GetAttrString(rv, "__dict__")
+	if err != nil {
+		return nil, err
+	}
+	dict, err := py.DictAsNamespace(dictObj)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		if _, ok := dict[key]; ok || key == "message" || key == "asctime" {
+			repr, err := py.ReprAsString(py.String(key))
+			if err != nil {
+				return nil, err
+			}
+			return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{py.String("Attempt to overwrite " + repr + " in LogRecord")}}
+		}
+		if _, err = py.SetAttrString(rv, key, value); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// Passes the record to the handlers if the logger isn't disabled and
+// its filters let it through
+func loggerHandle(self, record py.Object) (py.Object, error) {
+	disabled, err := py.GetAttrString(self, "disabled")
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(disabled) {
+		return py.None, nil
+	}
+	ok, err := filterRecord(self, record)
+	if err != nil || !ok {
+		return py.None, err
+	}
+	_, err = py.CallMethodByName(self, "callHandlers", record)
+	return py.None, err
+}
+
+// Passes the record to the handlers of the logger and its ancestors,
+// stopping at a logger which doesn't propagate
+func loggerCallHandlers(self, record py.Object) (py.Object, error) {
+	levelno, err := py.IntAttr(record, "levelno")
+	if err != nil {
+		return nil, err
+	}
+	// Passes the record to h if it is at h's level or above
+	handle := func(h py.Object) error {
+		level, err := py.IntAttr(h, "level")
+		if err != nil {
+			return err
+		}
+		if levelno >= level {
+			_, err = py.CallMethodByName(h, "handle", record)
+		}
+		return err
+	}
+	found := 0
+	for c := self; c != py.None; {
+		handlers, err := py.GetAttrString(c, "handlers")
+		if err != nil {
+			return nil, err
+		}
+		items, err := py.SequenceList(handlers)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range items.Items {
+			found++
+			if err = handle(h); err != nil {
+				return nil, err
+			}
+		}
+		propagate, err := py.GetAttrString(c, "propagate")
+		if err != nil {
+			return nil, err
+		}
+		if !py.IsTrue(propagate) {
+			break
+		}
+		if c, err = py.GetAttrString(c, "parent"); err != nil {
+			return nil, err
+		}
+	}
+	if found == 0 {
+		if lastResort := moduleGlobal("lastResort"); lastResort != nil && lastResort != py.None {
+			if err = handle(lastResort); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return py.None, nil
+}
+
+func loggerAddHandler(self, h py.Object) (py.Object, error) {
+	handlers, err := handlerList(self)
+	if err != nil {
+		return nil, err
+	}
+	if indexOf(handlers, h) < 0 {
+		handlers.Append(h)
+	}
+	return py.None, nil
+}
+
+func loggerRemoveHandler(self, h py.Object) (py.Object, error) {
+	handlers, err := handlerList(self)
+	if err != nil {
+		return nil, err
+	}
+	if i := indexOf(handlers, h); i >= 0 {
+		handlers.Items = append(handlers.Items[:i], handlers.Items[i+1:]...)
+	}
+	return py.None, nil
+}
+
+// Returns the handlers of a logger
+func handlerList(self py.Object) (*py.List, error) {
+	handlers, err := py.GetAttrString(self, "handlers")
+	if err != nil {
+		return nil, err
+	}
+	list, ok := handlers.(*py.List)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "handlers must be a list, not %s", handlers.Type().Name)
+	}
+	return list, nil
+}
+
+// Returns whether the logger or any ancestor it propagates to has
+// handlers
+func loggerHasHandlers(self py.Object) (py.Object, error) {
+	for c := self; c != py.None; {
+		handlers, err := py.GetAttrString(c, "handlers")
+		if err != nil {
+			return nil, err
+		}
+		if py.IsTrue(handlers) {
+			return py.True, nil
+		}
+		propagate, err := py.GetAttrString(c, "propagate")
+		if err != nil {
+			return nil, err
+		}
+		if !py.IsTrue(propagate) {
+			break
+		}
+		if c, err = py.GetAttrString(c, "parent"); err != nil {
+			return nil, err
+		}
+	}
+	return py.False, nil
+}
+
+// Returns the level of the logger or of the nearest ancestor with a
+// level set
+func effectiveLevel(self py.Object) (int, error) {
+	for c := self; c != py.None; {
+		level, err := py.IntAttr(c, "level")
+		if err != nil {
+			return 0, err
+		}
+		if level != NOTSET {
+			return level, nil
+		}
+		if c, err = py.GetAttrString(c, "parent"); err != nil {
+			return 0, err
+		}
+	}
+	return NOTSET, nil
+}
+
+func loggerGetEffectiveLevel(self py.Object) (py.Object, error) {
+	level, err := effectiveLevel(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(level), nil
+}
+
+func loggerIsEnabledFor(self, levelObj py.Object) (py.Object, error) {
+	level, err := py.MakeGoInt(levelObj)
+	if err != nil {
+		return nil, err
+	}
+	disabled, err := py.GetAttrString(self, "disabled")
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(disabled) {
+		return py.False, nil
+	}
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	if mgr.disable >= level {
+		return py.False, nil
+	}
+	effective, err := effectiveLevel(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(level >= effective), nil
+}
+
+// Returns the logger below this one called suffix
+func loggerGetChild(self, suffix py.Object) (py.Object, error) {
+	s, err := py.StrAsString(suffix)
+	if err != nil {
+		return nil, err
+	}
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	if self != mgr.root {
+		name, err := loggerName(self)
+		if err != nil {
+			return nil, err
+		}
+		s = name + "." + s
+	}
+	return mgr.GetLogger(py.String(s))
+}
+
+func loggerRepr(self py.Object) (py.Object, error) {
+	name, err := loggerName(self)
+	if err != nil {
+		return nil, err
+	}
+	level, err := effectiveLevel(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("<%s %s (%s)>", self.Type().Name, name, LevelName(level))), nil
+}
+
+const root_logger_doc = `A root logger is not that different to any other logger, except that
+it must have a logging level and there is only one instance of it in
+the hierarchy.`
+
+// RootLoggerClass is the class of the root of the logger hierarchy
+var RootLoggerClass = py.NewClass(py.TypeType, "logging", "RootLogger", root_logger_doc, py.Tuple{LoggerClass}, py.StringDict{
+	"__init__": py.NewGoMethod1("__init__", func(self, level py.Object) (py.Object, error) {
+		return loggerInit(self, py.Tuple{py.String("root"), level}, nil)
+	}),
+})
+
+const logger_adapter_doc = `An adapter for loggers which makes it easier to specify contextual
+information in logging output.`
+
+// LoggerAdapterClass wraps a logger adding extra to its records
+var LoggerAdapterClass = py.NewClass(py.TypeType, "logging", "LoggerAdapter", logger_adapter_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__init__": py.NewGoMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var logger, extra py.Object = nil, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:LoggerAdapter", []string{"logger", "extra"}, &logger, &extra)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.SetAttrString(self, "logger", logger); err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(self, "extra", extra)
+		return py.None, err
+	}),
+	"process": py.NewGoMethod("process", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var msg, kw py.Object
+		err := py.UnpackTuple(args, kwargs, "process", 2, 2, &msg, &kw)
+		if err != nil {
+			return nil, err
+		}
+		extra, err := py.GetAttrString(self, "extra")
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.SetItem(kw, py.String("extra"), extra); err != nil {
+			return nil, err
+		}
+		return py.Tuple{msg, kw}, nil
+	}),
+	"debug":             adapterLevelMethod("debug", DEBUG),
+	"info":              adapterLevelMethod("info", INFO),
+	"warning":           adapterLevelMethod("warning", WARNING),
+	"warn":              adapterLevelMethod("warn", WARNING),
+	"error":             adapterLevelMethod("error", ERROR),
+	"critical":          adapterLevelMethod("critical", CRITICAL),
+	"exception":         py.NewGoMethod("exception", adapterException),
+	"log":               py.NewGoMethod("log", adapterLog),
+	"isEnabledFor":      adapterDelegate("isEnabledFor"),
+	"setLevel":          adapterDelegate("setLevel"),
+	"getEffectiveLevel": adapterDelegate("getEffectiveLevel"),
+	"hasHandlers":       adapterDelegate("hasHandlers"),
+	"name": &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			logger, err := py.GetAttrString(self, "logger")
+			if err != nil {
+				return nil, err
+			}
+			return py.GetAttrString(logger, "name")
+		},
+	},
+	"manager": &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			logger, err := py.GetAttrString(self, "logger")
+			if err != nil {
+				return nil, err
+			}
+			return py.GetAttrString(logger, "manager")
+		},
+	},
+	"__repr__": py.NewGoMethod0("__repr__", func(self py.Object) (py.Object, error) {
+		logger, err := py.GetAttrString(self, "logger")
+		if err != nil {
+			return nil, err
+		}
+		name, err := loggerName(logger)
+		if err != nil {
+			return nil, err
+		}
+		level, err := effectiveLevel(logger)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("<%s %s (%s)>", self.Type().Name, name, LevelName(level))), nil
+	}),
+})
+
+// Makes the method of LoggerAdapter which calls the method name of
+// the logger
+func adapterDelegate(name string) *py.GoMethod {
+	return py.NewGoMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		logger, err := py.GetAttrString(self, "logger")
+		if err != nil {
+			return nil, err
+		}
+		method, err := py.GetAttrString(logger, name)
+		if err != nil {
+			return nil, err
+		}
+		return py.Call(method, args, kwargs)
+	})
+}
+
+// Makes the method of LoggerAdapter which logs at level
+func adapterLevelMethod(name string, level int) *py.GoMethod {
+	return py.NewGoMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return adapterLog(self, append(py.Tuple{py.Int(level)}, args...), kwargs)
+	})
+}
+
+func adapterException(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	kw := kwargs.Copy()
+	if _, ok := kw["exc_info"]; !ok {
+		kw["exc_info"] = py.True
+	}
+	return adapterLog(self, append(py.Tuple{py.Int(ERROR)}, args...), kw)
+}
+
+// Processes the message and keyword arguments then logs them with the
+// logger
+func adapterLog(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 2 {
+		return nil, py.ExceptionNewf(py.TypeError, "log() missing required positional arguments: 'level' and 'msg'")
+	}
+	logger, err := py.GetAttrString(self, "logger")
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := py.CallMethodByName(logger, "isEnabledFor", args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !py.IsTrue(enabled) {
+		return py.None, nil
+	}
+	kw := py.NewDict()
+	for key, value := range kwargs {
+		kw.M__setitem__(py.String(key), value)
+	}
+	res, err := py.CallMethodByName(self, "process", args[1], kw)
+	if err != nil {
+		return nil, err
+	}
+	processed, ok := res.(py.Tuple)
+	if !ok || len(processed) != 2 {
+		return nil, py.ExceptionNewf(py.TypeError, "process() must return a tuple of (msg, kwargs)")
+	}
+	newKwargs, err := py.DictAsNamespace(processed[1])
+	if err != nil {
+		return nil, err
+	}
+	log, err := py.GetAttrString(logger, "log")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(log, append(py.Tuple{args[0], processed[0]}, args[2:]...), newKwargs)
+}
@@ -0,0 +1,584 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Logging module
+//
+// Logger, Handler, Formatter and the rest are python classes made in
+// Go so python code can subclass them and override their methods as
+// it would in CPython.  Their state is kept in the instance
+// dictionaries under the same names as CPython uses.
+//
+// Each Context has its own hierarchy of loggers, held by the Manager
+// made when logging is first imported into it.  The level names and
+// the record factory are shared by all contexts.
+
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const module_doc = `Logging package for Python.
+
+Loggers are arranged in a hierarchy by their dotted names, records
+logged to one are passed to its handlers and those of its ancestors,
+and the handlers format the records and write them out.`
+
+// ------------------------------------------------------------
+// Levels
+
+const (
+	CRITICAL = 50
+	ERROR    = 40
+	WARNING  = 30
+	INFO     = 20
+	DEBUG    = 10
+	NOTSET   = 0
+)
+
+var (
+	levelToName = map[int]string{
+		CRITICAL: "CRITICAL",
+		ERROR:    "ERROR",
+		WARNING:  "WARNING",
+		INFO:     "INFO",
+		DEBUG:    "DEBUG",
+		NOTSET:   "NOTSET",
+	}
+	nameToLevel = map[string]int{
+		"CRITICAL": CRITICAL,
+		"FATAL":    CRITICAL,
+		"ERROR":    ERROR,
+		"WARN":     WARNING,
+		"WARNING":  WARNING,
+		"INFO":     INFO,
+		"DEBUG":    DEBUG,
+		"NOTSET":   NOTSET,
+	}
+)
+
+// LevelName returns the name of a level
+func LevelName(level int) string {
+	if name, ok := levelToName[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("Level %d", level)
+}
+
+// Converts a level given as an int or a level name to an int
+func checkLevel(level py.Object) (int, error) {
+	switch x := level.(type) {
+	case py.Int:
+		return int(x), nil
+	case py.String:
+		if n, ok := nameToLevel[string(x)]; ok {
+			return n, nil
+		}
+		repr, err := py.ReprAsString(x)
+		if err != nil {
+			return 0, err
+		}
+		return 0, py.ExceptionNewf(py.ValueError, "Unknown level: %s", repr)
+	}
+	repr, err := py.ReprAsString(level)
+	if err != nil {
+		return 0, err
+	}
+	return 0, py.ExceptionNewf(py.TypeError, "Level not an integer or a valid string: %s", repr)
+}
+
+const get_level_name_doc = `Return the textual or numeric representation of logging level 'level'.
+
+If the level is one of the predefined levels (CRITICAL, ERROR, WARNING,
+INFO, DEBUG) then you get the corresponding string. If you have
+associated levels with names using addLevelName then the name you have
+associated with 'level' is returned.
+
+If a numeric value corresponding to one of the defined levels is passed
+in, the corresponding string representation is returned.
+
+If a string representation of the level is passed in, the corresponding
+numeric value is returned.
+
+If no matching numeric or string value is passed in, the string
+'Level %s' % level is returned.`
+
+func logging_getLevelName(self, level py.Object) (py.Object, error) {
+	switch x := level.(type) {
+	case py.Int:
+		if name, ok := levelToName[int(x)]; ok {
+			return py.String(name), nil
+		}
+	case py.String:
+		if n, ok := nameToLevel[string(x)]; ok {
+			return py.Int(n), nil
+		}
+	}
+	str, err := py.StrAsString(level)
+	if err != nil {
+		s, err := py.Str(level)
+		if err != nil {
+			return nil, err
+		}
+		str = string(s.(py.String))
+	}
+	return py.String("Level " + str), nil
+}
+
+const add_level_name_doc = `Associate 'levelName' with 'level'.
+
+This is used when converting levels to text during message formatting.`
+
+func logging_addLevelName(self py.Object, args py.Tuple) (py.Object, error) {
+	var levelObj, nameObj py.Object
+	err := py.UnpackTuple(args, nil, "addLevelName", 2, 2, &levelObj, &nameObj)
+	if err != nil {
+		return nil, err
+	}
+	level, err := py.MakeGoInt(levelObj)
+	if err != nil {
+		return nil, err
+	}
+	name, err := py.StrAsString(nameObj)
+	if err != nil {
+		return nil, err
+	}
+	levelToName[level] = name
+	nameToLevel[name] = level
+	return py.None, nil
+}
+
+const get_level_names_mapping_doc = `Return a dict mapping the level names to their levels.`
+
+func logging_getLevelNamesMapping(self py.Object) (py.Object, error) {
+	d := py.NewDict()
+	names := make([]string, 0, len(nameToLevel))
+	for name := range nameToLevel {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d.M__setitem__(py.String(name), py.Int(nameToLevel[name]))
+	}
+	return d, nil
+}
+
+// ------------------------------------------------------------
+// Module functions
+
+const BASIC_FORMAT = "%(levelname)s:%(name)s:%(message)s"
+
+// Returns the manager of the loggers of the current context
+func currentManager() (*Manager, error) {
+	m, err := py.CurrentContext.GetModule("logging")
+	if err != nil {
+		return nil, err
+	}
+	return m.Globals["_manager"].(*Manager), nil
+}
+
+// Returns the value of the module global name in the current context
+func moduleGlobal(name string) py.Object {
+	m, err := py.CurrentContext.GetModule("logging")
+	if err != nil {
+		return nil
+	}
+	return m.Globals[name]
+}
+
+const get_logger_doc = `Return a logger with the specified name, creating it if necessary.
+
+If no name is specified, return the root logger.`
+
+func logging_getLogger(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:getLogger", []string{"name"}, &name)
+	if err != nil {
+		return nil, err
+	}
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	if name == py.None || name == py.String("") {
+		return mgr.root, nil
+	}
+	return mgr.GetLogger(name)
+}
+
+const get_logger_class_doc = `Return the class to be used when instantiating a logger.`
+
+func logging_getLoggerClass(self py.Object) (py.Object, error) {
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	return mgr.loggerClass, nil
+}
+
+const set_logger_class_doc = `Set the class to be used when instantiating a logger.
+
+The class should define __init__() such that only a name argument is
+required, and the __init__() should call Logger.__init__()`
+
+func logging_setLoggerClass(self, klass py.Object) (py.Object, error) {
+	cls, ok := klass.(*py.Type)
+	if !ok || !cls.IsSubtype(LoggerClass) {
+		name := ""
+		if ok {
+			name = cls.Name
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "logger not derived from logging.Logger: %s", name)
+	}
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	mgr.loggerClass = cls
+	return py.None, nil
+}
+
+// Logs a message with the root logger, configuring it first if it
+// has no handlers
+func rootLog(method string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	handlers, err := py.GetAttrString(mgr.root, "handlers")
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.Len(handlers)
+	if err != nil {
+		return nil, err
+	}
+	if n == py.Int(0) {
+		_, err = logging_basicConfig(nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fn, err := py.GetAttrString(mgr.root, method)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, args, kwargs)
+}
+
+// Makes a module function which logs with the root logger
+func rootLogFunc(name, method, doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return rootLog(method, args, kwargs)
+	}, 0, doc)
+}
+
+const basic_config_doc = `Do basic configuration for the logging system.
+
+This function does nothing if the root logger already has handlers
+configured, unless the keyword argument *force* is set to True.
+It is a convenience method intended for use by simple scripts
+to do one-shot configuration of the logging package.
+
+The default behaviour is to create a StreamHandler which writes to
+sys.stderr, set a formatter using the BASIC_FORMAT format string, and
+add the handler to the root logger.
+
+A number of optional keyword arguments may be specified, which can alter
+the default behaviour.
+
+filename  Specifies that a FileHandler be created, using the specified
+          filename, rather than a StreamHandler.
+filemode  Specifies the mode to open the file, if filename is specified
+          (if filemode is unspecified, it defaults to 'a').
+format    Use the specified format string for the handler.
+datefmt   Use the specified date/time format.
+style     If a format string is specified, use this to specify the
+          type of format string (possible values '%', '{', '$', for
+          %-formatting, :meth:` + "`str.format`" + ` and :class:` + "`string.Template`" + `
+          - defaults to '%').
+level     Set the root logger level to the specified level.
+stream    Use the specified stream to initialize the StreamHandler. Note
+          that this argument is incompatible with 'filename' - if both
+          are present, 'stream' is ignored.
+handlers  If specified, this should be an iterable of already created
+          handlers, which will be added to the root logger. Any handler
+          in the list which does not have a formatter assigned will be
+          assigned the formatter created in this function.
+force     If this keyword is specified as true, any existing handlers
+          attached to the root logger are removed and closed, before
+          carrying out the configuration as specified by the other
+          arguments.
+encoding  If specified together with a filename, this encoding is passed to
+          the created FileHandler, causing it to be used when the file is
+          opened.
+errors    If specified together with a filename, this value is passed to the
+          created FileHandler, causing it to be used when the file is
+          opened in text mode. If not specified, the default value is
+          ` + "`backslashreplace`" + `.`
+
+func logging_basicConfig(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) != 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "basicConfig() takes 0 positional arguments but %d were given", len(args))
+	}
+	kw := kwargs.Copy()
+	pop := func(name string, def py.Object) py.Object {
+		if value, ok := kw[name]; ok {
+			delete(kw, name)
+			return value
+		}
+		return def
+	}
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	root := mgr.root
+	rootHandlers, err := py.GetAttrString(root, "handlers")
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(pop("force", py.False)) {
+		items, err := py.SequenceList(rootHandlers)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range items.Items {
+			if _, err = py.CallMethodByName(root, "removeHandler", h); err != nil {
+				return nil, err
+			}
+			if _, err = py.CallMethodByName(h, "close"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	n, err := py.Len(rootHandlers)
+	if err != nil {
+		return nil, err
+	}
+	if n != py.Int(0) {
+		return py.None, nil
+	}
+	handlers := pop("handlers", py.None)
+	stream := pop("stream", py.None)
+	filename := pop("filename", py.None)
+	if handlers == py.None {
+		if stream != py.None && filename != py.None {
+			return nil, py.ExceptionNewf(py.ValueError, "'stream' and 'filename' should not be specified together")
+		}
+	} else if stream != py.None || filename != py.None {
+		return nil, py.ExceptionNewf(py.ValueError, "'stream' or 'filename' should not be specified together with 'handlers'")
+	}
+	filemode := pop("filemode", py.String("a"))
+	encoding := pop("encoding", py.None)
+	errors := pop("errors", py.String("backslashreplace"))
+	if handlers == py.None {
+		var h py.Object
+		if filename != py.None {
+			if strings.Contains(string(filemode.(py.String)), "b") {
+				errors = py.None
+			}
+			h, err = py.Call(FileHandlerClass, py.Tuple{filename, filemode}, py.StringDict{"encoding": encoding, "errors": errors})
+		} else {
+			h, err = py.Call(StreamHandlerClass, py.Tuple{stream}, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		handlers = py.NewListFromItems([]py.Object{h})
+	}
+	handlerList, err := py.SequenceList(handlers)
+	if err != nil {
+		return nil, err
+	}
+	datefmt := pop("datefmt", py.None)
+	style := pop("style", py.String("%"))
+	styleStr, ok := style.(py.String)
+	if !ok || !strings.Contains("%{$", string(styleStr)) || len(styleStr) != 1 {
+		return nil, py.ExceptionNewf(py.ValueError, "Style must be one of: %%,{,$")
+	}
+	format := pop("format", py.String(basicFormats[string(styleStr)]))
+	fmtr, err := py.Call(FormatterClass, py.Tuple{format, datefmt, style}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range handlerList.Items {
+		formatter, err := py.GetAttrString(h, "formatter")
+		if err != nil {
+			return nil, err
+		}
+		if formatter == py.None {
+			if _, err = py.CallMethodByName(h, "setFormatter", fmtr); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = py.CallMethodByName(root, "addHandler", h); err != nil {
+			return nil, err
+		}
+	}
+	if level := pop("level", py.None); level != py.None {
+		if _, err = py.CallMethodByName(root, "setLevel", level); err != nil {
+			return nil, err
+		}
+	}
+	if len(kw) != 0 {
+		keys := make([]string, 0, len(kw))
+		for key := range kw {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, py.ExceptionNewf(py.ValueError, "Unrecognised argument(s): %s", strings.Join(keys, ", "))
+	}
+	return py.None, nil
+}
+
+const disable_doc = `Disable all logging calls of severity 'level' and below.`
+
+func logging_disable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var level py.Object = py.Int(CRITICAL)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:disable", []string{"level"}, &level)
+	if err != nil {
+		return nil, err
+	}
+	n, err := checkLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	mgr.disable = n
+	return py.None, nil
+}
+
+const shutdown_doc = `Perform any cleanup actions in the logging system (e.g. flushing
+buffers).
+
+Should be called at application exit.`
+
+func logging_shutdown(self py.Object) (py.Object, error) {
+	mgr, err := currentManager()
+	if err != nil {
+		return nil, err
+	}
+	return py.None, mgr.shutdown()
+}
+
+const get_log_record_factory_doc = `Return the factory to be used when instantiating a log record.`
+
+func logging_getLogRecordFactory(self py.Object) (py.Object, error) {
+	return recordFactory, nil
+}
+
+const set_log_record_factory_doc = `Set the factory to be used when instantiating a log record.
+
+:param factory: A callable which will be called to instantiate
+a log record.`
+
+func logging_setLogRecordFactory(self, factory py.Object) (py.Object, error) {
+	recordFactory = factory
+	return py.None, nil
+}
+
+const make_log_record_doc = `Make a LogRecord whose attributes are defined by the specified dictionary,
+This function is useful for converting a logging event received over
+a socket connection (which is sent as a dictionary) into a LogRecord
+instance.`
+
+func logging_makeLogRecord(self, dict py.Object) (py.Object, error) {
+	rv, err := py.Call(recordFactory, py.Tuple{py.None, py.Int(NOTSET), py.String(""), py.Int(0), py.String(""), py.Tuple{}, py.None}, nil)
+	if err != nil {
+		return nil, err
+	}
+	d, err := py.DictAsNamespace(dict)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range d {
+		if _, err = py.SetAttrString(rv, key, value); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// Sets up the loggers of the context
+func loggingInit(ctx *py.Context, m *py.Module) error {
+	mgr, err := newManager()
+	if err != nil {
+		return err
+	}
+	m.Globals["_manager"] = mgr
+	m.Globals["root"] = mgr.root
+	lastResort, err := py.Call(stderrHandlerClass, py.Tuple{py.Int(WARNING)}, nil)
+	if err != nil {
+		return err
+	}
+	m.Globals["lastResort"] = lastResort
+	return nil
+}
+
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("getLogger", logging_getLogger, 0, get_logger_doc),
+		py.MustNewMethod("getLoggerClass", logging_getLoggerClass, 0, get_logger_class_doc),
+		py.MustNewMethod("setLoggerClass", logging_setLoggerClass, 0, set_logger_class_doc),
+		py.MustNewMethod("getLevelName", logging_getLevelName, 0, get_level_name_doc),
+		py.MustNewMethod("getLevelNamesMapping", logging_getLevelNamesMapping, 0, get_level_names_mapping_doc),
+		py.MustNewMethod("addLevelName", logging_addLevelName, 0, add_level_name_doc),
+		py.MustNewMethod("basicConfig", logging_basicConfig, 0, basic_config_doc),
+		py.MustNewMethod("disable", logging_disable, 0, disable_doc),
+		py.MustNewMethod("shutdown", logging_shutdown, 0, shutdown_doc),
+		py.MustNewMethod("getLogRecordFactory", logging_getLogRecordFactory, 0, get_log_record_factory_doc),
+		py.MustNewMethod("setLogRecordFactory", logging_setLogRecordFactory, 0, set_log_record_factory_doc),
+		py.MustNewMethod("makeLogRecord", logging_makeLogRecord, 0, make_log_record_doc),
+		rootLogFunc("critical", "critical", "Log a message with severity 'CRITICAL' on the root logger."),
+		rootLogFunc("fatal", "critical", "Don't use this function, use critical() instead."),
+		rootLogFunc("error", "error", "Log a message with severity 'ERROR' on the root logger."),
+		rootLogFunc("exception", "exception", "Log a message with severity 'ERROR' on the root logger, with exception\ninformation."),
+		rootLogFunc("warning", "warning", "Log a message with severity 'WARNING' on the root logger."),
+		rootLogFunc("warn", "warning", "Don't use this function, use warning() instead."),
+		rootLogFunc("info", "info", "Log a message with severity 'INFO' on the root logger."),
+		rootLogFunc("debug", "debug", "Log a message with severity 'DEBUG' on the root logger."),
+		rootLogFunc("log", "log", "Log 'msg % args' with the integer severity 'level' on the root logger."),
+	}
+	globals := py.StringDict{
+		"CRITICAL":          py.Int(CRITICAL),
+		"FATAL":             py.Int(CRITICAL),
+		"ERROR":             py.Int(ERROR),
+		"WARNING":           py.Int(WARNING),
+		"WARN":              py.Int(WARNING),
+		"INFO":              py.Int(INFO),
+		"DEBUG":             py.Int(DEBUG),
+		"NOTSET":            py.Int(NOTSET),
+		"BASIC_FORMAT":      py.String(BASIC_FORMAT),
+		"raiseExceptions":   py.True,
+		"LogRecord":         LogRecordClass,
+		"Filterer":          FiltererClass,
+		"Filter":            FilterClass,
+		"Formatter":         FormatterClass,
+		"Handler":           HandlerClass,
+		"StreamHandler":     StreamHandlerClass,
+		"FileHandler":       FileHandlerClass,
+		"NullHandler":       NullHandlerClass,
+		"Logger":            LoggerClass,
+		"RootLogger":        RootLoggerClass,
+		"LoggerAdapter":     LoggerAdapterClass,
+		"Manager":           ManagerType,
+		"_StderrHandler":    stderrHandlerClass,
+		"_defaultFormatter": defaultFormatter,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "logging",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+		Init:    loggingInit,
+	})
+}
@@ -0,0 +1,17 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging_test
+
+import (
+	"testing"
+
+	_ "github.com/go-python/gpython/io"
+	_ "github.com/go-python/gpython/os"
+	"github.com/go-python/gpython/pytest"
+)
+
+func TestLogging(t *testing.T) {
+	pytest.RunTests(t, "tests")
+}
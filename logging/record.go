@@ -0,0 +1,151 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// LogRecord
+
+package logging
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+// When the logging package was loaded, which relativeCreated counts
+// from
+var startTime = time.Now()
+
+const log_record_doc = `A LogRecord instance represents an event being logged.
+
+LogRecord instances are created every time something is logged. They
+contain all the information pertinent to the event being logged. The
+main information passed in is in msg and args, which are combined
+using str(msg) % args to create the message field of the record. The
+record also includes information such as when the record was created,
+the source line where the logging call was made, and any exception
+information to be logged.`
+
+// LogRecordClass is the class of the records passed to the handlers
+var LogRecordClass = py.NewClass(py.TypeType, "logging", "LogRecord", log_record_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__init__":   py.NewGoMethod("__init__", logRecordInit),
+	"getMessage": py.NewGoMethod0("getMessage", logRecordGetMessage),
+	"__repr__":   py.NewGoMethod0("__repr__", logRecordRepr),
+})
+
+// The callable which makes the records, set by setLogRecordFactory
+var recordFactory py.Object = LogRecordClass
+
+// Returns the name of the running thread, which is only known if the
+// threading module has been imported
+func threadName() py.Object {
+	if threading, ok := py.CurrentContext.Modules["threading"]; ok {
+		thread, err := py.CallMethodByName(threading, "current_thread")
+		if err == nil {
+			name, err := py.GetAttrString(thread, "name")
+			if err == nil {
+				return name
+			}
+		}
+	}
+	return py.String("MainThread")
+}
+
+func logRecordInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name, levelObj, pathname, lineno, msg, recordArgs, excInfo py.Object
+	var funcName, sinfo py.Object = py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOOOOOO|OO:LogRecord", []string{"name", "level", "pathname", "lineno", "msg", "args", "exc_info", "func", "sinfo"}, &name, &levelObj, &pathname, &lineno, &msg, &recordArgs, &excInfo, &funcName, &sinfo)
+	if err != nil {
+		return nil, err
+	}
+	level, err := py.MakeGoInt(levelObj)
+	if err != nil {
+		return nil, err
+	}
+	// A single mapping argument is used for %(name)s formatting
+	if t, ok := recordArgs.(py.Tuple); ok && len(t) == 1 {
+		if d, ok := t[0].(*py.Dict); ok && d.Len() > 0 {
+			recordArgs = d
+		}
+	}
+	filename, module := py.Object(pathname), py.Object(py.String("Unknown module"))
+	if s, ok := pathname.(py.String); ok {
+		base := filepath.Base(string(s))
+		filename = py.String(base)
+		module = py.String(strings.TrimSuffix(base, filepath.Ext(base)))
+	}
+	now := time.Now()
+	created := float64(now.UnixNano()) / 1e9
+	attrs := []struct {
+		name  string
+		value py.Object
+	}{
+		{"name", name},
+		{"msg", msg},
+		{"args", recordArgs},
+		{"levelname", py.String(LevelName(level))},
+		{"levelno", py.Int(level)},
+		{"pathname", pathname},
+		{"filename", filename},
+		{"module", module},
+		{"exc_info", excInfo},
+		{"exc_text", py.None},
+		{"stack_info", sinfo},
+		{"lineno", lineno},
+		{"funcName", funcName},
+		{"created", py.Float(created)},
+		{"msecs", py.Float(math.Floor((created - math.Floor(created)) * 1000))},
+		{"relativeCreated", py.Float(float64(now.Sub(startTime)) / float64(time.Millisecond))},
+		{"thread", py.Int(vm.ThreadIdent())},
+		{"threadName", threadName()},
+		{"processName", py.String("MainProcess")},
+		{"process", py.Int(os.Getpid())},
+	}
+	for _, attr := range attrs {
+		if _, err = py.SetAttrString(self, attr.name, attr.value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Returns the message of the record with the arguments merged in
+func logRecordGetMessage(self py.Object) (py.Object, error) {
+	msg, err := py.GetAttrString(self, "msg")
+	if err != nil {
+		return nil, err
+	}
+	str, err := py.Str(msg)
+	if err != nil {
+		return nil, err
+	}
+	args, err := py.GetAttrString(self, "args")
+	if err != nil {
+		return nil, err
+	}
+	if !py.IsTrue(args) {
+		return str, nil
+	}
+	return py.Mod(str, args)
+}
+
+func logRecordRepr(self py.Object) (py.Object, error) {
+	var values [5]interface{}
+	for i, name := range []string{"name", "levelno", "pathname", "lineno", "msg"} {
+		value, err := py.GetAttrString(self, name)
+		if err != nil {
+			return nil, err
+		}
+		values[i], err = py.Str(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.String(fmt.Sprintf("<LogRecord: %s, %s, %s, %s, \"%s\">", values[:]...)), nil
+}
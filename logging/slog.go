@@ -0,0 +1,198 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+// Bridge to log/slog
+//
+// A program embedding gpython can send the records logged by python
+// code to its own log/slog pipeline by adding a handler wrapping its
+// slog.Handler to the root logger with AddSlogHandler.
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+var goSlogHandlerType = py.NewType("slog_handler", "A Go log/slog handler")
+
+// Holds the slog.Handler of a SlogHandler instance
+type goSlogHandler struct {
+	h slog.Handler
+}
+
+// Type of this object
+func (h *goSlogHandler) Type() *py.Type {
+	return goSlogHandlerType
+}
+
+// The attributes every record has, so aren't passed to slog as extra
+// attributes
+var recordAttrs = map[string]bool{
+	"name": true, "msg": true, "args": true, "levelname": true,
+	"levelno": true, "pathname": true, "filename": true, "module": true,
+	"exc_info": true, "exc_text": true, "stack_info": true, "lineno": true,
+	"funcName": true, "created": true, "msecs": true,
+	"relativeCreated": true, "thread": true, "threadName": true,
+	"processName": true, "process": true, "message": true, "asctime": true,
+}
+
+// SlogHandlerClass is the class of the handlers which pass the
+// records to a Go slog.Handler
+var SlogHandlerClass = py.NewClass(py.TypeType, "logging", "SlogHandler", "A handler class which passes the records to a Go log/slog handler.", py.Tuple{HandlerClass}, py.StringDict{
+	"emit": py.NewGoMethod1("emit", slogHandlerEmit),
+})
+
+// SlogLevel returns the slog level of a logging level
+//
+// DEBUG, INFO, WARNING and ERROR map to the slog levels of the same
+// name with the levels in between scaled to fit.
+func SlogLevel(level int) slog.Level {
+	return slog.Level((level - INFO) * 2 / 5)
+}
+
+// Converts a python value to the value of a slog attribute
+func slogValue(value py.Object) (slog.Value, error) {
+	switch x := value.(type) {
+	case py.String:
+		return slog.StringValue(string(x)), nil
+	case py.Bool:
+		return slog.BoolValue(bool(x)), nil
+	case py.Int:
+		return slog.Int64Value(int64(x)), nil
+	case py.Float:
+		return slog.Float64Value(float64(x)), nil
+	}
+	str, err := py.StrAsString(value)
+	if err != nil {
+		return slog.Value{}, err
+	}
+	return slog.StringValue(str), nil
+}
+
+// Makes the slog record for a python record
+func slogRecord(self, record py.Object) (slog.Record, error) {
+	levelno, err := py.IntAttr(record, "levelno")
+	if err != nil {
+		return slog.Record{}, err
+	}
+	createdObj, err := py.GetAttrString(record, "created")
+	if err != nil {
+		return slog.Record{}, err
+	}
+	created, err := py.FloatAsFloat64(createdObj)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	msg, err := py.CallMethodByName(self, "format", record)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	msgStr, err := py.StrAsString(msg)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	secs, frac := math.Modf(created)
+	r := slog.NewRecord(time.Unix(int64(secs), int64(frac*1e9)), SlogLevel(levelno), msgStr, 0)
+	name, err := loggerName(record)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	r.AddAttrs(slog.String("logger", name))
+	dictObj, err := py.GetAttrString(record, "__dict__")
+	if err != nil {
+		return slog.Record{}, err
+	}
+	dict, err := py.DictAsNamespace(dictObj)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	// Pass the attributes added by extra in name order
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		if !recordAttrs[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := slogValue(dict[key])
+		if err != nil {
+			return slog.Record{}, err
+		}
+		r.AddAttrs(slog.Attr{Key: key, Value: value})
+	}
+	return r, nil
+}
+
+func slogHandlerEmit(self, record py.Object) (py.Object, error) {
+	handlerObj, err := py.GetAttrString(self, "_handler")
+	if err != nil {
+		return nil, err
+	}
+	h, ok := handlerObj.(*goSlogHandler)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "SlogHandler has no Go handler")
+	}
+	err = func() error {
+		r, err := slogRecord(self, record)
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		if !h.h.Enabled(ctx, r.Level) {
+			return nil
+		}
+		if err = h.h.Handle(ctx, r); err != nil {
+			return py.ExceptionNewf(py.OSError, "%v", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		return nil, handleError(self, record, err)
+	}
+	return py.None, nil
+}
+
+// NewSlogHandler makes a logging handler which passes the records to
+// h
+//
+// The message of the slog record is the record formatted by the
+// handler's formatter.  The logger name and any attributes added with
+// extra are passed as slog attributes.
+func NewSlogHandler(h slog.Handler) (py.Object, error) {
+	handler, err := py.Call(SlogHandlerClass, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = py.SetAttrString(handler, "_handler", &goSlogHandler{h: h}); err != nil {
+		return nil, err
+	}
+	return handler, nil
+}
+
+// AddSlogHandler adds a handler passing the records to h to the root
+// logger of ctx, so everything logged by python code running in ctx
+// ends up in h
+func AddSlogHandler(ctx *py.Context, h slog.Handler) error {
+	m, err := ctx.GetModule("logging")
+	if err != nil {
+		return err
+	}
+	handler, err := NewSlogHandler(h)
+	if err != nil {
+		return err
+	}
+	mgr := m.Globals["_manager"].(*Manager)
+	_, err = py.CallMethodByName(mgr.root, "addHandler", handler)
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package logging_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/logging"
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	const prog = `
+import logging
+log = logging.getLogger("app.db")
+log.setLevel(logging.DEBUG)
+log.debug("connecting to %s", "db1")
+log.warning("slow query", extra={"ms": 250, "table": "users"})
+log.log(25, "between")
+`
+	obj, err := compile.Compile(prog, "<slog>", "exec", 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := py.NewContext()
+	py.RunInContext(ctx, func() {
+		if err = logging.AddSlogHandler(ctx, h); err != nil {
+			return
+		}
+		globals := py.NewStringDict()
+		_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `level=DEBUG msg="connecting to db1" logger=app.db
+level=WARN msg="slow query" logger=app.db ms=250 table=users
+level=INFO+2 msg=between logger=app.db
+`
+	if got := buf.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	for _, test := range []struct {
+		level int
+		want  slog.Level
+	}{
+		{logging.DEBUG, slog.LevelDebug},
+		{logging.INFO, slog.LevelInfo},
+		{logging.WARNING, slog.LevelWarn},
+		{logging.ERROR, slog.LevelError},
+		{logging.CRITICAL, slog.LevelError + 4},
+	} {
+		if got := logging.SlogLevel(test.level); got != test.want {
+			t.Errorf("SlogLevel(%d): want %v got %v", test.level, test.want, got)
+		}
+	}
+}
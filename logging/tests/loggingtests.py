@@ -0,0 +1,308 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import logging
+import io
+import sys
+from libtest import *
+
+def handler(level=logging.NOTSET, fmt="%(levelname)s:%(name)s:%(message)s"):
+    stream = io.StringIO()
+    h = logging.StreamHandler(stream)
+    h.setLevel(level)
+    h.setFormatter(logging.Formatter(fmt))
+    return h, stream
+
+doc = "levels"
+assert logging.DEBUG == 10
+assert logging.INFO == 20
+assert logging.WARNING == logging.WARN == 30
+assert logging.ERROR == 40
+assert logging.CRITICAL == logging.FATAL == 50
+assert logging.getLevelName(logging.INFO) == "INFO"
+assert logging.getLevelName("ERROR") == 40
+assert logging.getLevelName(15) == "Level 15"
+logging.addLevelName(25, "NOTICE")
+assert logging.getLevelName(25) == "NOTICE"
+assert logging.getLevelName("NOTICE") == 25
+assert logging.getLevelNamesMapping()["NOTICE"] == 25
+
+doc = "getLogger caching and hierarchy"
+root = logging.getLogger()
+assert root is logging.root
+assert root is logging.getLogger("")
+assert root.name == "root"
+assert root.level == logging.WARNING
+assert repr(root) == "<RootLogger root (WARNING)>"
+abc = logging.getLogger("a.b.c")
+assert abc is logging.getLogger("a.b.c")
+assert abc.parent is root
+a = logging.getLogger("a")
+assert abc.parent is a
+assert a.parent is root
+ab = logging.getLogger("a.b")
+assert abc.parent is ab
+assert ab.parent is a
+assert a.getChild("b") is ab
+assert root.getChild("a") is a
+assert abc.getEffectiveLevel() == logging.WARNING
+assert repr(abc) == "<Logger a.b.c (WARNING)>"
+assert isinstance(abc, logging.Logger)
+assert "a.b" in logging.root.manager.loggerDict
+assertRaisesText(TypeError, "A logger name must be a string", logging.getLogger, 1)
+
+doc = "levels and propagation"
+h, stream = handler()
+a.addHandler(h)
+a.setLevel(logging.INFO)
+abc.debug("hidden")
+abc.info("shown %d", 1)
+ab.warning("warned %s", "here")
+assert stream.getvalue() == "INFO:a.b.c:shown 1\nWARNING:a.b:warned here\n", stream.getvalue()
+assert abc.isEnabledFor(logging.INFO)
+assert not abc.isEnabledFor(logging.DEBUG)
+ab.setLevel("DEBUG")
+assert abc.getEffectiveLevel() == logging.DEBUG
+assertRaisesText(ValueError, "Unknown level: 'LOUD'", ab.setLevel, "LOUD")
+
+stream.truncate(0)
+stream.seek(0)
+h2, stream2 = handler()
+ab.addHandler(h2)
+ab.propagate = False
+abc.info("stops at a.b")
+assert stream.getvalue() == ""
+assert stream2.getvalue() == "INFO:a.b.c:stops at a.b\n"
+ab.propagate = True
+ab.removeHandler(h2)
+assert ab.handlers == []
+assert ab.hasHandlers()
+
+doc = "handler levels"
+stream.truncate(0)
+stream.seek(0)
+h.setLevel(logging.ERROR)
+a.warning("dropped")
+a.error("kept")
+a.log(logging.CRITICAL, "logged %s", "too")
+assert stream.getvalue() == "ERROR:a:kept\nCRITICAL:a:logged too\n", stream.getvalue()
+assert repr(h) == "<StreamHandler (ERROR)>"
+h.setLevel(logging.NOTSET)
+
+doc = "filters"
+stream.truncate(0)
+stream.seek(0)
+f = logging.Filter("a.b")
+h.addFilter(f)
+a.info("filtered")
+abc.info("passed")
+h.removeFilter(f)
+h.addFilter(lambda record: record.msg != "secret")
+a.info("secret")
+a.info("public")
+h.filters = []
+assert stream.getvalue() == "INFO:a.b.c:passed\nINFO:a:public\n", stream.getvalue()
+
+doc = "disabled"
+stream.truncate(0)
+stream.seek(0)
+logging.disable(logging.INFO)
+a.info("disabled")
+a.warning("enabled")
+logging.disable(logging.NOTSET)
+a.disabled = True
+a.error("disabled logger")
+a.disabled = False
+assert stream.getvalue() == "WARNING:a:enabled\n", stream.getvalue()
+
+doc = "formatters"
+record = logging.LogRecord("n", logging.INFO, "/path/to/mod.py", 42, "x=%s y=%d", ("a", 2), None, "fn")
+assert record.getMessage() == "x=a y=2"
+assert record.filename == "mod.py"
+assert record.module == "mod"
+assert record.levelname == "INFO"
+assert record.funcName == "fn"
+assert repr(record) == '<LogRecord: n, 20, /path/to/mod.py, 42, "x=%s y=%d">'
+fmt = logging.Formatter("%(name)s|%(lineno)d|%(message)s")
+assert fmt.format(record) == "n|42|x=a y=2"
+fmt = logging.Formatter("{levelname}-{name}: {message}", style="{")
+assert fmt.format(record) == "INFO-n: x=a y=2"
+fmt = logging.Formatter("$levelname ${name} $message", style="$")
+assert fmt.format(record) == "INFO n x=a y=2"
+fmt = logging.Formatter("%(custom)s %(message)s", defaults={"custom": "dflt"})
+assert fmt.format(record) == "dflt x=a y=2"
+assert not fmt.usesTime()
+assert logging.Formatter("%(asctime)s %(message)s").usesTime()
+assertRaisesText(ValueError, "Style must be one of", logging.Formatter, "x", None, "!")
+assertRaisesText(ValueError, "Invalid format", logging.Formatter, "no fields")
+dict_record = logging.LogRecord("n", logging.INFO, "p", 1, "%(a)s-%(b)s", ({"a": 1, "b": 2},), None)
+assert dict_record.getMessage() == "1-2"
+
+doc = "formatTime"
+record.created = 0.5
+record.msecs = 500
+t = logging.Formatter().formatTime(record, "%Y")
+assert t in ("1970", "1969"), t
+t = logging.Formatter().formatTime(record)
+assert t.endswith(",500"), t
+assert len(t) == len("1970-01-01 00:00:00,500"), t
+
+doc = "extra and exceptions"
+stream.truncate(0)
+stream.seek(0)
+h.setFormatter(logging.Formatter("%(levelname)s:%(user)s:%(message)s"))
+a.info("with extra", extra={"user": "bob"})
+assert stream.getvalue() == "INFO:bob:with extra\n", stream.getvalue()
+assertRaisesText(KeyError, "Attempt to overwrite 'msg' in LogRecord", a.info, "x", extra={"msg": 1})
+h.setFormatter(logging.Formatter("%(message)s"))
+stream.truncate(0)
+stream.seek(0)
+try:
+    1/0
+except ZeroDivisionError:
+    a.exception("failed")
+out = stream.getvalue()
+assert out.startswith("failed\nTraceback (most recent call last):\n"), out
+assert "ZeroDivisionError" in out, out
+stream.truncate(0)
+stream.seek(0)
+a.error("no exception", exc_info=ValueError("bad"))
+assert stream.getvalue() == "no exception\nValueError: bad\n", stream.getvalue()
+
+doc = "caller information"
+stream.truncate(0)
+stream.seek(0)
+h.setFormatter(logging.Formatter("%(funcName)s:%(module)s:%(message)s"))
+def where():
+    a.info("here")
+where()
+assert stream.getvalue() == "where:loggingtests:here\n", stream.getvalue()
+stream.truncate(0)
+stream.seek(0)
+def inner():
+    a.info("up", stacklevel=2)
+def outer():
+    inner()
+outer()
+assert stream.getvalue() == "outer:loggingtests:up\n", stream.getvalue()
+stream.truncate(0)
+stream.seek(0)
+a.info("stack", stack_info=True)
+assert "Stack (most recent call last):" in stream.getvalue()
+
+doc = "subclasses"
+class ListHandler(logging.Handler):
+    def __init__(self):
+        logging.Handler.__init__(self)
+        self.records = []
+    def emit(self, record):
+        self.records.append(self.format(record))
+lh = ListHandler()
+lh.setFormatter(logging.Formatter("%(message)s"))
+a.addHandler(lh)
+a.info("to list")
+a.removeHandler(lh)
+assert lh.records == ["to list"]
+assertRaises(NotImplementedError, logging.Handler().emit, None)
+
+class MyLogger(logging.Logger):
+    pass
+logging.setLoggerClass(MyLogger)
+assert logging.getLoggerClass() is MyLogger
+my = logging.getLogger("mine")
+assert type(my) is MyLogger
+logging.setLoggerClass(logging.Logger)
+assertRaises(TypeError, logging.setLoggerClass, int)
+
+doc = "handleError"
+class Broken:
+    def write(self, s):
+        raise IOError("broken")
+err = io.StringIO()
+old_stderr = sys.stderr
+sys.stderr = err
+try:
+    bh = logging.StreamHandler(Broken())
+    a.addHandler(bh)
+    a.warning("lost")
+    a.removeHandler(bh)
+    logging.raiseExceptions = False
+    a.addHandler(bh)
+    a.warning("quiet")
+    a.removeHandler(bh)
+    logging.raiseExceptions = True
+finally:
+    sys.stderr = old_stderr
+out = err.getvalue()
+assert out.startswith("--- Logging error ---\n"), out
+assert "OSError: broken" in out, out
+assert "Message: 'lost'\n" in out, out
+assert "quiet" not in out, out
+
+doc = "LoggerAdapter"
+stream.truncate(0)
+stream.seek(0)
+h.setFormatter(logging.Formatter("%(conn)s:%(message)s"))
+adapter = logging.LoggerAdapter(a, {"conn": "c1"})
+adapter.info("adapted %d", 7)
+assert stream.getvalue() == "c1:adapted 7\n", stream.getvalue()
+assert adapter.name == "a"
+assert repr(adapter) == "<LoggerAdapter a (INFO)>"
+assert adapter.isEnabledFor(logging.INFO)
+a.removeHandler(h)
+
+doc = "lastResort"
+err = io.StringIO()
+sys.stderr = err
+try:
+    lonely = logging.getLogger("lonely")
+    lonely.propagate = False
+    lonely.warning("last resort")
+    lonely.info("not shown")
+finally:
+    sys.stderr = old_stderr
+assert err.getvalue() == "last resort\n", err.getvalue()
+
+doc = "basicConfig"
+stream = io.StringIO()
+logging.basicConfig(stream=stream, level=logging.INFO, format="%(levelname)s %(message)s")
+assert len(root.handlers) == 1
+logging.info("root %s", "info")
+logging.debug("root debug")
+logging.basicConfig(stream=io.StringIO())
+assert len(root.handlers) == 1
+assert stream.getvalue() == "INFO root info\n", stream.getvalue()
+stream2 = io.StringIO()
+logging.basicConfig(stream=stream2, force=True, style="{", format="{name}/{message}")
+logging.warning("forced")
+assert stream2.getvalue() == "root/forced\n", stream2.getvalue()
+assertRaisesText(ValueError, "Unrecognised argument(s): bogus", logging.basicConfig, force=True, bogus=1)
+assertRaisesText(ValueError, "'stream' and 'filename' should not be specified together", logging.basicConfig, force=True, stream=stream, filename="x")
+
+doc = "FileHandler"
+import os
+name = "loggingtests.log"
+fh = logging.FileHandler(name, mode="w")
+assert fh.baseFilename == os.path.abspath(name)
+fh.setFormatter(logging.Formatter("%(levelname)s %(message)s"))
+a.addHandler(fh)
+a.info("to file")
+a.removeHandler(fh)
+fh.close()
+with open(name) as f:
+    assert f.read() == "INFO to file\n"
+delayed = logging.FileHandler(name, delay=True)
+assert delayed.stream is None
+delayed.close()
+os.remove(name)
+
+doc = "makeLogRecord"
+r = logging.makeLogRecord({"msg": "hi %s", "args": ("there",), "levelno": 40})
+assert r.getMessage() == "hi there"
+assert r.levelno == 40
+
+doc = "shutdown"
+logging.shutdown()
+
+doc = "finished"
@@ -12,7 +12,32 @@ import (
 	"runtime"
 	"runtime/pprof"
 
+	_ "github.com/go-python/gpython/abc"
+	_ "github.com/go-python/gpython/asyncio"
+	_ "github.com/go-python/gpython/base64"
+	_ "github.com/go-python/gpython/binascii"
 	_ "github.com/go-python/gpython/builtin"
+	_ "github.com/go-python/gpython/codecs"
+	_ "github.com/go-python/gpython/collections"
+	_ "github.com/go-python/gpython/contextlib"
+	_ "github.com/go-python/gpython/contextvars"
+	_ "github.com/go-python/gpython/cprofile"
+	_ "github.com/go-python/gpython/dataclasses"
+	_ "github.com/go-python/gpython/datetime"
+	_ "github.com/go-python/gpython/decimal"
+	_ "github.com/go-python/gpython/dis"
+	_ "github.com/go-python/gpython/enum"
+	_ "github.com/go-python/gpython/fractions"
+	_ "github.com/go-python/gpython/functools"
+	_ "github.com/go-python/gpython/gc"
+	_ "github.com/go-python/gpython/hashlib"
+	_ "github.com/go-python/gpython/hmac"
+	_ "github.com/go-python/gpython/http"
+	_ "github.com/go-python/gpython/inspect"
+	_ "github.com/go-python/gpython/io"
+	_ "github.com/go-python/gpython/itertools"
+	_ "github.com/go-python/gpython/json"
+	_ "github.com/go-python/gpython/logging"
 	"github.com/go-python/gpython/repl/cli"
 
 	//_ "github.com/go-python/gpython/importlib"
@@ -21,28 +46,72 @@ import (
 	"os"
 	"strings"
 
+	_ "github.com/go-python/gpython/cmath"
 	"github.com/go-python/gpython/compile"
 	"github.com/go-python/gpython/marshal"
 	_ "github.com/go-python/gpython/math"
+	_ "github.com/go-python/gpython/numbers"
+	_ "github.com/go-python/gpython/os"
+	_ "github.com/go-python/gpython/pdb"
+	_ "github.com/go-python/gpython/pickle"
 	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/pyast"
+	_ "github.com/go-python/gpython/random"
+	_ "github.com/go-python/gpython/re"
+	pysignal "github.com/go-python/gpython/signal"
+	_ "github.com/go-python/gpython/socket"
+	_ "github.com/go-python/gpython/statistics"
+	_ "github.com/go-python/gpython/struct"
+	_ "github.com/go-python/gpython/subprocess"
 	pysys "github.com/go-python/gpython/sys"
+	_ "github.com/go-python/gpython/threading"
 	_ "github.com/go-python/gpython/time"
+	_ "github.com/go-python/gpython/timeit"
+	_ "github.com/go-python/gpython/types"
+	_ "github.com/go-python/gpython/typing"
+	_ "github.com/go-python/gpython/unittest"
+	_ "github.com/go-python/gpython/urllib"
 	"github.com/go-python/gpython/vm"
+	_ "github.com/go-python/gpython/weakref"
 )
 
 // Globals
 var (
 	// Flags
-	debug      = flag.Bool("d", false, "Print lots of debugging")
-	cpuprofile = flag.String("cpuprofile", "", "Write cpu profile to file")
+	debug       = flag.Bool("d", false, "Print lots of debugging")
+	cpuprofile  = flag.String("cpuprofile", "", "Write cpu profile to file")
+	command     = flag.String("c", "", "Program passed in as string")
+	moduleName  = flag.String("m", "", "Run library module as a script")
+	inspect     = flag.Bool("i", false, "Inspect interactively after running script")
+	optimize    = flag.Bool("O", false, "Remove assert statements and make __debug__ false")
+	optimize2   = flag.Bool("OO", false, "Do -O changes and also discard docstrings")
+	warnOptions stringList
 )
 
+func init() {
+	flag.Var(&warnOptions, "W", "Warning control, added to sys.warnoptions")
+}
+
+// stringList is a flag which may be given more than once
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 // syntaxError prints the syntax
 func syntaxError() {
 	fmt.Fprintf(os.Stderr, `GPython
 
 A python implementation in Go
 
+Usage: gpython [option] ... [-c cmd | -m mod | file | -] [arg] ...
+
 Full options:
 `)
 	flag.PrintDefaults()
@@ -58,23 +127,120 @@ func fatal(message string, args ...interface{}) {
 	os.Exit(1)
 }
 
+// Returns whether the flag called name was given
+func isSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// Returns whether f is a terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Sets the command line options in sys
+func setSysFlags(sys *py.Module) {
+	if *optimize2 {
+		py.Optimize = 2
+	} else if *optimize {
+		py.Optimize = 1
+	}
+	flags := pysys.Flags{Optimize: py.Optimize}
+	if *debug {
+		flags.Debug = 1
+	}
+	if *inspect {
+		flags.Inspect, flags.Interactive = 1, 1
+	}
+	sys.Globals["flags"] = pysys.MakeFlags(flags)
+	sys.Globals["warnoptions"] = pysys.MakeArgv(warnOptions)
+}
+
+// Makes the __main__ module, with __file__ set to file if it isn't ""
+func newMain(file string) *py.Module {
+	module := py.NewModule("__main__", "", nil, nil)
+	if file != "" {
+		module.Globals["__file__"] = py.String(file)
+	}
+	return module
+}
+
+// Runs the python source src, which came from filename, as the
+// __main__ module with __file__ set to file if it isn't ""
+func runSource(src, filename, file string) (*py.Module, error) {
+	module := newMain(file)
+	obj, err := compile.Compile(src, filename, "exec", 0, true)
+	if err != nil {
+		return module, err
+	}
+	_, err = vm.Run(module.Globals, module.Globals, obj.(*py.Code), nil)
+	return module, err
+}
+
+// Runs the program in the file prog, which is python source unless
+// it is a .pyc file, as the __main__ module
+func runFile(prog string) (*py.Module, error) {
+	// FIXME should be using ImportModuleLevelObject() here
+	f, err := os.Open(prog)
+	if err != nil {
+		log.Fatalf("Failed to open %q: %v", prog, err)
+	}
+	defer f.Close()
+	if !strings.HasSuffix(prog, ".pyc") {
+		str, err := ioutil.ReadAll(f)
+		if err != nil {
+			log.Fatalf("Failed to read %q: %v", prog, err)
+		}
+		return runSource(string(str), prog, prog)
+	}
+	obj, err := marshal.ReadPyc(f)
+	if err != nil {
+		log.Fatalf("Failed to marshal %q: %v", prog, err)
+	}
+	module := newMain(prog)
+	_, err = vm.Run(module.Globals, module.Globals, obj.(*py.Code), nil)
+	return module, err
+}
+
+// Runs the module called name as the __main__ module, exiting if it
+// can't be found
+func runModule(name string) (*py.Module, error) {
+	// The module's imports are found from the current directory
+	// rather than the module's
+	path := py.CurrentContext.Path
+	if len(path.Items) > 0 && path.Items[0] == py.String("") {
+		if dir, err := os.Getwd(); err == nil {
+			path.Items[0] = py.String(dir)
+		}
+	}
+	module, err := py.RunModuleAsMain(name)
+	if module == nil {
+		if py.IsException(py.ImportError, err) {
+			msg, _ := py.StrAsString(err.(*py.Exception))
+			fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], msg)
+		} else {
+			py.TracebackDump(err)
+		}
+		py.FlushFiles()
+		os.Exit(1)
+	}
+	return module, err
+}
+
 func main() {
 	flag.Usage = syntaxError
 	flag.Parse()
 	args := flag.Args()
-	py.MustGetModule("sys").Globals["argv"] = pysys.MakeArgv(args)
-	if len(args) == 0 {
-
-		fmt.Printf("Python 3.4.0 (%s, %s)\n", commit, date)
-		fmt.Printf("[Gpython %s]\n", version)
-		fmt.Printf("- os/arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)
-		fmt.Printf("- go version: %s\n", runtime.Version())
-
-		cli.RunREPL()
-		return
-	}
-	prog := args[0]
-	// fmt.Printf("Running %q\n", prog)
+	// Make Ctrl-C raise KeyboardInterrupt in the running code
+	pysignal.InstallDefaultHandlers()
+	sys := py.MustGetModule("sys")
+	setSysFlags(sys)
 
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
@@ -88,41 +254,65 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	// FIXME should be using ImportModuleLevelObject() here
-	f, err := os.Open(prog)
-	if err != nil {
-		log.Fatalf("Failed to open %q: %v", prog, err)
-	}
-	var obj py.Object
-	if strings.HasSuffix(prog, ".pyc") {
-		obj, err = marshal.ReadPyc(f)
-		if err != nil {
-			log.Fatalf("Failed to marshal %q: %v", prog, err)
-		}
-	} else if strings.HasSuffix(prog, ".py") {
-		str, err := ioutil.ReadAll(f)
-		if err != nil {
-			log.Fatalf("Failed to read %q: %v", prog, err)
+	var module *py.Module
+	var err error
+	switch {
+	case isSet("c"):
+		sys.Globals["argv"] = pysys.MakeArgv(append([]string{"-c"}, args...))
+		module, err = runSource(*command, "<string>", "")
+	case isSet("m"):
+		sys.Globals["argv"] = pysys.MakeArgv(append([]string{"-m"}, args...))
+		module, err = runModule(*moduleName)
+	case len(args) > 0 && args[0] != "-":
+		sys.Globals["argv"] = pysys.MakeArgv(args)
+		module, err = runFile(args[0])
+	case len(args) > 0 || (!*inspect && !isTerminal(os.Stdin)):
+		// Run the program read from stdin
+		if len(args) == 0 {
+			args = []string{""}
 		}
-		obj, err = compile.Compile(string(str), prog, "exec", 0, true)
-		if err != nil {
-			log.Fatalf("Can't compile %q: %v", prog, err)
+		sys.Globals["argv"] = pysys.MakeArgv(args)
+		src, readErr := ioutil.ReadAll(os.Stdin)
+		if readErr != nil {
+			log.Fatalf("Failed to read stdin: %v", readErr)
 		}
-	} else {
-		log.Fatalf("Can't execute %q", prog)
+		module, err = runSource(string(src), "<stdin>", "<stdin>")
+	default:
+		sys.Globals["argv"] = pysys.MakeArgv([]string{""})
+
+		fmt.Printf("Python %s (%s, %s)\n", pysys.Version, commit, date)
+		fmt.Printf("[Gpython %s]\n", version)
+		fmt.Printf("- os/arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		fmt.Printf("- go version: %s\n", runtime.Version())
+
+		cli.RunREPL()
+		py.FlushFiles()
+		return
 	}
-	if err = f.Close(); err != nil {
-		log.Fatalf("Failed to close %q: %v", prog, err)
+	status := 0
+	if *inspect {
+		// Carry on in the REPL even after SystemExit
+		if err != nil && !py.IsException(py.SystemExit, err) {
+			py.TracebackDump(err)
+		}
+		cli.RunREPLInModule(module)
+	} else if err != nil {
+		var ok bool
+		status, ok = py.SystemExitStatus(err, os.Stderr)
+		if !ok {
+			py.TracebackDump(err)
+			status = 1
+			if py.IsException(py.KeyboardInterrupt, err) {
+				// As the shell reports a process killed by SIGINT
+				status = 130
+			}
+		}
 	}
-	code := obj.(*py.Code)
-	module := py.NewModule("__main__", "", nil, nil)
-	module.Globals["__file__"] = py.String(prog)
-	res, err := vm.Run(module.Globals, module.Globals, code, nil)
-	if err != nil {
-		py.TracebackDump(err)
-		log.Fatal(err)
+	// Wait for the threads which aren't daemons to finish then
+	// write out what is left in the buffers of the open files
+	vm.JoinThreads()
+	py.FlushFiles()
+	if status != 0 {
+		os.Exit(status)
 	}
-	// fmt.Printf("Return = %v\n", res)
-	_ = res
-
 }
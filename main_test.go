@@ -0,0 +1,86 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The tests run this test binary as gpython with GPYTHON_TEST_MAIN set
+// in its environment to see what the interpreter does as it exits
+func TestMain(m *testing.M) {
+	if os.Getenv("GPYTHON_TEST_MAIN") != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// Runs gpython with args returning its combined output and exit status
+func runMain(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "GPYTHON_TEST_MAIN=1")
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return string(out), exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("Failed to run gpython: %v", err)
+	}
+	return string(out), 0
+}
+
+func TestExitFlushesFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpython")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		name   string
+		src    string
+		status int
+	}{
+		{"end", "f.write('data')", 0},
+		{"sys.exit", "f.write('data')\nimport sys\nsys.exit(3)", 3},
+		{"exception", "f.write('data')\n1/0", 1},
+		{"binary", "f.close()\nf = open(name, 'wb')\nf.write(b'data')", 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(dir, test.name)
+			src := "name = " + `"` + filepath.ToSlash(name) + `"` + "\nf = open(name, 'w')\n" + test.src
+			out, status := runMain(t, "-c", src)
+			if status != test.status {
+				t.Errorf("want status %d, got %d: %s", test.status, status, out)
+			}
+			data, err := ioutil.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "data" {
+				t.Errorf("want %q in the file, got %q", "data", data)
+			}
+		})
+	}
+}
+
+func TestKeyboardInterruptStatus(t *testing.T) {
+	out, status := runMain(t, "-c", "raise KeyboardInterrupt")
+	if status != 130 {
+		t.Errorf("want status 130, got %d: %s", status, out)
+	}
+	if !strings.Contains(out, "KeyboardInterrupt") {
+		t.Errorf("want the traceback of KeyboardInterrupt, got %q", out)
+	}
+	out, status = runMain(t, "-c", "try:\n    raise KeyboardInterrupt\nexcept KeyboardInterrupt:\n    pass")
+	if status != 0 {
+		t.Errorf("want status 0 when it is caught, got %d: %s", status, out)
+	}
+}
@@ -9,8 +9,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
-	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"strconv"
 
@@ -67,6 +67,25 @@ type rFile struct {
 	refs []py.Object
 }
 
+// Reads a float written as a string with a one byte length
+func (rfile *rFile) readFloatString() (float64, error) {
+	var length uint8
+	err := binary.Read(rfile.r, binary.LittleEndian, &length)
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, int(length))
+	_, err = io.ReadFull(rfile.r, buf)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(string(buf), 64)
+	if err != nil {
+		return 0, py.ExceptionNewf(py.ValueError, "bad marshal data (invalid float %q)", buf)
+	}
+	return f, nil
+}
+
 // Reads an object from the input
 func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 	var code byte
@@ -139,18 +158,8 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		return addRef(py.Int(n)), nil
 	case TYPE_FLOAT:
 		// Floating point number as a string
-		var length uint8
-		err = binary.Read(rfile.r, binary.LittleEndian, &length)
-		if err != nil {
-			return
-		}
-		buf := make([]byte, int(length))
-		_, err = io.ReadFull(rfile.r, buf)
-		if err != nil {
-			return
-		}
 		var f float64
-		f, err = strconv.ParseFloat(string(buf), 64)
+		f, err = rfile.readFloatString()
 		if err != nil {
 			return
 		}
@@ -163,24 +172,17 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		}
 		return addRef(py.Float(f)), nil
 	case TYPE_COMPLEX:
-		// Complex number as a string
-		// FIXME this is using Go conversion not Python conversion which may differ
-		var length uint8
-		err = binary.Read(rfile.r, binary.LittleEndian, &length)
-		if err != nil {
-			return
-		}
-		buf := make([]byte, int(length))
-		_, err = io.ReadFull(rfile.r, buf)
+		// Complex number as the strings of its real and imaginary parts
+		var re, im float64
+		re, err = rfile.readFloatString()
 		if err != nil {
 			return
 		}
-		var c complex128
-		// FIXME c, err = strconv.ParseComplex(string(buf), 64)
+		im, err = rfile.readFloatString()
 		if err != nil {
 			return
 		}
-		return addRef(py.Complex(c)), nil
+		return addRef(py.Complex(complex(re, im))), nil
 	case TYPE_BINARY_COMPLEX:
 		var c complex128
 		err = binary.Read(rfile.r, binary.LittleEndian, &c)
@@ -194,43 +196,61 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		if err != nil {
 			return
 		}
-		// FIXME negative := false
+		// The sign of the size is the sign of the number
+		negative := false
 		if size < 0 {
-			// FIXME negative = true
+			negative = true
 			size = -size
 		}
-		if size < 0 || size > SIZE32_MAX {
-			return nil, errors.New("bad marshal data (long size out of range)")
+		if size <= 0 || size > SIZE32_MAX {
+			return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (long size out of range)")
 		}
-		// FIXME not sure what -ve size means!
-		// Now read shorts which have 15 bits of the number in
+		// Now read shorts which have 15 bits of the number in,
+		// least significant first
 		digits := make([]int16, size)
 		err = binary.Read(rfile.r, binary.LittleEndian, &digits)
 		if err != nil {
 			return
 		}
-		if digits[0] == 0 {
-			// FIXME should be ValueError
-			return nil, errors.New("bad marshal data (digit out of range in long)")
+		if digits[size-1] == 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (digit out of range in long)")
 		}
 		// Convert into a big.Int
 		r := new(big.Int)
 		t := new(big.Int)
-		for _, digit := range digits {
-			r.Lsh(r, 15)
-			t.SetInt64(int64(digit))
+		for i := len(digits) - 1; i >= 0; i-- {
+			r.Lsh(r, PyLong_MARSHAL_SHIFT)
+			t.SetInt64(int64(digits[i]))
 			r.Add(r, t)
 		}
-		// FIXME try to fit into int64 if possible
-		return addRef((*py.BigInt)(r)), nil
-	case TYPE_STRING, TYPE_INTERNED, TYPE_UNICODE, TYPE_ASCII, TYPE_ASCII_INTERNED:
+		if negative {
+			r.Neg(r)
+		}
+		return addRef((*py.BigInt)(r).MaybeInt()), nil
+	case TYPE_STRING:
+		// Bytes
+		var size int32
+		err = binary.Read(rfile.r, binary.LittleEndian, &size)
+		if err != nil {
+			return
+		}
+		if size < 0 || size > SIZE32_MAX {
+			return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (bytes object size out of range)")
+		}
+		buf := make([]byte, int(size))
+		_, err = io.ReadFull(rfile.r, buf)
+		if err != nil {
+			return
+		}
+		return addRef(py.Bytes(buf)), nil
+	case TYPE_INTERNED, TYPE_UNICODE, TYPE_ASCII, TYPE_ASCII_INTERNED:
 		var size int32
 		err = binary.Read(rfile.r, binary.LittleEndian, &size)
 		if err != nil {
 			return
 		}
 		if size < 0 || size > SIZE32_MAX {
-			return nil, errors.New("bad marshal data (string size out of range)")
+			return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (string size out of range)")
 		}
 		buf := make([]byte, int(size))
 		_, err = io.ReadFull(rfile.r, buf)
@@ -259,7 +279,7 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 			return
 		}
 		if size < 0 || size > SIZE32_MAX {
-			return nil, errors.New("bad marshal data (tuple size out of range)")
+			return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (tuple size out of range)")
 		}
 		tuple := make([]py.Object, int(size))
 		iref := reserveRef()
@@ -275,9 +295,17 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		case TYPE_LIST:
 			return updateRef(iref, py.NewListFromItems(tuple)), nil
 		case TYPE_SET:
-			return updateRef(iref, py.NewSetFromItems(tuple)), nil
+			set, err := py.NewSetFromItems(tuple)
+			if err != nil {
+				return nil, err
+			}
+			return updateRef(iref, set), nil
 		case TYPE_FROZENSET:
-			return updateRef(iref, py.NewFrozenSetFromItems(tuple)), nil
+			set, err := py.NewFrozenSetFromItems(tuple)
+			if err != nil {
+				return nil, err
+			}
+			return updateRef(iref, set), nil
 		}
 	case TYPE_SMALL_TUPLE:
 		var size uint8
@@ -295,8 +323,7 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		}
 		return updateRef(iref, py.Tuple(tuple)), nil
 	case TYPE_DICT:
-		// FIXME should be py.Dict
-		dict := py.NewStringDict()
+		dict := py.NewDict()
 		iref := reserveRef()
 		var key, value py.Object
 		for {
@@ -312,8 +339,10 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 				return
 			}
 			if value != nil {
-				// FIXME should be objects as key
-				dict[string(key.(py.String))] = value
+				err = dict.Set(key, value)
+				if err != nil {
+					return
+				}
 			}
 		}
 		return updateRef(iref, dict), nil
@@ -324,17 +353,14 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		if err != nil {
 			return
 		}
-		if n < 0 || int(n) >= len(rfile.refs) {
-			AddRef = false
-			fmt.Printf("Returning None as %d/%d out of range\n", n, len(rfile.refs))
-			return py.None, nil
-
-			// return nil, fmt.Errorf("TYPE_REF (out of range) - %d vs %d: %#v", n, len(rfile.refs), rfile.refs)
+		if n < 0 || int(n) >= len(rfile.refs) || rfile.refs[n] == nil {
+			return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (invalid reference)")
 		}
 		AddRef = false
 		return rfile.refs[n], nil
 	case TYPE_CODE:
 		var argcount int32
+		var posonlyargcount int32
 		var kwonlyargcount int32
 		var nlocals int32
 		var stacksize int32
@@ -349,11 +375,15 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		var name py.Object
 		var firstlineno int32
 		var lnotab py.Object
+		var positions py.Object
 		iref := reserveRef()
 
 		if err = binary.Read(rfile.r, binary.LittleEndian, &argcount); err != nil {
 			return
 		}
+		if err = binary.Read(rfile.r, binary.LittleEndian, &posonlyargcount); err != nil {
+			return
+		}
 		if err = binary.Read(rfile.r, binary.LittleEndian, &kwonlyargcount); err != nil {
 			return
 		}
@@ -396,8 +426,12 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		if lnotab, err = rfile.ReadObject(); err != nil {
 			return
 		}
+		if positions, err = rfile.ReadObject(); err != nil {
+			return
+		}
 
 		// fmt.Printf("argcount = %v\n", argcount)
+		// fmt.Printf("posonlyargcount = %v\n", posonlyargcount)
 		// fmt.Printf("kwonlyargcount = %v\n", kwonlyargcount)
 		// fmt.Printf("nlocals = %v\n", nlocals)
 		// fmt.Printf("stacksize = %v\n", stacksize)
@@ -413,20 +447,61 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		// fmt.Printf("firstlineno = %v\n", firstlineno)
 		// fmt.Printf("lnotab = %x\n", lnotab)
 
+		// The bytecode and lnotab are bytes in the file
+		if b, ok := code.(py.Bytes); ok {
+			code = py.String(b)
+		}
+		if b, ok := lnotab.(py.Bytes); ok {
+			lnotab = py.String(b)
+		}
+
 		v := py.NewCode(
 			argcount, kwonlyargcount,
 			nlocals, stacksize, flags,
 			code, consts, names, varnames,
 			freevars, cellvars, filename, name,
 			firstlineno, lnotab)
+		v.Posonlyargcount = posonlyargcount
+		if v.Positions, err = unpackPositions(positions); err != nil {
+			return
+		}
 		return updateRef(iref, v), nil
 	default:
-		return nil, fmt.Errorf("bad marshal data (unknown type code) 0x%02X '%c'", Type, Type)
+		return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (unknown type code)")
 	}
 
 	return
 }
 
+// The bytes of each CodePosition in the packed positions of a code
+// object
+const positionSize = 5 * 4
+
+// Packs the positions of a code object into a string of little endian
+// int32s to be marshalled as bytes
+func packPositions(positions []py.CodePosition) string {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, positions)
+	return buf.String()
+}
+
+// Unpacks the positions of a code object packed by packPositions
+func unpackPositions(obj py.Object) ([]py.CodePosition, error) {
+	b, ok := obj.(py.Bytes)
+	if !ok || len(b)%positionSize != 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "bad marshal data (invalid code positions)")
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	positions := make([]py.CodePosition, len(b)/positionSize)
+	err := binary.Read(bytes.NewReader(b), binary.LittleEndian, positions)
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
 // Reads an object from the input
 func ReadObject(r io.Reader) (obj py.Object, err error) {
 	rfile := &rFile{r: r}
@@ -454,6 +529,223 @@ func ReadPyc(r io.Reader) (obj py.Object, err error) {
 	return ReadObject(r)
 }
 
+// The maximum depth of nested containers which can be marshalled
+const maxMarshalDepth = 2000
+
+// Represents the file being marshalled to
+type wFile struct {
+	w       io.Writer
+	err     error
+	version int // the marshal format version
+	depth   int // the depth of containers being written
+}
+
+// Writes the binary representation of data to the output, remembering
+// the first error
+func (wfile *wFile) write(data interface{}) {
+	if wfile.err == nil {
+		wfile.err = binary.Write(wfile.w, binary.LittleEndian, data)
+	}
+}
+
+// Writes a string or bytes object with its size
+func (wfile *wFile) writeString(Type byte, s string) {
+	wfile.write(Type)
+	wfile.write(int32(len(s)))
+	if wfile.err == nil {
+		_, wfile.err = io.WriteString(wfile.w, s)
+	}
+}
+
+// Writes the items of a sequence with its size
+func (wfile *wFile) writeItems(Type byte, items []py.Object) {
+	wfile.write(Type)
+	wfile.write(int32(len(items)))
+	for _, item := range items {
+		wfile.WriteObject(item)
+	}
+}
+
+// Writes a tuple of strings
+func (wfile *wFile) writeNames(names []string) {
+	wfile.write(byte(TYPE_TUPLE))
+	wfile.write(int32(len(names)))
+	for _, name := range names {
+		wfile.writeString(TYPE_UNICODE, name)
+	}
+}
+
+// Writes an integer in 15 bit digits, least significant first
+func (wfile *wFile) writeLong(x *big.Int) {
+	var digits []int16
+	r := new(big.Int).Abs(x)
+	mask := big.NewInt(PyLong_MARSHAL_MASK)
+	t := new(big.Int)
+	for r.Sign() != 0 {
+		digits = append(digits, int16(t.And(r, mask).Int64()))
+		r.Rsh(r, PyLong_MARSHAL_SHIFT)
+	}
+	size := int32(len(digits))
+	if x.Sign() < 0 {
+		size = -size
+	}
+	wfile.write(byte(TYPE_LONG))
+	wfile.write(size)
+	wfile.write(digits)
+}
+
+// Writes a float as a string with a one byte length
+func (wfile *wFile) writeFloatString(f float64) {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	wfile.write(uint8(len(s)))
+	if wfile.err == nil {
+		_, wfile.err = io.WriteString(wfile.w, s)
+	}
+}
+
+// Writes an object to the output
+func (wfile *wFile) WriteObject(obj py.Object) {
+	if wfile.err != nil {
+		return
+	}
+	wfile.depth++
+	defer func() { wfile.depth-- }()
+	if wfile.depth > maxMarshalDepth {
+		wfile.err = py.ExceptionNewf(py.ValueError, "object too deeply nested to marshal")
+		return
+	}
+	if obj == py.StopIteration {
+		wfile.write(byte(TYPE_STOPITER))
+		return
+	}
+	switch x := obj.(type) {
+	case nil:
+		wfile.write(byte(TYPE_NULL))
+	case py.NoneType:
+		wfile.write(byte(TYPE_NONE))
+	case py.Bool:
+		if x {
+			wfile.write(byte(TYPE_TRUE))
+		} else {
+			wfile.write(byte(TYPE_FALSE))
+		}
+	case py.EllipsisType:
+		wfile.write(byte(TYPE_ELLIPSIS))
+	case py.Int:
+		if x >= math.MinInt32 && x <= math.MaxInt32 {
+			wfile.write(byte(TYPE_INT))
+			wfile.write(int32(x))
+		} else {
+			wfile.writeLong(big.NewInt(int64(x)))
+		}
+	case *py.BigInt:
+		wfile.writeLong((*big.Int)(x))
+	case py.Float:
+		if wfile.version < 2 {
+			wfile.write(byte(TYPE_FLOAT))
+			wfile.writeFloatString(float64(x))
+		} else {
+			wfile.write(byte(TYPE_BINARY_FLOAT))
+			wfile.write(float64(x))
+		}
+	case py.Complex:
+		if wfile.version < 2 {
+			wfile.write(byte(TYPE_COMPLEX))
+			wfile.writeFloatString(real(x))
+			wfile.writeFloatString(imag(x))
+		} else {
+			wfile.write(byte(TYPE_BINARY_COMPLEX))
+			wfile.write(complex128(x))
+		}
+	case py.String:
+		wfile.writeString(TYPE_UNICODE, string(x))
+	case py.Bytes:
+		wfile.writeString(TYPE_STRING, string(x))
+	case py.Tuple:
+		wfile.writeItems(TYPE_TUPLE, x)
+	case *py.List:
+		wfile.writeItems(TYPE_LIST, x.Items)
+	case *py.FrozenSet:
+		wfile.writeItems(TYPE_FROZENSET, x.Items())
+	case *py.Set:
+		wfile.writeItems(TYPE_SET, x.Items())
+	case *py.Dict, py.StringDict:
+		wfile.write(byte(TYPE_DICT))
+		for _, item := range x.(interface{ Items() []py.Tuple }).Items() {
+			wfile.WriteObject(item[0])
+			wfile.WriteObject(item[1])
+		}
+		wfile.write(byte(TYPE_NULL))
+	case *py.Code:
+		wfile.write(byte(TYPE_CODE))
+		wfile.write(x.Argcount)
+		wfile.write(x.Posonlyargcount)
+		wfile.write(x.Kwonlyargcount)
+		wfile.write(x.Nlocals)
+		wfile.write(x.Stacksize)
+		wfile.write(x.Flags)
+		wfile.writeString(TYPE_STRING, x.Code)
+		wfile.WriteObject(x.Consts)
+		wfile.writeNames(x.Names)
+		wfile.writeNames(x.Varnames)
+		wfile.writeNames(x.Freevars)
+		wfile.writeNames(x.Cellvars)
+		wfile.writeString(TYPE_UNICODE, x.Filename)
+		wfile.writeString(TYPE_UNICODE, x.Name)
+		wfile.write(x.Firstlineno)
+		wfile.writeString(TYPE_STRING, x.Lnotab)
+		wfile.writeString(TYPE_STRING, packPositions(x.Positions))
+	default:
+		wfile.err = py.ExceptionNewf(py.ValueError, "unmarshallable object")
+	}
+}
+
+// Writes an object to the output
+func WriteObject(w io.Writer, obj py.Object) error {
+	return writeObject(w, obj, MARSHAL_VERSION)
+}
+
+// Writes an object to the output in the given format version
+func writeObject(w io.Writer, obj py.Object, version int) error {
+	wfile := &wFile{w: w, version: version}
+	wfile.WriteObject(obj)
+	return wfile.err
+}
+
+// Converts an error from reading a marshalled object into a python
+// exception
+func readError(err error) error {
+	switch err {
+	case io.EOF:
+		return py.ExceptionNewf(py.EOFError, "EOF read where object expected")
+	case io.ErrUnexpectedEOF:
+		return py.ExceptionNewf(py.EOFError, "marshal data too short")
+	}
+	return err
+}
+
+// fileReader reads from a python file object
+type fileReader struct {
+	read py.Object // the read method of the file
+}
+
+// Read implements io.Reader by calling the read method of the file
+// for exactly the bytes wanted so nothing after the object is consumed
+func (r *fileReader) Read(p []byte) (int, error) {
+	res, err := py.Call(r.read, py.Tuple{py.Int(len(p))}, nil)
+	if err != nil {
+		return 0, err
+	}
+	b, ok := res.(py.Bytes)
+	if !ok {
+		return 0, py.ExceptionNewf(py.TypeError, "file.read() returned not bytes but %s", res.Type().Name)
+	}
+	if len(b) == 0 {
+		return 0, io.EOF
+	}
+	return copy(p, b), nil
+}
+
 // Unmarshals a frozen module
 func LoadFrozenModule(name string, data []byte) (*py.Module, error) {
 	r := bytes.NewBuffer(data)
@@ -484,25 +776,21 @@ to the file. The object will not be properly read back by load()
 The version argument indicates the data format that dump should use.`
 
 func marshal_dump(self py.Object, args py.Tuple) (py.Object, error) {
-	/*
-	   // XXX Quick hack -- need to do this differently
-	   PyObject *x;
-	   PyObject *f;
-	   int version = Py_MARSHAL_VERSION;
-	   PyObject *s;
-	   PyObject *res;
-	   _Py_IDENTIFIER(write);
-
-	   if (!PyArg_ParseTuple(args, "OO|i:dump", &x, &f, &version))
-	       return NULL;
-	   s = PyMarshal_WriteObjectToString(x, version);
-	   if (s == NULL)
-	       return NULL;
-	   res = _PyObject_CallMethodId(f, &PyId_write, "O", s);
-	   Py_DECREF(s);
-	   return res;
-	*/
-	return nil, py.ExceptionNewf(py.SystemError, "dump not implemented")
+	var value, file py.Object
+	var version py.Object = py.Int(MARSHAL_VERSION)
+	err := py.UnpackTuple(args, nil, "dump", 2, 3, &value, &file, &version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := marshal_dumps(nil, py.Tuple{value, version})
+	if err != nil {
+		return nil, err
+	}
+	write, err := py.GetAttrString(file, "write")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(write, py.Tuple{data}, nil)
 }
 
 const load_doc = `load(file)
@@ -517,34 +805,15 @@ Note: If an object containing an unsupported type was marshalled with
 dump(), load() will substitute None for the unmarshallable type.`
 
 func marshal_load(self, f py.Object) (py.Object, error) {
-	/*
-	   PyObject *data, *result;
-	   _Py_IDENTIFIER(read);
-	   RFILE rf;
-
-	    // Make a call to the read method, but read zero bytes.
-	    // This is to ensure that the object passed in at least
-	    // has a read method which returns bytes.
-	   data = _PyObject_CallMethodId(f, &PyId_read, "i", 0);
-	   if (data == NULL)
-	       return NULL;
-	   if (!PyBytes_Check(data)) {
-	       PyErr_Format(PyExc_TypeError,
-	                    "f.read() returned not bytes but %.100s",
-	                    data->ob_type->tp_name);
-	       result = NULL;
-	   }
-	   else {
-	       rf.depth = 0;
-	       rf.fp = NULL;
-	       rf.readable = f;
-	       rf.current_filename = NULL;
-	       result = read_object(&rf);
-	   }
-	   Py_DECREF(data);
-	   return result;
-	*/
-	return nil, py.ExceptionNewf(py.SystemError, "load not implemented")
+	read, err := py.GetAttrString(f, "read")
+	if err != nil {
+		return nil, err
+	}
+	obj, err := ReadObject(&fileReader{read: read})
+	if err != nil {
+		return nil, readError(err)
+	}
+	return obj, nil
 }
 
 const dumps_doc = `dumps(value[, version])
@@ -556,14 +825,22 @@ value has (or contains an object that has) an unsupported type.
 The version argument indicates the data format that dumps should use.`
 
 func marshal_dumps(self py.Object, args py.Tuple) (py.Object, error) {
-	/*
-	   PyObject *x;
-	   int version = Py_MARSHAL_VERSION;
-	   if (!PyArg_ParseTuple(args, "O|i:dumps", &x, &version))
-	       return NULL;
-	   return PyMarshal_WriteObjectToString(x, version);
-	*/
-	return nil, py.ExceptionNewf(py.SystemError, "dumps not implemented")
+	var value py.Object
+	var versionObj py.Object = py.Int(MARSHAL_VERSION)
+	err := py.UnpackTuple(args, nil, "dumps", 1, 2, &value, &versionObj)
+	if err != nil {
+		return nil, err
+	}
+	version, err := py.IndexInt(versionObj)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = writeObject(&buf, value, version)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(buf.Bytes()), nil
 }
 
 const loads_doc = `loads(bytes)
@@ -573,27 +850,20 @@ EOFError, ValueError or TypeError. Extra characters in the input are
 ignored.`
 
 func marshal_loads(self py.Object, args py.Tuple) (py.Object, error) {
-	/*
-	   RFILE rf;
-	   Py_buffer p;
-	   char *s;
-	   Py_ssize_t n;
-	   PyObject* result;
-	   if (!PyArg_ParseTuple(args, "y*:loads", &p))
-	       return NULL;
-	   s = p.buf;
-	   n = p.len;
-	   rf.fp = NULL;
-	   rf.readable = NULL;
-	   rf.current_filename = NULL;
-	   rf.ptr = s;
-	   rf.end = s + n;
-	   rf.depth = 0;
-	   result = read_object(&rf);
-	   PyBuffer_Release(&p);
-	   return result;
-	*/
-	return nil, py.ExceptionNewf(py.SystemError, "loads not implemented")
+	var data py.Object
+	err := py.UnpackTuple(args, nil, "loads", 1, 1, &data)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := data.(py.Bytes)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", data.Type().Name)
+	}
+	obj, err := ReadObject(bytes.NewReader(b))
+	if err != nil {
+		return nil, readError(err)
+	}
+	return obj, nil
 }
 
 const module_doc = `This module contains functions that can read and write Python values in
@@ -625,6 +895,21 @@ loads() -- read value from a string`
 
 // Initialise the module
 func init() {
+	py.MarshalCode = func(w io.Writer, code *py.Code) error {
+		return WriteObject(w, code)
+	}
+	py.UnmarshalCode = func(r io.Reader) (*py.Code, error) {
+		obj, err := ReadObject(r)
+		if err != nil {
+			return nil, err
+		}
+		code, ok := obj.(*py.Code)
+		if !ok {
+			return nil, errors.New("marshalled object isn't a code object")
+		}
+		return code, nil
+	}
+
 	methods := []*py.Method{
 		py.MustNewMethod("dump", marshal_dump, 0, dump_doc),
 		py.MustNewMethod("load", marshal_load, 0, load_doc),
@@ -634,5 +919,10 @@ func init() {
 	globals := py.StringDict{
 		"version": py.Int(MARSHAL_VERSION),
 	}
-	py.NewModule("marshal", module_doc, methods, globals)
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "marshal",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
 }
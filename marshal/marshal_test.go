@@ -0,0 +1,192 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package marshal_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-python/gpython/compile"
+	_ "github.com/go-python/gpython/io"
+	"github.com/go-python/gpython/marshal"
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/pytest"
+	_ "github.com/go-python/gpython/sys"
+)
+
+func TestRoundTrip(t *testing.T) {
+	src := `
+X = (1, -2, 2**40, -2**100, 1.5, 2j, "str", b"bytes", None, True, False, ..., frozenset([1]))
+def f(a, *args, b=1, **kwargs):
+    def g():
+        return a
+    return g
+`
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	code := obj.(*py.Code)
+	var buf bytes.Buffer
+	err = marshal.WriteObject(&buf, code)
+	if err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
+	}
+	want := buf.Bytes()
+	got, err := marshal.ReadObject(bytes.NewReader(want))
+	if err != nil {
+		t.Fatalf("ReadObject failed: %v", err)
+	}
+	// The code objects read are new objects so only compare the
+	// other constants
+	gotConsts := got.(*py.Code).Consts
+	if len(gotConsts) != len(code.Consts) {
+		t.Errorf("want consts %#v got %#v", code.Consts, gotConsts)
+	}
+	for i, want := range code.Consts {
+		if _, ok := want.(*py.Code); ok || i >= len(gotConsts) {
+			continue
+		}
+		if !reflect.DeepEqual(gotConsts[i], want) {
+			t.Errorf("const %d: want %#v got %#v", i, want, gotConsts[i])
+		}
+	}
+	// Marshalling what was read gives the same data
+	var buf2 bytes.Buffer
+	err = marshal.WriteObject(&buf2, got)
+	if err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
+	}
+	if !bytes.Equal(buf2.Bytes(), want) {
+		t.Errorf("want %q got %q", want, buf2.Bytes())
+	}
+
+	for _, obj := range []py.Object{
+		py.NewListFromItems([]py.Object{py.Int(1), py.String("a")}),
+		py.StringDict{"a": py.Int(1)},
+	} {
+		var buf bytes.Buffer
+		err = marshal.WriteObject(&buf, obj)
+		if err != nil {
+			t.Fatalf("WriteObject(%v) failed: %v", obj, err)
+		}
+		got, err := marshal.ReadObject(&buf)
+		if err != nil {
+			t.Fatalf("ReadObject(%v) failed: %v", obj, err)
+		}
+		eq, err := py.Eq(got, obj)
+		if err != nil || eq != py.True {
+			t.Errorf("want %v got %v", obj, got)
+		}
+	}
+
+	err = marshal.WriteObject(&buf2, py.NewModule("notmarshallable", "", nil, nil))
+	if !py.IsException(py.ValueError, err) {
+		t.Errorf("want ValueError got %v", err)
+	}
+}
+
+// Imports name in a new context searching dir, returning the value of X
+func importX(t *testing.T, dir, name string, dontWriteBytecode bool) py.Object {
+	ctx := py.NewContext()
+	ctx.Path = py.NewListFromItems([]py.Object{py.String(dir)})
+	sys, err := ctx.GetModule("sys")
+	if err != nil {
+		t.Fatalf("no sys: %v", err)
+	}
+	sys.Globals["path"] = ctx.Path
+	sys.Globals["dont_write_bytecode"] = py.NewBool(dontWriteBytecode)
+	var module py.Object
+	py.RunInContext(ctx, func() {
+		module, err = py.ImportModuleLevelObject(name, nil, nil, nil, 0)
+	})
+	if err != nil {
+		t.Fatalf("import %s failed: %v", name, err)
+	}
+	x, err := py.GetAttrString(module, "X")
+	if err != nil {
+		t.Fatalf("no X: %v", err)
+	}
+	return x
+}
+
+func TestImportCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpython-pyc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "cached.py")
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	write := func(src string, mtime time.Time) {
+		err := ioutil.WriteFile(source, []byte(src), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(source, mtime, mtime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("X = 1\n", mtime)
+	if got := importX(t, dir, "cached", false); got != py.Int(1) {
+		t.Errorf("want 1 got %v", got)
+	}
+	cache := py.CachePath(source)
+	if cache != filepath.Join(dir, "__pycache__", "cached."+py.CacheTag+".pyc") {
+		t.Errorf("bad cache path %q", cache)
+	}
+	if _, err := os.Stat(cache); err != nil {
+		t.Fatalf("cache not written: %v", err)
+	}
+
+	// A source with the same size and modification time is loaded
+	// from the cache
+	write("X = 2\n", mtime)
+	if got := importX(t, dir, "cached", false); got != py.Int(1) {
+		t.Errorf("want 1 from the cache got %v", got)
+	}
+
+	// A changed source is compiled again
+	write("X = 2\n", mtime.Add(time.Second))
+	if got := importX(t, dir, "cached", false); got != py.Int(2) {
+		t.Errorf("want 2 got %v", got)
+	}
+	write("X = 3\n", mtime.Add(time.Second))
+	if got := importX(t, dir, "cached", false); got != py.Int(2) {
+		t.Errorf("want 2 from the rewritten cache got %v", got)
+	}
+
+	// A bad cache is ignored
+	err = ioutil.WriteFile(cache, []byte("rubbish"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importX(t, dir, "cached", false); got != py.Int(3) {
+		t.Errorf("want 3 got %v", got)
+	}
+
+	// sys.dont_write_bytecode stops the cache being written
+	err = ioutil.WriteFile(filepath.Join(dir, "uncached.py"), []byte("X = 4\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importX(t, dir, "uncached", true); got != py.Int(4) {
+		t.Errorf("want 4 got %v", got)
+	}
+	if _, err := os.Stat(py.CachePath(filepath.Join(dir, "uncached.py"))); !os.IsNotExist(err) {
+		t.Errorf("cache written with dont_write_bytecode set: %v", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	pytest.RunTests(t, "tests")
+}
@@ -0,0 +1,82 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import marshal
+import io
+from libtest import *
+
+doc = "version"
+assertTrue(marshal.version >= 3)
+
+doc = "round trip"
+values = [
+    None, True, False, ..., StopIteration, 0, 1, -1, 2**31-1, -2**31,
+    2**31, 2**40, -2**100, 2**1000, 1.5, -0.0, 1e100, float("inf"), 2j,
+    1.5-3j, "", "str", "h\xe9llo\U0001f600", "x"*300, b"", b"bytes",
+    bytes(300), (), (1, 2), [], [3, [4]], {}, {"a": 1, 2: (3,)}, set(),
+    {4, 5}, frozenset(), frozenset([5]), list(range(1000)),
+]
+for version in range(5):
+    for value in values:
+        data = marshal.dumps(value, version)
+        assertEqual(type(data), bytes)
+        got = marshal.loads(data)
+        assertEqual(type(got), type(value))
+        assertEqual(got, value)
+    got = marshal.loads(marshal.dumps(float("nan"), version))
+    assertTrue(got != got)
+assertEqual(marshal.loads(marshal.dumps(values)), values)
+
+doc = "code objects"
+def f(a, b=2):
+    return a + b
+code = marshal.loads(marshal.dumps(f.__code__))
+assertEqual(code.co_name, "f")
+assertEqual(code.co_argcount, 2)
+assertEqual(eval(marshal.loads(marshal.dumps(compile("1+2", "<test>", "eval")))), 3)
+
+doc = "same bytes as CPython"
+assertEqual(marshal.dumps(None), b'N')
+assertEqual(marshal.dumps(1, 2), b'i\x01\x00\x00\x00')
+assertEqual(marshal.dumps(-2**100, 2), b'l\xf9\xff\xff\xff\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04')
+assertEqual(marshal.dumps(1.5, 1), b'f\x031.5')
+assertEqual(marshal.dumps(1.5, 2), b'g\x00\x00\x00\x00\x00\x00\xf8?')
+assertEqual(marshal.dumps(2j, 1), b'x\x010\x012')
+assertEqual(marshal.dumps("h\xe9llo", 2), b'u\x06\x00\x00\x00h\xc3\xa9llo')
+assertEqual(marshal.dumps([(1, 2), {"a": 1}], 2), b'[\x02\x00\x00\x00(\x02\x00\x00\x00i\x01\x00\x00\x00i\x02\x00\x00\x00{u\x01\x00\x00\x00ai\x01\x00\x00\x000')
+assertEqual(marshal.dumps(StopIteration), b'S')
+
+doc = "load data made by CPython"
+expected = [None, True, False, ..., 1, -1, 2**40, -2**100, 1.5, 2j, 'str', 'h\xe9llo', b'bytes', (1, 2), [3], {'a': 1}, {4}, frozenset([5]), StopIteration]
+assertEqual(marshal.loads(b'[\x13\x00\x00\x00NTF.i\x01\x00\x00\x00i\xff\xff\xff\xffl\x03\x00\x00\x00\x00\x00\x00\x00\x00\x04l\xf9\xff\xff\xff\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04f\x031.5x\x010\x012u\x03\x00\x00\x00stru\x06\x00\x00\x00h\xc3\xa9llos\x05\x00\x00\x00bytes(\x02\x00\x00\x00i\x01\x00\x00\x00i\x02\x00\x00\x00[\x01\x00\x00\x00i\x03\x00\x00\x00{u\x01\x00\x00\x00ai\x01\x00\x00\x000<\x01\x00\x00\x00i\x04\x00\x00\x00>\x01\x00\x00\x00i\x05\x00\x00\x00S'), expected)
+assertEqual(marshal.loads(b'\xdb\x13\x00\x00\x00NTF.\xe9\x01\x00\x00\x00\xe9\xff\xff\xff\xff\xec\x03\x00\x00\x00\x00\x00\x00\x00\x00\x04l\xf9\xff\xff\xff\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04\xe7\x00\x00\x00\x00\x00\x00\xf8?\xf9\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00@\xda\x03str\xf5\x06\x00\x00\x00h\xc3\xa9llo\xf3\x05\x00\x00\x00bytes\xa9\x02r\x01\x00\x00\x00\xe9\x02\x00\x00\x00[\x01\x00\x00\x00\xe9\x03\x00\x00\x00{\xda\x01ar\x01\x00\x00\x000<\x01\x00\x00\x00\xe9\x04\x00\x00\x00>\x01\x00\x00\x00\xe9\x05\x00\x00\x00S'), expected)
+
+doc = "dump and load with files"
+f = io.BytesIO()
+marshal.dump([1, "two"], f)
+marshal.dump({"three": 3.0}, f, 1)
+f.seek(0)
+assertEqual(marshal.load(f), [1, "two"])
+assertEqual(marshal.load(f), {"three": 3.0})
+assertRaisesText(EOFError, "EOF read where object expected", marshal.load, f)
+
+doc = "errors"
+assertRaisesText(ValueError, "unmarshallable object", marshal.dumps, object())
+assertRaisesText(TypeError, "a bytes-like object is required, not 'str'", marshal.loads, "x")
+assertRaisesText(EOFError, "EOF read where object expected", marshal.loads, b'')
+assertRaisesText(EOFError, "marshal data too short", marshal.loads, b'i\x01')
+assertRaisesText(EOFError, "EOF read where object expected", marshal.loads, b'[\x02\x00\x00\x00N')
+assertRaisesText(ValueError, "bad marshal data (invalid reference)", marshal.loads, b'r\x05\x00\x00\x00')
+assertRaisesText(ValueError, "bad marshal data (unknown type code)", marshal.loads, b'\x01')
+deep = []
+for i in range(3000):
+    deep = [deep]
+assertRaisesText(ValueError, "object too deeply nested to marshal", marshal.dumps, deep)
+
+doc = "corrupt code"
+code = compile("1", "<test>", "eval")
+data = marshal.dumps(code).replace(code.co_code, b'd\x09\x00S')
+assertRaisesText(SystemError, "index out of range", eval, marshal.loads(data))
+
+doc = "finished"
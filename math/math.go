@@ -360,6 +360,10 @@ func math_lgamma(self py.Object, arg py.Object) (py.Object, error) {
 	if err != nil {
 		return nil, err
 	}
+	// lgamma(+-inf) = +inf
+	if math.IsInf(x, 0) {
+		return py.Float(math.Inf(1)), nil
+	}
 	// If x is -ve integer...
 	if x <= 0 && x == math.Floor(x) {
 		return nil, EDOM
@@ -1337,5 +1341,10 @@ func init() {
 		"pi": py.Float(math.Pi),
 		"e":  py.Float(math.E),
 	}
-	py.NewModule("math", math_doc, methods, globals)
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "math",
+		Doc:     math_doc,
+		Methods: methods,
+		Globals: globals,
+	})
 }
@@ -261,6 +261,24 @@ ftest('degrees(pi)', math.degrees(math.pi), 180.0)
 ftest('degrees(pi/2)', math.degrees(math.pi/2), 90.0)
 ftest('degrees(-pi/4)', math.degrees(-math.pi/4), -45.0)
 
+doc="Erf"
+assertRaises(TypeError, math.erf)
+ftest('erf(0)', math.erf(0), 0)
+ftest('erf(0.5)', math.erf(0.5), 0.5204998778130465)
+ftest('erf(-0.5)', math.erf(-0.5), -0.5204998778130465)
+assertEqual(math.erf(INF), 1.)
+assertEqual(math.erf(NINF), -1.)
+assertTrue(math.isnan(math.erf(NAN)))
+
+doc="Erfc"
+assertRaises(TypeError, math.erfc)
+ftest('erfc(0)', math.erfc(0), 1)
+ftest('erfc(0.5)', math.erfc(0.5), 0.4795001221869535)
+ftest('erfc(-0.5)', math.erfc(-0.5), 1.5204998778130465)
+assertEqual(math.erfc(INF), 0.)
+assertEqual(math.erfc(NINF), 2.)
+assertTrue(math.isnan(math.erfc(NAN)))
+
 doc="Exp"
 assertRaises(TypeError, math.exp)
 ftest('exp(-1)', math.exp(-1), 1/math.e)
@@ -270,6 +288,16 @@ assertEqual(math.exp(INF), INF)
 assertEqual(math.exp(NINF), 0.)
 assertTrue(math.isnan(math.exp(NAN)))
 
+doc="Expm1"
+assertRaises(TypeError, math.expm1)
+ftest('expm1(0)', math.expm1(0), 0)
+ftest('expm1(1)', math.expm1(1), math.e-1)
+ftest('expm1(1e-10)', math.expm1(1e-10), 1.00000000005e-10)
+assertEqual(math.expm1(INF), INF)
+assertEqual(math.expm1(NINF), -1.)
+assertTrue(math.isnan(math.expm1(NAN)))
+assertRaises(OverflowError, math.expm1, 1000)
+
 doc="Fabs"
 assertRaises(TypeError, math.fabs)
 ftest('fabs(-1)', math.fabs(-1), 1)
@@ -458,6 +486,19 @@ for vals, expected in test_values:
 #     s = msum(vals)
 #     assertEqual(msum(vals), math.fsum(vals))
 
+doc="Gamma"
+assertRaises(TypeError, math.gamma)
+ftest('gamma(1)', math.gamma(1), 1)
+ftest('gamma(5)', math.gamma(5), 24)
+ftest('gamma(0.5)', math.gamma(0.5), math.sqrt(math.pi))
+ftest('gamma(-0.5)', math.gamma(-0.5), -2*math.sqrt(math.pi))
+assertEqual(math.gamma(INF), INF)
+assertTrue(math.isnan(math.gamma(NAN)))
+assertRaises(ValueError, math.gamma, 0)
+assertRaises(ValueError, math.gamma, -1)
+assertRaises(ValueError, math.gamma, -2.)
+assertRaises(ValueError, math.gamma, NINF)
+
 doc="Hypot"
 assertRaises(TypeError, math.hypot)
 ftest('hypot(0,0)', math.hypot(0,0), 0)
@@ -501,6 +542,19 @@ for n in [10**5, 10**10, 10**20, 10**40]:
     assertEqual(math.ldexp(NINF, n), NINF)
     assertTrue(math.isnan(math.ldexp(NAN, n)))
 
+doc="Lgamma"
+assertRaises(TypeError, math.lgamma)
+ftest('lgamma(1)', math.lgamma(1), 0)
+ftest('lgamma(2)', math.lgamma(2), 0)
+ftest('lgamma(5)', math.lgamma(5), math.log(24))
+ftest('lgamma(0.5)', math.lgamma(0.5), math.log(math.sqrt(math.pi)))
+assertEqual(math.lgamma(INF), INF)
+assertEqual(math.lgamma(NINF), INF)
+assertTrue(math.isnan(math.lgamma(NAN)))
+assertRaises(ValueError, math.lgamma, 0)
+assertRaises(ValueError, math.lgamma, -1)
+assertRaises(ValueError, math.lgamma, -2.)
+
 doc="Log"
 assertRaises(TypeError, math.log)
 ftest('log(1/e)', math.log(1/math.e), -1)
@@ -870,4 +924,26 @@ except ValueError:
 else:
     fail("sqrt(-1) didn't raise ValueError")
 
+doc="domain errors"
+assertRaises(ValueError, math.sqrt, -1)
+assertRaises(ValueError, math.acos, 2)
+assertRaises(ValueError, math.acos, -2)
+assertRaises(ValueError, math.asin, 2)
+assertRaises(ValueError, math.asin, -2)
+assertRaises(ValueError, math.acosh, 0.5)
+assertRaises(ValueError, math.atanh, 1)
+assertRaises(ValueError, math.atanh, -1)
+assertRaises(ValueError, math.log, 0)
+assertRaises(ValueError, math.log, -1)
+assertRaises(ValueError, math.log10, 0)
+assertRaises(ValueError, math.log2, -1)
+assertRaises(ValueError, math.log1p, -1)
+assertRaises(ValueError, math.log1p, -2)
+assertRaises(ValueError, math.cos, INF)
+assertRaises(ValueError, math.sin, INF)
+assertRaises(ValueError, math.tan, NINF)
+assertRaises(ValueError, math.pow, -1, 0.5)
+assertRaises(ValueError, math.pow, 0, -1)
+assertRaises(ValueError, math.fmod, 1, 0)
+
 doc="finished"
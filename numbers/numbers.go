@@ -0,0 +1,68 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Numbers module
+//
+// Abstract Base Classes (ABCs) for numbers, according to PEP 3141.
+
+package numbers
+
+import (
+	"github.com/go-python/gpython/abc"
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	NumberType = abc.NewABC("Number", `All numbers inherit from this class.
+
+If you just want to check if an argument x is a number, without
+caring what kind, use isinstance(x, Number).`, py.ObjectType)
+
+	ComplexType = abc.NewABC("Complex", `Complex defines the operations that work on the builtin complex type.
+
+In short, those are: a conversion to complex, .real, .imag, +, -,
+*, /, abs(), .conjugate, ==, and !=.`, NumberType)
+
+	RealType = abc.NewABC("Real", `To Complex, Real adds the operations that work on real numbers.
+
+In short, those are: a conversion to float, trunc(), divmod,
+%, <, <=, >, and >=.`, ComplexType)
+
+	RationalType = abc.NewABC("Rational", `.numerator and .denominator should be in lowest terms.`, RealType)
+
+	IntegralType = abc.NewABC("Integral", `Integral adds a conversion to int and the bit-string operations.`, RationalType)
+)
+
+// Registers subclass with cls panicking on error
+func mustRegister(cls, subclass *py.Type) {
+	err := abc.Register(cls, subclass)
+	if err != nil {
+		panic(err)
+	}
+}
+
+const numbers_doc = `Abstract Base Classes (ABCs) for numbers, according to PEP 3141.
+
+TODO: Fill out more detailed documentation on the operators.`
+
+// Initialise the module
+func init() {
+	mustRegister(ComplexType, py.ComplexType)
+	mustRegister(RealType, py.FloatType)
+	mustRegister(IntegralType, py.IntType)
+	mustRegister(IntegralType, py.BigIntType)
+
+	globals := py.StringDict{
+		"Number":   NumberType,
+		"Complex":  ComplexType,
+		"Real":     RealType,
+		"Rational": RationalType,
+		"Integral": IntegralType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "numbers",
+		Doc:     numbers_doc,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,70 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import numbers
+from numbers import Number, Complex, Real, Rational, Integral
+from fractions import Fraction
+from libtest import *
+
+doc="tower"
+assert issubclass(Complex, Number)
+assert issubclass(Real, Complex)
+assert issubclass(Rational, Real)
+assert issubclass(Integral, Rational)
+assert issubclass(Integral, Number)
+assert not issubclass(Number, Integral)
+
+doc="int"
+for x in (3, 10**30, True):
+    assert isinstance(x, Integral)
+    assert isinstance(x, Rational)
+    assert isinstance(x, Real)
+    assert isinstance(x, Complex)
+    assert isinstance(x, Number)
+
+doc="float"
+assert isinstance(1.5, Real)
+assert isinstance(1.5, Complex)
+assert isinstance(1.5, Number)
+assert not isinstance(1.5, Rational)
+assert not isinstance(1.5, Integral)
+
+doc="complex"
+assert isinstance(1j, Complex)
+assert isinstance(1j, Number)
+assert not isinstance(1j, Real)
+
+doc="Fraction"
+f = Fraction(1, 2)
+assert isinstance(f, Rational)
+assert isinstance(f, Real)
+assert isinstance(f, Number)
+assert not isinstance(f, Integral)
+
+doc="not numbers"
+for x in ("1", [1], None):
+    assert not isinstance(x, Number)
+
+doc="virtual subclasses"
+class MyNumber:
+    pass
+Real.register(MyNumber)
+assert isinstance(MyNumber(), Number)
+assert not isinstance(MyNumber(), Integral)
+
+doc="abstract"
+try:
+    Integral()
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="subclassing an ABC"
+class MyInt(Integral):
+    pass
+assert issubclass(MyInt, Number)
+assert isinstance(MyInt(), Rational)
+
+doc="finished"
@@ -0,0 +1,267 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The os.environ mapping
+//
+// os.environ reads and writes the environment of the process directly
+// so changes to it are seen by Go code and by child processes.
+
+package os
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+var EnvironType = py.NewType("_Environ", "A mapping of the environment variables of the process")
+
+// Environ is os.environ
+var Environ = &environ{}
+
+type environ struct{}
+
+// Type of this object
+func (e *environ) Type() *py.Type {
+	return EnvironType
+}
+
+// Returns the environment as a map
+func environMap() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	return env
+}
+
+// Returns the names of the environment variables in order
+func environKeys() []string {
+	env := environMap()
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Returns the name of a environment variable
+func environKey(key py.Object) (string, error) {
+	k, ok := key.(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "str expected, not %s", key.Type().Name)
+	}
+	return string(k), nil
+}
+
+func (e *environ) M__getitem__(key py.Object) (py.Object, error) {
+	k, err := environKey(key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := os.LookupEnv(k)
+	if !ok {
+		return nil, py.ExceptionNewf(py.KeyError, "%s", k)
+	}
+	return py.String(value), nil
+}
+
+func (e *environ) M__setitem__(key, value py.Object) (py.Object, error) {
+	k, err := environKey(key)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := value.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "str expected, not %s", value.Type().Name)
+	}
+	return py.None, setenv(k, string(v))
+}
+
+func (e *environ) M__delitem__(key py.Object) (py.Object, error) {
+	k, err := environKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := os.LookupEnv(k); !ok {
+		return nil, py.ExceptionNewf(py.KeyError, "%s", k)
+	}
+	if err = os.Unsetenv(k); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+func (e *environ) M__contains__(key py.Object) (py.Object, error) {
+	k, ok := key.(py.String)
+	if !ok {
+		return py.False, nil
+	}
+	_, found := os.LookupEnv(string(k))
+	return py.NewBool(found), nil
+}
+
+func (e *environ) M__len__() (py.Object, error) {
+	return py.Int(len(environMap())), nil
+}
+
+func (e *environ) M__iter__() (py.Object, error) {
+	return py.NewIterator(e.keys()), nil
+}
+
+func (e *environ) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("environ({")
+	env := environMap()
+	for i, key := range environKeys() {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		k, err := py.ReprAsString(py.String(key))
+		if err != nil {
+			return nil, err
+		}
+		v, err := py.ReprAsString(py.String(env[key]))
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(k + ": " + v)
+	}
+	out.WriteString("})")
+	return py.String(out.String()), nil
+}
+
+// Returns the names of the variables
+func (e *environ) keys() py.Tuple {
+	keys := environKeys()
+	t := make(py.Tuple, len(keys))
+	for i, key := range keys {
+		t[i] = py.String(key)
+	}
+	return t
+}
+
+// Returns a dict copy of the environment
+func (e *environ) copy() py.StringDict {
+	d := py.NewStringDict()
+	for key, value := range environMap() {
+		d[key] = py.String(value)
+	}
+	return d
+}
+
+func init() {
+	EnvironType.Dict["get"] = py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key py.Object
+		var def py.Object = py.None
+		err := py.UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		if k, ok := key.(py.String); ok {
+			if value, ok := os.LookupEnv(string(k)); ok {
+				return py.String(value), nil
+			}
+		}
+		return def, nil
+	}, 0, "get(key[, default]) -> the value of key if it is set, else default")
+	EnvironType.Dict["keys"] = py.MustNewMethod("keys", func(self py.Object) (py.Object, error) {
+		return py.NewListFromItems(self.(*environ).keys()), nil
+	}, 0, "keys() -> a list of the names of the environment variables")
+	EnvironType.Dict["values"] = py.MustNewMethod("values", func(self py.Object) (py.Object, error) {
+		env := environMap()
+		values := py.NewList()
+		for _, key := range environKeys() {
+			values.Append(py.String(env[key]))
+		}
+		return values, nil
+	}, 0, "values() -> a list of the values of the environment variables")
+	EnvironType.Dict["items"] = py.MustNewMethod("items", func(self py.Object) (py.Object, error) {
+		env := environMap()
+		items := py.NewList()
+		for _, key := range environKeys() {
+			items.Append(py.Tuple{py.String(key), py.String(env[key])})
+		}
+		return items, nil
+	}, 0, "items() -> a list of the (name, value) pairs of the environment variables")
+	EnvironType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*environ).copy(), nil
+	}, 0, "copy() -> a dict of the environment variables")
+	EnvironType.Dict["pop"] = py.MustNewMethod("pop", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, def py.Object
+		err := py.UnpackTuple(args, nil, "pop", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		e := self.(*environ)
+		value, err := e.M__getitem__(key)
+		if err != nil {
+			if def != nil && py.IsException(py.KeyError, err) {
+				return def, nil
+			}
+			return nil, err
+		}
+		_, err = e.M__delitem__(key)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}, 0, "pop(key[, default]) -> remove key returning its value, or default if it isn't set")
+	EnvironType.Dict["setdefault"] = py.MustNewMethod("setdefault", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, value py.Object
+		err := py.UnpackTuple(args, nil, "setdefault", 2, 2, &key, &value)
+		if err != nil {
+			return nil, err
+		}
+		e := self.(*environ)
+		if found, _ := e.M__contains__(key); found == py.True {
+			return e.M__getitem__(key)
+		}
+		_, err = e.M__setitem__(key, value)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}, 0, "setdefault(key, value) -> the value of key, setting it to value first if it isn't set")
+	EnvironType.Dict["update"] = py.MustNewMethod("update", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var other py.Object = py.None
+		err := py.UnpackTuple(args, nil, "update", 0, 1, &other)
+		if err != nil {
+			return nil, err
+		}
+		e := self.(*environ)
+		var items []py.Tuple
+		switch d := other.(type) {
+		case py.NoneType:
+		case py.StringDict:
+			items = d.Items()
+		case *py.Dict:
+			items = d.Items()
+		default:
+			return nil, py.ExceptionNewf(py.TypeError, "update() argument must be a dict, not %s", other.Type().Name)
+		}
+		items = append(items, kwargs.Items()...)
+		for _, item := range items {
+			_, err = e.M__setitem__(item[0], item[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return py.None, nil
+	}, 0, "update([other], **kwargs) -> None.  Set the variables in other and kwargs")
+}
+
+// Check interfaces
+var _ py.I__getitem__ = (*environ)(nil)
+var _ py.I__setitem__ = (*environ)(nil)
+var _ py.I__delitem__ = (*environ)(nil)
+var _ py.I__contains__ = (*environ)(nil)
+var _ py.I__len__ = (*environ)(nil)
+var _ py.I__iter__ = (*environ)(nil)
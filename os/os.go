@@ -0,0 +1,459 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// OS module
+//
+// The functions of the os module are mapped onto Go's os package so
+// they behave the same on all the platforms Go supports.
+
+package os
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/go-python/gpython/py"
+)
+
+const os_doc = `OS routines for NT or Posix depending on what system we're on.
+
+This exports:
+  - environ, a mapping of the environment variables
+  - the file system functions getcwd, chdir, listdir, mkdir, makedirs,
+    remove, rmdir, rename, stat...
+  - os.path, the path manipulation functions of posixpath
+  - os.name, 'posix' or 'nt'
+  - os.curdir, os.pardir, os.sep, os.altsep, os.extsep, os.pathsep,
+    os.linesep and os.devnull`
+
+// Returns the path given as the argument of the function name
+//
+// This is a str or an object with an __fspath__ method returning one.
+func pathArg(name string, arg py.Object) (string, error) {
+	path, err := fspath(arg)
+	if err != nil {
+		return "", py.ExceptionNewf(py.TypeError, "%s: path should be string or os.PathLike, not %s", name, arg.Type().Name)
+	}
+	return string(path.(py.String)), nil
+}
+
+// Returns the file system path of arg, a str or bytes or an object
+// with an __fspath__ method
+func fspath(arg py.Object) (py.Object, error) {
+	switch arg.(type) {
+	case py.String, py.Bytes:
+		return arg, nil
+	}
+	path, ok, err := py.TypeCall0(arg, "__fspath__")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "expected str, bytes or os.PathLike object, not %s", arg.Type().Name)
+	}
+	switch path.(type) {
+	case py.String, py.Bytes:
+		return path, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "expected %s.__fspath__() to return str or bytes, not %s", arg.Type().Name, path.Type().Name)
+}
+
+const fspath_doc = `fspath(path) -> the file system representation of the path.
+
+If str or bytes is passed in, it is returned unchanged.  Otherwise the
+result of the __fspath__ method is returned.`
+
+func os_fspath(self, path py.Object) (py.Object, error) {
+	return fspath(path)
+}
+
+const getcwd_doc = `getcwd() -> a unicode string representing the current working directory.`
+
+func os_getcwd(self py.Object) (py.Object, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.String(dir), nil
+}
+
+const getcwdb_doc = `getcwdb() -> a bytes string representing the current working directory.`
+
+func os_getcwdb(self py.Object) (py.Object, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.Bytes(dir), nil
+}
+
+const chdir_doc = `chdir(path) -> None
+
+Change the current working directory to the specified path.`
+
+func os_chdir(self, arg py.Object) (py.Object, error) {
+	path, err := pathArg("chdir", arg)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chdir(path); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+const listdir_doc = `listdir(path='.') -> list_of_filenames
+
+Return a list containing the names of the files in the directory.
+The list is in arbitrary order.  It does not include the special
+entries '.' and '..' even if they are present in the directory.`
+
+func os_listdir(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var arg py.Object = py.String(".")
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:listdir", []string{"path"}, &arg)
+	if err != nil {
+		return nil, err
+	}
+	path := "."
+	if arg != py.None {
+		path, err = pathArg("listdir", arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	sort.Strings(names)
+	list := py.NewListSized(len(names))
+	for i, name := range names {
+		list.Items[i] = py.String(name)
+	}
+	return list, nil
+}
+
+// Parses the arguments of mkdir and makedirs
+func modeArgs(name string, pathName string, args py.Tuple, kwargs py.StringDict, extra ...*py.Object) (string, os.FileMode, error) {
+	var arg py.Object
+	var mode py.Object = py.Int(0777)
+	format := "O|i"
+	kwlist := []string{pathName, "mode"}
+	results := []*py.Object{&arg, &mode}
+	if len(extra) > 0 {
+		format += "p"
+		kwlist = append(kwlist, "exist_ok")
+		results = append(results, extra...)
+	}
+	err := py.ParseTupleAndKeywords(args, kwargs, format+":"+name, kwlist, results...)
+	if err != nil {
+		return "", 0, err
+	}
+	path, err := pathArg(name, arg)
+	if err != nil {
+		return "", 0, err
+	}
+	return path, os.FileMode(mode.(py.Int)) & os.ModePerm, nil
+}
+
+const mkdir_doc = `mkdir(path, mode=0o777) -> None
+
+Create a directory.  The mode argument is masked by the umask.`
+
+func os_mkdir(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	path, mode, err := modeArgs("mkdir", "path", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Mkdir(path, mode); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+const makedirs_doc = `makedirs(name, mode=0o777, exist_ok=False) -> None
+
+Super-mkdir; create a leaf directory and all intermediate ones.  Works
+like mkdir, except that any intermediate path segment (not just the
+rightmost) will be created if it does not exist.  If the target
+directory already exists, raise an OSError if exist_ok is False.
+Otherwise no exception is raised.`
+
+func os_makedirs(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var existOk py.Object = py.False
+	path, mode, err := modeArgs("makedirs", "name", args, kwargs, &existOk)
+	if err != nil {
+		return nil, err
+	}
+	if existOk != py.True {
+		if _, err := os.Lstat(path); err == nil {
+			return nil, py.MakeOSError(&os.PathError{Op: "mkdir", Path: path, Err: syscall.EEXIST})
+		}
+	}
+	if err = os.MkdirAll(path, mode); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+const remove_doc = `remove(path) -> None
+
+Remove a file (same as unlink()).`
+
+const unlink_doc = `unlink(path) -> None
+
+Remove a file (same as remove()).`
+
+func os_remove(self, arg py.Object) (py.Object, error) {
+	path, err := pathArg("remove", arg)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(path)
+	if err == nil && fi.IsDir() {
+		return nil, py.MakeOSError(&os.PathError{Op: "remove", Path: path, Err: errIsDir})
+	}
+	if err = os.Remove(path); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+const rmdir_doc = `rmdir(path) -> None
+
+Remove a directory.`
+
+func os_rmdir(self, arg py.Object) (py.Object, error) {
+	path, err := pathArg("rmdir", arg)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(path)
+	if err == nil && !fi.IsDir() {
+		return nil, py.MakeOSError(&os.PathError{Op: "rmdir", Path: path, Err: errNotDir})
+	}
+	if err = os.Remove(path); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+const rename_doc = `rename(src, dst) -> None
+
+Rename a file or directory.`
+
+const replace_doc = `replace(src, dst) -> None
+
+Rename a file or directory, overwriting the destination.`
+
+func os_rename(self py.Object, args py.Tuple) (py.Object, error) {
+	var srcArg, dstArg py.Object
+	err := py.UnpackTuple(args, nil, "rename", 2, 2, &srcArg, &dstArg)
+	if err != nil {
+		return nil, err
+	}
+	src, err := pathArg("rename", srcArg)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := pathArg("rename", dstArg)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Rename(src, dst); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+const stat_doc = `stat(path) -> stat result
+
+Perform a stat system call on the given path.`
+
+func os_stat(self, arg py.Object) (py.Object, error) {
+	path, err := pathArg("stat", arg)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return newStatResult(fi), nil
+}
+
+const lstat_doc = `lstat(path) -> stat result
+
+Like stat(path), but do not follow symbolic links.`
+
+func os_lstat(self, arg py.Object) (py.Object, error) {
+	path, err := pathArg("lstat", arg)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return newStatResult(fi), nil
+}
+
+const getenv_doc = `getenv(key, default=None) -> the environment variable key if it exists, else default.`
+
+func os_getenv(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var key py.Object
+	var def py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "s|O:getenv", []string{"key", "default"}, &key, &def)
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := os.LookupEnv(string(key.(py.String))); ok {
+		return py.String(value), nil
+	}
+	return def, nil
+}
+
+const putenv_doc = `putenv(key, value) -> None
+
+Change or add an environment variable.`
+
+func os_putenv(self py.Object, args py.Tuple) (py.Object, error) {
+	var key, value py.Object
+	err := py.ParseTuple(args, "ss:putenv", &key, &value)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, setenv(string(key.(py.String)), string(value.(py.String)))
+}
+
+const unsetenv_doc = `unsetenv(key) -> None
+
+Delete an environment variable.`
+
+func os_unsetenv(self, key py.Object) (py.Object, error) {
+	k, ok := key.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "unsetenv() argument must be str, not %s", key.Type().Name)
+	}
+	if err := os.Unsetenv(string(k)); err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.None, nil
+}
+
+const getpid_doc = `getpid() -> the current process id.`
+
+func os_getpid(self py.Object) (py.Object, error) {
+	return py.Int(os.Getpid()), nil
+}
+
+// The errors of remove and rmdir for paths of the wrong type which
+// os.Remove would remove anyway
+var (
+	errIsDir  error = syscall.EISDIR
+	errNotDir error = syscall.ENOTDIR
+)
+
+// Returns os.name
+func osName() string {
+	if runtime.GOOS == "windows" {
+		return "nt"
+	}
+	return "posix"
+}
+
+// Returns os.linesep
+func lineSep() string {
+	if runtime.GOOS == "windows" {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// Returns os.altsep
+func altSep() py.Object {
+	if filepath.Separator != '/' {
+		return py.String("/")
+	}
+	return py.None
+}
+
+// Returns os.devnull
+func devNull() string {
+	return os.DevNull
+}
+
+// Sets the environment variable key to value
+func setenv(key, value string) error {
+	if key == "" || strings.ContainsRune(key, '=') {
+		return py.ExceptionNewf(py.ValueError, "illegal environment variable name")
+	}
+	if err := os.Setenv(key, value); err != nil {
+		return py.MakeOSError(err)
+	}
+	return nil
+}
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("chdir", os_chdir, 0, chdir_doc),
+		py.MustNewMethod("fspath", os_fspath, 0, fspath_doc),
+		py.MustNewMethod("getcwd", os_getcwd, 0, getcwd_doc),
+		py.MustNewMethod("getcwdb", os_getcwdb, 0, getcwdb_doc),
+		py.MustNewMethod("getenv", os_getenv, 0, getenv_doc),
+		py.MustNewMethod("getpid", os_getpid, 0, getpid_doc),
+		py.MustNewMethod("listdir", os_listdir, 0, listdir_doc),
+		py.MustNewMethod("lstat", os_lstat, 0, lstat_doc),
+		py.MustNewMethod("makedirs", os_makedirs, 0, makedirs_doc),
+		py.MustNewMethod("mkdir", os_mkdir, 0, mkdir_doc),
+		py.MustNewMethod("putenv", os_putenv, 0, putenv_doc),
+		py.MustNewMethod("remove", os_remove, 0, remove_doc),
+		py.MustNewMethod("rename", os_rename, 0, rename_doc),
+		py.MustNewMethod("replace", os_rename, 0, replace_doc),
+		py.MustNewMethod("rmdir", os_rmdir, 0, rmdir_doc),
+		py.MustNewMethod("stat", os_stat, 0, stat_doc),
+		py.MustNewMethod("unlink", os_remove, 0, unlink_doc),
+		py.MustNewMethod("unsetenv", os_unsetenv, 0, unsetenv_doc),
+	}
+	globals := py.StringDict{
+		"environ":     Environ,
+		"error":       py.OSError,
+		"stat_result": StatResultType,
+		"name":        py.String(osName()),
+		"curdir":      py.String("."),
+		"pardir":      py.String(".."),
+		"sep":         py.String(filepath.Separator),
+		"altsep":      altSep(),
+		"extsep":      py.String("."),
+		"pathsep":     py.String(filepath.ListSeparator),
+		"linesep":     py.String(lineSep()),
+		"devnull":     py.String(devNull()),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "os",
+		Doc:     os_doc,
+		Methods: methods,
+		Globals: globals,
+		Init: func(ctx *py.Context, m *py.Module) error {
+			// os.path is posixpath which is imported with os
+			path, err := ctx.GetModule("posixpath")
+			if err != nil {
+				return err
+			}
+			m.Globals["path"] = path
+			ctx.Modules["os.path"] = path
+			return nil
+		},
+	})
+}
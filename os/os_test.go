@@ -0,0 +1,17 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-python/gpython/pytest"
+)
+
+func TestOs(t *testing.T) {
+	defer os.RemoveAll("tests/ostests.tmp")
+	pytest.RunTests(t, "tests")
+}
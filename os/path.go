@@ -0,0 +1,366 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The os.path module
+//
+// The path manipulation functions follow posixpath on all platforms.
+// The functions which look at the file system go through Go's os
+// package which accepts / as a separator on Windows too.
+
+package os
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const path_doc = `Common operations on Posix pathnames.
+
+Instead of importing this module directly, import os and refer to
+this module as os.path.`
+
+// Returns the path arguments of the function name
+func pathArgs(name string, args py.Tuple) ([]string, error) {
+	paths := make([]string, len(args))
+	for i, arg := range args {
+		path, err := fspath(arg)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := path.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "%s() argument must be str, not %s", name, path.Type().Name)
+		}
+		paths[i] = string(s)
+	}
+	return paths, nil
+}
+
+// Makes a function of a path returning a path
+func pathFunc(name, doc string, fn func(path string) (string, error)) *py.Method {
+	return py.MustNewMethod(name, func(self, arg py.Object) (py.Object, error) {
+		paths, err := pathArgs(name, py.Tuple{arg})
+		if err != nil {
+			return nil, err
+		}
+		result, err := fn(paths[0])
+		if err != nil {
+			return nil, err
+		}
+		return py.String(result), nil
+	}, 0, doc)
+}
+
+// Makes a function of a path returning a bool which is False for
+// any path which can't be looked at
+func pathTest(name, doc string, fn func(path string) bool) *py.Method {
+	return py.MustNewMethod(name, func(self, arg py.Object) (py.Object, error) {
+		paths, err := pathArgs(name, py.Tuple{arg})
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(fn(paths[0])), nil
+	}, 0, doc)
+}
+
+// Makes a function returning a number from the stat of a path
+func pathStat(name, doc string, fn func(fi os.FileInfo) py.Object) *py.Method {
+	return py.MustNewMethod(name, func(self, arg py.Object) (py.Object, error) {
+		paths, err := pathArgs(name, py.Tuple{arg})
+		if err != nil {
+			return nil, err
+		}
+		fi, err := os.Stat(paths[0])
+		if err != nil {
+			return nil, py.MakeOSError(err)
+		}
+		return fn(fi), nil
+	}, 0, doc)
+}
+
+// Joins the paths as posixpath.join does, an absolute path
+// discarding the paths before it
+func join(paths ...string) string {
+	result := ""
+	for i, path := range paths {
+		switch {
+		case i == 0 || strings.HasPrefix(path, "/"):
+			result = path
+		case result == "" || strings.HasSuffix(result, "/"):
+			result += path
+		default:
+			result += "/" + path
+		}
+	}
+	return result
+}
+
+// Splits path into the directory and the last component
+func split(path string) (head, tail string) {
+	i := strings.LastIndexByte(path, '/') + 1
+	head, tail = path[:i], path[i:]
+	if trimmed := strings.TrimRight(head, "/"); trimmed != "" {
+		head = trimmed
+	}
+	return head, tail
+}
+
+// Splits path into a root and an extension which is empty
+// or starts with the last dot of the last component
+//
+// Dots at the start of the last component are ignored.
+func splitExt(path string) (root, ext string) {
+	dot := strings.LastIndexByte(path, '.')
+	slash := strings.LastIndexByte(path, '/')
+	if dot > slash {
+		// Skip all leading dots of the last component
+		for i := slash + 1; i < dot; i++ {
+			if path[i] != '.' {
+				return path[:dot], path[dot:]
+			}
+		}
+	}
+	return path, ""
+}
+
+// Normalizes a path as posixpath.normpath does
+func normPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	// Posix allows an implementation defined meaning for exactly
+	// two leading slashes so they are kept
+	prefix := ""
+	if strings.HasPrefix(path, "//") && !strings.HasPrefix(path, "///") {
+		prefix = "/"
+	}
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		switch {
+		case part == "" || part == ".":
+		case part != "..":
+			parts = append(parts, part)
+		case len(parts) > 0 && parts[len(parts)-1] != "..":
+			parts = parts[:len(parts)-1]
+		case !strings.HasPrefix(path, "/"):
+			parts = append(parts, part)
+		}
+	}
+	result := strings.Join(parts, "/")
+	if strings.HasPrefix(path, "/") {
+		return prefix + "/" + result
+	}
+	if result == "" {
+		return "."
+	}
+	return result
+}
+
+// Returns the normalized absolute path of path
+func absPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") && !filepath.IsAbs(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", py.MakeOSError(err)
+		}
+		path = join(filepath.ToSlash(cwd), path)
+	}
+	return normPath(path), nil
+}
+
+// Returns the current directory relative path of path
+func relPath(path, start string) (string, error) {
+	if path == "" {
+		return "", py.ExceptionNewf(py.ValueError, "no path specified")
+	}
+	abs, err := absPath(path)
+	if err != nil {
+		return "", err
+	}
+	absStart, err := absPath(start)
+	if err != nil {
+		return "", err
+	}
+	pathParts := strings.Split(strings.Trim(abs, "/"), "/")
+	startParts := strings.Split(strings.Trim(absStart, "/"), "/")
+	if absStart == "/" {
+		startParts = nil
+	}
+	if abs == "/" {
+		pathParts = nil
+	}
+	n := 0
+	for n < len(pathParts) && n < len(startParts) && pathParts[n] == startParts[n] {
+		n++
+	}
+	var parts []string
+	for range startParts[n:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, pathParts[n:]...)
+	if len(parts) == 0 {
+		return ".", nil
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// Returns a path with ~ or ~user at the start replaced by the home
+// directory, leaving it alone if the home directory isn't known
+func expandUser(path string) string {
+	if !strings.HasPrefix(path, "~") {
+		return path
+	}
+	i := strings.IndexByte(path, '/')
+	if i < 0 {
+		i = len(path)
+	}
+	if i != 1 {
+		// ~user isn't supported
+		return path
+	}
+	home, ok := os.LookupEnv("HOME")
+	if !ok {
+		if profile := os.Getenv("USERPROFILE"); profile != "" {
+			home = profile
+		} else {
+			return path
+		}
+	}
+	home = strings.TrimRight(filepath.ToSlash(home), "/")
+	if home == "" {
+		home = "/"
+	}
+	return home + path[i:]
+}
+
+func init() {
+	methods := []*py.Method{
+		pathFunc("abspath", "abspath(path) -> the absolute version of path", absPath),
+		pathFunc("basename", "basename(path) -> the final component of path", func(path string) (string, error) {
+			_, tail := split(path)
+			return tail, nil
+		}),
+		pathFunc("dirname", "dirname(path) -> the directory component of path", func(path string) (string, error) {
+			head, _ := split(path)
+			return head, nil
+		}),
+		pathTest("exists", "exists(path) -> whether path refers to an existing path.\n\nBroken symbolic links are False.", func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}),
+		pathTest("lexists", "lexists(path) -> whether path exists.\n\nBroken symbolic links are True.", func(path string) bool {
+			_, err := os.Lstat(path)
+			return err == nil
+		}),
+		pathFunc("expanduser", "expanduser(path) -> path with an initial ~ replaced by the home directory", func(path string) (string, error) {
+			return expandUser(path), nil
+		}),
+		pathStat("getatime", "getatime(path) -> the last access time of path", func(fi os.FileInfo) py.Object {
+			return newStatResult(fi).times[0]
+		}),
+		pathStat("getmtime", "getmtime(path) -> the last modification time of path", func(fi os.FileInfo) py.Object {
+			return newStatResult(fi).times[1]
+		}),
+		pathStat("getctime", "getctime(path) -> the metadata change time of path", func(fi os.FileInfo) py.Object {
+			return newStatResult(fi).times[2]
+		}),
+		pathStat("getsize", "getsize(path) -> the size of path", func(fi os.FileInfo) py.Object {
+			return py.Int(fi.Size())
+		}),
+		pathTest("isabs", "isabs(path) -> whether path is absolute", func(path string) bool {
+			return strings.HasPrefix(path, "/") || filepath.IsAbs(path)
+		}),
+		pathTest("isdir", "isdir(path) -> whether path is an existing directory", func(path string) bool {
+			fi, err := os.Stat(path)
+			return err == nil && fi.IsDir()
+		}),
+		pathTest("isfile", "isfile(path) -> whether path is an existing regular file", func(path string) bool {
+			fi, err := os.Stat(path)
+			return err == nil && fi.Mode().IsRegular()
+		}),
+		pathTest("islink", "islink(path) -> whether path is a symbolic link", func(path string) bool {
+			fi, err := os.Lstat(path)
+			return err == nil && fi.Mode()&os.ModeSymlink != 0
+		}),
+		py.MustNewMethod("join", func(self py.Object, args py.Tuple) (py.Object, error) {
+			if len(args) == 0 {
+				return nil, py.ExceptionNewf(py.TypeError, "join() missing 1 required positional argument: 'a'")
+			}
+			paths, err := pathArgs("join", args)
+			if err != nil {
+				return nil, err
+			}
+			return py.String(join(paths...)), nil
+		}, 0, "join(a, *p) -> the paths joined with /.\n\nAn absolute path discards the paths before it.  An empty last part\nmakes the result end in a separator."),
+		pathFunc("normpath", "normpath(path) -> path with redundant separators and up-level references collapsed", func(path string) (string, error) {
+			return normPath(path), nil
+		}),
+		pathFunc("realpath", "realpath(path) -> the canonical path of path, eliminating any symbolic links", func(path string) (string, error) {
+			abs, err := absPath(path)
+			if err != nil {
+				return "", err
+			}
+			real, err := filepath.EvalSymlinks(abs)
+			if err != nil {
+				// Like posixpath, leave any part not found alone
+				return abs, nil
+			}
+			return filepath.ToSlash(real), nil
+		}),
+		py.MustNewMethod("relpath", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var path py.Object
+			var start py.Object = py.None
+			err := py.ParseTupleAndKeywords(args, kwargs, "O|O:relpath", []string{"path", "start"}, &path, &start)
+			if err != nil {
+				return nil, err
+			}
+			if start == py.None {
+				start = py.String(".")
+			}
+			paths, err := pathArgs("relpath", py.Tuple{path, start})
+			if err != nil {
+				return nil, err
+			}
+			result, err := relPath(paths[0], paths[1])
+			if err != nil {
+				return nil, err
+			}
+			return py.String(result), nil
+		}, 0, "relpath(path, start=os.curdir) -> a relative version of path from start"),
+		py.MustNewMethod("split", func(self, arg py.Object) (py.Object, error) {
+			paths, err := pathArgs("split", py.Tuple{arg})
+			if err != nil {
+				return nil, err
+			}
+			head, tail := split(paths[0])
+			return py.Tuple{py.String(head), py.String(tail)}, nil
+		}, 0, "split(path) -> (head, tail) where tail is everything after the final slash"),
+		py.MustNewMethod("splitext", func(self, arg py.Object) (py.Object, error) {
+			paths, err := pathArgs("splitext", py.Tuple{arg})
+			if err != nil {
+				return nil, err
+			}
+			root, ext := splitExt(paths[0])
+			return py.Tuple{py.String(root), py.String(ext)}, nil
+		}, 0, "splitext(path) -> (root, ext) where ext is empty or begins with a period"),
+	}
+	globals := py.StringDict{
+		"curdir":  py.String("."),
+		"pardir":  py.String(".."),
+		"extsep":  py.String("."),
+		"sep":     py.String("/"),
+		"pathsep": py.String(filepath.ListSeparator),
+		"altsep":  py.None,
+		"devnull": py.String(devNull()),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "posixpath",
+		Doc:     path_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The result of os.stat
+
+package os
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/go-python/gpython/py"
+)
+
+var StatResultType = py.NewType("stat_result", `stat_result: Result from stat, fstat, or lstat.
+
+The attributes are st_mode, st_ino, st_dev, st_nlink, st_uid, st_gid,
+st_size, st_atime, st_mtime and st_ctime.  Indexing gives the first ten
+of them as a tuple would with the times as integers.`)
+
+// The names of the fields of a stat_result in order
+var statFields = []string{"st_mode", "st_ino", "st_dev", "st_nlink", "st_uid", "st_gid", "st_size", "st_atime", "st_mtime", "st_ctime"}
+
+// StatResult is a stat_result
+type StatResult struct {
+	fields py.Tuple // the fields indexed by position
+	times  [3]py.Float
+}
+
+// Type of this object
+func (s *StatResult) Type() *py.Type {
+	return StatResultType
+}
+
+// File mode bits of the st_mode field
+const (
+	sIFMT   = 0170000
+	sIFSOCK = 0140000
+	sIFLNK  = 0120000
+	sIFREG  = 0100000
+	sIFBLK  = 0060000
+	sIFDIR  = 0040000
+	sIFCHR  = 0020000
+	sIFIFO  = 0010000
+	sISUID  = 0004000
+	sISGID  = 0002000
+	sISVTX  = 0001000
+)
+
+// Returns the st_mode of fi from its os.FileMode
+func fileModeBits(fi os.FileInfo) int {
+	mode := fi.Mode()
+	bits := int(mode.Perm())
+	switch {
+	case mode&os.ModeDir != 0:
+		bits |= sIFDIR
+	case mode&os.ModeSymlink != 0:
+		bits |= sIFLNK
+	case mode&os.ModeNamedPipe != 0:
+		bits |= sIFIFO
+	case mode&os.ModeSocket != 0:
+		bits |= sIFSOCK
+	case mode&os.ModeCharDevice != 0:
+		bits |= sIFCHR
+	case mode&os.ModeDevice != 0:
+		bits |= sIFBLK
+	default:
+		bits |= sIFREG
+	}
+	if mode&os.ModeSetuid != 0 {
+		bits |= sISUID
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= sISGID
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= sISVTX
+	}
+	return bits
+}
+
+// Makes the stat_result of fi
+//
+// Go doesn't give the access and change times portably so these
+// are the modification time.
+func newStatResult(fi os.FileInfo) *StatResult {
+	st := sysStat(fi)
+	mtime := py.Float(fi.ModTime().UnixNano()) / 1e9
+	s := &StatResult{
+		fields: py.Tuple{
+			py.Int(st.mode),
+			py.Int(st.ino),
+			py.Int(st.dev),
+			py.Int(st.nlink),
+			py.Int(st.uid),
+			py.Int(st.gid),
+			py.Int(fi.Size()),
+			py.Int(fi.ModTime().Unix()),
+			py.Int(fi.ModTime().Unix()),
+			py.Int(fi.ModTime().Unix()),
+		},
+		times: [3]py.Float{mtime, mtime, mtime},
+	}
+	return s
+}
+
+// The fields of a stat_result which depend on the platform
+type platformStat struct {
+	mode, ino, dev, nlink, uid, gid int64
+}
+
+func (s *StatResult) M__getitem__(key py.Object) (py.Object, error) {
+	return s.fields.M__getitem__(key)
+}
+
+func (s *StatResult) M__len__() (py.Object, error) {
+	return py.Int(len(s.fields)), nil
+}
+
+func (s *StatResult) M__iter__() (py.Object, error) {
+	return py.NewIterator(s.fields), nil
+}
+
+// Returns the fields of other if it is a stat_result
+func statTuple(other py.Object) py.Object {
+	if o, ok := other.(*StatResult); ok {
+		return o.fields
+	}
+	return other
+}
+
+func (s *StatResult) M__eq__(other py.Object) (py.Object, error) {
+	return s.fields.M__eq__(statTuple(other))
+}
+
+func (s *StatResult) M__ne__(other py.Object) (py.Object, error) {
+	return s.fields.M__ne__(statTuple(other))
+}
+
+func (s *StatResult) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("os.stat_result(")
+	for i, name := range statFields {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := py.ReprAsString(s.fields[i])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(name + "=" + repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+func init() {
+	for i, name := range statFields {
+		i := i
+		StatResultType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				s := self.(*StatResult)
+				if i >= 7 {
+					return s.times[i-7], nil
+				}
+				return s.fields[i], nil
+			},
+		}
+	}
+}
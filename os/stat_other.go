@@ -0,0 +1,18 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows || plan9
+// +build windows plan9
+
+package os
+
+import (
+	"os"
+)
+
+// Returns the fields of the stat_result of fi which has no inode,
+// device or owner
+func sysStat(fi os.FileInfo) platformStat {
+	return platformStat{mode: int64(fileModeBits(fi)), nlink: 1}
+}
@@ -0,0 +1,29 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package os
+
+import (
+	"os"
+	"syscall"
+)
+
+// Returns the fields of the stat_result of fi from the stat system call
+func sysStat(fi os.FileInfo) platformStat {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return platformStat{mode: int64(fileModeBits(fi)), nlink: 1}
+	}
+	return platformStat{
+		mode:  int64(st.Mode),
+		ino:   int64(st.Ino),
+		dev:   int64(st.Dev),
+		nlink: int64(st.Nlink),
+		uid:   int64(st.Uid),
+		gid:   int64(st.Gid),
+	}
+}
@@ -0,0 +1,147 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+from libtest import *
+
+# The directory the tests work in, removed by the Go test when it
+# finishes
+TMP = __file__[:-3] + ".tmp"
+
+doc = "constants"
+assert os.name in ("posix", "nt")
+assert os.curdir == "."
+assert os.pardir == ".."
+assert os.extsep == "."
+assert os.sep in ("/", "\\")
+assert os.pathsep in (":", ";")
+assert os.linesep in ("\n", "\r\n")
+assert os.error is OSError
+import os.path
+assert os.path is __import__("sys").modules["os.path"]
+
+doc = "environ"
+os.environ["GPYTHON_TEST"] = "value"
+assert os.environ["GPYTHON_TEST"] == "value"
+assert "GPYTHON_TEST" in os.environ
+assert "GPYTHON_TEST" in list(os.environ)
+assert "GPYTHON_TEST" in os.environ.keys()
+assert ("GPYTHON_TEST", "value") in os.environ.items()
+assert os.environ.copy()["GPYTHON_TEST"] == "value"
+assert os.getenv("GPYTHON_TEST") == "value"
+assert os.environ.get("GPYTHON_TEST") == "value"
+assert len(os.environ) == len(os.environ.keys())
+del os.environ["GPYTHON_TEST"]
+assert "GPYTHON_TEST" not in os.environ
+assertRaises(KeyError, lambda: os.environ["GPYTHON_TEST"])
+assert os.getenv("GPYTHON_TEST") is None
+assert os.getenv("GPYTHON_TEST", "default") == "default"
+assert os.environ.get("GPYTHON_TEST", 1) == 1
+assert os.environ.setdefault("GPYTHON_TEST", "set") == "set"
+assert os.environ.setdefault("GPYTHON_TEST", "again") == "set"
+assert os.environ.pop("GPYTHON_TEST") == "set"
+assert os.environ.pop("GPYTHON_TEST", None) is None
+assertRaises(KeyError, os.environ.pop, "GPYTHON_TEST")
+os.environ.update({"GPYTHON_TEST": "updated"})
+assert os.environ["GPYTHON_TEST"] == "updated"
+os.unsetenv("GPYTHON_TEST")
+assertRaises(TypeError, os.environ.__setitem__, "GPYTHON_TEST", 1)
+assert repr(os.environ).startswith("environ({")
+
+doc = "getcwd and chdir"
+cwd = os.getcwd()
+assert os.path.isabs(cwd)
+assert isinstance(os.getcwdb(), bytes)
+assertRaises(FileNotFoundError, os.chdir, "not-existent.dir")
+assertRaises(TypeError, os.chdir, None)
+
+doc = "mkdir"
+os.mkdir(TMP)
+assert os.path.isdir(TMP)
+assertRaises(FileExistsError, os.mkdir, TMP)
+deep = os.path.join(TMP, "a", "b", "c")
+assertRaises(FileNotFoundError, os.mkdir, deep)
+os.makedirs(deep)
+assert os.path.isdir(deep)
+assertRaises(FileExistsError, os.makedirs, deep)
+os.makedirs(deep, exist_ok=True)
+
+doc = "listdir"
+with open(os.path.join(TMP, "file.txt"), "w") as f:
+    f.write("hello")
+assert sorted(os.listdir(TMP)) == ["a", "file.txt"]
+os.chdir(TMP)
+try:
+    assert sorted(os.listdir()) == ["a", "file.txt"]
+    assert os.listdir("a") == ["b"]
+    assert os.path.samefile if hasattr(os.path, "samefile") else True
+finally:
+    os.chdir(cwd)
+assert os.getcwd() == cwd
+assertRaises(FileNotFoundError, os.listdir, "not-existent.dir")
+assertRaises(NotADirectoryError, os.listdir, os.path.join(TMP, "file.txt"))
+
+doc = "stat"
+name = os.path.join(TMP, "file.txt")
+st = os.stat(name)
+assert st.st_size == 5
+assert st[6] == 5
+assert len(st) == 10
+assert st.st_mode & 0o170000 == 0o100000
+assert os.stat(TMP).st_mode & 0o170000 == 0o040000
+assert isinstance(st.st_mtime, float)
+assert st[8] == int(st.st_mtime)
+assert os.lstat(name).st_size == 5
+assert repr(st).startswith("os.stat_result(st_mode=")
+assert os.path.getsize(name) == 5
+assert os.path.getmtime(name) == st.st_mtime
+try:
+    os.stat("not-existent.file")
+except FileNotFoundError as e:
+    assert e.errno == 2
+    assert e.filename == "not-existent.file"
+    assert str(e) == "[Errno 2] No such file or directory: 'not-existent.file'", str(e)
+else:
+    assert False, "FileNotFoundError not raised"
+
+doc = "rename and remove"
+new = os.path.join(TMP, "new.txt")
+os.rename(name, new)
+assert not os.path.exists(name)
+assert os.path.isfile(new)
+with open(name, "w") as f:
+    f.write("replaced")
+os.replace(name, new)
+with open(new) as f:
+    assert f.read() == "replaced"
+assertRaises(FileNotFoundError, os.rename, name, new)
+assertRaises(IsADirectoryError if os.name == "posix" else OSError, os.remove, TMP)
+os.remove(new)
+assert not os.path.exists(new)
+assertRaises(FileNotFoundError, os.remove, new)
+with open(name, "w") as f:
+    pass
+os.unlink(name)
+assertRaises(OSError, os.rmdir, os.path.join(TMP, "a"))
+os.rmdir(deep)
+os.rmdir(os.path.join(TMP, "a", "b"))
+os.rmdir(os.path.join(TMP, "a"))
+assert os.listdir(TMP) == []
+os.rmdir(TMP)
+assert not os.path.exists(TMP)
+
+doc = "fspath"
+class P:
+    def __fspath__(self):
+        return "path"
+assert os.fspath("x") == "x"
+assert os.fspath(b"x") == b"x"
+assert os.fspath(P()) == "path"
+assert os.path.join(P(), "a") == "path/a"
+assertRaises(TypeError, os.fspath, 1)
+
+doc = "getpid"
+assert isinstance(os.getpid(), int)
+
+doc = "finished"
@@ -0,0 +1,87 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os.path
+from os import path
+from libtest import *
+
+doc = "join"
+assert path.join("a") == "a"
+assert path.join("a", "b", "c") == "a/b/c"
+assert path.join("a/", "b") == "a/b"
+assert path.join("a", "/b", "c") == "/b/c"
+assert path.join("a", "") == "a/"
+assert path.join("", "a") == "a"
+assert path.join("", "") == ""
+assertRaises(TypeError, path.join)
+assertRaises(TypeError, path.join, "a", 1)
+
+doc = "split"
+assert path.split("a/b/c") == ("a/b", "c")
+assert path.split("a/b/") == ("a/b", "")
+assert path.split("c") == ("", "c")
+assert path.split("/c") == ("/", "c")
+assert path.split("//c") == ("//", "c")
+assert path.split("a//c") == ("a", "c")
+assert path.split("") == ("", "")
+
+doc = "basename and dirname"
+assert path.basename("/a/b.txt") == "b.txt"
+assert path.basename("/a/b/") == ""
+assert path.dirname("/a/b.txt") == "/a"
+assert path.dirname("b.txt") == ""
+assert path.dirname("/") == "/"
+
+doc = "splitext"
+assert path.splitext("a/b.txt") == ("a/b", ".txt")
+assert path.splitext("a/b.tar.gz") == ("a/b.tar", ".gz")
+assert path.splitext("a/.bashrc") == ("a/.bashrc", "")
+assert path.splitext("a/..bashrc.x") == ("a/..bashrc", ".x")
+assert path.splitext("a.b/c") == ("a.b/c", "")
+assert path.splitext("a/b.") == ("a/b", ".")
+
+doc = "normpath"
+assert path.normpath("") == "."
+assert path.normpath("a//b/./c/../d") == "a/b/d"
+assert path.normpath("../a/..") == ".."
+assert path.normpath("/../a") == "/a"
+assert path.normpath("//a/b") == "//a/b"
+assert path.normpath("///a/b/") == "/a/b"
+assert path.normpath("a/..") == "."
+
+doc = "isabs"
+assert path.isabs("/a")
+assert not path.isabs("a/b")
+
+doc = "abspath and relpath"
+cwd = os.getcwd()
+assert path.abspath("a/../b") == path.join(cwd, "b")
+assert path.abspath("/x/y/..") == "/x"
+assert path.relpath(path.join(cwd, "a", "b")) == "a/b"
+assert path.relpath("/a/b", "/a/c/d") == "../../b"
+assert path.relpath("/a", "/a") == "."
+assertRaises(ValueError, path.relpath, "")
+
+doc = "file system"
+assert path.exists(__file__)
+assert path.isfile(__file__)
+assert not path.isdir(__file__)
+assert path.isdir(path.dirname(path.abspath(__file__)))
+assert not path.exists("not-existent.file")
+assert not path.isfile("not-existent.file")
+assert not path.isdir("not-existent.file")
+assert not path.islink(__file__)
+assert path.lexists(__file__)
+assert path.getsize(__file__) > 0
+assertRaises(FileNotFoundError, path.getsize, "not-existent.file")
+assert path.realpath(__file__) == path.realpath(path.abspath(__file__))
+
+doc = "expanduser"
+home = os.environ.get("HOME")
+if home is not None and home != "/":
+    assert path.expanduser("~/x") == path.join(home, "x")
+    assert path.expanduser("~") == path.normpath(home)
+assert path.expanduser("a/~") == "a/~"
+
+doc = "finished"
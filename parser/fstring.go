@@ -0,0 +1,350 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Parse formatted string literals (f-strings)
+
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/ast"
+	"github.com/go-python/gpython/py"
+)
+
+// Conversions for FormattedValue
+const (
+	conversionNone  = -1
+	conversionStr   = 's'
+	conversionRepr  = 'r'
+	conversionASCII = 'a'
+)
+
+// An fstringParser splits the body of a formatted string literal into
+// literal text and expressions
+type fstringParser struct {
+	body string  // the body of the string without quotes or prefix
+	raw  bool    // set if this is an rf"" string
+	i    int     // current position in body
+	pos  ast.Pos // position of the start of body in the file
+}
+
+// Parses the body of a formatted string literal starting at pos
+func parseFString(body string, raw bool, pos ast.Pos) (*ast.JoinedStr, error) {
+	p := &fstringParser{body: body, raw: raw, pos: pos}
+	values, err := p.parse(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.body) {
+		return nil, fmt.Errorf("f-string: single '}' is not allowed")
+	}
+	return &ast.JoinedStr{ExprBase: ast.ExprBase{Pos: pos}, Values: values}, nil
+}
+
+// Parses literal text and replacement fields until the end of the body
+// or an unmatched '}' which ends a format spec when depth > 0
+func (p *fstringParser) parse(depth int) ([]ast.Expr, error) {
+	var values []ast.Expr
+	literal := new(bytes.Buffer)
+	flush := func() error {
+		if literal.Len() == 0 {
+			return nil
+		}
+		s := literal
+		if !p.raw {
+			var err error
+			s, err = DecodeEscape(literal, false)
+			if err != nil {
+				return fmt.Errorf("(unicode error) %v", err)
+			}
+		}
+		values = append(values, &ast.Str{ExprBase: ast.ExprBase{Pos: p.pos}, S: py.String(s.String())})
+		literal = new(bytes.Buffer)
+		return nil
+	}
+	for p.i < len(p.body) {
+		c := p.body[p.i]
+		switch {
+		case c == '\\' && !p.raw && p.i+1 < len(p.body):
+			// Copy escapes through for DecodeEscape including
+			// any braces in \N{...}
+			end := p.i + 2
+			if p.body[p.i+1] == 'N' && end < len(p.body) && p.body[end] == '{' {
+				if close := strings.IndexByte(p.body[end:], '}'); close >= 0 {
+					end += close + 1
+				}
+			}
+			literal.WriteString(p.body[p.i:end])
+			p.i = end
+		case c == '{' && strings.HasPrefix(p.body[p.i:], "{{") && depth == 0:
+			literal.WriteByte('{')
+			p.i += 2
+		case c == '}' && strings.HasPrefix(p.body[p.i:], "}}") && depth == 0:
+			literal.WriteByte('}')
+			p.i += 2
+		case c == '{':
+			if depth >= 2 {
+				return nil, fmt.Errorf("f-string: expressions nested too deeply")
+			}
+			debugText, value, err := p.replacementField(depth)
+			if err != nil {
+				return nil, err
+			}
+			// Any {expr=} text becomes part of the preceding literal
+			literal.WriteString(debugText)
+			err = flush()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		case c == '}':
+			// End of a format spec or a single '}'
+			err := flush()
+			return values, err
+		default:
+			literal.WriteByte(c)
+			p.i++
+		}
+	}
+	err := flush()
+	return values, err
+}
+
+// Parses the replacement field {expr=!conversion:format_spec} at the
+// current position
+//
+// If the expression is followed by '=' then its text, including the
+// '=' and any whitespace, is returned as debugText.
+func (p *fstringParser) replacementField(depth int) (debugText string, value ast.Expr, err error) {
+	p.i++
+	start := p.i
+	end, err := p.findExpressionEnd()
+	if err != nil {
+		return "", nil, err
+	}
+	text := p.body[start:end]
+	if strings.TrimSpace(text) == "" {
+		return "", nil, fmt.Errorf("f-string: empty expression not allowed")
+	}
+	value, err = p.parseExpression(text, start)
+	if err != nil {
+		return "", nil, err
+	}
+	p.i = end
+	fv := &ast.FormattedValue{ExprBase: ast.ExprBase{Pos: p.pos}, Value: value, Conversion: conversionNone}
+	if p.i < len(p.body) && p.body[p.i] == '=' {
+		p.i++
+		// Include any whitespace after the '='
+		for p.i < len(p.body) && strings.IndexByte(" \t\n\r\f", p.body[p.i]) >= 0 {
+			p.i++
+		}
+		debugText = p.body[start:p.i]
+	}
+	if p.i < len(p.body) && p.body[p.i] == '!' {
+		p.i++
+		if p.i >= len(p.body) {
+			return "", nil, fmt.Errorf("f-string: expecting '}'")
+		}
+		switch c := p.body[p.i]; c {
+		case conversionStr, conversionRepr, conversionASCII:
+			fv.Conversion = int(c)
+		default:
+			return "", nil, fmt.Errorf("f-string: invalid conversion character: expected 's', 'r', or 'a'")
+		}
+		p.i++
+	}
+	if p.i < len(p.body) && p.body[p.i] == ':' {
+		p.i++
+		values, err := p.parse(depth + 1)
+		if err != nil {
+			return "", nil, err
+		}
+		fv.FormatSpec = &ast.JoinedStr{ExprBase: ast.ExprBase{Pos: p.pos}, Values: values}
+	}
+	if p.i >= len(p.body) || p.body[p.i] != '}' {
+		return "", nil, fmt.Errorf("f-string: expecting '}'")
+	}
+	p.i++
+	// {expr=} defaults to the repr unless there is a format spec
+	if debugText != "" && fv.Conversion == conversionNone && fv.FormatSpec == nil {
+		fv.Conversion = conversionRepr
+	}
+	return debugText, fv, nil
+}
+
+// Finds the end of the expression starting at the current position
+//
+// This is the first '!', ':', '=' or '}' which isn't nested in
+// brackets or a string and isn't part of an operator such as '!=' or
+// '=='.
+func (p *fstringParser) findExpressionEnd() (int, error) {
+	var brackets []byte
+	var quote string
+	for i := p.i; i < len(p.body); i++ {
+		c := p.body[i]
+		if c == '\\' {
+			return 0, fmt.Errorf("f-string expression part cannot include a backslash")
+		}
+		if quote != "" {
+			if strings.HasPrefix(p.body[i:], quote) {
+				i += len(quote) - 1
+				quote = ""
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = string(c)
+			if strings.HasPrefix(p.body[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+				i += 2
+			}
+		case '(', '[', '{':
+			brackets = append(brackets, c)
+		case ')', ']', '}':
+			if len(brackets) == 0 {
+				if c == '}' {
+					return i, nil
+				}
+				return 0, fmt.Errorf("f-string: unmatched '%c'", c)
+			}
+			open := brackets[len(brackets)-1]
+			if (open == '(' && c != ')') || (open == '[' && c != ']') || (open == '{' && c != '}') {
+				return 0, fmt.Errorf("f-string: closing parenthesis '%c' does not match opening parenthesis '%c'", c, open)
+			}
+			brackets = brackets[:len(brackets)-1]
+		case '#':
+			return 0, fmt.Errorf("f-string expression part cannot include '#'")
+		case '!':
+			if len(brackets) == 0 && !strings.HasPrefix(p.body[i:], "!=") {
+				return i, nil
+			}
+			if strings.HasPrefix(p.body[i:], "!=") {
+				i++
+			}
+		case ':':
+			if len(brackets) == 0 {
+				return i, nil
+			}
+		case '=':
+			if strings.HasPrefix(p.body[i:], "==") {
+				i++
+			} else if len(brackets) == 0 && (i == p.i || strings.IndexByte("<>=!", p.body[i-1]) < 0) {
+				return i, nil
+			}
+		}
+	}
+	if quote != "" {
+		return 0, fmt.Errorf("f-string: unterminated string")
+	}
+	if len(brackets) != 0 {
+		return 0, fmt.Errorf("f-string: unmatched '%c'", brackets[len(brackets)-1])
+	}
+	return 0, fmt.Errorf("f-string: expecting '}'")
+}
+
+// Parses text, which starts at offset in the body, as an expression
+func (p *fstringParser) parseExpression(text string, offset int) (ast.Expr, error) {
+	mod, err := Parse(strings.NewReader("("+text+")"), "<fstring>", "eval")
+	if err != nil {
+		msg := "invalid syntax"
+		if exc, ok := err.(*py.Exception); ok {
+			if args, ok := exc.Args.(py.Tuple); ok && len(args) > 0 {
+				if s, ok := args[0].(py.String); ok {
+					msg = string(s)
+				}
+			}
+		}
+		return nil, fmt.Errorf("f-string: %s", msg)
+	}
+	expr := mod.(*ast.Expression).Body
+	// Fix up the positions to be relative to the file rather than text
+	line, col := p.pos.Lineno, p.pos.ColOffset
+	for _, c := range p.body[:offset] {
+		if c == '\n' {
+			line++
+			col = 0
+		} else {
+			col++
+		}
+	}
+	ast.Walk(expr, func(node ast.Ast) bool {
+		pos := nodePos(node)
+		if pos == nil {
+			return true
+		}
+		if pos.Lineno == 1 {
+			// Account for the ( added to the start of text
+			pos.ColOffset += col - 1
+		}
+		pos.Lineno += line - 1
+		if pos.EndLineno == 1 {
+			pos.EndColOffset += col - 1
+		}
+		if pos.EndLineno != 0 {
+			pos.EndLineno += line - 1
+		}
+		return true
+	})
+	return expr, nil
+}
+
+// Returns the parts of a string literal as a list of expressions
+func fstringValues(s py.Object) []ast.Expr {
+	switch s := s.(type) {
+	case py.String:
+		return []ast.Expr{&ast.Str{S: s}}
+	case *ast.JoinedStr:
+		return s.Values
+	}
+	return nil
+}
+
+// Concatenates the adjacent string literals a and b which may be
+// py.String, py.Bytes or *ast.JoinedStr for f-strings
+func concatStrings(x *yyLex, a, b py.Object) py.Object {
+	switch a := a.(type) {
+	case py.String:
+		if b, ok := b.(py.String); ok {
+			return a + b
+		}
+	case py.Bytes:
+		if b, ok := b.(py.Bytes); ok {
+			return append(a, b...)
+		}
+		x.SyntaxError("cannot mix bytes and nonbytes literals")
+		return a
+	}
+	if _, ok := b.(py.Bytes); ok {
+		x.SyntaxError("cannot mix bytes and nonbytes literals")
+		return a
+	}
+	// At least one is an f-string so join the parts merging
+	// adjacent literals
+	joined := &ast.JoinedStr{}
+	if a, ok := a.(*ast.JoinedStr); ok {
+		joined.Pos = a.Pos
+	} else if b, ok := b.(*ast.JoinedStr); ok {
+		joined.Pos = b.Pos
+	}
+	for _, value := range append(fstringValues(a), fstringValues(b)...) {
+		if str, ok := value.(*ast.Str); ok && str.S == "" {
+			continue
+		}
+		if str, ok := value.(*ast.Str); ok && len(joined.Values) > 0 {
+			if last, ok := joined.Values[len(joined.Values)-1].(*ast.Str); ok {
+				joined.Values[len(joined.Values)-1] = &ast.Str{ExprBase: last.ExprBase, S: last.S + str.S}
+				continue
+			}
+		}
+		if str, ok := value.(*ast.Str); ok && str.Pos == (ast.Pos{}) {
+			str.Pos = joined.Pos
+		}
+		joined.Values = append(joined.Values, value)
+	}
+	return joined
+}
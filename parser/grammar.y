@@ -10,6 +10,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/ast"
 )
@@ -26,18 +27,21 @@ func tupleOrExpr(pos ast.Pos, elts []ast.Expr, optional_comma bool) ast.Expr {
 	}
 }
 
-// Apply trailers (if any) to expr
+// Apply trailers (if any) to expr which starts at pos
 //
 // trailers are half made Call, Subscript or Attribute
-func applyTrailers(expr ast.Expr, trailers []ast.Expr) ast.Expr {
+func applyTrailers(pos ast.Pos, expr ast.Expr, trailers []ast.Expr) ast.Expr {
 	//trailers := $1
 	for _, trailer := range trailers {
 		switch x := trailer.(type) {
 		case *ast.Call:
+			x.Pos = pos
 			x.Func, expr = expr, x
 		case *ast.Subscript:
+			x.Pos = pos
 			x.Value, expr = expr, x
 		case *ast.Attribute:
+			x.Pos = pos
 			x.Value, expr = expr, x
 		default:
 			panic(fmt.Sprintf("Unknown trailer type: %T", expr))
@@ -50,47 +54,94 @@ func applyTrailers(expr ast.Expr, trailers []ast.Expr) ast.Expr {
 func setCtx(yylex yyLexer, expr ast.Expr, ctx ast.ExprContext) {
 	setctxer, ok := expr.(ast.SetCtxer)
 	if !ok {
-		expr_name := ""
-		switch expr.(type) {
-		case *ast.Lambda:
-			expr_name = "lambda"
-		case *ast.Call:
-			expr_name = "function call"
-		case *ast.BoolOp, *ast.BinOp, *ast.UnaryOp:
-			expr_name = "operator"
-		case *ast.GeneratorExp:
-			expr_name = "generator expression"
-		case *ast.Yield, *ast.YieldFrom:
-			expr_name = "yield expression"
-		case *ast.ListComp:
-			expr_name = "list comprehension"
-		case *ast.SetComp:
-			expr_name = "set comprehension"
-		case *ast.DictComp:
-			expr_name = "dict comprehension"
-		case *ast.Dict, *ast.Set, *ast.Num, *ast.Str, *ast.Bytes:
-			expr_name = "literal"
-		case *ast.NameConstant:
-			expr_name = "keyword"
-		case *ast.Ellipsis:
-			expr_name = "Ellipsis"
-		case *ast.Compare:
-			expr_name = "comparison"
-		case *ast.IfExp:
-			expr_name = "conditional expression"
-		default:
-			expr_name = fmt.Sprintf("unexpected %T", expr)
-		}
 		action := "assign to"
 		if ctx == ast.Del {
 			action = "delete"
 		}
-		yylex.(*yyLex).SyntaxErrorf("can't %s %s", action, expr_name)
+		yylex.(*yyLex).SyntaxErrorf("can't %s %s", action, exprName(expr))
 		return
 	}
 	setctxer.SetCtx(ctx)
 }
 
+// Returns the name of the kind of expr for use in error messages
+func exprName(expr ast.Expr) string {
+	switch expr.(type) {
+	case *ast.Attribute:
+		return "attribute"
+	case *ast.Subscript:
+		return "subscript"
+	case *ast.Starred:
+		return "starred"
+	case *ast.Name:
+		return "name"
+	case *ast.List:
+		return "list"
+	case *ast.Tuple:
+		return "tuple"
+	case *ast.Lambda:
+		return "lambda"
+	case *ast.Call:
+		return "function call"
+	case *ast.BoolOp, *ast.BinOp, *ast.UnaryOp:
+		return "operator"
+	case *ast.GeneratorExp:
+		return "generator expression"
+	case *ast.Yield, *ast.YieldFrom:
+		return "yield expression"
+	case *ast.ListComp:
+		return "list comprehension"
+	case *ast.SetComp:
+		return "set comprehension"
+	case *ast.DictComp:
+		return "dict comprehension"
+	case *ast.Dict, *ast.Set, *ast.Num, *ast.Str, *ast.Bytes, *ast.JoinedStr, *ast.FormattedValue:
+		return "literal"
+	case *ast.NameConstant:
+		return "keyword"
+	case *ast.Ellipsis:
+		return "Ellipsis"
+	case *ast.Compare:
+		return "comparison"
+	case *ast.IfExp:
+		return "conditional expression"
+	case *ast.NamedExpr:
+		return "named expression"
+	}
+	return fmt.Sprintf("unexpected %T", expr)
+}
+
+// Makes the assignment expression target := value checking the
+// target is a plain name
+func namedExpr(yylex yyLexer, pos ast.Pos, target ast.Expr, value ast.Expr) ast.Expr {
+	if _, ok := target.(*ast.Name); !ok {
+		yylex.(*yyLex).SyntaxErrorf("cannot use named assignment with %s", exprName(target))
+	}
+	setCtx(yylex, target, ast.Store)
+	return &ast.NamedExpr{ExprBase: ast.ExprBase{Pos: pos}, Target: target, Value: value}
+}
+
+// Makes the annotated assignment target: annotation = value, where
+// value may be nil, checking the target can be annotated
+func annAssign(yylex yyLexer, pos ast.Pos, target, annotation, value ast.Expr) ast.Stmt {
+	switch target.(type) {
+	case *ast.Name, *ast.Attribute, *ast.Subscript:
+		setCtx(yylex, target, ast.Store)
+	case *ast.Tuple:
+		yylex.(*yyLex).SyntaxError("only single target (not tuple) can be annotated")
+	case *ast.List:
+		yylex.(*yyLex).SyntaxError("only single target (not list) can be annotated")
+	default:
+		yylex.(*yyLex).SyntaxError("illegal target for annotation")
+	}
+	// A name is simple unless it is in parentheses
+	simple := 0
+	if name, ok := target.(*ast.Name); ok && name.Pos == pos {
+		simple = 1
+	}
+	return &ast.AnnAssign{StmtBase: ast.StmtBase{Pos: pos}, Target: target, Annotation: annotation, Value: value, Simple: simple}
+}
+
 // Set the context for all the items in exprs
 func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 	for i := range exprs {
@@ -98,6 +149,149 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 	}
 }
 
+// Move the arguments before the '/' marker (a nil Arg) in args into
+// Posonlyargs
+func setPosonlyargs(yylex yyLexer, args *ast.Arguments) {
+	for _, arg := range args.Kwonlyargs {
+		if arg == nil {
+			yylex.(*yyLex).SyntaxError("/ must be ahead of *")
+			return
+		}
+	}
+	for i, arg := range args.Args {
+		if arg != nil {
+			continue
+		}
+		if i == 0 {
+			yylex.(*yyLex).SyntaxError("at least one argument must precede /")
+			return
+		}
+		rest := args.Args[i+1:]
+		for _, arg := range rest {
+			if arg == nil {
+				yylex.(*yyLex).SyntaxError("/ may appear only once")
+				return
+			}
+		}
+		args.Posonlyargs = args.Args[:i]
+		args.Args = rest
+		return
+	}
+}
+
+// Add the half made Call arg holding a single argument to call,
+// checking the arguments are in a legal order
+func addArgument(yylex yyLexer, call *ast.Call, arg *ast.Call) {
+	if call.Func != nil || arg.Func != nil {
+		yylex.(*yyLex).SyntaxError("Generator expression must be parenthesized")
+		return
+	}
+	if len(arg.Args) != 0 {
+		_, isStarred := arg.Args[0].(*ast.Starred)
+		for _, kw := range call.Keywords {
+			if kw.Arg == "" {
+				if isStarred {
+					yylex.(*yyLex).SyntaxError("iterable argument unpacking follows keyword argument unpacking")
+				} else {
+					yylex.(*yyLex).SyntaxError("positional argument follows keyword argument unpacking")
+				}
+				return
+			}
+		}
+		if !isStarred && len(call.Keywords) != 0 {
+			yylex.(*yyLex).SyntaxError("positional argument follows keyword argument")
+			return
+		}
+	}
+	call.Args = append(call.Args, arg.Args...)
+	call.Keywords = append(call.Keywords, arg.Keywords...)
+}
+
+// Move a single trailing *args and **kwargs in call into Starargs and
+// Kwargs.  Calls with any other unpackings are left as they are.
+func setStarargs(call *ast.Call) {
+	starred := 0
+	for _, arg := range call.Args {
+		if _, ok := arg.(*ast.Starred); ok {
+			starred++
+		}
+	}
+	kwargs := 0
+	for _, kw := range call.Keywords {
+		if kw.Arg == "" {
+			kwargs++
+		}
+	}
+	nargs, nkeywords := len(call.Args), len(call.Keywords)
+	if starred > 1 || kwargs > 1 {
+		return
+	}
+	if starred == 1 {
+		if _, ok := call.Args[nargs-1].(*ast.Starred); !ok {
+			return
+		}
+	}
+	if kwargs == 1 && call.Keywords[nkeywords-1].Arg != "" {
+		return
+	}
+	if starred == 1 {
+		call.Starargs = call.Args[nargs-1].(*ast.Starred).Value
+		call.Args = call.Args[:nargs-1]
+	}
+	if kwargs == 1 {
+		call.Kwargs = call.Keywords[nkeywords-1].Value
+		call.Keywords = call.Keywords[:nkeywords-1]
+	}
+}
+
+// Make an expression which loads a dotted name, eg "a.b.c"
+func dottedNameExpr(pos ast.Pos, dottedName string) ast.Expr {
+	names := strings.Split(dottedName, ".")
+	var expr ast.Expr = &ast.Name{ExprBase: ast.ExprBase{Pos: pos}, Id: ast.Identifier(names[0]), Ctx: ast.Load}
+	for _, name := range names[1:] {
+		expr = &ast.Attribute{ExprBase: ast.ExprBase{Pos: pos}, Value: expr, Attr: ast.Identifier(name), Ctx: ast.Load}
+	}
+	return expr
+}
+
+// Make the pattern for a list of patterns, which is a sequence
+// pattern unless it is a single pattern without a trailing comma
+func patternsOrPattern(yylex yyLexer, pos ast.Pos, patterns []ast.Pattern, optional_comma bool) ast.Pattern {
+	if optional_comma || len(patterns) != 1 {
+		return &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: pos}, Patterns: patterns}
+	}
+	if _, ok := patterns[0].(*ast.MatchStar); ok {
+		yylex.(*yyLex).SyntaxError("invalid syntax")
+	}
+	return patterns[0]
+}
+
+// Make a value pattern from a literal, checking it is one which can
+// appear in a pattern
+func literalPattern(yylex yyLexer, pos ast.Pos, value ast.Expr) ast.Pattern {
+	switch x := value.(type) {
+	case *ast.NameConstant:
+		return &ast.MatchSingleton{PatternBase: ast.PatternBase{Pos: pos}, Value: x.Value}
+	case *ast.JoinedStr:
+		yylex.(*yyLex).SyntaxError("patterns may only match literals and attribute lookups")
+	}
+	return &ast.MatchValue{PatternBase: ast.PatternBase{Pos: pos}, Value: value}
+}
+
+// Make a complex literal real +/- imag for a pattern
+func complexLiteral(yylex yyLexer, pos ast.Pos, real ast.Expr, op ast.OperatorNumber, imag py.Object) ast.Expr {
+	n := real
+	if unary, ok := n.(*ast.UnaryOp); ok {
+		n = unary.Operand
+	}
+	if _, ok := n.(*ast.Num).N.(py.Complex); ok {
+		yylex.(*yyLex).SyntaxError("real number required in complex literal")
+	} else if _, ok := imag.(py.Complex); !ok {
+		yylex.(*yyLex).SyntaxError("imaginary number required in complex literal")
+	}
+	return &ast.BinOp{ExprBase: ast.ExprBase{Pos: pos}, Left: real, Op: op, Right: &ast.Num{ExprBase: ast.ExprBase{Pos: pos}, N: imag}}
+}
+
 %}
 
 %union {
@@ -128,20 +322,26 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 	arg		*ast.Arg
 	args		[]*ast.Arg
 	arguments	*ast.Arguments
+	pattern		ast.Pattern
+	patterns	[]ast.Pattern
+	matchmapping	*ast.MatchMapping
+	matchclass	*ast.MatchClass
+	matchcase	*ast.MatchCase
+	matchcases	[]*ast.MatchCase
 }
 
 %type <obj> strings
 %type <mod> inputs file_input single_input eval_input
 %type <stmts> simple_stmt stmt nl_or_stmt small_stmts stmts suite optional_else
-%type <stmt> compound_stmt small_stmt expr_stmt del_stmt pass_stmt flow_stmt import_stmt global_stmt nonlocal_stmt assert_stmt break_stmt continue_stmt return_stmt raise_stmt yield_stmt import_name import_from while_stmt if_stmt for_stmt try_stmt with_stmt funcdef classdef classdef_or_funcdef decorated
+%type <stmt> match_stmt compound_stmt small_stmt expr_stmt del_stmt pass_stmt flow_stmt import_stmt global_stmt nonlocal_stmt assert_stmt break_stmt continue_stmt return_stmt raise_stmt yield_stmt import_name import_from while_stmt if_stmt for_stmt try_stmt with_stmt funcdef classdef classdef_or_funcdef decorated async_stmt async_funcdef
 %type <op> augassign
-%type <expr> expr_or_star_expr expr star_expr xor_expr and_expr shift_expr arith_expr term factor power trailer atom test_or_star_expr test not_test lambdef test_nocond lambdef_nocond or_test and_test comparison testlist testlist_star_expr yield_expr_or_testlist yield_expr yield_expr_or_testlist_star_expr dictorsetmaker sliceop except_clause optional_return_type decorator
-%type <exprs> exprlist testlistraw comp_if comp_iter expr_or_star_exprs test_or_star_exprs tests test_colon_tests trailers equals_yield_expr_or_testlist_star_expr decorators
+%type <expr> namedexpr_test namedexpr_or_star_expr expr_or_star_expr expr star_expr xor_expr and_expr shift_expr arith_expr term factor power atom_expr trailer atom test_or_star_expr test not_test lambdef test_nocond lambdef_nocond or_test and_test comparison testlist testlist_star_expr yield_expr_or_testlist yield_expr yield_expr_or_testlist_star_expr dictorsetmaker sliceop except_clause optional_return_type decorator
+%type <exprs> exprlist comp_if comp_iter expr_or_star_exprs test_or_star_exprs namedexpr_or_star_exprs tests test_colon_tests trailers equals_yield_expr_or_testlist_star_expr decorators
 %type <cmpop> comp_op
 %type <comma> optional_comma
 %type <comprehensions> comp_for
 %type <slice> subscript subscriptlist subscripts
-%type <call> argument arguments optional_arguments arguments2 arglist optional_arglist_call optional_arglist
+%type <call> argument arguments arglist optional_arglist_call optional_arglist
 %type <level> dot dots
 %type <str> dotted_name from_arg
 %type <identifiers> names
@@ -154,6 +354,13 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 %type <arg> vfpdeftest vfpdef optional_vfpdef tfpdeftest tfpdef optional_tfpdef
 %type <args> vfpdeftests vfpdeftests1 tfpdeftests tfpdeftests1
 %type <arguments> varargslist parameters optional_typedargslist typedargslist
+%type <expr> guard name_or_attr literal_expr signed_number
+%type <pattern> patterns pattern or_pattern closed_pattern maybe_star_pattern
+%type <patterns> maybe_star_patterns closed_patterns
+%type <matchmapping> mapping_items
+%type <matchclass> class_arguments
+%type <matchcase> case_block
+%type <matchcases> case_blocks
 
 %token NEWLINE
 %token ENDMARKER
@@ -185,6 +392,7 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 %token GTGT // >>
 %token GTGTEQ // >>=
 %token HATEQ // ^=
+%token ATEQ // @=
 %token PIPEEQ // |=
 
 %token FALSE // False
@@ -193,6 +401,8 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 %token AND // and
 %token AS // as
 %token ASSERT // assert
+%token ASYNC // async
+%token AWAIT // await
 %token BREAK // break
 %token CLASS // class
 %token CONTINUE // continue
@@ -221,10 +431,17 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 %token WITH // with
 %token YIELD // yield
 
+%token MATCH // match - only a keyword at the start of a match statement
+%token CASE // case - only a keyword at the start of a case block
+
 %token '(' ')' '[' ']' ':' ',' ';' '+' '-' '*' '/' '|' '&' '<' '>' '=' '.' '%' '{' '}' '^' '~' '@'
 
 %token SINGLE_INPUT FILE_INPUT EVAL_INPUT
 
+%token <obj> FSTRING // f"" formatted string literal
+
+%token COLONEQ // := assignment expression
+
 // Note:  Changing the grammar specified in this file will most likely
 //        require corresponding changes in the parser module
 //        (../Modules/parsermodule.c).  If you can't make the changes to
@@ -332,11 +549,12 @@ optional_arglist_call:
 decorator:
 	'@' dotted_name optional_arglist_call NEWLINE
 	{
-		fn := &ast.Name{ExprBase: ast.ExprBase{Pos: $<pos>$}, Id: ast.Identifier($2), Ctx: ast.Load}
+		fn := dottedNameExpr($<pos>2, $2)
 		if $3 == nil {
 			$$ = fn
 		} else {
 			call := *$3
+			call.Pos = $<pos>2
 			call.Func = fn
 			$$ = &call
 		}
@@ -362,6 +580,10 @@ classdef_or_funcdef:
 	{
 		$$ = $1
 	}
+|	async_funcdef
+	{
+		$$ = $1
+	}
 
 decorated:
 	decorators classdef_or_funcdef
@@ -373,6 +595,9 @@ decorated:
 		case *ast.FunctionDef:
 			x.DecoratorList = $1
 			$$ = x
+		case *ast.AsyncFunctionDef:
+			x.DecoratorList = $1
+			$$ = x
 		default:
 			panic("bad type for decorated")
 		}
@@ -393,6 +618,13 @@ funcdef:
 		$$ = &ast.FunctionDef{StmtBase: ast.StmtBase{Pos: $<pos>$}, Name: ast.Identifier($2), Args: $3, Body: $6, Returns: $4}
 	}
 
+async_funcdef:
+	ASYNC funcdef
+	{
+		fn := $2.(*ast.FunctionDef)
+		$$ = &ast.AsyncFunctionDef{StmtBase: ast.StmtBase{Pos: $<pos>$}, Name: fn.Name, Args: fn.Args, Body: fn.Body, Returns: fn.Returns}
+	}
+
 parameters:
 	'(' optional_typedargslist ')'
 	{
@@ -406,6 +638,7 @@ optional_typedargslist:
 |	typedargslist
 	{
 		$$ = $1
+		setPosonlyargs(yylex, $$)
 	}
 
 // (',' tfpdef ['=' test])*
@@ -420,6 +653,11 @@ tfpdeftest:
 		$$ = $1
 		$<expr>$ = $3
 	}
+|	'/'
+	{
+		$$ = nil
+		$<expr>$ = nil
+	}
 
 tfpdeftests:
 	{
@@ -429,9 +667,7 @@ tfpdeftests:
 |	tfpdeftests ',' tfpdeftest
 	{
 		$$ = append($$, $3)
-		if $<expr>3 != nil {
-			$<exprs>$ = append($<exprs>$, $<expr>3)
-		}
+		$<exprs>$ = append($<exprs>$, $<expr>3)
 	}
 
 tfpdeftests1:
@@ -513,6 +749,11 @@ vfpdeftest:
 		$$ = $1
 		$<expr>$ = $3
 	}
+|	'/'
+	{
+		$$ = nil
+		$<expr>$ = nil
+	}
 
 vfpdeftests:
 	{
@@ -522,9 +763,7 @@ vfpdeftests:
 |	vfpdeftests ',' vfpdeftest
 	{
 		$$ = append($$, $3)
-		if $<expr>3 != nil {
-			$<exprs>$ = append($<exprs>$, $<expr>3)
-		}
+		$<exprs>$ = append($<exprs>$, $<expr>3)
 	}
 
 vfpdeftests1:
@@ -682,6 +921,14 @@ expr_stmt:
 		setCtx(yylex, target, ast.Store)
 		$$ = &ast.AugAssign{StmtBase: ast.StmtBase{Pos: $<pos>$}, Target: target, Op: $2, Value: $3}
 	}
+|	testlist_star_expr ':' test
+	{
+		$$ = annAssign(yylex, $<pos>$, $1, $3, nil)
+	}
+|	testlist_star_expr ':' test '=' yield_expr_or_testlist_star_expr
+	{
+		$$ = annAssign(yylex, $<pos>$, $1, $3, $5)
+	}
 |	testlist_star_expr equals_yield_expr_or_testlist_star_expr
 	{
 		targets := []ast.Expr{$1}
@@ -748,6 +995,27 @@ test_or_star_expr:
 		$$ = $1
 	}
 
+namedexpr_or_star_exprs:
+	namedexpr_or_star_expr
+	{
+		$$ = nil
+		$$ = append($$, $1)
+	}
+|	namedexpr_or_star_exprs ',' namedexpr_or_star_expr
+	{
+		$$ = append($$, $3)
+	}
+
+namedexpr_or_star_expr:
+	namedexpr_test
+	{
+		$$ = $1
+	}
+|	star_expr
+	{
+		$$ = $1
+	}
+
 optional_comma:
 	{
 		$$ = false
@@ -812,6 +1080,10 @@ augassign:
 	{
 		$$ = ast.FloorDiv
 	}
+|	ATEQ
+	{
+		$$ = ast.MatMult
+	}
 
 // For normal assignments, additional restrictions enforced by the interpreter
 del_stmt:
@@ -1094,16 +1366,40 @@ compound_stmt:
 	{
 		$$ = $1
 	}
+|	async_stmt
+	{
+		$$ = $1
+	}
+|	match_stmt
+	{
+		$$ = $1
+	}
+
+async_stmt:
+	async_funcdef
+	{
+		$$ = $1
+	}
+|	ASYNC with_stmt
+	{
+		with := $2.(*ast.With)
+		$$ = &ast.AsyncWith{StmtBase: ast.StmtBase{Pos: $<pos>$}, Items: with.Items, Body: with.Body}
+	}
+|	ASYNC for_stmt
+	{
+		loop := $2.(*ast.For)
+		$$ = &ast.AsyncFor{StmtBase: ast.StmtBase{Pos: $<pos>$}, Target: loop.Target, Iter: loop.Iter, Body: loop.Body, Orelse: loop.Orelse}
+	}
 
 elifs:
 	{
 		$$ = nil
 		$<lastif>$ = nil
 	}
-|	elifs ELIF test ':' suite
+|	elifs ELIF namedexpr_test ':' suite
 	{
 		elifs := $$
-		newif := &ast.If{StmtBase: ast.StmtBase{Pos: $<pos>$}, Test: $3, Body: $5}
+		newif := &ast.If{StmtBase: ast.StmtBase{Pos: $<pos>2}, Test: $3, Body: $5}
 		if elifs == nil {
 			$$ = newif
 		} else {
@@ -1122,7 +1418,7 @@ optional_else:
 	}
 
 if_stmt:
-	IF test ':' suite elifs optional_else
+	IF namedexpr_test ':' suite elifs optional_else
 	{
 		newif := &ast.If{StmtBase: ast.StmtBase{Pos: $<pos>$}, Test: $2, Body: $4}
 		$$ = newif
@@ -1143,7 +1439,7 @@ if_stmt:
 	}
 
 while_stmt:
-	WHILE test ':' suite optional_else
+	WHILE namedexpr_test ':' suite optional_else
 	{
 		$$ = &ast.While{StmtBase: ast.StmtBase{Pos: $<pos>$}, Test: $2, Body: $4, Orelse: $5}
 	}
@@ -1151,7 +1447,7 @@ while_stmt:
 for_stmt:
 	FOR exprlist IN testlist ':' suite optional_else
 	{
-		target := tupleOrExpr($<pos>$, $2, false)
+		target := tupleOrExpr($<pos>2, $2, false)
 		setCtx(yylex, target, ast.Store)
 		$$ = &ast.For{StmtBase: ast.StmtBase{Pos: $<pos>$}, Target: target, Iter: $4, Body: $6, Orelse: $7}
 	}
@@ -1162,7 +1458,7 @@ except_clauses:
 	}
 |	except_clauses except_clause ':' suite
 	{
-		exc := &ast.ExceptHandler{Pos: $<pos>$, ExprType: $2, Name: ast.Identifier($<str>2), Body: $4}
+		exc := &ast.ExceptHandler{Pos: $<pos>2, ExprType: $2, Name: ast.Identifier($<str>2), Body: $4}
 		$$ = append($$, exc)
 	}
 
@@ -1213,6 +1509,278 @@ with_item:
 		$$ = &ast.WithItem{Pos: $<pos>$, ContextExpr: $1, OptionalVars: v}
 	}
 
+match_stmt:
+	MATCH testlist_star_expr ':' NEWLINE INDENT case_blocks DEDENT
+	{
+		if _, ok := $2.(*ast.Starred); ok {
+			yylex.(*yyLex).SyntaxError("invalid syntax")
+		}
+		$$ = &ast.Match{StmtBase: ast.StmtBase{Pos: $<pos>$}, Subject: $2, Cases: $6}
+	}
+
+case_blocks:
+	case_block
+	{
+		$$ = nil
+		$$ = append($$, $1)
+	}
+|	case_blocks case_block
+	{
+		$$ = append($$, $2)
+	}
+
+case_block:
+	CASE patterns guard ':' suite
+	{
+		$$ = &ast.MatchCase{Pos: $<pos>$, Pattern: $2, Guard: $3, Body: $5}
+	}
+
+guard:
+	{
+		$$ = nil
+	}
+|	IF namedexpr_test
+	{
+		$$ = $2
+	}
+
+patterns:
+	maybe_star_patterns optional_comma
+	{
+		$$ = patternsOrPattern(yylex, $<pos>$, $1, $2)
+	}
+
+maybe_star_patterns:
+	maybe_star_pattern
+	{
+		$$ = nil
+		$$ = append($$, $1)
+	}
+|	maybe_star_patterns ',' maybe_star_pattern
+	{
+		$$ = append($$, $3)
+	}
+
+maybe_star_pattern:
+	pattern
+	{
+		$$ = $1
+	}
+|	'*' NAME
+	{
+		name := ast.Identifier($2)
+		if name == "_" {
+			name = ""
+		}
+		$$ = &ast.MatchStar{PatternBase: ast.PatternBase{Pos: $<pos>$}, Name: name}
+	}
+
+pattern:
+	or_pattern
+	{
+		$$ = $1
+	}
+|	or_pattern AS NAME
+	{
+		if $3 == "_" {
+			yylex.(*yyLex).SyntaxError("cannot use '_' as a target")
+		}
+		$$ = &ast.MatchAs{PatternBase: ast.PatternBase{Pos: $<pos>$}, Pattern: $1, Name: ast.Identifier($3)}
+	}
+
+or_pattern:
+	closed_patterns
+	{
+		if len($1) == 1 {
+			$$ = $1[0]
+		} else {
+			$$ = &ast.MatchOr{PatternBase: ast.PatternBase{Pos: $<pos>$}, Patterns: $1}
+		}
+	}
+
+closed_patterns:
+	closed_pattern
+	{
+		$$ = nil
+		$$ = append($$, $1)
+	}
+|	closed_patterns '|' closed_pattern
+	{
+		$$ = append($$, $3)
+	}
+
+closed_pattern:
+	literal_expr
+	{
+		$$ = literalPattern(yylex, $<pos>$, $1)
+	}
+|	name_or_attr
+	{
+		switch x := $1.(type) {
+		case *ast.Name:
+			if x.Id == "_" {
+				$$ = &ast.MatchAs{PatternBase: ast.PatternBase{Pos: $<pos>$}}
+			} else {
+				$$ = &ast.MatchAs{PatternBase: ast.PatternBase{Pos: $<pos>$}, Name: x.Id}
+			}
+		default:
+			$$ = &ast.MatchValue{PatternBase: ast.PatternBase{Pos: $<pos>$}, Value: $1}
+		}
+	}
+|	'(' ')'
+	{
+		$$ = &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: $<pos>$}}
+	}
+|	'(' maybe_star_patterns optional_comma ')'
+	{
+		$$ = patternsOrPattern(yylex, $<pos>$, $2, $3)
+	}
+|	'[' ']'
+	{
+		$$ = &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: $<pos>$}}
+	}
+|	'[' maybe_star_patterns optional_comma ']'
+	{
+		$$ = &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: $<pos>$}, Patterns: $2}
+	}
+|	'{' '}'
+	{
+		$$ = &ast.MatchMapping{PatternBase: ast.PatternBase{Pos: $<pos>$}}
+	}
+|	'{' mapping_items optional_comma '}'
+	{
+		$2.Pos = $<pos>$
+		$$ = $2
+	}
+|	'{' mapping_items ',' STARSTAR NAME optional_comma '}'
+	{
+		$2.Pos = $<pos>$
+		$2.Rest = ast.Identifier($5)
+		$$ = $2
+	}
+|	'{' STARSTAR NAME optional_comma '}'
+	{
+		$$ = &ast.MatchMapping{PatternBase: ast.PatternBase{Pos: $<pos>$}, Rest: ast.Identifier($3)}
+	}
+|	name_or_attr '(' ')'
+	{
+		$$ = &ast.MatchClass{PatternBase: ast.PatternBase{Pos: $<pos>$}, Cls: $1}
+	}
+|	name_or_attr '(' class_arguments optional_comma ')'
+	{
+		$3.Pos = $<pos>$
+		$3.Cls = $1
+		$$ = $3
+	}
+
+mapping_items:
+	literal_expr ':' pattern
+	{
+		$$ = &ast.MatchMapping{Keys: []ast.Expr{$1}, Patterns: []ast.Pattern{$3}}
+	}
+|	name_or_attr ':' pattern
+	{
+		if _, ok := $1.(*ast.Attribute); !ok {
+			yylex.(*yyLex).SyntaxError("invalid syntax")
+		}
+		$$ = &ast.MatchMapping{Keys: []ast.Expr{$1}, Patterns: []ast.Pattern{$3}}
+	}
+|	mapping_items ',' literal_expr ':' pattern
+	{
+		$$.Keys = append($$.Keys, $3)
+		$$.Patterns = append($$.Patterns, $5)
+	}
+|	mapping_items ',' name_or_attr ':' pattern
+	{
+		if _, ok := $3.(*ast.Attribute); !ok {
+			yylex.(*yyLex).SyntaxError("invalid syntax")
+		}
+		$$.Keys = append($$.Keys, $3)
+		$$.Patterns = append($$.Patterns, $5)
+	}
+
+class_arguments:
+	pattern
+	{
+		$$ = &ast.MatchClass{Patterns: []ast.Pattern{$1}}
+	}
+|	NAME '=' pattern
+	{
+		$$ = &ast.MatchClass{KwdAttrs: []ast.Identifier{ast.Identifier($1)}, KwdPatterns: []ast.Pattern{$3}}
+	}
+|	class_arguments ',' pattern
+	{
+		if len($$.KwdAttrs) != 0 {
+			yylex.(*yyLex).SyntaxError("positional patterns follow keyword patterns")
+		}
+		$$.Patterns = append($$.Patterns, $3)
+	}
+|	class_arguments ',' NAME '=' pattern
+	{
+		$$.KwdAttrs = append($$.KwdAttrs, ast.Identifier($3))
+		$$.KwdPatterns = append($$.KwdPatterns, $5)
+	}
+
+name_or_attr:
+	NAME
+	{
+		$$ = &ast.Name{ExprBase: ast.ExprBase{Pos: $<pos>$}, Id: ast.Identifier($1), Ctx: ast.Load}
+	}
+|	name_or_attr '.' NAME
+	{
+		$$ = &ast.Attribute{ExprBase: ast.ExprBase{Pos: $<pos>$}, Value: $1, Attr: ast.Identifier($3), Ctx: ast.Load}
+	}
+
+literal_expr:
+	signed_number
+	{
+		$$ = $1
+	}
+|	signed_number '+' NUMBER
+	{
+		$$ = complexLiteral(yylex, $<pos>$, $1, ast.Add, $3)
+	}
+|	signed_number '-' NUMBER
+	{
+		$$ = complexLiteral(yylex, $<pos>$, $1, ast.Sub, $3)
+	}
+|	strings
+	{
+		switch s := $1.(type) {
+		case py.String:
+			$$ = &ast.Str{ExprBase: ast.ExprBase{Pos: $<pos>$}, S: s}
+		case py.Bytes:
+			$$ = &ast.Bytes{ExprBase: ast.ExprBase{Pos: $<pos>$}, S: s}
+		case *ast.JoinedStr:
+			s.Pos = $<pos>$
+			$$ = s
+		default:
+			panic("not Bytes or String in strings")
+		}
+	}
+|	NONE
+	{
+		$$ = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: $<pos>$}, Value: py.None}
+	}
+|	TRUE
+	{
+		$$ = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: $<pos>$}, Value: py.True}
+	}
+|	FALSE
+	{
+		$$ = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: $<pos>$}, Value: py.False}
+	}
+
+signed_number:
+	NUMBER
+	{
+		$$ = &ast.Num{ExprBase: ast.ExprBase{Pos: $<pos>$}, N: $1}
+	}
+|	'-' NUMBER
+	{
+		$$ = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Op: ast.USub, Operand: &ast.Num{ExprBase: ast.ExprBase{Pos: $<pos>$}, N: $2}}
+	}
+
 // NB compile.c makes sure that the default except clause is last
 except_clause:
 	EXCEPT
@@ -1252,6 +1820,16 @@ suite:
 		$$ = $3
 	}
 
+namedexpr_test:
+	test
+	{
+		$$ = $1
+	}
+|	test COLONEQ test
+	{
+		$$ = namedExpr(yylex, $<pos>$, $1, $3)
+	}
+
 test:
 	or_test
 	{
@@ -1284,6 +1862,7 @@ lambdef:
 	}
 |	LAMBDA varargslist ':' test
 	{
+		setPosonlyargs(yylex, $2)
 		$$ = &ast.Lambda{ExprBase: ast.ExprBase{Pos: $<pos>$}, Args: $2, Body: $4}
 	}
 
@@ -1295,6 +1874,7 @@ lambdef_nocond:
 	}
 |	LAMBDA varargslist ':' test_nocond
 	{
+		setPosonlyargs(yylex, $2)
 		$$ = &ast.Lambda{ExprBase: ast.ExprBase{Pos: $<pos>$}, Args: $2, Body: $4}
 	}
 
@@ -1493,6 +2073,10 @@ term:
 	{
 		$$ = &ast.BinOp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Left: $1, Op: ast.FloorDiv, Right: $3}
 	}
+|	term '@' factor
+	{
+		$$ = &ast.BinOp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Left: $1, Op: ast.MatMult, Right: $3}
+	}
 
 factor:
 	'+' factor
@@ -1513,13 +2097,23 @@ factor:
 	}
 
 power:
+	atom_expr
+	{
+		$$ = $1
+	}
+|	atom_expr STARSTAR factor
+	{
+		$$ = &ast.BinOp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Left: $1, Op: ast.Pow, Right: $3}
+	}
+
+atom_expr:
 	atom trailers
 	{
-		$$ = applyTrailers($1, $2)
+		$$ = applyTrailers($<pos>$, $1, $2)
 	}
-|	atom trailers STARSTAR factor
+|	AWAIT atom trailers
 	{
-		$$ = &ast.BinOp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Left: applyTrailers($1, $2), Op: ast.Pow, Right: $4}
+		$$ = &ast.Await{ExprBase: ast.ExprBase{Pos: $<pos>$}, Value: applyTrailers($<pos>2, $2, $3)}
 	}
 
 // Trailers are half made Call, Attribute or Subscript
@@ -1537,24 +2131,17 @@ strings:
 	{
 		$$ = $1
 	}
+|	FSTRING
+	{
+		$$ = $1
+	}
 |	strings STRING
 	{
-		switch a := $$.(type) {
-		case py.String:
-			switch b := $2.(type) {
-			case py.String:
-				$$ = a + b
-			default:
-				yylex.(*yyLex).SyntaxError("cannot mix string and nonstring literals")
-			}
-		case py.Bytes:
-			switch b := $2.(type) {
-			case py.Bytes:
-				$$ = append(a, b...)
-			default:
-				yylex.(*yyLex).SyntaxError("cannot mix bytes and nonbytes literals")
-			}
-		}
+		$$ = concatStrings(yylex.(*yyLex), $1, $2)
+	}
+|	strings FSTRING
+	{
+		$$ = concatStrings(yylex.(*yyLex), $1, $2)
 	}
 
 atom:
@@ -1566,11 +2153,11 @@ atom:
 	{
 		$$ = $2
 	}
-|	'(' test_or_star_expr comp_for ')'
+|	'(' namedexpr_or_star_expr comp_for ')'
 	{
 		$$ = &ast.GeneratorExp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Elt: $2, Generators: $3}
 	}
-|	'(' test_or_star_exprs optional_comma ')' 
+|	'(' namedexpr_or_star_exprs optional_comma ')' 
 	{
 		$$ = tupleOrExpr($<pos>$, $2, $3)
 	}
@@ -1578,11 +2165,11 @@ atom:
 	{
 		$$ = &ast.List{ExprBase: ast.ExprBase{Pos: $<pos>$}, Ctx: ast.Load}
 	}
-|	'[' test_or_star_expr comp_for ']'
+|	'[' namedexpr_or_star_expr comp_for ']'
 	{
 		$$ = &ast.ListComp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Elt: $2, Generators: $3}
 	}
-|	'[' test_or_star_exprs optional_comma ']'
+|	'[' namedexpr_or_star_exprs optional_comma ']'
 	{
 		$$ = &ast.List{ExprBase: ast.ExprBase{Pos: $<pos>$}, Elts: $2, Ctx: ast.Load}
 	}
@@ -1593,6 +2180,8 @@ atom:
 |	'{' dictorsetmaker '}'
 	{
 		$$ = $2
+		// The node starts with the {
+		*nodePos($$) = $<pos>$
 	}
 |	NAME
 	{
@@ -1609,6 +2198,9 @@ atom:
 			$$ = &ast.Str{ExprBase: ast.ExprBase{Pos: $<pos>$}, S: s}
 		case py.Bytes:
 			$$ = &ast.Bytes{ExprBase: ast.ExprBase{Pos: $<pos>$}, S: s}
+		case *ast.JoinedStr:
+			s.Pos = $<pos>$
+			$$ = s
 		default:
 			panic("not Bytes or String in strings")
 		}
@@ -1777,23 +2369,26 @@ testlist:
 		}
 	}
 
-testlistraw:
-	tests optional_comma
-	{
-		$$ = $1
-	}
-
-// (',' test ':' test)*
+// (',' (test ':' test | '**' expr))*
 test_colon_tests:
 	test ':' test
 	{
 		$$ = nil
 		$$ = append($$, $1, $3)	// key, value order
 	}
+|	STARSTAR expr
+	{
+		$$ = nil
+		$$ = append($$, nil, $2)	// nil key for **mapping
+	}
 |	test_colon_tests ',' test ':' test
 	{
 		$$ = append($$, $3, $5)
 	}
+|	test_colon_tests ',' STARSTAR expr
+	{
+		$$ = append($$, nil, $4)
+	}
 
 dictorsetmaker:
 	test_colon_tests optional_comma
@@ -1810,7 +2405,7 @@ dictorsetmaker:
 	{
 		$$ = &ast.DictComp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Key: $1, Value: $3, Generators: $4}
 	}
-|	testlistraw
+|	test_or_star_exprs optional_comma
 	{
 		$$ = &ast.Set{ExprBase: ast.ExprBase{Pos: $<pos>$}, Elts: $1}
 	}
@@ -1840,60 +2435,18 @@ arguments:
 	}
 |	arguments ',' argument
 	{
-		$$.Args = append($$.Args, $3.Args...)
-		$$.Keywords = append($$.Keywords, $3.Keywords...)
-	}
-
-optional_arguments:
-	{
-		$$ = &ast.Call{}
-	}
-|	arguments ','
-	{
-		$$ = $1
-	}
-
-arguments2:
-	{
-		$$ = &ast.Call{}
-	}
-|	arguments2 ',' argument
-	{
-		$$.Args = append($$.Args, $3.Args...)
-		$$.Keywords = append($$.Keywords, $3.Keywords...)
+		addArgument(yylex, $$, $3)
 	}
 
 arglist:
 	arguments optional_comma
 	{
 		$$ = $1
-	}
-|	optional_arguments '*' test arguments2
-	{
-		call := $1
-		call.Starargs = $3
-		if len($4.Args) != 0 {
-			yylex.(*yyLex).SyntaxError("only named arguments may follow *expression")
+		if $2 && $$.Func != nil {
+			yylex.(*yyLex).SyntaxError("Generator expression must be parenthesized")
 		}
-		call.Keywords = append(call.Keywords, $4.Keywords...)
-		$$ = call
-	}
-|	optional_arguments '*' test arguments2 ',' STARSTAR test
-	{
-		call := $1
-		call.Starargs = $3
-		call.Kwargs = $7
-		if len($4.Args) != 0 {
-			yylex.(*yyLex).SyntaxError("only named arguments may follow *expression")
-		}
-		call.Keywords = append(call.Keywords, $4.Keywords...)
-		$$ = call
-	}
-|	optional_arguments STARSTAR test
-	{
-		call := $1
-		call.Kwargs = $3
-		$$ = call
+		$$.Func = nil
+		setStarargs($$)
 	}
 
 // The reason that keywords are test nodes instead of NAME is that using NAME
@@ -1907,9 +2460,16 @@ argument:
 |	test comp_for
 	{
 		$$ = &ast.Call{}
-		$$.Args = []ast.Expr{
-			&ast.GeneratorExp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Elt: $1, Generators: $2},
-		}
+		genexp := &ast.GeneratorExp{ExprBase: ast.ExprBase{Pos: $<pos>$}, Elt: $1, Generators: $2}
+		$$.Args = []ast.Expr{genexp}
+		// Func marks an unparenthesized generator expression which
+		// must be the only argument
+		$$.Func = genexp
+	}
+|	test COLONEQ test
+	{
+		$$ = &ast.Call{}
+		$$.Args = []ast.Expr{namedExpr(yylex, $<pos>$, $1, $3)}
 	}
 |	test '=' test  // Really [keyword '='] test
 	{
@@ -1921,6 +2481,16 @@ argument:
 			yylex.(*yyLex).SyntaxError("keyword can't be an expression")
 		}
 	}
+|	'*' test
+	{
+		$$ = &ast.Call{}
+		$$.Args = []ast.Expr{&ast.Starred{ExprBase: ast.ExprBase{Pos: $<pos>$}, Value: $2, Ctx: ast.Load}}
+	}
+|	STARSTAR test
+	{
+		$$ = &ast.Call{}
+		$$.Keywords = []*ast.Keyword{&ast.Keyword{Pos: $<pos>$, Value: $2}}
+	}
 
 comp_iter:
 	comp_for
@@ -1938,7 +2508,7 @@ comp_for:
 	FOR exprlist IN or_test
 	{
 		c := ast.Comprehension{
-			Target: tupleOrExpr($<pos>$, $2, $<comma>2),
+			Target: tupleOrExpr($<pos>2, $2, $<comma>2),
 			Iter: $4,
 		}
 		setCtx(yylex, c.Target, ast.Store)
@@ -1947,7 +2517,7 @@ comp_for:
 |	FOR exprlist IN or_test comp_iter
 	{
 		c := ast.Comprehension{
-			Target: tupleOrExpr($<pos>$, $2, $<comma>2),
+			Target: tupleOrExpr($<pos>2, $2, $<comma>2),
 			Iter: $4,
 			Ifs: $5,
 		}
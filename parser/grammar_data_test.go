@@ -31,11 +31,34 @@ var grammarTestData = []struct {
 	{"\"abc\" \"\"\"123\"\"\"", "eval", "Expression(body=Str(s='abc123'))", nil, ""},
 	{"b'abc'", "eval", "Expression(body=Bytes(s=b'abc'))", nil, ""},
 	{"b'abc' b'''123'''", "eval", "Expression(body=Bytes(s=b'abc123'))", nil, ""},
+	{"f\"abc\"", "eval", "Expression(body=JoinedStr(values=[Str(s='abc')]))", nil, ""},
+	{"f\"a{b}c\"", "eval", "Expression(body=JoinedStr(values=[Str(s='a'), FormattedValue(value=Name(id='b', ctx=Load()), conversion=-1, format_spec=None), Str(s='c')]))", nil, ""},
+	{"f\"{a!r:>{w}.{p}}\"", "eval", "Expression(body=JoinedStr(values=[FormattedValue(value=Name(id='a', ctx=Load()), conversion=114, format_spec=JoinedStr(values=[Str(s='>'), FormattedValue(value=Name(id='w', ctx=Load()), conversion=-1, format_spec=None), Str(s='.'), FormattedValue(value=Name(id='p', ctx=Load()), conversion=-1, format_spec=None)]))]))", nil, ""},
+	{"\"x\" f\"y{z}\" \"w\"", "eval", "Expression(body=JoinedStr(values=[Str(s='xy'), FormattedValue(value=Name(id='z', ctx=Load()), conversion=-1, format_spec=None), Str(s='w')]))", nil, ""},
+	{"f\"{{}}{x!=y}\"", "eval", "Expression(body=JoinedStr(values=[Str(s='{}'), FormattedValue(value=Compare(left=Name(id='x', ctx=Load()), ops=[NotEq()], comparators=[Name(id='y', ctx=Load())]), conversion=-1, format_spec=None)]))", nil, ""},
+	{"f\"{a!s}{b!a}\"", "eval", "Expression(body=JoinedStr(values=[FormattedValue(value=Name(id='a', ctx=Load()), conversion=115, format_spec=None), FormattedValue(value=Name(id='b', ctx=Load()), conversion=97, format_spec=None)]))", nil, ""},
+	{"rf\"\\n{x}\"", "eval", "Expression(body=JoinedStr(values=[Str(s='\\n'), FormattedValue(value=Name(id='x', ctx=Load()), conversion=-1, format_spec=None)]))", nil, ""},
+	{"f\"{}\"", "eval", "", py.SyntaxError, "f-string: empty expression not allowed"},
+	{"f\"{x!z}\"", "eval", "", py.SyntaxError, "f-string: invalid conversion character: expected 's', 'r', or 'a'"},
+	{"f\"}\"", "eval", "", py.SyntaxError, "f-string: single '}' is not allowed"},
+	{"f\"{x\"", "eval", "", py.SyntaxError, "f-string: expecting '}'"},
+	{"f\"{x#}\"", "eval", "", py.SyntaxError, "f-string expression part cannot include '#'"},
+	{"f\"{a b}\"", "eval", "", py.SyntaxError, "f-string: invalid syntax"},
+	{"b\"\" f\"\"", "eval", "", py.SyntaxError, "cannot mix bytes and nonbytes literals"},
+	{"f\"{3:{4:{5}}}\"", "eval", "", py.SyntaxError, "f-string: expressions nested too deeply"},
+	{"f\"{x=}\"", "eval", "Expression(body=JoinedStr(values=[Str(s='x='), FormattedValue(value=Name(id='x', ctx=Load()), conversion=114, format_spec=None)]))", nil, ""},
+	{"f\"a{x = }b\"", "eval", "Expression(body=JoinedStr(values=[Str(s='ax = '), FormattedValue(value=Name(id='x', ctx=Load()), conversion=114, format_spec=None), Str(s='b')]))", nil, ""},
+	{"f\"{x=!s}\"", "eval", "Expression(body=JoinedStr(values=[Str(s='x='), FormattedValue(value=Name(id='x', ctx=Load()), conversion=115, format_spec=None)]))", nil, ""},
+	{"f\"{x=:>10}\"", "eval", "Expression(body=JoinedStr(values=[Str(s='x='), FormattedValue(value=Name(id='x', ctx=Load()), conversion=-1, format_spec=JoinedStr(values=[Str(s='>10')]))]))", nil, ""},
+	{"f\"{x==y}\"", "eval", "Expression(body=JoinedStr(values=[FormattedValue(value=Compare(left=Name(id='x', ctx=Load()), ops=[Eq()], comparators=[Name(id='y', ctx=Load())]), conversion=-1, format_spec=None)]))", nil, ""},
+	{"f\"{x<=y}\"", "eval", "Expression(body=JoinedStr(values=[FormattedValue(value=Compare(left=Name(id='x', ctx=Load()), ops=[LtE()], comparators=[Name(id='y', ctx=Load())]), conversion=-1, format_spec=None)]))", nil, ""},
+	{"f\"{x!=y=}\"", "eval", "Expression(body=JoinedStr(values=[Str(s='x!=y='), FormattedValue(value=Compare(left=Name(id='x', ctx=Load()), ops=[NotEq()], comparators=[Name(id='y', ctx=Load())]), conversion=114, format_spec=None)]))", nil, ""},
+	{"f\"{=}\"", "eval", "", py.SyntaxError, "f-string: empty expression not allowed"},
 	{"1234", "eval", "Expression(body=Num(n=1234))", nil, ""},
 	{"01234", "eval", "", py.SyntaxError, "illegal decimal with leading zero"},
 	{"1234d", "eval", "", py.SyntaxError, "invalid syntax"},
 	{"1234d", "exec", "", py.SyntaxError, "invalid syntax"},
-	{"1234d", "single", "", py.SyntaxError, "unexpected EOF while parsing"},
+	{"1234d", "single", "", py.SyntaxError, "invalid syntax"},
 	{"0x1234", "eval", "Expression(body=Num(n=4660))", nil, ""},
 	{"12.34", "eval", "Expression(body=Num(n=12.34))", nil, ""},
 	{"1,", "eval", "Expression(body=Tuple(elts=[Num(n=1)], ctx=Load()))", nil, ""},
@@ -60,6 +83,10 @@ var grammarTestData = []struct {
 	{"[1,]", "eval", "Expression(body=List(elts=[Num(n=1)], ctx=Load()))", nil, ""},
 	{"[1,2]", "eval", "Expression(body=List(elts=[Num(n=1), Num(n=2)], ctx=Load()))", nil, ""},
 	{"[1,2,]", "eval", "Expression(body=List(elts=[Num(n=1), Num(n=2)], ctx=Load()))", nil, ""},
+	{"[*a,*b]", "eval", "Expression(body=List(elts=[Starred(value=Name(id='a', ctx=Load()), ctx=Load()), Starred(value=Name(id='b', ctx=Load()), ctx=Load())], ctx=Load()))", nil, ""},
+	{"(*a,b)", "eval", "Expression(body=Tuple(elts=[Starred(value=Name(id='a', ctx=Load()), ctx=Load()), Name(id='b', ctx=Load())], ctx=Load()))", nil, ""},
+	{"{*a,b}", "eval", "Expression(body=Set(elts=[Starred(value=Name(id='a', ctx=Load()), ctx=Load()), Name(id='b', ctx=Load())]))", nil, ""},
+	{"{**a,b:c,**d}", "eval", "Expression(body=Dict(keys=[None, Name(id='b', ctx=Load()), None], values=[Name(id='a', ctx=Load()), Name(id='c', ctx=Load()), Name(id='d', ctx=Load())]))", nil, ""},
 	{"[e for e in (1,2,3)]", "eval", "Expression(body=ListComp(elt=Name(id='e', ctx=Load()), generators=[comprehension(target=Name(id='e', ctx=Store()), iter=Tuple(elts=[Num(n=1), Num(n=2), Num(n=3)], ctx=Load()), ifs=[])]))", nil, ""},
 	{"( a for a in ab )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[])]))", nil, ""},
 	{"( a for a, in ab )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Tuple(elts=[Name(id='a', ctx=Store())], ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[])]))", nil, ""},
@@ -68,8 +95,8 @@ var grammarTestData = []struct {
 	{"( a for a in ab if a if b if c )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[Name(id='a', ctx=Load()), Name(id='b', ctx=Load()), Name(id='c', ctx=Load())])]))", nil, ""},
 	{"( a for a in ab for A in AB )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[]), comprehension(target=Name(id='A', ctx=Store()), iter=Name(id='AB', ctx=Load()), ifs=[])]))", nil, ""},
 	{"( a for a in ab if a if b for A in AB if c )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[Name(id='a', ctx=Load()), Name(id='b', ctx=Load())]), comprehension(target=Name(id='A', ctx=Store()), iter=Name(id='AB', ctx=Load()), ifs=[Name(id='c', ctx=Load())])]))", nil, ""},
-	{"( a for a in ab if lambda: None )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[Lambda(args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=NameConstant(value=None))])]))", nil, ""},
-	{"( a for a in ab if lambda x,y: x+y )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[Lambda(args=arguments(args=[arg(arg='x', annotation=None), arg(arg='y', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=BinOp(left=Name(id='x', ctx=Load()), op=Add(), right=Name(id='y', ctx=Load())))])]))", nil, ""},
+	{"( a for a in ab if lambda: None )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[Lambda(args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=NameConstant(value=None))])]))", nil, ""},
+	{"( a for a in ab if lambda x,y: x+y )", "eval", "Expression(body=GeneratorExp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[Lambda(args=arguments(posonlyargs=[], args=[arg(arg='x', annotation=None), arg(arg='y', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=BinOp(left=Name(id='x', ctx=Load()), op=Add(), right=Name(id='y', ctx=Load())))])]))", nil, ""},
 	{"[ a for a in ab ]", "eval", "Expression(body=ListComp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Name(id='a', ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[])]))", nil, ""},
 	{"[ a for a, in ab ]", "eval", "Expression(body=ListComp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Tuple(elts=[Name(id='a', ctx=Store())], ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[])]))", nil, ""},
 	{"[ a for a, b in ab ]", "eval", "Expression(body=ListComp(elt=Name(id='a', ctx=Load()), generators=[comprehension(target=Tuple(elts=[Name(id='a', ctx=Store()), Name(id='b', ctx=Store())], ctx=Store()), iter=Name(id='ab', ctx=Load()), ifs=[])]))", nil, ""},
@@ -101,6 +128,8 @@ var grammarTestData = []struct {
 	{"a*b", "eval", "Expression(body=BinOp(left=Name(id='a', ctx=Load()), op=Mult(), right=Name(id='b', ctx=Load())))", nil, ""},
 	{"a/b", "eval", "Expression(body=BinOp(left=Name(id='a', ctx=Load()), op=Div(), right=Name(id='b', ctx=Load())))", nil, ""},
 	{"a//b", "eval", "Expression(body=BinOp(left=Name(id='a', ctx=Load()), op=FloorDiv(), right=Name(id='b', ctx=Load())))", nil, ""},
+	{"a@b", "eval", "Expression(body=BinOp(left=Name(id='a', ctx=Load()), op=MatMult(), right=Name(id='b', ctx=Load())))", nil, ""},
+	{"a*b@c", "eval", "Expression(body=BinOp(left=BinOp(left=Name(id='a', ctx=Load()), op=Mult(), right=Name(id='b', ctx=Load())), op=MatMult(), right=Name(id='c', ctx=Load())))", nil, ""},
 	{"a**b", "eval", "Expression(body=BinOp(left=Name(id='a', ctx=Load()), op=Pow(), right=Name(id='b', ctx=Load())))", nil, ""},
 	{"not a", "eval", "Expression(body=UnaryOp(op=Not(), operand=Name(id='a', ctx=Load())))", nil, ""},
 	{"+a", "eval", "Expression(body=UnaryOp(op=UAdd(), operand=Name(id='a', ctx=Load())))", nil, ""},
@@ -141,13 +170,23 @@ var grammarTestData = []struct {
 	{"a(b,c)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Name(id='b', ctx=Load()), Name(id='c', ctx=Load())], keywords=[], starargs=None, kwargs=None))", nil, ""},
 	{"a(b,*c)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Name(id='b', ctx=Load())], keywords=[], starargs=Name(id='c', ctx=Load()), kwargs=None))", nil, ""},
 	{"a(*b)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[], keywords=[], starargs=Name(id='b', ctx=Load()), kwargs=None))", nil, ""},
-	{"a(*b,c)", "eval", "", py.SyntaxError, "only named arguments may follow *expression"},
+	{"a(*b,c)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Starred(value=Name(id='b', ctx=Load()), ctx=Load()), Name(id='c', ctx=Load())], keywords=[], starargs=None, kwargs=None))", nil, ""},
 	{"a(b,*c,**d)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Name(id='b', ctx=Load())], keywords=[], starargs=Name(id='c', ctx=Load()), kwargs=Name(id='d', ctx=Load())))", nil, ""},
 	{"a(b,**c)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Name(id='b', ctx=Load())], keywords=[], starargs=None, kwargs=Name(id='c', ctx=Load())))", nil, ""},
+	{"a(*b,*c)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Starred(value=Name(id='b', ctx=Load()), ctx=Load()), Starred(value=Name(id='c', ctx=Load()), ctx=Load())], keywords=[], starargs=None, kwargs=None))", nil, ""},
+	{"a(**b,**c)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[], keywords=[keyword(arg=None, value=Name(id='b', ctx=Load())), keyword(arg=None, value=Name(id='c', ctx=Load()))], starargs=None, kwargs=None))", nil, ""},
+	{"a(**b,c=d)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[], keywords=[keyword(arg=None, value=Name(id='b', ctx=Load())), keyword(arg='c', value=Name(id='d', ctx=Load()))], starargs=None, kwargs=None))", nil, ""},
+	{"a(b,*c,d,**e,f=g)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Name(id='b', ctx=Load()), Starred(value=Name(id='c', ctx=Load()), ctx=Load()), Name(id='d', ctx=Load())], keywords=[keyword(arg=None, value=Name(id='e', ctx=Load())), keyword(arg='f', value=Name(id='g', ctx=Load()))], starargs=None, kwargs=None))", nil, ""},
+	{"a(**b,*c)", "eval", "", py.SyntaxError, "iterable argument unpacking follows keyword argument unpacking"},
+	{"a(**b,c)", "eval", "", py.SyntaxError, "positional argument follows keyword argument unpacking"},
+	{"a(b=c,d)", "eval", "", py.SyntaxError, "positional argument follows keyword argument"},
 	{"a(a=b)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[], keywords=[keyword(arg='a', value=Name(id='b', ctx=Load()))], starargs=None, kwargs=None))", nil, ""},
 	{"a(a,a=b,*args,**kwargs)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Name(id='a', ctx=Load())], keywords=[keyword(arg='a', value=Name(id='b', ctx=Load()))], starargs=Name(id='args', ctx=Load()), kwargs=Name(id='kwargs', ctx=Load())))", nil, ""},
 	{"a(a,a=b,*args,e=f,**kwargs)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[Name(id='a', ctx=Load())], keywords=[keyword(arg='a', value=Name(id='b', ctx=Load())), keyword(arg='e', value=Name(id='f', ctx=Load()))], starargs=Name(id='args', ctx=Load()), kwargs=Name(id='kwargs', ctx=Load())))", nil, ""},
 	{"a(b for c in d)", "eval", "Expression(body=Call(func=Name(id='a', ctx=Load()), args=[GeneratorExp(elt=Name(id='b', ctx=Load()), generators=[comprehension(target=Name(id='c', ctx=Store()), iter=Name(id='d', ctx=Load()), ifs=[])])], keywords=[], starargs=None, kwargs=None))", nil, ""},
+	{"a(b for c in d, e)", "eval", "", py.SyntaxError, "Generator expression must be parenthesized"},
+	{"a(b, c for d in e)", "eval", "", py.SyntaxError, "Generator expression must be parenthesized"},
+	{"a(b for c in d,)", "eval", "", py.SyntaxError, "Generator expression must be parenthesized"},
 	{"a.b", "eval", "Expression(body=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Load()))", nil, ""},
 	{"a.b.c.d", "eval", "Expression(body=Attribute(value=Attribute(value=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Load()), attr='c', ctx=Load()), attr='d', ctx=Load()))", nil, ""},
 	{"a.b().c.d()()", "eval", "Expression(body=Call(func=Call(func=Attribute(value=Attribute(value=Call(func=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None), attr='c', ctx=Load()), attr='d', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None), args=[], keywords=[], starargs=None, kwargs=None))", nil, ""},
@@ -217,6 +256,19 @@ var grammarTestData = []struct {
 	{"while True: pass", "exec", "Module(body=[While(test=NameConstant(value=True), body=[Pass()], orelse=[])])", nil, ""},
 	{"while True:\n pass\n", "exec", "Module(body=[While(test=NameConstant(value=True), body=[Pass()], orelse=[])])", nil, ""},
 	{"while True:\n pass\nelse:\n return\n", "exec", "Module(body=[While(test=NameConstant(value=True), body=[Pass()], orelse=[Return(value=None)])])", nil, ""},
+	{"(x := 1)", "eval", "Expression(body=NamedExpr(target=Name(id='x', ctx=Store()), value=Num(n=1)))", nil, ""},
+	{"[y := f(x), y**2]", "eval", "Expression(body=List(elts=[NamedExpr(target=Name(id='y', ctx=Store()), value=Call(func=Name(id='f', ctx=Load()), args=[Name(id='x', ctx=Load())], keywords=[], starargs=None, kwargs=None)), BinOp(left=Name(id='y', ctx=Load()), op=Pow(), right=Num(n=2))], ctx=Load()))", nil, ""},
+	{"f(a := 1, b)", "eval", "Expression(body=Call(func=Name(id='f', ctx=Load()), args=[NamedExpr(target=Name(id='a', ctx=Store()), value=Num(n=1)), Name(id='b', ctx=Load())], keywords=[], starargs=None, kwargs=None))", nil, ""},
+	{"if (n := len(a)) > 10: pass", "exec", "Module(body=[If(test=Compare(left=NamedExpr(target=Name(id='n', ctx=Store()), value=Call(func=Name(id='len', ctx=Load()), args=[Name(id='a', ctx=Load())], keywords=[], starargs=None, kwargs=None)), ops=[Gt()], comparators=[Num(n=10)]), body=[Pass()], orelse=[])])", nil, ""},
+	{"if x := 1: pass\nelif y := 2: pass", "exec", "Module(body=[If(test=NamedExpr(target=Name(id='x', ctx=Store()), value=Num(n=1)), body=[Pass()], orelse=[If(test=NamedExpr(target=Name(id='y', ctx=Store()), value=Num(n=2)), body=[Pass()], orelse=[])])])", nil, ""},
+	{"while chunk := read(): pass", "exec", "Module(body=[While(test=NamedExpr(target=Name(id='chunk', ctx=Store()), value=Call(func=Name(id='read', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None)), body=[Pass()], orelse=[])])", nil, ""},
+	{"[y for x in data if (y := f(x))]", "eval", "Expression(body=ListComp(elt=Name(id='y', ctx=Load()), generators=[comprehension(target=Name(id='x', ctx=Store()), iter=Name(id='data', ctx=Load()), ifs=[NamedExpr(target=Name(id='y', ctx=Store()), value=Call(func=Name(id='f', ctx=Load()), args=[Name(id='x', ctx=Load())], keywords=[], starargs=None, kwargs=None))])]))", nil, ""},
+	{"(y := x for x in z)", "eval", "Expression(body=GeneratorExp(elt=NamedExpr(target=Name(id='y', ctx=Store()), value=Name(id='x', ctx=Load())), generators=[comprehension(target=Name(id='x', ctx=Store()), iter=Name(id='z', ctx=Load()), ifs=[])]))", nil, ""},
+	{"x := 1", "exec", "", py.SyntaxError, "invalid syntax"},
+	{"(a.b := 1)", "eval", "", py.SyntaxError, "cannot use named assignment with attribute"},
+	{"(a[1] := 1)", "eval", "", py.SyntaxError, "cannot use named assignment with subscript"},
+	{"((a, b) := 1)", "eval", "", py.SyntaxError, "cannot use named assignment with tuple"},
+	{"f(x.y := 1)", "eval", "", py.SyntaxError, "cannot use named assignment with attribute"},
 	{"if True: pass", "exec", "Module(body=[If(test=NameConstant(value=True), body=[Pass()], orelse=[])])", nil, ""},
 	{"if True:\n pass\n", "exec", "Module(body=[If(test=NameConstant(value=True), body=[Pass()], orelse=[])])", nil, ""},
 	{"if True:\n pass\n\n", "exec", "Module(body=[If(test=NameConstant(value=True), body=[Pass()], orelse=[])])", nil, ""},
@@ -224,7 +276,7 @@ var grammarTestData = []struct {
 	{"if a:\n    continue\nelif b:\n    break\nelif c:\n    pass\nelif c:\n    continue\n    pass\n", "exec", "Module(body=[If(test=Name(id='a', ctx=Load()), body=[Continue()], orelse=[If(test=Name(id='b', ctx=Load()), body=[Break()], orelse=[If(test=Name(id='c', ctx=Load()), body=[Pass()], orelse=[If(test=Name(id='c', ctx=Load()), body=[Continue(), Pass()], orelse=[])])])])])", nil, ""},
 	{"if a:\n    continue\nelif b:\n    break\nelse:\n    continue\n    pass\n", "exec", "Module(body=[If(test=Name(id='a', ctx=Load()), body=[Continue()], orelse=[If(test=Name(id='b', ctx=Load()), body=[Break()], orelse=[Continue(), Pass()])])])", nil, ""},
 	{"if a:\n    continue\nelif b:\n    break\nelif c:\n    pass\nelse:\n    continue\n    pass\n", "exec", "Module(body=[If(test=Name(id='a', ctx=Load()), body=[Continue()], orelse=[If(test=Name(id='b', ctx=Load()), body=[Break()], orelse=[If(test=Name(id='c', ctx=Load()), body=[Pass()], orelse=[Continue(), Pass()])])])])", nil, ""},
-	{"if lambda: None:\n pass\n", "exec", "Module(body=[If(test=Lambda(args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=NameConstant(value=None)), body=[Pass()], orelse=[])])", nil, ""},
+	{"if lambda: None:\n pass\n", "exec", "Module(body=[If(test=Lambda(args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=NameConstant(value=None)), body=[Pass()], orelse=[])])", nil, ""},
 	{"for a in b: pass", "exec", "Module(body=[For(target=Name(id='a', ctx=Store()), iter=Name(id='b', ctx=Load()), body=[Pass()], orelse=[])])", nil, ""},
 	{"for a, b in b: pass", "exec", "Module(body=[For(target=Tuple(elts=[Name(id='a', ctx=Store()), Name(id='b', ctx=Store())], ctx=Store()), iter=Name(id='b', ctx=Load()), body=[Pass()], orelse=[])])", nil, ""},
 	{"for a, b in b:\n pass\nelse: break\n", "exec", "Module(body=[For(target=Tuple(elts=[Name(id='a', ctx=Store()), Name(id='b', ctx=Store())], ctx=Store()), iter=Name(id='b', ctx=Load()), body=[Pass()], orelse=[Break()])])", nil, ""},
@@ -251,6 +303,7 @@ var grammarTestData = []struct {
 	{"a **= b", "exec", "Module(body=[AugAssign(target=Name(id='a', ctx=Store()), op=Pow(), value=Name(id='b', ctx=Load()))])", nil, ""},
 	{"a //= b", "exec", "Module(body=[AugAssign(target=Name(id='a', ctx=Store()), op=FloorDiv(), value=Name(id='b', ctx=Load()))])", nil, ""},
 	{"a //= yield b", "exec", "Module(body=[AugAssign(target=Name(id='a', ctx=Store()), op=FloorDiv(), value=Yield(value=Name(id='b', ctx=Load())))])", nil, ""},
+	{"a @= b", "exec", "Module(body=[AugAssign(target=Name(id='a', ctx=Store()), op=MatMult(), value=Name(id='b', ctx=Load()))])", nil, ""},
 	{"a <> b", "exec", "", py.SyntaxError, "invalid syntax"},
 	{"a.b += 1", "exec", "Module(body=[AugAssign(target=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Store()), op=Add(), value=Num(n=1))])", nil, ""},
 	{"a = b", "exec", "Module(body=[Assign(targets=[Name(id='a', ctx=Store())], value=Name(id='b', ctx=Load()))])", nil, ""},
@@ -277,37 +330,52 @@ var grammarTestData = []struct {
 	{"... = 1", "exec", "", py.SyntaxError, "can't assign to Ellipsis"},
 	{"(a < b) = 1", "exec", "", py.SyntaxError, "can't assign to comparison"},
 	{"(a if b else c) = 1", "exec", "", py.SyntaxError, "can't assign to conditional expression"},
-	{"lambda: a", "eval", "Expression(body=Lambda(args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda: lambda: a", "eval", "Expression(body=Lambda(args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Lambda(args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load()))))", nil, ""},
-	{"lambda a: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, b: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, b,: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a = b: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='b', ctx=Load())]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, b=c: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='c', ctx=Load())]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, *b: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, *b, c=d: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, *, c=d: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, *b, c=d, **kws: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda a, c=d, **kws: a", "eval", "Expression(body=Lambda(args=arguments(args=[arg(arg='a', annotation=None), arg(arg='c', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[Name(id='d', ctx=Load())]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda *args, c=d: a", "eval", "Expression(body=Lambda(args=arguments(args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda *args, c=d, **kws: a", "eval", "Expression(body=Lambda(args=arguments(args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"lambda **kws: a", "eval", "Expression(body=Lambda(args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
-	{"def fn(): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, b): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, b,): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a = b): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='b', ctx=Load())]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, b=c): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='c', ctx=Load())]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, *b): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, *b, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, *b, c=d, **kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, c=d, **kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None), arg(arg='c', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[Name(id='d', ctx=Load())]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(*args, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(a, *, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(*args, c=d, **kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn(**kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
-	{"def fn() -> None: pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=NameConstant(value=None))])", nil, ""},
-	{"def fn(a:'potato') -> 'sausage': pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[arg(arg='a', annotation=Str(s='potato'))], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=Str(s='sausage'))])", nil, ""},
+	{"a: int", "exec", "Module(body=[AnnAssign(target=Name(id='a', ctx=Store()), annotation=Name(id='int', ctx=Load()), value=None, simple=1)])", nil, ""},
+	{"a: int = 1", "exec", "Module(body=[AnnAssign(target=Name(id='a', ctx=Store()), annotation=Name(id='int', ctx=Load()), value=Num(n=1), simple=1)])", nil, ""},
+	{"(a): int", "exec", "Module(body=[AnnAssign(target=Name(id='a', ctx=Store()), annotation=Name(id='int', ctx=Load()), value=None, simple=0)])", nil, ""},
+	{"a.b: int = c", "exec", "Module(body=[AnnAssign(target=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Store()), annotation=Name(id='int', ctx=Load()), value=Name(id='c', ctx=Load()), simple=0)])", nil, ""},
+	{"a[1]: List[int] = yield", "exec", "Module(body=[AnnAssign(target=Subscript(value=Name(id='a', ctx=Load()), slice=Index(value=Num(n=1)), ctx=Store()), annotation=Subscript(value=Name(id='List', ctx=Load()), slice=Index(value=Name(id='int', ctx=Load())), ctx=Load()), value=Yield(value=None), simple=0)])", nil, ""},
+	{"a, b: int", "exec", "", py.SyntaxError, "only single target (not tuple) can be annotated"},
+	{"[a]: int", "exec", "", py.SyntaxError, "only single target (not list) can be annotated"},
+	{"f(): int", "exec", "", py.SyntaxError, "illegal target for annotation"},
+	{"lambda: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda: lambda: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Lambda(args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load()))))", nil, ""},
+	{"lambda a: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, b: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, b,: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a = b: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='b', ctx=Load())]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, b=c: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='c', ctx=Load())]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, *b: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, *b, c=d: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, *, c=d: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, *b, c=d, **kws: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, c=d, **kws: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='c', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[Name(id='d', ctx=Load())]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda *args, c=d: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda *args, c=d, **kws: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda **kws: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"lambda a, /: a", "eval", "Expression(body=Lambda(args=arguments(posonlyargs=[arg(arg='a', annotation=None)], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=Name(id='a', ctx=Load())))", nil, ""},
+	{"def fn(): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, b): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, b,): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a = b): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='b', ctx=Load())]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, b=c): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='c', ctx=Load())]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, *b): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, *b, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, *b, c=d, **kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=arg(arg='b', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, c=d, **kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None), arg(arg='c', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[Name(id='d', ctx=Load())]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(*args, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, *, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(*args, c=d, **kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=arg(arg='args', annotation=None), kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[Name(id='d', ctx=Load())], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(**kws): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=arg(arg='kws', annotation=None), defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, /, b, *, c): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[arg(arg='a', annotation=None)], args=[arg(arg='b', annotation=None)], vararg=None, kwonlyargs=[arg(arg='c', annotation=None)], kw_defaults=[None], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a=b, /, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[arg(arg='a', annotation=None)], args=[arg(arg='c', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[Name(id='b', ctx=Load()), Name(id='d', ctx=Load())]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(a, *, b, c=d): pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=None)], vararg=None, kwonlyargs=[arg(arg='b', annotation=None), arg(arg='c', annotation=None)], kw_defaults=[None, Name(id='d', ctx=Load())], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=None)])", nil, ""},
+	{"def fn(/): pass", "exec", "", py.SyntaxError, "at least one argument must precede /"},
+	{"def fn(a, /, b, /): pass", "exec", "", py.SyntaxError, "/ may appear only once"},
+	{"def fn(*, a, /): pass", "exec", "", py.SyntaxError, "/ must be ahead of *"},
+	{"def fn() -> None: pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=NameConstant(value=None))])", nil, ""},
+	{"def fn(a:'potato') -> 'sausage': pass", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[arg(arg='a', annotation=Str(s='potato'))], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[], returns=Str(s='sausage'))])", nil, ""},
 	{"del f()", "exec", "", py.SyntaxError, "can't delete function call"},
 	{"class A: pass", "exec", "Module(body=[ClassDef(name='A', bases=[], keywords=[], starargs=None, kwargs=None, body=[Pass()], decorator_list=[])])", nil, ""},
 	{"class A(): pass", "exec", "Module(body=[ClassDef(name='A', bases=[], keywords=[], starargs=None, kwargs=None, body=[Pass()], decorator_list=[])])", nil, ""},
@@ -315,11 +383,38 @@ var grammarTestData = []struct {
 	{"class A(B,C): pass", "exec", "Module(body=[ClassDef(name='A', bases=[Name(id='B', ctx=Load()), Name(id='C', ctx=Load())], keywords=[], starargs=None, kwargs=None, body=[Pass()], decorator_list=[])])", nil, ""},
 	{"class A(B,C,D=F): pass", "exec", "Module(body=[ClassDef(name='A', bases=[Name(id='B', ctx=Load()), Name(id='C', ctx=Load())], keywords=[keyword(arg='D', value=Name(id='F', ctx=Load()))], starargs=None, kwargs=None, body=[Pass()], decorator_list=[])])", nil, ""},
 	{"class A(B,C,D=F,*AS,**KWS): pass", "exec", "Module(body=[ClassDef(name='A', bases=[Name(id='B', ctx=Load()), Name(id='C', ctx=Load())], keywords=[keyword(arg='D', value=Name(id='F', ctx=Load()))], starargs=Name(id='AS', ctx=Load()), kwargs=Name(id='KWS', ctx=Load()), body=[Pass()], decorator_list=[])])", nil, ""},
-	{"@dec\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Name(id='dec', ctx=Load())], returns=None)])", nil, ""},
-	{"@dec()\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Call(func=Name(id='dec', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None)], returns=None)])", nil, ""},
-	{"@dec(a,b,c=d,*args,**kwargs)\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Call(func=Name(id='dec', ctx=Load()), args=[Name(id='a', ctx=Load()), Name(id='b', ctx=Load())], keywords=[keyword(arg='c', value=Name(id='d', ctx=Load()))], starargs=Name(id='args', ctx=Load()), kwargs=Name(id='kwargs', ctx=Load()))], returns=None)])", nil, ""},
-	{"@dec1\n@dec2()\n@dec3(a)\n@dec4(a,b)\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Name(id='dec1', ctx=Load()), Call(func=Name(id='dec2', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None), Call(func=Name(id='dec3', ctx=Load()), args=[Name(id='a', ctx=Load())], keywords=[], starargs=None, kwargs=None), Call(func=Name(id='dec4', ctx=Load()), args=[Name(id='a', ctx=Load()), Name(id='b', ctx=Load())], keywords=[], starargs=None, kwargs=None)], returns=None)])", nil, ""},
+	{"@dec\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Name(id='dec', ctx=Load())], returns=None)])", nil, ""},
+	{"@dec()\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Call(func=Name(id='dec', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None)], returns=None)])", nil, ""},
+	{"@dec(a,b,c=d,*args,**kwargs)\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Call(func=Name(id='dec', ctx=Load()), args=[Name(id='a', ctx=Load()), Name(id='b', ctx=Load())], keywords=[keyword(arg='c', value=Name(id='d', ctx=Load()))], starargs=Name(id='args', ctx=Load()), kwargs=Name(id='kwargs', ctx=Load()))], returns=None)])", nil, ""},
+	{"@dec1\n@dec2()\n@dec3(a)\n@dec4(a,b)\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Name(id='dec1', ctx=Load()), Call(func=Name(id='dec2', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None), Call(func=Name(id='dec3', ctx=Load()), args=[Name(id='a', ctx=Load())], keywords=[], starargs=None, kwargs=None), Call(func=Name(id='dec4', ctx=Load()), args=[Name(id='a', ctx=Load()), Name(id='b', ctx=Load())], keywords=[], starargs=None, kwargs=None)], returns=None)])", nil, ""},
 	{"@dec1\n@dec2()\n@dec3(a)\n@dec4(a,b)\nclass A(B):\n    pass\n", "exec", "Module(body=[ClassDef(name='A', bases=[Name(id='B', ctx=Load())], keywords=[], starargs=None, kwargs=None, body=[Pass()], decorator_list=[Name(id='dec1', ctx=Load()), Call(func=Name(id='dec2', ctx=Load()), args=[], keywords=[], starargs=None, kwargs=None), Call(func=Name(id='dec3', ctx=Load()), args=[Name(id='a', ctx=Load())], keywords=[], starargs=None, kwargs=None), Call(func=Name(id='dec4', ctx=Load()), args=[Name(id='a', ctx=Load()), Name(id='b', ctx=Load())], keywords=[], starargs=None, kwargs=None)])])", nil, ""},
+	{"@a.b\n@a.b.c(d)\ndef fn():\n    pass\n", "exec", "Module(body=[FunctionDef(name='fn', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Pass()], decorator_list=[Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Load()), Call(func=Attribute(value=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Load()), attr='c', ctx=Load()), args=[Name(id='d', ctx=Load())], keywords=[], starargs=None, kwargs=None)], returns=None)])", nil, ""},
+	{"async def f():\n    await a\n", "exec", "Module(body=[AsyncFunctionDef(name='f', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Expr(value=Await(value=Name(id='a', ctx=Load())))], decorator_list=[], returns=None)])", nil, ""},
+	{"async def f():\n    async for a in b:\n        pass\n    else:\n        pass\n", "exec", "Module(body=[AsyncFunctionDef(name='f', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[AsyncFor(target=Name(id='a', ctx=Store()), iter=Name(id='b', ctx=Load()), body=[Pass()], orelse=[Pass()])], decorator_list=[], returns=None)])", nil, ""},
+	{"async def f():\n    async with a as b, c:\n        pass\n", "exec", "Module(body=[AsyncFunctionDef(name='f', args=arguments(posonlyargs=[], args=[], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[AsyncWith(items=[withitem(context_expr=Name(id='a', ctx=Load()), optional_vars=Name(id='b', ctx=Store())), withitem(context_expr=Name(id='c', ctx=Load()), optional_vars=None)], body=[Pass()])], decorator_list=[], returns=None)])", nil, ""},
+	{"@dec\nasync def f(x) -> int:\n    return await a.b(c) ** 2\n", "exec", "Module(body=[AsyncFunctionDef(name='f', args=arguments(posonlyargs=[], args=[arg(arg='x', annotation=None)], vararg=None, kwonlyargs=[], kw_defaults=[], kwarg=None, defaults=[]), body=[Return(value=BinOp(left=Await(value=Call(func=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Load()), args=[Name(id='c', ctx=Load())], keywords=[], starargs=None, kwargs=None)), op=Pow(), right=Num(n=2)))], decorator_list=[Name(id='dec', ctx=Load())], returns=Name(id='int', ctx=Load()))])", nil, ""},
+	{"match x:\n case 1:\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchValue(value=Num(n=1)), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case -1 | 2.5 | -1+2j | 'a' 'b' | b'c':\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchOr(patterns=[MatchValue(value=UnaryOp(op=USub(), operand=Num(n=1))), MatchValue(value=Num(n=2.5)), MatchValue(value=BinOp(left=UnaryOp(op=USub(), operand=Num(n=1)), op=Add(), right=Num(n=2j))), MatchValue(value=Str(s='ab')), MatchValue(value=Bytes(s=b'c'))]), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case None:\n  pass\n case True | False:\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchSingleton(value=None), guard=None, body=[Pass()]), match_case(pattern=MatchOr(patterns=[MatchSingleton(value=True), MatchSingleton(value=False)]), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case a:\n  pass\n case _:\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchAs(pattern=None, name='a'), guard=None, body=[Pass()]), match_case(pattern=MatchAs(pattern=None, name=None), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case a.b.c:\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchValue(value=Attribute(value=Attribute(value=Name(id='a', ctx=Load()), attr='b', ctx=Load()), attr='c', ctx=Load())), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x, y:\n case a, *b:\n  pass\n case (a, *_):\n  pass\n case [a, (b, c)]:\n  pass\n", "exec", "Module(body=[Match(subject=Tuple(elts=[Name(id='x', ctx=Load()), Name(id='y', ctx=Load())], ctx=Load()), cases=[match_case(pattern=MatchSequence(patterns=[MatchAs(pattern=None, name='a'), MatchStar(name='b')]), guard=None, body=[Pass()]), match_case(pattern=MatchSequence(patterns=[MatchAs(pattern=None, name='a'), MatchStar(name=None)]), guard=None, body=[Pass()]), match_case(pattern=MatchSequence(patterns=[MatchAs(pattern=None, name='a'), MatchSequence(patterns=[MatchAs(pattern=None, name='b'), MatchAs(pattern=None, name='c')])]), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case a,:\n  pass\n case ():\n  pass\n case []:\n  pass\n case (a):\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchSequence(patterns=[MatchAs(pattern=None, name='a')]), guard=None, body=[Pass()]), match_case(pattern=MatchSequence(patterns=[]), guard=None, body=[Pass()]), match_case(pattern=MatchSequence(patterns=[]), guard=None, body=[Pass()]), match_case(pattern=MatchAs(pattern=None, name='a'), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case {}:\n  pass\n case {1: a, 'b': [c], d.e: f, **rest}:\n  pass\n case {**rest}:\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchMapping(keys=[], patterns=[], rest=None), guard=None, body=[Pass()]), match_case(pattern=MatchMapping(keys=[Num(n=1), Str(s='b'), Attribute(value=Name(id='d', ctx=Load()), attr='e', ctx=Load())], patterns=[MatchAs(pattern=None, name='a'), MatchSequence(patterns=[MatchAs(pattern=None, name='c')]), MatchAs(pattern=None, name='f')], rest='rest'), guard=None, body=[Pass()]), match_case(pattern=MatchMapping(keys=[], patterns=[], rest='rest'), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case Point():\n  pass\n case Point(1, y=2):\n  pass\n case a.B(c, d=e,):\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchClass(cls=Name(id='Point', ctx=Load()), patterns=[], kwd_attrs=[], kwd_patterns=[]), guard=None, body=[Pass()]), match_case(pattern=MatchClass(cls=Name(id='Point', ctx=Load()), patterns=[MatchValue(value=Num(n=1))], kwd_attrs=['y'], kwd_patterns=[MatchValue(value=Num(n=2))]), guard=None, body=[Pass()]), match_case(pattern=MatchClass(cls=Attribute(value=Name(id='a', ctx=Load()), attr='B', ctx=Load()), patterns=[MatchAs(pattern=None, name='c')], kwd_attrs=['d'], kwd_patterns=[MatchAs(pattern=None, name='e')]), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case [1, 2] as y if y:\n  pass\n case (1 | 2) as z:\n  pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchAs(pattern=MatchSequence(patterns=[MatchValue(value=Num(n=1)), MatchValue(value=Num(n=2))]), name='y'), guard=Name(id='y', ctx=Load()), body=[Pass()]), match_case(pattern=MatchAs(pattern=MatchOr(patterns=[MatchValue(value=Num(n=1)), MatchValue(value=Num(n=2))]), name='z'), guard=None, body=[Pass()])])])", nil, ""},
+	{"match x:\n case 1: pass\n case 2: pass\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchValue(value=Num(n=1)), guard=None, body=[Pass()]), match_case(pattern=MatchValue(value=Num(n=2)), guard=None, body=[Pass()])])])", nil, ""},
+	{"match = 1\nmatch.x(match)\ncase = 2\nmatch[1]\n", "exec", "Module(body=[Assign(targets=[Name(id='match', ctx=Store())], value=Num(n=1)), Expr(value=Call(func=Attribute(value=Name(id='match', ctx=Load()), attr='x', ctx=Load()), args=[Name(id='match', ctx=Load())], keywords=[], starargs=None, kwargs=None)), Assign(targets=[Name(id='case', ctx=Store())], value=Num(n=2)), Expr(value=Subscript(value=Name(id='match', ctx=Load()), slice=Index(value=Num(n=1)), ctx=Load()))])", nil, ""},
+	{"match x:\n case 1:\n  match y:\n   case 2:\n    pass\n  case = 3\n", "exec", "Module(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchValue(value=Num(n=1)), guard=None, body=[Match(subject=Name(id='y', ctx=Load()), cases=[match_case(pattern=MatchValue(value=Num(n=2)), guard=None, body=[Pass()])]), Assign(targets=[Name(id='case', ctx=Store())], value=Num(n=3))])])])", nil, ""},
+	{"match *x:\n case 1:\n  pass\n", "exec", "", py.SyntaxError, "invalid syntax"},
+	{"match x:\n case *a:\n  pass\n", "exec", "", py.SyntaxError, "invalid syntax"},
+	{"match x:\n case a as _:\n  pass\n", "exec", "", py.SyntaxError, "cannot use '_' as a target"},
+	{"match x:\n case 1j+2:\n  pass\n", "exec", "", py.SyntaxError, "real number required in complex literal"},
+	{"match x:\n case 1+2:\n  pass\n", "exec", "", py.SyntaxError, "imaginary number required in complex literal"},
+	{"match x:\n case {a: 1}:\n  pass\n", "exec", "", py.SyntaxError, "invalid syntax"},
+	{"match x:\n case C(a=1, b):\n  pass\n", "exec", "", py.SyntaxError, "positional patterns follow keyword patterns"},
+	{"match x:\n pass\n", "exec", "", py.SyntaxError, "invalid syntax"},
+	{"match x:\n case 1:\n  pass\n\n", "single", "Interactive(body=[Match(subject=Name(id='x', ctx=Load()), cases=[match_case(pattern=MatchValue(value=Num(n=1)), guard=None, body=[Pass()])])])", nil, ""},
 	{"", "single", "", py.SyntaxError, "unexpected EOF while parsing"},
 	{"\n", "single", "", py.SyntaxError, "unexpected EOF while parsing"},
 	{"pass\n", "single", "Interactive(body=[Pass()])", nil, ""},
@@ -36,24 +36,35 @@ const tabSize = 8
 // the methods Lex(*<prefix>SymType) int and Error(string).
 type yyLex struct {
 	reader        *bufio.Reader
-	filename      string     // name of the file being read
-	line          string     // current line being parsed
-	lastLine      string     // last line that was parsed
-	pos           ast.Pos    // current position within file
-	yylval        *yySymType // last token
-	eof           bool       // flag to show EOF was read
-	error         bool       // set if an error has ocurred
-	errorString   string     // the string of the error
-	indentStack   []int      // indent stack to control INDENT / DEDENT tokens
-	state         int        // current state of state machine
-	currentIndent string     // whitespace at start of current line
-	interactive   bool       // set if mode "single" reading interactive input
-	exec          bool       // set if mode "exec" reading from file
-	bracket       int        // number of open [ ]
-	parenthesis   int        // number of open ( )
-	brace         int        // number of open { }
-	mod           ast.Mod    // output
-	tokens        []int      // buffered tokens to output
+	filename      string      // name of the file being read
+	line          string      // current line being parsed
+	lastLine      string      // last line that was parsed
+	pos           ast.Pos     // current position within file
+	yylval        *yySymType  // last token
+	eof           bool        // flag to show EOF was read
+	error         bool        // set if an error has ocurred
+	errorString   string      // the string of the error
+	indentStack   []int       // indent stack to control INDENT / DEDENT tokens
+	state         int         // current state of state machine
+	currentIndent string      // whitespace at start of current line
+	interactive   bool        // set if mode "single" reading interactive input
+	exec          bool        // set if mode "exec" reading from file
+	bracket       int         // number of open [ ]
+	parenthesis   int         // number of open ( )
+	brace         int         // number of open { }
+	mod           ast.Mod     // output
+	tokens        []int       // buffered tokens to output
+	lookahead     []lexToken  // tokens read ahead to resolve soft keywords
+	lineStart     bool        // set if the next token starts a logical line
+	matchPending  bool        // set if the next INDENT opens a match block
+	matchIndents  []int       // indentStack depths of the open match blocks
+	spans         []tokenSpan // extent of each token read
+}
+
+// A token read ahead along with its value
+type lexToken struct {
+	token  int
+	yylval yySymType
 }
 
 // Create a new lexer
@@ -128,6 +139,12 @@ func (x *yyLex) refill() {
 	if x.eof && x.exec && len(x.line) > 0 && x.line[len(x.line)-1] != '\n' {
 		x.line += "\n"
 	}
+	// Single input is finished by a blank line so leave the eof
+	// to be read after it.
+	if x.eof && x.interactive && len(x.line) > 0 && x.line[len(x.line)-1] != '\n' {
+		x.line += "\n"
+		x.eof = false
+	}
 	x.lastLine = x.line
 }
 
@@ -203,6 +220,7 @@ var operators = map[string]int{
 	"+=": PLUSEQ,
 	"-=": MINUSEQ,
 	"->": MINUSGT,
+	":=": COLONEQ,
 	"//": DIVDIV,
 	"/=": DIVEQ,
 	"<<": LTLT,
@@ -211,6 +229,7 @@ var operators = map[string]int{
 	"==": EQEQ,
 	">=": GTEQ,
 	">>": GTGT,
+	"@=": ATEQ,
 	"^=": HATEQ,
 	"|=": PIPEEQ,
 
@@ -230,6 +249,8 @@ var tokens = map[string]int{
 	"and":      AND,
 	"as":       AS,
 	"assert":   ASSERT,
+	"async":    ASYNC,
+	"await":    AWAIT,
 	"break":    BREAK,
 	"class":    CLASS,
 	"continue": CONTINUE,
@@ -278,10 +299,13 @@ func init() {
 	tokenToString[INDENT] = "INDENT"
 	tokenToString[DEDENT] = "DEDENT"
 	tokenToString[STRING] = "STRING"
+	tokenToString[FSTRING] = "FSTRING"
 	tokenToString[NUMBER] = "NUMBER"
 	tokenToString[FILE_INPUT] = "FILE_INPUT"
 	tokenToString[SINGLE_INPUT] = "SINGLE_INPUT"
 	tokenToString[EVAL_INPUT] = "EVAL_INPUT"
+	tokenToString[MATCH] = "match"
+	tokenToString[CASE] = "case"
 }
 
 // True if there are any open brackets
@@ -313,7 +337,7 @@ func newLexToken(token int, yylval *yySymType) (lt LexToken) {
 	lt.pos = yylval.pos
 	if token == NAME {
 		lt.value = py.String(yylval.str)
-	} else if token == STRING || token == NUMBER {
+	} else if token == STRING || token == FSTRING || token == NUMBER {
 		lt.value = yylval.obj
 	} else {
 		lt.value = nil
@@ -370,9 +394,99 @@ func (x *yyLex) queueDedents() {
 	x.indentStack = x.indentStack[:1]
 }
 
-// The parser calls this method to get each new token.  This
-// implementation returns operators and NUM.
+// The parser calls this method to get each new token.
+//
+// This resolves the soft keywords match and case which are only
+// keywords at the start of a match statement or a case block
+// respectively and are names everywhere else.
 func (x *yyLex) Lex(yylval *yySymType) (ret int) {
+	if len(x.lookahead) > 0 {
+		*yylval = x.lookahead[0].yylval
+		ret = x.lookahead[0].token
+		x.lookahead = x.lookahead[1:]
+	} else {
+		ret = x.readToken(yylval)
+	}
+	lineStart := x.lineStart
+	switch ret {
+	case NEWLINE, INDENT, DEDENT, FILE_INPUT, SINGLE_INPUT, EVAL_INPUT:
+		x.lineStart = true
+	default:
+		x.lineStart = false
+	}
+	switch ret {
+	case INDENT:
+		if x.matchPending {
+			x.matchIndents = append(x.matchIndents, len(x.indentStack))
+			x.matchPending = false
+		}
+	case DEDENT:
+		for len(x.matchIndents) > 0 && x.matchIndents[len(x.matchIndents)-1] > len(x.indentStack) {
+			x.matchIndents = x.matchIndents[:len(x.matchIndents)-1]
+		}
+	case NAME:
+		if !lineStart {
+			break
+		}
+		switch yylval.str {
+		case "match":
+			if x.isMatchStatement() {
+				ret = MATCH
+				x.matchPending = true
+			}
+		case "case":
+			if n := len(x.matchIndents); n > 0 && x.matchIndents[n-1] == len(x.indentStack) {
+				ret = CASE
+			}
+		}
+	}
+	return ret
+}
+
+// Reads ahead to the end of the logical line to see whether the
+// "match" just read starts a match statement.
+//
+// No other statement starting with a name can end in a ':' so this is
+// the case if the line ends with one.
+func (x *yyLex) isMatchStatement() bool {
+	last := eof
+	for i := 0; ; i++ {
+		var yylval yySymType
+		token := x.readToken(&yylval)
+		x.lookahead = append(x.lookahead, lexToken{token: token, yylval: yylval})
+		switch token {
+		case NEWLINE:
+			return i > 1 && last == ':'
+		case eof, ENDMARKER:
+			return false
+		}
+		if i == 0 && (token == ':' || token == '=') {
+			return false
+		}
+		last = token
+	}
+}
+
+// Reads the next token from the input noting where it is for
+// setEndPositions
+func (x *yyLex) readToken(yylval *yySymType) int {
+	token := x.lexToken(yylval)
+	span := tokenSpan{token: token, start: position{yylval.pos.Lineno, yylval.pos.ColOffset}}
+	switch token {
+	case INDENT, DEDENT, FILE_INPUT, SINGLE_INPUT, EVAL_INPUT, eof:
+		// Not in the source
+		return token
+	case NEWLINE, ENDMARKER:
+		span.end = span.start
+	default:
+		span.end = position{x.pos.Lineno, x.pos.ColOffset}
+	}
+	x.spans = append(x.spans, span)
+	return token
+}
+
+// Reads the next token from the input
+func (x *yyLex) lexToken(yylval *yySymType) (ret int) {
 	// Clear out the yySymType on each token (copied from rsc's cc)
 	*yylval = yySymType{}
 	x.yylval = yylval
@@ -775,8 +889,9 @@ func (x *yyLex) readString() (token int, value py.Object) {
 		}
 	}
 
-	rawString := false  // whether we are parsing a r"" string
-	byteString := false // whether we are parsing a b"" string
+	rawString := false    // whether we are parsing a r"" string
+	byteString := false   // whether we are parsing a b"" string
+	formatString := false // whether we are parsing a f"" string
 	// u"" strings are just normal strings so we ignore that qualifier
 
 	// Start of string
@@ -798,6 +913,11 @@ func (x *yyLex) readString() (token int, value py.Object) {
 		x.cut(1)
 		goto found
 	}
+	if (r0 == 'f' || r0 == 'F') && (r1 == '\'' || r1 == '"') {
+		formatString = true
+		x.cut(1)
+		goto found
+	}
 	// Or start of br"" Br"" bR"" BR"" rb"" rB"" Rb"" RB""
 	if (r0 == 'r' || r0 == 'R') && (r1 == 'b' || r1 == 'B') && (r2 == '\'' || r2 == '"') {
 		rawString = true
@@ -811,6 +931,19 @@ func (x *yyLex) readString() (token int, value py.Object) {
 		x.cut(2)
 		goto found
 	}
+	// Or start of fr"" Fr"" fR"" FR"" rf"" rF"" Rf"" RF""
+	if (r0 == 'r' || r0 == 'R') && (r1 == 'f' || r1 == 'F') && (r2 == '\'' || r2 == '"') {
+		rawString = true
+		formatString = true
+		x.cut(2)
+		goto found
+	}
+	if (r0 == 'f' || r0 == 'F') && (r1 == 'r' || r1 == 'R') && (r2 == '\'' || r2 == '"') {
+		rawString = true
+		formatString = true
+		x.cut(2)
+		goto found
+	}
 	return eof, nil
 found:
 	multiLineString := false
@@ -835,6 +968,7 @@ found:
 	} else {
 		panic("Bad string start")
 	}
+	bodyPos := x.pos
 	buf := new(bytes.Buffer)
 	for {
 		escape := false
@@ -877,6 +1011,15 @@ found:
 		x.refill()
 	}
 foundEndOfString:
+	if formatString {
+		// The parts of f"" strings are decoded separately
+		joinedStr, err := parseFString(buf.String(), rawString, bodyPos)
+		if err != nil {
+			x.SyntaxErrorf("%v", err)
+			return eofError, nil
+		}
+		return FSTRING, joinedStr
+	}
 	if !rawString {
 		var err error
 		buf, err = DecodeEscape(buf, byteString)
@@ -944,11 +1087,28 @@ func Parse(in io.Reader, filename string, mode string) (mod ast.Mod, err error)
 		}
 	}()
 	yyParse(lex)
+	// Only the parser reports errors without a message
+	parseError := lex.errorString == ""
 	err = lex.ErrorReturn()
 	if err != nil {
-		err = py.MakeSyntaxError(err, filename, lex.pos.Lineno, lex.pos.ColOffset, lex.lastLine)
+		return nil, lex.syntaxError(err, parseError)
+	}
+	setEndPositions(lex.mod, lex.spans)
+	return lex.mod, nil
+}
+
+// Returns err as a SyntaxError at the current position
+//
+// If it is a parseError because the parser couldn't make sense of the
+// last token read then the SyntaxError covers that token.
+func (x *yyLex) syntaxError(err error, parseError bool) error {
+	if parseError && x.errorString == "invalid syntax" && len(x.spans) > 0 {
+		span := x.spans[len(x.spans)-1]
+		if span.token != NEWLINE && span.token != ENDMARKER && span.start.lineno == x.pos.Lineno {
+			return py.MakeSyntaxErrorRange(err, x.filename, span.start.lineno, span.start.colOffset, span.end.lineno, span.end.colOffset, x.lastLine)
+		}
 	}
-	return lex.mod, err
+	return py.MakeSyntaxError(err, x.filename, x.pos.Lineno, x.pos.ColOffset, x.lastLine)
 }
 
 // Parse a string
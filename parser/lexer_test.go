@@ -93,64 +93,64 @@ func TestLexTokensEq(t *testing.T) {
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			true,
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			false,
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			LexTokens{
-				{NUMBER, py.Int(2), ast.Pos{1, 0}},
+				{NUMBER, py.Int(2), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			false,
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			LexTokens{
-				{NEWLINE, nil, ast.Pos{1, 0}},
+				{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			false,
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
-				{NEWLINE, nil, ast.Pos{1, 0}},
-				{ENDMARKER, nil, ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+				{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+				{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
-				{NEWLINE, nil, ast.Pos{1, 0}},
-				{ENDMARKER, nil, ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+				{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+				{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			true,
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
-				{NEWLINE, nil, ast.Pos{1, 0}},
-				{ENDMARKER, nil, ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+				{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+				{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
-				{NEWLINE, nil, ast.Pos{1, 0}},
-				{NEWLINE, nil, ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+				{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+				{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			false,
 		},
@@ -173,14 +173,14 @@ func TestLexTokensString(t *testing.T) {
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 0}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
 			},
 			`[{"NUMBER" (57352) = py.Int{1} 1:0}, ]`,
 		},
 		{
 			LexTokens{
-				{NUMBER, py.Int(1), ast.Pos{1, 2}},
-				{NUMBER, py.Int(1), ast.Pos{3, 4}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 2}},
+				{NUMBER, py.Int(1), ast.Pos{Lineno: 3, ColOffset: 4}},
 			},
 			`[{"NUMBER" (57352) = py.Int{1} 1:2}, {"NUMBER" (57352) = py.Int{1} 3:4}, ]`,
 		},
@@ -200,222 +200,254 @@ func TestLex(t *testing.T) {
 		lts       LexTokens
 	}{
 		{"", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{ENDMARKER, nil, ast.Pos{1, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
 		}},
 		{"", "", "single", LexTokens{
-			{SINGLE_INPUT, nil, ast.Pos{0, 0}},
-			{NEWLINE, nil, ast.Pos{1, 0}},
+			{SINGLE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
 		}},
 		{"\n", "", "single", LexTokens{
-			{SINGLE_INPUT, nil, ast.Pos{0, 0}},
-			{NEWLINE, nil, ast.Pos{2, 0}},
+			{SINGLE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
 		}},
 		{"pass", "", "single", LexTokens{
-			{SINGLE_INPUT, nil, ast.Pos{0, 0}},
-			{PASS, nil, ast.Pos{1, 0}},
+			{SINGLE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{PASS, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 4}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
 		}},
 		{"pass\n", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{PASS, nil, ast.Pos{1, 0}},
-			{NEWLINE, nil, ast.Pos{1, 4}},
-			{ENDMARKER, nil, ast.Pos{2, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{PASS, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 4}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
 		}},
 		{"\n#hello\n  #comment\n", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{ENDMARKER, nil, ast.Pos{4, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
 		}},
 		{"\n#hello\n\f  #comment\n", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{ENDMARKER, nil, ast.Pos{4, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
 		}},
 		{"1\n 2\n", "", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{NEWLINE, nil, ast.Pos{1, 1}},
-			{INDENT, nil, ast.Pos{2, 0}},
-			{NUMBER, py.Int(2), ast.Pos{2, 1}},
-			{NEWLINE, nil, ast.Pos{2, 2}},
-			{DEDENT, nil, ast.Pos{3, 0}},
-			{ENDMARKER, nil, ast.Pos{3, 0}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 1}},
+			{INDENT, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
+			{NUMBER, py.Int(2), ast.Pos{Lineno: 2, ColOffset: 1}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 2}},
+			{DEDENT, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
 		}},
 		{"1", "", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{ENDMARKER, nil, ast.Pos{1, 1}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 1}},
 		}},
 		{"01", "illegal decimal with leading zero 1:0", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
 		}},
 		{"1", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{NEWLINE, nil, ast.Pos{1, 1}},
-			{ENDMARKER, nil, ast.Pos{1, 1}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 1}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 1}},
 		}},
 		{"1 2 3", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{NUMBER, py.Int(2), ast.Pos{1, 2}},
-			{NUMBER, py.Int(3), ast.Pos{1, 4}},
-			{NEWLINE, nil, ast.Pos{1, 5}},
-			{ENDMARKER, nil, ast.Pos{1, 5}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NUMBER, py.Int(2), ast.Pos{Lineno: 1, ColOffset: 2}},
+			{NUMBER, py.Int(3), ast.Pos{Lineno: 1, ColOffset: 4}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 5}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 5}},
 		}},
 		{"01", "illegal decimal with leading zero 1:0", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
 		}},
 		{"1\n 2\n  3\n4\n", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{NEWLINE, nil, ast.Pos{1, 1}},
-			{INDENT, nil, ast.Pos{2, 0}},
-			{NUMBER, py.Int(2), ast.Pos{2, 1}},
-			{NEWLINE, nil, ast.Pos{2, 2}},
-			{INDENT, nil, ast.Pos{3, 0}},
-			{NUMBER, py.Int(3), ast.Pos{3, 2}},
-			{NEWLINE, nil, ast.Pos{3, 3}},
-			{DEDENT, nil, ast.Pos{4, 0}},
-			{DEDENT, nil, ast.Pos{4, 0}},
-			{NUMBER, py.Int(4), ast.Pos{4, 0}},
-			{NEWLINE, nil, ast.Pos{4, 1}},
-			{ENDMARKER, nil, ast.Pos{5, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 1}},
+			{INDENT, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
+			{NUMBER, py.Int(2), ast.Pos{Lineno: 2, ColOffset: 1}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 2}},
+			{INDENT, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{NUMBER, py.Int(3), ast.Pos{Lineno: 3, ColOffset: 2}},
+			{NEWLINE, nil, ast.Pos{Lineno: 3, ColOffset: 3}},
+			{DEDENT, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+			{DEDENT, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+			{NUMBER, py.Int(4), ast.Pos{Lineno: 4, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 4, ColOffset: 1}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 5, ColOffset: 0}},
 		}},
 		{"if 1:\n  pass \n pass\n", "Inconsistent indent 3:1", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{IF, nil, ast.Pos{1, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 3}},
-			{':', nil, ast.Pos{1, 4}},
-			{NEWLINE, nil, ast.Pos{1, 5}},
-			{INDENT, nil, ast.Pos{2, 0}},
-			{PASS, nil, ast.Pos{2, 2}},
-			{NEWLINE, nil, ast.Pos{2, 6}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{IF, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 3}},
+			{':', nil, ast.Pos{Lineno: 1, ColOffset: 4}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 5}},
+			{INDENT, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
+			{PASS, nil, ast.Pos{Lineno: 2, ColOffset: 2}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 6}},
 		}},
 		{"(\n  1\n)", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{'(', nil, ast.Pos{1, 0}},
-			{NUMBER, py.Int(1), ast.Pos{2, 2}},
-			{')', nil, ast.Pos{3, 0}},
-			{NEWLINE, nil, ast.Pos{3, 1}},
-			{ENDMARKER, nil, ast.Pos{3, 1}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{'(', nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 2, ColOffset: 2}},
+			{')', nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 3, ColOffset: 1}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 3, ColOffset: 1}},
 		}},
 		{"{\n  1\n}", "", "single", LexTokens{
-			{SINGLE_INPUT, nil, ast.Pos{0, 0}},
-			{'{', nil, ast.Pos{1, 0}},
-			{NUMBER, py.Int(1), ast.Pos{2, 2}},
-			{'}', nil, ast.Pos{3, 0}},
+			{SINGLE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{'{', nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 2, ColOffset: 2}},
+			{'}', nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 3, ColOffset: 1}},
+			{NEWLINE, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
 		}},
 		{"[\n  1\n]", "", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
-			{'[', nil, ast.Pos{1, 0}},
-			{NUMBER, py.Int(1), ast.Pos{2, 2}},
-			{']', nil, ast.Pos{3, 0}},
-			{ENDMARKER, nil, ast.Pos{3, 1}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{'[', nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 2, ColOffset: 2}},
+			{']', nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 3, ColOffset: 1}},
 		}},
 		{"1\\\n2", "", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{NUMBER, py.Int(2), ast.Pos{2, 0}},
-			{ENDMARKER, nil, ast.Pos{2, 1}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NUMBER, py.Int(2), ast.Pos{Lineno: 2, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 2, ColOffset: 1}},
 		}},
 		{"1\\\n", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{ENDMARKER, nil, ast.Pos{2, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
 		}},
 		{"1\\", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{ENDMARKER, nil, ast.Pos{1, 1}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 1}},
 		}},
 		{"'1\\\n2'", "", "single", LexTokens{
-			{SINGLE_INPUT, nil, ast.Pos{0, 0}},
-			{STRING, py.String("12"), ast.Pos{1, 0}},
+			{SINGLE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{STRING, py.String("12"), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 2}},
+			{NEWLINE, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
 		}},
 		{"0x1234 +\t0.1-6.1j", "", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(0x1234), ast.Pos{1, 0}},
-			{'+', nil, ast.Pos{1, 7}},
-			{NUMBER, py.Float(0.1), ast.Pos{1, 9}},
-			{'-', nil, ast.Pos{1, 12}},
-			{NUMBER, py.Complex(complex(0, 6.1)), ast.Pos{1, 13}},
-			{ENDMARKER, nil, ast.Pos{1, 17}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(0x1234), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{'+', nil, ast.Pos{Lineno: 1, ColOffset: 7}},
+			{NUMBER, py.Float(0.1), ast.Pos{Lineno: 1, ColOffset: 9}},
+			{'-', nil, ast.Pos{Lineno: 1, ColOffset: 12}},
+			{NUMBER, py.Complex(complex(0, 6.1)), ast.Pos{Lineno: 1, ColOffset: 13}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 1, ColOffset: 17}},
 		}},
 		{"001", "illegal decimal with leading zero 1:0", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
 		}},
 		{"u'''1\n2\n'''", "", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
-			{STRING, py.String("1\n2\n"), ast.Pos{1, 0}},
-			{ENDMARKER, nil, ast.Pos{3, 3}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{STRING, py.String("1\n2\n"), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 3, ColOffset: 3}},
 		}},
 		{"\"hello\n", "EOL while scanning string literal 1:1", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
 		}},
 		{"1 >>-3\na <<=+12", "", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
-			{NUMBER, py.Int(1), ast.Pos{1, 0}},
-			{GTGT, nil, ast.Pos{1, 2}},
-			{'-', nil, ast.Pos{1, 4}},
-			{NUMBER, py.Int(3), ast.Pos{1, 5}},
-			{NEWLINE, nil, ast.Pos{1, 6}},
-			{NAME, py.String("a"), ast.Pos{2, 0}},
-			{LTLTEQ, nil, ast.Pos{2, 2}},
-			{'+', nil, ast.Pos{2, 5}},
-			{NUMBER, py.Int(12), ast.Pos{2, 6}},
-			{ENDMARKER, nil, ast.Pos{2, 8}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NUMBER, py.Int(1), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{GTGT, nil, ast.Pos{Lineno: 1, ColOffset: 2}},
+			{'-', nil, ast.Pos{Lineno: 1, ColOffset: 4}},
+			{NUMBER, py.Int(3), ast.Pos{Lineno: 1, ColOffset: 5}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 6}},
+			{NAME, py.String("a"), ast.Pos{Lineno: 2, ColOffset: 0}},
+			{LTLTEQ, nil, ast.Pos{Lineno: 2, ColOffset: 2}},
+			{'+', nil, ast.Pos{Lineno: 2, ColOffset: 5}},
+			{NUMBER, py.Int(12), ast.Pos{Lineno: 2, ColOffset: 6}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 2, ColOffset: 8}},
 		}},
 		{"$asdasd", "invalid syntax 1:0", "eval", LexTokens{
-			{EVAL_INPUT, nil, ast.Pos{0, 0}},
+			{EVAL_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
 		}},
 		{"if True:\n   pass\n\n", "", "single", LexTokens{
-			{SINGLE_INPUT, nil, ast.Pos{0, 0}},
-			{IF, nil, ast.Pos{1, 0}},
-			{TRUE, nil, ast.Pos{1, 3}},
-			{':', nil, ast.Pos{1, 7}},
-			{NEWLINE, nil, ast.Pos{1, 8}},
-			{INDENT, nil, ast.Pos{2, 0}},
-			{PASS, nil, ast.Pos{2, 3}},
-			{NEWLINE, nil, ast.Pos{2, 7}},
-			{DEDENT, nil, ast.Pos{4, 0}},
-			{NEWLINE, nil, ast.Pos{4, 0}},
+			{SINGLE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{IF, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{TRUE, nil, ast.Pos{Lineno: 1, ColOffset: 3}},
+			{':', nil, ast.Pos{Lineno: 1, ColOffset: 7}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 8}},
+			{INDENT, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
+			{PASS, nil, ast.Pos{Lineno: 2, ColOffset: 3}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 7}},
+			{DEDENT, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
 		}},
 		{"while True:\n pass\nelse:\n return\n", "", "single", LexTokens{
-			{SINGLE_INPUT, nil, ast.Pos{0, 0}},
-			{WHILE, nil, ast.Pos{1, 0}},
-			{TRUE, nil, ast.Pos{1, 6}},
-			{':', nil, ast.Pos{1, 10}},
-			{NEWLINE, nil, ast.Pos{1, 11}},
-			{INDENT, nil, ast.Pos{2, 0}},
-			{PASS, nil, ast.Pos{2, 1}},
-			{NEWLINE, nil, ast.Pos{2, 5}},
-			{DEDENT, nil, ast.Pos{3, 0}},
-			{ELSE, nil, ast.Pos{3, 0}},
-			{':', nil, ast.Pos{3, 4}},
-			{NEWLINE, nil, ast.Pos{3, 5}},
-			{INDENT, nil, ast.Pos{4, 0}},
-			{RETURN, nil, ast.Pos{4, 1}},
-			{NEWLINE, nil, ast.Pos{4, 7}},
-			{DEDENT, nil, ast.Pos{5, 0}},
-			{NEWLINE, nil, ast.Pos{5, 0}},
+			{SINGLE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{WHILE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{TRUE, nil, ast.Pos{Lineno: 1, ColOffset: 6}},
+			{':', nil, ast.Pos{Lineno: 1, ColOffset: 10}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 11}},
+			{INDENT, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
+			{PASS, nil, ast.Pos{Lineno: 2, ColOffset: 1}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 5}},
+			{DEDENT, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{ELSE, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{':', nil, ast.Pos{Lineno: 3, ColOffset: 4}},
+			{NEWLINE, nil, ast.Pos{Lineno: 3, ColOffset: 5}},
+			{INDENT, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+			{RETURN, nil, ast.Pos{Lineno: 4, ColOffset: 1}},
+			{NEWLINE, nil, ast.Pos{Lineno: 4, ColOffset: 7}},
+			{DEDENT, nil, ast.Pos{Lineno: 5, ColOffset: 0}},
+			{NEWLINE, nil, ast.Pos{Lineno: 5, ColOffset: 0}},
 		}},
 		{"while True:\n pass\nelse:\n return\n", "", "exec", LexTokens{
-			{FILE_INPUT, nil, ast.Pos{0, 0}},
-			{WHILE, nil, ast.Pos{1, 0}},
-			{TRUE, nil, ast.Pos{1, 6}},
-			{':', nil, ast.Pos{1, 10}},
-			{NEWLINE, nil, ast.Pos{1, 11}},
-			{INDENT, nil, ast.Pos{2, 0}},
-			{PASS, nil, ast.Pos{2, 1}},
-			{NEWLINE, nil, ast.Pos{2, 5}},
-			{DEDENT, nil, ast.Pos{3, 0}},
-			{ELSE, nil, ast.Pos{3, 0}},
-			{':', nil, ast.Pos{3, 4}},
-			{NEWLINE, nil, ast.Pos{3, 5}},
-			{INDENT, nil, ast.Pos{4, 0}},
-			{RETURN, nil, ast.Pos{4, 1}},
-			{NEWLINE, nil, ast.Pos{4, 7}},
-			{DEDENT, nil, ast.Pos{5, 0}},
-			{ENDMARKER, nil, ast.Pos{5, 0}},
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{WHILE, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{TRUE, nil, ast.Pos{Lineno: 1, ColOffset: 6}},
+			{':', nil, ast.Pos{Lineno: 1, ColOffset: 10}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 11}},
+			{INDENT, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
+			{PASS, nil, ast.Pos{Lineno: 2, ColOffset: 1}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 5}},
+			{DEDENT, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{ELSE, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{':', nil, ast.Pos{Lineno: 3, ColOffset: 4}},
+			{NEWLINE, nil, ast.Pos{Lineno: 3, ColOffset: 5}},
+			{INDENT, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+			{RETURN, nil, ast.Pos{Lineno: 4, ColOffset: 1}},
+			{NEWLINE, nil, ast.Pos{Lineno: 4, ColOffset: 7}},
+			{DEDENT, nil, ast.Pos{Lineno: 5, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 5, ColOffset: 0}},
+		}},
+		{"match x:\n case y:\n  pass\n", "", "exec", LexTokens{
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{MATCH, nil, ast.Pos{Lineno: 1, ColOffset: 0}},
+			{NAME, py.String("x"), ast.Pos{Lineno: 1, ColOffset: 6}},
+			{':', nil, ast.Pos{Lineno: 1, ColOffset: 7}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 8}},
+			{INDENT, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
+			{CASE, nil, ast.Pos{Lineno: 2, ColOffset: 1}},
+			{NAME, py.String("y"), ast.Pos{Lineno: 2, ColOffset: 6}},
+			{':', nil, ast.Pos{Lineno: 2, ColOffset: 7}},
+			{NEWLINE, nil, ast.Pos{Lineno: 2, ColOffset: 8}},
+			{INDENT, nil, ast.Pos{Lineno: 3, ColOffset: 0}},
+			{PASS, nil, ast.Pos{Lineno: 3, ColOffset: 2}},
+			{NEWLINE, nil, ast.Pos{Lineno: 3, ColOffset: 6}},
+			{DEDENT, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+			{DEDENT, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 4, ColOffset: 0}},
+		}},
+		{"match = case\n", "", "exec", LexTokens{
+			{FILE_INPUT, nil, ast.Pos{Lineno: 0, ColOffset: 0}},
+			{NAME, py.String("match"), ast.Pos{Lineno: 1, ColOffset: 0}},
+			{'=', nil, ast.Pos{Lineno: 1, ColOffset: 6}},
+			{NAME, py.String("case"), ast.Pos{Lineno: 1, ColOffset: 8}},
+			{NEWLINE, nil, ast.Pos{Lineno: 1, ColOffset: 12}},
+			{ENDMARKER, nil, ast.Pos{Lineno: 2, ColOffset: 0}},
 		}},
 	} {
 		lts, err := LexString(test.in, test.mode)
@@ -425,7 +457,7 @@ func TestLex(t *testing.T) {
 			offset := -1
 			if exc, ok := err.(*py.Exception); ok {
 				lineno = int(exc.Dict["lineno"].(py.Int))
-				offset = int(exc.Dict["offset"].(py.Int))
+				offset = int(exc.Dict["offset"].(py.Int)) - 1
 				errString = fmt.Sprintf("%s %d:%d", exc.Args.(py.Tuple)[0], lineno, offset)
 			} else {
 				panic("bad exception")
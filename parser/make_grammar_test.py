@@ -29,6 +29,29 @@ inp = [
     ('"abc" """123"""', "eval"),
     ("b'abc'", "eval"),
     ("b'abc' b'''123'''", "eval"),
+    ('f"abc"', "eval"),
+    ('f"a{b}c"', "eval"),
+    ('f"{a!r:>{w}.{p}}"', "eval"),
+    ('"x" f"y{z}" "w"', "eval"),
+    ('f"{{}}{x!=y}"', "eval"),
+    ('f"{a!s}{b!a}"', "eval"),
+    ('rf"\\n{x}"', "eval"),
+    ('f"{}"', "eval", SyntaxError, "f-string: empty expression not allowed"),
+    ('f"{x!z}"', "eval", SyntaxError, "f-string: invalid conversion character: expected 's', 'r', or 'a'"),
+    ('f"}"', "eval", SyntaxError, "f-string: single '}' is not allowed"),
+    ('f"{x"', "eval", SyntaxError, "f-string: expecting '}'"),
+    ('f"{x#}"', "eval", SyntaxError, "f-string expression part cannot include '#'"),
+    ('f"{a b}"', "eval", SyntaxError, "f-string: invalid syntax"),
+    ('b"" f""', "eval", SyntaxError, "cannot mix bytes and nonbytes literals"),
+    ('f"{3:{4:{5}}}"', "eval", SyntaxError, "f-string: expressions nested too deeply"),
+    ('f"{x=}"', "eval"),
+    ('f"a{x = }b"', "eval"),
+    ('f"{x=!s}"', "eval"),
+    ('f"{x=:>10}"', "eval"),
+    ('f"{x==y}"', "eval"),
+    ('f"{x<=y}"', "eval"),
+    ('f"{x!=y=}"', "eval"),
+    ('f"{=}"', "eval", SyntaxError, "f-string: empty expression not allowed"),
     ("1234", "eval"),
     ("01234", "eval", SyntaxError, "illegal decimal with leading zero"),
     ("1234d", "eval", SyntaxError, "invalid syntax"),
@@ -58,6 +81,10 @@ inp = [
     ("[1,]", "eval"),
     ("[1,2]", "eval"),
     ("[1,2,]", "eval"),
+    ("[*a,*b]", "eval"),
+    ("(*a,b)", "eval"),
+    ("{*a,b}", "eval"),
+    ("{**a,b:c,**d}", "eval"),
     ("[e for e in (1,2,3)]", "eval"),
 
     # tuple
@@ -109,6 +136,8 @@ inp = [
     ("a*b", "eval"),
     ("a/b", "eval"),
     ("a//b", "eval"),
+    ("a@b", "eval"),
+    ("a*b@c", "eval"),
     ("a**b", "eval"),
 
     # UnaryOp
@@ -159,13 +188,23 @@ inp = [
     ("a(b,c)", "eval"),
     ("a(b,*c)", "eval"),
     ("a(*b)", "eval"),
-    ("a(*b,c)", "eval", SyntaxError),
+    ("a(*b,c)", "eval"),
     ("a(b,*c,**d)", "eval"),
     ("a(b,**c)", "eval"),
+    ("a(*b,*c)", "eval"),
+    ("a(**b,**c)", "eval"),
+    ("a(**b,c=d)", "eval"),
+    ("a(b,*c,d,**e,f=g)", "eval"),
+    ("a(**b,*c)", "eval", SyntaxError, "iterable argument unpacking follows keyword argument unpacking"),
+    ("a(**b,c)", "eval", SyntaxError, "positional argument follows keyword argument unpacking"),
+    ("a(b=c,d)", "eval", SyntaxError, "positional argument follows keyword argument"),
     ("a(a=b)", "eval"),
     ("a(a,a=b,*args,**kwargs)", "eval"),
     ("a(a,a=b,*args,e=f,**kwargs)", "eval"),
     ("a(b for c in d)", "eval"),
+    ("a(b for c in d, e)", "eval", SyntaxError, "Generator expression must be parenthesized"),
+    ("a(b, c for d in e)", "eval", SyntaxError, "Generator expression must be parenthesized"),
+    ("a(b for c in d,)", "eval", SyntaxError, "Generator expression must be parenthesized"),
     ("a.b", "eval"),
     ("a.b.c.d", "eval"),
     ("a.b().c.d()()", "eval"),
@@ -241,6 +280,20 @@ inp = [
     ("while True: pass", "exec"),
     ("while True:\n pass\n", "exec"),
     ("while True:\n pass\nelse:\n return\n", "exec"),
+    # assignment expressions
+    ("(x := 1)", "eval"),
+    ("[y := f(x), y**2]", "eval"),
+    ("f(a := 1, b)", "eval"),
+    ("if (n := len(a)) > 10: pass", "exec"),
+    ("if x := 1: pass\nelif y := 2: pass", "exec"),
+    ("while chunk := read(): pass", "exec"),
+    ("[y for x in data if (y := f(x))]", "eval"),
+    ("(y := x for x in z)", "eval"),
+    ("x := 1", "exec", SyntaxError, "invalid syntax"),
+    ("(a.b := 1)", "eval", SyntaxError, "cannot use named assignment with attribute"),
+    ("(a[1] := 1)", "eval", SyntaxError, "cannot use named assignment with subscript"),
+    ("((a, b) := 1)", "eval", SyntaxError, "cannot use named assignment with tuple"),
+    ("f(x.y := 1)", "eval", SyntaxError, "cannot use named assignment with attribute"),
     ("if True: pass", "exec"),
     ("if True:\n pass\n", "exec"),
     ("if True:\n pass\n\n", "exec"),
@@ -365,6 +418,7 @@ with x as y, a as b, c, d as e:
     ("a **= b", "exec"),
     ("a //= b", "exec"),
     ("a //= yield b", "exec"),
+    ("a @= b", "exec"),
     ("a <> b", "exec", SyntaxError),
     ('''a.b += 1''', "exec"),
 
@@ -393,6 +447,15 @@ with x as y, a as b, c, d as e:
     ('''... = 1''', "exec", SyntaxError),
     ('''(a < b) = 1''', "exec", SyntaxError),
     ('''(a if b else c) = 1''', "exec", SyntaxError),
+    # AnnAssign
+    ("a: int", "exec"),
+    ("a: int = 1", "exec"),
+    ("(a): int", "exec"),
+    ("a.b: int = c", "exec"),
+    ("a[1]: List[int] = yield", "exec"),
+    ("a, b: int", "exec", SyntaxError, "only single target (not tuple) can be annotated"),
+    ("[a]: int", "exec", SyntaxError, "only single target (not list) can be annotated"),
+    ("f(): int", "exec", SyntaxError, "illegal target for annotation"),
 
     # lambda
     ("lambda: a", "eval"),
@@ -410,6 +473,7 @@ with x as y, a as b, c, d as e:
     ("lambda *args, c=d: a", "eval"),
     ("lambda *args, c=d, **kws: a", "eval"),
     ("lambda **kws: a", "eval"),
+    ("lambda a, /: a", "eval"),
 
     # function
     ("def fn(): pass", "exec"),
@@ -426,6 +490,12 @@ with x as y, a as b, c, d as e:
     ("def fn(a, *, c=d): pass", "exec"),
     ("def fn(*args, c=d, **kws): pass", "exec"),
     ("def fn(**kws): pass", "exec"),
+    ("def fn(a, /, b, *, c): pass", "exec"),
+    ("def fn(a=b, /, c=d): pass", "exec"),
+    ("def fn(a, *, b, c=d): pass", "exec"),
+    ("def fn(/): pass", "exec", SyntaxError, "at least one argument must precede /"),
+    ("def fn(a, /, b, /): pass", "exec", SyntaxError, "/ may appear only once"),
+    ("def fn(*, a, /): pass", "exec", SyntaxError, "/ must be ahead of *"),
     ("def fn() -> None: pass", "exec"),
     ("def fn(a:'potato') -> 'sausage': pass", "exec"),
     ("del f()", "exec", SyntaxError),
@@ -470,6 +540,56 @@ def fn():
 class A(B):
     pass
 """, "exec"),
+    ("""\
+@a.b
+@a.b.c(d)
+def fn():
+    pass
+""", "exec"),
+    ("""\
+async def f():
+    await a
+""", "exec"),
+    ("""\
+async def f():
+    async for a in b:
+        pass
+    else:
+        pass
+""", "exec"),
+    ("""\
+async def f():
+    async with a as b, c:
+        pass
+""", "exec"),
+    ("""\
+@dec
+async def f(x) -> int:
+    return await a.b(c) ** 2
+""", "exec"),
+    # match
+    ("match x:\n case 1:\n  pass\n", "exec"),
+    ("match x:\n case -1 | 2.5 | -1+2j | 'a' 'b' | b'c':\n  pass\n", "exec"),
+    ("match x:\n case None:\n  pass\n case True | False:\n  pass\n", "exec"),
+    ("match x:\n case a:\n  pass\n case _:\n  pass\n", "exec"),
+    ("match x:\n case a.b.c:\n  pass\n", "exec"),
+    ("match x, y:\n case a, *b:\n  pass\n case (a, *_):\n  pass\n case [a, (b, c)]:\n  pass\n", "exec"),
+    ("match x:\n case a,:\n  pass\n case ():\n  pass\n case []:\n  pass\n case (a):\n  pass\n", "exec"),
+    ("match x:\n case {}:\n  pass\n case {1: a, 'b': [c], d.e: f, **rest}:\n  pass\n case {**rest}:\n  pass\n", "exec"),
+    ("match x:\n case Point():\n  pass\n case Point(1, y=2):\n  pass\n case a.B(c, d=e,):\n  pass\n", "exec"),
+    ("match x:\n case [1, 2] as y if y:\n  pass\n case (1 | 2) as z:\n  pass\n", "exec"),
+    ("match x:\n case 1: pass\n case 2: pass\n", "exec"),
+    ("match = 1\nmatch.x(match)\ncase = 2\nmatch[1]\n", "exec"),
+    ("match x:\n case 1:\n  match y:\n   case 2:\n    pass\n  case = 3\n", "exec"),
+    ("match *x:\n case 1:\n  pass\n", "exec", SyntaxError, "invalid syntax"),
+    ("match x:\n case *a:\n  pass\n", "exec", SyntaxError, "invalid syntax"),
+    ("match x:\n case a as _:\n  pass\n", "exec", SyntaxError, "cannot use '_' as a target"),
+    ("match x:\n case 1j+2:\n  pass\n", "exec", SyntaxError, "real number required in complex literal"),
+    ("match x:\n case 1+2:\n  pass\n", "exec", SyntaxError, "imaginary number required in complex literal"),
+    ("match x:\n case {a: 1}:\n  pass\n", "exec", SyntaxError, "invalid syntax"),
+    ("match x:\n case C(a=1, b):\n  pass\n", "exec", SyntaxError, "positional patterns follow keyword patterns"),
+    ("match x:\n pass\n", "exec", SyntaxError, "invalid syntax"),
+    ("match x:\n case 1:\n  pass\n\n", "single"),
 
     # single input
     ("", "single", SyntaxError),
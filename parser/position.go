@@ -0,0 +1,264 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Working out where the nodes end in the source
+
+package parser
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/go-python/gpython/ast"
+)
+
+// A position in the source
+type position struct {
+	lineno    int
+	colOffset int
+}
+
+// Returns whether p is before q
+func (p position) before(q position) bool {
+	return p.lineno < q.lineno || (p.lineno == q.lineno && p.colOffset < q.colOffset)
+}
+
+// A token read by the lexer and where it is in the source.  The end
+// is just past its last character.
+type tokenSpan struct {
+	token int
+	start position
+	end   position
+}
+
+// Returns the position of node or nil if it hasn't got one
+func nodePos(node ast.Ast) *ast.Pos {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	pos := v.Elem().FieldByName("Pos")
+	if !pos.IsValid() || !pos.CanSet() {
+		return nil
+	}
+	return pos.Addr().Interface().(*ast.Pos)
+}
+
+// Returns where node ends
+func endOf(node ast.Ast) position {
+	return position{node.GetEndLineno(), node.GetEndColOffset()}
+}
+
+// An endFinder sets the end positions of the nodes in a tree from the
+// tokens it was parsed from
+//
+// The parser only records where the nodes start, so each node ends
+// with the last of its own tokens or those of its children.
+type endFinder struct {
+	spans []tokenSpan
+}
+
+// Sets the end positions of node and the nodes in it from the tokens
+// it was parsed from
+func setEndPositions(node ast.Ast, spans []tokenSpan) {
+	f := endFinder{spans: spans}
+	f.setEnd(node)
+}
+
+// Returns the index of the first token starting at or after p
+func (f *endFinder) after(p position) int {
+	return sort.Search(len(f.spans), func(i int) bool {
+		return !f.spans[i].start.before(p)
+	})
+}
+
+// Returns the index of the token starting at p or -1 if there isn't one
+func (f *endFinder) find(p position) int {
+	i := f.after(p)
+	if i < len(f.spans) && f.spans[i].start == p {
+		return i
+	}
+	return -1
+}
+
+// Returns the index of the token matching the open bracket at i or
+// -1 if there isn't one
+func (f *endFinder) closer(i int) int {
+	if i < 0 {
+		return -1
+	}
+	depth := 0
+	for ; i < len(f.spans); i++ {
+		switch f.spans[i].token {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Returns end moved past the closing brackets of any opened by the
+// tokens from first up to end, such as those around the last
+// child of a node
+func (f *endFinder) closeBrackets(first int, end position) position {
+	depth := 0
+	for i := first; i < len(f.spans); i++ {
+		if depth <= 0 && !f.spans[i].start.before(end) {
+			break
+		}
+		switch f.spans[i].token {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+		if end.before(f.spans[i].end) {
+			end = f.spans[i].end
+		}
+	}
+	return end
+}
+
+// Returns the index of the first token from p on, skipping the ) of
+// a parenthesized expression ending there, if it is token or -1 if it
+// isn't
+func (f *endFinder) next(p position, token int) int {
+	i := f.after(p)
+	for i < len(f.spans) && f.spans[i].token == ')' {
+		i++
+	}
+	if i < len(f.spans) && f.spans[i].token == token {
+		return i
+	}
+	return -1
+}
+
+// Returns the index of the last token before the first of the
+// tokens from i on which aren't in brackets and are in terminators
+func (f *endFinder) last(i int, terminators ...int) int {
+	depth := 0
+	for j := i; j < len(f.spans); j++ {
+		token := f.spans[j].token
+		switch token {
+		case '(', '[', '{':
+			depth++
+			continue
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+		}
+		if depth > 0 {
+			continue
+		}
+		for _, terminator := range terminators {
+			if token == terminator {
+				if j > i {
+					return j - 1
+				}
+				return i
+			}
+		}
+	}
+	return len(f.spans) - 1
+}
+
+// Sets the end of node and the nodes in it, returning where it ends
+func (f *endFinder) setEnd(node ast.Ast) position {
+	pos := nodePos(node)
+	if pos == nil {
+		return position{}
+	}
+	// Start with the end of the first token of the node
+	end := position{pos.Lineno, pos.ColOffset}
+	first := f.find(end)
+	if first >= 0 {
+		end = f.spans[first].end
+	}
+	// Leave the insides of strings alone as any expressions in them
+	// were parsed separately
+	if _, ok := node.(*ast.JoinedStr); !ok {
+		ast.Walk(node, func(child ast.Ast) bool {
+			if child == node {
+				return true
+			}
+			if childEnd := f.setEnd(child); end.before(childEnd) {
+				end = childEnd
+			}
+			return false
+		})
+	}
+	// Then find any tokens at the end of the node which aren't part
+	// of its children
+	i := -1
+	switch node := node.(type) {
+	case *ast.Str, *ast.Bytes, *ast.JoinedStr:
+		// Adjacent strings are concatenated
+		for i = first; i >= 0 && i+1 < len(f.spans); i++ {
+			if token := f.spans[i+1].token; token != STRING && token != FSTRING {
+				break
+			}
+		}
+	case *ast.Call:
+		i = f.closer(f.next(endOf(node.Func), '('))
+	case *ast.Subscript:
+		i = f.closer(f.next(endOf(node.Value), '['))
+	case *ast.Attribute:
+		if i = f.next(endOf(node.Value), '.'); i >= 0 {
+			i++
+		}
+	case *ast.MatchClass:
+		i = f.closer(f.next(endOf(node.Cls), '('))
+	case *ast.MatchAs:
+		if node.Pattern != nil {
+			if i = f.next(endOf(node.Pattern), AS); i >= 0 {
+				i++
+			}
+		}
+	case *ast.MatchStar:
+		if first >= 0 {
+			i = first + 1
+		}
+	case *ast.Slice:
+		if first >= 0 {
+			i = f.last(first, ',', ']')
+		}
+	case *ast.Alias:
+		// dotted_name ["as" NAME]
+		for i = first; i >= 0 && i+2 < len(f.spans); i += 2 {
+			if token := f.spans[i+1].token; token != '.' && token != AS {
+				break
+			}
+		}
+	case *ast.ExprStmt, *ast.Assign, *ast.AugAssign, *ast.AnnAssign, *ast.Return, *ast.Delete, *ast.Pass, *ast.Break, *ast.Continue, *ast.Raise, *ast.Global, *ast.Nonlocal, *ast.Import, *ast.ImportFrom, *ast.Assert:
+		// Simple statements end with the logical line or a ;
+		if first >= 0 {
+			i = f.last(first, NEWLINE, ';', ENDMARKER)
+		}
+	}
+	if i >= 0 && i < len(f.spans) && end.before(f.spans[i].end) {
+		end = f.spans[i].end
+	}
+	if first >= 0 {
+		end = f.closeBrackets(first, end)
+	}
+	pos.EndLineno, pos.EndColOffset = end.lineno, end.colOffset
+	if _, ok := node.(*ast.JoinedStr); ok {
+		// The parts of the string cover all of it as CPython's do,
+		// apart from the expressions in it which have been placed
+		ast.Walk(node, func(child ast.Ast) bool {
+			if childPos := nodePos(child); childPos != nil && childPos.EndLineno == 0 {
+				*childPos = *pos
+			}
+			return true
+		})
+	}
+	return end
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-python/gpython/ast"
+)
+
+// Returns the positions of the nodes in Ast as a sorted list of
+// Type@lineno:col_offset=end_lineno:end_col_offset leaving out the
+// nodes which haven't got positions in python
+func dumpPositions(Ast ast.Ast) string {
+	var out []string
+	ast.Walk(Ast, func(node ast.Ast) bool {
+		switch node.(type) {
+		case *ast.Arguments, *ast.Index:
+			return true
+		}
+		if node.GetLineno() != 0 {
+			out = append(out, fmt.Sprintf("%s@%d:%d=%d:%d", node.Type().Name, node.GetLineno(), node.GetColOffset(), node.GetEndLineno(), node.GetEndColOffset()))
+		}
+		return true
+	})
+	sort.Strings(out)
+	return strings.Join(out, " ")
+}
+
+func TestEndPositions(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"a.b.c(d, e)[f]\n", "Attribute@1:0=1:3 Attribute@1:0=1:5 Call@1:0=1:11 ExprStmt@1:0=1:14 Name@1:0=1:1 Name@1:12=1:13 Name@1:6=1:7 Name@1:9=1:10 Subscript@1:0=1:14"},
+		{"x = not a and b or c\n", "Assign@1:0=1:20 BoolOp@1:4=1:15 BoolOp@1:4=1:20 Name@1:0=1:1 Name@1:14=1:15 Name@1:19=1:20 Name@1:8=1:9 UnaryOp@1:4=1:9"},
+		{"x = -(2 ** 32)\n", "Assign@1:0=1:14 BinOp@1:6=1:13 Name@1:0=1:1 Num@1:11=1:13 Num@1:6=1:7 UnaryOp@1:4=1:14"},
+		{"x = '''a\nb''' 'c'\n", "Assign@1:0=2:8 Name@1:0=1:1 Str@1:4=2:8"},
+		{"x = [\n  1,\n  2,\n]\n", "Assign@1:0=4:1 List@1:4=4:1 Name@1:0=1:1 Num@2:2=2:3 Num@3:2=3:3"},
+		{"if x:\n    pass\nelif y:\n    pass\nelse:\n    z = 1\n", "Assign@6:4=6:9 If@1:0=6:9 If@3:0=6:9 Name@1:3=1:4 Name@3:5=3:6 Name@6:4=6:5 Num@6:8=6:9 Pass@2:4=2:8 Pass@4:4=4:8"},
+		{"@dec\n@dec2(1)\ndef f(): pass\n", "Call@2:1=2:8 FunctionDef@3:0=3:13 Name@1:1=1:4 Name@2:1=2:5 Num@2:6=2:7 Pass@3:9=3:13"},
+		{"import a.b as c, d\n", "Alias@1:17=1:18 Alias@1:7=1:15 Import@1:0=1:18"},
+	} {
+		Ast, err := ParseString(test.in, "exec")
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.in, err)
+			continue
+		}
+		got := dumpPositions(Ast)
+		if got != test.want {
+			t.Errorf("Parse(%q)\nwant> %s\n got> %s", test.in, test.want, got)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import __yyfmt__ "fmt"
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-python/gpython/ast"
 	"github.com/go-python/gpython/py"
@@ -30,18 +31,21 @@ func tupleOrExpr(pos ast.Pos, elts []ast.Expr, optional_comma bool) ast.Expr {
 	}
 }
 
-// Apply trailers (if any) to expr
+// Apply trailers (if any) to expr which starts at pos
 //
 // trailers are half made Call, Subscript or Attribute
-func applyTrailers(expr ast.Expr, trailers []ast.Expr) ast.Expr {
+func applyTrailers(pos ast.Pos, expr ast.Expr, trailers []ast.Expr) ast.Expr {
 	//trailers := $1
 	for _, trailer := range trailers {
 		switch x := trailer.(type) {
 		case *ast.Call:
+			x.Pos = pos
 			x.Func, expr = expr, x
 		case *ast.Subscript:
+			x.Pos = pos
 			x.Value, expr = expr, x
 		case *ast.Attribute:
+			x.Pos = pos
 			x.Value, expr = expr, x
 		default:
 			panic(fmt.Sprintf("Unknown trailer type: %T", expr))
@@ -54,47 +58,94 @@ func applyTrailers(expr ast.Expr, trailers []ast.Expr) ast.Expr {
 func setCtx(yylex yyLexer, expr ast.Expr, ctx ast.ExprContext) {
 	setctxer, ok := expr.(ast.SetCtxer)
 	if !ok {
-		expr_name := ""
-		switch expr.(type) {
-		case *ast.Lambda:
-			expr_name = "lambda"
-		case *ast.Call:
-			expr_name = "function call"
-		case *ast.BoolOp, *ast.BinOp, *ast.UnaryOp:
-			expr_name = "operator"
-		case *ast.GeneratorExp:
-			expr_name = "generator expression"
-		case *ast.Yield, *ast.YieldFrom:
-			expr_name = "yield expression"
-		case *ast.ListComp:
-			expr_name = "list comprehension"
-		case *ast.SetComp:
-			expr_name = "set comprehension"
-		case *ast.DictComp:
-			expr_name = "dict comprehension"
-		case *ast.Dict, *ast.Set, *ast.Num, *ast.Str, *ast.Bytes:
-			expr_name = "literal"
-		case *ast.NameConstant:
-			expr_name = "keyword"
-		case *ast.Ellipsis:
-			expr_name = "Ellipsis"
-		case *ast.Compare:
-			expr_name = "comparison"
-		case *ast.IfExp:
-			expr_name = "conditional expression"
-		default:
-			expr_name = fmt.Sprintf("unexpected %T", expr)
-		}
 		action := "assign to"
 		if ctx == ast.Del {
 			action = "delete"
 		}
-		yylex.(*yyLex).SyntaxErrorf("can't %s %s", action, expr_name)
+		yylex.(*yyLex).SyntaxErrorf("can't %s %s", action, exprName(expr))
 		return
 	}
 	setctxer.SetCtx(ctx)
 }
 
+// Returns the name of the kind of expr for use in error messages
+func exprName(expr ast.Expr) string {
+	switch expr.(type) {
+	case *ast.Attribute:
+		return "attribute"
+	case *ast.Subscript:
+		return "subscript"
+	case *ast.Starred:
+		return "starred"
+	case *ast.Name:
+		return "name"
+	case *ast.List:
+		return "list"
+	case *ast.Tuple:
+		return "tuple"
+	case *ast.Lambda:
+		return "lambda"
+	case *ast.Call:
+		return "function call"
+	case *ast.BoolOp, *ast.BinOp, *ast.UnaryOp:
+		return "operator"
+	case *ast.GeneratorExp:
+		return "generator expression"
+	case *ast.Yield, *ast.YieldFrom:
+		return "yield expression"
+	case *ast.ListComp:
+		return "list comprehension"
+	case *ast.SetComp:
+		return "set comprehension"
+	case *ast.DictComp:
+		return "dict comprehension"
+	case *ast.Dict, *ast.Set, *ast.Num, *ast.Str, *ast.Bytes, *ast.JoinedStr, *ast.FormattedValue:
+		return "literal"
+	case *ast.NameConstant:
+		return "keyword"
+	case *ast.Ellipsis:
+		return "Ellipsis"
+	case *ast.Compare:
+		return "comparison"
+	case *ast.IfExp:
+		return "conditional expression"
+	case *ast.NamedExpr:
+		return "named expression"
+	}
+	return fmt.Sprintf("unexpected %T", expr)
+}
+
+// Makes the assignment expression target := value checking the
+// target is a plain name
+func namedExpr(yylex yyLexer, pos ast.Pos, target ast.Expr, value ast.Expr) ast.Expr {
+	if _, ok := target.(*ast.Name); !ok {
+		yylex.(*yyLex).SyntaxErrorf("cannot use named assignment with %s", exprName(target))
+	}
+	setCtx(yylex, target, ast.Store)
+	return &ast.NamedExpr{ExprBase: ast.ExprBase{Pos: pos}, Target: target, Value: value}
+}
+
+// Makes the annotated assignment target: annotation = value, where
+// value may be nil, checking the target can be annotated
+func annAssign(yylex yyLexer, pos ast.Pos, target, annotation, value ast.Expr) ast.Stmt {
+	switch target.(type) {
+	case *ast.Name, *ast.Attribute, *ast.Subscript:
+		setCtx(yylex, target, ast.Store)
+	case *ast.Tuple:
+		yylex.(*yyLex).SyntaxError("only single target (not tuple) can be annotated")
+	case *ast.List:
+		yylex.(*yyLex).SyntaxError("only single target (not list) can be annotated")
+	default:
+		yylex.(*yyLex).SyntaxError("illegal target for annotation")
+	}
+	// A name is simple unless it is in parentheses
+	simple := 0
+	if name, ok := target.(*ast.Name); ok && name.Pos == pos {
+		simple = 1
+	}
+	return &ast.AnnAssign{StmtBase: ast.StmtBase{Pos: pos}, Target: target, Annotation: annotation, Value: value, Simple: simple}
+}
+
 // Set the context for all the items in exprs
 func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 	for i := range exprs {
@@ -102,7 +153,150 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 	}
 }
 
-//line grammar.y:103
+// Move the arguments before the '/' marker (a nil Arg) in args into
+// Posonlyargs
+func setPosonlyargs(yylex yyLexer, args *ast.Arguments) {
+	for _, arg := range args.Kwonlyargs {
+		if arg == nil {
+			yylex.(*yyLex).SyntaxError("/ must be ahead of *")
+			return
+		}
+	}
+	for i, arg := range args.Args {
+		if arg != nil {
+			continue
+		}
+		if i == 0 {
+			yylex.(*yyLex).SyntaxError("at least one argument must precede /")
+			return
+		}
+		rest := args.Args[i+1:]
+		for _, arg := range rest {
+			if arg == nil {
+				yylex.(*yyLex).SyntaxError("/ may appear only once")
+				return
+			}
+		}
+		args.Posonlyargs = args.Args[:i]
+		args.Args = rest
+		return
+	}
+}
+
+// Add the half made Call arg holding a single argument to call,
+// checking the arguments are in a legal order
+func addArgument(yylex yyLexer, call *ast.Call, arg *ast.Call) {
+	if call.Func != nil || arg.Func != nil {
+		yylex.(*yyLex).SyntaxError("Generator expression must be parenthesized")
+		return
+	}
+	if len(arg.Args) != 0 {
+		_, isStarred := arg.Args[0].(*ast.Starred)
+		for _, kw := range call.Keywords {
+			if kw.Arg == "" {
+				if isStarred {
+					yylex.(*yyLex).SyntaxError("iterable argument unpacking follows keyword argument unpacking")
+				} else {
+					yylex.(*yyLex).SyntaxError("positional argument follows keyword argument unpacking")
+				}
+				return
+			}
+		}
+		if !isStarred && len(call.Keywords) != 0 {
+			yylex.(*yyLex).SyntaxError("positional argument follows keyword argument")
+			return
+		}
+	}
+	call.Args = append(call.Args, arg.Args...)
+	call.Keywords = append(call.Keywords, arg.Keywords...)
+}
+
+// Move a single trailing *args and **kwargs in call into Starargs and
+// Kwargs.  Calls with any other unpackings are left as they are.
+func setStarargs(call *ast.Call) {
+	starred := 0
+	for _, arg := range call.Args {
+		if _, ok := arg.(*ast.Starred); ok {
+			starred++
+		}
+	}
+	kwargs := 0
+	for _, kw := range call.Keywords {
+		if kw.Arg == "" {
+			kwargs++
+		}
+	}
+	nargs, nkeywords := len(call.Args), len(call.Keywords)
+	if starred > 1 || kwargs > 1 {
+		return
+	}
+	if starred == 1 {
+		if _, ok := call.Args[nargs-1].(*ast.Starred); !ok {
+			return
+		}
+	}
+	if kwargs == 1 && call.Keywords[nkeywords-1].Arg != "" {
+		return
+	}
+	if starred == 1 {
+		call.Starargs = call.Args[nargs-1].(*ast.Starred).Value
+		call.Args = call.Args[:nargs-1]
+	}
+	if kwargs == 1 {
+		call.Kwargs = call.Keywords[nkeywords-1].Value
+		call.Keywords = call.Keywords[:nkeywords-1]
+	}
+}
+
+// Make an expression which loads a dotted name, eg "a.b.c"
+func dottedNameExpr(pos ast.Pos, dottedName string) ast.Expr {
+	names := strings.Split(dottedName, ".")
+	var expr ast.Expr = &ast.Name{ExprBase: ast.ExprBase{Pos: pos}, Id: ast.Identifier(names[0]), Ctx: ast.Load}
+	for _, name := range names[1:] {
+		expr = &ast.Attribute{ExprBase: ast.ExprBase{Pos: pos}, Value: expr, Attr: ast.Identifier(name), Ctx: ast.Load}
+	}
+	return expr
+}
+
+// Make the pattern for a list of patterns, which is a sequence
+// pattern unless it is a single pattern without a trailing comma
+func patternsOrPattern(yylex yyLexer, pos ast.Pos, patterns []ast.Pattern, optional_comma bool) ast.Pattern {
+	if optional_comma || len(patterns) != 1 {
+		return &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: pos}, Patterns: patterns}
+	}
+	if _, ok := patterns[0].(*ast.MatchStar); ok {
+		yylex.(*yyLex).SyntaxError("invalid syntax")
+	}
+	return patterns[0]
+}
+
+// Make a value pattern from a literal, checking it is one which can
+// appear in a pattern
+func literalPattern(yylex yyLexer, pos ast.Pos, value ast.Expr) ast.Pattern {
+	switch x := value.(type) {
+	case *ast.NameConstant:
+		return &ast.MatchSingleton{PatternBase: ast.PatternBase{Pos: pos}, Value: x.Value}
+	case *ast.JoinedStr:
+		yylex.(*yyLex).SyntaxError("patterns may only match literals and attribute lookups")
+	}
+	return &ast.MatchValue{PatternBase: ast.PatternBase{Pos: pos}, Value: value}
+}
+
+// Make a complex literal real +/- imag for a pattern
+func complexLiteral(yylex yyLexer, pos ast.Pos, real ast.Expr, op ast.OperatorNumber, imag py.Object) ast.Expr {
+	n := real
+	if unary, ok := n.(*ast.UnaryOp); ok {
+		n = unary.Operand
+	}
+	if _, ok := n.(*ast.Num).N.(py.Complex); ok {
+		yylex.(*yyLex).SyntaxError("real number required in complex literal")
+	} else if _, ok := imag.(py.Complex); !ok {
+		yylex.(*yyLex).SyntaxError("imaginary number required in complex literal")
+	}
+	return &ast.BinOp{ExprBase: ast.ExprBase{Pos: pos}, Left: real, Op: op, Right: &ast.Num{ExprBase: ast.ExprBase{Pos: pos}, N: imag}}
+}
+
+//line grammar.y:297
 type yySymType struct {
 	yys            int
 	pos            ast.Pos // kept up to date by the lexer
@@ -132,6 +326,12 @@ type yySymType struct {
 	arg            *ast.Arg
 	args           []*ast.Arg
 	arguments      *ast.Arguments
+	pattern        ast.Pattern
+	patterns       []ast.Pattern
+	matchmapping   *ast.MatchMapping
+	matchclass     *ast.MatchClass
+	matchcase      *ast.MatchCase
+	matchcases     []*ast.MatchCase
 }
 
 const NEWLINE = 57346
@@ -163,43 +363,50 @@ const GTEQ = 57371
 const GTGT = 57372
 const GTGTEQ = 57373
 const HATEQ = 57374
-const PIPEEQ = 57375
-const FALSE = 57376
-const NONE = 57377
-const TRUE = 57378
-const AND = 57379
-const AS = 57380
-const ASSERT = 57381
-const BREAK = 57382
-const CLASS = 57383
-const CONTINUE = 57384
-const DEF = 57385
-const DEL = 57386
-const ELIF = 57387
-const ELSE = 57388
-const EXCEPT = 57389
-const FINALLY = 57390
-const FOR = 57391
-const FROM = 57392
-const GLOBAL = 57393
-const IF = 57394
-const IMPORT = 57395
-const IN = 57396
-const IS = 57397
-const LAMBDA = 57398
-const NONLOCAL = 57399
-const NOT = 57400
-const OR = 57401
-const PASS = 57402
-const RAISE = 57403
-const RETURN = 57404
-const TRY = 57405
-const WHILE = 57406
-const WITH = 57407
-const YIELD = 57408
-const SINGLE_INPUT = 57409
-const FILE_INPUT = 57410
-const EVAL_INPUT = 57411
+const ATEQ = 57375
+const PIPEEQ = 57376
+const FALSE = 57377
+const NONE = 57378
+const TRUE = 57379
+const AND = 57380
+const AS = 57381
+const ASSERT = 57382
+const ASYNC = 57383
+const AWAIT = 57384
+const BREAK = 57385
+const CLASS = 57386
+const CONTINUE = 57387
+const DEF = 57388
+const DEL = 57389
+const ELIF = 57390
+const ELSE = 57391
+const EXCEPT = 57392
+const FINALLY = 57393
+const FOR = 57394
+const FROM = 57395
+const GLOBAL = 57396
+const IF = 57397
+const IMPORT = 57398
+const IN = 57399
+const IS = 57400
+const LAMBDA = 57401
+const NONLOCAL = 57402
+const NOT = 57403
+const OR = 57404
+const PASS = 57405
+const RAISE = 57406
+const RETURN = 57407
+const TRY = 57408
+const WHILE = 57409
+const WITH = 57410
+const YIELD = 57411
+const MATCH = 57412
+const CASE = 57413
+const SINGLE_INPUT = 57414
+const FILE_INPUT = 57415
+const EVAL_INPUT = 57416
+const FSTRING = 57417
+const COLONEQ = 57418
 
 var yyToknames = [...]string{
 	"$end",
@@ -234,6 +441,7 @@ var yyToknames = [...]string{
 	"GTGT",
 	"GTGTEQ",
 	"HATEQ",
+	"ATEQ",
 	"PIPEEQ",
 	"FALSE",
 	"NONE",
@@ -241,6 +449,8 @@ var yyToknames = [...]string{
 	"AND",
 	"AS",
 	"ASSERT",
+	"ASYNC",
+	"AWAIT",
 	"BREAK",
 	"CLASS",
 	"CONTINUE",
@@ -268,6 +478,8 @@ var yyToknames = [...]string{
 	"WHILE",
 	"WITH",
 	"YIELD",
+	"MATCH",
+	"CASE",
 	"'('",
 	"')'",
 	"'['",
@@ -294,6 +506,8 @@ var yyToknames = [...]string{
 	"SINGLE_INPUT",
 	"FILE_INPUT",
 	"EVAL_INPUT",
+	"FSTRING",
+	"COLONEQ",
 }
 var yyStatenames = [...]string{}
 
@@ -306,423 +520,506 @@ var yyExca = [...]int{
 	-1, 1,
 	1, -1,
 	-2, 0,
-	-1, 232,
-	68, 13,
-	-2, 291,
-	-1, 382,
-	68, 91,
-	-2, 292,
 }
 
 const yyPrivate = 57344
 
-const yyLast = 1441
+const yyLast = 1803
 
 var yyAct = [...]int{
 
-	59, 468, 61, 314, 160, 97, 165, 164, 456, 421,
-	401, 375, 321, 349, 361, 342, 141, 464, 224, 101,
-	102, 6, 259, 111, 335, 223, 334, 103, 210, 69,
-	318, 145, 457, 54, 60, 35, 237, 110, 95, 105,
-	74, 71, 72, 66, 64, 146, 150, 73, 137, 57,
-	106, 75, 231, 97, 143, 107, 70, 180, 24, 97,
-	23, 17, 189, 288, 133, 247, 106, 277, 49, 124,
-	125, 107, 130, 122, 120, 121, 2, 3, 4, 131,
-	123, 284, 128, 96, 204, 139, 243, 232, 129, 127,
-	126, 142, 378, 157, 138, 262, 236, 154, 181, 152,
-	179, 315, 148, 243, 184, 185, 48, 227, 226, 420,
-	243, 167, 211, 215, 387, 195, 166, 186, 187, 97,
-	279, 222, 280, 336, 341, 188, 385, 166, 190, 191,
-	192, 196, 199, 315, 166, 163, 281, 99, 474, 132,
-	466, 312, 163, 453, 450, 390, 398, 395, 382, 373,
-	197, 200, 234, 214, 251, 284, 289, 235, 252, 140,
-	255, 216, 151, 257, 246, 175, 238, 206, 239, 260,
-	261, 419, 241, 240, 221, 486, 473, 291, 258, 460,
-	173, 174, 171, 172, 333, 403, 340, 413, 384, 412,
-	244, 242, 480, 332, 411, 250, 249, 162, 263, 159,
-	409, 253, 254, 311, 162, 405, 400, 379, 176, 178,
-	370, 363, 177, 316, 285, 296, 256, 287, 219, 218,
-	290, 97, 267, 293, 268, 271, 272, 111, 108, 283,
-	269, 270, 286, 322, 169, 170, 266, 292, 273, 274,
-	275, 276, 325, 397, 297, 298, 328, 357, 313, 356,
-	452, 106, 396, 304, 381, 372, 107, 338, 305, 299,
-	355, 300, 353, 343, 303, 339, 282, 232, 230, 337,
-	238, 84, 239, 323, 91, 85, 284, 155, 329, 459,
-	322, 350, 156, 156, 156, 87, 156, 265, 404, 264,
-	358, 220, 359, 284, 248, 245, 459, 284, 461, 90,
-	88, 89, 365, 367, 366, 407, 362, 134, 371, 362,
-	346, 447, 354, 391, 106, 376, 377, 228, 158, 107,
-	182, 211, 307, 67, 207, 302, 183, 374, 315, 344,
-	34, 369, 81, 15, 82, 14, 383, 315, 392, 76,
-	77, 166, 380, 166, 462, 483, 430, 260, 394, 467,
-	83, 389, 402, 78, 136, 386, 114, 336, 315, 116,
-	388, 117, 166, 393, 352, 399, 465, 330, 414, 139,
-	433, 327, 324, 295, 294, 408, 135, 113, 112, 422,
-	423, 326, 217, 322, 98, 425, 426, 211, 427, 410,
-	212, 418, 406, 7, 100, 424, 417, 415, 213, 350,
-	309, 436, 308, 432, 438, 428, 440, 439, 441, 431,
-	229, 435, 434, 437, 310, 429, 161, 109, 301, 360,
-	331, 144, 147, 376, 449, 443, 149, 317, 451, 320,
-	319, 448, 348, 347, 168, 442, 25, 444, 445, 446,
-	454, 119, 193, 203, 104, 458, 205, 455, 306, 364,
-	202, 233, 68, 470, 62, 80, 278, 79, 463, 118,
-	16, 432, 469, 115, 13, 12, 11, 322, 9, 475,
-	10, 44, 43, 42, 478, 41, 481, 479, 484, 476,
-	40, 39, 485, 469, 38, 33, 472, 487, 488, 469,
-	209, 208, 84, 32, 31, 91, 85, 30, 29, 482,
-	28, 27, 26, 368, 8, 93, 87, 94, 5, 92,
-	1, 86, 0, 0, 0, 0, 0, 0, 0, 0,
-	90, 88, 89, 0, 0, 47, 50, 24, 51, 23,
-	36, 0, 0, 0, 0, 20, 56, 45, 18, 55,
-	0, 0, 65, 46, 67, 0, 37, 53, 52, 21,
-	19, 22, 58, 81, 84, 82, 416, 91, 85, 0,
-	76, 77, 63, 0, 0, 0, 0, 0, 87, 0,
-	0, 83, 0, 0, 78, 48, 0, 0, 0, 0,
-	0, 0, 90, 88, 89, 0, 0, 47, 50, 24,
-	51, 23, 36, 0, 0, 0, 0, 20, 56, 45,
-	18, 55, 0, 0, 65, 46, 67, 0, 37, 53,
-	52, 21, 19, 22, 58, 81, 84, 82, 0, 91,
-	85, 0, 76, 77, 63, 0, 0, 0, 0, 0,
-	87, 0, 0, 83, 0, 0, 78, 48, 0, 0,
-	0, 0, 0, 0, 90, 88, 89, 0, 0, 47,
-	50, 24, 51, 23, 36, 0, 0, 0, 0, 20,
-	56, 45, 18, 55, 0, 0, 65, 46, 67, 0,
-	37, 53, 52, 21, 19, 22, 58, 81, 0, 82,
-	0, 0, 0, 0, 76, 77, 63, 225, 0, 84,
-	0, 0, 91, 85, 0, 83, 0, 0, 78, 48,
+	93, 66, 553, 158, 182, 499, 346, 177, 521, 219,
+	503, 504, 505, 181, 462, 498, 497, 469, 412, 441,
+	64, 398, 378, 371, 384, 105, 286, 353, 529, 370,
+	109, 111, 249, 522, 248, 6, 59, 40, 74, 264,
+	217, 110, 110, 65, 234, 120, 351, 112, 162, 114,
+	119, 79, 213, 77, 75, 71, 78, 167, 62, 69,
+	103, 115, 256, 76, 14, 19, 116, 163, 132, 313,
+	230, 243, 198, 154, 2, 3, 4, 617, 105, 160,
+	115, 610, 592, 514, 105, 116, 98, 515, 207, 150,
+	128, 546, 126, 26, 129, 25, 318, 274, 270, 612,
+	589, 54, 415, 307, 423, 308, 80, 174, 239, 110,
+	110, 227, 513, 511, 512, 609, 422, 247, 159, 309,
+	86, 155, 218, 156, 171, 216, 577, 539, 185, 165,
+	222, 538, 220, 220, 235, 257, 91, 169, 539, 98,
+	92, 358, 289, 53, 263, 348, 270, 539, 204, 205,
+	94, 270, 199, 585, 105, 262, 206, 516, 537, 231,
+	372, 208, 197, 549, 550, 97, 95, 96, 544, 184,
+	260, 184, 87, 240, 279, 99, 107, 180, 608, 180,
+	202, 203, 285, 597, 287, 288, 265, 266, 209, 210,
+	211, 278, 228, 129, 261, 348, 588, 282, 184, 252,
+	251, 574, 88, 461, 89, 168, 377, 348, 214, 81,
+	82, 68, 223, 534, 559, 344, 271, 518, 269, 493,
+	90, 347, 315, 83, 276, 290, 369, 317, 99, 184,
+	320, 281, 323, 280, 277, 368, 430, 552, 438, 582,
+	348, 176, 184, 435, 179, 183, 179, 183, 530, 419,
+	476, 312, 293, 295, 298, 299, 316, 296, 297, 327,
+	410, 322, 294, 157, 329, 319, 105, 310, 324, 601,
+	460, 347, 120, 376, 183, 314, 284, 328, 354, 330,
+	273, 268, 343, 347, 345, 115, 267, 336, 246, 361,
+	116, 238, 337, 364, 576, 561, 332, 313, 375, 591,
+	265, 266, 335, 359, 374, 183, 331, 558, 525, 443,
+	379, 300, 301, 302, 303, 304, 347, 454, 183, 305,
+	453, 321, 365, 452, 450, 445, 440, 416, 354, 385,
+	407, 400, 349, 283, 514, 110, 258, 98, 515, 244,
+	394, 514, 396, 242, 98, 515, 411, 117, 437, 593,
+	413, 414, 115, 393, 381, 390, 420, 116, 220, 392,
+	408, 389, 603, 513, 511, 512, 590, 235, 517, 436,
+	513, 511, 512, 418, 429, 409, 424, 425, 391, 406,
+	388, 287, 434, 311, 417, 494, 257, 439, 421, 255,
+	470, 25, 373, 173, 432, 265, 266, 22, 427, 428,
+	506, 540, 507, 433, 444, 173, 291, 442, 516, 500,
+	172, 313, 292, 24, 524, 516, 275, 173, 508, 449,
+	245, 173, 532, 272, 313, 455, 99, 524, 313, 526,
+	447, 151, 471, 99, 446, 451, 463, 464, 235, 459,
+	354, 399, 458, 466, 467, 25, 482, 465, 470, 475,
+	456, 402, 404, 403, 472, 536, 474, 490, 484, 385,
+	478, 479, 480, 477, 481, 175, 483, 413, 492, 110,
+	431, 510, 253, 486, 448, 399, 200, 339, 212, 491,
+	13, 519, 201, 153, 579, 485, 495, 487, 488, 489,
+	39, 28, 140, 141, 15, 146, 138, 136, 137, 520,
+	11, 533, 147, 139, 578, 144, 528, 510, 510, 510,
+	130, 145, 143, 148, 142, 551, 426, 334, 123, 127,
+	547, 548, 125, 541, 543, 607, 554, 348, 184, 575,
+	131, 570, 475, 564, 535, 510, 527, 560, 510, 510,
+	473, 372, 562, 387, 568, 571, 366, 572, 565, 573,
+	563, 156, 363, 110, 360, 152, 134, 580, 326, 325,
+	557, 122, 581, 121, 583, 362, 357, 149, 241, 106,
+	236, 587, 108, 7, 237, 510, 468, 510, 510, 596,
+	567, 545, 598, 599, 554, 600, 594, 595, 502, 510,
+	510, 501, 602, 584, 604, 606, 586, 496, 509, 531,
+	233, 232, 91, 554, 611, 98, 92, 341, 340, 510,
+	510, 613, 254, 510, 614, 615, 94, 342, 616, 178,
+	118, 333, 397, 367, 161, 164, 166, 350, 352, 383,
+	382, 97, 95, 96, 186, 27, 52, 29, 87, 55,
+	26, 56, 25, 41, 135, 226, 104, 113, 22, 61,
+	50, 20, 60, 523, 338, 70, 51, 72, 401, 42,
+	58, 57, 23, 21, 24, 63, 30, 225, 88, 91,
+	89, 457, 98, 92, 259, 81, 82, 68, 73, 555,
+	67, 306, 85, 94, 84, 133, 90, 17, 16, 83,
+	53, 124, 12, 9, 99, 10, 49, 48, 97, 95,
+	96, 47, 46, 52, 29, 87, 55, 26, 56, 25,
+	41, 45, 44, 43, 38, 22, 61, 50, 20, 60,
+	37, 36, 70, 51, 72, 35, 42, 58, 57, 23,
+	21, 24, 63, 30, 34, 88, 91, 89, 33, 98,
+	92, 32, 81, 82, 68, 31, 18, 405, 8, 101,
+	94, 102, 5, 90, 100, 1, 83, 53, 0, 0,
+	0, 99, 0, 0, 0, 97, 95, 96, 0, 0,
+	52, 29, 87, 55, 26, 56, 25, 41, 0, 0,
+	0, 0, 22, 61, 50, 20, 60, 0, 0, 70,
+	51, 72, 0, 42, 58, 57, 23, 21, 24, 63,
+	30, 250, 88, 91, 89, 0, 98, 92, 0, 81,
+	82, 68, 0, 0, 0, 0, 0, 94, 0, 0,
+	90, 0, 0, 83, 53, 0, 0, 0, 99, 0,
+	0, 0, 97, 95, 96, 0, 0, 52, 0, 87,
+	55, 0, 56, 0, 41, 0, 0, 0, 0, 0,
+	61, 50, 0, 60, 0, 0, 70, 51, 72, 0,
+	42, 58, 57, 0, 0, 0, 63, 0, 0, 88,
+	91, 89, 0, 98, 92, 0, 81, 82, 68, 0,
+	0, 0, 0, 0, 94, 0, 0, 90, 0, 0,
+	83, 0, 0, 0, 0, 99, 0, 0, 0, 97,
+	95, 96, 0, 0, 52, 0, 87, 55, 0, 56,
+	0, 41, 0, 0, 0, 0, 0, 61, 50, 0,
+	60, 0, 0, 70, 51, 72, 0, 42, 58, 57,
+	0, 0, 0, 63, 0, 0, 88, 0, 89, 0,
+	0, 0, 0, 81, 82, 68, 0, 91, 0, 0,
+	98, 92, 0, 0, 90, 356, 0, 83, 0, 0,
+	0, 94, 99, 0, 0, 0, 0, 514, 0, 0,
+	98, 515, 0, 0, 0, 0, 97, 95, 96, 0,
+	0, 0, 0, 87, 0, 0, 0, 91, 0, 0,
+	98, 92, 0, 0, 0, 229, 513, 511, 512, 0,
+	70, 94, 72, 0, 0, 0, 0, 514, 0, 0,
+	98, 515, 0, 88, 380, 89, 97, 95, 96, 0,
+	81, 82, 355, 87, 0, 0, 0, 0, 0, 0,
+	0, 90, 0, 506, 83, 507, 513, 511, 512, 99,
+	70, 516, 72, 0, 0, 0, 0, 0, 0, 0,
+	0, 508, 0, 88, 91, 89, 0, 98, 92, 99,
+	81, 82, 68, 0, 0, 0, 0, 0, 94, 0,
+	0, 90, 224, 506, 83, 507, 542, 0, 0, 99,
+	0, 516, 500, 97, 95, 96, 0, 0, 0, 0,
+	87, 508, 0, 0, 91, 0, 0, 98, 92, 99,
+	0, 0, 356, 0, 0, 0, 0, 70, 94, 72,
+	0, 0, 0, 0, 0, 0, 0, 63, 0, 0,
+	88, 215, 89, 97, 95, 96, 0, 81, 82, 68,
+	87, 0, 0, 0, 0, 0, 0, 0, 90, 0,
+	0, 83, 0, 0, 0, 0, 99, 70, 0, 72,
+	0, 0, 0, 0, 569, 0, 0, 98, 515, 0,
+	88, 91, 89, 0, 98, 92, 0, 81, 82, 355,
+	0, 0, 0, 0, 0, 94, 0, 0, 90, 0,
+	0, 83, 0, 513, 511, 512, 99, 0, 0, 0,
+	97, 95, 96, 0, 0, 0, 0, 87, 0, 0,
+	0, 91, 0, 0, 98, 92, 0, 0, 0, 0,
+	0, 0, 0, 0, 70, 94, 72, 0, 0, 0,
+	506, 566, 507, 0, 63, 0, 0, 88, 516, 89,
+	97, 95, 96, 0, 81, 82, 68, 87, 508, 0,
+	0, 0, 0, 0, 0, 90, 99, 0, 83, 0,
+	0, 0, 0, 99, 70, 0, 72, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 88, 91, 89,
+	221, 98, 92, 0, 81, 82, 68, 91, 0, 0,
+	98, 92, 94, 0, 0, 90, 0, 0, 83, 0,
+	0, 94, 0, 99, 0, 0, 0, 97, 95, 96,
+	0, 0, 0, 0, 87, 0, 97, 95, 96, 0,
 	0, 0, 0, 87, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 90, 88, 89,
-	0, 0, 47, 50, 0, 51, 0, 36, 0, 0,
-	0, 0, 0, 56, 45, 0, 55, 0, 0, 65,
-	46, 67, 0, 37, 53, 52, 0, 0, 0, 58,
-	81, 84, 82, 0, 91, 85, 0, 76, 77, 63,
-	0, 0, 0, 0, 0, 87, 0, 0, 83, 0,
-	0, 78, 0, 0, 0, 0, 0, 0, 0, 90,
-	88, 89, 0, 0, 47, 50, 0, 51, 0, 36,
-	0, 0, 0, 0, 0, 56, 45, 0, 55, 0,
-	0, 65, 46, 67, 0, 37, 53, 52, 0, 0,
-	0, 58, 81, 84, 82, 0, 91, 85, 0, 76,
-	77, 63, 0, 0, 0, 0, 0, 87, 0, 0,
-	83, 0, 0, 78, 0, 0, 0, 0, 0, 0,
-	0, 90, 88, 89, 0, 0, 0, 0, 0, 0,
-	84, 0, 0, 91, 85, 0, 0, 0, 0, 0,
-	0, 0, 0, 65, 87, 67, 0, 0, 0, 0,
-	0, 0, 0, 58, 81, 194, 82, 0, 90, 88,
-	89, 76, 77, 63, 0, 0, 0, 84, 0, 0,
-	91, 85, 83, 0, 0, 78, 0, 0, 0, 0,
-	65, 87, 67, 0, 0, 0, 0, 0, 0, 0,
-	58, 81, 0, 82, 0, 90, 88, 89, 76, 77,
-	63, 0, 0, 0, 0, 0, 0, 0, 0, 83,
-	84, 0, 78, 91, 85, 0, 0, 65, 477, 67,
-	0, 0, 0, 0, 87, 0, 0, 0, 81, 0,
-	82, 198, 0, 0, 0, 76, 77, 63, 90, 88,
-	89, 0, 0, 0, 0, 0, 83, 84, 0, 78,
-	91, 85, 0, 0, 0, 0, 0, 0, 0, 0,
-	65, 87, 67, 0, 0, 0, 0, 0, 0, 0,
-	0, 81, 0, 82, 0, 90, 88, 89, 76, 77,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 83,
-	84, 0, 78, 91, 85, 0, 0, 65, 0, 67,
-	0, 0, 0, 0, 87, 0, 0, 0, 81, 0,
-	82, 0, 403, 0, 0, 76, 77, 0, 90, 88,
-	89, 0, 0, 0, 0, 0, 83, 0, 0, 78,
-	0, 0, 84, 0, 0, 91, 85, 0, 0, 0,
-	65, 0, 67, 0, 0, 0, 87, 0, 0, 0,
-	0, 81, 0, 82, 0, 351, 0, 0, 76, 77,
-	90, 88, 89, 0, 0, 0, 0, 0, 0, 83,
-	0, 0, 78, 0, 84, 0, 0, 91, 85, 0,
-	0, 0, 65, 0, 67, 0, 0, 0, 87, 0,
-	0, 0, 0, 81, 345, 82, 0, 0, 0, 0,
-	76, 77, 90, 88, 89, 0, 0, 0, 0, 0,
-	0, 83, 0, 0, 78, 0, 0, 84, 0, 0,
-	91, 85, 0, 0, 65, 0, 67, 0, 0, 0,
-	0, 87, 0, 0, 0, 81, 0, 82, 0, 0,
-	0, 0, 76, 77, 63, 90, 88, 89, 0, 0,
-	0, 0, 0, 83, 84, 0, 78, 91, 85, 0,
-	0, 0, 0, 0, 0, 0, 0, 65, 87, 67,
-	0, 0, 0, 0, 0, 0, 0, 58, 81, 0,
-	82, 0, 90, 88, 89, 76, 77, 0, 0, 0,
-	0, 84, 0, 0, 91, 85, 83, 0, 0, 78,
-	0, 0, 0, 0, 65, 87, 67, 0, 0, 0,
-	0, 0, 0, 0, 0, 81, 0, 82, 0, 90,
-	88, 89, 76, 77, 0, 0, 0, 0, 84, 0,
-	0, 91, 85, 83, 201, 153, 78, 0, 0, 0,
-	0, 65, 87, 67, 0, 0, 0, 0, 0, 0,
-	0, 0, 81, 0, 82, 0, 90, 88, 89, 76,
-	77, 0, 0, 0, 0, 84, 0, 0, 91, 85,
-	83, 0, 0, 78, 0, 0, 0, 0, 471, 87,
-	67, 0, 0, 0, 0, 0, 0, 0, 0, 81,
-	0, 82, 0, 90, 88, 89, 76, 77, 0, 0,
-	0, 0, 84, 0, 0, 91, 85, 83, 0, 0,
-	78, 0, 0, 0, 0, 65, 87, 67, 0, 0,
-	0, 0, 0, 0, 0, 0, 81, 0, 82, 0,
-	90, 88, 89, 76, 77, 0, 0, 0, 84, 0,
-	0, 91, 85, 0, 83, 0, 0, 78, 0, 0,
+	0, 70, 0, 72, 0, 0, 0, 0, 0, 0,
+	70, 0, 72, 0, 88, 0, 89, 0, 443, 0,
+	0, 81, 82, 88, 0, 89, 0, 386, 0, 0,
+	81, 82, 90, 0, 91, 83, 0, 98, 92, 0,
+	99, 90, 395, 0, 83, 0, 0, 0, 94, 99,
+	0, 605, 0, 0, 98, 515, 0, 0, 0, 0,
+	0, 0, 0, 97, 95, 96, 0, 0, 0, 0,
+	87, 0, 0, 0, 91, 0, 0, 98, 92, 0,
+	513, 511, 512, 0, 0, 0, 0, 70, 94, 72,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	88, 0, 89, 97, 95, 96, 0, 81, 82, 0,
+	87, 0, 0, 0, 0, 0, 0, 506, 90, 507,
+	0, 83, 0, 0, 0, 516, 99, 70, 0, 72,
+	0, 0, 0, 0, 0, 508, 0, 0, 0, 0,
+	88, 91, 89, 99, 98, 92, 0, 81, 82, 68,
+	91, 0, 0, 98, 92, 94, 0, 0, 90, 0,
+	0, 83, 0, 0, 94, 0, 99, 0, 0, 0,
+	97, 95, 96, 0, 0, 0, 0, 87, 0, 97,
+	95, 96, 0, 0, 0, 0, 87, 0, 0, 0,
+	0, 0, 0, 0, 70, 0, 72, 170, 0, 0,
+	0, 0, 0, 70, 63, 72, 0, 88, 0, 89,
+	0, 0, 0, 0, 81, 82, 88, 91, 89, 0,
+	98, 92, 0, 81, 82, 90, 91, 0, 83, 98,
+	92, 94, 0, 99, 90, 0, 0, 83, 0, 0,
+	94, 0, 99, 0, 0, 0, 97, 95, 96, 0,
+	0, 0, 0, 87, 0, 97, 95, 96, 0, 0,
 	0, 0, 87, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 81, 0, 82, 90, 88, 89, 0,
-	76, 77, 63, 0, 0, 0, 0, 0, 0, 0,
-	0, 83, 0, 0, 78, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 81,
-	0, 82, 0, 0, 0, 0, 76, 77, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 83, 0, 0,
-	78,
+	556, 0, 72, 0, 0, 0, 0, 0, 0, 70,
+	0, 72, 0, 88, 0, 89, 0, 0, 0, 0,
+	81, 82, 88, 91, 89, 0, 98, 92, 0, 81,
+	82, 90, 91, 0, 83, 98, 92, 94, 0, 99,
+	90, 0, 0, 83, 0, 0, 94, 0, 99, 0,
+	0, 0, 97, 95, 96, 0, 0, 0, 0, 87,
+	0, 97, 95, 96, 0, 0, 0, 0, 87, 0,
+	0, 0, 514, 0, 0, 98, 515, 0, 72, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 88,
+	0, 89, 0, 0, 0, 0, 81, 82, 88, 0,
+	89, 513, 511, 512, 0, 81, 82, 90, 0, 0,
+	83, 0, 0, 0, 91, 99, 90, 98, 92, 83,
+	0, 0, 0, 0, 99, 0, 0, 0, 94, 0,
+	0, 0, 0, 0, 0, 0, 0, 193, 506, 0,
+	507, 0, 0, 97, 95, 96, 516, 500, 0, 0,
+	0, 0, 191, 192, 189, 190, 508, 0, 0, 0,
+	0, 0, 0, 0, 99, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	88, 0, 89, 194, 196, 0, 0, 195, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 90, 0,
+	0, 0, 0, 0, 0, 0, 99, 0, 0, 0,
+	0, 187, 188,
 }
 var yyPact = [...]int{
 
-	-14, -1000, 610, -1000, 1279, -1000, -1000, 380, 64, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 1279, 1279,
-	1316, 157, 1279, 372, 371, 17, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, 57, 1316, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, 370, 370, 1279, 363, 87,
-	-1000, -1000, 1279, 1279, -1000, 363, 79, -1000, 1205, -1000,
-	-1000, 225, -1000, 1352, 281, 128, -1000, 265, 154, 22,
-	-30, 19, 296, 30, 41, -1000, 1352, 1352, 1352, -1000,
-	-1000, 807, 881, 1168, -1000, -1000, 315, -1000, -1000, -1000,
-	-1000, -1000, -1000, 486, -1000, -1000, 81, -1000, -1000, 745,
-	378, 148, 147, 237, 102, -1000, 22, -1000, 683, 36,
-	-1000, 279, 201, 200, -1000, -1000, -1000, -1000, 1131, 14,
+	-21, -1000, 730, -1000, 1540, -1000, -1000, 565, 98, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, 844, -1000, 101, -1000, 101, 100, 20, -1000,
-	1088, -1000, -1000, 245, 92, -1000, 27, 241, 3, 79,
-	-1000, -1000, -1000, 1279, -1000, 265, 265, 22, 265, 1279,
-	145, 91, 337, 337, -1000, 13, -1000, -1000, 1352, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 235, 229, 1352,
-	1352, 1352, 1352, 1352, 1352, 1352, 1352, 1352, 1352, 1352,
-	-1000, -1000, -1000, 53, -1000, 198, 248, 87, -1000, 248,
-	87, -1000, -23, 84, 106, -1000, 81, -1000, -1000, -1000,
-	-1000, -1000, -1000, 369, 1279, -1000, -1000, -1000, 683, 683,
-	1279, 1316, -1000, -1000, -1000, 318, 1279, 683, 1352, 303,
-	127, 142, 1279, -1000, -1000, -1000, 844, -1000, -1000, -1000,
-	366, 1279, 377, 365, -1000, 1279, 363, 361, 117, -1000,
-	3, -1000, 223, 281, -1000, -1000, 1279, 110, -1000, -1000,
-	-1000, -1000, 1279, 22, -1000, -1000, -30, 19, 296, 30,
-	30, 41, 41, -1000, -1000, -1000, -1000, 1352, -1000, 1046,
-	1004, 358, -1000, 194, 1316, 192, 179, 177, -1000, 1279,
-	-1000, 1279, -1000, -1000, -1000, -1000, -1000, -1000, 263, 140,
-	-1000, 256, 610, -1000, -1000, 22, 139, 1279, 187, -1000,
-	77, 322, 322, -1000, 10, 136, 683, 186, -1000, 76,
-	112, -1000, 32, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, 351, 73, -1000, 275, 1279, -1000, -1000,
-	337, 337, 75, -1000, -1000, -1000, 184, 173, 74, -1000,
-	135, 961, -1000, -1000, 234, -1000, -1000, -1000, 134, 248,
-	260, -1000, 129, 683, 123, 118, 116, 1279, 548, -1000,
-	683, -1000, -1000, 95, -1000, -1000, -1000, -1000, 1279, 1279,
-	-1000, -1000, 1279, -1000, 1279, 1279, -1000, 1279, 73, -1000,
-	351, 340, -1000, -1000, -1000, 356, -1000, -1000, 1004, -1000,
-	961, -1000, 114, 1279, 265, 1279, -1000, 1279, -1000, 683,
-	263, 683, 683, 683, 273, -1000, -1000, -1000, -1000, 322,
-	322, 72, -1000, -1000, -1000, -1000, -1000, -1000, 182, -1000,
-	-1000, 71, -1000, 337, -1000, -1000, 114, -1000, -1000, 227,
-	-1000, 108, -1000, -1000, -1000, 250, -1000, 338, -1000, -1000,
-	352, 68, -1000, 335, -1000, -1000, -1000, -1000, -1000, 1242,
-	683, 105, -1000, 66, -1000, 322, 924, 337, 244, 224,
-	-1000, 121, -1000, 683, 331, -1000, -1000, 1279, -1000, -1000,
-	1242, 104, -1000, 322, -1000, -1000, 1242, -1000, -1000,
+	1540, 1540, 130, 271, 1540, 557, 555, 49, -1000, 345,
+	1388, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	480, 130, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	549, 549, 1540, 545, 186, -1000, -1000, 1540, 1540, -1000,
+	545, 117, -1000, 1464, -1000, -1000, 355, -1000, 1616, 427,
+	165, -1000, 1607, 1716, 79, -20, 68, 452, 101, 67,
+	-1000, 1616, 1616, 1616, -1000, 464, -1000, 1698, 1048, 1195,
+	981, -1000, -1000, 61, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, 596, -1000, -1000, 214, -1000, -1000, 864, 564, 267,
+	-28, 263, 363, 211, -1000, 79, -1000, 797, 123, -1000,
+	433, 317, 314, -1000, -1000, -1000, -1000, -1000, 399, -1000,
+	-1000, -1000, 260, 1455, 1540, 57, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 1155,
+	-1000, 209, -1000, 209, 204, 63, -1000, 1388, -1000, -1000,
+	370, 203, -1000, 58, 360, 10, 117, -1000, -1000, -1000,
+	1540, -1000, 1607, 1607, 79, 1607, 1540, 257, 199, 522,
+	522, -1000, 55, -1000, -1000, -1000, 1616, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, 349, 351, 1616, 1616, 1616,
+	1616, 1616, 1616, 1616, 1616, 1616, 1616, 1616, 1616, -1000,
+	-1000, -1000, 1616, 31, -1000, -1000, 310, 376, 198, -1000,
+	-1000, -1000, 376, 198, -1000, 5, 188, 245, 186, 1616,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 554, 1540, -1000,
+	-1000, -1000, 797, 1540, 797, 1540, 130, -1000, -1000, -1000,
+	510, 1540, 797, 1616, 458, 201, 256, 1088, 562, -1000,
+	-1000, -1000, 54, 1155, -1000, -1000, -1000, 548, 1540, 561,
+	546, -1000, 1540, 545, 540, 154, -1000, 10, -1000, 343,
+	427, -1000, -1000, 1540, 192, -1000, -1000, -1000, -1000, 1540,
+	79, -1000, -1000, -20, 68, 452, 101, 101, 67, 67,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 941, 1271, 537,
+	31, -1000, 307, 130, 1388, 305, 284, 278, -1000, 1348,
+	-1000, 1540, -1000, -1000, 79, -1000, -1000, -1000, -1000, -1000,
+	392, 255, -1000, 402, 730, -1000, -1000, 79, 254, 1540,
+	302, -1000, 183, 521, 521, -1000, 15, -1000, 251, 797,
+	300, -1000, 172, -1000, 17, 1540, 1540, 509, 1155, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 535,
+	159, -1000, 431, 1540, -1000, -1000, 522, 522, 166, -1000,
+	-1000, 296, 273, 161, -1000, 250, 1262, -1000, -1000, 347,
+	-1000, -1000, -1000, -1000, 249, 1616, 376, 426, -1000, 248,
+	797, 247, 244, 241, 1540, 663, -1000, 797, -1000, -1000,
+	189, -1000, -1000, -1000, -1000, 1540, 1540, -1000, -1000, 1088,
+	-1000, -1000, 1540, 1540, -1000, -1000, 319, -1000, 159, -1000,
+	535, 534, -1000, -1000, -1000, 236, -1000, -1000, 1271, -1000,
+	1262, -1000, 233, 1540, 1607, 1540, 79, -1000, 1540, -1000,
+	797, 392, 797, 797, 797, 418, -1000, -1000, -1000, -1000,
+	521, 521, 142, -1000, -1000, -1000, -1000, -1000, 377, -1000,
+	1656, 295, -1000, -1000, 140, -1000, 522, -1000, -1000, 233,
+	-1000, -1000, 359, -1000, 232, -1000, -1000, -1000, 378, -1000,
+	530, -1000, -1000, 234, -1000, -1000, 367, 136, -1000, -1000,
+	528, 416, 75, -1000, -1000, 59, 328, 1001, 77, 84,
+	61, -1000, -1000, -1000, -1000, -1000, 505, -1000, 223, -1000,
+	-1000, -1000, -1000, -1000, 1531, 797, 231, -1000, 137, -1000,
+	521, 219, 1540, -1000, 1656, -1000, 527, 961, 1148, 525,
+	-1000, 136, -1000, 136, -1000, 124, 523, 218, 50, 494,
+	474, -1000, 522, 372, 331, -1000, 163, -1000, 797, 139,
+	-1000, 797, -1000, -1000, -1000, -1000, -1000, 119, -1000, 13,
+	-1000, 293, 224, -9, 335, 106, 961, 961, -1000, -1000,
+	-1000, -1000, 1531, 193, -1000, 521, -1000, 289, 1365, 961,
+	-1000, -1000, -1000, 519, 102, 39, -10, -1000, -1000, -1000,
+	-1000, 1531, -1000, -1000, -1000, 12, -1000, 106, 961, 961,
+	-1000, -1000, 961, -14, -1000, -1000, -1000, -1000,
 }
 var yyPgo = [...]int{
 
-	0, 511, 510, 509, 508, 507, 18, 28, 505, 504,
-	503, 25, 14, 390, 61, 502, 501, 500, 498, 497,
-	494, 493, 485, 484, 481, 480, 475, 473, 472, 471,
-	470, 468, 466, 465, 464, 335, 333, 463, 460, 459,
-	39, 29, 34, 56, 41, 42, 47, 40, 51, 457,
-	456, 455, 49, 0, 43, 454, 1, 453, 2, 44,
-	452, 38, 35, 451, 33, 36, 450, 10, 449, 448,
-	330, 27, 446, 445, 8, 444, 68, 83, 443, 442,
-	441, 436, 434, 16, 32, 13, 433, 432, 12, 430,
-	429, 428, 30, 52, 427, 46, 426, 45, 422, 307,
-	31, 24, 421, 26, 420, 419, 418, 37, 417, 7,
-	6, 22, 17, 3, 11, 15, 416, 9, 414, 4,
-	410, 402, 400, 398, 394,
+	0, 0, 755, 754, 752, 751, 32, 44, 749, 748,
+	747, 34, 21, 746, 570, 65, 745, 741, 738, 734,
+	725, 721, 720, 714, 713, 712, 711, 702, 701, 697,
+	696, 695, 693, 500, 692, 480, 64, 494, 691, 688,
+	687, 491, 685, 9, 40, 49, 38, 43, 54, 63,
+	53, 56, 51, 106, 684, 682, 681, 120, 58, 20,
+	55, 680, 2, 679, 1, 59, 678, 60, 37, 674,
+	36, 39, 667, 19, 658, 654, 490, 47, 653, 8,
+	647, 101, 122, 646, 645, 52, 644, 635, 634, 3,
+	33, 24, 630, 629, 27, 628, 46, 62, 627, 57,
+	626, 67, 625, 431, 48, 23, 624, 29, 623, 622,
+	621, 50, 620, 13, 4, 26, 28, 6, 18, 22,
+	619, 14, 617, 7, 612, 608, 607, 599, 12, 11,
+	598, 597, 5, 591, 10, 15, 16, 588, 581, 580,
+	17, 576, 574, 572,
 }
 var yyR1 = [...]int{
 
 	0, 2, 2, 2, 4, 4, 3, 8, 8, 8,
-	5, 123, 123, 94, 94, 93, 93, 70, 81, 81,
-	37, 37, 38, 69, 69, 35, 120, 121, 121, 112,
-	112, 117, 117, 118, 118, 114, 114, 122, 122, 122,
-	122, 122, 122, 122, 113, 113, 109, 109, 115, 115,
-	116, 116, 111, 111, 119, 119, 119, 119, 119, 119,
-	119, 110, 7, 7, 124, 124, 9, 9, 6, 14,
-	14, 14, 14, 14, 14, 14, 14, 15, 15, 15,
-	63, 63, 65, 65, 80, 80, 76, 76, 52, 52,
-	83, 83, 62, 39, 39, 39, 39, 39, 39, 39,
-	39, 39, 39, 39, 39, 16, 17, 18, 18, 18,
-	18, 18, 23, 24, 25, 25, 27, 26, 26, 26,
-	19, 19, 28, 95, 95, 96, 96, 98, 98, 98,
-	104, 104, 104, 29, 101, 101, 100, 100, 103, 103,
-	102, 102, 97, 97, 99, 99, 20, 21, 77, 77,
-	22, 22, 13, 13, 13, 13, 13, 13, 13, 13,
-	105, 105, 12, 12, 31, 30, 32, 106, 106, 33,
-	33, 33, 33, 108, 108, 34, 107, 107, 68, 68,
-	68, 10, 10, 11, 11, 53, 53, 53, 56, 56,
-	55, 55, 57, 57, 58, 58, 59, 59, 54, 54,
-	60, 60, 82, 82, 82, 82, 82, 82, 82, 82,
-	82, 82, 82, 42, 41, 41, 43, 43, 44, 44,
-	45, 45, 45, 46, 46, 46, 47, 47, 47, 47,
-	47, 48, 48, 48, 48, 49, 49, 79, 79, 1,
-	1, 51, 51, 51, 51, 51, 51, 51, 51, 51,
-	51, 51, 51, 51, 51, 51, 51, 50, 50, 50,
-	50, 87, 87, 86, 85, 85, 85, 85, 85, 85,
-	85, 85, 85, 67, 67, 40, 40, 75, 75, 71,
-	61, 72, 78, 78, 66, 66, 66, 66, 36, 89,
-	89, 90, 90, 91, 91, 92, 92, 92, 92, 88,
-	88, 88, 74, 74, 84, 84, 73, 73, 64, 64,
-	64,
+	5, 142, 142, 98, 98, 97, 97, 76, 87, 87,
+	38, 38, 38, 39, 75, 75, 36, 41, 124, 125,
+	125, 116, 116, 116, 121, 121, 122, 122, 118, 118,
+	126, 126, 126, 126, 126, 126, 126, 117, 117, 113,
+	113, 113, 119, 119, 120, 120, 115, 115, 123, 123,
+	123, 123, 123, 123, 123, 114, 7, 7, 143, 143,
+	9, 9, 6, 15, 15, 15, 15, 15, 15, 15,
+	15, 16, 16, 16, 16, 16, 69, 69, 71, 71,
+	86, 86, 81, 81, 58, 58, 82, 82, 44, 44,
+	89, 89, 68, 42, 42, 42, 42, 42, 42, 42,
+	42, 42, 42, 42, 42, 42, 17, 18, 19, 19,
+	19, 19, 19, 24, 25, 26, 26, 28, 27, 27,
+	27, 20, 20, 29, 99, 99, 100, 100, 102, 102,
+	102, 108, 108, 108, 30, 105, 105, 104, 104, 107,
+	107, 106, 106, 101, 101, 103, 103, 21, 22, 83,
+	83, 23, 23, 14, 14, 14, 14, 14, 14, 14,
+	14, 14, 14, 40, 40, 40, 109, 109, 12, 12,
+	32, 31, 33, 110, 110, 34, 34, 34, 34, 112,
+	112, 35, 111, 111, 13, 141, 141, 140, 127, 127,
+	131, 136, 136, 135, 135, 132, 132, 133, 137, 137,
+	134, 134, 134, 134, 134, 134, 134, 134, 134, 134,
+	134, 134, 138, 138, 138, 138, 139, 139, 139, 139,
+	128, 128, 129, 129, 129, 129, 129, 129, 129, 130,
+	130, 74, 74, 74, 10, 10, 11, 11, 43, 43,
+	59, 59, 59, 62, 62, 61, 61, 63, 63, 64,
+	64, 65, 65, 60, 60, 66, 66, 88, 88, 88,
+	88, 88, 88, 88, 88, 88, 88, 88, 47, 46,
+	46, 48, 48, 49, 49, 50, 50, 50, 51, 51,
+	51, 52, 52, 52, 52, 52, 52, 53, 53, 53,
+	53, 54, 54, 55, 55, 85, 85, 1, 1, 1,
+	1, 57, 57, 57, 57, 57, 57, 57, 57, 57,
+	57, 57, 57, 57, 57, 57, 57, 56, 56, 56,
+	56, 93, 93, 92, 91, 91, 91, 91, 91, 91,
+	91, 91, 91, 73, 73, 45, 45, 80, 80, 77,
+	67, 84, 84, 84, 84, 72, 72, 72, 72, 37,
+	95, 95, 96, 94, 94, 94, 94, 94, 94, 79,
+	79, 90, 90, 78, 78, 70, 70, 70,
 }
 var yyR2 = [...]int{
 
 	0, 2, 2, 2, 1, 2, 2, 0, 2, 2,
 	3, 0, 2, 0, 1, 0, 3, 4, 1, 2,
-	1, 1, 2, 0, 2, 6, 3, 0, 1, 1,
-	3, 0, 3, 1, 3, 0, 1, 2, 5, 8,
-	4, 3, 6, 2, 1, 3, 1, 3, 0, 3,
-	1, 3, 0, 1, 2, 5, 8, 4, 3, 6,
-	2, 1, 1, 1, 0, 1, 1, 3, 3, 1,
-	1, 1, 1, 1, 1, 1, 1, 3, 2, 1,
-	1, 1, 1, 1, 2, 3, 1, 3, 1, 1,
+	1, 1, 1, 2, 0, 2, 6, 2, 3, 0,
+	1, 1, 3, 1, 0, 3, 1, 3, 0, 1,
+	2, 5, 8, 4, 3, 6, 2, 1, 3, 1,
+	3, 1, 0, 3, 1, 3, 0, 1, 2, 5,
+	8, 4, 3, 6, 2, 1, 1, 1, 0, 1,
+	1, 3, 3, 1, 1, 1, 1, 1, 1, 1,
+	1, 3, 3, 5, 2, 1, 1, 1, 1, 1,
+	2, 3, 1, 3, 1, 1, 1, 3, 1, 1,
 	0, 1, 2, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 2, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 2, 1, 1, 2, 4,
-	1, 1, 2, 1, 1, 1, 2, 1, 2, 1,
-	1, 4, 2, 4, 1, 3, 1, 3, 1, 3,
-	1, 3, 1, 3, 1, 3, 2, 2, 1, 3,
-	2, 4, 1, 1, 1, 1, 1, 1, 1, 1,
-	0, 5, 0, 3, 6, 5, 7, 0, 4, 4,
-	7, 7, 10, 1, 3, 4, 1, 3, 1, 2,
-	4, 1, 2, 1, 4, 1, 5, 1, 1, 1,
-	3, 4, 3, 4, 1, 3, 1, 3, 2, 1,
-	1, 3, 1, 1, 1, 1, 1, 1, 1, 1,
-	2, 1, 2, 2, 1, 3, 1, 3, 1, 3,
-	1, 3, 3, 1, 3, 3, 1, 3, 3, 3,
-	3, 2, 2, 2, 1, 2, 4, 0, 2, 1,
+	1, 1, 1, 1, 1, 1, 2, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 2, 1, 1, 2,
+	4, 1, 1, 2, 1, 1, 1, 2, 1, 2,
+	1, 1, 4, 2, 4, 1, 3, 1, 3, 1,
+	3, 1, 3, 1, 3, 1, 3, 2, 2, 1,
+	3, 2, 4, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 2, 2, 0, 5, 0, 3,
+	6, 5, 7, 0, 4, 4, 7, 7, 10, 1,
+	3, 4, 1, 3, 7, 1, 2, 5, 0, 2,
+	2, 1, 3, 1, 2, 1, 3, 1, 1, 3,
+	1, 1, 2, 4, 2, 4, 2, 4, 7, 5,
+	3, 5, 3, 3, 5, 5, 1, 3, 3, 5,
+	1, 3, 1, 3, 3, 1, 1, 1, 1, 1,
+	2, 1, 2, 4, 1, 2, 1, 4, 1, 3,
+	1, 5, 1, 1, 1, 3, 4, 3, 4, 1,
+	3, 1, 3, 2, 1, 1, 3, 1, 1, 1,
+	1, 1, 1, 1, 1, 2, 1, 2, 2, 1,
+	3, 1, 3, 1, 3, 1, 3, 3, 1, 3,
+	3, 1, 3, 3, 3, 3, 3, 2, 2, 2,
+	1, 1, 3, 2, 3, 0, 2, 1, 1, 2,
 	2, 2, 3, 4, 4, 2, 4, 4, 2, 3,
 	1, 1, 1, 1, 1, 1, 1, 2, 3, 3,
 	2, 1, 3, 2, 1, 1, 2, 2, 3, 2,
 	3, 3, 4, 1, 2, 1, 1, 1, 3, 2,
-	2, 2, 3, 5, 2, 4, 1, 2, 5, 1,
-	3, 0, 2, 0, 3, 2, 4, 7, 3, 1,
-	2, 3, 1, 1, 4, 5, 2, 3, 1, 3,
-	2,
+	2, 3, 2, 5, 4, 2, 4, 2, 2, 5,
+	1, 3, 2, 1, 2, 3, 3, 2, 2, 1,
+	1, 4, 5, 2, 3, 1, 3, 2,
 }
 var yyChk = [...]int{
 
-	-1000, -2, 90, 91, 92, -4, -6, -13, -9, -31,
-	-30, -32, -33, -34, -35, -36, -38, -14, 52, 64,
-	49, 63, 65, 43, 41, -81, -15, -16, -17, -18,
-	-19, -20, -21, -22, -70, -62, 44, 60, -23, -24,
-	-25, -26, -27, -28, -29, 51, 57, 39, 89, -76,
-	40, 42, 62, 61, -64, 53, 50, -52, 66, -53,
-	-42, -58, -55, 76, -59, 56, -54, 58, -60, -41,
-	-43, -44, -45, -46, -47, -48, 74, 75, 88, -49,
-	-51, 67, 69, 85, 6, 10, -1, 20, 35, 36,
-	34, 9, -3, -8, -5, -61, -77, -53, 4, 73,
-	-124, -53, -53, -71, -75, -40, -41, -42, 71, -108,
-	-107, -53, 6, 6, -70, -37, -36, -35, -39, -80,
-	17, 18, 16, 23, 12, 13, 33, 32, 25, 31,
-	15, 22, 82, -71, -99, 6, -99, -53, -97, 6,
-	72, -83, -61, -53, -102, -100, -97, -98, -97, -96,
-	-95, 83, 20, 50, -61, 52, 59, -41, 37, 71,
-	-119, -116, 76, 14, -109, -110, 6, -54, -82, 80,
-	81, 28, 29, 26, 27, 11, 54, 58, 55, 78,
-	87, 79, 24, 30, 74, 75, 76, 77, 84, 21,
-	-48, -48, -48, -79, 68, -64, -52, -76, 70, -52,
-	-76, 86, -66, -78, -53, -72, -77, 9, 5, 4,
-	-7, -6, -13, -123, 72, -83, -14, 4, 71, 71,
-	54, 72, -83, -11, -6, 4, 72, 71, 38, -120,
-	67, -93, 67, -63, -64, -61, 82, -65, -64, -62,
-	72, 72, -93, 83, -52, 50, 72, 38, 53, -95,
-	-97, -53, -58, -59, -54, -53, 71, 72, -83, -111,
-	-110, -110, 82, -41, 54, 58, -43, -44, -45, -46,
-	-46, -47, -47, -48, -48, -48, -48, 14, -50, 67,
-	69, 83, 68, -84, 49, -83, -84, -83, 86, 72,
-	-83, 71, -84, -83, 5, 4, -53, -11, -11, -61,
-	-40, -106, 7, -107, -11, -41, -69, 19, -121, -122,
-	-118, 76, 14, -112, -113, 6, 71, -94, -92, -89,
-	-90, -88, -53, -65, 6, -53, 4, 6, -53, -100,
-	6, -104, 76, 67, -103, -101, 6, 46, -53, -109,
-	76, 14, -115, -53, -48, 68, -92, -86, -87, -85,
-	-53, 71, 6, 68, -71, 68, 70, 70, -53, -53,
-	-105, -12, 46, 71, -68, 46, 48, 47, -10, -7,
-	71, -53, 68, 72, -83, -114, -113, -113, 82, 71,
-	-11, 68, 72, -83, 76, 14, -84, 82, -103, -83,
-	72, 38, -53, -111, -110, 72, 68, 70, 72, -83,
-	71, -67, -53, 71, 54, 71, -84, 45, -12, 71,
-	-11, 71, 71, 71, -53, -7, 8, -11, -112, 76,
-	14, -117, -53, -53, -88, -53, -53, -53, -83, -101,
-	6, -115, -109, 14, -85, -67, -53, -67, -53, -58,
-	-53, -53, -11, -12, -11, -11, -11, 38, -114, -113,
-	72, -91, 68, 72, -110, -67, -74, -84, -73, 52,
-	71, 48, 6, -117, -112, 14, 72, 14, -56, -58,
-	-57, 56, -11, 71, 72, -113, -88, 14, -110, -74,
-	71, -119, -11, 14, -53, -56, 71, -113, -56,
+	-1000, -2, 95, 96, 97, -4, -6, -14, -9, -32,
+	-31, -33, -34, -35, -36, -37, -39, -40, -13, -15,
+	55, 67, 52, 66, 68, 46, 44, -87, -41, 41,
+	70, -16, -17, -18, -19, -20, -21, -22, -23, -76,
+	-68, 47, 63, -24, -25, -26, -27, -28, -29, -30,
+	54, 60, 40, 94, -81, 43, 45, 65, 64, -70,
+	56, 53, -58, 69, -59, -47, -64, -61, 81, -65,
+	59, -60, 61, -66, -46, -48, -49, -50, -51, -52,
+	-53, 79, 80, 93, -54, -55, -57, 42, 72, 74,
+	90, 6, 10, -1, 20, 36, 37, 35, 9, 98,
+	-3, -8, -5, -67, -83, -59, 4, 78, -143, -43,
+	-59, -43, -77, -80, -45, -46, -47, 76, -112, -111,
+	-59, 6, 6, -76, -38, -37, -36, -41, 41, -36,
+	-35, -33, -68, -42, 76, -86, 17, 18, 16, 23,
+	12, 13, 34, 32, 25, 31, 15, 22, 33, 87,
+	-77, -103, 6, -103, -59, -101, 6, 77, -89, -67,
+	-59, -106, -104, -101, -102, -101, -100, -99, 88, 20,
+	53, -67, 55, 62, -46, 38, 76, -123, -120, 81,
+	14, -113, -114, 82, 6, -60, -88, 85, 86, 28,
+	29, 26, 27, 11, 57, 61, 58, 83, 92, 84,
+	24, 30, 79, 80, 81, 82, 89, 21, 94, -53,
+	-53, -53, 14, -85, -57, 73, -70, -44, -82, -43,
+	-47, 75, -44, -82, 91, -72, -84, -59, -81, 14,
+	9, 98, 5, 4, -7, -6, -14, -142, 77, -89,
+	-15, 4, 76, 99, 76, 57, 77, -89, -11, -6,
+	4, 77, 76, 39, -124, 72, -97, 72, 76, -69,
+	-70, -67, -59, 87, -71, -70, -68, 77, 77, -97,
+	88, -58, 53, 77, 39, 56, -99, -101, -59, -64,
+	-65, -60, -59, 76, 77, -89, -115, -114, -114, 87,
+	-46, 57, 61, -48, -49, -50, -51, -51, -52, -52,
+	-53, -53, -53, -53, -53, -53, -56, 72, 74, 88,
+	-85, 73, -90, 52, 77, -89, -90, -89, 91, 77,
+	-89, 76, -90, -89, -46, 5, 4, -59, -11, -59,
+	-11, -67, -45, -110, 7, -111, -11, -46, -75, 19,
+	-125, -126, -122, 81, 14, -116, -117, 82, 6, 76,
+	-98, -96, -95, -94, -59, 81, 14, 4, 87, -71,
+	6, -59, 4, 6, -59, -104, 6, -108, 81, 72,
+	-107, -105, 6, 49, -59, -113, 81, 14, -119, -59,
+	73, -96, -92, -93, -91, -59, 76, 6, 73, -77,
+	-44, 73, 75, 75, -59, 14, -59, -109, -12, 49,
+	76, -74, 49, 51, 50, -10, -7, 76, -59, 73,
+	77, -89, -118, -117, -117, 87, 76, -11, 73, 77,
+	-89, -90, 99, 87, -59, -59, 7, -71, -107, -89,
+	77, 39, -59, -115, -114, 77, 73, 75, 77, -89,
+	76, -73, -59, 76, 57, 76, -46, -90, 48, -12,
+	76, -11, 76, 76, 76, -59, -7, 8, -11, -116,
+	81, 14, -121, -59, -59, -94, -59, -59, -141, -140,
+	71, -89, -105, 6, -119, -113, 14, -91, -73, -59,
+	-73, -59, -64, -59, -43, -11, -12, -11, -11, -11,
+	39, -118, -117, 77, 8, -140, -131, -136, -135, -132,
+	81, -133, -137, -134, -129, -128, 72, 74, 90, -130,
+	-1, 36, 37, 35, 6, 10, 80, 73, 77, -114,
+	-73, -79, -90, -78, 55, 76, 51, 6, -121, -116,
+	14, -127, 55, -89, 77, 6, 39, 83, 72, 88,
+	73, -136, 75, -136, 91, -138, 14, -129, -128, 79,
+	80, 10, 14, -62, -64, -63, 59, -11, 76, 77,
+	-117, 76, -43, -135, 6, -134, 73, -139, -132, 6,
+	6, -89, -89, -89, 77, 6, 76, 76, 10, 10,
+	-114, -79, 76, -123, -11, 14, -11, -89, 77, 87,
+	73, 75, 91, 14, -129, -128, -89, 77, -132, -132,
+	-62, 76, -117, 73, -132, 6, -132, 6, 76, 76,
+	91, -62, 87, -89, -132, -132, -132, 91,
 }
 var yyDef = [...]int{
 
-	0, -2, 0, 7, 0, 1, 4, 0, 64, 152,
-	153, 154, 155, 156, 157, 158, 159, 66, 0, 0,
-	0, 0, 0, 0, 0, 0, 69, 70, 71, 72,
-	73, 74, 75, 76, 18, 79, 0, 106, 107, 108,
-	109, 110, 111, 120, 121, 0, 0, 0, 0, 90,
-	112, 113, 114, 117, 116, 0, 0, 86, 308, 88,
-	89, 185, 187, 0, 194, 0, 196, 0, 199, 200,
-	214, 216, 218, 220, 223, 226, 0, 0, 0, 234,
-	237, 0, 0, 0, 250, 251, 252, 253, 254, 255,
-	256, 239, 2, 0, 3, 11, 90, 148, 5, 65,
-	0, 0, 0, 0, 90, 277, 275, 276, 0, 0,
-	173, 176, 0, 15, 19, 22, 20, 21, 0, 78,
-	93, 94, 95, 96, 97, 98, 99, 100, 101, 102,
-	103, 104, 0, 105, 146, 144, 147, 150, 15, 142,
-	91, 92, 115, 118, 122, 140, 136, 0, 127, 129,
-	125, 123, 124, 0, 310, 0, 0, 213, 0, 0,
-	0, 90, 52, 0, 50, 46, 61, 198, 0, 202,
-	203, 204, 205, 206, 207, 208, 209, 0, 211, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	231, 232, 233, 235, 241, 0, 86, 90, 245, 86,
-	90, 248, 0, 90, 148, 286, 90, 240, 6, 8,
-	9, 62, 63, 0, 91, 280, 67, 68, 0, 0,
-	0, 91, 279, 167, 183, 0, 0, 0, 0, 23,
-	27, 0, -2, 77, 80, 81, 0, 84, 82, 83,
-	0, 0, 0, 0, 87, 0, 0, 0, 0, 126,
-	128, 309, 0, 195, 197, 190, 0, 91, 54, 48,
-	53, 60, 0, 201, 210, 212, 215, 217, 219, 221,
-	222, 224, 225, 227, 228, 229, 230, 0, 238, 291,
-	0, 0, 242, 0, 0, 0, 0, 0, 249, 91,
-	284, 0, 287, 281, 10, 12, 149, 160, 162, 0,
-	278, 169, 0, 174, 175, 177, 0, 0, 0, 28,
-	90, 35, 0, 33, 29, 44, 0, 0, 14, 90,
-	0, 289, 299, 85, 145, 151, 17, 143, 119, 141,
-	137, 133, 130, 0, 90, 138, 134, 0, 191, 51,
-	52, 0, 58, 47, 236, 257, 0, 0, 90, 261,
-	264, 265, 260, 243, 0, 244, 246, 247, 0, 282,
-	162, 165, 0, 0, 0, 0, 0, 178, 0, 181,
-	0, 24, 26, 91, 37, 31, 36, 43, 0, 0,
-	288, 16, -2, 295, 0, 0, 300, 0, 90, 132,
-	91, 0, 186, 48, 57, 0, 258, 259, 91, 263,
-	269, 266, 267, 273, 0, 0, 285, 0, 164, 0,
-	162, 0, 0, 0, 179, 182, 184, 25, 34, 35,
-	0, 41, 30, 45, 290, 293, 298, 301, 0, 139,
-	135, 55, 49, 0, 262, 270, 271, 268, 274, 304,
-	283, 0, 163, 166, 168, 170, 171, 0, 31, 40,
-	0, 296, 131, 0, 59, 272, 305, 302, 303, 0,
-	0, 0, 180, 38, 32, 0, 0, 0, 306, 188,
-	189, 0, 161, 0, 0, 42, 294, 0, 56, 307,
-	0, 0, 172, 0, 297, 192, 0, 39, 193,
+	0, -2, 0, 7, 0, 1, 4, 0, 68, 163,
+	164, 165, 166, 167, 168, 169, 170, 171, 172, 70,
+	0, 0, 0, 0, 0, 0, 0, 0, 173, 0,
+	0, 73, 74, 75, 76, 77, 78, 79, 80, 18,
+	85, 0, 117, 118, 119, 120, 121, 122, 131, 132,
+	0, 0, 0, 0, 100, 123, 124, 125, 128, 127,
+	0, 0, 92, 375, 94, 95, 250, 252, 0, 259,
+	0, 261, 0, 264, 265, 279, 281, 283, 285, 288,
+	291, 0, 0, 0, 300, 301, 305, 0, 0, 0,
+	0, 320, 321, 322, 323, 324, 325, 326, 307, 308,
+	2, 0, 3, 11, 100, 159, 5, 69, 0, 0,
+	248, 0, 0, 100, 347, 345, 346, 0, 0, 189,
+	192, 0, 15, 19, 23, 20, 21, 22, 0, 27,
+	174, 175, 0, 0, 0, 84, 103, 104, 105, 106,
+	107, 108, 109, 110, 111, 112, 113, 114, 115, 0,
+	116, 157, 155, 158, 161, 15, 153, 101, 102, 126,
+	129, 133, 151, 147, 0, 138, 140, 136, 134, 135,
+	0, 377, 0, 0, 278, 0, 0, 0, 100, 56,
+	0, 54, 49, 51, 65, 263, 0, 267, 268, 269,
+	270, 271, 272, 273, 274, 0, 276, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 297,
+	298, 299, 0, 303, 305, 311, 0, 96, 100, 98,
+	99, 315, 96, 100, 318, 0, 100, 94, 100, 0,
+	309, 310, 6, 8, 9, 66, 67, 0, 101, 350,
+	71, 72, 0, 0, 0, 0, 101, 349, 183, 246,
+	0, 0, 0, 0, 24, 29, 0, 13, 0, 81,
+	86, 87, 82, 0, 90, 88, 89, 0, 0, 0,
+	0, 93, 0, 0, 0, 0, 137, 139, 376, 0,
+	260, 262, 255, 0, 101, 58, 52, 57, 64, 0,
+	266, 275, 277, 280, 282, 284, 286, 287, 289, 290,
+	292, 293, 294, 295, 296, 302, 306, 0, 0, 0,
+	304, 312, 0, 0, 101, 0, 0, 0, 319, 101,
+	355, 0, 358, 357, 352, 10, 12, 160, 176, 249,
+	178, 0, 348, 185, 0, 190, 191, 193, 0, 0,
+	0, 30, 100, 38, 0, 36, 31, 33, 47, 0,
+	0, 14, 100, 360, 363, 0, 0, 0, 0, 91,
+	156, 162, 17, 154, 130, 152, 148, 144, 141, 0,
+	100, 149, 145, 0, 256, 55, 56, 0, 62, 50,
+	327, 0, 0, 100, 331, 334, 335, 330, 313, 0,
+	97, 314, 316, 317, 0, 0, 351, 178, 181, 0,
+	0, 0, 0, 0, 241, 0, 244, 0, 25, 28,
+	101, 40, 34, 39, 46, 0, 0, 359, 16, 101,
+	362, 364, 0, 0, 367, 368, 0, 83, 100, 143,
+	101, 0, 251, 52, 61, 0, 328, 329, 101, 333,
+	339, 336, 337, 343, 0, 0, 354, 356, 0, 180,
+	0, 178, 0, 0, 0, 242, 245, 247, 26, 37,
+	38, 0, 44, 32, 48, 361, 365, 366, 0, 195,
+	0, 0, 150, 146, 59, 53, 0, 332, 340, 341,
+	338, 344, 371, 353, 0, 179, 182, 184, 186, 187,
+	0, 34, 43, 0, 194, 196, 198, 100, 201, 203,
+	0, 205, 207, 208, 210, 211, 0, 0, 0, 232,
+	235, 236, 237, 238, 230, 239, 0, 142, 0, 63,
+	342, 372, 369, 370, 0, 0, 0, 243, 41, 35,
+	0, 0, 0, 200, 101, 204, 0, 0, 0, 0,
+	212, 100, 214, 100, 216, 100, 0, 0, 0, 0,
+	0, 240, 0, 373, 253, 254, 0, 177, 0, 0,
+	45, 0, 199, 202, 206, 209, 220, 100, 226, 230,
+	231, 0, 0, 0, 101, 100, 0, 0, 233, 234,
+	60, 374, 0, 0, 188, 0, 197, 0, 101, 0,
+	213, 215, 217, 0, 0, 0, 0, 101, 222, 223,
+	257, 0, 42, 221, 228, 230, 227, 100, 0, 0,
+	219, 258, 0, 0, 224, 225, 229, 218,
 }
 var yyTok1 = [...]int{
 
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 84, 79, 3,
-	67, 68, 76, 74, 72, 75, 83, 77, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 71, 73,
-	80, 82, 81, 3, 89, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 89, 84, 3,
+	72, 73, 81, 79, 77, 80, 88, 82, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 76, 78,
+	85, 87, 86, 3, 94, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 69, 3, 70, 87, 3, 3, 3, 3, 3,
+	3, 74, 3, 75, 92, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 85, 78, 86, 88,
+	3, 3, 3, 90, 83, 91, 93,
 }
 var yyTok2 = [...]int{
 
@@ -732,7 +1029,8 @@ var yyTok2 = [...]int{
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
 	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
 	52, 53, 54, 55, 56, 57, 58, 59, 60, 61,
-	62, 63, 64, 65, 66, 90, 91, 92,
+	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
+	95, 96, 97, 98, 99,
 }
 var yyTok3 = [...]int{
 	0,
@@ -1077,132 +1375,139 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:250
+		//line grammar.y:467
 		{
 			yylex.(*yyLex).mod = yyDollar[2].mod
 			return 0
 		}
 	case 2:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:255
+		//line grammar.y:472
 		{
 			yylex.(*yyLex).mod = yyDollar[2].mod
 			return 0
 		}
 	case 3:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:260
+		//line grammar.y:477
 		{
 			yylex.(*yyLex).mod = yyDollar[2].mod
 			return 0
 		}
 	case 4:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:274
+		//line grammar.y:491
 		{
 			yyVAL.mod = &ast.Interactive{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: yyDollar[1].stmts}
 		}
 	case 5:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:278
+		//line grammar.y:495
 		{
 			//  NB: compound_stmt in single_input is followed by extra NEWLINE!
 			yyVAL.mod = &ast.Interactive{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: []ast.Stmt{yyDollar[1].stmt}}
 		}
 	case 6:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:286
+		//line grammar.y:503
 		{
 			yyVAL.mod = &ast.Module{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: yyDollar[1].stmts}
 		}
 	case 7:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:292
+		//line grammar.y:509
 		{
 			yyVAL.stmts = nil
 		}
 	case 8:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:296
+		//line grammar.y:513
 		{
 		}
 	case 9:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:299
+		//line grammar.y:516
 		{
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[2].stmts...)
 		}
 	case 10:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:306
+		//line grammar.y:523
 		{
 			yyVAL.mod = &ast.Expression{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: yyDollar[1].expr}
 		}
 	case 13:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:315
+		//line grammar.y:532
 		{
 			yyVAL.call = &ast.Call{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
 	case 14:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:319
+		//line grammar.y:536
 		{
 			yyVAL.call = yyDollar[1].call
 		}
 	case 15:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:324
+		//line grammar.y:541
 		{
 			yyVAL.call = nil
 		}
 	case 16:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:328
+		//line grammar.y:545
 		{
 			yyVAL.call = yyDollar[2].call
 		}
 	case 17:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:334
+		//line grammar.y:551
 		{
-			fn := &ast.Name{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Id: ast.Identifier(yyDollar[2].str), Ctx: ast.Load}
+			fn := dottedNameExpr(yyDollar[2].pos, yyDollar[2].str)
 			if yyDollar[3].call == nil {
 				yyVAL.expr = fn
 			} else {
 				call := *yyDollar[3].call
+				call.Pos = yyDollar[2].pos
 				call.Func = fn
 				yyVAL.expr = &call
 			}
 		}
 	case 18:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:347
+		//line grammar.y:565
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
 	case 19:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:352
+		//line grammar.y:570
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[2].expr)
 		}
 	case 20:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:358
+		//line grammar.y:576
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 21:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:362
+		//line grammar.y:580
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 22:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:584
+		{
+			yyVAL.stmt = yyDollar[1].stmt
+		}
+	case 23:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:368
+		//line grammar.y:590
 		{
 			switch x := (yyDollar[2].stmt).(type) {
 			case *ast.ClassDef:
@@ -1211,79 +1516,95 @@ yydefault:
 			case *ast.FunctionDef:
 				x.DecoratorList = yyDollar[1].exprs
 				yyVAL.stmt = x
+			case *ast.AsyncFunctionDef:
+				x.DecoratorList = yyDollar[1].exprs
+				yyVAL.stmt = x
 			default:
 				panic("bad type for decorated")
 			}
 		}
-	case 23:
+	case 24:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:382
+		//line grammar.y:607
 		{
 			yyVAL.expr = nil
 		}
-	case 24:
+	case 25:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:386
+		//line grammar.y:611
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 25:
+	case 26:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:392
+		//line grammar.y:617
 		{
 			yyVAL.stmt = &ast.FunctionDef{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Name: ast.Identifier(yyDollar[2].str), Args: yyDollar[3].arguments, Body: yyDollar[6].stmts, Returns: yyDollar[4].expr}
 		}
-	case 26:
+	case 27:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:623
+		{
+			fn := yyDollar[2].stmt.(*ast.FunctionDef)
+			yyVAL.stmt = &ast.AsyncFunctionDef{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Name: fn.Name, Args: fn.Args, Body: fn.Body, Returns: fn.Returns}
+		}
+	case 28:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:398
+		//line grammar.y:630
 		{
 			yyVAL.arguments = yyDollar[2].arguments
 		}
-	case 27:
+	case 29:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:403
+		//line grammar.y:635
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos}
 		}
-	case 28:
+	case 30:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:407
+		//line grammar.y:639
 		{
 			yyVAL.arguments = yyDollar[1].arguments
+			setPosonlyargs(yylex, yyVAL.arguments)
 		}
-	case 29:
+	case 31:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:414
+		//line grammar.y:647
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = nil
 		}
-	case 30:
+	case 32:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:419
+		//line grammar.y:652
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = yyDollar[3].expr
 		}
-	case 31:
+	case 33:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:657
+		{
+			yyVAL.arg = nil
+			yyVAL.expr = nil
+		}
+	case 34:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:425
+		//line grammar.y:663
 		{
 			yyVAL.args = nil
 			yyVAL.exprs = nil
 		}
-	case 32:
+	case 35:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:430
+		//line grammar.y:668
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
-			if yyDollar[3].expr != nil {
-				yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
-			}
+			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
-	case 33:
+	case 36:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:439
+		//line grammar.y:675
 		{
 			yyVAL.args = nil
 			yyVAL.args = append(yyVAL.args, yyDollar[1].arg)
@@ -1292,114 +1613,119 @@ yydefault:
 				yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 			}
 		}
-	case 34:
+	case 37:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:448
+		//line grammar.y:684
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
 			if yyDollar[3].expr != nil {
 				yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 			}
 		}
-	case 35:
+	case 38:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:456
+		//line grammar.y:692
 		{
 			yyVAL.arg = nil
 		}
-	case 36:
+	case 39:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:460
+		//line grammar.y:696
 		{
 			yyVAL.arg = yyDollar[1].arg
 		}
-	case 37:
+	case 40:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:467
+		//line grammar.y:703
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs}
 		}
-	case 38:
+	case 41:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:471
+		//line grammar.y:707
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs}
 		}
-	case 39:
+	case 42:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line grammar.y:475
+		//line grammar.y:711
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs, Kwarg: yyDollar[8].arg}
 		}
-	case 40:
+	case 43:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:479
+		//line grammar.y:715
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Kwarg: yyDollar[4].arg}
 		}
-	case 41:
+	case 44:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:483
+		//line grammar.y:719
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs}
 		}
-	case 42:
+	case 45:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:487
+		//line grammar.y:723
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs, Kwarg: yyDollar[6].arg}
 		}
-	case 43:
+	case 46:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:491
+		//line grammar.y:727
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Kwarg: yyDollar[2].arg}
 		}
-	case 44:
+	case 47:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:497
+		//line grammar.y:733
 		{
 			yyVAL.arg = &ast.Arg{Pos: yyVAL.pos, Arg: ast.Identifier(yyDollar[1].str)}
 		}
-	case 45:
+	case 48:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:501
+		//line grammar.y:737
 		{
 			yyVAL.arg = &ast.Arg{Pos: yyVAL.pos, Arg: ast.Identifier(yyDollar[1].str), Annotation: yyDollar[3].expr}
 		}
-	case 46:
+	case 49:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:507
+		//line grammar.y:743
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = nil
 		}
-	case 47:
+	case 50:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:512
+		//line grammar.y:748
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = yyDollar[3].expr
 		}
-	case 48:
+	case 51:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:753
+		{
+			yyVAL.arg = nil
+			yyVAL.expr = nil
+		}
+	case 52:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:518
+		//line grammar.y:759
 		{
 			yyVAL.args = nil
 			yyVAL.exprs = nil
 		}
-	case 49:
+	case 53:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:523
+		//line grammar.y:764
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
-			if yyDollar[3].expr != nil {
-				yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
-			}
+			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
-	case 50:
+	case 54:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:532
+		//line grammar.y:771
 		{
 			yyVAL.args = nil
 			yyVAL.args = append(yyVAL.args, yyDollar[1].arg)
@@ -1408,165 +1734,177 @@ yydefault:
 				yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 			}
 		}
-	case 51:
+	case 55:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:541
+		//line grammar.y:780
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
 			if yyDollar[3].expr != nil {
 				yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 			}
 		}
-	case 52:
+	case 56:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:549
+		//line grammar.y:788
 		{
 			yyVAL.arg = nil
 		}
-	case 53:
+	case 57:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:553
+		//line grammar.y:792
 		{
 			yyVAL.arg = yyDollar[1].arg
 		}
-	case 54:
+	case 58:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:560
+		//line grammar.y:799
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs}
 		}
-	case 55:
+	case 59:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:564
+		//line grammar.y:803
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs}
 		}
-	case 56:
+	case 60:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line grammar.y:568
+		//line grammar.y:807
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs, Kwarg: yyDollar[8].arg}
 		}
-	case 57:
+	case 61:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:572
+		//line grammar.y:811
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Kwarg: yyDollar[4].arg}
 		}
-	case 58:
+	case 62:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:576
+		//line grammar.y:815
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs}
 		}
-	case 59:
+	case 63:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:580
+		//line grammar.y:819
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs, Kwarg: yyDollar[6].arg}
 		}
-	case 60:
+	case 64:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:584
+		//line grammar.y:823
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Kwarg: yyDollar[2].arg}
 		}
-	case 61:
+	case 65:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:590
+		//line grammar.y:829
 		{
 			yyVAL.arg = &ast.Arg{Pos: yyVAL.pos, Arg: ast.Identifier(yyDollar[1].str)}
 		}
-	case 62:
+	case 66:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:596
+		//line grammar.y:835
 		{
 			yyVAL.stmts = yyDollar[1].stmts
 		}
-	case 63:
+	case 67:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:600
+		//line grammar.y:839
 		{
 			yyVAL.stmts = []ast.Stmt{yyDollar[1].stmt}
 		}
-	case 66:
+	case 70:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:608
+		//line grammar.y:847
 		{
 			yyVAL.stmts = nil
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[1].stmt)
 		}
-	case 67:
+	case 71:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:613
+		//line grammar.y:852
 		{
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[3].stmt)
 		}
-	case 68:
+	case 72:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:619
+		//line grammar.y:858
 		{
 			yyVAL.stmts = yyDollar[1].stmts
 		}
-	case 69:
+	case 73:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:625
+		//line grammar.y:864
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 70:
+	case 74:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:629
+		//line grammar.y:868
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 71:
+	case 75:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:633
+		//line grammar.y:872
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 72:
+	case 76:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:637
+		//line grammar.y:876
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 73:
+	case 77:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:641
+		//line grammar.y:880
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 74:
+	case 78:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:645
+		//line grammar.y:884
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 75:
+	case 79:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:649
+		//line grammar.y:888
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 76:
+	case 80:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:653
+		//line grammar.y:892
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 77:
+	case 81:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:680
+		//line grammar.y:919
 		{
 			target := yyDollar[1].expr
 			setCtx(yylex, target, ast.Store)
 			yyVAL.stmt = &ast.AugAssign{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Target: target, Op: yyDollar[2].op, Value: yyDollar[3].expr}
 		}
-	case 78:
+	case 82:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:925
+		{
+			yyVAL.stmt = annAssign(yylex, yyVAL.pos, yyDollar[1].expr, yyDollar[3].expr, nil)
+		}
+	case 83:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line grammar.y:929
+		{
+			yyVAL.stmt = annAssign(yylex, yyVAL.pos, yyDollar[1].expr, yyDollar[3].expr, yyDollar[5].expr)
+		}
+	case 84:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:686
+		//line grammar.y:933
 		{
 			targets := []ast.Expr{yyDollar[1].expr}
 			targets = append(targets, yyDollar[2].exprs...)
@@ -1575,515 +1913,578 @@ yydefault:
 			setCtxs(yylex, targets, ast.Store)
 			yyVAL.stmt = &ast.Assign{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Targets: targets, Value: value}
 		}
-	case 79:
+	case 85:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:695
+		//line grammar.y:942
 		{
 			yyVAL.stmt = &ast.ExprStmt{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr}
 		}
-	case 80:
+	case 86:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:701
+		//line grammar.y:948
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 81:
+	case 87:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:705
+		//line grammar.y:952
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 82:
+	case 88:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:711
+		//line grammar.y:958
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 83:
+	case 89:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:715
+		//line grammar.y:962
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 84:
+	case 90:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:721
+		//line grammar.y:968
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[2].expr)
 		}
-	case 85:
+	case 91:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:726
+		//line grammar.y:973
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
-	case 86:
+	case 92:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:732
+		//line grammar.y:979
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
-	case 87:
+	case 93:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:737
+		//line grammar.y:984
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
-	case 88:
+	case 94:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:743
+		//line grammar.y:990
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 89:
+	case 95:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:747
+		//line grammar.y:994
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 90:
+	case 96:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1000
+		{
+			yyVAL.exprs = nil
+			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
+		}
+	case 97:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1005
+		{
+			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
+		}
+	case 98:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1011
+		{
+			yyVAL.expr = yyDollar[1].expr
+		}
+	case 99:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1015
+		{
+			yyVAL.expr = yyDollar[1].expr
+		}
+	case 100:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:752
+		//line grammar.y:1020
 		{
 			yyVAL.comma = false
 		}
-	case 91:
+	case 101:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:756
+		//line grammar.y:1024
 		{
 			yyVAL.comma = true
 		}
-	case 92:
+	case 102:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:762
+		//line grammar.y:1030
 		{
 			yyVAL.expr = tupleOrExpr(yyVAL.pos, yyDollar[1].exprs, yyDollar[2].comma)
 		}
-	case 93:
+	case 103:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:768
+		//line grammar.y:1036
 		{
 			yyVAL.op = ast.Add
 		}
-	case 94:
+	case 104:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:772
+		//line grammar.y:1040
 		{
 			yyVAL.op = ast.Sub
 		}
-	case 95:
+	case 105:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:776
+		//line grammar.y:1044
 		{
 			yyVAL.op = ast.Mult
 		}
-	case 96:
+	case 106:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:780
+		//line grammar.y:1048
 		{
 			yyVAL.op = ast.Div
 		}
-	case 97:
+	case 107:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:784
+		//line grammar.y:1052
 		{
 			yyVAL.op = ast.Modulo
 		}
-	case 98:
+	case 108:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:788
+		//line grammar.y:1056
 		{
 			yyVAL.op = ast.BitAnd
 		}
-	case 99:
+	case 109:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:792
+		//line grammar.y:1060
 		{
 			yyVAL.op = ast.BitOr
 		}
-	case 100:
+	case 110:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:796
+		//line grammar.y:1064
 		{
 			yyVAL.op = ast.BitXor
 		}
-	case 101:
+	case 111:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:800
+		//line grammar.y:1068
 		{
 			yyVAL.op = ast.LShift
 		}
-	case 102:
+	case 112:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:804
+		//line grammar.y:1072
 		{
 			yyVAL.op = ast.RShift
 		}
-	case 103:
+	case 113:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:808
+		//line grammar.y:1076
 		{
 			yyVAL.op = ast.Pow
 		}
-	case 104:
+	case 114:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:812
+		//line grammar.y:1080
 		{
 			yyVAL.op = ast.FloorDiv
 		}
-	case 105:
+	case 115:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1084
+		{
+			yyVAL.op = ast.MatMult
+		}
+	case 116:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:819
+		//line grammar.y:1091
 		{
 			setCtxs(yylex, yyDollar[2].exprs, ast.Del)
 			yyVAL.stmt = &ast.Delete{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Targets: yyDollar[2].exprs}
 		}
-	case 106:
+	case 117:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:826
+		//line grammar.y:1098
 		{
 			yyVAL.stmt = &ast.Pass{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
-	case 107:
+	case 118:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:832
+		//line grammar.y:1104
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 108:
+	case 119:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:836
+		//line grammar.y:1108
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 109:
+	case 120:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:840
+		//line grammar.y:1112
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 110:
+	case 121:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:844
+		//line grammar.y:1116
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 111:
+	case 122:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:848
+		//line grammar.y:1120
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 112:
+	case 123:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:854
+		//line grammar.y:1126
 		{
 			yyVAL.stmt = &ast.Break{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
-	case 113:
+	case 124:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:860
+		//line grammar.y:1132
 		{
 			yyVAL.stmt = &ast.Continue{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
-	case 114:
+	case 125:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:866
+		//line grammar.y:1138
 		{
 			yyVAL.stmt = &ast.Return{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
-	case 115:
+	case 126:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:870
+		//line grammar.y:1142
 		{
 			yyVAL.stmt = &ast.Return{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Value: yyDollar[2].expr}
 		}
-	case 116:
+	case 127:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:876
+		//line grammar.y:1148
 		{
 			yyVAL.stmt = &ast.ExprStmt{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr}
 		}
-	case 117:
+	case 128:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:882
+		//line grammar.y:1154
 		{
 			yyVAL.stmt = &ast.Raise{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
-	case 118:
+	case 129:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:886
+		//line grammar.y:1158
 		{
 			yyVAL.stmt = &ast.Raise{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Exc: yyDollar[2].expr}
 		}
-	case 119:
+	case 130:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:890
+		//line grammar.y:1162
 		{
 			yyVAL.stmt = &ast.Raise{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Exc: yyDollar[2].expr, Cause: yyDollar[4].expr}
 		}
-	case 120:
+	case 131:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:896
+		//line grammar.y:1168
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 121:
+	case 132:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:900
+		//line grammar.y:1172
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 122:
+	case 133:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:906
+		//line grammar.y:1178
 		{
 			yyVAL.stmt = &ast.Import{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Names: yyDollar[2].aliases}
 		}
-	case 123:
+	case 134:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:913
+		//line grammar.y:1185
 		{
 			yyVAL.level = 1
 		}
-	case 124:
+	case 135:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:917
+		//line grammar.y:1189
 		{
 			yyVAL.level = 3
 		}
-	case 125:
+	case 136:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:923
+		//line grammar.y:1195
 		{
 			yyVAL.level = yyDollar[1].level
 		}
-	case 126:
+	case 137:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:927
+		//line grammar.y:1199
 		{
 			yyVAL.level += yyDollar[2].level
 		}
-	case 127:
+	case 138:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:933
+		//line grammar.y:1205
 		{
 			yyVAL.level = 0
 			yyVAL.str = yyDollar[1].str
 		}
-	case 128:
+	case 139:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:938
+		//line grammar.y:1210
 		{
 			yyVAL.level = yyDollar[1].level
 			yyVAL.str = yyDollar[2].str
 		}
-	case 129:
+	case 140:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:943
+		//line grammar.y:1215
 		{
 			yyVAL.level = yyDollar[1].level
 			yyVAL.str = ""
 		}
-	case 130:
+	case 141:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:950
+		//line grammar.y:1222
 		{
 			yyVAL.aliases = []*ast.Alias{&ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier("*")}}
 		}
-	case 131:
+	case 142:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:954
+		//line grammar.y:1226
 		{
 			yyVAL.aliases = yyDollar[2].aliases
 		}
-	case 132:
+	case 143:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:958
+		//line grammar.y:1230
 		{
 			yyVAL.aliases = yyDollar[1].aliases
 		}
-	case 133:
+	case 144:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:964
+		//line grammar.y:1236
 		{
 			yyVAL.stmt = &ast.ImportFrom{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Module: ast.Identifier(yyDollar[2].str), Names: yyDollar[4].aliases, Level: yyDollar[2].level}
 		}
-	case 134:
+	case 145:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:970
+		//line grammar.y:1242
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str)}
 		}
-	case 135:
+	case 146:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:974
+		//line grammar.y:1246
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str), AsName: ast.Identifier(yyDollar[3].str)}
 		}
-	case 136:
+	case 147:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:980
+		//line grammar.y:1252
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str)}
 		}
-	case 137:
+	case 148:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:984
+		//line grammar.y:1256
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str), AsName: ast.Identifier(yyDollar[3].str)}
 		}
-	case 138:
+	case 149:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:990
+		//line grammar.y:1262
 		{
 			yyVAL.aliases = nil
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[1].alias)
 		}
-	case 139:
+	case 150:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:995
+		//line grammar.y:1267
 		{
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[3].alias)
 		}
-	case 140:
+	case 151:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1001
+		//line grammar.y:1273
 		{
 			yyVAL.aliases = nil
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[1].alias)
 		}
-	case 141:
+	case 152:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1006
+		//line grammar.y:1278
 		{
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[3].alias)
 		}
-	case 142:
+	case 153:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1012
+		//line grammar.y:1284
 		{
 			yyVAL.str = yyDollar[1].str
 		}
-	case 143:
+	case 154:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1016
+		//line grammar.y:1288
 		{
 			yyVAL.str += "." + yyDollar[3].str
 		}
-	case 144:
+	case 155:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1022
+		//line grammar.y:1294
 		{
 			yyVAL.identifiers = nil
 			yyVAL.identifiers = append(yyVAL.identifiers, ast.Identifier(yyDollar[1].str))
 		}
-	case 145:
+	case 156:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1027
+		//line grammar.y:1299
 		{
 			yyVAL.identifiers = append(yyVAL.identifiers, ast.Identifier(yyDollar[3].str))
 		}
-	case 146:
+	case 157:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1033
+		//line grammar.y:1305
 		{
 			yyVAL.stmt = &ast.Global{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Names: yyDollar[2].identifiers}
 		}
-	case 147:
+	case 158:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1039
+		//line grammar.y:1311
 		{
 			yyVAL.stmt = &ast.Nonlocal{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Names: yyDollar[2].identifiers}
 		}
-	case 148:
+	case 159:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1045
+		//line grammar.y:1317
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
-	case 149:
+	case 160:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1050
+		//line grammar.y:1322
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
-	case 150:
+	case 161:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1056
+		//line grammar.y:1328
 		{
 			yyVAL.stmt = &ast.Assert{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr}
 		}
-	case 151:
+	case 162:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1060
+		//line grammar.y:1332
 		{
 			yyVAL.stmt = &ast.Assert{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr, Msg: yyDollar[4].expr}
 		}
-	case 152:
+	case 163:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1066
+		//line grammar.y:1338
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 153:
+	case 164:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1070
+		//line grammar.y:1342
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 154:
+	case 165:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1074
+		//line grammar.y:1346
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 155:
+	case 166:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1078
+		//line grammar.y:1350
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 156:
+	case 167:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1082
+		//line grammar.y:1354
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 157:
+	case 168:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1086
+		//line grammar.y:1358
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 158:
+	case 169:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1090
+		//line grammar.y:1362
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 159:
+	case 170:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1094
+		//line grammar.y:1366
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
-	case 160:
+	case 171:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1370
+		{
+			yyVAL.stmt = yyDollar[1].stmt
+		}
+	case 172:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1374
+		{
+			yyVAL.stmt = yyDollar[1].stmt
+		}
+	case 173:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1380
+		{
+			yyVAL.stmt = yyDollar[1].stmt
+		}
+	case 174:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1384
+		{
+			with := yyDollar[2].stmt.(*ast.With)
+			yyVAL.stmt = &ast.AsyncWith{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Items: with.Items, Body: with.Body}
+		}
+	case 175:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1389
+		{
+			loop := yyDollar[2].stmt.(*ast.For)
+			yyVAL.stmt = &ast.AsyncFor{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Target: loop.Target, Iter: loop.Iter, Body: loop.Body, Orelse: loop.Orelse}
+		}
+	case 176:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1099
+		//line grammar.y:1395
 		{
 			yyVAL.ifstmt = nil
 			yyVAL.lastif = nil
 		}
-	case 161:
+	case 177:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1104
+		//line grammar.y:1400
 		{
 			elifs := yyVAL.ifstmt
-			newif := &ast.If{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[3].expr, Body: yyDollar[5].stmts}
+			newif := &ast.If{StmtBase: ast.StmtBase{Pos: yyDollar[2].pos}, Test: yyDollar[3].expr, Body: yyDollar[5].stmts}
 			if elifs == nil {
 				yyVAL.ifstmt = newif
 			} else {
@@ -2091,21 +2492,21 @@ yydefault:
 			}
 			yyVAL.lastif = newif
 		}
-	case 162:
+	case 178:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1116
+		//line grammar.y:1412
 		{
 			yyVAL.stmts = nil
 		}
-	case 163:
+	case 179:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1120
+		//line grammar.y:1416
 		{
 			yyVAL.stmts = yyDollar[3].stmts
 		}
-	case 164:
+	case 180:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:1126
+		//line grammar.y:1422
 		{
 			newif := &ast.If{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr, Body: yyDollar[4].stmts}
 			yyVAL.stmt = newif
@@ -2124,202 +2525,551 @@ yydefault:
 				}
 			}
 		}
-	case 165:
+	case 181:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1147
+		//line grammar.y:1443
 		{
 			yyVAL.stmt = &ast.While{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr, Body: yyDollar[4].stmts, Orelse: yyDollar[5].stmts}
 		}
-	case 166:
+	case 182:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1153
+		//line grammar.y:1449
 		{
-			target := tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, false)
+			target := tupleOrExpr(yyDollar[2].pos, yyDollar[2].exprs, false)
 			setCtx(yylex, target, ast.Store)
 			yyVAL.stmt = &ast.For{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Target: target, Iter: yyDollar[4].expr, Body: yyDollar[6].stmts, Orelse: yyDollar[7].stmts}
 		}
-	case 167:
+	case 183:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1160
+		//line grammar.y:1456
 		{
 			yyVAL.exchandlers = nil
 		}
-	case 168:
+	case 184:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1164
+		//line grammar.y:1460
 		{
-			exc := &ast.ExceptHandler{Pos: yyVAL.pos, ExprType: yyDollar[2].expr, Name: ast.Identifier(yyDollar[2].str), Body: yyDollar[4].stmts}
+			exc := &ast.ExceptHandler{Pos: yyDollar[2].pos, ExprType: yyDollar[2].expr, Name: ast.Identifier(yyDollar[2].str), Body: yyDollar[4].stmts}
 			yyVAL.exchandlers = append(yyVAL.exchandlers, exc)
 		}
-	case 169:
+	case 185:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1171
+		//line grammar.y:1467
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers}
 		}
-	case 170:
+	case 186:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1175
+		//line grammar.y:1471
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers, Orelse: yyDollar[7].stmts}
 		}
-	case 171:
+	case 187:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1179
+		//line grammar.y:1475
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers, Finalbody: yyDollar[7].stmts}
 		}
-	case 172:
+	case 188:
 		yyDollar = yyS[yypt-10 : yypt+1]
-		//line grammar.y:1183
+		//line grammar.y:1479
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers, Orelse: yyDollar[7].stmts, Finalbody: yyDollar[10].stmts}
 		}
-	case 173:
+	case 189:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1189
+		//line grammar.y:1485
 		{
 			yyVAL.withitems = nil
 			yyVAL.withitems = append(yyVAL.withitems, yyDollar[1].withitem)
 		}
-	case 174:
+	case 190:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1194
+		//line grammar.y:1490
 		{
 			yyVAL.withitems = append(yyVAL.withitems, yyDollar[3].withitem)
 		}
-	case 175:
+	case 191:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1200
+		//line grammar.y:1496
 		{
 			yyVAL.stmt = &ast.With{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Items: yyDollar[2].withitems, Body: yyDollar[4].stmts}
 		}
-	case 176:
+	case 192:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1206
+		//line grammar.y:1502
 		{
 			yyVAL.withitem = &ast.WithItem{Pos: yyVAL.pos, ContextExpr: yyDollar[1].expr}
 		}
-	case 177:
+	case 193:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1210
+		//line grammar.y:1506
 		{
 			v := yyDollar[3].expr
 			setCtx(yylex, v, ast.Store)
 			yyVAL.withitem = &ast.WithItem{Pos: yyVAL.pos, ContextExpr: yyDollar[1].expr, OptionalVars: v}
 		}
-	case 178:
+	case 194:
+		yyDollar = yyS[yypt-7 : yypt+1]
+		//line grammar.y:1514
+		{
+			if _, ok := yyDollar[2].expr.(*ast.Starred); ok {
+				yylex.(*yyLex).SyntaxError("invalid syntax")
+			}
+			yyVAL.stmt = &ast.Match{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Subject: yyDollar[2].expr, Cases: yyDollar[6].matchcases}
+		}
+	case 195:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1219
+		//line grammar.y:1523
+		{
+			yyVAL.matchcases = nil
+			yyVAL.matchcases = append(yyVAL.matchcases, yyDollar[1].matchcase)
+		}
+	case 196:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1528
+		{
+			yyVAL.matchcases = append(yyVAL.matchcases, yyDollar[2].matchcase)
+		}
+	case 197:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line grammar.y:1534
+		{
+			yyVAL.matchcase = &ast.MatchCase{Pos: yyVAL.pos, Pattern: yyDollar[2].pattern, Guard: yyDollar[3].expr, Body: yyDollar[5].stmts}
+		}
+	case 198:
+		yyDollar = yyS[yypt-0 : yypt+1]
+		//line grammar.y:1539
+		{
+			yyVAL.expr = nil
+		}
+	case 199:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1543
+		{
+			yyVAL.expr = yyDollar[2].expr
+		}
+	case 200:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1549
+		{
+			yyVAL.pattern = patternsOrPattern(yylex, yyVAL.pos, yyDollar[1].patterns, yyDollar[2].comma)
+		}
+	case 201:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1555
+		{
+			yyVAL.patterns = nil
+			yyVAL.patterns = append(yyVAL.patterns, yyDollar[1].pattern)
+		}
+	case 202:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1560
+		{
+			yyVAL.patterns = append(yyVAL.patterns, yyDollar[3].pattern)
+		}
+	case 203:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1566
+		{
+			yyVAL.pattern = yyDollar[1].pattern
+		}
+	case 204:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1570
+		{
+			name := ast.Identifier(yyDollar[2].str)
+			if name == "_" {
+				name = ""
+			}
+			yyVAL.pattern = &ast.MatchStar{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Name: name}
+		}
+	case 205:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1580
+		{
+			yyVAL.pattern = yyDollar[1].pattern
+		}
+	case 206:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1584
+		{
+			if yyDollar[3].str == "_" {
+				yylex.(*yyLex).SyntaxError("cannot use '_' as a target")
+			}
+			yyVAL.pattern = &ast.MatchAs{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Pattern: yyDollar[1].pattern, Name: ast.Identifier(yyDollar[3].str)}
+		}
+	case 207:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1593
+		{
+			if len(yyDollar[1].patterns) == 1 {
+				yyVAL.pattern = yyDollar[1].patterns[0]
+			} else {
+				yyVAL.pattern = &ast.MatchOr{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Patterns: yyDollar[1].patterns}
+			}
+		}
+	case 208:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1603
+		{
+			yyVAL.patterns = nil
+			yyVAL.patterns = append(yyVAL.patterns, yyDollar[1].pattern)
+		}
+	case 209:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1608
+		{
+			yyVAL.patterns = append(yyVAL.patterns, yyDollar[3].pattern)
+		}
+	case 210:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1614
+		{
+			yyVAL.pattern = literalPattern(yylex, yyVAL.pos, yyDollar[1].expr)
+		}
+	case 211:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1618
+		{
+			switch x := yyDollar[1].expr.(type) {
+			case *ast.Name:
+				if x.Id == "_" {
+					yyVAL.pattern = &ast.MatchAs{PatternBase: ast.PatternBase{Pos: yyVAL.pos}}
+				} else {
+					yyVAL.pattern = &ast.MatchAs{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Name: x.Id}
+				}
+			default:
+				yyVAL.pattern = &ast.MatchValue{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr}
+			}
+		}
+	case 212:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1631
+		{
+			yyVAL.pattern = &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: yyVAL.pos}}
+		}
+	case 213:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line grammar.y:1635
+		{
+			yyVAL.pattern = patternsOrPattern(yylex, yyVAL.pos, yyDollar[2].patterns, yyDollar[3].comma)
+		}
+	case 214:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1639
+		{
+			yyVAL.pattern = &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: yyVAL.pos}}
+		}
+	case 215:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line grammar.y:1643
+		{
+			yyVAL.pattern = &ast.MatchSequence{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Patterns: yyDollar[2].patterns}
+		}
+	case 216:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1647
+		{
+			yyVAL.pattern = &ast.MatchMapping{PatternBase: ast.PatternBase{Pos: yyVAL.pos}}
+		}
+	case 217:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line grammar.y:1651
+		{
+			yyDollar[2].matchmapping.Pos = yyVAL.pos
+			yyVAL.pattern = yyDollar[2].matchmapping
+		}
+	case 218:
+		yyDollar = yyS[yypt-7 : yypt+1]
+		//line grammar.y:1656
+		{
+			yyDollar[2].matchmapping.Pos = yyVAL.pos
+			yyDollar[2].matchmapping.Rest = ast.Identifier(yyDollar[5].str)
+			yyVAL.pattern = yyDollar[2].matchmapping
+		}
+	case 219:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line grammar.y:1662
+		{
+			yyVAL.pattern = &ast.MatchMapping{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Rest: ast.Identifier(yyDollar[3].str)}
+		}
+	case 220:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1666
+		{
+			yyVAL.pattern = &ast.MatchClass{PatternBase: ast.PatternBase{Pos: yyVAL.pos}, Cls: yyDollar[1].expr}
+		}
+	case 221:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line grammar.y:1670
+		{
+			yyDollar[3].matchclass.Pos = yyVAL.pos
+			yyDollar[3].matchclass.Cls = yyDollar[1].expr
+			yyVAL.pattern = yyDollar[3].matchclass
+		}
+	case 222:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1678
+		{
+			yyVAL.matchmapping = &ast.MatchMapping{Keys: []ast.Expr{yyDollar[1].expr}, Patterns: []ast.Pattern{yyDollar[3].pattern}}
+		}
+	case 223:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1682
+		{
+			if _, ok := yyDollar[1].expr.(*ast.Attribute); !ok {
+				yylex.(*yyLex).SyntaxError("invalid syntax")
+			}
+			yyVAL.matchmapping = &ast.MatchMapping{Keys: []ast.Expr{yyDollar[1].expr}, Patterns: []ast.Pattern{yyDollar[3].pattern}}
+		}
+	case 224:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line grammar.y:1689
+		{
+			yyVAL.matchmapping.Keys = append(yyVAL.matchmapping.Keys, yyDollar[3].expr)
+			yyVAL.matchmapping.Patterns = append(yyVAL.matchmapping.Patterns, yyDollar[5].pattern)
+		}
+	case 225:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line grammar.y:1694
+		{
+			if _, ok := yyDollar[3].expr.(*ast.Attribute); !ok {
+				yylex.(*yyLex).SyntaxError("invalid syntax")
+			}
+			yyVAL.matchmapping.Keys = append(yyVAL.matchmapping.Keys, yyDollar[3].expr)
+			yyVAL.matchmapping.Patterns = append(yyVAL.matchmapping.Patterns, yyDollar[5].pattern)
+		}
+	case 226:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1704
+		{
+			yyVAL.matchclass = &ast.MatchClass{Patterns: []ast.Pattern{yyDollar[1].pattern}}
+		}
+	case 227:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1708
+		{
+			yyVAL.matchclass = &ast.MatchClass{KwdAttrs: []ast.Identifier{ast.Identifier(yyDollar[1].str)}, KwdPatterns: []ast.Pattern{yyDollar[3].pattern}}
+		}
+	case 228:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1712
+		{
+			if len(yyVAL.matchclass.KwdAttrs) != 0 {
+				yylex.(*yyLex).SyntaxError("positional patterns follow keyword patterns")
+			}
+			yyVAL.matchclass.Patterns = append(yyVAL.matchclass.Patterns, yyDollar[3].pattern)
+		}
+	case 229:
+		yyDollar = yyS[yypt-5 : yypt+1]
+		//line grammar.y:1719
+		{
+			yyVAL.matchclass.KwdAttrs = append(yyVAL.matchclass.KwdAttrs, ast.Identifier(yyDollar[3].str))
+			yyVAL.matchclass.KwdPatterns = append(yyVAL.matchclass.KwdPatterns, yyDollar[5].pattern)
+		}
+	case 230:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1726
+		{
+			yyVAL.expr = &ast.Name{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Id: ast.Identifier(yyDollar[1].str), Ctx: ast.Load}
+		}
+	case 231:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1730
+		{
+			yyVAL.expr = &ast.Attribute{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr, Attr: ast.Identifier(yyDollar[3].str), Ctx: ast.Load}
+		}
+	case 232:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1736
+		{
+			yyVAL.expr = yyDollar[1].expr
+		}
+	case 233:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1740
+		{
+			yyVAL.expr = complexLiteral(yylex, yyVAL.pos, yyDollar[1].expr, ast.Add, yyDollar[3].obj)
+		}
+	case 234:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1744
+		{
+			yyVAL.expr = complexLiteral(yylex, yyVAL.pos, yyDollar[1].expr, ast.Sub, yyDollar[3].obj)
+		}
+	case 235:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1748
+		{
+			switch s := yyDollar[1].obj.(type) {
+			case py.String:
+				yyVAL.expr = &ast.Str{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, S: s}
+			case py.Bytes:
+				yyVAL.expr = &ast.Bytes{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, S: s}
+			case *ast.JoinedStr:
+				s.Pos = yyVAL.pos
+				yyVAL.expr = s
+			default:
+				panic("not Bytes or String in strings")
+			}
+		}
+	case 236:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1762
+		{
+			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.None}
+		}
+	case 237:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1766
+		{
+			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.True}
+		}
+	case 238:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1770
+		{
+			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.False}
+		}
+	case 239:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1776
+		{
+			yyVAL.expr = &ast.Num{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, N: yyDollar[1].obj}
+		}
+	case 240:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:1780
+		{
+			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.USub, Operand: &ast.Num{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, N: yyDollar[2].obj}}
+		}
+	case 241:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1787
 		{
 			yyVAL.expr = nil
 			yyVAL.str = ""
 		}
-	case 179:
+	case 242:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1224
+		//line grammar.y:1792
 		{
 			yyVAL.expr = yyDollar[2].expr
 			yyVAL.str = ""
 		}
-	case 180:
+	case 243:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1229
+		//line grammar.y:1797
 		{
 			yyVAL.expr = yyDollar[2].expr
 			yyVAL.str = yyDollar[4].str
 		}
-	case 181:
+	case 244:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1236
+		//line grammar.y:1804
 		{
 			yyVAL.stmts = nil
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[1].stmts...)
 		}
-	case 182:
+	case 245:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1241
+		//line grammar.y:1809
 		{
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[2].stmts...)
 		}
-	case 183:
+	case 246:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1247
+		//line grammar.y:1815
 		{
 			yyVAL.stmts = yyDollar[1].stmts
 		}
-	case 184:
+	case 247:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1251
+		//line grammar.y:1819
 		{
 			yyVAL.stmts = yyDollar[3].stmts
 		}
-	case 185:
+	case 248:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1257
+		//line grammar.y:1825
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 186:
+	case 249:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:1829
+		{
+			yyVAL.expr = namedExpr(yylex, yyVAL.pos, yyDollar[1].expr, yyDollar[3].expr)
+		}
+	case 250:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:1835
+		{
+			yyVAL.expr = yyDollar[1].expr
+		}
+	case 251:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1261
+		//line grammar.y:1839
 		{
 			yyVAL.expr = &ast.IfExp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Test: yyDollar[3].expr, Body: yyDollar[1].expr, Orelse: yyDollar[5].expr}
 		}
-	case 187:
+	case 252:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1265
+		//line grammar.y:1843
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 188:
+	case 253:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1271
+		//line grammar.y:1849
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 189:
+	case 254:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1275
+		//line grammar.y:1853
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 190:
+	case 255:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1281
+		//line grammar.y:1859
 		{
 			args := &ast.Arguments{Pos: yyVAL.pos}
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: args, Body: yyDollar[3].expr}
 		}
-	case 191:
+	case 256:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1286
+		//line grammar.y:1864
 		{
+			setPosonlyargs(yylex, yyDollar[2].arguments)
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: yyDollar[2].arguments, Body: yyDollar[4].expr}
 		}
-	case 192:
+	case 257:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1292
+		//line grammar.y:1871
 		{
 			args := &ast.Arguments{Pos: yyVAL.pos}
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: args, Body: yyDollar[3].expr}
 		}
-	case 193:
+	case 258:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1297
+		//line grammar.y:1876
 		{
+			setPosonlyargs(yylex, yyDollar[2].arguments)
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: yyDollar[2].arguments, Body: yyDollar[4].expr}
 		}
-	case 194:
+	case 259:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1303
+		//line grammar.y:1883
 		{
 			yyVAL.expr = yyDollar[1].expr
 			yyVAL.isExpr = true
 		}
-	case 195:
+	case 260:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1308
+		//line grammar.y:1888
 		{
 			if !yyDollar[1].isExpr {
 				boolop := yyVAL.expr.(*ast.BoolOp)
@@ -2329,16 +3079,16 @@ yydefault:
 			}
 			yyVAL.isExpr = false
 		}
-	case 196:
+	case 261:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1320
+		//line grammar.y:1900
 		{
 			yyVAL.expr = yyDollar[1].expr
 			yyVAL.isExpr = true
 		}
-	case 197:
+	case 262:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1325
+		//line grammar.y:1905
 		{
 			if !yyDollar[1].isExpr {
 				boolop := yyVAL.expr.(*ast.BoolOp)
@@ -2348,28 +3098,28 @@ yydefault:
 			}
 			yyVAL.isExpr = false
 		}
-	case 198:
+	case 263:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1337
+		//line grammar.y:1917
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.Not, Operand: yyDollar[2].expr}
 		}
-	case 199:
+	case 264:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1341
+		//line grammar.y:1921
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 200:
+	case 265:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1347
+		//line grammar.y:1927
 		{
 			yyVAL.expr = yyDollar[1].expr
 			yyVAL.isExpr = true
 		}
-	case 201:
+	case 266:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1352
+		//line grammar.y:1932
 		{
 			if !yyDollar[1].isExpr {
 				comp := yyVAL.expr.(*ast.Compare)
@@ -2380,373 +3130,393 @@ yydefault:
 			}
 			yyVAL.isExpr = false
 		}
-	case 202:
+	case 267:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1367
+		//line grammar.y:1947
 		{
 			yyVAL.cmpop = ast.Lt
 		}
-	case 203:
+	case 268:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1371
+		//line grammar.y:1951
 		{
 			yyVAL.cmpop = ast.Gt
 		}
-	case 204:
+	case 269:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1375
+		//line grammar.y:1955
 		{
 			yyVAL.cmpop = ast.Eq
 		}
-	case 205:
+	case 270:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1379
+		//line grammar.y:1959
 		{
 			yyVAL.cmpop = ast.GtE
 		}
-	case 206:
+	case 271:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1383
+		//line grammar.y:1963
 		{
 			yyVAL.cmpop = ast.LtE
 		}
-	case 207:
+	case 272:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1387
+		//line grammar.y:1967
 		{
 			yylex.(*yyLex).SyntaxError("invalid syntax")
 		}
-	case 208:
+	case 273:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1391
+		//line grammar.y:1971
 		{
 			yyVAL.cmpop = ast.NotEq
 		}
-	case 209:
+	case 274:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1395
+		//line grammar.y:1975
 		{
 			yyVAL.cmpop = ast.In
 		}
-	case 210:
+	case 275:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1399
+		//line grammar.y:1979
 		{
 			yyVAL.cmpop = ast.NotIn
 		}
-	case 211:
+	case 276:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1403
+		//line grammar.y:1983
 		{
 			yyVAL.cmpop = ast.Is
 		}
-	case 212:
+	case 277:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1407
+		//line grammar.y:1987
 		{
 			yyVAL.cmpop = ast.IsNot
 		}
-	case 213:
+	case 278:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1413
+		//line grammar.y:1993
 		{
 			yyVAL.expr = &ast.Starred{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[2].expr, Ctx: ast.Load}
 		}
-	case 214:
+	case 279:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1419
+		//line grammar.y:1999
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 215:
+	case 280:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1423
+		//line grammar.y:2003
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.BitOr, Right: yyDollar[3].expr}
 		}
-	case 216:
+	case 281:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1429
+		//line grammar.y:2009
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 217:
+	case 282:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1433
+		//line grammar.y:2013
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.BitXor, Right: yyDollar[3].expr}
 		}
-	case 218:
+	case 283:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1439
+		//line grammar.y:2019
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 219:
+	case 284:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1443
+		//line grammar.y:2023
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.BitAnd, Right: yyDollar[3].expr}
 		}
-	case 220:
+	case 285:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1449
+		//line grammar.y:2029
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 221:
+	case 286:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1453
+		//line grammar.y:2033
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.LShift, Right: yyDollar[3].expr}
 		}
-	case 222:
+	case 287:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1457
+		//line grammar.y:2037
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.RShift, Right: yyDollar[3].expr}
 		}
-	case 223:
+	case 288:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1463
+		//line grammar.y:2043
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 224:
+	case 289:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1467
+		//line grammar.y:2047
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Add, Right: yyDollar[3].expr}
 		}
-	case 225:
+	case 290:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1471
+		//line grammar.y:2051
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Sub, Right: yyDollar[3].expr}
 		}
-	case 226:
+	case 291:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1477
+		//line grammar.y:2057
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 227:
+	case 292:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1481
+		//line grammar.y:2061
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Mult, Right: yyDollar[3].expr}
 		}
-	case 228:
+	case 293:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1485
+		//line grammar.y:2065
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Div, Right: yyDollar[3].expr}
 		}
-	case 229:
+	case 294:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1489
+		//line grammar.y:2069
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Modulo, Right: yyDollar[3].expr}
 		}
-	case 230:
+	case 295:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1493
+		//line grammar.y:2073
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.FloorDiv, Right: yyDollar[3].expr}
 		}
-	case 231:
+	case 296:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:2077
+		{
+			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.MatMult, Right: yyDollar[3].expr}
+		}
+	case 297:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1499
+		//line grammar.y:2083
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.UAdd, Operand: yyDollar[2].expr}
 		}
-	case 232:
+	case 298:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1503
+		//line grammar.y:2087
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.USub, Operand: yyDollar[2].expr}
 		}
-	case 233:
+	case 299:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1507
+		//line grammar.y:2091
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.Invert, Operand: yyDollar[2].expr}
 		}
-	case 234:
+	case 300:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1511
+		//line grammar.y:2095
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 235:
+	case 301:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:2101
+		{
+			yyVAL.expr = yyDollar[1].expr
+		}
+	case 302:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:2105
+		{
+			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Pow, Right: yyDollar[3].expr}
+		}
+	case 303:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1517
+		//line grammar.y:2111
 		{
-			yyVAL.expr = applyTrailers(yyDollar[1].expr, yyDollar[2].exprs)
+			yyVAL.expr = applyTrailers(yyVAL.pos, yyDollar[1].expr, yyDollar[2].exprs)
 		}
-	case 236:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1521
+	case 304:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:2115
 		{
-			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: applyTrailers(yyDollar[1].expr, yyDollar[2].exprs), Op: ast.Pow, Right: yyDollar[4].expr}
+			yyVAL.expr = &ast.Await{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: applyTrailers(yyDollar[2].pos, yyDollar[2].expr, yyDollar[3].exprs)}
 		}
-	case 237:
+	case 305:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1527
+		//line grammar.y:2121
 		{
 			yyVAL.exprs = nil
 		}
-	case 238:
+	case 306:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1531
+		//line grammar.y:2125
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[2].expr)
 		}
-	case 239:
+	case 307:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1537
+		//line grammar.y:2131
 		{
 			yyVAL.obj = yyDollar[1].obj
 		}
-	case 240:
+	case 308:
+		yyDollar = yyS[yypt-1 : yypt+1]
+		//line grammar.y:2135
+		{
+			yyVAL.obj = yyDollar[1].obj
+		}
+	case 309:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1541
+		//line grammar.y:2139
 		{
-			switch a := yyVAL.obj.(type) {
-			case py.String:
-				switch b := yyDollar[2].obj.(type) {
-				case py.String:
-					yyVAL.obj = a + b
-				default:
-					yylex.(*yyLex).SyntaxError("cannot mix string and nonstring literals")
-				}
-			case py.Bytes:
-				switch b := yyDollar[2].obj.(type) {
-				case py.Bytes:
-					yyVAL.obj = append(a, b...)
-				default:
-					yylex.(*yyLex).SyntaxError("cannot mix bytes and nonbytes literals")
-				}
-			}
+			yyVAL.obj = concatStrings(yylex.(*yyLex), yyDollar[1].obj, yyDollar[2].obj)
 		}
-	case 241:
+	case 310:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:2143
+		{
+			yyVAL.obj = concatStrings(yylex.(*yyLex), yyDollar[1].obj, yyDollar[2].obj)
+		}
+	case 311:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1562
+		//line grammar.y:2149
 		{
 			yyVAL.expr = &ast.Tuple{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Ctx: ast.Load}
 		}
-	case 242:
+	case 312:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1566
+		//line grammar.y:2153
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 243:
+	case 313:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1570
+		//line grammar.y:2157
 		{
 			yyVAL.expr = &ast.GeneratorExp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[2].expr, Generators: yyDollar[3].comprehensions}
 		}
-	case 244:
+	case 314:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1574
+		//line grammar.y:2161
 		{
 			yyVAL.expr = tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, yyDollar[3].comma)
 		}
-	case 245:
+	case 315:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1578
+		//line grammar.y:2165
 		{
 			yyVAL.expr = &ast.List{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Ctx: ast.Load}
 		}
-	case 246:
+	case 316:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1582
+		//line grammar.y:2169
 		{
 			yyVAL.expr = &ast.ListComp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[2].expr, Generators: yyDollar[3].comprehensions}
 		}
-	case 247:
+	case 317:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1586
+		//line grammar.y:2173
 		{
 			yyVAL.expr = &ast.List{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elts: yyDollar[2].exprs, Ctx: ast.Load}
 		}
-	case 248:
+	case 318:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1590
+		//line grammar.y:2177
 		{
 			yyVAL.expr = &ast.Dict{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
-	case 249:
+	case 319:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1594
+		//line grammar.y:2181
 		{
 			yyVAL.expr = yyDollar[2].expr
+			// The node starts with the {
+			*nodePos(yyVAL.expr) = yyVAL.pos
 		}
-	case 250:
+	case 320:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1598
+		//line grammar.y:2187
 		{
 			yyVAL.expr = &ast.Name{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Id: ast.Identifier(yyDollar[1].str), Ctx: ast.Load}
 		}
-	case 251:
+	case 321:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1602
+		//line grammar.y:2191
 		{
 			yyVAL.expr = &ast.Num{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, N: yyDollar[1].obj}
 		}
-	case 252:
+	case 322:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1606
+		//line grammar.y:2195
 		{
 			switch s := yyDollar[1].obj.(type) {
 			case py.String:
 				yyVAL.expr = &ast.Str{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, S: s}
 			case py.Bytes:
 				yyVAL.expr = &ast.Bytes{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, S: s}
+			case *ast.JoinedStr:
+				s.Pos = yyVAL.pos
+				yyVAL.expr = s
 			default:
 				panic("not Bytes or String in strings")
 			}
 		}
-	case 253:
+	case 323:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1617
+		//line grammar.y:2209
 		{
 			yyVAL.expr = &ast.Ellipsis{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
-	case 254:
+	case 324:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1621
+		//line grammar.y:2213
 		{
 			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.None}
 		}
-	case 255:
+	case 325:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1625
+		//line grammar.y:2217
 		{
 			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.True}
 		}
-	case 256:
+	case 326:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1629
+		//line grammar.y:2221
 		{
 			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.False}
 		}
-	case 257:
+	case 327:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1636
+		//line grammar.y:2228
 		{
 			yyVAL.expr = &ast.Call{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
-	case 258:
+	case 328:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1640
+		//line grammar.y:2232
 		{
 			yyVAL.expr = yyDollar[2].call
 		}
-	case 259:
+	case 329:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1644
+		//line grammar.y:2236
 		{
 			slice := yyDollar[2].slice
 			// If all items of a ExtSlice are just Index then return as tuple
@@ -2764,22 +3534,22 @@ yydefault:
 			}
 			yyVAL.expr = &ast.Subscript{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Slice: slice, Ctx: ast.Load}
 		}
-	case 260:
+	case 330:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1662
+		//line grammar.y:2254
 		{
 			yyVAL.expr = &ast.Attribute{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Attr: ast.Identifier(yyDollar[2].str), Ctx: ast.Load}
 		}
-	case 261:
+	case 331:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1668
+		//line grammar.y:2260
 		{
 			yyVAL.slice = yyDollar[1].slice
 			yyVAL.isExpr = true
 		}
-	case 262:
+	case 332:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1673
+		//line grammar.y:2265
 		{
 			if !yyDollar[1].isExpr {
 				extSlice := yyVAL.slice.(*ast.ExtSlice)
@@ -2789,9 +3559,9 @@ yydefault:
 			}
 			yyVAL.isExpr = false
 		}
-	case 263:
+	case 333:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1685
+		//line grammar.y:2277
 		{
 			if yyDollar[2].comma && yyDollar[1].isExpr {
 				yyVAL.slice = &ast.ExtSlice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Dims: []ast.Slicer{yyDollar[1].slice}}
@@ -2799,107 +3569,107 @@ yydefault:
 				yyVAL.slice = yyDollar[1].slice
 			}
 		}
-	case 264:
+	case 334:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1695
+		//line grammar.y:2287
 		{
 			yyVAL.slice = &ast.Index{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr}
 		}
-	case 265:
+	case 335:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1699
+		//line grammar.y:2291
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: nil, Step: nil}
 		}
-	case 266:
+	case 336:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1703
+		//line grammar.y:2295
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: nil, Step: yyDollar[2].expr}
 		}
-	case 267:
+	case 337:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1707
+		//line grammar.y:2299
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: yyDollar[2].expr, Step: nil}
 		}
-	case 268:
+	case 338:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1711
+		//line grammar.y:2303
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: yyDollar[2].expr, Step: yyDollar[3].expr}
 		}
-	case 269:
+	case 339:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1715
+		//line grammar.y:2307
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: nil, Step: nil}
 		}
-	case 270:
+	case 340:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1719
+		//line grammar.y:2311
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: nil, Step: yyDollar[3].expr}
 		}
-	case 271:
+	case 341:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1723
+		//line grammar.y:2315
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: yyDollar[3].expr, Step: nil}
 		}
-	case 272:
+	case 342:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1727
+		//line grammar.y:2319
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: yyDollar[3].expr, Step: yyDollar[4].expr}
 		}
-	case 273:
+	case 343:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1733
+		//line grammar.y:2325
 		{
 			yyVAL.expr = nil
 		}
-	case 274:
+	case 344:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1737
+		//line grammar.y:2329
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 275:
+	case 345:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1743
+		//line grammar.y:2335
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 276:
+	case 346:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1747
+		//line grammar.y:2339
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 277:
+	case 347:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1753
+		//line grammar.y:2345
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
-	case 278:
+	case 348:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1758
+		//line grammar.y:2350
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
-	case 279:
+	case 349:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1764
+		//line grammar.y:2356
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 			yyVAL.comma = yyDollar[2].comma
 		}
-	case 280:
+	case 350:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1771
+		//line grammar.y:2363
 		{
 			elts := yyDollar[1].exprs
 			if yyDollar[2].comma || len(elts) > 1 {
@@ -2908,28 +3678,35 @@ yydefault:
 				yyVAL.expr = elts[0]
 			}
 		}
-	case 281:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1782
-		{
-			yyVAL.exprs = yyDollar[1].exprs
-		}
-	case 282:
+	case 351:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1789
+		//line grammar.y:2375
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr, yyDollar[3].expr) // key, value order
 		}
-	case 283:
+	case 352:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:2380
+		{
+			yyVAL.exprs = nil
+			yyVAL.exprs = append(yyVAL.exprs, nil, yyDollar[2].expr) // nil key for **mapping
+		}
+	case 353:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1794
+		//line grammar.y:2385
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr, yyDollar[5].expr)
 		}
-	case 284:
+	case 354:
+		yyDollar = yyS[yypt-4 : yypt+1]
+		//line grammar.y:2389
+		{
+			yyVAL.exprs = append(yyVAL.exprs, nil, yyDollar[4].expr)
+		}
+	case 355:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1800
+		//line grammar.y:2395
 		{
 			keyValues := yyDollar[1].exprs
 			d := &ast.Dict{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Keys: nil, Values: nil}
@@ -2939,27 +3716,27 @@ yydefault:
 			}
 			yyVAL.expr = d
 		}
-	case 285:
+	case 356:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1810
+		//line grammar.y:2405
 		{
 			yyVAL.expr = &ast.DictComp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Key: yyDollar[1].expr, Value: yyDollar[3].expr, Generators: yyDollar[4].comprehensions}
 		}
-	case 286:
-		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1814
+	case 357:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:2409
 		{
 			yyVAL.expr = &ast.Set{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elts: yyDollar[1].exprs}
 		}
-	case 287:
+	case 358:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1818
+		//line grammar.y:2413
 		{
 			yyVAL.expr = &ast.SetComp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[1].expr, Generators: yyDollar[2].comprehensions}
 		}
-	case 288:
+	case 359:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1824
+		//line grammar.y:2419
 		{
 			classDef := &ast.ClassDef{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Name: ast.Identifier(yyDollar[2].str), Body: yyDollar[5].stmts}
 			yyVAL.stmt = classDef
@@ -2971,102 +3748,57 @@ yydefault:
 				classDef.Kwargs = args.Kwargs
 			}
 		}
-	case 289:
+	case 360:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1838
-		{
-			yyVAL.call = yyDollar[1].call
-		}
-	case 290:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1842
-		{
-			yyVAL.call.Args = append(yyVAL.call.Args, yyDollar[3].call.Args...)
-			yyVAL.call.Keywords = append(yyVAL.call.Keywords, yyDollar[3].call.Keywords...)
-		}
-	case 291:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1848
-		{
-			yyVAL.call = &ast.Call{}
-		}
-	case 292:
-		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1852
+		//line grammar.y:2433
 		{
 			yyVAL.call = yyDollar[1].call
 		}
-	case 293:
-		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1857
-		{
-			yyVAL.call = &ast.Call{}
-		}
-	case 294:
+	case 361:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1861
+		//line grammar.y:2437
 		{
-			yyVAL.call.Args = append(yyVAL.call.Args, yyDollar[3].call.Args...)
-			yyVAL.call.Keywords = append(yyVAL.call.Keywords, yyDollar[3].call.Keywords...)
+			addArgument(yylex, yyVAL.call, yyDollar[3].call)
 		}
-	case 295:
+	case 362:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1868
+		//line grammar.y:2443
 		{
 			yyVAL.call = yyDollar[1].call
-		}
-	case 296:
-		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1872
-		{
-			call := yyDollar[1].call
-			call.Starargs = yyDollar[3].expr
-			if len(yyDollar[4].call.Args) != 0 {
-				yylex.(*yyLex).SyntaxError("only named arguments may follow *expression")
-			}
-			call.Keywords = append(call.Keywords, yyDollar[4].call.Keywords...)
-			yyVAL.call = call
-		}
-	case 297:
-		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1882
-		{
-			call := yyDollar[1].call
-			call.Starargs = yyDollar[3].expr
-			call.Kwargs = yyDollar[7].expr
-			if len(yyDollar[4].call.Args) != 0 {
-				yylex.(*yyLex).SyntaxError("only named arguments may follow *expression")
+			if yyDollar[2].comma && yyVAL.call.Func != nil {
+				yylex.(*yyLex).SyntaxError("Generator expression must be parenthesized")
 			}
-			call.Keywords = append(call.Keywords, yyDollar[4].call.Keywords...)
-			yyVAL.call = call
-		}
-	case 298:
-		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1893
-		{
-			call := yyDollar[1].call
-			call.Kwargs = yyDollar[3].expr
-			yyVAL.call = call
+			yyVAL.call.Func = nil
+			setStarargs(yyVAL.call)
 		}
-	case 299:
+	case 363:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1903
+		//line grammar.y:2456
 		{
 			yyVAL.call = &ast.Call{}
 			yyVAL.call.Args = []ast.Expr{yyDollar[1].expr}
 		}
-	case 300:
+	case 364:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1908
+		//line grammar.y:2461
 		{
 			yyVAL.call = &ast.Call{}
-			yyVAL.call.Args = []ast.Expr{
-				&ast.GeneratorExp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[1].expr, Generators: yyDollar[2].comprehensions},
-			}
+			genexp := &ast.GeneratorExp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[1].expr, Generators: yyDollar[2].comprehensions}
+			yyVAL.call.Args = []ast.Expr{genexp}
+			// Func marks an unparenthesized generator expression which
+			// must be the only argument
+			yyVAL.call.Func = genexp
 		}
-	case 301:
+	case 365:
+		yyDollar = yyS[yypt-3 : yypt+1]
+		//line grammar.y:2470
+		{
+			yyVAL.call = &ast.Call{}
+			yyVAL.call.Args = []ast.Expr{namedExpr(yylex, yyVAL.pos, yyDollar[1].expr, yyDollar[3].expr)}
+		}
+	case 366:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1915
+		//line grammar.y:2475
 		{
 			yyVAL.call = &ast.Call{}
 			test := yyDollar[1].expr
@@ -3076,37 +3808,51 @@ yydefault:
 				yylex.(*yyLex).SyntaxError("keyword can't be an expression")
 			}
 		}
-	case 302:
+	case 367:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:2485
+		{
+			yyVAL.call = &ast.Call{}
+			yyVAL.call.Args = []ast.Expr{&ast.Starred{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[2].expr, Ctx: ast.Load}}
+		}
+	case 368:
+		yyDollar = yyS[yypt-2 : yypt+1]
+		//line grammar.y:2490
+		{
+			yyVAL.call = &ast.Call{}
+			yyVAL.call.Keywords = []*ast.Keyword{&ast.Keyword{Pos: yyVAL.pos, Value: yyDollar[2].expr}}
+		}
+	case 369:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1927
+		//line grammar.y:2497
 		{
 			yyVAL.comprehensions = yyDollar[1].comprehensions
 			yyVAL.exprs = nil
 		}
-	case 303:
+	case 370:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1932
+		//line grammar.y:2502
 		{
 			yyVAL.comprehensions = yyDollar[1].comprehensions
 			yyVAL.exprs = yyDollar[1].exprs
 		}
-	case 304:
+	case 371:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1939
+		//line grammar.y:2509
 		{
 			c := ast.Comprehension{
-				Target: tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, yyDollar[2].comma),
+				Target: tupleOrExpr(yyDollar[2].pos, yyDollar[2].exprs, yyDollar[2].comma),
 				Iter:   yyDollar[4].expr,
 			}
 			setCtx(yylex, c.Target, ast.Store)
 			yyVAL.comprehensions = []ast.Comprehension{c}
 		}
-	case 305:
+	case 372:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1948
+		//line grammar.y:2518
 		{
 			c := ast.Comprehension{
-				Target: tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, yyDollar[2].comma),
+				Target: tupleOrExpr(yyDollar[2].pos, yyDollar[2].exprs, yyDollar[2].comma),
 				Iter:   yyDollar[4].expr,
 				Ifs:    yyDollar[5].exprs,
 			}
@@ -3114,36 +3860,36 @@ yydefault:
 			yyVAL.comprehensions = []ast.Comprehension{c}
 			yyVAL.comprehensions = append(yyVAL.comprehensions, yyDollar[5].comprehensions...)
 		}
-	case 306:
+	case 373:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1961
+		//line grammar.y:2531
 		{
 			yyVAL.exprs = []ast.Expr{yyDollar[2].expr}
 			yyVAL.comprehensions = nil
 		}
-	case 307:
+	case 374:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1966
+		//line grammar.y:2536
 		{
 			yyVAL.exprs = []ast.Expr{yyDollar[2].expr}
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].exprs...)
 			yyVAL.comprehensions = yyDollar[3].comprehensions
 		}
-	case 308:
+	case 375:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1977
+		//line grammar.y:2547
 		{
 			yyVAL.expr = &ast.Yield{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
-	case 309:
+	case 376:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1981
+		//line grammar.y:2551
 		{
 			yyVAL.expr = &ast.YieldFrom{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[3].expr}
 		}
-	case 310:
+	case 377:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1985
+		//line grammar.y:2555
 		{
 			yyVAL.expr = &ast.Yield{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[2].expr}
 		}
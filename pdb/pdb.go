@@ -0,0 +1,468 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Pdb module
+//
+// A minimal debugger driven by sys.settrace.  It supports stepping
+// through code a line at a time, printing expressions and listing
+// the source.  Breakpoints aren't supported yet.
+
+package pdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+// BdbQuit is raised into the program being debugged when the user
+// quits the debugger
+var BdbQuit = py.ExceptionType.NewType("BdbQuit", "Exception to give up completely.", nil, nil)
+
+var PdbType = py.NewTypeX("Pdb", `Pdb(stdin=None, stdout=None) -> debugger
+
+stdin should have a readline() method and stdout a write() method.  They
+default to sys.stdin and sys.stdout.`, PdbNew, nil)
+
+// Pdb is the state of the debugger
+type Pdb struct {
+	Stdin  py.Object // where commands are read from or nil for sys.stdin
+	Stdout py.Object // where output is written or nil for sys.stdout
+
+	dispatch  py.Object     // the trace function
+	stopframe *py.Frame     // frame to stop in or nil to stop anywhere
+	lastcmd   string        // last command for repeating
+	lineno    int           // last line listed or 0
+	reader    *bufio.Reader // reader for a stdin without readline
+	lines     map[string][]string
+}
+
+// Type of this Pdb object
+func (p *Pdb) Type() *py.Type {
+	return PdbType
+}
+
+// NewPdb makes a new debugger reading commands from stdin and writing
+// to stdout.  Either may be nil to use sys.stdin and sys.stdout.
+func NewPdb(stdin, stdout py.Object) *Pdb {
+	p := &Pdb{
+		Stdin:  stdin,
+		Stdout: stdout,
+		lines:  make(map[string][]string),
+	}
+	p.dispatch = py.MustNewMethod("trace_dispatch", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var frame, event, arg py.Object
+		err := py.UnpackTuple(args, nil, "trace_dispatch", 3, 3, &frame, &event, &arg)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := frame.(*py.Frame)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "trace_dispatch() expects a frame, not %s", frame.Type().Name)
+		}
+		return p.traceDispatch(f, string(event.(py.String)))
+	}, 0, "trace_dispatch(frame, event, arg) -> trace function")
+	return p
+}
+
+// PdbNew makes a Pdb object from Python
+func PdbNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stdin, stdout py.Object = py.None, py.None
+	kwlist := []string{"stdin", "stdout"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:Pdb", kwlist, &stdin, &stdout)
+	if err != nil {
+		return nil, err
+	}
+	if stdin == py.None {
+		stdin = nil
+	}
+	if stdout == py.None {
+		stdout = nil
+	}
+	return NewPdb(stdin, stdout), nil
+}
+
+// Called by the vm for each trace event
+func (p *Pdb) traceDispatch(frame *py.Frame, event string) (py.Object, error) {
+	switch event {
+	case "line":
+		if p.stopHere(frame) {
+			err := p.interaction(frame)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case "call":
+		// Only trace new frames when stepping into them
+		if p.stopframe != nil {
+			return py.None, nil
+		}
+	case "return":
+		// Stop in the caller when the frame we were in returns
+		if frame == p.stopframe {
+			p.stopframe = nil
+		}
+	}
+	return p.dispatch, nil
+}
+
+// Returns whether the debugger should stop in this frame
+func (p *Pdb) stopHere(frame *py.Frame) bool {
+	return p.stopframe == nil || p.stopframe == frame
+}
+
+// SetTrace starts debugging at the next line to run in frame
+func (p *Pdb) SetTrace(frame *py.Frame) {
+	for f := frame; f != nil; f = f.Back {
+		f.Trace = p.dispatch
+	}
+	p.stopframe = nil
+	py.TraceFunc = p.dispatch
+}
+
+// Stops tracing
+func (p *Pdb) stopTrace(frame *py.Frame) {
+	py.TraceFunc = nil
+	for f := frame; f != nil; f = f.Back {
+		f.Trace = nil
+	}
+}
+
+// Writes s to the output
+func (p *Pdb) write(s string) error {
+	stdout := p.Stdout
+	if stdout == nil {
+		sys, err := py.GetModule("sys")
+		if err != nil {
+			return err
+		}
+		stdout = sys.Globals["stdout"]
+	}
+	write, err := py.GetAttrString(stdout, "write")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(write, py.Tuple{py.String(s)}, nil)
+	return err
+}
+
+// Writes a line to the output
+func (p *Pdb) message(s string) error {
+	return p.write(s + "\n")
+}
+
+// Writes an error to the output
+func (p *Pdb) printError(err error) error {
+	exc := py.MakeException(err)
+	if info, ok := err.(py.ExceptionInfo); ok {
+		if e, ok := info.Value.(*py.Exception); ok {
+			exc = e
+		}
+	}
+	msg := exc.Base.Name
+	if args, ok := exc.Args.(py.Tuple); ok && len(args) > 0 {
+		var arg py.Object = args
+		if len(args) == 1 {
+			arg = args[0]
+		}
+		s, err := py.StrAsString(arg)
+		if err != nil {
+			return err
+		}
+		msg += ": " + s
+	}
+	return p.message("*** " + msg)
+}
+
+// Reads a line of input returning io.EOF at the end
+func (p *Pdb) readline() (string, error) {
+	stdin := p.Stdin
+	if stdin == nil {
+		sys, err := py.GetModule("sys")
+		if err != nil {
+			return "", err
+		}
+		stdin = sys.Globals["stdin"]
+	}
+	readline, err := py.GetAttrString(stdin, "readline")
+	if err != nil {
+		file, ok := stdin.(*py.File)
+		if !ok {
+			return "", err
+		}
+		if p.reader == nil {
+			p.reader = bufio.NewReader(file.File)
+		}
+		line, err := p.reader.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return line, err
+	}
+	res, err := py.Call(readline, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	line, ok := res.(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "readline() should return a str, not %s", res.Type().Name)
+	}
+	if line == "" {
+		return "", io.EOF
+	}
+	return string(line), nil
+}
+
+// Returns the source lines of the file, or nil if it can't be read
+func (p *Pdb) getlines(filename string) []string {
+	lines, ok := p.lines[filename]
+	if !ok {
+		data, err := ioutil.ReadFile(filename)
+		if err == nil {
+			lines = strings.SplitAfter(string(data), "\n")
+			if lines[len(lines)-1] == "" {
+				lines = lines[:len(lines)-1]
+			}
+		}
+		p.lines[filename] = lines
+	}
+	return lines
+}
+
+// Returns source line lineno of the file or "" if not found
+func (p *Pdb) getline(filename string, lineno int) string {
+	lines := p.getlines(filename)
+	if lineno < 1 || lineno > len(lines) {
+		return ""
+	}
+	return lines[lineno-1]
+}
+
+// Prints where the frame is stopped
+func (p *Pdb) printStackEntry(frame *py.Frame) error {
+	co := frame.Code
+	err := p.message(fmt.Sprintf("> %s(%d)%s()", co.Filename, frame.Lineno, co.Name))
+	if err != nil {
+		return err
+	}
+	line := strings.TrimSpace(p.getline(co.Filename, int(frame.Lineno)))
+	if line == "" {
+		return nil
+	}
+	return p.message("-> " + line)
+}
+
+// Reads commands and acts on them until one of them resumes
+// execution
+func (p *Pdb) interaction(frame *py.Frame) error {
+	err := p.printStackEntry(frame)
+	if err != nil {
+		return err
+	}
+	p.lineno = 0
+	for {
+		err = p.write("(Pdb) ")
+		if err != nil {
+			return err
+		}
+		line, err := p.readline()
+		if err == io.EOF {
+			err = p.message("")
+			if err != nil {
+				return err
+			}
+			line = "q"
+		} else if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			line = p.lastcmd
+		} else {
+			p.lastcmd = line
+		}
+		if line == "" {
+			continue
+		}
+		cmd, arg := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			cmd, arg = line[:i], strings.TrimSpace(line[i:])
+		}
+		switch cmd {
+		case "n", "next":
+			p.stopframe = frame
+			return nil
+		case "s", "step":
+			p.stopframe = nil
+			return nil
+		case "c", "cont", "continue":
+			p.stopTrace(frame)
+			return nil
+		case "q", "quit", "exit":
+			p.stopTrace(frame)
+			return py.MakeException(BdbQuit)
+		case "p":
+			err = p.printExpr(frame, arg)
+		case "l", "list":
+			err = p.list(frame, arg)
+		default:
+			err = p.exec(frame, strings.TrimPrefix(line, "!"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Runs code compiled in mode in the frame
+func (p *Pdb) run(frame *py.Frame, source, mode string) (py.Object, error) {
+	code, err := py.Compile(source+"\n", "<stdin>", mode, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	frame.FastToLocals()
+	res, err := py.VmRun(frame.Globals, frame.Locals, code.(*py.Code), nil)
+	if err != nil {
+		return nil, err
+	}
+	frame.LocalsToFast(false)
+	return res, nil
+}
+
+// Prints the value of the expression evaluated in the frame
+func (p *Pdb) printExpr(frame *py.Frame, expr string) error {
+	res, err := p.run(frame, expr, "eval")
+	if err != nil {
+		return p.printError(err)
+	}
+	repr, err := py.ReprAsString(res)
+	if err != nil {
+		return p.printError(err)
+	}
+	return p.message(repr)
+}
+
+// Runs the statement in the frame
+func (p *Pdb) exec(frame *py.Frame, stmt string) error {
+	_, err := p.run(frame, stmt, "single")
+	if err != nil {
+		return p.printError(err)
+	}
+	return nil
+}
+
+// Lists the source around the current line or the range in arg
+func (p *Pdb) list(frame *py.Frame, arg string) error {
+	var first, last int
+	var err error
+	if arg != "" && arg != "." {
+		if i := strings.Index(arg, ","); i >= 0 {
+			first, err = strconv.Atoi(strings.TrimSpace(arg[:i]))
+			if err == nil {
+				last, err = strconv.Atoi(strings.TrimSpace(arg[i+1:]))
+			}
+			if last < first {
+				// assume it's a count
+				last = first + last
+			}
+		} else {
+			first, err = strconv.Atoi(arg)
+			first -= 5
+		}
+		if err != nil {
+			return p.message(fmt.Sprintf("*** Error in argument: %q", arg))
+		}
+	} else if p.lineno == 0 || arg == "." {
+		first = int(frame.Lineno) - 5
+	} else {
+		first = p.lineno + 1
+	}
+	if first < 1 {
+		first = 1
+	}
+	if last == 0 {
+		last = first + 10
+	}
+	lines := p.getlines(frame.Code.Filename)
+	for lineno := first; lineno <= last && lineno <= len(lines); lineno++ {
+		s := fmt.Sprintf("%3d", lineno)
+		if len(s) < 4 {
+			s += " "
+		}
+		s += " "
+		if lineno == int(frame.Lineno) {
+			s += "->"
+		}
+		err = p.message(s + "\t" + strings.TrimRight(lines[lineno-1], " \t\r\n"))
+		if err != nil {
+			return err
+		}
+	}
+	p.lineno = last
+	if len(lines) < last {
+		p.lineno = len(lines)
+		return p.message("[EOF]")
+	}
+	return nil
+}
+
+const pdb_set_trace_doc = `set_trace() -> None
+
+Enter the debugger at the calling stack frame.`
+
+func pdb_set_trace(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "set_trace", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	NewPdb(nil, nil).SetTrace(py.CurrentFrame)
+	return py.None, nil
+}
+
+const pdb_doc = `The Python Debugger Pdb
+
+To use the debugger in its simplest form:
+
+        >>> import pdb
+        >>> pdb.set_trace()
+
+The debugger's prompt is '(Pdb) '.  The commands are
+
+n(ext)      Continue until the next line in the current function is reached
+            or it returns.
+s(tep)      Execute the current line, stop at the first possible occasion.
+c(ont(inue)) Continue execution.
+p expr      Print the value of the expression.
+l(ist) [first[, last] | .]  List source code for the current file.
+q(uit)      Quit from the debugger.  The program being executed is aborted.
+
+Anything else is executed as a Python statement in the context of the
+current frame.`
+
+// Initialise the module
+func init() {
+	PdbType.Dict["set_trace"] = py.MustNewMethod("set_trace", func(self py.Object) (py.Object, error) {
+		self.(*Pdb).SetTrace(py.CurrentFrame)
+		return py.None, nil
+	}, 0, "set_trace() -> None\n\nStart debugging from the calling stack frame.")
+
+	methods := []*py.Method{
+		py.MustNewMethod("set_trace", pdb_set_trace, 0, pdb_set_trace_doc),
+	}
+	globals := py.StringDict{
+		"BdbQuit": BdbQuit,
+		"Pdb":     PdbType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "pdb",
+		Doc:     pdb_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,146 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import pdb
+import sys
+from libtest import assertRaises
+
+class Input:
+    def __init__(self, commands):
+        self.commands = commands
+    def readline(self):
+        if not self.commands:
+            return ""
+        command = self.commands[0]
+        self.commands = self.commands[1:]
+        return command + "\n"
+
+class Output:
+    def __init__(self):
+        self.text = ""
+    def write(self, s):
+        self.text += s
+
+def debugger(*commands):
+    out = Output()
+    return pdb.Pdb(stdin=Input(list(commands)), stdout=out), out
+
+def add(a, b):
+    total = a + b
+    return total
+
+def stops(out):
+    """Returns the lines the debugger stopped at"""
+    return [line[3:] for line in out.text.split("\n") if line.startswith("-> ")]
+
+doc="next"
+def f():
+    x = 1
+    y = add(x, 2)
+    z = y * 2
+    return z
+d, out = debugger("n", "c")
+d.set_trace()
+r = f()
+assert r == 6
+assert stops(out) == ["r = f()", "assert r == 6"], stops(out)
+assert sys.gettrace() is None
+
+doc="step"
+def g():
+    d.set_trace()
+    x = add(1, 2)
+    return x
+d, out = debugger("s", "s", "s", "c")
+assert g() == 3
+assert stops(out) == ["x = add(1, 2)", "total = a + b", "return total", "return x"], stops(out)
+
+doc="next out of a function"
+def h():
+    d.set_trace()
+    return 1
+d, out = debugger("n", "c")
+r = h()
+after = r
+assert after == 1
+assert stops(out) == ["return 1", "after = r"], stops(out)
+
+doc="print"
+def k():
+    a = 42
+    b = [1, 2]
+    d.set_trace()
+    return a
+d, out = debugger("p a", "p a + 1", "p b", "p missing", "c")
+assert k() == 42
+assert "(Pdb) 42\n(Pdb) 43\n(Pdb) [1, 2]\n(Pdb) *** NameError: name 'missing' is not defined\n" in out.text, out.text
+
+doc="statements change locals"
+def m():
+    a = 1
+    d.set_trace()
+    return a
+d, out = debugger("a = 99", "c")
+assert m() == 99
+
+doc="repeat last command"
+def loop():
+    d.set_trace()
+    total = 0
+    for i in range(3):
+        total += i
+    return total
+d, out = debugger("n", "", "", "", "c")
+assert loop() == 3
+assert stops(out) == ["total = 0", "for i in range(3):", "total += i", "for i in range(3):", "total += i"], stops(out)
+
+doc="list"
+def lst():
+    d.set_trace()
+    return 1
+d, out = debugger("l", "c")
+lst()
+lines = out.text.split("\n")
+listing = [line for line in lines if "\t" in line]
+assert len(listing) == 11, listing
+current = [line for line in listing if "->" in line]
+assert len(current) == 1, listing
+assert current[0].endswith("->\t    return 1"), current
+
+doc="list range"
+d, out = debugger("l 1, 3", "c")
+lst()
+assert "  1  \t# Copyright 2018 The go-python Authors.  All rights reserved.\n  2  \t# Use of" in out.text, out.text
+
+doc="quit"
+def quitter():
+    d.set_trace()
+    return 1
+d, out = debugger("q")
+assertRaises(pdb.BdbQuit, quitter)
+assert sys.gettrace() is None
+
+doc="end of input quits"
+d, out = debugger()
+assertRaises(pdb.BdbQuit, quitter)
+
+doc="settrace"
+events = []
+def tracer(frame, event, arg):
+    events.append(event)
+    return tracer
+def traced():
+    a = 1
+    return a
+sys.settrace(tracer)
+assert sys.gettrace() is tracer
+traced()
+sys.settrace(None)
+assert sys.gettrace() is None
+assert events == ["call", "line", "line", "return"], events
+
+doc="breakpoint"
+assert sys.breakpointhook is sys.__breakpointhook__
+
+doc="finished"
@@ -0,0 +1,294 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Pickle module
+//
+// This reads and writes the same pickle format as CPython so pickles
+// can be exchanged with it for the types both support.
+package pickle
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const (
+	HIGHEST_PROTOCOL = 5
+	DEFAULT_PROTOCOL = 4
+)
+
+// The pickle opcodes
+const (
+	MARK             = '('    // push special markobject on stack
+	STOP             = '.'    // every pickle ends with STOP
+	POP              = '0'    // discard topmost stack item
+	POP_MARK         = '1'    // discard stack top through topmost markobject
+	DUP              = '2'    // duplicate top stack item
+	FLOAT            = 'F'    // push float object; decimal string argument
+	INT              = 'I'    // push integer or bool; decimal string argument
+	BININT           = 'J'    // push four-byte signed int
+	BININT1          = 'K'    // push 1-byte unsigned int
+	LONG             = 'L'    // push long; decimal string argument
+	BININT2          = 'M'    // push 2-byte unsigned int
+	NONE             = 'N'    // push None
+	PERSID           = 'P'    // push persistent object; id is taken from string arg
+	BINPERSID        = 'Q'    // push persistent object; id is taken from stack
+	REDUCE           = 'R'    // apply callable to argtuple, both on stack
+	STRING           = 'S'    // push string; NL-terminated string argument
+	BINSTRING        = 'T'    // push string; counted binary string argument
+	SHORT_BINSTRING  = 'U'    // push string; counted binary string argument < 256 bytes
+	UNICODE          = 'V'    // push Unicode string; raw-unicode-escaped'd argument
+	BINUNICODE       = 'X'    // push Unicode string; counted UTF-8 string argument
+	APPEND           = 'a'    // append stack top to list below it
+	BUILD            = 'b'    // call __setstate__ or __dict__.update()
+	GLOBAL           = 'c'    // push self.find_class(modname, name); 2 string args
+	DICT             = 'd'    // build a dict from stack items
+	EMPTY_DICT       = '}'    // push empty dict
+	APPENDS          = 'e'    // extend list on stack by topmost stack slice
+	GET              = 'g'    // push item from memo on stack; index is string arg
+	BINGET           = 'h'    // push item from memo on stack; index is 1-byte arg
+	INST             = 'i'    // build & push class instance
+	LONG_BINGET      = 'j'    // push item from memo on stack; index is 4-byte arg
+	LIST             = 'l'    // build list from topmost stack items
+	EMPTY_LIST       = ']'    // push empty list
+	OBJ              = 'o'    // build & push class instance
+	PUT              = 'p'    // store stack top in memo; index is string arg
+	BINPUT           = 'q'    // store stack top in memo; index is 1-byte arg
+	LONG_BINPUT      = 'r'    // store stack top in memo; index is 4-byte arg
+	SETITEM          = 's'    // add key+value pair to dict
+	TUPLE            = 't'    // build tuple from topmost stack items
+	EMPTY_TUPLE      = ')'    // push empty tuple
+	SETITEMS         = 'u'    // modify dict by adding topmost key+value pairs
+	BINFLOAT         = 'G'    // push float; arg is 8-byte float encoding
+	PROTO            = '\x80' // identify pickle protocol
+	NEWOBJ           = '\x81' // build object by applying cls.__new__ to argtuple
+	EXT1             = '\x82' // push object from extension registry; 1-byte index
+	EXT2             = '\x83' // ditto, but 2-byte index
+	EXT4             = '\x84' // ditto, but 4-byte index
+	TUPLE1           = '\x85' // build 1-tuple from stack top
+	TUPLE2           = '\x86' // build 2-tuple from two topmost stack items
+	TUPLE3           = '\x87' // build 3-tuple from three topmost stack items
+	NEWTRUE          = '\x88' // push True
+	NEWFALSE         = '\x89' // push False
+	LONG1            = '\x8a' // push long from < 256 bytes
+	LONG4            = '\x8b' // push really big long
+	BINBYTES         = 'B'    // push bytes; counted binary string argument
+	SHORT_BINBYTES   = 'C'    // push bytes; counted binary string argument < 256 bytes
+	SHORT_BINUNICODE = '\x8c' // push short string; UTF-8 length < 256 bytes
+	BINUNICODE8      = '\x8d' // push very long string
+	BINBYTES8        = '\x8e' // push very long bytes string
+	EMPTY_SET        = '\x8f' // push empty set on the stack
+	ADDITEMS         = '\x90' // modify set by adding topmost stack items
+	FROZENSET        = '\x91' // build frozenset from topmost stack items
+	NEWOBJ_EX        = '\x92' // like NEWOBJ but work with keyword only arguments
+	STACK_GLOBAL     = '\x93' // same as GLOBAL but using names on the stacks
+	MEMOIZE          = '\x94' // store top of the stack in memo
+	FRAME            = '\x95' // indicate the beginning of a new frame
+	BYTEARRAY8       = '\x96' // push bytearray
+	NEXT_BUFFER      = '\x97' // push next out-of-band buffer
+	READONLY_BUFFER  = '\x98' // make top of stack readonly
+)
+
+var (
+	PickleError     = py.ExceptionType.NewType("PickleError", "", nil, nil)
+	PicklingError   = PickleError.NewType("PicklingError", "", nil, nil)
+	UnpicklingError = PickleError.NewType("UnpicklingError", "", nil, nil)
+)
+
+// Returns the protocol to use from the protocol argument
+func getProtocol(protocol py.Object) (int, error) {
+	if protocol == nil || protocol == py.None {
+		return DEFAULT_PROTOCOL, nil
+	}
+	proto, err := py.IndexInt(protocol)
+	if err != nil {
+		return 0, err
+	}
+	if proto < 0 {
+		return HIGHEST_PROTOCOL, nil
+	}
+	if proto > HIGHEST_PROTOCOL {
+		return 0, py.ExceptionNewf(py.ValueError, "pickle protocol must be <= %d", HIGHEST_PROTOCOL)
+	}
+	return proto, nil
+}
+
+// Reads the keyword only arguments to the load functions
+func getLoadOptions(name string, kwargs py.StringDict) (fixImports bool, encoding string, err error) {
+	var fixImportsObj py.Object = py.True
+	var encodingObj py.Object = py.String("ASCII")
+	var errorsObj py.Object = py.String("strict")
+	var buffers py.Object = py.None
+	err = py.ParseTupleAndKeywords(nil, kwargs, "|$OOOO:"+name, []string{"fix_imports", "encoding", "errors", "buffers"}, &fixImportsObj, &encodingObj, &errorsObj, &buffers)
+	if err != nil {
+		return false, "", err
+	}
+	encodingStr, ok := encodingObj.(py.String)
+	if !ok {
+		return false, "", py.ExceptionNewf(py.TypeError, "%s() argument 'encoding' must be str, not %s", name, encodingObj.Type().Name)
+	}
+	return py.ObjectIsTrue(fixImportsObj), string(encodingStr), nil
+}
+
+const dumps_doc = `dumps(obj, protocol=None, *, fix_imports=True)
+
+Return the pickled representation of the object as a bytes object.
+
+The optional *protocol* argument tells the pickler to use the given
+protocol; supported protocols are 0 to 5.  The default protocol is 4.
+Specifying a negative protocol version selects the highest protocol
+version supported.
+
+If *fix_imports* is True and *protocol* is less than 3, pickle will
+try to map the new Python 3 names to the old module names used in
+Python 2, so that the pickle data stream is readable with Python 2.`
+
+func pickle_dumps(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	var protocol py.Object = py.None
+	var fixImports py.Object = py.True
+	var bufferCallback py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOO:dumps", []string{"obj", "protocol", "fix_imports", "buffer_callback"}, &obj, &protocol, &fixImports, &bufferCallback)
+	if err != nil {
+		return nil, err
+	}
+	proto, err := getProtocol(protocol)
+	if err != nil {
+		return nil, err
+	}
+	return dumps(obj, proto, py.ObjectIsTrue(fixImports))
+}
+
+const dump_doc = `dump(obj, file, protocol=None, *, fix_imports=True)
+
+Write a pickled representation of obj to the open file object file.
+
+This is equivalent to file.write(dumps(obj, protocol)).`
+
+func pickle_dump(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj, file py.Object
+	var protocol py.Object = py.None
+	var fixImports py.Object = py.True
+	var bufferCallback py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|OOO:dump", []string{"obj", "file", "protocol", "fix_imports", "buffer_callback"}, &obj, &file, &protocol, &fixImports, &bufferCallback)
+	if err != nil {
+		return nil, err
+	}
+	proto, err := getProtocol(protocol)
+	if err != nil {
+		return nil, err
+	}
+	write, err := py.GetAttrString(file, "write")
+	if err != nil {
+		return nil, py.ExceptionNewf(py.TypeError, "file must have a 'write' attribute")
+	}
+	data, err := dumps(obj, proto, py.ObjectIsTrue(fixImports))
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.Call(write, py.Tuple{data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+const loads_doc = `loads(data, /, *, fix_imports=True, encoding="ASCII", errors="strict")
+
+Read and return an object from the given pickle data.
+
+The protocol version of the pickle is detected automatically.  Bytes
+past the pickled representation of the object are ignored.
+
+The *encoding* tells pickle how to decode 8-bit string instances
+pickled by Python 2; it can be 'ASCII', 'latin1', 'utf-8' or 'bytes'
+to read them as bytes objects.`
+
+func pickle_loads(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var data py.Object
+	err := py.UnpackTuple(args, nil, "loads", 1, 1, &data)
+	if err != nil {
+		return nil, err
+	}
+	fixImports, encoding, err := getLoadOptions("loads", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := data.(py.Bytes)
+	if !ok {
+		if _, ok := data.(py.String); ok {
+			return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not 'str'")
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", data.Type().Name)
+	}
+	return loads(&bytesInput{data: b}, fixImports, encoding)
+}
+
+const load_doc = `load(file, *, fix_imports=True, encoding="ASCII", errors="strict")
+
+Read and return an object from the pickle data stored in a file.
+
+The file must have a read() method that takes an integer argument and
+a readline() method that requires no arguments.  Both methods should
+return bytes.  Only the bytes of the pickle are read from the file.`
+
+func pickle_load(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var file py.Object
+	err := py.UnpackTuple(args, nil, "load", 1, 1, &file)
+	if err != nil {
+		return nil, err
+	}
+	fixImports, encoding, err := getLoadOptions("load", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	read, err := py.GetAttrString(file, "read")
+	if err != nil {
+		return nil, py.ExceptionNewf(py.TypeError, "file must have 'read' and 'readline' attributes")
+	}
+	readline, err := py.GetAttrString(file, "readline")
+	if err != nil {
+		return nil, py.ExceptionNewf(py.TypeError, "file must have 'read' and 'readline' attributes")
+	}
+	return loads(&fileInput{readFn: read, readlineFn: readline}, fixImports, encoding)
+}
+
+const module_doc = `Create portable serialized representations of Python objects.
+
+The format is the same as CPython's so objects can be exchanged with
+it.  None, bools, ints, floats, complex numbers, strings, bytes,
+tuples, lists, dicts, sets, frozensets, classes and functions which
+can be found by name, and instances of classes can be pickled.
+
+Functions:
+
+dump() -- write a pickled representation of an object to a file
+dumps() -- return a pickled representation of an object as bytes
+load() -- read an object from a pickle in a file
+loads() -- read an object from a pickle in a bytes object`
+
+// Initialise the module
+func init() {
+	for _, t := range []*py.Type{PickleError, PicklingError, UnpicklingError} {
+		t.Dict["__module__"] = py.String("pickle")
+	}
+	methods := []*py.Method{
+		py.MustNewMethod("dump", pickle_dump, 0, dump_doc),
+		py.MustNewMethod("dumps", pickle_dumps, 0, dumps_doc),
+		py.MustNewMethod("load", pickle_load, 0, load_doc),
+		py.MustNewMethod("loads", pickle_loads, 0, loads_doc),
+	}
+	globals := py.StringDict{
+		"HIGHEST_PROTOCOL": py.Int(HIGHEST_PROTOCOL),
+		"DEFAULT_PROTOCOL": py.Int(DEFAULT_PROTOCOL),
+		"PickleError":      PickleError,
+		"PicklingError":    PicklingError,
+		"UnpicklingError":  UnpicklingError,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "pickle",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
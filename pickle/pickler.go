@@ -0,0 +1,1060 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Write pickles
+
+package pickle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+const (
+	batchSize        = 1000      // items written between MARKs
+	frameSizeTarget  = 64 * 1024 // frames are committed when they get this big
+	frameSizeMin     = 4         // smaller frames aren't worth the header
+	frameHeaderSize  = 9         // FRAME opcode and 8 byte length
+	maxPickleDepth   = 1000      // deepest nesting of objects allowed
+	maxShortBinBytes = 255       // longest SHORT_BINBYTES or SHORT_BINUNICODE
+)
+
+// The names written in place of the Python 3 ones when fix_imports
+// is set and the protocol is less than 3
+var reverseImportMapping = map[string]string{
+	"builtins": "__builtin__",
+	"copyreg":  "copy_reg",
+}
+
+var reverseNameMapping = map[[2]string][2]string{
+	{"builtins", "range"}:        {"__builtin__", "xrange"},
+	{"functools", "reduce"}:      {"__builtin__", "reduce"},
+	{"sys", "intern"}:            {"__builtin__", "intern"},
+	{"builtins", "chr"}:          {"__builtin__", "unichr"},
+	{"builtins", "str"}:          {"__builtin__", "unicode"},
+	{"builtins", "int"}:          {"__builtin__", "long"},
+	{"builtins", "zip"}:          {"itertools", "izip"},
+	{"builtins", "map"}:          {"itertools", "imap"},
+	{"builtins", "filter"}:       {"itertools", "ifilter"},
+	{"itertools", "filterfalse"}: {"itertools", "ifilterfalse"},
+	{"itertools", "zip_longest"}: {"itertools", "izip_longest"},
+}
+
+// Python 2 kept these exceptions in the exceptions module
+var python2Exceptions = []string{
+	"ArithmeticError", "AssertionError", "AttributeError",
+	"BaseException", "BufferError", "BytesWarning", "DeprecationWarning",
+	"EOFError", "EnvironmentError", "Exception", "FloatingPointError",
+	"FutureWarning", "GeneratorExit", "IOError", "ImportError",
+	"ImportWarning", "IndentationError", "IndexError", "KeyError",
+	"KeyboardInterrupt", "LookupError", "MemoryError", "NameError",
+	"NotImplementedError", "OSError", "OverflowError",
+	"PendingDeprecationWarning", "ReferenceError", "RuntimeError",
+	"RuntimeWarning", "StopIteration", "SyntaxError", "SyntaxWarning",
+	"SystemError", "SystemExit", "TabError", "TypeError",
+	"UnboundLocalError", "UnicodeDecodeError", "UnicodeEncodeError",
+	"UnicodeError", "UnicodeTranslateError", "UnicodeWarning",
+	"UserWarning", "ValueError", "Warning", "ZeroDivisionError",
+}
+
+func init() {
+	for _, name := range python2Exceptions {
+		reverseNameMapping[[2]string{"builtins", name}] = [2]string{"exceptions", name}
+	}
+}
+
+// memoKey identifies the objects which aren't comparable, like
+// tuples and bytes, by the address and length of their data
+type memoKey struct {
+	kind reflect.Kind
+	data uintptr
+	len  int
+}
+
+// pickler writes the pickle of an object
+type pickler struct {
+	out        []byte
+	proto      int
+	fixImports bool
+	memo       map[interface{}]int
+	memoized   []py.Object // keeps the objects in memo keys alive
+	frameStart int         // start of the current frame or -1 if none
+	depth      int
+}
+
+// Returns the pickle of obj using protocol proto
+func dumps(obj py.Object, proto int, fixImports bool) (py.Object, error) {
+	p := &pickler{
+		proto:      proto,
+		fixImports: fixImports,
+		memo:       map[interface{}]int{},
+		frameStart: -1,
+	}
+	if p.proto >= 2 {
+		p.out = append(p.out, PROTO, byte(p.proto))
+	}
+	err := p.save(obj)
+	if err != nil {
+		return nil, err
+	}
+	p.write(STOP)
+	p.commitFrame()
+	return py.Bytes(p.out), nil
+}
+
+// Writes opcodes and their arguments, starting a frame if needed
+func (p *pickler) write(data ...byte) {
+	if p.proto >= 4 && p.frameStart < 0 {
+		p.frameStart = len(p.out)
+		p.out = append(p.out, make([]byte, frameHeaderSize)...)
+	}
+	p.out = append(p.out, data...)
+}
+
+// Writes a string without copying it via a byte slice
+func (p *pickler) writeString(s string) {
+	p.write()
+	p.out = append(p.out, s...)
+}
+
+// Writes a payload following its opcode and length header
+//
+// Big payloads are written outside the frames
+func (p *pickler) writeLarge(header []byte, payload string) {
+	p.write(header...)
+	if p.proto >= 4 && len(payload) >= frameSizeTarget {
+		p.commitFrame()
+		p.out = append(p.out, payload...)
+		return
+	}
+	p.writeString(payload)
+}
+
+// Finishes the current frame, writing its header
+func (p *pickler) commitFrame() {
+	if p.frameStart < 0 {
+		return
+	}
+	start := p.frameStart
+	p.frameStart = -1
+	frameLen := len(p.out) - start - frameHeaderSize
+	if frameLen < frameSizeMin {
+		// Too small to be worth framing so remove the header
+		p.out = append(p.out[:start], p.out[start+frameHeaderSize:]...)
+		return
+	}
+	p.out[start] = FRAME
+	binary.LittleEndian.PutUint64(p.out[start+1:start+frameHeaderSize], uint64(frameLen))
+}
+
+// Called between opcodes to start a new frame if this one is big
+func (p *pickler) opcodeBoundary() {
+	if p.frameStart >= 0 && len(p.out)-p.frameStart-frameHeaderSize >= frameSizeTarget {
+		p.commitFrame()
+	}
+}
+
+// Returns a 4 byte little endian length
+func uint32Bytes(n int) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(n))
+	return b[:]
+}
+
+// Returns an 8 byte little endian length
+func uint64Bytes(n int) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(n))
+	return b[:]
+}
+
+// Returns the key to memoize obj under or false if it can't be
+// memoized
+func getMemoKey(obj py.Object) (interface{}, bool) {
+	switch x := obj.(type) {
+	case py.String, py.Complex:
+		return x, true
+	case py.Tuple, py.Bytes, py.StringDict:
+		v := reflect.ValueOf(x)
+		return memoKey{kind: v.Kind(), data: v.Pointer(), len: v.Len()}, true
+	}
+	if reflect.ValueOf(obj).Kind() == reflect.Ptr {
+		return obj, true
+	}
+	return nil, false
+}
+
+// Returns the memo index of obj if it has been memoized
+func (p *pickler) memoGet(obj py.Object) (int, bool) {
+	key, ok := getMemoKey(obj)
+	if !ok {
+		return 0, false
+	}
+	i, ok := p.memo[key]
+	return i, ok
+}
+
+// Writes the opcode to push memo entry i
+func (p *pickler) writeGet(i int) {
+	switch {
+	case p.proto == 0:
+		p.writeString(fmt.Sprintf("%c%d\n", GET, i))
+	case i < 256:
+		p.write(BINGET, byte(i))
+	default:
+		p.write(LONG_BINGET)
+		p.write(uint32Bytes(i)...)
+	}
+}
+
+// Stores the object on the top of the stack in the memo
+func (p *pickler) memoPut(obj py.Object) {
+	key, ok := getMemoKey(obj)
+	if !ok {
+		return
+	}
+	i := len(p.memo)
+	p.memo[key] = i
+	p.memoized = append(p.memoized, obj)
+	switch {
+	case p.proto >= 4:
+		p.write(MEMOIZE)
+	case p.proto == 0:
+		p.writeString(fmt.Sprintf("%c%d\n", PUT, i))
+	case i < 256:
+		p.write(BINPUT, byte(i))
+	default:
+		p.write(LONG_BINPUT)
+		p.write(uint32Bytes(i)...)
+	}
+}
+
+// Writes obj to the pickle
+func (p *pickler) save(obj py.Object) error {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxPickleDepth {
+		return py.ExceptionNewf(py.RuntimeError, "maximum recursion depth exceeded while pickling an object")
+	}
+	err := p.saveObject(obj)
+	if err != nil {
+		return err
+	}
+	p.opcodeBoundary()
+	return nil
+}
+
+// Writes obj choosing the opcodes by its type
+func (p *pickler) saveObject(obj py.Object) error {
+	switch x := obj.(type) {
+	case py.NoneType:
+		p.write(NONE)
+		return nil
+	case py.Bool:
+		p.saveBool(bool(x))
+		return nil
+	case py.Int:
+		p.saveInt(big.NewInt(int64(x)))
+		return nil
+	case *py.BigInt:
+		p.saveInt((*big.Int)(x))
+		return nil
+	case py.Float:
+		p.saveFloat(float64(x))
+		return nil
+	}
+
+	if i, ok := p.memoGet(obj); ok {
+		p.writeGet(i)
+		return nil
+	}
+
+	switch x := obj.(type) {
+	case py.Complex:
+		return p.saveReduce(py.ComplexType, py.Tuple{py.Float(real(x)), py.Float(imag(x))}, obj)
+	case py.String:
+		p.saveString(string(x))
+		return nil
+	case py.Bytes:
+		return p.saveBytes(x)
+	case py.Tuple:
+		return p.saveTuple(x)
+	case *py.List:
+		return p.saveList(x)
+	case *py.Dict:
+		return p.saveDict(x, x.Items())
+	case py.StringDict:
+		return p.saveDict(x, x.Items())
+	case *py.FrozenSet:
+		return p.saveFrozenSet(x)
+	case *py.Set:
+		return p.saveSet(x)
+	case *py.Function, *py.Method:
+		if names, ok := internalGlobals[obj]; ok {
+			err := p.writeGlobal(names[0], names[1])
+			if err != nil {
+				return err
+			}
+			p.memoPut(obj)
+			return nil
+		}
+		return p.saveGlobal(obj, "")
+	}
+	if obj == py.Ellipsis {
+		return p.saveGlobal(obj, "Ellipsis")
+	}
+	if obj == py.NotImplemented {
+		return p.saveGlobal(obj, "NotImplemented")
+	}
+	if obj.Type().IsSubtype(py.TypeType) {
+		return p.saveGlobal(obj, "")
+	}
+	return p.saveInstance(obj)
+}
+
+func (p *pickler) saveBool(x bool) {
+	switch {
+	case p.proto >= 2 && x:
+		p.write(NEWTRUE)
+	case p.proto >= 2:
+		p.write(NEWFALSE)
+	case x:
+		p.writeString("I01\n")
+	default:
+		p.writeString("I00\n")
+	}
+}
+
+func (p *pickler) saveInt(x *big.Int) {
+	if x.IsInt64() && x.Int64() >= math.MinInt32 && x.Int64() <= math.MaxInt32 {
+		n := x.Int64()
+		switch {
+		case p.proto == 0:
+			p.writeString(fmt.Sprintf("%c%d\n", INT, n))
+		case n >= 0 && n <= 0xff:
+			p.write(BININT1, byte(n))
+		case n >= 0 && n <= 0xffff:
+			p.write(BININT2, byte(n), byte(n>>8))
+		default:
+			p.write(BININT)
+			p.write(uint32Bytes(int(n))...)
+		}
+		return
+	}
+	if p.proto < 2 {
+		p.writeString(fmt.Sprintf("%c%sL\n", LONG, x.String()))
+		return
+	}
+	data := encodeLong(x)
+	if len(data) < 256 {
+		p.write(LONG1, byte(len(data)))
+	} else {
+		p.write(LONG4)
+		p.write(uint32Bytes(len(data))...)
+	}
+	p.write(data...)
+}
+
+// Returns the shortest little endian two's complement encoding of x
+func encodeLong(x *big.Int) []byte {
+	if x.Sign() == 0 {
+		return nil
+	}
+	n := x.BitLen()/8 + 1
+	v := new(big.Int).Set(x)
+	if x.Sign() < 0 {
+		// Two's complement by adding 2**(8*n)
+		v.Add(v, new(big.Int).Lsh(big.NewInt(1), uint(8*n)))
+	}
+	be := v.Bytes()
+	data := make([]byte, n)
+	for i, c := range be {
+		data[len(be)-1-i] = c
+	}
+	if x.Sign() < 0 && n > 1 && data[n-1] == 0xff && data[n-2]&0x80 != 0 {
+		data = data[:n-1]
+	}
+	return data
+}
+
+func (p *pickler) saveFloat(x float64) {
+	if p.proto == 0 {
+		p.writeString(fmt.Sprintf("%c%s\n", FLOAT, floatRepr(x)))
+		return
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(x))
+	p.write(BINFLOAT)
+	p.write(b[:]...)
+}
+
+// Returns x formatted the same as repr(x)
+func floatRepr(x float64) string {
+	switch {
+	case math.IsInf(x, 1):
+		return "inf"
+	case math.IsInf(x, -1):
+		return "-inf"
+	case math.IsNaN(x):
+		return "nan"
+	}
+	s := strconv.FormatFloat(x, 'r', -1, 64)
+	exp := 0
+	if x != 0 {
+		exp = int(math.Floor(math.Log10(math.Abs(x))))
+	}
+	if exp < -4 || exp >= 16 {
+		s = strconv.FormatFloat(x, 'e', -1, 64)
+		mantissa, exponent := s[:strings.IndexByte(s, 'e')], s[strings.IndexByte(s, 'e')+1:]
+		sign := exponent[0]
+		exponent = strings.TrimLeft(exponent[1:], "0")
+		if len(exponent) < 2 {
+			exponent = strings.Repeat("0", 2-len(exponent)) + exponent
+		}
+		return mantissa + "e" + string(sign) + exponent
+	}
+	s = strconv.FormatFloat(x, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+func (p *pickler) saveString(s string) {
+	if p.proto == 0 {
+		p.write(UNICODE)
+		p.writeString(rawUnicodeEscape(s))
+		p.write('\n')
+	} else if p.proto >= 4 && len(s) <= maxShortBinBytes {
+		p.write(SHORT_BINUNICODE, byte(len(s)))
+		p.writeString(s)
+	} else if len(s) <= math.MaxUint32 {
+		p.writeLarge(append([]byte{BINUNICODE}, uint32Bytes(len(s))...), s)
+	} else {
+		p.writeLarge(append([]byte{BINUNICODE8}, uint64Bytes(len(s))...), s)
+	}
+	p.memoPut(py.String(s))
+}
+
+// Escapes s for the UNICODE opcode
+func rawUnicodeEscape(s string) string {
+	var out strings.Builder
+	for _, c := range s {
+		switch {
+		case c >= 0x10000:
+			fmt.Fprintf(&out, "\\U%08x", c)
+		case c >= 0x100 || c == '\\' || c == 0 || c == '\n' || c == '\r' || c == 0x1a:
+			fmt.Fprintf(&out, "\\u%04x", c)
+		default:
+			out.WriteByte(byte(c))
+		}
+	}
+	return out.String()
+}
+
+func (p *pickler) saveBytes(b py.Bytes) error {
+	if p.proto < 3 {
+		// Python 2 has no bytes so pickle a call which makes them
+		if len(b) == 0 {
+			return p.saveReduce(py.BytesType, py.Tuple{}, b)
+		}
+		var s strings.Builder
+		for _, c := range b {
+			s.WriteRune(rune(c))
+		}
+		return p.saveReduce(codecsEncode, py.Tuple{py.String(s.String()), py.String("latin1")}, b)
+	}
+	if len(b) <= maxShortBinBytes {
+		p.write(SHORT_BINBYTES, byte(len(b)))
+		p.write(b...)
+	} else if len(b) <= math.MaxUint32 {
+		p.writeLarge(append([]byte{BINBYTES}, uint32Bytes(len(b))...), string(b))
+	} else {
+		p.writeLarge(append([]byte{BINBYTES8}, uint64Bytes(len(b))...), string(b))
+	}
+	p.memoPut(b)
+	return nil
+}
+
+func (p *pickler) saveTuple(t py.Tuple) error {
+	if len(t) == 0 {
+		if p.proto == 0 {
+			p.write(MARK, TUPLE)
+		} else {
+			p.write(EMPTY_TUPLE)
+		}
+		return nil
+	}
+	if p.proto >= 2 && len(t) <= 3 {
+		for _, item := range t {
+			err := p.save(item)
+			if err != nil {
+				return err
+			}
+		}
+		// The tuple may have been written while writing its
+		// items if it is recursive
+		if i, ok := p.memoGet(t); ok {
+			for range t {
+				p.write(POP)
+			}
+			p.writeGet(i)
+			return nil
+		}
+		p.write([]byte{TUPLE1, TUPLE2, TUPLE3}[len(t)-1])
+		p.memoPut(t)
+		return nil
+	}
+	p.write(MARK)
+	for _, item := range t {
+		err := p.save(item)
+		if err != nil {
+			return err
+		}
+	}
+	if i, ok := p.memoGet(t); ok {
+		if p.proto == 0 {
+			for i := 0; i <= len(t); i++ {
+				p.write(POP)
+			}
+		} else {
+			p.write(POP_MARK)
+		}
+		p.writeGet(i)
+		return nil
+	}
+	p.write(TUPLE)
+	p.memoPut(t)
+	return nil
+}
+
+func (p *pickler) saveList(l *py.List) error {
+	if p.proto == 0 {
+		p.write(MARK, LIST)
+	} else {
+		p.write(EMPTY_LIST)
+	}
+	p.memoPut(l)
+	return p.batchAppends(l.Items)
+}
+
+// Writes the items for APPEND or APPENDS to add to the list on the
+// top of the stack
+func (p *pickler) batchAppends(items []py.Object) error {
+	if p.proto == 0 || len(items) == 1 {
+		for _, item := range items {
+			err := p.save(item)
+			if err != nil {
+				return err
+			}
+			p.write(APPEND)
+		}
+		return nil
+	}
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		p.write(MARK)
+		for _, item := range items[start:end] {
+			err := p.save(item)
+			if err != nil {
+				return err
+			}
+		}
+		p.write(APPENDS)
+	}
+	return nil
+}
+
+func (p *pickler) saveDict(d py.Object, items []py.Tuple) error {
+	if p.proto == 0 {
+		p.write(MARK, DICT)
+	} else {
+		p.write(EMPTY_DICT)
+	}
+	p.memoPut(d)
+	return p.batchSetItems(items)
+}
+
+// Writes the key value pairs for SETITEM or SETITEMS to add to the
+// dict on the top of the stack
+func (p *pickler) batchSetItems(items []py.Tuple) error {
+	savePair := func(item py.Tuple) error {
+		err := p.save(item[0])
+		if err != nil {
+			return err
+		}
+		return p.save(item[1])
+	}
+	if p.proto == 0 || len(items) == 1 {
+		for _, item := range items {
+			err := savePair(item)
+			if err != nil {
+				return err
+			}
+			p.write(SETITEM)
+		}
+		return nil
+	}
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		p.write(MARK)
+		for _, item := range items[start:end] {
+			err := savePair(item)
+			if err != nil {
+				return err
+			}
+		}
+		p.write(SETITEMS)
+	}
+	return nil
+}
+
+func (p *pickler) saveSet(s *py.Set) error {
+	if p.proto < 4 {
+		return p.saveReduce(py.SetType, py.Tuple{py.NewListFromItems(s.Items())}, s)
+	}
+	p.write(EMPTY_SET)
+	p.memoPut(s)
+	items := s.Items()
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		p.write(MARK)
+		for _, item := range items[start:end] {
+			err := p.save(item)
+			if err != nil {
+				return err
+			}
+		}
+		p.write(ADDITEMS)
+	}
+	return nil
+}
+
+func (p *pickler) saveFrozenSet(s *py.FrozenSet) error {
+	if p.proto < 4 {
+		return p.saveReduce(py.FrozenSetType, py.Tuple{py.NewListFromItems(s.Items())}, s)
+	}
+	p.write(MARK)
+	for _, item := range s.Items() {
+		err := p.save(item)
+		if err != nil {
+			return err
+		}
+	}
+	if i, ok := p.memoGet(s); ok {
+		p.write(POP_MARK)
+		p.writeGet(i)
+		return nil
+	}
+	p.write(FROZENSET)
+	p.memoPut(s)
+	return nil
+}
+
+// Returns the __module__ and __qualname__ of obj
+func globalNames(obj py.Object) (module py.Object, qualname string, err error) {
+	module = py.None
+	switch x := obj.(type) {
+	case *py.Type:
+		qualname = x.Qualname
+		if qualname == "" {
+			qualname = x.Name
+		}
+		if m, ok := x.Dict["__module__"]; ok {
+			module = m
+		} else if x.Flags&py.TPFLAGS_HEAPTYPE == 0 {
+			module = py.String("builtins")
+			// Some built in types have a different name
+			// internally, eg complex
+			for name, obj := range py.CurrentContext.Builtins().Globals {
+				if obj == py.Object(x) {
+					qualname = name
+					break
+				}
+			}
+		}
+	case *py.Function:
+		module = x.Module
+		qualname = x.Qualname
+	case *py.Method:
+		qualname = x.Name
+	default:
+		qualnameObj, err := py.GetAttrString(obj, "__qualname__")
+		if err != nil {
+			return nil, "", err
+		}
+		s, ok := qualnameObj.(py.String)
+		if !ok {
+			return nil, "", py.ExceptionNewf(py.TypeError, "__qualname__ must be a str")
+		}
+		qualname = string(s)
+		if m, err := py.GetAttrString(obj, "__module__"); err == nil {
+			module = m
+		}
+	}
+	return module, qualname, nil
+}
+
+// Returns the name of the module obj called name can be found in
+func whichModule(obj py.Object, name string) string {
+	ctx := py.CurrentContext
+	if builtins := ctx.Builtins(); builtins.Globals[name] == obj {
+		return builtins.Name
+	}
+	for moduleName, m := range ctx.Modules {
+		if m, ok := m.(*py.Module); ok && moduleName != "__main__" && m.Globals[name] == obj {
+			return moduleName
+		}
+	}
+	return "__main__"
+}
+
+// Looks up the dotted name in module
+func getAttribute(obj py.Object, names []string) (py.Object, error) {
+	var err error
+	for _, name := range names {
+		obj, err = py.GetAttrString(obj, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// Writes obj as a reference to it by its module and name
+//
+// If name is empty then the __qualname__ of obj is used
+func (p *pickler) saveGlobal(obj py.Object, name string) error {
+	var moduleObj py.Object = py.None
+	qualname := name
+	if qualname != "" {
+		if module, err := py.GetAttrString(obj, "__module__"); err == nil {
+			moduleObj = module
+		}
+	} else {
+		var err error
+		moduleObj, qualname, err = globalNames(obj)
+		if err != nil {
+			return err
+		}
+	}
+	var moduleName string
+	if s, ok := moduleObj.(py.String); ok {
+		moduleName = string(s)
+	} else {
+		moduleName = whichModule(obj, qualname)
+	}
+	objRepr, err := py.ReprAsString(obj)
+	if err != nil {
+		return err
+	}
+	names := strings.Split(qualname, ".")
+	for _, name := range names {
+		if name == "<locals>" {
+			return py.ExceptionNewf(py.AttributeError, "Can't pickle local object '%s'", qualname)
+		}
+	}
+	module, err := py.ImportModuleLevelObject(moduleName, nil, nil, nil, 0)
+	if err != nil {
+		return py.ExceptionNewf(PicklingError, "Can't pickle %s: import of module '%s' failed", objRepr, moduleName)
+	}
+	found, err := getAttribute(module, names)
+	if err != nil {
+		return py.ExceptionNewf(PicklingError, "Can't pickle %s: attribute lookup %s on %s failed", objRepr, qualname, moduleName)
+	}
+	if found != obj {
+		return py.ExceptionNewf(PicklingError, "Can't pickle %s: it's not the same object as %s.%s", objRepr, moduleName, qualname)
+	}
+
+	if p.proto >= 4 {
+		p.saveString(moduleName)
+		p.saveString(qualname)
+		p.write(STACK_GLOBAL)
+	} else if len(names) > 1 {
+		// Python before 3.4 can't look up dotted names so
+		// pickle getattr(parent, name)
+		parent, err := getAttribute(module, names[:len(names)-1])
+		if err != nil {
+			return err
+		}
+		getattr, err := py.GetAttrString(py.CurrentContext.Builtins(), "getattr")
+		if err != nil {
+			return err
+		}
+		return p.saveReduce(getattr, py.Tuple{parent, py.String(names[len(names)-1])}, obj)
+	} else {
+		err = p.writeGlobal(moduleName, qualname)
+		if err != nil {
+			return err
+		}
+	}
+	p.memoPut(obj)
+	return nil
+}
+
+// Writes a GLOBAL opcode, using the Python 2 names if required
+func (p *pickler) writeGlobal(module, name string) error {
+	if p.proto < 3 && p.fixImports {
+		if mapped, ok := reverseNameMapping[[2]string{module, name}]; ok {
+			module, name = mapped[0], mapped[1]
+		} else if mapped, ok := reverseImportMapping[module]; ok {
+			module = mapped
+		}
+	}
+	if p.proto < 3 && (!isASCII(module) || !isASCII(name)) {
+		return py.ExceptionNewf(PicklingError, "can't pickle global identifier '%s.%s' using pickle protocol %d", module, name, p.proto)
+	}
+	p.write(GLOBAL)
+	p.writeString(module + "\n" + name + "\n")
+	return nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Writes the opcodes to call fn with args to make obj
+func (p *pickler) saveReduce(fn py.Object, args py.Tuple, obj py.Object) error {
+	err := p.save(fn)
+	if err != nil {
+		return err
+	}
+	err = p.save(args)
+	if err != nil {
+		return err
+	}
+	p.write(REDUCE)
+	if obj != nil {
+		if i, ok := p.memoGet(obj); ok {
+			// obj was made while pickling the args
+			p.write(POP)
+			p.writeGet(i)
+		} else {
+			p.memoPut(obj)
+		}
+	}
+	return nil
+}
+
+// Writes the state of an instance for BUILD
+func (p *pickler) saveState(state py.Object) error {
+	if state == nil || state == py.None {
+		return nil
+	}
+	err := p.save(state)
+	if err != nil {
+		return err
+	}
+	p.write(BUILD)
+	return nil
+}
+
+// Calls the method name of obj if it is defined in Python
+//
+// Returns nil if obj doesn't have the method
+func callPythonMethod(obj py.Object, name string, args ...py.Object) (py.Object, error) {
+	method := obj.Type().Lookup(name)
+	if method == nil {
+		return nil, nil
+	}
+	if _, ok := method.(*py.Function); !ok {
+		return nil, nil
+	}
+	return py.Call(method, append(py.Tuple{obj}, args...), nil)
+}
+
+// Writes an instance of a class
+func (p *pickler) saveInstance(obj py.Object) error {
+	reduced, err := callPythonMethod(obj, "__reduce_ex__", py.Int(p.proto))
+	if err == nil && reduced == nil {
+		reduced, err = callPythonMethod(obj, "__reduce__")
+	}
+	if err != nil {
+		return err
+	}
+	if reduced != nil {
+		return p.saveReduceValue(obj, reduced)
+	}
+
+	cls := obj.Type()
+	inst, ok := obj.(*py.Type)
+	if !ok || cls.Flags&py.TPFLAGS_HEAPTYPE == 0 {
+		return py.ExceptionNewf(py.TypeError, "cannot pickle '%s' object", cls.Name)
+	}
+
+	state, err := callPythonMethod(obj, "__getstate__")
+	if err != nil {
+		return err
+	}
+	if state == nil && len(inst.Dict) != 0 {
+		state = inst.Dict
+	}
+
+	if p.proto < 2 {
+		err = p.saveReduce(reconstructor, py.Tuple{cls, py.ObjectType, py.None}, obj)
+		if err != nil {
+			return err
+		}
+		return p.saveState(state)
+	}
+
+	args := py.Tuple{}
+	newArgs, err := callPythonMethod(obj, "__getnewargs_ex__")
+	if err != nil {
+		return err
+	}
+	if newArgs != nil {
+		pair, ok := newArgs.(py.Tuple)
+		if !ok || len(pair) != 2 {
+			return py.ExceptionNewf(py.TypeError, "__getnewargs_ex__ should return a tuple of length 2")
+		}
+		args, ok = pair[0].(py.Tuple)
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "first item from list returned by __getnewargs_ex__ must be a tuple, not %s", pair[0].Type().Name)
+		}
+		kwargs, ok := pair[1].(*py.Dict)
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "second item from list returned by __getnewargs_ex__ must be a dict, not %s", pair[1].Type().Name)
+		}
+		if kwargs.Len() != 0 {
+			if p.proto < 4 {
+				return py.ExceptionNewf(PicklingError, "must use protocol 4 or greater to copy this object; since __getnewargs_ex__ returned keyword arguments.")
+			}
+			for _, o := range []py.Object{cls, args, kwargs} {
+				err = p.save(o)
+				if err != nil {
+					return err
+				}
+			}
+			p.write(NEWOBJ_EX)
+			p.memoPut(obj)
+			return p.saveState(state)
+		}
+	} else {
+		newArgs, err = callPythonMethod(obj, "__getnewargs__")
+		if err != nil {
+			return err
+		}
+		if newArgs != nil {
+			args, ok = newArgs.(py.Tuple)
+			if !ok {
+				return py.ExceptionNewf(py.TypeError, "__getnewargs__ should return a tuple, not '%s'", newArgs.Type().Name)
+			}
+		}
+	}
+	err = p.save(cls)
+	if err != nil {
+		return err
+	}
+	err = p.save(args)
+	if err != nil {
+		return err
+	}
+	p.write(NEWOBJ)
+	p.memoPut(obj)
+	return p.saveState(state)
+}
+
+// Writes obj using the value returned by its __reduce__ method
+func (p *pickler) saveReduceValue(obj, reduced py.Object) error {
+	if name, ok := reduced.(py.String); ok {
+		return p.saveGlobal(obj, string(name))
+	}
+	t, ok := reduced.(py.Tuple)
+	if !ok {
+		return py.ExceptionNewf(PicklingError, "__reduce__ must return a string or tuple")
+	}
+	if len(t) < 2 || len(t) > 6 {
+		return py.ExceptionNewf(PicklingError, "tuple returned by __reduce__ must contain 2 through 6 elements")
+	}
+	item := func(i int) py.Object {
+		if i < len(t) && t[i] != py.None {
+			return t[i]
+		}
+		return nil
+	}
+	fn, state, listItems, dictItems, stateSetter := t[0], item(2), item(3), item(4), item(5)
+	if _, ok := fn.(py.I__call__); !ok {
+		return py.ExceptionNewf(PicklingError, "first item of the tuple returned by __reduce__ must be callable")
+	}
+	args, ok := t[1].(py.Tuple)
+	if !ok {
+		return py.ExceptionNewf(PicklingError, "second item of the tuple returned by __reduce__ must be a tuple")
+	}
+	err := p.saveReduce(fn, args, obj)
+	if err != nil {
+		return err
+	}
+	if listItems != nil {
+		var items []py.Object
+		err = py.Iterate(listItems, func(item py.Object) bool {
+			items = append(items, item)
+			return false
+		})
+		if err != nil {
+			return err
+		}
+		err = p.batchAppends(items)
+		if err != nil {
+			return err
+		}
+	}
+	if dictItems != nil {
+		var items []py.Tuple
+		var pairErr error
+		err = py.Iterate(dictItems, func(item py.Object) bool {
+			pair, ok := item.(py.Tuple)
+			if !ok || len(pair) != 2 {
+				pairErr = py.ExceptionNewf(PicklingError, "fifth element of the tuple returned by __reduce__ must be an iterator of 2-tuples")
+				return true
+			}
+			items = append(items, pair)
+			return false
+		})
+		if err == nil {
+			err = pairErr
+		}
+		if err != nil {
+			return err
+		}
+		err = p.batchSetItems(items)
+		if err != nil {
+			return err
+		}
+	}
+	if stateSetter != nil && state != nil {
+		// Pickle state_setter(obj, state) and throw away the result
+		for _, o := range []py.Object{stateSetter, obj, state} {
+			err = p.save(o)
+			if err != nil {
+				return err
+			}
+		}
+		p.write(TUPLE2, REDUCE, POP)
+		return nil
+	}
+	return p.saveState(state)
+}
@@ -0,0 +1,244 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import pickle
+import io
+from libtest import *
+
+class Point:
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+
+class Empty:
+    pass
+
+class Stateful:
+    def __init__(self, value):
+        self.value = value
+        self.cache = "not pickled"
+    def __getstate__(self):
+        return {"value": self.value}
+    def __setstate__(self, state):
+        self.value = state["value"] * 10
+        self.cache = None
+
+class Reduced:
+    def __init__(self, a, b):
+        self.a = a
+        self.b = b
+    def __reduce__(self):
+        return (Reduced, (self.a, self.b))
+
+class NewArgs:
+    def __new__(cls, n):
+        self = object.__new__(cls)
+        self.n = n
+        return self
+    def __getnewargs__(self):
+        return (self.n,)
+
+def function():
+    pass
+
+doc = "constants"
+assertEqual(pickle.HIGHEST_PROTOCOL, 5)
+assertEqual(pickle.DEFAULT_PROTOCOL, 4)
+assertTrue(issubclass(pickle.PicklingError, pickle.PickleError))
+assertTrue(issubclass(pickle.UnpicklingError, pickle.PickleError))
+
+doc = "round trip"
+values = [
+    None, True, False, 0, 1, -1, 255, 256, 65535, 65536, 2**31-1, 2**31,
+    -2**31, -2**31-1, 2**64, -2**64, 2**1000, -2**1000, 1.5, -0.0, 1e100,
+    float("inf"), 1+2j, "", "hello", "h\xe9llo\n\\€\U0001f600",
+    "x"*300, b"", b"\x00\xff", bytes([121]*300), (), (1,), (1, 2), (1, 2, 3),
+    (1, 2, 3, 4), [], [1, [2, [3]]], {}, {"a": 1, 2: [3]}, set(), {1, 2, 3},
+    frozenset(), frozenset([1, 2]), list(range(2500)),
+    {i: str(i) for i in range(1500)},
+]
+for proto in range(pickle.HIGHEST_PROTOCOL + 1):
+    for value in values:
+        data = pickle.dumps(value, proto)
+        assertEqual(type(data), bytes)
+        got = pickle.loads(data)
+        assertEqual(type(got), type(value))
+        assertEqual(got, value)
+    got = pickle.loads(pickle.dumps(float("nan"), proto))
+    assertTrue(got != got)
+assertEqual(pickle.loads(pickle.dumps(123, -1)), 123)
+assertEqual(pickle.loads(pickle.dumps(123, None)), 123)
+
+doc = "shared and recursive references"
+for proto in range(pickle.HIGHEST_PROTOCOL + 1):
+    shared = [1, 2]
+    got = pickle.loads(pickle.dumps([shared, shared], proto))
+    assertEqual(got, [[1, 2], [1, 2]])
+    assertTrue(got[0] is got[1])
+    recursive = []
+    recursive.append(recursive)
+    got = pickle.loads(pickle.dumps(recursive, proto))
+    assertTrue(got[0] is got)
+    d = {}
+    d["self"] = d
+    got = pickle.loads(pickle.dumps(d, proto))
+    assertTrue(got["self"] is got)
+
+doc = "classes and functions"
+for proto in range(pickle.HIGHEST_PROTOCOL + 1):
+    assertTrue(pickle.loads(pickle.dumps(Point, proto)) is Point)
+    assertTrue(pickle.loads(pickle.dumps(function, proto)) is function)
+    assertTrue(pickle.loads(pickle.dumps(len, proto)) is len)
+    assertTrue(pickle.loads(pickle.dumps(int, proto)) is int)
+    assertTrue(pickle.loads(pickle.dumps(complex, proto)) is complex)
+    assertTrue(pickle.loads(pickle.dumps(ValueError, proto)) is ValueError)
+    assertTrue(pickle.loads(pickle.dumps(Ellipsis, proto)) is Ellipsis)
+
+doc = "instances"
+for proto in range(pickle.HIGHEST_PROTOCOL + 1):
+    p = pickle.loads(pickle.dumps(Point(1, [2, 3]), proto))
+    assertEqual(type(p), Point)
+    assertEqual(p.x, 1)
+    assertEqual(p.y, [2, 3])
+    e = pickle.loads(pickle.dumps(Empty(), proto))
+    assertEqual(type(e), Empty)
+    s = pickle.loads(pickle.dumps(Stateful(4), proto))
+    assertEqual(s.value, 40)
+    assertEqual(s.cache, None)
+    r = pickle.loads(pickle.dumps(Reduced("a", 2), proto))
+    assertEqual(type(r), Reduced)
+    assertEqual((r.a, r.b), ("a", 2))
+    points = [Point(1, 2)]
+    points.append(points[0])
+    got = pickle.loads(pickle.dumps(points, proto))
+    assertTrue(got[0] is got[1])
+for proto in range(2, pickle.HIGHEST_PROTOCOL + 1):
+    n = pickle.loads(pickle.dumps(NewArgs(7), proto))
+    assertEqual(type(n), NewArgs)
+    assertEqual(n.n, 7)
+
+doc = "same bytes as CPython"
+assertEqual(pickle.dumps([1, 2], 0), b'(lp0\nI1\naI2\na.')
+assertEqual(pickle.dumps([1, 2], 1), b']q\x00(K\x01K\x02e.')
+assertEqual(pickle.dumps([1, 2], 2), b'\x80\x02]q\x00(K\x01K\x02e.')
+assertEqual(pickle.dumps(None), b'\x80\x04N.')
+assertEqual(pickle.dumps(1000), b'\x80\x04\x95\x04\x00\x00\x00\x00\x00\x00\x00M\xe8\x03.')
+assertEqual(pickle.dumps(2**40, 2), b'\x80\x02\x8a\x06\x00\x00\x00\x00\x00\x01.')
+assertEqual(pickle.dumps(-128, 3), b'\x80\x03J\x80\xff\xff\xff.')
+assertEqual(pickle.dumps(2**40, 0), b'L1099511627776L\n.')
+assertEqual(pickle.dumps(1.5, 0), b'F1.5\n.')
+assertEqual(pickle.dumps(1e100, 0), b'F1e+100\n.')
+assertEqual(pickle.dumps("a€\n\\", 0), b'Va\\u20ac\\u000a\\u005c\np0\n.')
+assertEqual(pickle.dumps("a€", 3), b'\x80\x03X\x04\x00\x00\x00a\xe2\x82\xacq\x00.')
+assertEqual(pickle.dumps(b"ab", 2), b'\x80\x02c_codecs\nencode\nq\x00X\x02\x00\x00\x00abq\x01X\x06\x00\x00\x00latin1q\x02\x86q\x03Rq\x04.')
+assertEqual(pickle.dumps(b"ab", 3), b'\x80\x03C\x02abq\x00.')
+assertEqual(pickle.dumps(b"", 1), b'c__builtin__\nbytes\nq\x00)Rq\x01.')
+assertEqual(pickle.dumps((), 0), b'(t.')
+assertEqual(pickle.dumps((1,), 2), b'\x80\x02K\x01\x85q\x00.')
+assertEqual(pickle.dumps({1: 2, 3: 4}, 1), b'}q\x00(K\x01K\x02K\x03K\x04u.')
+assertEqual(pickle.dumps({1}, 2), b'\x80\x02c__builtin__\nset\nq\x00]q\x01K\x01a\x85q\x02Rq\x03.')
+assertEqual(pickle.dumps({1}, 4), b'\x80\x04\x95\x07\x00\x00\x00\x00\x00\x00\x00\x8f\x94(K\x01\x90.')
+assertEqual(pickle.dumps(frozenset([1]), 4), b'\x80\x04\x95\x06\x00\x00\x00\x00\x00\x00\x00(K\x01\x91\x94.')
+assertEqual(pickle.dumps(1+2j, 2), b'\x80\x02c__builtin__\ncomplex\nq\x00G?\xf0\x00\x00\x00\x00\x00\x00G@\x00\x00\x00\x00\x00\x00\x00\x86q\x01Rq\x02.')
+assertEqual(pickle.dumps(len, 4), b'\x80\x04\x95\x14\x00\x00\x00\x00\x00\x00\x00\x8c\x08builtins\x94\x8c\x03len\x94\x93\x94.')
+assertEqual(pickle.dumps(range, 2), b'\x80\x02c__builtin__\nxrange\nq\x00.')
+assertEqual(pickle.dumps(range, 2, fix_imports=False), b'\x80\x02cbuiltins\nrange\nq\x00.')
+assertEqual(pickle.dumps(ValueError, 1), b'cexceptions\nValueError\nq\x00.')
+assertEqual(pickle.dumps(Empty, 3), b'\x80\x03c__main__\nEmpty\nq\x00.')
+e = Empty()
+e.x = 1
+assertEqual(pickle.dumps(e, 0), b'ccopy_reg\n_reconstructor\np0\n(c__main__\nEmpty\np1\nc__builtin__\nobject\np2\nNtp3\nRp4\n(dp5\nVx\np6\nI1\nsb.')
+assertEqual(pickle.dumps(e, 2), b'\x80\x02c__main__\nEmpty\nq\x00)\x81q\x01}q\x02X\x01\x00\x00\x00xq\x03K\x01sb.')
+assertEqual(pickle.dumps(e), b'\x80\x04\x95#\x00\x00\x00\x00\x00\x00\x00\x8c\x08__main__\x94\x8c\x05Empty\x94\x93\x94)\x81\x94}\x94\x8c\x01x\x94K\x01sb.')
+x = ("s", "s")
+assertEqual(pickle.dumps(["ab", "ab", x, x], 4), b'\x80\x04\x95\x16\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\x02ab\x94h\x01\x8c\x01s\x94h\x02\x86\x94h\x03e.')
+
+doc = "load pickles made by CPython"
+expected = [None, True, False, 1, -1, 255, 65535, 2**31, -2**31-1, 2**100, -2**100, 1.5, -0.0, "h\xe9llo\n", b"\x00\xff", (), (1,), (1, 2), (1, 2, 3, 4), {"a": 1, 2: [3]}, {1, 2}, frozenset([3])]
+assertEqual(pickle.loads(b'(lp0\nNaI01\naI00\naI1\naI-1\naI255\naI65535\naL2147483648L\naL-2147483649L\naL1267650600228229401496703205376L\naL-1267650600228229401496703205376L\naF1.5\naF-0.0\naVh\xe9llo\\u000a\np1\nac_codecs\nencode\np2\n(V\\u0000\xff\np3\nVlatin1\np4\ntp5\nRp6\na(ta(I1\ntp7\na(I1\nI2\ntp8\na(I1\nI2\nI3\nI4\ntp9\na(dp10\nVa\np11\nI1\nsI2\n(lp12\nI3\nasac__builtin__\nset\np13\n((lp14\nI1\naI2\natp15\nRp16\nac__builtin__\nfrozenset\np17\n((lp18\nI3\natp19\nRp20\na.'), expected)
+assertEqual(pickle.loads(b']q\x00(NI01\nI00\nK\x01J\xff\xff\xff\xffK\xffM\xff\xffL2147483648L\nL-2147483649L\nL1267650600228229401496703205376L\nL-1267650600228229401496703205376L\nG?\xf8\x00\x00\x00\x00\x00\x00G\x80\x00\x00\x00\x00\x00\x00\x00X\x07\x00\x00\x00h\xc3\xa9llo\nq\x01c_codecs\nencode\nq\x02(X\x03\x00\x00\x00\x00\xc3\xbfq\x03X\x06\x00\x00\x00latin1q\x04tq\x05Rq\x06)(K\x01tq\x07(K\x01K\x02tq\x08(K\x01K\x02K\x03K\x04tq\t}q\n(X\x01\x00\x00\x00aq\x0bK\x01K\x02]q\x0cK\x03auc__builtin__\nset\nq\r(]q\x0e(K\x01K\x02etq\x0fRq\x10c__builtin__\nfrozenset\nq\x11(]q\x12K\x03atq\x13Rq\x14e.'), expected)
+assertEqual(pickle.loads(b'\x80\x02]q\x00(N\x88\x89K\x01J\xff\xff\xff\xffK\xffM\xff\xff\x8a\x05\x00\x00\x00\x80\x00\x8a\x05\xff\xff\xff\x7f\xff\x8a\r\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x8a\r\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0G?\xf8\x00\x00\x00\x00\x00\x00G\x80\x00\x00\x00\x00\x00\x00\x00X\x07\x00\x00\x00h\xc3\xa9llo\nq\x01c_codecs\nencode\nq\x02X\x03\x00\x00\x00\x00\xc3\xbfq\x03X\x06\x00\x00\x00latin1q\x04\x86q\x05Rq\x06)K\x01\x85q\x07K\x01K\x02\x86q\x08(K\x01K\x02K\x03K\x04tq\t}q\n(X\x01\x00\x00\x00aq\x0bK\x01K\x02]q\x0cK\x03auc__builtin__\nset\nq\r]q\x0e(K\x01K\x02e\x85q\x0fRq\x10c__builtin__\nfrozenset\nq\x11]q\x12K\x03a\x85q\x13Rq\x14e.'), expected)
+assertEqual(pickle.loads(b'\x80\x04\x95\x95\x00\x00\x00\x00\x00\x00\x00]\x94(N\x88\x89K\x01J\xff\xff\xff\xffK\xffM\xff\xff\x8a\x05\x00\x00\x00\x80\x00\x8a\x05\xff\xff\xff\x7f\xff\x8a\r\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x8a\r\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0G?\xf8\x00\x00\x00\x00\x00\x00G\x80\x00\x00\x00\x00\x00\x00\x00\x8c\x07h\xc3\xa9llo\n\x94C\x02\x00\xff\x94)K\x01\x85\x94K\x01K\x02\x86\x94(K\x01K\x02K\x03K\x04t\x94}\x94(\x8c\x01a\x94K\x01K\x02]\x94K\x03au\x8f\x94(K\x01K\x02\x90(K\x03\x91\x94e.'), expected)
+p = pickle.loads(b'\x80\x04\x950\x00\x00\x00\x00\x00\x00\x00\x8c\x08__main__\x94\x8c\x05Point\x94\x93\x94)\x81\x94}\x94(\x8c\x01x\x94K\x01\x8c\x01y\x94]\x94(K\x02K\x03eub.')
+assertEqual(type(p), Point)
+assertEqual((p.x, p.y), (1, [2, 3]))
+assertEqual(pickle.loads(b'\x96\x03\x00\x00\x00\x00\x00\x00\x00ab\xff.'), bytearray(b"ab\xff"))
+
+doc = "load Python 2 pickles"
+assertEqual(pickle.loads(b"(dp0\nS'a'\np1\nS'abc'\np2\nsS'u'\np3\nV\xe9\np4\ns."), {"a": "abc", "u": "\xe9"})
+assertEqual(pickle.loads(b"S'a\\'b\\n\\x41'\n."), "a'b\nA")
+assertEqual(pickle.loads(b'U\x02\xe9a.', encoding="latin1"), "\xe9a")
+assertEqual(pickle.loads(b'U\x02\xe9a.', encoding="bytes"), b"\xe9a")
+assertEqual(pickle.loads(b'T\x02\x00\x00\x00\xc3\xa9.', encoding="utf-8"), "\xe9")
+assertRaises(UnicodeDecodeError, pickle.loads, b'U\x02\xe9a.')
+assertRaisesText(LookupError, "unknown encoding: nope", pickle.loads, b'U\x02\xe9a.', encoding="nope")
+assertTrue(pickle.loads(b'\x80\x02c__builtin__\nxrange\nq\x00.') is range)
+assertTrue(pickle.loads(b'\x80\x02c__builtin__\nunicode\nq\x00.') is str)
+assertTrue(pickle.loads(b'\x80\x02cexceptions\nValueError\nq\x00.') is ValueError)
+assertRaises(ImportError, pickle.loads, b'\x80\x02c__builtin__\nxrange\nq\x00.', fix_imports=False)
+p = pickle.loads(b"ccopy_reg\n_reconstructor\np0\n(c__main__\nPoint\np1\nc__builtin__\nobject\np2\nNtp3\nRp4\n(dp5\nS'y'\np6\nI2\nsS'x'\np7\nI1\nsb.")
+assertEqual(type(p), Point)
+assertEqual((p.x, p.y), (1, 2))
+p = pickle.loads(b"(i__main__\nEmpty\np0\n(dp1\nS'x'\np2\nI5\nsb.")
+assertEqual(type(p), Empty)
+assertEqual(p.x, 5)
+p = pickle.loads(b"(c__main__\nEmpty\no}S'x'\nI6\nsb.")
+assertEqual(p.x, 6)
+
+doc = "dump and load with files"
+f = io.BytesIO()
+pickle.dump([1, "two", Point(3, 4)], f)
+pickle.dump({"second": 2}, f, 0)
+f.seek(0)
+got = pickle.load(f)
+assertEqual(got[:2], [1, "two"])
+assertEqual((got[2].x, got[2].y), (3, 4))
+assertEqual(pickle.load(f), {"second": 2})
+assertRaisesText(EOFError, "Ran out of input", pickle.load, f)
+assertRaises(TypeError, pickle.dump, 1, 2)
+assertRaises(TypeError, pickle.load, 2)
+
+doc = "pickling errors"
+assertRaisesText(ValueError, "pickle protocol must be <= 5", pickle.dumps, 1, 6)
+assertRaisesText(TypeError, "cannot pickle 'module' object", pickle.dumps, pickle)
+assertRaisesText(pickle.PicklingError, "attribute lookup <lambda> on __main__ failed", pickle.dumps, lambda: 1)
+def local():
+    class Local:
+        pass
+    return Local
+assertRaisesText(AttributeError, "Can't pickle local object 'local.<locals>.Local'", pickle.dumps, local())
+class BadReduce:
+    def __reduce__(self):
+        return (1, 2)
+assertRaisesText(pickle.PicklingError, "first item of the tuple returned by __reduce__ must be callable", pickle.dumps, BadReduce())
+deep = []
+for i in range(2000):
+    deep = [deep]
+assertRaisesText(RuntimeError, "maximum recursion depth exceeded while pickling an object", pickle.dumps, deep)
+
+doc = "unpickling errors"
+assertRaisesText(TypeError, "a bytes-like object is required, not 'str'", pickle.loads, "x")
+assertRaisesText(EOFError, "Ran out of input", pickle.loads, b'')
+assertRaisesText(EOFError, "Ran out of input", pickle.loads, b'N')
+assertRaisesText(pickle.UnpicklingError, "pickle data was truncated", pickle.loads, b'\x80')
+assertRaisesText(pickle.UnpicklingError, "pickle data was truncated", pickle.loads, b'K')
+assertRaisesText(pickle.UnpicklingError, "pickle data was truncated", pickle.loads, b'I1')
+assertRaisesText(pickle.UnpicklingError, "unpickling stack underflow", pickle.loads, b'0.')
+assertRaisesText(pickle.UnpicklingError, "unexpected MARK found", pickle.loads, b'(.')
+assertRaisesText(pickle.UnpicklingError, "Memo value not found at index 5", pickle.loads, b'h\x05.')
+assertRaisesText(pickle.UnpicklingError, "invalid load key, 'Z'.", pickle.loads, b'Z')
+assertRaisesText(pickle.UnpicklingError, "invalid load key, '\\xff'.", pickle.loads, b'\xff')
+assertRaisesText(ValueError, "unsupported pickle protocol: 9", pickle.loads, b'\x80\x09N.')
+assertRaisesText(pickle.UnpicklingError, "the STRING opcode argument must be quoted", pickle.loads, b'S abc\n.')
+assertRaisesText(pickle.UnpicklingError, "LONG pickle has negative byte count", pickle.loads, b'\x8b\xff\xff\xff\xff')
+assertRaisesText(pickle.UnpicklingError, "persistent id", pickle.loads, b'P1\n.')
+assertRaisesText(ValueError, "unregistered extension code 1", pickle.loads, b'\x82\x01.')
+assertRaisesText(pickle.UnpicklingError, "state is not a dictionary", pickle.loads, b'])b.')
+assertRaisesText(pickle.UnpicklingError, "NEWOBJ class argument must be a type, not NoneType", pickle.loads, b'NN\x81.')
+assertRaisesText(TypeError, "argument list must be a tuple", pickle.loads, b'NK\x01R.')
+assertRaisesText(AttributeError, "Can't get attribute 'nope'", pickle.loads, b'cbuiltins\nnope\n.')
+assertRaises(ImportError, pickle.loads, b'cnomodule\nx\n.')
+assertRaisesText(pickle.UnpicklingError, "STACK_GLOBAL requires str", pickle.loads, b'NN\x93.')
+
+doc = "finished"
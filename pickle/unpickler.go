@@ -0,0 +1,1183 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Read pickles
+
+package pickle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// The names used by Python 2 which are read as the Python 3 ones
+// when fix_imports is set
+var importMapping = map[string]string{
+	"__builtin__": "builtins",
+	"copy_reg":    "copyreg",
+	"exceptions":  "builtins",
+}
+
+var nameMapping = map[[2]string][2]string{}
+
+func init() {
+	for py3, py2 := range reverseNameMapping {
+		nameMapping[py2] = py3
+	}
+	// These Python 2 names don't have a single Python 3 name to
+	// map back to
+	nameMapping[[2]string{"__builtin__", "basestring"}] = [2]string{"builtins", "str"}
+	nameMapping[[2]string{"exceptions", "StandardError"}] = [2]string{"builtins", "Exception"}
+}
+
+// reconstructor is copyreg._reconstructor which pickles written with
+// protocols 0 and 1 use to make instances
+var reconstructor = py.MustNewMethod("_reconstructor", func(self py.Object, args py.Tuple) (py.Object, error) {
+	var cls, base, state py.Object
+	err := py.UnpackTuple(args, nil, "_reconstructor", 3, 3, &cls, &base, &state)
+	if err != nil {
+		return nil, err
+	}
+	if base == py.ObjectType {
+		return callNew(cls, nil, nil)
+	}
+	obj, err := callNew(base, py.Tuple{state}, nil)
+	if err != nil {
+		return nil, err
+	}
+	init, err := py.GetAttrString(base, "__init__")
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.Call(init, py.Tuple{obj, state}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}, 0, "Make an instance of cls using base")
+
+// codecsEncode is _codecs.encode which pickles written with
+// protocols below 3 use to make bytes
+var codecsEncode = py.MustNewMethod("encode", func(self py.Object, args py.Tuple) (py.Object, error) {
+	var obj py.Object
+	var encoding py.Object = py.String("utf-8")
+	var errors py.Object = py.String("strict")
+	err := py.UnpackTuple(args, nil, "encode", 1, 3, &obj, &encoding, &errors)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "utf_8_encode() argument 1 must be str, not %s", obj.Type().Name)
+	}
+	name, ok := encoding.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "encode() argument 'encoding' must be str, not %s", encoding.Type().Name)
+	}
+	switch normalizeEncoding(string(name)) {
+	case "utf_8":
+		return py.Bytes(s), nil
+	case "latin_1", "ascii":
+		limit := rune(0xff)
+		codec := "latin-1"
+		if normalizeEncoding(string(name)) == "ascii" {
+			limit, codec = 0x7f, "ascii"
+		}
+		b := make(py.Bytes, 0, len(s))
+		i := 0
+		for _, c := range string(s) {
+			if c > limit {
+				return nil, py.ExceptionNewf(py.UnicodeEncodeError, "'%s' codec can't encode character '\\x%02x' in position %d: ordinal not in range(%d)", codec, c, i, limit+1)
+			}
+			b = append(b, byte(c))
+			i++
+		}
+		return b, nil
+	}
+	return nil, py.ExceptionNewf(py.LookupError, "unknown encoding: %s", name)
+}, 0, "Encode obj using the codec registered for encoding")
+
+// The functions the pickler writes as globals without them being in
+// an importable module
+var internalGlobals = map[py.Object][2]string{
+	reconstructor: {"copyreg", "_reconstructor"},
+	codecsEncode:  {"_codecs", "encode"},
+}
+
+// Returns the canonical name of an encoding
+func normalizeEncoding(name string) string {
+	name = strings.Replace(strings.Replace(strings.ToLower(name), "-", "_", -1), " ", "_", -1)
+	switch name {
+	case "utf8", "utf_8", "u8":
+		return "utf_8"
+	case "latin1", "latin_1", "iso_8859_1", "iso8859_1", "l1":
+		return "latin_1"
+	case "ascii", "us_ascii", "646":
+		return "ascii"
+	}
+	return name
+}
+
+// input is where the unpickler reads the pickle from
+type input interface {
+	// read returns the next n bytes or errEOF if there are none
+	read(n int) ([]byte, error)
+	// readline returns the bytes up to and including the next
+	// newline
+	readline() ([]byte, error)
+}
+
+var (
+	// errEOF is returned by input.read when there is no more input
+	errEOF = errors.New("EOF")
+	// errTruncated is returned when the pickle ends part way through
+	errTruncated = errors.New("pickle data was truncated")
+)
+
+// bytesInput reads a pickle from a bytes object
+type bytesInput struct {
+	data []byte
+	pos  int
+}
+
+func (in *bytesInput) read(n int) ([]byte, error) {
+	if in.pos >= len(in.data) && n > 0 {
+		return nil, errEOF
+	}
+	if n > len(in.data)-in.pos {
+		return nil, errTruncated
+	}
+	b := in.data[in.pos : in.pos+n]
+	in.pos += n
+	return b, nil
+}
+
+func (in *bytesInput) readline() ([]byte, error) {
+	i := bytes.IndexByte(in.data[in.pos:], '\n')
+	if i < 0 {
+		return nil, errTruncated
+	}
+	b := in.data[in.pos : in.pos+i+1]
+	in.pos += i + 1
+	return b, nil
+}
+
+// fileInput reads a pickle using the read and readline methods of a
+// file object
+type fileInput struct {
+	readFn     py.Object
+	readlineFn py.Object
+}
+
+// Calls fn and checks it returns bytes
+func callRead(fn py.Object, args py.Tuple) ([]byte, error) {
+	res, err := py.Call(fn, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := res.(py.Bytes)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", res.Type().Name)
+	}
+	return b, nil
+}
+
+func (in *fileInput) read(n int) ([]byte, error) {
+	b, err := callRead(in.readFn, py.Tuple{py.Int(n)})
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 && n > 0 {
+		return nil, errEOF
+	}
+	if len(b) < n {
+		return nil, errTruncated
+	}
+	return b, nil
+}
+
+func (in *fileInput) readline() ([]byte, error) {
+	b, err := callRead(in.readlineFn, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 || b[len(b)-1] != '\n' {
+		return nil, errTruncated
+	}
+	return b, nil
+}
+
+// unpickler reads objects from a pickle
+type unpickler struct {
+	in         input
+	stack      []py.Object
+	metastack  [][]py.Object
+	memo       map[int]py.Object
+	proto      int
+	fixImports bool
+	encoding   string
+}
+
+// Reads an object from the pickle in in
+func loads(in input, fixImports bool, encoding string) (py.Object, error) {
+	u := &unpickler{
+		in:         in,
+		memo:       map[int]py.Object{},
+		fixImports: fixImports,
+		encoding:   encoding,
+	}
+	for {
+		op, err := u.in.read(1)
+		if err == errEOF {
+			return nil, py.ExceptionNewf(py.EOFError, "Ran out of input")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if op[0] == STOP {
+			return u.pop()
+		}
+		err = u.dispatch(op[0])
+		if err == errEOF || err == errTruncated {
+			return nil, py.ExceptionNewf(UnpicklingError, "pickle data was truncated")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Reads n bytes where there must be n bytes
+func (u *unpickler) read(n int) ([]byte, error) {
+	b, err := u.in.read(n)
+	if err == errEOF {
+		return nil, errTruncated
+	}
+	return b, err
+}
+
+// Reads a line without its newline
+func (u *unpickler) readline() ([]byte, error) {
+	b, err := u.in.readline()
+	if err != nil {
+		return nil, err
+	}
+	return b[:len(b)-1], nil
+}
+
+// Reads a little endian unsigned integer of n bytes
+func (u *unpickler) readUint(n int) (uint64, error) {
+	b, err := u.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var x uint64
+	for i := n - 1; i >= 0; i-- {
+		x = x<<8 | uint64(b[i])
+	}
+	return x, nil
+}
+
+// Reads a little endian length of n bytes followed by that many bytes
+func (u *unpickler) readCounted(n int) ([]byte, error) {
+	size, err := u.readUint(n)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(^uint(0)>>1) {
+		return nil, py.ExceptionNewf(py.OverflowError, "pickle data is too large")
+	}
+	return u.read(int(size))
+}
+
+func (u *unpickler) push(obj py.Object) {
+	u.stack = append(u.stack, obj)
+}
+
+// Returns the error for popping from an empty stack
+func (u *unpickler) underflow() error {
+	if len(u.metastack) > 0 {
+		return py.ExceptionNewf(UnpicklingError, "unexpected MARK found")
+	}
+	return py.ExceptionNewf(UnpicklingError, "unpickling stack underflow")
+}
+
+func (u *unpickler) pop() (py.Object, error) {
+	if len(u.stack) == 0 {
+		return nil, u.underflow()
+	}
+	obj := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	return obj, nil
+}
+
+func (u *unpickler) top() (py.Object, error) {
+	if len(u.stack) == 0 {
+		return nil, u.underflow()
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+// Pops n items from the stack
+func (u *unpickler) popN(n int) ([]py.Object, error) {
+	if len(u.stack) < n {
+		return nil, u.underflow()
+	}
+	items := append([]py.Object(nil), u.stack[len(u.stack)-n:]...)
+	u.stack = u.stack[:len(u.stack)-n]
+	return items, nil
+}
+
+// Pops the items above the topmost MARK and the MARK
+func (u *unpickler) popMark() ([]py.Object, error) {
+	if len(u.metastack) == 0 {
+		return nil, py.ExceptionNewf(UnpicklingError, "could not find MARK")
+	}
+	items := u.stack
+	u.stack = u.metastack[len(u.metastack)-1]
+	u.metastack = u.metastack[:len(u.metastack)-1]
+	return items, nil
+}
+
+// Returns the error for an opcode which isn't known
+func invalidLoadKey(op byte) error {
+	if op >= 0x20 && op <= 0x7e && op != '\'' && op != '\\' {
+		return py.ExceptionNewf(UnpicklingError, "invalid load key, '%c'.", op)
+	}
+	return py.ExceptionNewf(UnpicklingError, "invalid load key, '\\x%02x'.", op)
+}
+
+// Runs the opcode op
+func (u *unpickler) dispatch(op byte) error {
+	switch op {
+	case PROTO:
+		proto, err := u.read(1)
+		if err != nil {
+			return err
+		}
+		if proto[0] > HIGHEST_PROTOCOL {
+			return py.ExceptionNewf(py.ValueError, "unsupported pickle protocol: %d", proto[0])
+		}
+		u.proto = int(proto[0])
+	case FRAME:
+		// The frames are only used to read big chunks at once
+		_, err := u.readUint(8)
+		return err
+	case MARK:
+		u.metastack = append(u.metastack, u.stack)
+		u.stack = nil
+	case POP:
+		if len(u.stack) > 0 {
+			u.stack = u.stack[:len(u.stack)-1]
+			return nil
+		}
+		if len(u.metastack) == 0 {
+			return u.underflow()
+		}
+		_, err := u.popMark()
+		return err
+	case POP_MARK:
+		_, err := u.popMark()
+		return err
+	case DUP:
+		obj, err := u.top()
+		if err != nil {
+			return err
+		}
+		u.push(obj)
+
+	case NONE:
+		u.push(py.None)
+	case NEWTRUE:
+		u.push(py.True)
+	case NEWFALSE:
+		u.push(py.False)
+	case INT:
+		return u.loadInt()
+	case BININT:
+		x, err := u.readUint(4)
+		if err != nil {
+			return err
+		}
+		u.push(py.Int(int32(x)))
+	case BININT1:
+		x, err := u.readUint(1)
+		if err != nil {
+			return err
+		}
+		u.push(py.Int(x))
+	case BININT2:
+		x, err := u.readUint(2)
+		if err != nil {
+			return err
+		}
+		u.push(py.Int(x))
+	case LONG:
+		line, err := u.readline()
+		if err != nil {
+			return err
+		}
+		line = bytes.TrimSuffix(line, []byte("L"))
+		x, err := parseInt(line)
+		if err != nil {
+			return err
+		}
+		u.push(x)
+	case LONG1, LONG4:
+		n := 1
+		if op == LONG4 {
+			n = 4
+		}
+		size, err := u.readUint(n)
+		if err != nil {
+			return err
+		}
+		if op == LONG4 && int32(size) < 0 {
+			return py.ExceptionNewf(UnpicklingError, "LONG pickle has negative byte count")
+		}
+		data, err := u.read(int(size))
+		if err != nil {
+			return err
+		}
+		u.push(decodeLong(data))
+	case FLOAT:
+		line, err := u.readline()
+		if err != nil {
+			return err
+		}
+		x, err := strconv.ParseFloat(strings.TrimSpace(string(line)), 64)
+		if err != nil {
+			return py.ExceptionNewf(py.ValueError, "could not convert string to float")
+		}
+		u.push(py.Float(x))
+	case BINFLOAT:
+		b, err := u.read(8)
+		if err != nil {
+			return err
+		}
+		u.push(py.Float(math.Float64frombits(binary.BigEndian.Uint64(b))))
+
+	case STRING:
+		line, err := u.readline()
+		if err != nil {
+			return err
+		}
+		line = bytes.TrimRight(line, " \t\r")
+		if len(line) < 2 || (line[0] != '\'' && line[0] != '"') || line[len(line)-1] != line[0] {
+			return py.ExceptionNewf(UnpicklingError, "the STRING opcode argument must be quoted")
+		}
+		data, err := escapeDecode(line[1 : len(line)-1])
+		if err != nil {
+			return err
+		}
+		return u.pushString(data)
+	case BINSTRING, SHORT_BINSTRING:
+		n := 4
+		if op == SHORT_BINSTRING {
+			n = 1
+		}
+		data, err := u.readCounted(n)
+		if err != nil {
+			return err
+		}
+		return u.pushString(data)
+	case BINBYTES, SHORT_BINBYTES, BINBYTES8:
+		n := map[byte]int{BINBYTES: 4, SHORT_BINBYTES: 1, BINBYTES8: 8}[op]
+		data, err := u.readCounted(n)
+		if err != nil {
+			return err
+		}
+		u.push(py.Bytes(append([]byte(nil), data...)))
+	case BYTEARRAY8:
+		data, err := u.readCounted(8)
+		if err != nil {
+			return err
+		}
+		u.push(py.NewByteArray(data))
+	case UNICODE:
+		line, err := u.readline()
+		if err != nil {
+			return err
+		}
+		s, err := rawUnicodeUnescape(line)
+		if err != nil {
+			return err
+		}
+		u.push(py.String(s))
+	case BINUNICODE, SHORT_BINUNICODE, BINUNICODE8:
+		n := map[byte]int{BINUNICODE: 4, SHORT_BINUNICODE: 1, BINUNICODE8: 8}[op]
+		data, err := u.readCounted(n)
+		if err != nil {
+			return err
+		}
+		s, err := decodeUTF8(data)
+		if err != nil {
+			return err
+		}
+		u.push(s)
+
+	case EMPTY_TUPLE:
+		u.push(py.Tuple{})
+	case TUPLE:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(py.Tuple(items))
+	case TUPLE1, TUPLE2, TUPLE3:
+		items, err := u.popN(int(op-TUPLE1) + 1)
+		if err != nil {
+			return err
+		}
+		u.push(py.Tuple(items))
+	case EMPTY_LIST:
+		u.push(py.NewList())
+	case LIST:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(py.NewListFromItems(items))
+	case EMPTY_DICT:
+		u.push(py.NewDict())
+	case DICT:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		d := py.NewDictSized(len(items) / 2)
+		err = setItems(d, items)
+		if err != nil {
+			return err
+		}
+		u.push(d)
+	case EMPTY_SET:
+		u.push(py.NewSet())
+	case FROZENSET:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		s, err := py.NewFrozenSetFromItems(items)
+		if err != nil {
+			return err
+		}
+		u.push(s)
+
+	case APPEND:
+		item, err := u.pop()
+		if err != nil {
+			return err
+		}
+		return u.appendItems([]py.Object{item})
+	case APPENDS:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		return u.appendItems(items)
+	case SETITEM:
+		items, err := u.popN(2)
+		if err != nil {
+			return err
+		}
+		d, err := u.top()
+		if err != nil {
+			return err
+		}
+		return setItems(d, items)
+	case SETITEMS:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		d, err := u.top()
+		if err != nil {
+			return err
+		}
+		return setItems(d, items)
+	case ADDITEMS:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		set, err := u.top()
+		if err != nil {
+			return err
+		}
+		if s, ok := set.(*py.Set); ok {
+			return s.Update(items)
+		}
+		add, err := py.GetAttrString(set, "add")
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			_, err = py.Call(add, py.Tuple{item}, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+	case GET:
+		i, err := u.readLineInt()
+		if err != nil {
+			return err
+		}
+		return u.memoGet(i)
+	case BINGET, LONG_BINGET:
+		n := 1
+		if op == LONG_BINGET {
+			n = 4
+		}
+		i, err := u.readUint(n)
+		if err != nil {
+			return err
+		}
+		return u.memoGet(int(i))
+	case PUT:
+		i, err := u.readLineInt()
+		if err != nil {
+			return err
+		}
+		if i < 0 {
+			return py.ExceptionNewf(py.ValueError, "negative PUT argument")
+		}
+		return u.memoPut(i)
+	case BINPUT, LONG_BINPUT:
+		n := 1
+		if op == LONG_BINPUT {
+			n = 4
+		}
+		i, err := u.readUint(n)
+		if err != nil {
+			return err
+		}
+		return u.memoPut(int(i))
+	case MEMOIZE:
+		return u.memoPut(len(u.memo))
+
+	case GLOBAL:
+		module, err := u.readline()
+		if err != nil {
+			return err
+		}
+		name, err := u.readline()
+		if err != nil {
+			return err
+		}
+		obj, err := u.findClass(string(module), string(name))
+		if err != nil {
+			return err
+		}
+		u.push(obj)
+	case STACK_GLOBAL:
+		items, err := u.popN(2)
+		if err != nil {
+			return err
+		}
+		module, ok1 := items[0].(py.String)
+		name, ok2 := items[1].(py.String)
+		if !ok1 || !ok2 {
+			return py.ExceptionNewf(UnpicklingError, "STACK_GLOBAL requires str")
+		}
+		obj, err := u.findClass(string(module), string(name))
+		if err != nil {
+			return err
+		}
+		u.push(obj)
+	case REDUCE:
+		args, err := u.pop()
+		if err != nil {
+			return err
+		}
+		fn, err := u.pop()
+		if err != nil {
+			return err
+		}
+		argsTuple, ok := args.(py.Tuple)
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "argument list must be a tuple")
+		}
+		obj, err := py.Call(fn, argsTuple, nil)
+		if err != nil {
+			return err
+		}
+		u.push(obj)
+	case BUILD:
+		state, err := u.pop()
+		if err != nil {
+			return err
+		}
+		inst, err := u.top()
+		if err != nil {
+			return err
+		}
+		return build(inst, state)
+	case INST:
+		module, err := u.readline()
+		if err != nil {
+			return err
+		}
+		name, err := u.readline()
+		if err != nil {
+			return err
+		}
+		cls, err := u.findClass(string(module), string(name))
+		if err != nil {
+			return err
+		}
+		args, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		return u.instantiate(cls, args)
+	case OBJ:
+		args, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return u.underflow()
+		}
+		return u.instantiate(args[0], args[1:])
+	case NEWOBJ:
+		items, err := u.popN(2)
+		if err != nil {
+			return err
+		}
+		return u.newObj("NEWOBJ", items[0], items[1], nil)
+	case NEWOBJ_EX:
+		items, err := u.popN(3)
+		if err != nil {
+			return err
+		}
+		return u.newObj("NEWOBJ_EX", items[0], items[1], items[2])
+
+	case PERSID, BINPERSID:
+		return py.ExceptionNewf(UnpicklingError, "A load persistent id instruction was encountered,\nbut no persistent_load function was specified.")
+	case EXT1, EXT2, EXT4:
+		n := map[byte]int{EXT1: 1, EXT2: 2, EXT4: 4}[op]
+		code, err := u.readUint(n)
+		if err != nil {
+			return err
+		}
+		return py.ExceptionNewf(py.ValueError, "unregistered extension code %d", code)
+	case NEXT_BUFFER:
+		return py.ExceptionNewf(UnpicklingError, "pickle stream refers to out-of-band data but no *buffers* argument was given")
+	case READONLY_BUFFER:
+		// Only bytes are read here which are read only already
+		_, err := u.top()
+		return err
+	default:
+		return invalidLoadKey(op)
+	}
+	return nil
+}
+
+// Parses a decimal integer written by the INT and LONG opcodes
+func parseInt(line []byte) (py.Object, error) {
+	x, ok := new(big.Int).SetString(strings.TrimSpace(string(line)), 10)
+	if !ok {
+		return nil, py.ExceptionNewf(py.ValueError, "could not convert string to int")
+	}
+	return (*py.BigInt)(x).MaybeInt(), nil
+}
+
+func (u *unpickler) loadInt() error {
+	line, err := u.readline()
+	if err != nil {
+		return err
+	}
+	switch string(line) {
+	case "00":
+		u.push(py.False)
+		return nil
+	case "01":
+		u.push(py.True)
+		return nil
+	}
+	x, err := parseInt(line)
+	if err != nil {
+		return err
+	}
+	u.push(x)
+	return nil
+}
+
+// Reads the memo index for GET and PUT
+func (u *unpickler) readLineInt() (int, error) {
+	line, err := u.readline()
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(string(line)))
+	if err != nil {
+		return 0, py.ExceptionNewf(py.ValueError, "could not convert string to int")
+	}
+	return i, nil
+}
+
+// Decodes a little endian two's complement integer
+func decodeLong(data []byte) py.Object {
+	be := make([]byte, len(data))
+	for i, c := range data {
+		be[len(data)-1-i] = c
+	}
+	x := new(big.Int).SetBytes(be)
+	if len(data) > 0 && data[len(data)-1]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(data))))
+	}
+	return (*py.BigInt)(x).MaybeInt()
+}
+
+// Returns data as a str or an error if it isn't valid UTF-8
+func decodeUTF8(data []byte) (py.String, error) {
+	if !utf8.Valid(data) {
+		for i := 0; i < len(data); {
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size <= 1 {
+				return "", py.ExceptionNewf(py.UnicodeDecodeError, "'utf-8' codec can't decode byte 0x%02x in position %d: invalid start byte", data[i], i)
+			}
+			i += size
+		}
+	}
+	return py.String(data), nil
+}
+
+// Pushes a Python 2 str decoded with the encoding passed to load
+func (u *unpickler) pushString(data []byte) error {
+	switch normalizeEncoding(u.encoding) {
+	case "bytes":
+		u.push(py.Bytes(append([]byte(nil), data...)))
+	case "latin_1":
+		var s strings.Builder
+		for _, c := range data {
+			s.WriteRune(rune(c))
+		}
+		u.push(py.String(s.String()))
+	case "ascii":
+		for i, c := range data {
+			if c >= utf8.RuneSelf {
+				return py.ExceptionNewf(py.UnicodeDecodeError, "'ascii' codec can't decode byte 0x%02x in position %d: ordinal not in range(128)", c, i)
+			}
+		}
+		u.push(py.String(data))
+	case "utf_8":
+		s, err := decodeUTF8(data)
+		if err != nil {
+			return err
+		}
+		u.push(s)
+	default:
+		return py.ExceptionNewf(py.LookupError, "unknown encoding: %s", u.encoding)
+	}
+	return nil
+}
+
+// Decodes the backslash escapes in the argument of STRING
+func escapeDecode(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c != '\\' {
+			out = append(out, c)
+			continue
+		}
+		i++
+		if i >= len(data) {
+			return nil, py.ExceptionNewf(py.ValueError, "Trailing \\ in string")
+		}
+		c = data[i]
+		switch c {
+		case '\n':
+		case '\\', '\'', '"':
+			out = append(out, c)
+		case 'a':
+			out = append(out, '\a')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'v':
+			out = append(out, '\v')
+		case 'x':
+			if i+2 >= len(data) {
+				return nil, py.ExceptionNewf(py.ValueError, "invalid \\x escape at position %d", i-1)
+			}
+			x, err := strconv.ParseUint(string(data[i+1:i+3]), 16, 8)
+			if err != nil {
+				return nil, py.ExceptionNewf(py.ValueError, "invalid \\x escape at position %d", i-1)
+			}
+			out = append(out, byte(x))
+			i += 2
+		default:
+			if c >= '0' && c <= '7' {
+				x := int(c - '0')
+				for n := 1; n < 3 && i+1 < len(data) && data[i+1] >= '0' && data[i+1] <= '7'; n++ {
+					i++
+					x = x*8 + int(data[i]-'0')
+				}
+				out = append(out, byte(x))
+			} else {
+				out = append(out, '\\', c)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Decodes the argument of UNICODE
+func rawUnicodeUnescape(data []byte) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c == '\\' && i+1 < len(data) && (data[i+1] == 'u' || data[i+1] == 'U') {
+			n := 4
+			if data[i+1] == 'U' {
+				n = 8
+			}
+			if i+2+n > len(data) {
+				return "", py.ExceptionNewf(py.UnicodeDecodeError, "'rawunicodeescape' codec can't decode bytes in position %d-%d: truncated \\%cXXXX escape", i, len(data)-1, data[i+1])
+			}
+			x, err := strconv.ParseUint(string(data[i+2:i+2+n]), 16, 32)
+			if err != nil || x > utf8.MaxRune {
+				return "", py.ExceptionNewf(py.UnicodeDecodeError, "'rawunicodeescape' codec can't decode bytes in position %d-%d: truncated \\%cXXXX escape", i, i+1+n, data[i+1])
+			}
+			out.WriteRune(rune(x))
+			i += 1 + n
+			continue
+		}
+		out.WriteRune(rune(c))
+	}
+	return out.String(), nil
+}
+
+// Sets the key value pairs in items in d
+func setItems(d py.Object, items []py.Object) error {
+	if len(items)%2 != 0 {
+		return py.ExceptionNewf(UnpicklingError, "odd number of items for SETITEMS")
+	}
+	for i := 0; i < len(items); i += 2 {
+		_, err := py.SetItem(d, items[i], items[i+1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Adds items to the list on the top of the stack
+func (u *unpickler) appendItems(items []py.Object) error {
+	obj, err := u.top()
+	if err != nil {
+		return err
+	}
+	if l, ok := obj.(*py.List); ok {
+		l.Extend(items)
+		return nil
+	}
+	if extend, err := py.GetAttrString(obj, "extend"); err == nil {
+		_, err = py.Call(extend, py.Tuple{py.NewListFromItems(items)}, nil)
+		return err
+	}
+	appendMethod, err := py.GetAttrString(obj, "append")
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		_, err = py.Call(appendMethod, py.Tuple{item}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *unpickler) memoGet(i int) error {
+	obj, ok := u.memo[i]
+	if !ok {
+		return py.ExceptionNewf(UnpicklingError, "Memo value not found at index %d", i)
+	}
+	u.push(obj)
+	return nil
+}
+
+func (u *unpickler) memoPut(i int) error {
+	obj, err := u.top()
+	if err != nil {
+		return err
+	}
+	u.memo[i] = obj
+	return nil
+}
+
+// Returns the object called name in module
+func (u *unpickler) findClass(module, name string) (py.Object, error) {
+	if u.proto < 3 && u.fixImports {
+		if mapped, ok := nameMapping[[2]string{module, name}]; ok {
+			module, name = mapped[0], mapped[1]
+		} else if mapped, ok := importMapping[module]; ok {
+			module = mapped
+		}
+	}
+	for obj, names := range internalGlobals {
+		if names[0] == module && names[1] == name {
+			return obj, nil
+		}
+	}
+	m, err := py.ImportModuleLevelObject(module, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{name}
+	if u.proto >= 4 {
+		names = strings.Split(name, ".")
+	}
+	obj, err := getAttribute(m, names)
+	if err != nil {
+		moduleRepr, reprErr := py.ReprAsString(m)
+		if reprErr != nil {
+			return nil, reprErr
+		}
+		return nil, py.ExceptionNewf(py.AttributeError, "Can't get attribute '%s' on %s", name, moduleRepr)
+	}
+	return obj, nil
+}
+
+// Calls cls.__new__(cls, *args, **kwargs)
+func callNew(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	newMethod, err := py.GetAttrString(cls, "__new__")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(newMethod, append(py.Tuple{cls}, args...), kwargs)
+}
+
+// Makes an instance of cls for the INST and OBJ opcodes
+func (u *unpickler) instantiate(cls py.Object, args []py.Object) error {
+	var obj py.Object
+	var err error
+	_, isType := cls.(*py.Type)
+	_, initArgsErr := py.GetAttrString(cls, "__getinitargs__")
+	if len(args) > 0 || !isType || initArgsErr == nil {
+		obj, err = py.Call(cls, py.Tuple(args), nil)
+	} else {
+		obj, err = callNew(cls, nil, nil)
+	}
+	if err != nil {
+		return err
+	}
+	u.push(obj)
+	return nil
+}
+
+// Makes an instance of cls for the NEWOBJ and NEWOBJ_EX opcodes
+func (u *unpickler) newObj(opcode string, cls, args, kwargs py.Object) error {
+	if !cls.Type().IsSubtype(py.TypeType) {
+		return py.ExceptionNewf(UnpicklingError, "%s class argument must be a type, not %s", opcode, cls.Type().Name)
+	}
+	argsTuple, ok := args.(py.Tuple)
+	if !ok {
+		return py.ExceptionNewf(UnpicklingError, "%s args argument must be a tuple, not %s", opcode, args.Type().Name)
+	}
+	var kwargsDict py.StringDict
+	if kwargs != nil {
+		d, ok := kwargs.(*py.Dict)
+		if !ok {
+			return py.ExceptionNewf(UnpicklingError, "%s kwargs argument must be a dict, not %s", opcode, kwargs.Type().Name)
+		}
+		kwargsDict = py.NewStringDict()
+		for _, item := range d.Items() {
+			key, ok := item[0].(py.String)
+			if !ok {
+				return py.ExceptionNewf(py.TypeError, "keywords must be strings")
+			}
+			kwargsDict[string(key)] = item[1]
+		}
+	}
+	obj, err := callNew(cls, argsTuple, kwargsDict)
+	if err != nil {
+		return err
+	}
+	u.push(obj)
+	return nil
+}
+
+// Sets the state of inst for the BUILD opcode
+func build(inst, state py.Object) error {
+	if setstate, err := py.GetAttrString(inst, "__setstate__"); err == nil {
+		_, err = py.Call(setstate, py.Tuple{state}, nil)
+		return err
+	} else if !py.IsException(py.AttributeError, err) {
+		return err
+	}
+	var slotState py.Object
+	if t, ok := state.(py.Tuple); ok && len(t) == 2 {
+		state, slotState = t[0], t[1]
+	}
+	if state != py.None {
+		items, ok := dictItems(state)
+		if !ok {
+			return py.ExceptionNewf(UnpicklingError, "state is not a dictionary")
+		}
+		d, hasDict := inst.(py.IGetDict)
+		for _, item := range items {
+			key, ok := item[0].(py.String)
+			if hasDict && ok {
+				d.GetDict()[string(key)] = item[1]
+				continue
+			}
+			if !ok {
+				return py.ExceptionNewf(py.TypeError, "attribute name must be string, not '%s'", item[0].Type().Name)
+			}
+			_, err := py.SetAttrString(inst, string(key), item[1])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if slotState != nil && slotState != py.None {
+		items, ok := dictItems(slotState)
+		if !ok {
+			return py.ExceptionNewf(UnpicklingError, "slot state is not a dictionary")
+		}
+		for _, item := range items {
+			key, ok := item[0].(py.String)
+			if !ok {
+				return py.ExceptionNewf(py.TypeError, "attribute name must be string, not '%s'", item[0].Type().Name)
+			}
+			_, err := py.SetAttrString(inst, string(key), item[1])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the items of a dict
+func dictItems(obj py.Object) ([]py.Tuple, bool) {
+	switch d := obj.(type) {
+	case *py.Dict:
+		return d.Items(), true
+	case py.StringDict:
+		return d.Items(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestSmallObjectsDontAllocate(t *testing.T) {
+	a, b := Int(200), Int(-150)
+	s := String("hello")
+	var res Object
+	for _, test := range []struct {
+		name string
+		fn   func()
+		want Object
+	}{
+		{"int add", func() { res, _ = a.M__add__(Int(56)) }, Int(256)},
+		{"int sub", func() { res, _ = b.M__sub__(Int(-146)) }, Int(-4)},
+		{"int mul", func() { res, _ = Int(-1).M__mul__(Int(5)) }, Int(-5)},
+		{"int floordiv", func() { res, _ = a.M__floordiv__(Int(-41)) }, Int(-5)},
+		{"str index", func() { res, _ = s.M__getitem__(Int(1)) }, String("e")},
+	} {
+		if n := testing.AllocsPerRun(100, test.fn); n != 0 {
+			t.Errorf("%s: want 0 allocations got %v", test.name, n)
+		}
+		if res != test.want {
+			t.Errorf("%s: want %v got %v", test.name, test.want, res)
+		}
+	}
+
+	r := &Range{Start: 250, Stop: 260, Step: 1, Length: 10}
+	iter, err := r.M__iter__()
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := iter.(I__next__)
+	// AllocsPerRun calls the function once more than asked to warm up
+	if n := testing.AllocsPerRun(2, func() { res, _ = next.M__next__() }); n != 0 {
+		t.Errorf("range: want 0 allocations got %v", n)
+	}
+	if res != Int(252) {
+		t.Errorf("range: want 252 got %v", res)
+	}
+}
+
+func TestInternString(t *testing.T) {
+	a := InternString(string([]byte("interned")))
+	b := InternString(string([]byte("interned")))
+	if a != b {
+		t.Fatalf("want %q got %q", a, b)
+	}
+	dataOf := func(s string) uintptr {
+		return *(*uintptr)(unsafe.Pointer(&s))
+	}
+	if dataOf(a) != dataOf(b) {
+		t.Errorf("interned strings don't share their memory")
+	}
+	if got := String("interned").Intern(); string(got) != a {
+		t.Errorf("want %q got %q", a, got)
+	}
+}
+
+func TestSlotsDontAllocate(t *testing.T) {
+	res, err := TypeNew(TypeType, Tuple{String("S"), Tuple{ObjectType}, StringDict{"__slots__": Tuple{String("a"), String("b")}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cls := res.(*Type)
+	if n := testing.AllocsPerRun(100, func() { cls.Alloc() }); n > 2 {
+		t.Errorf("alloc: want at most 2 allocations got %v", n)
+	}
+	obj := cls.Alloc()
+	if obj.Dict != nil {
+		t.Errorf("want no __dict__ got %v", obj.Dict)
+	}
+	b := cls.Dict["b"].(*MemberDescriptor)
+	if n := testing.AllocsPerRun(100, func() { _, _ = b.M__set__(obj, Int(1)) }); n != 0 {
+		t.Errorf("set slot: want 0 allocations got %v", n)
+	}
+	got, err := b.M__get__(obj, cls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != Int(1) {
+		t.Errorf("want 1 got %v", got)
+	}
+}
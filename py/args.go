@@ -446,12 +446,19 @@ func ParseTupleAndKeywords(args Tuple, kwargs StringDict, format string, kwlist
 			if len(args) > i {
 				return ExceptionNewf(TypeError, "%s() got multiple values for argument '%s'", name, kw)
 			}
+			// Leave any skipped optional arguments unset
+			for len(args) < i {
+				args = append(args, nil)
+			}
 			args = append(args, value)
 		} else if keywordOnly {
 			args = append(args, nil)
 		}
 	}
 	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
 		op := ops[i]
 		result := results[i]
 		switch op {
@@ -469,10 +476,19 @@ func ParseTupleAndKeywords(args Tuple, kwargs StringDict, format string, kwlist
 			}
 			*result = arg
 		case "i":
-			if _, ok := arg.(Int); !ok {
+			switch x := arg.(type) {
+			case Int:
+				*result = arg
+			case Bool:
+				// bool is a subclass of int
+				if x {
+					*result = Int(1)
+				} else {
+					*result = Int(0)
+				}
+			default:
 				return ExceptionNewf(TypeError, "%s() argument %d must be int, not %s", name, i+1, arg.Type().Name)
 			}
-			*result = arg
 		case "p":
 			if _, ok := arg.(Bool); !ok {
 				return ExceptionNewf(TypeError, "%s() argument %d must be bool, not %s", name, i+1, arg.Type().Name)
@@ -1,7 +1,3 @@
-// Copyright 2018 The go-python Authors.  All rights reserved.
-// Use of this source code is governed by a BSD-style
-// license that can be found in the LICENSE file.
-
 // Automatically generated - DO NOT EDIT
 // Regenerate with: go generate
 
@@ -22,6 +18,8 @@ func Neg(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__neg__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for -: '%s'", a.Type().Name)
@@ -40,6 +38,8 @@ func Pos(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__pos__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for +: '%s'", a.Type().Name)
@@ -58,6 +58,8 @@ func Abs(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__abs__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for abs: '%s'", a.Type().Name)
@@ -76,6 +78,8 @@ func Invert(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__invert__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for ~: '%s'", a.Type().Name)
@@ -98,6 +102,8 @@ func MakeComplex(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__complex__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for complex: '%s'", a.Type().Name)
@@ -120,6 +126,8 @@ func MakeInt(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__int__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for int: '%s'", a.Type().Name)
@@ -142,6 +150,8 @@ func MakeFloat(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__float__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for float: '%s'", a.Type().Name)
@@ -160,6 +170,8 @@ func Iter(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__iter__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for iter: '%s'", a.Type().Name)
@@ -169,6 +181,21 @@ func Iter(a Object) (Object, error) {
 //
 // Will raise TypeError if can't be add can't be run on these objects
 func Add(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// radd first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__radd__); ok {
+			res, err := B.M__radd__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to add
 	if A, ok := a.(I__add__); ok {
 		res, err := A.M__add__(b)
@@ -181,7 +208,7 @@ func Add(a, b Object) (Object, error) {
 	}
 
 	// Now using b to radd if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__radd__); ok {
 			res, err := B.M__radd__(a)
 			if err != nil {
@@ -213,6 +240,21 @@ func IAdd(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be sub can't be run on these objects
 func Sub(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rsub first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rsub__); ok {
+			res, err := B.M__rsub__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to sub
 	if A, ok := a.(I__sub__); ok {
 		res, err := A.M__sub__(b)
@@ -225,7 +267,7 @@ func Sub(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rsub if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rsub__); ok {
 			res, err := B.M__rsub__(a)
 			if err != nil {
@@ -257,6 +299,21 @@ func ISub(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be mul can't be run on these objects
 func Mul(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rmul first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rmul__); ok {
+			res, err := B.M__rmul__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to mul
 	if A, ok := a.(I__mul__); ok {
 		res, err := A.M__mul__(b)
@@ -269,7 +326,7 @@ func Mul(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rmul if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rmul__); ok {
 			res, err := B.M__rmul__(a)
 			if err != nil {
@@ -297,10 +354,84 @@ func IMul(a, b Object) (Object, error) {
 	return Mul(a, b)
 }
 
+// MatMul two python objects together returning an Object
+//
+// Will raise TypeError if can't be matmul can't be run on these objects
+func MatMul(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rmatmul first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rmatmul__); ok {
+			res, err := B.M__rmatmul__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
+	// Try using a to matmul
+	if A, ok := a.(I__matmul__); ok {
+		res, err := A.M__matmul__(b)
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
+	}
+
+	// Now using b to rmatmul if different in type to a
+	if !reflectedFirst && a.Type() != b.Type() {
+		if B, ok := b.(I__rmatmul__); ok {
+			res, err := B.M__rmatmul__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for @: '%s' and '%s'", a.Type().Name, b.Type().Name)
+}
+
+// Inplace matmul
+func IMatMul(a, b Object) (Object, error) {
+	if A, ok := a.(I__imatmul__); ok {
+		res, err := A.M__imatmul__(b)
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
+	}
+	return MatMul(a, b)
+}
+
 // TrueDiv two python objects together returning an Object
 //
 // Will raise TypeError if can't be truediv can't be run on these objects
 func TrueDiv(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rtruediv first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rtruediv__); ok {
+			res, err := B.M__rtruediv__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to truediv
 	if A, ok := a.(I__truediv__); ok {
 		res, err := A.M__truediv__(b)
@@ -313,7 +444,7 @@ func TrueDiv(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rtruediv if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rtruediv__); ok {
 			res, err := B.M__rtruediv__(a)
 			if err != nil {
@@ -345,6 +476,21 @@ func ITrueDiv(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be floordiv can't be run on these objects
 func FloorDiv(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rfloordiv first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rfloordiv__); ok {
+			res, err := B.M__rfloordiv__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to floordiv
 	if A, ok := a.(I__floordiv__); ok {
 		res, err := A.M__floordiv__(b)
@@ -357,7 +503,7 @@ func FloorDiv(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rfloordiv if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rfloordiv__); ok {
 			res, err := B.M__rfloordiv__(a)
 			if err != nil {
@@ -389,6 +535,21 @@ func IFloorDiv(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be mod can't be run on these objects
 func Mod(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rmod first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rmod__); ok {
+			res, err := B.M__rmod__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to mod
 	if A, ok := a.(I__mod__); ok {
 		res, err := A.M__mod__(b)
@@ -401,7 +562,7 @@ func Mod(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rmod if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rmod__); ok {
 			res, err := B.M__rmod__(a)
 			if err != nil {
@@ -433,6 +594,21 @@ func IMod(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be divmod can't be run on these objects
 func DivMod(a, b Object) (Object, Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rdivmod first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rdivmod__); ok {
+			res, res2, err := B.M__rdivmod__(a)
+			if err != nil {
+				return nil, nil, err
+			}
+			if res != NotImplemented {
+				return res, res2, nil
+			}
+		}
+	}
+
 	// Try using a to divmod
 	if A, ok := a.(I__divmod__); ok {
 		res, res2, err := A.M__divmod__(b)
@@ -445,7 +621,7 @@ func DivMod(a, b Object) (Object, Object, error) {
 	}
 
 	// Now using b to rdivmod if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rdivmod__); ok {
 			res, res2, err := B.M__rdivmod__(a)
 			if err != nil {
@@ -463,6 +639,21 @@ func DivMod(a, b Object) (Object, Object, error) {
 //
 // Will raise TypeError if can't be lshift can't be run on these objects
 func Lshift(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rlshift first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rlshift__); ok {
+			res, err := B.M__rlshift__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to lshift
 	if A, ok := a.(I__lshift__); ok {
 		res, err := A.M__lshift__(b)
@@ -475,7 +666,7 @@ func Lshift(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rlshift if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rlshift__); ok {
 			res, err := B.M__rlshift__(a)
 			if err != nil {
@@ -507,6 +698,21 @@ func ILshift(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be rshift can't be run on these objects
 func Rshift(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rrshift first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rrshift__); ok {
+			res, err := B.M__rrshift__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to rshift
 	if A, ok := a.(I__rshift__); ok {
 		res, err := A.M__rshift__(b)
@@ -519,7 +725,7 @@ func Rshift(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rrshift if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rrshift__); ok {
 			res, err := B.M__rrshift__(a)
 			if err != nil {
@@ -551,6 +757,21 @@ func IRshift(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be and can't be run on these objects
 func And(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rand first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rand__); ok {
+			res, err := B.M__rand__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to and
 	if A, ok := a.(I__and__); ok {
 		res, err := A.M__and__(b)
@@ -563,7 +784,7 @@ func And(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rand if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rand__); ok {
 			res, err := B.M__rand__(a)
 			if err != nil {
@@ -595,6 +816,21 @@ func IAnd(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be xor can't be run on these objects
 func Xor(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rxor first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rxor__); ok {
+			res, err := B.M__rxor__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to xor
 	if A, ok := a.(I__xor__); ok {
 		res, err := A.M__xor__(b)
@@ -607,7 +843,7 @@ func Xor(a, b Object) (Object, error) {
 	}
 
 	// Now using b to rxor if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__rxor__); ok {
 			res, err := B.M__rxor__(a)
 			if err != nil {
@@ -639,6 +875,21 @@ func IXor(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be or can't be run on these objects
 func Or(a, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// ror first so subclasses can override the operation
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__ror__); ok {
+			res, err := B.M__ror__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to or
 	if A, ok := a.(I__or__); ok {
 		res, err := A.M__or__(b)
@@ -651,7 +902,7 @@ func Or(a, b Object) (Object, error) {
 	}
 
 	// Now using b to ror if different in type to a
-	if a.Type() != b.Type() {
+	if !reflectedFirst && a.Type() != b.Type() {
 		if B, ok := b.(I__ror__); ok {
 			res, err := B.M__ror__(a)
 			if err != nil {
@@ -685,6 +936,21 @@ func IOr(a, b Object) (Object, error) {
 //
 // Will raise TypeError if can't be pow can't be run on these objects
 func Pow(a, b, c Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// rpow first so subclasses can override the operation
+	reflectedFirst := c == None && a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__rpow__); ok {
+			res, err := B.M__rpow__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to pow
 	if A, ok := a.(I__pow__); ok {
 		res, err := A.M__pow__(b, c)
@@ -697,7 +963,7 @@ func Pow(a, b, c Object) (Object, error) {
 	}
 
 	// Now using b to rpow if different in type to a
-	if c == None && a.Type() != b.Type() {
+	if !reflectedFirst && c == None && a.Type() != b.Type() {
 		if B, ok := b.(I__rpow__); ok {
 			res, err := B.M__rpow__(a)
 			if err != nil {
@@ -729,6 +995,21 @@ func IPow(a, b, c Object) (Object, error) {
 //
 // Will raise TypeError if Gt can't be run on this object
 func Gt(a Object, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// reflected method first so subclasses can override comparisons
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__lt__); ok {
+			res, err := B.M__lt__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to gt
 	if A, ok := a.(I__gt__); ok {
 		res, err := A.M__gt__(b)
@@ -741,23 +1022,40 @@ func Gt(a Object, b Object) (Object, error) {
 	}
 
 	// Try using b to lt with reversed parameters
-	if B, ok := b.(I__lt__); ok {
-		res, err := B.M__lt__(a)
-		if err != nil {
-			return nil, err
-		}
-		if res != NotImplemented {
-			return res, nil
+	if !reflectedFirst {
+		if B, ok := b.(I__lt__); ok {
+			res, err := B.M__lt__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 
-	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for >: '%s' and '%s'", a.Type().Name, b.Type().Name)
+	return nil, ExceptionNewf(TypeError, "'>' not supported between instances of '%s' and '%s'", a.Type().Name, b.Type().Name)
 }
 
 // Ge two python objects returning a boolean result
 //
 // Will raise TypeError if Ge can't be run on this object
 func Ge(a Object, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// reflected method first so subclasses can override comparisons
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__le__); ok {
+			res, err := B.M__le__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to ge
 	if A, ok := a.(I__ge__); ok {
 		res, err := A.M__ge__(b)
@@ -770,23 +1068,40 @@ func Ge(a Object, b Object) (Object, error) {
 	}
 
 	// Try using b to le with reversed parameters
-	if B, ok := b.(I__le__); ok {
-		res, err := B.M__le__(a)
-		if err != nil {
-			return nil, err
-		}
-		if res != NotImplemented {
-			return res, nil
+	if !reflectedFirst {
+		if B, ok := b.(I__le__); ok {
+			res, err := B.M__le__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 
-	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for >=: '%s' and '%s'", a.Type().Name, b.Type().Name)
+	return nil, ExceptionNewf(TypeError, "'>=' not supported between instances of '%s' and '%s'", a.Type().Name, b.Type().Name)
 }
 
 // Lt two python objects returning a boolean result
 //
 // Will raise TypeError if Lt can't be run on this object
 func Lt(a Object, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// reflected method first so subclasses can override comparisons
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__gt__); ok {
+			res, err := B.M__gt__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to lt
 	if A, ok := a.(I__lt__); ok {
 		res, err := A.M__lt__(b)
@@ -799,23 +1114,40 @@ func Lt(a Object, b Object) (Object, error) {
 	}
 
 	// Try using b to gt with reversed parameters
-	if B, ok := b.(I__gt__); ok {
-		res, err := B.M__gt__(a)
-		if err != nil {
-			return nil, err
-		}
-		if res != NotImplemented {
-			return res, nil
+	if !reflectedFirst {
+		if B, ok := b.(I__gt__); ok {
+			res, err := B.M__gt__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 
-	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for <: '%s' and '%s'", a.Type().Name, b.Type().Name)
+	return nil, ExceptionNewf(TypeError, "'<' not supported between instances of '%s' and '%s'", a.Type().Name, b.Type().Name)
 }
 
 // Le two python objects returning a boolean result
 //
 // Will raise TypeError if Le can't be run on this object
 func Le(a Object, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// reflected method first so subclasses can override comparisons
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__ge__); ok {
+			res, err := B.M__ge__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to le
 	if A, ok := a.(I__le__); ok {
 		res, err := A.M__le__(b)
@@ -828,23 +1160,40 @@ func Le(a Object, b Object) (Object, error) {
 	}
 
 	// Try using b to ge with reversed parameters
-	if B, ok := b.(I__ge__); ok {
-		res, err := B.M__ge__(a)
-		if err != nil {
-			return nil, err
-		}
-		if res != NotImplemented {
-			return res, nil
+	if !reflectedFirst {
+		if B, ok := b.(I__ge__); ok {
+			res, err := B.M__ge__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 
-	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for <=: '%s' and '%s'", a.Type().Name, b.Type().Name)
+	return nil, ExceptionNewf(TypeError, "'<=' not supported between instances of '%s' and '%s'", a.Type().Name, b.Type().Name)
 }
 
 // Eq two python objects returning a boolean result
 //
 // Will raise TypeError if Eq can't be run on this object
 func Eq(a Object, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// reflected method first so subclasses can override comparisons
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__eq__); ok {
+			res, err := B.M__eq__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to eq
 	if A, ok := a.(I__eq__); ok {
 		res, err := A.M__eq__(b)
@@ -857,27 +1206,44 @@ func Eq(a Object, b Object) (Object, error) {
 	}
 
 	// Try using b to eq with reversed parameters
-	if B, ok := b.(I__eq__); ok {
-		res, err := B.M__eq__(a)
-		if err != nil {
-			return nil, err
-		}
-		if res != NotImplemented {
-			return res, nil
+	if !reflectedFirst {
+		if B, ok := b.(I__eq__); ok {
+			res, err := B.M__eq__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 
+	// Fall back to comparing identity
 	if a.Type() != b.Type() {
 		return False, nil
 	}
-
-	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for ==: '%s' and '%s'", a.Type().Name, b.Type().Name)
+	return NewBool(a == b), nil
 }
 
 // Ne two python objects returning a boolean result
 //
 // Will raise TypeError if Ne can't be run on this object
 func Ne(a Object, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// reflected method first so subclasses can override comparisons
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__ne__); ok {
+			res, err := B.M__ne__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to ne
 	if A, ok := a.(I__ne__); ok {
 		res, err := A.M__ne__(b)
@@ -890,19 +1256,21 @@ func Ne(a Object, b Object) (Object, error) {
 	}
 
 	// Try using b to ne with reversed parameters
-	if B, ok := b.(I__ne__); ok {
-		res, err := B.M__ne__(a)
-		if err != nil {
-			return nil, err
-		}
-		if res != NotImplemented {
-			return res, nil
+	if !reflectedFirst {
+		if B, ok := b.(I__ne__); ok {
+			res, err := B.M__ne__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 
+	// Fall back to comparing identity
 	if a.Type() != b.Type() {
 		return True, nil
 	}
-
-	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for !=: '%s' and '%s'", a.Type().Name, b.Type().Name)
+	return NewBool(a != b), nil
 }
@@ -0,0 +1,68 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Audit hooks
+//
+// As in PEP 578, operations which reach outside the interpreter, such
+// as importing a module, compiling or running code, opening a file or
+// starting a process, raise an audit event before they happen.  The
+// audit hooks of the Context the code is running in are called with
+// the name of the event and its arguments.  A hook which returns an
+// error stops the operation, the error being raised in its place.
+//
+// A program embedding gpython adds hooks written in Go with
+// Context.AddAuditHook, and python code adds them with
+// sys.addaudithook.  Hooks can't be removed.  Events are raised with
+// Audit, which is sys.audit.
+//
+// The events raised by gpython itself are
+//
+//	compile(source, filename)
+//	exec(code_object)
+//	import(module, filename, sys.path, sys.meta_path, sys.path_hooks)
+//	open(path, mode, flags)
+//	subprocess.Popen(executable, args, cwd, env)
+//	sys._getframe(frame)
+//	sys.addaudithook()
+//
+// where import is only raised for modules which haven't been imported
+// already, and its filename, sys.meta_path and sys.path_hooks are
+// always None.  exec is raised for the code of the modules imported
+// as well as by exec and eval.
+
+package py
+
+// AuditHook is called with the name and arguments of each audit event
+// raised in the Context it was added to.  An error returned stops the
+// operation which raised the event.
+type AuditHook func(event string, args Tuple) error
+
+// AddAuditHook adds hook to those called for the audit events raised
+// in the context.  The hooks are called in the order they were added.
+func (ctx *Context) AddAuditHook(hook AuditHook) {
+	ctx.auditHooks = append(ctx.auditHooks, hook)
+}
+
+// Audit raises the audit event with the arguments given in the context,
+// returning the error from the first hook which returns one
+func (ctx *Context) Audit(event string, args ...Object) error {
+	if len(ctx.auditHooks) == 0 {
+		return nil
+	}
+	// Hooks may add more hooks which don't see this event
+	hooks := ctx.auditHooks
+	for _, hook := range hooks {
+		err := hook(event, Tuple(args))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Audit raises the audit event with the arguments given in the
+// CurrentContext
+func Audit(event string, args ...Object) error {
+	return CurrentContext.Audit(event, args...)
+}
@@ -7,7 +7,6 @@
 package py
 
 import (
-	"fmt"
 	"math"
 	"math/big"
 )
@@ -22,7 +21,9 @@ func (o *BigInt) Type() *Type {
 }
 
 func (a *BigInt) M__str__() (Object, error) {
-	return String(fmt.Sprintf("%d", (*big.Int)(a))), nil
+	// big.Int.Text uses a divide and conquer algorithm so is
+	// efficient even for very large numbers
+	return String((*big.Int)(a).Text(10)), nil
 }
 
 func (a *BigInt) M__repr__() (Object, error) {
@@ -221,15 +222,14 @@ func (a *BigInt) M__imul__(other Object) (Object, error) {
 }
 
 func (a *BigInt) M__truediv__(other Object) (Object, error) {
-	b, err := MakeFloat(other)
-	if err != nil {
-		return nil, err
+	fb, ok := convertToFloat(other)
+	if !ok {
+		return NotImplemented, nil
 	}
 	fa, err := a.Float()
 	if err != nil {
 		return nil, err
 	}
-	fb := b.(Float)
 	if fb == 0 {
 		return nil, divisionByZero
 	}
@@ -237,15 +237,14 @@ func (a *BigInt) M__truediv__(other Object) (Object, error) {
 }
 
 func (a *BigInt) M__rtruediv__(other Object) (Object, error) {
-	b, err := MakeFloat(other)
-	if err != nil {
-		return nil, err
+	fb, ok := convertToFloat(other)
+	if !ok {
+		return NotImplemented, nil
 	}
 	fa, err := a.Float()
 	if err != nil {
 		return nil, err
 	}
-	fb := b.(Float)
 	if fa == 0 {
 		return nil, divisionByZero
 	}
@@ -320,12 +319,32 @@ func (a *BigInt) M__rdivmod__(other Object) (Object, Object, error) {
 }
 
 // Raise to the power a**b or if m != nil, a**b mod m
+//
+// As in python the result of a modular power takes the sign of m and
+// a negative b uses the modular inverse of a.
 func (a *BigInt) pow(b, m *BigInt) (Object, error) {
+	if m != nil {
+		mBig := (*big.Int)(m)
+		if mBig.Sign() == 0 {
+			return nil, ExceptionNewf(ValueError, "pow() 3rd argument cannot be 0")
+		}
+		absM := new(big.Int).Abs(mBig)
+		base := new(big.Int).Mod((*big.Int)(a), absM)
+		exp := (*big.Int)(b)
+		if exp.Sign() < 0 {
+			if base.ModInverse(base, absM) == nil {
+				return nil, ExceptionNewf(ValueError, "base is not invertible for the given modulus")
+			}
+			exp = new(big.Int).Neg(exp)
+		}
+		r := new(big.Int).Exp(base, exp, absM)
+		if mBig.Sign() < 0 && r.Sign() != 0 {
+			r.Add(r, mBig)
+		}
+		return (*BigInt)(r).MaybeInt(), nil
+	}
 	// -ve power => make float
 	if (*big.Int)(b).Sign() < 0 {
-		if m != nil {
-			return nil, ExceptionNewf(TypeError, "pow() 2nd argument cannot be negative when 3rd argument specified")
-		}
 		fa, err := a.Float()
 		if err != nil {
 			return nil, err
@@ -336,7 +355,7 @@ func (a *BigInt) pow(b, m *BigInt) (Object, error) {
 		}
 		return fa.M__pow__(fb, None)
 	}
-	return (*BigInt)(new(big.Int).Exp((*big.Int)(a), (*big.Int)(b), (*big.Int)(m))).MaybeInt(), nil
+	return (*BigInt)(new(big.Int).Exp((*big.Int)(a), (*big.Int)(b), nil)).MaybeInt(), nil
 }
 
 func (a *BigInt) M__pow__(other, modulus Object) (Object, error) {
@@ -364,30 +383,47 @@ func (a *BigInt) M__ipow__(other, modulus Object) (Object, error) {
 	return a.M__pow__(other, modulus)
 }
 
+// Left shift a << b
+func bigLshift(a, b *BigInt) (Object, error) {
+	if (*big.Int)(b).Sign() < 0 {
+		return nil, negativeShiftCount
+	}
+	if (*big.Int)(a).Sign() == 0 {
+		return Int(0), nil
+	}
+	shift, err := b.GoInt()
+	if err != nil {
+		return nil, ExceptionNewf(OverflowError, "too many digits in integer")
+	}
+	return (*BigInt)(new(big.Int).Lsh((*big.Int)(a), uint(shift))).MaybeInt(), nil
+}
+
+// Right shift a >> b
+func bigRshift(a, b *BigInt) (Object, error) {
+	if (*big.Int)(b).Sign() < 0 {
+		return nil, negativeShiftCount
+	}
+	shift, err := b.GoInt()
+	if err != nil {
+		// Shifted out all the bits
+		if (*big.Int)(a).Sign() < 0 {
+			return Int(-1), nil
+		}
+		return Int(0), nil
+	}
+	return (*BigInt)(new(big.Int).Rsh((*big.Int)(a), uint(shift))).MaybeInt(), nil
+}
+
 func (a *BigInt) M__lshift__(other Object) (Object, error) {
 	if b, ok := ConvertToBigInt(other); ok {
-		bb, err := b.GoInt()
-		if err != nil {
-			return nil, err
-		}
-		if bb < 0 {
-			return nil, negativeShiftCount
-		}
-		return (*BigInt)(new(big.Int).Lsh((*big.Int)(a), uint(bb))).MaybeInt(), nil
+		return bigLshift(a, b)
 	}
 	return NotImplemented, nil
 }
 
 func (a *BigInt) M__rlshift__(other Object) (Object, error) {
 	if b, ok := ConvertToBigInt(other); ok {
-		aa, err := a.GoInt()
-		if err != nil {
-			return nil, err
-		}
-		if aa < 0 {
-			return nil, negativeShiftCount
-		}
-		return (*BigInt)(new(big.Int).Lsh((*big.Int)(b), uint(aa))).MaybeInt(), nil
+		return bigLshift(b, a)
 	}
 	return NotImplemented, nil
 }
@@ -398,28 +434,14 @@ func (a *BigInt) M__ilshift__(other Object) (Object, error) {
 
 func (a *BigInt) M__rshift__(other Object) (Object, error) {
 	if b, ok := ConvertToBigInt(other); ok {
-		bb, err := b.GoInt()
-		if err != nil {
-			return nil, err
-		}
-		if bb < 0 {
-			return nil, negativeShiftCount
-		}
-		return (*BigInt)(new(big.Int).Rsh((*big.Int)(a), uint(bb))).MaybeInt(), nil
+		return bigRshift(a, b)
 	}
 	return NotImplemented, nil
 }
 
 func (a *BigInt) M__rrshift__(other Object) (Object, error) {
 	if b, ok := ConvertToBigInt(other); ok {
-		aa, err := a.GoInt()
-		if err != nil {
-			return nil, err
-		}
-		if aa < 0 {
-			return nil, negativeShiftCount
-		}
-		return (*BigInt)(new(big.Int).Rsh((*big.Int)(b), uint(aa))).MaybeInt(), nil
+		return bigRshift(b, a)
 	}
 	return NotImplemented, nil
 }
@@ -477,6 +499,10 @@ func (a *BigInt) M__bool__() (Object, error) {
 	return NewBool((*big.Int)(a).Sign() != 0), nil
 }
 
+func (a *BigInt) M__hash__() (Object, error) {
+	return Int(hashBigInt((*big.Int)(a))), nil
+}
+
 func (a *BigInt) M__index__() (Int, error) {
 	return a.Int()
 }
@@ -498,6 +524,9 @@ func (a *BigInt) M__complex__() (Object, error) {
 }
 
 func (a *BigInt) M__round__(digits Object) (Object, error) {
+	if digits == None {
+		return a, nil
+	}
 	if b, ok := ConvertToBigInt(digits); ok {
 		if (*big.Int)(b).Sign() >= 0 {
 			return a, nil
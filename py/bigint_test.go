@@ -0,0 +1,41 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigIntStr(t *testing.T) {
+	for _, exp := range []int64{0, 1, 63, 64, 100, 1000, 10000, 100000} {
+		for _, neg := range []bool{false, true} {
+			x := new(big.Int).Exp(big.NewInt(3), big.NewInt(exp), nil)
+			if neg {
+				x.Neg(x)
+			}
+			res, err := (*BigInt)(x).M__str__()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(res.(String))
+			y, ok := new(big.Int).SetString(got, 10)
+			if !ok || y.Cmp(x) != 0 {
+				t.Errorf("3**%d: str doesn't round trip", exp)
+			}
+		}
+	}
+}
+
+func BenchmarkBigIntStr(b *testing.B) {
+	x := (*BigInt)(new(big.Int).Exp(big.NewInt(2), big.NewInt(100000), nil))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := x.M__str__()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
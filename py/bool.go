@@ -9,7 +9,7 @@ package py
 type Bool bool
 
 var (
-	BoolType = NewType("bool", "bool(x) -> bool\n\nReturns True when the argument x is true, False otherwise.\nThe builtins True and False are the only two instances of the class bool.\nThe class bool is a subclass of the class int, and cannot be subclassed.")
+	BoolType = IntType.NewTypeFlags("bool", "bool(x) -> bool\n\nReturns True when the argument x is true, False otherwise.\nThe builtins True and False are the only two instances of the class bool.\nThe class bool is a subclass of the class int, and cannot be subclassed.", BoolNew, nil, 0)
 	// Some well known bools
 	False = Bool(false)
 	True  = Bool(true)
@@ -20,6 +20,19 @@ func (s Bool) Type() *Type {
 	return BoolType
 }
 
+// BoolNew returns the truth of its argument, or False without one
+func BoolNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	if len(kwargs) != 0 {
+		return nil, ExceptionNewf(TypeError, "bool() takes no keyword arguments")
+	}
+	var x Object = False
+	err := UnpackTuple(args, nil, "bool", 0, 1, &x)
+	if err != nil {
+		return nil, err
+	}
+	return MakeBool(x)
+}
+
 // Make a new bool - returns the canonical True and False values
 func NewBool(t bool) Bool {
 	if t {
@@ -32,6 +45,13 @@ func (a Bool) M__bool__() (Object, error) {
 	return a, nil
 }
 
+func (a Bool) M__hash__() (Object, error) {
+	if a {
+		return Int(1), nil
+	}
+	return Int(0), nil
+}
+
 func (a Bool) M__index__() (Int, error) {
 	if a {
 		return Int(1), nil
@@ -83,14 +103,14 @@ func (a Bool) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToBool(other); ok {
 		return NewBool(a == b), nil
 	}
-	return False, nil
+	return NotImplemented, nil
 }
 
 func (a Bool) M__ne__(other Object) (Object, error) {
 	if b, ok := convertToBool(other); ok {
 		return NewBool(a != b), nil
 	}
-	return True, nil
+	return NotImplemented, nil
 }
 
 func notEq(eq Object, err error) (Object, error) {
@@ -8,13 +8,15 @@
 
 package py
 
+import "fmt"
+
 // A python BoundMethod object
 type BoundMethod struct {
 	Self   Object
 	Method Object
 }
 
-var BoundMethodType = NewType("boundmethod", "boundmethod object")
+var BoundMethodType = NewType("method", "method object")
 
 // Type of this object
 func (o *BoundMethod) Type() *Type {
@@ -43,3 +45,84 @@ func (bm *BoundMethod) M__call__(args Tuple, kwargs StringDict) (Object, error)
 	copy(newArgs[1:], args)
 	return Call(bm.Method, newArgs, kwargs)
 }
+
+func (bm *BoundMethod) M__repr__() (Object, error) {
+	name := "?"
+	if qualname, err := GetAttrString(bm.Method, "__qualname__"); err == nil {
+		if s, ok := qualname.(String); ok {
+			name = string(s)
+		}
+	}
+	self, err := ReprAsString(bm.Self)
+	if err != nil {
+		return nil, err
+	}
+	return String(fmt.Sprintf("<bound method %s of %s>", name, self)), nil
+}
+
+func (bm *BoundMethod) M__eq__(other Object) (Object, error) {
+	b, ok := other.(*BoundMethod)
+	if !ok {
+		return NotImplemented, nil
+	}
+	if !Is(bm.Self, b.Self) {
+		return False, nil
+	}
+	return Eq(bm.Method, b.Method)
+}
+
+func (bm *BoundMethod) M__ne__(other Object) (Object, error) {
+	res, err := bm.M__eq__(other)
+	if err != nil || res == NotImplemented {
+		return res, err
+	}
+	return Not(res)
+}
+
+func (bm *BoundMethod) M__hash__() (Object, error) {
+	// Combine the identity of self, if it has one, with the hash of
+	// the method so methods which compare equal have the same hash
+	x, err := HashPointer(bm.Self)
+	if err != nil {
+		x = 0
+	}
+	y, err := Hash(bm.Method)
+	if err != nil {
+		return nil, err
+	}
+	h := x ^ y
+	if h == -1 {
+		h = -2
+	}
+	return Int(h), nil
+}
+
+// Properties
+func init() {
+	BoundMethodType.Dict["__func__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*BoundMethod).Method, nil
+		},
+	}
+	BoundMethodType.Dict["__self__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*BoundMethod).Self, nil
+		},
+	}
+	// These are read from the function
+	for _, name := range []string{"__name__", "__qualname__", "__doc__", "__module__"} {
+		name := name
+		BoundMethodType.Dict[name] = &Property{
+			Fget: func(self Object) (Object, error) {
+				return GetAttrString(self.(*BoundMethod).Method, name)
+			},
+		}
+	}
+}
+
+// Check interface is satisfied
+var _ I__call__ = (*BoundMethod)(nil)
+var _ I__repr__ = (*BoundMethod)(nil)
+var _ I__eq__ = (*BoundMethod)(nil)
+var _ I__ne__ = (*BoundMethod)(nil)
+var _ I__hash__ = (*BoundMethod)(nil)
@@ -0,0 +1,414 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// ByteArray objects
+//
+// A bytearray is a mutable bytes.  The methods which don't modify
+// the contents are shared with bytes and are defined in bytes.go.
+
+package py
+
+import "bytes"
+
+var ByteArrayType = ObjectType.NewType("bytearray",
+	`bytearray(iterable_of_ints) -> bytearray
+bytearray(string, encoding[, errors]) -> bytearray
+bytearray(bytes_or_buffer) -> mutable copy of bytes_or_buffer
+bytearray(int) -> bytes array of size given by the parameter initialized with null bytes
+bytearray() -> empty bytes array
+
+Construct a mutable bytearray object from:
+  - an iterable yielding integers in range(256)
+  - a text string encoded using the specified encoding
+  - a bytes or a buffer object
+  - any object implementing the buffer API.
+  - an integer`, ByteArrayNew, nil)
+
+type ByteArray struct {
+	Data []byte
+}
+
+// Type of this ByteArray object
+func (o *ByteArray) Type() *Type {
+	return ByteArrayType
+}
+
+// ByteArrayNew
+func ByteArrayNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	b, err := newBytesFromArgs("bytearray", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return &ByteArray{Data: append([]byte{}, b...)}, nil
+}
+
+// Make a new bytearray holding a copy of b
+func NewByteArray(b []byte) *ByteArray {
+	return &ByteArray{Data: append([]byte{}, b...)}
+}
+
+// Returns the byte value of obj or an error if it isn't an int in
+// range(256)
+func byteValue(obj Object) (byte, error) {
+	value, err := IndexInt(obj)
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 || value >= 256 {
+		return 0, ExceptionNewf(ValueError, "byte must be in range(0, 256)")
+	}
+	return byte(value), nil
+}
+
+func init() {
+	ByteArrayType.Dict["append"] = MustNewMethod("append", func(self Object, args Tuple) (Object, error) {
+		var item Object
+		err := UnpackTuple(args, nil, "append", 1, 1, &item)
+		if err != nil {
+			return nil, err
+		}
+		c, err := byteValue(item)
+		if err != nil {
+			return nil, err
+		}
+		a := self.(*ByteArray)
+		a.Data = append(a.Data, c)
+		return None, nil
+	}, 0, "append(int) -> None\n\nAppend a single item to the end of the bytearray.")
+	ByteArrayType.Dict["extend"] = MustNewMethod("extend", func(self Object, args Tuple) (Object, error) {
+		var iterable Object
+		err := UnpackTuple(args, nil, "extend", 1, 1, &iterable)
+		if err != nil {
+			return nil, err
+		}
+		return None, self.(*ByteArray).Extend(iterable)
+	}, 0, "extend(iterable_of_ints) -> None\n\nAppend all the elements from the iterator or sequence to the\nend of the bytearray.")
+	ByteArrayType.Dict["insert"] = MustNewMethod("insert", func(self Object, args Tuple) (Object, error) {
+		var indexObj, item Object
+		err := UnpackTuple(args, nil, "insert", 2, 2, &indexObj, &item)
+		if err != nil {
+			return nil, err
+		}
+		a := self.(*ByteArray)
+		i, err := IndexInt(indexObj)
+		if err != nil {
+			return nil, err
+		}
+		c, err := byteValue(item)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			i += len(a.Data)
+			if i < 0 {
+				i = 0
+			}
+		} else if i > len(a.Data) {
+			i = len(a.Data)
+		}
+		a.Data = append(a.Data, 0)
+		copy(a.Data[i+1:], a.Data[i:])
+		a.Data[i] = c
+		return None, nil
+	}, 0, "insert(index, int) -> None\n\nInsert a single item into the bytearray before the given index.")
+	ByteArrayType.Dict["pop"] = MustNewMethod("pop", func(self Object, args Tuple) (Object, error) {
+		var indexObj Object = Int(-1)
+		err := UnpackTuple(args, nil, "pop", 0, 1, &indexObj)
+		if err != nil {
+			return nil, err
+		}
+		a := self.(*ByteArray)
+		if len(a.Data) == 0 {
+			return nil, ExceptionNewf(IndexError, "pop from empty bytearray")
+		}
+		i, err := IndexIntCheck(indexObj, len(a.Data))
+		if err != nil {
+			return nil, ExceptionNewf(IndexError, "pop index out of range")
+		}
+		c := a.Data[i]
+		a.Data = append(a.Data[:i], a.Data[i+1:]...)
+		return Int(c), nil
+	}, 0, "pop([index]) -> int\n\nRemove and return a single item from B. If no index\nargument is given, will pop the last value.")
+	ByteArrayType.Dict["remove"] = MustNewMethod("remove", func(self Object, args Tuple) (Object, error) {
+		var item Object
+		err := UnpackTuple(args, nil, "remove", 1, 1, &item)
+		if err != nil {
+			return nil, err
+		}
+		c, err := byteValue(item)
+		if err != nil {
+			return nil, err
+		}
+		a := self.(*ByteArray)
+		i := bytes.IndexByte(a.Data, c)
+		if i < 0 {
+			return nil, ExceptionNewf(ValueError, "value not found in bytearray")
+		}
+		a.Data = append(a.Data[:i], a.Data[i+1:]...)
+		return None, nil
+	}, 0, "remove(int) -> None\n\nRemove the first occurrence of a value in the bytearray.")
+	ByteArrayType.Dict["clear"] = MustNewMethod("clear", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "clear", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		self.(*ByteArray).Data = []byte{}
+		return None, nil
+	}, 0, "clear() -> None\n\nRemove all items from the bytearray.")
+	ByteArrayType.Dict["copy"] = MustNewMethod("copy", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "copy", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return NewByteArray(self.(*ByteArray).Data), nil
+	}, 0, "copy() -> bytearray\n\nReturn a copy of B.")
+	ByteArrayType.Dict["reverse"] = MustNewMethod("reverse", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "reverse", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		b := self.(*ByteArray).Data
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return None, nil
+	}, 0, "reverse() -> None\n\nReverse the order of the values in B in place.")
+	ByteArrayType.Dict["fromhex"] = &ClassMethod{
+		Callable: MustNewMethod("fromhex", func(self Object, args Tuple) (Object, error) {
+			b, err := bytesFromHex(args)
+			if err != nil {
+				return nil, err
+			}
+			return &ByteArray{Data: b}, nil
+		}, 0, `fromhex(string) -> bytearray
+
+Create a bytearray object from a string of hexadecimal numbers.
+Spaces between two numbers are accepted.
+Example: bytearray.fromhex('B9 01EF') -> bytearray(b'\xb9\x01\xef').`),
+	}
+}
+
+// Extends the bytearray with the bytes-like object or iterable of
+// ints passed in
+func (a *ByteArray) Extend(iterable Object) error {
+	b, err := BytesFromObject(iterable)
+	if err != nil {
+		return err
+	}
+	a.Data = append(a.Data, b...)
+	return nil
+}
+
+func (a *ByteArray) M__str__() (Object, error) {
+	return a.M__repr__()
+}
+
+func (a *ByteArray) M__repr__() (Object, error) {
+	return String("bytearray(" + bytesRepr(a.Data) + ")"), nil
+}
+
+func (a *ByteArray) M__len__() (Object, error) {
+	return Int(len(a.Data)), nil
+}
+
+func (a *ByteArray) M__bool__() (Object, error) {
+	return NewBool(len(a.Data) > 0), nil
+}
+
+func (a *ByteArray) M__iter__() (Object, error) {
+	return NewIterator(bytesItems(a.Data)), nil
+}
+
+func (a *ByteArray) M__getitem__(key Object) (Object, error) {
+	return bytesGetItem(a, a.Data, key)
+}
+
+func (a *ByteArray) M__setitem__(key, value Object) (Object, error) {
+	if slice, ok := key.(*Slice); ok {
+		start, stop, step, slicelength, err := slice.GetIndices(len(a.Data))
+		if err != nil {
+			return nil, err
+		}
+		var newBytes []byte
+		if _, isInt := value.(Int); isInt {
+			return nil, ExceptionNewf(TypeError, "can assign only bytes, buffers, or iterables of ints in range(0, 256)")
+		}
+		newBytes, err = BytesFromObject(value)
+		if err != nil {
+			return nil, err
+		}
+		if step == 1 {
+			if stop < start {
+				stop = start
+			}
+			tail := append([]byte{}, a.Data[stop:]...)
+			a.Data = append(append(a.Data[:start], newBytes...), tail...)
+		} else {
+			if len(newBytes) != slicelength {
+				return nil, ExceptionNewf(ValueError, "attempt to assign bytes of size %d to extended slice of size %d", len(newBytes), slicelength)
+			}
+			for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
+				a.Data[i] = newBytes[j]
+			}
+		}
+		return None, nil
+	}
+	i, err := IndexIntCheck(key, len(a.Data))
+	if err != nil {
+		return nil, ExceptionNewf(IndexError, "bytearray index out of range")
+	}
+	c, err := byteValue(value)
+	if err != nil {
+		return nil, err
+	}
+	a.Data[i] = c
+	return None, nil
+}
+
+func (a *ByteArray) M__delitem__(key Object) (Object, error) {
+	if slice, ok := key.(*Slice); ok {
+		start, stop, step, slicelength, err := slice.GetIndices(len(a.Data))
+		if err != nil {
+			return nil, err
+		}
+		if step == 1 {
+			if start < stop {
+				a.Data = append(a.Data[:start], a.Data[stop:]...)
+			}
+			return None, nil
+		}
+		if step < 0 {
+			// Delete the same items in ascending order
+			start += (slicelength - 1) * step
+			step = -step
+		}
+		out := a.Data[:0]
+		next := start
+		deleted := 0
+		for i, c := range a.Data {
+			if deleted < slicelength && i == next {
+				next += step
+				deleted++
+				continue
+			}
+			out = append(out, c)
+		}
+		a.Data = out
+		return None, nil
+	}
+	i, err := IndexIntCheck(key, len(a.Data))
+	if err != nil {
+		return nil, ExceptionNewf(IndexError, "bytearray index out of range")
+	}
+	a.Data = append(a.Data[:i], a.Data[i+1:]...)
+	return None, nil
+}
+
+func (a *ByteArray) M__contains__(item Object) (Object, error) {
+	return bytesContains(a.Data, item)
+}
+
+func (a *ByteArray) M__add__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return &ByteArray{Data: bytesConcat(a.Data, b)}, nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__radd__(other Object) (Object, error) {
+	if b, ok := other.(Bytes); ok {
+		return Bytes(bytesConcat(b, a.Data)), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__iadd__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		a.Data = append(a.Data, b...)
+		return a, nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__mul__(other Object) (Object, error) {
+	if n, ok := convertToInt(other); ok {
+		if err := checkRepeat(len(a.Data), 1, n); err != nil {
+			return nil, err
+		}
+		return &ByteArray{Data: bytesRepeat(a.Data, int(n))}, nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__rmul__(other Object) (Object, error) {
+	return a.M__mul__(other)
+}
+
+func (a *ByteArray) M__imul__(other Object) (Object, error) {
+	if n, ok := convertToInt(other); ok {
+		if err := checkRepeat(len(a.Data), 1, n); err != nil {
+			return nil, err
+		}
+		a.Data = bytesRepeat(a.Data, int(n))
+		return a, nil
+	}
+	return NotImplemented, nil
+}
+
+// Rich comparison
+
+func (a *ByteArray) M__lt__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(bytes.Compare(a.Data, b) < 0), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__le__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(bytes.Compare(a.Data, b) <= 0), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__eq__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(bytes.Equal(a.Data, b)), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__ne__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(!bytes.Equal(a.Data, b)), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__gt__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(bytes.Compare(a.Data, b) > 0), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__ge__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(bytes.Compare(a.Data, b) >= 0), nil
+	}
+	return NotImplemented, nil
+}
+
+// Check interface is satisfied
+var _ richComparison = (*ByteArray)(nil)
+var _ sequenceArithmetic = (*ByteArray)(nil)
+var _ I__len__ = (*ByteArray)(nil)
+var _ I__bool__ = (*ByteArray)(nil)
+var _ I__iter__ = (*ByteArray)(nil)
+var _ I__getitem__ = (*ByteArray)(nil)
+var _ I__setitem__ = (*ByteArray)(nil)
+var _ I__delitem__ = (*ByteArray)(nil)
+var _ I__contains__ = (*ByteArray)(nil)
+var _ I__iadd__ = (*ByteArray)(nil)
+var _ I__imul__ = (*ByteArray)(nil)
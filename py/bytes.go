@@ -8,6 +8,7 @@ package py
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"strings"
 )
@@ -34,13 +35,23 @@ func (o Bytes) Type() *Type {
 
 // BytesNew
 func BytesNew(metatype *Type, args Tuple, kwargs StringDict) (res Object, err error) {
+	b, err := newBytesFromArgs("bytes", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return Bytes(b), nil
+}
+
+// Makes the contents of a new bytes or bytearray from the arguments
+// passed to the constructor
+func newBytesFromArgs(name string, args Tuple, kwargs StringDict) ([]byte, error) {
 	var x Object
 	var encoding Object
 	var errors Object
 	var New Object
 	kwlist := []string{"source", "encoding", "errors"}
 
-	err = ParseTupleAndKeywords(args, kwargs, "|Oss:bytes", kwlist, &x, &encoding, &errors)
+	err := ParseTupleAndKeywords(args, kwargs, "|Oss:"+name, kwlist, &x, &encoding, &errors)
 	if err != nil {
 		return nil, err
 	}
@@ -48,29 +59,19 @@ func BytesNew(metatype *Type, args Tuple, kwargs StringDict) (res Object, err er
 		if encoding != nil || errors != nil {
 			return nil, ExceptionNewf(TypeError, "encoding or errors without sequence argument")
 		}
-		return Bytes{}, nil
+		return []byte{}, nil
 	}
 
 	if s, ok := x.(String); ok {
-		// Encode via the codec registry
 		if encoding == nil {
 			return nil, ExceptionNewf(TypeError, "string argument without an encoding")
 		}
-		encodingStr := strings.ToLower(string(encoding.(String)))
-		if encodingStr == "utf-8" || encodingStr == "utf8" {
-			return Bytes([]byte(s)), nil
-		}
-		// FIXME
-		// New = PyUnicode_AsEncodedString(x, encoding, errors)
-		// assert(PyBytes_Check(New))
-		// return New
-		return nil, ExceptionNewf(NotImplementedError, "String decode for %q not implemented", encodingStr)
+		return encodeStringArgs(string(s), encoding, errors)
 	}
 
 	// We'd like to call PyObject_Bytes here, but we need to check for an
 	// integer argument before deferring to PyBytes_FromObject, something
 	// PyObject_Bytes doesn't do.
-	var ok bool
 	if I, ok := x.(I__bytes__); ok {
 		New, err = I.M__bytes__()
 		if err != nil {
@@ -83,9 +84,10 @@ func BytesNew(metatype *Type, args Tuple, kwargs StringDict) (res Object, err er
 	} else {
 		goto no_bytes_method
 	}
-	if _, ok = New.(Bytes); !ok {
-		return nil, ExceptionNewf(TypeError, "__bytes__ returned non-bytes (type %s)", New.Type().Name)
+	if b, ok := New.(Bytes); ok {
+		return b, nil
 	}
+	return nil, ExceptionNewf(TypeError, "__bytes__ returned non-bytes (type %s)", New.Type().Name)
 no_bytes_method:
 
 	// Is it an integer?
@@ -99,7 +101,11 @@ no_bytes_method:
 		if size < 0 {
 			return nil, ExceptionNewf(ValueError, "negative count")
 		}
-		return make(Bytes, size), nil
+		err = checkAlloc(uint64(size), 1)
+		if err != nil {
+			return nil, err
+		}
+		return make([]byte, size), nil
 	}
 
 	// If it's not unicode, there can't be encoding or errors
@@ -110,6 +116,52 @@ no_bytes_method:
 	return BytesFromObject(x)
 }
 
+// Encodes s using the encoding and errors arguments, either of which
+// may be nil
+func encodeStringArgs(s string, encoding, errors Object) ([]byte, error) {
+	codec, errorsStr, err := codecArgs(encoding, errors)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(s, errorsStr)
+}
+
+// Decodes b using the encoding and errors arguments, either of which
+// may be nil
+func decodeBytesArgs(b []byte, encoding, errors Object) (String, error) {
+	codec, errorsStr, err := codecArgs(encoding, errors)
+	if err != nil {
+		return "", err
+	}
+	s, err := codec.Decode(b, errorsStr)
+	if err != nil {
+		return "", err
+	}
+	return String(s), nil
+}
+
+// Returns the codec and error handler for the encoding and errors
+// arguments, either of which may be nil
+func codecArgs(encoding, errors Object) (*Codec, string, error) {
+	encodingStr := "utf-8"
+	if encoding != nil {
+		encodingStr = string(encoding.(String))
+	}
+	errorsStr := "strict"
+	if errors != nil {
+		errorsStr = string(errors.(String))
+	}
+	codec, err := LookupCodec(encodingStr)
+	if err != nil {
+		return nil, "", err
+	}
+	err = checkErrors(errorsStr)
+	if err != nil {
+		return nil, "", err
+	}
+	return codec, errorsStr, nil
+}
+
 // Converts an object into bytes
 func BytesFromObject(x Object) (Bytes, error) {
 	// Look for special cases
@@ -118,10 +170,16 @@ func BytesFromObject(x Object) (Bytes, error) {
 	case Bytes:
 		// Immutable type so just return what was passed in
 		return z, nil
+	case *ByteArray:
+		return append(Bytes{}, z.Data...), nil
 	case String:
 		return nil, ExceptionNewf(TypeError, "cannot convert unicode object to bytes")
 	}
 	// Otherwise iterate through the whatever converting it into ints
+	err := checkSized(x, 1)
+	if err != nil {
+		return nil, err
+	}
 	b := Bytes{}
 	var loopErr error
 	iterErr := Iterate(x, func(item Object) bool {
@@ -151,6 +209,11 @@ func (a Bytes) M__str__() (Object, error) {
 }
 
 func (a Bytes) M__repr__() (Object, error) {
+	return String(bytesRepr(a)), nil
+}
+
+// Returns the b'...' representation of the bytes in a
+func bytesRepr(a []byte) string {
 	// FIXME combine this with parser/stringescape.go into file in py?
 	var out bytes.Buffer
 	quote := '\''
@@ -182,7 +245,7 @@ func (a Bytes) M__repr__() (Object, error) {
 		}
 	}
 	out.WriteRune(quote)
-	return String(out.String()), nil
+	return out.String()
 }
 
 // Convert an Object to an Bytes
@@ -192,6 +255,8 @@ func convertToBytes(other Object) (Bytes, bool) {
 	switch b := other.(type) {
 	case Bytes:
 		return b, true
+	case *ByteArray:
+		return b.Data, true
 	}
 	return []byte(nil), false
 }
@@ -212,6 +277,10 @@ func (a Bytes) M__le__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Bytes) M__hash__() (Object, error) {
+	return Int(hashBytes([]byte(a))), nil
+}
+
 func (a Bytes) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToBytes(other); ok {
 		return NewBool(bytes.Compare(a, b) == 0), nil
@@ -242,3 +311,1251 @@ func (a Bytes) M__ge__(other Object) (Object, error) {
 
 // Check interface is satisfied
 var _ richComparison = (Bytes)(nil)
+
+// Sequence protocol
+
+func (a Bytes) M__len__() (Object, error) {
+	return Int(len(a)), nil
+}
+
+func (a Bytes) M__bool__() (Object, error) {
+	return NewBool(len(a) > 0), nil
+}
+
+func (a Bytes) M__iter__() (Object, error) {
+	return NewIterator(bytesItems(a)), nil
+}
+
+func (a Bytes) M__getitem__(key Object) (Object, error) {
+	return bytesGetItem(a, a, key)
+}
+
+func (a Bytes) M__contains__(item Object) (Object, error) {
+	return bytesContains(a, item)
+}
+
+func (a Bytes) M__add__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return Bytes(bytesConcat(a, b)), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a Bytes) M__radd__(other Object) (Object, error) {
+	if b, ok := other.(Bytes); ok {
+		return Bytes(bytesConcat(b, a)), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a Bytes) M__iadd__(other Object) (Object, error) {
+	return a.M__add__(other)
+}
+
+func (a Bytes) M__mul__(other Object) (Object, error) {
+	if n, ok := convertToInt(other); ok {
+		if err := checkRepeat(len(a), 1, n); err != nil {
+			return nil, err
+		}
+		return Bytes(bytesRepeat(a, int(n))), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a Bytes) M__rmul__(other Object) (Object, error) {
+	return a.M__mul__(other)
+}
+
+func (a Bytes) M__imul__(other Object) (Object, error) {
+	return a.M__mul__(other)
+}
+
+// Returns the bytes in b as a slice of Int
+func bytesItems(b []byte) []Object {
+	items := make([]Object, len(b))
+	for i, c := range b {
+		items[i] = Int(c)
+	}
+	return items
+}
+
+// Implements __getitem__ for bytes and bytearray returning slices of
+// the same type as self
+func bytesGetItem(self Object, b []byte, key Object) (Object, error) {
+	if slice, ok := key.(*Slice); ok {
+		start, stop, step, slicelength, err := slice.GetIndices(len(b))
+		if err != nil {
+			return nil, err
+		}
+		if step == 1 {
+			if start >= stop {
+				return newBytesLike(self, nil), nil
+			}
+			return newBytesLike(self, b[start:stop]), nil
+		}
+		newBytes := make([]byte, slicelength)
+		for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
+			newBytes[j] = b[i]
+		}
+		return newBytesLike(self, newBytes), nil
+	}
+	i, err := IndexIntCheck(key, len(b))
+	if err != nil {
+		return nil, err
+	}
+	return Int(b[i]), nil
+}
+
+// Implements __contains__ for bytes and bytearray
+func bytesContains(b []byte, item Object) (Object, error) {
+	sub, err := bytesSubArg(item)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(bytes.Contains(b, sub)), nil
+}
+
+// Returns a new slice with b appended to a
+func bytesConcat(a, b []byte) []byte {
+	out := make([]byte, len(a)+len(b))
+	copy(out, a)
+	copy(out[len(a):], b)
+	return out
+}
+
+// Returns a new slice with n copies of b
+func bytesRepeat(b []byte, n int) []byte {
+	if n <= 0 {
+		return []byte{}
+	}
+	return bytes.Repeat(b, n)
+}
+
+// Methods shared by bytes and bytearray
+//
+// These work on the underlying []byte and return results of the same
+// type as self.
+
+// Returns the contents of a bytes or bytearray method receiver
+func bytesSelf(self Object) []byte {
+	switch b := self.(type) {
+	case Bytes:
+		return b
+	case *ByteArray:
+		return b.Data
+	}
+	panic(fmt.Sprintf("expecting bytes or bytearray, got %s", self.Type().Name))
+}
+
+// Returns a new object of the same type as self holding b
+//
+// A bytearray always gets a copy of b so it doesn't share storage
+// with self.
+func newBytesLike(self Object, b []byte) Object {
+	if _, ok := self.(*ByteArray); ok {
+		return &ByteArray{Data: append([]byte{}, b...)}
+	}
+	if b == nil {
+		return Bytes{}
+	}
+	return Bytes(b)
+}
+
+// Returns the bytes of a bytes-like argument or a TypeError
+func bytesArg(obj Object) ([]byte, error) {
+	if b, ok := convertToBytes(obj); ok {
+		return b, nil
+	}
+	return nil, ExceptionNewf(TypeError, "a bytes-like object is required, not '%s'", obj.Type().Name)
+}
+
+// Returns the bytes of a subsequence argument which may be a
+// bytes-like object or an int in range(256)
+func bytesSubArg(obj Object) ([]byte, error) {
+	if b, ok := convertToBytes(obj); ok {
+		return b, nil
+	}
+	switch obj.(type) {
+	case Int, *BigInt, Bool:
+		value, err := IndexInt(obj)
+		if err != nil {
+			return nil, err
+		}
+		if value < 0 || value >= 256 {
+			return nil, ExceptionNewf(ValueError, "byte must be in range(0, 256)")
+		}
+		return []byte{byte(value)}, nil
+	}
+	return nil, ExceptionNewf(TypeError, "argument should be integer or bytes-like object, not '%s'", obj.Type().Name)
+}
+
+// Converts the optional start and end arguments of a method into
+// indices into a sequence of length.  start may be > end if the
+// range is empty.
+func sliceIndices(startObj, endObj Object, length int) (start, end int, err error) {
+	start, end = 0, length
+	if startObj != nil && startObj != None {
+		start, err = IndexInt(startObj)
+		if err != nil {
+			return 0, 0, err
+		}
+		if start < 0 {
+			start += length
+			if start < 0 {
+				start = 0
+			}
+		}
+	}
+	if endObj != nil && endObj != None {
+		end, err = IndexInt(endObj)
+		if err != nil {
+			return 0, 0, err
+		}
+		if end < 0 {
+			end += length
+			if end < 0 {
+				end = 0
+			}
+		} else if end > length {
+			end = length
+		}
+	}
+	return start, end, nil
+}
+
+// Implements find, rfind, index and rindex returning the index of
+// sub in self or -1 if not found
+func bytesFind(self Object, args Tuple, name string, right bool) (int, error) {
+	var subObj, startObj, endObj Object
+	err := UnpackTuple(args, nil, name, 1, 3, &subObj, &startObj, &endObj)
+	if err != nil {
+		return 0, err
+	}
+	sub, err := bytesSubArg(subObj)
+	if err != nil {
+		return 0, err
+	}
+	b := bytesSelf(self)
+	start, end, err := sliceIndices(startObj, endObj, len(b))
+	if err != nil {
+		return 0, err
+	}
+	if start > end {
+		return -1, nil
+	}
+	var i int
+	if right {
+		i = bytes.LastIndex(b[start:end], sub)
+	} else {
+		i = bytes.Index(b[start:end], sub)
+	}
+	if i < 0 {
+		return -1, nil
+	}
+	return start + i, nil
+}
+
+// Implements startswith and endswith
+func bytesAffix(self Object, args Tuple, name string, suffix bool) (Object, error) {
+	var affixObj, startObj, endObj Object
+	err := UnpackTuple(args, nil, name, 1, 3, &affixObj, &startObj, &endObj)
+	if err != nil {
+		return nil, err
+	}
+	var affixes []Object
+	if t, ok := affixObj.(Tuple); ok {
+		affixes = t
+	} else {
+		affixes = []Object{affixObj}
+	}
+	b := bytesSelf(self)
+	start, end, err := sliceIndices(startObj, endObj, len(b))
+	if err != nil {
+		return nil, err
+	}
+	for _, affixObj := range affixes {
+		affix, ok := convertToBytes(affixObj)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "%s first arg must be bytes or a tuple of bytes, not %s", name, affixObj.Type().Name)
+		}
+		if start > end {
+			continue
+		}
+		if suffix && bytes.HasSuffix(b[start:end], affix) || !suffix && bytes.HasPrefix(b[start:end], affix) {
+			return True, nil
+		}
+	}
+	return False, nil
+}
+
+// Reports whether c is ASCII whitespace
+func isSpaceByte(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\x0b', '\x0c':
+		return true
+	}
+	return false
+}
+
+// Splits b on runs of whitespace doing at most maxsplit splits if
+// maxsplit >= 0
+func bytesSplitWhitespace(b []byte, maxsplit int) [][]byte {
+	var out [][]byte
+	i := 0
+	for {
+		for i < len(b) && isSpaceByte(b[i]) {
+			i++
+		}
+		if i == len(b) {
+			break
+		}
+		if maxsplit >= 0 && len(out) == maxsplit {
+			out = append(out, b[i:])
+			break
+		}
+		j := i
+		for j < len(b) && !isSpaceByte(b[j]) {
+			j++
+		}
+		out = append(out, b[i:j])
+		i = j
+	}
+	return out
+}
+
+// As bytesSplitWhitespace but splitting from the right
+func bytesRsplitWhitespace(b []byte, maxsplit int) [][]byte {
+	var out [][]byte
+	j := len(b)
+	for {
+		for j > 0 && isSpaceByte(b[j-1]) {
+			j--
+		}
+		if j == 0 {
+			break
+		}
+		if maxsplit >= 0 && len(out) == maxsplit {
+			out = append(out, b[:j])
+			break
+		}
+		i := j
+		for i > 0 && !isSpaceByte(b[i-1]) {
+			i--
+		}
+		out = append(out, b[i:j])
+		j = i
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// Splits b on sep from the right into at most n pieces if n >= 0
+func bytesRsplitN(b, sep []byte, n int) [][]byte {
+	var out [][]byte
+	for n < 0 || len(out) < n-1 {
+		i := bytes.LastIndex(b, sep)
+		if i < 0 {
+			break
+		}
+		out = append(out, b[i+len(sep):])
+		b = b[:i]
+	}
+	out = append(out, b)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// Implements split and rsplit
+func bytesSplit(self Object, args Tuple, kwargs StringDict, name string, right bool) (Object, error) {
+	var sepObj Object = None
+	var maxsplitObj Object
+	kwlist := []string{"sep", "maxsplit"}
+	err := ParseTupleAndKeywords(args, kwargs, "|OO:"+name, kwlist, &sepObj, &maxsplitObj)
+	if err != nil {
+		return nil, err
+	}
+	maxsplit := -1
+	if maxsplitObj != nil {
+		maxsplit, err = IndexInt(maxsplitObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	b := bytesSelf(self)
+	var fields [][]byte
+	if sepObj == None {
+		if right {
+			fields = bytesRsplitWhitespace(b, maxsplit)
+		} else {
+			fields = bytesSplitWhitespace(b, maxsplit)
+		}
+	} else {
+		sep, err := bytesArg(sepObj)
+		if err != nil {
+			return nil, err
+		}
+		if len(sep) == 0 {
+			return nil, ExceptionNewf(ValueError, "empty separator")
+		}
+		n := -1
+		if maxsplit >= 0 {
+			n = maxsplit + 1
+		}
+		if right {
+			fields = bytesRsplitN(b, sep, n)
+		} else {
+			fields = bytes.SplitN(b, sep, n)
+		}
+	}
+	o := NewListSized(len(fields))
+	for i, field := range fields {
+		o.Items[i] = newBytesLike(self, field)
+	}
+	return o, nil
+}
+
+// Implements partition and rpartition
+func bytesPartition(self Object, args Tuple, name string, right bool) (Object, error) {
+	var sepObj Object
+	err := UnpackTuple(args, nil, name, 1, 1, &sepObj)
+	if err != nil {
+		return nil, err
+	}
+	sep, err := bytesArg(sepObj)
+	if err != nil {
+		return nil, err
+	}
+	if len(sep) == 0 {
+		return nil, ExceptionNewf(ValueError, "empty separator")
+	}
+	b := bytesSelf(self)
+	var i int
+	if right {
+		i = bytes.LastIndex(b, sep)
+		if i < 0 {
+			return Tuple{newBytesLike(self, nil), newBytesLike(self, nil), newBytesLike(self, b)}, nil
+		}
+	} else {
+		i = bytes.Index(b, sep)
+		if i < 0 {
+			return Tuple{newBytesLike(self, b), newBytesLike(self, nil), newBytesLike(self, nil)}, nil
+		}
+	}
+	return Tuple{newBytesLike(self, b[:i]), newBytesLike(self, sep), newBytesLike(self, b[i+len(sep):])}, nil
+}
+
+// Implements strip, lstrip and rstrip
+func bytesStrip(self Object, args Tuple, name string, left, right bool) (Object, error) {
+	var charsObj Object = None
+	err := UnpackTuple(args, nil, name, 0, 1, &charsObj)
+	if err != nil {
+		return nil, err
+	}
+	var strip [256]bool
+	if charsObj == None {
+		for _, c := range []byte(" \t\n\r\x0b\x0c") {
+			strip[c] = true
+		}
+	} else {
+		chars, err := bytesArg(charsObj)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range chars {
+			strip[c] = true
+		}
+	}
+	b := bytesSelf(self)
+	i, j := 0, len(b)
+	if left {
+		for i < j && strip[b[i]] {
+			i++
+		}
+	}
+	if right {
+		for j > i && strip[b[j-1]] {
+			j--
+		}
+	}
+	return newBytesLike(self, b[i:j]), nil
+}
+
+// Implements replace
+func bytesReplace(self Object, args Tuple) (Object, error) {
+	var oldObj, newObj Object
+	var countObj Object = Int(-1)
+	err := UnpackTuple(args, nil, "replace", 2, 3, &oldObj, &newObj, &countObj)
+	if err != nil {
+		return nil, err
+	}
+	old, err := bytesArg(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	new, err := bytesArg(newObj)
+	if err != nil {
+		return nil, err
+	}
+	count, err := IndexInt(countObj)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	if len(old) != 0 {
+		return newBytesLike(self, bytes.Replace(b, old, new, count)), nil
+	}
+	// An empty old matches between every byte - bytes.Replace
+	// would match between every UTF-8 sequence instead
+	var out []byte
+	for i := 0; i <= len(b); i++ {
+		if count < 0 || i < count {
+			out = append(out, new...)
+		}
+		if i < len(b) {
+			out = append(out, b[i])
+		}
+	}
+	return newBytesLike(self, out), nil
+}
+
+// Implements join
+func bytesJoin(self Object, args Tuple) (Object, error) {
+	var iterable Object
+	err := UnpackTuple(args, nil, "join", 1, 1, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	sep := bytesSelf(self)
+	var out []byte
+	var loopErr error
+	i := 0
+	err = Iterate(iterable, func(item Object) bool {
+		b, ok := convertToBytes(item)
+		if !ok {
+			loopErr = ExceptionNewf(TypeError, "sequence item %d: expected a bytes-like object, %s found", i, item.Type().Name)
+			return true
+		}
+		if i > 0 {
+			out = append(out, sep...)
+		}
+		out = append(out, b...)
+		i++
+		return false
+	})
+	if err == nil {
+		err = loopErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newBytesLike(self, out), nil
+}
+
+// Returns a copy of self with fn applied to each byte
+func bytesMap(self Object, args Tuple, name string, fn func(c byte) byte) (Object, error) {
+	err := UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	out := make([]byte, len(b))
+	for i, c := range b {
+		out[i] = fn(c)
+	}
+	return newBytesLike(self, out), nil
+}
+
+// Returns True if self is not empty and fn is true for every byte
+func bytesIs(self Object, args Tuple, name string, fn func(c byte) bool) (Object, error) {
+	err := UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	if len(b) == 0 {
+		return False, nil
+	}
+	for _, c := range b {
+		if !fn(c) {
+			return False, nil
+		}
+	}
+	return True, nil
+}
+
+func isLowerByte(c byte) bool { return 'a' <= c && c <= 'z' }
+func isUpperByte(c byte) bool { return 'A' <= c && c <= 'Z' }
+func isDigitByte(c byte) bool { return '0' <= c && c <= '9' }
+func isAlphaByte(c byte) bool { return isLowerByte(c) || isUpperByte(c) }
+
+func upperByte(c byte) byte {
+	if isLowerByte(c) {
+		c -= 'a' - 'A'
+	}
+	return c
+}
+
+func lowerByte(c byte) byte {
+	if isUpperByte(c) {
+		c += 'a' - 'A'
+	}
+	return c
+}
+
+// Returns a copy of self with fn applied to each byte along with
+// whether the byte before it was a letter
+func bytesMapCased(self Object, args Tuple, name string, fn func(c byte, afterCased bool) byte) (Object, error) {
+	err := UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	out := make([]byte, len(b))
+	afterCased := false
+	for i, c := range b {
+		out[i] = fn(c, afterCased)
+		afterCased = isAlphaByte(c)
+	}
+	return newBytesLike(self, out), nil
+}
+
+// Implements islower and isupper, which are True if self has a byte
+// which is cased and none of the other case
+func bytesIsCase(self Object, args Tuple, name string, isCase, isOther func(c byte) bool) (Object, error) {
+	err := UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	cased := false
+	for _, c := range bytesSelf(self) {
+		if isOther(c) {
+			return False, nil
+		}
+		cased = cased || isCase(c)
+	}
+	return NewBool(cased), nil
+}
+
+// Implements istitle
+func bytesIsTitle(self Object, args Tuple) (Object, error) {
+	err := UnpackTuple(args, nil, "istitle", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	cased, afterCased := false, false
+	for _, c := range bytesSelf(self) {
+		switch {
+		case isUpperByte(c):
+			if afterCased {
+				return False, nil
+			}
+			cased, afterCased = true, true
+		case isLowerByte(c):
+			if !afterCased {
+				return False, nil
+			}
+			cased = true
+		default:
+			afterCased = false
+		}
+	}
+	return NewBool(cased), nil
+}
+
+// Implements center, ljust and rjust
+func bytesJustify(self Object, args Tuple, name string, align byte) (Object, error) {
+	var widthObj Object
+	var fillObj Object = Bytes(" ")
+	err := UnpackTuple(args, nil, name, 1, 2, &widthObj, &fillObj)
+	if err != nil {
+		return nil, err
+	}
+	width, err := IndexInt(widthObj)
+	if err != nil {
+		return nil, err
+	}
+	fill, ok := convertToBytes(fillObj)
+	if !ok || len(fill) != 1 {
+		return nil, ExceptionNewf(TypeError, "%s() argument 2 must be a byte string of length 1, not %s", name, fillObj.Type().Name)
+	}
+	b := bytesSelf(self)
+	n := width - len(b)
+	if n <= 0 {
+		return newBytesLike(self, b), nil
+	}
+	err = checkAlloc(uint64(width), 1)
+	if err != nil {
+		return nil, err
+	}
+	left := 0
+	switch align {
+	case '>':
+		left = n
+	case '^':
+		left = n/2 + (n & width & 1)
+	}
+	out := make([]byte, 0, width)
+	out = append(out, bytes.Repeat(fill, left)...)
+	out = append(out, b...)
+	out = append(out, bytes.Repeat(fill, n-left)...)
+	return newBytesLike(self, out), nil
+}
+
+// Implements zfill
+func bytesZfill(self Object, args Tuple) (Object, error) {
+	var widthObj Object
+	err := UnpackTuple(args, nil, "zfill", 1, 1, &widthObj)
+	if err != nil {
+		return nil, err
+	}
+	width, err := IndexInt(widthObj)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	n := width - len(b)
+	if n <= 0 {
+		return newBytesLike(self, b), nil
+	}
+	err = checkAlloc(uint64(width), 1)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, width)
+	if len(b) > 0 && (b[0] == '+' || b[0] == '-') {
+		out = append(out, b[0])
+		b = b[1:]
+	}
+	out = append(out, bytes.Repeat([]byte{'0'}, n)...)
+	out = append(out, b...)
+	return newBytesLike(self, out), nil
+}
+
+// Implements expandtabs
+func bytesExpandTabs(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	var tabsizeObj Object = Int(8)
+	err := ParseTupleAndKeywords(args, kwargs, "|O:expandtabs", []string{"tabsize"}, &tabsizeObj)
+	if err != nil {
+		return nil, err
+	}
+	tabsize, err := IndexInt(tabsizeObj)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	if tabsize > 0 {
+		err = checkRepeat(tabsize, 1, Int(bytes.Count(b, []byte{'\t'})))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out []byte
+	column := 0
+	for _, c := range b {
+		switch c {
+		case '\t':
+			if tabsize > 0 {
+				n := tabsize - column%tabsize
+				out = append(out, bytes.Repeat([]byte{' '}, n)...)
+				column += n
+			}
+		case '\n', '\r':
+			out = append(out, c)
+			column = 0
+		default:
+			out = append(out, c)
+			column++
+		}
+	}
+	return newBytesLike(self, out), nil
+}
+
+// Implements splitlines
+func bytesSplitLines(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	var keepends Object = False
+	err := ParseTupleAndKeywords(args, kwargs, "|O:splitlines", []string{"keepends"}, &keepends)
+	if err != nil {
+		return nil, err
+	}
+	keep, err := MakeBool(keepends)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	o := NewList()
+	start := 0
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\n' && b[i] != '\r' {
+			continue
+		}
+		end := i + 1
+		if b[i] == '\r' && end < len(b) && b[end] == '\n' {
+			end++
+		}
+		if keep == True {
+			o.Append(newBytesLike(self, b[start:end]))
+		} else {
+			o.Append(newBytesLike(self, b[start:i]))
+		}
+		start = end
+		i = end - 1
+	}
+	if start < len(b) {
+		o.Append(newBytesLike(self, b[start:]))
+	}
+	return o, nil
+}
+
+// Implements removeprefix and removesuffix
+func bytesRemoveAffix(self Object, args Tuple, name string, suffix bool) (Object, error) {
+	var affixObj Object
+	err := UnpackTuple(args, nil, name, 1, 1, &affixObj)
+	if err != nil {
+		return nil, err
+	}
+	affix, err := bytesArg(affixObj)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	if suffix {
+		if len(affix) > 0 && bytes.HasSuffix(b, affix) {
+			b = b[:len(b)-len(affix)]
+		}
+	} else if bytes.HasPrefix(b, affix) {
+		b = b[len(affix):]
+	}
+	return newBytesLike(self, b), nil
+}
+
+// Implements translate
+func bytesTranslate(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	var tableObj Object
+	var deleteObj Object = Bytes{}
+	err := ParseTupleAndKeywords(args, kwargs, "O|O:translate", []string{"table", "delete"}, &tableObj, &deleteObj)
+	if err != nil {
+		return nil, err
+	}
+	var table []byte
+	if tableObj != None {
+		table, err = bytesArg(tableObj)
+		if err != nil {
+			return nil, err
+		}
+		if len(table) != 256 {
+			return nil, ExceptionNewf(ValueError, "translation table must be 256 characters long")
+		}
+	}
+	del, err := bytesArg(deleteObj)
+	if err != nil {
+		return nil, err
+	}
+	var deleted [256]bool
+	for _, c := range del {
+		deleted[c] = true
+	}
+	b := bytesSelf(self)
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if deleted[c] {
+			continue
+		}
+		if table != nil {
+			c = table[c]
+		}
+		out = append(out, c)
+	}
+	return newBytesLike(self, out), nil
+}
+
+// Implements maketrans for bytes and bytearray
+func bytesMakeTrans(self Object, args Tuple) (Object, error) {
+	var fromObj, toObj Object
+	err := UnpackTuple(args, nil, "maketrans", 2, 2, &fromObj, &toObj)
+	if err != nil {
+		return nil, err
+	}
+	from, err := bytesArg(fromObj)
+	if err != nil {
+		return nil, err
+	}
+	to, err := bytesArg(toObj)
+	if err != nil {
+		return nil, err
+	}
+	if len(from) != len(to) {
+		return nil, ExceptionNewf(ValueError, "maketrans arguments must have same length")
+	}
+	table := make(Bytes, 256)
+	for i := range table {
+		table[i] = byte(i)
+	}
+	for i, c := range from {
+		table[c] = to[i]
+	}
+	return table, nil
+}
+
+// Implements hex, putting sep between every bytesPerSep bytes
+// counting from the right, or from the left if it is negative
+func bytesHex(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	var sepObj Object = None
+	var bytesPerSepObj Object = Int(1)
+	err := ParseTupleAndKeywords(args, kwargs, "|OO:hex", []string{"sep", "bytes_per_sep"}, &sepObj, &bytesPerSepObj)
+	if err != nil {
+		return nil, err
+	}
+	b := bytesSelf(self)
+	if sepObj == None {
+		return String(hex.EncodeToString(b)), nil
+	}
+	var sep string
+	switch x := sepObj.(type) {
+	case String:
+		sep = string(x)
+	default:
+		sepBytes, ok := convertToBytes(sepObj)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "sep must be str or bytes.")
+		}
+		sep = string(sepBytes)
+	}
+	if len(sep) != 1 || sep[0] >= 0x80 {
+		return nil, ExceptionNewf(ValueError, "sep must be ASCII.")
+	}
+	bytesPerSep, err := IndexInt(bytesPerSepObj)
+	if err != nil {
+		return nil, err
+	}
+	if bytesPerSep == 0 || len(b) == 0 {
+		return String(hex.EncodeToString(b)), nil
+	}
+	group := bytesPerSep
+	if group < 0 {
+		group = -group
+	}
+	var out strings.Builder
+	for i, c := range b {
+		if i > 0 {
+			if bytesPerSep > 0 && (len(b)-i)%group == 0 || bytesPerSep < 0 && i%group == 0 {
+				out.WriteString(sep)
+			}
+		}
+		out.WriteString(hex.EncodeToString([]byte{c}))
+	}
+	return String(out.String()), nil
+}
+
+// Implements fromhex for bytes and bytearray
+func bytesFromHex(args Tuple) ([]byte, error) {
+	var sObj Object
+	err := UnpackTuple(args, nil, "fromhex", 1, 1, &sObj)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := sObj.(String)
+	if !ok {
+		return nil, ExceptionNewf(TypeError, "fromhex() argument must be str, not %s", sObj.Type().Name)
+	}
+	var out []byte
+	for i := 0; i < len(s); {
+		if isSpaceByte(s[i]) {
+			i++
+			continue
+		}
+		if i+1 >= len(s) {
+			return nil, ExceptionNewf(ValueError, "non-hexadecimal number found in fromhex() arg at position %d", i+1)
+		}
+		c, err := hex.DecodeString(string(s[i : i+2]))
+		if err != nil {
+			pos := i
+			if _, err := hex.DecodeString("0" + string(s[i])); err == nil {
+				pos++
+			}
+			return nil, ExceptionNewf(ValueError, "non-hexadecimal number found in fromhex() arg at position %d", pos)
+		}
+		out = append(out, c...)
+		i += 2
+	}
+	return out, nil
+}
+
+func init() {
+	for _, t := range []*Type{BytesType, ByteArrayType} {
+		t.Dict["decode"] = MustNewMethod("decode", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			var encoding, errors Object
+			kwlist := []string{"encoding", "errors"}
+			err := ParseTupleAndKeywords(args, kwargs, "|ss:decode", kwlist, &encoding, &errors)
+			if err != nil {
+				return nil, err
+			}
+			return decodeBytesArgs(bytesSelf(self), encoding, errors)
+		}, 0, `decode(encoding='utf-8', errors='strict') -> str
+
+Decode the bytes using the codec registered for encoding.
+errors may be 'strict', 'ignore' or 'replace'.`)
+		t.Dict["find"] = MustNewMethod("find", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "find", false)
+			return Int(i), err
+		}, 0, `find(sub[, start[, end]]) -> int
+
+Return the lowest index where subsection sub is found, such that sub
+is contained within [start,end].  Return -1 on failure.`)
+		t.Dict["rfind"] = MustNewMethod("rfind", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "rfind", true)
+			return Int(i), err
+		}, 0, `rfind(sub[, start[, end]]) -> int
+
+Return the highest index where subsection sub is found, such that sub
+is contained within [start,end].  Return -1 on failure.`)
+		t.Dict["index"] = MustNewMethod("index", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "index", false)
+			if err == nil && i < 0 {
+				err = ExceptionNewf(ValueError, "subsection not found")
+			}
+			return Int(i), err
+		}, 0, `index(sub[, start[, end]]) -> int
+
+Like find() but raise ValueError when the subsection is not found.`)
+		t.Dict["rindex"] = MustNewMethod("rindex", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "rindex", true)
+			if err == nil && i < 0 {
+				err = ExceptionNewf(ValueError, "subsection not found")
+			}
+			return Int(i), err
+		}, 0, `rindex(sub[, start[, end]]) -> int
+
+Like rfind() but raise ValueError when the subsection is not found.`)
+		t.Dict["count"] = MustNewMethod("count", func(self Object, args Tuple) (Object, error) {
+			var subObj, startObj, endObj Object
+			err := UnpackTuple(args, nil, "count", 1, 3, &subObj, &startObj, &endObj)
+			if err != nil {
+				return nil, err
+			}
+			sub, err := bytesSubArg(subObj)
+			if err != nil {
+				return nil, err
+			}
+			b := bytesSelf(self)
+			start, end, err := sliceIndices(startObj, endObj, len(b))
+			if err != nil {
+				return nil, err
+			}
+			if start > end {
+				return Int(0), nil
+			}
+			if len(sub) == 0 {
+				return Int(end - start + 1), nil
+			}
+			return Int(bytes.Count(b[start:end], sub)), nil
+		}, 0, `count(sub[, start[, end]]) -> int
+
+Return the number of non-overlapping occurrences of subsection sub in
+bytes B[start:end].`)
+		t.Dict["startswith"] = MustNewMethod("startswith", func(self Object, args Tuple) (Object, error) {
+			return bytesAffix(self, args, "startswith", false)
+		}, 0, `startswith(prefix[, start[, end]]) -> bool
+
+Return True if B starts with the specified prefix, False otherwise.
+prefix can also be a tuple of bytes to try.`)
+		t.Dict["endswith"] = MustNewMethod("endswith", func(self Object, args Tuple) (Object, error) {
+			return bytesAffix(self, args, "endswith", true)
+		}, 0, `endswith(suffix[, start[, end]]) -> bool
+
+Return True if B ends with the specified suffix, False otherwise.
+suffix can also be a tuple of bytes to try.`)
+		t.Dict["split"] = MustNewMethod("split", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			return bytesSplit(self, args, kwargs, "split", false)
+		}, 0, `split(sep=None, maxsplit=-1) -> list of bytes
+
+Return a list of the sections in B, using sep as the delimiter.  If
+sep is not specified or is None, B is split on ASCII whitespace
+characters and empty sections are removed from the result.`)
+		t.Dict["rsplit"] = MustNewMethod("rsplit", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			return bytesSplit(self, args, kwargs, "rsplit", true)
+		}, 0, `rsplit(sep=None, maxsplit=-1) -> list of bytes
+
+As split() but splitting from the end of B.`)
+		t.Dict["partition"] = MustNewMethod("partition", func(self Object, args Tuple) (Object, error) {
+			return bytesPartition(self, args, "partition", false)
+		}, 0, `partition(sep) -> (head, sep, tail)
+
+Search for the separator sep in B, and return the part before it, the
+separator itself, and the part after it.  If the separator is not
+found, returns B and two empty bytes objects.`)
+		t.Dict["rpartition"] = MustNewMethod("rpartition", func(self Object, args Tuple) (Object, error) {
+			return bytesPartition(self, args, "rpartition", true)
+		}, 0, `rpartition(sep) -> (head, sep, tail)
+
+As partition() but searching for the separator from the end of B.`)
+		t.Dict["join"] = MustNewMethod("join", bytesJoin, 0, `join(iterable_of_bytes) -> bytes
+
+Concatenate any number of bytes objects, with B in between each pair.`)
+		t.Dict["replace"] = MustNewMethod("replace", func(self Object, args Tuple) (Object, error) {
+			return bytesReplace(self, args)
+		}, 0, `replace(old, new[, count]) -> bytes
+
+Return a copy of B with all occurrences of subsection old replaced by
+new.  If count is given only the first count occurrences are replaced.`)
+		t.Dict["strip"] = MustNewMethod("strip", func(self Object, args Tuple) (Object, error) {
+			return bytesStrip(self, args, "strip", true, true)
+		}, 0, `strip([bytes]) -> bytes
+
+Strip leading and trailing bytes contained in the argument.  If the
+argument is omitted or None, strip leading and trailing ASCII whitespace.`)
+		t.Dict["lstrip"] = MustNewMethod("lstrip", func(self Object, args Tuple) (Object, error) {
+			return bytesStrip(self, args, "lstrip", true, false)
+		}, 0, "lstrip([bytes]) -> bytes\n\nStrip leading bytes contained in the argument.")
+		t.Dict["rstrip"] = MustNewMethod("rstrip", func(self Object, args Tuple) (Object, error) {
+			return bytesStrip(self, args, "rstrip", false, true)
+		}, 0, "rstrip([bytes]) -> bytes\n\nStrip trailing bytes contained in the argument.")
+		t.Dict["lower"] = MustNewMethod("lower", func(self Object, args Tuple) (Object, error) {
+			return bytesMap(self, args, "lower", lowerByte)
+		}, 0, "lower() -> copy of B with all ASCII characters converted to lowercase.")
+		t.Dict["upper"] = MustNewMethod("upper", func(self Object, args Tuple) (Object, error) {
+			return bytesMap(self, args, "upper", upperByte)
+		}, 0, "upper() -> copy of B with all ASCII characters converted to uppercase.")
+		t.Dict["swapcase"] = MustNewMethod("swapcase", func(self Object, args Tuple) (Object, error) {
+			return bytesMap(self, args, "swapcase", func(c byte) byte {
+				if isUpperByte(c) {
+					return lowerByte(c)
+				}
+				return upperByte(c)
+			})
+		}, 0, "swapcase() -> copy of B with uppercase ASCII characters converted\nto lowercase ASCII and vice versa.")
+		t.Dict["capitalize"] = MustNewMethod("capitalize", func(self Object, args Tuple) (Object, error) {
+			first := true
+			return bytesMap(self, args, "capitalize", func(c byte) byte {
+				if first {
+					first = false
+					return upperByte(c)
+				}
+				return lowerByte(c)
+			})
+		}, 0, "capitalize() -> copy of B with only its first character capitalized (ASCII)\nand the rest lower-cased.")
+		t.Dict["title"] = MustNewMethod("title", func(self Object, args Tuple) (Object, error) {
+			return bytesMapCased(self, args, "title", func(c byte, afterCased bool) byte {
+				if afterCased {
+					return lowerByte(c)
+				}
+				return upperByte(c)
+			})
+		}, 0, "title() -> copy of B with ASCII words starting with an uppercase\ncharacter and all remaining cased characters lowercase.")
+		t.Dict["center"] = MustNewMethod("center", func(self Object, args Tuple) (Object, error) {
+			return bytesJustify(self, args, "center", '^')
+		}, 0, `center(width[, fillchar]) -> copy of B
+
+Return B centered in a string of length width.  Padding is done using
+the specified fill character (default is a space).`)
+		t.Dict["ljust"] = MustNewMethod("ljust", func(self Object, args Tuple) (Object, error) {
+			return bytesJustify(self, args, "ljust", '<')
+		}, 0, `ljust(width[, fillchar]) -> copy of B
+
+Return B left justified in a string of length width.  Padding is done
+using the specified fill character (default is a space).`)
+		t.Dict["rjust"] = MustNewMethod("rjust", func(self Object, args Tuple) (Object, error) {
+			return bytesJustify(self, args, "rjust", '>')
+		}, 0, `rjust(width[, fillchar]) -> copy of B
+
+Return B right justified in a string of length width.  Padding is done
+using the specified fill character (default is a space).`)
+		t.Dict["zfill"] = MustNewMethod("zfill", bytesZfill, 0, `zfill(width) -> copy of B
+
+Pad a numeric string B with zeros on the left, to fill a field of the
+specified width.  B is never truncated.`)
+		t.Dict["expandtabs"] = MustNewMethod("expandtabs", bytesExpandTabs, 0, `expandtabs(tabsize=8) -> copy of B
+
+Return a copy of B where all tab characters are expanded using spaces.
+If tabsize is not given, a tab size of 8 characters is assumed.`)
+		t.Dict["splitlines"] = MustNewMethod("splitlines", bytesSplitLines, 0, `splitlines([keepends]) -> list of lines
+
+Return a list of the lines in B, breaking at line boundaries.  Line
+breaks are not included in the resulting list unless keepends is given
+and true.`)
+		t.Dict["removeprefix"] = MustNewMethod("removeprefix", func(self Object, args Tuple) (Object, error) {
+			return bytesRemoveAffix(self, args, "removeprefix", false)
+		}, 0, `removeprefix(prefix) -> copy of B
+
+Return B with the given prefix removed if it starts with it, otherwise
+a copy of B.`)
+		t.Dict["removesuffix"] = MustNewMethod("removesuffix", func(self Object, args Tuple) (Object, error) {
+			return bytesRemoveAffix(self, args, "removesuffix", true)
+		}, 0, `removesuffix(suffix) -> copy of B
+
+Return B with the given suffix removed if it ends with it, otherwise
+a copy of B.`)
+		t.Dict["translate"] = MustNewMethod("translate", bytesTranslate, 0, `translate(table, delete=b'') -> copy of B
+
+Return a copy of B with each byte mapped by table, a bytes object of
+length 256 or None, after removing the bytes in delete.`)
+		t.Dict["maketrans"] = &StaticMethod{
+			Callable: MustNewMethod("maketrans", bytesMakeTrans, 0, `maketrans(frm, to) -> bytes
+
+Return a translation table usable for translate() mapping each byte in
+frm to the byte at the same position in to.`),
+			Dict: NewStringDict(),
+		}
+		t.Dict["isalnum"] = MustNewMethod("isalnum", func(self Object, args Tuple) (Object, error) {
+			return bytesIs(self, args, "isalnum", func(c byte) bool { return isAlphaByte(c) || isDigitByte(c) })
+		}, 0, "isalnum() -> bool\n\nReturn True if all bytes in B are alphanumeric and B is not empty.")
+		t.Dict["isalpha"] = MustNewMethod("isalpha", func(self Object, args Tuple) (Object, error) {
+			return bytesIs(self, args, "isalpha", isAlphaByte)
+		}, 0, "isalpha() -> bool\n\nReturn True if all bytes in B are alphabetic and B is not empty.")
+		t.Dict["isdigit"] = MustNewMethod("isdigit", func(self Object, args Tuple) (Object, error) {
+			return bytesIs(self, args, "isdigit", isDigitByte)
+		}, 0, "isdigit() -> bool\n\nReturn True if all bytes in B are digits and B is not empty.")
+		t.Dict["isspace"] = MustNewMethod("isspace", func(self Object, args Tuple) (Object, error) {
+			return bytesIs(self, args, "isspace", isSpaceByte)
+		}, 0, "isspace() -> bool\n\nReturn True if all bytes in B are whitespace and B is not empty.")
+		t.Dict["isascii"] = MustNewMethod("isascii", func(self Object, args Tuple) (Object, error) {
+			err := UnpackTuple(args, nil, "isascii", 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range bytesSelf(self) {
+				if c >= 0x80 {
+					return False, nil
+				}
+			}
+			return True, nil
+		}, 0, "isascii() -> bool\n\nReturn True if B is empty or all bytes in B are ASCII.")
+		t.Dict["islower"] = MustNewMethod("islower", func(self Object, args Tuple) (Object, error) {
+			return bytesIsCase(self, args, "islower", isLowerByte, isUpperByte)
+		}, 0, "islower() -> bool\n\nReturn True if all cased bytes in B are lowercase and there is\nat least one cased byte in B.")
+		t.Dict["isupper"] = MustNewMethod("isupper", func(self Object, args Tuple) (Object, error) {
+			return bytesIsCase(self, args, "isupper", isUpperByte, isLowerByte)
+		}, 0, "isupper() -> bool\n\nReturn True if all cased bytes in B are uppercase and there is\nat least one cased byte in B.")
+		t.Dict["istitle"] = MustNewMethod("istitle", bytesIsTitle, 0, "istitle() -> bool\n\nReturn True if B is a titlecased string and there is at least one\ncased byte in B.")
+		t.Dict["hex"] = MustNewMethod("hex", bytesHex, 0, `hex([sep[, bytes_per_sep]]) -> str
+
+Create a string of hexadecimal numbers from B.  If sep is given it is
+put between every bytes_per_sep bytes, counting from the right, or from
+the left if bytes_per_sep is negative.`)
+	}
+
+	BytesType.Dict["fromhex"] = &ClassMethod{
+		Callable: MustNewMethod("fromhex", func(self Object, args Tuple) (Object, error) {
+			b, err := bytesFromHex(args)
+			if err != nil {
+				return nil, err
+			}
+			return Bytes(b), nil
+		}, 0, `fromhex(string) -> bytes
+
+Create a bytes object from a string of hexadecimal numbers.  Spaces
+between two numbers are accepted.
+Example: bytes.fromhex('B9 01EF') -> b'\xb9\x01\xef'.`),
+	}
+}
+
+// Check interface is satisfied
+var _ sequenceArithmetic = Bytes(nil)
+var _ I__len__ = Bytes(nil)
+var _ I__bool__ = Bytes(nil)
+var _ I__iter__ = Bytes(nil)
+var _ I__getitem__ = Bytes(nil)
+var _ I__contains__ = Bytes(nil)
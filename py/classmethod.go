@@ -69,6 +69,12 @@ func init() {
 			return self.(*ClassMethod).Callable, nil
 		},
 	}
+	ClassMethodType.Dict["__isabstractmethod__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			abstract, err := IsAbstract(self.(*ClassMethod).Callable)
+			return NewBool(abstract), err
+		},
+	}
 }
 
 // Check interface is satisfied
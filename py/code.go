@@ -7,6 +7,8 @@
 package py
 
 import (
+	"math"
+	"sort"
 	"strings"
 )
 
@@ -19,25 +21,41 @@ import (
 // Cellvars are names of local variables referenced by functions with
 // a closure.
 type Code struct {
-	Argcount       int32    // #arguments, except *args
-	Kwonlyargcount int32    // #keyword only arguments
-	Nlocals        int32    // #local variables
-	Stacksize      int32    // #entries needed for evaluation stack
-	Flags          int32    // CO_..., see below
-	Code           string   // instruction opcodes
-	Consts         Tuple    // list (constants used)
-	Names          []string // list of strings (names used)
-	Varnames       []string // tuple of strings (local variable names)
-	Freevars       []string // tuple of strings (free variable names)
-	Cellvars       []string // tuple of strings (cell variable names)
+	Argcount        int32    // #arguments, except *args
+	Posonlyargcount int32    // #positional only arguments
+	Kwonlyargcount  int32    // #keyword only arguments
+	Nlocals         int32    // #local variables
+	Stacksize       int32    // #entries needed for evaluation stack
+	Flags           int32    // CO_..., see below
+	Code            string   // instruction opcodes
+	Consts          Tuple    // list (constants used)
+	Names           []string // list of strings (names used)
+	Varnames        []string // tuple of strings (local variable names)
+	Freevars        []string // tuple of strings (free variable names)
+	Cellvars        []string // tuple of strings (cell variable names)
 	// The rest doesn't count for hash or comparisons
-	Cell2arg    []byte // Maps cell vars which are arguments.
-	Filename    string // unicode (where it was loaded from)
-	Name        string // unicode (name, for reference)
-	Firstlineno int32  // first source line number
-	Lnotab      string // string (encoding addr<->lineno mapping) See Objects/lnotab_notes.txt for details.
+	Cell2arg    []byte         // Maps cell vars which are arguments.
+	Filename    string         // unicode (where it was loaded from)
+	Name        string         // unicode (name, for reference)
+	Firstlineno int32          // first source line number
+	Lnotab      string         // string (encoding addr<->lineno mapping) See Objects/lnotab_notes.txt for details.
+	Positions   []CodePosition // source of the instructions, see Addr2Position
 
 	Weakreflist *List // to support weakrefs to code objects
+
+	// Private to the vm which keeps the decoded instructions here
+	Cache interface{}
+}
+
+// CodePosition is the span of source the instructions from bytecode
+// index Addr onwards were compiled from.  The end is just past the
+// last character.
+type CodePosition struct {
+	Addr         int32
+	Lineno       int32
+	ColOffset    int32
+	EndLineno    int32
+	EndColOffset int32
 }
 
 var CodeType = NewType("code", "code(argcount, kwonlyargcount, nlocals, stacksize, flags, codestring,\n      constants, names, varnames, filename, name, firstlineno,\n      lnotab[, freevars[, cellvars]])\n\nCreate a code object.  Not for the faint of heart.")
@@ -65,6 +83,8 @@ const (
 	// work to be done when the call frame it setup.
 
 	CO_NOFREE                  = 0x0040
+	CO_COROUTINE               = 0x0080 // code is an async def
+	CO_ITERABLE_COROUTINE      = 0x0100 // generator which can be awaited
 	CO_GENERATOR_ALLOWED       = 0x1000
 	CO_FUTURE_DIVISION         = 0x2000
 	CO_FUTURE_ABSOLUTE_IMPORT  = 0x4000 // do absolute imports by default
@@ -112,8 +132,6 @@ func NewCode(argcount int32, kwonlyargcount int32,
 	filename_ Object, name_ Object, firstlineno int32,
 	lnotab_ Object) *Code {
 
-	var cell2arg []byte
-
 	// Type assert the objects
 	consts := consts_.(Tuple)
 	namesTuple := names_.(Tuple)
@@ -154,7 +172,6 @@ func NewCode(argcount int32, kwonlyargcount int32,
 	// 	return nil;
 	// }
 
-	n_cellvars := len(cellvars)
 	intern_strings(namesTuple)
 	intern_strings(varnamesTuple)
 	intern_strings(freevarsTuple)
@@ -168,34 +185,7 @@ func NewCode(argcount int32, kwonlyargcount int32,
 		}
 	}
 	/* Create mapping between cells and arguments if needed. */
-	if n_cellvars != 0 {
-		total_args := argcount + kwonlyargcount
-		if flags&CO_VARARGS != 0 {
-			total_args++
-		}
-		if flags&CO_VARKEYWORDS != 0 {
-			total_args++
-		}
-		used_cell2arg := false
-		cell2arg = make([]byte, n_cellvars)
-		for i := range cell2arg {
-			cell2arg[i] = CO_CELL_NOT_AN_ARG
-		}
-		// Find cells which are also arguments.
-		for i, cell := range cellvars {
-			for j := int32(0); j < total_args; j++ {
-				arg := varnames[j]
-				if cell == arg {
-					cell2arg[i] = byte(j)
-					used_cell2arg = true
-					break
-				}
-			}
-		}
-		if !used_cell2arg {
-			cell2arg = nil
-		}
-	}
+	cell2arg := MakeCell2arg(argcount, kwonlyargcount, flags, varnames, cellvars)
 
 	return &Code{
 		Argcount:       argcount,
@@ -218,6 +208,41 @@ func NewCode(argcount int32, kwonlyargcount int32,
 	}
 }
 
+// MakeCell2arg returns the mapping from cell variables to the
+// arguments they are initialised from or nil if there are none
+func MakeCell2arg(argcount, kwonlyargcount, flags int32, varnames, cellvars []string) []byte {
+	if len(cellvars) == 0 {
+		return nil
+	}
+	total_args := argcount + kwonlyargcount
+	if flags&CO_VARARGS != 0 {
+		total_args++
+	}
+	if flags&CO_VARKEYWORDS != 0 {
+		total_args++
+	}
+	used_cell2arg := false
+	cell2arg := make([]byte, len(cellvars))
+	for i := range cell2arg {
+		cell2arg[i] = CO_CELL_NOT_AN_ARG
+	}
+	// Find cells which are also arguments.
+	for i, cell := range cellvars {
+		for j := int32(0); j < total_args; j++ {
+			arg := varnames[j]
+			if cell == arg {
+				cell2arg[i] = byte(j)
+				used_cell2arg = true
+				break
+			}
+		}
+	}
+	if !used_cell2arg {
+		return nil
+	}
+	return cell2arg
+}
+
 // Return number of free variables
 func (co *Code) GetNumFree() int {
 	return len(co.Freevars)
@@ -239,6 +264,52 @@ func (co *Code) Addr2Line(addrq int32) int32 {
 	return line
 }
 
+// Use Positions to find the span of source the instruction at
+// bytecode index addrq was compiled from.  It returns false if it
+// isn't known.
+func (co *Code) Addr2Position(addrq int32) (CodePosition, bool) {
+	i := sort.Search(len(co.Positions), func(i int) bool {
+		return co.Positions[i].Addr > addrq
+	})
+	if i == 0 {
+		return CodePosition{}, false
+	}
+	position := co.Positions[i-1]
+	return position, position.Lineno > 0 && position.EndLineno > 0
+}
+
+// Use co_lnotab to compute the line number of the bytecode index
+// addrq, and the range of bytecode indexes [lower, upper) which are
+// on that line.
+//
+// This is the equivalent of _PyCode_CheckLineNumber and is used for
+// line tracing.
+func (co *Code) LineBounds(addrq int32) (line, lower, upper int32) {
+	line = co.Firstlineno
+	addr := int32(0)
+	i := 0
+	for ; i < len(co.Lnotab); i += 2 {
+		if addr+int32(co.Lnotab[i]) > addrq {
+			break
+		}
+		addr += int32(co.Lnotab[i])
+		if co.Lnotab[i+1] != 0 {
+			lower = addr
+		}
+		line += int32(co.Lnotab[i+1])
+	}
+	if i >= len(co.Lnotab) {
+		return line, lower, math.MaxInt32
+	}
+	for ; i < len(co.Lnotab); i += 2 {
+		addr += int32(co.Lnotab[i])
+		if co.Lnotab[i+1] != 0 {
+			break
+		}
+	}
+	return line, lower, addr
+}
+
 // FIXME this should be the default?
 func (co *Code) M__eq__(other Object) (Object, error) {
 	if otherCo, ok := other.(*Code); ok && co == otherCo {
@@ -255,6 +326,44 @@ func (co *Code) M__ne__(other Object) (Object, error) {
 	return True, nil
 }
 
+// Returns the strings as a Tuple of String
+func stringsTuple(strs []string) Tuple {
+	t := make(Tuple, len(strs))
+	for i, s := range strs {
+		t[i] = String(s)
+	}
+	return t
+}
+
+// Properties
+func init() {
+	for name, get := range map[string]func(co *Code) Object{
+		"co_argcount":        func(co *Code) Object { return Int(co.Argcount) },
+		"co_posonlyargcount": func(co *Code) Object { return Int(co.Posonlyargcount) },
+		"co_kwonlyargcount":  func(co *Code) Object { return Int(co.Kwonlyargcount) },
+		"co_nlocals":         func(co *Code) Object { return Int(co.Nlocals) },
+		"co_stacksize":       func(co *Code) Object { return Int(co.Stacksize) },
+		"co_flags":           func(co *Code) Object { return Int(co.Flags) },
+		"co_code":            func(co *Code) Object { return Bytes(co.Code) },
+		"co_consts":          func(co *Code) Object { return co.Consts },
+		"co_names":           func(co *Code) Object { return stringsTuple(co.Names) },
+		"co_varnames":        func(co *Code) Object { return stringsTuple(co.Varnames) },
+		"co_freevars":        func(co *Code) Object { return stringsTuple(co.Freevars) },
+		"co_cellvars":        func(co *Code) Object { return stringsTuple(co.Cellvars) },
+		"co_filename":        func(co *Code) Object { return String(co.Filename) },
+		"co_name":            func(co *Code) Object { return String(co.Name) },
+		"co_firstlineno":     func(co *Code) Object { return Int(co.Firstlineno) },
+		"co_lnotab":          func(co *Code) Object { return Bytes(co.Lnotab) },
+	} {
+		get := get
+		CodeType.Dict[name] = &Property{
+			Fget: func(self Object) (Object, error) {
+				return get(self.(*Code)), nil
+			},
+		}
+	}
+}
+
 // Check interface is satisfied
 var _ I__eq__ = (*Code)(nil)
 var _ I__ne__ = (*Code)(nil)
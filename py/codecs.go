@@ -0,0 +1,294 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Codecs
+//
+// str.encode(), bytes.decode() and the codecs module convert between
+// text and bytes with the Codecs registered here.  The built in codecs
+// are utf-8, ascii, latin-1 and utf-16 and utf-32 in both byte orders,
+// with the byte order marked by a BOM if it isn't given.  Files can
+// use the built in codecs which don't need a BOM.
+//
+// The error handlers are strict, ignore and replace.
+
+package py
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Codec converts between str and bytes
+type Codec struct {
+	// Name is the canonical name of the codec, eg "utf-8"
+	Name string
+	// Encode encodes s with the error handler errors
+	Encode func(s string, errors string) ([]byte, error)
+	// Decode decodes b with the error handler errors
+	Decode func(b []byte, errors string) (string, error)
+}
+
+// The registered codecs indexed by name
+var codecs = map[string]*Codec{}
+
+// Other names of the codecs
+var codecAliases = map[string]string{
+	"utf8":       "utf-8",
+	"u8":         "utf-8",
+	"utf":        "utf-8",
+	"cp65001":    "utf-8",
+	"us-ascii":   "ascii",
+	"646":        "ascii",
+	"latin1":     "latin-1",
+	"latin":      "latin-1",
+	"iso-8859-1": "latin-1",
+	"iso8859-1":  "latin-1",
+	"8859":       "latin-1",
+	"cp819":      "latin-1",
+	"l1":         "latin-1",
+	"utf16":      "utf-16",
+	"u16":        "utf-16",
+	"utf-16le":   "utf-16-le",
+	"utf-16be":   "utf-16-be",
+	"utf32":      "utf-32",
+	"u32":        "utf-32",
+	"utf-32le":   "utf-32-le",
+	"utf-32be":   "utf-32-be",
+}
+
+// Returns the key of the codec called name in codecs
+func codecKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	key = strings.NewReplacer("_", "-", " ", "-").Replace(key)
+	if alias, ok := codecAliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// RegisterCodec adds a codec which can be used by str.encode(),
+// bytes.decode() and the codecs module, replacing any with the same
+// name
+func RegisterCodec(c *Codec) {
+	codecs[codecKey(c.Name)] = c
+}
+
+// LookupCodec returns the codec for encoding
+func LookupCodec(encoding string) (*Codec, error) {
+	if c, ok := codecs[codecKey(encoding)]; ok {
+		return c, nil
+	}
+	return nil, ExceptionNewf(LookupError, "unknown encoding: %s", encoding)
+}
+
+// Returns a name for the encoding which is one of those supported by
+// decodeRune and encodeString
+func normalizeEncoding(encoding string) (string, error) {
+	c, err := LookupCodec(encoding)
+	if err != nil {
+		return "", err
+	}
+	switch c.Name {
+	case "utf-8", "ascii", "latin-1", "utf-16-le", "utf-16-be", "utf-32-le", "utf-32-be":
+		return c.Name, nil
+	}
+	return "", ExceptionNewf(LookupError, "encoding %s can't be used for files", encoding)
+}
+
+// Checks the name of the error handler
+func checkErrors(errors string) error {
+	switch errors {
+	case "strict", "replace", "ignore":
+		return nil
+	}
+	return ExceptionNewf(LookupError, "unknown error handler name '%s'", errors)
+}
+
+// Decodes the first character of b in the encoding, returning the
+// number of bytes used.  The character is -1 if it was ignored by the
+// error handler.
+func decodeRune(b []byte, encoding, errors string) (rune, int, error) {
+	var r rune
+	size := 1
+	valid := true
+	switch encoding {
+	case "", "utf-8":
+		r, size = utf8.DecodeRune(b)
+		valid = r != utf8.RuneError || size > 1
+		if !valid {
+			encoding = "utf-8"
+		}
+	case "ascii":
+		r = rune(b[0])
+		valid = r < 0x80
+	case "latin-1":
+		r = rune(b[0])
+	case "utf-16-le", "utf-16-be":
+		order := byteOrder(encoding)
+		if len(b) < 2 {
+			size, valid = len(b), false
+			break
+		}
+		size = 2
+		r = rune(order.Uint16(b))
+		if utf16.IsSurrogate(r) {
+			valid = false
+			if r < 0xdc00 && len(b) >= 4 {
+				r = utf16.DecodeRune(r, rune(order.Uint16(b[2:])))
+				if r != utf8.RuneError {
+					size, valid = 4, true
+				}
+			}
+		}
+	case "utf-32-le", "utf-32-be":
+		if len(b) < 4 {
+			size, valid = len(b), false
+			break
+		}
+		size = 4
+		r = rune(byteOrder(encoding).Uint32(b))
+		valid = utf8.ValidRune(r)
+	}
+	if valid {
+		return r, size, nil
+	}
+	switch errors {
+	case "replace":
+		return utf8.RuneError, size, nil
+	case "ignore":
+		return -1, size, nil
+	}
+	return 0, size, ExceptionNewf(UnicodeDecodeError, "'%s' codec can't decode byte 0x%02x", encoding, b[0])
+}
+
+// Encodes s in the encoding
+func encodeString(s string, encoding, errors string) ([]byte, error) {
+	switch encoding {
+	case "", "utf-8":
+		return []byte(s), nil
+	case "utf-16-le", "utf-16-be":
+		order := byteOrder(encoding)
+		b := make([]byte, 0, 2*len(s))
+		var unit [2]byte
+		for _, u := range utf16.Encode([]rune(s)) {
+			order.PutUint16(unit[:], u)
+			b = append(b, unit[:]...)
+		}
+		return b, nil
+	case "utf-32-le", "utf-32-be":
+		order := byteOrder(encoding)
+		b := make([]byte, 0, 4*len(s))
+		var unit [4]byte
+		for _, r := range s {
+			order.PutUint32(unit[:], uint32(r))
+			b = append(b, unit[:]...)
+		}
+		return b, nil
+	}
+	limit := rune(0x80)
+	if encoding == "latin-1" {
+		limit = 0x100
+	}
+	b := make([]byte, 0, len(s))
+	i := 0
+	for _, r := range s {
+		if r < limit {
+			b = append(b, byte(r))
+		} else {
+			switch errors {
+			case "replace":
+				b = append(b, '?')
+			case "ignore":
+			default:
+				return nil, ExceptionNewf(UnicodeEncodeError, "'%s' codec can't encode character '\\u%04x' in position %d: ordinal not in range(%d)", encoding, r, i, limit)
+			}
+		}
+		i++
+	}
+	return b, nil
+}
+
+// Returns the byte order of a utf-16 or utf-32 encoding
+func byteOrder(encoding string) binary.ByteOrder {
+	if strings.HasSuffix(encoding, "-be") {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// Decodes b in one of the encodings supported by decodeRune
+func decodeString(b []byte, encoding, errors string) (string, error) {
+	if encoding == "utf-8" && utf8.Valid(b) {
+		return string(b), nil
+	}
+	var out strings.Builder
+	for i := 0; i < len(b); {
+		r, size, err := decodeRune(b[i:], encoding, errors)
+		if err != nil {
+			return "", ExceptionNewf(UnicodeDecodeError, "'%s' codec can't decode byte 0x%02x in position %d", encoding, b[i], i)
+		}
+		if r >= 0 {
+			out.WriteRune(r)
+		}
+		i += size
+	}
+	return out.String(), nil
+}
+
+// Makes a codec for one of the encodings supported by decodeRune and
+// encodeString
+func newStreamCodec(name string) *Codec {
+	return &Codec{
+		Name: name,
+		Encode: func(s string, errors string) ([]byte, error) {
+			return encodeString(s, name, errors)
+		},
+		Decode: func(b []byte, errors string) (string, error) {
+			return decodeString(b, name, errors)
+		},
+	}
+}
+
+// Makes a utf-16 or utf-32 codec which writes a little endian BOM
+// and reads the byte order from the BOM if there is one
+func newBOMCodec(name string) *Codec {
+	var bomLE, bomBE []byte
+	if name == "utf-16" {
+		bomLE, bomBE = []byte{0xff, 0xfe}, []byte{0xfe, 0xff}
+	} else {
+		bomLE, bomBE = []byte{0xff, 0xfe, 0, 0}, []byte{0, 0, 0xfe, 0xff}
+	}
+	return &Codec{
+		Name: name,
+		Encode: func(s string, errors string) ([]byte, error) {
+			b, err := encodeString(s, name+"-le", errors)
+			if err != nil {
+				return nil, err
+			}
+			return append(append([]byte{}, bomLE...), b...), nil
+		},
+		Decode: func(b []byte, errors string) (string, error) {
+			encoding := name + "-le"
+			switch {
+			case bytes.HasPrefix(b, bomLE):
+				b = b[len(bomLE):]
+			case bytes.HasPrefix(b, bomBE):
+				b = b[len(bomBE):]
+				encoding = name + "-be"
+			}
+			return decodeString(b, encoding, errors)
+		},
+	}
+}
+
+func init() {
+	for _, name := range []string{"utf-8", "ascii", "latin-1", "utf-16-le", "utf-16-be", "utf-32-le", "utf-32-be"} {
+		RegisterCodec(newStreamCodec(name))
+	}
+	RegisterCodec(newBOMCodec("utf-16"))
+	RegisterCodec(newBOMCodec("utf-32"))
+}
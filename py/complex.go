@@ -7,12 +7,13 @@
 package py
 
 import (
-	"fmt"
 	"math"
 	"math/cmplx"
+	"strconv"
+	"strings"
 )
 
-var ComplexType = ObjectType.NewType("complex64", "complex(real[, imag]) -> complex number\n\nCreate a complex number from a real part and an optional imaginary part.\nThis is equivalent to (real + imag*1j) where imag defaults to 0.", ComplexNew, nil)
+var ComplexType = ObjectType.NewType("complex", "complex(real[, imag]) -> complex number\n\nCreate a complex number from a real part and an optional imaginary part.\nThis is equivalent to (real + imag*1j) where imag defaults to 0.", ComplexNew, nil)
 
 type Complex complex128
 
@@ -24,20 +25,140 @@ func (o Complex) Type() *Type {
 // ComplexNew
 func ComplexNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	var realObj Object = Float(0)
-	var imagObj Object = Float(0)
-	err := ParseTupleAndKeywords(args, kwargs, "|OO", []string{"real", "imag"}, &realObj, &imagObj)
+	var imagObj Object
+	err := ParseTupleAndKeywords(args, kwargs, "|OO:complex", []string{"real", "imag"}, &realObj, &imagObj)
 	if err != nil {
 		return nil, err
 	}
-	real, err := MakeFloat(realObj)
+	if str, ok := realObj.(String); ok {
+		if imagObj != nil {
+			return nil, ExceptionNewf(TypeError, "complex() can't take second arg if first is a string")
+		}
+		return ComplexFromString(string(str))
+	}
+	if _, ok := imagObj.(String); ok {
+		return nil, ExceptionNewf(TypeError, "complex() second arg can't be a string")
+	}
+	r, err := complexArg(realObj, "first")
 	if err != nil {
 		return nil, err
 	}
-	imag, err := MakeFloat(imagObj)
+	if imagObj == nil {
+		return Complex(r), nil
+	}
+	i, err := complexArg(imagObj, "second")
 	if err != nil {
 		return nil, err
 	}
-	return Complex(complex(real.(Float), imag.(Float))), nil
+	// real + imag*1j taking care to preserve the sign of zero parts
+	re, im := real(r), real(i)
+	if _, ok := imagObj.(Complex); ok {
+		re -= imag(i)
+	}
+	if _, ok := realObj.(Complex); ok {
+		im += imag(r)
+	}
+	return Complex(complex(re, im)), nil
+}
+
+// Converts an argument of complex() to a complex128
+func complexArg(obj Object, which string) (complex128, error) {
+	if c, ok := convertToComplex(obj); ok {
+		return complex128(c), nil
+	}
+	for _, method := range []string{"__complex__", "__float__"} {
+		res, ok, err := complexConversion(obj, method)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		if c, ok := convertToComplex(res); ok {
+			return complex128(c), nil
+		}
+		return 0, ExceptionNewf(TypeError, "%s returned non-%s (type %s)", method, method[2:len(method)-2], res.Type().Name)
+	}
+	return 0, ExceptionNewf(TypeError, "complex() %s argument must be a string or a number, not '%s'", which, obj.Type().Name)
+}
+
+// Calls the __complex__ or __float__ method of obj returning ok false
+// if it doesn't have one
+func complexConversion(obj Object, method string) (res Object, ok bool, err error) {
+	switch method {
+	case "__complex__":
+		if I, ok := obj.(I__complex__); ok {
+			res, err = I.M__complex__()
+			return res, true, err
+		}
+	case "__float__":
+		if I, ok := obj.(I__float__); ok {
+			res, err = I.M__float__()
+			return res, true, err
+		}
+	}
+	return TypeCall0(obj, method)
+}
+
+// ComplexFromString parses a complex number in the python syntax,
+// eg "1+2j", "(-3.5e2j)" or "nan-infj"
+func ComplexFromString(str string) (Object, error) {
+	malformed := ExceptionNewf(ValueError, "complex() arg is a malformed string")
+	s := strings.TrimSpace(str)
+	if len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if s == "" || strings.ContainsAny(s, " \t\n\r\v\f_") {
+		return nil, malformed
+	}
+	parse := func(x string, isImag bool) (float64, bool) {
+		if isImag {
+			switch x {
+			case "", "+":
+				return 1, true
+			case "-":
+				return -1, true
+			}
+		}
+		f, err := strconv.ParseFloat(x, 64)
+		if err != nil {
+			if numErr, ok := err.(*strconv.NumError); !ok || numErr.Err != strconv.ErrRange {
+				return 0, false
+			}
+		}
+		return f, true
+	}
+	last := s[len(s)-1]
+	if last != 'j' && last != 'J' {
+		r, ok := parse(s, false)
+		if !ok {
+			return nil, malformed
+		}
+		return Complex(complex(r, 0)), nil
+	}
+	body := s[:len(s)-1]
+	// Find the sign which separates the real and imaginary parts
+	split := -1
+	for i := len(body) - 1; i > 0; i-- {
+		if (body[i] == '+' || body[i] == '-') && body[i-1] != 'e' && body[i-1] != 'E' {
+			split = i
+			break
+		}
+	}
+	var r, i float64
+	ok := true
+	if split < 0 {
+		i, ok = parse(body, true)
+	} else {
+		var okImag bool
+		r, ok = parse(body[:split], false)
+		i, okImag = parse(body[split:], true)
+		ok = ok && okImag
+	}
+	if !ok {
+		return nil, malformed
+	}
+	return Complex(complex(r, i)), nil
 }
 
 // Convert an Object to an Complex
@@ -51,6 +172,12 @@ func convertToComplex(other Object) (Complex, bool) {
 		return Complex(complex(b, 0)), true
 	case Int:
 		return Complex(complex(float64(b), 0)), true
+	case *BigInt:
+		f, err := b.Float()
+		if err != nil {
+			return 0, false
+		}
+		return Complex(complex(float64(f), 0)), true
 	case Bool:
 		if b {
 			return Complex(1), true
@@ -61,12 +188,46 @@ func convertToComplex(other Object) (Complex, bool) {
 	return 0, false
 }
 
+// Formats a float64 as repr does but without a trailing ".0"
+func complexReprFloat(x float64, sign bool) string {
+	var out string
+	switch {
+	case math.IsNaN(x):
+		out = "nan"
+	case math.IsInf(x, 0):
+		out = "inf"
+		if x < 0 {
+			out = "-inf"
+		}
+	default:
+		e := strconv.FormatFloat(x, 'e', -1, 64)
+		exp, _ := strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+		if exp >= -4 && exp < 16 {
+			out = strconv.FormatFloat(x, 'f', -1, 64)
+		} else {
+			out = e
+		}
+	}
+	if sign && out[0] != '-' {
+		out = "+" + out
+	}
+	return out
+}
+
 func (a Complex) M__str__() (Object, error) {
-	return String(fmt.Sprintf("(%g%+gj)", real(complex128(a)), imag(complex128(a)))), nil
+	return a.M__repr__()
 }
 
 func (a Complex) M__repr__() (Object, error) {
-	return a.M__str__()
+	r, i := real(complex128(a)), imag(complex128(a))
+	if r == 0 && !math.Signbit(r) {
+		return String(complexReprFloat(i, false) + "j"), nil
+	}
+	return String("(" + complexReprFloat(r, false) + complexReprFloat(i, true) + "j)"), nil
+}
+
+func (a Complex) M__bool__() (Object, error) {
+	return NewBool(a != 0), nil
 }
 
 func (a Complex) M__neg__() (Object, error) {
@@ -129,75 +290,63 @@ func (a Complex) M__imul__(other Object) (Object, error) {
 	return a.M__mul__(other)
 }
 
+// Divide two complex numbers
+func complexDiv(a, b Complex) (Object, error) {
+	if b == 0 {
+		return nil, ExceptionNewf(ZeroDivisionError, "complex division by zero")
+	}
+	return Complex(a / b), nil
+}
+
 func (a Complex) M__truediv__(other Object) (Object, error) {
 	if b, ok := convertToComplex(other); ok {
-		return Complex(a / b), nil
+		return complexDiv(a, b)
 	}
 	return NotImplemented, nil
 }
 
 func (a Complex) M__rtruediv__(other Object) (Object, error) {
 	if b, ok := convertToComplex(other); ok {
-		return Complex(b / a), nil
+		return complexDiv(b, a)
 	}
 	return NotImplemented, nil
 }
 
 func (a Complex) M__itruediv__(other Object) (Object, error) {
-	return Complex(a).M__truediv__(other)
-}
-
-// Floor a complex number
-func complexFloor(a Complex) Complex {
-	return Complex(complex(math.Floor(real(a)), math.Floor(imag(a))))
+	return a.M__truediv__(other)
 }
 
-// Floor divide two complex numbers
-func complexFloorDiv(a, b Complex) Complex {
-	q := complexFloor(a / b)
-	r := a - q*b
-	return Complex(r)
-}
+// Errors for the operations complex numbers don't support
+var (
+	complexFloorError  = ExceptionNewf(TypeError, "can't take floor of complex number.")
+	complexModError    = ExceptionNewf(TypeError, "can't mod complex numbers.")
+	complexDivModError = ExceptionNewf(TypeError, "can't take floor or mod of complex number.")
+)
 
 func (a Complex) M__floordiv__(other Object) (Object, error) {
-	if b, ok := convertToComplex(other); ok {
-		return complexFloor(a / b), nil
+	if _, ok := convertToComplex(other); ok {
+		return nil, complexFloorError
 	}
 	return NotImplemented, nil
 }
 
 func (a Complex) M__rfloordiv__(other Object) (Object, error) {
-	if b, ok := convertToComplex(other); ok {
-		return complexFloor(b / a), nil
-	}
-	return NotImplemented, nil
+	return a.M__floordiv__(other)
 }
 
 func (a Complex) M__ifloordiv__(other Object) (Object, error) {
 	return a.M__floordiv__(other)
 }
 
-// Does Mod of two floating point numbers
-func complexDivMod(a, b Complex) (Complex, Complex) {
-	q := complexFloor(a / b)
-	r := a - Complex(q)*b
-	return q, Complex(r)
-}
-
 func (a Complex) M__mod__(other Object) (Object, error) {
-	if b, ok := convertToComplex(other); ok {
-		_, r := complexDivMod(a, b)
-		return r, nil
+	if _, ok := convertToComplex(other); ok {
+		return nil, complexModError
 	}
 	return NotImplemented, nil
 }
 
 func (a Complex) M__rmod__(other Object) (Object, error) {
-	if b, ok := convertToComplex(other); ok {
-		_, r := complexDivMod(b, a)
-		return r, nil
-	}
-	return NotImplemented, nil
+	return a.M__mod__(other)
 }
 
 func (a Complex) M__imod__(other Object) (Object, error) {
@@ -205,34 +354,162 @@ func (a Complex) M__imod__(other Object) (Object, error) {
 }
 
 func (a Complex) M__divmod__(other Object) (Object, Object, error) {
-	if b, ok := convertToComplex(other); ok {
-		x, y := complexDivMod(a, b)
-		return x, y, nil
+	if _, ok := convertToComplex(other); ok {
+		return nil, nil, complexDivModError
 	}
 	return NotImplemented, None, nil
 }
 
 func (a Complex) M__rdivmod__(other Object) (Object, Object, error) {
-	if b, ok := convertToComplex(other); ok {
-		x, y := complexDivMod(b, a)
-		return x, y, nil
+	return a.M__divmod__(other)
+}
+
+// Raise a to the power b
+//
+// Small integer powers are done by repeated multiplication as in
+// CPython so that, for example, (1+1j)**2 == 2j exactly.
+func complexPow(a, b Complex) (Object, error) {
+	if b == 0 {
+		return Complex(1), nil
 	}
-	return NotImplemented, None, nil
+	if a == 0 {
+		if imag(b) != 0 || real(b) < 0 {
+			return nil, ExceptionNewf(ZeroDivisionError, "0.0 to a negative or complex power")
+		}
+		return Complex(0), nil
+	}
+	if n := real(b); imag(b) == 0 && n == math.Trunc(n) && math.Abs(n) <= 100 {
+		e := int(math.Abs(n))
+		r, x := Complex(1), a
+		for ; e > 0; e >>= 1 {
+			if e&1 != 0 {
+				r *= x
+			}
+			x *= x
+		}
+		if n < 0 {
+			return complexDiv(1, r)
+		}
+		return r, nil
+	}
+	if imag(b) == 0 {
+		// Other real powers are done in polar form as in CPython so
+		// that, for example, (3+4j)**0.5 == 2+1j exactly.
+		length := math.Pow(cmplx.Abs(complex128(a)), real(b))
+		sin, cos := sincos(cmplx.Phase(complex128(a)) * real(b))
+		return Complex(complex(length*cos, length*sin)), nil
+	}
+	return Complex(cmplx.Pow(complex128(a), complex128(b))), nil
+}
+
+// 1/n! for the terms of the Taylor series of sin and cos
+var invFactorials = func() (f [26]float64) {
+	n := 1.0
+	for i := range f {
+		if i > 0 {
+			n *= float64(i)
+		}
+		f[i] = 1 / n
+	}
+	return f
+}()
+
+// Returns a*b as p, the rounded product, plus its rounding error e
+func twoProduct(a, b float64) (p, e float64) {
+	split := func(x float64) (hi, lo float64) {
+		c := 134217729 * x
+		hi = c - (c - x)
+		return hi, x - hi
+	}
+	p = a * b
+	ah, al := split(a)
+	bh, bl := split(b)
+	e = ((ah*bh - p) + ah*bl + al*bh) + al*bl
+	return p, e
+}
+
+// Returns the sine and cosine of x, correctly rounded in all but a
+// very few cases for all but huge x
+//
+// math.Sincos is often a unit in the last place out, which is enough
+// to make complex powers differ from CPython's, whose C library
+// nearly always rounds them correctly, so this sums the Taylor series of the
+// argument reduced to [-pi/4, pi/4] keeping the rounding errors of
+// the large terms.
+func sincos(x float64) (sin, cos float64) {
+	if x == 0 || math.Abs(x) > 1<<19 || math.IsNaN(x) {
+		return math.Sincos(x)
+	}
+	const (
+		// pi/2 split so that j*pio2_1 and j*pio2_2 are exact
+		pio2_1  = 1.57079632673412561417e+00
+		pio2_2  = 6.07710050630396597660e-11
+		pio2_2t = 2.02226624879595063154e-21
+	)
+	j := math.Floor(x*(2/math.Pi) + 0.5)
+	r := x - j*pio2_1
+	w := j * pio2_2
+	hi := r - w
+	lo := ((r - hi) - w) - j*pio2_2t
+	x = hi + lo
+	xe := lo - (x - hi)
+
+	// x² and x³ and x⁴ plus their rounding errors
+	zz, zze := twoProduct(x, x)
+	zze += 2 * x * xe
+	z3, z3e := twoProduct(x, zz)
+	z3e += x*zze + xe*zz
+	z4, z4e := twoProduct(zz, zz)
+	z4e += 2 * zz * zze
+
+	// The terms after x³/6 and x⁴/24
+	var sinTail, cosTail float64
+	for n := len(invFactorials) - 3; n >= 5; n -= 2 {
+		sinTail = invFactorials[n] - zz*sinTail
+		cosTail = invFactorials[n+1] - zz*cosTail
+	}
+
+	// sin = x - x³/6 + x⁵*sinTail
+	a := z3 / 6
+	p, e := twoProduct(a, 6)
+	ae := ((z3 - p) - e + z3e) / 6
+	sin = x - a
+	sin += ((x - sin) - a) + xe - ae + z3*zz*sinTail
+
+	// cos = 1 - x²/2 + x⁴/24 - x⁶*cosTail
+	h := zz / 2
+	b := z4 / 24
+	p, e = twoProduct(b, 24)
+	be := ((z4 - p) - e + z4e) / 24
+	t := 1 - h
+	te := (1 - t) - h
+	cos = t + b
+	cos += ((t - cos) + b) + te - zze/2 + be - z4*zz*cosTail
+
+	switch int(j) & 3 {
+	case 1:
+		sin, cos = cos, -sin
+	case 2:
+		sin, cos = -sin, -cos
+	case 3:
+		sin, cos = -cos, sin
+	}
+	return sin, cos
 }
 
 func (a Complex) M__pow__(other, modulus Object) (Object, error) {
 	if modulus != None {
-		return NotImplemented, nil
+		return nil, ExceptionNewf(ValueError, "complex modulus")
 	}
 	if b, ok := convertToComplex(other); ok {
-		return Complex(cmplx.Pow(complex128(a), complex128(b))), nil
+		return complexPow(a, b)
 	}
 	return NotImplemented, nil
 }
 
 func (a Complex) M__rpow__(other Object) (Object, error) {
 	if b, ok := convertToComplex(other); ok {
-		return Complex(cmplx.Pow(complex128(b), complex128(a))), nil
+		return complexPow(b, a)
 	}
 	return NotImplemented, nil
 }
@@ -273,6 +550,14 @@ func (a Complex) M__le__(other Object) (Object, error) {
 	return a.M__lt__(other)
 }
 
+func (a Complex) M__hash__() (Object, error) {
+	h := hashFloat64(real(complex128(a))) + HashImag*hashFloat64(imag(complex128(a)))
+	if h == -1 {
+		h = -2
+	}
+	return Int(h), nil
+}
+
 func (a Complex) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToComplex(other); ok {
 		return NewBool(a == b), nil
@@ -315,4 +600,5 @@ func init() {
 
 // Check interface is satisfied
 var _ floatArithmetic = Complex(complex(0, 0))
+var _ I__bool__ = Complex(0)
 var _ richComparison = Complex(0)
@@ -0,0 +1,195 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Interpreter contexts
+//
+// A Context is an isolated python environment with its own table of
+// loaded modules (sys.modules), its own instances of the built in
+// modules and its own module search path (sys.path).  Several can be
+// used at once in one process without importing a module in one
+// being seen in the others.
+//
+// Built in modules are defined once with RegisterModule and made
+// afresh in each Context the first time they are imported.  The
+// built in types and the Go state of the built in modules are shared
+// by all contexts.
+//
+// Code runs in CurrentContext which the vm keeps for each thread, so
+// threads started in a Context run in it too.
+
+package py
+
+import "strings"
+
+// ModuleImpl defines a built in module
+type ModuleImpl struct {
+	Name    string
+	Doc     string
+	Methods []*Method
+	Globals StringDict
+	// If set, Init is called when the module is made in a Context
+	// to set up the globals which mustn't be shared with other
+	// contexts
+	Init func(ctx *Context, m *Module) error
+}
+
+// The built in modules indexed by name
+var moduleImpls = map[string]*ModuleImpl{}
+
+// RegisterModule adds a built in module which can be imported in all
+// the contexts.  Built in modules call this from init.
+func RegisterModule(impl *ModuleImpl) {
+	moduleImpls[impl.Name] = impl
+}
+
+// RegisterModuleFunc adds a built in module called name whose
+// contents are made by init each time it is imported into a Context
+//
+// This is the simplest way for a program embedding gpython to add a
+// module written in Go. init fills in the module using SetDoc,
+// AddFunc and AddValue, for example
+//
+//	py.RegisterModuleFunc("greet", func(m *py.Module) error {
+//		m.SetDoc("Say hello")
+//		return m.AddFunc("hello", "", func(name string) string {
+//			return "Hello " + name
+//		})
+//	})
+//
+// An error returned by init is raised by the import.
+func RegisterModuleFunc(name string, init func(m *Module) error) {
+	RegisterModule(&ModuleImpl{
+		Name: name,
+		Init: func(ctx *Context, m *Module) error {
+			return init(m)
+		},
+	})
+}
+
+// DefaultPath is the initial module search path of a Context
+var DefaultPath = []string{"", "/usr/lib/python3.4", "/usr/local/lib/python3.4/dist-packages", "/usr/lib/python3/dist-packages"}
+
+// Context is an isolated python environment
+type Context struct {
+	// Modules is the table of loaded modules, which is sys.modules
+	Modules StringDict
+	// Path is the module search path, which is sys.path
+	Path *List
+	// cache of the builtins module
+	builtins *Module
+	// the ModuleFS of the sys.path entries handled by path hooks
+	importers map[string]ModuleFS
+	// RecursionLimit is the maximum depth of nested python calls,
+	// which is sys.getrecursionlimit()
+	RecursionLimit int
+	// the limits set by SetLimits and whether there are any
+	limits  limitState
+	limited bool
+	// the hooks added by AddAuditHook
+	auditHooks []AuditHook
+}
+
+// NewContext makes a Context with no modules loaded and the module
+// search path set to DefaultPath
+func NewContext() *Context {
+	path := NewListSized(len(DefaultPath))
+	for i, p := range DefaultPath {
+		path.Items[i] = String(p)
+	}
+	return &Context{
+		Modules:        NewStringDict(),
+		Path:           path,
+		RecursionLimit: DefaultRecursionLimit,
+	}
+}
+
+var (
+	// DefaultContext is the Context used by code which doesn't make
+	// its own
+	DefaultContext = NewContext()
+
+	// CurrentContext is the Context of the running thread,
+	// maintained by the vm
+	CurrentContext = DefaultContext
+)
+
+// RunInContext calls fn with ctx as the CurrentContext, putting the
+// old one back afterwards.  The GIL must be held.
+func RunInContext(ctx *Context, fn func()) {
+	old := CurrentContext
+	CurrentContext = ctx
+	defer func() {
+		CurrentContext = old
+	}()
+	fn()
+}
+
+// NewModule makes a module and adds it to the modules of the context
+func (ctx *Context) NewModule(name, doc string, methods []*Method, globals StringDict) *Module {
+	m := &Module{
+		Name:    name,
+		Doc:     doc,
+		Globals: globals.Copy(),
+	}
+	// Insert the methods into the module dictionary
+	for _, method := range methods {
+		m.Globals[method.Name] = method
+	}
+	// Set some module globals
+	m.Globals["__name__"] = String(name)
+	m.Globals["__doc__"] = String(doc)
+	m.Globals["__package__"] = None
+	// Register the module
+	ctx.Modules[name] = m
+	// fmt.Printf("Registering module %q\n", name)
+	return m
+}
+
+// Makes the built in module impl in the context
+func (ctx *Context) initModule(impl *ModuleImpl) (*Module, error) {
+	m := ctx.NewModule(impl.Name, impl.Doc, impl.Methods, impl.Globals)
+	// A built in module with built in submodules is a package
+	for name := range moduleImpls {
+		if strings.HasPrefix(name, impl.Name+".") {
+			m.Globals["__path__"] = NewList()
+			break
+		}
+	}
+	if impl.Init != nil {
+		err := impl.Init(ctx, m)
+		if err != nil {
+			delete(ctx.Modules, impl.Name)
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// GetModule returns the module called name from the context, making
+// it if it is a built in module which hasn't been imported yet
+func (ctx *Context) GetModule(name string) (*Module, error) {
+	if obj, ok := ctx.Modules[name]; ok {
+		if m, ok := obj.(*Module); ok {
+			return m, nil
+		}
+		return nil, ExceptionNewf(ImportError, "sys.modules[%q] is not a module", name)
+	}
+	if impl, ok := moduleImpls[name]; ok {
+		return ctx.initModule(impl)
+	}
+	return nil, ExceptionNewf(ImportError, "Module %q not found", name)
+}
+
+// Builtins returns the builtins module of the context
+func (ctx *Context) Builtins() *Module {
+	if ctx.builtins == nil {
+		m, err := ctx.GetModule("builtins")
+		if err != nil {
+			// The builtins package isn't linked in
+			m = ctx.NewModule("builtins", "", nil, nil)
+		}
+		ctx.builtins = m
+	}
+	return ctx.builtins
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Coroutine objects
+//
+// These are made by calling an async def function. They run in
+// exactly the same way as generators but aren't iterable - they are
+// driven by await or by calling send directly.
+
+package py
+
+// A python Coroutine object
+type Coroutine Generator
+
+var CoroutineType = NewType("coroutine", "coroutine object")
+
+// A python CoroutineWrapper object
+//
+// This is returned by coroutine.__await__ and is the iterator that
+// await and yield from use to drive the coroutine
+type CoroutineWrapper struct {
+	Coroutine *Coroutine
+}
+
+var CoroutineWrapperType = NewType("coroutine_wrapper", "A wrapper object implementing __await__ for coroutines.")
+
+func init() {
+	CoroutineType.Dict["send"] = MustNewMethod("send", func(self Object, value Object) (Object, error) {
+		return self.(*Coroutine).Send(value)
+	}, 0, "send(arg) -> send 'arg' into coroutine,\nreturn next iterated value or raise StopIteration.")
+	CoroutineType.Dict["throw"] = MustNewMethod("throw", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return self.(*Coroutine).Throw(args, kwargs)
+	}, 0, "throw(typ[,val[,tb]]) -> raise exception in coroutine,\nreturn next iterated value or raise StopIteration.")
+	CoroutineType.Dict["close"] = MustNewMethod("close", func(self Object) (Object, error) {
+		return self.(*Coroutine).Close()
+	}, 0, "close() -> raise GeneratorExit inside coroutine.")
+
+	CoroutineWrapperType.Dict["send"] = MustNewMethod("send", func(self Object, value Object) (Object, error) {
+		return self.(*CoroutineWrapper).Coroutine.Send(value)
+	}, 0, "send(arg) -> send 'arg' into coroutine,\nreturn next iterated value or raise StopIteration.")
+	CoroutineWrapperType.Dict["throw"] = MustNewMethod("throw", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return self.(*CoroutineWrapper).Coroutine.Throw(args, kwargs)
+	}, 0, "throw(typ[,val[,tb]]) -> raise exception in coroutine,\nreturn next iterated value or raise StopIteration.")
+	CoroutineWrapperType.Dict["close"] = MustNewMethod("close", func(self Object) (Object, error) {
+		return self.(*CoroutineWrapper).Coroutine.Close()
+	}, 0, "close() -> raise GeneratorExit inside coroutine.")
+}
+
+// Type of this object
+func (o *Coroutine) Type() *Type {
+	return CoroutineType
+}
+
+// Define a new coroutine
+func NewCoroutine(frame *Frame) *Coroutine {
+	return (*Coroutine)(NewGenerator(frame))
+}
+
+// The generator which runs the coroutine
+func (co *Coroutine) generator() *Generator {
+	return (*Generator)(co)
+}
+
+// coroutine.send(value)
+//
+// Starts or resumes execution of the coroutine. If value is None,
+// this is equivalent to advancing the iterator returned by
+// __await__(). If value is not None, this method delegates to the
+// send() method of the iterator that caused the coroutine to
+// suspend.
+func (co *Coroutine) Send(arg Object) (Object, error) {
+	if co.Frame != nil && co.Frame.Lasti == 0 && arg != None {
+		return nil, ExceptionNewf(TypeError, "can't send non-None value to a just-started coroutine")
+	}
+	return co.generator().resume(arg, nil)
+}
+
+// coroutine.throw(type[, value[, traceback]])
+//
+// Raises the specified exception in the coroutine.
+func (co *Coroutine) Throw(args Tuple, kwargs StringDict) (Object, error) {
+	return co.generator().Throw(args, kwargs)
+}
+
+// coroutine.close()
+//
+// Causes the coroutine to clean itself up and exit.
+func (co *Coroutine) Close() (Object, error) {
+	return co.generator().Close()
+}
+
+func (co *Coroutine) M__await__() (Object, error) {
+	return &CoroutineWrapper{Coroutine: co}, nil
+}
+
+// Type of this object
+func (o *CoroutineWrapper) Type() *Type {
+	return CoroutineWrapperType
+}
+
+func (it *CoroutineWrapper) M__iter__() (Object, error) {
+	return it, nil
+}
+
+func (it *CoroutineWrapper) M__next__() (Object, error) {
+	return it.Coroutine.Send(None)
+}
+
+func (it *CoroutineWrapper) Send(arg Object) (Object, error) {
+	return it.Coroutine.Send(arg)
+}
+
+// GetAwaitableIter returns the iterator which await o should drive
+//
+// This is the coroutine itself for coroutines and generators marked
+// with CO_ITERABLE_COROUTINE, otherwise the result of o.__await__()
+// which must be an iterator.
+func GetAwaitableIter(o Object) (Object, error) {
+	switch x := o.(type) {
+	case *Coroutine:
+		return x, nil
+	case *Generator:
+		if x.Code.Flags&CO_ITERABLE_COROUTINE != 0 {
+			return x, nil
+		}
+	}
+	var res Object
+	var err error
+	if I, ok := o.(I__await__); ok {
+		res, err = I.M__await__()
+	} else if r, ok, e := TypeCall0(o, "__await__"); ok {
+		res, err = r, e
+	} else {
+		return nil, ExceptionNewf(TypeError, "object %s can't be used in 'await' expression", o.Type().Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := res.(*Coroutine); ok {
+		return nil, ExceptionNewf(TypeError, "__await__() returned a coroutine")
+	}
+	if _, ok := res.(I__next__); !ok {
+		if _, err := GetAttrString(res, "__next__"); err != nil {
+			return nil, ExceptionNewf(TypeError, "__await__() returned non-iterator of type '%s'", res.Type().Name)
+		}
+	}
+	return res, nil
+}
+
+// Check interface is satisfied
+var _ I_send = (*Coroutine)(nil)
+var _ I__await__ = (*Coroutine)(nil)
+var _ I_send = (*CoroutineWrapper)(nil)
+var _ I_iterator = (*CoroutineWrapper)(nil)
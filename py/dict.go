@@ -4,12 +4,21 @@
 
 // Dict and StringDict type
 //
-// The idea is that most dicts just have strings for keys so we use
-// the simpler StringDict and promote it into a Dict when necessary
+// Dict is the python dict.  It is stored in a hash table so any
+// hashable object can be a key and it remembers the order in which its
+// keys were first inserted.
+//
+// StringDict is used for namespaces, keyword arguments and the like
+// where the keys can only be strings.  It is a dict to python too, but
+// as it is a go map it has no order of its own so it iterates in
+// sorted key order.
 
 package py
 
-import "bytes"
+import (
+	"bytes"
+	"sort"
+)
 
 const dictDoc = `dict() -> new empty dictionary
 dict(mapping) -> new dictionary initialized from a mapping object's
@@ -22,166 +31,600 @@ dict(**kwargs) -> new dictionary initialized with the name=value pairs
     in the keyword argument list.  For example:  dict(one=1, two=2)`
 
 var (
-	StringDictType = NewType("dict", dictDoc)
-	DictType       = NewType("dict", dictDoc)
+	DictType = NewTypeX("dict", dictDoc, DictNew, nil)
+	// A StringDict is the same type as a Dict to python
+	StringDictType = DictType
 	expectingDict  = ExceptionNewf(TypeError, "a dict is required")
 )
 
+// The methods shared by Dict and StringDict so that the python methods
+// can work on either
+type dictObject interface {
+	Object
+	Len() int
+	Get(key Object) (value Object, found bool, err error)
+	Set(key, value Object) error
+	Delete(key Object) (value Object, found bool, err error)
+	Keys() []Object
+	Values() []Object
+	Items() []Tuple
+	Clear()
+}
+
+var (
+	_ dictObject = (*Dict)(nil)
+	_ dictObject = StringDict(nil)
+)
+
 func init() {
-	StringDictType.Dict["items"] = MustNewMethod("items", func(self Object, args Tuple) (Object, error) {
+	DictType.Dict["keys"] = MustNewMethod("keys", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "keys", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &DictKeys{dict: self.(dictObject)}, nil
+	}, 0, "D.keys() -> a set-like object providing a view on D's keys")
+
+	DictType.Dict["values"] = MustNewMethod("values", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "values", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &DictValues{dict: self.(dictObject)}, nil
+	}, 0, "D.values() -> an object providing a view on D's values")
+
+	DictType.Dict["items"] = MustNewMethod("items", func(self Object, args Tuple) (Object, error) {
 		err := UnpackTuple(args, nil, "items", 0, 0)
 		if err != nil {
 			return nil, err
 		}
-		sMap := self.(StringDict)
-		o := make([]Object, 0, len(sMap))
-		for k, v := range sMap {
-			o = append(o, Tuple{String(k), v})
+		return &DictItems{dict: self.(dictObject)}, nil
+	}, 0, "D.items() -> a set-like object providing a view on D's items")
+
+	DictType.Dict["get"] = MustNewMethod("get", func(self Object, args Tuple) (Object, error) {
+		var key Object
+		var def Object = None
+		err := UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		value, found, err := self.(dictObject).Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return def, nil
 		}
-		return NewIterator(o), nil
-	}, 0, "items() -> list of D's (key, value) pairs, as 2-tuples")
+		return value, nil
+	}, 0, "D.get(k[,d]) -> D[k] if k in D, else d.  d defaults to None.")
 
-	StringDictType.Dict["get"] = MustNewMethod("get", func(self Object, args Tuple) (Object, error) {
-		var length = len(args)
-		switch {
-		case length == 0:
-			return nil, ExceptionNewf(TypeError, "%s expected at least 1 arguments, got %d", "items()", length)
-		case length > 2:
-			return nil, ExceptionNewf(TypeError, "%s expected at most 2 arguments, got %d", "items()", length)
+	DictType.Dict["setdefault"] = MustNewMethod("setdefault", func(self Object, args Tuple) (Object, error) {
+		var key Object
+		var def Object = None
+		err := UnpackTuple(args, nil, "setdefault", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(dictObject)
+		value, found, err := d.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return value, nil
+		}
+		return def, d.Set(key, def)
+	}, 0, "D.setdefault(k[,d]) -> D.get(k,d), also set D[k]=d if k not in D")
+
+	DictType.Dict["pop"] = MustNewMethod("pop", func(self Object, args Tuple) (Object, error) {
+		var key, def Object
+		err := UnpackTuple(args, nil, "pop", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
 		}
-		sMap := self.(StringDict)
-		if str, ok := args[0].(String); ok {
-			if res, ok := sMap[string(str)]; ok {
-				return res, nil
+		value, found, err := self.(dictObject).Delete(key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if def == nil {
+				return nil, &Exception{Base: KeyError, Args: Tuple{key}}
 			}
+			return def, nil
+		}
+		return value, nil
+	}, 0, "D.pop(k[,d]) -> v, remove specified key and return the corresponding value.\nIf key is not found, d is returned if given, otherwise KeyError is raised")
 
-			switch length {
-			case 2:
-				return args[1], nil
-			default:
-				return None, nil
+	DictType.Dict["popitem"] = MustNewMethod("popitem", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "popitem", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(dictObject)
+		keys := d.Keys()
+		if len(keys) == 0 {
+			return nil, ExceptionNewf(KeyError, "popitem(): dictionary is empty")
+		}
+		key := keys[len(keys)-1]
+		value, _, err := d.Delete(key)
+		if err != nil {
+			return nil, err
+		}
+		return Tuple{key, value}, nil
+	}, 0, "D.popitem() -> (k, v), remove and return the last inserted (key, value)\npair as a 2-tuple; but raise KeyError if D is empty.")
+
+	DictType.Dict["update"] = MustNewMethod("update", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var other Object
+		err := UnpackTuple(args, nil, "update", 0, 1, &other)
+		if err != nil {
+			return nil, err
+		}
+		return None, dictUpdate(self.(dictObject), other, kwargs)
+	}, 0, `D.update([E, ]**F) -> None.  Update D from dict/iterable E and F.
+If E is present and has a .keys() method, then does:  for k in E: D[k] = E[k]
+If E is present and lacks a .keys() method, then does:  for k, v in E: D[k] = v
+In either case, this is followed by: for k in F:  D[k] = F[k]`)
+
+	DictType.Dict["clear"] = MustNewMethod("clear", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "clear", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		self.(dictObject).Clear()
+		return None, nil
+	}, 0, "D.clear() -> None.  Remove all items from D.")
+
+	DictType.Dict["copy"] = MustNewMethod("copy", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "copy", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		switch d := self.(type) {
+		case *Dict:
+			return d.Copy(), nil
+		case StringDict:
+			return d.Copy(), nil
+		}
+		return nil, expectingDict
+	}, 0, "D.copy() -> a shallow copy of D")
+
+	DictType.Dict["fromkeys"] = &ClassMethod{
+		Callable: MustNewMethod("fromkeys", func(self Object, args Tuple) (Object, error) {
+			var iterable Object
+			var value Object = None
+			err := UnpackTuple(args, nil, "fromkeys", 1, 2, &iterable, &value)
+			if err != nil {
+				return nil, err
 			}
+			var d Object = NewDict()
+			if self != DictType {
+				d, err = Call(self, nil, nil)
+				if err != nil {
+					return nil, err
+				}
+			}
+			var loopErr error
+			err = Iterate(iterable, func(key Object) bool {
+				_, loopErr = SetItem(d, key, value)
+				return loopErr != nil
+			})
+			if err == nil {
+				err = loopErr
+			}
+			if err != nil {
+				return nil, err
+			}
+			return d, nil
+		}, 0, "Returns a new dict with keys from iterable and values equal to value."),
+	}
+}
+
+// Updates d with the items of other, which may be a mapping or an
+// iterable of (key, value) pairs, followed by kwargs
+func dictUpdate(d dictObject, other Object, kwargs StringDict) error {
+	switch x := other.(type) {
+	case nil:
+	case dictObject:
+		for _, item := range x.Items() {
+			err := d.Set(item[0], item[1])
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		if keys, err := GetAttrString(other, "keys"); err == nil {
+			keysObj, err := Call(keys, nil, nil)
+			if err != nil {
+				return err
+			}
+			var loopErr error
+			err = Iterate(keysObj, func(key Object) bool {
+				var value Object
+				value, loopErr = GetItem(other, key)
+				if loopErr == nil {
+					loopErr = d.Set(key, value)
+				}
+				return loopErr != nil
+			})
+			if err == nil {
+				err = loopErr
+			}
+			if err != nil {
+				return err
+			}
+			break
+		}
+		i := 0
+		var loopErr error
+		err := Iterate(other, func(item Object) bool {
+			var pair Tuple
+			pair, loopErr = SequenceTuple(item)
+			if loopErr != nil {
+				loopErr = ExceptionNewf(TypeError, "cannot convert dictionary update sequence element #%d to a sequence", i)
+				return true
+			}
+			if len(pair) != 2 {
+				loopErr = ExceptionNewf(ValueError, "dictionary update sequence element #%d has length %d; 2 is required", i, len(pair))
+				return true
+			}
+			loopErr = d.Set(pair[0], pair[1])
+			i++
+			return loopErr != nil
+		})
+		if err == nil {
+			err = loopErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, item := range kwargs.Items() {
+		err := d.Set(item[0], item[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DictMerge updates d with the items of other which must be a
+// mapping, that is a dict or an object with a keys() method
+func DictMerge(d *Dict, other Object) error {
+	if _, ok := other.(dictObject); !ok {
+		if _, err := GetAttrString(other, "keys"); err != nil {
+			return ExceptionNewf(TypeError, "'%s' object is not a mapping", other.Type().Name)
 		}
-		return nil, ExceptionNewf(KeyError, "%v", args[0])
-	}, 0, "gets(key, default) -> If there is a val corresponding to key, return val, otherwise default")
+	}
+	return dictUpdate(d, other, nil)
 }
 
-// String to object dictionary
+// DictNew
+func DictNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var other Object
+	err := UnpackTuple(args, nil, "dict", 0, 1, &other)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDict()
+	err = dictUpdate(d, other, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Python dictionary
 //
-// Used for variables etc where the keys can only be strings
-type StringDict map[string]Object
+// Any hashable object may be used as a key and iteration is in the
+// order the keys were first inserted
+type Dict struct {
+	hashTable
+	// Once the dictionary has been used as a namespace by
+	// DictAsNamespace its items are kept in ns instead
+	ns StringDict
+	// The keys of ns to iterate over first, the rest following
+	// in sorted order
+	nsOrder []string
+}
 
-// Type of this StringDict object
-func (o StringDict) Type() *Type {
-	return StringDictType
+// Type of this Dict object
+func (o *Dict) Type() *Type {
+	return DictType
 }
 
 // Make a new dictionary
-func NewStringDict() StringDict {
-	return make(StringDict)
+func NewDict() *Dict {
+	return NewDictSized(0)
 }
 
 // Make a new dictionary with reservation for n entries
-func NewStringDictSized(n int) StringDict {
-	return make(StringDict, n)
+func NewDictSized(n int) *Dict {
+	return &Dict{hashTable: newHashTable(n)}
 }
 
-// Checks that obj is exactly a dictionary and returns an error if not
-func DictCheckExact(obj Object) (StringDict, error) {
-	dict, ok := obj.(StringDict)
-	if !ok {
-		return nil, expectingDict
+// Len returns the number of items in the dictionary
+func (d *Dict) Len() int {
+	if d.ns != nil {
+		return len(d.ns)
 	}
-	return dict, nil
+	return d.used
 }
 
-// Checks that obj is exactly a dictionary and returns an error if not
-func DictCheck(obj Object) (StringDict, error) {
-	// FIXME should be checking subclasses
-	return DictCheckExact(obj)
+// Get returns the value stored under key and whether it was found
+//
+// Returns a TypeError if key is unhashable
+func (d *Dict) Get(key Object) (Object, bool, error) {
+	if d.ns != nil {
+		return d.ns.Get(key)
+	}
+	i, _, err := d.find(key)
+	if err != nil || i < 0 {
+		return nil, false, err
+	}
+	return d.entries[i].value, true, nil
 }
 
-// Copy a dictionary
-func (d StringDict) Copy() StringDict {
-	e := make(StringDict, len(d))
-	for k, v := range d {
-		e[k] = v
+// Set stores value under key
+//
+// An existing key keeps its position in the iteration order
+func (d *Dict) Set(key, value Object) error {
+	if d.ns != nil {
+		return d.ns.Set(key, value)
 	}
-	return e
+	i, h, err := d.find(key)
+	if err != nil {
+		return err
+	}
+	if i >= 0 {
+		d.entries[i].value = value
+		return nil
+	}
+	d.insert(h, key, value)
+	return nil
 }
 
-func (a StringDict) M__str__() (Object, error) {
-	return a.M__repr__()
+// Delete removes key returning its value and whether it was found
+func (d *Dict) Delete(key Object) (Object, bool, error) {
+	if d.ns != nil {
+		return d.ns.Delete(key)
+	}
+	i, h, err := d.find(key)
+	if err != nil || i < 0 {
+		return nil, false, err
+	}
+	value := d.entries[i].value
+	d.remove(i, h)
+	return value, true, nil
 }
 
-func (a StringDict) M__repr__() (Object, error) {
+// Keys returns the keys in insertion order
+func (d *Dict) Keys() []Object {
+	if d.ns != nil {
+		keys := make([]Object, 0, len(d.ns))
+		for _, k := range d.nsKeys() {
+			keys = append(keys, String(k))
+		}
+		return keys
+	}
+	return d.keys()
+}
+
+// Values returns the values in insertion order of their keys
+func (d *Dict) Values() []Object {
+	if d.ns != nil {
+		values := make([]Object, 0, len(d.ns))
+		for _, k := range d.nsKeys() {
+			values = append(values, d.ns[k])
+		}
+		return values
+	}
+	values := make([]Object, 0, d.used)
+	for _, e := range d.entries {
+		if e.key != nil {
+			values = append(values, e.value)
+		}
+	}
+	return values
+}
+
+// Items returns (key, value) pairs in insertion order
+func (d *Dict) Items() []Tuple {
+	if d.ns != nil {
+		items := make([]Tuple, 0, len(d.ns))
+		for _, k := range d.nsKeys() {
+			items = append(items, Tuple{String(k), d.ns[k]})
+		}
+		return items
+	}
+	items := make([]Tuple, 0, d.used)
+	for _, e := range d.entries {
+		if e.key != nil {
+			items = append(items, Tuple{e.key, e.value})
+		}
+	}
+	return items
+}
+
+// Returns the keys of a dictionary used as a namespace, those in
+// nsOrder first
+func (d *Dict) nsKeys() []string {
+	if d.nsOrder == nil {
+		return d.ns.sortedKeys()
+	}
+	keys := make([]string, 0, len(d.ns))
+	seen := make(map[string]struct{}, len(d.nsOrder))
+	for _, k := range d.nsOrder {
+		if _, found := d.ns[k]; !found {
+			continue
+		}
+		if _, dup := seen[k]; !dup {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range d.ns.sortedKeys() {
+		if _, found := seen[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// SetNamespaceOrder makes a dictionary which has been used as a
+// namespace by DictAsNamespace iterate over the names in order before
+// the rest of its keys, eg so the names of a class body come in the
+// order they were defined
+func (d *Dict) SetNamespaceOrder(order []string) {
+	d.nsOrder = order
+}
+
+// Clear removes all the items from the dictionary
+func (d *Dict) Clear() {
+	if d.ns != nil {
+		d.ns.Clear()
+		return
+	}
+	d.clear()
+}
+
+// Copy returns a shallow copy of the dictionary
+func (d *Dict) Copy() *Dict {
+	if d.ns != nil {
+		c := NewDictSized(len(d.ns))
+		for _, item := range d.ns.Items() {
+			// String keys can't fail to hash
+			_ = c.Set(item[0], item[1])
+		}
+		return c
+	}
+	return &Dict{hashTable: d.copy()}
+}
+
+// Returns the dictionary obj as a StringDict
+//
+// A StringDict is returned as is but a Dict is copied into a new
+// StringDict so changes to the result aren't seen in obj.  Returns a
+// TypeError if obj isn't a dictionary or has keys which aren't strings.
+func AsStringDict(obj Object) (StringDict, error) {
+	switch d := obj.(type) {
+	case StringDict:
+		return d, nil
+	case *Dict:
+		if d.ns != nil {
+			return d.ns.Copy(), nil
+		}
+		s := NewStringDictSized(d.used)
+		for _, e := range d.entries {
+			if e.key == nil {
+				continue
+			}
+			key, ok := e.key.(String)
+			if !ok {
+				return nil, ExceptionNewf(TypeError, "keys must be strings, not '%s'", e.key.Type().Name)
+			}
+			s[string(key)] = e.value
+		}
+		return s, nil
+	}
+	return nil, expectingDict
+}
+
+// Returns the dictionary obj as a StringDict which shares its items
+// with obj so it can be used as the globals or locals of running code
+//
+// A Dict is switched over to keeping its items in the returned
+// StringDict so from then on it can only have string keys and it
+// iterates in sorted key order.  Returns a TypeError if obj isn't a
+// dictionary or has keys which aren't strings.
+func DictAsNamespace(obj Object) (StringDict, error) {
+	d, ok := obj.(*Dict)
+	if !ok || d.ns != nil {
+		if ok {
+			return d.ns, nil
+		}
+		return AsStringDict(obj)
+	}
+	ns, err := AsStringDict(d)
+	if err != nil {
+		return nil, err
+	}
+	d.ns = ns
+	d.hashTable = hashTable{}
+	return ns, nil
+}
+
+// Writes the items of d as {k: v, ...}
+func dictRepr(d dictObject) (Object, error) {
+	seen, err := reprEnter(d)
+	if err != nil || seen {
+		return String("{...}"), err
+	}
+	defer reprLeave()
 	var out bytes.Buffer
 	out.WriteRune('{')
-	spacer := false
-	for key, value := range a {
-		if spacer {
+	for i, item := range d.Items() {
+		if i != 0 {
 			out.WriteString(", ")
 		}
-		keyStr, err := ReprAsString(String(key))
+		keyStr, err := ReprAsString(item[0])
 		if err != nil {
 			return nil, err
 		}
-		valueStr, err := ReprAsString(value)
+		valueStr, err := ReprAsString(item[1])
 		if err != nil {
 			return nil, err
 		}
 		out.WriteString(keyStr)
 		out.WriteString(": ")
 		out.WriteString(valueStr)
-		spacer = true
 	}
 	out.WriteRune('}')
 	return String(out.String()), nil
 }
 
-// Returns a list of keys from the dict
-func (d StringDict) M__iter__() (Object, error) {
-	o := make([]Object, 0, len(d))
-	for k := range d {
-		o = append(o, String(k))
+// Returns d[key] or a KeyError
+func dictGetItem(d dictObject, key Object) (Object, error) {
+	value, found, err := d.Get(key)
+	if err != nil {
+		return nil, err
 	}
-	return NewIterator(o), nil
-}
-
-func (d StringDict) M__getitem__(key Object) (Object, error) {
-	str, ok := key.(String)
-	if ok {
-		res, ok := d[string(str)]
-		if ok {
-			return res, nil
-		}
+	if !found {
+		return nil, &Exception{Base: KeyError, Args: Tuple{key}}
 	}
-	return nil, ExceptionNewf(KeyError, "%v", key)
+	return value, nil
 }
 
-func (d StringDict) M__setitem__(key, value Object) (Object, error) {
-	str, ok := key.(String)
-	if !ok {
-		return nil, ExceptionNewf(KeyError, "FIXME can only have string keys!: %v", key)
+// Deletes d[key] or returns a KeyError
+func dictDelItem(d dictObject, key Object) (Object, error) {
+	_, found, err := d.Delete(key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &Exception{Base: KeyError, Args: Tuple{key}}
 	}
-	d[string(str)] = value
 	return None, nil
 }
 
-func (a StringDict) M__eq__(other Object) (Object, error) {
-	b, ok := other.(StringDict)
+// Compares the dictionary a with other or returns NotImplemented if
+// other isn't a dictionary
+func dictEq(a dictObject, other Object) (Object, error) {
+	b, ok := other.(dictObject)
 	if !ok {
 		return NotImplemented, nil
 	}
-	if len(a) != len(b) {
+	if a.Len() != b.Len() {
 		return False, nil
 	}
-	for k, av := range a {
-		bv, ok := b[k]
-		if !ok {
+	for _, item := range a.Items() {
+		bv, found, err := b.Get(item[0])
+		if err != nil {
+			return nil, err
+		}
+		if !found {
 			return False, nil
 		}
-		res, err := Eq(av, bv)
+		res, err := Eq(item[1], bv)
 		if err != nil {
 			return nil, err
 		}
@@ -192,8 +635,9 @@ func (a StringDict) M__eq__(other Object) (Object, error) {
 	return True, nil
 }
 
-func (a StringDict) M__ne__(other Object) (Object, error) {
-	res, err := a.M__eq__(other)
+// The inverse of dictEq
+func dictNe(a dictObject, other Object) (Object, error) {
+	res, err := dictEq(a, other)
 	if err != nil {
 		return nil, err
 	}
@@ -206,14 +650,226 @@ func (a StringDict) M__ne__(other Object) (Object, error) {
 	return True, nil
 }
 
-func (a StringDict) M__contains__(other Object) (Object, error) {
-	key, ok := other.(String)
+// Returns whether key is in d
+func dictContains(d dictObject, key Object) (Object, error) {
+	_, found, err := d.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(found), nil
+}
+
+func (d *Dict) M__str__() (Object, error) {
+	return d.M__repr__()
+}
+
+func (d *Dict) M__repr__() (Object, error) {
+	return dictRepr(d)
+}
+
+func (d *Dict) M__len__() (Object, error) {
+	return Int(d.Len()), nil
+}
+
+// Returns an iterator over the keys of the dictionary
+func (d *Dict) M__iter__() (Object, error) {
+	return NewIterator(d.Keys()), nil
+}
+
+func (d *Dict) M__getitem__(key Object) (Object, error) {
+	return dictGetItem(d, key)
+}
+
+func (d *Dict) M__setitem__(key, value Object) (Object, error) {
+	return None, d.Set(key, value)
+}
+
+func (d *Dict) M__delitem__(key Object) (Object, error) {
+	return dictDelItem(d, key)
+}
+
+func (d *Dict) M__contains__(key Object) (Object, error) {
+	return dictContains(d, key)
+}
+
+func (a *Dict) M__eq__(other Object) (Object, error) {
+	return dictEq(a, other)
+}
+
+func (a *Dict) M__ne__(other Object) (Object, error) {
+	return dictNe(a, other)
+}
+
+// String to object dictionary
+//
+// Used for variables etc where the keys can only be strings
+type StringDict map[string]Object
+
+// Type of this StringDict object
+func (o StringDict) Type() *Type {
+	return StringDictType
+}
+
+// Make a new dictionary
+func NewStringDict() StringDict {
+	return make(StringDict)
+}
+
+// Make a new dictionary with reservation for n entries
+func NewStringDictSized(n int) StringDict {
+	return make(StringDict, n)
+}
+
+// Checks that obj is exactly a dictionary and returns an error if not
+func DictCheckExact(obj Object) (StringDict, error) {
+	dict, ok := obj.(StringDict)
 	if !ok {
-		return nil, ExceptionNewf(KeyError, "FIXME can only have string keys!: %v", key)
+		return nil, expectingDict
+	}
+	return dict, nil
+}
+
+// Checks that obj is exactly a dictionary and returns an error if not
+func DictCheck(obj Object) (StringDict, error) {
+	// FIXME should be checking subclasses
+	return DictCheckExact(obj)
+}
+
+// Copy a dictionary
+func (d StringDict) Copy() StringDict {
+	e := make(StringDict, len(d))
+	for k, v := range d {
+		e[k] = v
+	}
+	return e
+}
+
+// Len returns the number of items in the dictionary
+func (d StringDict) Len() int {
+	return len(d)
+}
+
+// Get returns the value stored under key and whether it was found
+//
+// Returns a TypeError if key is unhashable
+func (d StringDict) Get(key Object) (Object, bool, error) {
+	str, ok := key.(String)
+	if !ok {
+		_, err := Hash(key)
+		return nil, false, err
+	}
+	value, ok := d[string(str)]
+	return value, ok, nil
+}
+
+// Set stores value under key which must be a string
+func (d StringDict) Set(key, value Object) error {
+	str, ok := key.(String)
+	if !ok {
+		return ExceptionNewf(TypeError, "keys must be strings, not '%s'", key.Type().Name)
+	}
+	d[string(str)] = value
+	return nil
+}
+
+// Delete removes key returning its value and whether it was found
+func (d StringDict) Delete(key Object) (Object, bool, error) {
+	value, found, err := d.Get(key)
+	if found {
+		delete(d, string(key.(String)))
+	}
+	return value, found, err
+}
+
+// Returns the keys of the dictionary sorted
+func (d StringDict) sortedKeys() []string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Keys returns the keys in sorted order
+func (d StringDict) Keys() []Object {
+	keys := make([]Object, 0, len(d))
+	for _, k := range d.sortedKeys() {
+		keys = append(keys, String(k))
+	}
+	return keys
+}
 
-	if _, ok := a[string(key)]; ok {
-		return True, nil
+// Values returns the values in the sorted order of their keys
+func (d StringDict) Values() []Object {
+	values := make([]Object, 0, len(d))
+	for _, k := range d.sortedKeys() {
+		values = append(values, d[k])
+	}
+	return values
+}
+
+// Items returns (key, value) pairs in sorted key order
+func (d StringDict) Items() []Tuple {
+	items := make([]Tuple, 0, len(d))
+	for _, k := range d.sortedKeys() {
+		items = append(items, Tuple{String(k), d[k]})
+	}
+	return items
+}
+
+// Clear removes all the items from the dictionary
+func (d StringDict) Clear() {
+	for k := range d {
+		delete(d, k)
 	}
-	return False, nil
 }
+
+func (a StringDict) M__str__() (Object, error) {
+	return a.M__repr__()
+}
+
+func (a StringDict) M__repr__() (Object, error) {
+	return dictRepr(a)
+}
+
+func (d StringDict) M__len__() (Object, error) {
+	return Int(len(d)), nil
+}
+
+// Returns an iterator over the keys of the dictionary
+func (d StringDict) M__iter__() (Object, error) {
+	return NewIterator(d.Keys()), nil
+}
+
+func (d StringDict) M__getitem__(key Object) (Object, error) {
+	return dictGetItem(d, key)
+}
+
+func (d StringDict) M__setitem__(key, value Object) (Object, error) {
+	return None, d.Set(key, value)
+}
+
+func (d StringDict) M__delitem__(key Object) (Object, error) {
+	return dictDelItem(d, key)
+}
+
+func (a StringDict) M__eq__(other Object) (Object, error) {
+	return dictEq(a, other)
+}
+
+func (a StringDict) M__ne__(other Object) (Object, error) {
+	return dictNe(a, other)
+}
+
+func (a StringDict) M__contains__(other Object) (Object, error) {
+	return dictContains(a, other)
+}
+
+// Check interface is satisfied
+var _ I__len__ = (*Dict)(nil)
+var _ I__iter__ = (*Dict)(nil)
+var _ I__contains__ = (*Dict)(nil)
+var _ I__getitem__ = (*Dict)(nil)
+var _ I__setitem__ = (*Dict)(nil)
+var _ I__delitem__ = (*Dict)(nil)
@@ -0,0 +1,347 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Dictionary view objects
+//
+// These are returned by dict.keys(), dict.values() and dict.items().
+// They don't copy the dictionary so they see any changes made to it.
+// The keys and items views are set-like so support the set operators.
+
+package py
+
+import "bytes"
+
+var (
+	DictKeysType   = NewType("dict_keys", "")
+	DictValuesType = NewType("dict_values", "")
+	DictItemsType  = NewType("dict_items", "")
+)
+
+// A view on the keys of a dictionary
+type DictKeys struct {
+	dict dictObject
+}
+
+// A view on the values of a dictionary
+type DictValues struct {
+	dict dictObject
+}
+
+// A view on the (key, value) pairs of a dictionary
+type DictItems struct {
+	dict dictObject
+}
+
+func init() {
+	for _, t := range []*Type{DictKeysType, DictItemsType} {
+		t.Dict["isdisjoint"] = MustNewMethod("isdisjoint", func(self Object, args Tuple) (Object, error) {
+			var other Object
+			err := UnpackTuple(args, nil, "isdisjoint", 1, 1, &other)
+			if err != nil {
+				return nil, err
+			}
+			a, err := viewAsSet(self)
+			if err != nil {
+				return nil, err
+			}
+			b, err := toSet(other)
+			if err != nil {
+				return nil, err
+			}
+			res, err := a.IsDisjoint(b)
+			if err != nil {
+				return nil, err
+			}
+			return NewBool(res), nil
+		}, 0, "Return True if the view and the given iterable have a null intersection.")
+	}
+}
+
+// Type of this DictKeys object
+func (o *DictKeys) Type() *Type {
+	return DictKeysType
+}
+
+// Type of this DictValues object
+func (o *DictValues) Type() *Type {
+	return DictValuesType
+}
+
+// Type of this DictItems object
+func (o *DictItems) Type() *Type {
+	return DictItemsType
+}
+
+// Returns the items of a view
+func (v *DictKeys) viewItems() []Object {
+	return v.dict.Keys()
+}
+
+func (v *DictValues) viewItems() []Object {
+	return v.dict.Values()
+}
+
+func (v *DictItems) viewItems() []Object {
+	items := v.dict.Items()
+	o := make([]Object, len(items))
+	for i, item := range items {
+		o[i] = item
+	}
+	return o
+}
+
+// Writes name([item, ...])
+func viewRepr(name string, items []Object) (Object, error) {
+	var out bytes.Buffer
+	out.WriteString(name)
+	out.WriteString("([")
+	for i, item := range items {
+		if i != 0 {
+			out.WriteString(", ")
+		}
+		str, err := ReprAsString(item)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(str)
+	}
+	out.WriteString("])")
+	return String(out.String()), nil
+}
+
+func (v *DictKeys) M__len__() (Object, error) {
+	return Int(v.dict.Len()), nil
+}
+
+func (v *DictKeys) M__iter__() (Object, error) {
+	return NewIterator(v.viewItems()), nil
+}
+
+func (v *DictKeys) M__repr__() (Object, error) {
+	return viewRepr("dict_keys", v.viewItems())
+}
+
+func (v *DictKeys) M__contains__(key Object) (Object, error) {
+	return dictContains(v.dict, key)
+}
+
+func (v *DictValues) M__len__() (Object, error) {
+	return Int(v.dict.Len()), nil
+}
+
+func (v *DictValues) M__iter__() (Object, error) {
+	return NewIterator(v.viewItems()), nil
+}
+
+func (v *DictValues) M__repr__() (Object, error) {
+	return viewRepr("dict_values", v.viewItems())
+}
+
+func (v *DictItems) M__len__() (Object, error) {
+	return Int(v.dict.Len()), nil
+}
+
+func (v *DictItems) M__iter__() (Object, error) {
+	return NewIterator(v.viewItems()), nil
+}
+
+func (v *DictItems) M__repr__() (Object, error) {
+	return viewRepr("dict_items", v.viewItems())
+}
+
+// Returns whether the (key, value) pair item is in the dictionary
+func (v *DictItems) M__contains__(item Object) (Object, error) {
+	pair, ok := item.(Tuple)
+	if !ok || len(pair) != 2 {
+		return False, nil
+	}
+	value, found, err := v.dict.Get(pair[0])
+	if err != nil || !found {
+		return False, err
+	}
+	return Eq(value, pair[1])
+}
+
+// The keys and items views can be used as sets
+type setView interface {
+	viewItems() []Object
+}
+
+// Returns obj as a set if it is a set-like view
+func viewAsSet(obj Object) (*Set, error) {
+	switch x := obj.(type) {
+	case *DictKeys, *DictItems:
+		return NewSetFromItems(x.(setView).viewItems())
+	}
+	return nil, nil
+}
+
+// Applies the set operation op to a and b, at least one of which is a
+// view, returning a set or NotImplemented if the other isn't iterable
+func viewBinaryOp(a, b Object, op func(a, b *Set) (*Set, error)) (Object, error) {
+	toViewSet := func(obj Object) (*Set, error) {
+		if s, err := viewAsSet(obj); s != nil || err != nil {
+			return s, err
+		}
+		if s := asSet(obj); s != nil {
+			return s, nil
+		}
+		return SequenceSet(obj)
+	}
+	for _, obj := range []Object{a, b} {
+		if _, err := Iter(obj); err != nil {
+			return NotImplemented, nil
+		}
+	}
+	sa, err := toViewSet(a)
+	if err != nil {
+		return nil, err
+	}
+	sb, err := toViewSet(b)
+	if err != nil {
+		return nil, err
+	}
+	return op(sa, sb)
+}
+
+// Compares a view with a set or another view or returns NotImplemented
+func viewCompare(a, other Object, cmp func(a, b *Set) (bool, error)) (Object, error) {
+	b, err := viewAsSet(other)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		if b = asSet(other); b == nil {
+			return NotImplemented, nil
+		}
+	}
+	sa, err := viewAsSet(a)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cmp(sa, b)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(res), nil
+}
+
+func (v *DictKeys) M__and__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).Intersection)
+}
+
+func (v *DictKeys) M__rand__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).Intersection)
+}
+
+func (v *DictKeys) M__or__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).Union)
+}
+
+func (v *DictKeys) M__ror__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).Union)
+}
+
+func (v *DictKeys) M__sub__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).Difference)
+}
+
+func (v *DictKeys) M__rsub__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).Difference)
+}
+
+func (v *DictKeys) M__xor__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).SymmetricDifference)
+}
+
+func (v *DictKeys) M__rxor__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).SymmetricDifference)
+}
+
+func (v *DictKeys) M__eq__(other Object) (Object, error) {
+	return viewCompare(v, other, setEq)
+}
+
+func (v *DictKeys) M__ne__(other Object) (Object, error) {
+	return viewCompare(v, other, setNe)
+}
+
+func (v *DictKeys) M__lt__(other Object) (Object, error) {
+	return viewCompare(v, other, setLt)
+}
+
+func (v *DictKeys) M__le__(other Object) (Object, error) {
+	return viewCompare(v, other, setLe)
+}
+
+func (v *DictKeys) M__gt__(other Object) (Object, error) {
+	return viewCompare(v, other, setGt)
+}
+
+func (v *DictKeys) M__ge__(other Object) (Object, error) {
+	return viewCompare(v, other, setGe)
+}
+
+func (v *DictItems) M__and__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).Intersection)
+}
+
+func (v *DictItems) M__rand__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).Intersection)
+}
+
+func (v *DictItems) M__or__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).Union)
+}
+
+func (v *DictItems) M__ror__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).Union)
+}
+
+func (v *DictItems) M__sub__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).Difference)
+}
+
+func (v *DictItems) M__rsub__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).Difference)
+}
+
+func (v *DictItems) M__xor__(other Object) (Object, error) {
+	return viewBinaryOp(v, other, (*Set).SymmetricDifference)
+}
+
+func (v *DictItems) M__rxor__(other Object) (Object, error) {
+	return viewBinaryOp(other, v, (*Set).SymmetricDifference)
+}
+
+func (v *DictItems) M__eq__(other Object) (Object, error) {
+	return viewCompare(v, other, setEq)
+}
+
+func (v *DictItems) M__ne__(other Object) (Object, error) {
+	return viewCompare(v, other, setNe)
+}
+
+func (v *DictItems) M__lt__(other Object) (Object, error) {
+	return viewCompare(v, other, setLt)
+}
+
+func (v *DictItems) M__le__(other Object) (Object, error) {
+	return viewCompare(v, other, setLe)
+}
+
+func (v *DictItems) M__gt__(other Object) (Object, error) {
+	return viewCompare(v, other, setGt)
+}
+
+func (v *DictItems) M__ge__(other Object) (Object, error) {
+	return viewCompare(v, other, setGe)
+}
+
+// Check interface is satisfied
+var _ I__len__ = (*DictKeys)(nil)
+var _ I__iter__ = (*DictValues)(nil)
+var _ I__contains__ = (*DictItems)(nil)
+var _ richComparison = (*DictKeys)(nil)
+var _ richComparison = (*DictItems)(nil)
@@ -26,6 +26,15 @@ func (a EllipsisType) M__repr__() (Object, error) {
 	return String("Ellipsis"), nil
 }
 
+func (a EllipsisType) M__hash__() (Object, error) {
+	// Ellipsis is a singleton so hash the identity of its type
+	h, err := HashPointer(EllipsisTypeType)
+	if err != nil {
+		return nil, err
+	}
+	return Int(h), nil
+}
+
 func (a EllipsisType) M__eq__(other Object) (Object, error) {
 	if _, ok := other.(EllipsisType); ok {
 		return True, nil
@@ -45,3 +54,4 @@ var _ I__bool__ = Ellipsis
 var _ I__repr__ = Ellipsis
 var _ I__eq__ = Ellipsis
 var _ I__eq__ = Ellipsis
+var _ I__hash__ = Ellipsis
@@ -10,6 +10,9 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
+	"strings"
+	"syscall"
 )
 
 // A python Exception object
@@ -34,12 +37,13 @@ type ExceptionInfo struct {
 
 var (
 	// Exception heirachy
-	BaseException             = ObjectType.NewTypeFlags("BaseException", "Common base class for all exceptions", ExceptionNew, nil, ObjectType.Flags|TPFLAGS_BASE_EXC_SUBCLASS)
+	BaseException             = ObjectType.NewTypeFlags("BaseException", "Common base class for all exceptions", ExceptionNew, ExceptionInit, ObjectType.Flags|TPFLAGS_BASE_EXC_SUBCLASS)
 	SystemExit                = BaseException.NewType("SystemExit", "Request to exit from the interpreter.", nil, nil)
 	KeyboardInterrupt         = BaseException.NewType("KeyboardInterrupt", "Program interrupted by user.", nil, nil)
 	GeneratorExit             = BaseException.NewType("GeneratorExit", "Request that a generator exit.", nil, nil)
 	ExceptionType             = BaseException.NewType("Exception", "Common base class for all non-exit exceptions.", nil, nil)
 	StopIteration             = ExceptionType.NewType("StopIteration", "Signal the end from iterator.__next__().", nil, nil)
+	StopAsyncIteration        = ExceptionType.NewType("StopAsyncIteration", "Signal the end from iterator.__anext__().", nil, nil)
 	ArithmeticError           = ExceptionType.NewType("ArithmeticError", "Base class for arithmetic errors.", nil, nil)
 	FloatingPointError        = ArithmeticError.NewType("FloatingPointError", "Floating point operation failed.", nil, nil)
 	OverflowError             = ArithmeticError.NewType("OverflowError", "Result too large to be represented.", nil, nil)
@@ -74,7 +78,8 @@ var (
 	ReferenceError            = ExceptionType.NewType("ReferenceError", "Weak ref proxy used after referent went away.", nil, nil)
 	RuntimeError              = ExceptionType.NewType("RuntimeError", "Unspecified run-time error.", nil, nil)
 	NotImplementedError       = RuntimeError.NewType("NotImplementedError", "Method or function hasn't been implemented yet.", nil, nil)
-	SyntaxError               = ExceptionType.NewType("SyntaxError", "Invalid syntax.", nil, nil)
+	RecursionError            = RuntimeError.NewType("RecursionError", "Recursion limit exceeded.", nil, nil)
+	SyntaxError               = ExceptionType.NewType("SyntaxError", "Invalid syntax.", nil, syntaxErrorInit)
 	IndentationError          = SyntaxError.NewType("IndentationError", "Improper indentation.", nil, nil)
 	TabError                  = IndentationError.NewType("TabError", "Improper mixture of spaces and tabs.", nil, nil)
 	SystemError               = ExceptionType.NewType("SystemError", "Internal error in the Gpython interpreter.\n\nPlease report this to the Gpython maintainer, along with the traceback,\nthe Gpython version, and the hardware/OS platform and version.", nil, nil)
@@ -132,13 +137,43 @@ func (e *Exception) Error() string {
 			}
 		}
 	}
-	// FIXME Print out special stuff for things which look like SyntaxErrors
-	if e.Dict["lineno"] != nil {
-		message = fmt.Sprintf("\n  File \"%v\", line %v, offset %v\n    %s\n\n", e.Dict["filename"], e.Dict["lineno"], e.Dict["offset"], e.Dict["line"]) + message
+	// Print out special stuff for things which look like SyntaxErrors
+	if e.hasLocation() {
+		message = "\n" + e.syntaxErrorLocation() + message
 	}
 	return message
 }
 
+// Returns whether e has the line number of a SyntaxError
+func (e *Exception) hasLocation() bool {
+	_, ok := e.Dict["lineno"].(Int)
+	return ok
+}
+
+// Returns where a SyntaxError is as CPython prints it before the
+// message, the file and line number then the line of source with
+// carets under the error
+func (e *Exception) syntaxErrorLocation() string {
+	location := fmt.Sprintf("  File \"%v\", line %v\n", e.Dict["filename"], e.Dict["lineno"])
+	line, _ := e.Dict["text"].(String)
+	offset, ok := e.Dict["offset"].(Int)
+	if !ok || strings.TrimSpace(string(line)) == "" {
+		return location
+	}
+	// The offsets count from 1
+	offset--
+	endOffset := offset + 1
+	if end, ok := e.Dict["end_offset"].(Int); ok {
+		if e.Dict["end_lineno"] == e.Dict["lineno"] {
+			endOffset = end - 1
+		} else {
+			endOffset = Int(len(line))
+		}
+	}
+	text, start, end := sourceColumns(string(line), int(offset), int(endOffset))
+	return location + "    " + text + "\n    " + carets(start, end) + "\n"
+}
+
 // Go error interface
 func (e ExceptionInfo) Error() string {
 	if e.Value == nil {
@@ -151,14 +186,84 @@ func (e ExceptionInfo) Error() string {
 }
 
 // Dump a traceback for exc to w
+//
+// Any exceptions chained to the exception as its cause or context are
+// dumped first as CPython does.
 func (exc *ExceptionInfo) TracebackDump(w io.Writer) {
 	if exc == nil {
 		fmt.Fprintf(w, "Traceback <nil>\n")
 		return
 	}
-	fmt.Fprintf(w, "Traceback (most recent call last):\n")
-	exc.Traceback.TracebackDump(w)
-	fmt.Fprintf(w, "%v\n", exc.Value)
+	if e, ok := exc.Value.(*Exception); ok {
+		e.chainDump(w, map[*Exception]bool{e: true})
+	}
+	if exc.Traceback != nil {
+		fmt.Fprintf(w, "Traceback (most recent call last):\n")
+		exc.Traceback.TracebackDump(w)
+	}
+	fmt.Fprintf(w, "%s\n", exceptionLine(exc.Value))
+}
+
+// Dump a traceback for e and the exceptions chained to it to w
+func (e *Exception) TracebackDump(w io.Writer) {
+	tb, _ := e.Traceback.(*Traceback)
+	exc := ExceptionInfo{Type: e.Base, Value: e, Traceback: tb}
+	exc.TracebackDump(w)
+}
+
+const (
+	causeMessage   = "The above exception was the direct cause of the following exception:"
+	contextMessage = "During handling of the above exception, another exception occurred:"
+)
+
+// Dumps the exceptions chained to e to w, stopping at any already
+// seen to avoid looping forever
+func (e *Exception) chainDump(w io.Writer, seen map[*Exception]bool) {
+	var next *Exception
+	var message string
+	if cause, ok := e.Cause.(*Exception); ok {
+		next, message = cause, causeMessage
+	} else if context, ok := e.Context.(*Exception); ok && !e.SuppressContext {
+		next, message = context, contextMessage
+	}
+	if next == nil || seen[next] {
+		return
+	}
+	seen[next] = true
+	next.chainDump(w, seen)
+	if tb, ok := next.Traceback.(*Traceback); ok {
+		fmt.Fprintf(w, "Traceback (most recent call last):\n")
+		tb.TracebackDump(w)
+	}
+	fmt.Fprintf(w, "%s\n\n%s\n\n", exceptionLine(next), message)
+}
+
+// Returns the last line of a traceback for value, its type name and
+// its str() if not empty
+func exceptionLine(value Object) string {
+	if value == nil {
+		return "<nil>"
+	}
+	t := value.Type()
+	name := t.Name
+	if module, ok := t.Dict["__module__"].(String); ok && module != "builtins" {
+		name = string(module) + "." + name
+	}
+	str, err := StrAsString(value)
+	if err != nil {
+		str = "<exception str() failed>"
+	}
+	if e, ok := value.(*Exception); ok && t.IsSubtype(SyntaxError) && e.hasLocation() {
+		// The location is printed instead of being in the message
+		name = e.syntaxErrorLocation() + name
+		if msg, ok := e.Dict["msg"].(String); ok {
+			str = string(msg)
+		}
+	}
+	if str == "" {
+		return name
+	}
+	return name + ": " + str
 }
 
 // Test for being set
@@ -185,6 +290,46 @@ func ExceptionNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error)
 	return exceptionNew(metatype, args), nil
 }
 
+// ExceptionInit sets the args of the exception
+//
+// Keyword arguments have already been rejected by ExceptionNew
+func ExceptionInit(self Object, args Tuple, kwargs StringDict) error {
+	self.(*Exception).Args = args.Copy()
+	return nil
+}
+
+// Sets the args of a SyntaxError and its attributes from them, the
+// message and a tuple of where the error is, either of which may be
+// left out
+//
+// see Objects/exceptions.c SyntaxError_init
+func syntaxErrorInit(self Object, args Tuple, kwargs StringDict) error {
+	err := ExceptionInit(self, args, kwargs)
+	if err != nil {
+		return err
+	}
+	e := self.(*Exception)
+	for _, name := range []string{"msg", "filename", "lineno", "offset", "text", "end_lineno", "end_offset"} {
+		e.Dict[name] = None
+	}
+	if len(args) >= 1 {
+		e.Dict["msg"] = args[0]
+	}
+	if len(args) == 2 {
+		info, err := SequenceTuple(args[1])
+		if err != nil {
+			return err
+		}
+		if len(info) < 4 || len(info) > 6 {
+			return ExceptionNewf(TypeError, "SyntaxError location must be a tuple of 4 to 6 items, not %d", len(info))
+		}
+		for i, name := range []string{"filename", "lineno", "offset", "text", "end_lineno", "end_offset"}[:len(info)] {
+			e.Dict[name] = info[i]
+		}
+	}
+	return nil
+}
+
 // ExceptionNewf - make a new exception with fmt parameters
 func ExceptionNewf(metatype *Type, format string, a ...interface{}) *Exception {
 	message := fmt.Sprintf(format, a...)
@@ -195,6 +340,81 @@ func ExceptionNewf(metatype *Type, format string, a ...interface{}) *Exception {
 	}
 }
 
+// MakeOSError converts an error returned by the os package into the
+// subclass of OSError for its errno, setting errno, strerror and the
+// file names involved as CPython does
+func MakeOSError(err error) *Exception {
+	var filename, filename2 Object
+	for unwrapped := false; !unwrapped; {
+		switch e := err.(type) {
+		case *os.PathError:
+			filename, err = String(e.Path), e.Err
+		case *os.LinkError:
+			filename, filename2, err = String(e.Old), String(e.New), e.Err
+		case *os.SyscallError:
+			err = e.Err
+		default:
+			unwrapped = true
+		}
+	}
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		e := ExceptionNewf(OSError, "%v", err)
+		if filename != nil {
+			e.Dict["filename"] = filename
+		}
+		return e
+	}
+	t := OSError
+	switch {
+	case os.IsNotExist(errno):
+		t = FileNotFoundError
+	case os.IsExist(errno):
+		t = FileExistsError
+	case os.IsPermission(errno):
+		t = PermissionError
+	case errno == syscall.EISDIR:
+		t = IsADirectoryError
+	case errno == syscall.ENOTDIR:
+		t = NotADirectoryError
+	case errno == syscall.EINTR:
+		t = InterruptedError
+	case errno == syscall.ECHILD:
+		t = ChildProcessError
+	case errno == syscall.ESRCH:
+		t = ProcessLookupError
+	case errno == syscall.EAGAIN:
+		t = BlockingIOError
+	case errno == syscall.EPIPE:
+		t = BrokenPipeError
+	case errno == syscall.ECONNREFUSED:
+		t = ConnectionRefusedError
+	case errno == syscall.ECONNRESET:
+		t = ConnectionResetError
+	case errno == syscall.ECONNABORTED:
+		t = ConnectionAbortedError
+	case errno == syscall.ETIMEDOUT:
+		t = TimeoutError
+	}
+	strerror := errno.Error()
+	if strerror != "" {
+		strerror = strings.ToUpper(strerror[:1]) + strerror[1:]
+	}
+	args := Tuple{Int(errno), String(strerror)}
+	e := exceptionNew(t, args)
+	e.Dict["errno"] = Int(errno)
+	e.Dict["strerror"] = String(strerror)
+	if filename != nil {
+		e.Args = append(args, filename)
+		e.Dict["filename"] = filename
+		if filename2 != nil {
+			e.Args = append(args, filename, None, filename2)
+			e.Dict["filename2"] = filename2
+		}
+	}
+	return e
+}
+
 /*
 	if py.ExceptionClassCheck(exc) {
 		t = exc.(*py.Type)
@@ -237,14 +457,32 @@ func MakeException(r interface{}) *Exception {
 }
 
 // First calls MakeException then adds the extra details in to make it a SyntaxError
+//
+// The error is at the byte offset into line, which starts at 0, and
+// is set as the attributes of a SyntaxError, which count from 1.
 func MakeSyntaxError(r interface{}, filename string, lineno int, offset int, line string) *Exception {
-	// FIXME add more stuff to make it a SyntaxError!
 	// see Python/errors.c PyErr_SyntaxLocationObject
 	e := MakeException(r)
+	if args, ok := e.Args.(Tuple); ok && len(args) > 0 {
+		e.Dict["msg"] = args[0]
+	} else {
+		e.Dict["msg"] = None
+	}
 	e.Dict["filename"] = String(filename)
 	e.Dict["lineno"] = Int(lineno)
-	e.Dict["offset"] = Int(offset)
-	e.Dict["line"] = String(line)
+	e.Dict["offset"] = Int(offset + 1)
+	e.Dict["text"] = String(line)
+	e.Dict["end_lineno"] = Int(lineno)
+	e.Dict["end_offset"] = Int(offset + 1)
+	return e
+}
+
+// MakeSyntaxErrorRange is MakeSyntaxError for an error in the source
+// from lineno, offset up to endLineno, endOffset
+func MakeSyntaxErrorRange(r interface{}, filename string, lineno int, offset int, endLineno int, endOffset int, line string) *Exception {
+	e := MakeSyntaxError(r, filename, lineno, offset, line)
+	e.Dict["end_lineno"] = Int(endLineno)
+	e.Dict["end_offset"] = Int(endOffset + 1)
 	return e
 }
 
@@ -340,9 +578,16 @@ func IsException(exception *Type, r interface{}) bool {
 		t = ex.Type()
 	case *Type:
 		t = ex
+	case ExceptionInfo:
+		t = ex.Type
+	case *ExceptionInfo:
+		t = ex.Type
 	default:
 		return false
 	}
+	if t == nil {
+		return false
+	}
 	// Exact instance or subclass match
 	if t == exception {
 		return true
@@ -355,11 +600,310 @@ func IsException(exception *Type, r interface{}) bool {
 	return t.IsSubtype(exception)
 }
 
-// FIXME prototype __getattr__ before we do introspection!
-func (e *Exception) M__getattr__(name string) (Object, error) {
-	return e.Args, nil // FIXME All attributes are args!
+// StopIterationValue returns the value carried by a StopIteration
+// exception, ie its first argument or None if it has none
+func StopIterationValue(err interface{}) Object {
+	var value Object
+	switch ex := err.(type) {
+	case *Exception:
+		value = ex
+	case ExceptionInfo:
+		value = ex.Value
+	case *ExceptionInfo:
+		value = ex.Value
+	}
+	if exception, ok := value.(*Exception); ok {
+		if args, ok := exception.Args.(Tuple); ok && len(args) > 0 {
+			return args[0]
+		}
+	}
+	return None
+}
+
+// Returns the code of a SystemExit, which is None if it has no args,
+// its argument if it has one or else its args
+func systemExitCode(e *Exception) Object {
+	args := exceptionArgs(e)
+	switch len(args) {
+	case 0:
+		return None
+	case 1:
+		return args[0]
+	}
+	return args
+}
+
+// SystemExitStatus returns the exit status of the process for err if
+// it is a SystemExit which wasn't caught, working it out as the
+// interpreter does.  A code which isn't None or an int is written to
+// w and gives status 1.  ok is false if err isn't a SystemExit.
+func SystemExitStatus(err error, w io.Writer) (status int, ok bool) {
+	if !IsException(SystemExit, err) {
+		return 0, false
+	}
+	var value Object
+	switch ex := err.(type) {
+	case *Exception:
+		value = ex
+	case ExceptionInfo:
+		value = ex.Value
+	case *ExceptionInfo:
+		value = ex.Value
+	}
+	e, isException := value.(*Exception)
+	if !isException {
+		return 1, true
+	}
+	switch code := systemExitCode(e).(type) {
+	case NoneType:
+		return 0, true
+	case Int:
+		return int(code), true
+	case Bool:
+		// As from sys.exit(not ok)
+		if code {
+			return 1, true
+		}
+		return 0, true
+	default:
+		if str, err := Str(code); err == nil {
+			fmt.Fprintln(w, str)
+		}
+		return 1, true
+	}
+}
+
+// GetDict returns the attributes set on the exception
+func (e *Exception) GetDict() StringDict {
+	return e.Dict
+}
+
+// Returns obj or None if obj is nil
+func noneIfNil(obj Object) Object {
+	if obj == nil {
+		return None
+	}
+	return obj
+}
+
+// Checks value is None or an exception instance for use as the cause
+// or context of an exception returning nil for None
+func exceptionOrNone(value Object, name string) (Object, error) {
+	if value == None {
+		return nil, nil
+	}
+	if _, ok := value.(*Exception); !ok {
+		return nil, ExceptionNewf(TypeError, "exception %s must be None or derive from BaseException", name)
+	}
+	return value, nil
+}
+
+// SetContext sets the context of e to context, the exception which
+// was being handled when e was raised
+//
+// Any reference to e in the chain of contexts of context is removed
+// to avoid making a cycle.
+func (e *Exception) SetContext(context *Exception) {
+	if context == e {
+		return
+	}
+	for o := context; o != nil; {
+		next, ok := o.Context.(*Exception)
+		if !ok {
+			break
+		}
+		if next == e {
+			o.Context = nil
+			break
+		}
+		o = next
+	}
+	e.Context = context
+}
+
+// Returns the args of an exception as a Tuple
+func exceptionArgs(e *Exception) Tuple {
+	if args, ok := e.Args.(Tuple); ok {
+		return args
+	}
+	return nil
+}
+
+// Calls the method name of e with no arguments looking it up in the
+// type so that subclasses can override it
+func (e *Exception) callMethod(name string) (Object, error) {
+	fn, err := descriptorGet(e.Base.Lookup(name), e, e.Base)
+	if err != nil {
+		return nil, err
+	}
+	return Call(fn, nil, nil)
+}
+
+func (e *Exception) M__str__() (Object, error) {
+	return e.callMethod("__str__")
+}
+
+func (e *Exception) M__repr__() (Object, error) {
+	return e.callMethod("__repr__")
+}
+
+// Properties and methods
+func init() {
+	BaseException.Dict["args"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return exceptionArgs(self.(*Exception)), nil
+		},
+		Fset: func(self, value Object) error {
+			args, err := SequenceTuple(value)
+			if err != nil {
+				return err
+			}
+			self.(*Exception).Args = args
+			return nil
+		},
+	}
+	BaseException.Dict["__traceback__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return noneIfNil(self.(*Exception).Traceback), nil
+		},
+		Fset: func(self, value Object) error {
+			if _, ok := value.(*Traceback); !ok && value != None {
+				return ExceptionNewf(TypeError, "__traceback__ must be a traceback or None")
+			}
+			if value == None {
+				value = nil
+			}
+			self.(*Exception).Traceback = value
+			return nil
+		},
+	}
+	BaseException.Dict["__cause__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return noneIfNil(self.(*Exception).Cause), nil
+		},
+		Fset: func(self, value Object) error {
+			cause, err := exceptionOrNone(value, "cause")
+			if err != nil {
+				return err
+			}
+			e := self.(*Exception)
+			e.Cause = cause
+			e.SuppressContext = true
+			return nil
+		},
+		Doc: "exception cause",
+	}
+	BaseException.Dict["__context__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return noneIfNil(self.(*Exception).Context), nil
+		},
+		Fset: func(self, value Object) error {
+			context, err := exceptionOrNone(value, "context")
+			if err != nil {
+				return err
+			}
+			self.(*Exception).Context = context
+			return nil
+		},
+		Doc: "exception context",
+	}
+	BaseException.Dict["__suppress_context__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return NewBool(self.(*Exception).SuppressContext), nil
+		},
+		Fset: func(self, value Object) error {
+			suppress, err := MakeBool(value)
+			if err != nil {
+				return err
+			}
+			self.(*Exception).SuppressContext = suppress == True
+			return nil
+		},
+	}
+	BaseException.Dict["with_traceback"] = MustNewMethod("with_traceback", func(self, tb Object) (Object, error) {
+		_, err := SetAttrString(self, "__traceback__", tb)
+		if err != nil {
+			return nil, err
+		}
+		return self, nil
+	}, 0, "Exception.with_traceback(tb) --\n    set self.__traceback__ to tb and return self.")
+	BaseException.Dict["__str__"] = MustNewMethod("__str__", func(self Object) (Object, error) {
+		args := exceptionArgs(self.(*Exception))
+		switch len(args) {
+		case 0:
+			return String(""), nil
+		case 1:
+			return Str(args[0])
+		}
+		return Str(args)
+	}, 0, "Return str(self).")
+	BaseException.Dict["__repr__"] = MustNewMethod("__repr__", func(self Object) (Object, error) {
+		e := self.(*Exception)
+		args, err := ReprAsString(exceptionArgs(e))
+		if err != nil {
+			return nil, err
+		}
+		return String(e.Base.Name + args), nil
+	}, 0, "Return repr(self).")
+	KeyError.Dict["__str__"] = MustNewMethod("__str__", func(self Object) (Object, error) {
+		// Show the repr of a missing key so KeyError('') isn't blank
+		if args := exceptionArgs(self.(*Exception)); len(args) == 1 {
+			return Repr(args[0])
+		}
+		return BaseException.Dict["__str__"].(*Method).Call(self, nil)
+	}, 0, "Return str(self).")
+	OSError.Dict["__str__"] = MustNewMethod("__str__", func(self Object) (Object, error) {
+		e := self.(*Exception)
+		errno, strerror := e.Dict["errno"], e.Dict["strerror"]
+		if errno == nil || strerror == nil {
+			return BaseException.Dict["__str__"].(*Method).Call(self, nil)
+		}
+		message := fmt.Sprintf("[Errno %v] %v", errno, strerror)
+		if filename, ok := e.Dict["filename"]; ok {
+			repr, err := ReprAsString(filename)
+			if err != nil {
+				return nil, err
+			}
+			message += ": " + repr
+			if filename2, ok := e.Dict["filename2"]; ok {
+				repr, err := ReprAsString(filename2)
+				if err != nil {
+					return nil, err
+				}
+				message += " -> " + repr
+			}
+		}
+		return String(message), nil
+	}, 0, "Return str(self).")
+	for _, name := range []string{"errno", "strerror", "filename", "filename2"} {
+		name := name
+		OSError.Dict[name] = &Property{
+			Fget: func(self Object) (Object, error) {
+				return noneIfNil(self.(*Exception).Dict[name]), nil
+			},
+			Fset: func(self, value Object) error {
+				self.(*Exception).Dict[name] = value
+				return nil
+			},
+		}
+	}
+	SystemExit.Dict["code"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return systemExitCode(self.(*Exception)), nil
+		},
+		Doc: "exception code",
+	}
+	StopIteration.Dict["value"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return StopIterationValue(self), nil
+		},
+		Doc: "generator return value",
+	}
 }
 
 // Check Interfaces
 var _ error = (*Exception)(nil)
+var _ I__str__ = (*Exception)(nil)
+var _ I__repr__ = (*Exception)(nil)
+var _ IGetDict = (*Exception)(nil)
 var _ error = (*ExceptionInfo)(nil)
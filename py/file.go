@@ -4,34 +4,210 @@
 
 // File object
 //
-// FIXME cpython 3.3 has a compicated heirachy of types to implement
-// this which we do not emulate yet
+// A File is the object returned by open().  CPython stacks a raw
+// FileIO, a buffer and a TextIOWrapper on top of each other to make
+// one - gpython does it all in the File but gives it the type of the
+// outermost object so it looks the same to python code.
+//
+// Reads are always buffered.  Writes are kept in a buffer of the size
+// given to open() until it is full, the file is flushed or the file
+// is read or seeked.  Text files are read and written in the encoding
+// given to open() translating newlines as directed by newline.
+//
+// A File holding writes not yet made to the os.File is kept in a
+// registry until they are, so it isn't collected with them lost and
+// FlushFiles can write them out when the interpreter exits.
 
 package py
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"unicode/utf8"
 )
 
-var FileType = NewType("file", `represents an open file`)
-var errClosed = ExceptionNewf(ValueError, "I/O operation on closed file.")
+// DefaultBufferSize is the size of the write buffer of files opened
+// with the default buffering
+const DefaultBufferSize = 8192
+
+var (
+	IOBaseType         = NewType("IOBase", "The abstract base class for all I/O classes.")
+	RawIOBaseType      = IOBaseType.NewType("RawIOBase", "Base class for raw binary I/O.", nil, nil)
+	BufferedIOBaseType = IOBaseType.NewType("BufferedIOBase", "Base class for buffered IO objects.", nil, nil)
+	TextIOBaseType     = IOBaseType.NewType("TextIOBase", "Base class for text I/O.", nil, nil)
+	FileIOType         = RawIOBaseType.NewType("FileIO", "Open a file unbuffered in binary mode.", nil, nil)
+	BufferedReaderType = BufferedIOBaseType.NewType("BufferedReader", "A buffered binary file opened for reading.", nil, nil)
+	BufferedWriterType = BufferedIOBaseType.NewType("BufferedWriter", "A buffered binary file opened for writing.", nil, nil)
+	BufferedRandomType = BufferedIOBaseType.NewType("BufferedRandom", "A buffered binary file opened for reading and writing.", nil, nil)
+	TextIOWrapperType  = TextIOBaseType.NewType("TextIOWrapper", "A text file.", nil, nil)
+
+	// UnsupportedOperation is io.UnsupportedOperation, raised when
+	// a file doesn't support an operation
+	UnsupportedOperation = OSError.NewType("UnsupportedOperation", "", nil, nil)
+
+	errClosed = ExceptionNewf(ValueError, "I/O operation on closed file.")
+
+	unflushedMu sync.Mutex
+	unflushed   = map[*File]struct{}{} // the Files with writes in their buffer
+)
 
 func init() {
-	FileType.Dict["write"] = MustNewMethod("write", func(self Object, value Object) (Object, error) {
-		return self.(*File).Write(value)
-	}, 0, "write(arg) -> writes the contents of arg to the file, returning the number of characters written.")
+	// UnsupportedOperation is a ValueError too
+	if err := UnsupportedOperation.SetBases(Tuple{OSError, ValueError}); err != nil {
+		panic(err)
+	}
 
-	FileType.Dict["read"] = MustNewMethod("read", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
-		return self.(*File).Read(args, kwargs)
-	}, 0, "read([size]) -> read at most size bytes, returned as a string.\n\nIf the size argument is negative or omitted, read until EOF is reached.\nNotice that when in non-blocking mode, less data than what was requested\nmay be returned, even if no size parameter was given.")
-	FileType.Dict["close"] = MustNewMethod("close", func(self Object) (Object, error) {
-		return self.(*File).Close()
-	}, 0, "close() -> None or (perhaps) an integer.  Close the file.\n\nSets data attribute .closed to True.  A closed file cannot be used for\nfurther I/O operations.  close() may be called more than once without\nerror.  Some kinds of file objects (for example, opened by popen())\nmay return an exit status upon closing.")
-	FileType.Dict["flush"] = MustNewMethod("flush", func(self Object) (Object, error) {
-		return self.(*File).Flush()
-	}, 0, "flush() -> Flush the write buffers of the stream if applicable. This does nothing for read-only and non-blocking streams.")
+	methods := []*Method{
+		MustNewMethod("read", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			return self.(*File).Read(args, kwargs)
+		}, 0, "read([size]) -> read at most size bytes or characters.\n\nIf the size argument is negative or omitted, read until EOF is reached."),
+		MustNewMethod("readline", func(self Object, args Tuple) (Object, error) {
+			size, err := sizeArg("readline", args)
+			if err != nil {
+				return nil, err
+			}
+			return self.(*File).ReadLine(size)
+		}, 0, "readline([size]) -> next line from the file.\n\nRetain newline.  A non-negative size argument limits the maximum\nnumber of bytes or characters to return."),
+		MustNewMethod("readlines", func(self Object, args Tuple) (Object, error) {
+			hint, err := sizeArg("readlines", args)
+			if err != nil {
+				return nil, err
+			}
+			return ReadLines(self, hint)
+		}, 0, "readlines([hint]) -> list of lines from the file.\n\nStops once the lines read total more than hint."),
+		MustNewMethod("write", func(self Object, value Object) (Object, error) {
+			return self.(*File).Write(value)
+		}, 0, "write(arg) -> writes the contents of arg to the file, returning the number of characters written."),
+		MustNewMethod("writelines", func(self Object, lines Object) (Object, error) {
+			return WriteLines(self, lines)
+		}, 0, "writelines(lines) -> write each of the lines to the file.\n\nLine separators are not added."),
+		MustNewMethod("seek", func(self Object, args Tuple) (Object, error) {
+			var offset Object
+			var whence Object = Int(io.SeekStart)
+			err := UnpackTuple(args, nil, "seek", 1, 2, &offset, &whence)
+			if err != nil {
+				return nil, err
+			}
+			off, err := IndexInt(offset)
+			if err != nil {
+				return nil, err
+			}
+			wh, err := IndexInt(whence)
+			if err != nil {
+				return nil, err
+			}
+			return self.(*File).M_seek(int64(off), wh)
+		}, 0, "seek(offset[, whence]) -> change the stream position to offset.\n\nwhence is 0 for the start of the file, 1 for the current position and\n2 for the end of the file.  Returns the new position."),
+		MustNewMethod("tell", func(self Object) (Object, error) {
+			return self.(*File).Tell()
+		}, 0, "tell() -> current stream position."),
+		MustNewMethod("truncate", func(self Object, args Tuple) (Object, error) {
+			var size Object = None
+			err := UnpackTuple(args, nil, "truncate", 0, 1, &size)
+			if err != nil {
+				return nil, err
+			}
+			return self.(*File).Truncate(size)
+		}, 0, "truncate([size]) -> truncate the file to at most size bytes.\n\nSize defaults to the current position.  The position isn't changed."),
+		MustNewMethod("close", func(self Object) (Object, error) {
+			return self.(*File).Close()
+		}, 0, "close() -> None.  Flush and close the file.\n\nA closed file cannot be used for further I/O operations.  close() may\nbe called more than once without error."),
+		MustNewMethod("flush", func(self Object) (Object, error) {
+			return self.(*File).Flush()
+		}, 0, "flush() -> Flush the write buffers of the stream if applicable. This does nothing for read-only and non-blocking streams."),
+		MustNewMethod("fileno", func(self Object) (Object, error) {
+			o := self.(*File)
+			if o.closed {
+				return nil, errClosed
+			}
+			return Int(o.File.Fd()), nil
+		}, 0, "fileno() -> the underlying file descriptor."),
+		MustNewMethod("isatty", func(self Object) (Object, error) {
+			o := self.(*File)
+			if o.closed {
+				return nil, errClosed
+			}
+			fi, err := o.File.Stat()
+			return NewBool(err == nil && fi.Mode()&os.ModeCharDevice != 0), nil
+		}, 0, "isatty() -> whether the file is connected to a terminal."),
+		MustNewMethod("readable", func(self Object) (Object, error) {
+			o := self.(*File)
+			if o.closed {
+				return nil, errClosed
+			}
+			return NewBool(o.Can(FileRead)), nil
+		}, 0, "readable() -> whether the file was opened for reading."),
+		MustNewMethod("writable", func(self Object) (Object, error) {
+			o := self.(*File)
+			if o.closed {
+				return nil, errClosed
+			}
+			return NewBool(o.Can(FileWrite)), nil
+		}, 0, "writable() -> whether the file was opened for writing."),
+		MustNewMethod("seekable", func(self Object) (Object, error) {
+			o := self.(*File)
+			if o.closed {
+				return nil, errClosed
+			}
+			_, err := o.File.Seek(0, io.SeekCurrent)
+			return NewBool(err == nil), nil
+		}, 0, "seekable() -> whether the file supports seek()."),
+	}
+	properties := StringDict{
+		"closed": &Property{
+			Fget: func(self Object) (Object, error) {
+				return NewBool(self.(*File).closed), nil
+			},
+		},
+		"name": &Property{
+			Fget: func(self Object) (Object, error) {
+				return self.(*File).name(), nil
+			},
+		},
+		"mode": &Property{
+			Fget: func(self Object) (Object, error) {
+				return String(self.(*File).modeString()), nil
+			},
+		},
+	}
+	textProperties := StringDict{
+		"encoding": &Property{
+			Fget: func(self Object) (Object, error) {
+				o := self.(*File)
+				if o.encoding == "" {
+					return String("UTF-8"), nil
+				}
+				return String(o.encoding), nil
+			},
+		},
+		"errors": &Property{
+			Fget: func(self Object) (Object, error) {
+				return String(self.(*File).errorsHandler()), nil
+			},
+		},
+		"line_buffering": &Property{
+			Fget: func(self Object) (Object, error) {
+				return NewBool(self.(*File).lineBuffering), nil
+			},
+		},
+	}
+	for _, t := range []*Type{FileIOType, BufferedReaderType, BufferedWriterType, BufferedRandomType, TextIOWrapperType} {
+		for _, method := range methods {
+			t.Dict[method.Name] = method
+		}
+		for name, property := range properties {
+			t.Dict[name] = property
+		}
+	}
+	for name, property := range textProperties {
+		TextIOWrapperType.Dict[name] = property
+	}
 }
 
 type FileMode int
@@ -45,56 +221,280 @@ const (
 	FileReadWrite = FileRead + FileWrite
 )
 
+// File is an open file
+//
+// Just File and FileMode need setting to make a File for an open
+// os.File which is unbuffered for writing and uses the defaults of
+// open() for everything else.
 type File struct {
 	*os.File
 	FileMode
+	closed bool
+
+	nameObj       Object // the name passed to open() if set
+	mode          string // the mode passed to open()
+	raw           bool   // set if the file is an unbuffered binary file
+	encoding      string // the encoding of a text file, utf-8 if not set
+	errors        string // the encoding error handler, strict if not set
+	newline       Object // the newline argument to open(), None if not set
+	bufSize       int    // size of the write buffer
+	lineBuffering bool   // set to flush writes at the end of each line
+	keepFd        bool   // set if the os.File isn't to be closed with the File
+	rbuf          []byte // bytes read from the os.File but not from the File
+	wbuf          []byte // bytes written to the File but not to the os.File
+	eof           bool   // set when a read reaches the end of the file
 }
 
 // Type of this object
 func (o *File) Type() *Type {
-	return FileType
+	switch {
+	case !o.Can(FileBinary):
+		return TextIOWrapperType
+	case o.raw:
+		return FileIOType
+	case o.Can(FileReadWrite):
+		return BufferedRandomType
+	case o.Can(FileWrite):
+		return BufferedWriterType
+	}
+	return BufferedReaderType
 }
 
 func (o *File) Can(mode FileMode) bool {
 	return o.FileMode&mode == mode
 }
 
-func (o *File) Write(value Object) (Object, error) {
-	var b []byte
+// Returns the name attribute
+func (o *File) name() Object {
+	if o.nameObj != nil {
+		return o.nameObj
+	}
+	return String(o.File.Name())
+}
 
-	switch v := value.(type) {
-	// FIXME Bytearray
-	case Bytes:
-		b = v
+// Returns the mode attribute
+func (o *File) modeString() string {
+	if o.mode != "" {
+		return o.mode
+	}
+	mode := "r"
+	switch {
+	case o.Can(FileReadWrite):
+		mode = "r+"
+	case o.Can(FileWrite):
+		mode = "w"
+	}
+	if o.Can(FileBinary) {
+		mode += "b"
+	}
+	return mode
+}
 
-	case String:
-		b = []byte(v)
+// Returns the name of the encoding error handler
+func (o *File) errorsHandler() string {
+	if o.errors == "" {
+		return "strict"
+	}
+	return o.errors
+}
 
-	default:
-		return nil, ExceptionNewf(TypeError, "expected a string or other character buffer object")
+func (o *File) M__repr__() (Object, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<_io.%s", o.Type().Name)
+	name, err := ReprAsString(o.name())
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&buf, " name=%s", name)
+	if o.Type() != BufferedReaderType && o.Type() != BufferedWriterType && o.Type() != BufferedRandomType {
+		fmt.Fprintf(&buf, " mode='%s'", o.modeString())
+	}
+	if !o.Can(FileBinary) {
+		encoding := o.encoding
+		if encoding == "" {
+			encoding = "UTF-8"
+		}
+		fmt.Fprintf(&buf, " encoding='%s'", encoding)
+	}
+	buf.WriteString(">")
+	return String(buf.String()), nil
+}
+
+// Converts an error from the os package into a python exception
+func osError(err error) error {
+	if perr, ok := err.(*os.PathError); ok && perr.Err == os.ErrClosed {
+		return errClosed
 	}
+	return MakeOSError(err)
+}
 
-	n, err := o.File.Write(b)
-	if err != nil && err.(*os.PathError).Err == os.ErrClosed {
-		return nil, errClosed
+// Checks the file is open and readable and gets it ready for reading
+func (o *File) startRead() error {
+	if o.closed {
+		return errClosed
 	}
-	return Int(n), err
+	if !o.Can(FileRead) {
+		return ExceptionNewf(UnsupportedOperation, "not readable")
+	}
+	o.eof = false
+	return o.flushWrites()
 }
 
-func (o *File) readResult(b []byte) (Object, error) {
-	if o.Can(FileBinary) {
-		if b != nil {
-			return Bytes(b), nil
+// Checks the file is open and writable and gets it ready for writing
+func (o *File) startWrite() error {
+	if o.closed {
+		return errClosed
+	}
+	if !o.Can(FileWrite) {
+		return ExceptionNewf(UnsupportedOperation, "not writable")
+	}
+	if len(o.rbuf) > 0 {
+		// Put the position back to the first byte not read
+		_, _ = o.File.Seek(-int64(len(o.rbuf)), io.SeekCurrent)
+		o.rbuf = nil
+	}
+	return nil
+}
+
+// Writes out the write buffer
+func (o *File) flushWrites() error {
+	if len(o.wbuf) == 0 {
+		return nil
+	}
+	_, err := o.File.Write(o.wbuf)
+	o.wbuf = o.wbuf[:0]
+	unflushedMu.Lock()
+	delete(unflushed, o)
+	unflushedMu.Unlock()
+	if err != nil {
+		return osError(err)
+	}
+	return nil
+}
+
+// Reads more of the os.File into the read buffer, returning false at
+// the end of the file
+func (o *File) fill() (bool, error) {
+	if o.eof {
+		return false, nil
+	}
+	buf := make([]byte, DefaultBufferSize)
+	n, err := o.File.Read(buf)
+	o.rbuf = append(o.rbuf, buf[:n]...)
+	if err == io.EOF || (n == 0 && err == nil) {
+		o.eof = true
+		return n > 0, nil
+	}
+	if err != nil {
+		return n > 0, osError(err)
+	}
+	return true, nil
+}
+
+// Returns the next byte of the file without reading it, or false at
+// the end of the file
+func (o *File) peekByte() (byte, bool, error) {
+	if len(o.rbuf) == 0 {
+		ok, err := o.fill()
+		if !ok || err != nil {
+			return 0, false, err
 		}
+	}
+	return o.rbuf[0], true, nil
+}
 
-		return Bytes{}, nil
+// Reads the next character of a text file translating newlines if
+// newline is None, returning false at the end of the file
+func (o *File) readChar() (rune, bool, error) {
+	for {
+		if len(o.rbuf) == 0 || (o.encoding == "" || o.encoding == "utf-8") && !utf8.FullRune(o.rbuf) {
+			ok, err := o.fill()
+			if err != nil {
+				return 0, false, err
+			}
+			if !ok && len(o.rbuf) == 0 {
+				return 0, false, nil
+			}
+		}
+		r, size, err := decodeRune(o.rbuf, o.encoding, o.errorsHandler())
+		o.rbuf = o.rbuf[size:]
+		if err != nil {
+			return 0, false, err
+		}
+		if r < 0 {
+			// ignored by the error handler
+			continue
+		}
+		if r == '\r' && (o.newline == nil || o.newline == None) {
+			b, ok, err := o.peekByte()
+			if err != nil {
+				return 0, false, err
+			}
+			if ok && b == '\n' {
+				o.rbuf = o.rbuf[1:]
+			}
+			r = '\n'
+		}
+		return r, true, nil
 	}
+}
 
-	if b != nil {
-		return String(b), nil
+// Returns the optional size argument of read methods
+func sizeArg(name string, args Tuple) (int, error) {
+	var arg Object = None
+	err := UnpackTuple(args, nil, name, 0, 1, &arg)
+	if err != nil {
+		return 0, err
+	}
+	if arg == None {
+		return -1, nil
+	}
+	size, err := IndexInt(arg)
+	if err != nil {
+		return 0, ExceptionNewf(TypeError, "%s() argument 1 must be int, not %s", name, arg.Type().Name)
 	}
+	return size, nil
+}
 
-	return String(""), nil
+func (o *File) Write(value Object) (Object, error) {
+	var b []byte
+	var n int
+	if o.Can(FileBinary) {
+		v, ok := convertToBytes(value)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "a bytes-like object is required, not '%s'", value.Type().Name)
+		}
+		b, n = v, len(v)
+	} else {
+		v, ok := value.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "write() argument must be str, not %s", value.Type().Name)
+		}
+		s := string(v)
+		n = utf8.RuneCountInString(s)
+		if newline, ok := o.newline.(String); ok && newline != "" && newline != "\n" {
+			s = strings.Replace(s, "\n", string(newline), -1)
+		}
+		var err error
+		b, err = encodeString(s, o.encoding, o.errorsHandler())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := o.startWrite(); err != nil {
+		return nil, err
+	}
+	o.wbuf = append(o.wbuf, b...)
+	if len(o.wbuf) >= o.bufSize || o.lineBuffering && bytes.ContainsAny(b, "\n\r") {
+		if err := o.flushWrites(); err != nil {
+			return nil, err
+		}
+	} else if len(o.wbuf) > 0 {
+		unflushedMu.Lock()
+		unflushed[o] = struct{}{}
+		unflushedMu.Unlock()
+	}
+	return Int(n), nil
 }
 
 func (o *File) Read(args Tuple, kwargs StringDict) (Object, error) {
@@ -105,179 +505,518 @@ func (o *File) Read(args Tuple, kwargs StringDict) (Object, error) {
 		return nil, err
 	}
 
-	var r io.Reader = o.File
+	n := -1
+	if arg != None {
+		n, err = IndexInt(arg)
+		if err != nil {
+			return nil, ExceptionNewf(TypeError, "read() argument 1 must be int, not %s", arg.Type().Name)
+		}
+	}
+
+	if err := o.startRead(); err != nil {
+		return nil, err
+	}
 
-	switch pyN, ok := arg.(Int); {
-	case arg == None:
-		// read all
+	if o.Can(FileBinary) {
+		if n < 0 {
+			rest, err := ioutil.ReadAll(o.File)
+			if err != nil {
+				return nil, osError(err)
+			}
+			b := append(o.rbuf, rest...)
+			o.rbuf = nil
+			return Bytes(b), nil
+		}
+		for len(o.rbuf) < n {
+			ok, err := o.fill()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+		}
+		if n > len(o.rbuf) {
+			n = len(o.rbuf)
+		}
+		b := append(Bytes{}, o.rbuf[:n]...)
+		o.rbuf = o.rbuf[n:]
+		return b, nil
+	}
 
-	case ok:
-		// number of bytes to read
-		// 0: read nothing
-		// < 0: read all
-		// > 0: read n
-		n, _ := pyN.GoInt64()
-		if n == 0 {
-			return o.readResult(nil)
+	var buf strings.Builder
+	for i := 0; n < 0 || i < n; i++ {
+		r, ok, err := o.readChar()
+		if err != nil {
+			return nil, err
 		}
-		if n > 0 {
-			r = io.LimitReader(r, n)
+		if !ok {
+			break
 		}
+		buf.WriteRune(r)
+	}
+	return String(buf.String()), nil
+}
 
-	default:
-		// invalid type
-		return nil, ExceptionNewf(TypeError, "read() argument 1 must be int, not %s", arg.Type().Name)
+// ReadLine reads a line from the file, keeping its line ending.  If
+// size isn't negative at most size bytes or characters are read.  At
+// the end of the file an empty string is returned.
+func (o *File) ReadLine(size int) (Object, error) {
+	if err := o.startRead(); err != nil {
+		return nil, err
 	}
 
-	b, err := ioutil.ReadAll(r)
-	if err != nil {
-		if err == io.EOF {
-			return o.readResult(nil)
+	if o.Can(FileBinary) {
+		var line []byte
+		for size < 0 || len(line) < size {
+			if len(o.rbuf) == 0 {
+				ok, err := o.fill()
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					break
+				}
+			}
+			n := len(o.rbuf)
+			if i := bytes.IndexByte(o.rbuf, '\n'); i >= 0 {
+				n = i + 1
+			}
+			if size >= 0 && len(line)+n > size {
+				n = size - len(line)
+			}
+			line = append(line, o.rbuf[:n]...)
+			o.rbuf = o.rbuf[n:]
+			if line[len(line)-1] == '\n' {
+				break
+			}
 		}
-		if perr, ok := err.(*os.PathError); ok && perr.Err == os.ErrClosed {
-			return nil, errClosed
+		return Bytes(line), nil
+	}
+
+	var buf strings.Builder
+	newline, _ := o.newline.(String)
+	for i := 0; size < 0 || i < size; i++ {
+		r, ok, err := o.readChar()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		buf.WriteRune(r)
+		if o.newline == nil || o.newline == None || newline == "\n" {
+			if r == '\n' {
+				break
+			}
+			continue
+		}
+		if r == '\n' && newline == "" {
+			break
 		}
+		if r == '\r' && newline == "\r" {
+			break
+		}
+		if r == '\r' && (newline == "" || newline == "\r\n") {
+			b, ok, err := o.peekByte()
+			if err != nil {
+				return nil, err
+			}
+			if ok && b == '\n' {
+				o.rbuf = o.rbuf[1:]
+				buf.WriteByte('\n')
+				break
+			}
+			if newline == "" {
+				break
+			}
+		}
+	}
+	return String(buf.String()), nil
+}
 
+// ReadLines returns a list of the lines read with the readline method
+// of self until the end of the file or until they total more than
+// hint bytes or characters if hint is positive
+func ReadLines(self Object, hint int) (Object, error) {
+	lines := NewList()
+	total := 0
+	for {
+		line, err := Next(self)
+		if err != nil {
+			if IsException(StopIteration, err) {
+				break
+			}
+			return nil, err
+		}
+		lines.Append(line)
+		switch line := line.(type) {
+		case Bytes:
+			total += len(line)
+		case String:
+			total += line.len()
+		}
+		if hint > 0 && total >= hint {
+			break
+		}
+	}
+	return lines, nil
+}
+
+// WriteLines writes each of the lines to self with its write method
+func WriteLines(self Object, lines Object) (Object, error) {
+	write, err := GetAttrString(self, "write")
+	if err != nil {
 		return nil, err
 	}
+	var loopErr error
+	err = Iterate(lines, func(line Object) bool {
+		_, loopErr = Call(write, Tuple{line}, nil)
+		return loopErr != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if loopErr != nil {
+		return nil, loopErr
+	}
+	return None, nil
+}
 
-	return o.readResult(b)
+// M_seek moves the position of the file to offset bytes from the
+// start of the file, the current position or the end of the file as
+// whence is 0, 1 or 2, returning the new position.  It isn't called
+// Seek as it doesn't have the signature of io.Seeker.
+func (o *File) M_seek(offset int64, whence int) (Object, error) {
+	if o.closed {
+		return nil, errClosed
+	}
+	if whence < io.SeekStart || whence > io.SeekEnd {
+		return nil, ExceptionNewf(ValueError, "invalid whence (%d, should be 0, 1 or 2)", whence)
+	}
+	if !o.Can(FileBinary) && whence != io.SeekStart && offset != 0 {
+		return nil, ExceptionNewf(UnsupportedOperation, "can't do nonzero cur-relative seeks")
+	}
+	if err := o.flushWrites(); err != nil {
+		return nil, err
+	}
+	if whence == io.SeekCurrent {
+		offset -= int64(len(o.rbuf))
+	}
+	pos, err := o.File.Seek(offset, whence)
+	if err != nil {
+		return nil, osError(err)
+	}
+	o.rbuf = nil
+	return Int(pos), nil
+}
+
+// Tell returns the position of the file
+func (o *File) Tell() (Object, error) {
+	if o.closed {
+		return nil, errClosed
+	}
+	if err := o.flushWrites(); err != nil {
+		return nil, err
+	}
+	pos, err := o.File.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, osError(err)
+	}
+	return Int(pos - int64(len(o.rbuf))), nil
+}
+
+// Truncate cuts the file down to size bytes, or to the current
+// position if size is None, returning the new size
+func (o *File) Truncate(size Object) (Object, error) {
+	if err := o.startWrite(); err != nil {
+		return nil, err
+	}
+	if err := o.flushWrites(); err != nil {
+		return nil, err
+	}
+	if size == None {
+		pos, err := o.Tell()
+		if err != nil {
+			return nil, err
+		}
+		size = pos
+	}
+	n, err := IndexInt(size)
+	if err != nil {
+		return nil, err
+	}
+	if err = o.File.Truncate(int64(n)); err != nil {
+		return nil, osError(err)
+	}
+	return Int(n), nil
 }
 
 func (o *File) Close() (Object, error) {
-	_ = o.File.Close()
+	if o.closed {
+		return None, nil
+	}
+	err := o.flushWrites()
+	o.closed = true
+	if !o.keepFd {
+		_ = o.File.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
 	return None, nil
 }
 
 func (o *File) Flush() (Object, error) {
-	err := o.File.Sync()
-	if perr, ok := err.(*os.PathError); ok && perr.Err == os.ErrClosed {
+	if o.closed {
 		return nil, errClosed
 	}
-
+	if err := o.flushWrites(); err != nil {
+		return nil, err
+	}
 	return None, nil
 }
 
+func (o *File) M__iter__() (Object, error) {
+	if o.closed {
+		return nil, errClosed
+	}
+	return o, nil
+}
+
+func (o *File) M__next__() (Object, error) {
+	line, err := o.ReadLine(-1)
+	if err != nil {
+		return nil, err
+	}
+	switch line := line.(type) {
+	case Bytes:
+		if len(line) == 0 {
+			return nil, StopIteration
+		}
+	case String:
+		if line == "" {
+			return nil, StopIteration
+		}
+	}
+	return line, nil
+}
+
 func (o *File) M__enter__() (Object, error) {
+	if o.closed {
+		return nil, errClosed
+	}
 	return o, nil
 }
 
+// Closes the file leaving any exception to propagate
 func (o *File) M__exit__(exc_type, exc_value, traceback Object) (Object, error) {
-	return o.Close()
+	_, err := o.Close()
+	if err != nil {
+		return nil, err
+	}
+	return False, nil
 }
 
+// FlushFiles writes out the buffered writes of all the Files which
+// haven't been flushed, as is done when the interpreter exits.  Errors
+// are ignored as there is nowhere to raise them.
+func FlushFiles() {
+	unflushedMu.Lock()
+	files := make([]*File, 0, len(unflushed))
+	for o := range unflushed {
+		files = append(files, o)
+	}
+	unflushedMu.Unlock()
+	for _, o := range files {
+		_ = o.flushWrites()
+	}
+}
+
+// OpenFile opens the file called filename as open() does with the
+// default encoding, errors and newline
 func OpenFile(filename, mode string, buffering int) (Object, error) {
+	return Open(String(filename), mode, buffering, None, None, None, true)
+}
+
+// NewStdFile makes a text File for the standard stream f, such as
+// os.Stdout, called name.  f isn't closed when the File is.
+func NewStdFile(f *os.File, name string, mode FileMode) *File {
+	return &File{
+		File:     f,
+		FileMode: mode &^ FileBinary,
+		nameObj:  String(name),
+		keepFd:   true,
+	}
+}
+
+// Open is open(file, mode, buffering, encoding, errors, newline,
+// closefd).  file is the name of the file or a file descriptor and
+// encoding, errors and newline are strings or None.
+func Open(file Object, mode string, buffering int, encoding, errors, newline Object, closefd bool) (*File, error) {
 	var fileMode FileMode
-	var truncate bool
-	var exclusive bool
+	var creating, reading, writing, appending, updating bool
 
-	for _, m := range mode {
+	for i, m := range mode {
+		if strings.ContainsRune(mode[i+1:], m) {
+			return nil, ExceptionNewf(ValueError, "invalid mode: '%s'", mode)
+		}
 		switch m {
+		case 'x':
+			creating = true
 		case 'r':
-			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
-			}
-			fileMode |= FileRead
-
+			reading = true
 		case 'w':
-			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
-			}
-			fileMode |= FileWrite
-			truncate = true
-
-		case 'x':
-			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
-			}
-			fileMode |= FileWrite
-			exclusive = true
-
+			writing = true
 		case 'a':
-			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
-			}
-			fileMode |= FileWrite
-			truncate = false
-
+			appending = true
 		case '+':
-			if fileMode&FileReadWrite == 0 {
-				return nil, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
-			}
-
-			truncate = (fileMode & FileWrite) != 0
-			fileMode |= FileReadWrite
-
-		case 'b':
-			if fileMode&FileReadWrite == 0 {
-				return nil, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
-			}
-
-			if fileMode&FileText != 0 {
-				return nil, ExceptionNewf(ValueError, "can't have text and binary mode at once")
-			}
-
-			fileMode |= FileBinary
-
+			updating = true
 		case 't':
-			if fileMode&FileReadWrite == 0 {
-				return nil, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
-			}
-
-			if fileMode&FileBinary != 0 {
-				return nil, ExceptionNewf(ValueError, "can't have text and binary mode at once")
-			}
-
 			fileMode |= FileText
+		case 'b':
+			fileMode |= FileBinary
+		default:
+			return nil, ExceptionNewf(ValueError, "invalid mode: '%s'", mode)
 		}
 	}
-
-	var fmode int
-
-	switch fileMode & FileReadWrite {
-	case FileReadWrite:
-		fmode = os.O_RDWR
-
-	case FileRead:
-		fmode = os.O_RDONLY
-
-	case FileWrite:
-		fmode = os.O_WRONLY
+	if fileMode&FileText != 0 && fileMode&FileBinary != 0 {
+		return nil, ExceptionNewf(ValueError, "can't have text and binary mode at once")
 	}
-
-	if exclusive {
-		fmode |= os.O_EXCL
+	n := 0
+	for _, set := range []bool{creating, reading, writing, appending} {
+		if set {
+			n++
+		}
 	}
-
-	if truncate {
-		fmode |= os.O_CREATE | os.O_TRUNC
-	} else {
-		fmode |= os.O_APPEND
+	if n != 1 {
+		return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
+	}
+	binary := fileMode&FileBinary != 0
+	if binary && encoding != None {
+		return nil, ExceptionNewf(ValueError, "binary mode doesn't take an encoding argument")
+	}
+	if binary && errors != None {
+		return nil, ExceptionNewf(ValueError, "binary mode doesn't take an errors argument")
+	}
+	if binary && newline != None {
+		return nil, ExceptionNewf(ValueError, "binary mode doesn't take a newline argument")
+	}
+	if !binary && buffering == 0 {
+		return nil, ExceptionNewf(ValueError, "can't have unbuffered text I/O")
 	}
 
-	f, err := os.OpenFile(filename, fmode, 0666)
-	if err != nil {
-		switch {
-		case os.IsExist(err):
-			return nil, ExceptionNewf(FileExistsError, err.Error())
+	var flags int
+	switch {
+	case reading:
+		fileMode |= FileRead
+		flags = os.O_RDONLY
+	case writing:
+		fileMode |= FileWrite
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	case creating:
+		fileMode |= FileWrite
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	case appending:
+		fileMode |= FileWrite
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	if updating {
+		fileMode |= FileReadWrite
+		flags = flags&^(os.O_RDONLY|os.O_WRONLY) | os.O_RDWR
+	}
 
-		case os.IsNotExist(err):
-			return nil, ExceptionNewf(FileNotFoundError, err.Error())
+	o := &File{
+		FileMode: fileMode,
+		nameObj:  file,
+		mode:     mode,
+		newline:  newline,
+		keepFd:   !closefd,
+	}
+	if encoding != None {
+		s, ok := encoding.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "open() argument 'encoding' must be str or None, not %s", encoding.Type().Name)
+		}
+		var err error
+		o.encoding, err = normalizeEncoding(string(s))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if errors != None {
+		s, ok := errors.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "open() argument 'errors' must be str or None, not %s", errors.Type().Name)
 		}
+		if err := checkErrors(string(s)); err != nil {
+			return nil, err
+		}
+		o.errors = string(s)
+	}
+	if newline != None {
+		s, ok := newline.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "open() argument 'newline' must be str or None, not %s", newline.Type().Name)
+		}
+		switch s {
+		case "", "\n", "\r", "\r\n":
+		default:
+			return nil, ExceptionNewf(ValueError, "illegal newline value: %s", s)
+		}
+	}
 
-		return nil, ExceptionNewf(OSError, err.Error())
+	if err := Audit("open", file, String(mode), Int(flags)); err != nil {
+		return nil, err
+	}
+	switch fd := file.(type) {
+	case String:
+		if !closefd {
+			return nil, ExceptionNewf(ValueError, "Cannot use closefd=False with file name")
+		}
+		f, err := os.OpenFile(string(fd), flags, 0666)
+		if err != nil {
+			return nil, osError(err)
+		}
+		o.File = f
+	case Int:
+		if fd < 0 {
+			return nil, ExceptionNewf(ValueError, "negative file descriptor")
+		}
+		o.File = os.NewFile(uintptr(fd), fmt.Sprint(fd))
+		if _, err := o.File.Stat(); err != nil {
+			return nil, osError(err)
+		}
+	default:
+		return nil, ExceptionNewf(TypeError, "expected str, bytes or os.PathLike object, not %s", file.Type().Name)
 	}
 
-	if finfo, err := f.Stat(); err == nil {
+	if finfo, err := o.File.Stat(); err == nil {
 		if finfo.IsDir() {
-			f.Close()
-			return nil, ExceptionNewf(IsADirectoryError, "Is a directory: '%s'", filename)
+			o.File.Close()
+			return nil, MakeOSError(&os.PathError{Op: "open", Path: fmt.Sprint(o.name()), Err: syscall.EISDIR})
+		}
+		if !binary && buffering < 0 && finfo.Mode()&os.ModeCharDevice != 0 {
+			buffering = 1
 		}
 	}
 
-	return &File{f, fileMode}, nil
+	switch {
+	case buffering == 0:
+		o.raw = true
+	case buffering == 1 && !binary:
+		o.lineBuffering = true
+		o.bufSize = DefaultBufferSize
+	case buffering > 1:
+		o.bufSize = buffering
+	default:
+		o.bufSize = DefaultBufferSize
+	}
+	return o, nil
 }
 
 // Check interface is satisfied
 var _ I__enter__ = (*File)(nil)
 var _ I__exit__ = (*File)(nil)
+var _ I__iter__ = (*File)(nil)
+var _ I__next__ = (*File)(nil)
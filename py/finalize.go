@@ -0,0 +1,264 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Finalization
+//
+// Python objects are reclaimed by Go's garbage collector, which has
+// no reference counts, so an object isn't finalized as soon as the
+// last reference to it goes but some time after, when the collector
+// next finds it unreachable.
+//
+// Objects which need to know when that happens, instances of classes
+// with a __del__ method and objects which are weakly referenced, are
+// given a Go finalizer with AddFinalizer.  Go's finalizers run in a
+// goroutine of their own without the GIL so they can't run python
+// code.  Instead they queue it with QueueFinalization and the main
+// thread runs it before its next instruction.  GarbageCollect, which
+// is gc.collect, runs the collector and waits for the finalizers so
+// the __del__ methods and weakref callbacks of the objects which have
+// become unreachable have been called when it returns.
+//
+// __del__ is called once at most and may resurrect the object.  The
+// weakref callbacks are called the next time the object is found
+// unreachable after its __del__ method has been called, and not at
+// all if it has been resurrected.
+//
+// As with all Go finalizers, an object with a finalizer which is in
+// a reference cycle, for instance through its __dict__ or a closure,
+// is never collected so it is never finalized.  Cycles must be broken
+// by hand, or one of the references made weak, for it to be.  Objects
+// still alive when the interpreter exits aren't finalized.
+
+package py
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	finalizeMu    sync.Mutex
+	finalizers    = map[uintptr][]func(Object) bool{} // indexed by the address of the object
+	finalizations []func()                            // the python code queued by finalizers
+	scheduled     bool                                // set if the main thread has been asked to run them
+	enabled       = true                              // cleared by EnableFinalization(false)
+)
+
+// AddFinalizer arranges for fn to be called with obj when Go's garbage
+// collector finds that obj is unreachable.  obj must be a pointer.
+//
+// fn is called by the goroutine which runs Go's finalizers without the
+// GIL, so it mustn't run python code but may queue it with
+// QueueFinalization.  If it returns true it is called again the next
+// time obj is found unreachable, which it should do if obj may have
+// been resurrected since.
+//
+// When an object has more than one finalizer each is called when it is
+// found unreachable after the one before has finished, in the order
+// they were added, so the ones after the first only run if the first
+// hasn't resurrected the object.
+func AddFinalizer(obj Object, fn func(Object) bool) {
+	addr := reflect.ValueOf(obj).Pointer()
+	finalizeMu.Lock()
+	defer finalizeMu.Unlock()
+	fns := finalizers[addr]
+	finalizers[addr] = append(fns, fn)
+	if len(fns) == 0 {
+		runtime.SetFinalizer(obj, runFinalizer)
+	}
+}
+
+// Called by Go's garbage collector when obj is unreachable
+func runFinalizer(obj interface{}) {
+	addr := reflect.ValueOf(obj).Pointer()
+	finalizeMu.Lock()
+	fns := finalizers[addr]
+	finalizeMu.Unlock()
+	if len(fns) == 0 {
+		return
+	}
+	again := fns[0](obj.(Object))
+	finalizeMu.Lock()
+	defer finalizeMu.Unlock()
+	// Finalizers may have been added while the lock was released
+	fns = finalizers[addr]
+	if !again {
+		fns = fns[1:]
+	}
+	if len(fns) == 0 {
+		delete(finalizers, addr)
+		return
+	}
+	finalizers[addr] = fns
+	runtime.SetFinalizer(obj, runFinalizer)
+}
+
+// QueueFinalization arranges for fn to be called by the main thread
+// with the GIL held, or by RunFinalizations, whichever happens first.
+//
+// It may be called from any goroutine, with or without the GIL.
+func QueueFinalization(fn func()) {
+	finalizeMu.Lock()
+	finalizations = append(finalizations, fn)
+	schedule := enabled && !scheduled
+	if schedule {
+		scheduled = true
+	}
+	finalizeMu.Unlock()
+	if schedule {
+		scheduleFinalizations()
+	}
+}
+
+// Asks the main thread to run the queued finalizations
+func scheduleFinalizations() {
+	if VmAddPendingCall == nil {
+		return
+	}
+	VmAddPendingCall(func() error {
+		RunFinalizations()
+		return nil
+	})
+}
+
+// RunFinalizations runs the queued finalizations, including any they
+// queue, returning how many were run
+//
+// It must be called with the GIL held.
+func RunFinalizations() int {
+	n := 0
+	for {
+		finalizeMu.Lock()
+		scheduled = false
+		fns := finalizations
+		finalizations = nil
+		finalizeMu.Unlock()
+		if len(fns) == 0 {
+			return n
+		}
+		for _, fn := range fns {
+			fn()
+		}
+		n += len(fns)
+	}
+}
+
+// The most times GarbageCollect runs the collector
+const maxCollections = 16
+
+// GarbageCollect runs Go's garbage collector and the finalizations of
+// the objects it finds unreachable, repeating until there are none
+// left to do, returning how many were run
+//
+// It must be called with the GIL held.
+func GarbageCollect() int {
+	n := 0
+	for i := 0; i < maxCollections; i++ {
+		collectAndWait()
+		done := RunFinalizations()
+		n += done
+		// Run the collector twice at least as objects found
+		// unreachable the first time may still have their
+		// finalizers called again, eg if they have been
+		// resurrected
+		if done == 0 && i > 0 {
+			break
+		}
+	}
+	return n
+}
+
+// Runs Go's garbage collector and waits for the finalizers of the
+// objects it found unreachable to be called
+//
+// The finalizers are called one batch at a time, so once those of a
+// collection after this one have started this one's have finished.
+func collectAndWait() {
+	for i := 0; i < 2; i++ {
+		done := make(chan struct{})
+		addSentinel(done)
+		runtime.GC()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			// A finalizer is blocked so give up waiting
+			return
+		}
+	}
+}
+
+// An object whose finalizer signals when the finalizers have got to it
+type sentinel struct {
+	done chan struct{}
+}
+
+// Makes an unreachable sentinel which closes done when it is finalized
+//
+//go:noinline
+func addSentinel(done chan struct{}) {
+	s := &sentinel{done: done}
+	runtime.SetFinalizer(s, func(s *sentinel) {
+		close(s.done)
+	})
+}
+
+// EnableFinalization sets whether the main thread runs the queued
+// finalizations, as gc.enable and gc.disable do.  While it doesn't
+// they are left for GarbageCollect or RunFinalizations to run.
+func EnableFinalization(enable bool) {
+	finalizeMu.Lock()
+	enabled = enable
+	schedule := enabled && !scheduled && len(finalizations) > 0
+	if schedule {
+		scheduled = true
+	}
+	finalizeMu.Unlock()
+	if schedule {
+		scheduleFinalizations()
+	}
+}
+
+// FinalizationEnabled returns whether the main thread runs the queued
+// finalizations
+func FinalizationEnabled() bool {
+	finalizeMu.Lock()
+	defer finalizeMu.Unlock()
+	return enabled
+}
+
+// Gives instances of classes with a __del__ method a finalizer which
+// calls it
+func addDelFinalizer(obj *Type) {
+	// The finalizer mustn't refer to obj or it would never be
+	// unreachable
+	AddFinalizer(obj, func(obj Object) bool {
+		QueueFinalization(func() {
+			fn := typeSpecial(obj, "__del__")
+			if fn == nil {
+				return
+			}
+			_, err := Call(fn, Tuple{obj}, nil)
+			if err != nil {
+				WriteUnraisable(err, fn)
+			}
+		})
+		return false
+	})
+}
+
+// WriteUnraisable reports an error raised where there is no python
+// code to raise it in, such as a __del__ method or weakref callback,
+// with the object which was being called
+func WriteUnraisable(err error, obj Object) {
+	repr, reprErr := ReprAsString(obj)
+	if reprErr != nil {
+		repr = fmt.Sprintf("<%s object>", obj.Type().Name)
+	}
+	fmt.Fprintf(os.Stderr, "Exception ignored in: %s\n", repr)
+	TracebackDump(err)
+}
@@ -38,9 +38,10 @@ func FloatNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	}
 	// Special case converting string types
 	switch x := xObj.(type) {
-	// FIXME Bytearray
 	case Bytes:
 		return FloatFromString(string(x))
+	case *ByteArray:
+		return FloatFromString(string(x.Data))
 	case String:
 		return FloatFromString(string(x))
 	}
@@ -309,6 +310,10 @@ func (a Float) M__bool__() (Object, error) {
 	return NewBool(a != 0), nil
 }
 
+func (a Float) M__hash__() (Object, error) {
+	return Int(hashFloat64(float64(a))), nil
+}
+
 func (a Float) M__int__() (Object, error) {
 	if a >= IntMin && a <= IntMax {
 		return Int(a), nil
@@ -338,16 +343,23 @@ func (a Float) M__complex__() (Object, error) {
 }
 
 func (a Float) M__round__(digitsObj Object) (Object, error) {
-	digits := 0
-	if digitsObj != None {
-		var err error
-		digits, err = MakeGoInt(digitsObj)
-		if err != nil {
-			return nil, err
-		}
+	if digitsObj == None {
+		// Round half to even returning an int
+		return Float(math.RoundToEven(float64(a))).M__int__()
+	}
+	digits, err := MakeGoInt(digitsObj)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsInf(float64(a), 0) || math.IsNaN(float64(a)) {
+		return a, nil
+	}
+	if digits >= 0 {
+		scale := math.Pow(10, float64(digits))
+		return Float(math.RoundToEven(float64(a)*scale) / scale), nil
 	}
-	scale := Float(math.Pow(10, float64(digits)))
-	return scale * Float(math.Floor(float64(a)/float64(scale))), nil
+	scale := math.Pow(10, float64(-digits))
+	return Float(math.RoundToEven(float64(a)/scale) * scale), nil
 }
 
 // Rich comparison
@@ -0,0 +1,723 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Format specification mini-language as used by format() and f-strings
+
+package py
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Calls __format__ on the object with formatSpec
+//
+// Objects without __format__ are converted with str() and may only
+// be formatted with an empty format spec.
+func Format(self Object, formatSpec Object) (Object, error) {
+	if _, ok := formatSpec.(String); !ok {
+		return nil, ExceptionNewf(TypeError, "format() argument 2 must be str, not %s", formatSpec.Type().Name)
+	}
+	var res Object
+	var err error
+	if I, ok := self.(I__format__); ok {
+		res, err = I.M__format__(formatSpec)
+	} else if r, ok, e := TypeCall1(self, "__format__", formatSpec); ok {
+		res, err = r, e
+	} else {
+		if formatSpec.(String) != "" {
+			return nil, ExceptionNewf(TypeError, "non-empty format string passed to object.__format__")
+		}
+		res, err = Str(self)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := res.(String); !ok {
+		return nil, ExceptionNewf(TypeError, "__format__ must return a str, not %s", res.Type().Name)
+	}
+	return res, nil
+}
+
+// A parsed format spec
+//
+// [[fill]align][sign][#][0][width][grouping][.precision][type]
+type formatSpec struct {
+	fill      rune
+	align     byte // one of '<', '>', '=', '^' or 0 for the default
+	sign      byte // one of '+', '-', ' ' or 0 for the default
+	alternate bool
+	width     int
+	grouping  byte // one of ',', '_' or 0 for none
+	precision int  // -1 if not set
+	typ       byte
+}
+
+// Returns true if c is a format spec alignment character
+func isAlign(c byte) bool {
+	return c == '<' || c == '>' || c == '=' || c == '^'
+}
+
+// Parses a format spec
+func parseFormatSpec(spec String) (*formatSpec, error) {
+	s := string(spec)
+	f := &formatSpec{fill: ' ', precision: -1}
+	i := 0
+	if r, size := utf8.DecodeRuneInString(s); size > 0 && size < len(s) && isAlign(s[size]) {
+		f.fill = r
+		f.align = s[size]
+		i = size + 1
+	} else if len(s) > 0 && isAlign(s[0]) {
+		f.align = s[0]
+		i = 1
+	}
+	if i < len(s) && (s[i] == '+' || s[i] == '-' || s[i] == ' ') {
+		f.sign = s[i]
+		i++
+	}
+	if i < len(s) && s[i] == '#' {
+		f.alternate = true
+		i++
+	}
+	if i < len(s) && s[i] == '0' {
+		if f.align == 0 {
+			f.fill = '0'
+			f.align = '='
+		}
+		i++
+	}
+	start := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i > start {
+		width, err := strconv.Atoi(s[start:i])
+		if err != nil {
+			return nil, ExceptionNewf(ValueError, "Too many decimal digits in format string")
+		}
+		f.width = width
+	}
+	if i < len(s) && (s[i] == ',' || s[i] == '_') {
+		f.grouping = s[i]
+		i++
+		if i < len(s) && (s[i] == ',' || s[i] == '_') {
+			return nil, ExceptionNewf(ValueError, "Cannot specify both ',' and '_'.")
+		}
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		start = i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return nil, ExceptionNewf(ValueError, "Format specifier missing precision")
+		}
+		precision, err := strconv.Atoi(s[start:i])
+		if err != nil {
+			return nil, ExceptionNewf(ValueError, "Too many decimal digits in format string")
+		}
+		f.precision = precision
+	}
+	if len(s)-i > 1 {
+		return nil, ExceptionNewf(ValueError, "Invalid format specifier")
+	}
+	if i < len(s) {
+		f.typ = s[i]
+	}
+	switch f.grouping {
+	case ',':
+		switch f.typ {
+		case 0, 'd', 'e', 'f', 'g', 'E', 'F', 'G', '%':
+		default:
+			return nil, ExceptionNewf(ValueError, "Cannot specify ',' with '%c'.", f.typ)
+		}
+	case '_':
+		switch f.typ {
+		case 0, 'd', 'e', 'f', 'g', 'E', 'F', 'G', '%', 'b', 'o', 'x', 'X':
+		default:
+			return nil, ExceptionNewf(ValueError, "Cannot specify '_' with '%c'.", f.typ)
+		}
+	}
+	return f, nil
+}
+
+// Returns an error for a format code which doesn't apply to the object
+func (f *formatSpec) unknownCode(obj Object) error {
+	return ExceptionNewf(ValueError, "Unknown format code '%c' for object of type '%s'", f.typ, obj.Type().Name)
+}
+
+// Pads s out to the width using the fill character and alignment
+// with defaultAlign used if no alignment was specified.
+//
+// For '=' alignment the padding goes after the prefix.
+func (f *formatSpec) pad(prefix, s string, defaultAlign byte) string {
+	n := f.width - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return prefix + s
+	}
+	align := f.align
+	if align == 0 {
+		align = defaultAlign
+	}
+	fill := string(f.fill)
+	switch align {
+	case '<':
+		return prefix + s + strings.Repeat(fill, n)
+	case '^':
+		left := n / 2
+		return strings.Repeat(fill, left) + prefix + s + strings.Repeat(fill, n-left)
+	case '=':
+		return prefix + strings.Repeat(fill, n) + s
+	}
+	return strings.Repeat(fill, n) + prefix + s
+}
+
+// Returns the sign prefix for a number
+func (f *formatSpec) signPrefix(negative bool) string {
+	switch {
+	case negative:
+		return "-"
+	case f.sign == '+':
+		return "+"
+	case f.sign == ' ':
+		return " "
+	}
+	return ""
+}
+
+// Inserts the grouping separator every 3 digits, or 4 for binary,
+// octal and hex, into the integer part of digits if required, zero
+// padding with grouping if the fill is '0'
+func (f *formatSpec) group(prefix, digits string) string {
+	if f.grouping == 0 {
+		return digits
+	}
+	size := 3
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	switch f.typ {
+	case 'b', 'o', 'x', 'X':
+		size = 4
+		isDigit = func(r rune) bool { return r < utf8.RuneSelf && strings.ContainsRune("0123456789abcdefABCDEF", r) }
+	}
+	end := strings.IndexFunc(digits, func(r rune) bool { return !isDigit(r) })
+	if end < 0 {
+		end = len(digits)
+	}
+	intPart, rest := digits[:end], digits[end:]
+	grouped := func() string {
+		var out strings.Builder
+		for i, c := range intPart {
+			if i > 0 && (len(intPart)-i)%size == 0 {
+				out.WriteByte(f.grouping)
+			}
+			out.WriteRune(c)
+		}
+		return out.String()
+	}
+	if f.align == '=' && f.fill == '0' {
+		for len(prefix)+len(grouped())+len(rest) < f.width {
+			intPart = "0" + intPart
+		}
+	}
+	return grouped() + rest
+}
+
+// Formats a number whose digits, without a sign, are given
+func (f *formatSpec) number(negative bool, basePrefix, digits string) String {
+	prefix := f.signPrefix(negative) + basePrefix
+	return String(f.pad(prefix, f.group(prefix, digits), '>'))
+}
+
+// FormatNumber formats a number according to the format spec for the
+// __format__ method of a numeric type implemented outside this
+// package.
+//
+// digits is called with the presentation type and the precision (-1
+// if not given) and should return the digits of the absolute value.
+// The sign, grouping and padding are then applied as for int and
+// float.
+func FormatNumber(spec String, negative bool, digits func(typ byte, precision int) (string, error)) (Object, error) {
+	f, err := parseFormatSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	s, err := digits(f.typ, f.precision)
+	if err != nil {
+		return nil, err
+	}
+	return f.number(negative, "", s), nil
+}
+
+// Formats a string according to the format spec
+func formatString(s String, spec String) (Object, error) {
+	f, err := parseFormatSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if f.typ != 0 && f.typ != 's' {
+		return nil, f.unknownCode(s)
+	}
+	if f.sign != 0 {
+		return nil, ExceptionNewf(ValueError, "Sign not allowed in string format specifier")
+	}
+	if f.alternate {
+		return nil, ExceptionNewf(ValueError, "Alternate form (#) not allowed in string format specifier")
+	}
+	if f.grouping != 0 {
+		return nil, ExceptionNewf(ValueError, "Cannot specify '%c' with 's'.", f.grouping)
+	}
+	if f.align == '=' {
+		return nil, ExceptionNewf(ValueError, "'=' alignment not allowed in string format specifier")
+	}
+	str := string(s)
+	if f.precision >= 0 && utf8.RuneCountInString(str) > f.precision {
+		str = string([]rune(str)[:f.precision])
+	}
+	return String(f.pad("", str, '<')), nil
+}
+
+// Formats an integer according to the format spec
+func formatInteger(obj Object, x *big.Int, spec String) (Object, error) {
+	f, err := parseFormatSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch f.typ {
+	case 'e', 'E', 'f', 'F', 'g', 'G', '%':
+		fx, _ := new(big.Float).SetInt(x).Float64()
+		return formatFloat(Float(fx), spec)
+	case 's':
+		return nil, f.unknownCode(obj)
+	}
+	if f.precision >= 0 {
+		return nil, ExceptionNewf(ValueError, "Precision not allowed in integer format specifier")
+	}
+	negative := x.Sign() < 0
+	abs := new(big.Int).Abs(x)
+	basePrefix := ""
+	var digits string
+	switch f.typ {
+	case 0, 'd', 'n':
+		digits = abs.Text(10)
+	case 'b':
+		digits = abs.Text(2)
+		basePrefix = "0b"
+	case 'o':
+		digits = abs.Text(8)
+		basePrefix = "0o"
+	case 'x':
+		digits = abs.Text(16)
+		basePrefix = "0x"
+	case 'X':
+		digits = strings.ToUpper(abs.Text(16))
+		basePrefix = "0X"
+	case 'c':
+		if f.sign != 0 {
+			return nil, ExceptionNewf(ValueError, "Sign not allowed with integer format specifier 'c'")
+		}
+		if !x.IsInt64() || x.Int64() < 0 || x.Int64() > 0x10ffff {
+			return nil, ExceptionNewf(OverflowError, "%%c arg not in range(0x110000)")
+		}
+		return String(f.pad("", string(rune(x.Int64())), '>')), nil
+	default:
+		return nil, f.unknownCode(obj)
+	}
+	if !f.alternate {
+		basePrefix = ""
+	}
+	return f.number(negative, basePrefix, digits), nil
+}
+
+// Formats a float according to the format spec
+func formatFloat(x Float, spec String) (Object, error) {
+	f, err := parseFormatSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	fx := float64(x)
+	negative := math.Signbit(fx) && !math.IsNaN(fx)
+	abs := math.Abs(fx)
+	precision := f.precision
+	if precision < 0 {
+		precision = 6
+	}
+	var digits string
+	suffix := ""
+	switch f.typ {
+	case 0:
+		if f.precision < 0 {
+			str, _ := Float(abs).M__str__()
+			digits = string(str.(String))
+			break
+		}
+		// Like 'g' but always with at least one digit after the
+		// decimal point, so switching to exponent notation one
+		// exponent sooner
+		digits = formatGeneral(abs, precision, 1, f.alternate)
+		if !strings.ContainsAny(digits, ".en") {
+			digits += ".0"
+		}
+	case 'e', 'E':
+		digits = strconv.FormatFloat(abs, 'e', precision, 64)
+	case 'f', 'F':
+		digits = strconv.FormatFloat(abs, 'f', precision, 64)
+	case 'g', 'G', 'n':
+		digits = formatG(abs, precision, f.alternate)
+	case '%':
+		digits = strconv.FormatFloat(abs*100, 'f', precision, 64)
+		suffix = "%"
+	default:
+		return nil, f.unknownCode(x)
+	}
+	switch {
+	case math.IsInf(fx, 0):
+		digits = "inf"
+	case math.IsNaN(fx):
+		digits = "nan"
+	case f.alternate && !strings.Contains(digits, "."):
+		// The alternate form always has a decimal point
+		if i := strings.IndexAny(digits, "eE"); i >= 0 {
+			digits = digits[:i] + "." + digits[i:]
+		} else {
+			digits += "."
+		}
+	}
+	if f.typ == 'E' || f.typ == 'F' || f.typ == 'G' {
+		digits = strings.ToUpper(digits)
+	}
+	return f.number(negative, "", digits+suffix), nil
+}
+
+// Formats a non-negative float in the 'g' style with the number of
+// significant digits given by precision, removing trailing zeros
+// unless alternate is set
+func formatG(x float64, precision int, alternate bool) string {
+	return formatGeneral(x, precision, 0, alternate)
+}
+
+// Formats x like formatG but using exponent notation for exponents of
+// precision-sooner and above rather than precision and above
+func formatGeneral(x float64, precision, sooner int, alternate bool) string {
+	if precision == 0 {
+		precision = 1
+	}
+	// Find the exponent after rounding to precision digits
+	e := strconv.FormatFloat(x, 'e', precision-1, 64)
+	exp, _ := strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+	var out string
+	if exp >= -4 && exp < precision-sooner {
+		out = strconv.FormatFloat(x, 'f', precision-1-exp, 64)
+	} else {
+		out = e
+	}
+	if alternate {
+		return out
+	}
+	// Remove trailing zeros from the mantissa
+	mantissa, exponent := out, ""
+	if i := strings.IndexByte(out, 'e'); i >= 0 {
+		mantissa, exponent = out[:i], out[i:]
+	}
+	if strings.Contains(mantissa, ".") {
+		mantissa = strings.TrimRight(mantissa, "0")
+		mantissa = strings.TrimSuffix(mantissa, ".")
+	}
+	return mantissa + exponent
+}
+
+// Returns the format spec as a String or an error
+func formatSpecString(formatSpec Object) (String, error) {
+	spec, ok := formatSpec.(String)
+	if !ok {
+		return "", ExceptionNewf(TypeError, "format spec must be str, not %s", formatSpec.Type().Name)
+	}
+	return spec, nil
+}
+
+func (a String) M__format__(formatSpec Object) (Object, error) {
+	spec, err := formatSpecString(formatSpec)
+	if err != nil {
+		return nil, err
+	}
+	return formatString(a, spec)
+}
+
+func (a Int) M__format__(formatSpec Object) (Object, error) {
+	spec, err := formatSpecString(formatSpec)
+	if err != nil {
+		return nil, err
+	}
+	return formatInteger(a, big.NewInt(int64(a)), spec)
+}
+
+func (a *BigInt) M__format__(formatSpec Object) (Object, error) {
+	spec, err := formatSpecString(formatSpec)
+	if err != nil {
+		return nil, err
+	}
+	return formatInteger(a, (*big.Int)(a), spec)
+}
+
+func (a Bool) M__format__(formatSpec Object) (Object, error) {
+	spec, err := formatSpecString(formatSpec)
+	if err != nil {
+		return nil, err
+	}
+	if spec == "" {
+		return a.M__str__()
+	}
+	var x int64
+	if a {
+		x = 1
+	}
+	return formatInteger(a, big.NewInt(x), spec)
+}
+
+func (a Float) M__format__(formatSpec Object) (Object, error) {
+	spec, err := formatSpecString(formatSpec)
+	if err != nil {
+		return nil, err
+	}
+	return formatFloat(a, spec)
+}
+
+// Check interface is satisfied
+var _ I__format__ = String("")
+var _ I__format__ = Int(0)
+var _ I__format__ = (*BigInt)(nil)
+var _ I__format__ = Bool(false)
+var _ I__format__ = Float(0)
+
+// Formats the replacement fields in a format string as used by
+// str.format and str.format_map
+type stringFormatter struct {
+	args      Tuple
+	getKw     func(key String) (Object, error)
+	autoIndex int  // the next automatically numbered field
+	auto      bool // set if automatic field numbering is in use
+	manual    bool // set if manual field numbering is in use
+}
+
+// Formats s which may contain replacement fields nested to depth
+func (f *stringFormatter) format(s string, depth int) (string, error) {
+	if depth <= 0 {
+		return "", ExceptionNewf(ValueError, "Max string recursion exceeded")
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '{' && i+1 < len(s) && s[i+1] == '{', c == '}' && i+1 < len(s) && s[i+1] == '}':
+			out.WriteByte(c)
+			i += 2
+		case c == '}':
+			return "", ExceptionNewf(ValueError, "Single '}' encountered in format string")
+		case c == '{':
+			// Find the matching close brace
+			nesting := 1
+			j := i + 1
+			for ; j < len(s); j++ {
+				if s[j] == '{' {
+					nesting++
+				} else if s[j] == '}' {
+					nesting--
+					if nesting == 0 {
+						break
+					}
+				}
+			}
+			if j >= len(s) {
+				if i+1 == len(s) {
+					return "", ExceptionNewf(ValueError, "Single '{' encountered in format string")
+				}
+				return "", ExceptionNewf(ValueError, "expected '}' before end of string")
+			}
+			field, err := f.field(s[i+1:j], depth)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(field)
+			i = j + 1
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String(), nil
+}
+
+// Formats the contents of a single replacement field
+//
+// field_name ["!" conversion] [":" format_spec]
+func (f *stringFormatter) field(field string, depth int) (string, error) {
+	// The field name ends at the first '!' or ':' outside []
+	end := 0
+	for end < len(field) && field[end] != '!' && field[end] != ':' {
+		if field[end] == '[' {
+			for end < len(field) && field[end] != ']' {
+				end++
+			}
+			if end == len(field) {
+				break
+			}
+		}
+		end++
+	}
+	name, rest := field[:end], field[end:]
+	var conversion byte
+	if strings.HasPrefix(rest, "!") {
+		if len(rest) < 2 {
+			return "", ExceptionNewf(ValueError, "end of string while looking for conversion specifier")
+		}
+		conversion = rest[1]
+		rest = rest[2:]
+		if rest != "" && rest[0] != ':' {
+			return "", ExceptionNewf(ValueError, "expected ':' after conversion specifier")
+		}
+	}
+	spec := strings.TrimPrefix(rest, ":")
+	obj, err := f.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	switch conversion {
+	case 0:
+	case 's':
+		obj, err = Str(obj)
+	case 'r':
+		obj, err = Repr(obj)
+	case 'a':
+		obj, err = Repr(obj)
+		if err == nil {
+			obj = String(StringEscape(obj.(String), true))
+		}
+	default:
+		return "", ExceptionNewf(ValueError, "Unknown conversion specifier %c", conversion)
+	}
+	if err != nil {
+		return "", err
+	}
+	// The format spec may itself contain replacement fields
+	if strings.ContainsAny(spec, "{}") {
+		spec, err = f.format(spec, depth-1)
+		if err != nil {
+			return "", err
+		}
+	}
+	res, err := Format(obj, String(spec))
+	if err != nil {
+		return "", err
+	}
+	return string(res.(String)), nil
+}
+
+// Looks up the object for a field name
+//
+// arg_name ("." attribute_name | "[" element_index "]")*
+func (f *stringFormatter) lookup(name string) (Object, error) {
+	end := strings.IndexAny(name, ".[")
+	if end < 0 {
+		end = len(name)
+	}
+	first, rest := name[:end], name[end:]
+	var obj Object
+	if first == "" || isDecimalString(first) {
+		var index int
+		if first == "" {
+			if f.manual {
+				return nil, ExceptionNewf(ValueError, "cannot switch from manual field specification to automatic field numbering")
+			}
+			f.auto = true
+			index = f.autoIndex
+			f.autoIndex++
+		} else {
+			if f.auto {
+				return nil, ExceptionNewf(ValueError, "cannot switch from automatic field numbering to manual field specification")
+			}
+			f.manual = true
+			var err error
+			index, err = strconv.Atoi(first)
+			if err != nil {
+				return nil, ExceptionNewf(ValueError, "Too many decimal digits in format string")
+			}
+		}
+		if index >= len(f.args) {
+			return nil, ExceptionNewf(IndexError, "Replacement index %d out of range for positional args tuple", index)
+		}
+		obj = f.args[index]
+	} else {
+		var err error
+		obj, err = f.getKw(String(first))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for rest != "" {
+		var err error
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			attr := rest[1:end]
+			if attr == "" {
+				return nil, ExceptionNewf(ValueError, "Empty attribute in format string")
+			}
+			obj, err = GetAttrString(obj, attr)
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, ExceptionNewf(ValueError, "Missing ']' in format string")
+			}
+			key := rest[1:end]
+			if key == "" {
+				return nil, ExceptionNewf(ValueError, "Empty attribute in format string")
+			}
+			if isDecimalString(key) {
+				i, _ := strconv.Atoi(key)
+				obj, err = GetItem(obj, Int(i))
+			} else {
+				obj, err = GetItem(obj, String(key))
+			}
+			rest = rest[end+1:]
+			if rest != "" && rest[0] != '.' && rest[0] != '[' {
+				return nil, ExceptionNewf(ValueError, "Only '.' or '[' may follow ']' in format field specifier")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// Returns true if s is a non empty string of ASCII digits
+func isDecimalString(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Formats s with the positional args and the named arguments looked
+// up with getKw as str.format does
+func StringFormat(s String, args Tuple, getKw func(key String) (Object, error)) (String, error) {
+	f := &stringFormatter{args: args, getKw: getKw}
+	out, err := f.format(string(s), 2)
+	if err != nil {
+		return "", err
+	}
+	return String(out), nil
+}
@@ -6,6 +6,8 @@
 
 package py
 
+import "fmt"
+
 // What kind of block this is
 type TryBlockType byte
 
@@ -25,11 +27,12 @@ type TryBlock struct {
 
 // A python Frame object
 type Frame struct {
-	// Back       *Frame        // previous frame, or nil
+	Back            *Frame     // previous frame, or nil
 	Code            *Code      // code segment
 	Builtins        StringDict // builtin symbol table
 	Globals         StringDict // global symbol table
 	Locals          StringDict // local symbol table
+	LocalsMapping   Object     // local symbol table if it is a mapping other than a dict, or nil
 	Stack           []Object   // Valuestack
 	LocalVars       Tuple      // Fast access local vars
 	CellAndFreeVars Tuple      // Cellvars then Freevars Cell objects in one Tuple
@@ -38,8 +41,8 @@ type Frame struct {
 	// Frame evaluation usually NULLs it, but a frame that yields sets it
 	// to the current stack top.
 	// Stacktop *Object
-	Yielded bool // set if the function yielded, cleared otherwise
-	// Trace   Object // Trace function
+	Yielded bool   // set if the function yielded, cleared otherwise
+	Trace   Object // Trace function, or nil
 
 	// In a generator, we need to be able to swap between the exception
 	// state inside the generator and the exception state of the calling
@@ -54,6 +57,10 @@ type Frame struct {
 	// Borrowed reference to a generator, or NULL
 	// Gen Object
 
+	// The exception being handled by an except clause in this
+	// frame, maintained by the vm
+	Exc *ExceptionInfo
+
 	// FIXME Tstate *PyThreadState
 	Lasti int32 // Last instruction if called
 	// Call PyFrame_GetLineNumber() instead of reading this field
@@ -61,7 +68,7 @@ type Frame struct {
 	// active (i.e. when f_trace is set).  At other times we use
 	// PyCode_Addr2Line to calculate the line from the current
 	// bytecode index.
-	// Lineno     int        // Current line number
+	Lineno int32 // Current line number
 	// Iblock     int        // index in f_blockstack
 	// Executing  byte       // whether the frame is still executing
 	Blockstack []TryBlock // for try and loop blocks
@@ -71,11 +78,31 @@ type Frame struct {
 
 var FrameType = NewType("frame", "Represents a stack frame")
 
+// Interpreter state used for tracing and introspection
+var (
+	// The currently executing frame, maintained by the vm
+	CurrentFrame *Frame
+
+	// The global trace function set by sys.settrace, or nil
+	TraceFunc Object
+
+	// The global profile function set by sys.setprofile, or nil
+	ProfileFunc Object
+)
+
 // Type of this object
 func (o *Frame) Type() *Type {
 	return FrameType
 }
 
+func (f *Frame) M__repr__() (Object, error) {
+	filename, err := ReprAsString(String(f.Code.Filename))
+	if err != nil {
+		return nil, err
+	}
+	return String(fmt.Sprintf("<frame at %p, file %s, line %d, code %s>", f, filename, f.LineNumber(), f.Code.Name)), nil
+}
+
 // Make a new frame for a code object
 func NewFrame(globals, locals StringDict, code *Code, closure Tuple) *Frame {
 	nlocals := int(code.Nlocals)
@@ -83,7 +110,7 @@ func NewFrame(globals, locals StringDict, code *Code, closure Tuple) *Frame {
 	nfrees := len(code.Freevars)
 	varsize := nlocals + ncells + nfrees
 	// Allocate the stack, locals, cells and frees in a contigious block of memory
-	allocation := make([]Object, varsize)
+	allocation := make([]Object, varsize+int(code.Stacksize))
 	localVars := allocation[:nlocals]
 	//cellVars := allocation[nlocals : nlocals+ncells]
 	//freeVars := allocation[nlocals+ncells : varsize]
@@ -95,10 +122,39 @@ func NewFrame(globals, locals StringDict, code *Code, closure Tuple) *Frame {
 		Code:            code,
 		LocalVars:       localVars,
 		CellAndFreeVars: cellAndFreeVars,
-		Builtins:        Builtins.Globals,
-		Localsplus:      allocation,
-		Stack:           make([]Object, 0, code.Stacksize),
+		Builtins:        builtinsForGlobals(globals),
+		Localsplus:      allocation[:varsize],
+		Stack:           allocation[varsize:varsize],
+	}
+}
+
+// Returns the builtins for code running with globals
+//
+// These are globals["__builtins__"] if set, which may be a module or
+// a dictionary, so that the builtins can be restricted or replaced.
+// If it isn't set they are those of the code running, so that code
+// given restricted builtins can't get the others back by deleting
+// __builtins__, or those of the builtins module if no code is
+// running.  If it is set to anything else there are none.
+func builtinsForGlobals(globals StringDict) StringDict {
+	obj, ok := globals["__builtins__"]
+	if !ok {
+		if CurrentFrame != nil {
+			return CurrentFrame.Builtins
+		}
+		return CurrentContext.Builtins().Globals
 	}
+	switch builtins := obj.(type) {
+	case *Module:
+		return builtins.Globals
+	case StringDict:
+		return builtins
+	case *Dict:
+		if d, err := AsStringDict(builtins); err == nil {
+			return d
+		}
+	}
+	return StringDict{}
 }
 
 // Python globals  are looked up in two scopes
@@ -136,6 +192,92 @@ func (f *Frame) Lookup(name string) (obj Object, ok bool) {
 	return f.LookupGlobal(name)
 }
 
+// LookupName looks up name as Lookup does using LocalsMapping for the
+// local scope if it is set
+func (f *Frame) LookupName(name string) (obj Object, ok bool, err error) {
+	if f.LocalsMapping == nil {
+		obj, ok = f.Lookup(name)
+		return obj, ok, nil
+	}
+	obj, err = GetItem(f.LocalsMapping, String(name))
+	if err == nil {
+		return obj, true, nil
+	}
+	if !IsException(KeyError, err) {
+		return nil, false, err
+	}
+	obj, ok = f.LookupGlobal(name)
+	return obj, ok, nil
+}
+
+// StoreName sets name to value in the local scope
+func (f *Frame) StoreName(name string, value Object) error {
+	if f.LocalsMapping == nil {
+		f.Locals[name] = value
+		return nil
+	}
+	_, err := SetItem(f.LocalsMapping, String(name), value)
+	return err
+}
+
+// DeleteName deletes name from the local scope returning false if it
+// wasn't there
+func (f *Frame) DeleteName(name string) (ok bool, err error) {
+	if f.LocalsMapping == nil {
+		if _, ok = f.Locals[name]; ok {
+			delete(f.Locals, name)
+		}
+		return ok, nil
+	}
+	_, err = DelItem(f.LocalsMapping, String(name))
+	if err != nil {
+		if IsException(KeyError, err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetLocals returns the local scope as locals() does
+//
+// This is LocalsMapping if set, otherwise Locals with the fast
+// locals merged into it.
+func (f *Frame) GetLocals() Object {
+	if f.LocalsMapping != nil {
+		return f.LocalsMapping
+	}
+	f.FastToLocals()
+	return f.Locals
+}
+
+// HandledException returns the exception being handled by an except
+// clause in this frame or in one of its callers or nil if there isn't
+// one
+func (f *Frame) HandledException() *ExceptionInfo {
+	for ; f != nil; f = f.Back {
+		if f.Exc != nil && f.Exc.IsSet() {
+			return f.Exc
+		}
+	}
+	return nil
+}
+
+// LineNumber returns the line number the frame is executing
+//
+// The line number is kept up to date in Lineno while the frame is
+// being traced, otherwise it is worked out from Lasti.  A frame which
+// hasn't started yet is on the first line of its code.
+func (f *Frame) LineNumber() int32 {
+	if f.Trace != nil {
+		return f.Lineno
+	}
+	if f.Lasti == 0 {
+		return f.Code.Firstlineno
+	}
+	return f.Code.Addr2Line(f.Lasti)
+}
+
 // Make a new Block (try/for/while)
 func (f *Frame) PushBlock(Type TryBlockType, Handler int32, Level int) {
 	f.Blockstack = append(f.Blockstack, TryBlock{
@@ -293,3 +435,62 @@ func (f *Frame) LocalsToFast(clear bool) {
 		}
 	}
 }
+
+// Properties
+func init() {
+	FrameType.Dict["f_back"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			if back := self.(*Frame).Back; back != nil {
+				return back, nil
+			}
+			return None, nil
+		},
+	}
+	FrameType.Dict["f_code"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Frame).Code, nil
+		},
+	}
+	FrameType.Dict["f_globals"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Frame).Globals, nil
+		},
+	}
+	FrameType.Dict["f_builtins"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Frame).Builtins, nil
+		},
+	}
+	FrameType.Dict["f_locals"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Frame).GetLocals(), nil
+		},
+	}
+	FrameType.Dict["f_lasti"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return Int(self.(*Frame).Lasti), nil
+		},
+	}
+	FrameType.Dict["f_lineno"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return Int(self.(*Frame).LineNumber()), nil
+		},
+	}
+	FrameType.Dict["f_trace"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			if trace := self.(*Frame).Trace; trace != nil {
+				return trace, nil
+			}
+			return None, nil
+		},
+		Fset: func(self, value Object) error {
+			f := self.(*Frame)
+			if value == None {
+				f.Trace = nil
+			} else {
+				f.Trace = value
+			}
+			return nil
+		},
+	}
+}
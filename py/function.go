@@ -15,6 +15,10 @@
 // executed so far.
 package py
 
+import (
+	"fmt"
+)
+
 // A python Function object
 type Function struct {
 	Code        *Code      // A code object, the __code__ attribute
@@ -29,6 +33,7 @@ type Function struct {
 	Module      Object     // The __module__ attribute, can be anything
 	Annotations StringDict // Annotations, a dict or NULL
 	Qualname    string     // The qualified name
+	Builtins    StringDict // The builtins it was made with
 }
 
 var FunctionType = NewType("function", "A python function")
@@ -85,18 +90,30 @@ func NewFunction(code *Code, globals StringDict, qualname string) *Function {
 		Doc:      doc,
 		Module:   module,
 		Dict:     make(StringDict),
+		Builtins: builtinsForGlobals(globals),
 	}
 }
 
 // Call a function
 func (f *Function) M__call__(args Tuple, kwargs StringDict) (Object, error) {
-	result, err := VmEvalCodeEx(f.Code, f.Globals, NewStringDict(), args, kwargs, f.Defaults, f.KwDefaults, f.Closure)
+	// Optimized code keeps its locals in the frame so the locals
+	// dictionary is only made if something asks for it
+	var locals StringDict
+	if f.Code.Flags&CO_OPTIMIZED == 0 {
+		locals = NewStringDict()
+	}
+	result, err := VmEvalFunction(f, locals, args, kwargs)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// Represent the function by its qualified name
+func (f *Function) M__repr__() (Object, error) {
+	return String(fmt.Sprintf("<function %s at %p>", f.Qualname, f)), nil
+}
+
 // Read a function from a class which makes a bound method
 func (f *Function) M__get__(instance, owner Object) (Object, error) {
 	if instance != None {
@@ -151,8 +168,8 @@ func init() {
 		},
 		Fset: func(self, value Object) error {
 			f := self.(*Function)
-			kwdefaults, ok := value.(StringDict)
-			if !ok {
+			kwdefaults, err := AsStringDict(value)
+			if err != nil {
 				return ExceptionNewf(TypeError, "__kwdefaults__ must be set to a dict object")
 			}
 			f.KwDefaults = kwdefaults
@@ -169,8 +186,8 @@ func init() {
 		},
 		Fset: func(self, value Object) error {
 			f := self.(*Function)
-			annotations, ok := value.(StringDict)
-			if !ok {
+			annotations, err := AsStringDict(value)
+			if err != nil {
 				return ExceptionNewf(TypeError, "__annotations__ must be set to a dict object")
 			}
 			f.Annotations = annotations
@@ -187,14 +204,27 @@ func init() {
 		},
 		Fset: func(self, value Object) error {
 			f := self.(*Function)
-			dict, ok := value.(StringDict)
-			if !ok {
+			dict, err := AsStringDict(value)
+			if err != nil {
 				return ExceptionNewf(TypeError, "__dict__ must be set to a dict object")
 			}
 			f.Dict = dict
 			return nil
 		},
 	}
+	FunctionType.Dict["__globals__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Function).Globals, nil
+		},
+	}
+	FunctionType.Dict["__closure__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			if closure := self.(*Function).Closure; closure != nil {
+				return closure, nil
+			}
+			return None, nil
+		},
+	}
 	FunctionType.Dict["__name__"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return String(self.(*Function).Name), nil
@@ -209,6 +239,24 @@ func init() {
 			return nil
 		},
 	}
+	FunctionType.Dict["__doc__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Function).Doc, nil
+		},
+		Fset: func(self, value Object) error {
+			self.(*Function).Doc = value
+			return nil
+		},
+	}
+	FunctionType.Dict["__module__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Function).Module, nil
+		},
+		Fset: func(self, value Object) error {
+			self.(*Function).Module = value
+			return nil
+		},
+	}
 	FunctionType.Dict["__qualname__"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return String(self.(*Function).Qualname), nil
@@ -50,6 +50,7 @@ var data = Data{
 		{Name: "add", Title: "Add", Operator: "+", Binary: true},
 		{Name: "sub", Title: "Sub", Operator: "-", Binary: true},
 		{Name: "mul", Title: "Mul", Operator: "*", Binary: true},
+		{Name: "matmul", Title: "MatMul", Operator: "@", Binary: true},
 		{Name: "truediv", Title: "TrueDiv", Operator: "/", Binary: true},
 		{Name: "floordiv", Title: "FloorDiv", Operator: "//", Binary: true},
 		{Name: "mod", Title: "Mod", Operator: "%%", Binary: true},
@@ -115,6 +116,8 @@ func {{.Title}}(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__{{.Name}}__"); ok {
+		return res, err
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for {{.Operator}}: '%s'", a.Type().Name)
@@ -128,6 +131,21 @@ func {{.Title}}(a Object) (Object, error) {
 {{ end }}//
 // Will raise TypeError if can't be {{.Name}} can't be run on these objects
 func {{.Title}}(a, b {{ if .Ternary }}, c{{ end }} Object) (Object {{ if .TwoReturnParameters}}, Object{{ end }}, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// r{{.Name}} first so subclasses can override the operation
+	reflectedFirst := {{ if .Ternary }} c == None && {{ end }} a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__r{{.Name}}__); ok {
+			res {{ if .TwoReturnParameters}}, res2 {{ end }}, err := B.M__r{{.Name}}__(a)
+			if err != nil {
+				return nil {{ if .TwoReturnParameters }}, nil{{ end }}, err
+			}
+			if res != NotImplemented {
+				return res{{ if .TwoReturnParameters}}, res2{{ end }}, nil
+			}
+		}
+	}
+
 	// Try using a to {{.Name}}
 	if A, ok := a.(I__{{.Name}}__); ok {
 		res {{ if .TwoReturnParameters}}, res2{{ end }}, err := A.M__{{.Name}}__(b {{ if .Ternary }}, c{{ end }})
@@ -140,7 +158,7 @@ func {{.Title}}(a, b {{ if .Ternary }}, c{{ end }} Object) (Object {{ if .TwoRet
 	}
 
 	// Now using b to r{{.Name}} if different in type to a
-	if {{ if .Ternary }} c == None && {{ end }} a.Type() != b.Type() {
+	if !reflectedFirst && {{ if .Ternary }} c == None && {{ end }} a.Type() != b.Type() {
 		if B, ok := b.(I__r{{.Name}}__); ok {
 			res {{ if .TwoReturnParameters}}, res2 {{ end }}, err := B.M__r{{.Name}}__(a)
 			if err != nil {
@@ -176,6 +194,21 @@ func I{{.Title}}(a, b {{ if .Ternary }}, c{{ end }} Object) (Object, error) {
 //
 // Will raise TypeError if {{.Title}} can't be run on this object
 func {{.Title}}(a Object, b Object) (Object, error) {
+	// If b is an instance of a subclass of a's type then try b's
+	// reflected method first so subclasses can override comparisons
+	reflectedFirst := a.Type() != b.Type() && b.Type().IsSubtype(a.Type())
+	if reflectedFirst {
+		if B, ok := b.(I__{{.Reversed}}__); ok {
+			res, err := B.M__{{.Reversed}}__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
+		}
+	}
+
 	// Try using a to {{.Name}}
 	if A, ok := a.(I__{{.Name}}__); ok {
 		res, err := A.M__{{.Name}}__(b)
@@ -188,22 +221,28 @@ func {{.Title}}(a Object, b Object) (Object, error) {
 	}
 
 	// Try using b to {{.Reversed}} with reversed parameters
-	if B, ok := b.(I__{{.Reversed}}__); ok {
-		res, err := B.M__{{.Reversed}}__(a)
-		if err != nil {
-			return nil, err
-		}
-		if res != NotImplemented {
-			return res, nil
+	if !reflectedFirst {
+		if B, ok := b.(I__{{.Reversed}}__); ok {
+			res, err := B.M__{{.Reversed}}__(a)
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 
 {{ if .FailReturn}}
-if a.Type() != b.Type() {
-	return {{ .FailReturn }}, nil
+	// Fall back to comparing identity
+	if a.Type() != b.Type() {
+		return {{ .FailReturn }}, nil
+	}
+	return NewBool(a {{.Operator}} b), nil
 }
-{{ end }}
-	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for {{.Operator}}: '%s' and '%s'", a.Type().Name, b.Type().Name)
+{{ else }}
+	return nil, ExceptionNewf(TypeError, "'{{.Operator}}' not supported between instances of '%s' and '%s'", a.Type().Name, b.Type().Name)
 }
 {{ end }}
+{{ end }}
 `
@@ -81,30 +81,52 @@ func (it *Generator) M__next__() (Object, error) {
 // generator, it must be called with None as the argument, because
 // there is no yield expression that could receive the value.
 func (it *Generator) Send(arg Object) (Object, error) {
+	if it.Frame != nil && it.Frame.Lasti == 0 && arg != None {
+		return nil, ExceptionNewf(TypeError, "can't send non-None value to a just-started generator")
+	}
+	return it.resume(arg, nil)
+}
+
+// Resumes the generator, either sending arg into it or raising exc
+// in it if exc is not nil
+//
+// When the generator finishes its frame is released and
+// StopIteration is raised carrying the return value, if any.
+func (it *Generator) resume(arg Object, exc *Exception) (Object, error) {
 	if it.Running {
 		return nil, ExceptionNewf(ValueError, "generator already executing")
 	}
-	if it.Frame.Lasti == 0 {
-		if arg != None {
-			return nil, ExceptionNewf(TypeError, "can't send non-None value to a just-started generator")
+	if it.Frame == nil {
+		// Already finished
+		if exc != nil {
+			return nil, exc
 		}
+		return nil, StopIteration
+	}
+	var res Object
+	var err error
+	it.Running = true
+	if exc != nil {
+		res, err = VmRunFrameThrow(it.Frame, exc)
 	} else {
-		// If already returned a non yield value then stop
-		if !it.Frame.Yielded {
-			return nil, StopIteration
+		if it.Frame.Lasti != 0 {
+			// Push arg onto the frame's value stack
+			it.Frame.Stack = append(it.Frame.Stack, arg)
 		}
-		// Push arg onto the frame's value stack
-		it.Frame.Stack = append(it.Frame.Stack, arg)
+		res, err = VmRunFrame(it.Frame)
 	}
-	it.Running = true
-	res, err := VmRunFrame(it.Frame)
 	it.Running = false
 	if err != nil {
+		it.Frame = nil
 		return nil, err
 	}
 	if it.Frame.Yielded {
 		return res, nil
 	}
+	it.Frame = nil
+	if res != None {
+		return nil, exceptionNew(StopIteration, Tuple{res})
+	}
 	return nil, StopIteration
 }
 
@@ -117,7 +139,57 @@ func (it *Generator) Send(arg Object) (Object, error) {
 // not catch the passed-in exception, or raises a different exception,
 // then that exception propagates to the caller.
 func (it *Generator) Throw(args Tuple, kwargs StringDict) (Object, error) {
-	return nil, NotImplementedError
+	var typ Object
+	var value Object = None
+	var traceback Object = None
+	err := UnpackTuple(args, kwargs, "throw", 1, 3, &typ, &value, &traceback)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := traceback.(*Traceback); !ok && traceback != None {
+		return nil, ExceptionNewf(TypeError, "throw() third argument must be a traceback object")
+	}
+	var exc *Exception
+	switch t := typ.(type) {
+	case *Exception:
+		if value != None {
+			return nil, ExceptionNewf(TypeError, "instance exception may not have a separate value")
+		}
+		exc = t
+	case *Type:
+		if !ExceptionClassCheck(t) {
+			break
+		}
+		if e, ok := value.(*Exception); ok && e.Base.IsSubtype(t) {
+			exc = e
+			break
+		}
+		var excArgs Tuple
+		switch v := value.(type) {
+		case Tuple:
+			excArgs = v
+		default:
+			if value != None {
+				excArgs = Tuple{value}
+			}
+		}
+		obj, err := Call(t, excArgs, nil)
+		if err != nil {
+			return nil, err
+		}
+		e, ok := obj.(*Exception)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "calling %s should have returned an instance of BaseException, not %s", t.Name, obj.Type().Name)
+		}
+		exc = e
+	}
+	if exc == nil {
+		return nil, ExceptionNewf(TypeError, "exceptions must be classes or instances deriving from BaseException, not %s", typ.Type().Name)
+	}
+	if tb, ok := traceback.(*Traceback); ok {
+		exc.Traceback = tb
+	}
+	return it.resume(nil, exc)
 }
 
 // generator.close()
@@ -131,7 +203,22 @@ func (it *Generator) Throw(args Tuple, kwargs StringDict) (Object, error) {
 // caller. close() does nothing if the generator has already exited
 // due to an exception or normal exit.
 func (it *Generator) Close() (Object, error) {
-	return nil, NotImplementedError
+	if it.Frame == nil {
+		return None, nil
+	}
+	if it.Frame.Lasti == 0 && !it.Running {
+		// Never started so there is nothing to clean up
+		it.Frame = nil
+		return None, nil
+	}
+	_, err := it.resume(nil, MakeException(GeneratorExit))
+	if err == nil {
+		return nil, ExceptionNewf(RuntimeError, "generator ignored GeneratorExit")
+	}
+	if IsException(GeneratorExit, err) || IsException(StopIteration, err) {
+		return None, nil
+	}
+	return nil, err
 }
 
 // Check interface is satisfied
@@ -0,0 +1,366 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// GenericAlias objects made by subscripting classes such as list[int]
+// as described in PEP 585
+
+package py
+
+import (
+	"strings"
+)
+
+const genericAliasDoc = `Represent a PEP 585 generic type
+
+E.g. for t = list[int], t.__origin__ is list and t.__args__ is (int,).`
+
+var GenericAliasType = NewTypeX("types.GenericAlias", genericAliasDoc, GenericAliasNew, nil)
+
+// GenericAlias is a class subscripted with the types of its contents
+type GenericAlias struct {
+	Origin     Object
+	Args       Tuple
+	Parameters Tuple
+}
+
+// Type of this object
+func (a *GenericAlias) Type() *Type {
+	return GenericAliasType
+}
+
+// NewGenericAlias makes origin[args] where args is a Tuple of the
+// subscripts or a single subscript
+func NewGenericAlias(origin, args Object) (*GenericAlias, error) {
+	argsTuple, ok := args.(Tuple)
+	if !ok {
+		argsTuple = Tuple{args}
+	}
+	params, err := GenericParameters(argsTuple)
+	if err != nil {
+		return nil, err
+	}
+	return &GenericAlias{
+		Origin:     origin,
+		Args:       argsTuple,
+		Parameters: params,
+	}, nil
+}
+
+// GenericAliasNew makes a GenericAlias from python
+func GenericAliasNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var origin, aliasArgs Object
+	err := UnpackTuple(args, kwargs, "GenericAlias", 2, 2, &origin, &aliasArgs)
+	if err != nil {
+		return nil, err
+	}
+	return NewGenericAlias(origin, aliasArgs)
+}
+
+// Returns true if obj is a typing.TypeVar
+//
+// Like CPython this goes by the name of its type as the typing module
+// isn't part of py
+func isTypeVar(obj Object) bool {
+	return obj.Type().Name == "typing.TypeVar"
+}
+
+// GenericParameters returns the type variables in args, including
+// those in the __parameters__ of any generic aliases in args, in the
+// order they first appear
+func GenericParameters(args Tuple) (Tuple, error) {
+	params := Tuple{}
+	add := func(param Object) {
+		for _, p := range params {
+			if p == param {
+				return
+			}
+		}
+		params = append(params, param)
+	}
+	for _, arg := range args {
+		if isTypeVar(arg) {
+			add(arg)
+			continue
+		}
+		if t, ok := arg.(*Type); ok && t.Type().IsSubtype(TypeType) {
+			continue
+		}
+		subParams, err := GetAttrString(arg, "__parameters__")
+		if err != nil {
+			if IsException(AttributeError, err) {
+				continue
+			}
+			return nil, err
+		}
+		if subParamsTuple, ok := subParams.(Tuple); ok {
+			for _, param := range subParamsTuple {
+				add(param)
+			}
+		}
+	}
+	return params, nil
+}
+
+// GenericSubstitute returns args with the type variables in params
+// replaced by the corresponding items of subArgs, which is what
+// subscripting a generic alias with subArgs does
+func GenericSubstitute(alias Object, args, params Tuple, subArgs Object) (Tuple, error) {
+	aliasRepr, err := ReprAsString(alias)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) == 0 {
+		return nil, ExceptionNewf(TypeError, "%s is not a generic class", aliasRepr)
+	}
+	subArgsTuple, ok := subArgs.(Tuple)
+	if !ok {
+		subArgsTuple = Tuple{subArgs}
+	}
+	if len(subArgsTuple) != len(params) {
+		many := "many"
+		if len(subArgsTuple) < len(params) {
+			many = "few"
+		}
+		return nil, ExceptionNewf(TypeError, "Too %s arguments for %s; actual %d, expected %d", many, aliasRepr, len(subArgsTuple), len(params))
+	}
+	substitute := func(arg Object) Object {
+		for i, param := range params {
+			if arg == param {
+				return subArgsTuple[i]
+			}
+		}
+		return nil
+	}
+	newArgs := make(Tuple, len(args))
+	for i, arg := range args {
+		if res := substitute(arg); res != nil {
+			newArgs[i] = res
+			continue
+		}
+		newArgs[i] = arg
+		if t, ok := arg.(*Type); (ok && t.Type().IsSubtype(TypeType)) || isTypeVar(arg) {
+			continue
+		}
+		subParams, err := GetAttrString(arg, "__parameters__")
+		if err != nil {
+			if IsException(AttributeError, err) {
+				continue
+			}
+			return nil, err
+		}
+		subParamsTuple, ok := subParams.(Tuple)
+		if !ok || len(subParamsTuple) == 0 {
+			continue
+		}
+		items := make(Tuple, len(subParamsTuple))
+		for j, param := range subParamsTuple {
+			items[j] = substitute(param)
+			if items[j] == nil {
+				items[j] = param
+			}
+		}
+		var key Object = items
+		if len(items) == 1 {
+			key = items[0]
+		}
+		newArgs[i], err = GetItem(arg, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newArgs, nil
+}
+
+// TypeRepr returns obj as it is shown in the repr of a generic alias,
+// which is the qualified name for classes
+func TypeRepr(obj Object) (string, error) {
+	switch x := obj.(type) {
+	case EllipsisType:
+		return "...", nil
+	case *Function:
+		return x.Name, nil
+	case *Type:
+		if !x.Type().IsSubtype(TypeType) {
+			break
+		}
+		if x.Flags&TPFLAGS_HEAPTYPE == 0 {
+			return x.Name, nil
+		}
+		qualname, err := GetAttrString(x, "__qualname__")
+		if err != nil {
+			return "", err
+		}
+		module, err := GetAttrString(x, "__module__")
+		if err != nil {
+			return "", err
+		}
+		name, err := StrAsString(qualname)
+		if err != nil {
+			return "", err
+		}
+		if module == String("builtins") {
+			return name, nil
+		}
+		moduleName, err := StrAsString(module)
+		if err != nil {
+			return "", err
+		}
+		return moduleName + "." + name, nil
+	}
+	return ReprAsString(obj)
+}
+
+// TypeReprList returns the TypeRepr of the items of args joined with
+// commas
+func TypeReprList(args Tuple) (string, error) {
+	reprs := make([]string, len(args))
+	for i, arg := range args {
+		var err error
+		if list, ok := arg.(*List); ok {
+			// The arguments of Callable
+			var inner string
+			inner, err = TypeReprList(Tuple(list.Items))
+			reprs[i] = "[" + inner + "]"
+		} else {
+			reprs[i], err = TypeRepr(arg)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(reprs, ", "), nil
+}
+
+func (a *GenericAlias) M__repr__() (Object, error) {
+	origin, err := TypeRepr(a.Origin)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Args) == 0 {
+		return String(origin + "[()]"), nil
+	}
+	args, err := TypeReprList(a.Args)
+	if err != nil {
+		return nil, err
+	}
+	return String(origin + "[" + args + "]"), nil
+}
+
+// Calling the alias makes an instance of its origin
+func (a *GenericAlias) M__call__(args Tuple, kwargs StringDict) (Object, error) {
+	res, err := Call(a.Origin, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = SetAttrString(res, "__orig_class__", a)
+	if err != nil && !IsException(AttributeError, err) && !IsException(TypeError, err) {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (a *GenericAlias) M__getitem__(key Object) (Object, error) {
+	args, err := GenericSubstitute(a, a.Args, a.Parameters, key)
+	if err != nil {
+		return nil, err
+	}
+	return NewGenericAlias(a.Origin, args)
+}
+
+// Attributes which aren't read from the origin
+var genericAliasAttrExceptions = map[string]struct{}{
+	"__origin__":      {},
+	"__args__":        {},
+	"__parameters__":  {},
+	"__mro_entries__": {},
+	"__reduce_ex__":   {},
+	"__reduce__":      {},
+	"__copy__":        {},
+	"__deepcopy__":    {},
+}
+
+// Other attributes are those of the origin
+func (a *GenericAlias) M__getattr__(name string) (Object, error) {
+	if _, ok := genericAliasAttrExceptions[name]; !ok {
+		return GetAttrString(a.Origin, name)
+	}
+	return nil, ExceptionNewf(AttributeError, "'types.GenericAlias' object has no attribute '%s'", name)
+}
+
+func (a *GenericAlias) M__eq__(other Object) (Object, error) {
+	b, ok := other.(*GenericAlias)
+	if !ok {
+		return NotImplemented, nil
+	}
+	eq, err := Eq(a.Origin, b.Origin)
+	if err != nil || eq != True {
+		return eq, err
+	}
+	return a.Args.M__eq__(b.Args)
+}
+
+func (a *GenericAlias) M__ne__(other Object) (Object, error) {
+	eq, err := a.M__eq__(other)
+	if err != nil || eq == NotImplemented {
+		return eq, err
+	}
+	return Not(eq)
+}
+
+func (a *GenericAlias) M__hash__() (Object, error) {
+	origin, err := Hash(a.Origin)
+	if err != nil {
+		return nil, err
+	}
+	args, err := Hash(a.Args)
+	if err != nil {
+		return nil, err
+	}
+	return Int(origin ^ args), nil
+}
+
+const classGetItemDoc = `See PEP 585`
+
+func classGetItem(cls, key Object) (Object, error) {
+	return NewGenericAlias(cls, key)
+}
+
+// Properties
+func init() {
+	GenericAliasType.Dict["__origin__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*GenericAlias).Origin, nil
+		},
+	}
+	GenericAliasType.Dict["__args__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*GenericAlias).Args, nil
+		},
+	}
+	GenericAliasType.Dict["__parameters__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*GenericAlias).Parameters, nil
+		},
+	}
+	GenericAliasType.Dict["__mro_entries__"] = MustNewMethod("__mro_entries__", func(self, bases Object) (Object, error) {
+		return Tuple{self.(*GenericAlias).Origin}, nil
+	}, 0, "")
+
+	// The builtin containers can be subscripted
+	for _, t := range []*Type{TupleType, ListType, DictType, SetType, FrozenSetType} {
+		t.Dict["__class_getitem__"] = &ClassMethod{
+			Callable: MustNewMethod("__class_getitem__", classGetItem, 0, classGetItemDoc),
+			Dict:     NewStringDict(),
+		}
+	}
+}
+
+// Check interface is satisfied
+var _ I__repr__ = (*GenericAlias)(nil)
+var _ I__call__ = (*GenericAlias)(nil)
+var _ I__getitem__ = (*GenericAlias)(nil)
+var _ I__getattr__ = (*GenericAlias)(nil)
+var _ I__eq__ = (*GenericAlias)(nil)
+var _ I__ne__ = (*GenericAlias)(nil)
+var _ I__hash__ = (*GenericAlias)(nil)
@@ -0,0 +1,111 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Classes made in Go
+//
+// The modules which make python classes in Go, which python classes
+// can inherit from, make them with NewClass from a dict of GoMethods.
+// The methods call back into the python objects they are given with
+// CallMethodByName and IntAttr.
+
+package py
+
+import "fmt"
+
+var GoMethodType = NewType("method_descriptor", "Method of a class made in Go")
+
+// GoMethod is a method of a class made in Go, which is called with
+// the instance as the first argument
+type GoMethod struct {
+	Name string
+	Fn   func(self Object, args Tuple, kwargs StringDict) (Object, error)
+}
+
+// Type of this object
+func (m *GoMethod) Type() *Type {
+	return GoMethodType
+}
+
+// Binds the method to an instance
+func (m *GoMethod) M__get__(instance, owner Object) (Object, error) {
+	if instance != None {
+		return NewBoundMethod(instance, m), nil
+	}
+	return m, nil
+}
+
+func (m *GoMethod) M__call__(args Tuple, kwargs StringDict) (Object, error) {
+	if len(args) < 1 {
+		return nil, ExceptionNewf(TypeError, "%s() missing 1 required positional argument: 'self'", m.Name)
+	}
+	return m.Fn(args[0], args[1:], kwargs)
+}
+
+func (m *GoMethod) M__repr__() (Object, error) {
+	return String(fmt.Sprintf("<method '%s'>", m.Name)), nil
+}
+
+// NewGoMethod makes a method called name taking any arguments
+func NewGoMethod(name string, fn func(self Object, args Tuple, kwargs StringDict) (Object, error)) *GoMethod {
+	return &GoMethod{Name: name, Fn: fn}
+}
+
+// NewGoMethod0 makes a method called name which takes no arguments
+// other than the instance
+func NewGoMethod0(name string, fn func(self Object) (Object, error)) *GoMethod {
+	return &GoMethod{Name: name, Fn: func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		err := UnpackTuple(args, kwargs, name, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return fn(self)
+	}}
+}
+
+// NewGoMethod1 makes a method called name which takes one argument
+// other than the instance
+func NewGoMethod1(name string, fn func(self, arg Object) (Object, error)) *GoMethod {
+	return &GoMethod{Name: name, Fn: func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var arg Object
+		err := UnpackTuple(args, kwargs, name, 1, 1, &arg)
+		if err != nil {
+			return nil, err
+		}
+		return fn(self, arg)
+	}}
+}
+
+// NewClass makes the class name of the module with the metaclass
+// meta, which python classes can inherit from, panicking on error
+//
+// The class is made by TypeNew whatever meta.New is, so a metaclass
+// which does more when making a class must finish it itself.
+func NewClass(meta *Type, module, name, doc string, bases Tuple, dict StringDict) *Type {
+	dict["__module__"] = String(module)
+	dict["__qualname__"] = String(name)
+	dict["__doc__"] = String(doc)
+	cls, err := TypeNew(meta, Tuple{String(name), bases, dict}, nil)
+	if err != nil {
+		panic(err)
+	}
+	return cls.(*Type)
+}
+
+// CallMethodByName calls the method name of obj with args
+func CallMethodByName(obj Object, name string, args ...Object) (Object, error) {
+	method, err := GetAttrString(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return Call(method, Tuple(args), nil)
+}
+
+// IntAttr returns the attribute name of obj as a Go int
+func IntAttr(obj Object, name string) (int, error) {
+	value, err := GetAttrString(obj, name)
+	if err != nil {
+		return 0, err
+	}
+	return MakeGoInt(value)
+}
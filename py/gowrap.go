@@ -0,0 +1,601 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Go value objects
+//
+// These wrap arbitrary Go values using reflection so an embedding
+// program can hand its own types to Python code without writing a
+// Method or Property for each one.
+//
+// Exported fields of a wrapped struct are attributes and exported
+// methods are callable, both under their Go names.
+
+package py
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sync"
+)
+
+// A python GoValue object
+//
+// Value is always a pointer to the wrapped Go value so fields can be
+// set and methods with pointer receivers called.
+type GoValue struct {
+	Value reflect.Value
+}
+
+var (
+	goTypesMu sync.Mutex
+	goTypes   = map[reflect.Type]*Type{}
+)
+
+// Type of this GoValue object
+func (o *GoValue) Type() *Type {
+	return NewGoType(o.Value.Type().Elem())
+}
+
+// Interface returns the pointer to the wrapped Go value
+func (o *GoValue) Interface() interface{} {
+	return o.Value.Interface()
+}
+
+// NewGoType returns the python type for values of the Go type t,
+// making it the first time it is asked for
+//
+// A pointer type gives the type of the value it points to. The type
+// has a property for each exported field and a method for each
+// exported method of t. Calling it makes a new zero value of t with
+// the fields given as keyword arguments set.
+func NewGoType(t reflect.Type) *Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	goTypesMu.Lock()
+	defer goTypesMu.Unlock()
+	if pyType, ok := goTypes[t]; ok {
+		return pyType
+	}
+	name := t.Name()
+	if name == "" {
+		name = "GoValue"
+	}
+	pyType := NewTypeX(name, fmt.Sprintf("Go value of type %s", t), func(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+		return goValueNew(t, args, kwargs)
+	}, nil)
+	goTypes[t] = pyType
+	if t.Kind() == reflect.Struct {
+		for _, field := range goFields(t) {
+			pyType.Dict[field.Name] = goFieldProperty(field)
+		}
+	}
+	ptrType := reflect.PtrTo(t)
+	for i := 0; i < ptrType.NumMethod(); i++ {
+		method := ptrType.Method(i)
+		if method.PkgPath != "" {
+			continue
+		}
+		pyType.Dict[method.Name] = goMethod(method)
+	}
+	return pyType
+}
+
+// Returns the exported fields of the struct type t including those
+// promoted from embedded structs which aren't hidden by a shallower
+// field of the same name
+func goFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	seen := map[string]bool{}
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		seen[field.Name] = true
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, field)
+		}
+		if field.PkgPath == "" {
+			fields = append(fields, field)
+		}
+	}
+	for _, embed := range embedded {
+		for _, field := range goFields(embed.Type) {
+			if seen[field.Name] {
+				continue
+			}
+			seen[field.Name] = true
+			field.Index = append([]int{embed.Index[0]}, field.Index...)
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Makes the property which reads and writes field
+func goFieldProperty(field reflect.StructField) *Property {
+	index := field.Index
+	return &Property{
+		Fget: func(self Object) (Object, error) {
+			value := self.(*GoValue).Value.Elem().FieldByIndex(index)
+			if value.Kind() == reflect.Struct {
+				// Refer to the field so updating its
+				// attributes updates the struct
+				return &GoValue{Value: value.Addr()}, nil
+			}
+			return WrapGoValue(value.Interface())
+		},
+		Fset: func(self, value Object) error {
+			dst := self.(*GoValue).Value.Elem().FieldByIndex(index)
+			v, err := UnwrapGoValue(value, dst.Type())
+			if err != nil {
+				return err
+			}
+			dst.Set(v)
+			return nil
+		},
+		Doc: fmt.Sprintf("%s %s", field.Name, field.Type),
+	}
+}
+
+// Makes the python method which calls the Go method
+func goMethod(method reflect.Method) *Method {
+	return MustNewMethod(method.Name, func(self Object, args Tuple) (Object, error) {
+		receiver := self.(*GoValue).Value
+		return callGo(method.Name, receiver.Method(method.Index), args)
+	}, 0, fmt.Sprintf("%s%s", method.Name, method.Type.String()[len("func"):]))
+}
+
+// NewGoFunc makes a python callable called name from the Go function
+// fn, converting the arguments it is called with to the types of the
+// parameters of fn with UnwrapGoValue
+//
+// The results of fn are converted with WrapGoValue. If the last one
+// is an error which isn't nil it is raised instead, so a Go function
+// can raise a python exception by returning one. fn may also be a
+// PyCFunction or PyCFunctionWithKeywords which is called as it is.
+// If doc is empty the Go signature of fn is used.
+func NewGoFunc(name, doc string, fn interface{}) (*Method, error) {
+	switch fn.(type) {
+	case func(self Object, args Tuple) (Object, error), func(self Object, args Tuple, kwargs StringDict) (Object, error):
+		return NewMethod(name, fn, 0, doc)
+	}
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, ExceptionNewf(TypeError, "%s: can't make a function from Go %T", name, fn)
+	}
+	if doc == "" {
+		doc = name + v.Type().String()[len("func"):]
+	}
+	return NewMethod(name, func(self Object, args Tuple) (Object, error) {
+		return callGo(name, v, args)
+	}, 0, doc)
+}
+
+// As NewGoFunc but panics on error
+func MustNewGoFunc(name, doc string, fn interface{}) *Method {
+	m, err := NewGoFunc(name, doc, fn)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Makes a new GoValue of type t setting fields from kwargs
+func goValueNew(t reflect.Type, args Tuple, kwargs StringDict) (Object, error) {
+	if len(args) != 0 {
+		return nil, ExceptionNewf(TypeError, "%s() takes no positional arguments", t.Name())
+	}
+	o := &GoValue{Value: reflect.New(t)}
+	for name, value := range kwargs {
+		_, err := SetAttrString(o, name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Calls the Go function fn with args converted to the types it takes
+//
+// If the last result is an error and it isn't nil it is raised,
+// otherwise no results give None, one gives its value and more give
+// a tuple of them.
+func callGo(name string, fn reflect.Value, args Tuple) (Object, error) {
+	t := fn.Type()
+	nin := t.NumIn()
+	if t.IsVariadic() {
+		if len(args) < nin-1 {
+			return nil, ExceptionNewf(TypeError, "%s() takes at least %d arguments (%d given)", name, nin-1, len(args))
+		}
+	} else if len(args) != nin {
+		return nil, ExceptionNewf(TypeError, "%s() takes exactly %d arguments (%d given)", name, nin, len(args))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var argType reflect.Type
+		if t.IsVariadic() && i >= nin-1 {
+			argType = t.In(nin - 1).Elem()
+		} else {
+			argType = t.In(i)
+		}
+		v, err := UnwrapGoValue(arg, argType)
+		if err != nil {
+			return nil, err
+		}
+		in[i] = v
+	}
+	out := fn.Call(in)
+	if n := len(out); n > 0 && t.Out(n-1) == errorType {
+		if err := out[n-1].Interface(); err != nil {
+			return nil, goError(err.(error))
+		}
+		out = out[:n-1]
+	}
+	switch len(out) {
+	case 0:
+		return None, nil
+	case 1:
+		return WrapGoValue(out[0].Interface())
+	}
+	results := make(Tuple, len(out))
+	for i := range out {
+		result, err := WrapGoValue(out[i].Interface())
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Converts an error returned from Go into a python exception
+//
+// Python exceptions are passed through, other errors are raised as
+// RuntimeError.
+func goError(err error) error {
+	switch err.(type) {
+	case *Exception, ExceptionInfo, *ExceptionInfo:
+		return err
+	}
+	return ExceptionNewf(RuntimeError, "%v", err)
+}
+
+// WrapGoValue converts the Go value v into a python Object
+//
+// Objects are returned as they are, nil is None and Go's bools,
+// numbers, strings and byte slices become the corresponding python
+// types. Slices and arrays become lists and maps with string keys
+// become dicts, with their contents converted. Functions become
+// callables. Structs and pointers to structs become GoValue objects;
+// a struct value is copied first.
+func WrapGoValue(v interface{}) (Object, error) {
+	if v == nil {
+		return None, nil
+	}
+	if o, ok := v.(Object); ok {
+		return o, nil
+	}
+	return wrapGoReflect(reflect.ValueOf(v))
+}
+
+func wrapGoReflect(v reflect.Value) (Object, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return NewBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > math.MaxInt64 {
+			return (*BigInt)(new(big.Int).SetUint64(u)), nil
+		}
+		return Int(u), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(v.Float()), nil
+	case reflect.Complex64, reflect.Complex128:
+		return Complex(v.Complex()), nil
+	case reflect.String:
+		return String(v.String()), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return None, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return Bytes(append([]byte(nil), v.Bytes()...)), nil
+		}
+		return wrapGoItems(v)
+	case reflect.Array:
+		return wrapGoItems(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return None, nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, ExceptionNewf(TypeError, "can't convert Go map with %s keys", v.Type().Key())
+		}
+		d := NewStringDictSized(v.Len())
+		for _, key := range v.MapKeys() {
+			value, err := wrapGoReflect(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			d[key.String()] = value
+		}
+		return d, nil
+	case reflect.Func:
+		if v.IsNil() {
+			return None, nil
+		}
+		name := v.Type().String()
+		return MustNewMethod(name, func(self Object, args Tuple) (Object, error) {
+			return callGo(name, v, args)
+		}, 0, ""), nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return None, nil
+		}
+		return WrapGoValue(v.Elem().Interface())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return None, nil
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			return &GoValue{Value: v}, nil
+		}
+	case reflect.Struct:
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return &GoValue{Value: p}, nil
+	}
+	return nil, ExceptionNewf(TypeError, "can't convert Go value of type %s", v.Type())
+}
+
+// Wraps each item of the slice or array v into a list
+func wrapGoItems(v reflect.Value) (Object, error) {
+	items := make([]Object, v.Len())
+	for i := range items {
+		item, err := wrapGoReflect(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return NewListFromItems(items), nil
+}
+
+// UnwrapGoValue converts the python Object o into a Go value of type t
+//
+// This is the reverse of WrapGoValue. An interface{} gets the natural
+// Go value for None, bools, ints, floats, strings and GoValue objects
+// and the Object itself otherwise.
+func UnwrapGoValue(o Object, t reflect.Type) (reflect.Value, error) {
+	if g, ok := o.(*GoValue); ok {
+		if g.Value.Type().AssignableTo(t) {
+			return g.Value, nil
+		}
+		if g.Value.Elem().Type().AssignableTo(t) {
+			return g.Value.Elem(), nil
+		}
+	}
+	if t.Kind() == reflect.Interface {
+		if t.NumMethod() == 0 {
+			return unwrapGoInterface(o, t)
+		}
+		if reflect.TypeOf(o).Implements(t) {
+			return reflect.ValueOf(o).Convert(t), nil
+		}
+		if g, ok := o.(*GoValue); ok && g.Value.Type().Implements(t) {
+			return g.Value.Convert(t), nil
+		}
+		return reflect.Value{}, goTypeError(o, t)
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		b, ok := o.(Bool)
+		if !ok {
+			return v, goTypeError(o, t)
+		}
+		v.SetBool(bool(b))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := goBigInt(o, t)
+		if err != nil {
+			return v, err
+		}
+		if !i.IsInt64() || v.OverflowInt(i.Int64()) {
+			return v, ExceptionNewf(OverflowError, "Python int too large to convert to %s", t)
+		}
+		v.SetInt(i.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, err := goBigInt(o, t)
+		if err != nil {
+			return v, err
+		}
+		if !i.IsUint64() || v.OverflowUint(i.Uint64()) {
+			return v, ExceptionNewf(OverflowError, "Python int can't be converted to %s", t)
+		}
+		v.SetUint(i.Uint64())
+	case reflect.Float32, reflect.Float64:
+		f, err := FloatAsFloat64(o)
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		switch x := o.(type) {
+		case Complex:
+			v.SetComplex(complex128(x))
+		default:
+			f, err := FloatAsFloat64(o)
+			if err != nil {
+				return v, err
+			}
+			v.SetComplex(complex(f, 0))
+		}
+	case reflect.String:
+		s, ok := o.(String)
+		if !ok {
+			return v, goTypeError(o, t)
+		}
+		v.SetString(string(s))
+	case reflect.Slice:
+		if o == None {
+			return v, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			if b, ok := o.(Bytes); ok {
+				v.SetBytes(append([]byte(nil), b...))
+				return v.Convert(t), nil
+			}
+		}
+		items, err := SequenceList(o)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.MakeSlice(t, len(items.Items), len(items.Items)))
+		for i, item := range items.Items {
+			itemValue, err := UnwrapGoValue(item, t.Elem())
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(itemValue)
+		}
+	case reflect.Map:
+		if o == None {
+			return v, nil
+		}
+		d, err := AsStringDict(o)
+		if err != nil || t.Key().Kind() != reflect.String {
+			return v, goTypeError(o, t)
+		}
+		v.Set(reflect.MakeMapWithSize(t, len(d)))
+		for key, item := range d {
+			itemValue, err := UnwrapGoValue(item, t.Elem())
+			if err != nil {
+				return v, err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), itemValue)
+		}
+	case reflect.Ptr, reflect.Func:
+		if o == None {
+			return v, nil
+		}
+		return v, goTypeError(o, t)
+	default:
+		return v, goTypeError(o, t)
+	}
+	return v, nil
+}
+
+// Converts o into an interface{} value
+func unwrapGoInterface(o Object, t reflect.Type) (reflect.Value, error) {
+	var x interface{}
+	switch o := o.(type) {
+	case NoneType:
+		return reflect.Zero(t), nil
+	case Bool:
+		x = bool(o)
+	case Int:
+		x = int(o)
+	case Float:
+		x = float64(o)
+	case String:
+		x = string(o)
+	case *GoValue:
+		x = o.Value.Interface()
+	default:
+		x = o
+	}
+	return reflect.ValueOf(&x).Elem(), nil
+}
+
+// GoInterface converts the python Object o into its natural Go value
+//
+// This is the reverse of WrapGoValue for when the Go type isn't
+// known. None is nil, bools, ints, floats, complex numbers, strings
+// and bytes become the corresponding Go types with ints too big for
+// an int becoming *big.Int. Lists and tuples become []interface{} and
+// dicts with string keys become map[string]interface{}, with their
+// contents converted. GoValue objects give the pointer they wrap and
+// other objects are returned as they are.
+func GoInterface(o Object) (interface{}, error) {
+	switch x := o.(type) {
+	case NoneType:
+		return nil, nil
+	case Bool:
+		return bool(x), nil
+	case Int:
+		return int(x), nil
+	case *BigInt:
+		if i, ok := x.MaybeInt().(Int); ok {
+			return int(i), nil
+		}
+		return new(big.Int).Set((*big.Int)(x)), nil
+	case Float:
+		return float64(x), nil
+	case Complex:
+		return complex128(x), nil
+	case String:
+		return string(x), nil
+	case Bytes:
+		return append([]byte(nil), x...), nil
+	case *List:
+		return goInterfaces(x.Items)
+	case Tuple:
+		return goInterfaces(x)
+	case StringDict, *Dict:
+		d, err := AsStringDict(x)
+		if err != nil {
+			// Keys which aren't strings can't be map[string] keys
+			return o, nil
+		}
+		m := make(map[string]interface{}, len(d))
+		for key, value := range d {
+			v, err := GoInterface(value)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	case *GoValue:
+		return x.Value.Interface(), nil
+	}
+	return o, nil
+}
+
+// Converts each of items with GoInterface
+func goInterfaces(items []Object) (interface{}, error) {
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		v, err := GoInterface(item)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// Converts o to a big.Int for conversion to the Go integer type t
+func goBigInt(o Object, t reflect.Type) (*big.Int, error) {
+	if _, ok := o.(Bool); !ok {
+		if i, ok := ConvertToBigInt(o); ok {
+			return (*big.Int)(i), nil
+		}
+	}
+	return nil, goTypeError(o, t)
+}
+
+// Returns the TypeError for when o can't be converted to t
+func goTypeError(o Object, t reflect.Type) error {
+	return ExceptionNewf(TypeError, "can't convert '%s' object to Go %s", o.Type().Name, t)
+}
+
+// Check interface is satisfied
+var _ Object = (*GoValue)(nil)
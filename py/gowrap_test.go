@@ -0,0 +1,264 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrapGoValue(t *testing.T) {
+	type inner struct{ A int }
+	for _, test := range []struct {
+		in   interface{}
+		want Object
+	}{
+		{nil, None},
+		{true, True},
+		{int8(-3), Int(-3)},
+		{uint16(7), Int(7)},
+		{2.5, Float(2.5)},
+		{complex(1, 2), Complex(complex(1, 2))},
+		{"hello", String("hello")},
+		{[]byte("hi"), Bytes("hi")},
+		{[]int{1, 2}, NewListFromItems([]Object{Int(1), Int(2)})},
+		{[2]string{"a", "b"}, NewListFromItems([]Object{String("a"), String("b")})},
+		{map[string]int{"a": 1}, StringDict{"a": Int(1)}},
+		{[]int(nil), None},
+		{(*inner)(nil), None},
+		{Int(5), Int(5)},
+	} {
+		got, err := WrapGoValue(test.in)
+		if err != nil {
+			t.Errorf("%#v: error %v", test.in, err)
+			continue
+		}
+		eq, err := Eq(got, test.want)
+		if err != nil || eq != True {
+			t.Errorf("%#v: want %v got %v", test.in, test.want, got)
+		}
+	}
+
+	big, err := WrapGoValue(uint64(math.MaxUint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := big.(*BigInt); !ok {
+		t.Errorf("want BigInt got %T", big)
+	}
+
+	_, err = WrapGoValue(make(chan int))
+	if !IsException(TypeError, err) {
+		t.Errorf("want TypeError got %v", err)
+	}
+
+	// Struct values are copied, pointers are shared
+	v := inner{A: 1}
+	obj, err := WrapGoValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = SetAttrString(obj, "A", Int(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 1 {
+		t.Errorf("struct value not copied")
+	}
+	obj, err = WrapGoValue(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = SetAttrString(obj, "A", Int(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 3 {
+		t.Errorf("struct pointer not shared")
+	}
+	if obj.Type() != NewGoType(reflect.TypeOf(v)) || obj.Type().Name != "inner" {
+		t.Errorf("wrong type %v", obj.Type())
+	}
+}
+
+func TestUnwrapGoValue(t *testing.T) {
+	for _, test := range []struct {
+		in   Object
+		t    reflect.Type
+		want interface{}
+	}{
+		{True, reflect.TypeOf(false), true},
+		{Int(-3), reflect.TypeOf(int8(0)), int8(-3)},
+		{Int(7), reflect.TypeOf(uint(0)), uint(7)},
+		{Int(2), reflect.TypeOf(0.0), 2.0},
+		{Float(2.5), reflect.TypeOf(float32(0)), float32(2.5)},
+		{String("hi"), reflect.TypeOf(""), "hi"},
+		{Bytes("hi"), reflect.TypeOf([]byte(nil)), []byte("hi")},
+		{NewListFromItems([]Object{Int(1), Int(2)}), reflect.TypeOf([]int(nil)), []int{1, 2}},
+		{Tuple{String("a")}, reflect.TypeOf([]string(nil)), []string{"a"}},
+		{StringDict{"a": Int(1)}, reflect.TypeOf(map[string]int(nil)), map[string]int{"a": 1}},
+		{None, reflect.TypeOf([]int(nil)), []int(nil)},
+		{Int(1), reflect.TypeOf((*interface{})(nil)).Elem(), 1},
+		{String("x"), reflect.TypeOf((*interface{})(nil)).Elem(), "x"},
+		{Int(1), reflect.TypeOf((*Object)(nil)).Elem(), Int(1)},
+	} {
+		got, err := UnwrapGoValue(test.in, test.t)
+		if err != nil {
+			t.Errorf("%v to %v: error %v", test.in, test.t, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Interface(), test.want) {
+			t.Errorf("%v to %v: want %#v got %#v", test.in, test.t, test.want, got.Interface())
+		}
+	}
+
+	for _, test := range []struct {
+		in      Object
+		t       reflect.Type
+		wantErr *Type
+	}{
+		{Int(1), reflect.TypeOf(""), TypeError},
+		{True, reflect.TypeOf(0), TypeError},
+		{Int(300), reflect.TypeOf(int8(0)), OverflowError},
+		{Int(-1), reflect.TypeOf(uint(0)), OverflowError},
+		{String("x"), reflect.TypeOf(0.0), TypeError},
+		{NewListFromItems([]Object{String("a")}), reflect.TypeOf([]int(nil)), TypeError},
+	} {
+		_, err := UnwrapGoValue(test.in, test.t)
+		if !IsException(test.wantErr, err) {
+			t.Errorf("%v to %v: want %s got %v", test.in, test.t, test.wantErr.Name, err)
+		}
+	}
+}
+
+func TestNewGoFunc(t *testing.T) {
+	div := MustNewGoFunc("div", "", func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, ExceptionNewf(ZeroDivisionError, "division by zero")
+		}
+		return a / b, nil
+	})
+	if div.Doc != "div(int, int) (int, error)" {
+		t.Errorf("doc: got %q", div.Doc)
+	}
+	got, err := Call(div, Tuple{Int(7), Int(2)}, nil)
+	if err != nil || got != Int(3) {
+		t.Errorf("div(7, 2): want 3 got %v, %v", got, err)
+	}
+	_, err = Call(div, Tuple{Int(7), Int(0)}, nil)
+	if !IsException(ZeroDivisionError, err) {
+		t.Errorf("div(7, 0): want ZeroDivisionError got %v", err)
+	}
+	_, err = Call(div, Tuple{Int(7)}, nil)
+	if !IsException(TypeError, err) {
+		t.Errorf("div(7): want TypeError got %v", err)
+	}
+	_, err = Call(div, Tuple{Int(7), Int(1)}, StringDict{"x": Int(1)})
+	if !IsException(TypeError, err) {
+		t.Errorf("div with keywords: want TypeError got %v", err)
+	}
+
+	join := MustNewGoFunc("join", "join(*words) -> str", func(words ...string) string {
+		return strings.Join(words, " ")
+	})
+	got, err = Call(join, Tuple{String("a"), String("b")}, nil)
+	if err != nil || got != String("a b") {
+		t.Errorf("join: want 'a b' got %v, %v", got, err)
+	}
+	if join.Doc != "join(*words) -> str" {
+		t.Errorf("doc: got %q", join.Doc)
+	}
+
+	native := MustNewGoFunc("native", "", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return Int(len(args) + len(kwargs)), nil
+	})
+	got, err = Call(native, Tuple{None}, StringDict{"a": None})
+	if err != nil || got != Int(2) {
+		t.Errorf("native: want 2 got %v, %v", got, err)
+	}
+
+	_, err = NewGoFunc("bad", "", 42)
+	if !IsException(TypeError, err) {
+		t.Errorf("not a function: want TypeError got %v", err)
+	}
+}
+
+func TestRegisterModuleFunc(t *testing.T) {
+	RegisterModuleFunc("gowrap_test", func(m *Module) error {
+		m.SetDoc("A test module")
+		err := m.AddFunc("hello", "", func(name string) string {
+			return "Hello " + name
+		})
+		if err != nil {
+			return err
+		}
+		return m.AddValue("answers", []int{42})
+	})
+	defer delete(moduleImpls, "gowrap_test")
+	RegisterModuleFunc("gowrap_test_fails", func(m *Module) error {
+		return ExceptionNewf(ValueError, "failed")
+	})
+	defer delete(moduleImpls, "gowrap_test_fails")
+
+	ctx := NewContext()
+	m, err := ctx.GetModule("gowrap_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Doc != "A test module" || m.Globals["__doc__"] != String("A test module") {
+		t.Errorf("doc not set: %q", m.Doc)
+	}
+	got, err := m.Call("hello", Tuple{String("world")}, nil)
+	if err != nil || got != String("Hello world") {
+		t.Errorf("hello: want 'Hello world' got %v, %v", got, err)
+	}
+	answers, ok := m.Globals["answers"].(*List)
+	if !ok || len(answers.Items) != 1 || answers.Items[0] != Int(42) {
+		t.Errorf("answers: got %v", m.Globals["answers"])
+	}
+
+	_, err = ctx.GetModule("gowrap_test_fails")
+	if !IsException(ValueError, err) {
+		t.Errorf("want ValueError got %v", err)
+	}
+	if _, ok := ctx.Modules["gowrap_test_fails"]; ok {
+		t.Errorf("failed module left in modules")
+	}
+}
+
+func TestGoInterface(t *testing.T) {
+	type inner struct{ A int }
+	p := &inner{A: 1}
+	d := NewDict()
+	if err := d.Set(Int(1), None); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		in   Object
+		want interface{}
+	}{
+		{None, nil},
+		{True, true},
+		{Int(-3), -3},
+		{Float(2.5), 2.5},
+		{String("a"), "a"},
+		{Bytes("hi"), []byte("hi")},
+		{Tuple{Int(1), NewListFromItems([]Object{String("b")})}, []interface{}{1, []interface{}{"b"}}},
+		{StringDict{"a": Int(1)}, map[string]interface{}{"a": 1}},
+		{&GoValue{Value: reflect.ValueOf(p)}, p},
+		{d, d},
+	} {
+		got, err := GoInterface(test.in)
+		if err != nil {
+			t.Errorf("%v: error %v", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: want %#v got %#v", test.in, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Hashing
+//
+// Numeric types use the same hash algorithm as CPython, so numbers
+// which compare equal have the same hash whatever their type.  For
+// example hash(2) == hash(2.0) == hash(2+0j).
+//
+// For a rational number x = m/n the hash is m * inverse(n) modulo
+// the prime P = 2**61 - 1.
+//
+// Strings and bytes are hashed with SipHash-1-3 as CPython does.  The
+// key is set from the PYTHONHASHSEED environment variable: "random"
+// picks a random key, so hashes differ between runs, and an integer
+// makes the same key as CPython does for it.  Unlike CPython the key
+// is 0, which is the same as PYTHONHASHSEED=0, if it isn't set.
+
+package py
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"log"
+	"math"
+	"math/big"
+	"math/bits"
+	"os"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	HashBits    = 61
+	HashModulus = (1 << HashBits) - 1 // a Mersenne prime
+	HashInf     = 314159
+	HashNan     = 0
+	HashImag    = 1000003
+	HashCutoff  = 0 // strings shorter than this aren't hashed with SipHash
+)
+
+var bigHashModulus = big.NewInt(HashModulus)
+
+// HashRandomization is set if strings and bytes are hashed with a key
+// from PYTHONHASHSEED other than 0
+var HashRandomization bool
+
+// The key strings and bytes are hashed with
+var hashKey = hashKeyFromEnv()
+
+// Returns the key for SipHash set by PYTHONHASHSEED
+func hashKeyFromEnv() [2]uint64 {
+	key, random, err := hashKeyForSeed(os.Getenv("PYTHONHASHSEED"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	HashRandomization = random
+	return key
+}
+
+// Returns the key for SipHash for the value of PYTHONHASHSEED and
+// whether it isn't 0
+func hashKeyForSeed(seed string) (key [2]uint64, random bool, err error) {
+	var secret [24]byte
+	switch seed {
+	case "", "0":
+	case "random":
+		_, err = rand.Read(secret[:])
+		if err != nil {
+			return key, false, err
+		}
+		random = true
+	default:
+		x, err := strconv.ParseUint(seed, 10, 32)
+		if err != nil {
+			return key, false, errors.New("PYTHONHASHSEED must be \"random\" or an integer in range [0; 4294967295]")
+		}
+		// Fill the secret the same way CPython does
+		for i := range secret {
+			x = (x*214013 + 2531011) & 0xFFFFFFFF
+			secret[i] = byte(x >> 16)
+		}
+		random = true
+	}
+	key[0] = binary.LittleEndian.Uint64(secret[0:8])
+	key[1] = binary.LittleEndian.Uint64(secret[8:16])
+	return key, random, nil
+}
+
+// Hash returns the hash of the object
+//
+// Unhashable objects return a TypeError
+func Hash(a Object) (int64, error) {
+	if A, ok := a.(I__hash__); ok {
+		res, err := A.M__hash__()
+		if err != nil {
+			return 0, err
+		}
+		return hashResult(res)
+	}
+	// Look for __hash__ in the type, which is None for unhashable
+	// types and classes which define __eq__ but not __hash__
+	switch fn := a.Type().Lookup("__hash__"); fn {
+	case nil, objectHashMethod:
+		return HashPointer(a)
+	case None:
+		return 0, unhashable(a)
+	default:
+		res, err := Call(fn, Tuple{a}, nil)
+		if err != nil {
+			return 0, err
+		}
+		return hashResult(res)
+	}
+}
+
+// objectHashMethod is object.__hash__ which hashes the identity of
+// the object
+var objectHashMethod = MustNewMethod("__hash__", func(self Object, args Tuple) (Object, error) {
+	// Read from the class, as in object.__hash__(obj), the object
+	// is the argument
+	if self == None && len(args) == 1 {
+		self = args[0]
+	} else if len(args) != 0 {
+		return nil, ExceptionNewf(TypeError, "__hash__() takes no arguments (%d given)", len(args))
+	}
+	h, err := HashPointer(self)
+	if err != nil {
+		return nil, err
+	}
+	return Int(h), nil
+}, 0, "Return hash(self).")
+
+func init() {
+	ObjectType.Dict["__hash__"] = objectHashMethod
+	// Mutable types can't be hashed
+	for _, t := range []*Type{ListType, ByteArrayType, DictType, DictKeysType, DictItemsType, SetType, SliceType} {
+		t.Dict["__hash__"] = None
+	}
+}
+
+// Returns a TypeError for the unhashable object
+func unhashable(a Object) error {
+	return ExceptionNewf(TypeError, "unhashable type: '%s'", a.Type().Name)
+}
+
+// Converts the result of __hash__ into an int64
+func hashResult(res Object) (int64, error) {
+	switch x := res.(type) {
+	case Bool:
+		if x {
+			return 1, nil
+		}
+		return 0, nil
+	case Int:
+		if x == -1 {
+			return -2, nil
+		}
+		return int64(x), nil
+	case *BigInt:
+		// Reduce big results to a hash of the right size the
+		// same way an int would be
+		return hashBigInt((*big.Int)(x)), nil
+	}
+	return 0, ExceptionNewf(TypeError, "__hash__ method should return an integer")
+}
+
+// HashPointer returns a hash of the identity of the object
+func HashPointer(a Object) (int64, error) {
+	v := reflect.ValueOf(a)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		p := uint64(v.Pointer())
+		// bottom 3 or 4 bits are likely to be 0; rotate them
+		// away to avoid excessive hash collisions
+		h := int64(p>>4 | p<<60)
+		if h == -1 {
+			h = -2
+		}
+		return h, nil
+	}
+	return 0, unhashable(a)
+}
+
+// Returns the hash of an int64
+func hashInt64(x int64) int64 {
+	negative := x < 0
+	u := uint64(x)
+	if negative {
+		u = -u
+	}
+	h := int64(u % HashModulus)
+	if negative {
+		h = -h
+	}
+	if h == -1 {
+		h = -2
+	}
+	return h
+}
+
+// Returns the hash of a big.Int
+func hashBigInt(x *big.Int) int64 {
+	if x.IsInt64() {
+		return hashInt64(x.Int64())
+	}
+	m := new(big.Int).Abs(x)
+	m.Mod(m, bigHashModulus)
+	h := m.Int64()
+	if x.Sign() < 0 {
+		h = -h
+	}
+	if h == -1 {
+		h = -2
+	}
+	return h
+}
+
+// Returns the hash of a float64
+func hashFloat64(v float64) int64 {
+	if math.IsInf(v, 0) {
+		if v > 0 {
+			return HashInf
+		}
+		return -HashInf
+	}
+	if math.IsNaN(v) {
+		return HashNan
+	}
+	m, e := math.Frexp(v)
+	sign := int64(1)
+	if m < 0 {
+		sign = -1
+		m = -m
+	}
+	// Process 28 bits at a time, which is a little cheaper than
+	// doing the multiplication which fits in a uint64
+	var x uint64
+	for m != 0 {
+		x = ((x << 28) & HashModulus) | x>>(HashBits-28)
+		m *= 268435456.0 // 2**28
+		e -= 28
+		y := uint64(m)
+		m -= float64(y)
+		x += y
+		if x >= HashModulus {
+			x -= HashModulus
+		}
+	}
+	// Adjust for the exponent; first reduce it modulo HashBits
+	if e >= 0 {
+		e = e % HashBits
+	} else {
+		e = HashBits - 1 - ((-1 - e) % HashBits)
+	}
+	x = ((x << uint(e)) & HashModulus) | x>>uint(HashBits-e)
+	h := int64(x) * sign
+	if h == -1 {
+		h = -2
+	}
+	return h
+}
+
+// HashRat returns the hash of the rational number num/den where den > 0
+func HashRat(num, den *big.Int) int64 {
+	// To find the inverse of den modulo P use Fermat's Little
+	// Theorem: den**(P-2) % P
+	dinv := new(big.Int).Exp(den, big.NewInt(HashModulus-2), bigHashModulus)
+	var h int64
+	if dinv.Sign() == 0 {
+		h = HashInf
+	} else {
+		m := new(big.Int).Abs(num)
+		m.Mod(m, bigHashModulus)
+		m.Mul(m, dinv)
+		m.Mod(m, bigHashModulus)
+		h = m.Int64()
+	}
+	if num.Sign() < 0 {
+		h = -h
+	}
+	if h == -1 {
+		h = -2
+	}
+	return h
+}
+
+// Returns the hash of a string of bytes
+func hashBytes(b []byte) int64 {
+	if len(b) == 0 {
+		return 0
+	}
+	h := int64(sipHash13(hashKey[0], hashKey[1], b))
+	if h == -1 {
+		h = -2
+	}
+	return h
+}
+
+// Returns the hash of a string
+//
+// CPython hashes the characters of a string stored in 1, 2 or 4 bytes
+// each, whichever fits the largest of them, so they are converted to
+// that to give the same hashes.
+func hashString(s string) int64 {
+	width := 1
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			width = 0
+			break
+		}
+	}
+	if width == 1 {
+		return hashBytes([]byte(s))
+	}
+	var max rune
+	for _, c := range s {
+		if c > max {
+			max = c
+		}
+	}
+	switch {
+	case max < 0x100:
+		width = 1
+	case max < 0x10000:
+		width = 2
+	default:
+		width = 4
+	}
+	b := make([]byte, 0, width*len(s))
+	for _, c := range s {
+		switch width {
+		case 1:
+			b = append(b, byte(c))
+		case 2:
+			b = append(b, byte(c), byte(c>>8))
+		default:
+			b = append(b, byte(c), byte(c>>8), byte(c>>16), byte(c>>24))
+		}
+	}
+	return hashBytes(b)
+}
+
+// One round of SipHash
+func sipRound(v0, v1, v2, v3 uint64) (uint64, uint64, uint64, uint64) {
+	v0 += v1
+	v2 += v3
+	v1 = bits.RotateLeft64(v1, 13) ^ v0
+	v3 = bits.RotateLeft64(v3, 16) ^ v2
+	v0 = bits.RotateLeft64(v0, 32)
+	v2 += v1
+	v0 += v3
+	v1 = bits.RotateLeft64(v1, 17) ^ v2
+	v3 = bits.RotateLeft64(v3, 21) ^ v0
+	v2 = bits.RotateLeft64(v2, 32)
+	return v0, v1, v2, v3
+}
+
+// Returns the SipHash-1-3 of b with the key k0, k1 as CPython
+// calculates it
+func sipHash13(k0, k1 uint64, b []byte) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+	last := uint64(len(b)) << 56
+	for ; len(b) >= 8; b = b[8:] {
+		m := binary.LittleEndian.Uint64(b)
+		v3 ^= m
+		v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+		v0 ^= m
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		last |= uint64(b[i]) << (8 * uint(i))
+	}
+	v3 ^= last
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0 ^= last
+	v2 ^= 0xff
+	for i := 0; i < 3; i++ {
+		v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	}
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+// Constants for hashing tuples with the xxHash based algorithm
+// CPython uses
+const (
+	xxPrime1 = 11400714785074694791
+	xxPrime2 = 14029467366897019727
+	xxPrime5 = 2870177450012600261
+)
+
+// Returns the hash of the items of a tuple
+func hashTuple(items Tuple) (int64, error) {
+	acc := uint64(xxPrime5)
+	for _, item := range items {
+		lane, err := Hash(item)
+		if err != nil {
+			return 0, err
+		}
+		acc += uint64(lane) * xxPrime2
+		acc = bits.RotateLeft64(acc, 31)
+		acc *= xxPrime1
+	}
+	// Add the length, mangled to keep the hash of () the same as
+	// it always was
+	acc += uint64(len(items)) ^ (xxPrime5 ^ 3527539)
+	h := int64(acc)
+	if h == -1 {
+		h = 1546275796
+	}
+	return h, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import "testing"
+
+func TestHashSeed(t *testing.T) {
+	// Hashes from CPython run with PYTHONHASHSEED set to seed
+	for _, test := range []struct {
+		seed string
+		in   string
+		want int64
+	}{
+		{"0", "hello", -2096571579003691106},
+		{"0", "abcdefghi", -532774252720507163},
+		{"0", "\xff\xff\xff\xff\xff\xff\xff\xff\xff", -2129700217430894956},
+		{"", "hello", -2096571579003691106},
+		{"42", "hello", 841626496250501200},
+		{"42", "abcdefghi", -5970266004662334337},
+		{"42", "\xff\xff\xff\xff\xff\xff\xff\xff\xff", 9107322900498779704},
+	} {
+		key, random, err := hashKeyForSeed(test.seed)
+		if err != nil {
+			t.Fatalf("seed %q: %v", test.seed, err)
+		}
+		if random != (test.seed != "0" && test.seed != "") {
+			t.Errorf("seed %q: random = %v", test.seed, random)
+		}
+		got := int64(sipHash13(key[0], key[1], []byte(test.in)))
+		if got != test.want {
+			t.Errorf("seed %q: hash(%q) want %d got %d", test.seed, test.in, test.want, got)
+		}
+	}
+
+	a, _, err := hashKeyForSeed("random")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, _ := hashKeyForSeed("random")
+	if a == b {
+		t.Errorf("random keys are the same: %v", a)
+	}
+	for _, seed := range []string{"-1", "4294967296", "x"} {
+		if _, _, err := hashKeyForSeed(seed); err == nil {
+			t.Errorf("seed %q: no error", seed)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Hash table shared by Set and Dict
+//
+// Entries are kept in insertion order in a slice with an index from
+// the python hash of the key to the positions of the entries with that
+// hash.  Deleted entries are marked with a nil key and compacted away
+// when they make up more than half of the slice.
+
+package py
+
+// An entry in the hash table along with the hash of its key
+type hashEntry struct {
+	hash  int64
+	key   Object // nil if the entry has been deleted
+	value Object
+}
+
+type hashTable struct {
+	entries []hashEntry     // entries in insertion order
+	index   map[int64][]int // hash to indexes in entries
+	used    int             // number of live entries
+}
+
+// Makes a new hash table with capacity for n entries
+func newHashTable(n int) hashTable {
+	return hashTable{
+		entries: make([]hashEntry, 0, n),
+		index:   make(map[int64][]int, n),
+	}
+}
+
+// Finds key in the table returning its index in entries or -1 if not
+// found along with its hash
+//
+// Returns a TypeError if the key is unhashable
+func (t *hashTable) find(key Object) (int, int64, error) {
+	h, err := Hash(key)
+	if err != nil {
+		return -1, 0, err
+	}
+	for _, i := range t.index[h] {
+		eq, err := Eq(t.entries[i].key, key)
+		if err != nil {
+			return -1, 0, err
+		}
+		if eq == True {
+			return i, h, nil
+		}
+	}
+	return -1, h, nil
+}
+
+// Appends a new entry which is known not to be in the table
+func (t *hashTable) insert(h int64, key, value Object) {
+	if t.index == nil {
+		t.index = make(map[int64][]int)
+	}
+	t.index[h] = append(t.index[h], len(t.entries))
+	t.entries = append(t.entries, hashEntry{hash: h, key: key, value: value})
+	t.used++
+}
+
+// Removes the entry at index i which has hash h
+func (t *hashTable) remove(i int, h int64) {
+	bucket := t.index[h]
+	for j, k := range bucket {
+		if k == i {
+			bucket = append(bucket[:j], bucket[j+1:]...)
+			break
+		}
+	}
+	if len(bucket) == 0 {
+		delete(t.index, h)
+	} else {
+		t.index[h] = bucket
+	}
+	t.entries[i] = hashEntry{}
+	t.used--
+	// Compact the entries when they are mostly deleted
+	if t.used < len(t.entries)/2 {
+		t.rebuild()
+	}
+}
+
+// Rebuilds the entries and index removing deleted entries
+func (t *hashTable) rebuild() {
+	entries := make([]hashEntry, 0, t.used)
+	index := make(map[int64][]int, t.used)
+	for _, e := range t.entries {
+		if e.key != nil {
+			index[e.hash] = append(index[e.hash], len(entries))
+			entries = append(entries, e)
+		}
+	}
+	t.entries = entries
+	t.index = index
+}
+
+// Removes all the entries
+func (t *hashTable) clear() {
+	t.entries = nil
+	t.index = make(map[int64][]int)
+	t.used = 0
+}
+
+// Returns the index of the last live entry or -1 if there are none
+func (t *hashTable) last() int {
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		if t.entries[i].key != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns a compacted copy of the table
+func (t *hashTable) copy() hashTable {
+	c := newHashTable(t.used)
+	for _, e := range t.entries {
+		if e.key != nil {
+			c.insert(e.hash, e.key, e.value)
+		}
+	}
+	return c
+}
+
+// Returns the keys in insertion order
+func (t *hashTable) keys() []Object {
+	keys := make([]Object, 0, t.used)
+	for _, e := range t.entries {
+		if e.key != nil {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
@@ -7,18 +7,16 @@
 package py
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-var (
-	// This will become sys.path one day ;-)
-	modulePath = []string{"", "/usr/lib/python3.4", "/usr/local/lib/python3.4/dist-packages", "/usr/lib/python3/dist-packages"}
-)
-
 // The workings of __import__
 //
 // __import__(name, globals=None, locals=None, fromlist=(), level=0)
@@ -79,83 +77,646 @@ var (
 // Changed in version 3.3: Negative values for level are no longer
 // supported (which also changes the default value to 0).
 func ImportModuleLevelObject(name string, globals, locals StringDict, fromlist Tuple, level int) (Object, error) {
-	// Module already loaded - return that
-	if module, ok := modules[name]; ok {
-		return module, nil
+	ctx := CurrentContext
+
+	absName := name
+	if level > 0 {
+		var err error
+		absName, err = resolveName(name, globals, level)
+		if err != nil {
+			return nil, err
+		}
+	} else if level < 0 {
+		return nil, ExceptionNewf(ValueError, "level must be >= 0")
+	}
+	if absName == "" {
+		return nil, ExceptionNewf(ValueError, "Empty module name")
+	}
+	if err := ctx.checkImport(absName); err != nil {
+		return nil, err
 	}
 
-	if level != 0 {
-		return nil, ExceptionNewf(SystemError, "Relative import not supported yet")
+	module, ok := ctx.Modules[absName]
+	if ok && module == None {
+		return nil, ExceptionNewf(ImportError, "import of %s halted; None in sys.modules", absName)
+	}
+	if !ok {
+		var err error
+		module, err = ctx.findAndLoad(absName)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	parts := strings.Split(name, ".")
-	pathParts := path.Join(parts...)
+	if len(fromlist) == 0 {
+		// Return the top level package or, for a relative
+		// import, the package name is relative to
+		if level == 0 {
+			return ctx.Modules[strings.SplitN(absName, ".", 2)[0]], nil
+		}
+		if name == "" {
+			return module, nil
+		}
+		cutOff := len(name) - len(strings.SplitN(name, ".", 2)[0])
+		return ctx.Modules[absName[:len(absName)-cutOff]], nil
+	}
 
-	for _, mpath := range modulePath {
-		if mpath == "" {
-			mpathObj, ok := globals["__file__"]
-			if !ok {
-				var err error
-				mpath, err = os.Getwd()
-				if err != nil {
-					return nil, err
-				}
+	// Import any submodules in the fromlist of a package
+	if _, err := GetAttrString(module, "__path__"); err == nil {
+		err = ctx.handleFromlist(module, fromlist, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return module, nil
+}
+
+// Works out the absolute name of the module name imported with level
+// leading dots from the module with globals
+func resolveName(name string, globals StringDict, level int) (string, error) {
+	var pkg string
+	if pkgObj, ok := globals["__package__"]; ok && pkgObj != None {
+		pkgStr, ok := pkgObj.(String)
+		if !ok {
+			return "", ExceptionNewf(TypeError, "package must be a string")
+		}
+		pkg = string(pkgStr)
+	} else if nameObj, ok := globals["__name__"].(String); ok {
+		// A package is its own parent
+		pkg = string(nameObj)
+		if _, ok := globals["__path__"]; !ok {
+			if i := strings.LastIndexByte(pkg, '.'); i >= 0 {
+				pkg = pkg[:i]
 			} else {
-				mpath = path.Dir(string(mpathObj.(String)))
+				pkg = ""
 			}
 		}
-		fullPath := path.Join(mpath, pathParts)
-		// FIXME Read pyc/pyo too
-		fullPath, err := filepath.Abs(fullPath)
-		if err != nil {
-			continue
-		}
-		if fi, err := os.Stat(fullPath); err == nil && fi.IsDir() {
-			// FIXME this is a massive simplification!
-			fullPath = path.Join(fullPath, "__init__.py")
-		} else {
-			fullPath += ".py"
+	}
+	if pkg == "" {
+		return "", ExceptionNewf(ImportError, "attempted relative import with no known parent package")
+	}
+	bits := strings.Split(pkg, ".")
+	if len(bits) < level {
+		return "", ExceptionNewf(ImportError, "attempted relative import beyond top-level package")
+	}
+	base := strings.Join(bits[:len(bits)-level+1], ".")
+	if name == "" {
+		return base, nil
+	}
+	return base + "." + name, nil
+}
+
+// Returns sys.path, which may have been replaced by another list
+func (ctx *Context) sysPath() *List {
+	if sys, ok := ctx.Modules["sys"].(*Module); ok {
+		if path, ok := sys.Globals["path"].(*List); ok {
+			return path
 		}
-		// Check if file exists
-		if _, err := os.Stat(fullPath); err == nil {
-			str, err := ioutil.ReadFile(fullPath)
-			if err != nil {
-				return nil, ExceptionNewf(OSError, "Couldn't read %q: %v", fullPath, err)
+	}
+	return ctx.Path
+}
+
+// Returns the absolute directory named by an entry of a module
+// search path.
+//
+// As sys.path[0] is the directory of the script being run, "" means
+// the directory of the file of the __main__ module, or the current
+// directory if it hasn't got one.
+func (ctx *Context) pathDir(entry string) (string, error) {
+	if entry == "" {
+		if main, ok := ctx.Modules["__main__"].(*Module); ok {
+			if file, ok := main.Globals["__file__"].(String); ok {
+				return filepath.Abs(filepath.Dir(string(file)))
 			}
-			codeObj, err := Compile(string(str), fullPath, "exec", 0, true)
+		}
+		return os.Getwd()
+	}
+	return filepath.Abs(entry)
+}
+
+// Imports the module with the absolute name, importing its parent
+// packages first
+func (ctx *Context) findAndLoad(name string) (Object, error) {
+	err := ctx.Audit("import", String(name), None, ctx.sysPath(), None, None)
+	if err != nil {
+		return nil, err
+	}
+	var parent Object
+	var parentName, childName string
+	var path Object
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		parentName, childName = name[:i], name[i+1:]
+		var ok bool
+		parent, ok = ctx.Modules[parentName]
+		if !ok {
+			parent, err = ctx.findAndLoad(parentName)
 			if err != nil {
 				return nil, err
 			}
-			code, ok := codeObj.(*Code)
-			if !ok {
-				return nil, ExceptionNewf(ImportError, "Compile didn't return code object")
+		}
+		// Importing the parent may have imported the module
+		if module, ok := ctx.Modules[name]; ok {
+			return module, nil
+		}
+		if impl, ok := moduleImpls[name]; ok {
+			return ctx.initSubmodule(impl, parent, childName)
+		}
+		path, err = GetAttrString(parent, "__path__")
+		if err != nil {
+			return nil, ExceptionNewf(ImportError, "No module named '%s'; '%s' is not a package", name, parentName)
+		}
+	} else {
+		if impl, ok := moduleImpls[name]; ok {
+			return ctx.initModule(impl)
+		}
+		path = ctx.sysPath()
+	}
+
+	spec, err := ctx.findSpec(name, path)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		return nil, ExceptionNewf(ImportError, "No module named '%s'", name)
+	}
+	module, err := ctx.loadSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if parent != nil {
+		_, err = SetAttrString(parent, childName, module)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return module, nil
+}
+
+// Makes the built in module impl which is a submodule of parent,
+// setting it as the attribute childName of parent as importing it
+// from a package would
+func (ctx *Context) initSubmodule(impl *ModuleImpl, parent Object, childName string) (Object, error) {
+	module, err := ctx.initModule(impl)
+	if err != nil {
+		return nil, err
+	}
+	_, err = SetAttrString(parent, childName, module)
+	if err != nil {
+		return nil, err
+	}
+	return module, nil
+}
+
+// Where to load a module from
+type moduleSpec struct {
+	name string   // absolute name of the module
+	fsys ModuleFS // the file system the source is in
+	src  string   // the name of the source in fsys or "" for a namespace package
+	file string   // the path of the source for __file__
+	path []string // the directories of a package's submodules
+}
+
+// Looks for the module called name in the entries of path returning
+// nil if it isn't found.
+//
+// In each entry a package with an __init__.py is found first, then a
+// source file.  If neither can be found anywhere the directories with
+// the name of the module which don't have an __init__.py make up a
+// namespace package.
+func (ctx *Context) findSpec(name string, path Object) (*moduleSpec, error) {
+	base := name[strings.LastIndexByte(name, '.')+1:]
+	var spec *moduleSpec
+	var portions []string
+	err := Iterate(path, func(item Object) bool {
+		entry, ok := item.(String)
+		if !ok {
+			return false
+		}
+		fsys, root := ctx.importer(string(entry))
+		if fsys == nil {
+			return false
+		}
+		if fsys.IsDir(base) {
+			init := base + "/__init__.py"
+			if fsys.IsFile(init) {
+				spec = &moduleSpec{
+					name: name,
+					fsys: fsys,
+					src:  init,
+					file: joinRoot(fsys, root, init),
+					path: []string{joinRoot(fsys, root, base)},
+				}
+				return true
 			}
-			module := NewModule(name, "", nil, nil)
-			_, err = VmRun(module.Globals, module.Globals, code, nil)
-			if err != nil {
-				return nil, err
+			portions = append(portions, joinRoot(fsys, root, base))
+		}
+		if src := base + ".py"; fsys.IsFile(src) {
+			spec = &moduleSpec{
+				name: name,
+				fsys: fsys,
+				src:  src,
+				file: joinRoot(fsys, root, src),
 			}
-			module.Globals["__file__"] = String(fullPath)
-			return module, nil
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil && len(portions) > 0 {
+		spec = &moduleSpec{name: name, path: portions}
+	}
+	return spec, nil
+}
+
+// Returns whether path is a directory
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// Returns whether path is a file
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Makes the module described by spec, adding it to sys.modules
+// before running its code so that modules it imports can import it
+// in turn
+func (ctx *Context) loadSpec(spec *moduleSpec) (Object, error) {
+	module := ctx.NewModule(spec.name, "", nil, nil)
+	if spec.path != nil {
+		path := NewListSized(len(spec.path))
+		for i, dir := range spec.path {
+			path.Items[i] = String(dir)
+		}
+		module.Globals["__path__"] = path
+		module.Globals["__package__"] = String(spec.name)
+	} else if i := strings.LastIndexByte(spec.name, '.'); i >= 0 {
+		module.Globals["__package__"] = String(spec.name[:i])
+	} else {
+		module.Globals["__package__"] = String("")
+	}
+	if spec.src == "" {
+		return module, nil
+	}
+	module.Globals["__file__"] = String(spec.file)
+	code, err := ctx.specCode(spec, module)
+	if err == nil {
+		err = ctx.Audit("exec", code)
+	}
+	if err != nil {
+		delete(ctx.Modules, spec.name)
+		return nil, err
+	}
+	_, err = VmRun(module.Globals, module.Globals, code, nil)
+	if err != nil {
+		delete(ctx.Modules, spec.name)
+		return nil, err
+	}
+	// The module may have replaced itself in sys.modules
+	if obj, ok := ctx.Modules[spec.name]; ok {
+		return obj, nil
+	}
+	return nil, ExceptionNewf(ImportError, "Loaded module %s not found in sys.modules", spec.name)
+}
+
+// Returns the code of the module described by spec, setting
+// __cached__ in module if it is cached
+func (ctx *Context) specCode(spec *moduleSpec, module *Module) (*Code, error) {
+	if _, ok := spec.fsys.(osDir); ok {
+		if MarshalCode != nil {
+			module.Globals["__cached__"] = String(CachePath(spec.file))
 		}
+		return ctx.sourceCode(spec.file)
 	}
-	return nil, ExceptionNewf(ImportError, "No module named '%s'", name)
+	return compileSource(spec.fsys, spec.src, spec.file)
+}
 
-	// Convert to absolute path if relative
-	// Use __file__ from globals to work out what we are relative to
+// RunModuleAsMain runs the module called name as the __main__ module,
+// as the -m option does.  It is found as import would find it, after
+// importing its parent packages, and a package runs its __main__
+// submodule.  sys.argv[0] is set to the path of its file before it
+// runs.
+//
+// The module is returned with any error its code raised, or nil with
+// an ImportError if it couldn't be found.
+func RunModuleAsMain(name string) (*Module, error) {
+	ctx := CurrentContext
+	spec, err := ctx.findMainSpec(name)
+	if err != nil {
+		return nil, err
+	}
+	module := ctx.NewModule("__main__", "", nil, nil)
+	module.Globals["__file__"] = String(spec.file)
+	pkg := ""
+	if i := strings.LastIndexByte(spec.name, '.'); i >= 0 {
+		pkg = spec.name[:i]
+	}
+	module.Globals["__package__"] = String(pkg)
+	if sys, ok := ctx.Modules["sys"].(*Module); ok {
+		if argv, ok := sys.Globals["argv"].(*List); ok && len(argv.Items) > 0 {
+			argv.Items[0] = String(spec.file)
+		}
+	}
+	code, err := ctx.specCode(spec, module)
+	if err != nil {
+		return module, err
+	}
+	_, err = VmRun(module.Globals, module.Globals, code, nil)
+	return module, err
+}
 
-	// '' in path seems to mean use the current __file__
+// Finds the source of the module called name to run as the __main__
+// module, importing its parent packages
+func (ctx *Context) findMainSpec(name string) (*moduleSpec, error) {
+	var path Object = ctx.sysPath()
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		parentName := name[:i]
+		parent, err := ctx.importAbsolute(parentName)
+		if err != nil {
+			return nil, err
+		}
+		path, err = GetAttrString(parent, "__path__")
+		if err != nil {
+			return nil, ExceptionNewf(ImportError, "No module named '%s'; '%s' is not a package", name, parentName)
+		}
+	}
+	if _, ok := moduleImpls[name]; ok {
+		return nil, ExceptionNewf(ImportError, "No code object available for %s", name)
+	}
+	spec, err := ctx.findSpec(name, path)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		return nil, ExceptionNewf(ImportError, "No module named %s", name)
+	}
+	if spec.path == nil {
+		return spec, nil
+	}
+	// A package is run by running its __main__ submodule
+	if strings.HasSuffix(name, ".__main__") {
+		return nil, ExceptionNewf(ImportError, "Cannot use package as __main__ module")
+	}
+	pkg, err := ctx.importAbsolute(name)
+	if err != nil {
+		return nil, err
+	}
+	path, err = GetAttrString(pkg, "__path__")
+	if err != nil {
+		return nil, err
+	}
+	spec, err = ctx.findSpec(name+".__main__", path)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil || spec.path != nil {
+		return nil, ExceptionNewf(ImportError, "No module named %s.__main__; '%s' is a package and cannot be directly executed", name, name)
+	}
+	return spec, nil
+}
+
+// Returns the module with the absolute name, importing it if it
+// hasn't been already
+func (ctx *Context) importAbsolute(name string) (Object, error) {
+	if module, ok := ctx.Modules[name]; ok {
+		return module, nil
+	}
+	return ctx.findAndLoad(name)
+}
+
+// CacheTag is in the names of the .pyc files the importer writes
+const CacheTag = "gpython-34"
+
+// PycMagic starts the .pyc files the importer writes.  It must be
+// changed whenever the bytecode changes so that old caches aren't
+// used.
+const PycMagic = 3411 | '\r'<<16 | '\n'<<24
+
+// The header of a .pyc file
+type pycHeader struct {
+	Magic uint32
+	Mtime uint32 // modification time of the source
+	Size  uint32 // size of the source
+}
+
+// Returns the header of the .pyc file for a source file
+func newPycHeader(fi os.FileInfo) pycHeader {
+	return pycHeader{
+		Magic: PycMagic,
+		Mtime: uint32(fi.ModTime().Unix()),
+		Size:  uint32(fi.Size()),
+	}
+}
+
+// Optimize is the optimization level set by the -O option.  At 1 the
+// compiler leaves out assert statements and makes __debug__ false,
+// and at 2 it leaves out docstrings as well.
+var Optimize int
+
+// CachePath returns the path of the .pyc file in __pycache__ which
+// caches the code of the source file
+//
+// Code compiled with an optimization level is cached separately.
+func CachePath(source string) string {
+	dir, file := filepath.Split(source)
+	tag := CacheTag
+	if Optimize > 0 {
+		tag += ".opt-" + strconv.Itoa(Optimize)
+	}
+	return filepath.Join(dir, "__pycache__", strings.TrimSuffix(file, ".py")+"."+tag+".pyc")
+}
 
-	// Find a valid path which we need to check for the correct __init__.py in subdirectories etc
+// Returns whether sys.dont_write_bytecode is set
+func (ctx *Context) dontWriteBytecode() bool {
+	if sys, ok := ctx.Modules["sys"].(*Module); ok {
+		if flag, ok := sys.Globals["dont_write_bytecode"]; ok {
+			return ObjectIsTrue(flag)
+		}
+	}
+	return false
+}
 
-	// Look for .py and .pyc files
+// Returns the code of the source file.
+//
+// The code is loaded from the .pyc file in __pycache__ if it was made
+// from a source file with the same modification time and size.
+// Otherwise the source is compiled and cached there unless
+// sys.dont_write_bytecode is set.
+func (ctx *Context) sourceCode(file string) (*Code, error) {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, ExceptionNewf(OSError, "Couldn't stat %q: %v", file, err)
+	}
+	header := newPycHeader(fi)
+	cache := CachePath(file)
+	if UnmarshalCode != nil {
+		if code := readPyc(cache, header); code != nil {
+			return code, nil
+		}
+	}
+	code, err := compileSource(osDir(filepath.Dir(file)), filepath.Base(file), file)
+	if err != nil {
+		return nil, err
+	}
+	if MarshalCode != nil && !ctx.dontWriteBytecode() {
+		// Failing to write the cache doesn't stop the import
+		_ = writePyc(cache, header, code)
+	}
+	return code, nil
+}
 
-	// Make absolute module path too if we can for sys.modules
+// Compiles the source src in fsys whose path is file
+func compileSource(fsys ModuleFS, src, file string) (*Code, error) {
+	str, err := fsys.ReadFile(src)
+	if err != nil {
+		return nil, ExceptionNewf(OSError, "Couldn't read %q: %v", file, err)
+	}
+	codeObj, err := Compile(string(str), file, "exec", 0, true)
+	if err != nil {
+		return nil, err
+	}
+	code, ok := codeObj.(*Code)
+	if !ok {
+		return nil, ExceptionNewf(ImportError, "Compile didn't return code object")
+	}
+	return code, nil
+}
 
-	//How do we uniquely identify modules?
+// Reads the code from the .pyc file returning nil if it can't be read
+// or doesn't have the header
+func readPyc(path string, header pycHeader) *Code {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var got pycHeader
+	if binary.Read(r, binary.LittleEndian, &got) != nil || got != header {
+		return nil
+	}
+	code, err := UnmarshalCode(r)
+	if err != nil {
+		return nil
+	}
+	return code
+}
 
-	// SystemError: Parent module '' not loaded, cannot perform relative import
+// Writes the code to the .pyc file with the header, making the
+// __pycache__ directory if necessary.
+//
+// The file is written under a temporary name then renamed so that a
+// partly written file is never read.
+func writePyc(path string, header pycHeader, code *Code) error {
+	var buf bytes.Buffer
+	err := binary.Write(&buf, binary.LittleEndian, header)
+	if err != nil {
+		return err
+	}
+	err = MarshalCode(&buf, code)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(buf.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
 
+// Imports the submodules named in the fromlist of a package which
+// aren't attributes of it already.  "*" imports the submodules named
+// in __all__, which recursive is set for.
+func (ctx *Context) handleFromlist(module Object, fromlist Tuple, recursive bool) error {
+	nameObj, err := GetAttrString(module, "__name__")
+	if err != nil {
+		return err
+	}
+	pkgName, ok := nameObj.(String)
+	if !ok {
+		return ExceptionNewf(TypeError, "module __name__ must be a string")
+	}
+	for _, item := range fromlist {
+		name, ok := item.(String)
+		if !ok {
+			where := "fromlist"
+			if recursive {
+				where = string(pkgName) + ".__all__"
+			}
+			return ExceptionNewf(TypeError, "Item in %s must be str, not %s", where, item.Type().Name)
+		}
+		if name == "*" {
+			all, err := GetAttrString(module, "__all__")
+			if err != nil {
+				continue
+			}
+			items, err := SequenceTuple(all)
+			if err != nil {
+				return err
+			}
+			err = ctx.handleFromlist(module, items, true)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := GetAttrString(module, string(name)); err == nil {
+			continue
+		}
+		subName := string(pkgName) + "." + string(name)
+		if _, ok := ctx.Modules[subName]; ok {
+			continue
+		}
+		if err := ctx.checkImport(subName); err != nil {
+			return err
+		}
+		if impl, ok := moduleImpls[subName]; ok {
+			if _, err := ctx.initSubmodule(impl, module, string(name)); err != nil {
+				return err
+			}
+			continue
+		}
+		path, err := GetAttrString(module, "__path__")
+		if err != nil {
+			return err
+		}
+		spec, err := ctx.findSpec(subName, path)
+		if err != nil {
+			return err
+		}
+		if spec == nil {
+			// IMPORT_FROM will raise the error if it is needed
+			continue
+		}
+		sub, err := ctx.loadSpec(spec)
+		if err != nil {
+			return err
+		}
+		_, err = SetAttrString(module, string(name), sub)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Straight port of the python code
@@ -176,6 +737,13 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 	var name string
 	var err error
 
+	// this should be the frozen module importlib/_bootstrap.py
+	// generated by Modules/_freeze_importlib.c into Python/importlib.h
+	importlib, err := GetModule("importlib")
+	if err != nil {
+		return nil, err
+	}
+
 	// Make sure to use default values so as to not have
 	// PyObject_CallMethodObjArgs() truncate the parameter list because of a
 	// nil argument.
@@ -184,8 +752,9 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 	} else {
 		// Only have to care what given_globals is if it will be used
 		// for something.
-		globals, ok = given_globals.(StringDict)
-		if level > 0 && !ok {
+		var globalsErr error
+		globals, globalsErr = AsStringDict(given_globals)
+		if level > 0 && globalsErr != nil {
 			return nil, ExceptionNewf(TypeError, "globals must be a dict")
 		}
 	}
@@ -239,7 +808,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 			}
 		}
 
-		if _, ok = modules[string(Package)]; !ok {
+		if _, ok = CurrentContext.Modules[string(Package)]; !ok {
 			return nil, ExceptionNewf(SystemError, "Parent module %q not loaded, cannot perform relative import", Package)
 		}
 	} else { // level == 0 */
@@ -277,13 +846,13 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 	// From this point forward, goto error_with_unlock!
 	builtins_import, ok = globals["__import__"]
 	if !ok {
-		builtins_import, ok = Builtins.Globals["__import__"]
+		builtins_import, ok = CurrentContext.Builtins().Globals["__import__"]
 		if !ok {
 			return nil, ExceptionNewf(ImportError, "__import__ not found")
 		}
 	}
 
-	mod, ok = modules[abs_name]
+	mod, ok = CurrentContext.Modules[abs_name]
 	if mod == None {
 		return nil, ExceptionNewf(ImportError, "import of %q halted; None in sys.modules", abs_name)
 	} else if ok {
@@ -306,7 +875,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 		}
 		if initializing {
 			// _bootstrap._lock_unlock_module() releases the import lock */
-			value, err = Importlib.Call("_lock_unlock_module", Tuple{String(abs_name)}, nil)
+			value, err = importlib.Call("_lock_unlock_module", Tuple{String(abs_name)}, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -318,7 +887,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 		}
 	} else {
 		// _bootstrap._find_and_load() releases the import lock
-		mod, err = Importlib.Call("_find_and_load", Tuple{String(abs_name), builtins_import}, nil)
+		mod, err = importlib.Call("_find_and_load", Tuple{String(abs_name), builtins_import}, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -345,7 +914,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 				cut_off := len(name) - len(front)
 				abs_name_len := len(abs_name)
 				to_return := abs_name[:abs_name_len-cut_off]
-				final_mod, ok = modules[to_return]
+				final_mod, ok = CurrentContext.Modules[to_return]
 				if !ok {
 					return nil, ExceptionNewf(KeyError, "%q not in sys.modules as expected", to_return)
 				}
@@ -354,7 +923,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 			final_mod = mod
 		}
 	} else {
-		final_mod, err = Importlib.Call("_handle_fromlist", Tuple{mod, fromlist, builtins_import}, nil)
+		final_mod, err = importlib.Call("_handle_fromlist", Tuple{mod, fromlist, builtins_import}, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -391,5 +960,7 @@ func BuiltinImport(self Object, args Tuple, kwargs StringDict, currentGlobal Str
 	if fromlist == None {
 		fromlist = Tuple{}
 	}
-	return ImportModuleLevelObject(string(name.(String)), globals.(StringDict), locals.(StringDict), fromlist.(Tuple), int(level.(Int)))
+	globalsDict, _ := AsStringDict(globals)
+	localsDict, _ := AsStringDict(locals)
+	return ImportModuleLevelObject(string(name.(String)), globalsDict, localsDict, fromlist.(Tuple), int(level.(Int)))
 }
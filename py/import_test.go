@@ -0,0 +1,231 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py_test
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/go-python/gpython/compile"
+	_ "github.com/go-python/gpython/marshal"
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/vm"
+)
+
+// Imports name in a new context with the path entries, returning the
+// value of X in the module
+func importX(t *testing.T, name string, entries ...string) py.Object {
+	ctx := py.NewContext()
+	ctx.Path = py.NewListSized(len(entries))
+	for i, entry := range entries {
+		ctx.Path.Items[i] = py.String(entry)
+	}
+	var module py.Object
+	var err error
+	py.RunInContext(ctx, func() {
+		module, err = py.ImportModuleLevelObject(name, nil, nil, py.Tuple{py.String("X")}, 0)
+	})
+	if err != nil {
+		t.Fatalf("import %s failed: %v", name, err)
+	}
+	x, err := py.GetAttrString(module, "X")
+	if err != nil {
+		t.Fatalf("%s has no X: %v", name, err)
+	}
+	return x
+}
+
+func TestZipImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpython-zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	archive := filepath.Join(dir, "lib.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, src := range map[string]string{
+		"zipmod.py":            "X = 1\n",
+		"zippkg/__init__.py":   "from .sub import X\n",
+		"zippkg/sub.py":        "X = 2\n",
+		"lib/inner/deep.py":    "X = __file__\n",
+		"zipns/nsmod.py":       "X = __name__\n",
+		"zippkg/data/notpy.md": "",
+	} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = fw.Write([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := importX(t, "zipmod", archive); got != py.Int(1) {
+		t.Errorf("zipmod: want 1 got %v", got)
+	}
+	if got := importX(t, "zippkg", archive); got != py.Int(2) {
+		t.Errorf("zippkg: want 2 got %v", got)
+	}
+	if got := importX(t, "zippkg.sub", archive); got != py.Int(2) {
+		t.Errorf("zippkg.sub: want 2 got %v", got)
+	}
+	if got := importX(t, "zipns.nsmod", archive); got != py.String("zipns.nsmod") {
+		t.Errorf("zipns.nsmod: want zipns.nsmod got %v", got)
+	}
+	// A directory in the archive can be on the path
+	want := py.String(archive + "/lib/inner/deep.py")
+	if got := importX(t, "inner.deep", archive+"/lib"); got != want {
+		t.Errorf("inner.deep: want %v got %v", want, got)
+	}
+}
+
+func TestBuiltinSubmodule(t *testing.T) {
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "builtinpkg",
+		Globals: py.StringDict{"X": py.Int(1)},
+	})
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "builtinpkg.sub",
+		Globals: py.StringDict{"X": py.Int(2)},
+	})
+	if got := importX(t, "builtinpkg.sub"); got != py.Int(2) {
+		t.Errorf("builtinpkg.sub: want 2 got %v", got)
+	}
+	// from builtinpkg import sub
+	ctx := py.NewContext()
+	var module py.Object
+	var err error
+	py.RunInContext(ctx, func() {
+		module, err = py.ImportModuleLevelObject("builtinpkg", nil, nil, py.Tuple{py.String("sub")}, 0)
+	})
+	if err != nil {
+		t.Fatalf("from builtinpkg import sub failed: %v", err)
+	}
+	sub, err := py.GetAttrString(module, "sub")
+	if err != nil {
+		t.Fatalf("builtinpkg has no sub: %v", err)
+	}
+	if sub != ctx.Modules["builtinpkg.sub"] {
+		t.Errorf("builtinpkg.sub: want %v got %v", ctx.Modules["builtinpkg.sub"], sub)
+	}
+}
+
+func TestRunModuleAsMain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpython-main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, src := range map[string]string{
+		"script.py":          "X = __name__\n",
+		"pkg/__init__.py":    "",
+		"pkg/__main__.py":    "X = __package__\n",
+		"pkg/mod.py":         "X = __file__\n",
+		"nomain/__init__.py": "",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(name string) (py.Object, error) {
+		ctx := py.NewContext()
+		ctx.Path = py.NewListSized(1)
+		ctx.Path.Items[0] = py.String(dir)
+		var module *py.Module
+		var err error
+		py.RunInContext(ctx, func() {
+			module, err = py.RunModuleAsMain(name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ctx.Modules["__main__"] != module {
+			t.Errorf("%s: not __main__", name)
+		}
+		return module.Globals["X"], nil
+	}
+	for _, test := range []struct {
+		name string
+		want py.Object
+	}{
+		{"script", py.String("__main__")},
+		{"pkg", py.String("pkg")},
+		{"pkg.mod", py.String(filepath.Join(dir, "pkg", "mod.py"))},
+	} {
+		got, err := run(test.name)
+		if err != nil {
+			t.Errorf("%s: error %v", test.name, err)
+		} else if got != test.want {
+			t.Errorf("%s: want %v got %v", test.name, test.want, got)
+		}
+	}
+	for _, name := range []string{"missing", "nomain", "sys"} {
+		if _, err := run(name); !py.IsException(py.ImportError, err) {
+			t.Errorf("%s: want ImportError got %v", name, err)
+		}
+	}
+}
+
+func TestPycCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpython-pyc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := `def f(a, /, b):
+    return a, b
+try:
+    f(a=1, b=2)
+    rejected = False
+except TypeError:
+    rejected = True
+X = (f, rejected)
+`
+	err = ioutil.WriteFile(filepath.Join(dir, "posonly.py"), []byte(src), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first import writes the .pyc file and the second reads it
+	var codes []*py.Code
+	for _, run := range []string{"compiled", "cached"} {
+		x := importX(t, "posonly", dir).(py.Tuple)
+		if x[1] != py.True {
+			t.Errorf("%s: f(a=1, b=2) wasn't rejected", run)
+		}
+		codes = append(codes, x[0].(*py.Function).Code)
+	}
+	cache := py.CachePath(filepath.Join(dir, "posonly.py"))
+	if _, err := os.Stat(cache); err != nil {
+		t.Fatalf("no .pyc file written: %v", err)
+	}
+	if codes[0] == codes[1] {
+		t.Fatal("code not reloaded")
+	}
+	if codes[1].Posonlyargcount != 1 {
+		t.Errorf("want Posonlyargcount 1 got %d", codes[1].Posonlyargcount)
+	}
+	if len(codes[0].Positions) == 0 || !reflect.DeepEqual(codes[0].Positions, codes[1].Positions) {
+		t.Errorf("want Positions %v got %v", codes[0].Positions, codes[1].Positions)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Where modules are imported from
+//
+// Each entry of sys.path names a ModuleFS to look for modules in.
+// The path hooks are asked for the ModuleFS of an entry first, so an
+// entry can be a zip archive or a file system added by the Go program
+// with AddModuleFS.  Any other entry is a directory.
+
+package py
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ModuleFS is a file system which modules can be imported from
+//
+// The names passed to its methods are slash separated paths relative
+// to its root.
+type ModuleFS interface {
+	// IsDir returns whether name is a directory
+	IsDir(name string) bool
+	// IsFile returns whether name is a file
+	IsFile(name string) bool
+	// ReadFile returns the contents of the file name
+	ReadFile(name string) ([]byte, error)
+}
+
+// PathHook returns the ModuleFS for the modules of a sys.path entry,
+// or nil if it doesn't handle the entry
+type PathHook func(entry string) ModuleFS
+
+// The path hooks in the order they are tried
+var pathHooks []PathHook
+
+// RegisterPathHook adds a hook which is asked for the ModuleFS of
+// sys.path entries which the hooks registered before it don't handle
+func RegisterPathHook(hook PathHook) {
+	pathHooks = append(pathHooks, hook)
+}
+
+// Returns the ModuleFS for the sys.path entry and the path of its root
+// for the __file__ and __path__ of modules imported from it, or nil
+// if the entry can't be used
+func (ctx *Context) importer(entry string) (ModuleFS, string) {
+	if fsys, ok := ctx.importers[entry]; ok {
+		return fsys, entry
+	}
+	for _, hook := range pathHooks {
+		if fsys := hook(entry); fsys != nil {
+			if ctx.importers == nil {
+				ctx.importers = make(map[string]ModuleFS)
+			}
+			ctx.importers[entry] = fsys
+			return fsys, entry
+		}
+	}
+	dir, err := ctx.pathDir(entry)
+	if err != nil {
+		return nil, ""
+	}
+	return osDir(dir), dir
+}
+
+// Returns the path of name in a ModuleFS whose root is root
+func joinRoot(fsys ModuleFS, root, name string) string {
+	if _, ok := fsys.(osDir); ok {
+		return filepath.Join(root, filepath.FromSlash(name))
+	}
+	return root + "/" + name
+}
+
+// A directory of the operating system
+type osDir string
+
+func (dir osDir) path(name string) string {
+	return filepath.Join(string(dir), filepath.FromSlash(name))
+}
+
+func (dir osDir) IsDir(name string) bool {
+	return isDir(dir.path(name))
+}
+
+func (dir osDir) IsFile(name string) bool {
+	return isFile(dir.path(name))
+}
+
+func (dir osDir) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(dir.path(name))
+}
+
+// A directory of a ModuleFS
+type subFS struct {
+	fsys ModuleFS
+	dir  string
+}
+
+// SubModuleFS returns the ModuleFS of the directory dir of fsys
+func SubModuleFS(fsys ModuleFS, dir string) ModuleFS {
+	if dir == "" || dir == "." {
+		return fsys
+	}
+	return subFS{fsys: fsys, dir: dir}
+}
+
+func (s subFS) IsDir(name string) bool {
+	return s.fsys.IsDir(path.Join(s.dir, name))
+}
+
+func (s subFS) IsFile(name string) bool {
+	return s.fsys.IsFile(path.Join(s.dir, name))
+}
+
+func (s subFS) ReadFile(name string) ([]byte, error) {
+	return s.fsys.ReadFile(path.Join(s.dir, name))
+}
+
+// The file systems added with AddModuleFS indexed by their sys.path
+// entries
+var moduleFSes = map[string]ModuleFS{}
+
+// AddModuleFS makes the modules in fsys importable by adding an entry
+// for it to sys.path of the CurrentContext.  It returns the entry,
+// which other contexts can add to their sys.path too.
+func AddModuleFS(fsys ModuleFS) string {
+	entry := fmt.Sprintf("<fs %d>", len(moduleFSes)+1)
+	moduleFSes[entry] = fsys
+	CurrentContext.sysPath().Append(String(entry))
+	return entry
+}
+
+// The path hook for the entries made by AddModuleFS and the
+// directories in them
+func moduleFSPathHook(entry string) ModuleFS {
+	name, dir := entry, ""
+	if i := strings.IndexByte(entry, '/'); i >= 0 {
+		name, dir = entry[:i], entry[i+1:]
+	}
+	fsys, ok := moduleFSes[name]
+	if !ok {
+		return nil
+	}
+	return SubModuleFS(fsys, dir)
+}
+
+func init() {
+	RegisterPathHook(moduleFSPathHook)
+}
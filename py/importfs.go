@@ -0,0 +1,55 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+// Import modules from an fs.FS
+
+package py
+
+import "io/fs"
+
+// AddFSImporter makes the modules in fsys importable by adding an
+// entry for it to sys.path of the CurrentContext, returning the
+// entry.
+//
+// This lets a Go program ship its python sources inside its binary,
+// for instance
+//
+//	//go:embed lib
+//	var lib embed.FS
+//
+//	sub, _ := fs.Sub(lib, "lib")
+//	py.AddFSImporter(sub)
+func AddFSImporter(fsys fs.FS) string {
+	return AddModuleFS(ioFS{fsys: fsys})
+}
+
+// A ModuleFS reading an fs.FS
+type ioFS struct {
+	fsys fs.FS
+}
+
+// Returns the fs.FS name of name
+func fsName(name string) string {
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (f ioFS) IsDir(name string) bool {
+	fi, err := fs.Stat(f.fsys, fsName(name))
+	return err == nil && fi.IsDir()
+}
+
+func (f ioFS) IsFile(name string) bool {
+	fi, err := fs.Stat(f.fsys, fsName(name))
+	return err == nil && !fi.IsDir()
+}
+
+func (f ioFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(f.fsys, fsName(name))
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package py_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-python/gpython/py"
+)
+
+func TestFSImporter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fsmod.py":          {Data: []byte("X = 1\n")},
+		"fspkg/__init__.py": {Data: []byte("from . import sub\nX = sub.X\n")},
+		"fspkg/sub.py":      {Data: []byte("X = __file__\n")},
+	}
+	var entry string
+	ctx := py.NewContext()
+	py.RunInContext(ctx, func() {
+		entry = py.AddFSImporter(fsys)
+	})
+	if n := len(ctx.Path.Items); ctx.Path.Items[n-1] != py.String(entry) {
+		t.Errorf("%q not added to sys.path %v", entry, ctx.Path)
+	}
+	if got := importX(t, "fsmod", entry); got != py.Int(1) {
+		t.Errorf("fsmod: want 1 got %v", got)
+	}
+	want := py.String(entry + "/fspkg/sub.py")
+	if got := importX(t, "fspkg", entry); got != want {
+		t.Errorf("fspkg: want %v got %v", want, got)
+	}
+	if got := importX(t, "fspkg.sub", entry); got != want {
+		t.Errorf("fspkg.sub: want %v got %v", want, got)
+	}
+}
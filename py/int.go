@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"math/bits"
 	"strconv"
 	"strings"
 )
@@ -46,6 +47,31 @@ const (
 	GoIntMin  = -GoIntMax - 1
 )
 
+// The range of the small ints kept in smallInts
+const (
+	smallIntMin = -5
+	smallIntMax = 256
+)
+
+// smallInts holds the small ints already converted to Objects.  Go
+// only avoids allocating memory when converting the ints 0..255 to
+// an interface.
+var smallInts [smallIntMax - smallIntMin + 1]Object
+
+func init() {
+	for i := range smallInts {
+		smallInts[i] = Int(i + smallIntMin)
+	}
+}
+
+// Returns i as an Object without allocating memory if it is small
+func intObject(i Int) Object {
+	if i >= smallIntMin && i <= smallIntMax {
+		return smallInts[i-smallIntMin]
+	}
+	return i
+}
+
 // Type of this Int object
 func (o Int) Type() *Type {
 	return IntType
@@ -71,9 +97,10 @@ func IntNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	}
 	// Special case converting string types
 	switch x := xObj.(type) {
-	// FIXME Bytearray
 	case Bytes:
 		return IntFromString(string(x), base)
+	case *ByteArray:
+		return IntFromString(string(x.Data), base)
 	case String:
 		return IntFromString(string(x), base)
 	}
@@ -254,7 +281,10 @@ func (a Int) M__pos__() (Object, error) {
 
 func (a Int) M__abs__() (Object, error) {
 	if a == IntMin {
-		// FIXME upconvert
+		// Upconvert overflowing case
+		r := big.NewInt(IntMin)
+		r.Neg(r)
+		return (*BigInt)(r), nil
 	}
 	if a < 0 {
 		return -a, nil
@@ -285,7 +315,7 @@ func intAdd(a, b Int) Object {
 			goto overflow
 		}
 	}
-	return Int(a + b)
+	return intObject(a + b)
 
 overflow:
 	aBig := big.NewInt(int64(a))
@@ -306,14 +336,14 @@ func intSub(a, b Int) Object {
 		}
 	} else {
 		// Overflow when a - b > IntMax
-		// a < IntMax + b
+		// a > IntMax + b
 		// IntMax + b can't overflow since
 		// IntMax=7FFF, b = -8000..-1, IntMax + b = -1..0x7FFE
-		if a < IntMax+b {
+		if a > IntMax+b {
 			goto overflow
 		}
 	}
-	return Int(a - b)
+	return intObject(a - b)
 
 overflow:
 	aBig := big.NewInt(int64(a))
@@ -334,7 +364,7 @@ func intMul(a, b Int) Object {
 	}
 	// A crude but effective test!
 	if absA <= sqrtIntMax && absB <= sqrtIntMax {
-		return Int(a * b)
+		return intObject(a * b)
 	}
 	aBig := big.NewInt(int64(a))
 	bBig := big.NewInt(int64(b))
@@ -406,15 +436,11 @@ func (a Int) M__imul__(other Object) (Object, error) {
 }
 
 func (a Int) M__truediv__(other Object) (Object, error) {
-	b, err := MakeFloat(other)
-	if err != nil {
-		return nil, err
+	fb, ok := convertToFloat(other)
+	if !ok {
+		return NotImplemented, nil
 	}
 	fa := Float(a)
-	if err != nil {
-		return nil, err
-	}
-	fb := b.(Float)
 	if fb == 0 {
 		return nil, divisionByZero
 	}
@@ -422,15 +448,11 @@ func (a Int) M__truediv__(other Object) (Object, error) {
 }
 
 func (a Int) M__rtruediv__(other Object) (Object, error) {
-	b, err := MakeFloat(other)
-	if err != nil {
-		return nil, err
+	fb, ok := convertToFloat(other)
+	if !ok {
+		return NotImplemented, nil
 	}
 	fa := Float(a)
-	if err != nil {
-		return nil, err
-	}
-	fb := b.(Float)
 	if fa == 0 {
 		return nil, divisionByZero
 	}
@@ -475,7 +497,10 @@ func (a Int) divMod(b Int) (Object, Object, error) {
 	if b == 0 {
 		return nil, nil, divisionByZero
 	}
-	// Can't overflow
+	if a == IntMin && b == -1 {
+		// Upconvert the only overflowing case
+		return (*BigInt)(big.NewInt(IntMin)).divMod((*BigInt)(big.NewInt(-1)))
+	}
 	result, remainder := Int(a/b), Int(a%b)
 	// Implement floor division
 	negativeResult := (a < 0)
@@ -486,7 +511,7 @@ func (a Int) divMod(b Int) (Object, Object, error) {
 		result -= 1
 		remainder += b
 	}
-	return result, remainder, nil
+	return intObject(result), intObject(remainder), nil
 }
 
 func (a Int) M__divmod__(other Object) (Object, Object, error) {
@@ -503,7 +528,36 @@ func (a Int) M__rdivmod__(other Object) (Object, Object, error) {
 	return NotImplemented, NotImplemented, nil
 }
 
+// Integer power a**b for b >= 0 with overflow detection
+func intPow(a, b Int) Object {
+	result, base, n := Int(1), a, b
+	for {
+		if n&1 != 0 {
+			r, ok := intMul(result, base).(Int)
+			if !ok {
+				break
+			}
+			result = r
+		}
+		n >>= 1
+		if n == 0 {
+			return result
+		}
+		r, ok := intMul(base, base).(Int)
+		if !ok {
+			break
+		}
+		base = r
+	}
+	return (*BigInt)(new(big.Int).Exp(big.NewInt(int64(a)), big.NewInt(int64(b)), nil)).MaybeInt()
+}
+
 func (a Int) M__pow__(other, modulus Object) (Object, error) {
+	if modulus == None {
+		if b, ok := convertToInt(other); ok && b >= 0 {
+			return intPow(a, b), nil
+		}
+	}
 	return (*BigInt)(big.NewInt(int64(a))).M__pow__(other, modulus)
 }
 
@@ -608,6 +662,10 @@ func (a Int) M__bool__() (Object, error) {
 	return NewBool(a != 0), nil
 }
 
+func (a Int) M__hash__() (Object, error) {
+	return Int(hashInt64(int64(a))), nil
+}
+
 func (a Int) M__index__() (Int, error) {
 	return a, nil
 }
@@ -631,6 +689,9 @@ func (a Int) M__complex__() (Object, error) {
 }
 
 func (a Int) M__round__(digits Object) (Object, error) {
+	if digits == None {
+		return a, nil
+	}
 	if b, ok := convertToInt(digits); ok {
 		if b >= 0 {
 			return a, nil
@@ -716,6 +777,207 @@ func (a Int) M__trunc__() (Object, error) {
 	return a, nil
 }
 
+// Parses the byteorder and signed arguments of to_bytes and from_bytes
+func intByteOrder(byteorder, signed Object) (little bool, isSigned bool, err error) {
+	switch byteorder {
+	case String("little"):
+		little = true
+	case String("big"):
+	default:
+		return false, false, ExceptionNewf(ValueError, "byteorder must be either 'little' or 'big'")
+	}
+	if signed != nil {
+		isSigned = ObjectIsTrue(signed)
+	}
+	return little, isSigned, nil
+}
+
+// Reverses b in place
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// Returns the bytes representing x in length bytes
+func intToBytes(x *big.Int, length int, little, signed bool) ([]byte, error) {
+	if x.Sign() < 0 && !signed {
+		return nil, ExceptionNewf(OverflowError, "can't convert negative int to unsigned")
+	}
+	nbits := 8 * length
+	tooBig := x.BitLen() > nbits
+	if signed && x.Sign() != 0 {
+		// Need one bit for the sign
+		m := x
+		if x.Sign() < 0 {
+			m = new(big.Int).Not(x)
+		}
+		tooBig = m.BitLen() >= nbits
+	}
+	if tooBig {
+		return nil, ExceptionNewf(OverflowError, "int too big to convert")
+	}
+	v := x
+	if x.Sign() < 0 {
+		// Two's complement
+		v = new(big.Int).Lsh(big.NewInt(1), uint(nbits))
+		v.Add(v, x)
+	}
+	out := make([]byte, length)
+	v.FillBytes(out)
+	if little {
+		reverseBytes(out)
+	}
+	return out, nil
+}
+
+// Returns the int represented by b
+func intFromBytes(b []byte, little, signed bool) Object {
+	buf := make([]byte, len(b))
+	copy(buf, b)
+	if little {
+		reverseBytes(buf)
+	}
+	x := new(big.Int).SetBytes(buf)
+	if signed && len(buf) > 0 && buf[0]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(buf))))
+	}
+	return (*BigInt)(x).MaybeInt()
+}
+
+// Returns self as a *big.Int
+func intSelf(self Object) (*big.Int, error) {
+	a, ok := ConvertToBigInt(self)
+	if !ok {
+		_, err := cantConvert(self, "int")
+		return nil, err
+	}
+	return (*big.Int)(a), nil
+}
+
+func init() {
+	for _, t := range []*Type{IntType, BigIntType} {
+		t.Dict["bit_length"] = MustNewMethod("bit_length", func(self Object) (Object, error) {
+			a, err := intSelf(self)
+			if err != nil {
+				return nil, err
+			}
+			return Int(a.BitLen()), nil
+		}, 0, `bit_length() -> int
+
+Number of bits necessary to represent self in binary.
+>>> bin(37)
+'0b100101'
+>>> (37).bit_length()
+6`)
+		t.Dict["bit_count"] = MustNewMethod("bit_count", func(self Object) (Object, error) {
+			a, err := intSelf(self)
+			if err != nil {
+				return nil, err
+			}
+			n := 0
+			for _, w := range new(big.Int).Abs(a).Bits() {
+				n += bits.OnesCount(uint(w))
+			}
+			return Int(n), nil
+		}, 0, `bit_count() -> int
+
+Number of ones in the binary representation of the absolute value of self.`)
+		t.Dict["to_bytes"] = MustNewMethod("to_bytes", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			var lengthObj Object = Int(1)
+			var byteorder Object = String("big")
+			var signed Object
+			err := ParseTupleAndKeywords(args, kwargs, "|OsO:to_bytes", []string{"length", "byteorder", "signed"}, &lengthObj, &byteorder, &signed)
+			if err != nil {
+				return nil, err
+			}
+			a, err := intSelf(self)
+			if err != nil {
+				return nil, err
+			}
+			length, err := IndexInt(lengthObj)
+			if err != nil {
+				return nil, err
+			}
+			if length < 0 {
+				return nil, ExceptionNewf(ValueError, "length argument must be non-negative")
+			}
+			little, isSigned, err := intByteOrder(byteorder, signed)
+			if err != nil {
+				return nil, err
+			}
+			b, err := intToBytes(a, length, little, isSigned)
+			if err != nil {
+				return nil, err
+			}
+			return Bytes(b), nil
+		}, 0, `to_bytes(length=1, byteorder='big', signed=False) -> bytes
+
+Return an array of bytes representing an integer.
+
+The integer is represented using length bytes.  An OverflowError is
+raised if the integer is not representable with the given number of
+bytes.  byteorder is either 'big' or 'little'.  If signed is True two's
+complement is used to represent negative integers.`)
+		t.Dict["from_bytes"] = &ClassMethod{
+			Callable: MustNewMethod("from_bytes", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+				var bytesObj Object
+				var byteorder Object = String("big")
+				var signed Object
+				err := ParseTupleAndKeywords(args, kwargs, "O|sO:from_bytes", []string{"bytes", "byteorder", "signed"}, &bytesObj, &byteorder, &signed)
+				if err != nil {
+					return nil, err
+				}
+				b, err := BytesFromObject(bytesObj)
+				if err != nil {
+					return nil, err
+				}
+				little, isSigned, err := intByteOrder(byteorder, signed)
+				if err != nil {
+					return nil, err
+				}
+				return intFromBytes(b, little, isSigned), nil
+			}, 0, `from_bytes(bytes, byteorder='big', signed=False) -> int
+
+Return the integer represented by the given array of bytes.
+
+The bytes argument must be a bytes-like object or an iterable producing
+bytes.  byteorder is either 'big' or 'little'.  If signed is True two's
+complement is used to represent negative integers.`),
+		}
+		t.Dict["conjugate"] = MustNewMethod("conjugate", func(self Object) (Object, error) {
+			return MakeInt(self)
+		}, 0, "conjugate() -> Returns self, the complex conjugate of any int.")
+		t.Dict["as_integer_ratio"] = MustNewMethod("as_integer_ratio", func(self Object) (Object, error) {
+			a, err := MakeInt(self)
+			if err != nil {
+				return nil, err
+			}
+			return Tuple{a, Int(1)}, nil
+		}, 0, "as_integer_ratio() -> Return a pair of integers whose ratio is equal to self, with a positive denominator.")
+		t.Dict["real"] = &Property{
+			Fget: func(self Object) (Object, error) {
+				return MakeInt(self)
+			},
+		}
+		t.Dict["imag"] = &Property{
+			Fget: func(self Object) (Object, error) {
+				return Int(0), nil
+			},
+		}
+		t.Dict["numerator"] = &Property{
+			Fget: func(self Object) (Object, error) {
+				return MakeInt(self)
+			},
+		}
+		t.Dict["denominator"] = &Property{
+			Fget: func(self Object) (Object, error) {
+				return Int(1), nil
+			},
+		}
+	}
+}
+
 // Check interface is satisfied
 var _ floatArithmetic = Int(0)
 var _ booleanArithmetic = Int(0)
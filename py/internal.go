@@ -42,6 +42,14 @@ func MakeBool(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__bool__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := res.(Bool); !ok {
+			return nil, ExceptionNewf(TypeError, "__bool__ should return bool, returned %s", res.Type().Name)
+		}
+		return res, nil
 	}
 
 	if B, ok := a.(I__len__); ok {
@@ -52,6 +60,11 @@ func MakeBool(a Object) (Object, error) {
 		if res != NotImplemented {
 			return MakeBool(res)
 		}
+	} else if res, ok, err := TypeCall0(a, "__len__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		return MakeBool(res)
 	}
 
 	return True, nil
@@ -168,6 +181,46 @@ func Not(a Object) (Object, error) {
 	return nil, ExceptionNewf(TypeError, "bool() didn't return True or False")
 }
 
+// IsAbstract reports whether obj is an abstract method, which is
+// when its __isabstractmethod__ attribute is true
+func IsAbstract(obj Object) (bool, error) {
+	res, err := GetAttrString(obj, "__isabstractmethod__")
+	if err != nil {
+		if IsException(AttributeError, err) {
+			return false, nil
+		}
+		return false, err
+	}
+	b, err := MakeBool(res)
+	if err != nil {
+		return false, err
+	}
+	return b == True, nil
+}
+
+// Is reports whether a and b are the same object, which is the python
+// is operator
+//
+// Objects such as Tuple and StringDict can't be compared with == so
+// they are the same if they share their memory.
+func Is(a, b Object) bool {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ta == nil || ta.Comparable() {
+		return a == b
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Slice:
+		return va.Len() == vb.Len() && (va.Len() == 0 || va.Pointer() == vb.Pointer())
+	case reflect.Map, reflect.Func:
+		return va.Pointer() == vb.Pointer()
+	}
+	return false
+}
+
 // Calls function fnObj with args and kwargs in a new vm (or directly
 // if Go code)
 //
@@ -189,6 +242,20 @@ func GetItem(self Object, key Object) (Object, error) {
 		return I.M__getitem__(key)
 	} else if res, ok, err := TypeCall1(self, "__getitem__", key); ok {
 		return res, err
+	} else if t, ok := self.(*Type); ok && t.Type().IsSubtype(TypeType) {
+		// Subscripting a class calls its __class_getitem__ (PEP
+		// 560), with type itself subscriptable as type[C]
+		if t == TypeType {
+			return NewGenericAlias(t, key)
+		}
+		if fn := t.Lookup("__class_getitem__"); fn != nil {
+			fn, err := descriptorGet(fn, None, t)
+			if err != nil {
+				return nil, err
+			}
+			return Call(fn, Tuple{key}, nil)
+		}
+		return nil, ExceptionNewf(TypeError, "type '%s' is not subscriptable", t.Name)
 	}
 	return nil, ExceptionNewf(TypeError, "'%s' object is not subscriptable", self.Type().Name)
 }
@@ -225,6 +292,35 @@ func GetAttrString(self Object, key string) (res Object, err error) {
 		return res, err
 	}
 
+	res, err = genericGetAttr(self, key)
+	if res != nil || err != nil {
+		return res, err
+	}
+
+	// And now only if not found call __getattr__
+	if I, ok := self.(I__getattr__); ok {
+		return I.M__getattr__(key)
+	} else if res, ok, err = TypeCall1(self, "__getattr__", Object(String(key))); ok {
+		return res, err
+	}
+
+	// Not found - return nil
+	return nil, ExceptionNewf(AttributeError, "'%s' has no attribute '%s'", self.Type().Name, key)
+}
+
+// GenericGetAttrString looks up key in self and its type without
+// calling any __getattribute__ or __getattr__ defined for it.  This
+// is object.__getattribute__.
+func GenericGetAttrString(self Object, key string) (Object, error) {
+	res, err := genericGetAttr(self, key)
+	if res == nil && err == nil {
+		err = ExceptionNewf(AttributeError, "'%s' has no attribute '%s'", self.Type().Name, key)
+	}
+	return res, err
+}
+
+// Looks up key in self and its type returning nil if not found
+func genericGetAttr(self Object, key string) (res Object, err error) {
 	// Look up any __special__ methods as M__special__ and return a bound method
 	if len(key) >= 5 && strings.HasPrefix(key, "__") && strings.HasSuffix(key, "__") {
 		objectValue := reflect.ValueOf(self)
@@ -234,35 +330,151 @@ func GetAttrString(self Object, key string) (res Object, err error) {
 		}
 	}
 
-	// Look in the instance dictionary if it exists
-	if I, ok := self.(IGetDict); ok {
-		dict := I.GetDict()
-		res, ok = dict[key]
-		if ok {
-			return res, err
+	// Data descriptors such as properties in the type take
+	// precedence over the instance dictionary
+	t := self.Type()
+	descr := t.NativeGetAttrOrNil(key)
+	if descr != nil && isDataDescriptor(descr) {
+		return descriptorGet(descr, self, t)
+	}
+
+	if cls, ok := self.(*Type); ok && t.IsSubtype(TypeType) {
+		// Look in the class and its bases, binding any
+		// descriptors found to the class
+		if res = cls.NativeGetAttrOrNil(key); res != nil {
+			return descriptorGet(res, None, cls)
+		}
+	} else if I, ok := self.(IGetDict); ok {
+		// Look in the instance dictionary if it exists
+		if res, ok = I.GetDict()[key]; ok {
+			return res, nil
 		}
 	}
 
-	// Now look in type's dictionary etc
-	t := self.Type()
-	res = t.NativeGetAttrOrNil(key)
-	if res != nil {
+	// Now use what was found in the type's dictionary etc
+	if descr != nil {
 		// Call __get__ which creates bound methods, reads properties etc
-		if I, ok := res.(I__get__); ok {
-			res, err = I.M__get__(self, t)
+		return descriptorGet(descr, self, t)
+	}
+	return nil, nil
+}
+
+// Number of types an AttrCache remembers
+const attrCacheSize = 4
+
+// AttrCache remembers what GetAttrString found in the types of the
+// objects at one place which looks up an attribute, so looking it up
+// again on an object of one of those types can skip the search
+// through the MRO.  The entries are valid until any type is
+// modified.
+//
+// The zero value is an empty cache.
+type AttrCache struct {
+	entries [attrCacheSize]attrCacheEntry
+	next    int // the entry to replace next
+}
+
+// What was found looking up an attribute in one type
+type attrCacheEntry struct {
+	t      *Type  // type the entry is for
+	isType bool   // whether the object was a *Type
+	epoch  uint64 // typeEpoch when the entry was filled
+	slow   bool   // set if GetAttrString must be used
+	descr  Object // the attribute found in the type or nil
+	isData bool   // set if descr is a data descriptor
+}
+
+// Returns the entry for looking up key on self, filling in a new one
+// if it isn't in the cache
+func (cache *AttrCache) lookup(self Object, t *Type, key string) *attrCacheEntry {
+	cls, isType := self.(*Type)
+	for i := range cache.entries {
+		entry := &cache.entries[i]
+		if entry.t == t && entry.isType == isType && entry.epoch == typeEpoch {
+			return entry
 		}
-		return res, err
 	}
+	entry := &cache.entries[cache.next]
+	cache.next = (cache.next + 1) % attrCacheSize
+	entry.t = t
+	entry.isType = isType
+	entry.epoch = typeEpoch
+	// Special methods, classes and types with __getattribute__
+	// aren't cached
+	entry.slow = (len(key) >= 5 && strings.HasPrefix(key, "__") && strings.HasSuffix(key, "__")) ||
+		(isType && (t.IsSubtype(TypeType) || cls.lookupSpecial("__getattribute__") != nil))
+	entry.descr = nil
+	entry.isData = false
+	if !entry.slow {
+		entry.descr = t.NativeGetAttrOrNil(key)
+		entry.isData = entry.descr != nil && isDataDescriptor(entry.descr)
+	}
+	return entry
+}
 
-	// And now only if not found call __getattr__
-	if I, ok := self.(I__getattr__); ok {
-		return I.M__getattr__(key)
-	} else if res, ok, err = TypeCall1(self, "__getattr__", Object(String(key))); ok {
-		return res, err
+// GetAttrStringCached is GetAttrString using cache to remember the
+// lookups in the types of the objects.  Each cache must only be used
+// to look up the same key.
+func GetAttrStringCached(self Object, key string, cache *AttrCache) (Object, error) {
+	t := self.Type()
+	entry := cache.lookup(self, t, key)
+	if entry.slow {
+		return GetAttrString(self, key)
+	}
+	if I, ok := self.(I__getattribute__); ok {
+		return I.M__getattribute__(key)
+	}
+	if entry.isData {
+		return descriptorGet(entry.descr, self, t)
+	}
+	if I, ok := self.(IGetDict); ok {
+		if res, ok := I.GetDict()[key]; ok {
+			return res, nil
+		}
+	}
+	if entry.descr != nil {
+		return descriptorGet(entry.descr, self, t)
 	}
+	// Not found so GetAttrString calls __getattr__ or raises
+	return GetAttrString(self, key)
+}
 
-	// Not found - return nil
-	return nil, ExceptionNewf(AttributeError, "'%s' has no attribute '%s'", self.Type().Name, key)
+// Notes that the attributes of self have changed if it is a class
+func classModified(self Object) {
+	if cls, ok := self.(*Type); ok && cls.Type().IsSubtype(TypeType) {
+		cls.Modified()
+	}
+}
+
+// Returns the method name of a descriptor defined by a python class
+// or nil if it doesn't have one
+func descriptorMethod(descr Object, name string) Object {
+	if _, ok := descr.(*Type); ok {
+		return descr.Type().NativeGetAttrOrNil(name)
+	}
+	return nil
+}
+
+// Returns whether descr is a data descriptor, ie it defines __set__
+// or __delete__, which overrides the instance dictionary
+func isDataDescriptor(descr Object) bool {
+	switch descr.(type) {
+	case I__set__, I__delete__:
+		return true
+	}
+	return descriptorMethod(descr, "__set__") != nil || descriptorMethod(descr, "__delete__") != nil
+}
+
+// Calls the __get__ method of descr if it has one, otherwise returns
+// descr unchanged
+func descriptorGet(descr, instance Object, owner *Type) (Object, error) {
+	if I, ok := descr.(I__get__); ok {
+		return I.M__get__(instance, owner)
+	}
+	if get := descriptorMethod(descr, "__get__"); get != nil {
+		return Call(get, Tuple{descr, instance, owner}, nil)
+	}
+	return descr, nil
 }
 
 // GetAttrErr - returns the result or an err to be raised if not found
@@ -278,6 +490,19 @@ func GetAttr(self Object, keyObj Object) (res Object, err error) {
 
 // SetAttrString
 func SetAttrString(self Object, key string, value Object) (Object, error) {
+	// If we have __setattr__ then use that
+	if I, ok := self.(I__setattr__); ok {
+		return I.M__setattr__(key, value)
+	} else if res, ok, err := TypeCall2(self, "__setattr__", String(key), value); ok {
+		return res, err
+	}
+	return GenericSetAttrString(self, key, value)
+}
+
+// GenericSetAttrString sets key in self without calling any
+// __setattr__ defined for it.  This is object.__setattr__.
+func GenericSetAttrString(self Object, key string, value Object) (Object, error) {
+	classModified(self)
 	// First look in type's dictionary etc for a property that could
 	// be set - do this before looking in the instance dictionary
 	setter := self.Type().NativeGetAttrOrNil(key)
@@ -286,24 +511,22 @@ func SetAttrString(self Object, key string, value Object) (Object, error) {
 		if I, ok := setter.(I__set__); ok {
 			return I.M__set__(self, value)
 		}
-	}
-
-	// If we have __setattr__ then use that
-	if I, ok := self.(I__setattr__); ok {
-		return I.M__setattr__(key, value)
-	} else if res, ok, err := TypeCall2(self, "__setattr__", String(key), value); ok {
-		return res, err
+		if set := descriptorMethod(setter, "__set__"); set != nil {
+			return Call(set, Tuple{setter, self, value}, nil)
+		}
+		if descriptorMethod(setter, "__delete__") != nil {
+			return nil, ExceptionNewf(AttributeError, "__set__")
+		}
 	}
 
 	// Otherwise set the attribute in the instance dictionary if
 	// possible
 	if I, ok := self.(IGetDict); ok {
-		dict := I.GetDict()
-		if dict == nil {
-			return nil, ExceptionNewf(SystemError, "nil Dict in %s", self.Type().Name)
+		// A nil dict means the instance has none, eg because of __slots__
+		if dict := I.GetDict(); dict != nil {
+			dict[key] = value
+			return None, nil
 		}
-		dict[key] = value
-		return None, nil
 	}
 
 	// If not blow up
@@ -321,32 +544,42 @@ func SetAttr(self Object, keyObj Object, value Object) (Object, error) {
 
 // DeleteAttrString
 func DeleteAttrString(self Object, key string) error {
+	// If we have __delattr__ then use that
+	if I, ok := self.(I__delattr__); ok {
+		_, err := I.M__delattr__(key)
+		return err
+	} else if _, ok, err := TypeCall1(self, "__delattr__", String(key)); ok {
+		return err
+	}
+	return GenericDeleteAttrString(self, key)
+}
+
+// GenericDeleteAttrString deletes key from self without calling any
+// __delattr__ defined for it.  This is object.__delattr__.
+func GenericDeleteAttrString(self Object, key string) error {
+	classModified(self)
 	// First look in type's dictionary etc for a property that could
 	// be set - do this before looking in the instance dictionary
 	deleter := self.Type().NativeGetAttrOrNil(key)
 	if deleter != nil {
-		// Call __set__ which writes properties etc
+		// Call __delete__ which deletes properties etc
 		if I, ok := deleter.(I__delete__); ok {
 			_, err := I.M__delete__(self)
 			return err
 		}
-	}
-
-	// If we have __delattr__ then use that
-	if I, ok := self.(I__delattr__); ok {
-		_, err := I.M__delattr__(key)
-		return err
-	} else if _, ok, err := TypeCall1(self, "__delattr__", String(key)); ok {
-		return err
+		if del := descriptorMethod(deleter, "__delete__"); del != nil {
+			_, err := Call(del, Tuple{deleter, self}, nil)
+			return err
+		}
+		if descriptorMethod(deleter, "__set__") != nil {
+			return ExceptionNewf(AttributeError, "__delete__")
+		}
 	}
 
 	// Otherwise delete the attribute from the instance dictionary
 	// if possible
 	if I, ok := self.(IGetDict); ok {
 		dict := I.GetDict()
-		if dict == nil {
-			return ExceptionNewf(SystemError, "nil Dict in %s", self.Type().Name)
-		}
 		if _, ok := dict[key]; ok {
 			delete(dict, key)
 			return nil
@@ -366,6 +599,78 @@ func DeleteAttr(self Object, keyObj Object) error {
 	return DeleteAttrString(self, key)
 }
 
+// Dir returns a sorted list of the attribute names of the object
+//
+// Calls __dir__ if the object has one otherwise returns the names in
+// the object's dictionary and those of its class and bases.
+func Dir(self Object) (*List, error) {
+	var names Object
+	var err error
+	if I, ok := self.(I__dir__); ok {
+		names, err = I.M__dir__()
+	} else if res, ok, err2 := TypeCall0(self, "__dir__"); ok {
+		names, err = res, err2
+	} else {
+		names = defaultDir(self)
+	}
+	if err != nil {
+		return nil, err
+	}
+	list, err := SequenceList(names)
+	if err != nil {
+		return nil, err
+	}
+	err = SortInPlace(list, nil, "dir")
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Returns the attribute names of the object without using __dir__
+func defaultDir(self Object) *List {
+	seen := make(map[string]struct{})
+	add := func(name string) {
+		seen[name] = struct{}{}
+	}
+	if I, ok := self.(IGetDict); ok {
+		for name := range I.GetDict() {
+			add(name)
+		}
+	}
+	// Classes list the attributes of their bases, instances those of
+	// their class
+	t := self.Type()
+	if cls, ok := self.(*Type); ok && t.IsSubtype(TypeType) {
+		t = cls
+	}
+	mro := t.Mro
+	if len(mro) == 0 {
+		mro = Tuple{t}
+	}
+	for _, baseObj := range mro {
+		base := baseObj.(*Type)
+		for name := range base.Dict {
+			add(name)
+		}
+	}
+	// Methods implemented in Go are M__special__ methods
+	if _, ok := self.(*Type); !ok {
+		goType := reflect.TypeOf(self)
+		for i := 0; i < goType.NumMethod(); i++ {
+			name := goType.Method(i).Name
+			if strings.HasPrefix(name, "M__") && strings.HasSuffix(name, "__") {
+				add(name[1:])
+			}
+		}
+	}
+	names := NewListWithCapacity(len(seen))
+	for name := range seen {
+		names.Append(String(name))
+	}
+	return names
+}
+
 // Calls __str__ on the object
 //
 // Calls __repr__ on the object or returns a sensible default
@@ -378,6 +683,35 @@ func Repr(self Object) (Object, error) {
 	return String(fmt.Sprintf("<%s instance at %p>", self.Type().Name, self)), nil
 }
 
+// ReprStack holds the containers whose repr the running thread is
+// making, innermost last, so one which contains itself is shown as
+// [...] rather than recursing forever.  The vm keeps one for each
+// thread.
+var ReprStack []Object
+
+// Called by the repr of the container obj before making the reprs of
+// its items.  It returns true if the repr of obj is being made
+// already, when it should be shown as ..., or a RecursionError if the
+// containers are nested too deeply.  reprLeave must be called when the
+// repr is made unless it returns either.
+func reprEnter(obj Object) (bool, error) {
+	for _, o := range ReprStack {
+		if Is(o, obj) {
+			return true, nil
+		}
+	}
+	if len(ReprStack) >= CurrentContext.RecursionLimit {
+		return false, ExceptionNewf(RecursionError, "maximum recursion depth exceeded while getting the repr of an object")
+	}
+	ReprStack = append(ReprStack, obj)
+	return false, nil
+}
+
+// Called when the repr of the container passed to reprEnter is made
+func reprLeave() {
+	ReprStack = ReprStack[:len(ReprStack)-1]
+}
+
 // DebugRepr - see Repr but returns the repr or error as a string
 func DebugRepr(self Object) string {
 	res, err := Repr(self)
@@ -0,0 +1,193 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Resource limits
+//
+// A program embedding gpython to run code it doesn't trust can limit
+// the resources the code running in a Context uses with SetLimits,
+// stopping it running forever or using up all the memory.
+//
+// To stop the code reaching the outside world run it with
+// SandboxBuiltins, which leave out open, __import__ and the other
+// builtins which can, and which the code keeps even if it deletes or
+// replaces __builtins__.  If it is given __import__ then DenyModules
+// should name the modules such as os which can.  Anything reachable
+// through the objects and modules the code is given is reachable by
+// the code too, so those must be chosen with care.
+//
+// Once a limit is exceeded every instruction run in the Context
+// raises the error again, so the code can't catch it and carry on,
+// until SetLimits is called again.
+
+package py
+
+import (
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// DefaultRecursionLimit is the RecursionLimit of a new Context
+const DefaultRecursionLimit = 1000
+
+// Limits restricts the resources used by the code running in a
+// Context.  The zero value of each field means no limit.
+type Limits struct {
+	// MaxInstructions is the number of bytecode instructions
+	// which may be run.  Exceeding it raises RuntimeError.
+	MaxInstructions int64
+
+	// Timeout is how long code may run for.  Exceeding it raises
+	// TimeoutError.  It is checked every so many instructions so
+	// a single call into Go which blocks isn't interrupted.
+	Timeout time.Duration
+
+	// MaxMemory is the size in bytes the Go heap may grow to.
+	// Exceeding it raises MemoryError.  As Go doesn't account for
+	// memory by goroutine this is the heap of the whole process.
+	// It is checked every so many instructions, and before making
+	// a sequence whose size is known up front, such as repeating
+	// a sequence, padding a string, bytes(n) or list(range(n)),
+	// which could ask for a lot at once.
+	MaxMemory uint64
+
+	// DenyModules are the names of the modules which can't be
+	// imported, along with their submodules.  Deny sys too if
+	// the modules already imported shouldn't be reachable through
+	// sys.modules.
+	DenyModules []string
+}
+
+// The state of the limits of a Context
+type limitState struct {
+	Limits
+	deadline     time.Time
+	instructions int64
+	exceeded     error
+}
+
+// How often in instructions the time and memory limits are checked
+const (
+	timeCheckInterval   = 1 << 8
+	memoryCheckInterval = 1 << 16
+)
+
+// SetLimits sets the limits of the code running in the context,
+// starting the count of instructions and the timeout afresh.  Pass
+// Limits{} to remove them.
+func (ctx *Context) SetLimits(limits Limits) {
+	ctx.limits = limitState{Limits: limits}
+	if limits.Timeout > 0 {
+		ctx.limits.deadline = time.Now().Add(limits.Timeout)
+	}
+	ctx.limited = limits.MaxInstructions > 0 || limits.Timeout > 0 || limits.MaxMemory > 0
+}
+
+// Limits returns the limits set by SetLimits
+func (ctx *Context) Limits() Limits {
+	return ctx.limits.Limits
+}
+
+// CheckLimits returns an error if the code running in the context has
+// exceeded its limits.  The vm calls this before each instruction.
+func (ctx *Context) CheckLimits() error {
+	if !ctx.limited {
+		return nil
+	}
+	return ctx.checkLimits()
+}
+
+func (ctx *Context) checkLimits() error {
+	l := &ctx.limits
+	if l.exceeded != nil {
+		return l.exceeded
+	}
+	l.instructions++
+	if l.MaxInstructions > 0 && l.instructions > l.MaxInstructions {
+		l.exceeded = ExceptionNewf(RuntimeError, "instruction limit of %d exceeded", l.MaxInstructions)
+	} else if l.Timeout > 0 && l.instructions%timeCheckInterval == 0 && time.Now().After(l.deadline) {
+		l.exceeded = ExceptionNewf(TimeoutError, "time limit of %v exceeded", l.Timeout)
+	} else if l.MaxMemory > 0 && l.instructions%memoryCheckInterval == 0 {
+		l.exceeded = l.checkMemory(0)
+	}
+	return l.exceeded
+}
+
+// Returns a MemoryError if allocating size more bytes would take the
+// heap over MaxMemory
+func (l *limitState) checkMemory(size uint64) error {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc+size > l.MaxMemory {
+		return ExceptionNewf(MemoryError, "memory limit of %d bytes exceeded", l.MaxMemory)
+	}
+	return nil
+}
+
+// Checks that n items, each of which takes itemSize bytes, fit in
+// the memory limit of the CurrentContext
+func checkAlloc(n uint64, itemSize uintptr) error {
+	ctx := CurrentContext
+	if ctx.limits.MaxMemory == 0 || n == 0 {
+		return nil
+	}
+	if n > ctx.limits.MaxMemory/uint64(itemSize) {
+		return ExceptionNewf(MemoryError, "memory limit of %d bytes exceeded", ctx.limits.MaxMemory)
+	}
+	return ctx.limits.checkMemory(n * uint64(itemSize))
+}
+
+// Checks that repeating a sequence of length items, each of which
+// takes itemSize bytes, n times fits in the memory limit of the
+// CurrentContext
+func checkRepeat(length int, itemSize uintptr, n Int) error {
+	ctx := CurrentContext
+	if ctx.limits.MaxMemory == 0 || length == 0 || n <= 0 {
+		return nil
+	}
+	if uint64(n) > ctx.limits.MaxMemory/uint64(itemSize)/uint64(length) {
+		return ExceptionNewf(MemoryError, "memory limit of %d bytes exceeded", ctx.limits.MaxMemory)
+	}
+	return checkAlloc(uint64(n)*uint64(length), itemSize)
+}
+
+// Checks that the items of v, an iterable whose length is known
+// without iterating it such as a range, fit in the memory limit of
+// the CurrentContext when each takes itemSize bytes
+func checkSized(v Object, itemSize uintptr) error {
+	if CurrentContext.limits.MaxMemory == 0 {
+		return nil
+	}
+	if _, ok := v.(*Type); ok {
+		// Don't call python code to find the length
+		return nil
+	}
+	I, ok := v.(I__len__)
+	if !ok {
+		return nil
+	}
+	res, err := I.M__len__()
+	if err != nil {
+		return nil
+	}
+	if n, ok := res.(Int); ok && n > 0 {
+		return checkAlloc(uint64(n), itemSize)
+	}
+	return nil
+}
+
+// The size of an item of a list or tuple
+const objectSize = unsafe.Sizeof(Object(nil))
+
+// Returns an ImportError if the module called name is denied by the
+// limits of the context
+func (ctx *Context) checkImport(name string) error {
+	for _, denied := range ctx.limits.DenyModules {
+		if name == denied || strings.HasPrefix(name, denied+".") {
+			return ExceptionNewf(ImportError, "import of '%s' is not allowed", name)
+		}
+	}
+	return nil
+}
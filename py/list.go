@@ -35,10 +35,146 @@ func init() {
 		}
 		if oList, ok := args[0].(*List); ok {
 			listSelf.Items = append(listSelf.Items, oList.Items...)
+			return NoneType{}, nil
 		}
-		return NoneType{}, nil
+		return NoneType{}, listSelf.ExtendSequence(args[0])
 	}, 0, "extend([item])")
 
+	ListType.Dict["insert"] = MustNewMethod("insert", func(self Object, args Tuple) (Object, error) {
+		var indexObj, item Object
+		err := UnpackTuple(args, nil, "insert", 2, 2, &indexObj, &item)
+		if err != nil {
+			return nil, err
+		}
+		l := self.(*List)
+		i, err := IndexInt(indexObj)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			i += len(l.Items)
+			if i < 0 {
+				i = 0
+			}
+		} else if i > len(l.Items) {
+			i = len(l.Items)
+		}
+		l.Items = append(l.Items, nil)
+		copy(l.Items[i+1:], l.Items[i:])
+		l.Items[i] = item
+		return None, nil
+	}, 0, "insert(index, object) -- insert object before index")
+
+	ListType.Dict["pop"] = MustNewMethod("pop", func(self Object, args Tuple) (Object, error) {
+		var indexObj Object = Int(-1)
+		err := UnpackTuple(args, nil, "pop", 0, 1, &indexObj)
+		if err != nil {
+			return nil, err
+		}
+		l := self.(*List)
+		if len(l.Items) == 0 {
+			return nil, ExceptionNewf(IndexError, "pop from empty list")
+		}
+		i, err := IndexIntCheck(indexObj, len(l.Items))
+		if err != nil {
+			return nil, ExceptionNewf(IndexError, "pop index out of range")
+		}
+		item := l.Items[i]
+		l.DelItem(i)
+		return item, nil
+	}, 0, "pop([index]) -> item -- remove and return item at index (default last).\nRaises IndexError if list is empty or index is out of range.")
+
+	ListType.Dict["remove"] = MustNewMethod("remove", func(self Object, args Tuple) (Object, error) {
+		var item Object
+		err := UnpackTuple(args, nil, "remove", 1, 1, &item)
+		if err != nil {
+			return nil, err
+		}
+		l := self.(*List)
+		i, err := l.find(item, 0, len(l.Items))
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, ExceptionNewf(ValueError, "list.remove(x): x not in list")
+		}
+		l.DelItem(i)
+		return None, nil
+	}, 0, "remove(value) -- remove first occurrence of value.\nRaises ValueError if the value is not present.")
+
+	ListType.Dict["index"] = MustNewMethod("index", func(self Object, args Tuple) (Object, error) {
+		var item, startObj, stopObj Object
+		err := UnpackTuple(args, nil, "index", 1, 3, &item, &startObj, &stopObj)
+		if err != nil {
+			return nil, err
+		}
+		l := self.(*List)
+		start, stop, err := sliceIndices(startObj, stopObj, len(l.Items))
+		if err != nil {
+			return nil, err
+		}
+		i, err := l.find(item, start, stop)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			itemRepr, err := ReprAsString(item)
+			if err != nil {
+				return nil, err
+			}
+			return nil, ExceptionNewf(ValueError, "%s is not in list", itemRepr)
+		}
+		return Int(i), nil
+	}, 0, "index(value, [start, [stop]]) -> integer -- return first index of value.\nRaises ValueError if the value is not present.")
+
+	ListType.Dict["count"] = MustNewMethod("count", func(self Object, args Tuple) (Object, error) {
+		var item Object
+		err := UnpackTuple(args, nil, "count", 1, 1, &item)
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		for _, o := range self.(*List).Items {
+			eq, err := Eq(o, item)
+			if err != nil {
+				return nil, err
+			}
+			if eq == True {
+				n++
+			}
+		}
+		return Int(n), nil
+	}, 0, "count(value) -> integer -- return number of occurrences of value")
+
+	ListType.Dict["copy"] = MustNewMethod("copy", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "copy", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*List).Copy(), nil
+	}, 0, "copy() -> list -- a shallow copy of L")
+
+	ListType.Dict["clear"] = MustNewMethod("clear", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "clear", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		self.(*List).Items = []Object{}
+		return None, nil
+	}, 0, "clear() -> None -- remove all items from L")
+
+	ListType.Dict["reverse"] = MustNewMethod("reverse", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "reverse", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		items := self.(*List).Items
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		return None, nil
+	}, 0, "reverse() -- reverse *IN PLACE*")
+
 	ListType.Dict["sort"] = MustNewMethod("sort", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
 		const funcName = "sort"
 		var l *List
@@ -159,7 +295,7 @@ func (l *List) M__str__() (Object, error) {
 }
 
 func (l *List) M__repr__() (Object, error) {
-	return Tuple(l.Items).repr("[", "]")
+	return Tuple(l.Items).repr(l, "[", "]")
 }
 
 func (l *List) M__len__() (Object, error) {
@@ -199,29 +335,24 @@ func (l *List) M__setitem__(key, value Object) (Object, error) {
 		if err != nil {
 			return nil, err
 		}
+		// Read the new items before modifying the list in case
+		// value is the list itself
+		newItems, err := SequenceTuple(value)
+		if err != nil {
+			return nil, err
+		}
 		if step == 1 {
-			// Make a copy of the tail
-			tailSlice := l.Items[stop:]
-			tail := make([]Object, len(tailSlice))
-			copy(tail, tailSlice)
-			l.Items = l.Items[:start]
-			err = l.ExtendSequence(value)
-			if err != nil {
-				return nil, err
+			if stop < start {
+				stop = start
 			}
-			l.Items = append(l.Items, tail...)
+			tail := append([]Object{}, l.Items[stop:]...)
+			l.Items = append(append(l.Items[:start], newItems...), tail...)
 		} else {
-			newItems, err := SequenceTuple(value)
-			if err != nil {
-				return nil, err
-			}
 			if len(newItems) != slicelength {
 				return nil, ExceptionNewf(ValueError, "attempt to assign sequence of size %d to extended slice of size %d", len(newItems), slicelength)
 			}
-			j := 0
-			for i := start; i < stop; i += step {
+			for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
 				l.Items[i] = newItems[j]
-				j++
 			}
 		}
 	} else {
@@ -242,19 +373,37 @@ func (a *List) DelItem(i int) {
 // Removes items from a list
 func (a *List) M__delitem__(key Object) (Object, error) {
 	if slice, ok := key.(*Slice); ok {
-		start, stop, step, _, err := slice.GetIndices(len(a.Items))
+		start, stop, step, slicelength, err := slice.GetIndices(len(a.Items))
 		if err != nil {
 			return nil, err
 		}
 		if step == 1 {
-			a.Items = append(a.Items[:start], a.Items[stop:]...)
-		} else {
-			j := 0
-			for i := start; i < stop; i += step {
-				a.DelItem(i - j)
-				j++
+			if start < stop {
+				a.Items = append(a.Items[:start], a.Items[stop:]...)
+			}
+			return None, nil
+		}
+		if step < 0 {
+			// Delete the same items in ascending order
+			start += (slicelength - 1) * step
+			step = -step
+		}
+		out := a.Items[:0]
+		next := start
+		deleted := 0
+		for i, item := range a.Items {
+			if deleted < slicelength && i == next {
+				next += step
+				deleted++
+				continue
 			}
+			out = append(out, item)
 		}
+		// Clear the references left at the end
+		for i := len(out); i < len(a.Items); i++ {
+			a.Items[i] = nil
+		}
+		a.Items = out
 	} else {
 		i, err := IndexIntCheck(key, len(a.Items))
 		if err != nil {
@@ -265,6 +414,21 @@ func (a *List) M__delitem__(key Object) (Object, error) {
 	return None, nil
 }
 
+// Returns the index of the first occurrence of item in l.Items[start:stop]
+// or -1 if it isn't found
+func (l *List) find(item Object, start, stop int) (int, error) {
+	for i := start; i < stop && i < len(l.Items); i++ {
+		eq, err := Eq(l.Items[i], item)
+		if err != nil {
+			return 0, err
+		}
+		if eq == True {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
 func (a *List) M__add__(other Object) (Object, error) {
 	if b, ok := other.(*List); ok {
 		newList := NewListSized(len(a.Items) + len(b.Items))
@@ -293,6 +457,9 @@ func (a *List) M__iadd__(other Object) (Object, error) {
 func (l *List) M__mul__(other Object) (Object, error) {
 	if b, ok := convertToInt(other); ok {
 		m := len(l.Items)
+		if err := checkRepeat(m, objectSize, b); err != nil {
+			return nil, err
+		}
 		n := int(b) * m
 		if n < 0 {
 			n = 0
@@ -324,8 +491,41 @@ var _ I__bool__ = (*List)(nil)
 var _ I__iter__ = (*List)(nil)
 var _ I__getitem__ = (*List)(nil)
 var _ I__setitem__ = (*List)(nil)
+var _ I__delitem__ = (*List)(nil)
+
+var _ richComparison = (*List)(nil)
+
+func (a *List) M__lt__(other Object) (Object, error) {
+	b, ok := other.(*List)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a.Items, b.Items, Lt)
+}
+
+func (a *List) M__le__(other Object) (Object, error) {
+	b, ok := other.(*List)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a.Items, b.Items, Le)
+}
+
+func (a *List) M__gt__(other Object) (Object, error) {
+	b, ok := other.(*List)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a.Items, b.Items, Gt)
+}
 
-// var _ richComparison = (*List)(nil)
+func (a *List) M__ge__(other Object) (Object, error) {
+	b, ok := other.(*List)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a.Items, b.Items, Ge)
+}
 
 func (a *List) M__eq__(other Object) (Object, error) {
 	b, ok := other.(*List)
@@ -368,8 +568,8 @@ func (a *List) M__ne__(other Object) (Object, error) {
 }
 
 type sortable struct {
-	l        *List
-	keyFunc  Object
+	items    []Object
+	keys     []Object
 	reverse  bool
 	firstErr error
 }
@@ -379,94 +579,37 @@ type ptrSortable struct {
 }
 
 func (s ptrSortable) Len() int {
-	return s.s.l.Len()
+	return len(s.s.items)
 }
 
 func (s ptrSortable) Swap(i, j int) {
-	itemI, err := s.s.l.M__getitem__(Int(i))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return
-	}
-	itemJ, err := s.s.l.M__getitem__(Int(j))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return
-	}
-	_, err = s.s.l.M__setitem__(Int(i), itemJ)
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-	}
-	_, err = s.s.l.M__setitem__(Int(j), itemI)
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-	}
+	s.s.items[i], s.s.items[j] = s.s.items[j], s.s.items[i]
+	s.s.keys[i], s.s.keys[j] = s.s.keys[j], s.s.keys[i]
 }
 
 func (s ptrSortable) Less(i, j int) bool {
-	itemI, err := s.s.l.M__getitem__(Int(i))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
+	// Once a comparison has failed just finish the sort as quickly
+	// as possible
+	if s.s.firstErr != nil {
 		return false
 	}
-	itemJ, err := s.s.l.M__getitem__(Int(j))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return false
-	}
-
-	if s.s.keyFunc != None {
-		itemI, err = Call(s.s.keyFunc, Tuple{itemI}, nil)
-		if err != nil {
-			if s.s.firstErr == nil {
-				s.s.firstErr = err
-			}
-			return false
-		}
-		itemJ, err = Call(s.s.keyFunc, Tuple{itemJ}, nil)
-		if err != nil {
-			if s.s.firstErr == nil {
-				s.s.firstErr = err
-			}
-			return false
-		}
-	}
-
 	var cmpResult Object
+	var err error
 	if s.s.reverse {
-		cmpResult, err = Lt(itemJ, itemI)
+		cmpResult, err = Lt(s.s.keys[j], s.s.keys[i])
 	} else {
-		cmpResult, err = Lt(itemI, itemJ)
+		cmpResult, err = Lt(s.s.keys[i], s.s.keys[j])
+	}
+	if err == nil {
+		cmpResult, err = MakeBool(cmpResult)
 	}
-
 	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
+		s.s.firstErr = err
 		return false
 	}
-
-	if boolResult, ok := cmpResult.(Bool); ok {
-		return bool(boolResult)
-	}
-
-	return false
+	return cmpResult == True
 }
 
-// SortInPlace sorts the given List in place using a stable sort.
-// kwargs can have the keys "key" and "reverse".
 func SortInPlace(l *List, kwargs StringDict, funcName string) error {
 	var keyFunc Object
 	var reverse Object
@@ -481,7 +624,25 @@ func SortInPlace(l *List, kwargs StringDict, funcName string) error {
 		reverse = False
 	}
 	// FIXME: requires the same bool-check like CPython (or better "|$Op" that doesn't panic on nil).
-	s := ptrSortable{&sortable{l, keyFunc, ObjectIsTrue(reverse), nil}}
+	items := make([]Object, len(l.Items))
+	copy(items, l.Items)
+	keys := make([]Object, len(items))
+	for i, item := range items {
+		if keyFunc == None {
+			keys[i] = item
+			continue
+		}
+		// Call the key function once for each item
+		keys[i], err = Call(keyFunc, Tuple{item}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	s := ptrSortable{&sortable{items, keys, ObjectIsTrue(reverse), nil}}
 	sort.Stable(s)
-	return s.s.firstErr
+	if s.s.firstErr != nil {
+		return s.s.firstErr
+	}
+	l.Items = s.s.items
+	return nil
 }
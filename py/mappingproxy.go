@@ -0,0 +1,132 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// MappingProxy objects
+//
+// A read only view of a mapping which reflects changes to the
+// underlying mapping
+
+package py
+
+var MappingProxyType = NewTypeX("mappingproxy", `mappingproxy(mapping) -> read-only proxy of a mapping`, MappingProxyNew, nil)
+
+type MappingProxy struct {
+	Mapping Object
+}
+
+// Type of this MappingProxy object
+func (o *MappingProxy) Type() *Type {
+	return MappingProxyType
+}
+
+// NewMappingProxy makes a read only proxy of the mapping passed in
+func NewMappingProxy(mapping Object) *MappingProxy {
+	return &MappingProxy{Mapping: mapping}
+}
+
+// MappingProxyNew
+func MappingProxyNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var mapping Object
+	err := UnpackTuple(args, kwargs, "mappingproxy", 1, 1, &mapping)
+	if err != nil {
+		return nil, err
+	}
+	isMapping := true
+	switch mapping.(type) {
+	case *List, Tuple, String:
+		isMapping = false
+	default:
+		_, err = GetAttrString(mapping, "__getitem__")
+		isMapping = err == nil
+	}
+	if !isMapping {
+		return nil, ExceptionNewf(TypeError, "mappingproxy() argument must be a mapping, not %s", mapping.Type().Name)
+	}
+	return NewMappingProxy(mapping), nil
+}
+
+// Call the named method on the underlying mapping
+func (o *MappingProxy) callMethod(name string, args Tuple) (Object, error) {
+	method, err := GetAttrString(o.Mapping, name)
+	if err != nil {
+		return nil, err
+	}
+	return Call(method, args, nil)
+}
+
+func (o *MappingProxy) M__getitem__(key Object) (Object, error) {
+	return GetItem(o.Mapping, key)
+}
+
+func (o *MappingProxy) M__len__() (Object, error) {
+	return Len(o.Mapping)
+}
+
+func (o *MappingProxy) M__iter__() (Object, error) {
+	return Iter(o.Mapping)
+}
+
+func (o *MappingProxy) M__contains__(key Object) (Object, error) {
+	found, err := SequenceContains(o.Mapping, key)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(found), nil
+}
+
+func (o *MappingProxy) M__eq__(other Object) (Object, error) {
+	return Eq(o.Mapping, other)
+}
+
+func (o *MappingProxy) M__ne__(other Object) (Object, error) {
+	return Ne(o.Mapping, other)
+}
+
+func (o *MappingProxy) M__str__() (Object, error) {
+	return Str(o.Mapping)
+}
+
+func (o *MappingProxy) M__repr__() (Object, error) {
+	repr, err := ReprAsString(o.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	return String("mappingproxy(" + repr + ")"), nil
+}
+
+func init() {
+	MappingProxyType.Dict["get"] = MustNewMethod("get", func(self Object, args Tuple) (Object, error) {
+		return self.(*MappingProxy).callMethod("get", args)
+	}, 0, "D.get(k[,d]) -> D[k] if k in D, else d.  d defaults to None.")
+
+	MappingProxyType.Dict["keys"] = MustNewMethod("keys", func(self Object, args Tuple) (Object, error) {
+		return self.(*MappingProxy).callMethod("keys", args)
+	}, 0, "D.keys() -> the keys of D")
+
+	MappingProxyType.Dict["values"] = MustNewMethod("values", func(self Object, args Tuple) (Object, error) {
+		return self.(*MappingProxy).callMethod("values", args)
+	}, 0, "D.values() -> the values of D")
+
+	MappingProxyType.Dict["items"] = MustNewMethod("items", func(self Object, args Tuple) (Object, error) {
+		return self.(*MappingProxy).callMethod("items", args)
+	}, 0, "D.items() -> the (key, value) pairs of D")
+
+	MappingProxyType.Dict["copy"] = MustNewMethod("copy", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "copy", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		mapping := self.(*MappingProxy).Mapping
+		if d, ok := mapping.(StringDict); ok {
+			return d.Copy(), nil
+		}
+		return self.(*MappingProxy).callMethod("copy", nil)
+	}, 0, "D.copy() -> a shallow copy of D")
+}
+
+// Check interface is satisfied
+var _ I__getitem__ = (*MappingProxy)(nil)
+var _ I__len__ = (*MappingProxy)(nil)
+var _ I__iter__ = (*MappingProxy)(nil)
+var _ I__contains__ = (*MappingProxy)(nil)
@@ -0,0 +1,84 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Member descriptor objects
+//
+// These are made for each name in the __slots__ of a class.  The
+// values are kept in a slice in the instance rather than a dict, the
+// slots of a class following those of its base.
+
+package py
+
+var MemberDescriptorType = NewType("member_descriptor", "member descriptor object")
+
+// A MemberDescriptor stores the value of a slot in an instance
+type MemberDescriptor struct {
+	Name  string
+	Owner *Type
+	Index int // of the value in SlotValues
+}
+
+// Type of this object
+func (m *MemberDescriptor) Type() *Type {
+	return MemberDescriptorType
+}
+
+// Returns the instance as a *Type checking it is an instance of the owner
+func (m *MemberDescriptor) object(instance Object) (*Type, error) {
+	obj, ok := instance.(*Type)
+	if !ok || !instance.Type().IsSubtype(m.Owner) {
+		return nil, ExceptionNewf(TypeError, "descriptor '%s' for '%s' objects doesn't apply to '%s' object", m.Name, m.Owner.Name, instance.Type().Name)
+	}
+	return obj, nil
+}
+
+func (m *MemberDescriptor) M__get__(instance, owner Object) (Object, error) {
+	if instance == nil || instance == None {
+		return m, nil
+	}
+	obj, err := m.object(instance)
+	if err != nil {
+		return nil, err
+	}
+	if m.Index < len(obj.SlotValues) && obj.SlotValues[m.Index] != nil {
+		return obj.SlotValues[m.Index], nil
+	}
+	return nil, ExceptionNewf(AttributeError, "%s", m.Name)
+}
+
+func (m *MemberDescriptor) M__set__(instance, value Object) (Object, error) {
+	obj, err := m.object(instance)
+	if err != nil {
+		return nil, err
+	}
+	if m.Index >= len(obj.SlotValues) {
+		values := make([]Object, obj.Type().NumSlots)
+		copy(values, obj.SlotValues)
+		obj.SlotValues = values
+	}
+	obj.SlotValues[m.Index] = value
+	return None, nil
+}
+
+func (m *MemberDescriptor) M__delete__(instance Object) (Object, error) {
+	obj, err := m.object(instance)
+	if err != nil {
+		return nil, err
+	}
+	if m.Index >= len(obj.SlotValues) || obj.SlotValues[m.Index] == nil {
+		return nil, ExceptionNewf(AttributeError, "%s", m.Name)
+	}
+	obj.SlotValues[m.Index] = nil
+	return None, nil
+}
+
+func (m *MemberDescriptor) M__repr__() (Object, error) {
+	return String("<member '" + m.Name + "' of '" + m.Owner.Name + "' objects>"), nil
+}
+
+// Interfaces
+var _ I__get__ = (*MemberDescriptor)(nil)
+var _ I__set__ = (*MemberDescriptor)(nil)
+var _ I__delete__ = (*MemberDescriptor)(nil)
+var _ I__repr__ = (*MemberDescriptor)(nil)
@@ -84,7 +84,7 @@ const (
 	InternalMethodExec
 )
 
-var MethodType = NewType("method", "method object")
+var MethodType = NewType("builtin_function_or_method", "builtin_function_or_method object")
 
 // Type of this object
 func (o *Method) Type() *Type {
@@ -262,9 +262,32 @@ func (m *Method) M__ne__(other Object) (Object, error) {
 	return True, nil
 }
 
+func (m *Method) M__repr__() (Object, error) {
+	return String(fmt.Sprintf("<built-in function %s>", m.Name)), nil
+}
+
+// Properties
+func init() {
+	MethodType.Dict["__name__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return String(self.(*Method).Name), nil
+		},
+	}
+	MethodType.Dict["__qualname__"] = MethodType.Dict["__name__"]
+	MethodType.Dict["__doc__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			if doc := self.(*Method).Doc; doc != "" {
+				return String(doc), nil
+			}
+			return None, nil
+		},
+	}
+}
+
 // Make sure it satisfies the interface
 var _ Object = (*Method)(nil)
 var _ I__call__ = (*Method)(nil)
 var _ I__get__ = (*Method)(nil)
 var _ I__eq__ = (*Method)(nil)
 var _ I__ne__ = (*Method)(nil)
+var _ I__repr__ = (*Method)(nil)
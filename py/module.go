@@ -8,33 +8,65 @@ package py
 
 import "fmt"
 
-var (
-	// Registry of installed modules
-	modules = make(map[string]*Module)
-	// Builtin module
-	Builtins *Module
-	// this should be the frozen module importlib/_bootstrap.py generated
-	// by Modules/_freeze_importlib.c into Python/importlib.h
-	Importlib *Module
-)
-
 // A python Module object
 type Module struct {
 	Name    string
 	Doc     string
 	Globals StringDict
-	//	dict Dict
+	Base    *Type // type of the module if set, otherwise module
 }
 
-var ModuleType = NewType("module", "module object")
+const module_doc = `module(name, doc=None) -> create a module object with the name and doc given`
+
+var ModuleType = ObjectType.NewTypeFlags("module", module_doc, ModuleNew, ModuleInit, ObjectType.Flags|TPFLAGS_BASETYPE|TPFLAGS_SUBCLASS_NEW)
 
 // Type of this object
-func (o *Module) Type() *Type {
+func (m *Module) Type() *Type {
+	if m.Base != nil {
+		return m.Base
+	}
 	return ModuleType
 }
 
+// ModuleNew makes a module object which isn't in any Context's
+// modules, as types.ModuleType(name) does
+func ModuleNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	m := &Module{
+		Globals: NewStringDict(),
+		Base:    metatype,
+	}
+	return m, nil
+}
+
+// ModuleInit sets the name and doc of a module made by ModuleNew
+func ModuleInit(self Object, args Tuple, kwargs StringDict) error {
+	m := self.(*Module)
+	var name Object
+	var doc Object = None
+	err := ParseTupleAndKeywords(args, kwargs, "U|O:module", []string{"name", "doc"}, &name, &doc)
+	if err != nil {
+		return err
+	}
+	m.Name = string(name.(String))
+	m.Doc = ""
+	if s, ok := doc.(String); ok {
+		m.Doc = string(s)
+	}
+	m.Globals["__name__"] = name
+	m.Globals["__doc__"] = doc
+	for _, key := range []string{"__package__", "__loader__", "__spec__"} {
+		if _, ok := m.Globals[key]; !ok {
+			m.Globals[key] = None
+		}
+	}
+	return nil
+}
+
 func (m *Module) M__repr__() (Object, error) {
-	return String(fmt.Sprintf("<module %s>", m.Name)), nil
+	if file, ok := m.Globals["__file__"].(String); ok {
+		return String(fmt.Sprintf("<module '%s' from '%s'>", m.Name, string(file))), nil
+	}
+	return String(fmt.Sprintf("<module '%s'>", m.Name)), nil
 }
 
 // Get the Dict
@@ -42,41 +74,45 @@ func (m *Module) GetDict() StringDict {
 	return m.Globals
 }
 
-// Define a new module
-func NewModule(name, doc string, methods []*Method, globals StringDict) *Module {
-	m := &Module{
-		Name:    name,
-		Doc:     doc,
-		Globals: globals.Copy(),
+// Called when an attribute isn't found in the module
+//
+// If the module defines a __getattr__ function then it is called
+// with the name (PEP 562), otherwise the __getattr__ of a subclass
+// of module the module has been given as its __class__
+func (m *Module) M__getattr__(name string) (Object, error) {
+	if fn, ok := m.Globals["__getattr__"]; ok {
+		return Call(fn, Tuple{String(name)}, nil)
 	}
-	// Insert the methods into the module dictionary
-	for _, method := range methods {
-		m.Globals[method.Name] = method
+	if m.Base != nil && m.Base != ModuleType {
+		if fn := m.Base.NativeGetAttrOrNil("__getattr__"); fn != nil {
+			return Call(fn, Tuple{m, String(name)}, nil)
+		}
 	}
-	// Set some module globals
-	m.Globals["__name__"] = String(name)
-	m.Globals["__doc__"] = String(doc)
-	m.Globals["__package__"] = None
-	// Register the module
-	modules[name] = m
-	// Make a note of some modules
-	switch name {
-	case "builtins":
-		Builtins = m
-	case "importlib":
-		Importlib = m
+	return nil, ExceptionNewf(AttributeError, "module '%s' has no attribute '%s'", m.Name, name)
+}
+
+// Returns the names in the module
+//
+// If the module defines a __dir__ function then it is called instead
+func (m *Module) M__dir__() (Object, error) {
+	if fn, ok := m.Globals["__dir__"]; ok {
+		return Call(fn, nil, nil)
 	}
-	// fmt.Printf("Registering module %q\n", name)
-	return m
+	names := NewListWithCapacity(len(m.Globals))
+	for name := range m.Globals {
+		names.Append(String(name))
+	}
+	return names, nil
 }
 
-// Gets a module
+// NewModule makes a module in the CurrentContext
+func NewModule(name, doc string, methods []*Method, globals StringDict) *Module {
+	return CurrentContext.NewModule(name, doc, methods, globals)
+}
+
+// Gets a module from the CurrentContext
 func GetModule(name string) (*Module, error) {
-	m, ok := modules[name]
-	if !ok {
-		return nil, ExceptionNewf(ImportError, "Module %q not found", name)
-	}
-	return m, nil
+	return CurrentContext.GetModule(name)
 }
 
 // Gets a module or panics
@@ -88,6 +124,67 @@ func MustGetModule(name string) *Module {
 	return m
 }
 
+// UnsafeBuiltins are the builtins which can reach the filesystem,
+// import modules or run arbitrary code.  SandboxBuiltins leaves these
+// out.
+var UnsafeBuiltins = []string{
+	"__import__",
+	"breakpoint",
+	"compile",
+	"eval",
+	"exec",
+	"input",
+	"open",
+}
+
+// BuiltinsWithout returns a copy of the namespace of the builtins
+// module without the names passed in
+//
+// Use this with vm.RunWithBuiltins or by setting __builtins__ in the
+// globals to restrict the builtins available to code.  Using a
+// removed builtin raises a NameError.
+func BuiltinsWithout(names ...string) StringDict {
+	builtins := CurrentContext.Builtins().Globals.Copy()
+	for _, name := range names {
+		delete(builtins, name)
+	}
+	return builtins
+}
+
+// SandboxBuiltins returns a copy of the builtins without the
+// UnsafeBuiltins, for running untrusted code
+func SandboxBuiltins() StringDict {
+	return BuiltinsWithout(UnsafeBuiltins...)
+}
+
+// SetDoc sets the doc string of the module
+func (m *Module) SetDoc(doc string) {
+	m.Doc = doc
+	m.Globals["__doc__"] = String(doc)
+}
+
+// AddFunc adds the Go function fn to the module as name, converting
+// the arguments and results as described in NewGoFunc
+func (m *Module) AddFunc(name, doc string, fn interface{}) error {
+	method, err := NewGoFunc(name, doc, fn)
+	if err != nil {
+		return err
+	}
+	m.Globals[name] = method
+	return nil
+}
+
+// AddValue adds the Go value v to the module as name, converting it
+// with WrapGoValue
+func (m *Module) AddValue(name string, v interface{}) error {
+	obj, err := WrapGoValue(v)
+	if err != nil {
+		return err
+	}
+	m.Globals[name] = obj
+	return nil
+}
+
 // Calls a named method of a module
 func (m *Module) Call(name string, args Tuple, kwargs StringDict) (Object, error) {
 	attr, err := GetAttrString(m, name)
@@ -23,6 +23,15 @@ func (a NoneType) M__bool__() (Object, error) {
 	return False, nil
 }
 
+func (a NoneType) M__hash__() (Object, error) {
+	// None is a singleton so hash the identity of its type
+	h, err := HashPointer(NoneTypeType)
+	if err != nil {
+		return nil, err
+	}
+	return Int(h), nil
+}
+
 func (a NoneType) M__str__() (Object, error) {
 	return a.M__repr__()
 }
@@ -45,3 +45,12 @@ func ObjectIsTrue(o Object) bool {
 	}
 	return false
 }
+
+// IsTrue returns whether obj is true as bool(obj) would, which unlike
+// ObjectIsTrue looks at the length of containers and calls the
+// __bool__ and __len__ of python classes.  An object whose truth
+// can't be found is false.
+func IsTrue(obj Object) bool {
+	b, err := MakeBool(obj)
+	return err == nil && b == True
+}
@@ -0,0 +1,383 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// printf style formatting as used by str % args
+//
+// The conversions are done with the format spec machinery in
+// format.go by turning the flags, width and precision of each
+// conversion into the equivalent format spec.
+
+package py
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Formats the values for a printf style format string
+type printfFormatter struct {
+	format  string
+	args    Tuple
+	argIdx  int
+	mapping Object // set if the args are a mapping
+}
+
+// Returns the next positional argument
+func (p *printfFormatter) next() (Object, error) {
+	if p.argIdx >= len(p.args) {
+		return nil, ExceptionNewf(TypeError, "not enough arguments for format string")
+	}
+	arg := p.args[p.argIdx]
+	p.argIdx++
+	return arg, nil
+}
+
+// Reads a width or precision which is either digits or '*' for the
+// next argument starting at i, returning -1 if there is neither
+func (p *printfFormatter) number(i int) (int, int, error) {
+	s := p.format
+	if i < len(s) && s[i] == '*' {
+		arg, err := p.next()
+		if err != nil {
+			return 0, i, err
+		}
+		n, ok := arg.(Int)
+		if !ok {
+			return 0, i, ExceptionNewf(TypeError, "* wants int")
+		}
+		return int(n), i + 1, nil
+	}
+	start := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == start {
+		return -1, i, nil
+	}
+	n, err := strconv.Atoi(s[start:i])
+	if err != nil {
+		return 0, i, ExceptionNewf(ValueError, "width too big")
+	}
+	return n, i, nil
+}
+
+// Converts the argument of a numeric conversion to an integer
+func printfInteger(arg Object, code byte) (Object, *big.Int, error) {
+	var obj Object
+	var err error
+	switch code {
+	case 'd', 'i', 'u':
+		switch arg.(type) {
+		case Int, *BigInt, Bool:
+			obj = arg
+		default:
+			obj, err = MakeInt(arg)
+			if err != nil {
+				if IsException(TypeError, err) {
+					return nil, nil, ExceptionNewf(TypeError, "%%%c format: a real number is required, not %s", code, arg.Type().Name)
+				}
+				return nil, nil, err
+			}
+		}
+	default:
+		obj, err = Index(arg)
+		if err != nil {
+			if IsException(TypeError, err) {
+				return nil, nil, ExceptionNewf(TypeError, "%%%c format: an integer is required, not %s", code, arg.Type().Name)
+			}
+			return nil, nil, err
+		}
+	}
+	switch x := obj.(type) {
+	case Bool:
+		if x {
+			return Int(1), big.NewInt(1), nil
+		}
+		return Int(0), big.NewInt(0), nil
+	case Int:
+		return x, big.NewInt(int64(x)), nil
+	case *BigInt:
+		return x, (*big.Int)(x), nil
+	}
+	return nil, nil, ExceptionNewf(TypeError, "%%%c format: a number is required, not %s", code, arg.Type().Name)
+}
+
+// Formats a single conversion
+func (p *printfFormatter) convert(arg Object, flags string, width, precision int, code byte) (string, error) {
+	var spec strings.Builder
+	left := strings.IndexByte(flags, '-') >= 0
+	zero := strings.IndexByte(flags, '0') >= 0 && !left
+	if left {
+		spec.WriteByte('<')
+	}
+	writeNumberFlags := func() {
+		if strings.IndexByte(flags, '+') >= 0 {
+			spec.WriteByte('+')
+		} else if strings.IndexByte(flags, ' ') >= 0 {
+			spec.WriteByte(' ')
+		}
+		if strings.IndexByte(flags, '#') >= 0 {
+			spec.WriteByte('#')
+		}
+		if zero {
+			spec.WriteByte('0')
+		}
+	}
+	writeWidth := func() {
+		if width > 0 {
+			spec.WriteString(strconv.Itoa(width))
+		}
+	}
+	var res Object
+	var err error
+	switch code {
+	case 's', 'r', 'a':
+		switch code {
+		case 's':
+			res, err = Str(arg)
+		case 'r':
+			res, err = Repr(arg)
+		case 'a':
+			res, err = Repr(arg)
+			if err == nil {
+				res = String(StringEscape(res.(String), true))
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		if !left {
+			spec.WriteByte('>')
+		}
+		writeWidth()
+		if precision >= 0 {
+			spec.WriteString("." + strconv.Itoa(precision))
+		}
+		res, err = formatString(res.(String), String(spec.String()))
+	case 'c':
+		var c string
+		switch x := arg.(type) {
+		case String:
+			if len([]rune(string(x))) != 1 {
+				return "", ExceptionNewf(TypeError, "%%c requires int or char")
+			}
+			c = string(x)
+		default:
+			n, err := Index(arg)
+			if err != nil {
+				return "", ExceptionNewf(TypeError, "%%c requires int or char")
+			}
+			if n < 0 || n > 0x10ffff {
+				return "", ExceptionNewf(OverflowError, "%%c arg not in range(0x110000)")
+			}
+			c = string(rune(n))
+		}
+		if !left {
+			spec.WriteByte('>')
+		}
+		writeWidth()
+		res, err = formatString(String(c), String(spec.String()))
+	case 'd', 'i', 'u', 'o', 'x', 'X':
+		obj, x, err := printfInteger(arg, code)
+		if err != nil {
+			return "", err
+		}
+		if precision > 0 {
+			// The precision is the minimum number of digits
+			digits := new(big.Int).Abs(x).Text(10)
+			switch code {
+			case 'o':
+				digits = new(big.Int).Abs(x).Text(8)
+			case 'x':
+				digits = new(big.Int).Abs(x).Text(16)
+			case 'X':
+				digits = strings.ToUpper(new(big.Int).Abs(x).Text(16))
+			}
+			if n := precision - len(digits); n > 0 {
+				digits = strings.Repeat("0", n) + digits
+			}
+			f := &formatSpec{fill: ' ', width: width, precision: -1, typ: code}
+			if left {
+				f.align = '<'
+			} else if zero {
+				f.fill, f.align = '0', '='
+			}
+			if strings.IndexByte(flags, '+') >= 0 {
+				f.sign = '+'
+			} else if strings.IndexByte(flags, ' ') >= 0 {
+				f.sign = ' '
+			}
+			basePrefix := ""
+			if strings.IndexByte(flags, '#') >= 0 && code != 'd' && code != 'i' && code != 'u' {
+				basePrefix = "0" + string(code)
+			}
+			return string(f.number(x.Sign() < 0, basePrefix, digits)), nil
+		}
+		writeNumberFlags()
+		writeWidth()
+		typ := code
+		if typ == 'i' || typ == 'u' {
+			typ = 'd'
+		}
+		spec.WriteByte(typ)
+		res, err = formatInteger(obj, x, String(spec.String()))
+		if err != nil {
+			return "", err
+		}
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		x, err := MakeFloat(arg)
+		if err != nil {
+			if IsException(TypeError, err) {
+				return "", ExceptionNewf(TypeError, "must be real number, not %s", arg.Type().Name)
+			}
+			return "", err
+		}
+		f := x.(Float)
+		if math.IsInf(float64(f), 0) || math.IsNaN(float64(f)) {
+			// Zero padding doesn't apply to inf and nan
+			zero = false
+		}
+		writeNumberFlags()
+		writeWidth()
+		if precision < 0 {
+			precision = 6
+		}
+		spec.WriteString("." + strconv.Itoa(precision))
+		spec.WriteByte(code)
+		res, err = formatFloat(f, String(spec.String()))
+		if err != nil {
+			return "", err
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(res.(String)), nil
+}
+
+// Formats the format string with the args
+func (p *printfFormatter) run() (string, error) {
+	s := p.format
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c != '%' {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", ExceptionNewf(ValueError, "incomplete format")
+		}
+		if s[i] == '%' {
+			out.WriteByte('%')
+			i++
+			continue
+		}
+		var arg Object
+		if s[i] == '(' {
+			// A key in the mapping which may contain nested brackets
+			if p.mapping == nil {
+				return "", ExceptionNewf(TypeError, "format requires a mapping")
+			}
+			depth := 1
+			start := i + 1
+			for i++; i < len(s) && depth > 0; i++ {
+				switch s[i] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+			}
+			if depth > 0 {
+				return "", ExceptionNewf(ValueError, "incomplete format key")
+			}
+			var err error
+			arg, err = GetItem(p.mapping, String(s[start:i-1]))
+			if err != nil {
+				return "", err
+			}
+		}
+		start := i
+		for i < len(s) && strings.IndexByte("-+ #0", s[i]) >= 0 {
+			i++
+		}
+		flags := s[start:i]
+		var width int
+		var err error
+		width, i, err = p.number(i)
+		if err != nil {
+			return "", err
+		}
+		if width < -1 {
+			flags += "-"
+			width = -width
+		}
+		precision := -1
+		if i < len(s) && s[i] == '.' {
+			precision, i, err = p.number(i + 1)
+			if err != nil {
+				return "", err
+			}
+			if precision < 0 {
+				precision = 0
+			}
+		}
+		// Length modifiers are ignored as in C python
+		for i < len(s) && (s[i] == 'h' || s[i] == 'l' || s[i] == 'L') {
+			i++
+		}
+		if i >= len(s) {
+			return "", ExceptionNewf(ValueError, "incomplete format")
+		}
+		code := s[i]
+		if strings.IndexByte("sracdiuoxXeEfFgG", code) < 0 {
+			return "", ExceptionNewf(ValueError, "unsupported format character '%c' (0x%x) at index %d", code, code, i)
+		}
+		i++
+		if arg == nil {
+			arg, err = p.next()
+			if err != nil {
+				return "", err
+			}
+		}
+		res, err := p.convert(arg, flags, width, precision, code)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(res)
+	}
+	if p.mapping == nil && p.argIdx < len(p.args) {
+		return "", ExceptionNewf(TypeError, "not all arguments converted during string formatting")
+	}
+	return out.String(), nil
+}
+
+// PrintfFormat formats s with args as str % args does
+//
+// args may be a tuple of values, a mapping for the %(name)s form of
+// conversion or else a single value.
+func PrintfFormat(s String, args Object) (String, error) {
+	p := &printfFormatter{format: string(s)}
+	switch x := args.(type) {
+	case Tuple:
+		p.args = x
+	case String:
+		p.args = Tuple{x}
+	default:
+		p.args = Tuple{x}
+		if _, ok := x.(I__getitem__); ok || x.Type().Lookup("__getitem__") != nil {
+			p.mapping = x
+		}
+	}
+	out, err := p.run()
+	if err != nil {
+		return "", err
+	}
+	return String(out), nil
+}
@@ -6,22 +6,117 @@
 
 package py
 
+const propertyDoc = `property(fget=None, fset=None, fdel=None, doc=None) -> property attribute
+
+fget is a function to be used for getting an attribute value, and likewise
+fset is a function for setting, and fdel a function for del'ing, an
+attribute.  Typical use is to define a managed attribute x:
+
+class C(object):
+    def getx(self): return self._x
+    def setx(self, value): self._x = value
+    def delx(self): del self._x
+    x = property(getx, setx, delx, "I'm the 'x' property.")
+
+Decorators make defining new properties or modifying existing ones easy:
+
+class C(object):
+    @property
+    def x(self):
+        "I am the 'x' property."
+        return self._x
+    @x.setter
+    def x(self, value):
+        self._x = value
+    @x.deleter
+    def x(self):
+        del self._x
+`
+
 // A python Property object
+//
+// Properties made in Go set Fget, Fset and Fdel directly.  Properties
+// made with property() in python also record the python functions
+// they call in Getter, Setter and Deleter.
 type Property struct {
-	Fget func(self Object) (Object, error)
-	Fset func(self, value Object) error
-	Fdel func(self Object) error
-	Doc  string
+	Fget    func(self Object) (Object, error)
+	Fset    func(self, value Object) error
+	Fdel    func(self Object) error
+	Doc     string
+	Getter  Object
+	Setter  Object
+	Deleter Object
+	PyDoc   Object
 }
 
-var PropertyType = NewType("property", "property object")
+var PropertyType = NewTypeX("property", propertyDoc, PropertyNew, nil)
 
 // Type of this object
 func (o *Property) Type() *Type {
 	return PropertyType
 }
 
+// PropertyNew makes a property from python functions
+func PropertyNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var fget, fset, fdel, doc Object = None, None, None, None
+	err := ParseTupleAndKeywords(args, kwargs, "|OOOO:property", []string{"fget", "fset", "fdel", "doc"}, &fget, &fset, &fdel, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return newPyProperty(fget, fset, fdel, doc)
+}
+
+// Makes a property which calls the python functions passed in which
+// may be None
+func newPyProperty(fget, fset, fdel, doc Object) (*Property, error) {
+	p := &Property{
+		Getter:  fget,
+		Setter:  fset,
+		Deleter: fdel,
+		PyDoc:   doc,
+	}
+	// Use the getter's docstring if no doc was given
+	if doc == None && fget != None {
+		if fgetDoc, err := GetAttrString(fget, "__doc__"); err == nil {
+			p.PyDoc = fgetDoc
+		}
+	}
+	if s, ok := p.PyDoc.(String); ok {
+		p.Doc = string(s)
+	}
+	if fget != None {
+		p.Fget = func(self Object) (Object, error) {
+			return Call(fget, Tuple{self}, nil)
+		}
+	}
+	if fset != None {
+		p.Fset = func(self, value Object) error {
+			_, err := Call(fset, Tuple{self, value}, nil)
+			return err
+		}
+	}
+	if fdel != None {
+		p.Fdel = func(self Object) error {
+			_, err := Call(fdel, Tuple{self}, nil)
+			return err
+		}
+	}
+	return p, nil
+}
+
+// Returns the python function fn or None if it is unset
+func propertyFunction(fn Object) Object {
+	if fn == nil {
+		return None
+	}
+	return fn
+}
+
+// Reading a property from the class returns the property itself
 func (p *Property) M__get__(instance, owner Object) (Object, error) {
+	if instance == None {
+		return p, nil
+	}
 	if p.Fget == nil {
 		return nil, ExceptionNewf(AttributeError, "can't get attribute")
 	}
@@ -42,6 +137,64 @@ func (p *Property) M__delete__(instance Object) (Object, error) {
 	return None, p.Fdel(instance)
 }
 
+// Properties
+func init() {
+	PropertyType.Dict["fget"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return propertyFunction(self.(*Property).Getter), nil
+		},
+	}
+	PropertyType.Dict["fset"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return propertyFunction(self.(*Property).Setter), nil
+		},
+	}
+	PropertyType.Dict["fdel"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return propertyFunction(self.(*Property).Deleter), nil
+		},
+	}
+	PropertyType.Dict["__doc__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			p := self.(*Property)
+			if p.PyDoc != nil {
+				return p.PyDoc, nil
+			}
+			if p.Doc != "" {
+				return String(p.Doc), nil
+			}
+			return None, nil
+		},
+	}
+	PropertyType.Dict["__isabstractmethod__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			p := self.(*Property)
+			for _, fn := range []Object{p.Getter, p.Setter, p.Deleter} {
+				if fn == nil || fn == None {
+					continue
+				}
+				abstract, err := IsAbstract(fn)
+				if err != nil || abstract {
+					return NewBool(abstract), err
+				}
+			}
+			return False, nil
+		},
+	}
+	PropertyType.Dict["getter"] = MustNewMethod("getter", func(self, fget Object) (Object, error) {
+		p := self.(*Property)
+		return newPyProperty(fget, propertyFunction(p.Setter), propertyFunction(p.Deleter), propertyFunction(p.PyDoc))
+	}, 0, "Descriptor to change the getter on a property.")
+	PropertyType.Dict["setter"] = MustNewMethod("setter", func(self, fset Object) (Object, error) {
+		p := self.(*Property)
+		return newPyProperty(propertyFunction(p.Getter), fset, propertyFunction(p.Deleter), propertyFunction(p.PyDoc))
+	}, 0, "Descriptor to change the setter on a property.")
+	PropertyType.Dict["deleter"] = MustNewMethod("deleter", func(self, fdel Object) (Object, error) {
+		p := self.(*Property)
+		return newPyProperty(propertyFunction(p.Getter), propertyFunction(p.Setter), fdel, propertyFunction(p.PyDoc))
+	}, 0, "Descriptor to change the deleter on a property.")
+}
+
 // Interfaces
 var _ I__get__ = (*Property)(nil)
 var _ I__set__ = (*Property)(nil)
@@ -5,6 +5,8 @@
 // Python global definitions
 package py
 
+import "io"
+
 // Generate arithmetic boilerplate
 //go:generate go run gen.go
 
@@ -27,12 +29,22 @@ type IGoInt64 interface {
 // Some well known objects
 var (
 	// Set in vm/eval.go - to avoid circular import
-	VmRun        func(globals, locals StringDict, code *Code, closure Tuple) (res Object, err error)
-	VmRunFrame   func(frame *Frame) (res Object, err error)
-	VmEvalCodeEx func(co *Code, globals, locals StringDict, args []Object, kws StringDict, defs []Object, kwdefs StringDict, closure Tuple) (retval Object, err error)
+	VmRun            func(globals, locals StringDict, code *Code, closure Tuple) (res Object, err error)
+	VmRunFrame       func(frame *Frame) (res Object, err error)
+	VmRunFrameThrow  func(frame *Frame, exc *Exception) (res Object, err error)
+	VmEvalCodeEx     func(co *Code, globals, locals StringDict, args []Object, kws StringDict, defs []Object, kwdefs StringDict, closure Tuple) (retval Object, err error)
+	VmAddPendingCall func(fn func() error)
+	VmEvalFunction   func(f *Function, locals StringDict, args []Object, kws StringDict) (retval Object, err error)
 
 	// See compile/compile.go - set to avoid circular import
 	Compile func(str, filename, mode string, flags int, dont_inherit bool) (Object, error)
+
+	// See marshal/marshal.go - set to avoid circular import
+	MarshalCode   func(w io.Writer, code *Code) error
+	UnmarshalCode func(r io.Reader) (*Code, error)
+
+	// See weakref/weakref.go - set to avoid circular import
+	FirstWeakref func(obj Object) Object
 )
 
 // Called to create a new instance of class cls. __new__() is a static method (special-cased so you need not declare it as such) that takes the class of which an instance was requested as its first argument. The remaining arguments are those passed to the object constructor expression (the call to the class). The return value of __new__() should be the new object instance (usually an instance of cls).
@@ -636,7 +648,7 @@ type I__contains__ interface {
 }
 
 // These methods are called to implement the binary arithmetic
-// operations (+, -, *, /, //, %, divmod(), pow(), **, <<, >>, &, ^,
+// operations (+, -, *, @, /, //, %, divmod(), pow(), **, <<, >>, &, ^,
 // |). For instance, to evaluate the expression x + y, where x is an
 // instance of a class that has an __add__() method, x.__add__(y) is
 // called. The __divmod__() method should be the equivalent to using
@@ -663,6 +675,11 @@ type I__mul__ interface {
 	M__mul__(other Object) (Object, error)
 }
 
+// object.__matmul__(self, other)
+type I__matmul__ interface {
+	M__matmul__(other Object) (Object, error)
+}
+
 // object.__truediv__(self, other)
 type I__truediv__ interface {
 	M__truediv__(other Object) (Object, error)
@@ -714,7 +731,7 @@ type I__or__ interface {
 }
 
 // These methods are called to implement the binary arithmetic
-// operations (+, -, *, /, //, %, divmod(), pow(), **, <<, >>, &, ^,
+// operations (+, -, *, @, /, //, %, divmod(), pow(), **, <<, >>, &, ^,
 // |) with reflected (swapped) operands. These functions are only
 // called if the left operand does not support the corresponding
 // operation and the operands are of different types. [2] For
@@ -746,6 +763,11 @@ type I__rmul__ interface {
 	M__rmul__(other Object) (Object, error)
 }
 
+// object.__rmatmul__(self, other)
+type I__rmatmul__ interface {
+	M__rmatmul__(other Object) (Object, error)
+}
+
 // object.__rtruediv__(self, other)
 type I__rtruediv__ interface {
 	M__rtruediv__(other Object) (Object, error)
@@ -823,6 +845,11 @@ type I__imul__ interface {
 	M__imul__(other Object) (Object, error)
 }
 
+// object.__imatmul__(self, other)
+type I__imatmul__ interface {
+	M__imatmul__(other Object) (Object, error)
+}
+
 // object.__itruediv__(self, other)
 type I__itruediv__ interface {
 	M__itruediv__(other Object) (Object, error)
@@ -1038,6 +1065,28 @@ type I__exit__ interface {
 	M__exit__(exc_type, exc_value, traceback Object) (Object, error)
 }
 
+// Must return an iterator. Should be used to implement awaitable
+// objects. For instance, asyncio.Future implements this method to be
+// compatible with the await expression.
+//object.__await__(self)
+type I__await__ interface {
+	M__await__() (Object, error)
+}
+
+// Must return an asynchronous iterator object.
+//object.__aiter__(self)
+type I__aiter__ interface {
+	M__aiter__() (Object, error)
+}
+
+// Must return an awaitable resulting in a next value of the
+// iterator. Should raise a StopAsyncIteration error when the
+// iteration is over.
+//object.__anext__(self)
+type I__anext__ interface {
+	M__anext__() (Object, error)
+}
+
 // Return the ceiling of x, the smallest integer greater than or equal
 // to x. If x is not a float, delegates to x.__ceil__(), which should
 // return an Integral value.
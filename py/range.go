@@ -132,7 +132,7 @@ func (it *RangeIterator) M__next__() (Object, error) {
 		return nil, StopIteration
 	}
 	it.Index += it.Step
-	return r, nil
+	return intObject(r), nil
 }
 
 func computeItem(r *Range, item Int) Int {
@@ -236,6 +236,7 @@ func computeRangeSlice(r *Range, s *Slice) (Object, error) {
 // Check interface is satisfied
 var _ I__getitem__ = (*Range)(nil)
 var _ I__iter__ = (*Range)(nil)
+var _ I__hash__ = (*Range)(nil)
 var _ I_iterator = (*RangeIterator)(nil)
 
 func (a *Range) M__eq__(other Object) (Object, error) {
@@ -255,7 +256,7 @@ func (a *Range) M__eq__(other Object) (Object, error) {
 		return False, nil
 	}
 
-	if a.Step == 1 {
+	if a.Length == 1 {
 		return True, nil
 	}
 	if a.Step != b.Step {
@@ -282,7 +283,7 @@ func (a *Range) M__ne__(other Object) (Object, error) {
 		return True, nil
 	}
 
-	if a.Step == 1 {
+	if a.Length == 1 {
 		return False, nil
 	}
 	if a.Step != b.Step {
@@ -291,3 +292,21 @@ func (a *Range) M__ne__(other Object) (Object, error) {
 
 	return False, nil
 }
+
+// Ranges which compare equal have the same hash as CPython's do
+func (a *Range) M__hash__() (Object, error) {
+	var h int64
+	var err error
+	switch a.Length {
+	case 0:
+		h, err = hashTuple(Tuple{a.Length, None, None})
+	case 1:
+		h, err = hashTuple(Tuple{a.Length, a.Start, None})
+	default:
+		h, err = hashTuple(Tuple{a.Length, a.Start, a.Step})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Int(h), nil
+}
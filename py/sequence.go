@@ -14,8 +14,12 @@ func SequenceTuple(v Object) (Tuple, error) {
 	case *List:
 		return Tuple(x.Items).Copy(), nil
 	default:
+		err := checkSized(v, objectSize)
+		if err != nil {
+			return nil, err
+		}
 		t := Tuple{}
-		err := Iterate(v, func(item Object) bool {
+		err = Iterate(v, func(item Object) bool {
 			t = append(t, item)
 			return false
 		})
@@ -34,8 +38,12 @@ func SequenceList(v Object) (*List, error) {
 	case *List:
 		return x.Copy(), nil
 	default:
+		err := checkSized(v, objectSize)
+		if err != nil {
+			return nil, err
+		}
 		l := NewList()
-		err := l.ExtendSequence(v)
+		err = l.ExtendSequence(v)
 		if err != nil {
 			return nil, err
 		}
@@ -47,15 +55,12 @@ func SequenceList(v Object) (*List, error) {
 func SequenceSet(v Object) (*Set, error) {
 	switch x := v.(type) {
 	case Tuple:
-		return NewSetFromItems(x), nil
+		return NewSetFromItems(x)
 	case *List:
-		return NewSetFromItems(x.Items), nil
+		return NewSetFromItems(x.Items)
 	default:
 		s := NewSet()
-		err := Iterate(v, func(item Object) bool {
-			s.Add(item)
-			return false
-		})
+		err := s.UpdateIterable(v)
 		if err != nil {
 			return nil, err
 		}
@@ -114,7 +119,7 @@ func Iterate(obj Object, fn func(Object) bool) error {
 		}
 		for {
 			item, err := Next(iterator)
-			if err == StopIteration {
+			if IsException(StopIteration, err) {
 				break
 			}
 			if err != nil {
@@ -170,3 +175,22 @@ func SequenceContains(seq, obj Object) (found bool, err error) {
 	}
 	return found, err
 }
+
+// Orders the sequences a and b using cmp on the first items which
+// aren't equal, or on their lengths if one is a prefix of the other
+func sequenceCompare(a, b []Object, cmp func(a, b Object) (Object, error)) (Object, error) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		eq, err := Eq(a[i], b[i])
+		if err != nil {
+			return nil, err
+		}
+		eq, err = MakeBool(eq)
+		if err != nil {
+			return nil, err
+		}
+		if eq == False {
+			return cmp(a[i], b[i])
+		}
+	}
+	return cmp(Int(len(a)), Int(len(b)))
+}
@@ -4,7 +4,10 @@
 
 // Set and FrozenSet types
 //
-// FIXME preliminary implementation only - doesn't work properly!
+// Items are stored in a hash table keyed on the python hash of the
+// item so any hashable object may be stored and items which compare
+// equal, such as 1 and 1.0, are only stored once.  Iteration is in
+// insertion order.
 
 package py
 
@@ -12,10 +15,182 @@ import "bytes"
 
 var SetType = NewTypeX("set", "set() -> new empty set object\nset(iterable) -> new set object\n\nBuild an unordered collection of unique elements.", SetNew, nil)
 
-type SetValue struct{}
-
+// The values of the hash table are unused
 type Set struct {
-	items map[Object]SetValue
+	hashTable
+}
+
+func init() {
+	// Methods which don't modify the set are shared with frozenset
+	for _, t := range []*Type{SetType, FrozenSetType} {
+		t.Dict["copy"] = MustNewMethod("copy", setCopy, 0, "Return a shallow copy of a set.")
+		t.Dict["difference"] = MustNewMethod("difference", func(self Object, args Tuple) (Object, error) {
+			return setMultiOp(self, args, (*Set).Difference)
+		}, 0, "Return the difference of two or more sets as a new set.\n\n(i.e. all elements that are in this set but not the others.)")
+		t.Dict["intersection"] = MustNewMethod("intersection", func(self Object, args Tuple) (Object, error) {
+			return setMultiOp(self, args, (*Set).Intersection)
+		}, 0, "Return the intersection of two sets as a new set.\n\n(i.e. all elements that are in both sets.)")
+		t.Dict["union"] = MustNewMethod("union", func(self Object, args Tuple) (Object, error) {
+			return setMultiOp(self, args, (*Set).Union)
+		}, 0, "Return the union of sets as a new set.\n\n(i.e. all elements that are in either set.)")
+		t.Dict["symmetric_difference"] = MustNewMethod("symmetric_difference", func(self Object, args Tuple) (Object, error) {
+			var other Object
+			err := UnpackTuple(args, nil, "symmetric_difference", 1, 1, &other)
+			if err != nil {
+				return nil, err
+			}
+			return setMultiOp(self, args, (*Set).SymmetricDifference)
+		}, 0, "Return the symmetric difference of two sets as a new set.\n\n(i.e. all elements that are in exactly one of the sets.)")
+		t.Dict["isdisjoint"] = MustNewMethod("isdisjoint", func(self Object, args Tuple) (Object, error) {
+			return setPredicate(self, args, "isdisjoint", (*Set).IsDisjoint)
+		}, 0, "Return True if two sets have a null intersection.")
+		t.Dict["issubset"] = MustNewMethod("issubset", func(self Object, args Tuple) (Object, error) {
+			return setPredicate(self, args, "issubset", (*Set).IsSubset)
+		}, 0, "Report whether another set contains this set.")
+		t.Dict["issuperset"] = MustNewMethod("issuperset", func(self Object, args Tuple) (Object, error) {
+			return setPredicate(self, args, "issuperset", func(a, b *Set) (bool, error) {
+				return b.IsSubset(a)
+			})
+		}, 0, "Report whether this set contains another set.")
+	}
+
+	SetType.Dict["add"] = MustNewMethod("add", func(self Object, args Tuple) (Object, error) {
+		var item Object
+		err := UnpackTuple(args, nil, "add", 1, 1, &item)
+		if err != nil {
+			return nil, err
+		}
+		return None, self.(*Set).Add(item)
+	}, 0, "Add an element to a set.\n\nThis has no effect if the element is already present.")
+	SetType.Dict["clear"] = MustNewMethod("clear", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "clear", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		self.(*Set).Clear()
+		return None, nil
+	}, 0, "Remove all elements from this set.")
+	SetType.Dict["discard"] = MustNewMethod("discard", func(self Object, args Tuple) (Object, error) {
+		var item Object
+		err := UnpackTuple(args, nil, "discard", 1, 1, &item)
+		if err != nil {
+			return nil, err
+		}
+		_, err = self.(*Set).Discard(item)
+		return None, err
+	}, 0, "Remove an element from a set if it is a member.\n\nIf the element is not a member, do nothing.")
+	SetType.Dict["remove"] = MustNewMethod("remove", func(self Object, args Tuple) (Object, error) {
+		var item Object
+		err := UnpackTuple(args, nil, "remove", 1, 1, &item)
+		if err != nil {
+			return nil, err
+		}
+		found, err := self.(*Set).Discard(item)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, &Exception{Base: KeyError, Args: Tuple{item}}
+		}
+		return None, nil
+	}, 0, "Remove an element from a set; it must be a member.\n\nIf the element is not a member, raise a KeyError.")
+	SetType.Dict["pop"] = MustNewMethod("pop", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "pop", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		s := self.(*Set)
+		for i, e := range s.entries {
+			if e.key != nil {
+				s.remove(i, e.hash)
+				return e.key, nil
+			}
+		}
+		return nil, ExceptionNewf(KeyError, "pop from an empty set")
+	}, 0, "Remove and return an arbitrary set element.\nRaises KeyError if the set is empty.")
+	SetType.Dict["update"] = MustNewMethod("update", func(self Object, args Tuple) (Object, error) {
+		s := self.(*Set)
+		for _, arg := range args {
+			err := s.UpdateIterable(arg)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return None, nil
+	}, 0, "Update a set with the union of itself and others.")
+	SetType.Dict["difference_update"] = MustNewMethod("difference_update", func(self Object, args Tuple) (Object, error) {
+		return setMultiUpdate(self, args, (*Set).Difference)
+	}, 0, "Remove all elements of another set from this set.")
+	SetType.Dict["intersection_update"] = MustNewMethod("intersection_update", func(self Object, args Tuple) (Object, error) {
+		return setMultiUpdate(self, args, (*Set).Intersection)
+	}, 0, "Update a set with the intersection of itself and another.")
+	SetType.Dict["symmetric_difference_update"] = MustNewMethod("symmetric_difference_update", func(self Object, args Tuple) (Object, error) {
+		var other Object
+		err := UnpackTuple(args, nil, "symmetric_difference_update", 1, 1, &other)
+		if err != nil {
+			return nil, err
+		}
+		return setMultiUpdate(self, args, (*Set).SymmetricDifference)
+	}, 0, "Update a set with the symmetric difference of itself and another.")
+}
+
+// Returns a copy of a set or the frozenset itself
+func setCopy(self Object, args Tuple) (Object, error) {
+	err := UnpackTuple(args, nil, "copy", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if fs, ok := self.(*FrozenSet); ok {
+		return fs, nil
+	}
+	return self.(*Set).Copy(), nil
+}
+
+// Applies op to self and each of the iterables in others in turn
+// returning a new set of the same type as self
+func setMultiOp(self Object, others Tuple, op func(a, b *Set) (*Set, error)) (Object, error) {
+	res := asSet(self).Copy()
+	for _, other := range others {
+		b, err := toSet(other)
+		if err != nil {
+			return nil, err
+		}
+		res, err = op(res, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sameSetType(self, res), nil
+}
+
+// Applies op to the set self and each of the iterables in others in
+// turn updating self in place
+func setMultiUpdate(self Object, others Tuple, op func(a, b *Set) (*Set, error)) (Object, error) {
+	s := self.(*Set)
+	res, err := setMultiOp(s, others, op)
+	if err != nil {
+		return nil, err
+	}
+	s.replace(res.(*Set))
+	return None, nil
+}
+
+// Calls the predicate fn on self and the iterable passed in args
+func setPredicate(self Object, args Tuple, name string, fn func(a, b *Set) (bool, error)) (Object, error) {
+	var other Object
+	err := UnpackTuple(args, nil, name, 1, 1, &other)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toSet(other)
+	if err != nil {
+		return nil, err
+	}
+	res, err := fn(asSet(self), b)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(res), nil
 }
 
 // Type of this Set object
@@ -25,30 +200,205 @@ func (o *Set) Type() *Type {
 
 // Make a new empty set
 func NewSet() *Set {
-	return &Set{
-		items: make(map[Object]SetValue),
-	}
+	return NewSetWithCapacity(0)
 }
 
 // Make a new empty set with capacity for n items
 func NewSetWithCapacity(n int) *Set {
-	return &Set{
-		items: make(map[Object]SetValue, n),
-	}
+	return &Set{hashTable: newHashTable(n)}
 }
 
 // Make a new set with the items passed in
-func NewSetFromItems(items []Object) *Set {
+//
+// Returns a TypeError if any of the items are unhashable
+func NewSetFromItems(items []Object) (*Set, error) {
 	s := NewSetWithCapacity(len(items))
-	for _, item := range items {
-		s.items[item] = SetValue{}
+	err := s.Update(items)
+	if err != nil {
+		return nil, err
 	}
-	return s
+	return s, nil
 }
 
 // Add an item to the set
-func (s *Set) Add(item Object) {
-	s.items[item] = SetValue{}
+//
+// Returns a TypeError if the item is unhashable
+func (s *Set) Add(item Object) error {
+	i, h, err := s.find(item)
+	if err != nil || i >= 0 {
+		return err
+	}
+	s.insert(h, item, nil)
+	return nil
+}
+
+// Extend the set with items
+func (s *Set) Update(items []Object) error {
+	for _, item := range items {
+		err := s.Add(item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extend the set with the items from an iterable
+func (s *Set) UpdateIterable(iterable Object) error {
+	switch x := iterable.(type) {
+	case *Set:
+		return s.Update(x.Items())
+	case *FrozenSet:
+		return s.Update(x.Items())
+	}
+	var loopErr error
+	err := Iterate(iterable, func(item Object) bool {
+		loopErr = s.Add(item)
+		return loopErr != nil
+	})
+	if err == nil {
+		err = loopErr
+	}
+	return err
+}
+
+// Contains returns true if item is in the set
+func (s *Set) Contains(item Object) (bool, error) {
+	i, _, err := s.find(item)
+	return i >= 0, err
+}
+
+// Discard removes item from the set if present returning whether it
+// was found
+func (s *Set) Discard(item Object) (bool, error) {
+	i, h, err := s.find(item)
+	if err != nil || i < 0 {
+		return false, err
+	}
+	s.remove(i, h)
+	return true, nil
+}
+
+// Clear removes all the items from the set
+func (s *Set) Clear() {
+	s.clear()
+}
+
+// Items returns the items in the set in insertion order
+func (s *Set) Items() []Object {
+	return s.keys()
+}
+
+// Copy returns a shallow copy of the set
+func (s *Set) Copy() *Set {
+	return &Set{hashTable: s.copy()}
+}
+
+// Union returns a new set with the items from s and other
+func (s *Set) Union(other *Set) (*Set, error) {
+	ret := s.Copy()
+	for _, e := range other.entries {
+		if e.key == nil {
+			continue
+		}
+		err := ret.Add(e.key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Intersection returns a new set with the items common to s and other
+func (s *Set) Intersection(other *Set) (*Set, error) {
+	ret := NewSet()
+	for _, e := range s.entries {
+		if e.key == nil {
+			continue
+		}
+		found, err := other.Contains(e.key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ret.insert(e.hash, e.key, nil)
+		}
+	}
+	return ret, nil
+}
+
+// Difference returns a new set with the items in s but not in other
+func (s *Set) Difference(other *Set) (*Set, error) {
+	ret := NewSet()
+	for _, e := range s.entries {
+		if e.key == nil {
+			continue
+		}
+		found, err := other.Contains(e.key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			ret.insert(e.hash, e.key, nil)
+		}
+	}
+	return ret, nil
+}
+
+// SymmetricDifference returns a new set with the items in either s
+// or other but not both
+func (s *Set) SymmetricDifference(other *Set) (*Set, error) {
+	ret, err := s.Difference(other)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range other.entries {
+		if e.key == nil {
+			continue
+		}
+		found, err := s.Contains(e.key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			ret.insert(e.hash, e.key, nil)
+		}
+	}
+	return ret, nil
+}
+
+// IsSubset returns true if every item of s is in other
+func (s *Set) IsSubset(other *Set) (bool, error) {
+	if s.used > other.used {
+		return false, nil
+	}
+	for _, e := range s.entries {
+		if e.key == nil {
+			continue
+		}
+		found, err := other.Contains(e.key)
+		if err != nil || !found {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// IsDisjoint returns true if s and other have no items in common
+func (s *Set) IsDisjoint(other *Set) (bool, error) {
+	if s.used > other.used {
+		s, other = other, s
+	}
+	for _, e := range s.entries {
+		if e.key == nil {
+			continue
+		}
+		found, err := other.Contains(e.key)
+		if err != nil || found {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
 // SetNew
@@ -64,7 +414,7 @@ func SetNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	return NewSet(), nil
 }
 
-var FrozenSetType = NewType("frozenset", "frozenset() -> empty frozenset object\nfrozenset(iterable) -> frozenset object\n\nBuild an immutable unordered collection of unique elements.")
+var FrozenSetType = NewTypeX("frozenset", "frozenset() -> empty frozenset object\nfrozenset(iterable) -> frozenset object\n\nBuild an immutable unordered collection of unique elements.", FrozenSetNew, nil)
 
 type FrozenSet struct {
 	Set
@@ -83,164 +433,329 @@ func NewFrozenSet() *FrozenSet {
 }
 
 // Make a new set with the items passed in
-func NewFrozenSetFromItems(items []Object) *FrozenSet {
-	return &FrozenSet{
-		Set: *NewSetFromItems(items),
+//
+// Returns a TypeError if any of the items are unhashable
+func NewFrozenSetFromItems(items []Object) (*FrozenSet, error) {
+	s, err := NewSetFromItems(items)
+	if err != nil {
+		return nil, err
 	}
+	return &FrozenSet{Set: *s}, nil
 }
 
-// Extend the set with items
-func (s *Set) Update(items []Object) {
-	for _, item := range items {
-		s.items[item] = SetValue{}
+// FrozenSetNew
+func FrozenSetNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var iterable Object
+	err := UnpackTuple(args, kwargs, "frozenset", 0, 1, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	if fs, ok := iterable.(*FrozenSet); ok {
+		return fs, nil
 	}
+	fs := NewFrozenSet()
+	if iterable != nil {
+		err = fs.UpdateIterable(iterable)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
+func (s *FrozenSet) M__hash__() (Object, error) {
+	// Combine the hashes of the items in an order independent way
+	// as CPython does
+	shuffle := func(h uint64) uint64 {
+		return ((h ^ 89869747) ^ (h << 16)) * 3644798167
+	}
+	var x uint64
+	for _, e := range s.entries {
+		if e.key == nil {
+			continue
+		}
+		x ^= shuffle(uint64(e.hash))
+	}
+	x ^= (uint64(s.used) + 1) * 1927868237
+	// Disperse patterns arising in nested frozensets
+	x ^= (x >> 11) ^ (x >> 25)
+	x = x*69069 + 907133923
+	h := int64(x)
+	if h == -1 {
+		h = 590923713
+	}
+	return Int(h), nil
+}
+
+// Returns the *Set inside a set or frozenset or nil if obj is neither
+func asSet(obj Object) *Set {
+	switch x := obj.(type) {
+	case *Set:
+		return x
+	case *FrozenSet:
+		return &x.Set
+	}
+	return nil
+}
+
+// Returns the *Set inside a set or frozenset or makes a new set from
+// the iterable obj
+func toSet(obj Object) (*Set, error) {
+	if s := asSet(obj); s != nil {
+		return s, nil
+	}
+	return SequenceSet(obj)
+}
+
+// Returns s as the same type as self, a set or a frozenset
+func sameSetType(self Object, s *Set) Object {
+	if _, ok := self.(*FrozenSet); ok {
+		return &FrozenSet{Set: *s}
+	}
+	return s
 }
 
 func (s *Set) M__len__() (Object, error) {
-	return Int(len(s.items)), nil
+	return Int(s.used), nil
 }
 
 func (s *Set) M__bool__() (Object, error) {
-	return NewBool(len(s.items) > 0), nil
+	return NewBool(s.used > 0), nil
 }
 
-func (s *Set) M__repr__() (Object, error) {
+func (s *Set) M__contains__(item Object) (Object, error) {
+	found, err := s.Contains(item)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(found), nil
+}
+
+// Writes the items of the set as {a, b, ...}
+func (s *Set) reprItems() (string, error) {
+	seen, err := reprEnter(s)
+	if err != nil || seen {
+		return "{...}", err
+	}
+	defer reprLeave()
 	var out bytes.Buffer
 	out.WriteRune('{')
 	spacer := false
-	for item := range s.items {
+	for _, item := range s.Items() {
 		if spacer {
 			out.WriteString(", ")
 		}
 		str, err := ReprAsString(item)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		out.WriteString(str)
 		spacer = true
 	}
 	out.WriteRune('}')
-	return String(out.String()), nil
+	return out.String(), nil
 }
 
-func (s *Set) M__iter__() (Object, error) {
-	items := make(Tuple, 0, len(s.items))
-	for item := range s.items {
-		items = append(items, item)
+func (s *Set) M__repr__() (Object, error) {
+	if s.used == 0 {
+		return String("set()"), nil
 	}
-	return NewIterator(items), nil
+	str, err := s.reprItems()
+	if err != nil {
+		return nil, err
+	}
+	return String(str), nil
 }
 
-func (s *Set) M__and__(other Object) (Object, error) {
-	ret := NewSet()
-	b, ok := other.(*Set)
-	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
+func (s *FrozenSet) M__repr__() (Object, error) {
+	if s.used == 0 {
+		return String("frozenset()"), nil
 	}
-	for i := range b.items {
-		if _, ok := s.items[i]; ok {
-			ret.items[i] = SetValue{}
-		}
+	str, err := s.reprItems()
+	if err != nil {
+		return nil, err
 	}
-	return ret, nil
+	return String("frozenset(" + str + ")"), nil
 }
 
-func (s *Set) M__or__(other Object) (Object, error) {
-	ret := NewSet()
-	b, ok := other.(*Set)
-	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
-	}
-	for j := range s.items {
-		ret.items[j] = SetValue{}
+func (s *Set) M__iter__() (Object, error) {
+	return NewIterator(s.Items()), nil
+}
+
+// Applies the binary set operation op to a and b returning a result
+// of the same type as a or NotImplemented if either isn't a set
+func setBinaryOp(a, b Object, op func(a, b *Set) (*Set, error)) (Object, error) {
+	sa, sb := asSet(a), asSet(b)
+	if sa == nil || sb == nil {
+		return NotImplemented, nil
 	}
-	for i := range b.items {
-		if _, ok := s.items[i]; !ok {
-			ret.items[i] = SetValue{}
-		}
+	res, err := op(sa, sb)
+	if err != nil {
+		return nil, err
 	}
-	return ret, nil
+	return sameSetType(a, res), nil
+}
+
+func (s *Set) M__and__(other Object) (Object, error) {
+	return setBinaryOp(s, other, (*Set).Intersection)
+}
+
+func (s *Set) M__or__(other Object) (Object, error) {
+	return setBinaryOp(s, other, (*Set).Union)
 }
 
 func (s *Set) M__sub__(other Object) (Object, error) {
-	ret := NewSet()
-	b, ok := other.(*Set)
-	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
-	}
-	for j := range s.items {
-		ret.items[j] = SetValue{}
-	}
-	for i := range b.items {
-		if _, ok := s.items[i]; ok {
-			delete(ret.items, i)
-		}
-	}
-	return ret, nil
+	return setBinaryOp(s, other, (*Set).Difference)
 }
 
 func (s *Set) M__xor__(other Object) (Object, error) {
-	ret := NewSet()
-	b, ok := other.(*Set)
-	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
-	}
-	for j := range s.items {
-		ret.items[j] = SetValue{}
+	return setBinaryOp(s, other, (*Set).SymmetricDifference)
+}
+
+func (s *FrozenSet) M__and__(other Object) (Object, error) {
+	return setBinaryOp(s, other, (*Set).Intersection)
+}
+
+func (s *FrozenSet) M__or__(other Object) (Object, error) {
+	return setBinaryOp(s, other, (*Set).Union)
+}
+
+func (s *FrozenSet) M__sub__(other Object) (Object, error) {
+	return setBinaryOp(s, other, (*Set).Difference)
+}
+
+func (s *FrozenSet) M__xor__(other Object) (Object, error) {
+	return setBinaryOp(s, other, (*Set).SymmetricDifference)
+}
+
+// Replaces the contents of s with res
+func (s *Set) replace(res *Set) {
+	s.hashTable = res.hashTable
+}
+
+// Applies the binary set operation op to s and other updating s in
+// place or returns NotImplemented if other isn't a set
+func (s *Set) inplaceOp(other Object, op func(a, b *Set) (*Set, error)) (Object, error) {
+	b := asSet(other)
+	if b == nil {
+		return NotImplemented, nil
 	}
-	for i := range b.items {
-		_, ok := s.items[i]
-		if ok {
-			delete(ret.items, i)
-		} else {
-			ret.items[i] = SetValue{}
-		}
+	res, err := op(s, b)
+	if err != nil {
+		return nil, err
 	}
-	return ret, nil
+	s.replace(res)
+	return s, nil
+}
+
+func (s *Set) M__iand__(other Object) (Object, error) {
+	return s.inplaceOp(other, (*Set).Intersection)
+}
+
+func (s *Set) M__ior__(other Object) (Object, error) {
+	return s.inplaceOp(other, (*Set).Union)
+}
+
+func (s *Set) M__isub__(other Object) (Object, error) {
+	return s.inplaceOp(other, (*Set).Difference)
+}
+
+func (s *Set) M__ixor__(other Object) (Object, error) {
+	return s.inplaceOp(other, (*Set).SymmetricDifference)
+}
+
+// Frozen sets can't be updated in place so the in place operators
+// return NotImplemented to make a new frozenset with the binary
+// operator instead
+func (s *FrozenSet) M__iand__(other Object) (Object, error) {
+	return NotImplemented, nil
+}
+
+func (s *FrozenSet) M__ior__(other Object) (Object, error) {
+	return NotImplemented, nil
+}
+
+func (s *FrozenSet) M__isub__(other Object) (Object, error) {
+	return NotImplemented, nil
+}
+
+func (s *FrozenSet) M__ixor__(other Object) (Object, error) {
+	return NotImplemented, nil
 }
 
 // Check interface is satisfied
 var _ I__len__ = (*Set)(nil)
 var _ I__bool__ = (*Set)(nil)
 var _ I__iter__ = (*Set)(nil)
+var _ I__contains__ = (*Set)(nil)
+var _ I__hash__ = (*FrozenSet)(nil)
+var _ richComparison = (*Set)(nil)
 
-// var _ richComparison = (*Set)(nil)
-
-func (a *Set) M__eq__(other Object) (Object, error) {
-	b, ok := other.(*Set)
-	if !ok {
+// Compares a and b as sets with cmp or returns NotImplemented if
+// other isn't a set
+func setCompare(a *Set, other Object, cmp func(a, b *Set) (bool, error)) (Object, error) {
+	b := asSet(other)
+	if b == nil {
 		return NotImplemented, nil
 	}
-	if len(a.items) != len(b.items) {
-		return False, nil
-	}
-	// FIXME nasty O(n**2) algorithm, waiting for proper hashing!
-	for i := range a.items {
-		for j := range b.items {
-			eq, err := Eq(i, j)
-			if err != nil {
-				return nil, err
-			}
-			if eq == True {
-				goto found
-			}
-		}
-		return False, nil
-	found:
-	}
-	return True, nil
-}
-
-func (a *Set) M__ne__(other Object) (Object, error) {
-	eq, err := a.M__eq__(other)
+	res, err := cmp(a, b)
 	if err != nil {
 		return nil, err
 	}
-	if eq == NotImplemented {
-		return eq, nil
+	return NewBool(res), nil
+}
+
+func setEq(a, b *Set) (bool, error) {
+	if a.used != b.used {
+		return false, nil
 	}
-	if eq == True {
-		return False, nil
+	return a.IsSubset(b)
+}
+
+func setNe(a, b *Set) (bool, error) {
+	eq, err := setEq(a, b)
+	return !eq, err
+}
+
+func setLe(a, b *Set) (bool, error) {
+	return a.IsSubset(b)
+}
+
+func setLt(a, b *Set) (bool, error) {
+	if a.used >= b.used {
+		return false, nil
 	}
-	return True, nil
+	return a.IsSubset(b)
+}
+
+func setGe(a, b *Set) (bool, error) {
+	return b.IsSubset(a)
+}
+
+func setGt(a, b *Set) (bool, error) {
+	return setLt(b, a)
+}
+
+func (a *Set) M__eq__(other Object) (Object, error) {
+	return setCompare(a, other, setEq)
+}
+
+func (a *Set) M__ne__(other Object) (Object, error) {
+	return setCompare(a, other, setNe)
+}
+
+func (a *Set) M__lt__(other Object) (Object, error) {
+	return setCompare(a, other, setLt)
+}
+
+func (a *Set) M__le__(other Object) (Object, error) {
+	return setCompare(a, other, setLe)
+}
+
+func (a *Set) M__gt__(other Object) (Object, error) {
+	return setCompare(a, other, setGt)
+}
+
+func (a *Set) M__ge__(other Object) (Object, error) {
+	return setCompare(a, other, setGe)
 }
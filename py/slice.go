@@ -151,6 +151,22 @@ func (r *Slice) GetIndices(length int) (start, stop, step, slicelength int, err
 	return
 }
 
+func (r *Slice) M__repr__() (Object, error) {
+	start, err := ReprAsString(r.Start)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := ReprAsString(r.Stop)
+	if err != nil {
+		return nil, err
+	}
+	step, err := ReprAsString(r.Step)
+	if err != nil {
+		return nil, err
+	}
+	return String("slice(" + start + ", " + stop + ", " + step + ")"), nil
+}
+
 func (a *Slice) M__eq__(other Object) (Object, error) {
 	b, ok := other.(*Slice)
 	if !ok {
@@ -195,6 +211,31 @@ func init() {
 			return selfSlice.Step, nil
 		},
 	}
+	SliceType.Dict["indices"] = MustNewMethod("indices", func(self Object, args Tuple) (Object, error) {
+		var lengthObj Object
+		err := UnpackTuple(args, nil, "indices", 1, 1, &lengthObj)
+		if err != nil {
+			return nil, err
+		}
+		length, err := IndexInt(lengthObj)
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, ExceptionNewf(ValueError, "length should not be negative")
+		}
+		start, stop, step, _, err := self.(*Slice).GetIndices(length)
+		if err != nil {
+			return nil, err
+		}
+		return Tuple{Int(start), Int(stop), Int(step)}, nil
+	}, 0, `S.indices(len) -> (start, stop, stride)
+
+Assuming a sequence of length len, calculate the start and stop
+indices, and the stride length of the extended slice described by
+S. Out of bounds indices are clipped in a manner consistent with the
+handling of normal slices.`)
 }
 
 // Check interface is satisfied
+var _ I__repr__ = (*Slice)(nil)
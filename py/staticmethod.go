@@ -63,6 +63,12 @@ func init() {
 			return self.(*StaticMethod).Callable, nil
 		},
 	}
+	StaticMethodType.Dict["__isabstractmethod__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			abstract, err := IsAbstract(self.(*StaticMethod).Callable)
+			return NewBool(abstract), err
+		},
+	}
 }
 
 // Check interface is satisfied
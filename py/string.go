@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 	"unicode/utf8"
 )
@@ -59,13 +60,13 @@ func StringEscape(a String, ascii bool) string {
 			default:
 				fmt.Fprintf(&out, `\x%02x`, c)
 			}
-		case !ascii && c < 0x7F:
-			if c == '\\' || (quote == '\'' && c == '\'') || (quote == '"' && c == '"') {
+		case c < 0x7F:
+			if !ascii && (c == '\\' || (quote == '\'' && c == '\'') || (quote == '"' && c == '"')) {
 				out.WriteRune('\\')
 			}
 			out.WriteRune(c)
 		case c < 0x100:
-			if ascii || strconv.IsPrint(c) {
+			if !ascii && strconv.IsPrint(c) {
 				out.WriteRune(c)
 			} else {
 				fmt.Fprintf(&out, "\\x%02x", c)
@@ -122,98 +123,1021 @@ func fieldsN(s string, n int) []string {
 	return out
 }
 
+// rfieldsN is like fieldsN but splits from the right
+func rfieldsN(s string, n int) []string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	out := fieldsN(string(r), n)
+	for k, field := range out {
+		f := []rune(field)
+		for i, j := 0, len(f)-1; i < j; i, j = i+1, j-1 {
+			f[i], f[j] = f[j], f[i]
+		}
+		out[k] = string(f)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// rsplitN is like strings.SplitN but splits from the right
+func rsplitN(s, sep string, n int) []string {
+	out := []string{}
+	for n < 0 || len(out) < n-1 {
+		i := strings.LastIndex(s, sep)
+		if i < 0 {
+			break
+		}
+		out = append(out, s[i+len(sep):])
+		s = s[:i]
+	}
+	out = append(out, s)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// split implements str.split and str.rsplit
+func (s String) split(args Tuple, kwargs StringDict, name string, right bool) (Object, error) {
+	var sep Object = None
+	var maxsplitObj Object
+	kwlist := []string{"sep", "maxsplit"}
+	err := ParseTupleAndKeywords(args, kwargs, "|OO:"+name, kwlist, &sep, &maxsplitObj)
+	if err != nil {
+		return nil, err
+	}
+	maxsplit := -1
+	if maxsplitObj != nil {
+		maxsplit, err = MakeGoInt(maxsplitObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var fields []string
+	switch sepStr := sep.(type) {
+	case NoneType:
+		if right {
+			fields = rfieldsN(string(s), maxsplit)
+		} else {
+			fields = fieldsN(string(s), maxsplit)
+		}
+	case String:
+		if sepStr == "" {
+			return nil, ExceptionNewf(ValueError, "empty separator")
+		}
+		n := -1
+		if maxsplit >= 0 {
+			n = maxsplit + 1
+		}
+		if right {
+			fields = rsplitN(string(s), string(sepStr), n)
+		} else {
+			fields = strings.SplitN(string(s), string(sepStr), n)
+		}
+	default:
+		return nil, ExceptionNewf(TypeError, "Can't convert '%s' object to str implicitly", sep.Type().Name)
+	}
+	o := NewListSized(len(fields))
+	for i, field := range fields {
+		o.Items[i] = String(field)
+	}
+	return o, nil
+}
+
 func init() {
-	StringType.Dict["split"] = MustNewMethod("split", func(self Object, args Tuple) (Object, error) {
-		selfStr := self.(String)
-		var value Object = None
-		zeroRemove := true
-		if len(args) > 0 {
-			if _, ok := args[0].(NoneType); !ok {
-				value = args[0]
-				zeroRemove = false
+	StringType.Dict["split"] = MustNewMethod("split", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return self.(String).split(args, kwargs, "split", false)
+	}, 0, `split(sep=None, maxsplit=-1) -> list of strings
+
+Return a list of the words in the string, using sep as the
+delimiter string.  If maxsplit is given, at most maxsplit
+splits are done. If sep is not specified or is None, any
+whitespace string is a separator and empty strings are
+removed from the result.`)
+
+	StringType.Dict["rsplit"] = MustNewMethod("rsplit", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return self.(String).split(args, kwargs, "rsplit", true)
+	}, 0, `rsplit(sep=None, maxsplit=-1) -> list of strings
+
+Return a list of the words in the string, using sep as the
+delimiter string, starting at the end of the string and
+working to the front.  If maxsplit is given, at most maxsplit
+splits are done. If sep is not specified, any whitespace string
+is a separator.`)
+
+	StringType.Dict["startswith"] = MustNewMethod("startswith", func(self Object, args Tuple) (Object, error) {
+		return self.(String).affix(args, "startswith", false)
+	}, 0, `startswith(prefix[, start[, end]]) -> bool
+
+Return True if S starts with the specified prefix, False otherwise.
+With optional start, test S beginning at that position.
+With optional end, stop comparing S at that position.
+prefix can also be a tuple of strings to try.`)
+
+	StringType.Dict["endswith"] = MustNewMethod("endswith", func(self Object, args Tuple) (Object, error) {
+		return self.(String).affix(args, "endswith", true)
+	}, 0, `endswith(suffix[, start[, end]]) -> bool
+
+Return True if S ends with the specified suffix, False otherwise.
+With optional start, test S beginning at that position.
+With optional end, stop comparing S at that position.
+suffix can also be a tuple of strings to try.`)
+
+	StringType.Dict["find"] = MustNewMethod("find", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "find", false)
+		return Int(i), err
+	}, 0, `find(sub[, start[, end]]) -> int
+
+Return the lowest index in S where substring sub is found,
+such that sub is contained within S[start:end].  Optional
+arguments start and end are interpreted as in slice notation.
+
+Return -1 on failure.`)
+
+	StringType.Dict["rfind"] = MustNewMethod("rfind", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "rfind", true)
+		return Int(i), err
+	}, 0, `rfind(sub[, start[, end]]) -> int
+
+Return the highest index in S where substring sub is found,
+such that sub is contained within S[start:end].  Optional
+arguments start and end are interpreted as in slice notation.
+
+Return -1 on failure.`)
+
+	StringType.Dict["index"] = MustNewMethod("index", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "index", false)
+		if err == nil && i < 0 {
+			err = ExceptionNewf(ValueError, "substring not found")
+		}
+		return Int(i), err
+	}, 0, `index(sub[, start[, end]]) -> int
+
+Like S.find() but raise ValueError when the substring is not found.`)
+
+	StringType.Dict["rindex"] = MustNewMethod("rindex", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "rindex", true)
+		if err == nil && i < 0 {
+			err = ExceptionNewf(ValueError, "substring not found")
+		}
+		return Int(i), err
+	}, 0, `rindex(sub[, start[, end]]) -> int
+
+Like S.rfind() but raise ValueError when the substring is not found.`)
+
+	StringType.Dict["count"] = MustNewMethod("count", func(self Object, args Tuple) (Object, error) {
+		var subObj, startObj, endObj Object
+		err := UnpackTuple(args, nil, "count", 1, 3, &subObj, &startObj, &endObj)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := stringArg(subObj)
+		if err != nil {
+			return nil, err
+		}
+		s, _, ok, err := self.(String).subRange(startObj, endObj)
+		if err != nil || !ok {
+			return Int(0), err
+		}
+		return Int(strings.Count(string(s), string(sub))), nil
+	}, 0, `count(sub[, start[, end]]) -> int
+
+Return the number of non-overlapping occurrences of substring sub in
+string S[start:end].  Optional arguments start and end are
+interpreted as in slice notation.`)
+
+	StringType.Dict["join"] = MustNewMethod("join", func(self Object, args Tuple) (Object, error) {
+		var iterable Object
+		err := UnpackTuple(args, nil, "join", 1, 1, &iterable)
+		if err != nil {
+			return nil, err
+		}
+		sep := string(self.(String))
+		var out strings.Builder
+		var loopErr error
+		i := 0
+		err = Iterate(iterable, func(item Object) bool {
+			s, ok := item.(String)
+			if !ok {
+				loopErr = ExceptionNewf(TypeError, "sequence item %d: expected str instance, %s found", i, item.Type().Name)
+				return true
+			}
+			if i > 0 {
+				out.WriteString(sep)
 			}
+			out.WriteString(string(s))
+			i++
+			return false
+		})
+		if err == nil {
+			err = loopErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return String(out.String()), nil
+	}, 0, `join(iterable) -> str
+
+Return a string which is the concatenation of the strings in the
+iterable.  The separator between elements is S.`)
+
+	StringType.Dict["replace"] = MustNewMethod("replace", func(self Object, args Tuple) (Object, error) {
+		var oldObj, newObj Object
+		var countObj Object = Int(-1)
+		err := UnpackTuple(args, nil, "replace", 2, 3, &oldObj, &newObj, &countObj)
+		if err != nil {
+			return nil, err
+		}
+		old, err := stringArg(oldObj)
+		if err != nil {
+			return nil, err
 		}
-		var maxSplit int = -2
-		if len(args) > 1 {
-			if m, ok := args[1].(Int); ok {
-				maxSplit = int(m)
+		new, err := stringArg(newObj)
+		if err != nil {
+			return nil, err
+		}
+		count, err := IndexInt(countObj)
+		if err != nil {
+			return nil, err
+		}
+		return String(strings.Replace(string(self.(String)), string(old), string(new), count)), nil
+	}, 0, `replace(old, new[, count]) -> str
+
+Return a copy of S with all occurrences of substring
+old replaced by new.  If the optional argument count is
+given, only the first count occurrences are replaced.`)
+
+	StringType.Dict["strip"] = MustNewMethod("strip", func(self Object, args Tuple) (Object, error) {
+		return self.(String).strip(args, "strip", true, true)
+	}, 0, `strip([chars]) -> str
+
+Return a copy of the string S with leading and trailing
+whitespace removed.
+If chars is given and not None, remove characters in chars instead.`)
+
+	StringType.Dict["lstrip"] = MustNewMethod("lstrip", func(self Object, args Tuple) (Object, error) {
+		return self.(String).strip(args, "lstrip", true, false)
+	}, 0, `lstrip([chars]) -> str
+
+Return a copy of the string S with leading whitespace removed.
+If chars is given and not None, remove characters in chars instead.`)
+
+	StringType.Dict["rstrip"] = MustNewMethod("rstrip", func(self Object, args Tuple) (Object, error) {
+		return self.(String).strip(args, "rstrip", false, true)
+	}, 0, `rstrip([chars]) -> str
+
+Return a copy of the string S with trailing whitespace removed.
+If chars is given and not None, remove characters in chars instead.`)
+
+	StringType.Dict["partition"] = MustNewMethod("partition", func(self Object, args Tuple) (Object, error) {
+		return self.(String).partition(args, "partition", false)
+	}, 0, `partition(sep) -> (head, sep, tail)
+
+Search for the separator sep in S, and return the part before it,
+the separator itself, and the part after it.  If the separator is not
+found, return S and two empty strings.`)
+
+	StringType.Dict["rpartition"] = MustNewMethod("rpartition", func(self Object, args Tuple) (Object, error) {
+		return self.(String).partition(args, "rpartition", true)
+	}, 0, `rpartition(sep) -> (head, sep, tail)
+
+Search for the separator sep in S, starting at the end of S, and return
+the part before it, the separator itself, and the part after it.  If the
+separator is not found, return two empty strings and S.`)
+
+	StringType.Dict["splitlines"] = MustNewMethod("splitlines", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var keepends Object = False
+		err := ParseTupleAndKeywords(args, kwargs, "|O:splitlines", []string{"keepends"}, &keepends)
+		if err != nil {
+			return nil, err
+		}
+		keep, err := MakeBool(keepends)
+		if err != nil {
+			return nil, err
+		}
+		o := NewList()
+		s := string(self.(String))
+		start := 0
+		for i, r := range s {
+			if i < start || !isLineBreak(r) {
+				continue
+			}
+			end := i + utf8.RuneLen(r)
+			if r == '\r' && end < len(s) && s[end] == '\n' {
+				end++
 			}
+			if keep == True {
+				o.Append(String(s[start:end]))
+			} else {
+				o.Append(String(s[start:i]))
+			}
+			start = end
 		}
-		valArray := []string{}
-		if valStr, ok := value.(String); ok {
-			valArray = strings.SplitN(string(selfStr), string(valStr), maxSplit+1)
-		} else if _, ok := value.(NoneType); ok {
-			valArray = fieldsN(string(selfStr), maxSplit)
-		} else {
-			return nil, ExceptionNewf(TypeError, "Can't convert '%s' object to str implicitly", value.Type())
+		if start < len(s) {
+			o.Append(String(s[start:]))
 		}
-		o := List{}
-		for _, j := range valArray {
-			if len(j) > 0 || !zeroRemove {
-				o.Items = append(o.Items, String(j))
+		return o, nil
+	}, 0, `splitlines([keepends]) -> list of strings
+
+Return a list of the lines in S, breaking at line boundaries.
+Line breaks are not included in the resulting list unless keepends
+is given and true.`)
+
+	StringType.Dict["lower"] = MustNewMethod("lower", func(self Object, args Tuple) (Object, error) {
+		return self.(String).mapString(args, "lower", strings.ToLower)
+	}, 0, "lower() -> str\n\nReturn a copy of the string S converted to lowercase.")
+
+	StringType.Dict["upper"] = MustNewMethod("upper", func(self Object, args Tuple) (Object, error) {
+		return self.(String).mapString(args, "upper", strings.ToUpper)
+	}, 0, "upper() -> str\n\nReturn a copy of S converted to uppercase.")
+
+	StringType.Dict["casefold"] = MustNewMethod("casefold", func(self Object, args Tuple) (Object, error) {
+		return self.(String).mapString(args, "casefold", func(s string) string {
+			return strings.Replace(strings.ToLower(s), "ß", "ss", -1)
+		})
+	}, 0, "casefold() -> str\n\nReturn a version of S suitable for caseless comparisons.")
+
+	StringType.Dict["swapcase"] = MustNewMethod("swapcase", func(self Object, args Tuple) (Object, error) {
+		return self.(String).mapString(args, "swapcase", func(s string) string {
+			return strings.Map(func(r rune) rune {
+				if unicode.IsUpper(r) {
+					return unicode.ToLower(r)
+				}
+				return unicode.ToUpper(r)
+			}, s)
+		})
+	}, 0, `swapcase() -> str
+
+Return a copy of S with uppercase characters converted to lowercase
+and vice versa.`)
+
+	StringType.Dict["capitalize"] = MustNewMethod("capitalize", func(self Object, args Tuple) (Object, error) {
+		return self.(String).mapString(args, "capitalize", func(s string) string {
+			r, size := utf8.DecodeRuneInString(s)
+			if size == 0 {
+				return s
 			}
+			return string(unicode.ToTitle(r)) + strings.ToLower(s[size:])
+		})
+	}, 0, `capitalize() -> str
+
+Return a capitalized version of S, i.e. make the first character
+have upper case and the rest lower case.`)
+
+	StringType.Dict["title"] = MustNewMethod("title", func(self Object, args Tuple) (Object, error) {
+		return self.(String).mapString(args, "title", func(s string) string {
+			previousIsCased := false
+			return strings.Map(func(r rune) rune {
+				if previousIsCased {
+					r = unicode.ToLower(r)
+				} else {
+					r = unicode.ToTitle(r)
+				}
+				previousIsCased = isCased(r)
+				return r
+			}, s)
+		})
+	}, 0, `title() -> str
+
+Return a titlecased version of S, i.e. words start with title case
+characters, all remaining cased characters have lower case.`)
+
+	StringType.Dict["center"] = MustNewMethod("center", func(self Object, args Tuple) (Object, error) {
+		return self.(String).justify(args, "center", '^')
+	}, 0, `center(width[, fillchar]) -> str
+
+Return S centered in a string of length width. Padding is
+done using the specified fill character (default is a space)`)
+
+	StringType.Dict["ljust"] = MustNewMethod("ljust", func(self Object, args Tuple) (Object, error) {
+		return self.(String).justify(args, "ljust", '<')
+	}, 0, `ljust(width[, fillchar]) -> str
+
+Return S left-justified in a Unicode string of length width. Padding is
+done using the specified fill character (default is a space).`)
+
+	StringType.Dict["rjust"] = MustNewMethod("rjust", func(self Object, args Tuple) (Object, error) {
+		return self.(String).justify(args, "rjust", '>')
+	}, 0, `rjust(width[, fillchar]) -> str
+
+Return S right-justified in a string of length width. Padding is
+done using the specified fill character (default is a space).`)
+
+	StringType.Dict["zfill"] = MustNewMethod("zfill", func(self Object, args Tuple) (Object, error) {
+		var widthObj Object
+		err := UnpackTuple(args, nil, "zfill", 1, 1, &widthObj)
+		if err != nil {
+			return nil, err
+		}
+		width, err := IndexInt(widthObj)
+		if err != nil {
+			return nil, err
+		}
+		s := self.(String)
+		n := width - s.len()
+		if n <= 0 {
+			return s, nil
+		}
+		err = checkAlloc(uint64(n), 1)
+		if err != nil {
+			return nil, err
+		}
+		sign := ""
+		if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+			sign, s = string(s[0]), s[1:]
 		}
-		return &o, nil
-	}, 0, "split(sub) -> split string with sub.")
+		return String(sign + strings.Repeat("0", n) + string(s)), nil
+	}, 0, `zfill(width) -> str
 
-	StringType.Dict["startswith"] = MustNewMethod("startswith", func(self Object, args Tuple) (Object, error) {
-		selfStr := string(self.(String))
-		prefix := []string{}
-		if len(args) > 0 {
-			if s, ok := args[0].(String); ok {
-				prefix = append(prefix, string(s))
-			} else if s, ok := args[0].(Tuple); ok {
-				for _, t := range s {
-					if v, ok := t.(String); ok {
-						prefix = append(prefix, string(v))
-					}
+Pad a numeric string S with zeros on the left, to fill a field
+of the specified width. The string S is never truncated.`)
+
+	StringType.Dict["expandtabs"] = MustNewMethod("expandtabs", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var tabsizeObj Object = Int(8)
+		err := ParseTupleAndKeywords(args, kwargs, "|O:expandtabs", []string{"tabsize"}, &tabsizeObj)
+		if err != nil {
+			return nil, err
+		}
+		tabsize, err := IndexInt(tabsizeObj)
+		if err != nil {
+			return nil, err
+		}
+		if tabsize > 0 {
+			err = checkRepeat(tabsize, 1, Int(strings.Count(string(self.(String)), "\t")))
+			if err != nil {
+				return nil, err
+			}
+		}
+		var out strings.Builder
+		column := 0
+		for _, r := range string(self.(String)) {
+			switch r {
+			case '\t':
+				if tabsize > 0 {
+					n := tabsize - column%tabsize
+					out.WriteString(strings.Repeat(" ", n))
+					column += n
 				}
-			} else {
-				return nil, ExceptionNewf(TypeError, "startswith first arg must be str, unicode, or tuple, not %s", args[0].Type())
+			case '\n', '\r':
+				out.WriteRune(r)
+				column = 0
+			default:
+				out.WriteRune(r)
+				column++
 			}
-		} else {
-			return nil, ExceptionNewf(TypeError, "startswith() takes at least 1 argument (0 given)")
 		}
-		if len(args) > 1 {
-			if s, ok := args[1].(Int); ok {
-				selfStr = selfStr[s:]
+		return String(out.String()), nil
+	}, 0, `expandtabs(tabsize=8) -> str
+
+Return a copy of S where all tab characters are expanded using spaces.
+If tabsize is not given, a tab size of 8 characters is assumed.`)
+
+	StringType.Dict["isalnum"] = MustNewMethod("isalnum", func(self Object, args Tuple) (Object, error) {
+		return self.(String).is(args, "isalnum", func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) })
+	}, 0, "isalnum() -> bool\n\nReturn True if all characters in S are alphanumeric\nand there is at least one character in S, False otherwise.")
+
+	StringType.Dict["isalpha"] = MustNewMethod("isalpha", func(self Object, args Tuple) (Object, error) {
+		return self.(String).is(args, "isalpha", unicode.IsLetter)
+	}, 0, "isalpha() -> bool\n\nReturn True if all characters in S are alphabetic\nand there is at least one character in S, False otherwise.")
+
+	StringType.Dict["isdecimal"] = MustNewMethod("isdecimal", func(self Object, args Tuple) (Object, error) {
+		return self.(String).is(args, "isdecimal", unicode.IsDigit)
+	}, 0, "isdecimal() -> bool\n\nReturn True if there are only decimal characters in S,\nFalse otherwise.")
+
+	StringType.Dict["isdigit"] = MustNewMethod("isdigit", func(self Object, args Tuple) (Object, error) {
+		return self.(String).is(args, "isdigit", isDigitRune)
+	}, 0, "isdigit() -> bool\n\nReturn True if all characters in S are digits\nand there is at least one character in S, False otherwise.")
+
+	StringType.Dict["isnumeric"] = MustNewMethod("isnumeric", func(self Object, args Tuple) (Object, error) {
+		return self.(String).is(args, "isnumeric", unicode.IsNumber)
+	}, 0, "isnumeric() -> bool\n\nReturn True if there are only numeric characters in S,\nFalse otherwise.")
+
+	StringType.Dict["isspace"] = MustNewMethod("isspace", func(self Object, args Tuple) (Object, error) {
+		return self.(String).is(args, "isspace", isSpaceRune)
+	}, 0, "isspace() -> bool\n\nReturn True if all characters in S are whitespace\nand there is at least one character in S, False otherwise.")
+
+	StringType.Dict["isprintable"] = MustNewMethod("isprintable", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "isprintable", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range string(self.(String)) {
+			if !strconv.IsPrint(r) {
+				return False, nil
 			}
 		}
+		return True, nil
+	}, 0, "isprintable() -> bool\n\nReturn True if all characters in S are considered\nprintable in repr() or S is empty, False otherwise.")
 
-		for _, s := range prefix {
-			if strings.HasPrefix(selfStr, s) {
-				return Bool(true), nil
+	StringType.Dict["isascii"] = MustNewMethod("isascii", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "isascii", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		s := self.(String)
+		for i := 0; i < len(s); i++ {
+			if s[i] >= utf8.RuneSelf {
+				return False, nil
 			}
 		}
-		return Bool(false), nil
-	}, 0, "startswith(prefix[, start[, end]]) -> bool")
+		return True, nil
+	}, 0, "isascii() -> bool\n\nReturn True if all characters in S are ASCII, False otherwise.")
 
-	StringType.Dict["endswith"] = MustNewMethod("endswith", func(self Object, args Tuple) (Object, error) {
-		selfStr := string(self.(String))
-		suffix := []string{}
-		if len(args) > 0 {
-			if s, ok := args[0].(String); ok {
-				suffix = append(suffix, string(s))
-			} else if s, ok := args[0].(Tuple); ok {
-				for _, t := range s {
-					if v, ok := t.(String); ok {
-						suffix = append(suffix, string(v))
-					}
+	StringType.Dict["isidentifier"] = MustNewMethod("isidentifier", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "isidentifier", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		s := string(self.(String))
+		if s == "" {
+			return False, nil
+		}
+		for i, r := range s {
+			if !(r == '_' || unicode.IsLetter(r) || i > 0 && (unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc))) {
+				return False, nil
+			}
+		}
+		return True, nil
+	}, 0, `isidentifier() -> bool
+
+Return True if S is a valid identifier according
+to the language definition.`)
+
+	StringType.Dict["islower"] = MustNewMethod("islower", func(self Object, args Tuple) (Object, error) {
+		return self.(String).isCase(args, "islower", unicode.IsLower)
+	}, 0, "islower() -> bool\n\nReturn True if all cased characters in S are lowercase and there is\nat least one cased character in S, False otherwise.")
+
+	StringType.Dict["isupper"] = MustNewMethod("isupper", func(self Object, args Tuple) (Object, error) {
+		return self.(String).isCase(args, "isupper", unicode.IsUpper)
+	}, 0, "isupper() -> bool\n\nReturn True if all cased characters in S are uppercase and there is\nat least one cased character in S, False otherwise.")
+
+	StringType.Dict["istitle"] = MustNewMethod("istitle", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "istitle", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		cased := false
+		previousIsCased := false
+		for _, r := range string(self.(String)) {
+			switch {
+			case unicode.IsUpper(r) || unicode.IsTitle(r):
+				if previousIsCased {
+					return False, nil
 				}
-			} else {
-				return nil, ExceptionNewf(TypeError, "endswith first arg must be str, unicode, or tuple, not %s", args[0].Type())
+				previousIsCased = true
+				cased = true
+			case unicode.IsLower(r):
+				if !previousIsCased {
+					return False, nil
+				}
+				previousIsCased = true
+				cased = true
+			default:
+				previousIsCased = false
 			}
-		} else {
-			return nil, ExceptionNewf(TypeError, "endswith() takes at least 1 argument (0 given)")
 		}
-		for _, s := range suffix {
-			if strings.HasSuffix(selfStr, s) {
-				return Bool(true), nil
+		return NewBool(cased), nil
+	}, 0, `istitle() -> bool
+
+Return True if S is a titlecased string and there is at least one
+character in S, i.e. upper- and titlecase characters may only
+follow uncased characters and lowercase characters only cased ones.
+Return False otherwise.`)
+
+	StringType.Dict["removeprefix"] = MustNewMethod("removeprefix", func(self Object, args Tuple) (Object, error) {
+		var prefixObj Object
+		err := UnpackTuple(args, nil, "removeprefix", 1, 1, &prefixObj)
+		if err != nil {
+			return nil, err
+		}
+		prefix, err := stringArg(prefixObj)
+		if err != nil {
+			return nil, err
+		}
+		return String(strings.TrimPrefix(string(self.(String)), string(prefix))), nil
+	}, 0, "removeprefix(prefix) -> str\n\nReturn a str with the given prefix string removed if present.")
+
+	StringType.Dict["removesuffix"] = MustNewMethod("removesuffix", func(self Object, args Tuple) (Object, error) {
+		var suffixObj Object
+		err := UnpackTuple(args, nil, "removesuffix", 1, 1, &suffixObj)
+		if err != nil {
+			return nil, err
+		}
+		suffix, err := stringArg(suffixObj)
+		if err != nil {
+			return nil, err
+		}
+		return String(strings.TrimSuffix(string(self.(String)), string(suffix))), nil
+	}, 0, "removesuffix(suffix) -> str\n\nReturn a str with the given suffix string removed if present.")
+
+	StringType.Dict["encode"] = MustNewMethod("encode", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var encoding, errors Object
+		err := ParseTupleAndKeywords(args, kwargs, "|ss:encode", []string{"encoding", "errors"}, &encoding, &errors)
+		if err != nil {
+			return nil, err
+		}
+		b, err := encodeStringArgs(string(self.(String)), encoding, errors)
+		if err != nil {
+			return nil, err
+		}
+		return Bytes(b), nil
+	}, 0, `encode(encoding='utf-8', errors='strict') -> bytes
+
+Encode S using the codec registered for encoding.  errors may be
+'strict', 'ignore' or 'replace'.`)
+
+	StringType.Dict["format"] = MustNewMethod("format", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return StringFormat(self.(String), args, func(key String) (Object, error) {
+			if value, ok := kwargs[string(key)]; ok {
+				return value, nil
+			}
+			return nil, &Exception{Base: KeyError, Args: Tuple{key}}
+		})
+	}, 0, `format(*args, **kwargs) -> str
+
+Return a formatted version of S, using substitutions from args and kwargs.
+The substitutions are identified by braces ('{' and '}').`)
+
+	StringType.Dict["format_map"] = MustNewMethod("format_map", func(self Object, args Tuple) (Object, error) {
+		var mapping Object
+		err := UnpackTuple(args, nil, "format_map", 1, 1, &mapping)
+		if err != nil {
+			return nil, err
+		}
+		return StringFormat(self.(String), nil, func(key String) (Object, error) {
+			return GetItem(mapping, key)
+		})
+	}, 0, `format_map(mapping) -> str
+
+Return a formatted version of S, using substitutions from mapping.
+The substitutions are identified by braces ('{' and '}').`)
+
+	StringType.Dict["translate"] = MustNewMethod("translate", func(self Object, args Tuple) (Object, error) {
+		var table Object
+		err := UnpackTuple(args, nil, "translate", 1, 1, &table)
+		if err != nil {
+			return nil, err
+		}
+		var out strings.Builder
+		for _, r := range string(self.(String)) {
+			value, err := GetItem(table, Int(r))
+			if err != nil {
+				if IsException(LookupError, err) {
+					out.WriteRune(r)
+					continue
+				}
+				return nil, err
+			}
+			switch x := value.(type) {
+			case NoneType:
+			case String:
+				out.WriteString(string(x))
+			default:
+				i, err := IndexInt(value)
+				if err != nil {
+					return nil, ExceptionNewf(TypeError, "character mapping must return integer, None or str")
+				}
+				if i < 0 || i > unicode.MaxRune {
+					return nil, ExceptionNewf(ValueError, "character mapping must be in range(0x110000)")
+				}
+				out.WriteRune(rune(i))
+			}
+		}
+		return String(out.String()), nil
+	}, 0, `translate(table) -> str
+
+Return a copy of the string S in which each character has been mapped
+through the given translation table. The table must implement
+lookup/indexing via __getitem__, for instance a dictionary or list,
+mapping Unicode ordinals to Unicode ordinals, strings, or None. If
+this operation raises LookupError, the character is left untouched.
+Characters mapped to None are deleted.`)
+
+	StringType.Dict["maketrans"] = &StaticMethod{
+		Callable: MustNewMethod("maketrans", stringMakeTrans, 0, `maketrans(x[, y[, z]]) -> dict
+
+Return a translation table usable for str.translate().
+If there is only one argument, it must be a dictionary mapping Unicode
+ordinals (integers) or characters to Unicode ordinals, strings or None.
+Character keys will be then converted to ordinals.
+If there are two arguments, they must be strings of equal length, and
+in the resulting dictionary, each character in x will be mapped to the
+character at the same position in y. If there is a third argument, it
+must be a string, whose characters will be mapped to None in the result.`),
+		Dict: NewStringDict(),
+	}
+}
+
+// Returns the argument as a String or a TypeError
+func stringArg(obj Object) (String, error) {
+	s, ok := obj.(String)
+	if !ok {
+		return "", ExceptionNewf(TypeError, "must be str, not %s", obj.Type().Name)
+	}
+	return s, nil
+}
+
+// subRange returns the part of s between the optional start and end
+// character indices along with the start index.  ok is false if the
+// range is empty because start is after end.
+func (s String) subRange(startObj, endObj Object) (sub String, start int, ok bool, err error) {
+	if (startObj == nil || startObj == None) && (endObj == nil || endObj == None) {
+		return s, 0, true, nil
+	}
+	length := s.len()
+	start, end, err := sliceIndices(startObj, endObj, length)
+	if err != nil || start > end {
+		return "", 0, false, err
+	}
+	return s.slice(start, end, length), start, true, nil
+}
+
+// find implements find, rfind, index and rindex returning the
+// character index of sub in s or -1 if not found
+func (s String) find(args Tuple, name string, right bool) (int, error) {
+	var subObj, startObj, endObj Object
+	err := UnpackTuple(args, nil, name, 1, 3, &subObj, &startObj, &endObj)
+	if err != nil {
+		return 0, err
+	}
+	sub, err := stringArg(subObj)
+	if err != nil {
+		return 0, err
+	}
+	str, start, ok, err := s.subRange(startObj, endObj)
+	if err != nil || !ok {
+		return -1, err
+	}
+	var i int
+	if right {
+		i = strings.LastIndex(string(str), string(sub))
+	} else {
+		i = strings.Index(string(str), string(sub))
+	}
+	if i < 0 {
+		return -1, nil
+	}
+	return start + utf8.RuneCountInString(string(str[:i])), nil
+}
+
+// affix implements startswith and endswith
+func (s String) affix(args Tuple, name string, suffix bool) (Object, error) {
+	var affixObj, startObj, endObj Object
+	err := UnpackTuple(args, nil, name, 1, 3, &affixObj, &startObj, &endObj)
+	if err != nil {
+		return nil, err
+	}
+	var affixes []Object
+	if t, ok := affixObj.(Tuple); ok {
+		affixes = t
+	} else {
+		affixes = []Object{affixObj}
+	}
+	str, _, ok, err := s.subRange(startObj, endObj)
+	if err != nil {
+		return nil, err
+	}
+	for _, affixObj := range affixes {
+		affix, isString := affixObj.(String)
+		if !isString {
+			return nil, ExceptionNewf(TypeError, "%s first arg must be str or a tuple of str, not %s", name, affixObj.Type().Name)
+		}
+		if !ok {
+			continue
+		}
+		if suffix && strings.HasSuffix(string(str), string(affix)) || !suffix && strings.HasPrefix(string(str), string(affix)) {
+			return True, nil
+		}
+	}
+	return False, nil
+}
+
+// strip implements strip, lstrip and rstrip
+func (s String) strip(args Tuple, name string, left, right bool) (Object, error) {
+	var charsObj Object = None
+	err := UnpackTuple(args, nil, name, 0, 1, &charsObj)
+	if err != nil {
+		return nil, err
+	}
+	str := string(s)
+	if charsObj == None {
+		if left {
+			str = strings.TrimLeftFunc(str, isSpaceRune)
+		}
+		if right {
+			str = strings.TrimRightFunc(str, isSpaceRune)
+		}
+		return String(str), nil
+	}
+	chars, err := stringArg(charsObj)
+	if err != nil {
+		return nil, err
+	}
+	if left {
+		str = strings.TrimLeft(str, string(chars))
+	}
+	if right {
+		str = strings.TrimRight(str, string(chars))
+	}
+	return String(str), nil
+}
+
+// partition implements partition and rpartition
+func (s String) partition(args Tuple, name string, right bool) (Object, error) {
+	var sepObj Object
+	err := UnpackTuple(args, nil, name, 1, 1, &sepObj)
+	if err != nil {
+		return nil, err
+	}
+	sep, err := stringArg(sepObj)
+	if err != nil {
+		return nil, err
+	}
+	if sep == "" {
+		return nil, ExceptionNewf(ValueError, "empty separator")
+	}
+	var i int
+	if right {
+		i = strings.LastIndex(string(s), string(sep))
+		if i < 0 {
+			return Tuple{String(""), String(""), s}, nil
+		}
+	} else {
+		i = strings.Index(string(s), string(sep))
+		if i < 0 {
+			return Tuple{s, String(""), String("")}, nil
+		}
+	}
+	return Tuple{s[:i], sep, s[i+len(sep):]}, nil
+}
+
+// justify implements center, ljust and rjust
+func (s String) justify(args Tuple, name string, align byte) (Object, error) {
+	var widthObj Object
+	var fillObj Object = String(" ")
+	err := UnpackTuple(args, nil, name, 1, 2, &widthObj, &fillObj)
+	if err != nil {
+		return nil, err
+	}
+	width, err := IndexInt(widthObj)
+	if err != nil {
+		return nil, err
+	}
+	fill, ok := fillObj.(String)
+	if !ok || fill.len() != 1 {
+		return nil, ExceptionNewf(TypeError, "The fill character must be exactly one character long")
+	}
+	n := width - s.len()
+	if n <= 0 {
+		return s, nil
+	}
+	err = checkRepeat(len(fill), 1, Int(n))
+	if err != nil {
+		return nil, err
+	}
+	left := 0
+	switch align {
+	case '>':
+		left = n
+	case '^':
+		left = n/2 + (n & width & 1)
+	}
+	return String(strings.Repeat(string(fill), left) + string(s) + strings.Repeat(string(fill), n-left)), nil
+}
+
+// mapString returns fn applied to s
+func (s String) mapString(args Tuple, name string, fn func(string) string) (Object, error) {
+	err := UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return String(fn(string(s))), nil
+}
+
+// is returns True if s is not empty and fn is true for every character
+func (s String) is(args Tuple, name string, fn func(rune) bool) (Object, error) {
+	err := UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if s == "" {
+		return False, nil
+	}
+	for _, r := range string(s) {
+		if !fn(r) {
+			return False, nil
+		}
+	}
+	return True, nil
+}
+
+// isCase returns True if all the cased characters in s satisfy fn
+// and there is at least one cased character
+func (s String) isCase(args Tuple, name string, fn func(rune) bool) (Object, error) {
+	err := UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	cased := false
+	for _, r := range string(s) {
+		if isCased(r) {
+			if !fn(r) {
+				return False, nil
 			}
+			cased = true
 		}
-		return Bool(false), nil
-	}, 0, "endswith(suffix[, start[, end]]) -> bool")
+	}
+	return NewBool(cased), nil
+}
 
+// Reports whether r is an upper, lower or title case character
+func isCased(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsLower(r) || unicode.IsTitle(r)
+}
+
+// Reports whether r is whitespace as Python defines it
+func isSpaceRune(r rune) bool {
+	return unicode.IsSpace(r) || r >= 0x1c && r <= 0x1f
+}
+
+// Reports whether r is a digit including the superscript and
+// subscript digits
+func isDigitRune(r rune) bool {
+	return unicode.IsDigit(r) || r == 0xb2 || r == 0xb3 || r == 0xb9 || r >= 0x2070 && r <= 0x2079 && r != 0x2071 && r != 0x2072 && r != 0x2073 || r >= 0x2080 && r <= 0x2089
+}
+
+// Reports whether r is a line boundary for splitlines
+func isLineBreak(r rune) bool {
+	switch r {
+	case '\n', '\r', '\x0b', '\x0c', '\x1c', '\x1d', '\x1e', '\x85', '\u2028', '\u2029':
+		return true
+	}
+	return false
+}
+
+// Implements str.maketrans
+func stringMakeTrans(self Object, args Tuple) (Object, error) {
+	var x, y, z Object
+	err := UnpackTuple(args, nil, "maketrans", 1, 3, &x, &y, &z)
+	if err != nil {
+		return nil, err
+	}
+	table := NewDict()
+	if y == nil {
+		d, ok := x.(*Dict)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "if you give only one argument to maketrans it must be a dict")
+		}
+		for _, item := range d.Items() {
+			key := item[0]
+			if s, ok := key.(String); ok {
+				if s.len() != 1 {
+					return nil, ExceptionNewf(ValueError, "string keys in translate table must be of length 1")
+				}
+				r, _ := utf8.DecodeRuneInString(string(s))
+				key = Int(r)
+			} else if _, ok := key.(Int); !ok {
+				return nil, ExceptionNewf(TypeError, "keys in translate table must be strings or integers")
+			}
+			err = table.Set(key, item[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return table, nil
+	}
+	xs, ok1 := x.(String)
+	ys, ok2 := y.(String)
+	if !ok1 || !ok2 {
+		return nil, ExceptionNewf(TypeError, "maketrans() arguments must be str")
+	}
+	xr, yr := []rune(string(xs)), []rune(string(ys))
+	if len(xr) != len(yr) {
+		return nil, ExceptionNewf(ValueError, "the first two maketrans arguments must have equal length")
+	}
+	for i := range xr {
+		err = table.Set(Int(xr[i]), Int(yr[i]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if z != nil {
+		zs, ok := z.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "maketrans() argument 3 must be str, not %s", z.Type().Name)
+		}
+		for _, r := range string(zs) {
+			err = table.Set(Int(r), None)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return table, nil
 }
 
 // Type of this object
@@ -228,19 +1152,60 @@ func StrNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 		encoding Object
 		errors   Object
 	)
-	// FIXME ignoring encoding and errors
-	err := ParseTupleAndKeywords(args, kwargs, "|OOO:str", []string{"bytes_or_buffer", "encoding", "errors"}, &sObj, &encoding, &errors)
+	err := ParseTupleAndKeywords(args, kwargs, "|Oss:str", []string{"object", "encoding", "errors"}, &sObj, &encoding, &errors)
 	if err != nil {
 		return nil, err
 	}
-	// FIXME ignoring encoding
-	// FIXME ignoring buffer protocol
-	return Str(sObj)
+	if encoding == nil && errors == nil {
+		return Str(sObj)
+	}
+	b, ok := convertToBytes(sObj)
+	if !ok {
+		return nil, ExceptionNewf(TypeError, "decoding to str: need a bytes-like object, %s found", sObj.Type().Name)
+	}
+	return decodeBytesArgs(b, encoding, errors)
 }
 
+// charStrings holds the strings of the characters 0..255 already
+// converted to Objects so indexing and iterating over strings doesn't
+// allocate memory for them
+var charStrings [256]Object
+
+func init() {
+	for i := range charStrings {
+		charStrings[i] = String(rune(i))
+	}
+}
+
+// Returns the string of the character c as an Object
+func charString(c rune) Object {
+	if c >= 0 && int(c) < len(charStrings) {
+		return charStrings[c]
+	}
+	return String(c)
+}
+
+// The interned strings
+var (
+	internedMu sync.Mutex
+	interned   = map[string]string{}
+)
+
 // Intern s possibly returning a reference to an already interned string
 func (s String) Intern() String {
-	// fmt.Printf("FIXME interning %q\n", s)
+	return String(InternString(string(s)))
+}
+
+// InternString returns the interned copy of s, interning s if there
+// isn't one already.  Interned strings which are equal share their
+// memory so they compare quickly.
+func InternString(s string) string {
+	internedMu.Lock()
+	defer internedMu.Unlock()
+	if t, ok := interned[s]; ok {
+		return t
+	}
+	interned[s] = s
 	return s
 }
 
@@ -257,6 +1222,10 @@ func (s String) M__bool__() (Object, error) {
 	return NewBool(len(s) > 0), nil
 }
 
+func (s String) M__hash__() (Object, error) {
+	return Int(hashString(string(s))), nil
+}
+
 // len returns length of the string in unicode characters
 func (s String) len() int {
 	return utf8.RuneCountInString(string(s))
@@ -266,6 +1235,14 @@ func (s String) M__len__() (Object, error) {
 	return Int(s.len()), nil
 }
 
+func (s String) M__iter__() (Object, error) {
+	chars := make([]Object, 0, len(s))
+	for _, c := range s {
+		chars = append(chars, charString(c))
+	}
+	return NewIterator(chars), nil
+}
+
 func (a String) M__add__(other Object) (Object, error) {
 	if b, ok := other.(String); ok {
 		return a + b, nil
@@ -289,6 +1266,9 @@ func (a String) M__mul__(other Object) (Object, error) {
 		if b < 0 {
 			b = 0
 		}
+		if err := checkRepeat(len(a), 1, b); err != nil {
+			return nil, err
+		}
 		var out bytes.Buffer
 		for i := 0; i < int(b); i++ {
 			out.WriteString(string(a))
@@ -489,22 +1469,7 @@ Changed in version 3.1: %f conversions for numbers whose absolute
 value is over 1e50 are no longer replaced by %g conversions.
 */
 func (a String) M__mod__(other Object) (Object, error) {
-	var values Tuple
-	switch b := other.(type) {
-	case Tuple:
-		values = b
-	default:
-		values = Tuple{other}
-	}
-	// FIXME not a full implementation ;-)
-	params := make([]interface{}, len(values))
-	for i := range values {
-		params[i] = values[i]
-	}
-	s := string(a)
-	s = strings.Replace(s, "%s", "%v", -1)
-	s = strings.Replace(s, "%r", "%#v", -1)
-	return String(fmt.Sprintf(s, params...)), nil
+	return PrintfFormat(a, other)
 }
 
 func (a String) M__rmod__(other Object) (Object, error) {
@@ -583,7 +1548,7 @@ func (s String) M__getitem__(key Object) (Object, error) {
 		return nil, err
 	}
 	if asciiOnly {
-		return s[i : i+1], nil
+		return charString(rune(s[i])), nil
 	}
 	s = s[s.pos(i):]
 	_, runeSize := utf8.DecodeRuneInString(string(s))
@@ -608,3 +1573,4 @@ var _ I__len__ = String("")
 var _ I__bool__ = String("")
 var _ I__getitem__ = String("")
 var _ I__contains__ = String("")
+var _ I__iter__ = String("")
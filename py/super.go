@@ -0,0 +1,202 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Super object
+//
+// super() looks up attributes in the MRO of the type of an object
+// starting after a given class.  With no arguments the class is read
+// from the __class__ cell which the compiler makes for methods using
+// super and the object is the first argument of the calling function.
+
+package py
+
+import "fmt"
+
+const superDoc = `super() -> same as super(__class__, <first argument>)
+super(type) -> unbound super object
+super(type, obj) -> bound super object; requires isinstance(obj, type)
+super(type, type2) -> bound super object; requires issubclass(type2, type)
+Typical use to call a cooperative superclass method:
+class C(B):
+    def meth(self, arg):
+        super().meth(arg)
+This works for class methods too:
+class C(B):
+    @classmethod
+    def cmeth(cls, arg):
+        super().cmeth(arg)
+`
+
+var SuperType = NewTypeX("super", superDoc, SuperNew, nil)
+
+// A python Super object
+type Super struct {
+	ThisClass *Type  // the class invoking super()
+	Obj       Object // the instance invoking super(); may be nil
+	ObjType   *Type  // the type of the instance invoking super(); may be nil
+}
+
+// Type of this object
+func (s *Super) Type() *Type {
+	return SuperType
+}
+
+// SuperNew makes a super object
+func SuperNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var typeObj, obj Object
+	if len(kwargs) != 0 {
+		return nil, ExceptionNewf(TypeError, "super() takes no keyword arguments")
+	}
+	err := UnpackTuple(args, nil, "super", 0, 2, &typeObj, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if typeObj == nil {
+		typeObj, obj, err = superFromFrame(CurrentFrame)
+		if err != nil {
+			return nil, err
+		}
+	}
+	t, ok := typeObj.(*Type)
+	if !ok || !t.Type().IsSubtype(TypeType) {
+		return nil, ExceptionNewf(TypeError, "super() argument 1 must be type, not %s", typeObj.Type().Name)
+	}
+	s := &Super{ThisClass: t}
+	if obj != nil && obj != None {
+		s.ObjType, err = superCheck(t, obj)
+		if err != nil {
+			return nil, err
+		}
+		s.Obj = obj
+	}
+	return s, nil
+}
+
+// Finds the arguments for super() with no arguments from the frame
+// of the calling function
+func superFromFrame(frame *Frame) (Object, Object, error) {
+	if frame == nil {
+		return nil, nil, ExceptionNewf(RuntimeError, "super(): no current frame")
+	}
+	co := frame.Code
+	if co.Argcount == 0 {
+		return nil, nil, ExceptionNewf(RuntimeError, "super(): no arguments")
+	}
+	obj := frame.Localsplus[0]
+	if obj == nil {
+		// The first argument might be a cell
+		for i, arg := range co.Cell2arg {
+			if arg == 0 {
+				obj = frame.CellAndFreeVars[i].(*Cell).Get()
+				break
+			}
+		}
+	}
+	if obj == nil {
+		return nil, nil, ExceptionNewf(RuntimeError, "super(): arg[0] deleted")
+	}
+	for i, name := range co.Freevars {
+		if name != "__class__" {
+			continue
+		}
+		cell, ok := frame.CellAndFreeVars[len(co.Cellvars)+i].(*Cell)
+		if !ok {
+			return nil, nil, ExceptionNewf(RuntimeError, "super(): bad __class__ cell")
+		}
+		typeObj := cell.Get()
+		if typeObj == nil {
+			return nil, nil, ExceptionNewf(RuntimeError, "super(): empty __class__ cell")
+		}
+		if t, ok := typeObj.(*Type); !ok || !t.Type().IsSubtype(TypeType) {
+			return nil, nil, ExceptionNewf(RuntimeError, "super(): __class__ is not a type (%s)", typeObj.Type().Name)
+		}
+		return typeObj, obj, nil
+	}
+	return nil, nil, ExceptionNewf(RuntimeError, "super(): __class__ cell not found")
+}
+
+// Checks that obj is an instance or subclass of t returning the type
+// whose MRO should be searched
+func superCheck(t *Type, obj Object) (*Type, error) {
+	// obj can be a class
+	if objType, ok := obj.(*Type); ok && objType.Type().IsSubtype(TypeType) && objType.IsSubtype(t) {
+		return objType, nil
+	}
+	// or an instance of the class
+	if obj.Type().IsSubtype(t) {
+		return obj.Type(), nil
+	}
+	return nil, ExceptionNewf(TypeError, "super(type, obj): obj must be an instance or subtype of type")
+}
+
+// Looks up name in the MRO of the object's type starting after the
+// class super was called with
+func (s *Super) M__getattribute__(name string) (Object, error) {
+	if s.ObjType != nil && name != "__class__" {
+		mro := s.ObjType.Mro
+		i := 0
+		for ; i < len(mro); i++ {
+			if mro[i] == Object(s.ThisClass) {
+				break
+			}
+		}
+		var instance Object = None
+		if s.Obj != Object(s.ObjType) {
+			instance = s.Obj
+		}
+		for i++; i < len(mro); i++ {
+			t, ok := mro[i].(*Type)
+			if !ok {
+				continue
+			}
+			if res, ok := t.Dict[name]; ok {
+				return descriptorGet(res, instance, s.ObjType)
+			}
+		}
+	}
+	// Otherwise look up the attributes of the super object itself
+	if res := SuperType.NativeGetAttrOrNil(name); res != nil {
+		return descriptorGet(res, s, SuperType)
+	}
+	return nil, ExceptionNewf(AttributeError, "'super' object has no attribute '%s'", name)
+}
+
+func (s *Super) M__repr__() (Object, error) {
+	if s.ObjType != nil {
+		return String(fmt.Sprintf("<super: <class '%s'>, <%s object>>", s.ThisClass.Name, s.ObjType.Name)), nil
+	}
+	return String(fmt.Sprintf("<super: <class '%s'>, NULL>", s.ThisClass.Name)), nil
+}
+
+// Properties
+func init() {
+	SuperType.Dict["__thisclass__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Super).ThisClass, nil
+		},
+		Doc: "the class invoking super()",
+	}
+	SuperType.Dict["__self__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			if obj := self.(*Super).Obj; obj != nil {
+				return obj, nil
+			}
+			return None, nil
+		},
+		Doc: "the instance invoking super(); may be None",
+	}
+	SuperType.Dict["__self_class__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			if objType := self.(*Super).ObjType; objType != nil {
+				return objType, nil
+			}
+			return None, nil
+		},
+		Doc: "the type of the instance invoking super(); may be None",
+	}
+}
+
+// Check interface is satisfied
+var _ I__getattribute__ = (*Super)(nil)
+var _ I__repr__ = (*Super)(nil)
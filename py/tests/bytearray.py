@@ -0,0 +1,166 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from libtest import assertRaises, assertRaisesText
+
+doc="constructor"
+assert bytearray() == b""
+assert bytearray(3) == b"\x00\x00\x00"
+assert bytearray([1, 2, 3]) == b"\x01\x02\x03"
+assert bytearray(b"abc") == b"abc"
+assert bytearray("héllo", "utf-8") == b"h\xc3\xa9llo"
+assert bytes(bytearray(b"abc")) == b"abc"
+a = b"abc"
+b = bytearray(a)
+b[0] = 65
+assert a == b"abc"
+assert b == b"Abc"
+
+doc="repr"
+assert repr(bytearray()) == "bytearray(b'')"
+assert repr(bytearray(b"a\x00")) == r"bytearray(b'a\x00')"
+assert str(bytearray(b"abc")) == "bytearray(b'abc')"
+
+doc="type"
+assert type(bytearray()) is bytearray
+assert type(bytearray(b"abc")[0:1]) is bytearray
+assert type(bytearray(b"a,b").split(b",")[0]) is bytearray
+assert type(bytearray(b"abc").upper()) is bytearray
+assert type(bytearray(b"abc") + b"d") is bytearray
+assert type(b"abc" + bytearray(b"d")) is bytes
+assertRaisesText(TypeError, "unhashable", hash, bytearray())
+
+doc="getitem"
+b = bytearray(b"hello")
+assert b[0] == 104
+assert b[-1] == 111
+assert b[1:3] == b"el"
+assert b[::-1] == b"olleh"
+assert list(b) == [104, 101, 108, 108, 111]
+assert b"ell" in b
+assert 104 in b
+
+doc="setitem"
+b = bytearray(b"hello")
+b[0] = 106
+assert b == b"jello"
+b[-1] = ord("y")
+assert b == b"jelly"
+assertRaises(ValueError, b.__setitem__, 0, 256)
+assertRaises(IndexError, b.__setitem__, 5, 0)
+b[1:4] = b"ELL"
+assert b == b"jELLy"
+b[1:4] = b""
+assert b == b"jy"
+b[1:1] = [1, 2, 3]
+assert b == b"j\x01\x02\x03y"
+b[::2] = b"abc"
+assert b == b"a\x01b\x03c"
+assertRaises(ValueError, b.__setitem__, slice(None, None, 2), b"ab")
+b[:] = b
+assert b == b"a\x01b\x03c"
+
+doc="delitem"
+b = bytearray(b"abcdef")
+del b[0]
+assert b == b"bcdef"
+del b[-1]
+assert b == b"bcde"
+del b[1:3]
+assert b == b"be"
+b = bytearray(b"abcdef")
+del b[::2]
+assert b == b"bdf"
+b = bytearray(b"abcdef")
+del b[::-2]
+assert b == b"ace"
+assertRaises(IndexError, b.__delitem__, 10)
+
+doc="append and extend"
+b = bytearray()
+b.append(97)
+b.append(98)
+assert b == b"ab"
+assertRaises(ValueError, b.append, 256)
+assertRaises(TypeError, b.append, b"c")
+b.extend(b"cd")
+b.extend([101, 102])
+b.extend(bytearray(b"g"))
+assert b == b"abcdefg"
+assertRaises(ValueError, b.extend, [300])
+
+doc="insert pop remove"
+b = bytearray(b"ac")
+b.insert(1, 98)
+assert b == b"abc"
+b.insert(-100, 48)
+b.insert(100, 49)
+assert b == b"0abc1"
+assert b.pop() == 49
+assert b.pop(0) == 48
+assert b == b"abc"
+b.remove(98)
+assert b == b"ac"
+assertRaises(ValueError, b.remove, 98)
+b.clear()
+assertRaises(IndexError, b.pop)
+
+doc="copy and reverse"
+b = bytearray(b"abc")
+c = b.copy()
+c.reverse()
+assert b == b"abc"
+assert c == b"cba"
+
+doc="inplace operators"
+b = bytearray(b"ab")
+c = b
+b += b"cd"
+assert c is b
+assert c == b"abcd"
+b *= 2
+assert c is b
+assert c == b"abcdabcd"
+assert bytearray(b"ab") * 2 == b"abab"
+assert 2 * bytearray(b"ab") == b"abab"
+
+doc="shared methods"
+b = bytearray(b"  Hello, World  ")
+assert b.strip() == b"Hello, World"
+assert b.split() == [b"Hello,", b"World"]
+assert b.find(b"World") == 9
+assert b.count(b"l") == 3
+assert b.replace(b"l", b"L") == b"  HeLLo, WorLd  "
+assert b.strip().startswith(b"Hello")
+assert b.strip().endswith(b"World")
+assert b.decode() == "  Hello, World  "
+assert bytearray(b"\xb9\x01\xef").hex() == "b901ef"
+assert bytearray.fromhex("b9 01 ef") == b"\xb9\x01\xef"
+assert type(bytearray.fromhex("00")) is bytearray
+assert bytearray(b",").join([b"a", b"b"]) == b"a,b"
+assert bytearray(b"a=b").partition(b"=") == (b"a", b"=", b"b")
+assert bytearray(b"hello").capitalize() == b"Hello"
+assert type(bytearray(b"ab").center(5)) is bytearray
+assert bytearray(b"ab").ljust(4, bytearray(b"*")) == b"ab**"
+assert type(bytearray(b"abc").ljust(2)) is bytearray
+assert bytearray(b"a\nb").splitlines() == [b"a", b"b"]
+assert type(bytearray(b"a\nb").splitlines()[0]) is bytearray
+assert bytearray(b"abc").removeprefix(b"a") == b"bc"
+assert type(bytearray.maketrans(b"a", b"b")) is bytes
+assert bytearray(b"abc").translate(bytes.maketrans(b"a", b"b")) == b"bbc"
+assert bytearray(b"\x01\x02").hex(":") == "01:02"
+assert bytearray(b"ABC").isupper()
+
+doc="comparison"
+assert bytearray(b"abc") == bytearray(b"abc")
+assert bytearray(b"abc") < b"abd"
+assert bytearray(b"abc") >= b"abc"
+assert bytearray(b"abc") != "abc"
+
+doc="conversions"
+assert ord(bytearray(b"a")) == 97
+assert int(bytearray(b"42")) == 42
+assert float(bytearray(b"1.5")) == 1.5
+
+doc="finished"
@@ -2,6 +2,8 @@
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
 
+from libtest import assertRaises, assertRaisesText
+
 doc="str"
 assert str(b"") == "b''"
 assert str(b"hello") == r"b'hello'"
@@ -20,4 +22,260 @@ llo""") == r"""b'he\nllo'"""
 assert repr(rb"""hel'lo""") == r'''b"hel'lo"'''
 assert repr(b'\x00\x01\x02\x03\x04\x05\x06\x07\x08\t\n\x0b\x0c\r\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f !"#$%&\'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~\x7f\x80\x81\x82\x83\x84\x85\x86\x87\x88\x89\x8a\x8b\x8c\x8d\x8e\x8f\x90\x91\x92\x93\x94\x95\x96\x97\x98\x99\x9a\x9b\x9c\x9d\x9e\x9f\xa0\xa1\xa2\xa3\xa4\xa5\xa6\xa7\xa8\xa9\xaa\xab\xac\xad\xae\xaf\xb0\xb1\xb2\xb3\xb4\xb5\xb6\xb7\xb8\xb9\xba\xbb\xbc\xbd\xbe\xbf\xc0\xc1\xc2\xc3\xc4\xc5\xc6\xc7\xc8\xc9\xca\xcb\xcc\xcd\xce\xcf\xd0\xd1\xd2\xd3\xd4\xd5\xd6\xd7\xd8\xd9\xda\xdb\xdc\xdd\xde\xdf\xe0\xe1\xe2\xe3\xe4\xe5\xe6\xe7\xe8\xe9\xea\xeb\xec\xed\xee\xef\xf0\xf1\xf2\xf3\xf4\xf5\xf6\xf7\xf8\xf9\xfa\xfb\xfc\xfd\xfe\xff') == r"""b'\x00\x01\x02\x03\x04\x05\x06\x07\x08\t\n\x0b\x0c\r\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f !"#$%&\'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~\x7f\x80\x81\x82\x83\x84\x85\x86\x87\x88\x89\x8a\x8b\x8c\x8d\x8e\x8f\x90\x91\x92\x93\x94\x95\x96\x97\x98\x99\x9a\x9b\x9c\x9d\x9e\x9f\xa0\xa1\xa2\xa3\xa4\xa5\xa6\xa7\xa8\xa9\xaa\xab\xac\xad\xae\xaf\xb0\xb1\xb2\xb3\xb4\xb5\xb6\xb7\xb8\xb9\xba\xbb\xbc\xbd\xbe\xbf\xc0\xc1\xc2\xc3\xc4\xc5\xc6\xc7\xc8\xc9\xca\xcb\xcc\xcd\xce\xcf\xd0\xd1\xd2\xd3\xd4\xd5\xd6\xd7\xd8\xd9\xda\xdb\xdc\xdd\xde\xdf\xe0\xe1\xe2\xe3\xe4\xe5\xe6\xe7\xe8\xe9\xea\xeb\xec\xed\xee\xef\xf0\xf1\xf2\xf3\xf4\xf5\xf6\xf7\xf8\xf9\xfa\xfb\xfc\xfd\xfe\xff'"""
 
+doc="repr quotes"
+assert repr(b"'") == r'''b"'"'''
+assert repr(b'"') == r"""b'"'"""
+assert repr(b'\'"') == r"""b'\'"'"""
+assert repr(b'\x00\n\'"') == r"""b'\x00\n\'"'"""
+assert repr(b"\\") == r"b'\\'"
+assert repr(b"\x7f\x80\xff") == r"b'\x7f\x80\xff'"
+
+doc="repr round trip"
+b = bytes(range(256))
+assert eval(repr(b)) == b
+for i in range(256):
+    c = bytes([i])
+    assert eval(repr(c)) == c, i
+for c in [b"'", b'"', b"'\"", b"\\'", b"\\\"", b"a'b\"c\\d"]:
+    assert eval(repr(c)) == c, c
+
+doc="len and bool"
+assert len(b"") == 0
+assert len(b"abc") == 3
+assert not b""
+assert b"x"
+
+doc="indexing and slicing"
+b = b"hello world"
+assert b[0] == 104
+assert b[-1] == ord("d")
+assert b[1:5] == b"ello"
+assert b[::2] == b"hlowrd"
+assert b[::-1] == b"dlrow olleh"
+assert b[5:1] == b""
+assertRaises(IndexError, lambda: b[11])
+assert list(b"abc") == [97, 98, 99]
+assert [x for x in b"\x00\xff"] == [0, 255]
+
+doc="contains"
+assert b"ell" in b"hello"
+assert b"xyz" not in b"hello"
+assert 104 in b"hello"
+assert 105 not in b"hello"
+assertRaises(TypeError, lambda: "h" in b"hello")
+assertRaises(ValueError, lambda: 256 in b"hello")
+
+doc="concatenation and repetition"
+assert b"ab" + b"cd" == b"abcd"
+assert b"ab" * 3 == b"ababab"
+assert 2 * b"ab" == b"abab"
+assert b"ab" * -1 == b""
+a = b"x"
+a += b"y"
+assert a == b"xy"
+assertRaises(TypeError, lambda: b"ab" + "cd")
+
+doc="constructor"
+assert bytes() == b""
+assert bytes(3) == b"\x00\x00\x00"
+assert bytes([1, 2, 255]) == b"\x01\x02\xff"
+assert bytes("héllo", "utf-8") == b"h\xc3\xa9llo"
+assert bytes("héllo", "latin-1") == b"h\xe9llo"
+assert bytes("héllo", "ascii", "replace") == b"h?llo"
+assert bytes("héllo", encoding="ascii", errors="ignore") == b"hllo"
+assertRaises(UnicodeEncodeError, bytes, "héllo", "ascii")
+assertRaises(LookupError, bytes, "hello", "no-such-encoding")
+assertRaises(TypeError, bytes, "hello")
+assertRaises(ValueError, bytes, [256])
+class B:
+    def __bytes__(self):
+        return b"from __bytes__"
+assert bytes(B()) == b"from __bytes__"
+
+doc="decode"
+assert b"hello".decode() == "hello"
+assert b"h\xc3\xa9llo".decode("utf-8") == "héllo"
+assert b"h\xe9llo".decode("latin-1") == "héllo"
+assert b"h\xe9llo".decode("ascii", "replace") == "h\ufffdllo"
+assert b"h\xe9llo".decode("ascii", errors="ignore") == "hllo"
+assertRaises(UnicodeDecodeError, b"h\xe9llo".decode, "utf-8")
+assert str(b"h\xc3\xa9", "utf-8") == "hé"
+assert str(b"h\xe9", encoding="latin-1") == "hé"
+assert b"h\x00i\x00".decode("utf-16-le") == "hi"
+assert b"\x00h\x00i".decode("UTF_16BE") == "hi"
+assert b"\xff\xfeh\x00i\x00".decode("utf-16") == "hi"
+assert b"\xfe\xff\x00h\x00i".decode("utf-16") == "hi"
+assert b"=\xd8\x00\xde".decode("utf-16-le") == "\U0001f600"
+assert b"h\x00i".decode("utf-16-le", "replace") == "h\ufffd"
+assert b"h\x00i".decode("utf-16-le", "ignore") == "h"
+assertRaises(UnicodeDecodeError, b"h\x00i".decode, "utf-16-le")
+assert b"\xff\xfe\x00\x00h\x00\x00\x00".decode("utf-32") == "h"
+assert b"\x00\x00\x00h".decode("utf-32-be") == "h"
+assertRaises(LookupError, b"hi".decode, "no-such-codec")
+assertRaises(LookupError, b"\xff".decode, "utf-8", "bogus")
+
+doc="find and index"
+b = b"abcabc"
+assert b.find(b"c") == 2
+assert b.find(b"c", 3) == 5
+assert b.find(b"c", 3, 5) == -1
+assert b.find(b"x") == -1
+assert b.find(98) == 1
+assert b.find(b"") == 0
+assert b.find(b"", 6) == 6
+assert b.find(b"", 7) == -1
+assert b.find(b"a", -3) == 3
+assert b.rfind(b"c") == 5
+assert b.rfind(b"c", 0, 5) == 2
+assert b.rfind(b"x") == -1
+assert b.index(b"bc") == 1
+assert b.rindex(b"bc") == 4
+assertRaises(ValueError, b.index, b"x")
+assertRaises(ValueError, b.rindex, b"x")
+
+doc="count"
+assert b"aaaa".count(b"a") == 4
+assert b"aaaa".count(b"aa") == 2
+assert b"aaaa".count(b"a", 1, 3) == 2
+assert b"aaaa".count(97) == 4
+assert b"abc".count(b"") == 4
+
+doc="startswith and endswith"
+assert b"hello".startswith(b"he")
+assert not b"hello".startswith(b"lo")
+assert b"hello".startswith((b"x", b"h"))
+assert b"hello".startswith(b"ll", 2)
+assert not b"hello".startswith(b"ll", 2, 3)
+assert b"hello".endswith(b"lo")
+assert b"hello".endswith((b"x", b"o"))
+assert b"hello".endswith(b"ll", 0, 4)
+assertRaises(TypeError, b"hello".startswith, "h")
+
+doc="split"
+assert b"a b  c".split() == [b"a", b"b", b"c"]
+assert b"  a b  c  ".split(None, 1) == [b"a", b"b  c  "]
+assert b"a,b,,c".split(b",") == [b"a", b"b", b"", b"c"]
+assert b"a,b,,c".split(b",", 1) == [b"a", b"b,,c"]
+assert b"abc".split(b",") == [b"abc"]
+assert b"".split() == []
+assert b"".split(b",") == [b""]
+assert b"a b  c".rsplit() == [b"a", b"b", b"c"]
+assert b"  a b  c  ".rsplit(None, 1) == [b"  a b", b"c"]
+assert b"a,b,,c".rsplit(b",", 1) == [b"a,b,", b"c"]
+assert b"a,b".rsplit(sep=b",", maxsplit=0) == [b"a,b"]
+assertRaises(ValueError, b"abc".split, b"")
+
+doc="partition"
+assert b"a=b=c".partition(b"=") == (b"a", b"=", b"b=c")
+assert b"abc".partition(b"=") == (b"abc", b"", b"")
+assert b"a=b=c".rpartition(b"=") == (b"a=b", b"=", b"c")
+assert b"abc".rpartition(b"=") == (b"", b"", b"abc")
+
+doc="join"
+assert b",".join([b"a", b"b", b"c"]) == b"a,b,c"
+assert b"".join([]) == b""
+assert b"-".join([b"a", bytearray(b"b")]) == b"a-b"
+assertRaisesText(TypeError, "sequence item 1", b",".join, [b"a", "b"])
+
+doc="replace"
+assert b"aaa".replace(b"a", b"b") == b"bbb"
+assert b"aaa".replace(b"a", b"b", 2) == b"bba"
+assert b"abc".replace(b"", b"-") == b"-a-b-c-"
+assert b"\xff\xfe".replace(b"", b"-") == b"-\xff-\xfe-"
+
+doc="strip"
+assert b"  abc \n".strip() == b"abc"
+assert b"  abc \n".lstrip() == b"abc \n"
+assert b"  abc \n".rstrip() == b"  abc"
+assert b"xxabcxy".strip(b"xy") == b"abc"
+assert b"\xffabc\xff".strip(b"\xff") == b"abc"
+
+doc="case"
+assert b"Hello \xff".upper() == b"HELLO \xff"
+assert b"Hello \xff".lower() == b"hello \xff"
+assert b"abc1".isalnum()
+assert not b"abc!".isalnum()
+assert b"abc".isalpha()
+assert b"123".isdigit()
+assert b" \t".isspace()
+assert not b"".isdigit()
+assert b"hello WORLD".capitalize() == b"Hello world"
+assert b"Hello World".swapcase() == b"hELLO wORLD"
+assert b"they're bill's x1y".title() == b"They'Re Bill'S X1Y"
+assert b"".isascii()
+assert b"abc\x7f".isascii()
+assert not b"abc\x80".isascii()
+assert b"ab1".islower()
+assert not b"aB".islower()
+assert not b"1".islower()
+assert b"AB1".isupper()
+assert not b"Ab".isupper()
+assert b"Hello World".istitle()
+assert not b"Hello world".istitle()
+assert not b"HEllo".istitle()
+assert not b"".istitle()
+
+doc="justify"
+assert b"ab".center(7, b"*") == b"***ab**"
+assert b"abc".center(6) == b" abc  "
+assert b"ab".ljust(5) == b"ab   "
+assert b"ab".rjust(5, b"-") == b"---ab"
+assert b"abc".ljust(2) == b"abc"
+assertRaises(TypeError, b"ab".ljust, 5, b"--")
+assertRaises(TypeError, b"ab".ljust, 5, "-")
+assert b"42".zfill(5) == b"00042"
+assert b"-42".zfill(5) == b"-0042"
+assert b"42".zfill(1) == b"42"
+assert b"a\tbc\td\n\tx".expandtabs() == b"a       bc      d\n        x"
+assert b"a\tb".expandtabs(tabsize=3) == b"a  b"
+assert b"a\tb".expandtabs(0) == b"ab"
+
+doc="splitlines"
+assert b"a\nb\r\nc\rd".splitlines() == [b"a", b"b", b"c", b"d"]
+assert b"a\nb\r\n".splitlines(True) == [b"a\n", b"b\r\n"]
+assert b"a\x0bb".splitlines() == [b"a\x0bb"]
+assert b"".splitlines() == []
+
+doc="removeprefix and removesuffix"
+assert b"abc".removeprefix(b"ab") == b"c"
+assert b"abc".removeprefix(b"x") == b"abc"
+assert b"abc".removesuffix(b"bc") == b"a"
+assert b"abc".removesuffix(b"") == b"abc"
+
+doc="maketrans and translate"
+table = bytes.maketrans(b"abc", b"xyz")
+assert type(table) is bytes
+assert len(table) == 256
+assert b"aabbcd".translate(table) == b"xxyyzd"
+assert b"aabbcd".translate(None, b"b") == b"aacd"
+assert b"abcd".translate(table, delete=b"d") == b"xyz"
+assertRaises(ValueError, bytes.maketrans, b"ab", b"x")
+assertRaises(ValueError, b"ab".translate, b"x")
+
+doc="hex and fromhex"
+assert b"\xb9\x01\xef".hex() == "b901ef"
+assert b"".hex() == ""
+assert b"\x01\x02\x03\x04\x05".hex(":") == "01:02:03:04:05"
+assert b"\x01\x02\x03\x04\x05".hex(b"-", 2) == "01-0203-0405"
+assert b"\x01\x02\x03\x04\x05".hex(" ", -2) == "0102 0304 05"
+assert b"".hex(":") == ""
+assertRaises(ValueError, b"ab".hex, "::")
+assert bytes.fromhex("B9 01EF") == b"\xb9\x01\xef"
+assert bytes.fromhex("") == b""
+assertRaisesText(ValueError, "position 1", bytes.fromhex, "0g")
+assertRaisesText(ValueError, "position 0", bytes.fromhex, "g0")
+assertRaises(ValueError, bytes.fromhex, "abc")
+
+doc="comparison"
+assert b"abc" == b"abc"
+assert b"abc" != b"abd"
+assert b"abc" < b"abd"
+assert b"abc" == bytearray(b"abc")
+assert b"abc" != "abc"
+assert hash(b"abc") == hash(b"abc")
+
+doc="int and float"
+assert int(b"42") == 42
+assert float(b"1.5") == 1.5
+
 doc="finished"
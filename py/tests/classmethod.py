@@ -12,6 +12,7 @@ class A:
 
 a = A()
 assert a.fn(1) == 2
+assert A.fn(1) == 2
 
 a.x = 3
 assert a.x == 3
@@ -31,4 +31,117 @@ doc="mul"
 assert (3+4j) * 2 == 6+8j
 assert (3+4j) * 2j == -8+6j
 
+doc="repr more"
+assert repr(4j) == "4j"
+assert repr(-4j) == "(-0-4j)"
+assert repr(1+0j) == "(1+0j)"
+assert repr(1.5-2.25j) == "(1.5-2.25j)"
+assert repr(complex(0.1, 1e20)) == "(0.1+1e+20j)"
+assert repr(complex(1e-5, 123456789.125)) == "(1e-05+123456789.125j)"
+assert repr(complex(float("inf"), float("nan"))) == "(inf+nanj)"
+assert repr(complex(-0.0, -float("inf"))) == "(-0-infj)"
+assert str(1+2j) == "(1+2j)"
+
+doc="constructor"
+assert complex() == 0j
+assert complex(1) == 1+0j
+assert complex(1, 2) == 1+2j
+assert complex(1.5, -2) == 1.5-2j
+assert complex(1+2j, 3j) == -2+2j
+assert complex(real=1, imag=2) == 1+2j
+assert complex("1+2j") == 1+2j
+assert complex(" ( -1.5e1-2.5J ) ") == -15-2.5j
+assert complex("j") == 1j
+assert complex("-j") == -1j
+assert complex("3") == 3+0j
+assert complex("1e+3j") == 1000j
+assert complex("inf-infj") == complex(float("inf"), -float("inf"))
+assertRaises(ValueError, complex, "1+2")
+assertRaises(ValueError, complex, "1+2jj")
+assertRaises(ValueError, complex, "")
+assertRaises(ValueError, complex, "1 + 2j")
+assertRaises(TypeError, complex, "1", 2)
+assertRaises(TypeError, complex, 1, "2")
+assertRaises(TypeError, complex, [])
+class C:
+    def __complex__(self):
+        return 3+4j
+assert complex(C()) == 3+4j
+
+doc="bool"
+assert 1j
+assert 1+0j
+assert not 0j
+
+doc="abs"
+assert abs(3+4j) == 5.0
+assert abs(-3-4j) == 5.0
+
+doc="neg pos"
+assert -(1+2j) == -1-2j
+assert +(1+2j) == 1+2j
+
+doc="div"
+assert (2+4j) / 2 == 1+2j
+assert (-8+6j) / 2j == 3+4j
+assert 1 / 1j == -1j
+assert 2 / (1+1j) == 1-1j
+assertRaises(ZeroDivisionError, lambda: (1+2j) / 0)
+assertRaises(ZeroDivisionError, lambda: 1 / 0j)
+x = 2+4j
+x /= 2
+assert x == 1+2j
+
+doc="floordiv mod divmod"
+assertRaises(TypeError, lambda: (1+2j) // 2)
+assertRaises(TypeError, lambda: 2 // (1+2j))
+assertRaises(TypeError, lambda: (1+2j) % 2)
+assertRaises(TypeError, lambda: 2 % (1+2j))
+assertRaises(TypeError, lambda: divmod(1+2j, 2))
+
+doc="pow"
+assert (1+1j) ** 2 == 2j
+assert (1+1j) ** -2 == -0.5j
+assert 1j ** 4 == 1+0j
+assert (2+0j) ** 10 == 1024
+assert 2 ** (1+0j) == 2+0j
+assert (1+2j) ** 0 == 1
+assert 0j ** 2 == 0
+assert 1j ** 0.5 == 0.7071067811865476+0.7071067811865475j
+assert (3+4j) ** 0.5 == 2+1j
+assert (3+4j) ** -0.5 == 0.39999999999999997-0.19999999999999998j
+assert (3+4j) ** 2.5 == -37.999999999999986+41.00000000000001j
+assert (-1+0j) ** 0.5 == 6.123233995736766e-17+1j
+assert (1+1j) ** 0.25 == 1.0695539323639858+0.21274750472674303j
+assert repr((2+0j) ** -1.5) == "(0.3535533905932738-0j)"
+assert abs(1j ** 1j - 0.20787957635076193) < 1e-15
+assertRaises(ZeroDivisionError, lambda: 0j ** -1)
+assertRaises(ZeroDivisionError, lambda: 0j ** 1j)
+assertRaises(ValueError, pow, 1j, 2, 3)
+
+doc="comparison"
+assert 1+0j == 1
+assert 1 == 1+0j
+assert 1.5+0j == 1.5
+assert 1j != 1
+assert (1j == "1j") == False
+assert (1j != None) == True
+assertRaises(TypeError, lambda: 1j < 2j)
+assertRaises(TypeError, lambda: 1j <= 2)
+assertRaises(TypeError, lambda: 1j > 2.0)
+assertRaises(TypeError, lambda: 1 >= 1j)
+assertRaises(TypeError, lambda: 1j < "a")
+
+doc="conversion"
+assertRaises(TypeError, int, 1j)
+assertRaises(TypeError, float, 1j)
+
+doc="hash"
+assert hash(1+0j) == hash(1)
+assert hash(1.5+0j) == hash(1.5)
+
+doc="type"
+assert type(1j) == complex
+assert type(1j).__name__ == "complex"
+
 doc="finished"
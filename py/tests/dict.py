@@ -1,12 +1,15 @@
 # Copyright 2018 The go-python Authors.  All rights reserved.
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
-from libtest import assertRaises
+from libtest import assertRaises, assertRaisesText
 
 doc="str"
 assert str({}) == "{}"
 a = str({"a":"b","c":5.5})
 assert a == "{'a': 'b', 'c': 5.5}" or a == "{'c': 5.5, 'a': 'b'}"
+a = {}
+a[1] = a
+assert repr(a) == "{1: {...}}"
 
 doc="repr"
 assert repr({}) == "{}"
@@ -38,6 +41,20 @@ for k, v in a.items():
         assert v == 5.5
 assertRaises(TypeError, a.items, 'a')
 
+doc="check keys"
+a = {"a":"b","c":5.5}
+assert sorted(a.keys()) == ["a", "c"]
+assertRaises(TypeError, a.keys, 'a')
+
+doc="check values"
+a = {"a":"b","c":"d"}
+assert sorted(a.values()) == ["b", "d"]
+assertRaises(TypeError, a.values, 'a')
+
+doc="check len"
+assert len({}) == 0
+assert len({"a":1, "b":2}) == 2
+
 doc="__contain__"
 a = {'hello': 'world'}
 assert a.__contains__('hello')
@@ -54,4 +71,198 @@ assert a.__ne__({}) == True
 assert a.__eq__({'a': 'b'}) == True
 assert a.__ne__({'a': 'b'}) == False
 
+doc="__delitem__"
+a = {'a': 1, 'b': 2}
+del a['a']
+assert a == {'b': 2}
+assertRaises(KeyError, a.__delitem__, 'a')
+
+doc="insertion order"
+a = {"c": 1, "a": 2, "b": 3}
+assert list(a) == ["c", "a", "b"]
+assert repr(a) == "{'c': 1, 'a': 2, 'b': 3}"
+a["a"] = 4
+assert list(a) == ["c", "a", "b"]
+del a["c"]
+a["c"] = 5
+assert list(a.items()) == [("a", 4), ("b", 3), ("c", 5)]
+a = {}
+for i in range(100):
+    a[i] = i
+for i in range(90):
+    del a[i]
+assert list(a) == list(range(90, 100))
+
+doc="non string keys"
+a = {1: "a", (1, 2): "b", None: "c", 2.5: "d"}
+assert a[1] == "a"
+assert a[(1, 2)] == "b"
+assert a[None] == "c"
+assert a[2.5] == "d"
+assert a[1.0] == "a"
+a[True] = "e"
+assert a[1] == "e"
+assert len(a) == 4
+assertRaises(TypeError, lambda: {[]: 1})
+assertRaises(TypeError, a.get, [])
+try:
+    a[99]
+except KeyError as e:
+    assert e.args == (99,)
+else:
+    assert False, "KeyError not raised"
+
+doc="dict()"
+assert dict() == {}
+assert dict({"a": 1}) == {"a": 1}
+assert dict([("a", 1), ("b", 2)]) == {"a": 1, "b": 2}
+assert list(dict([("b", 1), ("a", 2)])) == ["b", "a"]
+assert dict(a=1) == {"a": 1}
+assert dict({"a": 1}, b=2) == {"a": 1, "b": 2}
+assert dict(zip(["a", "b"], range(2))) == {"a": 0, "b": 1}
+class Mapping:
+    def keys(self):
+        return ["x", "y"]
+    def __getitem__(self, key):
+        return key * 2
+assert dict(Mapping()) == {"x": "xx", "y": "yy"}
+assertRaises(TypeError, dict, [1])
+assertRaises(ValueError, dict, [(1, 2, 3)])
+assertRaises(TypeError, dict, {}, {})
+b = {"a": [1]}
+c = dict(b)
+c["a"] = 2
+assert b == {"a": [1]}
+
+doc="views"
+a = {"a": 1, "b": 2}
+k, v, i = a.keys(), a.values(), a.items()
+assert repr(type(k)) == "<class 'dict_keys'>"
+assert repr(type(v)) == "<class 'dict_values'>"
+assert repr(type(i)) == "<class 'dict_items'>"
+assert repr(k) == "dict_keys(['a', 'b'])"
+assert repr(v) == "dict_values([1, 2])"
+assert repr(i) == "dict_items([('a', 1), ('b', 2)])"
+assert len(k) == 2 and len(v) == 2 and len(i) == 2
+a["c"] = 3
+assert list(k) == ["a", "b", "c"]
+assert list(v) == [1, 2, 3]
+assert list(i) == [("a", 1), ("b", 2), ("c", 3)]
+assert "a" in k
+assert "z" not in k
+assert 3 in v
+assert ("a", 1) in i
+assert ("a", 2) not in i
+assert "a" not in i
+
+doc="set operations on views"
+a = {"a": 1, "b": 2, "c": 3}
+b = {"b": 4, "c": 3, "d": 5}
+assert a.keys() & b.keys() == {"b", "c"}
+assert a.keys() | b.keys() == {"a", "b", "c", "d"}
+assert a.keys() - b.keys() == {"a"}
+assert a.keys() ^ b.keys() == {"a", "d"}
+assert a.keys() & ["a", "z"] == {"a"}
+assert {"a", "z"} & a.keys() == {"a"}
+assert ["a", "z"] - a.keys() == {"z"}
+assert a.items() & b.items() == {("c", 3)}
+assert a.keys() == {"a", "b", "c"}
+assert a.keys() != {"a"}
+assert {"a"} < a.keys()
+assert a.keys() <= a.keys()
+assert a.keys() > {"a"}
+assert a.keys().isdisjoint(["x", "y"])
+assert not a.keys().isdisjoint(["a"])
+assert a.items() == {("a", 1), ("b", 2), ("c", 3)}
+assertRaises(TypeError, lambda: a.keys() & 1)
+assertRaises(TypeError, hash, a.keys())
+
+doc="setdefault"
+a = {"a": 1}
+assert a.setdefault("a", 2) == 1
+assert a.setdefault("b", 3) == 3
+assert a.setdefault("c") is None
+assert a == {"a": 1, "b": 3, "c": None}
+assertRaises(TypeError, a.setdefault)
+
+doc="pop"
+a = {"a": 1, "b": 2}
+assert a.pop("a") == 1
+assert a == {"b": 2}
+assert a.pop("a", 5) == 5
+assert a.pop("a", None) is None
+assertRaises(KeyError, a.pop, "a")
+assertRaises(TypeError, a.pop)
+
+doc="popitem"
+a = {"a": 1, "b": 2}
+assert a.popitem() == ("b", 2)
+assert a.popitem() == ("a", 1)
+assertRaisesText(KeyError, "dictionary is empty", a.popitem)
+
+doc="update"
+a = {"a": 1}
+a.update({"b": 2})
+a.update([("c", 3)])
+a.update(d=4)
+a.update({"a": 5}, e=6)
+a.update()
+assert a == {"a": 5, "b": 2, "c": 3, "d": 4, "e": 6}
+assert list(a) == ["a", "b", "c", "d", "e"]
+assert a.update({}) is None
+assertRaises(TypeError, a.update, 1)
+assertRaises(TypeError, a.update, {}, {})
+
+doc="clear and copy"
+a = {"a": 1, 2: "b"}
+b = a.copy()
+a.clear()
+assert a == {}
+assert b == {"a": 1, 2: "b"}
+b.clear()
+assert len(b) == 0
+
+doc="fromkeys"
+assert dict.fromkeys("ab") == {"a": None, "b": None}
+assert dict.fromkeys([1, 2], 0) == {1: 0, 2: 0}
+assert {}.fromkeys(["a"], 1) == {"a": 1}
+assert list(dict.fromkeys("cab")) == ["c", "a", "b"]
+
+doc="mapping protocol"
+a = {1: 2}
+assert a.__getitem__(1) == 2
+a.__setitem__(3, 4)
+assert a.__contains__(3)
+a.__delitem__(3)
+assert not a.__contains__(3)
+assert a.__len__() == 1
+assert not {}
+assert {1: 2}
+assertRaises(TypeError, hash, {})
+assert {1: 2, 3: 4} == {3: 4, 1: 2}
+assert {1: 2} != {1: 3}
+assert {1: 2} != {2: 2}
+
+doc="namespaces are dicts"
+def f(**kwargs):
+    return kwargs
+kw = f(b=1, a=2)
+assert type(kw) == dict
+assert kw == {"a": 2, "b": 1}
+assert {"a": 2, "b": 1} == kw
+assert f(**{"x": 1}) == {"x": 1}
+assertRaises(TypeError, lambda: f(**{1: 2}))
+assert isinstance(globals(), dict)
+kw.setdefault("c", 3)
+assert kw.pop("a") == 2
+assert sorted(kw.keys()) == ["b", "c"]
+
+doc="exec with a dict"
+ns = {"x": 1}
+exec("y = x + 1", ns)
+assert ns["y"] == 2
+exec("def g(): return z", ns)
+ns["z"] = 5
+assert ns["g"]() == 5
+
 doc="finished"
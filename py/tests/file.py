@@ -44,4 +44,24 @@ assertRaises(ValueError, f.flush)
 # closing a closed file should not throw an error
 assert f.close() == None
 
+doc = "with"
+with open(__file__) as f:
+    assert not f.closed
+    b = f.read(12)
+    assert b == '# Copyright '
+assert f.closed
+assertRaises(ValueError, f.read, 1)
+
+try:
+    with open(__file__) as f:
+        raise KeyError("in with")
+except KeyError:
+    pass
+assert f.closed
+
+def enter_closed():
+    with f:
+        pass
+assertRaises(ValueError, enter_closed)
+
 doc = "finished"
@@ -127,4 +127,14 @@ except TypeError:
 else:
     assert False, "TypeError not raised"
 
+doc="check __doc__ and __module__"
+def f9():
+    "docstring"
+assert f9.__doc__ == "docstring"
+assert f9.__module__ == __name__
+f9.__doc__ = "new doc"
+f9.__module__ = "new_module"
+assert f9.__doc__ == "new doc"
+assert f9.__module__ == "new_module"
+
 doc="finished"
@@ -0,0 +1,68 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from libtest import assertRaises
+
+doc="subscript builtins"
+assert repr(list[int]) == "list[int]"
+assert repr(dict[str, list[int]]) == "dict[str, list[int]]"
+assert repr(tuple[int, ...]) == "tuple[int, ...]"
+assert repr(tuple[()]) == "tuple[()]"
+assert repr(type[int]) == "type[int]"
+assert repr(set[frozenset[int]]) == "set[frozenset[int]]"
+assert list[int].__origin__ is list
+assert dict[str, int].__args__ == (str, int)
+assert list[int].__parameters__ == ()
+
+doc="equality"
+assert list[int] == list[int]
+assert list[int] != list[str]
+assert list[int] != list
+assert hash(dict[str, int]) == hash(dict[str, int])
+
+doc="call"
+l = list[int]([1, 2])
+assert l == [1, 2]
+assert type(l) is list
+assert dict[str, int](a=1) == {"a": 1}
+
+doc="attributes of the origin"
+assert list[int].append is list.append
+
+doc="not subscriptable"
+assertRaises(TypeError, lambda: int[str])
+assertRaises(TypeError, lambda: list[int][str])
+
+doc="__class_getitem__"
+class Box:
+    def __class_getitem__(cls, item):
+        return (cls, item)
+assert Box[int] == (Box, int)
+class SubBox(Box):
+    pass
+assert SubBox[str] == (SubBox, str)
+
+doc="__mro_entries__"
+class L(list[int]):
+    pass
+assert L.__bases__ == (list,)
+assert L.__orig_bases__ == (list[int],)
+class Plain(list):
+    pass
+try:
+    Plain.__orig_bases__
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+
+class Entry:
+    def __mro_entries__(self, bases):
+        return (Box,)
+class FromEntry(Entry()):
+    pass
+assert FromEntry.__bases__ == (Box,)
+assert FromEntry[int] == (FromEntry, int)
+
+doc="finished"
@@ -0,0 +1,160 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from libtest import assertRaises
+
+doc="int"
+assert hash(0) == 0
+assert hash(1) == 1
+assert hash(-1) == -2
+assert hash(-2) == -2
+assert hash(2**61-1) == 0
+assert hash(2**61) == 1
+assert hash(-2**61) == -2
+assert hash(2**100) == 549755813888
+assert hash(-2**100) == -549755813888
+
+doc="bool"
+assert hash(True) == hash(1) == 1
+assert hash(False) == hash(0) == 0
+
+doc="float"
+assert hash(2.0) == hash(2)
+assert hash(-1.0) == hash(-1) == -2
+assert hash(0.0) == hash(-0.0) == 0
+assert hash(0.5) == 1152921504606846976
+assert hash(1.5) == 1152921504606846977
+assert hash(-2.5) == -1152921504606846978
+assert hash(2.0**100) == hash(2**100)
+assert hash(1e300) == hash(int(1e300))
+assert hash(float("inf")) == 314159
+assert hash(float("-inf")) == -314159
+assert hash(float("nan")) == 0
+
+doc="complex"
+assert hash(2+0j) == hash(2.0) == hash(2)
+assert hash(complex(-1, 0)) == -2
+assert hash(1+2j) == 2000007
+assert hash(1.5j) == 3458764513821540931
+
+doc="mixed equality"
+assert 2 == 2.0 == (2+0j)
+assert True == 1 == 1.0 == (1+0j)
+assert 2**100 == 2.0**100
+assert 2**70 == complex(2**70)
+assert 2.5 != 2
+assert (2+1j) != 2
+
+doc="str and bytes"
+assert hash("hello") == hash("hel" + "lo")
+assert hash(b"hello") == hash(bytes([104, 101, 108, 108, 111]))
+assert hash("") == hash(b"") == 0
+# str is hashed as CPython stores it, in 1, 2 or 4 bytes per character
+assert hash("hello") == hash(b"hello")
+assert hash("naïve") == hash("naïve".encode("latin-1"))
+assert hash("€uro") == hash("€uro".encode("utf-16-le"))
+assert hash("😀x") == hash("😀x".encode("utf-32-le"))
+
+doc="tuple"
+assert hash(()) == 5740354900026072187
+assert hash((1, 2)) == -3550055125485641917
+assert hash((1, 2.0)) == hash((1.0, 2+0j))
+assert hash((1, (2, 3))) == 7267574591690527098
+
+doc="frozenset"
+assert hash(frozenset()) == 133146708735736
+assert hash(frozenset([1, 2])) == hash(frozenset([2, 1])) == -1826646154956904602
+assert hash(frozenset([frozenset([1]), 2])) == -1248503628839622056
+assert hash(frozenset([1, 2])) == hash(frozenset([1.0, 2+0j]))
+
+doc="range"
+assert hash(range(3)) == hash(range(0, 3, 1)) == -8338477496398685190
+assert hash(range(0)) == hash(range(5, 5))
+assert hash(range(3, 4)) == hash(range(3, 5, 7))
+assert range(0, 2) != range(0, 4, 2)
+
+doc="unhashable"
+assertRaises(TypeError, hash, [])
+assertRaises(TypeError, hash, {})
+assertRaises(TypeError, hash, set())
+assertRaises(TypeError, hash, bytearray())
+assertRaises(TypeError, hash, {}.keys())
+assertRaises(TypeError, hash, (1, []))
+assert list.__hash__ is None
+assert dict.__hash__ is None
+class L(list):
+    pass
+assertRaises(TypeError, hash, L())
+
+doc="__hash__ and __eq__"
+class K:
+    def __init__(self, v):
+        self.v = v
+    def __hash__(self):
+        return hash(self.v)
+    def __eq__(self, other):
+        return isinstance(other, K) and other.v == self.v
+d = {K(1): "a"}
+assert d[K(1)] == "a"
+assert K(2) not in d
+assert len({K(1), K(1), K(2)}) == 2
+assert hash(K("x")) == hash("x")
+
+class E:
+    def __eq__(self, other):
+        return True
+assert E.__hash__ is None
+assertRaises(TypeError, hash, E())
+assertRaises(TypeError, lambda: {E(): 1})
+
+class E2(E):
+    __hash__ = object.__hash__
+e2 = E2()
+assert hash(e2) == object.__hash__(e2)
+assert {e2: 1}[e2] == 1
+
+class H:
+    __hash__ = None
+assertRaises(TypeError, hash, H())
+
+class Collide:
+    def __hash__(self):
+        return 1
+    def __eq__(self, other):
+        return self is other
+a, b = Collide(), Collide()
+d = {a: 1, b: 2}
+assert d[a] == 1 and d[b] == 2
+
+doc="__hash__ results"
+class R:
+    def __init__(self, h):
+        self.h = h
+    def __hash__(self):
+        return self.h
+assert hash(R(-1)) == -2
+assert hash(R(2**100)) == hash(2**100)
+assert hash(R(True)) == 1
+assertRaises(TypeError, hash, R("x"))
+assertRaises(TypeError, hash, R(1.5))
+
+doc="methods"
+class M:
+    def f(self):
+        pass
+m = M()
+assert m.f == m.f
+assert hash(m.f) == hash(m.f)
+assert {m.f: 1}[m.f] == 1
+assert m.f != M().f
+
+doc="identity"
+assert hash(None) == hash(None)
+assert hash(int) == hash(int)
+assert hash(len) == hash(len)
+assert hash(...) == hash(Ellipsis)
+o = object()
+assert hash(o) == o.__hash__() == object.__hash__(o)
+
+doc="finished"
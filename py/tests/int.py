@@ -581,12 +581,7 @@ doc="pow(x,y,z)"
 assert pow(1938019302983,283019283019238,91283091283012938) == 90917306668848727
 pow(193801930298311111111111111111111,28301928301923822222222222222222222,9128309128301293822222222222222222222) == 2810220059867374937460899006752893533
 assert pow(True, 10) == 1
-try:
-    pow(1,-1,1)
-except TypeError:
-    pass
-else:
-    assert False, "TypeError not raised"
+assert pow(1,-1,1) == 0
 
 doc="round"
 assert round(12345678, 10) == 12345678
@@ -625,5 +620,166 @@ assert round(-123456789012345678901, -6) == -123456789012346000000
 assert round(-123456789012345678901,-19) == -120000000000000000000
 assert round(-123456789012345678901,-21) == 0
 
+doc="str of very large ints"
+x = 2**100000
+s = str(x)
+assert len(s) == 30103
+assert s[:20] == "99900209301438450794"
+assert s[-20:] == "55304734389883109376"
+assert repr(x) == s
+assert str(-x) == "-" + s
+assert int(s) == x
+assert int("-" + s) == -x
+x = 10**5000
+assert str(x) == "1" + "0"*5000
+assert str(x-1) == "9"*5000
+
+doc="bigint floor division and modulo"
+assert (-7**50) // (3**20) == -515794736873211148285242182534833
+assert (-7**50) % (3**20) == 994288784
+assert divmod(-7**50, 3**20) == (-515794736873211148285242182534833, 994288784)
+assert (7**50) // (-3**20) == -515794736873211148285242182534833
+assert (7**50) % (-3**20) == -994288784
+assert divmod(7**50, -3**20) == (-515794736873211148285242182534833, -994288784)
+assert (-7**50) // (-3**20) == 515794736873211148285242182534832
+assert (-7**50) % (-3**20) == -2492495617
+assert divmod(-7**50, -3**20) == (515794736873211148285242182534832, -2492495617)
+assert divmod(-2**64, 7) == (-2635249153387078803, 5)
+assert divmod(-2**64, -7) == (2635249153387078802, -2)
+assert divmod(-7, 2**64) == (-1, 18446744073709551609)
+assert divmod(7, -2**64) == (-1, -18446744073709551609)
+for a in [7**50, -7**50, 2**64+1, -2**64-1, 12345, -12345]:
+    for b in [3**20, -3**20, 2**64, -2**64, 7, -7]:
+        q, r = divmod(a, b)
+        assert q == a // b
+        assert r == a % b
+        assert q*b + r == a
+        assert r == 0 or (r < 0) == (b < 0)
+        assert abs(r) < abs(b)
+x = -7**50
+x //= 3**20
+assert x == -515794736873211148285242182534833
+x = -7**50
+x %= 3**20
+assert x == 994288784
+assertRaises(ZeroDivisionError, lambda: (7**50) // 0)
+assertRaises(ZeroDivisionError, lambda: (7**50) % 0)
+assertRaises(ZeroDivisionError, lambda: divmod(-7**50, 0))
+
+doc="int overflow boundaries"
+assert abs(minInt) == negativeMinInt
+assert minInt // -1 == negativeMinInt
+assert minInt % -1 == 0
+assert divmod(minInt, -1) == (negativeMinInt, 0)
+assert 1 << 64 == 18446744073709551616
+assert -1 << 63 == minInt
+assert 1 >> 64 == 0
+assert -1 >> 64 == -1
+assert 1 >> 2**100 == 0
+assert -5 >> 2**100 == -1
+assert 2**100 >> 2**100 == 0
+assert 0 << 2**100 == 0
+assertRaises(OverflowError, lambda: 1 << 2**100)
+assertRaises(ValueError, lambda: 1 << -1)
+assertRaises(ValueError, lambda: 1 >> -2**100)
+assert 3**39 == 4052555153018976267
+assert 3**40 == 12157665459056928801
+assert (-3)**41 == -36472996377170786403
+assert 2**63 == negativeMinInt
+assert (-2)**63 == minInt
+assert 0**0 == 1
+assert 2**-1 == 0.5
+
+doc="pow with modulus"
+assert pow(3, 4, 5) == 1
+assert pow(2, 100, 7**20) == 2**100 % 7**20
+assert pow(-2, 3, 5) == 2
+assert pow(2, 3, -5) == -2
+assert pow(-2, 3, -5) == -3
+assert pow(2, 10, 1) == 0
+assert pow(38, -1, 97) == 23
+assert pow(38, -2, 97) == 23*23 % 97
+assert pow(2**70, 2**70, 10**9+7) == pow(2**70 % (10**9+7), 2**70, 10**9+7)
+assertRaises(ValueError, pow, 2, 3, 0)
+assertRaises(ValueError, pow, 2, -1, 4)
+assertRaises(TypeError, pow, 2, 3, 5.0)
+
+doc="bit_length"
+assert (0).bit_length() == 0
+assert (1).bit_length() == 1
+assert (-1).bit_length() == 1
+assert (37).bit_length() == 6
+assert (-37).bit_length() == 6
+assert minInt.bit_length() == 64
+assert (2**100).bit_length() == 101
+assert (-2**100).bit_length() == 101
+
+doc="bit_count"
+assert (0).bit_count() == 0
+assert (37).bit_count() == 3
+assert (-37).bit_count() == 3
+assert (2**100-1).bit_count() == 100
+
+doc="to_bytes"
+assert (1024).to_bytes(2, 'big') == b'\x04\x00'
+assert (1024).to_bytes(10, 'big') == b'\x00\x00\x00\x00\x00\x00\x00\x00\x04\x00'
+assert (-1024).to_bytes(10, 'big', signed=True) == b'\xff\xff\xff\xff\xff\xff\xff\xff\xfc\x00'
+assert (1024).to_bytes(2, byteorder='little') == b'\x00\x04'
+assert (0).to_bytes(0, 'big') == b''
+assert (0).to_bytes(0, 'big', signed=True) == b''
+assert (127).to_bytes(1, 'big', signed=True) == b'\x7f'
+assert (-128).to_bytes(1, 'big', signed=True) == b'\x80'
+assert (255).to_bytes(1, 'big') == b'\xff'
+assert (2**64).to_bytes(9, 'little') == b'\x00'*8 + b'\x01'
+assert (5).to_bytes() == b'\x05'
+assertRaises(OverflowError, lambda: (256).to_bytes(1, 'big'))
+assertRaises(OverflowError, lambda: (128).to_bytes(1, 'big', signed=True))
+assertRaises(OverflowError, lambda: (-129).to_bytes(1, 'big', signed=True))
+assertRaises(OverflowError, lambda: (-1).to_bytes(2, 'big'))
+assertRaises(ValueError, lambda: (1).to_bytes(-1, 'big'))
+assertRaises(ValueError, lambda: (1).to_bytes(2, 'middle'))
+
+doc="from_bytes"
+assert int.from_bytes(b'\x00\x10', byteorder='big') == 16
+assert int.from_bytes(b'\x00\x10', byteorder='little') == 4096
+assert int.from_bytes(b'\xfc\x00', byteorder='big', signed=True) == -1024
+assert int.from_bytes(b'\xfc\x00', byteorder='big', signed=False) == 64512
+assert int.from_bytes([255, 0, 0], byteorder='big') == 16711680
+assert int.from_bytes(bytearray(b'\x01\x00'), 'big') == 256
+assert int.from_bytes(b'') == 0
+assert int.from_bytes(b'\x01' + b'\x00'*16, 'big') == 2**128
+assert int.from_bytes(b'\x80' + b'\x00'*15, 'big', signed=True) == -2**127
+for x in [0, 1, -1, 127, -128, 2**63, minInt, 7**50, -7**50]:
+    for order in ['big', 'little']:
+        assert int.from_bytes(x.to_bytes(30, order, signed=True), order, signed=True) == x
+assertRaises(ValueError, int.from_bytes, b'\x00', 'middle')
+
+doc="numbers protocol"
+assert (5).conjugate() == 5
+assert (2**100).conjugate() == 2**100
+assert (5).real == 5
+assert (5).imag == 0
+assert (-7).numerator == -7
+assert (-7).denominator == 1
+assert (2**100).numerator == 2**100
+assert (5).as_integer_ratio() == (5, 1)
+
+doc="bool is a subclass of int"
+assert issubclass(bool, int)
+assert isinstance(True, int)
+assert bool.__mro__ == (bool, int, object)
+assert True.bit_length() == 1
+assert bool() is False
+assert bool(3) is True
+assert bool([]) is False
+assertRaises(TypeError, bool, x=1)
+try:
+    class SubBool(bool):
+        pass
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
 doc="finished"
 
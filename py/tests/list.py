@@ -17,6 +17,12 @@ assert repr([1,2,3]) == "[1, 2, 3]"
 assert repr([1,[2,3],4]) == "[1, [2, 3], 4]"
 assert repr(["1",[2.5,17,[]]]) == "['1', [2.5, 17, []]]"
 assert repr([1, 1.0]) == "[1, 1.0]"
+a = []
+a.append(a)
+assert repr(a) == "[[...]]"
+a = [1, {}]
+a[1]["a"] = a
+assert repr(a) == "[1, {'a': [...]}]"
 
 doc="enumerate"
 a = [e for e in enumerate([3,4,5,6,7], 4)]
@@ -35,6 +41,110 @@ a.extend(['d', 'e', 'f'])
 assert repr(a) == "['a', 'b', 'c', 'd', 'e', 'f']"
 assertRaises(TypeError, lambda: [].append())
 
+doc="slice assignment"
+a = list(range(10))
+a[1:3] = ['x', 'y', 'z']
+assert a == [0, 'x', 'y', 'z', 3, 4, 5, 6, 7, 8, 9]
+a = list(range(5))
+a[8:2] = [1]
+assert a == [0, 1, 2, 3, 4, 1]
+a = list(range(5))
+a[3:1] = "ab"
+assert a == [0, 1, 2, 'a', 'b', 3, 4]
+a = list(range(5))
+a[1:3] = a
+assert a == [0, 0, 1, 2, 3, 4, 3, 4]
+a = list(range(10))
+a[::2] = "abcde"
+assert a == ['a', 1, 'b', 3, 'c', 5, 'd', 7, 'e', 9]
+a = list(range(10))
+a[::-2] = "abcde"
+assert a == [0, 'e', 2, 'd', 4, 'c', 6, 'b', 8, 'a']
+a = list(range(5))
+a[:] = []
+assert a == []
+assertRaises(ValueError, lambda: a.__setitem__(slice(None, None, 2), [1]))
+
+doc="slice deletion"
+a = list(range(10))
+del a[2:5]
+assert a == [0, 1, 5, 6, 7, 8, 9]
+a = list(range(10))
+del a[5:2]
+assert a == list(range(10))
+a = list(range(10))
+del a[::2]
+assert a == [1, 3, 5, 7, 9]
+a = list(range(10))
+del a[::-3]
+assert a == [1, 2, 4, 5, 7, 8]
+a = list(range(10))
+del a[-2::-4]
+assert a == [1, 2, 3, 5, 6, 7, 9]
+a = list(range(10))
+del a[:]
+assert a == []
+
+doc="user defined item access"
+class Seq:
+    def __getitem__(self, key):
+        return key
+    def __setitem__(self, key, value):
+        self.set = (key, value)
+    def __delitem__(self, key):
+        self.deleted = key
+s = Seq()
+assert s[1:2] == slice(1, 2)
+assert s[::3] == slice(None, None, 3)
+assert s[1:2, 3] == (slice(1, 2), 3)
+s[1:2] = 5
+assert s.set == (slice(1, 2), 5)
+del s[::2]
+assert s.deleted == slice(None, None, 2)
+
+doc="methods"
+a = [1, 2, 3, 2, 1]
+assert a.index(2) == 1
+assert a.index(2, 2) == 3
+assert a.index(1, -2) == 4
+assert a.index(3, 0, 3) == 2
+assertRaises(ValueError, lambda: a.index(3, 0, 2))
+assertRaises(ValueError, lambda: a.index(4))
+assert a.count(2) == 2
+assert a.count(4) == 0
+b = a.copy()
+assert b == a and b is not a
+assert a.pop() == 1
+assert a.pop(0) == 1
+assert a == [2, 3, 2]
+assertRaises(IndexError, lambda: a.pop(3))
+assertRaises(IndexError, lambda: [].pop())
+a.insert(-10, 's')
+a.insert(100, 'e')
+a.insert(1, 'x')
+assert a == ['s', 'x', 2, 3, 2, 'e']
+a.remove(2)
+assert a == ['s', 'x', 3, 2, 'e']
+assertRaises(ValueError, lambda: a.remove(9))
+a.reverse()
+assert a == ['e', 2, 3, 'x', 's']
+a.clear()
+assert a == []
+a.extend(range(3))
+a.extend("ab")
+assert a == [0, 1, 2, 'a', 'b']
+
+doc="comparison"
+assert [1, 2] < [1, 3]
+assert [1, 2] < [1, 2, 0]
+assert [1, 2] <= [1, 2]
+assert [2] > [1, 5]
+assert [1, 2, 0] >= [1, 2]
+assert [1, "a"] < [2, "b"]
+assert not ([] < [])
+assertRaises(TypeError, lambda: [1] < ["a"])
+assertRaises(TypeError, lambda: [1] < (1,))
+
 doc="mul"
 a = [1, 2, 3]
 assert a * 2  == [1, 2, 3, 1, 2, 3]
@@ -0,0 +1,101 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from libtest import assertRaises
+
+doc="property"
+class A:
+    def __init__(self):
+        self._x = 1
+    def getx(self):
+        "the x property"
+        return self._x
+    def setx(self, value):
+        self._x = value
+    def delx(self):
+        del self._x
+    x = property(getx, setx, delx)
+
+a = A()
+assert a.x == 1
+a.x = 2
+assert a.x == 2
+assert a._x == 2
+del a.x
+assert not hasattr(a, "_x")
+assert A.x.__class__ is property
+assert A.x.fget is A.getx
+assert A.x.fset is A.setx
+assert A.x.fdel is A.delx
+
+doc="property keywords"
+class B:
+    x = property(fget=lambda self: 42, doc="forty two")
+assert B().x == 42
+assert B.x.fset is None
+assert B.x.fdel is None
+assert B.x.__doc__ == "forty two"
+
+doc="read only property"
+b = B()
+assertRaises(AttributeError, setattr, b, "x", 1)
+def delete():
+    del b.x
+assertRaises(AttributeError, delete)
+p = property()
+class C:
+    x = p
+assertRaises(AttributeError, getattr, C(), "x")
+
+doc="property decorators"
+class D:
+    def __init__(self):
+        self._x = 0
+        self.log = []
+    @property
+    def x(self):
+        "I am the 'x' property"
+        self.log.append("get")
+        return self._x
+    @x.setter
+    def x(self, value):
+        self.log.append("set")
+        self._x = value
+    @x.deleter
+    def x(self):
+        self.log.append("del")
+        self._x = None
+d = D()
+assert d.x == 0
+d.x = 3
+assert d.x == 3
+del d.x
+assert d.x is None
+assert d.log == ["get", "set", "get", "del", "get"]
+assert D.x.fget is not None
+assert D.x.fset is not None
+assert D.x.fdel is not None
+
+doc="property getter"
+class E:
+    @property
+    def x(self):
+        return 1
+    y = x.getter(lambda self: 2)
+e = E()
+assert e.x == 1
+assert e.y == 2
+
+doc="property beats instance dict"
+e.__dict__["x"] = 3
+assert e.x == 1
+
+doc="property inherited"
+class F(D):
+    pass
+f = F()
+f.x = 4
+assert f.x == 4
+
+doc="finished"
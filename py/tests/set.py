@@ -1,6 +1,7 @@
 # Copyright 2019 The go-python Authors.  All rights reserved.
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
+from libtest import assertRaises, assertRaisesText
 
 doc="__and__"
 a = {1, 2, 3}
@@ -92,4 +93,164 @@ assert a.__ne__(set()) == True
 assert a.__eq__({1,2,3}) == True
 assert a.__ne__({1,2,3}) == False
 
+doc="hashing"
+a = {1, 1.0, True}
+assert len(a) == 1
+a = {(1, 2), (1, 2), "a", "a", 2**100, 2**100}
+assert len(a) == 3
+assert (1, 2) in a
+assert 2**100 in a
+assert 3 not in a
+assertRaisesText(TypeError, "unhashable type: 'list'", lambda: {[1]})
+assertRaisesText(TypeError, "unhashable type: 'list'", set, [[1]])
+assertRaisesText(TypeError, "unhashable type: 'set'", lambda: {set()})
+assert frozenset({1}) in {frozenset({1})}
+
+doc="repr"
+assert repr(set()) == "set()"
+assert repr({1, 2, 3}) == "{1, 2, 3}"
+assert repr(frozenset()) == "frozenset()"
+assert repr(frozenset([1, 2])) == "frozenset({1, 2})"
+
+doc="operators"
+a = {1, 2, 3}
+b = {2, 3, 4, 5}
+assert a & b == {2, 3}
+assert a | b == {1, 2, 3, 4, 5}
+assert a - b == {1}
+assert b - a == {4, 5}
+assert a ^ b == {1, 4, 5}
+assertRaisesText(TypeError, "unsupported operand type(s) for &: 'set' and 'list'", lambda: a & [1])
+assertRaisesText(TypeError, "unsupported operand type(s) for |: 'list' and 'set'", lambda: [1] | a)
+
+doc="in place operators"
+a = {1, 2, 3}
+c = a
+a &= {2, 3, 4}
+assert a == {2, 3}
+a |= {5}
+assert a == {2, 3, 5}
+a -= {3}
+assert a == {2, 5}
+a ^= {2, 6}
+assert a == {5, 6}
+assert c is a
+
+doc="comparisons"
+assert {1, 2} == {2, 1}
+assert {1, 2} != {1, 3}
+assert {1} < {1, 2}
+assert not {1, 2} < {1, 2}
+assert {1, 2} <= {1, 2}
+assert {1, 2, 3} > {1, 2}
+assert {1, 2} >= {1, 2}
+assert not {1, 3} <= {1, 2}
+assert {1, 2} == frozenset([1, 2])
+assert frozenset([1, 2]) == {1, 2}
+assert not ({1} == [1])
+assertRaises(TypeError, lambda: {1} < [1])
+
+doc="add, discard, remove, pop, clear"
+a = set()
+a.add(1)
+a.add(1)
+a.add(2)
+assert a == {1, 2}
+a.discard(1)
+a.discard(3)
+assert a == {2}
+a.remove(2)
+assert a == set()
+assertRaises(KeyError, a.remove, 2)
+try:
+    a.remove("x")
+except KeyError as e:
+    assert e.args[0] == "x"
+else:
+    assert False, "KeyError not raised"
+a = {1, 2}
+x = a.pop()
+y = a.pop()
+assert {x, y} == {1, 2}
+assertRaisesText(KeyError, "pop from an empty set", a.pop)
+a = {1, 2, 3}
+a.clear()
+assert len(a) == 0
+assertRaises(TypeError, a.add, [])
+
+doc="many items"
+a = set(range(1000))
+for i in range(0, 1000, 2):
+    a.discard(i)
+assert len(a) == 500
+assert 1 in a
+assert 2 not in a
+assert sum(a) == 250000
+
+doc="copy"
+a = {1, 2}
+b = a.copy()
+b.add(3)
+assert a == {1, 2}
+assert b == {1, 2, 3}
+f = frozenset([1])
+assert f.copy() is f
+
+doc="methods"
+a = {1, 2, 3}
+assert a.union([3, 4], (5,)) == {1, 2, 3, 4, 5}
+assert a.union() == a
+assert a.intersection([2, 3, 4], {3}) == {3}
+assert a.difference([1], {2}) == {3}
+assert a.symmetric_difference([3, 4]) == {1, 2, 4}
+assert a.isdisjoint([4, 5])
+assert not a.isdisjoint([3])
+assert a.issubset(range(5))
+assert not a.issubset([1])
+assert a.issuperset([1, 2])
+assert not a.issuperset([4])
+assert a == {1, 2, 3}
+assertRaises(TypeError, a.symmetric_difference)
+assertRaises(TypeError, a.union, 1)
+
+doc="update methods"
+a = {1, 2, 3}
+assert a.update([4], "a") is None
+assert a == {1, 2, 3, 4, "a"}
+a.difference_update(["a"], [4])
+assert a == {1, 2, 3}
+a.intersection_update([1, 2, 5], (2, 1))
+assert a == {1, 2}
+a.symmetric_difference_update([2, 3])
+assert a == {1, 3}
+
+doc="frozenset"
+f = frozenset([1, 2, 3])
+assert len(f) == 3
+assert 2 in f
+assert frozenset() == frozenset([])
+assert frozenset(f) is f
+assert hash(frozenset([1, 2])) == hash(frozenset([2, 1]))
+assert f | {4} == frozenset([1, 2, 3, 4])
+assert repr(type(f | {4})) == "<class 'frozenset'>"
+assert repr(type({4} | f)) == "<class 'set'>"
+assert repr(type(f.union([4]))) == "<class 'frozenset'>"
+assert repr(type(f.intersection([1]))) == "<class 'frozenset'>"
+g = f
+g |= {4}
+assert g == {1, 2, 3, 4}
+assert f == {1, 2, 3}
+assert not hasattr(f, "add")
+assert not hasattr(f, "update")
+assertRaisesText(TypeError, "unhashable type: 'set'", hash, {1})
+
+doc="set comprehension"
+a = {x % 3 for x in range(10)}
+assert a == {0, 1, 2}
+
+doc="iteration"
+a = {3, 1, 2}
+assert sorted(a) == [1, 2, 3]
+assert sorted(list(frozenset(a))) == [1, 2, 3]
+
 doc="finished"
@@ -24,4 +24,21 @@ assert slice(1, 0, 0) != slice(0, 0, 0)
 assert slice(0).__ne__(slice(1))
 assert slice(0, None, 3).__ne__(slice(0, 0, 3))
 
+doc="repr"
+assert repr(slice(1, 2)) == "slice(1, 2, None)"
+assert repr(slice(None, None, -1)) == "slice(None, None, -1)"
+assert str(slice('a', 2.5)) == "slice('a', 2.5, None)"
+
+doc="indices"
+assert slice(1, None, -1).indices(10) == (1, -1, -1)
+assert slice(None, None, -1).indices(5) == (4, -1, -1)
+assert slice(-20, 20).indices(5) == (0, 5, 1)
+assert slice(2, 8, 3).indices(6) == (2, 6, 3)
+try:
+    slice(1).indices(-1)
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+
 doc="finished"
\ No newline at end of file
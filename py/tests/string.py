@@ -117,6 +117,41 @@ assert ['a', 'd,c'] == list("a,d,c".split(",",1))
 assert ['a', 'd', 'b'] == list(" a   d   b   ".split())
 assert ['a', 'd   b   '] == list(" a   d   b   ".split(None, 1))
 assertRaisesText(TypeError, "Can't convert 'int' object to str implicitly", lambda: "0,1,2,4".split(1))
+assert ["abc"] == "abc".split(",")
+assert [""] == "".split(",")
+assert [] == "".split()
+assert [] == "   ".split()
+assert [] == "".split(None)
+assert ["", ""] == ",".split(",")
+assert ["a", "", "b"] == "a,,b".split(",")
+assert ["a", "b"] == "a<>b".split("<>")
+assert ["a,b,c"] == "a,b,c".split(",", 0)
+assert ["a", "b", "c"] == "a,b,c".split(",", -1)
+assert ["a", "b,c"] == "a,b,c".split(sep=",", maxsplit=1)
+assert ["a", "b c "] == " a b c ".split(maxsplit=1)
+assert ["a b c "] == " a b c ".split(None, 0)
+assertRaisesText(ValueError, "empty separator", lambda: "abc".split(""))
+assertRaises(TypeError, lambda: "abc".split(",", "1"))
+assertRaises(TypeError, lambda: "abc".split(foo=","))
+
+doc="rsplit"
+assert ["0","1","2","4"] == "0,1,2,4".rsplit(",")
+assert ["abc"] == "abc".rsplit(",")
+assert [""] == "".rsplit(",")
+assert [] == "".rsplit()
+assert [] == "   ".rsplit()
+assert ["", ""] == ",".rsplit(",")
+assert ["a,d", "c"] == "a,d,c".rsplit(",", 1)
+assert ["a,b,c"] == "a,b,c".rsplit(",", 0)
+assert ["a", "b", "c"] == "a,b,c".rsplit(",", -1)
+assert ["a<>b", "c"] == "a<>b<>c".rsplit("<>", 1)
+assert ['a', 'd', 'b'] == " a   d   b   ".rsplit()
+assert [' a   d', 'b'] == " a   d   b   ".rsplit(None, 1)
+assert [" a b c"] == " a b c ".rsplit(None, 0)
+assert ["a,b", "c"] == "a,b,c".rsplit(sep=",", maxsplit=1)
+assert ["£100", "世界𠜎"] == "£100 世界𠜎".rsplit()
+assertRaisesText(ValueError, "empty separator", lambda: "abc".rsplit(""))
+assertRaisesText(TypeError, "Can't convert 'int' object to str implicitly", lambda: "0,1,2,4".rsplit(1))
 
 doc="ascii len"
 assert len(asc) == 5
@@ -917,4 +952,281 @@ else:
     assert False, "TypeError not raised"
 
 
+doc="startswith with start and end"
+assert "hello".startswith("ll", 2)
+assert not "hello".startswith("ll", 2, 3)
+assert "héllo".startswith("llo", 2)
+assert "hello".startswith(("x", "he"))
+assert not "hello".startswith("h", 10)
+assertRaises(TypeError, "hello".startswith, 1)
+
+doc="endswith with start and end"
+assert "hello".endswith("ll", 0, 4)
+assert not "hello".endswith("ll", 0, 3)
+assert "héllo".endswith("é", 0, 2)
+assert "hello".endswith(("x", "lo"))
+assertRaises(TypeError, "hello".endswith, ("x", 1))
+
+doc="find and index"
+s = "abcabc"
+assert s.find("c") == 2
+assert s.find("c", 3) == 5
+assert s.find("c", 3, 5) == -1
+assert s.find("c", -1) == 5
+assert s.find("") == 0
+assert s.find("", 6) == 6
+assert s.find("", 7) == -1
+assert s.rfind("c") == 5
+assert s.rfind("c", 0, 5) == 2
+assert "héllo wörld".find("ö") == 7
+assert "héllo wörld".find("l", 4) == 9
+assert "héllo wörld".rfind("l") == 9
+assert s.index("bc") == 1
+assert s.rindex("bc") == 4
+assertRaisesText(ValueError, "substring not found", s.index, "x")
+assertRaisesText(ValueError, "substring not found", s.rindex, "x")
+
+doc="count"
+assert "aaaa".count("a") == 4
+assert "aaaa".count("aa") == 2
+assert "aaaa".count("a", 1) == 3
+assert "aaaa".count("a", 1, -1) == 2
+assert "héé".count("é") == 2
+assert "abc".count("") == 4
+assert "abc".count("", 1) == 3
+assert "abc".count("a", 5) == 0
+
+doc="join"
+assert ",".join(["a", "b", "c"]) == "a,b,c"
+assert "".join([]) == ""
+assert "-".join("abc") == "a-b-c"
+assert ", ".join(x for x in ("x", "y")) == "x, y"
+assertRaisesText(TypeError, "sequence item 1: expected str instance, int found", ",".join, ["a", 1])
+
+doc="replace"
+assert "aaa".replace("a", "b") == "bbb"
+assert "aaa".replace("a", "b", 2) == "bba"
+assert "aaa".replace("x", "b") == "aaa"
+assert "abc".replace("", "-") == "-a-b-c-"
+assert "éé".replace("", "-") == "-é-é-"
+
+doc="strip"
+assert "  abc \n".strip() == "abc"
+assert "  abc \n".lstrip() == "abc \n"
+assert "  abc \n".rstrip() == "  abc"
+assert "xxabcxy".strip("xy") == "abc"
+assert "éabcé".strip("é") == "abc"
+assert "abc".strip(None) == "abc"
+
+doc="partition"
+assert "a=b=c".partition("=") == ("a", "=", "b=c")
+assert "abc".partition("=") == ("abc", "", "")
+assert "a=b=c".rpartition("=") == ("a=b", "=", "c")
+assert "abc".rpartition("=") == ("", "", "abc")
+assertRaises(ValueError, "abc".partition, "")
+
+doc="splitlines"
+assert "a\nb\r\nc\rd".splitlines() == ["a", "b", "c", "d"]
+assert "a\nb\r\nc\rd".splitlines(True) == ["a\n", "b\r\n", "c\r", "d"]
+assert "a\n\nb\n".splitlines() == ["a", "", "b"]
+assert "".splitlines() == []
+assert "a\x0bb\x0cc d".splitlines() == ["a", "b", "c", "d"]
+assert "a\n".splitlines(keepends=True) == ["a\n"]
+
+doc="case"
+assert "Hello World".lower() == "hello world"
+assert "Hello World".upper() == "HELLO WORLD"
+assert "Hello World".swapcase() == "hELLO wORLD"
+assert "hello world".capitalize() == "Hello world"
+assert "HELLO".capitalize() == "Hello"
+assert "".capitalize() == ""
+assert "hello wORLD it's".title() == "Hello World It'S"
+assert "Straße".casefold() == "strasse"
+assert "ÉCOLE".lower() == "école"
+
+doc="justify"
+assert "abc".center(7) == "  abc  "
+assert "abc".center(6) == " abc  "
+assert "ab".center(5) == "  ab "
+assert "abc".center(7, "*") == "**abc**"
+assert "abc".center(2) == "abc"
+assert "abc".ljust(5) == "abc  "
+assert "abc".ljust(5, "é") == "abcéé"
+assert "abc".rjust(5) == "  abc"
+assert "abc".rjust(5, "0") == "00abc"
+assertRaisesText(TypeError, "exactly one character", "abc".center, 7, "ab")
+
+doc="zfill"
+assert "42".zfill(5) == "00042"
+assert "-42".zfill(5) == "-0042"
+assert "+42".zfill(5) == "+0042"
+assert "abc".zfill(2) == "abc"
+assert "".zfill(3) == "000"
+
+doc="expandtabs"
+assert "a\tb".expandtabs() == "a       b"
+assert "a\tb".expandtabs(4) == "a   b"
+assert "ab\tc\n\td".expandtabs(tabsize=4) == "ab  c\n    d"
+assert "a\tb".expandtabs(0) == "ab"
+
+doc="is methods"
+assert "abc1".isalnum()
+assert not "abc!".isalnum()
+assert not "".isalnum()
+assert "abcé".isalpha()
+assert not "abc1".isalpha()
+assert "123".isdecimal()
+assert "123".isdigit()
+assert "²".isdigit()
+assert not "²".isdecimal()
+assert "½".isnumeric()
+assert not "½".isdigit()
+assert " \t\n\x1c".isspace()
+assert not "".isspace()
+assert "abc".isprintable()
+assert "".isprintable()
+assert not "a\n".isprintable()
+assert "abc".isascii()
+assert "".isascii()
+assert not "é".isascii()
+assert "abc_1".isidentifier()
+assert "_".isidentifier()
+assert "é".isidentifier()
+assert not "1abc".isidentifier()
+assert not "a-b".isidentifier()
+assert not "".isidentifier()
+assert "abc1".islower()
+assert not "aBc".islower()
+assert not "123".islower()
+assert "ABC1".isupper()
+assert not "AbC".isupper()
+assert "Hello World".istitle()
+assert "Hello 1World".istitle()
+assert not "Hello world".istitle()
+assert not "HEllo".istitle()
+assert not "".istitle()
+
+doc="removeprefix and removesuffix"
+assert "hello".removeprefix("he") == "llo"
+assert "hello".removeprefix("x") == "hello"
+assert "hello".removesuffix("lo") == "hel"
+assert "hello".removesuffix("x") == "hello"
+
+doc="encode"
+assert "héllo".encode() == b"h\xc3\xa9llo"
+assert "héllo".encode("latin-1") == b"h\xe9llo"
+assert "héllo".encode("ascii", "replace") == b"h?llo"
+assert "héllo".encode(encoding="ascii", errors="ignore") == b"hllo"
+assertRaises(UnicodeEncodeError, "héllo".encode, "ascii")
+assert "hé".encode("utf-16-le") == b"h\x00\xe9\x00"
+assert "hé".encode("utf-16-be") == b"\x00h\x00\xe9"
+assert "hé".encode("utf-16") == b"\xff\xfeh\x00\xe9\x00"
+assert "\U0001f600".encode("utf-16-le") == b"=\xd8\x00\xde"
+assert "h".encode("utf-32") == b"\xff\xfe\x00\x00h\x00\x00\x00"
+assert "h".encode("utf-32-be") == b"\x00\x00\x00h"
+assert "héllo".encode("Latin1") == b"h\xe9llo"
+assertRaises(LookupError, "hi".encode, "no-such-codec")
+
+doc="translate and maketrans"
+table = str.maketrans("abc", "xyz")
+assert table == {97: 120, 98: 121, 99: 122}
+assert "aabbcc".translate(table) == "xxyyzz"
+table = str.maketrans("ab", "xy", "c")
+assert "abcd".translate(table) == "xyd"
+table = str.maketrans({"a": "AA", 98: None, "c": 100})
+assert "abcd".translate(table) == "AAdd"
+assert "abc".translate({}) == "abc"
+assert "abc".translate([None, None]) == "abc"
+assertRaises(ValueError, str.maketrans, "ab", "x")
+assertRaises(ValueError, str.maketrans, {"ab": 1})
+assertRaises(TypeError, str.maketrans, "ab")
+
+doc="format"
+assert "{} {}".format(1, 2) == "1 2"
+assert "{1} {0}".format(1, 2) == "2 1"
+assert "{a} {b}".format(a=1, b="x") == "1 x"
+assert "{0}{0}".format("ab") == "abab"
+assert "{{}} {}".format(1) == "{} 1"
+assert "{}}}".format(1) == "1}"
+assert "{!r}".format("a") == "'a'"
+assert "{!s}".format("a") == "a"
+assert "{!a}".format("é") == "'\\xe9'"
+assert "{:>5}".format("ab") == "   ab"
+assert "{0:{1}}".format("ab", 5) == "ab   "
+assert "{:{}{}}".format("ab", ">", 5) == "   ab"
+assert "{0[1]}".format([1, 2]) == "2"
+assert "{0[a]}".format({"a": 3}) == "3"
+assert "{0[a][0]}".format({"a": [4]}) == "4"
+class Point:
+    x = 1
+    y = [2, 3]
+assert "{0.x} {0.y[1]}".format(Point()) == "1 3"
+assert "{p.x}".format(p=Point()) == "1"
+assert "{:d}".format(True) == "1"
+assert "{:.2f}".format(3.14159) == "3.14"
+assert "{:,}".format(1234567) == "1,234,567"
+assert "{:_}".format(1234567) == "1_234_567"
+assert "{:_x}".format(0xdeadbeef) == "dead_beef"
+assert "{:#_b}".format(255) == "0b1111_1111"
+assert "{:_.2f}".format(1234.5) == "1_234.50"
+assert "{:010,}".format(1234) == "00,001,234"
+assert "{:+^9.1%}".format(0.25) == "++25.0%++"
+for x, spec, want in [
+    (3.14159, "4.1", "3e+00"),
+    (3.14159, ".2", "3.1"),
+    (3.14159, ".5", "3.1416"),
+    (0.5, "4.1", " 0.5"),
+    (10.0, ".2", "1e+01"),
+    (10.0, ".3", "10.0"),
+    (100.0, ".3", "1e+02"),
+    (100.0, ".5", "100.0"),
+    (12345.678, ".2", "1.2e+04"),
+    (0.0001234, ".1", "0.0001"),
+    (0.0001234, ".3", "0.000123"),
+    (1e-05, ".3", "1e-05"),
+    (1e16, ".3", "1e+16"),
+    (-2.5, ".2", "-2.5"),
+    (3.0, "", "3.0"),
+]:
+    got = format(x, spec)
+    assert got == want, (x, spec, got)
+assertRaisesText(IndexError, "Replacement index 1", "{} {}".format, 1)
+assertRaises(KeyError, "{a}".format, b=1)
+assertRaisesText(ValueError, "Single '}'", "}".format)
+assertRaisesText(ValueError, "Single '{'", "{".format)
+assertRaisesText(ValueError, "expected '}'", "{0".format, 1)
+assertRaisesText(ValueError, "cannot switch from manual", "{0} {}".format, 1, 2)
+assertRaisesText(ValueError, "cannot switch from automatic", "{} {0}".format, 1, 2)
+assertRaisesText(ValueError, "Max string recursion exceeded", "{:{:{}}}".format, 1, 2, 3)
+assertRaisesText(ValueError, "Cannot specify both", "{:,_}".format, 1)
+assertRaisesText(ValueError, "Cannot specify ',' with 'x'", "{:,x}".format, 1)
+
+doc="format_map"
+assert "{a} {b}".format_map({"a": 1, "b": 2}) == "1 2"
+class Default:
+    def __getitem__(self, key):
+        return key.upper()
+assert "{a}-{b}".format_map(Default()) == "A-B"
+assertRaises(KeyError, "{a}".format_map, {})
+
+doc="printf style formatting"
+assert "%s and %r" % ("a", "b") == "a and 'b'"
+assert "%d %i %u" % (1, 2.7, True) == "1 2 1"
+assert "%5.2f|%-6s|%06d" % (2.5, "x", -3) == " 2.50|x     |-00003"
+assert "%#x %#o %X %+d % d" % (255, 8, 255, 5, 5) == "0xff 0o10 FF +5  5"
+assert "%.3d %*d %-*d|" % (7, 4, 1, 3, 2) == "007    1 2  |"
+assert "%e %g %.2E" % (12345.678, 0.0001, 1.5) == "1.234568e+04 0.0001 1.50E+00"
+assert "%c%c %a %.2s %%" % (65, "z", "\xe9", "abc") == "Az '\\xe9' ab %"
+assert "%s" % [1, 2] == "[1, 2]"
+assert "%s" % (1,) == "1"
+assert "%(name)s is %(age)d" % {"name": "Bob", "age": 42} == "Bob is 42"
+assert "%(a)s %(a)r" % {"a": "x"} == "x 'x'"
+assertRaisesText(TypeError, "not enough arguments for format string", lambda: "%s %s" % (1,))
+assertRaisesText(TypeError, "not all arguments converted during string formatting", lambda: "%s" % (1, 2))
+assertRaisesText(TypeError, "format requires a mapping", lambda: "%(a)s" % 1)
+assertRaisesText(TypeError, "%d format: a real number is required, not str", lambda: "%d" % "x")
+assertRaisesText(ValueError, "unsupported format character 'y' (0x79) at index 1", lambda: "%y" % 1)
+assertRaisesText(ValueError, "incomplete format", lambda: "abc%" % ())
+assertRaises(KeyError, lambda: "%(b)s" % {"a": 1})
+
 doc="finished"
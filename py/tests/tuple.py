@@ -15,6 +15,9 @@ assert repr((1,2,3)) == "(1, 2, 3)"
 assert repr((1,(2,3),4)) == "(1, (2, 3), 4)"
 assert repr(("1",(2.5,17,()))) == "('1', (2.5, 17, ()))"
 assert repr((1, 1.0)) == "(1, 1.0)"
+a = ([], 1)
+a[0].append(a)
+assert repr(a) == "([(...)], 1)"
 
 doc="mul"
 a = (1, 2, 3)
@@ -52,4 +55,25 @@ except TypeError:
 else:
     assert False, "TypeError not raised"
 
+doc="add"
+assert (1,) + (2, 3) == (1, 2, 3)
+assert (1, 2) + (3,) == (1, 2, 3)
+assert () + (1,) == (1,)
+assert (1,) + () == (1,)
+
+doc="comparison"
+assert (1, 2) < (1, 3)
+assert (1, 2) < (1, 2, 0)
+assert (1, 2) <= (1, 2)
+assert (2,) > (1, 5)
+assert (1, 2, 0) >= (1, 2)
+assert not (() < ())
+assert sorted([(2, 'b'), (1, 'z'), (1, 'a')]) == [(1, 'a'), (1, 'z'), (2, 'b')]
+try:
+    (1,) < ("a",)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
 doc="finished"
@@ -9,7 +9,10 @@ package py
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
+	"unicode/utf8"
 )
 
 // A python Traceback object
@@ -49,11 +52,98 @@ RuntimeError: this is the error message
 */
 
 // Dump a traceback for tb to w
+//
+// The line of source for each entry is printed if the source file can
+// be read, with carets under the part of it which was running as
+// CPython does.
 func (tb *Traceback) TracebackDump(w io.Writer) {
 	for ; tb != nil; tb = tb.Next {
 		fmt.Fprintf(w, "  File %q, line %d, in %s\n", tb.Frame.Code.Filename, tb.Lineno, tb.Frame.Code.Name)
-		fmt.Fprintf(w, "    %s\n", "FIXME line of source goes here")
+		if line := SourceLine(tb.Frame.Code.Filename, int(tb.Lineno)); line != "" {
+			fmt.Fprintf(w, "    %s\n", line)
+			if markers := tb.markers(); markers != "" {
+				fmt.Fprintf(w, "    %s\n", markers)
+			}
+		}
+	}
+}
+
+// Returns the carets to print under the source line of tb marking the
+// instruction which was running, or "" if that isn't known or is the
+// whole line
+func (tb *Traceback) markers() string {
+	position, ok := tb.Frame.Code.Addr2Position(tb.Lasti - 1)
+	if !ok || position.Lineno != tb.Lineno {
+		return ""
+	}
+	line := SourceLines(tb.Frame.Code.Filename)[tb.Lineno-1]
+	endOffset := int(position.EndColOffset)
+	if position.EndLineno != position.Lineno {
+		endOffset = len(line)
+	}
+	text, start, end := sourceColumns(line, int(position.ColOffset), endOffset)
+	if end-start >= utf8.RuneCountInString(text) {
+		return ""
 	}
+	return carets(start, end)
+}
+
+// Returns line with the white space around it removed along with the
+// columns in characters of text for the byte offsets into line of
+// offset and endOffset.  The end is always after the start.
+func sourceColumns(line string, offset, endOffset int) (text string, start, end int) {
+	line = strings.TrimRight(line, " \t\f\r\n")
+	text = strings.TrimLeft(line, " \t\f")
+	indent := len(line) - len(text)
+	column := func(offset int) int {
+		if offset < indent {
+			offset = indent
+		} else if offset > len(line) {
+			offset = len(line)
+		}
+		return utf8.RuneCountInString(line[indent:offset])
+	}
+	start, end = column(offset), column(endOffset)
+	if end <= start {
+		end = start + 1
+	}
+	return text, start, end
+}
+
+// Returns carets under the columns from start up to end
+func carets(start, end int) string {
+	return strings.Repeat(" ", start) + strings.Repeat("^", end-start)
+}
+
+// Lines of the source files read by SourceLines
+var sourceLines = map[string][]string{}
+
+// SourceLines returns the lines of the source file filename without
+// their line endings, or nil if it can't be read.  Files are read
+// once and cached.
+func SourceLines(filename string) []string {
+	lines, ok := sourceLines[filename]
+	if !ok {
+		data, err := ioutil.ReadFile(filename)
+		if err == nil {
+			text := strings.TrimSuffix(string(data), "\n")
+			lines = strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+		}
+		sourceLines[filename] = lines
+	}
+	return lines
+}
+
+// SourceLine returns line lineno (counting from 1) of the source file
+// filename with leading and trailing white space removed
+//
+// It returns an empty string if the line can't be read.
+func SourceLine(filename string, lineno int) string {
+	lines := SourceLines(filename)
+	if lineno < 1 || lineno > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[lineno-1])
 }
 
 // Dumps a traceback to stderr
@@ -64,8 +154,7 @@ func TracebackDump(err interface{}) {
 	case *ExceptionInfo:
 		e.TracebackDump(os.Stderr)
 	case *Exception:
-		fmt.Fprintf(os.Stderr, "Exception %#v\n", e)
-		fmt.Fprintf(os.Stderr, "-- No traceback available --\n")
+		e.TracebackDump(os.Stderr)
 	default:
 		fmt.Fprintf(os.Stderr, "Error %#v\n", err)
 		fmt.Fprintf(os.Stderr, "-- No traceback available --\n")
@@ -74,7 +163,7 @@ func TracebackDump(err interface{}) {
 
 // Properties
 func init() {
-	TracebackType.Dict["__tb_next__"] = &Property{
+	TracebackType.Dict["tb_next"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			next := self.(*Traceback).Next
 			if next == nil {
@@ -83,17 +172,17 @@ func init() {
 			return next, nil
 		},
 	}
-	TracebackType.Dict["__tb_frame__"] = &Property{
+	TracebackType.Dict["tb_frame"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Traceback).Frame, nil
 		},
 	}
-	TracebackType.Dict["__tb_lasti__"] = &Property{
+	TracebackType.Dict["tb_lasti"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return Int(self.(*Traceback).Lasti), nil
 		},
 	}
-	TracebackType.Dict["__tb_lineno__"] = &Property{
+	TracebackType.Dict["tb_lineno"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return Int(self.(*Traceback).Lineno), nil
 		},
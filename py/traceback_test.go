@@ -0,0 +1,160 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTracebackDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpython-traceback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "prog.py")
+	err = ioutil.WriteFile(filename, []byte("def f():\n    raise KeyError('k')\nf()\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := &Frame{Code: &Code{Filename: filename, Name: "<module>"}}
+	f := &Frame{Code: &Code{Filename: filename, Name: "f"}}
+	missing := &Frame{Code: &Code{Filename: filepath.Join(dir, "missing.py"), Name: "g"}}
+
+	cause := ExceptionNewf(KeyError, "k")
+	cause.Traceback = NewTraceback(NewTraceback(nil, f, 0, 2), module, 0, 3)
+	exc := ExceptionNewf(ValueError, "bad")
+	exc.Cause = cause
+	exc.Context = ExceptionNewf(TypeError, "ignored")
+	exc.SuppressContext = true
+	excInfo := ExceptionInfo{Type: ValueError, Value: exc, Traceback: NewTraceback(nil, missing, 0, 1)}
+
+	var buf bytes.Buffer
+	excInfo.TracebackDump(&buf)
+	want := `Traceback (most recent call last):
+  File "` + filename + `", line 3, in <module>
+    f()
+  File "` + filename + `", line 2, in f
+    raise KeyError('k')
+KeyError: 'k'
+
+The above exception was the direct cause of the following exception:
+
+Traceback (most recent call last):
+  File "` + filepath.Join(dir, "missing.py") + `", line 1, in g
+ValueError: bad
+`
+	if got := buf.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+
+	// Context is shown when not suppressed
+	exc = ExceptionNewf(ValueError, "")
+	exc.Context = ExceptionNewf(TypeError, "first")
+	buf.Reset()
+	exc.TracebackDump(&buf)
+	want = `TypeError: first
+
+During handling of the above exception, another exception occurred:
+
+ValueError
+`
+	if got := buf.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestSourceLine(t *testing.T) {
+	f, err := ioutil.TempFile("", "gpython-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("first\n    second  \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		lineno int
+		want   string
+	}{
+		{0, ""},
+		{1, "first"},
+		{2, "second"},
+		{3, ""},
+		{4, ""},
+	} {
+		got := SourceLine(f.Name(), test.lineno)
+		if got != test.want {
+			t.Errorf("line %d: want %q got %q", test.lineno, test.want, got)
+		}
+	}
+	if got := SourceLine("<stdin>", 1); got != "" {
+		t.Errorf("<stdin>: want empty got %q", got)
+	}
+}
+
+func TestTracebackMarkers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpython-traceback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "prog.py")
+	err = ioutil.WriteFile(filename, []byte("    x = f(a) + 1\ny = f(\n  a)\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := &Code{Filename: filename, Name: "<module>", Positions: []CodePosition{
+		{Addr: 0, Lineno: 1, ColOffset: 8, EndLineno: 1, EndColOffset: 12},
+		{Addr: 3, Lineno: 1, ColOffset: 4, EndLineno: 1, EndColOffset: 16},
+		{Addr: 6, Lineno: 2, ColOffset: 4, EndLineno: 3, EndColOffset: 4},
+		{Addr: 9},
+	}}
+	frame := &Frame{Code: code}
+	for _, test := range []struct {
+		lasti  int32
+		lineno int32
+		want   string
+	}{
+		{3, 1, "    x = f(a) + 1\n        ^^^^\n"},
+		{6, 1, "    x = f(a) + 1\n"},
+		{9, 2, "    y = f(\n        ^^\n"},
+		{12, 2, "    y = f(\n"},
+		{3, 2, "    y = f(\n"},
+	} {
+		var buf bytes.Buffer
+		NewTraceback(nil, frame, test.lasti, test.lineno).TracebackDump(&buf)
+		want := `  File "` + filename + `", line ` + fmt.Sprint(test.lineno) + ", in <module>\n" + test.want
+		if got := buf.String(); got != want {
+			t.Errorf("lasti %d: want\n%s\ngot\n%s", test.lasti, want, got)
+		}
+	}
+}
+
+func TestSyntaxErrorLocation(t *testing.T) {
+	for _, test := range []struct {
+		err  *Exception
+		want string
+	}{
+		{MakeSyntaxError(ExceptionNewf(SyntaxError, "invalid syntax"), "<stdin>", 1, 8, "x = (1 +)"), "\n  File \"<stdin>\", line 1\n    x = (1 +)\n            ^\nSyntaxError: 'invalid syntax'"},
+		{MakeSyntaxErrorRange(ExceptionNewf(SyntaxError, "bad"), "f.py", 2, 5, 2, 8, "  if a b:\n"), "\n  File \"f.py\", line 2\n    if a b:\n       ^^^\nSyntaxError: 'bad'"},
+		{MakeSyntaxErrorRange(ExceptionNewf(SyntaxError, "bad"), "f.py", 2, 2, 3, 1, "  f(\n"), "\n  File \"f.py\", line 2\n    f(\n    ^^\nSyntaxError: 'bad'"},
+		{MakeSyntaxError(ExceptionNewf(SyntaxError, "bad"), "f.py", 1, 0, ""), "\n  File \"f.py\", line 1\nSyntaxError: 'bad'"},
+	} {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("want %q got %q", test.want, got)
+		}
+	}
+}
@@ -45,8 +45,13 @@ func (t Tuple) Reverse() {
 }
 
 // output the tuple to out, using fn to transform the tuple to out
-// start and end brackets
-func (t Tuple) repr(start, end string) (Object, error) {
+// start and end brackets.  self is the container whose items are t.
+func (t Tuple) repr(self Object, start, end string) (Object, error) {
+	seen, err := reprEnter(self)
+	if err != nil || seen {
+		return String(start + "..." + end), err
+	}
+	defer reprLeave()
 	var out bytes.Buffer
 	out.WriteString(start)
 	for i, obj := range t {
@@ -68,7 +73,7 @@ func (t Tuple) M__str__() (Object, error) {
 }
 
 func (t Tuple) M__repr__() (Object, error) {
-	return t.repr("(", ")")
+	return t.repr(t, "(", ")")
 }
 
 func (t Tuple) M__len__() (Object, error) {
@@ -79,6 +84,14 @@ func (t Tuple) M__bool__() (Object, error) {
 	return NewBool(len(t) > 0), nil
 }
 
+func (t Tuple) M__hash__() (Object, error) {
+	h, err := hashTuple(t)
+	if err != nil {
+		return nil, err
+	}
+	return Int(h), nil
+}
+
 func (t Tuple) M__iter__() (Object, error) {
 	return NewIterator(t), nil
 }
@@ -110,7 +123,7 @@ func (a Tuple) M__add__(other Object) (Object, error) {
 	if b, ok := other.(Tuple); ok {
 		newTuple := make(Tuple, len(a)+len(b))
 		copy(newTuple, a)
-		copy(newTuple[len(b):], b)
+		copy(newTuple[len(a):], b)
 		return newTuple, nil
 	}
 
@@ -131,6 +144,9 @@ func (a Tuple) M__iadd__(other Object) (Object, error) {
 func (l Tuple) M__mul__(other Object) (Object, error) {
 	if b, ok := convertToInt(other); ok {
 		m := len(l)
+		if err := checkRepeat(m, objectSize, b); err != nil {
+			return nil, err
+		}
 		n := int(b) * m
 		if n < 0 {
 			n = 0
@@ -152,6 +168,38 @@ func (a Tuple) M__imul__(other Object) (Object, error) {
 	return a.M__mul__(other)
 }
 
+func (a Tuple) M__lt__(other Object) (Object, error) {
+	b, ok := other.(Tuple)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a, b, Lt)
+}
+
+func (a Tuple) M__le__(other Object) (Object, error) {
+	b, ok := other.(Tuple)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a, b, Le)
+}
+
+func (a Tuple) M__gt__(other Object) (Object, error) {
+	b, ok := other.(Tuple)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a, b, Gt)
+}
+
+func (a Tuple) M__ge__(other Object) (Object, error) {
+	b, ok := other.(Tuple)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return sequenceCompare(a, b, Ge)
+}
+
 func (a Tuple) M__eq__(other Object) (Object, error) {
 	b, ok := other.(Tuple)
 	if !ok {
@@ -203,4 +251,4 @@ var _ I__getitem__ = Tuple(nil)
 var _ I__eq__ = Tuple(nil)
 var _ I__ne__ = Tuple(nil)
 
-// var _ richComparison = Tuple(nil)
+var _ richComparison = Tuple(nil)
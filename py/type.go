@@ -15,6 +15,9 @@ package py
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"unicode"
 )
 
 // Type flags (tp_flags)
@@ -61,6 +64,10 @@ const (
 	// Type is abstract and cannot be instantiated
 	TPFLAGS_IS_ABSTRACT uint = 1 << 20
 
+	// Set if New makes instances of the type it is passed so that
+	// python subclasses can use the New and Init of the type
+	TPFLAGS_SUBCLASS_NEW uint = 1 << 21
+
 	// These flags are used to determine if a type is a subclass.
 	TPFLAGS_INT_SUBCLASS      uint = 1 << 23
 	TPFLAGS_LONG_SUBCLASS     uint = 1 << 24
@@ -91,6 +98,13 @@ type Type struct {
 	//	Dictoffset int
 	Bases Tuple
 	Mro   Tuple // method resolution order
+	Slots Tuple // names defined in __slots__ by this type, sorted
+	// Set if instances of this heap type have no __dict__ or
+	// can't be weakly referenced because of __slots__
+	NoDict     bool
+	NoWeakref  bool
+	SlotValues []Object // values of __slots__ for instances, nil if unset
+	NumSlots   int      // number of __slots__ values instances have
 	//	Cache      Object
 	//	Subclasses Tuple
 	//	Weaklist   Tuple
@@ -180,9 +194,10 @@ type Type struct {
 }
 
 var TypeType *Type = &Type{
-	Name: "type",
-	Doc:  "type(object) -> the object's type\ntype(name, bases, dict) -> a new type",
-	Dict: StringDict{},
+	Name:  "type",
+	Doc:   "type(object) -> the object's type\ntype(name, bases, dict) -> a new type",
+	Flags: TPFLAGS_BASETYPE,
+	Dict:  StringDict{},
 }
 
 var ObjectType = &Type{
@@ -200,6 +215,65 @@ func init() {
 	ObjectType.New = ObjectNew
 	ObjectType.Init = ObjectInit
 	ObjectType.ObjectType = TypeType
+	ObjectType.Dict["__class__"] = &Property{
+		Fget: objectGetClass,
+		Fset: objectSetClass,
+		Doc:  "the object's class",
+	}
+	ObjectType.Dict["__dict__"] = &Property{
+		Fget: objectGetDict,
+		Fset: objectSetDict,
+	}
+	TypeType.Dict["__dict__"] = &Property{
+		Fget: typeGetDict,
+	}
+	TypeType.Dict["__name__"] = &Property{
+		Fget: typeGetName,
+		Fset: typeSetName,
+	}
+	TypeType.Dict["__qualname__"] = &Property{
+		Fget: typeGetQualname,
+		Fset: typeSetQualname,
+	}
+	TypeType.Dict["__module__"] = &Property{
+		Fget: typeGetModule,
+	}
+	TypeType.Dict["__bases__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Type).Bases.Copy(), nil
+		},
+	}
+	TypeType.Dict["__base__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			if base := self.(*Type).Base; base != nil {
+				return base, nil
+			}
+			return None, nil
+		},
+	}
+	TypeType.Dict["__mro__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Type).Mro.Copy(), nil
+		},
+	}
+	ObjectType.Dict["__init_subclass__"] = &ClassMethod{
+		Callable: MustNewMethod("__init_subclass__", objectInitSubclass, 0, objectInitSubclassDoc),
+		Dict:     NewStringDict(),
+	}
+	ObjectType.Dict["__subclasshook__"] = &ClassMethod{
+		Callable: MustNewMethod("__subclasshook__", objectSubclassHook, 0, objectSubclassHookDoc),
+		Dict:     NewStringDict(),
+	}
+	ObjectType.Dict["__getattribute__"] = NewGoMethod1("__getattribute__", objectGetAttribute)
+	ObjectType.Dict["__setattr__"] = NewGoMethod("__setattr__", objectSetAttr)
+	ObjectType.Dict["__delattr__"] = NewGoMethod1("__delattr__", objectDelAttr)
+	TypeType.Dict["__setattr__"] = NewGoMethod("__setattr__", typeSetAttr)
+	TypeType.Dict["__delattr__"] = NewGoMethod1("__delattr__", typeDelAttr)
+	TypeType.Dict["__call__"] = MustNewMethod("__call__", typeCall, 0, typeCallDoc)
+	TypeType.Dict["__prepare__"] = &ClassMethod{
+		Callable: MustNewMethod("__prepare__", typePrepare, 0, typePrepareDoc),
+		Dict:     NewStringDict(),
+	}
 	err := TypeType.Ready()
 	if err != nil {
 		log.Fatal(err)
@@ -226,12 +300,25 @@ func (t *Type) GetDict() StringDict {
 }
 
 // delayedReady holds types waiting to be intialised
-var delayedReady = []*Type{}
+var (
+	delayedReady = []*Type{}
+	// Set once the delayed types are ready - indirect to avoid an
+	// initialisation loop
+	readyType func(t *Type) error
+)
 
 // TypeDelayReady stores the list of types to initialise
 //
-// Call MakeReady when all initialised
+// Call MakeReady when all initialised.  Types made after that (eg in
+// modules other than py) are readied immediately.
 func TypeDelayReady(t *Type) {
+	if readyType != nil {
+		err := readyType(t)
+		if err != nil {
+			log.Fatalf("Error initialising go type %s: %v", t.Name, err)
+		}
+		return
+	}
 	delayedReady = append(delayedReady, t)
 }
 
@@ -252,6 +339,7 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	readyType = (*Type).Ready
 }
 
 // Make a new type from a name
@@ -297,16 +385,16 @@ func (t *Type) NewTypeFlags(Name string, Doc string, New NewFunc, Init InitFunc,
 	}
 	// FIXME inherit more stuff
 	tt := &Type{
-		ObjectType: t,
+		ObjectType: TypeType,
 		Name:       Name,
 		Doc:        Doc,
 		New:        New,
 		Init:       Init,
-		Flags:      Flags,
+		Flags:      Flags &^ (TPFLAGS_READY | TPFLAGS_READYING),
 		Dict:       StringDict{},
 		Bases:      Tuple{t},
 	}
-	TypeDelayReady(t)
+	TypeDelayReady(tt)
 	return tt
 }
 
@@ -373,6 +461,31 @@ func (a *Type) IsSubtype(b *Type) bool {
 
 // Call type()
 func (t *Type) M__call__(args Tuple, kwargs StringDict) (Object, error) {
+	// Instances of python classes are Types too, calling one calls
+	// the __call__ method of its class
+	if objType := t.Type(); objType != nil && !objType.IsSubtype(TypeType) {
+		fn := objType.Lookup("__call__")
+		if fn == nil {
+			return nil, ExceptionNewf(TypeError, "'%s' object is not callable", objType.Name)
+		}
+		method, err := descriptorGet(fn, t, objType)
+		if err != nil {
+			return nil, err
+		}
+		return Call(method, args, kwargs)
+	}
+	// A metaclass defined in python may define __call__ to change
+	// what calling its classes does
+	if fn := t.lookupSpecial("__call__"); fn != nil {
+		return Call(fn, append(Tuple{t}, args...), kwargs)
+	}
+	return t.call(args, kwargs)
+}
+
+// Makes an instance of t by calling its New and Init
+//
+// This is the equivalent of type.__call__
+func (t *Type) call(args Tuple, kwargs StringDict) (Object, error) {
 	if t.New == nil {
 		return nil, ExceptionNewf(TypeError, "cannot create '%s' instances", t.Name)
 	}
@@ -454,6 +567,23 @@ func (t *Type) Lookup(name string) Object {
 	return res
 }
 
+// typeEpoch is incremented whenever the attributes of a type change
+// which invalidates all the AttrCaches
+var typeEpoch uint64
+
+// Modified must be called by Go code which changes the Dict of a
+// type which may already be in use so that any attributes cached
+// from it are looked up again.  Setting and deleting attributes of a
+// class with SetAttrString and DeleteAttrString calls it already.
+//
+// This is the equivalent of PyType_Modified
+func (t *Type) Modified() {
+	typeEpoch++
+	if t.Flags&TPFLAGS_HEAPTYPE != 0 {
+		t.fixupSlotDispatchers()
+	}
+}
+
 // Get an attribute from the type of a go type
 //
 // Doesn't call __getattr__ etc
@@ -520,26 +650,55 @@ func (t *Type) CallMethod(name string, args Tuple, kwargs StringDict) (Object, b
 //
 // May raise exceptions if calling the method fails
 func TypeCall(self Object, name string, args Tuple, kwargs StringDict) (Object, bool, error) {
+	fn := typeSpecial(self, name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	res, err := Call(fn, args, kwargs)
+	return res, true, err
+}
+
+// Returns the special method name of self if it is a *Type which has
+// one, or nil
+func typeSpecial(self Object, name string) Object {
 	t, ok := self.(*Type)
 	if !ok {
-		return nil, false, nil
+		return nil
 	}
-	return t.CallMethod(name, args, kwargs)
+	return t.lookupSpecial(name)
 }
 
+// The TypeCallN functions only make the arguments if the method is
+// found, so looking for a method which isn't there doesn't allocate
+
 // Calls TypeCall with 0 arguments
 func TypeCall0(self Object, name string) (Object, bool, error) {
-	return TypeCall(self, name, Tuple{self}, nil)
+	fn := typeSpecial(self, name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	res, err := Call(fn, Tuple{self}, nil)
+	return res, true, err
 }
 
 // Calls TypeCall with 1 argument
 func TypeCall1(self Object, name string, arg Object) (Object, bool, error) {
-	return TypeCall(self, name, Tuple{self, arg}, nil)
+	fn := typeSpecial(self, name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	res, err := Call(fn, Tuple{self, arg}, nil)
+	return res, true, err
 }
 
 // Calls TypeCall with 2 arguments
 func TypeCall2(self Object, name string, arg1, arg2 Object) (Object, bool, error) {
-	return TypeCall(self, name, Tuple{self, arg1, arg2}, nil)
+	fn := typeSpecial(self, name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	res, err := Call(fn, Tuple{self, arg1, arg2}, nil)
+	return res, true, err
 }
 
 // Internal routines to do a method lookup in the type
@@ -975,6 +1134,14 @@ func remove_subclass(base, t *Type) {
 	// }
 }
 
+// SetBases sets the bases of a type made with NewType and friends
+// which only take a single base, recalculating the method resolution
+// order
+func (t *Type) SetBases(bases Tuple) error {
+	t.Bases = bases
+	return t.mro_internal()
+}
+
 // Ready the type for use
 //
 // Returns an error on problems
@@ -1046,7 +1213,18 @@ func (t *Type) Ready() error {
 	}
 
 	// Add type-specific descriptors to tp_dict
-	// FIXME not doing this
+	//
+	// Only __new__ and __init__ are added so far so that they can
+	// be called from python, eg via super()
+	if t.Flags&TPFLAGS_HEAPTYPE == 0 {
+		if _, ok := dict["__new__"]; !ok && t.New != nil {
+			dict["__new__"] = newTpNewWrapper(t)
+		}
+		if _, ok := dict["__init__"]; !ok && t.Init != nil {
+			dict["__init__"] = newTpInitWrapper(t)
+		}
+	}
+	// FIXME not doing the rest of this
 	// if add_operators(t) < 0 {
 	// 	goto error
 	// }
@@ -1092,7 +1270,7 @@ func (t *Type) Ready() error {
 
 	// if the type dictionary doesn't contain a __doc__, set it from
 	// the tp_doc slot.
-	if _, ok := t.Dict["__doc__"]; ok {
+	if _, ok := t.Dict["__doc__"]; !ok {
 		if t.Doc != "" {
 			t.Dict["__doc__"] = String(t.Doc)
 		} else {
@@ -1118,8 +1296,9 @@ func (t *Type) Ready() error {
 }
 
 func (t *Type) extra_ivars(base *Type) bool {
-	return false
-	/* FIXME implement this
+	// Only the names in __slots__ add to the layout of instances
+	return len(t.Slots) > 0
+	/* FIXME implement the rest of this
 	   	t_size := t.Basicsize;
 	   	b_size := base.Basicsize;
 
@@ -1205,7 +1384,15 @@ func (t *Type) Alloc() *Type {
 	obj := &Type{
 		ObjectType: t,
 		Base:       t,
-		Dict:       StringDict{},
+	}
+	if !t.NoDict {
+		obj.Dict = StringDict{}
+	}
+	if t.NumSlots > 0 {
+		obj.SlotValues = make([]Object, t.NumSlots)
+	}
+	if t.Lookup("__del__") != nil {
+		addDelFinalizer(obj)
 	}
 	return obj
 }
@@ -1227,14 +1414,13 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	}
 
 	// SF bug 475327 -- if that didn't trigger, we need 3
-	// arguments. but PyArg_ParseTupleAndKeywords below may give
-	// a msg saying type() needs exactly 3.
-	if len(args)+len(kwargs) != 3 {
+	// arguments.  Any keyword arguments are for __init_subclass__
+	if len(args) != 1 && len(args) != 3 {
 		return nil, ExceptionNewf(TypeError, "type() takes 1 or 3 arguments")
 	}
 
 	// Check arguments: (name, bases, dict)
-	err := ParseTupleAndKeywords(args, kwargs, "UOO:type", []string{"name", "bases", "dict"},
+	err := ParseTuple(args, "UOO:type",
 		&nameObj,
 		&basesObj,
 		&orig_dictObj)
@@ -1243,7 +1429,10 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	}
 	name := nameObj.(String)
 	bases := basesObj.(Tuple)
-	orig_dict := orig_dictObj.(StringDict)
+	orig_dict, err := AsStringDict(orig_dictObj)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine the proper metatype to deal with this:
 	winner, err = metatype.CalculateMetaclass(bases)
@@ -1277,122 +1466,89 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	dict := orig_dict.Copy()
 
 	// Check for a __slots__ sequence variable in dict, and count it
-	slots, haveSlots := dict["__slots__"]
-	nslots := 0
-	// add_dict := 0
-	// add_weak := 0
-	// may_add_dict = base.tp_dictoffset == 0
-	// may_add_weak = base.tp_weaklistoffset == 0 && base.tp_itemsize == 0
+	slotsObj, haveSlots := dict["__slots__"]
+	var slots Tuple
+	add_dict := false
+	add_weak := false
+	may_add_dict := !baseHasDict(base)
+	may_add_weak := !baseHasWeakref(base)
 	if !haveSlots {
-		// if may_add_dict {
-		// 	add_dict++
-		// }
-		// if may_add_weak {
-		// 	add_weak++
-		// }
+		if may_add_dict {
+			add_dict = true
+		}
+		if may_add_weak {
+			add_weak = true
+		}
 	} else {
-		_ = slots
-		return nil, ExceptionNewf(SystemError, "Can't do __slots__ yet")
-		/* FIXME ignore slots for the moment
 		// Have slots
 
 		// Make it into a tuple
-		if PyUnicode_Check(slots) {
-			slots = PyTuple_Pack(1, slots)
+		if slotName, ok := slotsObj.(String); ok {
+			slots = Tuple{slotName}
 		} else {
-			slots = PySequence_Tuple(slots)
-		}
-		if slots == nil {
-			goto error
-		}
-		assert(PyTuple_Check(slots))
-
-		// Are slots allowed?
-		nslots = PyTuple_GET_SIZE(slots)
-		if nslots > 0 && base.tp_itemsize != 0 {
-			PyErr_Format(PyExc_TypeError,
-				"nonempty __slots__ not supported for subtype of '%s'",
-				base.tp_name)
-			goto error
+			slots, err = SequenceTuple(slotsObj)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		// Check for valid slot names and two special cases
-		for i = 0; i < nslots; i++ {
-			PyObject * tmp = PyTuple_GET_ITEM(slots, i)
-			if !valid_identifier(tmp) {
-				goto error
+		for _, slot := range slots {
+			slotName, ok := slot.(String)
+			if !ok {
+				return nil, ExceptionNewf(TypeError, "__slots__ items must be strings, not '%s'", slot.Type().Name)
 			}
-			assert(PyUnicode_Check(tmp))
-			if _PyUnicode_CompareWithId(tmp, &PyId___dict__) == 0 {
+			if !isIdentifier(string(slotName)) {
+				return nil, ExceptionNewf(TypeError, "__slots__ must be identifiers")
+			}
+			switch slotName {
+			case "__dict__":
 				if !may_add_dict || add_dict {
-					PyErr_SetString(PyExc_TypeError,
-						"__dict__ slot disallowed: we already got one")
-					goto error
+					return nil, ExceptionNewf(TypeError, "__dict__ slot disallowed: we already got one")
 				}
-				add_dict++
-			}
-			if PyUnicode_CompareWithASCIIString(tmp, "__weakref__") == 0 {
+				add_dict = true
+			case "__weakref__":
 				if !may_add_weak || add_weak {
-					PyErr_SetString(PyExc_TypeError,
-						"__weakref__ slot disallowed: either we already got one, or __itemsize__ != 0")
-					goto error
+					return nil, ExceptionNewf(TypeError, "__weakref__ slot disallowed: either we already got one, or __itemsize__ != 0")
 				}
-				add_weak++
+				add_weak = true
 			}
 		}
 
-		// Copy slots into a list, mangle names and sort them.
-		// Sorted names are needed for __class__ assignment.
-		// Convert them back to tuple at the end.
-
-		newslots = PyList_New(nslots - add_dict - add_weak)
-		for i, j = 0, 0; i < nslots; i++ {
-			tmp = PyTuple_GET_ITEM(slots, i)
-			if (add_dict &&
-				_PyUnicode_CompareWithId(tmp, &PyId___dict__) == 0) ||
-				(add_weak &&
-					PyUnicode_CompareWithASCIIString(tmp, "__weakref__") == 0) {
+		// Copy slots into a list and sort them.  Sorted names are
+		// needed for __class__ assignment.
+		//
+		// FIXME names should be mangled here but the compiler
+		// doesn't mangle private names yet either
+		newslots := make([]string, 0, len(slots))
+		for _, slot := range slots {
+			slotName := string(slot.(String))
+			if slotName == "__dict__" || slotName == "__weakref__" {
 				continue
 			}
-			tmp = _Py_Mangle(name, tmp)
-			if !tmp {
-				goto error
-			}
-			PyList_SET_ITEM(newslots, j, tmp)
-			if PyDict_GetItem(dict, tmp) {
-				PyErr_Format(PyExc_ValueError,
-					"%R in __slots__ conflicts with class variable",
-					tmp)
-				goto error
+			if _, ok := dict[slotName]; ok {
+				return nil, ExceptionNewf(ValueError, "'%s' in __slots__ conflicts with class variable", slotName)
 			}
-			j++
+			newslots = append(newslots, slotName)
 		}
-		assert(j == nslots-add_dict-add_weak)
-		nslots = j
-		Py_CLEAR(slots)
-		if PyList_Sort(newslots) == -1 {
-			goto error
+		sort.Strings(newslots)
+		slots = make(Tuple, len(newslots))
+		for i, slotName := range newslots {
+			slots[i] = String(slotName)
 		}
-		slots = PyList_AsTuple(newslots)
 
 		// Secondary bases may provide weakrefs or dict
-		if nbases > 1 &&
-			((may_add_dict && !add_dict) ||
-				(may_add_weak && !add_weak)) {
-			for i = 0; i < nbases; i++ {
-				tmp = PyTuple_GET_ITEM(bases, i)
+		if len(bases) > 1 && ((may_add_dict && !add_dict) || (may_add_weak && !add_weak)) {
+			for _, tmp := range bases {
 				if tmp == base {
 					continue // Skip primary base
 				}
-				// assert(PyType_Check(tmp));
-				tmptype = tmp.(*Type)
-				if may_add_dict && !add_dict &&
-					tmptype.tp_dictoffset != 0 {
-					add_dict++
+				tmptype := tmp.(*Type)
+				if may_add_dict && !add_dict && baseHasDict(tmptype) {
+					add_dict = true
 				}
-				if may_add_weak && !add_weak &&
-					tmptype.tp_weaklistoffset != 0 {
-					add_weak++
+				if may_add_weak && !add_weak && baseHasWeakref(tmptype) {
+					add_weak = true
 				}
 				if may_add_dict && !add_dict {
 					continue
@@ -1404,23 +1560,40 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 				break
 			}
 		}
-		*/
 	}
 
 	// Allocate the type object
-	_ = nslots // FIXME
 	new_type = metatype.Alloc()
 	new_type.New = ObjectNew   // FIXME metatype.New // FIXME?
 	new_type.Init = ObjectInit // FIXME metatype.New // FIXME?
+	// Exception instances carry their type so subclasses can
+	// use the constructor and initialiser of the base exception
+	if base.Flags&TPFLAGS_BASE_EXC_SUBCLASS != 0 && base.New != nil {
+		new_type.New = base.New
+		new_type.Init = base.Init
+	}
+	// Go types whose instances carry their type can be subclassed
+	// using their constructors
+	if base.Flags&TPFLAGS_SUBCLASS_NEW != 0 {
+		new_type.New = base.New
+		new_type.Init = base.Init
+	}
+	// Metaclasses make classes the same way as their base
+	if base.IsSubtype(TypeType) {
+		new_type.New = base.New
+		new_type.Init = base.Init
+	}
 
 	// Keep name and slots alive in the extended type object
 	et := new_type
 	et.Name = string(name)
-	// FIXME et.Slots = slots
-	slots = nil
+	et.Slots = slots
+	et.NumSlots = base.NumSlots + len(slots)
+	et.NoDict = !add_dict && !baseHasDict(base)
+	et.NoWeakref = !add_weak && !baseHasWeakref(base)
 
 	// Initialize tp_flags
-	new_type.Flags = TPFLAGS_DEFAULT | TPFLAGS_HEAPTYPE | TPFLAGS_BASETYPE
+	new_type.Flags = TPFLAGS_DEFAULT | TPFLAGS_HEAPTYPE | TPFLAGS_BASETYPE | base.Flags&TPFLAGS_SUBCLASS_NEW
 
 	// Set tp_base and tp_bases
 	new_type.Bases = bases
@@ -1465,16 +1638,48 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 		}
 	}
 
+	// A class which overrides __eq__ without defining __hash__
+	// can't be hashed - its instances would break set and dict
+	if _, ok := dict["__eq__"]; ok {
+		if _, ok := dict["__hash__"]; !ok {
+			dict["__hash__"] = None
+		}
+	}
+
 	// Special-case __new__: if it's a plain function,
 	// make it a static function
-	// FIXME
-	// tmp = dict["__new__"]
-	// if tmp != nil && PyFunction_Check(tmp) {
-	// 	tmp = PyStaticMethod_New(tmp)
-	// 	if _PyDict_SetItemId(dict, &PyId___new__, tmp) < 0 {
-	// 		goto error
-	// 	}
-	// }
+	if fn, ok := dict["__new__"].(*Function); ok {
+		dict["__new__"] = &StaticMethod{Callable: fn, Dict: NewStringDict()}
+	}
+
+	// Special-case __init_subclass__: if it's a plain function,
+	// make it a classmethod
+	if fn, ok := dict["__init_subclass__"].(*Function); ok {
+		dict["__init_subclass__"] = &ClassMethod{Callable: fn, Dict: NewStringDict()}
+	}
+
+	// Special-case __class_getitem__: if it's a plain function,
+	// make it a classmethod
+	if fn, ok := dict["__class_getitem__"].(*Function); ok {
+		dict["__class_getitem__"] = &ClassMethod{Callable: fn, Dict: NewStringDict()}
+	}
+
+	// Add descriptors for custom slots from __slots__
+	for i, slot := range et.Slots {
+		slotName := string(slot.(String))
+		dict[slotName] = &MemberDescriptor{Name: slotName, Owner: new_type, Index: base.NumSlots + i}
+	}
+	if add_weak {
+		dict["__weakref__"] = &Property{
+			Fget: func(self Object) (Object, error) {
+				if FirstWeakref == nil {
+					return None, nil
+				}
+				return FirstWeakref(self), nil
+			},
+			Doc: "list of weak references to the object (if defined)",
+		}
+	}
 
 	/*
 		// Add descriptors for custom slots from __slots__, or for __dict__
@@ -1559,13 +1764,288 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	}
 
 	// Put the proper slots in place
-	// fixup_slot_dispatchers(new_type)
+	new_type.fixupSlotDispatchers()
+
+	// Tell the descriptors their names then the base classes about
+	// the new subclass
+	err = new_type.setNames()
+	if err != nil {
+		return nil, err
+	}
+	err = new_type.initSubclass(kwargs)
+	if err != nil {
+		return nil, err
+	}
 
 	return new_type, nil
 }
 
-func TypeInit(cls Object, args Tuple, kwargs StringDict) error {
+// Returns the first type in the MRO of t with name in its dictionary
+// or nil
+func (t *Type) lookupType(name string) *Type {
+	for _, base := range t.Mro {
+		if b, ok := base.(*Type); ok {
+			if _, ok := b.Dict[name]; ok {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+// Makes New and Init call __new__ and __init__ when they are defined
+// in python by t or one of its bases
+func (t *Type) fixupSlotDispatchers() {
+	if b := t.lookupType("__new__"); b != nil && b.Flags&TPFLAGS_HEAPTYPE != 0 {
+		t.New = slotNew
+	}
+	if b := t.lookupType("__init__"); b != nil && b.Flags&TPFLAGS_HEAPTYPE != 0 {
+		t.Init = slotInit
+	}
+}
+
+// New for types with a python __new__ which is called with the type
+// and the arguments
+func slotNew(t *Type, args Tuple, kwargs StringDict) (Object, error) {
+	fn, err := descriptorGet(t.Lookup("__new__"), None, t)
+	if err != nil {
+		return nil, err
+	}
+	newArgs := make(Tuple, len(args)+1)
+	newArgs[0] = t
+	copy(newArgs[1:], args)
+	return Call(fn, newArgs, kwargs)
+}
+
+// Init for types with a python __init__ which is called bound to the
+// new object
+func slotInit(self Object, args Tuple, kwargs StringDict) error {
+	t := self.Type()
+	fn, err := descriptorGet(t.Lookup("__init__"), self, t)
+	if err != nil {
+		return err
+	}
+	res, err := Call(fn, args, kwargs)
+	if err != nil {
+		return err
+	}
+	if res != None {
+		return ExceptionNewf(TypeError, "__init__() should return None, not '%s'", res.Type().Name)
+	}
+	return nil
+}
+
+// Makes the __new__ static method for the built in type t
+func newTpNewWrapper(t *Type) *StaticMethod {
+	name := t.Name + ".__new__"
+	fn := func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		if len(args) < 1 {
+			return nil, ExceptionNewf(TypeError, "%s(): not enough arguments", name)
+		}
+		subtype, ok := args[0].(*Type)
+		if !ok || !subtype.Type().IsSubtype(TypeType) {
+			return nil, ExceptionNewf(TypeError, "%s(X): X is not a type object (%s)", name, args[0].Type().Name)
+		}
+		if !subtype.IsSubtype(t) {
+			return nil, ExceptionNewf(TypeError, "%s(%s): %s is not a subtype of %s", name, subtype.Name, subtype.Name, t.Name)
+		}
+		return t.New(subtype, args[1:], kwargs)
+	}
+	return &StaticMethod{
+		Callable: MustNewMethod("__new__", fn, 0, "Create and return a new object.  See help(type) for accurate signature."),
+		Dict:     NewStringDict(),
+	}
+}
+
+// Makes the __init__ method for the built in type t
+func newTpInitWrapper(t *Type) *Method {
+	init := t.Init
+	fn := func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		err := init(self, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return None, nil
+	}
+	return MustNewMethod("__init__", fn, 0, "Initialize self.  See help(type(self)) for accurate signature.")
+}
+
+// Calls __set_name__ on the attributes of t which define it
+func (t *Type) setNames() error {
+	for _, name := range t.Dict.sortedKeys() {
+		value := t.Dict[name]
+		fn := value.Type().NativeGetAttrOrNil("__set_name__")
+		if fn == nil {
+			continue
+		}
+		_, err := Call(fn, Tuple{value, t, String(name)}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Calls __init_subclass__ of the bases of t with the class keyword
+// arguments
+func (t *Type) initSubclass(kwargs StringDict) error {
+	for _, base := range t.Mro[1:] {
+		b, ok := base.(*Type)
+		if !ok {
+			continue
+		}
+		if init, ok := b.Dict["__init_subclass__"]; ok {
+			fn, err := descriptorGet(init, None, t)
+			if err != nil {
+				return err
+			}
+			_, err = Call(fn, nil, kwargs)
+			return err
+		}
+	}
+	return nil
+}
+
+const objectInitSubclassDoc = `This method is called when a class is subclassed.
+
+The default implementation does nothing. It may be
+overridden to extend subclasses.`
+
+func objectInitSubclass(self Object, args Tuple, kwargs StringDict) (Object, error) {
 	if len(kwargs) != 0 {
+		return nil, ExceptionNewf(TypeError, "__init_subclass__() takes no keyword arguments")
+	}
+	if len(args) != 0 {
+		return nil, ExceptionNewf(TypeError, "__init_subclass__() takes no arguments (%d given)", len(args))
+	}
+	return None, nil
+}
+
+const objectSubclassHookDoc = `Abstract classes can override this to customize issubclass().
+
+This is invoked early on by abc.ABCMeta.__subclasscheck__().
+It should return True, False or NotImplemented.  If it returns
+NotImplemented, the normal algorithm is used.  Otherwise, it
+overrides the normal algorithm (and the outcome is cached).`
+
+func objectSubclassHook(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	return NotImplemented, nil
+}
+
+// object.__getattribute__, which python classes defining
+// __getattribute__ call to look up attributes the usual way
+func objectGetAttribute(self, name Object) (Object, error) {
+	key, err := AttributeName(name)
+	if err != nil {
+		return nil, err
+	}
+	return GenericGetAttrString(self, key)
+}
+
+// Returns an error if the attribute setter of object is used on a
+// class, which must use the setter of type so it is marked modified
+func objectCheckNotType(self Object, name string) error {
+	if cls, ok := self.(*Type); ok && cls.Type().IsSubtype(TypeType) {
+		return ExceptionNewf(TypeError, "can't apply this %s to type object", name)
+	}
+	return nil
+}
+
+// object.__setattr__, which python classes defining __setattr__ call
+// to set attributes the usual way
+func objectSetAttr(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	err := objectCheckNotType(self, "__setattr__")
+	if err != nil {
+		return nil, err
+	}
+	var name, value Object
+	err = UnpackTuple(args, kwargs, "__setattr__", 2, 2, &name, &value)
+	if err != nil {
+		return nil, err
+	}
+	key, err := AttributeName(name)
+	if err != nil {
+		return nil, err
+	}
+	return GenericSetAttrString(self, key, value)
+}
+
+// object.__delattr__, which python classes defining __delattr__ call
+// to delete attributes the usual way
+func objectDelAttr(self, name Object) (Object, error) {
+	err := objectCheckNotType(self, "__delattr__")
+	if err != nil {
+		return nil, err
+	}
+	key, err := AttributeName(name)
+	if err != nil {
+		return nil, err
+	}
+	err = GenericDeleteAttrString(self, key)
+	if err != nil {
+		return nil, err
+	}
+	return None, nil
+}
+
+// type.__setattr__, which metaclasses defining __setattr__ call to
+// set the attributes of their classes the usual way
+func typeSetAttr(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	if cls, ok := self.(*Type); !ok || !cls.Type().IsSubtype(TypeType) {
+		return nil, ExceptionNewf(TypeError, "descriptor '__setattr__' requires a 'type' object but received a '%s'", self.Type().Name)
+	}
+	var name, value Object
+	err := UnpackTuple(args, kwargs, "__setattr__", 2, 2, &name, &value)
+	if err != nil {
+		return nil, err
+	}
+	key, err := AttributeName(name)
+	if err != nil {
+		return nil, err
+	}
+	// GenericSetAttrString marks the class modified so the
+	// attribute caches see the change
+	return GenericSetAttrString(self, key, value)
+}
+
+// type.__delattr__, which metaclasses defining __delattr__ call to
+// delete the attributes of their classes the usual way
+func typeDelAttr(self, name Object) (Object, error) {
+	if cls, ok := self.(*Type); !ok || !cls.Type().IsSubtype(TypeType) {
+		return nil, ExceptionNewf(TypeError, "descriptor '__delattr__' requires a 'type' object but received a '%s'", self.Type().Name)
+	}
+	key, err := AttributeName(name)
+	if err != nil {
+		return nil, err
+	}
+	err = GenericDeleteAttrString(self, key)
+	if err != nil {
+		return nil, err
+	}
+	return None, nil
+}
+
+const typeCallDoc = `Call self as a function.`
+
+func typeCall(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	t, ok := self.(*Type)
+	if !ok || !t.Type().IsSubtype(TypeType) {
+		return nil, ExceptionNewf(TypeError, "descriptor '__call__' requires a 'type' object but received a '%s'", self.Type().Name)
+	}
+	return t.call(args, kwargs)
+}
+
+const typePrepareDoc = `__prepare__() -> dict
+used to create the namespace for the class statement`
+
+func typePrepare(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	return NewDict(), nil
+}
+
+func TypeInit(cls Object, args Tuple, kwargs StringDict) error {
+	// Keyword arguments are only allowed with the class statement
+	// form where they are passed to __init_subclass__
+	if len(args) == 1 && len(kwargs) != 0 {
 		return ExceptionNewf(TypeError, "type.__init__() takes no keyword arguments")
 	}
 
@@ -1636,23 +2116,30 @@ func ObjectInit(self Object, args Tuple, kwargs StringDict) error {
 		return ExceptionNewf(TypeError, "object.__init__() takes no parameters")
 	}
 
-	// Call the __init__ method if it exists
-	// FIXME this isn't the way cpython does it - it adjusts the function pointers
-	// Only do this for non built in types
-	if _, ok := self.(*Type); ok {
-		init := t.GetAttrOrNil("__init__")
-		// fmt.Printf("init = %v\n", init)
-		if init != nil {
-			newArgs := make(Tuple, len(args)+1)
-			newArgs[0] = self
-			copy(newArgs[1:], args)
-			_, err := Call(init, newArgs, kwargs)
-			if err != nil {
-				return err
+	// Any python __init__ method is called by slotInit
+	return nil
+}
+
+// Returns the error for instantiating the abstract class t
+func abstractError(t *Type) error {
+	var names []string
+	if abstracts, ok := t.Dict["__abstractmethods__"]; ok {
+		items, err := SequenceList(abstracts)
+		if err != nil {
+			return err
+		}
+		for _, item := range items.Items {
+			if name, ok := item.(String); ok {
+				names = append(names, string(name))
 			}
 		}
 	}
-	return nil
+	sort.Strings(names)
+	plural := "s"
+	if len(names) == 1 {
+		plural = ""
+	}
+	return ExceptionNewf(TypeError, "Can't instantiate abstract class %s with abstract method%s %s", t.Name, plural, strings.Join(names, ", "))
 }
 
 func ObjectNew(t *Type, args Tuple, kwargs StringDict) (Object, error) {
@@ -1667,85 +2154,488 @@ func ObjectNew(t *Type, args Tuple, kwargs StringDict) (Object, error) {
 		return nil, ExceptionNewf(TypeError, "object() takes no parameters")
 	}
 
-	// FIXME abstrac ty pes
-	// if (type->tp_flags & TPFLAGS_IS_ABSTRACT) {
-	// 	PyObject *abstract_methods = NULL;
-	// 	PyObject *builtins;
-	// 	PyObject *sorted;
-	// 	PyObject *sorted_methods = NULL;
-	// 	PyObject *joined = NULL;
-	// 	PyObject *comma;
-	// 	_Py_static_string(comma_id, ", ");
-	// 	_Py_IDENTIFIER(sorted);
-
-	// 	// Compute ", ".join(sorted(type.__abstractmethods__))
-	// 	// into joined.
-	// 	abstract_methods = type_abstractmethods(type, NULL);
-	// 	if (abstract_methods == NULL) {
-	// 		goto error;
-	// 	}
-	// 	builtins = PyEval_GetBuiltins();
-	// 	if (builtins == NULL) {
-	// 		goto error;
-	// 	}
-	// 	sorted = _PyDict_GetItemId(builtins, &PyId_sorted);
-	// 	if (sorted == NULL) {
-	// 		goto error;
-	// 	}
-	// 	sorted_methods = PyObject_CallFunctionObjArgs(sorted,
-	// 		abstract_methods,
-	// 		NULL);
-	// 	if (sorted_methods == NULL) {
-	// 		goto error;
-	// 	}
-	// 	comma = _PyUnicode_FromId(&comma_id);
-	// 	if (comma == NULL) {
-	// 		goto error;
-	// 	}
-	// 	joined = PyUnicode_Join(comma, sorted_methods);
-	// 	if (joined == NULL) {
-	// 		goto error;
-	// 	}
-
-	// 	PyErr_Format(PyExc_TypeError,
-	// 		"Can't instantiate abstract class %s "
-	// 		"with abstract methods %U",
-	// 		type->tp_name,
-	// 		joined);
-	// error:
-	// 	Py_XDECREF(joined);
-	// 	Py_XDECREF(sorted_methods);
-	// 	Py_XDECREF(abstract_methods);
-	// 	return NULL;
-	// }
+	if t.Flags&TPFLAGS_IS_ABSTRACT != 0 {
+		return nil, abstractError(t)
+	}
 	return t.Alloc(), nil
 }
 
-// FIXME this should be the default?
+// Returns the first non heap type found by following the bases of t
+//
+// This is the type which determines the layout of instances of t
+func solidBase(t *Type) *Type {
+	for t.Base != nil && t.Flags&TPFLAGS_HEAPTYPE != 0 {
+		t = t.Base
+	}
+	return t
+}
+
+// Returns the __slots__ declared by the heap types of t as a list of
+// names in the order their values are stored in instances
+func slotNames(t *Type) []string {
+	if t == nil || t.Flags&TPFLAGS_HEAPTYPE == 0 {
+		return nil
+	}
+	names := slotNames(t.Base)
+	for _, slot := range t.Slots {
+		names = append(names, string(slot.(String)))
+	}
+	return names
+}
+
+// Returns an error if instances of oldType can't have their
+// __class__ set to newType
+func compatibleForAssignment(oldType, newType *Type) error {
+	differs := ExceptionNewf(TypeError, "__class__ assignment: '%s' object layout differs from '%s'", newType.Name, oldType.Name)
+	if solidBase(oldType) != solidBase(newType) || oldType.NoDict != newType.NoDict || oldType.NoWeakref != newType.NoWeakref {
+		return differs
+	}
+	oldSlots := slotNames(oldType)
+	newSlots := slotNames(newType)
+	if len(oldSlots) != len(newSlots) {
+		return differs
+	}
+	for i := range oldSlots {
+		if oldSlots[i] != newSlots[i] {
+			return differs
+		}
+	}
+	return nil
+}
+
+// Returns true if instances of t have a __dict__
+func baseHasDict(t *Type) bool {
+	if t.Flags&TPFLAGS_HEAPTYPE != 0 {
+		return !t.NoDict
+	}
+	// Exceptions are the only go types with an instance dictionary
+	return t.Flags&TPFLAGS_BASE_EXC_SUBCLASS != 0
+}
+
+// Returns true if instances of t can be weakly referenced
+func baseHasWeakref(t *Type) bool {
+	if t.Flags&TPFLAGS_HEAPTYPE != 0 {
+		return !t.NoWeakref
+	}
+	return false
+}
+
+// Returns true if s is a valid python identifier
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c == '_' || unicode.IsLetter(c) {
+			continue
+		}
+		if i > 0 && unicode.IsDigit(c) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// Reads obj.__class__
+func objectGetClass(self Object) (Object, error) {
+	return self.Type(), nil
+}
+
+// Sets obj.__class__ changing the type of the instance
+func objectSetClass(self, value Object) error {
+	newType, ok := value.(*Type)
+	if !ok {
+		return ExceptionNewf(TypeError, "__class__ must be set to a class, not '%s' object", value.Type().Name)
+	}
+	oldType := self.Type()
+	// Modules can be given a subclass of module as their class to
+	// customise attribute access
+	if m, ok := self.(*Module); ok && newType.IsSubtype(ModuleType) {
+		m.Base = newType
+		return nil
+	}
+	// Instances of python classes are represented by a *Type
+	// whose ObjectType is the class
+	obj, ok := self.(*Type)
+	if !ok || oldType.Flags&TPFLAGS_HEAPTYPE == 0 || newType.Flags&TPFLAGS_HEAPTYPE == 0 {
+		return ExceptionNewf(TypeError, "__class__ assignment only supported for heap types or ModuleType subclasses")
+	}
+	err := compatibleForAssignment(oldType, newType)
+	if err != nil {
+		return err
+	}
+	obj.ObjectType = newType
+	obj.Base = newType
+	return nil
+}
+
+// Reads obj.__dict__ for objects with an instance dictionary
+func objectGetDict(self Object) (Object, error) {
+	if I, ok := self.(IGetDict); ok {
+		if dict := I.GetDict(); dict != nil {
+			return dict, nil
+		}
+	}
+	return nil, ExceptionNewf(AttributeError, "'%s' object has no attribute '__dict__'", self.Type().Name)
+}
+
+// Replaces the instance dictionary of an instance of a python class
+func objectSetDict(self, value Object) error {
+	dict, err := AsStringDict(value)
+	if err != nil {
+		return ExceptionNewf(TypeError, "__dict__ must be set to a dictionary, not a '%s'", value.Type().Name)
+	}
+	obj, ok := self.(*Type)
+	if !ok || self.Type().Flags&TPFLAGS_HEAPTYPE == 0 || self.Type().NoDict {
+		return ExceptionNewf(AttributeError, "attribute '__dict__' of '%s' objects is not writable", self.Type().Name)
+	}
+	obj.Dict = dict
+	return nil
+}
+
+// Reads cls.__dict__ which is a read only proxy of the class
+// namespace
+func typeGetDict(self Object) (Object, error) {
+	return NewMappingProxy(self.(*Type).Dict), nil
+}
+
+// Reads cls.__name__
+func typeGetName(self Object) (Object, error) {
+	return String(self.(*Type).Name), nil
+}
+
+// Sets cls.__name__ which must be a string
+func typeSetName(self, value Object) error {
+	name, ok := value.(String)
+	if !ok {
+		return ExceptionNewf(TypeError, "can only assign string to %s.__name__, not '%s'", self.(*Type).Name, value.Type().Name)
+	}
+	self.(*Type).Name = string(name)
+	return nil
+}
+
+// Reads cls.__qualname__ which defaults to the name
+func typeGetQualname(self Object) (Object, error) {
+	t := self.(*Type)
+	if t.Qualname == "" {
+		return String(t.Name), nil
+	}
+	return String(t.Qualname), nil
+}
+
+// Sets cls.__qualname__ which must be a string
+func typeSetQualname(self, value Object) error {
+	qualname, ok := value.(String)
+	if !ok {
+		return ExceptionNewf(TypeError, "can only assign string to %s.__qualname__, not '%s'", self.(*Type).Name, value.Type().Name)
+	}
+	self.(*Type).Qualname = string(qualname)
+	return nil
+}
+
+// Reads cls.__module__ from the class namespace - built in types are
+// in builtins unless they say otherwise
+func typeGetModule(self Object) (Object, error) {
+	t := self.(*Type)
+	if module, ok := t.Dict["__module__"]; ok {
+		return module, nil
+	}
+	if t.Flags&TPFLAGS_HEAPTYPE != 0 {
+		return nil, ExceptionNewf(AttributeError, "__module__")
+	}
+	return String("builtins"), nil
+}
+
+// Calls the unary special method name of ty returning NotImplemented
+// if it isn't defined
+func (ty *Type) callUnary(name string) (Object, error) {
+	res, ok, err := TypeCall0(ty, name)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return res, err
+}
+
+func (ty *Type) M__neg__() (Object, error) {
+	return ty.callUnary("__neg__")
+}
+
+func (ty *Type) M__pos__() (Object, error) {
+	return ty.callUnary("__pos__")
+}
+
+func (ty *Type) M__abs__() (Object, error) {
+	return ty.callUnary("__abs__")
+}
+
+func (ty *Type) M__invert__() (Object, error) {
+	return ty.callUnary("__invert__")
+}
+
+func (ty *Type) M__add__(other Object) (Object, error) {
+	return ty.callSpecial1("__add__", other)
+}
+
+func (ty *Type) M__radd__(other Object) (Object, error) {
+	return ty.callSpecial1("__radd__", other)
+}
+
+func (ty *Type) M__iadd__(other Object) (Object, error) {
+	return ty.callSpecial1("__iadd__", other)
+}
+
+func (ty *Type) M__sub__(other Object) (Object, error) {
+	return ty.callSpecial1("__sub__", other)
+}
+
+func (ty *Type) M__rsub__(other Object) (Object, error) {
+	return ty.callSpecial1("__rsub__", other)
+}
+
+func (ty *Type) M__isub__(other Object) (Object, error) {
+	return ty.callSpecial1("__isub__", other)
+}
+
+func (ty *Type) M__mul__(other Object) (Object, error) {
+	return ty.callSpecial1("__mul__", other)
+}
+
+func (ty *Type) M__rmul__(other Object) (Object, error) {
+	return ty.callSpecial1("__rmul__", other)
+}
+
+func (ty *Type) M__imul__(other Object) (Object, error) {
+	return ty.callSpecial1("__imul__", other)
+}
+
+func (ty *Type) M__matmul__(other Object) (Object, error) {
+	return ty.callSpecial1("__matmul__", other)
+}
+
+func (ty *Type) M__rmatmul__(other Object) (Object, error) {
+	return ty.callSpecial1("__rmatmul__", other)
+}
+
+func (ty *Type) M__imatmul__(other Object) (Object, error) {
+	return ty.callSpecial1("__imatmul__", other)
+}
+
+func (ty *Type) M__truediv__(other Object) (Object, error) {
+	return ty.callSpecial1("__truediv__", other)
+}
+
+func (ty *Type) M__rtruediv__(other Object) (Object, error) {
+	return ty.callSpecial1("__rtruediv__", other)
+}
+
+func (ty *Type) M__itruediv__(other Object) (Object, error) {
+	return ty.callSpecial1("__itruediv__", other)
+}
+
+func (ty *Type) M__floordiv__(other Object) (Object, error) {
+	return ty.callSpecial1("__floordiv__", other)
+}
+
+func (ty *Type) M__rfloordiv__(other Object) (Object, error) {
+	return ty.callSpecial1("__rfloordiv__", other)
+}
+
+func (ty *Type) M__ifloordiv__(other Object) (Object, error) {
+	return ty.callSpecial1("__ifloordiv__", other)
+}
+
+func (ty *Type) M__mod__(other Object) (Object, error) {
+	return ty.callSpecial1("__mod__", other)
+}
+
+func (ty *Type) M__rmod__(other Object) (Object, error) {
+	return ty.callSpecial1("__rmod__", other)
+}
+
+func (ty *Type) M__imod__(other Object) (Object, error) {
+	return ty.callSpecial1("__imod__", other)
+}
+
+func (ty *Type) M__lshift__(other Object) (Object, error) {
+	return ty.callSpecial1("__lshift__", other)
+}
+
+func (ty *Type) M__rlshift__(other Object) (Object, error) {
+	return ty.callSpecial1("__rlshift__", other)
+}
+
+func (ty *Type) M__ilshift__(other Object) (Object, error) {
+	return ty.callSpecial1("__ilshift__", other)
+}
+
+func (ty *Type) M__rshift__(other Object) (Object, error) {
+	return ty.callSpecial1("__rshift__", other)
+}
+
+func (ty *Type) M__rrshift__(other Object) (Object, error) {
+	return ty.callSpecial1("__rrshift__", other)
+}
+
+func (ty *Type) M__irshift__(other Object) (Object, error) {
+	return ty.callSpecial1("__irshift__", other)
+}
+
+func (ty *Type) M__and__(other Object) (Object, error) {
+	return ty.callSpecial1("__and__", other)
+}
+
+func (ty *Type) M__rand__(other Object) (Object, error) {
+	return ty.callSpecial1("__rand__", other)
+}
+
+func (ty *Type) M__iand__(other Object) (Object, error) {
+	return ty.callSpecial1("__iand__", other)
+}
+
+func (ty *Type) M__xor__(other Object) (Object, error) {
+	return ty.callSpecial1("__xor__", other)
+}
+
+func (ty *Type) M__rxor__(other Object) (Object, error) {
+	return ty.callSpecial1("__rxor__", other)
+}
+
+func (ty *Type) M__ixor__(other Object) (Object, error) {
+	return ty.callSpecial1("__ixor__", other)
+}
+
+func (ty *Type) M__or__(other Object) (Object, error) {
+	return ty.callSpecial1("__or__", other)
+}
+
+func (ty *Type) M__ror__(other Object) (Object, error) {
+	return ty.callSpecial1("__ror__", other)
+}
+
+func (ty *Type) M__ior__(other Object) (Object, error) {
+	return ty.callSpecial1("__ior__", other)
+}
+
+// Splits the result of calling __divmod__ or __rdivmod__ into the
+// quotient and remainder
+func (ty *Type) callDivMod(name string, other Object) (Object, Object, error) {
+	res, err := ty.callSpecial1(name, other)
+	if err != nil || res == NotImplemented {
+		return res, nil, err
+	}
+	t, ok := res.(Tuple)
+	if !ok || len(t) != 2 {
+		return nil, nil, ExceptionNewf(TypeError, "%s should return a tuple of length 2, not '%s'", name, res.Type().Name)
+	}
+	return t[0], t[1], nil
+}
+
+func (ty *Type) M__divmod__(other Object) (Object, Object, error) {
+	return ty.callDivMod("__divmod__", other)
+}
+
+func (ty *Type) M__rdivmod__(other Object) (Object, Object, error) {
+	return ty.callDivMod("__rdivmod__", other)
+}
+
+// Calls __pow__ passing modulo only if it was supplied
+func (ty *Type) M__pow__(other, modulo Object) (Object, error) {
+	if modulo == None {
+		return ty.callSpecial1("__pow__", other)
+	}
+	res, ok, err := TypeCall2(ty, "__pow__", other, modulo)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return res, err
+}
+
+func (ty *Type) M__rpow__(other Object) (Object, error) {
+	return ty.callSpecial1("__rpow__", other)
+}
+
+func (ty *Type) M__ipow__(other, modulo Object) (Object, error) {
+	return ty.callSpecial1("__ipow__", other)
+}
+
+// Calls __eq__ if defined otherwise objects are only equal to themselves
 func (ty *Type) M__eq__(other Object) (Object, error) {
+	if res, ok, err := TypeCall1(ty, "__eq__", other); ok {
+		return res, err
+	}
 	if otherTy, ok := other.(*Type); ok && ty == otherTy {
 		return True, nil
 	}
-	return False, nil
+	return NotImplemented, nil
 }
 
-// FIXME this should be the default?
+// Calls __ne__ if defined otherwise inverts the result of __eq__
 func (ty *Type) M__ne__(other Object) (Object, error) {
-	if otherTy, ok := other.(*Type); ok && ty == otherTy {
-		return False, nil
+	if res, ok, err := TypeCall1(ty, "__ne__", other); ok {
+		return res, err
+	}
+	res, err := ty.M__eq__(other)
+	if err != nil || res == NotImplemented {
+		return res, err
+	}
+	return Not(res)
+}
+
+// Looks up the special method name on the type of ty
+//
+// Special methods are looked up on the type only, so the special
+// methods of a class are those of its metaclass.  Only methods defined
+// in python are returned - the special methods of the built in types
+// are implemented by go interfaces.
+func (ty *Type) lookupSpecial(name string) Object {
+	b := ty.Type().lookupType(name)
+	if b == nil || b.Flags&TPFLAGS_HEAPTYPE == 0 {
+		return nil
 	}
-	return True, nil
+	return b.Dict[name]
+}
+
+// Calls the special method name of ty
+func (ty *Type) callSpecial(name string) (Object, bool, error) {
+	return TypeCall0(ty, name)
+}
+
+// Calls the binary special method name of ty returning NotImplemented
+// if it isn't defined
+func (ty *Type) callSpecial1(name string, other Object) (Object, error) {
+	res, ok, err := TypeCall1(ty, name, other)
+	if !ok {
+		return NotImplemented, nil
+	}
+	return res, err
+}
+
+func (ty *Type) M__lt__(other Object) (Object, error) {
+	return ty.callSpecial1("__lt__", other)
+}
+
+func (ty *Type) M__le__(other Object) (Object, error) {
+	return ty.callSpecial1("__le__", other)
+}
+
+func (ty *Type) M__gt__(other Object) (Object, error) {
+	return ty.callSpecial1("__gt__", other)
+}
+
+func (ty *Type) M__ge__(other Object) (Object, error) {
+	return ty.callSpecial1("__ge__", other)
 }
 
 func (ty *Type) M__str__() (Object, error) {
-	if res, ok, err := ty.CallMethod("__str__", Tuple{ty}, nil); ok {
+	if res, ok, err := ty.callSpecial("__str__"); ok {
 		return res, err
 	}
 	return ty.M__repr__()
 }
 
 func (ty *Type) M__repr__() (Object, error) {
-	if res, ok, err := ty.CallMethod("__repr__", Tuple{ty}, nil); ok {
+	if res, ok, err := ty.callSpecial("__repr__"); ok {
 		return res, err
 	}
 	if ty.Name == "" {
@@ -1762,3 +2652,9 @@ var _ I__call__ = (*Type)(nil)
 var _ IGetDict = (*Type)(nil)
 var _ I__repr__ = (*Type)(nil)
 var _ I__str__ = (*Type)(nil)
+var _ richComparison = (*Type)(nil)
+var _ floatArithmetic = (*Type)(nil)
+var _ booleanArithmetic = (*Type)(nil)
+var _ I__matmul__ = (*Type)(nil)
+var _ I__rmatmul__ = (*Type)(nil)
+var _ I__imatmul__ = (*Type)(nil)
@@ -0,0 +1,114 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Import modules from zip archives
+//
+// A sys.path entry which is a zip archive, or a directory in one such
+// as "lib.zip/pkg", imports the modules in the archive.
+
+package py
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// A zip archive
+type zipFS struct {
+	files map[string]*zip.File
+	dirs  map[string]bool
+}
+
+// The zip archives opened so far indexed by path
+var zipArchives = map[string]*zipFS{}
+
+// Opens the zip archive at path
+func openZip(path string) (*zipFS, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	z := &zipFS{
+		files: make(map[string]*zip.File),
+		dirs:  make(map[string]bool),
+	}
+	for _, f := range r.File {
+		name := strings.TrimSuffix(f.Name, "/")
+		if strings.HasSuffix(f.Name, "/") {
+			z.dirs[name] = true
+		} else {
+			z.files[name] = f
+		}
+		// Archives needn't have entries for the directories
+		for dir := name; strings.Contains(dir, "/"); {
+			dir = dir[:strings.LastIndexByte(dir, '/')]
+			z.dirs[dir] = true
+		}
+	}
+	return z, nil
+}
+
+func (z *zipFS) IsDir(name string) bool {
+	return z.dirs[name]
+}
+
+func (z *zipFS) IsFile(name string) bool {
+	_, ok := z.files[name]
+	return ok
+}
+
+func (z *zipFS) ReadFile(name string) ([]byte, error) {
+	f, ok := z.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// The path hook for zip archives and the directories in them
+func zipPathHook(entry string) ModuleFS {
+	if entry == "" {
+		return nil
+	}
+	// Find the file the entry starts with
+	archive, dir := filepath.Clean(entry), ""
+	for {
+		fi, err := os.Stat(archive)
+		if err == nil {
+			if !fi.Mode().IsRegular() {
+				return nil
+			}
+			break
+		}
+		parent := filepath.Dir(archive)
+		if parent == archive {
+			return nil
+		}
+		dir = path.Join(filepath.Base(archive), dir)
+		archive = parent
+	}
+	z, ok := zipArchives[archive]
+	if !ok {
+		var err error
+		z, err = openZip(archive)
+		if err != nil {
+			return nil
+		}
+		zipArchives[archive] = z
+	}
+	return SubModuleFS(z, dir)
+}
+
+func init() {
+	RegisterPathHook(zipPathHook)
+}
@@ -0,0 +1,763 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The node classes and converting between them and the Go ast
+//
+// Each node of the Go ast has a python class here whose instances keep
+// the fields in their dictionaries as CPython's ast nodes do, so they
+// can be changed freely from python.  The Go ast is converted to these
+// nodes after parsing and back again before compiling.
+
+package pyast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-python/gpython/ast"
+	"github.com/go-python/gpython/py"
+)
+
+// ASTClass is the base class of all the nodes
+var ASTClass = py.NewClass(py.TypeType, "ast", "AST", "Base class of the ast nodes", py.Tuple{py.ObjectType}, py.StringDict{
+	"_fields":     py.Tuple{},
+	"_attributes": py.Tuple{},
+	"__init__":    py.NewGoMethod("__init__", astInit),
+})
+
+// The attributes of the nodes which have positions
+var posAttributes = py.Tuple{py.String("lineno"), py.String("col_offset"), py.String("end_lineno"), py.String("end_col_offset")}
+
+// Sets the attributes of a node class in dict, the end of the
+// position being None if it isn't known
+func setAttributes(dict py.StringDict, attributes py.Tuple) {
+	dict["_attributes"] = attributes
+	if len(attributes) != 0 {
+		dict["end_lineno"] = py.None
+		dict["end_col_offset"] = py.None
+	}
+}
+
+// Makes an abstract base class of the nodes
+func newBaseClass(name string, attributes py.Tuple) *py.Type {
+	dict := py.StringDict{}
+	setAttributes(dict, attributes)
+	return py.NewClass(py.TypeType, "ast", name, name+" node", py.Tuple{ASTClass}, dict)
+}
+
+// The abstract base classes of the nodes
+var (
+	modClass           = newBaseClass("mod", py.Tuple{})
+	stmtClass          = newBaseClass("stmt", posAttributes)
+	exprClass          = newBaseClass("expr", posAttributes)
+	sliceClass         = newBaseClass("slice", py.Tuple{})
+	patternClass       = newBaseClass("pattern", posAttributes)
+	excepthandlerClass = newBaseClass("excepthandler", posAttributes)
+)
+
+// nodeField is a field of a Go ast node
+type nodeField struct {
+	name     string // python name
+	index    int    // index of the Go struct field
+	optional bool   // whether the field may be None
+}
+
+// nodeClass joins a python node class to the Go ast node it is made
+// from
+type nodeClass struct {
+	cls       *py.Type
+	goType    reflect.Type // type of the struct of the node
+	fields    []nodeField
+	hasPos    bool // whether the node has lineno and col_offset
+	isPointer bool // whether the node is used as a pointer to the struct
+}
+
+var (
+	classByGoType = map[reflect.Type]*nodeClass{}
+	classByType   = map[*py.Type]*nodeClass{}
+)
+
+// The python names of the Go nodes where they differ
+var nodeNames = map[string]string{
+	"ExprStmt":      "Expr",
+	"Arguments":     "arguments",
+	"Arg":           "arg",
+	"Keyword":       "keyword",
+	"Alias":         "alias",
+	"WithItem":      "withitem",
+	"MatchCase":     "match_case",
+	"Comprehension": "comprehension",
+}
+
+// The fields which may be None, or the lists which may contain None
+var optionalFields = map[string]bool{
+	"FunctionDef.returns":        true,
+	"AsyncFunctionDef.returns":   true,
+	"ClassDef.starargs":          true,
+	"ClassDef.kwargs":            true,
+	"Return.value":               true,
+	"AnnAssign.value":            true,
+	"Raise.exc":                  true,
+	"Raise.cause":                true,
+	"Assert.msg":                 true,
+	"ImportFrom.module":          true,
+	"Dict.keys":                  true,
+	"Yield.value":                true,
+	"Call.starargs":              true,
+	"Call.kwargs":                true,
+	"FormattedValue.format_spec": true,
+	"Slice.lower":                true,
+	"Slice.upper":                true,
+	"Slice.step":                 true,
+	"MatchMapping.rest":          true,
+	"MatchStar.name":             true,
+	"MatchAs.pattern":            true,
+	"MatchAs.name":               true,
+	"ExceptHandler.type":         true,
+	"ExceptHandler.name":         true,
+	"arguments.vararg":           true,
+	"arguments.kw_defaults":      true,
+	"arguments.kwarg":            true,
+	"arg.annotation":             true,
+	"keyword.arg":                true,
+	"alias.asname":               true,
+	"withitem.optional_vars":     true,
+	"match_case.guard":           true,
+}
+
+// The nodes of the Go ast
+var nodes = []interface{}{
+	// Mod
+	(*ast.Module)(nil),
+	(*ast.Interactive)(nil),
+	(*ast.Expression)(nil),
+	(*ast.Suite)(nil),
+	// Stmt
+	(*ast.FunctionDef)(nil),
+	(*ast.AsyncFunctionDef)(nil),
+	(*ast.ClassDef)(nil),
+	(*ast.Return)(nil),
+	(*ast.Delete)(nil),
+	(*ast.Assign)(nil),
+	(*ast.AugAssign)(nil),
+	(*ast.AnnAssign)(nil),
+	(*ast.For)(nil),
+	(*ast.AsyncFor)(nil),
+	(*ast.While)(nil),
+	(*ast.If)(nil),
+	(*ast.With)(nil),
+	(*ast.AsyncWith)(nil),
+	(*ast.Raise)(nil),
+	(*ast.Try)(nil),
+	(*ast.Assert)(nil),
+	(*ast.Import)(nil),
+	(*ast.ImportFrom)(nil),
+	(*ast.Global)(nil),
+	(*ast.Nonlocal)(nil),
+	(*ast.ExprStmt)(nil),
+	(*ast.Pass)(nil),
+	(*ast.Break)(nil),
+	(*ast.Continue)(nil),
+	(*ast.Match)(nil),
+	// Expr
+	(*ast.BoolOp)(nil),
+	(*ast.NamedExpr)(nil),
+	(*ast.BinOp)(nil),
+	(*ast.UnaryOp)(nil),
+	(*ast.Lambda)(nil),
+	(*ast.IfExp)(nil),
+	(*ast.Dict)(nil),
+	(*ast.Set)(nil),
+	(*ast.ListComp)(nil),
+	(*ast.SetComp)(nil),
+	(*ast.DictComp)(nil),
+	(*ast.GeneratorExp)(nil),
+	(*ast.Await)(nil),
+	(*ast.Yield)(nil),
+	(*ast.YieldFrom)(nil),
+	(*ast.Compare)(nil),
+	(*ast.Call)(nil),
+	(*ast.Num)(nil),
+	(*ast.Str)(nil),
+	(*ast.FormattedValue)(nil),
+	(*ast.JoinedStr)(nil),
+	(*ast.Bytes)(nil),
+	(*ast.NameConstant)(nil),
+	(*ast.Ellipsis)(nil),
+	(*ast.Attribute)(nil),
+	(*ast.Subscript)(nil),
+	(*ast.Starred)(nil),
+	(*ast.Name)(nil),
+	(*ast.List)(nil),
+	(*ast.Tuple)(nil),
+	// Slice
+	(*ast.Slice)(nil),
+	(*ast.ExtSlice)(nil),
+	(*ast.Index)(nil),
+	// Pattern
+	(*ast.MatchValue)(nil),
+	(*ast.MatchSingleton)(nil),
+	(*ast.MatchSequence)(nil),
+	(*ast.MatchMapping)(nil),
+	(*ast.MatchClass)(nil),
+	(*ast.MatchStar)(nil),
+	(*ast.MatchAs)(nil),
+	(*ast.MatchOr)(nil),
+	// Misc
+	(*ast.ExceptHandler)(nil),
+	(*ast.Arguments)(nil),
+	(*ast.Arg)(nil),
+	(*ast.Keyword)(nil),
+	(*ast.Alias)(nil),
+	(*ast.WithItem)(nil),
+	(*ast.MatchCase)(nil),
+	ast.Comprehension{},
+}
+
+// Types of the Go ast used in converting
+var (
+	modType        = reflect.TypeOf((*ast.Mod)(nil)).Elem()
+	stmtType       = reflect.TypeOf((*ast.Stmt)(nil)).Elem()
+	exprType       = reflect.TypeOf((*ast.Expr)(nil)).Elem()
+	slicerType     = reflect.TypeOf((*ast.Slicer)(nil)).Elem()
+	patternType    = reflect.TypeOf((*ast.Pattern)(nil)).Elem()
+	identifierType = reflect.TypeOf(ast.Identifier(""))
+	objectType     = reflect.TypeOf((*ast.Object)(nil)).Elem()
+	singletonType  = reflect.TypeOf((*ast.Singleton)(nil)).Elem()
+	stringType     = reflect.TypeOf(py.String(""))
+	bytesType      = reflect.TypeOf(py.Bytes(nil))
+	exprContext    = reflect.TypeOf(ast.ExprContext(0))
+)
+
+// The python names of the kinds of node the Go interfaces hold
+var interfaceNames = map[reflect.Type]string{
+	modType:     "mod",
+	stmtType:    "stmt",
+	exprType:    "expr",
+	slicerType:  "slice",
+	patternType: "pattern",
+}
+
+// Makes the class of the Go node
+func newNodeClass(node interface{}) *nodeClass {
+	t := reflect.TypeOf(node)
+	nc := &nodeClass{goType: t}
+	if t.Kind() == reflect.Ptr {
+		nc.isPointer = true
+		nc.goType = t.Elem()
+	}
+	name := nc.goType.Name()
+	if pyName, ok := nodeNames[name]; ok {
+		name = pyName
+	}
+	var base *py.Type
+	switch {
+	case t.Implements(modType):
+		base = modClass
+	case t.Implements(stmtType):
+		base = stmtClass
+	case t.Implements(exprType):
+		base = exprClass
+	case t.Implements(slicerType):
+		base = sliceClass
+	case t.Implements(patternType):
+		base = patternClass
+	case name == "ExceptHandler":
+		base = excepthandlerClass
+	default:
+		base = ASTClass
+	}
+	var fieldNames py.Tuple
+	var goNames []string
+	for i := 0; i < nc.goType.NumField(); i++ {
+		field := nc.goType.Field(i)
+		if field.Anonymous {
+			nc.hasPos = true
+			continue
+		}
+		fieldName := ast.FieldName(field.Name)
+		nc.fields = append(nc.fields, nodeField{
+			name:     fieldName,
+			index:    i,
+			optional: optionalFields[name+"."+fieldName],
+		})
+		fieldNames = append(fieldNames, py.String(fieldName))
+		goNames = append(goNames, fieldName)
+	}
+	dict := py.StringDict{
+		"_fields": fieldNames,
+	}
+	switch name {
+	case "arg", "keyword", "alias":
+		setAttributes(dict, posAttributes)
+	}
+	nc.cls = py.NewClass(py.TypeType, "ast", name, fmt.Sprintf("%s(%s)", name, strings.Join(goNames, ", ")), py.Tuple{base}, dict)
+	// Only the nodes with lineno and col_offset attributes keep them
+	attributes, err := py.GetAttrString(nc.cls, "_attributes")
+	if err != nil {
+		panic(err)
+	}
+	nc.hasPos = nc.hasPos && len(attributes.(py.Tuple)) != 0
+	return nc
+}
+
+// enumClass is one of the classes of the enums of the Go ast, such as
+// the operators, with the value it stands for
+type enumClass struct {
+	cls      *py.Type
+	value    reflect.Value
+	instance py.Object // shared instance used when converting
+}
+
+var (
+	enumByValue = map[interface{}]*enumClass{}
+	enumByType  = map[*py.Type]*enumClass{}
+)
+
+// The enums of the Go ast with the name of their base classes
+var enums = []struct {
+	base   string
+	values []fmt.Stringer
+}{
+	{"expr_context", []fmt.Stringer{ast.Load, ast.Store, ast.Del, ast.AugLoad, ast.AugStore, ast.Param}},
+	{"boolop", []fmt.Stringer{ast.And, ast.Or}},
+	{"operator", []fmt.Stringer{ast.Add, ast.Sub, ast.Mult, ast.MatMult, ast.Div, ast.Modulo, ast.Pow, ast.LShift, ast.RShift, ast.BitOr, ast.BitXor, ast.BitAnd, ast.FloorDiv}},
+	{"unaryop", []fmt.Stringer{ast.Invert, ast.Not, ast.UAdd, ast.USub}},
+	{"cmpop", []fmt.Stringer{ast.Eq, ast.NotEq, ast.Lt, ast.LtE, ast.Gt, ast.GtE, ast.Is, ast.IsNot, ast.In, ast.NotIn}},
+}
+
+// The classes of the module in the order they are made
+var classes []*py.Type
+
+func init() {
+	classes = append(classes, ASTClass, modClass, stmtClass, exprClass, sliceClass, patternClass, excepthandlerClass)
+	for _, node := range nodes {
+		nc := newNodeClass(node)
+		classByGoType[nc.goType] = nc
+		classByType[nc.cls] = nc
+		classes = append(classes, nc.cls)
+	}
+	for _, enum := range enums {
+		base := newBaseClass(enum.base, py.Tuple{})
+		classes = append(classes, base)
+		for _, value := range enum.values {
+			name := strings.TrimSuffix(value.String(), "()")
+			cls := py.NewClass(py.TypeType, "ast", name, name, py.Tuple{base}, py.StringDict{})
+			instance, err := py.Call(cls, nil, nil)
+			if err != nil {
+				panic(err)
+			}
+			ec := &enumClass{cls: cls, value: reflect.ValueOf(value), instance: instance}
+			enumByValue[value] = ec
+			enumByType[cls] = ec
+			classes = append(classes, cls)
+		}
+	}
+}
+
+// Initialises a node setting the fields from the arguments
+func astInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	fields, err := fieldNames(self)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > len(fields) {
+		plural := "s"
+		if len(fields) == 1 {
+			plural = ""
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "%s constructor takes at most %d positional argument%s", self.Type().Name, len(fields), plural)
+	}
+	for i, arg := range args {
+		_, err = py.SetAttrString(self, fields[i], arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range kwargs {
+		_, err = py.SetAttrString(self, name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Returns the names in the tuple attribute name of a node such as
+// _fields
+func namesAttr(node py.Object, name string) ([]string, error) {
+	obj, err := py.GetAttrString(node, name)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	err = py.Iterate(obj, func(item py.Object) bool {
+		s, ok := item.(py.String)
+		if !ok {
+			err = py.ExceptionNewf(py.TypeError, "%s must contain only strings", name)
+			return true
+		}
+		names = append(names, string(s))
+		return false
+	})
+	return names, err
+}
+
+// Returns the names of the fields of a node
+func fieldNames(node py.Object) ([]string, error) {
+	return namesAttr(node, "_fields")
+}
+
+// IsNode returns whether obj is an instance of one of the node classes
+func IsNode(obj py.Object) bool {
+	return obj.Type().IsSubtype(ASTClass)
+}
+
+// NodeToObject converts a node of the Go ast into the nodes of the
+// python ast module
+func NodeToObject(node ast.Ast) (py.Object, error) {
+	if node == nil {
+		return py.None, nil
+	}
+	return valueToObject(reflect.ValueOf(node))
+}
+
+// Converts a field of a Go node into a python object
+func valueToObject(v reflect.Value) (py.Object, error) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return py.None, nil
+		}
+	}
+	x := v.Interface()
+	switch x := x.(type) {
+	case ast.Ast, ast.Comprehension:
+		return nodeValueToObject(reflect.Indirect(reflect.ValueOf(x)))
+	case ast.Identifier:
+		if x == "" {
+			return py.None, nil
+		}
+		return py.String(x), nil
+	case py.Object:
+		return x, nil
+	case int:
+		return py.Int(x), nil
+	}
+	switch v.Kind() {
+	case reflect.Int:
+		if ec, ok := enumByValue[x]; ok {
+			return ec.instance, nil
+		}
+		if v.Int() == 0 {
+			// An enum which hasn't been set
+			return py.None, nil
+		}
+	case reflect.Slice:
+		items := make([]py.Object, v.Len())
+		for i := range items {
+			item, err := valueToObject(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return py.NewListFromItems(items), nil
+	}
+	return nil, py.ExceptionNewf(py.SystemError, "can't convert %T to a python object", x)
+}
+
+// Converts the struct of a Go node into a python node
+func nodeValueToObject(v reflect.Value) (py.Object, error) {
+	nc, ok := classByGoType[v.Type()]
+	if !ok {
+		return nil, py.ExceptionNewf(py.SystemError, "no ast class for %s", v.Type())
+	}
+	obj, err := py.Call(nc.cls, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range nc.fields {
+		value, err := valueToObject(v.Field(field.index))
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(obj, field.name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if nc.hasPos {
+		_, err = py.SetAttrString(obj, "lineno", py.Int(v.FieldByName("Lineno").Int()))
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(obj, "col_offset", py.Int(v.FieldByName("ColOffset").Int()))
+		if err != nil {
+			return nil, err
+		}
+		// The end isn't known for nodes made outside the parser
+		if v.FieldByName("EndLineno").Int() != 0 {
+			_, err = py.SetAttrString(obj, "end_lineno", py.Int(v.FieldByName("EndLineno").Int()))
+			if err != nil {
+				return nil, err
+			}
+			_, err = py.SetAttrString(obj, "end_col_offset", py.Int(v.FieldByName("EndColOffset").Int()))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return obj, nil
+}
+
+// Finds the node class obj is an instance of, looking through the
+// base classes so subclasses of the node classes can be converted
+func findNodeClass(obj py.Object) *nodeClass {
+	for _, cls := range obj.Type().Mro {
+		if nc, ok := classByType[cls.(*py.Type)]; ok {
+			return nc
+		}
+	}
+	return nil
+}
+
+// Finds the enum class obj is an instance of
+func findEnumClass(obj py.Object) *enumClass {
+	for _, cls := range obj.Type().Mro {
+		if ec, ok := enumByType[cls.(*py.Type)]; ok {
+			return ec
+		}
+	}
+	return nil
+}
+
+// ObjectToNode converts a node of the python ast module into a node
+// of the Go ast.  It raises TypeError if a required field is missing
+// or has the wrong type.
+func ObjectToNode(obj py.Object) (ast.Ast, error) {
+	v, err := objectToNodeValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	node, ok := v.Interface().(ast.Ast)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "expected AST, got %s", obj.Type().Name)
+	}
+	return node, nil
+}
+
+// ObjectToMod converts a python Module, Expression or Interactive node
+// as needed by compile for mode into a node of the Go ast
+func ObjectToMod(obj py.Object, mode string) (ast.Mod, error) {
+	var want *py.Type
+	switch mode {
+	case "exec":
+		want = classByGoType[reflect.TypeOf(ast.Module{})].cls
+	case "eval":
+		want = classByGoType[reflect.TypeOf(ast.Expression{})].cls
+	case "single":
+		want = classByGoType[reflect.TypeOf(ast.Interactive{})].cls
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "compile() mode must be 'exec', 'eval' or 'single'")
+	}
+	if !obj.Type().IsSubtype(want) {
+		return nil, py.ExceptionNewf(py.TypeError, "expected %s node, got %s", want.Name, obj.Type().Name)
+	}
+	node, err := ObjectToNode(obj)
+	if err != nil {
+		return nil, err
+	}
+	return node.(ast.Mod), nil
+}
+
+// Converts a python node into a pointer to or struct of a Go node
+func objectToNodeValue(obj py.Object) (reflect.Value, error) {
+	nc := findNodeClass(obj)
+	if nc == nil {
+		return reflect.Value{}, py.ExceptionNewf(py.TypeError, "expected some sort of AST, but got %s", reprOf(obj))
+	}
+	ptr := reflect.New(nc.goType)
+	v := ptr.Elem()
+	name := nc.cls.Name
+	for _, field := range nc.fields {
+		dst := v.Field(field.index)
+		value, err := py.GetAttrString(obj, field.name)
+		if err != nil {
+			if !py.IsException(py.AttributeError, err) {
+				return reflect.Value{}, err
+			}
+			switch {
+			case dst.Kind() == reflect.Slice || dst.Kind() == reflect.Int || field.optional:
+				// Missing lists are empty and missing numbers 0
+				continue
+			case dst.Type() == exprContext:
+				dst.Set(reflect.ValueOf(ast.Load))
+				continue
+			}
+			return reflect.Value{}, py.ExceptionNewf(py.TypeError, "required field \"%s\" missing from %s", field.name, name)
+		}
+		err = objectToValue(dst, value, field.optional, field.name, name)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if nc.hasPos {
+		for _, attr := range []struct {
+			name     string
+			field    string
+			optional bool
+		}{
+			{"lineno", "Lineno", false},
+			{"col_offset", "ColOffset", false},
+			{"end_lineno", "EndLineno", true},
+			{"end_col_offset", "EndColOffset", true},
+		} {
+			value, err := py.GetAttrString(obj, attr.name)
+			if err != nil {
+				if py.IsException(py.AttributeError, err) {
+					err = py.ExceptionNewf(py.TypeError, "required field \"%s\" missing from %s", attr.name, name)
+				}
+				return reflect.Value{}, err
+			}
+			if attr.optional && value == py.None {
+				continue
+			}
+			n, err := py.MakeGoInt(value)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.FieldByName(attr.field).SetInt(int64(n))
+		}
+	}
+	if nc.isPointer {
+		return ptr, nil
+	}
+	return v, nil
+}
+
+// Sets dst, a field of a Go node called field of the node called
+// name, from the python object obj
+func objectToValue(dst reflect.Value, obj py.Object, optional bool, field, name string) error {
+	t := dst.Type()
+	if obj == py.None {
+		switch t {
+		case objectType, singletonType:
+			dst.Set(reflect.ValueOf(obj))
+			return nil
+		}
+		if optional && t.Kind() != reflect.Slice {
+			dst.Set(reflect.Zero(t))
+			return nil
+		}
+		return py.ExceptionNewf(py.TypeError, "required field \"%s\" missing from %s", field, name)
+	}
+	switch t {
+	case identifierType:
+		s, ok := obj.(py.String)
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "AST identifier must be of type str")
+		}
+		dst.Set(reflect.ValueOf(ast.Identifier(s)))
+		return nil
+	case stringType:
+		s, ok := obj.(py.String)
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "AST string must be of type str")
+		}
+		dst.Set(reflect.ValueOf(s))
+		return nil
+	case bytesType:
+		b, ok := obj.(py.Bytes)
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "AST bytes must be of type bytes")
+		}
+		dst.Set(reflect.ValueOf(b))
+		return nil
+	case objectType, singletonType:
+		dst.Set(reflect.ValueOf(obj))
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if t.Name() == "int" {
+			n, err := py.MakeGoInt(obj)
+			if err != nil {
+				return err
+			}
+			dst.SetInt(int64(n))
+			return nil
+		}
+		ec := findEnumClass(obj)
+		if ec == nil || ec.value.Type() != t {
+			return py.ExceptionNewf(py.TypeError, "expected some sort of %s, but got %s", enumBaseName(t), reprOf(obj))
+		}
+		dst.Set(ec.value)
+		return nil
+	case reflect.Slice:
+		var items []reflect.Value
+		var err error
+		iterErr := py.Iterate(obj, func(item py.Object) bool {
+			elem := reflect.New(t.Elem()).Elem()
+			err = objectToValue(elem, item, optional, field, name)
+			if err != nil {
+				return true
+			}
+			items = append(items, elem)
+			return false
+		})
+		if iterErr != nil {
+			return py.ExceptionNewf(py.TypeError, "%s field \"%s\" must be a list, not a %s", name, field, obj.Type().Name)
+		}
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(t, 0, len(items))
+		slice = reflect.Append(slice, items...)
+		dst.Set(slice)
+		return nil
+	case reflect.Interface, reflect.Ptr, reflect.Struct:
+		v, err := objectToNodeValue(obj)
+		if err != nil {
+			return err
+		}
+		if !v.Type().AssignableTo(t) {
+			kind, ok := interfaceNames[t]
+			if !ok {
+				elem := t
+				if t.Kind() == reflect.Ptr {
+					elem = t.Elem()
+				}
+				kind = elem.Name()
+				if nc, ok := classByGoType[elem]; ok {
+					kind = nc.cls.Name
+				}
+			}
+			return py.ExceptionNewf(py.TypeError, "expected some sort of %s, but got %s", kind, reprOf(obj))
+		}
+		dst.Set(v)
+		return nil
+	}
+	return py.ExceptionNewf(py.SystemError, "can't convert %s.%s to %s", name, field, t)
+}
+
+// Returns the name of the base class of the enum with Go type t
+func enumBaseName(t reflect.Type) string {
+	for _, enum := range enums {
+		if reflect.TypeOf(enum.values[0]) == t {
+			return enum.base
+		}
+	}
+	return t.Name()
+}
+
+// Returns repr(obj) for error messages
+func reprOf(obj py.Object) string {
+	s, err := py.ReprAsString(obj)
+	if err != nil {
+		return "<" + obj.Type().Name + ">"
+	}
+	return s
+}
@@ -0,0 +1,772 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Ast module
+//
+// The nodes are those of gpython's parser, so numbers and strings are
+// Num, Str and Bytes nodes rather than Constant and subscripts have
+// Index and ExtSlice nodes as they do in CPython before 3.8.
+
+package pyast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-python/gpython/ast"
+	"github.com/go-python/gpython/inspect"
+	"github.com/go-python/gpython/parser"
+	"github.com/go-python/gpython/py"
+)
+
+const module_doc = `The ast module helps Python applications to process trees of the Python
+abstract syntax grammar.  The abstract syntax itself might change with
+each Python release; this module helps to find out programmatically what
+the current grammar looks like and allows modifications of it.
+
+An abstract syntax tree can be generated by passing ast.PyCF_ONLY_AST as
+a flag to the compile() builtin function or by using the parse()
+function from this module.  The result will be a tree of objects whose
+classes all inherit from ast.AST.
+
+A modified abstract syntax tree can be compiled into a Python code object
+using the built-in compile() function.
+
+Additionally various helper functions are provided that make working with
+the trees simpler.  The main intention of the helper functions and this
+module in general is to provide an easy to use interface for libraries
+that work tightly with the python syntax (template engines for example).`
+
+// PyCF_ONLY_AST is the flag to make compile() return the ast
+const PyCF_ONLY_AST = 0x0400
+
+// Parse parses source into a tree of python ast nodes as ast.parse
+// does
+func Parse(source, filename, mode string) (py.Object, error) {
+	mod, err := parser.Parse(strings.NewReader(source), filename, mode)
+	if err != nil {
+		return nil, err
+	}
+	return NodeToObject(mod)
+}
+
+// Returns the source code in a str or bytes object
+func sourceArg(obj py.Object, funcname string) (string, error) {
+	switch x := obj.(type) {
+	case py.String:
+		return string(x), nil
+	case py.Bytes:
+		return string(x), nil
+	}
+	return "", py.ExceptionNewf(py.TypeError, "%s() arg 1 must be a string or bytes object", funcname)
+}
+
+const parse_doc = `parse(source, filename='<unknown>', mode='exec')
+
+Parse the source into an AST node.
+Equivalent to compile(source, filename, mode, PyCF_ONLY_AST).`
+
+func ast_parse(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var source py.Object
+	var filename py.Object = py.String("<unknown>")
+	var mode py.Object = py.String("exec")
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|ss:parse", []string{"source", "filename", "mode"}, &source, &filename, &mode)
+	if err != nil {
+		return nil, err
+	}
+	src, err := sourceArg(source, "parse")
+	if err != nil {
+		return nil, err
+	}
+	return Parse(src, string(filename.(py.String)), string(mode.(py.String)))
+}
+
+// Formats node for dump
+func dumpNode(node py.Object, annotateFields, includeAttributes bool) (string, error) {
+	if IsNode(node) {
+		var args []string
+		keywords := annotateFields
+		add := func(attr string) error {
+			names, err := namesAttr(node, attr)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				value, err := py.GetAttrString(node, name)
+				if err != nil {
+					if !py.IsException(py.AttributeError, err) {
+						return err
+					}
+					keywords = true
+					continue
+				}
+				s, err := dumpNode(value, annotateFields, includeAttributes)
+				if err != nil {
+					return err
+				}
+				if keywords {
+					s = name + "=" + s
+				}
+				args = append(args, s)
+			}
+			return nil
+		}
+		err := add("_fields")
+		if err != nil {
+			return "", err
+		}
+		if includeAttributes {
+			keywords = true
+			err = add("_attributes")
+			if err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%s(%s)", node.Type().Name, strings.Join(args, ", ")), nil
+	}
+	if list, ok := node.(*py.List); ok {
+		items := make([]string, len(list.Items))
+		for i, item := range list.Items {
+			s, err := dumpNode(item, annotateFields, includeAttributes)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+	return py.ReprAsString(node)
+}
+
+const dump_doc = `dump(node, annotate_fields=True, include_attributes=False)
+
+Return a formatted dump of the tree in node.  This is mainly useful for
+debugging purposes.  If annotate_fields is true (by default),
+the returned string will show the names and the values for fields.
+If annotate_fields is false, the result string will be more compact by
+omitting unambiguous field names.  Attributes such as line
+numbers and column offsets are not dumped by default.  If this is wanted,
+include_attributes can be set to true.`
+
+func ast_dump(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var node py.Object
+	var annotateFields py.Object = py.True
+	var includeAttributes py.Object = py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:dump", []string{"node", "annotate_fields", "include_attributes"}, &node, &annotateFields, &includeAttributes)
+	if err != nil {
+		return nil, err
+	}
+	if !IsNode(node) {
+		return nil, py.ExceptionNewf(py.TypeError, "expected AST, got '%s'", node.Type().Name)
+	}
+	s, err := dumpNode(node, py.ObjectIsTrue(annotateFields), py.ObjectIsTrue(includeAttributes))
+	if err != nil {
+		return nil, err
+	}
+	return py.String(s), nil
+}
+
+// Returns a malformed node error for node
+func malformed(node ast.Ast) error {
+	if node == nil {
+		return py.ExceptionNewf(py.ValueError, "malformed node or string: None")
+	}
+	return py.ExceptionNewf(py.ValueError, "malformed node or string on line %d: %s", node.GetLineno(), ast.Dump(node))
+}
+
+// Evaluates a number with an optional sign for literal_eval
+func literalNum(node ast.Ast, signed bool) (py.Object, error) {
+	switch x := node.(type) {
+	case *ast.Num:
+		return x.N, nil
+	case *ast.UnaryOp:
+		if signed {
+			operand, err := literalNum(x.Operand, false)
+			if err != nil {
+				return nil, malformed(node)
+			}
+			switch x.Op {
+			case ast.UAdd:
+				return py.Pos(operand)
+			case ast.USub:
+				return py.Neg(operand)
+			}
+		}
+	}
+	return nil, malformed(node)
+}
+
+// LiteralEval evaluates an expression node containing only a python
+// literal or container display as ast.literal_eval does
+func LiteralEval(node ast.Ast) (py.Object, error) {
+	evalAll := func(nodes []ast.Expr) ([]py.Object, error) {
+		items := make([]py.Object, len(nodes))
+		for i, node := range nodes {
+			item, err := LiteralEval(node)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	switch x := node.(type) {
+	case *ast.Expression:
+		return LiteralEval(x.Body)
+	case *ast.Num, *ast.UnaryOp:
+		return literalNum(node, true)
+	case *ast.Str:
+		return x.S, nil
+	case *ast.Bytes:
+		return x.S, nil
+	case *ast.NameConstant:
+		return x.Value, nil
+	case *ast.Ellipsis:
+		return py.Ellipsis, nil
+	case *ast.Tuple:
+		items, err := evalAll(x.Elts)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple(items), nil
+	case *ast.List:
+		items, err := evalAll(x.Elts)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewListFromItems(items), nil
+	case *ast.Set:
+		items, err := evalAll(x.Elts)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewSetFromItems(items)
+	case *ast.Call:
+		// set() is the only way to write an empty set
+		if name, ok := x.Func.(*ast.Name); ok && name.Id == "set" && len(x.Args) == 0 && len(x.Keywords) == 0 && x.Starargs == nil && x.Kwargs == nil {
+			return py.NewSet(), nil
+		}
+	case *ast.Dict:
+		if len(x.Keys) != len(x.Values) {
+			return nil, malformed(node)
+		}
+		d := py.NewDict()
+		for i := range x.Keys {
+			if x.Keys[i] == nil {
+				return nil, malformed(x.Values[i])
+			}
+			key, err := LiteralEval(x.Keys[i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := LiteralEval(x.Values[i])
+			if err != nil {
+				return nil, err
+			}
+			_, err = d.M__setitem__(key, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return d, nil
+	case *ast.BinOp:
+		// Complex numbers such as 1+2j
+		if x.Op == ast.Add || x.Op == ast.Sub {
+			left, err := literalNum(x.Left, true)
+			if err != nil {
+				return nil, err
+			}
+			right, err := literalNum(x.Right, false)
+			if err != nil {
+				return nil, err
+			}
+			_, leftInt := left.(py.Int)
+			_, leftBigInt := left.(*py.BigInt)
+			_, leftFloat := left.(py.Float)
+			_, rightComplex := right.(py.Complex)
+			if (leftInt || leftBigInt || leftFloat) && rightComplex {
+				if x.Op == ast.Add {
+					return py.Add(left, right)
+				}
+				return py.Sub(left, right)
+			}
+		}
+	}
+	return nil, malformed(node)
+}
+
+const literal_eval_doc = `literal_eval(node_or_string)
+
+Safely evaluate an expression node or a string containing a Python
+expression.  The string or node provided may only consist of the following
+Python literal structures: strings, bytes, numbers, tuples, lists, dicts,
+sets, booleans, and None.`
+
+func ast_literal_eval(self, nodeOrString py.Object) (py.Object, error) {
+	var node ast.Ast
+	var err error
+	if s, ok := nodeOrString.(py.String); ok {
+		node, err = parser.Parse(strings.NewReader(strings.TrimLeft(string(s), " \t")), "<unknown>", "eval")
+	} else {
+		node, err = ObjectToNode(nodeOrString)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return LiteralEval(node)
+}
+
+// Returns the values of the fields of node as (name, value) pairs
+func iterFields(node py.Object) ([]py.Object, error) {
+	names, err := fieldNames(node)
+	if err != nil {
+		return nil, err
+	}
+	var fields []py.Object
+	for _, name := range names {
+		value, err := py.GetAttrString(node, name)
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				continue
+			}
+			return nil, err
+		}
+		fields = append(fields, py.Tuple{py.String(name), value})
+	}
+	return fields, nil
+}
+
+// Returns the nodes in the fields of node and in the lists in them
+func iterChildNodes(node py.Object) ([]py.Object, error) {
+	fields, err := iterFields(node)
+	if err != nil {
+		return nil, err
+	}
+	var children []py.Object
+	for _, field := range fields {
+		value := field.(py.Tuple)[1]
+		if IsNode(value) {
+			children = append(children, value)
+		} else if list, ok := value.(*py.List); ok {
+			for _, item := range list.Items {
+				if IsNode(item) {
+					children = append(children, item)
+				}
+			}
+		}
+	}
+	return children, nil
+}
+
+const iter_fields_doc = `iter_fields(node)
+
+Yield a tuple of ` + "``(fieldname, value)``" + ` for each field in ` + "``node._fields``" + `
+that is present on *node*.`
+
+func ast_iter_fields(self, node py.Object) (py.Object, error) {
+	fields, err := iterFields(node)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewIterator(fields), nil
+}
+
+const iter_child_nodes_doc = `iter_child_nodes(node)
+
+Yield all direct child nodes of *node*, that is, all fields that are nodes
+and all items of fields that are lists of nodes.`
+
+func ast_iter_child_nodes(self, node py.Object) (py.Object, error) {
+	children, err := iterChildNodes(node)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewIterator(children), nil
+}
+
+const walk_doc = `walk(node)
+
+Recursively yield all descendant nodes in the tree starting at *node*
+(including *node* itself), in no specified order.  This is useful if you
+only want to modify nodes in place and don't care about the context.`
+
+// Returns node and all its descendants, breadth first
+func walkNodes(node py.Object) ([]py.Object, error) {
+	nodes := []py.Object{node}
+	for i := 0; i < len(nodes); i++ {
+		children, err := iterChildNodes(nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, children...)
+	}
+	return nodes, nil
+}
+
+func ast_walk(self, node py.Object) (py.Object, error) {
+	nodes, err := walkNodes(node)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewIterator(nodes), nil
+}
+
+// Returns the python class of the Go node
+func classOf(node interface{}) *py.Type {
+	return classByGoType[reflect.TypeOf(node).Elem()].cls
+}
+
+const get_docstring_doc = `get_docstring(node, clean=True)
+
+Return the docstring for the given node or None if no docstring can
+be found.  If the node provided does not have docstrings a TypeError
+will be raised.
+
+If *clean* is ` + "`True`" + `, all tabs are expanded to spaces and any whitespace
+that can be uniformly removed from the second line onwards is removed.`
+
+func ast_get_docstring(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var node py.Object
+	var clean py.Object = py.True
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:get_docstring", []string{"node", "clean"}, &node, &clean)
+	if err != nil {
+		return nil, err
+	}
+	ok := false
+	for _, cls := range []*py.Type{classOf((*ast.FunctionDef)(nil)), classOf((*ast.AsyncFunctionDef)(nil)), classOf((*ast.ClassDef)(nil)), classOf((*ast.Module)(nil))} {
+		ok = ok || node.Type().IsSubtype(cls)
+	}
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "'%s' can't have docstrings", node.Type().Name)
+	}
+	body, err := py.GetAttrString(node, "body")
+	if err != nil {
+		return nil, err
+	}
+	list, ok := body.(*py.List)
+	if !ok || len(list.Items) == 0 || !list.Items[0].Type().IsSubtype(classOf((*ast.ExprStmt)(nil))) {
+		return py.None, nil
+	}
+	value, err := py.GetAttrString(list.Items[0], "value")
+	if err != nil {
+		return nil, err
+	}
+	if !value.Type().IsSubtype(classOf((*ast.Str)(nil))) {
+		return py.None, nil
+	}
+	text, err := py.GetAttrString(value, "s")
+	if err != nil {
+		return nil, err
+	}
+	s, ok := text.(py.String)
+	if !ok {
+		return py.None, nil
+	}
+	if py.ObjectIsTrue(clean) {
+		s = py.String(inspect.CleanDoc(string(s)))
+	}
+	return s, nil
+}
+
+const get_source_segment_doc = `get_source_segment(source, node, *, padded=False)
+
+Get source code segment of the *source* that generated *node*.
+
+If some location information (` + "`lineno`, `end_lineno`, `col_offset`, or `end_col_offset`" + `)
+is missing, return None.
+
+If *padded* is ` + "`True`" + `, the first line of a multi-line statement will
+be padded with spaces to match its original position.`
+
+func ast_get_source_segment(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var source, node py.Object
+	var padded py.Object = py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:get_source_segment", []string{"source", "node", "padded"}, &source, &node, &padded)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := source.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "expected str, got %s", source.Type().Name)
+	}
+	var location [4]int
+	for i, attr := range posAttributes {
+		value, err := py.GetAttrString(node, string(attr.(py.String)))
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				return py.None, nil
+			}
+			return nil, err
+		}
+		if value == py.None {
+			return py.None, nil
+		}
+		location[i], err = py.MakeGoInt(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	lineno, colOffset, endLineno, endColOffset := location[0]-1, location[1], location[2]-1, location[3]
+	lines := splitLines(string(text))
+	if lineno < 0 || lineno > endLineno || endLineno >= len(lines) {
+		return py.None, nil
+	}
+	// The offsets are into the UTF-8 of the lines
+	first, last := lines[lineno], lines[endLineno]
+	colOffset = clip(colOffset, len(first))
+	endColOffset = clip(endColOffset, len(last))
+	if lineno == endLineno {
+		return py.String(first[colOffset:clip(endColOffset, len(first))]), nil
+	}
+	var out strings.Builder
+	if py.ObjectIsTrue(padded) {
+		for _, c := range first[:colOffset] {
+			if c != '\f' && c != '\t' {
+				c = ' '
+			}
+			out.WriteRune(c)
+		}
+	}
+	out.WriteString(first[colOffset:])
+	for _, line := range lines[lineno+1 : endLineno] {
+		out.WriteString(line)
+	}
+	out.WriteString(last[:endColOffset])
+	return py.String(out.String()), nil
+}
+
+// Returns the lines of text with their line endings which are \n,
+// \r\n or \r
+func splitLines(text string) []string {
+	var lines []string
+	for len(text) > 0 {
+		i := strings.IndexAny(text, "\r\n")
+		if i < 0 {
+			break
+		}
+		if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			i++
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+	if len(text) > 0 {
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+// Returns i limited to 0 to n
+func clip(i, n int) int {
+	if i < 0 {
+		return 0
+	} else if i > n {
+		return n
+	}
+	return i
+}
+
+// Returns whether name is in the _attributes of node
+func hasAttribute(node py.Object, name string) (bool, error) {
+	attributes, err := namesAttr(node, "_attributes")
+	if err != nil {
+		return false, err
+	}
+	for _, attribute := range attributes {
+		if attribute == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+const copy_location_doc = `copy_location(new_node, old_node)
+
+Copy source location (` + "`lineno`, `col_offset`, `end_lineno`, and `end_col_offset`" + `
+attributes) from *old_node* to *new_node* if possible, and return *new_node*.`
+
+func ast_copy_location(self py.Object, args py.Tuple) (py.Object, error) {
+	var newNode, oldNode py.Object
+	err := py.UnpackTuple(args, nil, "copy_location", 2, 2, &newNode, &oldNode)
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range posAttributes {
+		name := string(attr.(py.String))
+		inOld, err := hasAttribute(oldNode, name)
+		if err != nil {
+			return nil, err
+		}
+		inNew, err := hasAttribute(newNode, name)
+		if err != nil {
+			return nil, err
+		}
+		if !inOld || !inNew {
+			continue
+		}
+		value, err := py.GetAttrString(oldNode, name)
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				continue
+			}
+			return nil, err
+		}
+		_, err = py.SetAttrString(newNode, name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newNode, nil
+}
+
+// Sets the position of node and its children where it is missing to
+// that of their parent.  The location holds the values of
+// posAttributes.
+func fixMissingLocations(node py.Object, location []py.Object) error {
+	location = append([]py.Object(nil), location...)
+	for i, attr := range posAttributes {
+		name := string(attr.(py.String))
+		ok, err := hasAttribute(node, name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		value, err := py.GetAttrString(node, name)
+		// The end is None if it isn't set
+		if err == nil && value != py.None {
+			location[i] = value
+			continue
+		}
+		if err != nil && !py.IsException(py.AttributeError, err) {
+			return err
+		}
+		_, err = py.SetAttrString(node, name, location[i])
+		if err != nil {
+			return err
+		}
+	}
+	children, err := iterChildNodes(node)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		err = fixMissingLocations(child, location)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const fix_missing_locations_doc = `fix_missing_locations(node)
+
+When you compile a node tree with compile(), the compiler expects lineno,
+col_offset, end_lineno and end_col_offset attributes for every node that
+supports them.  This is rather
+tedious to fill in for generated nodes, so this helper adds these attributes
+recursively where not already set, by setting them to the values of the
+parent node.  It works recursively starting at *node*.`
+
+func ast_fix_missing_locations(self, node py.Object) (py.Object, error) {
+	err := fixMissingLocations(node, []py.Object{py.Int(1), py.Int(0), py.Int(1), py.Int(0)})
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+const increment_lineno_doc = `increment_lineno(node, n=1)
+
+Increment the line number and end line number of each node in the tree
+starting at *node* by *n*.  This is useful to "move code" to a different
+location in a file.`
+
+func ast_increment_lineno(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var node py.Object
+	var n py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:increment_lineno", []string{"node", "n"}, &node, &n)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := walkNodes(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range nodes {
+		ok, err := hasAttribute(child, "lineno")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		var lineno py.Object = py.Int(0)
+		value, err := py.GetAttrString(child, "lineno")
+		if err == nil {
+			lineno = value
+		} else if !py.IsException(py.AttributeError, err) {
+			return nil, err
+		}
+		lineno, err = py.Add(lineno, n)
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(child, "lineno", lineno)
+		if err != nil {
+			return nil, err
+		}
+		endLineno, err := py.GetAttrString(child, "end_lineno")
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				continue
+			}
+			return nil, err
+		}
+		if endLineno == py.None {
+			continue
+		}
+		endLineno, err = py.Add(endLineno, n)
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetAttrString(child, "end_lineno", endLineno)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("parse", ast_parse, 0, parse_doc),
+		py.MustNewMethod("dump", ast_dump, 0, dump_doc),
+		py.MustNewMethod("literal_eval", ast_literal_eval, 0, literal_eval_doc),
+		py.MustNewMethod("iter_fields", ast_iter_fields, 0, iter_fields_doc),
+		py.MustNewMethod("iter_child_nodes", ast_iter_child_nodes, 0, iter_child_nodes_doc),
+		py.MustNewMethod("walk", ast_walk, 0, walk_doc),
+		py.MustNewMethod("get_docstring", ast_get_docstring, 0, get_docstring_doc),
+		py.MustNewMethod("get_source_segment", ast_get_source_segment, 0, get_source_segment_doc),
+		py.MustNewMethod("copy_location", ast_copy_location, 0, copy_location_doc),
+		py.MustNewMethod("fix_missing_locations", ast_fix_missing_locations, 0, fix_missing_locations_doc),
+		py.MustNewMethod("increment_lineno", ast_increment_lineno, 0, increment_lineno_doc),
+	}
+	globals := py.StringDict{
+		"PyCF_ONLY_AST":   py.Int(PyCF_ONLY_AST),
+		"NodeVisitor":     NodeVisitorClass,
+		"NodeTransformer": NodeTransformerClass,
+	}
+	for _, cls := range classes {
+		globals[cls.Name] = cls
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "ast",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
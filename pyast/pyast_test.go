@@ -0,0 +1,65 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pyast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-python/gpython/ast"
+	_ "github.com/go-python/gpython/builtin"
+	"github.com/go-python/gpython/parser"
+	"github.com/go-python/gpython/pyast"
+	"github.com/go-python/gpython/pytest"
+)
+
+func TestAst(t *testing.T) {
+	pytest.RunTests(t, "tests")
+}
+
+// Checks converting to python nodes and back gives the same tree
+func TestRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		mode string
+	}{
+		{"a + b * -c", "eval"},
+		{"[x for x in y if x] + {k: v for k, v in z}", "eval"},
+		{"f(a, *b, c=1, **d)", "eval"},
+		{"x[1:2, ::3]", "eval"},
+		{"lambda a, *b, c=1, **d: None", "eval"},
+		{"b'x' + 'y' if a is not b else ...", "eval"},
+		{"f'{x!r:>{w}}'", "eval"},
+		{"x = y = 1\ndel x\ny += 2\nz: int = 3", "exec"},
+		{"@d\ndef f(a, b=1, *, c, **k) -> int:\n    '''doc'''\n    global g\n    return (yield a)", "exec"},
+		{"class C(B, metaclass=M):\n    pass", "exec"},
+		{"for a, b in c:\n    break\nelse:\n    continue", "exec"},
+		{"while x:\n    pass\nif y:\n    pass\nelif z:\n    pass", "exec"},
+		{"try:\n    pass\nexcept E as e:\n    raise X from e\nfinally:\n    assert x, 'msg'", "exec"},
+		{"import a.b as c\nfrom ..d import e, f as g", "exec"},
+		{"with a as b, c:\n    pass", "exec"},
+		{"async def f():\n    async with a:\n        await b\n    async for c in d:\n        pass", "exec"},
+		{"match x:\n    case [1, *rest] | {'k': v, **kw} if v:\n        pass\n    case C(a, b=_) as y:\n        pass", "exec"},
+		{"(n := 10)", "eval"},
+		{"x = 1\n", "single"},
+	} {
+		mod, err := parser.Parse(strings.NewReader(test.in), "<string>", test.mode)
+		if err != nil {
+			t.Fatalf("%q: parse failed: %v", test.in, err)
+		}
+		obj, err := pyast.NodeToObject(mod)
+		if err != nil {
+			t.Fatalf("%q: NodeToObject failed: %v", test.in, err)
+		}
+		node, err := pyast.ObjectToNode(obj)
+		if err != nil {
+			t.Fatalf("%q: ObjectToNode failed: %v", test.in, err)
+		}
+		want, got := ast.Dump(mod), ast.Dump(node)
+		if want != got {
+			t.Errorf("%q: round trip\nwant %s\n got %s", test.in, want, got)
+		}
+	}
+}
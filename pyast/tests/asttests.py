@@ -0,0 +1,193 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import ast
+from libtest import *
+
+doc = "parse"
+tree = ast.parse("x = a + 1\nprint(x)")
+assert isinstance(tree, ast.Module)
+assert isinstance(tree, ast.mod)
+assert isinstance(tree, ast.AST)
+assign = tree.body[0]
+assert isinstance(assign, ast.Assign)
+assert isinstance(assign, ast.stmt)
+assert assign.lineno == 1
+assert assign.col_offset == 0
+assert isinstance(assign.targets[0].ctx, ast.Store)
+assert assign.value.left.id == "a"
+assert isinstance(assign.value.op, ast.Add)
+assert isinstance(assign.value.op, ast.operator)
+assert tree.body[1].lineno == 2
+assert isinstance(ast.parse("a and b", mode="eval"), ast.Expression)
+assert isinstance(ast.parse(b"pass", "<bytes>"), ast.Module)
+assertRaises(SyntaxError, ast.parse, "x = ")
+try:
+    ast.parse("\n(", filename="bad.py")
+except SyntaxError as e:
+    assert e.filename == "bad.py"
+else:
+    fail("SyntaxError not raised")
+
+doc = "node classes"
+assert ast.Name._fields == ("id", "ctx")
+assert ast.BinOp._fields == ("left", "op", "right")
+assert ast.stmt._attributes == ("lineno", "col_offset", "end_lineno", "end_col_offset")
+assert ast.Module._attributes == ()
+assert ast.Expr.__name__ == "Expr"
+assert ast.Expr._fields == ("value",)
+n = ast.Name("x", ast.Load(), lineno=3)
+assert n.id == "x" and isinstance(n.ctx, ast.Load) and n.lineno == 3
+assertRaises(AttributeError, getattr, n, "col_offset")
+assertRaises(TypeError, ast.Name, "x", ast.Load(), 1)
+n.extra = 1
+assert n.extra == 1
+assert isinstance(ast.arguments(), ast.AST)
+assert isinstance(ast.comprehension(), ast.AST)
+assert isinstance(ast.ExceptHandler(), ast.excepthandler)
+
+doc = "dump"
+assert ast.dump(ast.parse("x", mode="eval")) == "Expression(body=Name(id='x', ctx=Load()))"
+assert ast.dump(ast.parse("-x", mode="eval"), annotate_fields=False) == "Expression(UnaryOp(USub(), Name('x', Load())))"
+assert ast.dump(ast.parse("x", mode="eval").body, include_attributes=True) == "Name(id='x', ctx=Load(), lineno=1, col_offset=0, end_lineno=1, end_col_offset=1)"
+assert ast.dump(ast.Name(id="y")) == "Name(id='y')"
+assert ast.dump(ast.Name(ctx=ast.Load()), annotate_fields=False) == "Name(ctx=Load())"
+assertRaises(TypeError, ast.dump, "x")
+
+doc = "literal_eval"
+assert ast.literal_eval("1") == 1
+assert ast.literal_eval("  -2.5") == -2.5
+assert ast.literal_eval("'a' 'b'") == "ab"
+assert ast.literal_eval("b'x'") == b"x"
+assert ast.literal_eval("[1, (2, None), {3}, {'k': True}]") == [1, (2, None), {3}, {"k": True}]
+assert ast.literal_eval("set()") == set()
+assert ast.literal_eval("1+2j") == 1+2j
+assert ast.literal_eval("-1-2j") == -1-2j
+assert ast.literal_eval(ast.parse("(1, 2)", mode="eval")) == (1, 2)
+assert ast.literal_eval(ast.parse("[3]", mode="eval").body) == [3]
+assertRaises(ValueError, ast.literal_eval, "x")
+assertRaises(ValueError, ast.literal_eval, "1 + 1")
+assertRaises(ValueError, ast.literal_eval, "f()")
+assertRaises(ValueError, ast.literal_eval, "{**a}")
+assertRaises(ValueError, ast.literal_eval, "-'a'")
+assertRaises(SyntaxError, ast.literal_eval, "(")
+
+doc = "iter_fields, iter_child_nodes and walk"
+node = ast.parse("a + b", mode="eval").body
+assert [name for name, value in ast.iter_fields(node)] == ["left", "op", "right"]
+assert [child.id for child in ast.iter_child_nodes(node) if isinstance(child, ast.Name)] == ["a", "b"]
+assert list(ast.iter_fields(ast.Name(id="x"))) == [("id", "x")]
+names = [n.id for n in ast.walk(ast.parse("f(a, [b, c])")) if isinstance(n, ast.Name)]
+assert sorted(names) == ["a", "b", "c", "f"]
+
+doc = "get_docstring"
+tree = ast.parse('''
+def f():
+    """Doc
+       string"""
+class C:
+    "class doc"
+def g():
+    pass
+''')
+assert ast.get_docstring(tree.body[0]) == "Doc\nstring"
+assert ast.get_docstring(tree.body[0], clean=False) == "Doc\n       string"
+assert ast.get_docstring(tree.body[1]) == "class doc"
+assert ast.get_docstring(tree.body[2]) is None
+assert ast.get_docstring(ast.parse('"module"')) == "module"
+assertRaises(TypeError, ast.get_docstring, tree.body[2].body[0])
+
+doc = "locations"
+old = ast.parse("(\n  x)", mode="eval").body
+new = ast.copy_location(ast.Name(id="y", ctx=ast.Load()), old)
+assert new.lineno == 2 and new.col_offset == 2
+tree = ast.Expression(body=ast.BinOp(left=ast.Num(n=1, lineno=5, col_offset=3), op=ast.Add(), right=ast.Num(n=2)))
+assert ast.fix_missing_locations(tree) is tree
+assert tree.body.lineno == 1 and tree.body.col_offset == 0
+assert tree.body.left.lineno == 5 and tree.body.left.col_offset == 3
+assert tree.body.right.lineno == 1
+assert not hasattr(tree.body.op, "lineno")
+assert ast.increment_lineno(tree, 3) is tree
+assert tree.body.lineno == 4 and tree.body.left.lineno == 8
+assert tree.body.end_lineno == 4 and tree.body.left.end_lineno == 4
+assert ast.Name(id="x", ctx=ast.Load()).end_lineno is None
+
+doc = "end positions"
+tree = ast.parse("x = f(a,\n  (b + c))\n")
+call = tree.body[0].value
+assert (call.lineno, call.col_offset, call.end_lineno, call.end_col_offset) == (1, 4, 2, 10)
+assert (tree.body[0].end_lineno, tree.body[0].end_col_offset) == (2, 10)
+binop = call.args[1]
+assert (binop.col_offset, binop.end_col_offset) == (3, 8)
+
+doc = "get_source_segment"
+source = "x = 1\nif x:\n    y = [1,\n         2]\n"
+tree = ast.parse(source)
+assert ast.get_source_segment(source, tree.body[0]) == "x = 1"
+assert ast.get_source_segment(source, tree.body[0].value) == "1"
+lst = tree.body[1].body[0].value
+assert ast.get_source_segment(source, lst) == "[1,\n         2]"
+assert ast.get_source_segment(source, tree.body[1].body[0], padded=True) == "    y = [1,\n         2]"
+assert ast.get_source_segment(source, ast.Name(id="x", ctx=ast.Load())) is None
+
+doc = "NodeVisitor"
+class Counter(ast.NodeVisitor):
+    def __init__(self):
+        self.names = []
+        self.calls = 0
+    def visit_Name(self, node):
+        self.names.append(node.id)
+    def visit_Call(self, node):
+        self.calls += 1
+        self.generic_visit(node)
+counter = Counter()
+counter.visit(ast.parse("f(a, g(b))\nc = d"))
+assert counter.names == ["f", "a", "g", "b", "c", "d"]
+assert counter.calls == 2
+
+class Returner(ast.NodeVisitor):
+    def visit_Num(self, node):
+        return node.n
+assert Returner().visit(ast.parse("42", mode="eval").body) == 42
+assert Returner().visit(ast.parse("x", mode="eval")) is None
+
+doc = "NodeTransformer"
+class Doubler(ast.NodeTransformer):
+    def visit_Num(self, node):
+        return ast.copy_location(ast.Num(n=node.n * 2), node)
+tree = Doubler().visit(ast.parse("x = 1 + 2"))
+assert tree.body[0].value.left.n == 2
+assert tree.body[0].value.right.n == 4
+
+class Remover(ast.NodeTransformer):
+    def visit_Pass(self, node):
+        return None
+    def visit_Expr(self, node):
+        return [node, ast.copy_location(ast.Pass(), node)]
+tree = Remover().visit(ast.parse("pass\nx\npass"))
+assert [type(stmt).__name__ for stmt in tree.body] == ["Expr", "Pass"]
+
+doc = "compile"
+tree = ast.parse("result = 6 * 7")
+tree.body[0].value.op = ast.Add()
+ns = {}
+exec(compile(tree, "<ast>", "exec"), ns)
+assert ns["result"] == 13
+assert eval(compile(ast.parse("1 + 2", mode="eval"), "<ast>", "eval")) == 3
+tree = ast.Expression(body=ast.BinOp(left=ast.Num(n=6), op=ast.Mult(), right=ast.Num(n=7)))
+assert eval(compile(ast.fix_missing_locations(tree), "<ast>", "eval")) == 42
+tree = compile("a + 1", "<string>", "eval", ast.PyCF_ONLY_AST)
+assert isinstance(tree, ast.Expression)
+assert compile(tree, "<ast>", "eval", ast.PyCF_ONLY_AST) is tree
+tree.body.right.n = 41
+assert eval(compile(tree, "<ast>", "eval"), {"a": 1}) == 42
+code = compile(ast.parse("def f():\n    return 1"), "file.py", "exec")
+assert code.co_filename == "file.py"
+assertRaises(TypeError, compile, ast.parse("x"), "<ast>", "eval")
+assertRaises(TypeError, compile, ast.Expression(body=ast.Name(id="x", ctx=ast.Load())), "<ast>", "eval")
+assertRaises(TypeError, compile, ast.Expression(body=ast.Pass(lineno=1, col_offset=0)), "<ast>", "eval")
+assertRaises(TypeError, compile, ast.Expression(), "<ast>", "eval")
+assertRaises(TypeError, compile, ast.Module(body=ast.Pass(lineno=1, col_offset=0)), "<ast>", "exec")
+
+doc = "finished"
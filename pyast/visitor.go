@@ -0,0 +1,140 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// NodeVisitor and NodeTransformer
+
+package pyast
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const node_visitor_doc = `A node visitor base class that walks the abstract syntax tree and calls a
+visitor function for every node found.  This function may return a value
+which is forwarded by the ` + "`visit`" + ` method.
+
+This class is meant to be subclassed, with the subclass adding visitor
+methods.
+
+Per default the visitor functions for the nodes are ` + "``'visit_'``" + ` +
+class name of the node.  So a ` + "`TryFinally`" + ` node visit function would
+be ` + "`visit_TryFinally`" + `.  This behavior can be changed by overriding
+the ` + "`visit`" + ` method.  If no visitor function exists for a node
+(return value ` + "`None`" + `) the ` + "`generic_visit`" + ` visitor is used instead.
+
+Don't use the ` + "`NodeVisitor`" + ` if you want to apply changes to nodes during
+traversing.  For this a special visitor exists (` + "`NodeTransformer`" + `) that
+allows modifications.`
+
+// NodeVisitorClass is the base class of python visitors of the nodes
+var NodeVisitorClass = py.NewClass(py.TypeType, "ast", "NodeVisitor", node_visitor_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"visit":         py.NewGoMethod1("visit", nodeVisitorVisit),
+	"generic_visit": py.NewGoMethod1("generic_visit", nodeVisitorGenericVisit),
+})
+
+const node_transformer_doc = `A :class:` + "`NodeVisitor`" + ` subclass that walks the abstract syntax tree and
+allows modification of nodes.
+
+The ` + "`NodeTransformer`" + ` will walk the AST and use the return value of the
+visitor methods to replace or remove the old node.  If the return value of
+the visitor method is ` + "``None``" + `, the node will be removed from its location,
+otherwise it is replaced with the return value.  The return value may be the
+original node in which case no replacement takes place.`
+
+// NodeTransformerClass is the base class of python visitors which
+// change the nodes
+var NodeTransformerClass = py.NewClass(py.TypeType, "ast", "NodeTransformer", node_transformer_doc, py.Tuple{NodeVisitorClass}, py.StringDict{
+	"generic_visit": py.NewGoMethod1("generic_visit", nodeTransformerGenericVisit),
+})
+
+// Calls self.visit(node)
+func visit(self, node py.Object) (py.Object, error) {
+	method, err := py.GetAttrString(self, "visit")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(method, py.Tuple{node}, nil)
+}
+
+// Visits node with the visit_ method for its class or generic_visit
+func nodeVisitorVisit(self, node py.Object) (py.Object, error) {
+	visitor, err := py.GetAttrString(self, "visit_"+node.Type().Name)
+	if err != nil {
+		if !py.IsException(py.AttributeError, err) {
+			return nil, err
+		}
+		visitor, err = py.GetAttrString(self, "generic_visit")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.Call(visitor, py.Tuple{node}, nil)
+}
+
+// Visits the children of node
+func nodeVisitorGenericVisit(self, node py.Object) (py.Object, error) {
+	children, err := iterChildNodes(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		_, err = visit(self, child)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Visits the children of node replacing them with the results
+func nodeTransformerGenericVisit(self, node py.Object) (py.Object, error) {
+	fields, err := iterFields(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		name, oldValue := string(field.(py.Tuple)[0].(py.String)), field.(py.Tuple)[1]
+		if list, ok := oldValue.(*py.List); ok {
+			var newValues []py.Object
+			for _, value := range list.Items {
+				if IsNode(value) {
+					value, err = visit(self, value)
+					if err != nil {
+						return nil, err
+					}
+					if value == py.None {
+						continue
+					}
+					if !IsNode(value) {
+						// A sequence of nodes replaces the node
+						err = py.Iterate(value, func(item py.Object) bool {
+							newValues = append(newValues, item)
+							return false
+						})
+						if err != nil {
+							return nil, err
+						}
+						continue
+					}
+				}
+				newValues = append(newValues, value)
+			}
+			list.Items = newValues
+		} else if IsNode(oldValue) {
+			newNode, err := visit(self, oldValue)
+			if err != nil {
+				return nil, err
+			}
+			if newNode == py.None {
+				err = py.DeleteAttrString(node, name)
+			} else {
+				_, err = py.SetAttrString(node, name, newNode)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Mersenne Twister
+//
+// This is MT19937 seeded in the same way as CPython so that the same
+// seed gives the same sequence of numbers.
+
+package random
+
+import (
+	"math/big"
+)
+
+const (
+	mtN         = 624
+	mtM         = 397
+	mtMatrixA   = 0x9908b0df
+	mtUpperMask = 0x80000000
+	mtLowerMask = 0x7fffffff
+)
+
+// mersenne is the state of the generator
+type mersenne struct {
+	mt    [mtN]uint32
+	index int // next word of mt to use, mtN if they are all used
+}
+
+// Seeds the generator from a single word
+func (g *mersenne) initGenRand(s uint32) {
+	g.mt[0] = s
+	for i := 1; i < mtN; i++ {
+		g.mt[i] = 1812433253*(g.mt[i-1]^(g.mt[i-1]>>30)) + uint32(i)
+	}
+	g.index = mtN
+}
+
+// Seeds the generator from the words in key
+func (g *mersenne) initByArray(key []uint32) {
+	g.initGenRand(19650218)
+	i, j := 1, 0
+	k := mtN
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		g.mt[i] = (g.mt[i] ^ ((g.mt[i-1] ^ (g.mt[i-1] >> 30)) * 1664525)) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= mtN {
+			g.mt[0] = g.mt[mtN-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = mtN - 1; k > 0; k-- {
+		g.mt[i] = (g.mt[i] ^ ((g.mt[i-1] ^ (g.mt[i-1] >> 30)) * 1566083941)) - uint32(i)
+		i++
+		if i >= mtN {
+			g.mt[0] = g.mt[mtN-1]
+			i = 1
+		}
+	}
+	g.mt[0] = 0x80000000 // MSB is 1 assuring non-zero initial array
+}
+
+// Seeds the generator from the absolute value of n using all its bits
+func (g *mersenne) initByInt(n *big.Int) {
+	b := new(big.Int).Abs(n).Bytes()
+	key := make([]uint32, 0, len(b)/4+1)
+	for end := len(b); end > 0; end -= 4 {
+		var word uint32
+		for i := end - 4; i < end; i++ {
+			word <<= 8
+			if i >= 0 {
+				word |= uint32(b[i])
+			}
+		}
+		key = append(key, word)
+	}
+	if len(key) == 0 {
+		key = append(key, 0)
+	}
+	g.initByArray(key)
+}
+
+// Returns the next 32 random bits
+func (g *mersenne) uint32() uint32 {
+	var mag01 = [2]uint32{0, mtMatrixA}
+	if g.index >= mtN {
+		var kk int
+		for kk = 0; kk < mtN-mtM; kk++ {
+			y := (g.mt[kk] & mtUpperMask) | (g.mt[kk+1] & mtLowerMask)
+			g.mt[kk] = g.mt[kk+mtM] ^ (y >> 1) ^ mag01[y&1]
+		}
+		for ; kk < mtN-1; kk++ {
+			y := (g.mt[kk] & mtUpperMask) | (g.mt[kk+1] & mtLowerMask)
+			g.mt[kk] = g.mt[kk+(mtM-mtN)] ^ (y >> 1) ^ mag01[y&1]
+		}
+		y := (g.mt[mtN-1] & mtUpperMask) | (g.mt[0] & mtLowerMask)
+		g.mt[mtN-1] = g.mt[mtM-1] ^ (y >> 1) ^ mag01[y&1]
+		g.index = 0
+	}
+	y := g.mt[g.index]
+	g.index++
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// Returns a float in [0, 1) with 53 random bits
+func (g *mersenne) float64() float64 {
+	a := g.uint32() >> 5
+	b := g.uint32() >> 6
+	return (float64(a)*67108864.0 + float64(b)) * (1.0 / 9007199254740992.0)
+}
+
+// Returns an int with k random bits
+func (g *mersenne) bits(k int) *big.Int {
+	words := make([]byte, 0, (k+31)/32*4)
+	for ; k > 0; k -= 32 {
+		r := g.uint32()
+		if k < 32 {
+			r >>= uint(32 - k)
+		}
+		words = append(words, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+	}
+	// words are least significant first but SetBytes wants big endian
+	for i, j := 0, len(words)-4; i < j; i, j = i+4, j-4 {
+		for n := 0; n < 4; n++ {
+			words[i+n], words[j+n] = words[j+n], words[i+n]
+		}
+	}
+	return new(big.Int).SetBytes(words)
+}
+
+// Returns a random int in [0, n) for n > 0
+func (g *mersenne) below(n uint64) uint64 {
+	k := 0
+	for x := n; x != 0; x >>= 1 {
+		k++
+	}
+	for {
+		var r uint64
+		if k <= 32 {
+			r = uint64(g.uint32() >> uint(32-k))
+		} else {
+			r = uint64(g.uint32()) | uint64(g.uint32()>>uint(64-k))<<32
+		}
+		if r < n {
+			return r
+		}
+	}
+}
+
+// Returns a random int in [0, n) for n > 0
+func (g *mersenne) belowBig(n *big.Int) *big.Int {
+	k := n.BitLen()
+	for {
+		r := g.bits(k)
+		if r.Cmp(n) < 0 {
+			return r
+		}
+	}
+}
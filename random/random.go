@@ -0,0 +1,875 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Random module
+//
+// The module level functions are bound methods of a hidden Random
+// instance, as in CPython.
+package random
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+const random_doc = `Random() -> create a random number generator with its own internal state.`
+
+// RandomType is the type of Random objects
+var RandomType = py.ObjectType.NewTypeFlags("Random", random_doc, RandomNew, RandomInit, py.ObjectType.Flags|py.TPFLAGS_BASETYPE|py.TPFLAGS_SUBCLASS_NEW)
+
+// The version of the state returned by getstate
+const stateVersion = 3
+
+// Random is a random number generator
+type Random struct {
+	Base      *py.Type
+	Dict      py.StringDict
+	gen       mersenne
+	gaussNext py.Object // nil if there isn't a saved gauss value
+}
+
+// Type of this object
+func (r *Random) Type() *py.Type {
+	return r.Base
+}
+
+// GetDict returns the attributes of the Random
+func (r *Random) GetDict() py.StringDict {
+	return r.Dict
+}
+
+// NewRandom makes a Random seeded from the operating system
+func NewRandom() *Random {
+	r := &Random{Base: RandomType}
+	r.seedFromSystem()
+	return r
+}
+
+// RandomNew makes a Random ignoring any arguments which are for __init__
+func RandomNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	r := &Random{Base: metatype}
+	if metatype.Flags&py.TPFLAGS_HEAPTYPE != 0 {
+		r.Dict = py.NewStringDict()
+	}
+	r.seedFromSystem()
+	return r, nil
+}
+
+// RandomInit seeds the Random from its optional argument
+func RandomInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	var x py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, "Random", 0, 1, &x)
+	if err != nil {
+		return err
+	}
+	return self.(*Random).seed(x, 2)
+}
+
+// Seeds the generator from the operating system or the time if that
+// isn't available
+func (r *Random) seedFromSystem() {
+	var buf [32]byte
+	key := make([]uint32, len(buf)/4)
+	if _, err := rand.Read(buf[:]); err == nil {
+		for i := range key {
+			key[i] = binary.LittleEndian.Uint32(buf[4*i:])
+		}
+	} else {
+		now := uint64(time.Now().UnixNano())
+		key = []uint32{uint32(now), uint32(now >> 32)}
+	}
+	r.gen.initByArray(key)
+	r.gaussNext = nil
+}
+
+// Seeds the generator from a in the same way as CPython
+func (r *Random) seed(a py.Object, version int) error {
+	var n *big.Int
+	switch x := a.(type) {
+	case py.NoneType:
+		r.seedFromSystem()
+		return nil
+	case py.Int:
+		n = big.NewInt(int64(x))
+	case *py.BigInt:
+		n = (*big.Int)(x)
+	case py.Bool:
+		n = big.NewInt(0)
+		if x {
+			n.SetInt64(1)
+		}
+	case py.String:
+		if version == 1 {
+			n = seedFromChars([]rune(string(x)))
+		} else {
+			n = seedFromBytes([]byte(x))
+		}
+	case py.Bytes:
+		if version == 1 {
+			chars := make([]rune, len(x))
+			for i, c := range x {
+				chars[i] = rune(c)
+			}
+			n = seedFromChars(chars)
+		} else {
+			n = seedFromBytes([]byte(x))
+		}
+	case py.Float:
+		hash, err := py.Hash(x)
+		if err != nil {
+			return err
+		}
+		n = new(big.Int).SetUint64(uint64(hash))
+	default:
+		return py.ExceptionNewf(py.TypeError, "The only supported seed types are: None,\nint, float, str, bytes, and bytearray.")
+	}
+	r.gen.initByInt(n)
+	r.gaussNext = nil
+	return nil
+}
+
+// Returns the int to seed the generator with for a str or bytes
+func seedFromBytes(b []byte) *big.Int {
+	sum := sha512.Sum512(b)
+	return new(big.Int).SetBytes(append(b[:len(b):len(b)], sum[:]...))
+}
+
+// Returns the int to seed the generator with for a str or bytes with
+// the narrower version 1 algorithm
+func seedFromChars(chars []rune) *big.Int {
+	var x uint64
+	if len(chars) > 0 {
+		x = uint64(chars[0]) << 7
+	}
+	for _, c := range chars {
+		x = (1000003 * x) ^ uint64(c)
+	}
+	x ^= uint64(len(chars))
+	return new(big.Int).SetUint64(x)
+}
+
+// Returns the state of the generator as a python object
+func (r *Random) getState() py.Object {
+	state := make(py.Tuple, mtN+1)
+	for i, x := range r.gen.mt {
+		state[i] = py.Int(x)
+	}
+	state[mtN] = py.Int(r.gen.index)
+	gaussNext := r.gaussNext
+	if gaussNext == nil {
+		gaussNext = py.None
+	}
+	return py.Tuple{py.Int(stateVersion), state, gaussNext}
+}
+
+// Restores the state of the generator from the result of getState
+func (r *Random) setState(stateObj py.Object) error {
+	state, ok := stateObj.(py.Tuple)
+	if !ok || len(state) != 3 {
+		return py.ExceptionNewf(py.TypeError, "state must be a tuple of (version, internalstate, gauss_next)")
+	}
+	version, err := py.IndexInt(state[0])
+	if err != nil {
+		return err
+	}
+	if version != 2 && version != stateVersion {
+		return py.ExceptionNewf(py.ValueError, "state with version %d passed to Random.setstate() of version %d", version, stateVersion)
+	}
+	internal, ok := state[1].(py.Tuple)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "state vector must be a tuple")
+	}
+	if len(internal) != mtN+1 {
+		return py.ExceptionNewf(py.ValueError, "state vector is the wrong size")
+	}
+	var gen mersenne
+	for i := range gen.mt {
+		x, ok := py.ConvertToBigInt(internal[i])
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "state vector items must be integers")
+		}
+		// Version 2 states were saved as signed ints so reduce them
+		// modulo 2**32
+		gen.mt[i] = uint32(new(big.Int).And((*big.Int)(x), big.NewInt(0xffffffff)).Uint64())
+	}
+	gen.index, err = py.IndexInt(internal[mtN])
+	if err != nil {
+		return err
+	}
+	if gen.index < 0 || gen.index > mtN {
+		return py.ExceptionNewf(py.ValueError, "invalid state")
+	}
+	r.gen = gen
+	r.gaussNext = state[2]
+	if r.gaussNext == py.None {
+		r.gaussNext = nil
+	}
+	return nil
+}
+
+// Returns an int with k random bits
+func (r *Random) getRandBits(k int) (py.Object, error) {
+	if k < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "number of bits must be non-negative")
+	}
+	if k <= 32 {
+		if k == 0 {
+			return py.Int(0), nil
+		}
+		return py.Int(r.gen.uint32() >> uint(32-k)), nil
+	}
+	return (*py.BigInt)(r.gen.bits(k)).MaybeInt(), nil
+}
+
+// Returns a random int in [0, n) for n > 0
+func (r *Random) randBelow(n *big.Int) *big.Int {
+	if n.IsUint64() {
+		return new(big.Int).SetUint64(r.gen.below(n.Uint64()))
+	}
+	return r.gen.belowBig(n)
+}
+
+// Returns a random int in [0, n) for n > 0
+func (r *Random) randBelowInt(n int) int {
+	return int(r.gen.below(uint64(n)))
+}
+
+// Converts an argument of randrange to an int, allowing floats with
+// integral values for compatibility
+func rangeArg(obj py.Object, what string) (*big.Int, error) {
+	if f, ok := obj.(py.Float); ok {
+		if f != py.Float(math.Trunc(float64(f))) || math.IsInf(float64(f), 0) {
+			return nil, py.ExceptionNewf(py.ValueError, "non-integer %s for randrange()", what)
+		}
+		n, _ := new(big.Float).SetFloat64(float64(f)).Int(nil)
+		return n, nil
+	}
+	if n, ok := obj.(*py.BigInt); ok {
+		return (*big.Int)(n), nil
+	}
+	i, err := py.Index(obj)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(int64(i)), nil
+}
+
+// Chooses a random item from range(start, stop, step) where stop and
+// step are nil if not supplied
+func (r *Random) randRange(startObj, stopObj, stepObj py.Object) (py.Object, error) {
+	start, err := rangeArg(startObj, "arg 1")
+	if err != nil {
+		return nil, err
+	}
+	if stopObj == nil || stopObj == py.None {
+		if stepObj != nil {
+			return nil, py.ExceptionNewf(py.TypeError, "Missing a non-None stop argument")
+		}
+		if start.Sign() > 0 {
+			return (*py.BigInt)(r.randBelow(start)).MaybeInt(), nil
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "empty range for randrange()")
+	}
+	stop, err := rangeArg(stopObj, "stop")
+	if err != nil {
+		return nil, err
+	}
+	width := new(big.Int).Sub(stop, start)
+	step := big.NewInt(1)
+	if stepObj != nil {
+		step, err = rangeArg(stepObj, "step")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if step.IsInt64() && step.Int64() == 1 {
+		if width.Sign() <= 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "empty range for randrange() (%v, %v, %v)", start, stop, width)
+		}
+		return (*py.BigInt)(new(big.Int).Add(start, r.randBelow(width))).MaybeInt(), nil
+	}
+	n := new(big.Int)
+	switch step.Sign() {
+	case 0:
+		return nil, py.ExceptionNewf(py.ValueError, "zero step for randrange()")
+	case 1:
+		n.Add(width, step).Sub(n, big.NewInt(1))
+	default:
+		n.Add(width, step).Add(n, big.NewInt(1))
+	}
+	// big.Int.DivMod is Euclidean so fix up the floor division
+	_, m := n.DivMod(n, step, new(big.Int))
+	if m.Sign() != 0 && step.Sign() < 0 {
+		n.Sub(n, big.NewInt(1))
+	}
+	if n.Sign() <= 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "empty range for randrange()")
+	}
+	res := new(big.Int).Mul(step, r.randBelow(n))
+	return (*py.BigInt)(res.Add(start, res)).MaybeInt(), nil
+}
+
+// Returns the length of obj as a go int
+func length(obj py.Object) (int, error) {
+	n, err := py.Len(obj)
+	if err != nil {
+		return 0, err
+	}
+	return py.IndexInt(n)
+}
+
+// Chooses a random element from the non-empty sequence seq
+func (r *Random) choice(seq py.Object) (py.Object, error) {
+	n, err := length(seq)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, py.ExceptionNewf(py.IndexError, "Cannot choose from an empty sequence")
+	}
+	return py.GetItem(seq, py.Int(r.randBelowInt(n)))
+}
+
+// Shuffles the sequence x in place
+func (r *Random) shuffle(x py.Object) error {
+	n, err := length(x)
+	if err != nil {
+		return err
+	}
+	if l, ok := x.(*py.List); ok {
+		for i := n - 1; i > 0; i-- {
+			j := r.randBelowInt(i + 1)
+			l.Items[i], l.Items[j] = l.Items[j], l.Items[i]
+		}
+		return nil
+	}
+	for i := n - 1; i > 0; i-- {
+		j := r.randBelowInt(i + 1)
+		a, err := py.GetItem(x, py.Int(i))
+		if err != nil {
+			return err
+		}
+		b, err := py.GetItem(x, py.Int(j))
+		if err != nil {
+			return err
+		}
+		if _, err = py.SetItem(x, py.Int(i), b); err != nil {
+			return err
+		}
+		if _, err = py.SetItem(x, py.Int(j), a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns k unique random indexes into a population of size n in
+// selection order
+func (r *Random) sampleIndexes(n, k int) ([]int, error) {
+	if k < 0 || k > n {
+		return nil, py.ExceptionNewf(py.ValueError, "Sample larger than population or is negative")
+	}
+	result := make([]int, k)
+	// Use whichever of a pool of the unselected or a set of the
+	// selected indexes would be smaller
+	setsize := 21
+	if k > 5 {
+		setsize += int(math.Pow(4, math.Ceil(math.Log(float64(k*3))/math.Log(4))))
+	}
+	if n <= setsize {
+		pool := make([]int, n)
+		for i := range pool {
+			pool[i] = i
+		}
+		for i := range result {
+			j := r.randBelowInt(n - i)
+			result[i] = pool[j]
+			pool[j] = pool[n-i-1]
+		}
+	} else {
+		selected := make(map[int]struct{}, k)
+		for i := range result {
+			j := r.randBelowInt(n)
+			for {
+				if _, found := selected[j]; !found {
+					break
+				}
+				j = r.randBelowInt(n)
+			}
+			selected[j] = struct{}{}
+			result[i] = j
+		}
+	}
+	return result, nil
+}
+
+// Returns the running totals of the items of iterable
+func accumulate(iterable py.Object) ([]py.Object, error) {
+	var totals []py.Object
+	var total py.Object
+	var err error
+	err = py.Iterate(iterable, func(item py.Object) bool {
+		if total == nil {
+			total = item
+		} else {
+			total, err = py.Add(total, item)
+			if err != nil {
+				return true
+			}
+		}
+		totals = append(totals, total)
+		return false
+	})
+	return totals, err
+}
+
+// Returns the index of the first item of a[lo:hi] greater than x
+func bisectRight(a []py.Object, x py.Object, lo, hi int) (int, error) {
+	for lo < hi {
+		mid := (lo + hi) / 2
+		less, err := py.Lt(x, a[mid])
+		if err != nil {
+			return 0, err
+		}
+		if less == py.True {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// Chooses k unique random elements from the population
+func (r *Random) sample(population, kObj, counts py.Object) (py.Object, error) {
+	switch population.Type() {
+	case py.SetType, py.FrozenSetType, py.DictType:
+		return nil, py.ExceptionNewf(py.TypeError, "Population must be a sequence.  For dicts or sets, use sorted(d).")
+	}
+	n, err := length(population)
+	if err != nil {
+		return nil, err
+	}
+	k, err := py.IndexInt(kObj)
+	if err != nil {
+		return nil, err
+	}
+	result := py.NewListSized(k)
+	if counts != nil && counts != py.None {
+		cumCounts, err := accumulate(counts)
+		if err != nil {
+			return nil, err
+		}
+		if len(cumCounts) != n {
+			return nil, py.ExceptionNewf(py.ValueError, "The number of counts does not match the population")
+		}
+		var total int
+		if n > 0 {
+			last, ok := cumCounts[n-1].(py.Int)
+			if !ok {
+				return nil, py.ExceptionNewf(py.TypeError, "Counts must be integers")
+			}
+			total = int(last)
+			cumCounts = cumCounts[:n-1]
+		}
+		if total <= 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "Total of counts must be greater than zero")
+		}
+		selections, err := r.sampleIndexes(total, k)
+		if err != nil {
+			return nil, err
+		}
+		for i, s := range selections {
+			j, err := bisectRight(cumCounts, py.Int(s), 0, len(cumCounts))
+			if err != nil {
+				return nil, err
+			}
+			result.Items[i], err = py.GetItem(population, py.Int(j))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	selections, err := r.sampleIndexes(n, k)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := range selections {
+		result.Items[i], err = py.GetItem(population, py.Int(j))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Chooses k elements from the population with replacement
+func (r *Random) choices(population, weights, cumWeights py.Object, k int) (py.Object, error) {
+	n, err := length(population)
+	if err != nil {
+		return nil, err
+	}
+	if k < 0 {
+		k = 0
+	}
+	result := py.NewListSized(k)
+	var cum []py.Object
+	if cumWeights == nil || cumWeights == py.None {
+		if weights == nil || weights == py.None {
+			for i := range result.Items {
+				j := int(math.Floor(r.gen.float64() * float64(n)))
+				result.Items[i], err = py.GetItem(population, py.Int(j))
+				if err != nil {
+					return nil, err
+				}
+			}
+			return result, nil
+		}
+		cum, err = accumulate(weights)
+		if err != nil {
+			if k, ok := weights.(py.Int); ok {
+				return nil, py.ExceptionNewf(py.TypeError, "The number of choices must be a keyword argument: k=%d", k)
+			}
+			return nil, err
+		}
+	} else if weights != nil && weights != py.None {
+		return nil, py.ExceptionNewf(py.TypeError, "Cannot specify both weights and cumulative weights")
+	} else {
+		list, err := py.SequenceList(cumWeights)
+		if err != nil {
+			return nil, err
+		}
+		cum = list.Items
+	}
+	if len(cum) != n {
+		return nil, py.ExceptionNewf(py.ValueError, "The number of weights does not match the population")
+	}
+	if n == 0 {
+		return nil, py.ExceptionNewf(py.IndexError, "list index out of range")
+	}
+	totalObj, err := py.Add(cum[n-1], py.Float(0))
+	if err != nil {
+		return nil, err
+	}
+	total, err := py.FloatAsFloat64(totalObj)
+	if err != nil {
+		return nil, err
+	}
+	if total <= 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "Total of weights must be greater than zero")
+	}
+	if math.IsInf(total, 0) || math.IsNaN(total) {
+		return nil, py.ExceptionNewf(py.ValueError, "Total of weights must be finite")
+	}
+	for i := range result.Items {
+		j, err := bisectRight(cum, py.Float(r.gen.float64()*total), 0, n-1)
+		if err != nil {
+			return nil, err
+		}
+		result.Items[i], err = py.GetItem(population, py.Int(j))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Returns a normally distributed float using the Box-Muller transform
+// keeping the second value for the next call
+func (r *Random) gauss(mu, sigma float64) float64 {
+	var z float64
+	if r.gaussNext != nil {
+		z, _ = py.FloatAsFloat64(r.gaussNext)
+		r.gaussNext = nil
+	} else {
+		x2pi := r.gen.float64() * 2 * math.Pi
+		g2rad := math.Sqrt(-2.0 * math.Log(1.0-r.gen.float64()))
+		z = math.Cos(x2pi) * g2rad
+		r.gaussNext = py.Float(math.Sin(x2pi) * g2rad)
+	}
+	return mu + z*sigma
+}
+
+// The constant for normalvariate, 4 * exp(-0.5) / sqrt(2.0)
+var nvMagicConst = 4 * math.Exp(-0.5) / math.Sqrt(2.0)
+
+// Returns a normally distributed float using the Kinderman and Monahan
+// method
+func (r *Random) normalVariate(mu, sigma float64) float64 {
+	var z float64
+	for {
+		u1 := r.gen.float64()
+		u2 := 1.0 - r.gen.float64()
+		z = nvMagicConst * (u1 - 0.5) / u2
+		if z*z/4.0 <= -math.Log(u2) {
+			break
+		}
+	}
+	return mu + z*sigma
+}
+
+// Reads the optional float arguments into results leaving the
+// defaults for those which are missing
+func getFloats(args []py.Object, results ...*float64) error {
+	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
+		x, err := py.FloatAsFloat64(arg)
+		if err != nil {
+			return err
+		}
+		*results[i] = x
+	}
+	return nil
+}
+
+// Rejects positional arguments beyond the first max counting self as
+// python does
+func checkPositional(name string, args py.Tuple, min, max int) error {
+	if len(args) <= max {
+		return nil
+	}
+	if min == max {
+		return py.ExceptionNewf(py.TypeError, "%s() takes %d positional arguments but %d were given", name, max+1, len(args)+1)
+	}
+	return py.ExceptionNewf(py.TypeError, "%s() takes from %d to %d positional arguments but %d were given", name, min+1, max+1, len(args)+1)
+}
+
+func init() {
+	RandomType.Dict["seed"] = py.MustNewMethod("seed", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var a py.Object = py.None
+		var versionObj py.Object = py.Int(2)
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:seed", []string{"a", "version"}, &a, &versionObj)
+		if err != nil {
+			return nil, err
+		}
+		version, err := py.IndexInt(versionObj)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Random).seed(a, version)
+	}, 0, "seed([n]) -> None.\n\nDefaults to use urandom and falls back to a combination\nof the current time and the process identifier.")
+	RandomType.Dict["random"] = py.MustNewMethod("random", func(self py.Object) (py.Object, error) {
+		return py.Float(self.(*Random).gen.float64()), nil
+	}, 0, "random() -> x in the interval [0, 1).")
+	RandomType.Dict["getrandbits"] = py.MustNewMethod("getrandbits", func(self, k py.Object) (py.Object, error) {
+		n, err := py.IndexInt(k)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Random).getRandBits(n)
+	}, 0, "getrandbits(k) -> x.  Generates an int with k random bits.")
+	RandomType.Dict["randbytes"] = py.MustNewMethod("randbytes", func(self, nObj py.Object) (py.Object, error) {
+		n, err := py.IndexInt(nObj)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "number of bits must be non-negative")
+		}
+		// Little endian bytes of getrandbits(n * 8)
+		b := self.(*Random).gen.bits(n * 8).Bytes()
+		res := make(py.Bytes, n)
+		for i, c := range b {
+			res[len(b)-1-i] = c
+		}
+		return res, nil
+	}, 0, "Generate n random bytes.")
+	RandomType.Dict["getstate"] = py.MustNewMethod("getstate", func(self py.Object) (py.Object, error) {
+		return self.(*Random).getState(), nil
+	}, 0, "Return internal state; can be passed to setstate() later.")
+	RandomType.Dict["setstate"] = py.MustNewMethod("setstate", func(self, state py.Object) (py.Object, error) {
+		return py.None, self.(*Random).setState(state)
+	}, 0, "Restore internal state from object returned by getstate().")
+	RandomType.Dict["randrange"] = py.MustNewMethod("randrange", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var start, stop, step py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:randrange", []string{"start", "stop", "step"}, &start, &stop, &step)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Random).randRange(start, stop, step)
+	}, 0, "Choose a random item from range(stop) or range(start, stop[, step]).")
+	RandomType.Dict["randint"] = py.MustNewMethod("randint", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var a, b py.Object
+		err := py.UnpackTuple(args, nil, "randint", 2, 2, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		stop, err := py.Add(b, py.Int(1))
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Random).randRange(a, stop, nil)
+	}, 0, "Return random integer in range [a, b], including both end points.")
+	RandomType.Dict["choice"] = py.MustNewMethod("choice", func(self, seq py.Object) (py.Object, error) {
+		return self.(*Random).choice(seq)
+	}, 0, "Choose a random element from a non-empty sequence.")
+	RandomType.Dict["shuffle"] = py.MustNewMethod("shuffle", func(self, x py.Object) (py.Object, error) {
+		return py.None, self.(*Random).shuffle(x)
+	}, 0, "Shuffle list x in place, and return None.")
+	RandomType.Dict["sample"] = py.MustNewMethod("sample", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var population, k, counts py.Object
+		if err := checkPositional("sample", args, 2, 2); err != nil {
+			return nil, err
+		}
+		err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:sample", []string{"population", "k", "counts"}, &population, &k, &counts)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Random).sample(population, k, counts)
+	}, 0, "Chooses k unique random elements from a population sequence.")
+	RandomType.Dict["choices"] = py.MustNewMethod("choices", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var population, weights, cumWeights py.Object
+		var kObj py.Object = py.Int(1)
+		if err := checkPositional("choices", args, 1, 2); err != nil {
+			return nil, err
+		}
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|OOO:choices", []string{"population", "weights", "cum_weights", "k"}, &population, &weights, &cumWeights, &kObj)
+		if err != nil {
+			return nil, err
+		}
+		k, err := py.IndexInt(kObj)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Random).choices(population, weights, cumWeights, k)
+	}, 0, "Return a k sized list of population elements chosen with replacement.")
+	RandomType.Dict["uniform"] = py.MustNewMethod("uniform", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var a, b py.Object
+		err := py.UnpackTuple(args, nil, "uniform", 2, 2, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		width, err := py.Sub(b, a)
+		if err != nil {
+			return nil, err
+		}
+		x, err := py.Mul(width, py.Float(self.(*Random).gen.float64()))
+		if err != nil {
+			return nil, err
+		}
+		return py.Add(a, x)
+	}, 0, "Get a random number in the range [a, b) or [a, b] depending on rounding.")
+	RandomType.Dict["triangular"] = py.MustNewMethod("triangular", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var lowObj, highObj py.Object
+		var modeObj py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OOO:triangular", []string{"low", "high", "mode"}, &lowObj, &highObj, &modeObj)
+		if err != nil {
+			return nil, err
+		}
+		low, high := 0.0, 1.0
+		if err = getFloats([]py.Object{lowObj, highObj}, &low, &high); err != nil {
+			return nil, err
+		}
+		u := self.(*Random).gen.float64()
+		c := 0.5
+		if modeObj != py.None {
+			mode, err := py.FloatAsFloat64(modeObj)
+			if err != nil {
+				return nil, err
+			}
+			if high == low {
+				if lowObj == nil {
+					return py.Float(low), nil
+				}
+				return lowObj, nil
+			}
+			c = (mode - low) / (high - low)
+		}
+		if u > c {
+			u = 1.0 - u
+			c = 1.0 - c
+			low, high = high, low
+		}
+		return py.Float(low + (high-low)*math.Sqrt(u*c)), nil
+	}, 0, "Triangular distribution.")
+	RandomType.Dict["normalvariate"] = py.MustNewMethod("normalvariate", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var muObj, sigmaObj py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:normalvariate", []string{"mu", "sigma"}, &muObj, &sigmaObj)
+		if err != nil {
+			return nil, err
+		}
+		mu, sigma := 0.0, 1.0
+		if err = getFloats([]py.Object{muObj, sigmaObj}, &mu, &sigma); err != nil {
+			return nil, err
+		}
+		return py.Float(self.(*Random).normalVariate(mu, sigma)), nil
+	}, 0, "Normal distribution.\n\nmu is the mean, and sigma is the standard deviation.")
+	RandomType.Dict["lognormvariate"] = py.MustNewMethod("lognormvariate", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var muObj, sigmaObj py.Object
+		err := py.UnpackTuple(args, nil, "lognormvariate", 2, 2, &muObj, &sigmaObj)
+		if err != nil {
+			return nil, err
+		}
+		var mu, sigma float64
+		if err = getFloats([]py.Object{muObj, sigmaObj}, &mu, &sigma); err != nil {
+			return nil, err
+		}
+		return py.Float(math.Exp(self.(*Random).normalVariate(mu, sigma))), nil
+	}, 0, "Log normal distribution.")
+	RandomType.Dict["gauss"] = py.MustNewMethod("gauss", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var muObj, sigmaObj py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:gauss", []string{"mu", "sigma"}, &muObj, &sigmaObj)
+		if err != nil {
+			return nil, err
+		}
+		mu, sigma := 0.0, 1.0
+		if err = getFloats([]py.Object{muObj, sigmaObj}, &mu, &sigma); err != nil {
+			return nil, err
+		}
+		return py.Float(self.(*Random).gauss(mu, sigma)), nil
+	}, 0, "Gaussian distribution.\n\nmu is the mean, and sigma is the standard deviation.  This is\nslightly faster than the normalvariate() function.")
+	RandomType.Dict["expovariate"] = py.MustNewMethod("expovariate", func(self, lambdObj py.Object) (py.Object, error) {
+		lambd, err := py.FloatAsFloat64(lambdObj)
+		if err != nil {
+			return nil, err
+		}
+		if lambd == 0 {
+			return nil, py.ExceptionNewf(py.ZeroDivisionError, "float division by zero")
+		}
+		return py.Float(-math.Log(1.0-self.(*Random).gen.float64()) / lambd), nil
+	}, 0, "Exponential distribution.\n\nlambd is 1.0 divided by the desired mean.")
+}
+
+const module_doc = `Random variable generators.
+
+The functions are bound methods of a hidden instance of the Random
+class, a Mersenne Twister which gives the same sequences as CPython
+for the same seed.  Make your own Random instances to get generators
+which don't share state.`
+
+// Initialise the module
+func init() {
+	RandomType.Dict["__module__"] = py.String("random")
+	inst := NewRandom()
+	globals := py.StringDict{
+		"Random": RandomType,
+		"_inst":  inst,
+	}
+	for _, name := range []string{
+		"choice", "choices", "expovariate", "gauss", "getrandbits",
+		"getstate", "lognormvariate", "normalvariate", "randbytes",
+		"randint", "random", "randrange", "sample", "seed", "setstate",
+		"shuffle", "triangular", "uniform",
+	} {
+		method, err := py.GetAttrString(inst, name)
+		if err != nil {
+			panic(err)
+		}
+		globals[name] = method
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "random",
+		Doc:     module_doc,
+		Globals: globals,
+	})
+}
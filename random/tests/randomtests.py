@@ -0,0 +1,133 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import random
+from libtest import *
+
+doc = "seed and random match CPython"
+random.seed(12345)
+assert random.random() == 0.41661987254534116
+assert random.random() == 0.010169169457068361
+random.seed(0)
+assert random.random() == 0.8444218515250481
+random.seed(-7)
+assert random.random() == 0.32383276483316237
+random.seed(2**100)
+assert random.random() == 0.7586581712996778
+random.seed("hello")
+assert random.random() == 0.3537754404730722
+random.seed(b"hello")
+assert random.random() == 0.3537754404730722
+random.seed("hello", version=1)
+assert random.random() == 0.8180391270568783
+random.seed()
+x = random.random()
+assert 0 <= x < 1
+assertRaisesText(TypeError, "The only supported seed types are", random.seed, [])
+
+doc = "getrandbits"
+random.seed(12345)
+random.random()
+random.random()
+assert random.getrandbits(5) == 26
+assert random.getrandbits(40) == 329865722087
+assert random.getrandbits(100) == 311508076646916808838757374138
+assert random.getrandbits(0) == 0
+assertRaisesText(ValueError, "number of bits must be non-negative", random.getrandbits, -1)
+
+doc = "randrange and randint"
+random.seed(12345)
+random.random()
+random.random()
+random.getrandbits(5)
+random.getrandbits(40)
+random.getrandbits(100)
+assert random.randint(1, 6) == 3
+assert random.randrange(10) == 9
+assert random.randrange(3, 100, 7) == 45
+assert random.randrange(100, 3, -7) == 86
+assert random.randrange(10**30) == 544628201610046148185631562937
+for i in range(100):
+    x = random.randrange(-5, 5, 3)
+    assert x in (-5, -2, 1, 4)
+    x = random.randint(-2, 2)
+    assert -2 <= x <= 2
+assertRaisesText(ValueError, "empty range for randrange()", random.randrange, 0)
+assertRaisesText(ValueError, "empty range for randrange() (5, 5, 0)", random.randrange, 5, 5)
+assertRaisesText(ValueError, "zero step for randrange()", random.randrange, 1, 10, 0)
+assertRaisesText(ValueError, "empty range for randrange()", random.randrange, 1, 10, -1)
+assertRaisesText(ValueError, "non-integer arg 1 for randrange()", random.randrange, 1.5)
+assertRaisesText(TypeError, "Missing a non-None stop argument", random.randrange, 5, None, 2)
+
+doc = "sequences"
+random.seed(12345)
+assert random.choice([1, 2, 3, 4, 5]) == 4
+l = list(range(20))
+random.shuffle(l)
+assert l == [17, 19, 3, 7, 10, 14, 15, 12, 4, 13, 1, 5, 2, 16, 18, 8, 6, 11, 9, 0]
+assert random.sample(range(100), 10) == [67, 94, 52, 74, 64, 21, 18, 26, 92, 9]
+assert random.sample(range(1000), 10) == [194, 857, 349, 329, 24, 469, 347, 27, 990, 522]
+assert sorted(random.sample([1, 2, 3], 3)) == [1, 2, 3]
+assert random.sample(['r', 'b'], counts=[4, 2], k=5) == ['r', 'r', 'b', 'r', 'b']
+assert random.choices([1, 2, 3], k=5) == [2, 1, 3, 1, 2]
+assert random.choices("abc", [1, 2, 3], k=5) == ['c', 'c', 'c', 'b', 'a']
+assert random.choices("abc", cum_weights=[1, 5, 6], k=4) == ['a', 'b', 'b', 'b']
+assert random.choices([1, 2]) in ([1], [2])
+assert random.choices([1, 2], k=0) == []
+t = (1, 2, 3)
+assert random.choice(t) in t
+assertRaisesText(IndexError, "Cannot choose from an empty sequence", random.choice, [])
+assertRaisesText(ValueError, "Sample larger than population or is negative", random.sample, [1], 2)
+assertRaisesText(TypeError, "Population must be a sequence", random.sample, {1, 2}, 1)
+assertRaisesText(ValueError, "The number of counts does not match the population", random.sample, [1, 2], 1, counts=[1])
+assertRaisesText(ValueError, "The number of weights does not match the population", random.choices, [1], [1, 2])
+assertRaisesText(TypeError, "Cannot specify both weights and cumulative weights", random.choices, [1], [1], cum_weights=[1])
+assertRaisesText(ValueError, "Total of weights must be greater than zero", random.choices, [1], [0])
+assertRaisesText(TypeError, "The number of choices must be a keyword argument: k=3", random.choices, [1, 2], 3)
+
+doc = "distributions"
+random.seed(12345)
+assert random.uniform(1, 2) == 1.4166198725453412
+assert random.gauss(0, 1) == 1.8638879649284623
+assert random.gauss(0, 1) == 0.11925503633144963
+assert random.normalvariate(1, 2) == -0.09387369718962657
+assert random.expovariate(3) == 0.07175048741465022
+assert random.triangular(0, 10, 2) == 4.1076875081432505
+assert random.lognormvariate(0, 1) == 0.5154367660019595
+assert random.gauss() == -1.172671518788357
+assert 0 <= random.triangular() <= 1
+assert random.triangular(3, 3, 3) == 3
+assertRaises(ZeroDivisionError, random.expovariate, 0)
+
+doc = "state"
+s = random.getstate()
+assert s[0] == 3
+assert len(s[1]) == 625
+a = [random.random() for i in range(5)]
+random.setstate(s)
+assert a == [random.random() for i in range(5)]
+random.seed(7)
+assert random.randbytes(5) == b'8\xb4\xe6R\xf2'
+_, internal, _ = random.getstate()
+assertRaisesText(ValueError, "state vector is the wrong size", random.setstate, (3, internal[:-1], None))
+assertRaisesText(ValueError, "state with version 9 passed to Random.setstate() of version 3", random.setstate, (9, internal, None))
+
+doc = "Random instances"
+r1 = random.Random(5)
+r2 = random.Random(5)
+assert r1.random() == r2.random()
+assert r1.randint(1, 1000) == r2.randint(1, 1000)
+random.seed(5)
+r1.seed(5)
+assert random.random() == r1.random()
+assert isinstance(random.Random(), random.Random)
+class R(random.Random):
+    def double(self):
+        return 2 * self.random()
+r = R(3)
+r.x = 1
+assert r.x == 1
+assert r.double() == 2 * random.Random(3).random()
+
+doc = "finished"
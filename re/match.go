@@ -0,0 +1,431 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Match objects and replacement templates
+
+package re
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+// MatchType is the type of the result of a successful match
+var MatchType = py.NewType("Match", "The result of re.match() and re.search().")
+
+// Match is a successful match of a Pattern
+type Match struct {
+	pattern *Pattern
+	subj    *subject
+	loc     []int // byte offsets of the start and end of each group or -1
+	pos     int
+	endpos  int
+}
+
+// Type of this Match object
+func (m *Match) Type() *py.Type {
+	return MatchType
+}
+
+// Makes a new Match from the group offsets in loc
+func (p *Pattern) newMatch(subj *subject, loc []int, pos, endpos int) *Match {
+	return &Match{
+		pattern: p,
+		subj:    subj,
+		loc:     loc,
+		pos:     pos,
+		endpos:  endpos,
+	}
+}
+
+// Returns the group number referred to by the index or name g
+func (m *Match) groupIndex(g py.Object) (int, error) {
+	switch x := g.(type) {
+	case py.Int:
+		if x >= 0 && int(x) <= m.pattern.groups() {
+			return int(x), nil
+		}
+	case py.String:
+		for i, name := range m.pattern.re.SubexpNames() {
+			if name != "" && name == string(x) {
+				return i, nil
+			}
+		}
+	}
+	return 0, py.ExceptionNewf(py.IndexError, "no such group")
+}
+
+// Returns the contents of group i or def if it didn't match
+func (m *Match) group(i int, def py.Object) py.Object {
+	if m.loc[2*i] < 0 {
+		return def
+	}
+	return m.subj.slice(m.loc[2*i], m.loc[2*i+1])
+}
+
+// Returns the python indexes of the start and end of group i
+func (m *Match) span(i int) (int, int) {
+	if m.loc[2*i] < 0 {
+		return -1, -1
+	}
+	return m.subj.index(m.loc[2*i]), m.subj.index(m.loc[2*i+1])
+}
+
+// Returns the index of the last group to match or -1
+//
+// This is the group which ended last, the outermost one if several
+// ended at the same place.
+func (m *Match) lastIndex() int {
+	last := -1
+	for i := 1; i <= m.pattern.groups(); i++ {
+		if m.loc[2*i] >= 0 && (last < 0 || m.loc[2*i+1] > m.loc[2*last+1]) {
+			last = i
+		}
+	}
+	return last
+}
+
+func (m *Match) M__repr__() (py.Object, error) {
+	repr, err := py.ReprAsString(m.group(0, py.None))
+	if err != nil {
+		return nil, err
+	}
+	start, end := m.span(0)
+	return py.String(fmt.Sprintf("<re.Match object; span=(%d, %d), match=%s>", start, end, repr)), nil
+}
+
+func (m *Match) M__getitem__(key py.Object) (py.Object, error) {
+	i, err := m.groupIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	return m.group(i, py.None), nil
+}
+
+func (m *Match) M__bool__() (py.Object, error) {
+	return py.True, nil
+}
+
+// Returns the group argument of start, end and span
+func (m *Match) groupArg(name string, args py.Tuple) (int, error) {
+	var g py.Object = py.Int(0)
+	err := py.UnpackTuple(args, nil, name, 0, 1, &g)
+	if err != nil {
+		return 0, err
+	}
+	return m.groupIndex(g)
+}
+
+// Returns the default argument of groups and groupdict
+func defaultArg(name string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var def py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:"+name, []string{"default"}, &def)
+	return def, err
+}
+
+func init() {
+	MatchType.Dict["group"] = py.MustNewMethod("group", func(self py.Object, args py.Tuple) (py.Object, error) {
+		m := self.(*Match)
+		if len(args) == 0 {
+			return m.group(0, py.None), nil
+		}
+		res := make(py.Tuple, len(args))
+		for i, arg := range args {
+			g, err := m.groupIndex(arg)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = m.group(g, py.None)
+		}
+		if len(res) == 1 {
+			return res[0], nil
+		}
+		return res, nil
+	}, 0, `group([group1, ...]) -> str or tuple.
+
+Return subgroup(s) of the match by indices or names.
+For 0 returns the entire match.`)
+	MatchType.Dict["groups"] = py.MustNewMethod("groups", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		m := self.(*Match)
+		def, err := defaultArg("groups", args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		res := make(py.Tuple, m.pattern.groups())
+		for i := range res {
+			res[i] = m.group(i+1, def)
+		}
+		return res, nil
+	}, 0, `groups([default=None]) -> tuple.
+
+Return a tuple containing all the subgroups of the match, from 1.
+The default argument is used for groups that did not participate in
+the match.`)
+	MatchType.Dict["groupdict"] = py.MustNewMethod("groupdict", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		m := self.(*Match)
+		def, err := defaultArg("groupdict", args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		d := py.NewStringDict()
+		for i, name := range m.pattern.re.SubexpNames() {
+			if name != "" {
+				d[name] = m.group(i, def)
+			}
+		}
+		return d, nil
+	}, 0, `groupdict([default=None]) -> dict.
+
+Return a dictionary containing all the named subgroups of the match,
+keyed by the subgroup name. The default argument is used for groups
+that did not participate in the match.`)
+	MatchType.Dict["start"] = py.MustNewMethod("start", func(self py.Object, args py.Tuple) (py.Object, error) {
+		m := self.(*Match)
+		g, err := m.groupArg("start", args)
+		if err != nil {
+			return nil, err
+		}
+		start, _ := m.span(g)
+		return py.Int(start), nil
+	}, 0, `start([group=0]) -> int.
+
+Return index of the start of the substring matched by group.`)
+	MatchType.Dict["end"] = py.MustNewMethod("end", func(self py.Object, args py.Tuple) (py.Object, error) {
+		m := self.(*Match)
+		g, err := m.groupArg("end", args)
+		if err != nil {
+			return nil, err
+		}
+		_, end := m.span(g)
+		return py.Int(end), nil
+	}, 0, `end([group=0]) -> int.
+
+Return index of the end of the substring matched by group.`)
+	MatchType.Dict["span"] = py.MustNewMethod("span", func(self py.Object, args py.Tuple) (py.Object, error) {
+		m := self.(*Match)
+		g, err := m.groupArg("span", args)
+		if err != nil {
+			return nil, err
+		}
+		start, end := m.span(g)
+		return py.Tuple{py.Int(start), py.Int(end)}, nil
+	}, 0, `span([group=0]) -> tuple.
+
+For match object m, return the 2-tuple (m.start(group), m.end(group)).`)
+	MatchType.Dict["expand"] = py.MustNewMethod("expand", func(self py.Object, arg py.Object) (py.Object, error) {
+		m := self.(*Match)
+		tmpl, err := m.pattern.parseTemplate(arg)
+		if err != nil {
+			return nil, err
+		}
+		s := tmpl.expand(m)
+		if m.pattern.isBytes {
+			return py.Bytes(s), nil
+		}
+		return py.String(s), nil
+	}, 0, `expand(template) -> str.
+
+Return the string obtained by doing backslash substitution
+on the string template, as done by the sub() method.`)
+	MatchType.Dict["string"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Match).subj.obj, nil
+		},
+	}
+	MatchType.Dict["re"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Match).pattern, nil
+		},
+	}
+	MatchType.Dict["pos"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*Match).pos), nil
+		},
+	}
+	MatchType.Dict["endpos"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*Match).endpos), nil
+		},
+	}
+	MatchType.Dict["lastindex"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			last := self.(*Match).lastIndex()
+			if last < 0 {
+				return py.None, nil
+			}
+			return py.Int(last), nil
+		},
+	}
+	MatchType.Dict["lastgroup"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			m := self.(*Match)
+			last := m.lastIndex()
+			if last < 0 || m.pattern.re.SubexpNames()[last] == "" {
+				return py.None, nil
+			}
+			return py.String(m.pattern.re.SubexpNames()[last]), nil
+		},
+	}
+}
+
+// A parsed replacement template for sub
+//
+// Each part is either literal text or a group reference.
+type template struct {
+	parts []templatePart
+}
+
+// A part of a template
+type templatePart struct {
+	literal string
+	group   int // -1 for literal text
+}
+
+// Escapes which stand for a single character in a template
+var templateEscapes = map[byte]byte{
+	'a':  '\a',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\\': '\\',
+}
+
+// Parses the replacement template repl
+func (p *Pattern) parseTemplate(repl py.Object) (*template, error) {
+	s, err := p.stringOf(repl, "expected %s instance, %s found")
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &template{}
+	var literal strings.Builder
+	addGroup := func(g int) {
+		if literal.Len() > 0 {
+			tmpl.parts = append(tmpl.parts, templatePart{literal: literal.String(), group: -1})
+			literal.Reset()
+		}
+		tmpl.parts = append(tmpl.parts, templatePart{group: g})
+	}
+	writeChar := func(value int) {
+		if p.isBytes {
+			literal.WriteByte(byte(value))
+		} else {
+			literal.WriteRune(rune(value))
+		}
+	}
+	checkGroup := func(g int, pos int) error {
+		if g > p.groups() {
+			return py.ExceptionNewf(Error, "invalid group reference %d at position %d", g, pos)
+		}
+		return nil
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			literal.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(s) {
+			return nil, py.ExceptionNewf(Error, "bad escape (end of pattern) at position %d", i)
+		}
+		c = s[i+1]
+		switch {
+		case c == 'g':
+			if i+2 >= len(s) || s[i+2] != '<' {
+				return nil, py.ExceptionNewf(Error, "missing < at position %d", i+2)
+			}
+			end := strings.IndexByte(s[i+3:], '>')
+			if end < 0 {
+				return nil, py.ExceptionNewf(Error, "missing >, unterminated name at position %d", i+3)
+			}
+			name := s[i+3 : i+3+end]
+			if name == "" {
+				return nil, py.ExceptionNewf(Error, "missing group name at position %d", i+3)
+			}
+			g, err := strconv.Atoi(name)
+			if err == nil {
+				err = checkGroup(g, i+3)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				g = -1
+				for j, groupName := range p.re.SubexpNames() {
+					if groupName != "" && groupName == name {
+						g = j
+					}
+				}
+				if g < 0 {
+					return nil, py.ExceptionNewf(py.IndexError, "unknown group name '%s'", name)
+				}
+			}
+			addGroup(g)
+			i += 3 + end
+		case c == '0':
+			// Octal escape of up to 3 digits
+			value, j := 0, i+1
+			for j < len(s) && j < i+4 && isOctal(s[j]) {
+				value = value*8 + int(s[j]-'0')
+				j++
+			}
+			writeChar(value)
+			i = j - 1
+		case c >= '1' && c <= '9':
+			if i+3 < len(s) && isOctal(c) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+				value := int(c-'0')*64 + int(s[i+2]-'0')*8 + int(s[i+3]-'0')
+				if value > 0377 {
+					return nil, py.ExceptionNewf(Error, "octal escape value \\%s outside of range 0-0o377 at position %d", s[i+1:i+4], i)
+				}
+				writeChar(value)
+				i += 3
+				break
+			}
+			g := int(c - '0')
+			j := i + 2
+			if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				g = g*10 + int(s[j]-'0')
+				j++
+			}
+			err := checkGroup(g, i+1)
+			if err != nil {
+				return nil, err
+			}
+			addGroup(g)
+			i = j - 1
+		case templateEscapes[c] != 0:
+			literal.WriteByte(templateEscapes[c])
+			i++
+		case isAlnum(c):
+			return nil, py.ExceptionNewf(Error, "bad escape \\%c at position %d", c, i)
+		default:
+			literal.WriteByte('\\')
+			literal.WriteByte(c)
+			i++
+		}
+	}
+	if literal.Len() > 0 {
+		tmpl.parts = append(tmpl.parts, templatePart{literal: literal.String(), group: -1})
+	}
+	return tmpl, nil
+}
+
+// Expands the template with the groups from m
+//
+// Groups which didn't match are replaced with an empty string.
+func (tmpl *template) expand(m *Match) string {
+	var out strings.Builder
+	for _, part := range tmpl.parts {
+		if part.group < 0 {
+			out.WriteString(part.literal)
+		} else if m.loc[2*part.group] >= 0 {
+			out.WriteString(m.subj.s[m.loc[2*part.group]:m.loc[2*part.group+1]])
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,835 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Regular expression module
+//
+// Patterns are translated into Go regular expression syntax and run
+// with the regexp package. This means that matching takes linear time
+// but constructs RE2 doesn't support, such as backreferences and
+// look-around assertions, raise error.
+
+package re
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// Error is raised for invalid regular expressions
+var Error = py.ExceptionType.NewType("error", "Exception raised for invalid regular expressions.", nil, nil)
+
+// PatternType is the type of compiled regular expressions
+var PatternType = py.NewType("Pattern", "Compiled regular expression object.")
+
+// Pattern is a compiled regular expression
+type Pattern struct {
+	pattern  py.Object // the source as str or bytes
+	flags    int
+	isBytes  bool
+	re       *regexp.Regexp
+	anchored *regexp.Regexp // for match
+	full     *regexp.Regexp // for fullmatch
+}
+
+// Type of this Pattern object
+func (p *Pattern) Type() *py.Type {
+	return PatternType
+}
+
+// Names of the flags in the order they are shown by repr
+var flagNames = []struct {
+	flag int
+	name string
+}{
+	{flagTemplate, "re.TEMPLATE"},
+	{flagIgnoreCase, "re.IGNORECASE"},
+	{flagLocale, "re.LOCALE"},
+	{flagMultiline, "re.MULTILINE"},
+	{flagDotAll, "re.DOTALL"},
+	{flagUnicode, "re.UNICODE"},
+	{flagVerbose, "re.VERBOSE"},
+	{flagASCII, "re.ASCII"},
+}
+
+func (p *Pattern) M__repr__() (py.Object, error) {
+	repr, err := py.ReprAsString(p.pattern)
+	if err != nil {
+		return nil, err
+	}
+	flags := p.flags
+	if !p.isBytes {
+		flags &^= flagUnicode
+	}
+	var names []string
+	for _, f := range flagNames {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	if len(names) == 0 {
+		return py.String("re.compile(" + repr + ")"), nil
+	}
+	return py.String("re.compile(" + repr + ", " + strings.Join(names, "|") + ")"), nil
+}
+
+func (p *Pattern) M__eq__(other py.Object) (py.Object, error) {
+	q, ok := other.(*Pattern)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if p.flags != q.flags || p.isBytes != q.isBytes {
+		return py.False, nil
+	}
+	return py.Eq(p.pattern, q.pattern)
+}
+
+func (p *Pattern) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := p.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.Not(eq)
+}
+
+// Patterns which have been compiled already
+var cache = struct {
+	sync.Mutex
+	patterns map[cacheKey]*Pattern
+}{
+	patterns: make(map[cacheKey]*Pattern),
+}
+
+// Key for the pattern cache
+type cacheKey struct {
+	pattern string
+	isBytes bool
+	flags   int
+}
+
+// Maximum number of patterns to keep in the cache
+const maxCache = 512
+
+// Compiles pattern with flags returning a cached version if possible
+func compile(pattern py.Object, flags int) (*Pattern, error) {
+	if p, ok := pattern.(*Pattern); ok {
+		if flags != 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "cannot process flags argument with a compiled pattern")
+		}
+		return p, nil
+	}
+	var key cacheKey
+	switch x := pattern.(type) {
+	case py.String:
+		key = cacheKey{pattern: string(x), flags: flags}
+	case py.Bytes:
+		key = cacheKey{pattern: string(x), isBytes: true, flags: flags}
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "first argument must be string or compiled pattern")
+	}
+	cache.Lock()
+	p, ok := cache.patterns[key]
+	cache.Unlock()
+	if ok {
+		return p, nil
+	}
+	p, err := newPattern(pattern, key.pattern, key.isBytes, flags)
+	if err != nil {
+		return nil, err
+	}
+	cache.Lock()
+	if len(cache.patterns) >= maxCache {
+		cache.patterns = make(map[cacheKey]*Pattern)
+	}
+	cache.patterns[key] = p
+	cache.Unlock()
+	return p, nil
+}
+
+// Makes a new Pattern from src
+func newPattern(pattern py.Object, src string, isBytes bool, flags int) (*Pattern, error) {
+	if isBytes {
+		if flags&flagUnicode != 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "cannot use UNICODE flag with a bytes pattern")
+		}
+	} else {
+		if flags&flagLocale != 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "cannot use LOCALE flag with a str pattern")
+		}
+		if flags&flagASCII == 0 {
+			flags |= flagUnicode
+		} else if flags&flagUnicode != 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "ASCII and UNICODE flags are incompatible")
+		}
+	}
+	if flags&flagLocale != 0 {
+		return nil, py.ExceptionNewf(Error, "the LOCALE flag is not supported")
+	}
+	goSrc, flags, err := translate(src, flags)
+	if err != nil {
+		return nil, py.ExceptionNewf(Error, "%s", err.Error())
+	}
+	if !isBytes && flags&flagASCII != 0 {
+		flags &^= flagUnicode
+	}
+	p := &Pattern{
+		pattern: pattern,
+		flags:   flags,
+		isBytes: isBytes,
+	}
+	for _, x := range []struct {
+		re  **regexp.Regexp
+		src string
+	}{
+		{&p.re, goSrc},
+		{&p.anchored, `\A(?:` + goSrc + `)`},
+		{&p.full, `\A(?:` + goSrc + `)\z`},
+	} {
+		*x.re, err = regexp.Compile(x.src)
+		if err != nil {
+			return nil, py.ExceptionNewf(Error, "%s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+		}
+	}
+	return p, nil
+}
+
+// The string being matched and its python indexes
+type subject struct {
+	obj     py.Object
+	s       string
+	isBytes bool
+	offsets []int // byte offset of each character or nil if they are the same
+}
+
+// Makes the subject for matching o against p
+func (p *Pattern) newSubject(o py.Object) (*subject, error) {
+	switch x := o.(type) {
+	case py.String:
+		if p.isBytes {
+			return nil, py.ExceptionNewf(py.TypeError, "cannot use a bytes pattern on a string-like object")
+		}
+		subj := &subject{obj: o, s: string(x)}
+		if utf8.RuneCountInString(subj.s) != len(subj.s) {
+			subj.offsets = make([]int, 0, len(subj.s)+1)
+			for i := range subj.s {
+				subj.offsets = append(subj.offsets, i)
+			}
+			subj.offsets = append(subj.offsets, len(subj.s))
+		}
+		return subj, nil
+	case py.Bytes:
+		if !p.isBytes {
+			return nil, py.ExceptionNewf(py.TypeError, "cannot use a string pattern on a bytes-like object")
+		}
+		return &subject{obj: o, s: string(x), isBytes: true}, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "expected string or bytes-like object")
+}
+
+// Returns the number of characters in the subject
+func (subj *subject) len() int {
+	if subj.offsets != nil {
+		return len(subj.offsets) - 1
+	}
+	return len(subj.s)
+}
+
+// Converts the python index i into a byte offset
+func (subj *subject) byteOffset(i int) int {
+	if subj.offsets != nil {
+		return subj.offsets[i]
+	}
+	return i
+}
+
+// Converts the byte offset b into a python index
+func (subj *subject) index(b int) int {
+	if subj.offsets != nil {
+		return sort.SearchInts(subj.offsets, b)
+	}
+	return b
+}
+
+// Returns the part of the subject between byte offsets start and end
+func (subj *subject) slice(start, end int) py.Object {
+	if subj.isBytes {
+		return py.Bytes(subj.s[start:end])
+	}
+	return py.String(subj.s[start:end])
+}
+
+// Returns an empty string of the subject type
+func (subj *subject) empty() py.Object {
+	return subj.slice(0, 0)
+}
+
+// Clips the python pos and endpos to the subject returning them
+func (subj *subject) bounds(posObj, endposObj py.Object) (pos, endpos int, err error) {
+	n := subj.len()
+	pos, endpos = 0, n
+	if posObj != nil {
+		pos, err = py.IndexInt(posObj)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if endposObj != nil {
+		endpos, err = py.IndexInt(endposObj)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	} else if pos > n {
+		pos = n
+	}
+	if endpos > n {
+		endpos = n
+	} else if endpos < pos {
+		endpos = pos
+	}
+	return pos, endpos, nil
+}
+
+// Runs re on the subject within the python indexes pos and endpos
+// returning a Match or None
+func (p *Pattern) find(re *regexp.Regexp, subj *subject, pos, endpos int) py.Object {
+	start, end := subj.byteOffset(pos), subj.byteOffset(endpos)
+	loc := re.FindStringSubmatchIndex(subj.s[start:end])
+	if loc == nil {
+		return py.None
+	}
+	return p.newMatch(subj, offsetIndexes(loc, start), pos, endpos)
+}
+
+// Runs the pattern over the subject within the python indexes pos and
+// endpos returning all the non-overlapping matches
+func (p *Pattern) findAll(subj *subject, pos, endpos int) [][]int {
+	start, end := subj.byteOffset(pos), subj.byteOffset(endpos)
+	locs := p.re.FindAllStringSubmatchIndex(subj.s[start:end], -1)
+	for _, loc := range locs {
+		offsetIndexes(loc, start)
+	}
+	return locs
+}
+
+// Adds offset to all the matched indexes in loc returning it
+func offsetIndexes(loc []int, offset int) []int {
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += offset
+		}
+	}
+	return loc
+}
+
+// Returns the number of groups in the pattern
+func (p *Pattern) groups() int {
+	return p.re.NumSubexp()
+}
+
+// Returns a dictionary mapping group names to group numbers
+func (p *Pattern) groupIndex() py.StringDict {
+	d := py.NewStringDict()
+	for i, name := range p.re.SubexpNames() {
+		if name != "" {
+			d[name] = py.Int(i)
+		}
+	}
+	return d
+}
+
+// Parses the arguments (string, pos=None, endpos=None) for a search
+// function called name
+func (p *Pattern) parseSearchArgs(name string, args py.Tuple, kwargs py.StringDict) (subj *subject, pos, endpos int, err error) {
+	var stringObj, posObj, endposObj py.Object
+	err = py.ParseTupleAndKeywords(args, kwargs, "O|OO:"+name, []string{"string", "pos", "endpos"}, &stringObj, &posObj, &endposObj)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	subj, err = p.newSubject(stringObj)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	pos, endpos, err = subj.bounds(posObj, endposObj)
+	return subj, pos, endpos, err
+}
+
+// Makes a method of Pattern which runs the regexp chosen by which
+func searchMethod(name string, which func(p *Pattern) *regexp.Regexp) func(py.Object, py.Tuple, py.StringDict) (py.Object, error) {
+	return func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		p := self.(*Pattern)
+		subj, pos, endpos, err := p.parseSearchArgs(name, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return p.find(which(p), subj, pos, endpos), nil
+	}
+}
+
+// Returns the value of each group in loc for findall
+func (p *Pattern) findallItem(subj *subject, loc []int) py.Object {
+	group := func(i int) py.Object {
+		if loc[2*i] < 0 {
+			return subj.empty()
+		}
+		return subj.slice(loc[2*i], loc[2*i+1])
+	}
+	switch n := p.groups(); n {
+	case 0:
+		return group(0)
+	case 1:
+		return group(1)
+	default:
+		items := make(py.Tuple, n)
+		for i := range items {
+			items[i] = group(i + 1)
+		}
+		return items
+	}
+}
+
+// Returns the matches of the pattern in the subject as a list
+func (p *Pattern) findall(subj *subject, pos, endpos int) py.Object {
+	locs := p.findAll(subj, pos, endpos)
+	items := make([]py.Object, len(locs))
+	for i, loc := range locs {
+		items[i] = p.findallItem(subj, loc)
+	}
+	return py.NewListFromItems(items)
+}
+
+// Returns an iterator over the Match objects in the subject
+func (p *Pattern) finditer(subj *subject, pos, endpos int) py.Object {
+	locs := p.findAll(subj, pos, endpos)
+	items := make([]py.Object, len(locs))
+	for i, loc := range locs {
+		items[i] = p.newMatch(subj, loc, pos, endpos)
+	}
+	return py.NewIterator(items)
+}
+
+// Splits the subject by the occurrences of the pattern
+//
+// Empty matches are never split on.
+func (p *Pattern) split(subj *subject, maxsplit int) py.Object {
+	var items []py.Object
+	last := 0
+	n := 0
+	for _, loc := range p.findAll(subj, 0, subj.len()) {
+		if maxsplit > 0 && n >= maxsplit {
+			break
+		}
+		if loc[0] == loc[1] {
+			continue
+		}
+		items = append(items, subj.slice(last, loc[0]))
+		for i := 1; i <= p.groups(); i++ {
+			if loc[2*i] < 0 {
+				items = append(items, py.None)
+			} else {
+				items = append(items, subj.slice(loc[2*i], loc[2*i+1]))
+			}
+		}
+		last = loc[1]
+		n++
+	}
+	items = append(items, subj.slice(last, len(subj.s)))
+	return py.NewListFromItems(items)
+}
+
+// Replaces the first count occurrences of the pattern in the subject
+// with repl returning the new string and the number of replacements
+func (p *Pattern) subn(repl py.Object, subj *subject, count int) (py.Object, int, error) {
+	var tmpl *template
+	if _, ok := repl.(py.I__call__); !ok {
+		var err error
+		tmpl, err = p.parseTemplate(repl)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	var out strings.Builder
+	last := 0
+	n := 0
+	for _, loc := range p.findAll(subj, 0, subj.len()) {
+		if count > 0 && n >= count {
+			break
+		}
+		out.WriteString(subj.s[last:loc[0]])
+		m := p.newMatch(subj, loc, 0, subj.len())
+		if tmpl != nil {
+			out.WriteString(tmpl.expand(m))
+		} else {
+			res, err := py.Call(repl, py.Tuple{m}, nil)
+			if err != nil {
+				return nil, 0, err
+			}
+			s, err := p.stringOf(res, "expected %s instance, %s found")
+			if err != nil {
+				return nil, 0, err
+			}
+			out.WriteString(s)
+		}
+		last = loc[1]
+		n++
+	}
+	out.WriteString(subj.s[last:])
+	if subj.isBytes {
+		return py.Bytes(out.String()), n, nil
+	}
+	return py.String(out.String()), n, nil
+}
+
+// Returns the contents of o which must be the same type as the
+// pattern. format is used for the error with the expected and actual
+// type names.
+func (p *Pattern) stringOf(o py.Object, format string) (string, error) {
+	switch x := o.(type) {
+	case py.String:
+		if !p.isBytes {
+			return string(x), nil
+		}
+	case py.Bytes:
+		if p.isBytes {
+			return string(x), nil
+		}
+	}
+	want := "str"
+	if p.isBytes {
+		want = "bytes"
+	}
+	return "", py.ExceptionNewf(py.TypeError, format, want, o.Type().Name)
+}
+
+// Parses the arguments (repl, string, count=0) for sub or subn
+func (p *Pattern) subnArgs(name string, args py.Tuple, kwargs py.StringDict) (py.Object, int, error) {
+	var repl, stringObj py.Object
+	var countObj py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:"+name, []string{"repl", "string", "count"}, &repl, &stringObj, &countObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := py.IndexInt(countObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	subj, err := p.newSubject(stringObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p.subn(repl, subj, count)
+}
+
+func init() {
+	PatternType.Dict["search"] = py.MustNewMethod("search", searchMethod("search", func(p *Pattern) *regexp.Regexp { return p.re }), 0, `search(string[, pos[, endpos]]) --> Match object or None.
+
+Scan through string looking for a match, and return a corresponding
+match object instance. Return None if no position in the string matches.`)
+	PatternType.Dict["match"] = py.MustNewMethod("match", searchMethod("match", func(p *Pattern) *regexp.Regexp { return p.anchored }), 0, `match(string[, pos[, endpos]]) --> Match object or None.
+
+Matches zero or more characters at the beginning of the string.`)
+	PatternType.Dict["fullmatch"] = py.MustNewMethod("fullmatch", searchMethod("fullmatch", func(p *Pattern) *regexp.Regexp { return p.full }), 0, `fullmatch(string[, pos[, endpos]]) --> Match object or None.
+
+Matches against all of the string.`)
+	PatternType.Dict["findall"] = py.MustNewMethod("findall", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		p := self.(*Pattern)
+		subj, pos, endpos, err := p.parseSearchArgs("findall", args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return p.findall(subj, pos, endpos), nil
+	}, 0, `findall(string[, pos[, endpos]]) --> list.
+
+Return a list of all non-overlapping matches of pattern in string.`)
+	PatternType.Dict["finditer"] = py.MustNewMethod("finditer", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		p := self.(*Pattern)
+		subj, pos, endpos, err := p.parseSearchArgs("finditer", args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return p.finditer(subj, pos, endpos), nil
+	}, 0, `finditer(string[, pos[, endpos]]) --> iterator.
+
+Return an iterator over all non-overlapping matches for the RE pattern
+in string. For each match, the iterator returns a match object.`)
+	PatternType.Dict["split"] = py.MustNewMethod("split", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		p := self.(*Pattern)
+		var stringObj py.Object
+		var maxsplitObj py.Object = py.Int(0)
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:split", []string{"string", "maxsplit"}, &stringObj, &maxsplitObj)
+		if err != nil {
+			return nil, err
+		}
+		maxsplit, err := py.IndexInt(maxsplitObj)
+		if err != nil {
+			return nil, err
+		}
+		subj, err := p.newSubject(stringObj)
+		if err != nil {
+			return nil, err
+		}
+		return p.split(subj, maxsplit), nil
+	}, 0, `split(string[, maxsplit = 0])  --> list.
+
+Split string by the occurrences of pattern.`)
+	PatternType.Dict["sub"] = py.MustNewMethod("sub", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		res, _, err := self.(*Pattern).subnArgs("sub", args, kwargs)
+		return res, err
+	}, 0, `sub(repl, string[, count = 0]) --> newstring.
+
+Return the string obtained by replacing the leftmost non-overlapping
+occurrences of pattern in string by the replacement repl.`)
+	PatternType.Dict["subn"] = py.MustNewMethod("subn", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		res, n, err := self.(*Pattern).subnArgs("subn", args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{res, py.Int(n)}, nil
+	}, 0, `subn(repl, string[, count = 0]) --> (newstring, number of subs)
+
+Return the tuple (new_string, number_of_subs_made) found by replacing
+the leftmost non-overlapping occurrences of pattern with the
+replacement repl.`)
+	PatternType.Dict["pattern"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Pattern).pattern, nil
+		},
+	}
+	PatternType.Dict["flags"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*Pattern).flags), nil
+		},
+	}
+	PatternType.Dict["groups"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*Pattern).groups()), nil
+		},
+	}
+	PatternType.Dict["groupindex"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Pattern).groupIndex(), nil
+		},
+	}
+}
+
+// Parses the arguments for a module level function called name which
+// takes a pattern followed by the arguments in kwlist and an optional
+// flags argument, returning the compiled pattern and the other
+// arguments. The arguments from the first optional one may be nil.
+func parseModuleArgs(name string, args py.Tuple, kwargs py.StringDict, kwlist []string, optional int) (*Pattern, py.Tuple, error) {
+	kwlist = append(append([]string{"pattern"}, kwlist...), "flags")
+	results := make([]py.Object, len(kwlist))
+	ptrs := make([]*py.Object, len(kwlist))
+	for i := range results {
+		ptrs[i] = &results[i]
+	}
+	format := strings.Repeat("O", 1+optional) + "|" + strings.Repeat("O", len(kwlist)-1-optional) + ":" + name
+	err := py.ParseTupleAndKeywords(args, kwargs, format, kwlist, ptrs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	flags := 0
+	if flagsObj := results[len(results)-1]; flagsObj != nil {
+		flags, err = py.IndexInt(flagsObj)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	p, err := compile(results[0], flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, py.Tuple(results[1 : len(results)-1]), nil
+}
+
+const re_compile_doc = `compile(pattern, flags=0) -> Pattern
+
+Compile a regular expression pattern, returning a Pattern object.`
+
+func re_compile(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	p, _, err := parseModuleArgs("compile", args, kwargs, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Makes a module level function which calls the Pattern method called
+// name after compiling the pattern
+func moduleFunction(name string, kwlist []string, optional int) func(py.Object, py.Tuple, py.StringDict) (py.Object, error) {
+	return func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		p, rest, err := parseModuleArgs(name, args, kwargs, kwlist, optional)
+		if err != nil {
+			return nil, err
+		}
+		for len(rest) > 0 && rest[len(rest)-1] == nil {
+			rest = rest[:len(rest)-1]
+		}
+		method, err := py.GetAttrString(p, name)
+		if err != nil {
+			return nil, err
+		}
+		return py.Call(method, rest, nil)
+	}
+}
+
+const re_search_doc = `search(pattern, string, flags=0) -> Match or None
+
+Scan through string looking for a match to the pattern, returning a
+Match object, or None if no match was found.`
+
+const re_match_doc = `match(pattern, string, flags=0) -> Match or None
+
+Try to apply the pattern at the start of the string, returning a Match
+object, or None if no match was found.`
+
+const re_fullmatch_doc = `fullmatch(pattern, string, flags=0) -> Match or None
+
+Try to apply the pattern to all of the string, returning a Match
+object, or None if no match was found.`
+
+const re_findall_doc = `findall(pattern, string, flags=0) -> list
+
+Return a list of all non-overlapping matches in the string.
+
+If one or more capturing groups are present in the pattern, return a
+list of groups; this will be a list of tuples if the pattern has more
+than one group.
+
+Empty matches are included in the result.`
+
+const re_finditer_doc = `finditer(pattern, string, flags=0) -> iterator
+
+Return an iterator over all non-overlapping matches in the string. For
+each match, the iterator returns a Match object.
+
+Empty matches are included in the result.`
+
+const re_split_doc = `split(pattern, string, maxsplit=0, flags=0) -> list
+
+Split the source string by the occurrences of the pattern, returning a
+list containing the resulting substrings. If capturing parentheses are
+used in pattern, then the text of all groups in the pattern are also
+returned as part of the resulting list. If maxsplit is nonzero, at most
+maxsplit splits occur, and the remainder of the string is returned as
+the final element of the list.`
+
+const re_sub_doc = `sub(pattern, repl, string, count=0, flags=0) -> str
+
+Return the string obtained by replacing the leftmost non-overlapping
+occurrences of the pattern in string by the replacement repl. repl can
+be either a string or a callable; if a string, backslash escapes in it
+are processed. If it is a callable, it's passed the Match object and
+must return a replacement string to be used.`
+
+const re_subn_doc = `subn(pattern, repl, string, count=0, flags=0) -> (str, int)
+
+Return a 2-tuple containing (new_string, number). new_string is the
+string obtained by replacing the leftmost non-overlapping occurrences
+of the pattern in the source string by the replacement repl. number is
+the number of substitutions that were made. repl can be either a string
+or a callable; if a string, backslash escapes in it are processed. If
+it is a callable, it's passed the Match object and must return a
+replacement string to be used.`
+
+// Characters which escape puts a backslash in front of
+const specialChars = "()[]{}?*+-|^$\\.&~# \t\n\r\v\f"
+
+const re_escape_doc = `escape(pattern) -> str
+
+Escape special characters in a string.`
+
+func re_escape(self py.Object, arg py.Object) (py.Object, error) {
+	var s string
+	switch x := arg.(type) {
+	case py.String:
+		s = string(x)
+	case py.Bytes:
+		s = string(x)
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "expected str or bytes, not %s", arg.Type().Name)
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(specialChars, s[i]) >= 0 {
+			out.WriteByte('\\')
+		}
+		out.WriteByte(s[i])
+	}
+	if _, ok := arg.(py.Bytes); ok {
+		return py.Bytes(out.String()), nil
+	}
+	return py.String(out.String()), nil
+}
+
+const re_purge_doc = `purge()
+
+Clear the regular expression cache.`
+
+func re_purge(self py.Object) (py.Object, error) {
+	cache.Lock()
+	cache.patterns = make(map[cacheKey]*Pattern)
+	cache.Unlock()
+	return py.None, nil
+}
+
+const re_doc = `Support for regular expressions (RE).
+
+Patterns are run by Go's regexp package which guarantees matching in
+time linear in the size of the input. As a result backreferences,
+look-ahead and look-behind assertions and conditional groups are not
+supported and raise error when compiled.
+
+Non ASCII characters in bytes patterns are matched as unicode
+characters rather than bytes.`
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("compile", re_compile, 0, re_compile_doc),
+		py.MustNewMethod("escape", re_escape, 0, re_escape_doc),
+		py.MustNewMethod("findall", moduleFunction("findall", []string{"string"}, 1), 0, re_findall_doc),
+		py.MustNewMethod("finditer", moduleFunction("finditer", []string{"string"}, 1), 0, re_finditer_doc),
+		py.MustNewMethod("fullmatch", moduleFunction("fullmatch", []string{"string"}, 1), 0, re_fullmatch_doc),
+		py.MustNewMethod("match", moduleFunction("match", []string{"string"}, 1), 0, re_match_doc),
+		py.MustNewMethod("purge", re_purge, 0, re_purge_doc),
+		py.MustNewMethod("search", moduleFunction("search", []string{"string"}, 1), 0, re_search_doc),
+		py.MustNewMethod("split", moduleFunction("split", []string{"string", "maxsplit"}, 1), 0, re_split_doc),
+		py.MustNewMethod("sub", moduleFunction("sub", []string{"repl", "string", "count"}, 2), 0, re_sub_doc),
+		py.MustNewMethod("subn", moduleFunction("subn", []string{"repl", "string", "count"}, 2), 0, re_subn_doc),
+	}
+	globals := py.StringDict{
+		"error":      Error,
+		"Pattern":    PatternType,
+		"Match":      MatchType,
+		"TEMPLATE":   py.Int(flagTemplate),
+		"T":          py.Int(flagTemplate),
+		"IGNORECASE": py.Int(flagIgnoreCase),
+		"I":          py.Int(flagIgnoreCase),
+		"LOCALE":     py.Int(flagLocale),
+		"L":          py.Int(flagLocale),
+		"MULTILINE":  py.Int(flagMultiline),
+		"M":          py.Int(flagMultiline),
+		"DOTALL":     py.Int(flagDotAll),
+		"S":          py.Int(flagDotAll),
+		"UNICODE":    py.Int(flagUnicode),
+		"U":          py.Int(flagUnicode),
+		"VERBOSE":    py.Int(flagVerbose),
+		"X":          py.Int(flagVerbose),
+		"ASCII":      py.Int(flagASCII),
+		"A":          py.Int(flagASCII),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "re",
+		Doc:     re_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
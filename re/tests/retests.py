@@ -0,0 +1,272 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import re
+from libtest import *
+
+doc="search"
+m = re.search(r"b+", "abbbc")
+assertEqual(m.group(), "bbb")
+assertEqual(m.span(), (1, 4))
+assertEqual(m.start(), 1)
+assertEqual(m.end(), 4)
+assertEqual(re.search("x", "abc"), None)
+
+doc="match"
+assertEqual(re.match("b", "abc"), None)
+assertEqual(re.match("a", "abc").group(0), "a")
+assertEqual(re.match("a|ab", "abc").group(), "a")
+
+doc="fullmatch"
+assertEqual(re.fullmatch("a.c", "abc").group(), "abc")
+assertEqual(re.fullmatch("a.", "abc"), None)
+assertEqual(re.fullmatch("a|ab", "ab").group(), "ab")
+
+doc="groups"
+m = re.match(r"(\w+) (\w+)(x)?", "hello world")
+assertEqual(m.group(1), "hello")
+assertEqual(m.group(2), "world")
+assertEqual(m.group(1, 2), ("hello", "world"))
+assertEqual(m.group(3), None)
+assertEqual(m.groups(), ("hello", "world", None))
+assertEqual(m.groups("-"), ("hello", "world", "-"))
+assertEqual(m[0], "hello world")
+assertEqual(m[2], "world")
+assertEqual(m.span(2), (6, 11))
+assertEqual(m.span(3), (-1, -1))
+assertEqual(m.start(3), -1)
+assertEqual(m.lastindex, 2)
+assertEqual(m.lastgroup, None)
+assertRaisesText(IndexError, "no such group", m.group, 4)
+assertRaisesText(IndexError, "no such group", m.group, "nope")
+
+doc="named groups"
+m = re.search(r"(?P<key>\w+)=(?P<value>\w*)", "  a=b  ")
+assertEqual(m.group("key"), "a")
+assertEqual(m["value"], "b")
+assertEqual(m.groupdict(), {"key": "a", "value": "b"})
+assertEqual(m.lastgroup, "value")
+p = re.compile(r"(?P<first>a)(b)(?P<third>c)?")
+assertEqual(p.groups, 3)
+assertEqual(p.groupindex, {"first": 1, "third": 3})
+assertEqual(p.match("ab").groupdict(), {"first": "a", "third": None})
+assertEqual(p.match("ab").groupdict(0), {"first": "a", "third": 0})
+
+doc="lastindex"
+assertEqual(re.match("(a)b", "ab").lastindex, 1)
+assertEqual(re.match("((a)b)", "ab").lastindex, 1)
+assertEqual(re.match("(a)(b)", "ab").lastindex, 2)
+assertEqual(re.match("a", "a").lastindex, None)
+
+doc="match attributes"
+p = re.compile("b")
+m = p.search("abc", 1, 2)
+assertEqual(m.string, "abc")
+assertEqual(m.re, p)
+assertEqual(m.pos, 1)
+assertEqual(m.endpos, 2)
+assertEqual(repr(m), "<re.Match object; span=(1, 2), match='b'>")
+assertTrue(m)
+
+doc="pos and endpos"
+p = re.compile("a")
+assertEqual(p.search("aaa", 1).span(), (1, 2))
+assertEqual(p.search("aaa", 3), None)
+assertEqual(p.search("baa", 0, 1), None)
+assertEqual(p.match("ba", 1).span(), (1, 2))
+assertEqual(p.findall("aaaa", 1, 3), ["a", "a"])
+assertEqual(p.search("aaa", -5).span(), (0, 1))
+assertEqual(p.search("aaa", 1, 100).span(), (1, 2))
+assertEqual(re.compile("a+").fullmatch("baaab", 1, 4).group(), "aaa")
+
+doc="unicode indexes"
+m = re.search("b+", "\xe9€bb\xe9")
+assertEqual(m.span(), (2, 4))
+assertEqual(m.group(), "bb")
+assertEqual(re.compile(".").search("\xe9€x", 1).group(), "€")
+assertEqual([m.start() for m in re.finditer("x", "\xe9x\xe9\xe9x")], [1, 4])
+
+doc="unicode classes"
+assertEqual(re.findall(r"\w+", "caf\xe9 na\xefve"), ["caf\xe9", "na\xefve"])
+assertEqual(re.findall(r"[\w]+", "caf\xe9!"), ["caf\xe9"])
+assertEqual(re.findall(r"\d", "1٣"), ["1", "٣"])
+assertEqual(re.findall(r"\W", "a\xe9!"), ["!"])
+assertEqual(re.split(r"\s+", "a　b c"), ["a", "b", "c"])
+assertEqual(re.findall(r"\w+", "caf\xe9", re.ASCII), ["caf"])
+assertEqual(re.findall(r"(?a)\w+", "caf\xe9"), ["caf"])
+
+doc="findall"
+assertEqual(re.findall(r"\d+", "a1b22c333"), ["1", "22", "333"])
+assertEqual(re.findall(r"(\w)=(\d)", "a=1 b=2"), [("a", "1"), ("b", "2")])
+assertEqual(re.findall(r"(\w)=\d", "a=1 b=2"), ["a", "b"])
+assertEqual(re.findall(r"(a)|b", "ab"), ["a", ""])
+assertEqual(re.findall("x*", "ab"), ["", "", ""])
+assertEqual(re.findall("x", "ab"), [])
+
+doc="finditer"
+it = re.finditer(r"\d", "a1b2")
+assertEqual([m.group() for m in it], ["1", "2"])
+assertEqual([m.span() for m in re.compile("o").finditer("foo")], [(1, 2), (2, 3)])
+
+doc="split"
+assertEqual(re.split(",", "a,b,,c"), ["a", "b", "", "c"])
+assertEqual(re.split(r"\W+", "Words, words, words."), ["Words", "words", "words", ""])
+assertEqual(re.split(r"(\W+)", "Words, words."), ["Words", ", ", "words", ".", ""])
+assertEqual(re.split(r"\W+", "Words, words, words.", 1), ["Words", "words, words."])
+assertEqual(re.split(r"\W+", "Words, words, words.", maxsplit=2), ["Words", "words", "words."])
+assertEqual(re.split("[a-f]+", "0a3B9", flags=re.IGNORECASE), ["0", "3", "9"])
+assertEqual(re.split("(a)|b", "1a2b3"), ["1", "a", "2", None, "3"])
+assertEqual(re.split("x", ""), [""])
+assertEqual(re.compile(",").split("a,b", maxsplit=0), ["a", "b"])
+
+doc="sub"
+assertEqual(re.sub("a", "b", "aaa"), "bbb")
+assertEqual(re.sub("a", "b", "aaa", 2), "bba")
+assertEqual(re.sub("a", "b", "aaa", count=1), "baa")
+assertEqual(re.sub(r"(\w+) (\w+)", r"\2 \1", "hello world"), "world hello")
+assertEqual(re.sub(r"(?P<x>\w+)", r"<\g<x>>", "a b"), "<a> <b>")
+assertEqual(re.sub(r"(\w+)", r"\g<1>0", "a"), "a0")
+assertEqual(re.sub(r"(\w+)", r"\g<0>!", "a"), "a!")
+assertEqual(re.sub("a", r"\n\t\\", "a"), "\n\t\\")
+assertEqual(re.sub("a", r"\-", "a"), "\\-")
+assertEqual(re.sub("a", r"\101\0", "a"), "A\0")
+assertEqual(re.sub("(a)|b", r"[\1]", "ab"), "[a][]")
+assertEqual(re.sub("x*", "-", "abc"), "-a-b-c-")
+assertEqual(re.sub("", "-", "ab"), "-a-b-")
+assertEqual(re.sub(r"\d+", lambda m: str(int(m.group()) * 2), "a1b22"), "a2b44")
+assertEqual(re.sub("A", "b", "aA", flags=re.I), "bb")
+assertEqual(re.sub("\xe9", "e", "caf\xe9 \xe9"), "cafe e")
+
+doc="sub errors"
+assertRaisesText(re.error, "invalid group reference 2", re.sub, "(a)", r"\2", "a")
+assertRaisesText(re.error, "invalid group reference 2", re.sub, "(a)", r"\g<2>", "a")
+assertRaisesText(re.error, "bad escape \\q", re.sub, "a", r"\q", "a")
+assertRaisesText(re.error, "missing <", re.sub, "a", r"\g", "a")
+assertRaisesText(IndexError, "unknown group name 'x'", re.sub, "a", r"\g<x>", "a")
+assertRaises(TypeError, re.sub, "a", lambda m: 1, "a")
+assertRaises(TypeError, re.sub, "a", 1, "a")
+
+doc="subn"
+assertEqual(re.subn("a", "b", "aaa"), ("bbb", 3))
+assertEqual(re.subn("a", "b", "aaa", 2), ("bba", 2))
+assertEqual(re.compile("x").subn("y", "abc"), ("abc", 0))
+
+doc="expand"
+m = re.match(r"(\w+) (?P<second>\w+)", "hello world")
+assertEqual(m.expand(r"\2-\1-\g<second>"), "world-hello-world")
+
+doc="flags"
+assertEqual(re.I, re.IGNORECASE)
+assertEqual(re.IGNORECASE, 2)
+assertEqual(re.MULTILINE, 8)
+assertEqual(re.DOTALL, 16)
+assertEqual(re.UNICODE, 32)
+assertEqual(re.VERBOSE, 64)
+assertEqual(re.ASCII, 256)
+assertEqual(re.findall("^a", "a\na", re.M), ["a", "a"])
+assertEqual(re.findall("^a", "a\na"), ["a"])
+assertEqual(re.match("a.b", "a\nb"), None)
+assertEqual(re.match("a.b", "a\nb", re.S).group(), "a\nb")
+assertEqual(re.match("A", "a", re.I | re.M).group(), "a")
+assertEqual(re.compile("a").flags, re.UNICODE)
+assertEqual(re.compile("a", re.I).flags, re.I | re.UNICODE)
+assertEqual(re.compile("a", re.A).flags, re.A)
+assertEqual(re.compile(b"a").flags, 0)
+assertEqual(re.compile("(?i)a").flags, re.I | re.UNICODE)
+assertEqual(re.match("(?i)A", "a").group(), "a")
+assertEqual(re.match("(?s).", "\n").group(), "\n")
+assertEqual(re.match("a(?i:B)c", "abc").group(), "abc")
+assertEqual(re.match("a(?i:B)c", "abC"), None)
+
+doc="verbose"
+p = re.compile(r"""
+    (\d+)   # the number
+    \ +     # escaped spaces
+    [ ]x    # a space in a class
+""", re.X)
+assertEqual(p.match("12  x").groups(), ("12",))
+assertEqual(re.match(r"(?x) a b # c", "ab").group(), "ab")
+assertEqual(p.flags, re.X | re.U)
+
+doc="syntax translations"
+assertEqual(re.match(r"a{,2}", "aaa").group(), "aa")
+assertEqual(re.match(r"a{1,}", "aaa").group(), "aaa")
+assertEqual(re.match(r"a{", "a{").group(), "a{")
+assertEqual(re.search(r"a\Z", "ba").span(), (1, 2))
+assertEqual(re.search(r"a\Z", "a\n"), None)
+assertEqual(re.match(r"é\U0001F600", "\xe9\U0001f600").span(), (0, 2))
+assertEqual(re.match(r"\x41\101\0", "AA\0").group(), "AA\0")
+assertEqual(re.match(r"[\b]", "\b").group(), "\b")
+assertEqual(re.match(r"[]a]+", "a]").group(), "a]")
+assertEqual(re.match(r"[^]a]+", "bc]").group(), "bc")
+assertEqual(re.match(r"[[a]+", "[a").group(), "[a")
+assertEqual(re.match(r"a(?#comment)b", "ab").group(), "ab")
+assertEqual(re.match(r"\%\ \-", "% -").group(), "% -")
+assertEqual(re.match(r"(?:ab)+", "abab").group(), "abab")
+assertEqual(re.match(r"a*?", "aaa").group(), "")
+
+doc="unsupported syntax"
+assertRaisesText(re.error, "backreferences are not supported", re.compile, r"(a)\1")
+assertRaisesText(re.error, "backreferences are not supported", re.compile, r"(?P<a>x)(?P=a)")
+assertRaisesText(re.error, "look-ahead and look-behind assertions are not supported", re.compile, r"a(?=b)")
+assertRaisesText(re.error, "look-ahead and look-behind assertions are not supported", re.compile, r"a(?!b)")
+assertRaisesText(re.error, "look-ahead and look-behind assertions are not supported", re.compile, r"(?<=a)b")
+assertRaisesText(re.error, "look-ahead and look-behind assertions are not supported", re.compile, r"(?<!a)b")
+assertRaisesText(re.error, "conditional groups are not supported", re.compile, r"(a)?(?(1)b|c)")
+assertRaisesText(re.error, "at position 1", re.compile, r"a(?=b)")
+
+doc="errors"
+assertTrue(issubclass(re.error, Exception))
+assertRaises(re.error, re.compile, "(a")
+assertRaises(re.error, re.compile, "a)")
+assertRaises(re.error, re.compile, "*")
+assertRaisesText(re.error, "unterminated character set", re.compile, "[a")
+assertRaisesText(re.error, "bad escape (end of pattern)", re.compile, "a\\")
+assertRaisesText(re.error, "unknown extension ?<", re.compile, "(?<a>b)")
+assertRaisesText(ValueError, "cannot use LOCALE flag with a str pattern", re.compile, "a", re.L)
+assertRaisesText(ValueError, "cannot use UNICODE flag with a bytes pattern", re.compile, b"a", re.U)
+assertRaisesText(ValueError, "ASCII and UNICODE flags are incompatible", re.compile, "a", re.A | re.U)
+assertRaisesText(ValueError, "cannot process flags argument with a compiled pattern", re.compile, re.compile("a"), re.I)
+assertRaisesText(TypeError, "first argument must be string or compiled pattern", re.compile, 1)
+assertRaisesText(TypeError, "cannot use a string pattern on a bytes-like object", re.search, "a", b"a")
+assertRaisesText(TypeError, "cannot use a bytes pattern on a string-like object", re.search, b"a", "a")
+assertRaises(TypeError, re.search, "a", 1)
+
+doc="bytes"
+m = re.search(rb"(\d+)", b"ab12c")
+assertEqual(m.group(1), b"12")
+assertEqual(m.span(), (2, 4))
+assertEqual(re.findall(rb"\w", b"a-b"), [b"a", b"b"])
+assertEqual(re.sub(rb"a", rb"[\g<0>]", b"xay"), b"x[a]y")
+assertEqual(re.split(rb",", b"a,b"), [b"a", b"b"])
+assertEqual(re.findall(rb"(a)|b", b"ab"), [b"a", b""])
+
+doc="compile"
+p = re.compile(r"\d+")
+assertEqual(p.pattern, r"\d+")
+assertEqual(re.compile(p), p)
+assertEqual(re.search(p, "a12").group(), "12")
+assertEqual(p.findall("1 2"), ["1", "2"])
+assertEqual(p.sub("#", "a1b2"), "a#b#")
+assertEqual(p.split("a1b"), ["a", "b"])
+assertEqual(repr(p), "re.compile('\\\\d+')")
+assertEqual(repr(re.compile("a", re.I | re.M)), "re.compile('a', re.IGNORECASE|re.MULTILINE)")
+assertEqual(repr(re.compile(b"a")), "re.compile(b'a')")
+assertTrue(re.compile("a") == re.compile("a"))
+assertTrue(re.compile("a") != re.compile("a", re.I))
+assertEqual(repr(type(p)), "<class 'Pattern'>")
+assertEqual(repr(type(p.match("1"))), "<class 'Match'>")
+
+doc="escape"
+assertEqual(re.escape("a.b*c"), "a\\.b\\*c")
+assertEqual(re.escape("_x1 \xe9"), "_x1\\ \xe9")
+assertEqual(re.escape(b"a+"), b"a\\+")
+s = "[(.*+?{})]^$|\\-&~#"
+assertEqual(re.match(re.escape(s), s).group(), s)
+
+doc="purge"
+re.purge()
+assertEqual(re.match("a", "a").group(), "a")
+
+doc="finished"
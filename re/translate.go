@@ -0,0 +1,325 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Translation of python regular expressions into Go (RE2) syntax
+
+package re
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Regular expression flags
+const (
+	flagTemplate   = 1
+	flagIgnoreCase = 2
+	flagLocale     = 4
+	flagMultiline  = 8
+	flagDotAll     = 16
+	flagUnicode    = 32
+	flagVerbose    = 64
+	flagASCII      = 256
+)
+
+// Replacements for the character class escapes which match unicode
+// characters in python but only ASCII in Go. Each is given as it
+// appears outside a character class and inside one, an empty string
+// meaning it is left alone.
+var unicodeClasses = map[byte][2]string{
+	'd': {`\p{Nd}`, `\p{Nd}`},
+	'D': {`\P{Nd}`, ``},
+	'w': {`[\p{L}\p{N}_]`, `\p{L}\p{N}_`},
+	'W': {`[^\p{L}\p{N}_]`, ``},
+	's': {`[\s\v\p{Z}\x{1c}-\x{1f}\x{85}]`, `\s\v\p{Z}\x{1c}-\x{1f}\x{85}`},
+	'S': {`[^\s\v\p{Z}\x{1c}-\x{1f}\x{85}]`, ``},
+}
+
+// A translator turns a python regular expression into Go syntax
+type translator struct {
+	src   string
+	pos   int
+	flags int
+	out   strings.Builder
+}
+
+// Makes an error at the current position
+func (t *translator) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, a...), t.pos)
+}
+
+// Returns true if the flags say the pattern matches unicode
+func (t *translator) unicode() bool {
+	return t.flags&flagUnicode != 0 && t.flags&flagASCII == 0
+}
+
+// Translates the python regular expression src with flags into Go
+// syntax returning the new flags which may have been updated by
+// inline flags in the pattern.
+//
+// Constructs which RE2 can't match, such as backreferences and
+// look-around assertions, return an error.
+func translate(src string, flags int) (string, int, error) {
+	t := &translator{src: src, flags: flags}
+	inClass := false
+	classStart := 0
+	for t.pos < len(src) {
+		c := src[t.pos]
+		switch {
+		case c == '\\':
+			err := t.escape(inClass)
+			if err != nil {
+				return "", 0, err
+			}
+			continue
+		case inClass:
+			// A ] first in the class is a literal
+			if c == ']' && t.pos > classStart {
+				inClass = false
+			} else if c == '[' {
+				// Stop Go interpreting [: as a POSIX class
+				t.out.WriteString(`\[`)
+				t.pos++
+				continue
+			}
+		case c == '[':
+			inClass = true
+			t.out.WriteByte(c)
+			t.pos++
+			if t.pos < len(src) && src[t.pos] == '^' {
+				t.out.WriteByte('^')
+				t.pos++
+			}
+			classStart = t.pos
+			continue
+		case c == '(' && strings.HasPrefix(src[t.pos:], "(?"):
+			err := t.extension()
+			if err != nil {
+				return "", 0, err
+			}
+			continue
+		case c == '{':
+			// {,n} means {0,n}
+			if end := strings.IndexByte(src[t.pos:], '}'); end > 1 && src[t.pos+1] == ',' && isDigits(src[t.pos+2:t.pos+end]) {
+				t.out.WriteString("{0")
+				t.pos++
+				continue
+			}
+		case t.flags&flagVerbose != 0 && (c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'):
+			t.pos++
+			continue
+		case t.flags&flagVerbose != 0 && c == '#':
+			for t.pos < len(src) && src[t.pos] != '\n' {
+				t.pos++
+			}
+			continue
+		}
+		t.out.WriteByte(c)
+		t.pos++
+	}
+	if inClass {
+		t.pos = classStart
+		return "", 0, t.errorf("unterminated character set")
+	}
+	var prefix string
+	for _, f := range []struct {
+		flag int
+		c    string
+	}{
+		{flagIgnoreCase, "i"},
+		{flagMultiline, "m"},
+		{flagDotAll, "s"},
+	} {
+		if t.flags&f.flag != 0 {
+			prefix += f.c
+		}
+	}
+	if prefix != "" {
+		prefix = "(?" + prefix + ")"
+	}
+	return prefix + t.out.String(), t.flags, nil
+}
+
+// Returns true if s is non empty and only decimal digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Returns true if c is an ASCII letter or digit
+func isAlnum(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Returns true if c is an octal digit
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// Translates the escape sequence at the current position
+func (t *translator) escape(inClass bool) error {
+	src := t.src
+	if t.pos+1 >= len(src) {
+		return t.errorf("bad escape (end of pattern)")
+	}
+	c := src[t.pos+1]
+	switch {
+	case c == '0' || inClass && isOctal(c):
+		return t.octal()
+	case isOctal(c) && t.pos+3 < len(src) && isOctal(src[t.pos+2]) && isOctal(src[t.pos+3]):
+		return t.octal()
+	case c >= '1' && c <= '9':
+		return t.errorf("backreferences are not supported")
+	case c == 'Z':
+		t.out.WriteString(`\z`)
+	case c == 'b' && inClass:
+		t.out.WriteString(`\x08`)
+	case c == 'u' || c == 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		digits := src[t.pos+2:]
+		if len(digits) > n {
+			digits = digits[:n]
+		}
+		if len(digits) != n || !isHex(digits) {
+			return t.errorf("incomplete escape \\%c%s", c, digits)
+		}
+		fmt.Fprintf(&t.out, `\x{%s}`, digits)
+		t.pos += 2 + n
+		return nil
+	case unicodeClasses[c] != [2]string{} && t.unicode():
+		replacement := unicodeClasses[c][0]
+		if inClass {
+			replacement = unicodeClasses[c][1]
+		}
+		if replacement == "" {
+			replacement = src[t.pos : t.pos+2]
+		}
+		t.out.WriteString(replacement)
+	case c >= utf8.RuneSelf:
+		// Any other escaped character is a literal
+		r, size := utf8.DecodeRuneInString(src[t.pos+1:])
+		t.out.WriteString(regexp.QuoteMeta(string(r)))
+		t.pos += 1 + size
+		return nil
+	case isAlnum(c):
+		t.out.WriteString(src[t.pos : t.pos+2])
+	default:
+		t.out.WriteString(regexp.QuoteMeta(src[t.pos+1 : t.pos+2]))
+	}
+	t.pos += 2
+	return nil
+}
+
+// Translates the octal escape of up to 3 digits at the current position
+func (t *translator) octal() error {
+	end := t.pos + 1
+	value := 0
+	for end < len(t.src) && end < t.pos+4 && isOctal(t.src[end]) {
+		value = value*8 + int(t.src[end]-'0')
+		end++
+	}
+	if value > 0377 {
+		return t.errorf("octal escape value \\%s outside of range 0-0o377", t.src[t.pos+1:end])
+	}
+	fmt.Fprintf(&t.out, `\x{%x}`, value)
+	t.pos = end
+	return nil
+}
+
+// Returns true if s is only hex digits
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') && !(c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// Translates the (? extension at the current position
+func (t *translator) extension() error {
+	rest := t.src[t.pos+2:]
+	switch {
+	case strings.HasPrefix(rest, "P<"), strings.HasPrefix(rest, ":"):
+		t.out.WriteString("(?")
+		t.pos += 2
+		return nil
+	case strings.HasPrefix(rest, "P="):
+		return t.errorf("backreferences are not supported")
+	case strings.HasPrefix(rest, "#"):
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return t.errorf("missing ), unterminated comment")
+		}
+		t.pos += 2 + end + 1
+		return nil
+	case strings.HasPrefix(rest, "="), strings.HasPrefix(rest, "!"), strings.HasPrefix(rest, "<="), strings.HasPrefix(rest, "<!"):
+		return t.errorf("look-ahead and look-behind assertions are not supported")
+	case strings.HasPrefix(rest, "("):
+		return t.errorf("conditional groups are not supported")
+	}
+	// Inline flags, either global (?imsx) or scoped (?ims-imsx:...)
+	var goFlags string
+	extraFlags := 0
+	negative := false
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch c {
+		case 'i', 'm', 's':
+			goFlags += string(c)
+		case '-':
+			if negative {
+				return t.errorf("unknown extension ?%s", rest[:i+1])
+			}
+			negative = true
+			goFlags += "-"
+		case 'x':
+			extraFlags |= flagVerbose
+		case 'a':
+			extraFlags |= flagASCII
+		case 'u':
+		case 'L':
+			return t.errorf("the LOCALE flag is not supported")
+		case ')':
+			if negative {
+				return t.errorf("missing :")
+			}
+			// Global flags are added to the start of the pattern
+			t.flags |= extraFlags
+			for _, f := range goFlags {
+				switch f {
+				case 'i':
+					t.flags |= flagIgnoreCase
+				case 'm':
+					t.flags |= flagMultiline
+				case 's':
+					t.flags |= flagDotAll
+				}
+			}
+			t.pos += 2 + i + 1
+			return nil
+		case ':':
+			// The verbose and ASCII flags can only be set globally
+			if strings.HasSuffix(goFlags, "-") {
+				goFlags = goFlags[:len(goFlags)-1]
+			}
+			t.out.WriteString("(?" + goFlags + ":")
+			t.pos += 2 + i + 1
+			return nil
+		default:
+			return t.errorf("unknown extension ?%c", c)
+		}
+	}
+	return t.errorf("missing -, : or )")
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/peterh/liner"
 )
 
-const HistoryFileName = ".gpyhistory"
+// HistoryFileName is the name of the file in the home directory the
+// lines entered are saved in between sessions
+const HistoryFileName = ".gpython_history"
 
 // homeDirectory finds the home directory or returns ""
 func homeDirectory() string {
@@ -50,6 +52,7 @@ func newReadline(repl *repl.REPL) *readline {
 	if home != "" {
 		rl.historyFile = filepath.Join(home, HistoryFileName)
 	}
+	rl.SetCtrlCAborts(true)
 	rl.SetTabCompletionStyle(liner.TabPrints)
 	rl.SetWordCompleter(rl.Completer)
 	return rl
@@ -71,7 +74,9 @@ func (rl *readline) ReadHistory() error {
 
 // writeHistory writes the history from the term
 func (rl *readline) WriteHistory() error {
-	f, err := os.OpenFile(rl.historyFile, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666)
+	// The history read at the start is written back too so
+	// overwrite the file rather than appending to it
+	f, err := os.OpenFile(rl.historyFile, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0666)
 	if err != nil {
 		return err
 	}
@@ -119,11 +124,25 @@ func (rl *readline) Print(out string) {
 }
 
 // RunREPL starts the REPL loop
+//
+// It returns at the end of the input or exits the process with the
+// status of a SystemExit raised by the code run.
 func RunREPL() {
-	repl := repl.New()
+	runREPL(repl.New())
+}
+
+// RunREPLInModule starts the REPL loop running the statements in
+// module, as the -i option does after running a script
+//
+// It returns or exits as RunREPL does.
+func RunREPLInModule(module *py.Module) {
+	runREPL(repl.NewWithModule(module))
+}
+
+// Runs the REPL loop reading lines from the terminal
+func runREPL(repl *repl.REPL) {
 	rl := newReadline(repl)
 	repl.SetUI(rl)
-	defer rl.Close()
 	err := rl.ReadHistory()
 	if err != nil {
 		if !os.IsNotExist(err) {
@@ -131,19 +150,37 @@ func RunREPL() {
 		}
 	}
 
-	for {
+	status, exit := 0, false
+	for !exit {
 		line, err := rl.Prompt(rl.prompt)
 		if err != nil {
 			if err == io.EOF {
 				fmt.Printf("\n")
 				break
 			}
+			if err == liner.ErrPromptAborted {
+				// Ctrl-C throws away the statement being entered
+				fmt.Printf("KeyboardInterrupt\n")
+				rl.repl.Reset()
+				continue
+			}
 			fmt.Printf("Problem reading line: %v\n", err)
 			continue
 		}
 		if line != "" {
 			rl.AppendHistory(line)
 		}
-		rl.repl.Run(line)
+		err = rl.repl.Run(line)
+		if err != nil {
+			status, exit = py.SystemExitStatus(err, os.Stderr)
+		}
+	}
+	err = rl.Close()
+	if err != nil {
+		fmt.Printf("Failed to save history: %v\n", err)
+	}
+	if exit {
+		py.FlushFiles()
+		os.Exit(status)
 	}
 }
@@ -0,0 +1,129 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tab completion for the REPL
+
+package repl
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// The keywords offered as completions
+var keywords = []string{
+	"False", "None", "True", "and", "as", "assert", "async", "await",
+	"break", "class", "continue", "def", "del", "elif", "else",
+	"except", "finally", "for", "from", "global", "if", "import", "in",
+	"is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return",
+	"try", "while", "with", "yield",
+}
+
+// Returns true if c can be part of a python name
+func isNameChar(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// Completer takes the currently edited line with the cursor
+// position and returns the completion candidates for the partial word
+// to be completed. If the line is "Hello, wo!!!" and the cursor is
+// before the first '!', ("Hello, wo!!!", 9) is passed to the
+// completer which may returns ("Hello, ", {"world", "Word"}, "!!!")
+// to have "Hello, world!!!".
+//
+// The partial word is a name, completed from the globals, builtins
+// and keywords, or a dotted name such as "os.pa" whose last part is
+// completed from the attributes of the object the rest refers to.
+func (r *REPL) Completer(line string, pos int) (head string, completions []string, tail string) {
+	head, tail = line[:pos], line[pos:]
+	start := len(head)
+	for start > 0 {
+		c, size := utf8.DecodeLastRuneInString(head[:start])
+		if !isNameChar(c) && c != '.' {
+			break
+		}
+		start -= size
+	}
+	head, partial := head[:start], head[start:]
+	if dot := strings.LastIndex(partial, "."); dot >= 0 {
+		completions = r.completeAttributes(partial[:dot], partial[dot+1:])
+	} else {
+		completions = r.completeNames(partial)
+	}
+	sort.Strings(completions)
+	return head, completions, tail
+}
+
+// Returns the globals, builtins and keywords starting with partial
+func (r *REPL) completeNames(partial string) (completions []string) {
+	found := make(map[string]struct{})
+	match := func(name string) {
+		if strings.HasPrefix(name, partial) {
+			if _, ok := found[name]; !ok {
+				completions = append(completions, name)
+				found[name] = struct{}{}
+			}
+		}
+	}
+	for name := range r.module.Globals {
+		match(name)
+	}
+	for name := range py.CurrentContext.Builtins().Globals {
+		match(name)
+	}
+	for _, name := range keywords {
+		match(name)
+	}
+	return completions
+}
+
+// Returns the dotted name expr, which must be made of names, looked
+// up in the globals and builtins or nil if it can't be found
+func (r *REPL) lookup(expr string) py.Object {
+	names := strings.Split(expr, ".")
+	obj, ok := r.module.Globals[names[0]]
+	if !ok {
+		obj, ok = py.CurrentContext.Builtins().Globals[names[0]]
+		if !ok {
+			return nil
+		}
+	}
+	for _, name := range names[1:] {
+		var err error
+		obj, err = py.GetAttrString(obj, name)
+		if err != nil {
+			return nil
+		}
+	}
+	return obj
+}
+
+// Returns "expr.name" for the attributes of the object expr refers to
+// which start with partial.  Private names are only completed if
+// partial starts with "_".
+func (r *REPL) completeAttributes(expr, partial string) (completions []string) {
+	obj := r.lookup(expr)
+	if obj == nil {
+		return nil
+	}
+	names, err := py.Dir(obj)
+	if err != nil {
+		return nil
+	}
+	for _, item := range names.Items {
+		name, ok := item.(py.String)
+		if !ok || !strings.HasPrefix(string(name), partial) {
+			continue
+		}
+		if strings.HasPrefix(string(name), "_") && !strings.HasPrefix(partial, "_") {
+			continue
+		}
+		completions = append(completions, expr+"."+string(name))
+	}
+	return completions
+}
@@ -7,7 +7,6 @@ package repl
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/go-python/gpython/compile"
@@ -25,8 +24,9 @@ const (
 type REPL struct {
 	module       *py.Module
 	prog         string
-	continuation bool
-	previous     string
+	continuation bool   // set if reading the rest of a statement
+	block        bool   // set if the statement is only ended by a blank line
+	previous     string // the lines of the statement read so far
 	term         UI
 }
 
@@ -41,14 +41,20 @@ type UI interface {
 
 // New create a new REPL and initialises the state machine
 func New() *REPL {
-	r := &REPL{
-		module:       py.NewModule("__main__", "", nil, nil),
+	module := py.NewModule("__main__", "", nil, nil)
+	module.Globals["__file__"] = py.String("<stdin>")
+	return NewWithModule(module)
+}
+
+// NewWithModule creates a new REPL which runs the statements in
+// module, such as the __main__ module of a script which has been run
+func NewWithModule(module *py.Module) *REPL {
+	return &REPL{
+		module:       module,
 		prog:         "<stdin>",
 		continuation: false,
 		previous:     "",
 	}
-	r.module.Globals["__file__"] = py.String(r.prog)
-	return r
 }
 
 // SetUI initialises the output user interface
@@ -57,80 +63,103 @@ func (r *REPL) SetUI(term UI) {
 	r.term.SetPrompt(NormalPrompt)
 }
 
+// Words which start a compound statement
+var blockKeywords = map[string]bool{
+	"async": true,
+	"class": true,
+	"def":   true,
+	"for":   true,
+	"if":    true,
+	"try":   true,
+	"while": true,
+	"with":  true,
+}
+
+// Returns true if line starts a compound statement which, as in
+// CPython, is only ended by a blank line even if it is complete
+func startsBlock(line string) bool {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "@") {
+		return true
+	}
+	end := strings.IndexFunc(line, func(c rune) bool { return !isNameChar(c) })
+	if end < 0 {
+		end = len(line)
+	}
+	return blockKeywords[line[:end]]
+}
+
+// Returns true if source has nothing but blank lines and comments
+func isBlank(source string) bool {
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line[0] != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if err is the error from compiling a statement which
+// needs more lines to finish it
+func isIncomplete(err error) bool {
+	if err == nil || !py.IsException(py.SyntaxError, err) {
+		return false
+	}
+	// FIXME detect EOF without looking at the message
+	errText := err.Error()
+	return strings.Contains(errText, "unexpected EOF while parsing") || strings.Contains(errText, "EOF while scanning triple-quoted string literal")
+}
+
+// Reset discards the lines of the statement being read, if any, and
+// shows the normal prompt
+func (r *REPL) Reset() {
+	r.continuation = false
+	r.block = false
+	r.previous = ""
+	r.term.SetPrompt(NormalPrompt)
+}
+
 // Run runs a single line of the REPL
-func (r *REPL) Run(line string) {
+//
+// If the line doesn't finish a statement it is saved and the
+// continuation prompt shown.  Errors from the statement are printed
+// except for SystemExit which is returned so the caller can exit.
+func (r *REPL) Run(line string) error {
 	// Override the PrintExpr output temporarily
 	oldPrintExpr := vm.PrintExpr
 	vm.PrintExpr = r.term.Print
 	defer func() {
 		vm.PrintExpr = oldPrintExpr
 	}()
-	if r.continuation {
-		if line != "" {
-			r.previous += string(line) + "\n"
-			return
+	toCompile := r.previous + line
+	if !r.continuation {
+		if isBlank(toCompile) {
+			return nil
 		}
+		r.block = startsBlock(line)
 	}
 	// need +"\n" because "single" expects \n terminated input
-	toCompile := r.previous + string(line)
-	if toCompile == "" {
-		return
-	}
 	obj, err := compile.Compile(toCompile+"\n", r.prog, "single", 0, true)
-	if err != nil {
-		// Detect that we should start a continuation line
-		// FIXME detect EOF properly!
-		errText := err.Error()
-		if strings.Contains(errText, "unexpected EOF while parsing") || strings.Contains(errText, "EOF while scanning triple-quoted string literal") {
-			stripped := strings.TrimSpace(toCompile)
-			isComment := len(stripped) > 0 && stripped[0] == '#'
-			if !isComment {
-				r.continuation = true
-				r.previous += string(line) + "\n"
-				r.term.SetPrompt(ContinuationPrompt)
-			}
-			return
-		}
+	if isIncomplete(err) || (err == nil && r.block && strings.TrimSpace(line) != "") {
+		// Start or carry on with a continuation line
+		r.continuation = true
+		r.previous = toCompile + "\n"
+		r.term.SetPrompt(ContinuationPrompt)
+		return nil
 	}
-	r.continuation = false
-	r.term.SetPrompt(NormalPrompt)
-	r.previous = ""
+	r.Reset()
 	if err != nil {
 		r.term.Print(fmt.Sprintf("Compile error: %v", err))
-		return
+		return nil
 	}
 	code := obj.(*py.Code)
 	_, err = vm.Run(r.module.Globals, r.module.Globals, code, nil)
 	if err != nil {
-		py.TracebackDump(err)
-	}
-}
-
-// WordCompleter takes the currently edited line with the cursor
-// position and returns the completion candidates for the partial word
-// to be completed. If the line is "Hello, wo!!!" and the cursor is
-// before the first '!', ("Hello, wo!!!", 9) is passed to the
-// completer which may returns ("Hello, ", {"world", "Word"}, "!!!")
-// to have "Hello, world!!!".
-func (r *REPL) Completer(line string, pos int) (head string, completions []string, tail string) {
-	head = line[:pos]
-	tail = line[pos:]
-	lastSpace := strings.LastIndex(head, " ")
-	head, partial := line[:lastSpace+1], line[lastSpace+1:]
-	// log.Printf("head = %q, partial = %q, tail = %q", head, partial, tail)
-	found := make(map[string]struct{})
-	match := func(d py.StringDict) {
-		for k := range d {
-			if strings.HasPrefix(k, partial) {
-				if _, ok := found[k]; !ok {
-					completions = append(completions, k)
-					found[k] = struct{}{}
-				}
-			}
+		if py.IsException(py.SystemExit, err) {
+			return err
 		}
+		py.TracebackDump(err)
 	}
-	match(r.module.Globals)
-	match(py.Builtins.Globals)
-	sort.Strings(completions)
-	return head, completions, tail
+	return nil
 }
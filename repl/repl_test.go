@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/go-python/gpython/py"
+
 	// import required modules
 	_ "github.com/go-python/gpython/builtin"
 	_ "github.com/go-python/gpython/math"
@@ -66,7 +68,7 @@ func TestREPL(t *testing.T) {
 	rt.assert(t, "multi#5", NormalPrompt, "45")
 
 	r.Run("if")
-	rt.assert(t, "compileError", NormalPrompt, "Compile error: \n  File \"<string>\", line 1, offset 2\n    if\n\n\nSyntaxError: 'invalid syntax'")
+	rt.assert(t, "compileError", NormalPrompt, "Compile error: \n  File \"<stdin>\", line 1\n    if\n      ^\nSyntaxError: 'invalid syntax'")
 
 	// test comments in the REPL work properly
 	r.Run("# this is a comment")
@@ -75,12 +77,86 @@ func TestREPL(t *testing.T) {
 	rt.assert(t, "comment continuation", NormalPrompt, "")
 	r.Run("a")
 	rt.assert(t, "comment check", NormalPrompt, "42")
+
+	// brackets are finished without a blank line
+	r.Run("b = (1,")
+	rt.assert(t, "brackets#1", ContinuationPrompt, "")
+	r.Run("2)")
+	rt.assert(t, "brackets#2", NormalPrompt, "")
+	r.Run("b")
+	rt.assert(t, "brackets#3", NormalPrompt, "(1, 2)")
+
+	// compound statements need a blank line even on one line
+	r.Run("if b: c = 3")
+	rt.assert(t, "one line block#1", ContinuationPrompt, "")
+	r.Run("")
+	rt.assert(t, "one line block#2", NormalPrompt, "")
+	r.Run("c")
+	rt.assert(t, "one line block#3", NormalPrompt, "3")
+
+	// blank lines in strings and brackets don't end the block
+	r.Run("@staticmethod")
+	rt.assert(t, "decorator#1", ContinuationPrompt, "")
+	r.Run("def f():")
+	rt.assert(t, "decorator#2", ContinuationPrompt, "")
+	r.Run("    return \"\"\"a")
+	rt.assert(t, "decorator#3", ContinuationPrompt, "")
+	r.Run("")
+	rt.assert(t, "decorator#4", ContinuationPrompt, "")
+	r.Run("b\"\"\"")
+	rt.assert(t, "decorator#5", ContinuationPrompt, "")
+	r.Run("")
+	rt.assert(t, "decorator#6", NormalPrompt, "")
+	r.Run("f.__func__()")
+	rt.assert(t, "decorator#7", NormalPrompt, "'a\\n\\nb'")
+
+	// errors in the middle of a block end it
+	r.Run("while True:")
+	rt.assert(t, "block error#1", ContinuationPrompt, "")
+	r.Run("    )")
+	rt.assert(t, "block error#2", NormalPrompt, "Compile error: \n  File \"<stdin>\", line 2\n    )\n    ^\nSyntaxError: 'invalid syntax'")
+
+	// Reset throws the statement away
+	r.Run("for i in range(3):")
+	rt.assert(t, "reset#1", ContinuationPrompt, "")
+	r.Reset()
+	rt.assert(t, "reset#2", NormalPrompt, "")
+	r.Run("1")
+	rt.assert(t, "reset#3", NormalPrompt, "1")
+
+	// SystemExit is returned rather than printed
+	if err := r.Run("import sys"); err != nil {
+		t.Fatalf("import sys: %v", err)
+	}
+	err := r.Run("sys.exit(3)")
+	if status, ok := py.SystemExitStatus(err, nil); !ok || status != 3 {
+		t.Errorf("exit: want status 3 got %d, %v from %v", status, ok, err)
+	}
+	rt.assert(t, "exit", NormalPrompt, "")
+}
+
+func TestNewWithModule(t *testing.T) {
+	module := py.NewModule("__main__", "", nil, nil)
+	module.Globals["x"] = py.Int(20)
+	r := NewWithModule(module)
+	rt := &replTest{}
+	r.SetUI(rt)
+
+	r.Run("x + 1")
+	rt.assert(t, "x + 1", NormalPrompt, "21")
+
+	r.Run("y = x * 2")
+	rt.assert(t, "y = x * 2", NormalPrompt, "")
+	if got := module.Globals["y"]; got != py.Int(40) {
+		t.Errorf("y: want 40 got %v", got)
+	}
 }
 
 func TestCompleter(t *testing.T) {
 	r := New()
 	rt := &replTest{}
 	r.SetUI(rt)
+	r.Run("import sys")
 
 	for _, test := range []struct {
 		line            string
@@ -93,7 +169,7 @@ func TestCompleter(t *testing.T) {
 			line:            "di",
 			pos:             2,
 			wantHead:        "",
-			wantCompletions: []string{"dict", "divmod"},
+			wantCompletions: []string{"dict", "dir", "divmod"},
 			wantTail:        "",
 		},
 		{
@@ -114,9 +190,58 @@ func TestCompleter(t *testing.T) {
 			line:            "divmod divm",
 			pos:             9,
 			wantHead:        "divmod ",
-			wantCompletions: []string{"divmod"},
+			wantCompletions: []string{"dict", "dir", "divmod"},
 			wantTail:        "vm",
 		},
+		{
+			line:            "print(le",
+			pos:             8,
+			wantHead:        "print(",
+			wantCompletions: []string{"len"},
+			wantTail:        "",
+		},
+		{
+			line:            "whi",
+			pos:             3,
+			wantHead:        "",
+			wantCompletions: []string{"while"},
+			wantTail:        "",
+		},
+		{
+			line:            "x = sys.ver + 1",
+			pos:             11,
+			wantHead:        "x = ",
+			wantCompletions: []string{"sys.version", "sys.version_info"},
+			wantTail:        " + 1",
+		},
+		{
+			line:            "sys.version_info.maj",
+			pos:             20,
+			wantHead:        "",
+			wantCompletions: []string{"sys.version_info.major"},
+			wantTail:        "",
+		},
+		{
+			line:            "sys.__na",
+			pos:             8,
+			wantHead:        "",
+			wantCompletions: []string{"sys.__name__"},
+			wantTail:        "",
+		},
+		{
+			line:            "sys.getr",
+			pos:             8,
+			wantHead:        "",
+			wantCompletions: []string{"sys.getrecursionlimit", "sys.getrefcount"},
+			wantTail:        "",
+		},
+		{
+			line:            "nothere.a",
+			pos:             9,
+			wantHead:        "",
+			wantCompletions: nil,
+			wantTail:        "",
+		},
 	} {
 		t.Run(fmt.Sprintf("line=%q,pos=%d)", test.line, test.pos), func(t *testing.T) {
 			gotHead, gotCompletions, gotTail := r.Completer(test.line, test.pos)
@@ -0,0 +1,217 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package session is the simplest way for a Go program to embed
+// gpython.
+//
+// A Session runs python code in a __main__ module of its own Context
+// and converts values between Go and python in both directions, so
+// the program can share variables with the python code, call it and
+// be called back by it without handling python objects itself:
+//
+//	s := session.New()
+//	err := s.Set("x", 41)
+//	...
+//	err = s.Func("double", func(i int) int { return 2 * i })
+//	...
+//	y, err := s.Eval("double(x + 1)") // y is int(84)
+//
+// Go values are converted to python with py.WrapGoValue and python
+// objects back to Go with py.GoInterface, or py.UnwrapGoValue when
+// the Go type is known.
+//
+// Like all Go code using the interpreter, the methods must be called
+// with the GIL held, which the goroutine which started the program
+// does to begin with.  See vm.RunThread for using a Session from
+// other goroutines.
+package session
+
+import (
+	"reflect"
+
+	_ "github.com/go-python/gpython/builtin"
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/sys"
+	"github.com/go-python/gpython/vm"
+)
+
+// Session is a python interpreter session which a Go program runs
+// code in and shares variables with
+type Session struct {
+	ctx    *py.Context
+	module *py.Module
+}
+
+// New makes a Session with a new Context and an empty __main__
+// module
+func New() *Session {
+	return NewInContext(py.NewContext())
+}
+
+// NewInContext makes a Session running code in ctx with a new
+// __main__ module, replacing any ctx already has
+func NewInContext(ctx *py.Context) *Session {
+	module := ctx.NewModule("__main__", "", nil, nil)
+	module.Globals["__builtins__"] = ctx.Builtins()
+	return &Session{
+		ctx:    ctx,
+		module: module,
+	}
+}
+
+// Context returns the Context the Session runs code in
+func (s *Session) Context() *py.Context {
+	return s.ctx
+}
+
+// Globals returns the global variables of the __main__ module
+func (s *Session) Globals() py.StringDict {
+	return s.module.Globals
+}
+
+// Runs source compiled in mode in the __main__ module returning the
+// result
+func (s *Session) run(source, mode string) (res py.Object, err error) {
+	py.RunInContext(s.ctx, func() {
+		var obj py.Object
+		obj, err = compile.Compile(source, "<string>", mode, 0, true)
+		if err != nil {
+			return
+		}
+		res, err = vm.EvalCode(obj.(*py.Code), s.module.Globals, s.module.Globals)
+	})
+	return res, err
+}
+
+// Exec runs the python statements in source
+//
+// Variables they set are globals of the Session. Errors from
+// compiling or running the code are returned as python exceptions.
+func (s *Session) Exec(source string) error {
+	_, err := s.run(source, "exec")
+	return err
+}
+
+// EvalObject evaluates the python expression in source returning the
+// python object it gives
+func (s *Session) EvalObject(source string) (py.Object, error) {
+	return s.run(source, "eval")
+}
+
+// Eval evaluates the python expression in source returning its value
+// converted to Go with py.GoInterface
+func (s *Session) Eval(source string) (interface{}, error) {
+	res, err := s.EvalObject(source)
+	if err != nil {
+		return nil, err
+	}
+	return py.GoInterface(res)
+}
+
+// EvalAs evaluates the python expression in source and stores its
+// value in the Go variable ptr points to, converting it to the type
+// of that with py.UnwrapGoValue
+func (s *Session) EvalAs(source string, ptr interface{}) error {
+	res, err := s.EvalObject(source)
+	if err != nil {
+		return err
+	}
+	return store(res, ptr)
+}
+
+// Set sets the global variable name to the Go value converted to
+// python with py.WrapGoValue
+//
+// Functions become callables which convert their arguments and
+// results, though Func gives them a better name.
+func (s *Session) Set(name string, value interface{}) error {
+	obj, err := py.WrapGoValue(value)
+	if err != nil {
+		return err
+	}
+	s.module.Globals[name] = obj
+	return nil
+}
+
+// Func sets the global variable name to a python callable which calls
+// the Go function fn, as py.NewGoFunc makes
+func (s *Session) Func(name string, fn interface{}) error {
+	method, err := py.NewGoFunc(name, "", fn)
+	if err != nil {
+		return err
+	}
+	s.module.Globals[name] = method
+	return nil
+}
+
+// GetObject returns the python object in the global variable name
+//
+// It returns a NameError if there isn't one.
+func (s *Session) GetObject(name string) (py.Object, error) {
+	obj, ok := s.module.Globals[name]
+	if !ok {
+		return nil, py.ExceptionNewf(py.NameError, "name '%s' is not defined", name)
+	}
+	return obj, nil
+}
+
+// Get returns the value of the global variable name converted to Go
+// with py.GoInterface
+func (s *Session) Get(name string) (interface{}, error) {
+	obj, err := s.GetObject(name)
+	if err != nil {
+		return nil, err
+	}
+	return py.GoInterface(obj)
+}
+
+// GetAs stores the value of the global variable name in the Go
+// variable ptr points to, converting it to the type of that with
+// py.UnwrapGoValue
+func (s *Session) GetAs(name string, ptr interface{}) error {
+	obj, err := s.GetObject(name)
+	if err != nil {
+		return err
+	}
+	return store(obj, ptr)
+}
+
+// Call calls the python callable in the global variable name with
+// args converted to python, returning the result converted to Go
+func (s *Session) Call(name string, args ...interface{}) (res interface{}, err error) {
+	fn, err := s.GetObject(name)
+	if err != nil {
+		return nil, err
+	}
+	pyArgs := make(py.Tuple, len(args))
+	for i, arg := range args {
+		pyArgs[i], err = py.WrapGoValue(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var obj py.Object
+	py.RunInContext(s.ctx, func() {
+		obj, err = py.Call(fn, pyArgs, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return py.GoInterface(obj)
+}
+
+// Stores obj in the Go variable ptr points to
+func store(obj py.Object, ptr interface{}) error {
+	p := reflect.ValueOf(ptr)
+	if p.Kind() != reflect.Ptr || p.IsNil() {
+		return py.ExceptionNewf(py.TypeError, "can't store python value in Go %T, need a non nil pointer", ptr)
+	}
+	v, err := py.UnwrapGoValue(obj, p.Elem().Type())
+	if err != nil {
+		return err
+	}
+	p.Elem().Set(v)
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-python/gpython/py"
+)
+
+func TestEvalAndExec(t *testing.T) {
+	s := New()
+	err := s.Exec("x = 40\ndef f(a, b=2):\n    return a + b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		in   string
+		want interface{}
+	}{
+		{"f(x)", 42},
+		{"None", nil},
+		{"x > 1", true},
+		{"x / 16", 2.5},
+		{"1j", complex(0, 1)},
+		{"'a' * 3", "aaa"},
+		{"b'hi'", []byte("hi")},
+		{"[1, 'a', (2.5, None)]", []interface{}{1, "a", []interface{}{2.5, nil}}},
+		{"{'a': [1], 'b': {}}", map[string]interface{}{"a": []interface{}{1}, "b": map[string]interface{}{}}},
+	} {
+		got, err := s.Eval(test.in)
+		if err != nil {
+			t.Errorf("%s: error %v", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: want %#v got %#v", test.in, test.want, got)
+		}
+	}
+
+	got, err := s.Eval("2**100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := new(big.Int).Lsh(big.NewInt(1), 100)
+	if i, ok := got.(*big.Int); !ok || i.Cmp(want) != 0 {
+		t.Errorf("2**100: want %v got %#v", want, got)
+	}
+
+	// Objects with no Go equivalent are returned as they are
+	got, err = s.Eval("f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.(*py.Function); !ok {
+		t.Errorf("f: want *py.Function got %T", got)
+	}
+
+	_, err = s.Eval("x = 1")
+	if !py.IsException(py.SyntaxError, err) {
+		t.Errorf("want SyntaxError got %v", err)
+	}
+	err = s.Exec("1/0")
+	if !py.IsException(py.ZeroDivisionError, err) {
+		t.Errorf("want ZeroDivisionError got %v", err)
+	}
+	err = s.Exec("import sys\nn = len(sys.argv)")
+	if err != nil {
+		t.Errorf("import sys failed: %v", err)
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	s := New()
+	type point struct{ X, Y int }
+	for name, value := range map[string]interface{}{
+		"i":     3,
+		"words": []string{"a", "b"},
+		"ages":  map[string]int{"bob": 7},
+		"p":     &point{X: 1, Y: 2},
+	} {
+		if err := s.Set(name, value); err != nil {
+			t.Fatalf("Set %s: %v", name, err)
+		}
+	}
+	err := s.Exec("j = i + 1\nwords.append('c')\nages['ann'] = 9\np.X = 10\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Get("j")
+	if err != nil || got != 4 {
+		t.Errorf("j: want 4 got %v, %v", got, err)
+	}
+	var words []string
+	if err = s.GetAs("words", &words); err != nil || !reflect.DeepEqual(words, []string{"a", "b", "c"}) {
+		t.Errorf("words: got %v, %v", words, err)
+	}
+	var ages map[string]int
+	if err = s.GetAs("ages", &ages); err != nil || !reflect.DeepEqual(ages, map[string]int{"bob": 7, "ann": 9}) {
+		t.Errorf("ages: got %v, %v", ages, err)
+	}
+	got, err = s.Get("p")
+	if p, ok := got.(*point); err != nil || !ok || p.X != 10 {
+		t.Errorf("p: got %#v, %v", got, err)
+	}
+	var f float64
+	if err = s.EvalAs("j * 1.5", &f); err != nil || f != 6 {
+		t.Errorf("EvalAs: want 6 got %v, %v", f, err)
+	}
+
+	_, err = s.Get("missing")
+	if !py.IsException(py.NameError, err) {
+		t.Errorf("missing: want NameError got %v", err)
+	}
+	var str string
+	err = s.GetAs("j", &str)
+	if !py.IsException(py.TypeError, err) {
+		t.Errorf("GetAs wrong type: want TypeError got %v", err)
+	}
+	err = s.GetAs("j", str)
+	if !py.IsException(py.TypeError, err) {
+		t.Errorf("GetAs not pointer: want TypeError got %v", err)
+	}
+	err = s.Set("c", make(chan int))
+	if !py.IsException(py.TypeError, err) {
+		t.Errorf("Set chan: want TypeError got %v", err)
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	s := New()
+	var logged []string
+	err := s.Func("log", func(words ...string) {
+		logged = append(logged, strings.Join(words, " "))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Func("check", func(n int) (int, error) {
+		if n < 0 {
+			return 0, py.ExceptionNewf(py.ValueError, "negative")
+		}
+		return n * 2, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Exec(`
+log("hello", "world")
+def safe(n):
+    try:
+        return check(n)
+    except ValueError as e:
+        return str(e)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(logged, []string{"hello world"}) {
+		t.Errorf("logged: got %v", logged)
+	}
+	got, err := s.Call("safe", 21)
+	if err != nil || got != 42 {
+		t.Errorf("safe(21): want 42 got %v, %v", got, err)
+	}
+	got, err = s.Call("safe", -1)
+	if err != nil || got != "negative" {
+		t.Errorf("safe(-1): want 'negative' got %v, %v", got, err)
+	}
+	_, err = s.Call("check", -1)
+	if !py.IsException(py.ValueError, err) {
+		t.Errorf("check(-1): want ValueError got %v", err)
+	}
+	_, err = s.Call("missing")
+	if !py.IsException(py.NameError, err) {
+		t.Errorf("missing: want NameError got %v", err)
+	}
+	err = s.Func("bad", 1)
+	if !py.IsException(py.TypeError, err) {
+		t.Errorf("Func not a function: want TypeError got %v", err)
+	}
+}
+
+func TestSessionsAreIsolated(t *testing.T) {
+	a, b := New(), New()
+	if err := a.Set("x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Exec("import sys\nsys.shared = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Get("x"); !py.IsException(py.NameError, err) {
+		t.Errorf("x visible in other session: %v", err)
+	}
+	got, err := b.Eval("hasattr(__import__('sys'), 'shared')")
+	if err != nil || got != false {
+		t.Errorf("sys shared between sessions: %v, %v", got, err)
+	}
+}
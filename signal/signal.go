@@ -0,0 +1,234 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Signal module
+//
+// Signals are received by a goroutine using os/signal which asks the
+// main thread to run the python handler with vm.AddPendingCall, so the
+// handler runs between two instructions of whatever code is running
+// and an exception it raises, such as the KeyboardInterrupt raised by
+// default_int_handler, is raised in that code.
+//
+// Signals belong to the process rather than a Context so the handlers
+// are shared by all of them.  They are only touched with the GIL held.
+
+package signal
+
+import (
+	"os"
+	ossignal "os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+const module_doc = `This module provides mechanisms to use signal handlers in Python.
+
+Functions:
+
+signal() -- set the action for a given signal
+getsignal() -- get the signal action for a given signal
+default_int_handler() -- default SIGINT handler
+
+signal constants:
+SIG_DFL -- used to refer to the system default handler
+SIG_IGN -- used to ignore the signal
+NSIG -- number of defined signals
+SIGINT, SIGTERM, etc. -- signal numbers
+
+*** IMPORTANT NOTICE ***
+A signal handler function is called with two arguments:
+the first is the signal number, the second is the interrupted stack frame.`
+
+// The handlers which aren't python functions
+const (
+	SIG_DFL = py.Int(0)
+	SIG_IGN = py.Int(1)
+)
+
+// The signals which can be used everywhere
+var commonSignals = map[string]syscall.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGTERM": syscall.SIGTERM,
+}
+
+var (
+	handlers = map[syscall.Signal]py.Object{} // the handlers set indexed by signal
+	signals  = map[syscall.Signal]struct{}{}  // the signals which can be used
+	nsig     int                              // one more than the largest signal
+	received = make(chan os.Signal, 16)       // the signals with python handlers
+	started  sync.Once                        // starts the goroutine receiving them
+)
+
+// Starts the goroutine which runs the handlers of the signals received
+func start() {
+	started.Do(func() {
+		go func() {
+			for sig := range received {
+				signum := sig.(syscall.Signal)
+				vm.AddPendingCall(func() error {
+					return dispatch(signum)
+				})
+			}
+		}()
+	})
+}
+
+// Calls the python handler for signum in the main thread
+func dispatch(signum syscall.Signal) error {
+	handler, ok := handlers[signum]
+	if !ok || handler == SIG_DFL || handler == SIG_IGN {
+		return nil
+	}
+	var frame py.Object = py.None
+	if py.CurrentFrame != nil {
+		frame = py.CurrentFrame
+	}
+	_, err := py.Call(handler, py.Tuple{py.Int(signum), frame}, nil)
+	return err
+}
+
+// Sets the handler for signum, which must be SIG_DFL, SIG_IGN or a
+// callable, and makes os/signal deliver it accordingly
+func setHandler(signum syscall.Signal, handler py.Object) {
+	handlers[signum] = handler
+	switch handler {
+	case SIG_DFL:
+		ossignal.Reset(signum)
+	case SIG_IGN:
+		ossignal.Ignore(signum)
+	default:
+		start()
+		ossignal.Notify(received, signum)
+	}
+}
+
+// InstallDefaultHandlers installs the handlers the interpreter starts
+// with when it is run as a program, which is default_int_handler for
+// SIGINT so that Ctrl-C raises KeyboardInterrupt in the running code
+// rather than killing the process.
+//
+// It must be called with the GIL held.  Programs embedding gpython
+// which want to handle SIGINT themselves shouldn't call it.
+func InstallDefaultHandlers() {
+	setHandler(syscall.SIGINT, defaultIntHandler)
+}
+
+// Converts a python signal number into a signal which can be used
+func signalArg(arg py.Object) (syscall.Signal, error) {
+	n, err := py.MakeGoInt(arg)
+	if err != nil {
+		return 0, err
+	}
+	signum := syscall.Signal(n)
+	if _, ok := signals[signum]; !ok {
+		return 0, py.ExceptionNewf(py.ValueError, "signal number out of range")
+	}
+	return signum, nil
+}
+
+// Returns the handler of signum
+func getHandler(signum syscall.Signal) py.Object {
+	handler, ok := handlers[signum]
+	if !ok {
+		return SIG_DFL
+	}
+	return handler
+}
+
+const default_int_handler_doc = `default_int_handler(...)
+
+The default handler for SIGINT installed by Python.
+It raises KeyboardInterrupt.`
+
+func signal_default_int_handler(self py.Object, args py.Tuple) (py.Object, error) {
+	exc, err := py.ExceptionNew(py.KeyboardInterrupt, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return nil, exc.(*py.Exception)
+}
+
+var defaultIntHandler = py.MustNewMethod("default_int_handler", signal_default_int_handler, 0, default_int_handler_doc)
+
+const signal_doc = `signal(sig, action) -> action
+
+Set the action for the given signal.  The action can be SIG_DFL,
+SIG_IGN, or a callable Python object.  The previous action is
+returned.  See getsignal() for possible return values.
+
+*** IMPORTANT NOTICE ***
+A signal handler function is called with two arguments:
+the first is the signal number, the second is the interrupted stack frame.`
+
+func signal_signal(self py.Object, args py.Tuple) (py.Object, error) {
+	var sigObj, handler py.Object
+	err := py.UnpackTuple(args, nil, "signal", 2, 2, &sigObj, &handler)
+	if err != nil {
+		return nil, err
+	}
+	if vm.ThreadIdent() != 1 {
+		return nil, py.ExceptionNewf(py.ValueError, "signal only works in main thread")
+	}
+	signum, err := signalArg(sigObj)
+	if err != nil {
+		return nil, err
+	}
+	if handler != SIG_DFL && handler != SIG_IGN {
+		if _, ok := handler.(py.I__call__); !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "signal handler must be signal.SIG_IGN, signal.SIG_DFL, or a callable object")
+		}
+	}
+	if uncatchable[signum] {
+		return nil, py.MakeOSError(syscall.EINVAL)
+	}
+	old := getHandler(signum)
+	setHandler(signum, handler)
+	return old, nil
+}
+
+const getsignal_doc = `getsignal(sig) -> action
+
+Return the current action for the given signal.  The return value can be:
+SIG_IGN -- if the signal is being ignored
+SIG_DFL -- if the default action for the signal is in effect
+None -- if an unknown handler is in effect
+anything else -- the callable Python object used as a handler`
+
+func signal_getsignal(self py.Object, arg py.Object) (py.Object, error) {
+	signum, err := signalArg(arg)
+	if err != nil {
+		return nil, err
+	}
+	return getHandler(signum), nil
+}
+
+func init() {
+	globals := py.StringDict{
+		"SIG_DFL": SIG_DFL,
+		"SIG_IGN": SIG_IGN,
+	}
+	for _, names := range []map[string]syscall.Signal{commonSignals, platformSignals} {
+		for name, signum := range names {
+			globals[name] = py.Int(signum)
+			signals[signum] = struct{}{}
+			if int(signum) >= nsig {
+				nsig = int(signum) + 1
+			}
+		}
+	}
+	globals["NSIG"] = py.Int(nsig)
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "signal",
+		Doc:  module_doc,
+		Methods: []*py.Method{
+			defaultIntHandler,
+			py.MustNewMethod("signal", signal_signal, 0, signal_doc),
+			py.MustNewMethod("getsignal", signal_getsignal, 0, getsignal_doc),
+		},
+		Globals: globals,
+	})
+}
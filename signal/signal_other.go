@@ -0,0 +1,16 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package signal
+
+import "syscall"
+
+// Only the commonSignals can be used on other systems
+var platformSignals = map[string]syscall.Signal{}
+
+// The signals whose handlers can't be changed
+var uncatchable = map[syscall.Signal]bool{}
@@ -0,0 +1,33 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package signal
+
+import "syscall"
+
+// The signals which can be used on unix as well as the commonSignals
+var platformSignals = map[string]syscall.Signal{
+	"SIGABRT":  syscall.SIGABRT,
+	"SIGALRM":  syscall.SIGALRM,
+	"SIGCHLD":  syscall.SIGCHLD,
+	"SIGCONT":  syscall.SIGCONT,
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGKILL":  syscall.SIGKILL,
+	"SIGPIPE":  syscall.SIGPIPE,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGSTOP":  syscall.SIGSTOP,
+	"SIGTSTP":  syscall.SIGTSTP,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGWINCH": syscall.SIGWINCH,
+}
+
+// The signals whose handlers can't be changed
+var uncatchable = map[syscall.Signal]bool{
+	syscall.SIGKILL: true,
+	syscall.SIGSTOP: true,
+}
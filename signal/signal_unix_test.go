@@ -0,0 +1,74 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package signal_test
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/py"
+	pysignal "github.com/go-python/gpython/signal"
+	_ "github.com/go-python/gpython/time"
+	"github.com/go-python/gpython/vm"
+)
+
+// Runs src sending sig to the process once it has started
+func runWithSignal(t *testing.T, src string, sig syscall.Signal) (py.StringDict, error) {
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		err := syscall.Kill(syscall.Getpid(), sig)
+		if err != nil {
+			t.Errorf("kill failed: %v", err)
+		}
+	}()
+	globals := py.NewStringDict()
+	_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+	return globals, err
+}
+
+func TestHandler(t *testing.T) {
+	globals, err := runWithSignal(t, `
+import signal
+got = []
+def handler(signum, frame):
+    got.append((signum, frame.f_code.co_name))
+signal.signal(signal.SIGUSR1, handler)
+def loop():
+    while not got:
+        pass
+loop()
+signal.signal(signal.SIGUSR1, signal.SIG_DFL)
+`, syscall.SIGUSR1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := globals["got"].(*py.List)
+	want := py.Tuple{py.Int(syscall.SIGUSR1), py.String("loop")}
+	if !reflect.DeepEqual(got.Items, []py.Object{want}) {
+		t.Errorf("want [%v] got %v", want, got)
+	}
+}
+
+func TestKeyboardInterrupt(t *testing.T) {
+	pysignal.InstallDefaultHandlers()
+	_, err := runWithSignal(t, `
+import time
+while True:
+    time.sleep(1)
+`, syscall.SIGINT)
+	if !py.IsException(py.KeyboardInterrupt, err) {
+		t.Fatalf("want KeyboardInterrupt got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import signal
+from libtest import *
+
+doc="constants"
+assertEqual(signal.SIG_DFL, 0)
+assertEqual(signal.SIG_IGN, 1)
+assertEqual(signal.SIGINT, 2)
+assertEqual(signal.SIGTERM, 15)
+assertTrue(signal.NSIG > signal.SIGTERM)
+
+doc="default_int_handler"
+assertRaises(KeyboardInterrupt, signal.default_int_handler, signal.SIGINT, None)
+try:
+    signal.default_int_handler(signal.SIGINT, None)
+except KeyboardInterrupt as e:
+    assertEqual(e.args, ())
+
+doc="signal and getsignal"
+def handler(signum, frame):
+    pass
+assertEqual(signal.getsignal(signal.SIGTERM), signal.SIG_DFL)
+assertEqual(signal.signal(signal.SIGTERM, handler), signal.SIG_DFL)
+assertTrue(signal.getsignal(signal.SIGTERM) is handler)
+assertTrue(signal.signal(signal.SIGTERM, signal.SIG_IGN) is handler)
+assertEqual(signal.getsignal(signal.SIGTERM), signal.SIG_IGN)
+assertEqual(signal.signal(signal.SIGTERM, signal.SIG_DFL), signal.SIG_IGN)
+assertEqual(signal.getsignal(signal.SIGTERM), signal.SIG_DFL)
+
+doc="signal errors"
+assertRaises(TypeError, signal.signal, signal.SIGTERM, "not callable")
+assertRaises(TypeError, signal.signal, signal.SIGTERM)
+assertRaises(ValueError, signal.signal, -1, handler)
+assertRaises(ValueError, signal.signal, 1000, handler)
+assertRaises(ValueError, signal.getsignal, 1000)
+if hasattr(signal, "SIGKILL"):
+    assertRaises(OSError, signal.signal, signal.SIGKILL, handler)
+
+doc="finished"
@@ -0,0 +1,1404 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Socket module
+//
+// Sockets are made with Go's net package so they only get an
+// operating system socket when they are bound or connected.  Binding a
+// stream socket starts listening on it straight away as net.Listen
+// does both.  The GIL is released while blocking so other python
+// threads can run.
+
+package socket
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+const socket_doc = `Implementation module for socket operations.
+
+See the socket module for documentation.`
+
+// Constants
+const (
+	AF_UNSPEC = 0
+	AF_UNIX   = 1
+	AF_INET   = 2
+	AF_INET6  = 10
+
+	SOCK_STREAM = 1
+	SOCK_DGRAM  = 2
+
+	IPPROTO_IP  = 0
+	IPPROTO_TCP = 6
+	IPPROTO_UDP = 17
+
+	SOL_SOCKET   = 1
+	SO_REUSEADDR = 2
+	SO_KEEPALIVE = 9
+	SO_SNDBUF    = 7
+	SO_RCVBUF    = 8
+	TCP_NODELAY  = 1
+
+	SHUT_RD   = 0
+	SHUT_WR   = 1
+	SHUT_RDWR = 2
+
+	// getaddrinfo errors as returned by glibc
+	EAI_NONAME = -2
+	EAI_AGAIN  = -3
+)
+
+var (
+	GaiError = py.OSError.NewType("gaierror", "Address-related error raised by getaddrinfo() and getnameinfo().", nil, nil)
+	HError   = py.OSError.NewType("herror", "Host-related error.", nil, nil)
+
+	SocketType = py.NewTypeX("socket", socket_type_doc, SocketNew, nil)
+)
+
+// The timeout given to new sockets in seconds, negative for none
+var defaultTimeout = -1.0
+
+const socket_type_doc = `socket(family=AF_INET, type=SOCK_STREAM, proto=0) -> socket object
+
+Open a socket of the given type.  The family argument specifies the
+address family; it defaults to AF_INET.  The type argument specifies
+whether this is a stream (SOCK_STREAM, this is the default)
+or datagram (SOCK_DGRAM) socket.  The protocol argument defaults to 0,
+specifying the default protocol.`
+
+// Socket is a python socket
+type Socket struct {
+	family   int
+	typ      int
+	proto    int
+	timeout  float64 // in seconds, negative to block forever
+	conn     net.Conn
+	listener net.Listener
+	packet   net.PacketConn // a bound datagram socket which isn't connected
+	options  map[[2]int]int
+	closed   bool
+}
+
+// Type of this object
+func (s *Socket) Type() *py.Type {
+	return SocketType
+}
+
+// SocketNew makes a socket
+func SocketNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var family, typ, proto, fileno py.Object = py.Int(AF_INET), py.Int(SOCK_STREAM), py.Int(0), py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOO:socket", []string{"family", "type", "proto", "fileno"}, &family, &typ, &proto, &fileno)
+	if err != nil {
+		return nil, err
+	}
+	if fileno != py.None {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "socket from a file descriptor is not supported")
+	}
+	var ints [3]int
+	for i, obj := range []py.Object{family, typ, proto} {
+		if ints[i], err = py.IndexInt(obj); err != nil {
+			return nil, err
+		}
+	}
+	return newSocket(ints[0], ints[1], ints[2])
+}
+
+// Makes a socket checking the family and type are supported
+func newSocket(family, typ, proto int) (*Socket, error) {
+	switch family {
+	case AF_INET, AF_INET6, AF_UNIX:
+	default:
+		return nil, osError(syscall.EAFNOSUPPORT)
+	}
+	switch typ {
+	case SOCK_STREAM, SOCK_DGRAM:
+	default:
+		return nil, osError(syscall.EPROTONOSUPPORT)
+	}
+	return &Socket{
+		family:  family,
+		typ:     typ,
+		proto:   proto,
+		timeout: defaultTimeout,
+		options: map[[2]int]int{},
+	}, nil
+}
+
+// Returns the OSError for errno
+func osError(errno syscall.Errno) error {
+	return py.MakeOSError(errno)
+}
+
+// Makes an OSError subclass t with errno and strerror set
+func errnoError(t *py.Type, errno int, strerror string) error {
+	e := py.ExceptionNewf(t, "%s", strerror)
+	e.Args = py.Tuple{py.Int(errno), py.String(strerror)}
+	e.Dict["errno"] = py.Int(errno)
+	e.Dict["strerror"] = py.String(strerror)
+	return e
+}
+
+// Converts an error from the net package into a python exception
+func (s *Socket) error(err error) error {
+	var netErr net.Error
+	if s.timeout == 0 && errors.As(err, &netErr) && netErr.Timeout() {
+		return osError(syscall.EAGAIN)
+	}
+	return NetError(err)
+}
+
+// NetError converts an error from the net package, or a package using
+// it, into the exception the socket module would raise
+func NetError(err error) error {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return gaiError(dnsErr)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return py.ExceptionNewf(py.TimeoutError, "timed out")
+	}
+	if errors.Is(err, net.ErrClosed) {
+		return osError(syscall.EBADF)
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return osError(errno)
+	}
+	return py.ExceptionNewf(py.OSError, "%v", err)
+}
+
+// Converts a failed name lookup into a gaierror
+func gaiError(err *net.DNSError) error {
+	if err.IsTemporary {
+		return errnoError(GaiError, EAI_AGAIN, "Temporary failure in name resolution")
+	}
+	return errnoError(GaiError, EAI_NONAME, "Name or service not known")
+}
+
+// Returns the name of the network of the socket for the net package
+func (s *Socket) network() string {
+	switch s.family {
+	case AF_UNIX:
+		if s.typ == SOCK_DGRAM {
+			return "unixgram"
+		}
+		return "unix"
+	case AF_INET6:
+		if s.typ == SOCK_DGRAM {
+			return "udp6"
+		}
+		return "tcp6"
+	}
+	if s.typ == SOCK_DGRAM {
+		return "udp4"
+	}
+	return "tcp4"
+}
+
+// Converts a python address into a host:port string or the path of a
+// unix socket
+func (s *Socket) address(addr py.Object) (string, error) {
+	if s.family == AF_UNIX {
+		path, ok := addr.(py.String)
+		if !ok {
+			return "", py.ExceptionNewf(py.TypeError, "a string is required for AF_UNIX addresses, not %s", addr.Type().Name)
+		}
+		return string(path), nil
+	}
+	tuple, ok := addr.(py.Tuple)
+	if !ok || len(tuple) < 2 || (s.family == AF_INET && len(tuple) != 2) || len(tuple) > 4 {
+		if s.family == AF_INET6 {
+			return "", py.ExceptionNewf(py.TypeError, "AF_INET6 address must be a tuple (host, port[, flowinfo[, scopeid]])")
+		}
+		return "", py.ExceptionNewf(py.TypeError, "AF_INET address must be a pair (host, port)")
+	}
+	host, ok := tuple[0].(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "host name must be str, not %s", tuple[0].Type().Name)
+	}
+	port, err := py.IndexInt(tuple[1])
+	if err != nil {
+		return "", err
+	}
+	if port < 0 || port > 0xFFFF {
+		return "", py.ExceptionNewf(py.OverflowError, "port must be 0-65535.")
+	}
+	if host == "<broadcast>" {
+		host = "255.255.255.255"
+	}
+	return net.JoinHostPort(string(host), strconv.Itoa(port)), nil
+}
+
+// Converts an address from the net package into a python address
+func (s *Socket) addressObject(addr net.Addr) py.Object {
+	if addr == nil {
+		if s.family == AF_UNIX {
+			return py.String("")
+		}
+		return s.ipAddress(nil, 0)
+	}
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return s.ipAddress(a.IP, a.Port)
+	case *net.UDPAddr:
+		return s.ipAddress(a.IP, a.Port)
+	case *net.UnixAddr:
+		return py.String(a.Name)
+	}
+	return py.String(addr.String())
+}
+
+// Makes an AF_INET or AF_INET6 address
+func (s *Socket) ipAddress(ip net.IP, port int) py.Object {
+	if s.family == AF_INET6 {
+		if ip == nil {
+			ip = net.IPv6zero
+		}
+		return py.Tuple{py.String(ip.String()), py.Int(port), py.Int(0), py.Int(0)}
+	}
+	if ip == nil {
+		ip = net.IPv4zero
+	}
+	return py.Tuple{py.String(ip.String()), py.Int(port)}
+}
+
+// Returns the deadline for an operation starting now
+func (s *Socket) deadline() time.Time {
+	if s.timeout < 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(s.timeout * float64(time.Second)))
+}
+
+// Checks the socket hasn't been closed
+func (s *Socket) checkOpen() error {
+	if s.closed {
+		return osError(syscall.EBADF)
+	}
+	return nil
+}
+
+// Checks the socket is connected returning its connection
+func (s *Socket) connected() (net.Conn, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+	if s.conn == nil {
+		return nil, osError(syscall.ENOTCONN)
+	}
+	return s.conn, nil
+}
+
+// Applies the options set with setsockopt which the net package
+// supports to the connection
+func (s *Socket) applyOptions() {
+	for key, value := range s.options {
+		switch c := s.conn.(type) {
+		case *net.TCPConn:
+			switch key {
+			case [2]int{IPPROTO_TCP, TCP_NODELAY}:
+				_ = c.SetNoDelay(value != 0)
+			case [2]int{SOL_SOCKET, SO_KEEPALIVE}:
+				_ = c.SetKeepAlive(value != 0)
+			case [2]int{SOL_SOCKET, SO_RCVBUF}:
+				_ = c.SetReadBuffer(value)
+			case [2]int{SOL_SOCKET, SO_SNDBUF}:
+				_ = c.SetWriteBuffer(value)
+			}
+		case *net.UDPConn:
+			switch key {
+			case [2]int{SOL_SOCKET, SO_RCVBUF}:
+				_ = c.SetReadBuffer(value)
+			case [2]int{SOL_SOCKET, SO_SNDBUF}:
+				_ = c.SetWriteBuffer(value)
+			}
+		}
+	}
+}
+
+// Bind binds the socket to the address, which starts a stream socket
+// listening
+func (s *Socket) Bind(addr py.Object) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	address, err := s.address(addr)
+	if err != nil {
+		return err
+	}
+	if s.conn != nil || s.listener != nil || s.packet != nil {
+		return osError(syscall.EINVAL)
+	}
+	network := s.network()
+	vm.AllowThreads(func() {
+		if s.typ == SOCK_DGRAM {
+			s.packet, err = net.ListenPacket(network, address)
+		} else {
+			s.listener, err = net.Listen(network, address)
+		}
+	})
+	if err != nil {
+		return s.error(err)
+	}
+	return nil
+}
+
+// Listen makes the socket accept connections, binding it to any free
+// port if it hasn't been bound
+func (s *Socket) Listen() error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	if s.typ != SOCK_STREAM {
+		return osError(syscall.EOPNOTSUPP)
+	}
+	if s.listener != nil {
+		return nil
+	}
+	if s.conn != nil {
+		return osError(syscall.EINVAL)
+	}
+	if s.family == AF_UNIX {
+		return osError(syscall.EINVAL)
+	}
+	return s.Bind(py.Tuple{py.String(""), py.Int(0)})
+}
+
+// Accept waits for a connection returning a socket for it and the
+// address of the other end
+func (s *Socket) Accept() (*Socket, py.Object, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, nil, err
+	}
+	if s.listener == nil {
+		return nil, nil, osError(syscall.EINVAL)
+	}
+	var conn net.Conn
+	var err error
+	deadline := s.deadline()
+	vm.AllowThreads(func() {
+		if l, ok := s.listener.(interface{ SetDeadline(time.Time) error }); ok {
+			_ = l.SetDeadline(deadline)
+		}
+		conn, err = s.listener.Accept()
+	})
+	if err != nil {
+		return nil, nil, s.error(err)
+	}
+	c := &Socket{
+		family:  s.family,
+		typ:     s.typ,
+		proto:   s.proto,
+		timeout: defaultTimeout,
+		conn:    conn,
+		options: map[[2]int]int{},
+	}
+	return c, c.addressObject(conn.RemoteAddr()), nil
+}
+
+// Connect connects the socket to the address
+func (s *Socket) Connect(addr py.Object) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	address, err := s.address(addr)
+	if err != nil {
+		return err
+	}
+	if s.conn != nil && s.typ == SOCK_STREAM {
+		return osError(syscall.EISCONN)
+	}
+	dialer := net.Dialer{Deadline: s.deadline()}
+	// Dial from the address the socket is bound to
+	if s.listener != nil {
+		dialer.LocalAddr = s.listener.Addr()
+		err = s.listener.Close()
+		s.listener = nil
+	} else if s.packet != nil {
+		dialer.LocalAddr = s.packet.LocalAddr()
+		err = s.packet.Close()
+		s.packet = nil
+	}
+	if err != nil {
+		return s.error(err)
+	}
+	network := s.network()
+	var conn net.Conn
+	vm.AllowThreads(func() {
+		conn, err = dialer.Dial(network, address)
+	})
+	if err != nil {
+		return s.error(err)
+	}
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+	s.conn = conn
+	s.applyOptions()
+	return nil
+}
+
+// Send sends data returning the number of bytes sent
+func (s *Socket) Send(data []byte) (int, error) {
+	conn, err := s.connected()
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	deadline := s.deadline()
+	vm.AllowThreads(func() {
+		_ = conn.SetWriteDeadline(deadline)
+		n, err = conn.Write(data)
+	})
+	if err != nil && n == 0 {
+		return 0, s.error(err)
+	}
+	return n, nil
+}
+
+// SendAll sends all the data
+func (s *Socket) SendAll(data []byte) error {
+	for len(data) > 0 {
+		n, err := s.Send(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// SendTo sends a datagram to the address
+func (s *Socket) SendTo(data []byte, addr py.Object) (int, error) {
+	if err := s.checkOpen(); err != nil {
+		return 0, err
+	}
+	if s.typ != SOCK_DGRAM {
+		if s.conn != nil {
+			return 0, osError(syscall.EISCONN)
+		}
+		return 0, osError(syscall.ENOTCONN)
+	}
+	address, err := s.address(addr)
+	if err != nil {
+		return 0, err
+	}
+	if s.conn != nil {
+		return 0, osError(syscall.EISCONN)
+	}
+	network := s.network()
+	var n int
+	deadline := s.deadline()
+	vm.AllowThreads(func() {
+		var to net.Addr
+		if s.family == AF_UNIX {
+			to, err = net.ResolveUnixAddr(network, address)
+		} else {
+			to, err = net.ResolveUDPAddr(network, address)
+		}
+		if err != nil {
+			return
+		}
+		if s.packet == nil {
+			// Sending from an unbound socket binds it to any port
+			local := ":0"
+			if s.family == AF_UNIX {
+				local = ""
+			}
+			if s.packet, err = net.ListenPacket(network, local); err != nil {
+				return
+			}
+		}
+		_ = s.packet.SetWriteDeadline(deadline)
+		n, err = s.packet.WriteTo(data, to)
+	})
+	if err != nil {
+		return 0, s.error(err)
+	}
+	return n, nil
+}
+
+// Recv receives up to bufsize bytes, returning no bytes when the other
+// end has closed the connection
+func (s *Socket) Recv(bufsize int) (py.Bytes, error) {
+	if s.packet != nil {
+		data, _, err := s.RecvFrom(bufsize)
+		return data, err
+	}
+	conn, err := s.connected()
+	if err != nil {
+		return nil, err
+	}
+	if bufsize < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "negative buffersize in recv")
+	}
+	buf := make([]byte, bufsize)
+	var n int
+	deadline := s.deadline()
+	vm.AllowThreads(func() {
+		_ = conn.SetReadDeadline(deadline)
+		n, err = conn.Read(buf)
+	})
+	if err != nil && err != io.EOF && n == 0 {
+		return nil, s.error(err)
+	}
+	return py.Bytes(buf[:n]), nil
+}
+
+// RecvFrom receives a datagram of up to bufsize bytes returning it and
+// the address it came from
+func (s *Socket) RecvFrom(bufsize int) (py.Bytes, py.Object, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, nil, err
+	}
+	if bufsize < 0 {
+		return nil, nil, py.ExceptionNewf(py.ValueError, "negative buffersize in recvfrom")
+	}
+	if s.packet == nil {
+		if s.conn == nil {
+			return nil, nil, osError(syscall.EINVAL)
+		}
+		data, err := s.Recv(bufsize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data, s.addressObject(s.conn.RemoteAddr()), nil
+	}
+	buf := make([]byte, bufsize)
+	var n int
+	var from net.Addr
+	var err error
+	deadline := s.deadline()
+	vm.AllowThreads(func() {
+		_ = s.packet.SetReadDeadline(deadline)
+		n, from, err = s.packet.ReadFrom(buf)
+	})
+	if err != nil && n == 0 {
+		return nil, nil, s.error(err)
+	}
+	return py.Bytes(buf[:n]), s.addressObject(from), nil
+}
+
+// Shutdown shuts down reading, writing or both halves of the connection
+func (s *Socket) Shutdown(how int) error {
+	conn, err := s.connected()
+	if err != nil {
+		return err
+	}
+	type halfCloser interface {
+		CloseRead() error
+		CloseWrite() error
+	}
+	c, ok := conn.(halfCloser)
+	if !ok {
+		return osError(syscall.EOPNOTSUPP)
+	}
+	switch how {
+	case SHUT_RD:
+		err = c.CloseRead()
+	case SHUT_WR:
+		err = c.CloseWrite()
+	case SHUT_RDWR:
+		if err = c.CloseRead(); err == nil {
+			err = c.CloseWrite()
+		}
+	default:
+		return osError(syscall.EINVAL)
+	}
+	if err != nil {
+		return s.error(err)
+	}
+	return nil
+}
+
+// Close closes the socket.  Closing it again does nothing.
+func (s *Socket) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var err error
+	for _, c := range []io.Closer{s.conn, s.listener, s.packet} {
+		if c == nil {
+			continue
+		}
+		if closeErr := c.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	s.conn, s.listener, s.packet = nil, nil, nil
+	if err != nil {
+		return s.error(err)
+	}
+	return nil
+}
+
+// Returns the local address of the socket
+func (s *Socket) localAddr() net.Addr {
+	switch {
+	case s.conn != nil:
+		return s.conn.LocalAddr()
+	case s.listener != nil:
+		return s.listener.Addr()
+	case s.packet != nil:
+		return s.packet.LocalAddr()
+	}
+	return nil
+}
+
+// Returns the file descriptor of the socket or -1 if it doesn't have
+// one yet
+func (s *Socket) fileno() int {
+	fd := -1
+	for _, c := range []interface{}{s.conn, s.listener, s.packet} {
+		sc, ok := c.(syscall.Conn)
+		if !ok {
+			continue
+		}
+		raw, err := sc.SyscallConn()
+		if err != nil {
+			continue
+		}
+		_ = raw.Control(func(f uintptr) {
+			fd = int(f)
+		})
+		break
+	}
+	return fd
+}
+
+func (s *Socket) M__repr__() (py.Object, error) {
+	repr := fmt.Sprintf("<socket.socket fd=%d, family=%d, type=%d, proto=%d", s.fileno(), s.family, s.typ, s.proto)
+	if s.closed {
+		return py.String("<socket.socket [closed] " + repr[len("<socket.socket "):] + ">"), nil
+	}
+	if addr := s.localAddr(); addr != nil {
+		laddr, err := py.ReprAsString(s.addressObject(addr))
+		if err != nil {
+			return nil, err
+		}
+		repr += ", laddr=" + laddr
+	}
+	if s.conn != nil && s.conn.RemoteAddr() != nil {
+		raddr, err := py.ReprAsString(s.addressObject(s.conn.RemoteAddr()))
+		if err != nil {
+			return nil, err
+		}
+		repr += ", raddr=" + raddr
+	}
+	return py.String(repr + ">"), nil
+}
+
+func (s *Socket) M__enter__() (py.Object, error) {
+	return s, nil
+}
+
+func (s *Socket) M__exit__(exc_type, exc_value, traceback py.Object) (py.Object, error) {
+	return py.None, s.Close()
+}
+
+// Returns a timeout argument in seconds or -1 for None
+func timeoutArg(timeout py.Object) (float64, error) {
+	if timeout == py.None {
+		return -1, nil
+	}
+	f, err := py.FloatAsFloat64(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 {
+		return 0, py.ExceptionNewf(py.ValueError, "Timeout value out of range")
+	}
+	return f, nil
+}
+
+// Returns a timeout in seconds as a python object
+func timeoutObject(timeout float64) py.Object {
+	if timeout < 0 {
+		return py.None
+	}
+	return py.Float(timeout)
+}
+
+// Returns the bytes of a bytes like object
+func bytesArg(name string, data py.Object) ([]byte, error) {
+	switch b := data.(type) {
+	case py.Bytes:
+		return b, nil
+	case *py.ByteArray:
+		return b.Data, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "%s() argument 1 must be a bytes-like object, not '%s'", name, data.Type().Name)
+}
+
+func init() {
+	SocketType.Dict["bind"] = py.MustNewMethod("bind", func(self, address py.Object) (py.Object, error) {
+		return py.None, self.(*Socket).Bind(address)
+	}, 0, `bind(address)
+
+Bind the socket to a local address.  For IP sockets, the address is a
+pair (host, port); the host must refer to the local host.`)
+	SocketType.Dict["listen"] = py.MustNewMethod("listen", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var backlog py.Object = py.Int(128)
+		err := py.UnpackTuple(args, nil, "listen", 0, 1, &backlog)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.IndexInt(backlog); err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Socket).Listen()
+	}, 0, `listen([backlog])
+
+Enable a server to accept connections.  The backlog is accepted for
+compatibility but the operating system default is used.`)
+	SocketType.Dict["accept"] = py.MustNewMethod("accept", func(self py.Object) (py.Object, error) {
+		conn, addr, err := self.(*Socket).Accept()
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{conn, addr}, nil
+	}, 0, `accept() -> (socket object, address info)
+
+Wait for an incoming connection.  Return a new socket
+representing the connection, and the address of the client.
+For IP sockets, the address info is a pair (hostaddr, port).`)
+	SocketType.Dict["connect"] = py.MustNewMethod("connect", func(self, address py.Object) (py.Object, error) {
+		return py.None, self.(*Socket).Connect(address)
+	}, 0, `connect(address)
+
+Connect the socket to a remote address.  For IP sockets, the address
+is a pair (host, port).`)
+	SocketType.Dict["connect_ex"] = py.MustNewMethod("connect_ex", func(self, address py.Object) (py.Object, error) {
+		err := self.(*Socket).Connect(address)
+		if err == nil {
+			return py.Int(0), nil
+		}
+		if exc, ok := err.(*py.Exception); ok && py.IsException(py.OSError, err) {
+			if errno, ok := exc.Dict["errno"]; ok {
+				return errno, nil
+			}
+		}
+		return nil, err
+	}, 0, `connect_ex(address) -> errno
+
+This is like connect(address), but returns an error code (the errno value)
+instead of raising an exception when an error occurs.`)
+	SocketType.Dict["send"] = py.MustNewMethod("send", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var data, flags py.Object = nil, py.Int(0)
+		err := py.UnpackTuple(args, nil, "send", 1, 2, &data, &flags)
+		if err != nil {
+			return nil, err
+		}
+		b, err := bytesArg("send", data)
+		if err != nil {
+			return nil, err
+		}
+		n, err := self.(*Socket).Send(b)
+		if err != nil {
+			return nil, err
+		}
+		return py.Int(n), nil
+	}, 0, `send(data[, flags]) -> count
+
+Send a data string to the socket.  Return the number of bytes
+sent; this may be less than len(data) if the network is busy.`)
+	SocketType.Dict["sendall"] = py.MustNewMethod("sendall", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var data, flags py.Object = nil, py.Int(0)
+		err := py.UnpackTuple(args, nil, "sendall", 1, 2, &data, &flags)
+		if err != nil {
+			return nil, err
+		}
+		b, err := bytesArg("sendall", data)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Socket).SendAll(b)
+	}, 0, `sendall(data[, flags])
+
+Send a data string to the socket.  This calls send() repeatedly
+until all data is sent.  If an error occurs, it's impossible
+to tell how much data has been sent.`)
+	SocketType.Dict["sendto"] = py.MustNewMethod("sendto", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var data, flags, address py.Object
+		err := py.UnpackTuple(args, nil, "sendto", 2, 3, &data, &flags, &address)
+		if err != nil {
+			return nil, err
+		}
+		if address == nil {
+			address = flags
+		}
+		b, err := bytesArg("sendto", data)
+		if err != nil {
+			return nil, err
+		}
+		n, err := self.(*Socket).SendTo(b, address)
+		if err != nil {
+			return nil, err
+		}
+		return py.Int(n), nil
+	}, 0, `sendto(data[, flags], address) -> count
+
+Like send(data, flags) but allows specifying the destination address.
+For IP sockets, the address is a pair (hostaddr, port).`)
+	SocketType.Dict["recv"] = py.MustNewMethod("recv", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var bufsize, flags py.Object = nil, py.Int(0)
+		err := py.UnpackTuple(args, nil, "recv", 1, 2, &bufsize, &flags)
+		if err != nil {
+			return nil, err
+		}
+		n, err := py.IndexInt(bufsize)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Socket).Recv(n)
+	}, 0, `recv(buffersize[, flags]) -> data
+
+Receive up to buffersize bytes from the socket.  When no data is
+available, block until at least one byte is available or until the
+remote end is closed.  When the remote end is closed and all data is
+read, return the empty string.`)
+	SocketType.Dict["recvfrom"] = py.MustNewMethod("recvfrom", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var bufsize, flags py.Object = nil, py.Int(0)
+		err := py.UnpackTuple(args, nil, "recvfrom", 1, 2, &bufsize, &flags)
+		if err != nil {
+			return nil, err
+		}
+		n, err := py.IndexInt(bufsize)
+		if err != nil {
+			return nil, err
+		}
+		data, addr, err := self.(*Socket).RecvFrom(n)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{data, addr}, nil
+	}, 0, `recvfrom(buffersize[, flags]) -> (data, address info)
+
+Like recv(buffersize, flags) but also return the sender's address info.`)
+	SocketType.Dict["shutdown"] = py.MustNewMethod("shutdown", func(self, how py.Object) (py.Object, error) {
+		n, err := py.IndexInt(how)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Socket).Shutdown(n)
+	}, 0, `shutdown(flag)
+
+Shut down the reading side of the socket (flag == SHUT_RD), the writing side
+of the socket (flag == SHUT_WR), or both ends (flag == SHUT_RDWR).`)
+	SocketType.Dict["close"] = py.MustNewMethod("close", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*Socket).Close()
+	}, 0, `close()
+
+Close the socket.  It cannot be used after this call.`)
+	SocketType.Dict["detach"] = py.MustNewMethod("detach", func(self py.Object) (py.Object, error) {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "detach() is not supported")
+	}, 0, `detach()
+
+Not supported as the socket belongs to the Go runtime.`)
+	SocketType.Dict["fileno"] = py.MustNewMethod("fileno", func(self py.Object) (py.Object, error) {
+		return py.Int(self.(*Socket).fileno()), nil
+	}, 0, `fileno() -> integer
+
+Return the integer file descriptor of the socket, or -1 if it hasn't
+been bound or connected yet.`)
+	SocketType.Dict["getsockname"] = py.MustNewMethod("getsockname", func(self py.Object) (py.Object, error) {
+		s := self.(*Socket)
+		if err := s.checkOpen(); err != nil {
+			return nil, err
+		}
+		return s.addressObject(s.localAddr()), nil
+	}, 0, `getsockname() -> address info
+
+Return the address of the local endpoint.  For IP sockets, the address
+info is a pair (hostaddr, port).`)
+	SocketType.Dict["getpeername"] = py.MustNewMethod("getpeername", func(self py.Object) (py.Object, error) {
+		s := self.(*Socket)
+		conn, err := s.connected()
+		if err != nil {
+			return nil, err
+		}
+		return s.addressObject(conn.RemoteAddr()), nil
+	}, 0, `getpeername() -> address info
+
+Return the address of the remote endpoint.  For IP sockets, the address
+info is a pair (hostaddr, port).`)
+	SocketType.Dict["settimeout"] = py.MustNewMethod("settimeout", func(self, timeout py.Object) (py.Object, error) {
+		t, err := timeoutArg(timeout)
+		if err != nil {
+			return nil, err
+		}
+		self.(*Socket).timeout = t
+		return py.None, nil
+	}, 0, `settimeout(timeout)
+
+Set a timeout on socket operations.  'timeout' can be a float,
+giving in seconds, or None.  Setting a timeout of None disables
+the timeout feature and is equivalent to setblocking(1).
+Setting a timeout of zero is the same as setblocking(0).`)
+	SocketType.Dict["gettimeout"] = py.MustNewMethod("gettimeout", func(self py.Object) (py.Object, error) {
+		return timeoutObject(self.(*Socket).timeout), nil
+	}, 0, `gettimeout() -> timeout
+
+Returns the timeout in seconds (float) associated with socket
+operations. A timeout of None indicates that timeouts on socket
+operations are disabled.`)
+	SocketType.Dict["setblocking"] = py.MustNewMethod("setblocking", func(self, flag py.Object) (py.Object, error) {
+		blocking, err := py.MakeBool(flag)
+		if err != nil {
+			return nil, err
+		}
+		if blocking == py.True {
+			self.(*Socket).timeout = -1
+		} else {
+			self.(*Socket).timeout = 0
+		}
+		return py.None, nil
+	}, 0, `setblocking(flag)
+
+Set the socket to blocking (flag is true) or non-blocking (false).
+setblocking(True) is equivalent to settimeout(None);
+setblocking(False) is equivalent to settimeout(0.0).`)
+	SocketType.Dict["getblocking"] = py.MustNewMethod("getblocking", func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*Socket).timeout != 0), nil
+	}, 0, `getblocking()
+
+Returns True if socket is in blocking mode, or False if it
+is in non-blocking mode.`)
+	SocketType.Dict["setsockopt"] = py.MustNewMethod("setsockopt", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var level, option, value py.Object
+		err := py.UnpackTuple(args, nil, "setsockopt", 3, 3, &level, &option, &value)
+		if err != nil {
+			return nil, err
+		}
+		var ints [3]int
+		for i, obj := range []py.Object{level, option, value} {
+			if ints[i], err = py.IndexInt(obj); err != nil {
+				return nil, err
+			}
+		}
+		s := self.(*Socket)
+		if err = s.checkOpen(); err != nil {
+			return nil, err
+		}
+		s.options[[2]int{ints[0], ints[1]}] = ints[2]
+		s.applyOptions()
+		return py.None, nil
+	}, 0, `setsockopt(level, option, value: int)
+
+Set a socket option.  TCP_NODELAY, SO_KEEPALIVE, SO_RCVBUF and
+SO_SNDBUF are passed to the connection, the others are remembered
+so getsockopt returns them.`)
+	SocketType.Dict["getsockopt"] = py.MustNewMethod("getsockopt", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var level, option py.Object
+		err := py.UnpackTuple(args, nil, "getsockopt", 2, 2, &level, &option)
+		if err != nil {
+			return nil, err
+		}
+		l, err := py.IndexInt(level)
+		if err != nil {
+			return nil, err
+		}
+		o, err := py.IndexInt(option)
+		if err != nil {
+			return nil, err
+		}
+		s := self.(*Socket)
+		if err = s.checkOpen(); err != nil {
+			return nil, err
+		}
+		return py.Int(s.options[[2]int{l, o}]), nil
+	}, 0, `getsockopt(level, option) -> value
+
+Get the value of a socket option set with setsockopt.`)
+	for name, get := range map[string]func(s *Socket) py.Object{
+		"family":  func(s *Socket) py.Object { return py.Int(s.family) },
+		"type":    func(s *Socket) py.Object { return py.Int(s.typ) },
+		"proto":   func(s *Socket) py.Object { return py.Int(s.proto) },
+		"timeout": func(s *Socket) py.Object { return timeoutObject(s.timeout) },
+	} {
+		get := get
+		SocketType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return get(self.(*Socket)), nil
+			},
+		}
+	}
+}
+
+// ------------------------------------------------------------
+// Module functions
+
+const create_connection_doc = `create_connection(address, timeout=None, source_address=None) -> socket
+
+Connect to *address* and return the socket object.
+
+Convenience function.  Connect to *address* (a 2-tuple ` + "``(host,\nport)``" + `) and return the socket object.  Passing the optional
+*timeout* parameter will set the timeout on the socket instance
+before attempting to connect.  If no *timeout* is supplied, the
+global default timeout setting returned by getdefaulttimeout()
+is used.`
+
+func socket_create_connection(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var address, timeout, sourceAddress py.Object = nil, nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:create_connection", []string{"address", "timeout", "source_address"}, &address, &timeout, &sourceAddress)
+	if err != nil {
+		return nil, err
+	}
+	family := AF_INET
+	if tuple, ok := address.(py.Tuple); ok && len(tuple) > 0 {
+		if host, ok := tuple[0].(py.String); ok {
+			if ip := net.ParseIP(string(host)); ip != nil && ip.To4() == nil {
+				family = AF_INET6
+			}
+		}
+	}
+	s, err := newSocket(family, SOCK_STREAM, 0)
+	if err != nil {
+		return nil, err
+	}
+	if timeout != nil {
+		if s.timeout, err = timeoutArg(timeout); err != nil {
+			return nil, err
+		}
+	}
+	if sourceAddress != py.None {
+		if err = s.Bind(sourceAddress); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.Connect(address); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+const create_server_doc = `create_server(address, *, family=AF_INET, backlog=None, reuse_port=False, dualstack_ipv6=False) -> socket
+
+Convenience function which creates a SOCK_STREAM type socket
+bound to *address* (a 2-tuple (host, port)) and return the socket
+object.`
+
+func socket_create_server(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var address, family, backlog, reusePort, dualstack py.Object = nil, py.Int(AF_INET), py.None, py.False, py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOOO:create_server", []string{"address", "family", "backlog", "reuse_port", "dualstack_ipv6"}, &address, &family, &backlog, &reusePort, &dualstack)
+	if err != nil {
+		return nil, err
+	}
+	f, err := py.IndexInt(family)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSocket(f, SOCK_STREAM, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Bind(address); err != nil {
+		return nil, err
+	}
+	if err = s.Listen(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+const gethostname_doc = `gethostname() -> string
+
+Return the current host name.`
+
+func socket_gethostname(self py.Object) (py.Object, error) {
+	name, err := os.Hostname()
+	if err != nil {
+		return nil, py.MakeOSError(err)
+	}
+	return py.String(name), nil
+}
+
+// Looks up the addresses of host
+func lookupIP(host string, family int) ([]net.IP, error) {
+	network := "ip"
+	switch family {
+	case AF_INET:
+		network = "ip4"
+	case AF_INET6:
+		network = "ip6"
+	}
+	var ips []net.IP
+	var err error
+	if host == "" {
+		return []net.IP{nil}, nil
+	}
+	vm.AllowThreads(func() {
+		if ip := net.ParseIP(host); ip != nil {
+			ips = []net.IP{ip}
+			return
+		}
+		ips, err = net.DefaultResolver.LookupIP(context.Background(), network, host)
+	})
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return nil, gaiError(dnsErr)
+		}
+		return nil, errnoError(GaiError, EAI_NONAME, "Name or service not known")
+	}
+	if family == AF_INET || family == AF_INET6 {
+		var matching []net.IP
+		for _, ip := range ips {
+			if (ip.To4() != nil) == (family == AF_INET) {
+				matching = append(matching, ip)
+			}
+		}
+		ips = matching
+	}
+	if len(ips) == 0 {
+		return nil, errnoError(GaiError, EAI_NONAME, "Name or service not known")
+	}
+	return ips, nil
+}
+
+const gethostbyname_doc = `gethostbyname(host) -> address
+
+Return the IP address (a string of the form '255.255.255.255') for a host.`
+
+func socket_gethostbyname(self, host py.Object) (py.Object, error) {
+	name, ok := host.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "gethostbyname() argument 1 must be str, not %s", host.Type().Name)
+	}
+	ips, err := lookupIP(string(name), AF_INET)
+	if err != nil {
+		return nil, err
+	}
+	if ips[0] == nil {
+		return py.String("0.0.0.0"), nil
+	}
+	return py.String(ips[0].String()), nil
+}
+
+const getaddrinfo_doc = `getaddrinfo(host, port [, family, type, proto, flags])
+    -> list of (family, type, proto, canonname, sockaddr)
+
+Resolve host and port into addrinfo struct.`
+
+func socket_getaddrinfo(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var host, port py.Object
+	var family, typ, proto, flags py.Object = py.Int(AF_UNSPEC), py.Int(0), py.Int(0), py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|OOOO:getaddrinfo", []string{"host", "port", "family", "type", "proto", "flags"}, &host, &port, &family, &typ, &proto, &flags)
+	if err != nil {
+		return nil, err
+	}
+	var ints [3]int
+	for i, obj := range []py.Object{family, typ, proto} {
+		if ints[i], err = py.IndexInt(obj); err != nil {
+			return nil, err
+		}
+	}
+	hostName := ""
+	switch h := host.(type) {
+	case py.String:
+		hostName = string(h)
+	case py.Bytes:
+		hostName = string(h)
+	case py.NoneType:
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "getaddrinfo() argument 1 must be string or None")
+	}
+	portNum := 0
+	switch p := port.(type) {
+	case py.String:
+		if portNum, err = net.LookupPort("tcp", string(p)); err != nil {
+			return nil, errnoError(GaiError, -8, "Servname not supported for ai_socktype")
+		}
+	case py.NoneType:
+	default:
+		if portNum, err = py.IndexInt(port); err != nil {
+			return nil, py.ExceptionNewf(py.OSError, "Int or String expected")
+		}
+	}
+	if hostName == "" {
+		hostName = "localhost"
+	}
+	ips, err := lookupIP(hostName, ints[0])
+	if err != nil {
+		return nil, err
+	}
+	types := []int{SOCK_STREAM, SOCK_DGRAM}
+	if ints[1] != 0 {
+		types = []int{ints[1]}
+	}
+	result := py.NewList()
+	for _, ip := range ips {
+		f := AF_INET6
+		if ip.To4() != nil {
+			f = AF_INET
+		}
+		for _, t := range types {
+			p := ints[2]
+			if p == 0 {
+				p = IPPROTO_TCP
+				if t == SOCK_DGRAM {
+					p = IPPROTO_UDP
+				}
+			}
+			s := &Socket{family: f}
+			result.Append(py.Tuple{py.Int(f), py.Int(t), py.Int(p), py.String(""), s.ipAddress(ip, portNum)})
+		}
+	}
+	return result, nil
+}
+
+const getdefaulttimeout_doc = `getdefaulttimeout() -> timeout
+
+Returns the default timeout in seconds (float) for new socket objects.
+A value of None indicates that new socket objects have no timeout.
+When the socket module is first imported, the default is None.`
+
+func socket_getdefaulttimeout(self py.Object) (py.Object, error) {
+	return timeoutObject(defaultTimeout), nil
+}
+
+const setdefaulttimeout_doc = `setdefaulttimeout(timeout)
+
+Set the default timeout in seconds (float) for new socket objects.
+A value of None indicates that new socket objects have no timeout.
+When the socket module is first imported, the default is None.`
+
+func socket_setdefaulttimeout(self, timeout py.Object) (py.Object, error) {
+	t, err := timeoutArg(timeout)
+	if err != nil {
+		return nil, err
+	}
+	defaultTimeout = t
+	return py.None, nil
+}
+
+// The byte order of the host
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// Makes a function converting an integer between host and network
+// byte order, which is the same both ways
+func byteOrderFunc(name string, bits uint, doc string) *py.Method {
+	return py.MustNewMethod(name, func(self, x py.Object) (py.Object, error) {
+		n, err := py.IndexInt(x)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 || uint64(n) >= 1<<bits {
+			return nil, py.ExceptionNewf(py.OverflowError, "%s: Python int too large to convert to C %d-bit unsigned integer", name, bits)
+		}
+		var buf [4]byte
+		if bits == 16 {
+			binary.BigEndian.PutUint16(buf[:], uint16(n))
+			return py.Int(nativeEndian.Uint16(buf[:])), nil
+		}
+		binary.BigEndian.PutUint32(buf[:], uint32(n))
+		return py.Int(nativeEndian.Uint32(buf[:])), nil
+	}, 0, doc)
+}
+
+const inet_aton_doc = `inet_aton(string) -> bytes giving packed 32-bit IP representation
+
+Convert an IP address in string format (123.45.67.89) to the 32-bit packed
+binary format used in low-level network functions.`
+
+func socket_inet_aton(self, addr py.Object) (py.Object, error) {
+	s, ok := addr.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "inet_aton() argument 1 must be str, not %s", addr.Type().Name)
+	}
+	ip := net.ParseIP(string(s)).To4()
+	if ip == nil {
+		return nil, py.ExceptionNewf(py.OSError, "illegal IP address string passed to inet_aton")
+	}
+	return py.Bytes(ip), nil
+}
+
+const inet_ntoa_doc = `inet_ntoa(packed_ip) -> ip_address_string
+
+Convert an IP address from 32-bit packed binary format to string format`
+
+func socket_inet_ntoa(self, packed py.Object) (py.Object, error) {
+	b, err := bytesArg("inet_ntoa", packed)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 4 {
+		return nil, py.ExceptionNewf(py.OSError, "packed IP wrong length for inet_ntoa")
+	}
+	return py.String(net.IP(b).String()), nil
+}
+
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("create_connection", socket_create_connection, 0, create_connection_doc),
+		py.MustNewMethod("create_server", socket_create_server, 0, create_server_doc),
+		py.MustNewMethod("gethostname", socket_gethostname, 0, gethostname_doc),
+		py.MustNewMethod("gethostbyname", socket_gethostbyname, 0, gethostbyname_doc),
+		py.MustNewMethod("getaddrinfo", socket_getaddrinfo, 0, getaddrinfo_doc),
+		py.MustNewMethod("getdefaulttimeout", socket_getdefaulttimeout, 0, getdefaulttimeout_doc),
+		py.MustNewMethod("setdefaulttimeout", socket_setdefaulttimeout, 0, setdefaulttimeout_doc),
+		py.MustNewMethod("inet_aton", socket_inet_aton, 0, inet_aton_doc),
+		py.MustNewMethod("inet_ntoa", socket_inet_ntoa, 0, inet_ntoa_doc),
+		byteOrderFunc("htons", 16, "htons(integer) -> integer\n\nConvert a 16-bit unsigned integer from host to network byte order."),
+		byteOrderFunc("ntohs", 16, "ntohs(integer) -> integer\n\nConvert a 16-bit unsigned integer from network to host byte order."),
+		byteOrderFunc("htonl", 32, "htonl(integer) -> integer\n\nConvert a 32-bit integer from host to network byte order."),
+		byteOrderFunc("ntohl", 32, "ntohl(integer) -> integer\n\nConvert a 32-bit integer from network to host byte order."),
+	}
+	globals := py.StringDict{
+		"socket":       SocketType,
+		"SocketType":   SocketType,
+		"error":        py.OSError,
+		"timeout":      py.TimeoutError,
+		"gaierror":     GaiError,
+		"herror":       HError,
+		"has_ipv6":     py.True,
+		"AF_UNSPEC":    py.Int(AF_UNSPEC),
+		"AF_UNIX":      py.Int(AF_UNIX),
+		"AF_INET":      py.Int(AF_INET),
+		"AF_INET6":     py.Int(AF_INET6),
+		"SOCK_STREAM":  py.Int(SOCK_STREAM),
+		"SOCK_DGRAM":   py.Int(SOCK_DGRAM),
+		"IPPROTO_IP":   py.Int(IPPROTO_IP),
+		"IPPROTO_TCP":  py.Int(IPPROTO_TCP),
+		"IPPROTO_UDP":  py.Int(IPPROTO_UDP),
+		"SOL_SOCKET":   py.Int(SOL_SOCKET),
+		"SO_REUSEADDR": py.Int(SO_REUSEADDR),
+		"SO_KEEPALIVE": py.Int(SO_KEEPALIVE),
+		"SO_SNDBUF":    py.Int(SO_SNDBUF),
+		"SO_RCVBUF":    py.Int(SO_RCVBUF),
+		"TCP_NODELAY":  py.Int(TCP_NODELAY),
+		"SHUT_RD":      py.Int(SHUT_RD),
+		"SHUT_WR":      py.Int(SHUT_WR),
+		"SHUT_RDWR":    py.Int(SHUT_RDWR),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "socket",
+		Doc:     socket_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
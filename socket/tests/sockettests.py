@@ -0,0 +1,138 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import socket
+from libtest import *
+
+doc = "constants"
+assert socket.AF_INET == 2
+assert socket.SOCK_STREAM == 1
+assert socket.SOCK_DGRAM == 2
+assert socket.error is OSError
+assert socket.timeout is TimeoutError
+assert issubclass(socket.gaierror, OSError)
+
+doc = "byte order"
+assert socket.ntohs(socket.htons(0x1234)) == 0x1234
+assert socket.ntohl(socket.htonl(0x12345678)) == 0x12345678
+assertRaises(OverflowError, socket.htons, 0x10000)
+assert socket.inet_aton("127.0.0.1") == b"\x7f\x00\x00\x01"
+assert socket.inet_ntoa(b"\x7f\x00\x00\x01") == "127.0.0.1"
+assertRaises(OSError, socket.inet_aton, "not an address")
+
+doc = "new socket"
+s = socket.socket()
+assert s.family == socket.AF_INET
+assert s.type == socket.SOCK_STREAM
+assert s.gettimeout() is None
+assert s.getblocking()
+s.settimeout(1.5)
+assert s.gettimeout() == 1.5
+s.setblocking(False)
+assert s.gettimeout() == 0.0
+assert not s.getblocking()
+assertRaises(ValueError, s.settimeout, -1)
+assert s.fileno() == -1
+assertRaises(OSError, s.getpeername)
+assertRaises(OSError, s.recv, 10)
+s.setsockopt(socket.SOL_SOCKET, socket.SO_REUSEADDR, 1)
+assert s.getsockopt(socket.SOL_SOCKET, socket.SO_REUSEADDR) == 1
+s.close()
+s.close()
+assertRaises(OSError, s.bind, ("127.0.0.1", 0))
+assert "closed" in repr(s)
+
+doc = "default timeout"
+assert socket.getdefaulttimeout() is None
+socket.setdefaulttimeout(3)
+assert socket.socket().gettimeout() == 3.0
+socket.setdefaulttimeout(None)
+assert socket.socket().gettimeout() is None
+
+doc = "tcp"
+server = socket.socket(socket.AF_INET, socket.SOCK_STREAM)
+server.bind(("127.0.0.1", 0))
+server.listen(1)
+host, port = server.getsockname()
+assert host == "127.0.0.1"
+assert port > 0
+assert server.fileno() >= 0
+
+client = socket.create_connection(("127.0.0.1", port), timeout=5)
+conn, addr = server.accept()
+assert addr == client.getsockname()
+assert conn.getpeername() == client.getsockname()
+assert client.getpeername() == ("127.0.0.1", port)
+client.setsockopt(socket.IPPROTO_TCP, socket.TCP_NODELAY, 1)
+
+assert client.send(b"hello") == 5
+client.sendall(bytearray(b" world"))
+got = b""
+while len(got) < 11:
+    got += conn.recv(100)
+assert got == b"hello world"
+assertRaises(TypeError, client.send, "str")
+
+conn.settimeout(0.01)
+assertRaises(socket.timeout, conn.recv, 10)
+conn.setblocking(False)
+assertRaises(BlockingIOError, conn.recv, 10)
+conn.setblocking(True)
+
+with conn:
+    conn.sendall(b"bye")
+    conn.shutdown(socket.SHUT_WR)
+    assert client.recv(10) == b"bye"
+    assert client.recv(10) == b""
+client.close()
+
+server.settimeout(0.01)
+assertRaises(socket.timeout, server.accept)
+server.close()
+
+doc = "connection refused"
+s = socket.socket()
+s.bind(("127.0.0.1", 0))
+addr = s.getsockname()
+s.close()
+s = socket.socket()
+assertRaises(ConnectionRefusedError, s.connect, addr)
+assert s.connect_ex(addr) != 0
+s.close()
+
+doc = "create_server"
+with socket.create_server(("127.0.0.1", 0)) as server:
+    port = server.getsockname()[1]
+    with socket.socket() as client:
+        client.connect(("127.0.0.1", port))
+        conn, _ = server.accept()
+        client.sendall(b"x")
+        assert conn.recv(1) == b"x"
+        conn.close()
+
+doc = "udp"
+receiver = socket.socket(socket.AF_INET, socket.SOCK_DGRAM)
+receiver.bind(("127.0.0.1", 0))
+receiver.settimeout(5)
+sender = socket.socket(socket.AF_INET, socket.SOCK_DGRAM)
+assert sender.sendto(b"ping", receiver.getsockname()) == 4
+data, addr = receiver.recvfrom(100)
+assert data == b"ping"
+assert addr == ("127.0.0.1", sender.getsockname()[1])
+receiver.sendto(b"pong", addr)
+assert sender.recvfrom(100) == (b"pong", receiver.getsockname())
+sender.connect(receiver.getsockname())
+sender.send(b"again")
+assert receiver.recv(100) == b"again"
+sender.close()
+receiver.close()
+
+doc = "names"
+assert isinstance(socket.gethostname(), str)
+assert socket.gethostbyname("127.0.0.1") == "127.0.0.1"
+infos = socket.getaddrinfo("127.0.0.1", 80, socket.AF_INET, socket.SOCK_STREAM)
+assert infos == [(socket.AF_INET, socket.SOCK_STREAM, socket.IPPROTO_TCP, "", ("127.0.0.1", 80))]
+assertRaises(socket.gaierror, socket.gethostbyname, "no-such-host.invalid")
+
+doc = "finished"
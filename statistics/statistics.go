@@ -0,0 +1,417 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Statistics module
+
+package statistics
+
+import (
+	"math"
+
+	"github.com/go-python/gpython/py"
+)
+
+// StatisticsError is raised for statistics related errors, such as
+// empty data
+var StatisticsError = py.ValueError.NewType("StatisticsError", "", nil, nil)
+
+// Fetch the data out of the iterable passed in as a list
+func getData(arg py.Object) (*py.List, error) {
+	return py.SequenceList(arg)
+}
+
+// Fetch the data out of the iterable passed in as a sorted list
+func getSortedData(arg py.Object, name string) (*py.List, error) {
+	data, err := getData(arg)
+	if err != nil {
+		return nil, err
+	}
+	err = py.SortInPlace(data, nil, name)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Returns true if o is an integer type
+func isInteger(o py.Object) bool {
+	switch o.(type) {
+	case py.Int, *py.BigInt, py.Bool:
+		return true
+	}
+	return false
+}
+
+// Adds up all the items starting from int 0
+func sum(items []py.Object) (py.Object, error) {
+	var total py.Object = py.Int(0)
+	var err error
+	for _, item := range items {
+		total, err = py.Add(total, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+// Divides a by n
+//
+// If a is an integer and the division is exact then an integer is
+// returned, otherwise the result of true division.
+func divide(a py.Object, n int) (py.Object, error) {
+	if isInteger(a) {
+		q, r, err := py.DivMod(a, py.Int(n))
+		if err != nil {
+			return nil, err
+		}
+		if !py.ObjectIsTrue(r) {
+			return q, nil
+		}
+	}
+	return py.TrueDiv(a, py.Int(n))
+}
+
+// Returns the mean of the items
+func mean(items []py.Object) (py.Object, error) {
+	total, err := sum(items)
+	if err != nil {
+		return nil, err
+	}
+	return divide(total, len(items))
+}
+
+// Returns the sum of square deviations of the items about c
+//
+// If c is nil then the mean of the items is used
+func ss(items []py.Object, c py.Object) (py.Object, error) {
+	var err error
+	if c == nil {
+		c, err = mean(items)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var total py.Object = py.Int(0)
+	var deviations py.Object = py.Int(0)
+	for _, item := range items {
+		d, err := py.Sub(item, c)
+		if err != nil {
+			return nil, err
+		}
+		deviations, err = py.Add(deviations, d)
+		if err != nil {
+			return nil, err
+		}
+		d2, err := py.Mul(d, d)
+		if err != nil {
+			return nil, err
+		}
+		total, err = py.Add(total, d2)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Correct for rounding errors in the mean - the sum of the
+	// deviations should be zero
+	correction, err := py.Mul(deviations, deviations)
+	if err != nil {
+		return nil, err
+	}
+	correction, err = divide(correction, len(items))
+	if err != nil {
+		return nil, err
+	}
+	return py.Sub(total, correction)
+}
+
+// Returns the variance of the data using n - ddof as the divisor
+//
+// muName is the name of the optional argument giving the mean
+func variance(args py.Tuple, kwargs py.StringDict, name, muName string, ddof int) (py.Object, error) {
+	var dataObj py.Object
+	var mu py.Object = py.None
+	kwlist := []string{"data", muName}
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:"+name, kwlist, &dataObj, &mu)
+	if err != nil {
+		return nil, err
+	}
+	if mu == py.None {
+		mu = nil
+	}
+	data, err := getData(dataObj)
+	if err != nil {
+		return nil, err
+	}
+	n := len(data.Items)
+	if n < 1+ddof {
+		if ddof == 0 {
+			return nil, py.ExceptionNewf(StatisticsError, "%s requires at least one data point", name)
+		}
+		return nil, py.ExceptionNewf(StatisticsError, "%s requires at least two data points", name)
+	}
+	total, err := ss(data.Items, mu)
+	if err != nil {
+		return nil, err
+	}
+	return divide(total, n-ddof)
+}
+
+// Returns the square root of x as a float
+func sqrt(x py.Object) (py.Object, error) {
+	f, err := py.FloatAsFloat64(x)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(math.Sqrt(f)), nil
+}
+
+const statistics_mean_doc = `mean(data) -> arithmetic mean
+
+Return the sample arithmetic mean of data.
+
+    >>> mean([1, 2, 3, 4, 4])
+    2.8
+
+If data is empty, StatisticsError will be raised.`
+
+func statistics_mean(self py.Object, arg py.Object) (py.Object, error) {
+	data, err := getData(arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Items) < 1 {
+		return nil, py.ExceptionNewf(StatisticsError, "mean requires at least one data point")
+	}
+	return mean(data.Items)
+}
+
+const statistics_median_doc = `median(data) -> median
+
+Return the median (middle value) of numeric data.
+
+When the number of data points is odd, return the middle data point.
+When the number of data points is even, the median is interpolated by
+taking the average of the two middle values:
+
+    >>> median([1, 3, 5])
+    3
+    >>> median([1, 3, 5, 7])
+    4.0
+
+If data is empty, StatisticsError will be raised.`
+
+func statistics_median(self py.Object, arg py.Object) (py.Object, error) {
+	data, err := getSortedData(arg, "median")
+	if err != nil {
+		return nil, err
+	}
+	n := len(data.Items)
+	if n == 0 {
+		return nil, py.ExceptionNewf(StatisticsError, "no median for empty data")
+	}
+	if n%2 == 1 {
+		return data.Items[n/2], nil
+	}
+	total, err := py.Add(data.Items[n/2-1], data.Items[n/2])
+	if err != nil {
+		return nil, err
+	}
+	return py.TrueDiv(total, py.Int(2))
+}
+
+const statistics_median_low_doc = `median_low(data) -> low median
+
+Return the low median of numeric data.
+
+When the number of data points is odd, the middle value is returned.
+When it is even, the smaller of the two middle values is returned.
+
+If data is empty, StatisticsError will be raised.`
+
+func statistics_median_low(self py.Object, arg py.Object) (py.Object, error) {
+	data, err := getSortedData(arg, "median_low")
+	if err != nil {
+		return nil, err
+	}
+	n := len(data.Items)
+	if n == 0 {
+		return nil, py.ExceptionNewf(StatisticsError, "no median for empty data")
+	}
+	if n%2 == 1 {
+		return data.Items[n/2], nil
+	}
+	return data.Items[n/2-1], nil
+}
+
+const statistics_median_high_doc = `median_high(data) -> high median
+
+Return the high median of data.
+
+When the number of data points is odd, the middle value is returned.
+When it is even, the larger of the two middle values is returned.
+
+If data is empty, StatisticsError will be raised.`
+
+func statistics_median_high(self py.Object, arg py.Object) (py.Object, error) {
+	data, err := getSortedData(arg, "median_high")
+	if err != nil {
+		return nil, err
+	}
+	n := len(data.Items)
+	if n == 0 {
+		return nil, py.ExceptionNewf(StatisticsError, "no median for empty data")
+	}
+	return data.Items[n/2], nil
+}
+
+const statistics_mode_doc = `mode(data) -> most common value
+
+Return the most common data point from discrete or nominal data.
+
+    >>> mode([1, 1, 2, 3, 3, 3, 3, 4])
+    3
+
+If there is more than one most common value, the first one
+encountered is returned.
+
+If data is empty, StatisticsError will be raised.`
+
+func statistics_mode(self py.Object, arg py.Object) (py.Object, error) {
+	data, err := getData(arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Items) == 0 {
+		return nil, py.ExceptionNewf(StatisticsError, "no mode for empty data")
+	}
+	// Count the items in order of first appearance using equality
+	// so this works for objects which can't be dictionary keys
+	var values []py.Object
+	var counts []int
+	for _, item := range data.Items {
+		found := false
+		for i, value := range values {
+			eq, err := py.Eq(item, value)
+			if err != nil {
+				return nil, err
+			}
+			if py.ObjectIsTrue(eq) {
+				counts[i]++
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, item)
+			counts = append(counts, 1)
+		}
+	}
+	best := 0
+	for i := range counts {
+		if counts[i] > counts[best] {
+			best = i
+		}
+	}
+	return values[best], nil
+}
+
+const statistics_variance_doc = `variance(data, xbar=None) -> sample variance
+
+Return the sample variance of data.
+
+data should be an iterable of Real-valued numbers, with at least two
+values. The optional argument xbar, if given, should be the mean of
+the data. If it is missing or None, the mean is automatically
+calculated.
+
+    >>> variance([2.75, 1.75, 1.25, 0.25, 0.5, 1.25, 3.5])
+    1.3720238095238095
+
+If data has fewer than two values, StatisticsError will be raised.`
+
+func statistics_variance(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return variance(args, kwargs, "variance", "xbar", 1)
+}
+
+const statistics_pvariance_doc = `pvariance(data, mu=None) -> population variance
+
+Return the population variance of data.
+
+data should be an iterable of Real-valued numbers, with at least one
+value. The optional argument mu, if given, should be the mean of
+the data. If it is missing or None, the mean is automatically
+calculated.
+
+    >>> pvariance([0.0, 0.25, 0.25, 1.25, 1.5, 1.75, 2.75, 3.25])
+    1.25
+
+If data is empty, StatisticsError will be raised.`
+
+func statistics_pvariance(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return variance(args, kwargs, "pvariance", "mu", 0)
+}
+
+const statistics_stdev_doc = `stdev(data, xbar=None) -> sample standard deviation
+
+Return the square root of the sample variance.
+
+    >>> stdev([1.5, 2.5, 2.5, 2.75, 3.25, 4.75])
+    1.0810874155219827
+
+See variance for arguments and other details.`
+
+func statistics_stdev(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	v, err := variance(args, kwargs, "stdev", "xbar", 1)
+	if err != nil {
+		return nil, err
+	}
+	return sqrt(v)
+}
+
+const statistics_pstdev_doc = `pstdev(data, mu=None) -> population standard deviation
+
+Return the square root of the population variance.
+
+    >>> pstdev([1.5, 2.5, 2.5, 2.75, 3.25, 4.75])
+    0.986893273527251
+
+See pvariance for arguments and other details.`
+
+func statistics_pstdev(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	v, err := variance(args, kwargs, "pstdev", "mu", 0)
+	if err != nil {
+		return nil, err
+	}
+	return sqrt(v)
+}
+
+const statistics_doc = `Basic statistics module.
+
+This module provides functions for calculating statistics of data,
+including averages, variance, and standard deviation.`
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("mean", statistics_mean, 0, statistics_mean_doc),
+		py.MustNewMethod("median", statistics_median, 0, statistics_median_doc),
+		py.MustNewMethod("median_high", statistics_median_high, 0, statistics_median_high_doc),
+		py.MustNewMethod("median_low", statistics_median_low, 0, statistics_median_low_doc),
+		py.MustNewMethod("mode", statistics_mode, 0, statistics_mode_doc),
+		py.MustNewMethod("pstdev", statistics_pstdev, 0, statistics_pstdev_doc),
+		py.MustNewMethod("pvariance", statistics_pvariance, 0, statistics_pvariance_doc),
+		py.MustNewMethod("stdev", statistics_stdev, 0, statistics_stdev_doc),
+		py.MustNewMethod("variance", statistics_variance, 0, statistics_variance_doc),
+	}
+	globals := py.StringDict{
+		"StatisticsError": StatisticsError,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "statistics",
+		Doc:     statistics_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,95 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import statistics
+from statistics import StatisticsError
+from libtest import *
+
+def approx(a, b, eps=1e-12):
+    assert abs(a - b) <= eps, "%r != %r" % (a, b)
+
+doc="StatisticsError"
+try:
+    raise StatisticsError("boom")
+except ValueError:
+    pass
+else:
+    fail("StatisticsError not a ValueError")
+
+doc="mean"
+assertEqual(statistics.mean([1, 2, 3, 4, 4]), 2.8)
+assertEqual(statistics.mean([1, 2, 3]), 2)
+assertEqual(type(statistics.mean([1, 2, 3])), int)
+assertEqual(statistics.mean([1, 2, 3, 4]), 2.5)
+assertEqual(statistics.mean([-1.0, 2.5, 3.25, 5.75]), 2.625)
+assertEqual(statistics.mean([1, 2.5]), 1.75)
+assertEqual(statistics.mean((x for x in range(10))), 4.5)
+assertEqual(statistics.mean(range(1, 6)), 3)
+assertEqual(statistics.mean([10**30, 10**30 + 2]), 10**30 + 1)
+assertRaises(StatisticsError, statistics.mean, [])
+assertRaises(StatisticsError, statistics.mean, iter([]))
+assertRaises(TypeError, statistics.mean, 1)
+
+doc="median"
+assertEqual(statistics.median([1, 3, 5]), 3)
+assertEqual(statistics.median([5, 1, 3]), 3)
+assertEqual(statistics.median([1, 3, 5, 7]), 4.0)
+assertEqual(statistics.median([7, 1, 5, 3]), 4.0)
+assertEqual(statistics.median([2.5]), 2.5)
+assertEqual(statistics.median(iter([3, 1, 2])), 2)
+data = [3, 1, 2]
+statistics.median(data)
+assertEqual(data, [3, 1, 2])
+assertRaises(StatisticsError, statistics.median, [])
+
+doc="median_low"
+assertEqual(statistics.median_low([1, 3, 5]), 3)
+assertEqual(statistics.median_low([1, 3, 5, 7]), 3)
+assertEqual(statistics.median_low([7, 5, 3, 1]), 3)
+assertRaises(StatisticsError, statistics.median_low, [])
+
+doc="median_high"
+assertEqual(statistics.median_high([1, 3, 5]), 3)
+assertEqual(statistics.median_high([1, 3, 5, 7]), 5)
+assertEqual(statistics.median_high([7, 5, 3, 1]), 5)
+assertRaises(StatisticsError, statistics.median_high, [])
+
+doc="mode"
+assertEqual(statistics.mode([1, 1, 2, 3, 3, 3, 3, 4]), 3)
+assertEqual(statistics.mode(["red", "blue", "blue", "red", "green", "red"]), "red")
+assertEqual(statistics.mode([1.5]), 1.5)
+assertEqual(statistics.mode([1, 2, 2, 1]), 1)
+assertEqual(statistics.mode([(1, 2), (3, 4), (1, 2)]), (1, 2))
+assertRaises(StatisticsError, statistics.mode, [])
+
+doc="variance"
+approx(statistics.variance([2.75, 1.75, 1.25, 0.25, 0.5, 1.25, 3.5]), 1.3720238095238095)
+assertEqual(statistics.variance([1, 2, 3]), 1)
+assertEqual(type(statistics.variance([1, 2, 3])), int)
+approx(statistics.variance([1, 2, 3, 4]), 1.6666666666666667)
+approx(statistics.variance([1, 2, 3, 4], 2.5), 1.6666666666666667)
+approx(statistics.variance([1, 2, 3, 4], xbar=2.5), 1.6666666666666667)
+assertEqual(statistics.variance([5, 5, 5]), 0)
+assertRaises(StatisticsError, statistics.variance, [])
+assertRaises(StatisticsError, statistics.variance, [1])
+
+doc="pvariance"
+approx(statistics.pvariance([0.0, 0.25, 0.25, 1.25, 1.5, 1.75, 2.75, 3.25]), 1.25)
+assertEqual(statistics.pvariance([1, 2, 3, 4]), 1.25)
+assertEqual(statistics.pvariance([1, 2, 3, 4], mu=2.5), 1.25)
+assertEqual(statistics.pvariance([7]), 0)
+assertRaises(StatisticsError, statistics.pvariance, [])
+
+doc="stdev"
+approx(statistics.stdev([1.5, 2.5, 2.5, 2.75, 3.25, 4.75]), 1.0810874155219827)
+assertEqual(statistics.stdev([1, 2, 3]), 1.0)
+assertEqual(type(statistics.stdev([1, 2, 3])), float)
+assertRaises(StatisticsError, statistics.stdev, [1])
+
+doc="pstdev"
+approx(statistics.pstdev([1.5, 2.5, 2.5, 2.75, 3.25, 4.75]), 0.986893273527251)
+assertEqual(statistics.pstdev([2, 4, 4, 4, 5, 5, 7, 9]), 2.0)
+assertRaises(StatisticsError, statistics.pstdev, [])
+
+doc="finished"
@@ -0,0 +1,797 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Struct module
+//
+// The package can't be called struct as that is a go keyword.
+package pystruct
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"unsafe"
+
+	"github.com/go-python/gpython/py"
+)
+
+// StructError is raised for bad formats and values which don't fit
+var StructError = py.ExceptionType.NewType("error", "", nil, nil)
+
+var StructType = py.NewTypeX("Struct", struct_doc, StructNew, nil)
+
+// The byte order of the machine
+var nativeOrder binary.ByteOrder = binary.LittleEndian
+
+// The sizes of C long and of C pointers
+var (
+	nativeLongSize    = 8
+	nativePointerSize = int(unsafe.Sizeof(uintptr(0)))
+)
+
+func init() {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 0 {
+		nativeOrder = binary.BigEndian
+	}
+	if runtime.GOOS == "windows" || nativePointerSize == 4 {
+		nativeLongSize = 4
+	}
+}
+
+// The sizes of the format characters when not in native mode
+var standardSizes = map[byte]int{
+	'x': 1, 'c': 1, 'b': 1, 'B': 1, '?': 1,
+	'h': 2, 'H': 2, 'i': 4, 'I': 4, 'l': 4, 'L': 4, 'q': 8, 'Q': 8,
+	'e': 2, 'f': 4, 'd': 8, 's': 1, 'p': 1,
+}
+
+// Returns the size of the format character c in native mode or 0 if
+// it isn't one
+func nativeSize(c byte) int {
+	switch c {
+	case 'l', 'L':
+		return nativeLongSize
+	case 'n', 'N', 'P':
+		return nativePointerSize
+	}
+	return standardSizes[c]
+}
+
+// A format character with the number of items it stands for
+type formatCode struct {
+	code   byte
+	count  int // the number of items, or the length of s and p
+	offset int // of the first item
+	size   int // of each item
+}
+
+// A parsed struct format
+type format struct {
+	format string
+	order  binary.ByteOrder
+	native bool // native sizes and alignment
+	codes  []formatCode
+	size   int // of the packed bytes
+	items  int // number of values packed
+}
+
+// Returns an error for the struct module
+func structErrorf(format string, args ...interface{}) error {
+	return py.ExceptionNewf(StructError, format, args...)
+}
+
+// Parses the format string f
+func parseFormat(f string) (*format, error) {
+	fm := &format{format: f, order: nativeOrder, native: true}
+	s := f
+	if len(s) > 0 {
+		switch s[0] {
+		case '@':
+			s = s[1:]
+		case '=':
+			fm.native = false
+			s = s[1:]
+		case '<':
+			fm.order, fm.native = binary.LittleEndian, false
+			s = s[1:]
+		case '>', '!':
+			fm.order, fm.native = binary.BigEndian, false
+			s = s[1:]
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			continue
+		}
+		count := 1
+		if c >= '0' && c <= '9' {
+			count = 0
+			for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+				count = count*10 + int(s[i]-'0')
+				if count > math.MaxInt32 {
+					return nil, structErrorf("total struct size too long")
+				}
+			}
+			if i >= len(s) {
+				return nil, structErrorf("repeat count given without format specifier")
+			}
+			c = s[i]
+		}
+		var size int
+		if fm.native {
+			size = nativeSize(c)
+		} else {
+			size = standardSizes[c]
+		}
+		if size == 0 {
+			return nil, structErrorf("bad char in struct format")
+		}
+		if fm.native && fm.size%size != 0 {
+			fm.size += size - fm.size%size
+		}
+		fm.codes = append(fm.codes, formatCode{code: c, count: count, offset: fm.size, size: size})
+		fm.size += count * size
+		switch c {
+		case 'x':
+		case 's', 'p':
+			fm.items++
+		default:
+			fm.items += count
+		}
+	}
+	return fm, nil
+}
+
+// Returns the format from a str or bytes
+func getFormat(obj py.Object) (*format, error) {
+	switch x := obj.(type) {
+	case py.String:
+		return parseFormat(string(x))
+	case py.Bytes:
+		return parseFormat(string(x))
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "Struct() argument 1 must be a str or bytes object, not %s", obj.Type().Name)
+}
+
+// Returns the bytes of the buffer obj
+func getBuffer(obj py.Object) ([]byte, error) {
+	if b, ok := obj.(py.Bytes); ok {
+		return b, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not '%s'", obj.Type().Name)
+}
+
+// Returns the integer value of obj
+func getInteger(obj py.Object) (*big.Int, error) {
+	var err error
+	if _, isInt := obj.(py.Int); !isInt {
+		if I, ok := obj.(py.I__index__); ok {
+			if _, isBig := obj.(*py.BigInt); !isBig {
+				obj, err = I.M__index__()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	switch x := obj.(type) {
+	case py.Int:
+		return big.NewInt(int64(x)), nil
+	case *py.BigInt:
+		return (*big.Int)(x), nil
+	}
+	return nil, structErrorf("required argument is not an integer")
+}
+
+// Returns the error for an integer out of range for code
+func rangeError(code byte, size int, unsigned bool, negative bool) error {
+	switch code {
+	case 'b':
+		return structErrorf("byte format requires -128 <= number <= 127")
+	case 'B':
+		return structErrorf("ubyte format requires 0 <= number <= 255")
+	case 'h':
+		return structErrorf("short format requires -32768 <= number <= 32767")
+	case 'H':
+		return structErrorf("ushort format requires 0 <= number <= 65535")
+	}
+	if size == 8 || (unsigned && negative) {
+		return structErrorf("argument out of range")
+	}
+	bits := uint(8 * size)
+	if unsigned {
+		return structErrorf("'%c' format requires 0 <= number <= %d", code, uint64(1)<<bits-1)
+	}
+	return structErrorf("'%c' format requires %d <= number <= %d", code, -(int64(1) << (bits - 1)), int64(1)<<(bits-1)-1)
+}
+
+// Writes u into b in the byte order
+func putUint(order binary.ByteOrder, b []byte, u uint64) {
+	switch len(b) {
+	case 1:
+		b[0] = byte(u)
+	case 2:
+		order.PutUint16(b, uint16(u))
+	case 4:
+		order.PutUint32(b, uint32(u))
+	case 8:
+		order.PutUint64(b, u)
+	}
+}
+
+// Reads an unsigned integer from b in the byte order
+func getUint(order binary.ByteOrder, b []byte) uint64 {
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(order.Uint16(b))
+	case 4:
+		return uint64(order.Uint32(b))
+	}
+	return order.Uint64(b)
+}
+
+// Returns x as an IEEE 754 half precision float rounding to nearest even
+func float16Bits(x float64) (uint16, error) {
+	overflow := py.ExceptionNewf(py.OverflowError, "float too large to pack with e format")
+	var sign uint16
+	if math.Signbit(x) {
+		sign = 0x8000
+	}
+	switch {
+	case math.IsNaN(x):
+		return sign | 0x7e00, nil
+	case math.IsInf(x, 0):
+		return sign | 0x7c00, nil
+	case x == 0:
+		return sign, nil
+	}
+	frac, exp := math.Frexp(math.Abs(x))
+	f, e := 2*frac, exp-1 // x = f * 2**e with 1 <= f < 2
+	if e >= 16 {
+		return 0, overflow
+	}
+	if e < -14 {
+		// Subnormal
+		f = math.Ldexp(f, e+14)
+		e = 0
+	} else {
+		f -= 1
+		e += 15
+	}
+	f *= 1024
+	bits := uint16(f)
+	rem := f - float64(bits)
+	if rem > 0.5 || (rem == 0.5 && bits&1 == 1) {
+		bits++
+		if bits == 1024 {
+			bits = 0
+			e++
+			if e == 31 {
+				return 0, overflow
+			}
+		}
+	}
+	return sign | uint16(e)<<10 | bits, nil
+}
+
+// Returns the value of the IEEE 754 half precision float h
+func float16FromBits(h uint16) float64 {
+	e := int(h >> 10 & 0x1f)
+	f := float64(h & 0x3ff)
+	var x float64
+	switch e {
+	case 0:
+		x = math.Ldexp(f, -24)
+	case 31:
+		if f == 0 {
+			x = math.Inf(1)
+		} else {
+			x = math.NaN()
+		}
+	default:
+		x = math.Ldexp(f+1024, e-25)
+	}
+	if h&0x8000 != 0 {
+		x = -x
+	}
+	return x
+}
+
+// Packs value into b as the format character code
+func (fm *format) packItem(code byte, b []byte, value py.Object) error {
+	switch code {
+	case 'c':
+		s, ok := value.(py.Bytes)
+		if !ok || len(s) != 1 {
+			return structErrorf("char format requires a bytes object of length 1")
+		}
+		b[0] = s[0]
+	case '?':
+		if py.ObjectIsTrue(value) {
+			b[0] = 1
+		}
+	case 'e', 'f', 'd':
+		x, err := py.FloatAsFloat64(value)
+		if err != nil {
+			return structErrorf("required argument is not a float")
+		}
+		switch code {
+		case 'e':
+			h, err := float16Bits(x)
+			if err != nil {
+				return err
+			}
+			putUint(fm.order, b, uint64(h))
+		case 'f':
+			if !fm.native && math.Abs(x) > math.MaxFloat32 && !math.IsInf(x, 0) {
+				return py.ExceptionNewf(py.OverflowError, "float too large to pack with f format")
+			}
+			putUint(fm.order, b, uint64(math.Float32bits(float32(x))))
+		case 'd':
+			putUint(fm.order, b, math.Float64bits(x))
+		}
+	default:
+		n, err := getInteger(value)
+		if err != nil {
+			return err
+		}
+		unsigned := code == 'B' || code == 'H' || code == 'I' || code == 'L' || code == 'Q' || code == 'N' || code == 'P'
+		bits := uint(8 * len(b))
+		if unsigned {
+			limit := new(big.Int).Lsh(big.NewInt(1), bits)
+			if code == 'P' && n.Sign() < 0 {
+				// Pointers wrap around like C
+				n = new(big.Int).Add(n, limit)
+			}
+			if n.Sign() < 0 || n.Cmp(limit) >= 0 {
+				return rangeError(code, len(b), true, n.Sign() < 0)
+			}
+			putUint(fm.order, b, n.Uint64())
+		} else {
+			limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+			if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+				return rangeError(code, len(b), false, n.Sign() < 0)
+			}
+			putUint(fm.order, b, uint64(n.Int64()))
+		}
+	}
+	return nil
+}
+
+// Unpacks the format character code from b
+func (fm *format) unpackItem(code byte, b []byte) py.Object {
+	switch code {
+	case 'c':
+		return py.Bytes{b[0]}
+	case '?':
+		return py.NewBool(b[0] != 0)
+	case 'e':
+		return py.Float(float16FromBits(uint16(getUint(fm.order, b))))
+	case 'f':
+		return py.Float(math.Float32frombits(uint32(getUint(fm.order, b))))
+	case 'd':
+		return py.Float(math.Float64frombits(getUint(fm.order, b)))
+	case 'B', 'H', 'I', 'L', 'Q', 'N', 'P':
+		u := getUint(fm.order, b)
+		if u > math.MaxInt64 {
+			return (*py.BigInt)(new(big.Int).SetUint64(u))
+		}
+		return py.Int(u)
+	}
+	// Sign extend
+	shift := uint(64 - 8*len(b))
+	return py.Int(int64(getUint(fm.order, b)<<shift) >> shift)
+}
+
+// Packs values into a new byte slice
+func (fm *format) pack(values py.Tuple, name string) ([]byte, error) {
+	if len(values) != fm.items {
+		return nil, structErrorf("%s expected %d items for packing (got %d)", name, fm.items, len(values))
+	}
+	b := make([]byte, fm.size)
+	i := 0
+	for _, c := range fm.codes {
+		switch c.code {
+		case 'x':
+			continue
+		case 's', 'p':
+			s, ok := values[i].(py.Bytes)
+			if !ok {
+				return nil, structErrorf("argument for '%c' must be a bytes object", c.code)
+			}
+			i++
+			field := b[c.offset : c.offset+c.count]
+			if c.code == 's' {
+				copy(field, s)
+				continue
+			}
+			if c.count == 0 {
+				continue
+			}
+			n := len(s)
+			if n > c.count-1 {
+				n = c.count - 1
+			}
+			if n > 255 {
+				n = 255
+			}
+			field[0] = byte(n)
+			copy(field[1:], s[:n])
+			continue
+		}
+		for j := 0; j < c.count; j++ {
+			offset := c.offset + j*c.size
+			err := fm.packItem(c.code, b[offset:offset+c.size], values[i])
+			if err != nil {
+				return nil, err
+			}
+			i++
+		}
+	}
+	return b, nil
+}
+
+// Unpacks the values from b which must be fm.size long
+func (fm *format) unpack(b []byte) py.Tuple {
+	values := make(py.Tuple, 0, fm.items)
+	for _, c := range fm.codes {
+		switch c.code {
+		case 'x':
+			continue
+		case 's':
+			values = append(values, py.Bytes(append([]byte(nil), b[c.offset:c.offset+c.count]...)))
+			continue
+		case 'p':
+			var s py.Bytes
+			if c.count > 0 {
+				n := int(b[c.offset])
+				if n > c.count-1 {
+					n = c.count - 1
+				}
+				s = append(py.Bytes{}, b[c.offset+1:c.offset+1+n]...)
+			} else {
+				s = py.Bytes{}
+			}
+			values = append(values, s)
+			continue
+		}
+		for j := 0; j < c.count; j++ {
+			offset := c.offset + j*c.size
+			values = append(values, fm.unpackItem(c.code, b[offset:offset+c.size]))
+		}
+	}
+	return values
+}
+
+// Returns the offset into a buffer of length n checking it is in range
+func checkOffset(offsetObj py.Object, n int) (int, error) {
+	offset, err := py.IndexInt(offsetObj)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		if offset+n < 0 {
+			return 0, structErrorf("offset %d out of range for %d-byte buffer", offset, n)
+		}
+		offset += n
+	}
+	return offset, nil
+}
+
+// Unpacks the values from buffer starting at offset
+func (fm *format) unpackFrom(buffer, offsetObj py.Object) (py.Object, error) {
+	b, err := getBuffer(buffer)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := checkOffset(offsetObj, len(b))
+	if err != nil {
+		return nil, err
+	}
+	if len(b)-offset < fm.size {
+		return nil, structErrorf("unpack_from requires a buffer of at least %d bytes for unpacking %d bytes at offset %d (actual buffer size is %d)", fm.size+offset, fm.size, offset, len(b))
+	}
+	return fm.unpack(b[offset : offset+fm.size]), nil
+}
+
+// Packs values into the writable buffer starting at offset
+func (fm *format) packInto(buffer, offsetObj py.Object, values py.Tuple) error {
+	b, err := fm.pack(values, "pack_into")
+	if err != nil {
+		return err
+	}
+	n, err := py.Len(buffer)
+	if err != nil {
+		return err
+	}
+	offset, err := checkOffset(offsetObj, int(n.(py.Int)))
+	if err != nil {
+		return err
+	}
+	if int(n.(py.Int))-offset < fm.size {
+		return structErrorf("pack_into requires a buffer of at least %d bytes for packing %d bytes at offset %d (actual buffer size is %d)", fm.size+offset, fm.size, offset, n)
+	}
+	_, err = py.SetItem(buffer, &py.Slice{Start: py.Int(offset), Stop: py.Int(offset + fm.size), Step: py.None}, py.Bytes(b))
+	return err
+}
+
+// Returns an iterator unpacking the values from consecutive chunks of
+// buffer
+func (fm *format) iterUnpack(buffer py.Object) (py.Object, error) {
+	b, err := getBuffer(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if fm.size == 0 {
+		return nil, structErrorf("cannot iteratively unpack with a struct of length 0")
+	}
+	if len(b)%fm.size != 0 {
+		return nil, structErrorf("iterative unpacking requires a buffer of a multiple of %d bytes", fm.size)
+	}
+	var items []py.Object
+	for i := 0; i < len(b); i += fm.size {
+		items = append(items, fm.unpack(b[i:i+fm.size]))
+	}
+	return py.NewIterator(items), nil
+}
+
+const struct_doc = `Struct(fmt) --> compiled struct object
+
+Return a new Struct object which writes and reads binary data according to
+the format string fmt.  See help(struct) for more on format strings.`
+
+// Struct is a compiled format
+type Struct struct {
+	format *format
+}
+
+// Type of this object
+func (s *Struct) Type() *py.Type {
+	return StructType
+}
+
+// StructNew compiles a format
+func StructNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var formatObj py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:Struct", []string{"format"}, &formatObj)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := getFormat(formatObj)
+	if err != nil {
+		return nil, err
+	}
+	return &Struct{format: fm}, nil
+}
+
+func (s *Struct) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<_struct.Struct object at %p>", s)), nil
+}
+
+func init() {
+	StructType.Dict["pack"] = py.MustNewMethod("pack", func(self py.Object, args py.Tuple) (py.Object, error) {
+		b, err := self.(*Struct).format.pack(args, "pack")
+		if err != nil {
+			return nil, err
+		}
+		return py.Bytes(b), nil
+	}, 0, "S.pack(v1, v2, ...) -> bytes\n\nReturn a bytes object containing values v1, v2, ... packed according\nto the format string S.format.  See help(struct) for more on format\nstrings.")
+	StructType.Dict["pack_into"] = py.MustNewMethod("pack_into", func(self py.Object, args py.Tuple) (py.Object, error) {
+		if len(args) < 2 {
+			return nil, py.ExceptionNewf(py.TypeError, "pack_into expected buffer argument and offset")
+		}
+		return py.None, self.(*Struct).format.packInto(args[0], args[1], args[2:])
+	}, 0, "S.pack_into(buffer, offset, v1, v2, ...)\n\nPack the values v1, v2, ... according to the format string S.format\nand write the packed bytes into the writable buffer buf starting at\noffset.  Note that the offset is a required argument.  See\nhelp(struct) for more on format strings.")
+	StructType.Dict["unpack"] = py.MustNewMethod("unpack", func(self, buffer py.Object) (py.Object, error) {
+		return unpack(self.(*Struct).format, buffer)
+	}, 0, "Return a tuple containing unpacked values.\n\nUnpack according to the format string Struct.format. The buffer's size\nin bytes must be Struct.size.\n\nSee help(struct) for more on format strings.")
+	StructType.Dict["unpack_from"] = py.MustNewMethod("unpack_from", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var buffer py.Object
+		var offset py.Object = py.Int(0)
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:unpack_from", []string{"buffer", "offset"}, &buffer, &offset)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Struct).format.unpackFrom(buffer, offset)
+	}, 0, "Return a tuple containing unpacked values.\n\nValues are unpacked according to the format string Struct.format.\n\nThe buffer's size in bytes, starting at position offset, must be\nat least Struct.size.\n\nSee help(struct) for more on format strings.")
+	StructType.Dict["iter_unpack"] = py.MustNewMethod("iter_unpack", func(self, buffer py.Object) (py.Object, error) {
+		return self.(*Struct).format.iterUnpack(buffer)
+	}, 0, "Return an iterator yielding tuples.\n\nTuples are unpacked from the given bytes source, like a repeated\ninvocation of unpack_from().\n\nRequires that the bytes length be a multiple of the struct size.")
+	StructType.Dict["format"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*Struct).format.format), nil
+		},
+		Doc: "struct format string",
+	}
+	StructType.Dict["size"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*Struct).format.size), nil
+		},
+		Doc: "struct size in bytes",
+	}
+}
+
+// Unpacks buffer which must be exactly the size of the format
+func unpack(fm *format, buffer py.Object) (py.Object, error) {
+	b, err := getBuffer(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != fm.size {
+		return nil, structErrorf("unpack requires a buffer of %d bytes", fm.size)
+	}
+	return fm.unpack(b), nil
+}
+
+// Returns the format from the first argument
+func formatArg(args py.Tuple, name string, min int) (*format, py.Tuple, error) {
+	if len(args) < min {
+		return nil, nil, py.ExceptionNewf(py.TypeError, "%s expected at least %d arguments, got %d", name, min, len(args))
+	}
+	fm, err := getFormat(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return fm, args[1:], nil
+}
+
+const pack_doc = `pack(format, v1, v2, ...) -> bytes
+
+Return a bytes object containing the values v1, v2, ... packed according
+to the format string.  See help(struct) for more on format strings.`
+
+func struct_pack(self py.Object, args py.Tuple) (py.Object, error) {
+	fm, values, err := formatArg(args, "pack", 1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := fm.pack(values, "pack")
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(b), nil
+}
+
+const pack_into_doc = `pack_into(format, buffer, offset, v1, v2, ...)
+
+Pack the values v1, v2, ... according to the format string and write
+the packed bytes into the writable buffer buf starting at offset.  Note
+that the offset is a required argument.  See help(struct) for more
+on format strings.`
+
+func struct_pack_into(self py.Object, args py.Tuple) (py.Object, error) {
+	fm, rest, err := formatArg(args, "pack_into", 3)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, fm.packInto(rest[0], rest[1], rest[2:])
+}
+
+const unpack_doc = `Return a tuple containing values unpacked according to the format string.
+
+The buffer's size in bytes must be calcsize(format).
+
+See help(struct) for more on format strings.`
+
+func struct_unpack(self py.Object, args py.Tuple) (py.Object, error) {
+	var formatObj, buffer py.Object
+	err := py.UnpackTuple(args, nil, "unpack", 2, 2, &formatObj, &buffer)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := getFormat(formatObj)
+	if err != nil {
+		return nil, err
+	}
+	return unpack(fm, buffer)
+}
+
+const unpack_from_doc = `Return a tuple containing values unpacked according to the format string.
+
+The buffer's size, minus offset, must be at least calcsize(format).
+
+See help(struct) for more on format strings.`
+
+func struct_unpack_from(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var formatObj, buffer py.Object
+	var offset py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:unpack_from", []string{"format", "buffer", "offset"}, &formatObj, &buffer, &offset)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := getFormat(formatObj)
+	if err != nil {
+		return nil, err
+	}
+	return fm.unpackFrom(buffer, offset)
+}
+
+const iter_unpack_doc = `Return an iterator yielding tuples unpacked from the given bytes.
+
+The bytes are unpacked according to the format string, like
+a repeated invocation of unpack_from().
+
+Requires that the bytes length be a multiple of the format struct size.`
+
+func struct_iter_unpack(self py.Object, args py.Tuple) (py.Object, error) {
+	var formatObj, buffer py.Object
+	err := py.UnpackTuple(args, nil, "iter_unpack", 2, 2, &formatObj, &buffer)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := getFormat(formatObj)
+	if err != nil {
+		return nil, err
+	}
+	return fm.iterUnpack(buffer)
+}
+
+const calcsize_doc = `Return size in bytes of the struct described by the format string.`
+
+func struct_calcsize(self, formatObj py.Object) (py.Object, error) {
+	fm, err := getFormat(formatObj)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(fm.size), nil
+}
+
+const module_doc = `Functions to convert between Python values and C structs.
+Python bytes objects are used to hold the data representing the C struct
+and also as format strings (explained below) to describe the layout of data
+in the C struct.
+
+The optional first format char indicates byte order, size and alignment:
+  @: native order, size & alignment (default)
+  =: native order, std. size & alignment
+  <: little-endian, std. size & alignment
+  >: big-endian, std. size & alignment
+  !: same as >
+
+The remaining chars indicate types of args and must match exactly;
+these can be preceded by a decimal repeat count:
+  x: pad byte (no data); c:char; b:signed byte; B:unsigned byte;
+  ?: _Bool (requires C99; if not available, char is used instead)
+  h:short; H:unsigned short; i:int; I:unsigned int;
+  l:long; L:unsigned long; f:float; d:double; e:half-float.
+Special cases (preceding decimal count indicates length):
+  s:string (array of char); p: pascal string (with count byte).
+Special cases (only available in native format):
+  n:ssize_t; N:size_t;
+  P:an integer type that is wide enough to hold a pointer.
+Special case (not in native mode unless 'long long' in platform C):
+  q:long long; Q:unsigned long long
+Whitespace between formats is ignored.
+
+The variable struct.error is an exception raised on errors.`
+
+// Initialise the module
+func init() {
+	StructError.Dict["__module__"] = py.String("struct")
+	methods := []*py.Method{
+		py.MustNewMethod("calcsize", struct_calcsize, 0, calcsize_doc),
+		py.MustNewMethod("iter_unpack", struct_iter_unpack, 0, iter_unpack_doc),
+		py.MustNewMethod("pack", struct_pack, 0, pack_doc),
+		py.MustNewMethod("pack_into", struct_pack_into, 0, pack_into_doc),
+		py.MustNewMethod("unpack", struct_unpack, 0, unpack_doc),
+		py.MustNewMethod("unpack_from", struct_unpack_from, 0, unpack_from_doc),
+	}
+	globals := py.StringDict{
+		"Struct": StructType,
+		"error":  StructError,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "struct",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
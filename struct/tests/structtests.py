@@ -0,0 +1,159 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import struct
+from struct import pack, unpack, calcsize, unpack_from, iter_unpack, Struct
+from libtest import *
+
+doc = "calcsize"
+assert calcsize("") == 0
+assert calcsize("b") == 1
+assert calcsize("<bhiq") == 15
+assert calcsize("=bi") == 5
+assert calcsize("@bi") == 8
+assert calcsize("bi") == 8
+assert calcsize("bxq") == 16
+assert calcsize("ci0q") == 8
+assert calcsize("10s") == 10
+assert calcsize("3i") == 12
+assert calcsize("> 2h \t i") == 8
+assert calcsize(b"<i") == 4
+assertRaisesText(struct.error, "bad char in struct format", calcsize, "z")
+assertRaisesText(struct.error, "bad char in struct format", calcsize, "<n")
+assertRaisesText(struct.error, "bad char in struct format", calcsize, "<P")
+assertRaisesText(struct.error, "repeat count given without format specifier", calcsize, "3")
+assertRaises(TypeError, calcsize, 3)
+
+doc = "pack integers"
+assert pack("<h", 1) == b"\x01\x00"
+assert pack(">h", 1) == b"\x00\x01"
+assert pack("!I", 0xdeadbeef) == b"\xde\xad\xbe\xef"
+assert pack("<q", -2) == b"\xfe\xff\xff\xff\xff\xff\xff\xff"
+assert pack(">Q", 2**64-1) == b"\xff\xff\xff\xff\xff\xff\xff\xff"
+assert pack("<bB", -1, 255) == b"\xff\xff"
+assert pack(">3h", 1, 2, 3) == b"\x00\x01\x00\x02\x00\x03"
+assert pack(">?", 5) == b"\x01"
+assert pack(">?", []) == b"\x00"
+assert pack("<i", True) == b"\x01\x00\x00\x00"
+assert pack("@bi", 1, 2) == pack("=bxxxi", 1, 2)
+assertRaisesText(struct.error, "byte format requires -128 <= number <= 127", pack, "b", 128)
+assertRaisesText(struct.error, "ubyte format requires 0 <= number <= 255", pack, "B", -1)
+assertRaisesText(struct.error, "short format requires -32768 <= number <= 32767", pack, "<h", 32768)
+assertRaisesText(struct.error, "ushort format requires 0 <= number <= 65535", pack, "<H", 65536)
+assertRaisesText(struct.error, "'i' format requires -2147483648 <= number <= 2147483647", pack, "<i", 2**31)
+assertRaisesText(struct.error, "'I' format requires 0 <= number <= 4294967295", pack, "<I", 2**32)
+assertRaisesText(struct.error, "argument out of range", pack, "<I", -1)
+assertRaisesText(struct.error, "argument out of range", pack, "<q", 2**63)
+assertRaisesText(struct.error, "argument out of range", pack, "<Q", -1)
+assertRaisesText(struct.error, "required argument is not an integer", pack, "<i", 1.5)
+assertRaisesText(struct.error, "required argument is not an integer", pack, "<i", "1")
+
+doc = "pack floats"
+assert pack("<d", 1.5) == b"\x00\x00\x00\x00\x00\x00\xf8\x3f"
+assert pack(">f", 1.5) == b"\x3f\xc0\x00\x00"
+assert pack(">e", 1.5) == b"\x3e\x00"
+assert pack(">e", 65504.0) == b"\x7b\xff"
+assert pack(">e", -0.0) == b"\x80\x00"
+assert pack(">e", 2**-24) == b"\x00\x01"
+assert pack(">e", float("inf")) == b"\x7c\x00"
+assert pack(">e", float("nan")) == b"\x7e\x00"
+assert pack(">d", 2) == pack(">d", 2.0)
+assertRaises(OverflowError, pack, ">e", 65520.0)
+assertRaises(OverflowError, pack, "<f", 1e300)
+assertRaisesText(struct.error, "required argument is not a float", pack, "<d", "x")
+
+doc = "pack bytes"
+assert pack("c", b"a") == b"a"
+assert pack("3s", b"ab") == b"ab\x00"
+assert pack("3s", b"abcd") == b"abc"
+assert pack("0s", b"abc") == b""
+assert pack("4p", b"ab") == b"\x02ab\x00"
+assert pack("3p", b"abcd") == b"\x02ab"
+assert pack("<2xh", 1) == b"\x00\x00\x01\x00"
+assertRaisesText(struct.error, "char format requires a bytes object of length 1", pack, "c", b"ab")
+assertRaisesText(struct.error, "argument for 's' must be a bytes object", pack, "s", "a")
+assertRaisesText(struct.error, "argument for 'p' must be a bytes object", pack, "p", 1)
+
+doc = "pack argument count"
+assertRaisesText(struct.error, "pack expected 2 items for packing (got 1)", pack, "hh", 1)
+assertRaisesText(struct.error, "pack expected 1 items for packing (got 2)", pack, "3s", b"a", b"b")
+assertRaises(TypeError, pack)
+
+doc = "unpack"
+assert unpack("<h", b"\x01\x00") == (1,)
+assert unpack(">h", b"\xff\xfe") == (-2,)
+assert unpack("<bB", b"\xff\xff") == (-1, 255)
+assert unpack(">Q", b"\xff\xff\xff\xff\xff\xff\xff\xff") == (2**64-1,)
+assert unpack("<q", b"\xfe\xff\xff\xff\xff\xff\xff\xff") == (-2,)
+assert unpack(">f", b"\x3f\xc0\x00\x00") == (1.5,)
+assert unpack(">e", b"\x7b\xff") == (65504.0,)
+assert unpack(">e", b"\x00\x01") == (2**-24,)
+assert unpack(">e", b"\xfc\x00") == (float("-inf"),)
+x, = unpack(">e", b"\x7e\x00")
+assert x != x
+assert unpack("<d", pack("<d", 0.1)) == (0.1,)
+assert unpack("??", b"\x00\x02") == (False, True)
+assert unpack("c3s", b"abcd") == (b"a", b"bcd")
+assert unpack("4p", b"\x02abc") == (b"ab",)
+assert unpack("4p", b"\x09abc") == (b"abc",)
+assert unpack("<2xh", b"\x00\x00\x01\x00") == (1,)
+assert unpack("", b"") == ()
+assert unpack("bhiq2s?", pack("bhiq2s?", 1, 2, -3, 4, b"xy", True)) == (1, 2, -3, 4, b"xy", True)
+assert unpack("@lLnNP", pack("@lLnNP", -1, 2, -3, 4, 5)) == (-1, 2, -3, 4, 5)
+assert unpack(b"<i", b"\x01\x00\x00\x00") == (1,)
+assertRaisesText(struct.error, "unpack requires a buffer of 4 bytes", unpack, "<i", b"\x00")
+assertRaisesText(TypeError, "a bytes-like object is required, not 'str'", unpack, "<i", "abcd")
+
+doc = "unpack_from"
+buf = b"\x00\x01\x00\x02\x00\x03"
+assert unpack_from(">h", buf) == (1,)
+assert unpack_from(">h", buf, 2) == (2,)
+assert unpack_from(">h", buf, offset=4) == (3,)
+assert unpack_from(">h", buf, -2) == (3,)
+assert unpack_from(">2h", buf, 1) == (256, 512)
+assertRaisesText(struct.error, "unpack_from requires a buffer of at least 8 bytes for unpacking 2 bytes at offset 6 (actual buffer size is 6)", unpack_from, ">h", buf, 6)
+assertRaisesText(struct.error, "offset -10 out of range for 6-byte buffer", unpack_from, ">h", buf, -10)
+
+doc = "iter_unpack"
+assert list(iter_unpack(">h", buf)) == [(1,), (2,), (3,)]
+assert list(iter_unpack(">hh", b"")) == []
+assertRaisesText(struct.error, "iterative unpacking requires a buffer of a multiple of 4 bytes", iter_unpack, ">hh", buf)
+assertRaisesText(struct.error, "cannot iteratively unpack with a struct of length 0", iter_unpack, "", buf)
+
+doc = "pack_into"
+try:
+    bytearray
+except NameError:
+    pass
+else:
+    b = bytearray(6)
+    struct.pack_into(">hh", b, 1, 1, 2)
+    assert bytes(b) == b"\x00\x00\x01\x00\x02\x00"
+    struct.pack_into(">h", b, -2, 3)
+    assert bytes(b) == b"\x00\x00\x01\x00\x00\x03"
+    assertRaisesText(struct.error, "pack_into requires a buffer of at least 8 bytes for packing 4 bytes at offset 4 (actual buffer size is 6)", struct.pack_into, ">hh", b, 4, 1, 2)
+assertRaises(TypeError, struct.pack_into, ">h", b"\x00\x00", 0, 1)
+
+doc = "Struct"
+s = Struct(">hi")
+assert s.format == ">hi"
+assert s.size == 6
+assert s.pack(1, 2) == b"\x00\x01\x00\x00\x00\x02"
+assert s.unpack(b"\x00\x01\x00\x00\x00\x02") == (1, 2)
+assert s.unpack_from(b"\xff\x00\x01\x00\x00\x00\x02", 1) == (1, 2)
+assert s.unpack_from(b"\xff\x00\x01\x00\x00\x00\x02", offset=1) == (1, 2)
+assert list(s.iter_unpack(b"\x00\x01\x00\x00\x00\x02\x00\x01\x00\x00\x00\x02")) == [(1, 2), (1, 2)]
+assert Struct(b"<h").format == "<h"
+assert repr(s).startswith("<_struct.Struct object at ")
+assertRaisesText(struct.error, "pack expected 2 items for packing (got 3)", s.pack, 1, 2, 3)
+assertRaisesText(struct.error, "unpack requires a buffer of 6 bytes", s.unpack, b"")
+assertRaises(struct.error, Struct, "y")
+assertRaises(TypeError, Struct, 1)
+
+doc = "error"
+assert issubclass(struct.error, Exception)
+assert struct.error.__module__ == "struct"
+assert struct.error.__name__ == "error"
+
+doc = "finished"
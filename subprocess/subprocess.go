@@ -0,0 +1,1115 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Subprocess module
+//
+// Processes are started with Go's os/exec.  The GIL is released while
+// waiting for them so other python threads can run.
+
+package subprocess
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+const subprocess_doc = `Subprocesses with accessible I/O streams
+
+This module allows you to spawn processes, connect to their
+input/output/error pipes, and obtain their return codes.
+
+Use run() to run a command and wait for it to finish, or Popen to
+start one and interact with it while it runs.  stdin, stdout and stderr
+can be None to use those of this process, PIPE to make a pipe to the
+process, DEVNULL to use os.devnull, a file descriptor or a file object.
+stderr can also be STDOUT to send it to the same place as stdout.`
+
+// Special values of stdin, stdout and stderr
+const (
+	PIPE    = py.Int(-1)
+	STDOUT  = py.Int(-2)
+	DEVNULL = py.Int(-3)
+)
+
+var (
+	SubprocessError    = py.ExceptionType.NewType("SubprocessError", "Base class for the exceptions of the subprocess module.", nil, nil)
+	CalledProcessError = SubprocessError.NewType("CalledProcessError", "Raised when a process run by check_call() or check_output() returns a non-zero exit status.", CalledProcessErrorNew, nil)
+	TimeoutExpired     = SubprocessError.NewType("TimeoutExpired", "Raised when the timeout expires while waiting for a child process.", TimeoutExpiredNew, nil)
+
+	PopenType             = py.NewTypeX("Popen", popen_doc, PopenNew, nil)
+	CompletedProcessType  = py.NewTypeX("CompletedProcess", completed_process_doc, CompletedProcessNew, nil)
+	errInvalidFileObject  = py.ExceptionNewf(py.TypeError, "stdin, stdout and stderr must be None, PIPE, DEVNULL, a file descriptor or a file object")
+	errNoCommunicateInput = py.ExceptionNewf(py.ValueError, "Cannot send input after starting communication")
+)
+
+// Makes an exception of type t with args setting the keyword
+// arguments kwlist in its Dict
+func newSubprocessError(t *py.Type, name string, args py.Tuple, kwargs py.StringDict, min int, kwlist []string) (py.Object, error) {
+	results := make([]*py.Object, len(kwlist))
+	values := make([]py.Object, len(kwlist))
+	for i := range values {
+		values[i] = py.None
+		results[i] = &values[i]
+	}
+	format := strings.Repeat("O", min) + "|" + strings.Repeat("O", len(kwlist)-min) + ":" + name
+	err := py.ParseTupleAndKeywords(args, kwargs, format, kwlist, results...)
+	if err != nil {
+		return nil, err
+	}
+	e := py.ExceptionNewf(t, "")
+	e.Args = py.Tuple(values[:min])
+	for i, key := range kwlist[min:] {
+		e.Dict[key] = values[min+i]
+	}
+	return e, nil
+}
+
+// CalledProcessErrorNew makes a CalledProcessError(returncode, cmd,
+// output=None, stderr=None)
+func CalledProcessErrorNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newSubprocessError(metatype, "CalledProcessError", args, kwargs, 2, []string{"returncode", "cmd", "output", "stderr"})
+}
+
+// TimeoutExpiredNew makes a TimeoutExpired(cmd, timeout, output=None,
+// stderr=None)
+func TimeoutExpiredNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newSubprocessError(metatype, "TimeoutExpired", args, kwargs, 2, []string{"cmd", "timeout", "output", "stderr"})
+}
+
+// Returns the exception field which is args[i] or named name in the
+// Dict
+func errorField(self py.Object, i int, name string) py.Object {
+	e := self.(*py.Exception)
+	if args, ok := e.Args.(py.Tuple); ok && i < len(args) {
+		return args[i]
+	}
+	if value, ok := e.Dict[name]; ok {
+		return value
+	}
+	return py.None
+}
+
+// Adds a property for an exception field
+func addErrorField(t *py.Type, i int, name, key string) {
+	t.Dict[name] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return errorField(self, i, key), nil
+		},
+		Fset: func(self, value py.Object) error {
+			e := self.(*py.Exception)
+			if args, ok := e.Args.(py.Tuple); ok && i < len(args) {
+				args[i] = value
+			} else {
+				e.Dict[key] = value
+			}
+			return nil
+		},
+	}
+}
+
+func init() {
+	addErrorField(CalledProcessError, 0, "returncode", "returncode")
+	addErrorField(CalledProcessError, 1, "cmd", "cmd")
+	addErrorField(CalledProcessError, 2, "output", "output")
+	addErrorField(CalledProcessError, 2, "stdout", "output")
+	addErrorField(CalledProcessError, 3, "stderr", "stderr")
+	CalledProcessError.Dict["__str__"] = py.MustNewMethod("__str__", func(self py.Object) (py.Object, error) {
+		cmd, err := py.StrAsString(errorField(self, 1, "cmd"))
+		if err != nil {
+			return nil, err
+		}
+		code, ok := errorField(self, 0, "returncode").(py.Int)
+		if ok && code < 0 {
+			return py.String(fmt.Sprintf("Command '%s' died with signal %d.", cmd, -code)), nil
+		}
+		returncode, err := py.StrAsString(errorField(self, 0, "returncode"))
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("Command '%s' returned non-zero exit status %s.", cmd, returncode)), nil
+	}, 0, "Return str(self).")
+
+	addErrorField(TimeoutExpired, 0, "cmd", "cmd")
+	addErrorField(TimeoutExpired, 1, "timeout", "timeout")
+	addErrorField(TimeoutExpired, 2, "output", "output")
+	addErrorField(TimeoutExpired, 2, "stdout", "output")
+	addErrorField(TimeoutExpired, 3, "stderr", "stderr")
+	TimeoutExpired.Dict["__str__"] = py.MustNewMethod("__str__", func(self py.Object) (py.Object, error) {
+		cmd, err := py.StrAsString(errorField(self, 0, "cmd"))
+		if err != nil {
+			return nil, err
+		}
+		timeout, err := py.StrAsString(errorField(self, 1, "timeout"))
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("Command '%s' timed out after %s seconds", cmd, timeout)), nil
+	}, 0, "Return str(self).")
+}
+
+// Makes a CalledProcessError
+func newCalledProcessError(returncode, cmd, output, stderr py.Object) error {
+	e := py.ExceptionNewf(CalledProcessError, "")
+	e.Args = py.Tuple{returncode, cmd}
+	e.Dict["output"] = output
+	e.Dict["stderr"] = stderr
+	return e
+}
+
+// Makes a TimeoutExpired
+func newTimeoutExpired(cmd py.Object, timeout float64, output, stderr py.Object) error {
+	e := py.ExceptionNewf(TimeoutExpired, "")
+	e.Args = py.Tuple{cmd, py.Float(timeout)}
+	e.Dict["output"] = output
+	e.Dict["stderr"] = stderr
+	return e
+}
+
+const popen_doc = `Popen(args, bufsize=-1, executable=None, stdin=None, stdout=None,
+      stderr=None, close_fds=True, shell=False, cwd=None, env=None,
+      universal_newlines=None, text=None) -> a child process
+
+Execute a child program in a new process.
+
+args is a sequence of program arguments or a string.  With shell=True
+a string is run by /bin/sh (cmd.exe on Windows).  executable replaces
+the program to run, cwd sets the directory it runs in and env, if not
+None, is a mapping of all its environment variables.  With text or
+universal_newlines set the streams are opened in text mode.`
+
+// Popen is a child process
+type Popen struct {
+	cmd        *exec.Cmd
+	args       py.Object
+	text       bool
+	stdin      py.Object // the stdin pipe or None
+	stdout     py.Object // the stdout pipe or None
+	stderr     py.Object // the stderr pipe or None
+	returncode py.Object
+	done       chan struct{} // closed when the process has exited
+	exitCode   int           // set before done is closed
+	comm       *communication
+}
+
+// The state of a communicate call which can be resumed after a
+// timeout
+type communication struct {
+	done            chan struct{} // closed when the output has been read
+	stdout, stderr  []byte
+	stdoutErr, errs error
+}
+
+// Type of this object
+func (p *Popen) Type() *py.Type {
+	return PopenType
+}
+
+// The arguments of Popen
+type popenOptions struct {
+	args                  py.Object
+	executable            py.Object
+	stdin, stdout, stderr py.Object
+	shell                 bool
+	cwd, env              py.Object
+	text                  bool
+}
+
+// The keyword arguments of Popen
+var popenKwlist = []string{"args", "bufsize", "executable", "stdin", "stdout", "stderr", "close_fds", "shell", "cwd", "env", "universal_newlines", "text"}
+
+// Parses the arguments of Popen
+func parsePopenArgs(name string, args py.Tuple, kwargs py.StringDict) (*popenOptions, error) {
+	values := make([]py.Object, len(popenKwlist))
+	results := make([]*py.Object, len(popenKwlist))
+	for i := range values {
+		values[i] = py.None
+		results[i] = &values[i]
+	}
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOOOOOOOOOO:"+name, popenKwlist, results...)
+	if err != nil {
+		return nil, err
+	}
+	o := &popenOptions{
+		args:       values[0],
+		executable: values[2],
+		stdin:      values[3],
+		stdout:     values[4],
+		stderr:     values[5],
+		shell:      py.ObjectIsTrue(values[7]),
+		cwd:        values[8],
+		env:        values[9],
+		text:       py.ObjectIsTrue(values[10]) || py.ObjectIsTrue(values[11]),
+	}
+	return o, nil
+}
+
+// Returns a path argument
+func pathString(arg py.Object) (string, error) {
+	if s, ok := arg.(py.String); ok {
+		return string(s), nil
+	}
+	path, ok, err := py.TypeCall0(arg, "__fspath__")
+	if err != nil {
+		return "", err
+	}
+	if s, isString := path.(py.String); ok && isString {
+		return string(s), nil
+	}
+	return "", py.ExceptionNewf(py.TypeError, "expected str, bytes or os.PathLike object, not %s", arg.Type().Name)
+}
+
+// Returns the program and arguments to run
+func (o *popenOptions) argv() ([]string, error) {
+	var argv []string
+	if s, ok := o.args.(py.String); ok {
+		argv = []string{string(s)}
+	} else {
+		var loopErr error
+		err := py.Iterate(o.args, func(arg py.Object) bool {
+			var s string
+			s, loopErr = pathString(arg)
+			argv = append(argv, s)
+			return loopErr != nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if loopErr != nil {
+			return nil, loopErr
+		}
+	}
+	if len(argv) == 0 {
+		return nil, py.ExceptionNewf(py.IndexError, "list index out of range")
+	}
+	if o.shell {
+		if runtime.GOOS == "windows" {
+			comspec := os.Getenv("COMSPEC")
+			if comspec == "" {
+				comspec = "cmd.exe"
+			}
+			argv = append([]string{comspec, "/c"}, argv...)
+		} else {
+			argv = append([]string{"/bin/sh", "-c"}, argv...)
+		}
+	}
+	return argv, nil
+}
+
+// Returns the environment to run the process in
+func (o *popenOptions) environ() ([]string, error) {
+	if o.env == py.None {
+		return nil, nil
+	}
+	items, err := py.GetAttrString(o.env, "items")
+	if err != nil {
+		return nil, err
+	}
+	items, err = py.Call(items, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	env := []string{}
+	var loopErr error
+	err = py.Iterate(items, func(item py.Object) bool {
+		kv, ok := item.(py.Tuple)
+		if !ok || len(kv) != 2 {
+			loopErr = py.ExceptionNewf(py.TypeError, "env must be a mapping")
+			return true
+		}
+		key, ok1 := kv[0].(py.String)
+		value, ok2 := kv[1].(py.String)
+		if !ok1 || !ok2 {
+			loopErr = py.ExceptionNewf(py.TypeError, "environment variables must be str")
+			return true
+		}
+		if strings.ContainsRune(string(key), '=') {
+			loopErr = py.ExceptionNewf(py.ValueError, "illegal environment variable name")
+			return true
+		}
+		env = append(env, string(key)+"="+string(value))
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return env, loopErr
+}
+
+// The child's end of a stream and the parent's end if it is a pipe
+type stream struct {
+	child  *os.File
+	parent *os.File
+	close  bool // set if child is to be closed once the child has started
+}
+
+// Returns the stream for the stdin, stdout or stderr argument spec
+// where std is the stream of this process
+func openStream(spec py.Object, std *os.File, input bool) (stream, error) {
+	switch spec {
+	case py.None:
+		return stream{child: std}, nil
+	case PIPE:
+		r, w, err := os.Pipe()
+		if err != nil {
+			return stream{}, py.MakeOSError(err)
+		}
+		if input {
+			return stream{child: r, parent: w, close: true}, nil
+		}
+		return stream{child: w, parent: r, close: true}, nil
+	case DEVNULL:
+		flag := os.O_WRONLY
+		if input {
+			flag = os.O_RDONLY
+		}
+		f, err := os.OpenFile(os.DevNull, flag, 0)
+		if err != nil {
+			return stream{}, py.MakeOSError(err)
+		}
+		return stream{child: f, close: true}, nil
+	}
+	if f, ok := spec.(*py.File); ok {
+		if _, err := f.Flush(); err != nil {
+			return stream{}, err
+		}
+		return stream{child: f.File}, nil
+	}
+	fd := spec
+	if _, ok := spec.(py.Int); !ok {
+		fileno, err := py.GetAttrString(spec, "fileno")
+		if err != nil {
+			return stream{}, errInvalidFileObject
+		}
+		fd, err = py.Call(fileno, nil, nil)
+		if err != nil {
+			return stream{}, err
+		}
+	}
+	n, ok := fd.(py.Int)
+	if !ok || n < 0 {
+		return stream{}, errInvalidFileObject
+	}
+	return stream{child: os.NewFile(uintptr(n), fmt.Sprintf("fd %d", n))}, nil
+}
+
+// Returns the file object for the parent's end of a pipe
+func (p *Popen) pipeFile(f *os.File, mode py.FileMode) py.Object {
+	if f == nil {
+		return py.None
+	}
+	if !p.text {
+		mode |= py.FileBinary
+	}
+	return &py.File{File: f, FileMode: mode}
+}
+
+// Returns the return code of a process which has finished
+func exitCode(state *os.ProcessState) int {
+	if state == nil {
+		return -1
+	}
+	if ws, ok := state.Sys().(interface {
+		Signaled() bool
+		Signal() syscall.Signal
+	}); ok && ws.Signaled() {
+		return -int(ws.Signal())
+	}
+	return state.ExitCode()
+}
+
+// Starts the process described by o
+func startPopen(o *popenOptions) (*Popen, error) {
+	argv, err := o.argv()
+	if err != nil {
+		return nil, err
+	}
+	env, err := o.environ()
+	if err != nil {
+		return nil, err
+	}
+	program := argv[0]
+	if o.executable != py.None {
+		program, err = pathString(o.executable)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = py.Audit("subprocess.Popen", py.String(program), o.args, o.cwd, o.env)
+	if err != nil {
+		return nil, err
+	}
+	p := &Popen{
+		args:       o.args,
+		text:       o.text,
+		returncode: py.None,
+		done:       make(chan struct{}),
+	}
+	cmd := &exec.Cmd{
+		Path: program,
+		Args: argv,
+		Env:  env,
+	}
+	if o.cwd != py.None {
+		cmd.Dir, err = pathString(o.cwd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !strings.ContainsAny(program, `/\`) {
+		path, err := exec.LookPath(program)
+		if err != nil {
+			return nil, py.MakeOSError(&os.PathError{Op: "exec", Path: program, Err: syscall.ENOENT})
+		}
+		cmd.Path = path
+	} else if cmd.Dir != "" && !strings.HasPrefix(program, "/") {
+		// A relative program is relative to the working directory
+		// of the child as it is with exec
+		cmd.Path = cmd.Dir + "/" + program
+	}
+
+	var streams []stream
+	defer func() {
+		for _, s := range streams {
+			if s.close {
+				s.child.Close()
+			}
+		}
+	}()
+	for i, spec := range []py.Object{o.stdin, o.stdout, o.stderr} {
+		var s stream
+		if i == 2 && spec == STDOUT {
+			s = stream{child: streams[1].child}
+		} else {
+			s, err = openStream(spec, []*os.File{os.Stdin, os.Stdout, os.Stderr}[i], i == 0)
+			if err != nil {
+				for _, s := range streams {
+					if s.parent != nil {
+						s.parent.Close()
+					}
+				}
+				return nil, err
+			}
+		}
+		streams = append(streams, s)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = streams[0].child, streams[1].child, streams[2].child
+
+	if err = cmd.Start(); err != nil {
+		for _, s := range streams {
+			if s.parent != nil {
+				s.parent.Close()
+			}
+		}
+		if _, ok := err.(*os.PathError); ok {
+			return nil, py.MakeOSError(err)
+		}
+		return nil, py.ExceptionNewf(py.OSError, "%v", err)
+	}
+	p.cmd = cmd
+	p.stdin = p.pipeFile(streams[0].parent, py.FileWrite)
+	p.stdout = p.pipeFile(streams[1].parent, py.FileRead)
+	p.stderr = p.pipeFile(streams[2].parent, py.FileRead)
+	go func() {
+		_ = cmd.Wait()
+		p.exitCode = exitCode(cmd.ProcessState)
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// PopenNew starts a child process
+func PopenNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	o, err := parsePopenArgs("Popen", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return startPopen(o)
+}
+
+// Waits for done to be closed for at most timeout seconds if timeout
+// isn't negative, returning false on timing out
+func waitFor(done chan struct{}, timeout float64) bool {
+	finished := true
+	vm.AllowThreads(func() {
+		if timeout < 0 {
+			<-done
+			return
+		}
+		timer := time.NewTimer(time.Duration(timeout * float64(time.Second)))
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			finished = false
+		}
+	})
+	return finished
+}
+
+// Returns a timeout argument in seconds or -1 for None
+func timeoutArg(timeout py.Object) (float64, error) {
+	if timeout == py.None {
+		return -1, nil
+	}
+	f, err := py.FloatAsFloat64(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 {
+		f = 0
+	}
+	return f, nil
+}
+
+// Poll returns the return code if the process has finished or None
+func (p *Popen) Poll() py.Object {
+	select {
+	case <-p.done:
+		p.returncode = py.Int(p.exitCode)
+	default:
+	}
+	return p.returncode
+}
+
+// Wait waits for the process to finish for at most timeout seconds if
+// timeout isn't negative, returning the return code
+func (p *Popen) Wait(timeout float64) (py.Object, error) {
+	if !waitFor(p.done, timeout) {
+		return nil, newTimeoutExpired(p.args, timeout, py.None, py.None)
+	}
+	return p.Poll(), nil
+}
+
+// Closes the file object f if it is one
+func closeFile(f py.Object) error {
+	if f, ok := f.(*py.File); ok {
+		_, err := f.Close()
+		return err
+	}
+	return nil
+}
+
+// Returns whether the file object f is closed
+func isClosed(f *py.File) bool {
+	closed, err := py.GetAttrString(f, "closed")
+	return err == nil && closed == py.True
+}
+
+// Returns the output read from a pipe as str or bytes
+func (p *Popen) output(pipe py.Object, data []byte) py.Object {
+	if pipe == py.None {
+		return py.None
+	}
+	if p.text {
+		s := strings.Replace(string(data), "\r\n", "\n", -1)
+		return py.String(strings.Replace(s, "\r", "\n", -1))
+	}
+	return py.Bytes(data)
+}
+
+// Communicate sends input to the process, reads its output until the
+// end and waits for it to finish, for at most timeout seconds if
+// timeout isn't negative.  It returns the output and the error output
+// read from the pipes.
+func (p *Popen) Communicate(input py.Object, timeout float64) (py.Object, py.Object, error) {
+	if p.comm == nil {
+		var data []byte
+		switch x := input.(type) {
+		case py.NoneType:
+		case py.String:
+			if !p.text {
+				return nil, nil, py.ExceptionNewf(py.TypeError, "a bytes-like object is required, not 'str'")
+			}
+			data = []byte(x)
+		case py.Bytes:
+			if p.text {
+				return nil, nil, py.ExceptionNewf(py.TypeError, "write() argument must be str, not bytes")
+			}
+			data = x
+		default:
+			return nil, nil, py.ExceptionNewf(py.TypeError, "input must be str or bytes, not %s", input.Type().Name)
+		}
+		comm := &communication{done: make(chan struct{})}
+		var wg sync.WaitGroup
+		// A closed stdin has nothing more to send
+		if stdin, ok := p.stdin.(*py.File); ok && !isClosed(stdin) {
+			if _, err := stdin.Flush(); err != nil {
+				return nil, nil, err
+			}
+			f := stdin.File
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if len(data) > 0 {
+					// A process which exits without reading its
+					// input gives a broken pipe which is ignored
+					_, _ = f.Write(data)
+				}
+				_ = f.Close()
+			}()
+		}
+		read := func(pipe py.Object, out *[]byte, outErr *error) {
+			if f, ok := pipe.(*py.File); ok {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					*out, *outErr = ioutil.ReadAll(f.File)
+				}()
+			}
+		}
+		read(p.stdout, &comm.stdout, &comm.stdoutErr)
+		read(p.stderr, &comm.stderr, &comm.errs)
+		go func() {
+			wg.Wait()
+			<-p.done
+			close(comm.done)
+		}()
+		p.comm = comm
+	} else if input != py.None {
+		return nil, nil, errNoCommunicateInput
+	}
+	if !waitFor(p.comm.done, timeout) {
+		return nil, nil, newTimeoutExpired(p.args, timeout, py.None, py.None)
+	}
+	for _, f := range []py.Object{p.stdin, p.stdout, p.stderr} {
+		_ = closeFile(f)
+	}
+	p.Poll()
+	for _, err := range []error{p.comm.stdoutErr, p.comm.errs} {
+		if err != nil {
+			return nil, nil, py.MakeOSError(err)
+		}
+	}
+	return p.output(p.stdout, p.comm.stdout), p.output(p.stderr, p.comm.stderr), nil
+}
+
+// Kill kills the process if it is still running
+func (p *Popen) Kill() error {
+	return p.signal(os.Kill)
+}
+
+// Sends sig to the process if it is still running
+func (p *Popen) signal(sig os.Signal) error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	err := p.cmd.Process.Signal(sig)
+	if err != nil && err.Error() != "os: process already finished" {
+		return py.MakeOSError(err)
+	}
+	return nil
+}
+
+func (p *Popen) M__repr__() (py.Object, error) {
+	args, err := py.ReprAsString(p.args)
+	if err != nil {
+		return nil, err
+	}
+	returncode, err := py.ReprAsString(p.returncode)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("<Popen: returncode: %s args: %s>", returncode, args)), nil
+}
+
+func (p *Popen) M__enter__() (py.Object, error) {
+	return p, nil
+}
+
+// Closes the pipes and waits for the process
+func (p *Popen) M__exit__(exc_type, exc_value, traceback py.Object) (py.Object, error) {
+	for _, f := range []py.Object{p.stdout, p.stderr, p.stdin} {
+		if err := closeFile(f); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.Wait(-1); err != nil {
+		return nil, err
+	}
+	return py.False, nil
+}
+
+func init() {
+	PopenType.Dict["poll"] = py.MustNewMethod("poll", func(self py.Object) (py.Object, error) {
+		return self.(*Popen).Poll(), nil
+	}, 0, "poll() -> the return code if the child process has terminated, else None")
+	PopenType.Dict["wait"] = py.MustNewMethod("wait", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var timeout py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:wait", []string{"timeout"}, &timeout)
+		if err != nil {
+			return nil, err
+		}
+		t, err := timeoutArg(timeout)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Popen).Wait(t)
+	}, 0, "wait(timeout=None) -> wait for the child process to terminate, returning its return code.\n\nRaises TimeoutExpired if it is still running after timeout seconds.")
+	PopenType.Dict["communicate"] = py.MustNewMethod("communicate", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var input py.Object = py.None
+		var timeout py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:communicate", []string{"input", "timeout"}, &input, &timeout)
+		if err != nil {
+			return nil, err
+		}
+		t, err := timeoutArg(timeout)
+		if err != nil {
+			return nil, err
+		}
+		stdout, stderr, err := self.(*Popen).Communicate(input, t)
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{stdout, stderr}, nil
+	}, 0, `communicate(input=None, timeout=None) -> (stdout_data, stderr_data)
+
+Send input to the child's stdin, read its stdout and stderr until the
+end and wait for it to terminate.  The data read is None for streams
+which aren't pipes.  Raises TimeoutExpired if it is still running
+after timeout seconds, after which communicate can be called again.`)
+	PopenType.Dict["kill"] = py.MustNewMethod("kill", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*Popen).Kill()
+	}, 0, "kill() -> None.  Kill the child process.")
+	PopenType.Dict["terminate"] = py.MustNewMethod("terminate", func(self py.Object) (py.Object, error) {
+		p := self.(*Popen)
+		if runtime.GOOS == "windows" {
+			return py.None, p.Kill()
+		}
+		return py.None, p.signal(syscall.SIGTERM)
+	}, 0, "terminate() -> None.  Terminate the child process with SIGTERM.")
+	PopenType.Dict["send_signal"] = py.MustNewMethod("send_signal", func(self py.Object, sig py.Object) (py.Object, error) {
+		n, err := py.IndexInt(sig)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Popen).signal(syscall.Signal(n))
+	}, 0, "send_signal(signal) -> None.  Send signal to the child process.")
+	for name, get := range map[string]func(p *Popen) py.Object{
+		"args":       func(p *Popen) py.Object { return p.args },
+		"stdin":      func(p *Popen) py.Object { return p.stdin },
+		"stdout":     func(p *Popen) py.Object { return p.stdout },
+		"stderr":     func(p *Popen) py.Object { return p.stderr },
+		"returncode": func(p *Popen) py.Object { return p.returncode },
+		"pid":        func(p *Popen) py.Object { return py.Int(p.cmd.Process.Pid) },
+	} {
+		get := get
+		PopenType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return get(self.(*Popen)), nil
+			},
+		}
+	}
+}
+
+const completed_process_doc = `CompletedProcess(args, returncode, stdout=None, stderr=None)
+
+A process that has finished running.  This is returned by run().`
+
+// CompletedProcess is the result of run()
+type CompletedProcess struct {
+	Args       py.Object
+	ReturnCode py.Object
+	Stdout     py.Object
+	Stderr     py.Object
+}
+
+// Type of this object
+func (c *CompletedProcess) Type() *py.Type {
+	return CompletedProcessType
+}
+
+// CompletedProcessNew makes a CompletedProcess
+func CompletedProcessNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	c := &CompletedProcess{Stdout: py.None, Stderr: py.None}
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|OO:CompletedProcess", []string{"args", "returncode", "stdout", "stderr"}, &c.Args, &c.ReturnCode, &c.Stdout, &c.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CheckReturnCode raises CalledProcessError if the return code isn't 0
+func (c *CompletedProcess) CheckReturnCode() error {
+	if c.ReturnCode != py.Int(0) {
+		return newCalledProcessError(c.ReturnCode, c.Args, c.Stdout, c.Stderr)
+	}
+	return nil
+}
+
+func (c *CompletedProcess) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("CompletedProcess(")
+	for i, field := range []struct {
+		name  string
+		value py.Object
+	}{{"args", c.Args}, {"returncode", c.ReturnCode}, {"stdout", c.Stdout}, {"stderr", c.Stderr}} {
+		if i >= 2 && field.value == py.None {
+			continue
+		}
+		repr, err := py.ReprAsString(field.value)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(field.name + "=" + repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+func init() {
+	for name, field := range map[string]func(c *CompletedProcess) *py.Object{
+		"args":       func(c *CompletedProcess) *py.Object { return &c.Args },
+		"returncode": func(c *CompletedProcess) *py.Object { return &c.ReturnCode },
+		"stdout":     func(c *CompletedProcess) *py.Object { return &c.Stdout },
+		"stderr":     func(c *CompletedProcess) *py.Object { return &c.Stderr },
+	} {
+		field := field
+		CompletedProcessType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return *field(self.(*CompletedProcess)), nil
+			},
+			Fset: func(self, value py.Object) error {
+				*field(self.(*CompletedProcess)) = value
+				return nil
+			},
+		}
+	}
+	CompletedProcessType.Dict["check_returncode"] = py.MustNewMethod("check_returncode", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*CompletedProcess).CheckReturnCode()
+	}, 0, "check_returncode() -> None.  Raise CalledProcessError if the exit code is non-zero.")
+}
+
+// Removes the keyword arguments which aren't Popen's from kwargs
+// returning their values, or def for those not given
+func popKwargs(kwargs py.StringDict, names []string, defs ...py.Object) (py.StringDict, []py.Object) {
+	rest := kwargs.Copy()
+	values := make([]py.Object, len(names))
+	for i, name := range names {
+		values[i] = defs[i]
+		if value, ok := rest[name]; ok {
+			values[i] = value
+			delete(rest, name)
+		}
+	}
+	return rest, values
+}
+
+// Runs a command as run() does
+func run(name string, args py.Tuple, kwargs py.StringDict) (*CompletedProcess, error) {
+	kwargs, values := popKwargs(kwargs, []string{"input", "capture_output", "timeout", "check"}, py.None, py.False, py.None, py.False)
+	input, captureOutput, timeout, check := values[0], py.ObjectIsTrue(values[1]), values[2], py.ObjectIsTrue(values[3])
+	o, err := parsePopenArgs(name, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if input != py.None {
+		if o.stdin != py.None {
+			return nil, py.ExceptionNewf(py.ValueError, "stdin and input arguments may not both be used.")
+		}
+		o.stdin = PIPE
+	}
+	if captureOutput {
+		if o.stdout != py.None || o.stderr != py.None {
+			return nil, py.ExceptionNewf(py.ValueError, "stdout and stderr arguments may not be used with capture_output.")
+		}
+		o.stdout, o.stderr = PIPE, PIPE
+	}
+	t, err := timeoutArg(timeout)
+	if err != nil {
+		return nil, err
+	}
+	p, err := startPopen(o)
+	if err != nil {
+		return nil, err
+	}
+	stdout, stderr, err := p.Communicate(input, t)
+	if err != nil {
+		if py.IsException(TimeoutExpired, err) {
+			_ = p.Kill()
+			stdout, stderr, _ = p.Communicate(py.None, -1)
+			return nil, newTimeoutExpired(p.args, t, stdout, stderr)
+		}
+		return nil, err
+	}
+	c := &CompletedProcess{Args: p.args, ReturnCode: p.returncode, Stdout: stdout, Stderr: stderr}
+	if check {
+		if err := c.CheckReturnCode(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+const run_doc = `run(args, *, stdin=None, input=None, stdout=None, stderr=None,
+    capture_output=False, shell=False, cwd=None, timeout=None,
+    check=False, env=None, text=None) -> CompletedProcess
+
+Run command with arguments and return a CompletedProcess instance.
+
+The other arguments are the same as for the Popen constructor.  input
+is sent to the process's stdin.  With capture_output set stdout and
+stderr are captured.  If the process runs for more than timeout
+seconds it is killed and TimeoutExpired raised.  If check is set and
+the exit code is non-zero CalledProcessError is raised.`
+
+func subprocess_run(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return run("run", args, kwargs)
+}
+
+// Runs the command returning the Popen once it has finished
+func call(name string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	kwargs, values := popKwargs(kwargs, []string{"timeout"}, py.None)
+	t, err := timeoutArg(values[0])
+	if err != nil {
+		return nil, err
+	}
+	o, err := parsePopenArgs(name, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	p, err := startPopen(o)
+	if err != nil {
+		return nil, err
+	}
+	code, err := p.Wait(t)
+	if err != nil {
+		_ = p.Kill()
+		_, _ = p.Wait(-1)
+		return nil, err
+	}
+	return code, nil
+}
+
+const call_doc = `call(args, *, stdin=None, stdout=None, stderr=None, shell=False,
+     cwd=None, timeout=None, env=None) -> the return code
+
+Run command with arguments.  Wait for command to complete or timeout,
+then return the returncode attribute.`
+
+func subprocess_call(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return call("call", args, kwargs)
+}
+
+const check_call_doc = `check_call(args, *, stdin=None, stdout=None, stderr=None, shell=False,
+           cwd=None, timeout=None, env=None) -> 0
+
+Run command with arguments.  Wait for command to complete.  If the exit
+code was zero then return, otherwise raise CalledProcessError.`
+
+func subprocess_check_call(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	code, err := call("check_call", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if code != py.Int(0) {
+		cmd := kwargs["args"]
+		if len(args) > 0 {
+			cmd = args[0]
+		}
+		return nil, newCalledProcessError(code, cmd, py.None, py.None)
+	}
+	return py.Int(0), nil
+}
+
+const check_output_doc = `check_output(args, *, stdin=None, stderr=None, shell=False, cwd=None,
+             timeout=None, env=None, input=None, text=None) -> output
+
+Run command with arguments and return its output.  If the exit code
+was non-zero it raises a CalledProcessError.`
+
+func subprocess_check_output(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if _, ok := kwargs["stdout"]; ok {
+		return nil, py.ExceptionNewf(py.ValueError, "stdout argument not allowed, it will be overridden.")
+	}
+	kwargs = kwargs.Copy()
+	kwargs["stdout"] = PIPE
+	kwargs["check"] = py.True
+	c, err := run("check_output", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return c.Stdout, nil
+}
+
+// Runs cmd in the shell returning its exit code and its output and
+// error output with a trailing newline removed
+func getStatusOutput(cmd py.Object) (py.Object, py.Object, error) {
+	c, err := run("getstatusoutput", py.Tuple{cmd}, py.StringDict{
+		"shell":  py.True,
+		"text":   py.True,
+		"stdout": PIPE,
+		"stderr": STDOUT,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	output := strings.TrimSuffix(string(c.Stdout.(py.String)), "\n")
+	return c.ReturnCode, py.String(output), nil
+}
+
+const getstatusoutput_doc = `getstatusoutput(cmd) -> (exitcode, output)
+
+Return (exitcode, output) of executing cmd in a shell.  The output is
+stdout and stderr combined with a trailing newline removed.`
+
+func subprocess_getstatusoutput(self, cmd py.Object) (py.Object, error) {
+	code, output, err := getStatusOutput(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{code, output}, nil
+}
+
+const getoutput_doc = `getoutput(cmd) -> output
+
+Return output (stdout and stderr) of executing cmd in a shell.`
+
+func subprocess_getoutput(self, cmd py.Object) (py.Object, error) {
+	_, output, err := getStatusOutput(cmd)
+	return output, err
+}
+
+// Initialise the module
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("call", subprocess_call, 0, call_doc),
+		py.MustNewMethod("check_call", subprocess_check_call, 0, check_call_doc),
+		py.MustNewMethod("check_output", subprocess_check_output, 0, check_output_doc),
+		py.MustNewMethod("getoutput", subprocess_getoutput, 0, getoutput_doc),
+		py.MustNewMethod("getstatusoutput", subprocess_getstatusoutput, 0, getstatusoutput_doc),
+		py.MustNewMethod("run", subprocess_run, 0, run_doc),
+	}
+	globals := py.StringDict{
+		"PIPE":               PIPE,
+		"STDOUT":             STDOUT,
+		"DEVNULL":            DEVNULL,
+		"Popen":              PopenType,
+		"CompletedProcess":   CompletedProcessType,
+		"SubprocessError":    SubprocessError,
+		"CalledProcessError": CalledProcessError,
+		"TimeoutExpired":     TimeoutExpired,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "subprocess",
+		Doc:     subprocess_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package subprocess_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/go-python/gpython/os"
+	"github.com/go-python/gpython/pytest"
+	_ "github.com/go-python/gpython/subprocess"
+)
+
+// The tests run this test binary as the child process with "helper"
+// as its first argument so they don't depend on the commands of the
+// platform
+func TestMain(m *testing.M) {
+	if len(os.Args) > 2 && os.Args[1] == "helper" {
+		os.Exit(helper(os.Args[2], os.Args[3:]))
+	}
+	os.Exit(m.Run())
+}
+
+// Runs the helper command cmd returning the exit code
+func helper(cmd string, args []string) int {
+	switch cmd {
+	case "echo":
+		fmt.Println(strings.Join(args, " "))
+	case "cat":
+		_, _ = io.Copy(os.Stdout, os.Stdin)
+	case "stderr":
+		fmt.Fprintln(os.Stderr, strings.Join(args, " "))
+	case "exit":
+		code, _ := strconv.Atoi(args[0])
+		return code
+	case "getenv":
+		fmt.Println(os.Getenv(args[0]))
+	case "pwd":
+		dir, _ := os.Getwd()
+		fmt.Println(dir)
+	case "sleep":
+		seconds, _ := strconv.ParseFloat(args[0], 64)
+		time.Sleep(time.Duration(seconds * float64(time.Second)))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown helper command %q\n", cmd)
+		return 2
+	}
+	return 0
+}
+
+func TestSubprocess(t *testing.T) {
+	os.Setenv("SUBPROCESS_TEST_HELPER", os.Args[0])
+	defer os.Unsetenv("SUBPROCESS_TEST_HELPER")
+	pytest.RunTests(t, "tests")
+}
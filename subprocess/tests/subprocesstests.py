@@ -0,0 +1,170 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+import subprocess
+from libtest import *
+
+# The Go test runs its own binary as the child process
+HELPER = [os.environ["SUBPROCESS_TEST_HELPER"], "helper"]
+
+doc = "module"
+assert (subprocess.PIPE, subprocess.STDOUT, subprocess.DEVNULL) == (-1, -2, -3)
+assert issubclass(subprocess.SubprocessError, Exception)
+assert issubclass(subprocess.CalledProcessError, subprocess.SubprocessError)
+assert issubclass(subprocess.TimeoutExpired, subprocess.SubprocessError)
+
+doc = "run"
+r = subprocess.run(HELPER + ["echo", "hello", "world"], stdout=subprocess.PIPE)
+assert isinstance(r, subprocess.CompletedProcess)
+assert r.args == HELPER + ["echo", "hello", "world"]
+assert r.returncode == 0
+assert r.stdout == b"hello world\n"
+assert r.stderr is None
+r.check_returncode()
+
+r = subprocess.run(HELPER + ["exit", "3"])
+assert r.returncode == 3
+assertRaises(subprocess.CalledProcessError, r.check_returncode)
+assert r.stdout is None
+
+r = subprocess.run(HELPER + ["stderr", "oops"], capture_output=True)
+assert r.stdout == b""
+assert r.stderr == b"oops\n"
+assertRaises(ValueError, subprocess.run, HELPER + ["echo"], capture_output=True, stdout=subprocess.PIPE)
+
+r = subprocess.run(HELPER + ["stderr", "oops"], stdout=subprocess.PIPE, stderr=subprocess.STDOUT)
+assert r.stdout == b"oops\n"
+assert r.stderr is None
+
+r = subprocess.run(HELPER + ["echo", "quiet"], stdout=subprocess.DEVNULL)
+assert r.stdout is None
+
+doc = "input"
+r = subprocess.run(HELPER + ["cat"], input=b"some\ninput", stdout=subprocess.PIPE)
+assert r.stdout == b"some\ninput"
+r = subprocess.run(HELPER + ["cat"], input="text\r\ninput", stdout=subprocess.PIPE, text=True)
+assert r.stdout == "text\ninput"
+r = subprocess.run(HELPER + ["cat"], input="universal", capture_output=True, universal_newlines=True)
+assert r.stdout == "universal"
+assert r.stderr == ""
+assertRaises(TypeError, subprocess.run, HELPER + ["cat"], input="str", stdout=subprocess.PIPE)
+assertRaises(ValueError, subprocess.run, HELPER + ["cat"], input=b"x", stdin=subprocess.PIPE)
+r = subprocess.run(HELPER + ["cat"], stdin=subprocess.DEVNULL, stdout=subprocess.PIPE)
+assert r.stdout == b""
+
+doc = "check"
+try:
+    subprocess.run(HELPER + ["exit", "2"], check=True, stdout=subprocess.PIPE)
+except subprocess.CalledProcessError as e:
+    assert e.returncode == 2
+    assert e.cmd == HELPER + ["exit", "2"]
+    assert e.output == b""
+    assert e.stdout == b""
+    assert e.stderr is None
+    assert str(e).endswith("returned non-zero exit status 2.")
+else:
+    assert False, "CalledProcessError not raised"
+
+e = subprocess.CalledProcessError(1, "cmd", output=b"out")
+assert e.returncode == 1
+assert e.cmd == "cmd"
+assert e.output == b"out"
+assert e.stderr is None
+assert str(e) == "Command 'cmd' returned non-zero exit status 1."
+e = subprocess.TimeoutExpired("cmd", 1.5)
+assert e.timeout == 1.5
+assert e.output is None
+assert str(e) == "Command 'cmd' timed out after 1.5 seconds"
+
+doc = "env and cwd"
+env = dict(os.environ)
+env["SUBPROCESS_TEST_VALUE"] = "from the environment"
+r = subprocess.run(HELPER + ["getenv", "SUBPROCESS_TEST_VALUE"], env=env, stdout=subprocess.PIPE)
+assert r.stdout == b"from the environment\n"
+r = subprocess.run(HELPER + ["getenv", "SUBPROCESS_TEST_VALUE"], stdout=subprocess.PIPE)
+assert r.stdout == b"\n"
+os.environ["SUBPROCESS_TEST_VALUE"] = "inherited"
+r = subprocess.run(HELPER + ["getenv", "SUBPROCESS_TEST_VALUE"], stdout=subprocess.PIPE, text=True)
+assert r.stdout == "inherited\n"
+del os.environ["SUBPROCESS_TEST_VALUE"]
+
+cwd = os.getcwd()
+os.chdir("..")
+parent = os.getcwd()
+os.chdir(cwd)
+r = subprocess.run(HELPER + ["pwd"], cwd="..", stdout=subprocess.PIPE, text=True)
+assert r.stdout == parent + "\n"
+
+doc = "errors"
+assertRaises(FileNotFoundError, subprocess.run, ["no-such-program-gpython"])
+assertRaises(FileNotFoundError, subprocess.Popen, ["no-such-program-gpython"])
+assertRaises(TypeError, subprocess.run, HELPER + ["echo", 1])
+assertRaises(TypeError, subprocess.run, HELPER + ["echo"], env={"A": 1})
+
+doc = "call"
+assert subprocess.call(HELPER + ["exit", "4"]) == 4
+assert subprocess.check_call(HELPER + ["exit", "0"]) == 0
+assertRaises(subprocess.CalledProcessError, subprocess.check_call, HELPER + ["exit", "1"])
+assert subprocess.check_output(HELPER + ["echo", "out"]) == b"out\n"
+assert subprocess.check_output(HELPER + ["cat"], input="in", text=True) == "in"
+assertRaises(subprocess.CalledProcessError, subprocess.check_output, HELPER + ["exit", "1"])
+assertRaises(ValueError, subprocess.check_output, HELPER + ["echo"], stdout=subprocess.PIPE)
+
+doc = "timeout"
+try:
+    subprocess.run(HELPER + ["sleep", "10"], timeout=0.1)
+except subprocess.TimeoutExpired as e:
+    assert 0 < e.timeout <= 0.1
+    assert e.cmd == HELPER + ["sleep", "10"]
+else:
+    assert False, "TimeoutExpired not raised"
+assertRaises(subprocess.TimeoutExpired, subprocess.call, HELPER + ["sleep", "10"], timeout=0.1)
+
+doc = "Popen"
+p = subprocess.Popen(HELPER + ["cat"], stdin=subprocess.PIPE, stdout=subprocess.PIPE)
+assert isinstance(p.pid, int)
+assert p.returncode is None
+p.stdin.write(b"piped")
+p.stdin.close()
+assert p.stdout.read() == b"piped"
+assert p.wait() == 0
+assert p.returncode == 0
+assert p.poll() == 0
+p.stdout.close()
+
+p = subprocess.Popen(HELPER + ["cat"], stdin=subprocess.PIPE, stdout=subprocess.PIPE, stderr=subprocess.PIPE)
+out, err = p.communicate(b"data")
+assert (out, err) == (b"data", b"")
+assert p.returncode == 0
+
+p = subprocess.Popen(HELPER + ["sleep", "10"])
+assertRaises(subprocess.TimeoutExpired, p.wait, 0.05)
+assert p.poll() is None
+p.kill()
+assert p.wait() != 0
+p.kill()
+
+p = subprocess.Popen(HELPER + ["sleep", "10"], stdout=subprocess.PIPE)
+assertRaises(subprocess.TimeoutExpired, p.communicate, timeout=0.05)
+p.terminate()
+out, err = p.communicate()
+assert out == b""
+assert err is None
+assert p.returncode != 0
+
+with subprocess.Popen(HELPER + ["echo", "with"], stdout=subprocess.PIPE, text=True) as p:
+    assert p.stdout.read() == "with\n"
+assert p.returncode == 0
+assert p.stdout.closed
+
+doc = "shell"
+if os.name != "nt":
+    assert subprocess.call("exit 5", shell=True) == 5
+r = subprocess.run("echo shell", shell=True, stdout=subprocess.PIPE, text=True)
+assert r.stdout.split() == ["shell"]
+assert subprocess.getoutput("echo output") == "output"
+assert subprocess.getstatusoutput("echo status") == (0, "status")
+
+doc = "finished"
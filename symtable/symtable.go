@@ -107,6 +107,8 @@ type SymTable struct {
 	Free              bool      // true if block has free variables
 	ChildFree         bool      // true if a child block has free vars, including free refs to globals
 	Generator         bool      // true if namespace is a generator
+	Comprehension     bool      // true if namespace is a comprehension or generator expression
+	Coroutine         bool      // true if namespace is a coroutine (async def)
 	Varargs           bool      // true if block has varargs
 	Varkeywords       bool      // true if block has varkeywords
 	ReturnsValue      bool      // true if namespace uses return with an argument
@@ -114,8 +116,10 @@ type SymTable struct {
 	// col_offset        int       // offset of first line of block
 	// opt_lineno        int       // lineno of last exec or import *
 	// opt_col_offset    int       // offset of last exec or import *
-	TmpName int    // counter for listcomp temp vars
-	Private string // name of current class or ""
+	TmpName         int       // counter for listcomp temp vars
+	Private         string    // name of current class or ""
+	CompIterExpr    int       // > 0 while parsing the iterable of a comprehension
+	CompIterTargets StringSet // names bound by the for clauses of a comprehension
 
 	Symbols     Symbols
 	Global      *SymTable // symbol table entry for module
@@ -203,6 +207,9 @@ func (st *SymTable) GetScope(name string) Scope {
 func (st *SymTable) addArgumentsToSymbolTable(node *ast.Arguments) {
 	// skip default arguments inside function block
 	// XXX should ast be different?
+	for _, arg := range node.Posonlyargs {
+		st.AddDef(node, arg.Arg, DefParam)
+	}
 	for _, arg := range node.Args {
 		st.AddDef(node, arg.Arg, DefParam)
 	}
@@ -250,6 +257,23 @@ func (st *SymTable) Parse(Ast ast.Ast) {
 				}
 				st.AddDef(node, name, DefGlobal)
 			}
+		case *ast.NamedExpr:
+			if st.CompIterExpr > 0 {
+				st.panicSyntaxErrorf(node, "assignment expression cannot be used in a comprehension iterable expression")
+			}
+			if st.Comprehension {
+				st.extendNamedExprScope(node.Target.(*ast.Name))
+			}
+		case *ast.AnnAssign:
+			if name, ok := node.Target.(*ast.Name); ok && node.Simple != 0 {
+				cur := st.Symbols[string(name.Id)]
+				if (cur.Flags & DefGlobal) != 0 {
+					st.panicSyntaxErrorf(node, "annotated name '%s' can't be global", name.Id)
+				}
+				if (cur.Flags & DefNonlocal) != 0 {
+					st.panicSyntaxErrorf(node, "annotated name '%s' can't be nonlocal", name.Id)
+				}
+			}
 		case *ast.Name:
 			if node.Ctx == ast.Load {
 				st.AddDef(node, node.Id, DefUse)
@@ -261,30 +285,12 @@ func (st *SymTable) Parse(Ast ast.Ast) {
 				st.AddDef(node, ast.Identifier("__class__"), DefUse)
 			}
 		case *ast.FunctionDef:
-			// Add the function name to the SymTable
-			st.AddDef(node, node.Name, DefLocal)
-			name := string(node.Name)
-
-			// Walk these things in original symbol table
-			if node.Args != nil {
-				st.Parse(node.Args)
-			}
-			for _, expr := range node.DecoratorList {
-				st.Parse(expr)
-			}
-			st.Parse(node.Returns)
-
-			// Make a new symtable
-			stNew := newSymTableBlock(Ast, FunctionBlock, name, st)
-
-			// Add the arguments to the new symbol table
-			stNew.addArgumentsToSymbolTable(node.Args)
-
-			// Walk the Body in the new symtable
-			for _, stmt := range node.Body {
-				stNew.Parse(stmt)
-			}
-
+			st.parseFunctionDef(Ast, node.Name, node.Args, node.Body, node.DecoratorList, node.Returns)
+			// return false to stop the parse
+			return false
+		case *ast.AsyncFunctionDef:
+			stNew := st.parseFunctionDef(Ast, node.Name, node.Args, node.Body, node.DecoratorList, node.Returns)
+			stNew.Coroutine = true
 			// return false to stop the parse
 			return false
 		case *ast.ClassDef:
@@ -348,6 +354,18 @@ func (st *SymTable) Parse(Ast ast.Ast) {
 			if node.Name != "" {
 				st.AddDef(node, node.Name, DefLocal)
 			}
+		case *ast.MatchAs:
+			if node.Name != "" {
+				st.AddDef(node, node.Name, DefLocal)
+			}
+		case *ast.MatchStar:
+			if node.Name != "" {
+				st.AddDef(node, node.Name, DefLocal)
+			}
+		case *ast.MatchMapping:
+			if node.Rest != "" {
+				st.AddDef(node, node.Rest, DefLocal)
+			}
 		case *ast.Alias:
 			// Compute store_name, the name actually bound by the import
 			// operation.  It is different than node.name when node.name is a
@@ -374,12 +392,43 @@ func (st *SymTable) Parse(Ast ast.Ast) {
 				st.ReturnsValue = true
 			}
 		case *ast.Yield, *ast.YieldFrom:
+			if st.Comprehension {
+				st.panicSyntaxErrorf(node, "'yield' inside %s", comprehensionDescriptions[st.Name])
+			}
 			st.Generator = true
 		}
 		return true
 	})
 }
 
+// Parses a function definition, returning the new symtable for the
+// function
+func (st *SymTable) parseFunctionDef(Ast ast.Ast, name ast.Identifier, args *ast.Arguments, body []ast.Stmt, decoratorList []ast.Expr, returns ast.Expr) *SymTable {
+	// Add the function name to the SymTable
+	st.AddDef(Ast, name, DefLocal)
+
+	// Walk these things in original symbol table
+	if args != nil {
+		st.Parse(args)
+	}
+	for _, expr := range decoratorList {
+		st.Parse(expr)
+	}
+	st.Parse(returns)
+
+	// Make a new symtable
+	stNew := newSymTableBlock(Ast, FunctionBlock, string(name), st)
+
+	// Add the arguments to the new symbol table
+	stNew.addArgumentsToSymbolTable(args)
+
+	// Walk the Body in the new symtable
+	for _, stmt := range body {
+		stNew.Parse(stmt)
+	}
+	return stNew
+}
+
 // make a new temporary name
 func (st *SymTable) newTmpName(node ast.Ast) {
 	st.TmpName++
@@ -387,15 +436,26 @@ func (st *SymTable) newTmpName(node ast.Ast) {
 	st.AddDef(node, id, DefLocal)
 }
 
+// Descriptions of the comprehension scopes for error messages
+var comprehensionDescriptions = map[string]string{
+	"listcomp": "list comprehension",
+	"setcomp":  "set comprehension",
+	"dictcomp": "dict comprehension",
+	"genexpr":  "generator expression",
+}
+
 func (st *SymTable) parseComprehension(Ast ast.Ast, scopeName string, generators []ast.Comprehension, elt ast.Expr, value ast.Expr) {
 	_, isGenerator := Ast.(*ast.GeneratorExp)
 	needsTmp := !isGenerator
 	outermost := generators[0]
 	// Outermost iterator is evaluated in current scope
+	st.CompIterExpr++
 	st.Parse(outermost.Iter)
+	st.CompIterExpr--
 	// Create comprehension scope for the rest
 	stNew := newSymTableBlock(Ast, FunctionBlock, scopeName, st)
 	stNew.Generator = isGenerator
+	stNew.Comprehension = true
 	// Outermost iter is received as an argument
 	id := ast.Identifier(fmt.Sprintf(".%d", 0))
 	stNew.AddDef(Ast, id, DefParam)
@@ -403,13 +463,15 @@ func (st *SymTable) parseComprehension(Ast ast.Ast, scopeName string, generators
 	if needsTmp {
 		stNew.newTmpName(Ast)
 	}
-	stNew.Parse(outermost.Target)
+	stNew.parseCompIterTarget(outermost.Target)
 	for _, expr := range outermost.Ifs {
 		stNew.Parse(expr)
 	}
 	for _, comprehension := range generators[1:] {
-		stNew.Parse(comprehension.Target)
+		stNew.parseCompIterTarget(comprehension.Target)
+		stNew.CompIterExpr++
 		stNew.Parse(comprehension.Iter)
+		stNew.CompIterExpr--
 		for _, expr := range comprehension.Ifs {
 			stNew.Parse(expr)
 		}
@@ -420,6 +482,48 @@ func (st *SymTable) parseComprehension(Ast ast.Ast, scopeName string, generators
 	stNew.Parse(elt)
 }
 
+// Parses the target of a for clause in a comprehension marking the
+// names it binds as iteration variables
+func (st *SymTable) parseCompIterTarget(target ast.Expr) {
+	st.Parse(target)
+	if st.CompIterTargets == nil {
+		st.CompIterTargets = make(StringSet)
+	}
+	ast.Walk(target, func(Ast ast.Ast) bool {
+		if name, ok := Ast.(*ast.Name); ok {
+			st.CompIterTargets.Add(string(name.Id))
+		}
+		return true
+	})
+}
+
+// Binds the target of an assignment expression in a comprehension in
+// the nearest enclosing scope which isn't a comprehension (PEP 572)
+func (st *SymTable) extendNamedExprScope(target *ast.Name) {
+	for parent := st; parent != nil; parent = parent.Parent {
+		if parent.Comprehension {
+			if parent.CompIterTargets.Contains(string(target.Id)) {
+				st.panicSyntaxErrorf(target, "assignment expression cannot rebind comprehension iteration variable '%s'", target.Id)
+			}
+			continue
+		}
+		switch parent.Type {
+		case FunctionBlock:
+			if sym, ok := parent.Symbols[string(target.Id)]; ok && (sym.Flags&DefGlobal) != 0 {
+				st.AddDef(target, target.Id, DefGlobal)
+			} else {
+				st.AddDef(target, target.Id, DefNonlocal)
+			}
+			parent.AddDef(target, target.Id, DefLocal)
+		case ModuleBlock:
+			st.AddDef(target, target.Id, DefGlobal)
+		case ClassBlock:
+			st.panicSyntaxErrorf(target, "assignment expression within a comprehension cannot be used in a class body")
+		}
+		return
+	}
+}
+
 // Add a symbol into the symble table
 func (st *SymTable) AddDef(node ast.Ast, name ast.Identifier, flags DefUseFlags) {
 	// FIXME mangled := _Py_Mangle(st.Private, name)
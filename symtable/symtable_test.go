@@ -120,6 +120,7 @@ func EqSymTable(t *testing.T, name string, a, b *SymTable) {
 	EqBool(t, name+": Free", a.Free, b.Free)
 	EqBool(t, name+": ChildFree", a.ChildFree, b.ChildFree)
 	EqBool(t, name+": Generator", a.Generator, b.Generator)
+	EqBool(t, name+": Coroutine", a.Coroutine, b.Coroutine)
 	EqBool(t, name+": Varargs", a.Varargs, b.Varargs)
 	EqBool(t, name+": Varkeywords", a.Varkeywords, b.Varkeywords)
 	EqBool(t, name+": ReturnsValue", a.ReturnsValue, b.ReturnsValue)
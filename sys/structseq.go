@@ -0,0 +1,109 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Struct sequences such as sys.hash_info
+
+package sys
+
+import (
+	"bytes"
+
+	"github.com/go-python/gpython/py"
+)
+
+// The names of the fields of each struct sequence type
+var structSeqFields = map[*py.Type][]string{}
+
+// Makes a struct sequence type with the fields named
+func newStructSeqType(name, doc string, fields []string) *py.Type {
+	t := py.NewType(name, doc)
+	structSeqFields[t] = fields
+	for i, field := range fields {
+		i := i
+		t.Dict[field] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return self.(*structSeq).fields[i], nil
+			},
+		}
+	}
+	return t
+}
+
+// A structSeq behaves as a read only tuple of its fields which can
+// also be read as attributes
+type structSeq struct {
+	t      *py.Type
+	fields py.Tuple
+}
+
+// Makes a struct sequence of type t with the field values given
+func newStructSeq(t *py.Type, fields ...py.Object) *structSeq {
+	return &structSeq{
+		t:      t,
+		fields: fields,
+	}
+}
+
+// Type of this object
+func (s *structSeq) Type() *py.Type {
+	return s.t
+}
+
+// Returns the fields of other if it is a struct sequence
+func structSeqTuple(other py.Object) py.Object {
+	if o, ok := other.(*structSeq); ok {
+		return o.fields
+	}
+	return other
+}
+
+func (s *structSeq) M__getitem__(key py.Object) (py.Object, error) {
+	return s.fields.M__getitem__(key)
+}
+
+func (s *structSeq) M__len__() (py.Object, error) {
+	return py.Int(len(s.fields)), nil
+}
+
+func (s *structSeq) M__iter__() (py.Object, error) {
+	return py.NewIterator(s.fields), nil
+}
+
+func (s *structSeq) M__hash__() (py.Object, error) {
+	return s.fields.M__hash__()
+}
+
+func (s *structSeq) M__eq__(other py.Object) (py.Object, error) {
+	return s.fields.M__eq__(structSeqTuple(other))
+}
+
+func (s *structSeq) M__ne__(other py.Object) (py.Object, error) {
+	return s.fields.M__ne__(structSeqTuple(other))
+}
+
+func (s *structSeq) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("sys." + s.t.Name + "(")
+	for i, name := range structSeqFields[s.t] {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := py.ReprAsString(s.fields[i])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(name + "=" + repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+// Check interface is satisfied
+var _ py.I__getitem__ = (*structSeq)(nil)
+var _ py.I__len__ = (*structSeq)(nil)
+var _ py.I__iter__ = (*structSeq)(nil)
+var _ py.I__hash__ = (*structSeq)(nil)
+var _ py.I__eq__ = (*structSeq)(nil)
+var _ py.I__ne__ = (*structSeq)(nil)
+var _ py.I__repr__ = (*structSeq)(nil)
@@ -18,7 +18,11 @@
 package sys
 
 import (
+	"fmt"
 	"os"
+	"runtime"
+	"unicode"
+	"unsafe"
 
 	"github.com/go-python/gpython/py"
 )
@@ -75,6 +79,8 @@ __excepthook__ -- the original excepthook; don't touch!
 
 Functions:
 
+addaudithook() -- add a hook called with each audit event
+audit() -- raise an audit event
 displayhook() -- print an object to the screen, and save it in builtins._
 excepthook() -- print an exception and its traceback to sys.stderr
 exc_info() -- return thread-safe information about the current exception
@@ -114,7 +120,15 @@ Return information about the most recent exception caught by an except
 clause in the current stack frame or in an older stack frame.`
 
 func sys_exc_info(self py.Object) (py.Object, error) {
-	return nil, py.NotImplementedError
+	exc := py.CurrentFrame.HandledException()
+	if exc == nil {
+		return py.Tuple{py.None, py.None, py.None}, nil
+	}
+	var traceback py.Object = py.None
+	if exc.Traceback != nil {
+		traceback = exc.Traceback
+	}
+	return py.Tuple{exc.Type, exc.Value, traceback}, nil
 }
 
 const exit_doc = `exit([status])
@@ -132,7 +146,11 @@ func sys_exit(self py.Object, args py.Tuple) (py.Object, error) {
 		return nil, err
 	}
 	// Raise SystemExit so callers may catch it or clean up.
-	return py.ExceptionNew(py.SystemExit, args, nil)
+	exc, err := py.ExceptionNew(py.SystemExit, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	return nil, exc.(*py.Exception)
 }
 
 const getdefaultencoding_doc = `getdefaultencoding() -> string
@@ -168,20 +186,16 @@ Return the string itself or the previously interned string object with the
 same value.`
 
 func sys_intern(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// py.Object s;
-	// if (!PyArg_ParseTuple(args, "U:intern", &s)) {
-	//     return nil;
-	// }
-	// if (PyUnicode_CheckExact(s)) {
-	//     Py_INCREF(s);
-	//     PyUnicode_InternInPlace(&s);
-	//     return s;
-	// } else {
-	//     PyErr_Format(PyExc_TypeError,
-	//                  "can't intern %.400s", s->ob_type->tp_name);
-	//     return nil;
-	// }
+	var s py.Object
+	err := py.UnpackTuple(args, nil, "intern", 1, 1, &s)
+	if err != nil {
+		return nil, err
+	}
+	str, ok := s.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "can't intern %s", s.Type().Name)
+	}
+	return str.Intern(), nil
 }
 
 const settrace_doc = `settrace(function)
@@ -190,17 +204,17 @@ Set the global debug tracing function.  It will be called on each
 function call.  See the debugger chapter in the library manual.`
 
 func sys_settrace(self py.Object, args py.Tuple) (py.Object, error) {
-	// if (trace_init() == -1) {
-	//     return nil;
-	// }
-	// if (args == Py_None) {
-	//     PyEval_SetTrace(nil, nil);
-	// } else {
-	//     PyEval_SetTrace(trace_trampoline, args);
-	// }
-	// Py_INCREF(Py_None);
-	// return Py_None;
-	return nil, py.NotImplementedError
+	var function py.Object
+	err := py.UnpackTuple(args, nil, "settrace", 1, 1, &function)
+	if err != nil {
+		return nil, err
+	}
+	if function == py.None {
+		py.TraceFunc = nil
+	} else {
+		py.TraceFunc = function
+	}
+	return py.None, nil
 }
 
 const gettrace_doc = `gettrace()
@@ -209,15 +223,32 @@ Return the global debug tracing function set with sys.settrace.
 See the debugger chapter in the library manual.`
 
 func sys_gettrace(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// PyThreadState *tstate = PyThreadState_GET();
-	// py.Object temp = tstate->c_traceobj;
+	err := py.UnpackTuple(args, nil, "gettrace", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if py.TraceFunc == nil {
+		return py.None, nil
+	}
+	return py.TraceFunc, nil
+}
 
-	// if (temp == nil) {
-	//     temp = Py_None;
-	// }
-	// Py_INCREF(temp);
-	// return temp;
+const breakpointhook_doc = `breakpointhook(*args, **kws)
+
+This hook function is called by built-in breakpoint().
+
+By default it calls pdb.set_trace().  Setting the PYTHONBREAKPOINT
+environment variable to "0" disables it.`
+
+func sys_breakpointhook(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if os.Getenv("PYTHONBREAKPOINT") == "0" {
+		return py.None, nil
+	}
+	pdb, err := py.GetModule("pdb")
+	if err != nil {
+		return nil, err
+	}
+	return pdb.Call("set_trace", args, kwargs)
 }
 
 const setprofile_doc = `setprofile(function)
@@ -226,17 +257,17 @@ Set the profiling function.  It will be called on each function call
 and return.  See the profiler chapter in the library manual.`
 
 func sys_setprofile(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// if (trace_init() == -1) {
-	//     return nil;
-	// }
-	// if (args == Py_None) {
-	//     PyEval_SetProfile(nil, nil);
-	// } else {
-	//     PyEval_SetProfile(profile_trampoline, args);
-	// }
-	// Py_INCREF(Py_None);
-	// return Py_None;
+	var function py.Object
+	err := py.UnpackTuple(args, nil, "setprofile", 1, 1, &function)
+	if err != nil {
+		return nil, err
+	}
+	if function == py.None {
+		py.ProfileFunc = nil
+	} else {
+		py.ProfileFunc = function
+	}
+	return py.None, nil
 }
 
 const getprofile_doc = `getprofile()
@@ -245,15 +276,14 @@ Return the profiling function set with sys.setprofile.
 See the profiler chapter in the library manual.`
 
 func sys_getprofile(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// PyThreadState *tstate = PyThreadState_GET();
-	// py.Object temp = tstate->c_profileobj;
-
-	// if (temp == nil) {
-	//     temp = Py_None;
-	// }
-	// Py_INCREF(temp);
-	// return temp;
+	err := py.UnpackTuple(args, nil, "getprofile", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if py.ProfileFunc == nil {
+		return py.None, nil
+	}
+	return py.ProfileFunc, nil
 }
 
 // int _check_interval = 100;
@@ -332,19 +362,20 @@ stack and crashing Python.  The highest possible limit is platform-
 dependent.`
 
 func sys_setrecursionlimit(self py.Object, args py.Tuple) (py.Object, error) {
-	// int new_limit;
-	// if (!PyArg_ParseTuple(args, "i:setrecursionlimit", &new_limit)) {
-	//     return nil;
-	// }
-	// if (new_limit <= 0) {
-	//     PyErr_SetString(PyExc_ValueError,
-	//                     "recursion limit must be positive");
-	//     return nil;
-	// }
-	// Py_SetRecursionLimit(new_limit);
-	// Py_INCREF(Py_None);
-	// return Py_None;
-	return nil, py.NotImplementedError
+	var limitObj py.Object
+	err := py.UnpackTuple(args, nil, "setrecursionlimit", 1, 1, &limitObj)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := py.MakeGoInt(limitObj)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "recursion limit must be positive")
+	}
+	py.CurrentContext.RecursionLimit = limit
+	return py.None, nil
 }
 
 const hash_info_doc = `hash_info
@@ -352,48 +383,25 @@ const hash_info_doc = `hash_info
 A struct sequence providing parameters used for computing
 numeric hashes.  The attributes are read only.`
 
-//  PyStructSequence_Field hash_info_fields[] = {
-//     {"width", "width of the type used for hashing, in bits"},
-//     {
-//         "modulus", "prime number giving the modulus on which the hash "
-//         "function is based"
-//     },
-//     {"inf", "value to be used for hash of a positive infinity"},
-//     {"nan", "value to be used for hash of a nan"},
-//     {"imag", "multiplier used for the imaginary part of a complex number"},
-//     {nil, nil}
-// };
-
-//  PyStructSequence_Desc hash_info_desc = {
-//     "sys.hash_info",
-//     hash_info_doc,
-//     hash_info_fields,
-//     5,
-// };
-
-// get_hash_info(void) {
-//     py.Object hash_info;
-//     int field = 0;
-//     hash_info = PyStructSequence_New(&Hash_InfoType);
-//     if (hash_info == nil) {
-//         return nil;
-//     }
-//     PyStructSequence_SET_ITEM(hash_info, field++,
-//                               PyLong_FromLong(8*sizeof(Py_hash_t)));
-//     PyStructSequence_SET_ITEM(hash_info, field++,
-//                               PyLong_FromSsize_t(_PyHASH_MODULUS));
-//     PyStructSequence_SET_ITEM(hash_info, field++,
-//                               PyLong_FromLong(_PyHASH_INF));
-//     PyStructSequence_SET_ITEM(hash_info, field++,
-//                               PyLong_FromLong(_PyHASH_NAN));
-//     PyStructSequence_SET_ITEM(hash_info, field++,
-//                               PyLong_FromLong(_PyHASH_IMAG));
-//     if (PyErr_Occurred()) {
-//         Py_CLEAR(hash_info);
-//         return nil;
-//     }
-//     return hash_info;
-// }
+// The type of sys.hash_info
+var hashInfoType = newStructSeqType("hash_info", hash_info_doc, []string{
+	"width", "modulus", "inf", "nan", "imag", "algorithm", "hash_bits", "seed_bits", "cutoff",
+})
+
+// Returns sys.hash_info describing how objects are hashed
+func getHashInfo() py.Object {
+	return newStructSeq(hashInfoType,
+		py.Int(64),
+		py.Int(py.HashModulus),
+		py.Int(py.HashInf),
+		py.Int(py.HashNan),
+		py.Int(py.HashImag),
+		py.String("siphash13"),
+		py.Int(64),
+		py.Int(128),
+		py.Int(py.HashCutoff),
+	)
+}
 
 const getrecursionlimit_doc = `getrecursionlimit()
 
@@ -402,8 +410,7 @@ of the Python interpreter stack.  This limit prevents infinite
 recursion from causing an overflow of the C stack and crashing Python.`
 
 func sys_getrecursionlimit(self py.Object) (py.Object, error) {
-	// return PyLong_FromLong(Py_GetRecursionLimit());
-	return nil, py.NotImplementedError
+	return py.Int(py.CurrentContext.RecursionLimit), nil
 }
 
 const getsizeof_doc = `getsizeof(object, default) -> int
@@ -446,25 +453,75 @@ This function should be used for internal and specialized
 purposes only.`
 
 func sys_getframe(self py.Object, args py.Tuple) (py.Object, error) {
-	// PyFrameObject *f = PyThreadState_GET()->frame;
-	// int depth = -1;
+	var depthObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, nil, "_getframe", 0, 1, &depthObj)
+	if err != nil {
+		return nil, err
+	}
+	depth, err := py.MakeGoInt(depthObj)
+	if err != nil {
+		return nil, err
+	}
+	f := py.CurrentFrame
+	for ; depth > 0 && f != nil; depth-- {
+		f = f.Back
+	}
+	if f == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "call stack is not deep enough")
+	}
+	err = py.Audit("sys._getframe", f)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
 
-	// if (!PyArg_ParseTuple(args, "|i:_getframe", &depth)) {
-	//     return nil;
-	// }
+const audit_doc = `audit(event, *args)
 
-	// while (depth > 0 && f != nil) {
-	//     f = f->f_back;
-	//     --depth;
-	// }
-	// if (f == nil) {
-	//     PyErr_SetString(PyExc_ValueError,
-	//                     "call stack is not deep enough");
-	//     return nil;
-	// }
-	// Py_INCREF(f);
-	// return (PyObject*)f;
-	return nil, py.NotImplementedError
+Passes the event to any audit hooks that are attached.`
+
+func sys_audit(self py.Object, args py.Tuple) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "audit() missing 1 required positional argument: 'event'")
+	}
+	event, ok := args[0].(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "expected str for argument 'event', not %s", args[0].Type().Name)
+	}
+	err := py.Audit(string(event), args[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+const addaudithook_doc = `addaudithook(hook)
+
+Adds a new audit hook callback.
+
+The hook is called with the name of each audit event and a tuple of
+its arguments.  If it raises an exception the operation which raised
+the event is stopped and the exception raised in its place.
+
+The existing hooks are called with the sys.addaudithook event first
+and if one raises an Exception the new hook isn't added.`
+
+func sys_addaudithook(self py.Object, hook py.Object) (py.Object, error) {
+	err := py.Audit("sys.addaudithook")
+	if err != nil {
+		// A hook raising an Exception stops the new one being
+		// added without an error, only a BaseException such as
+		// KeyboardInterrupt is raised
+		if py.IsException(py.ExceptionType, err) {
+			return py.None, nil
+		}
+		return nil, err
+	}
+	py.CurrentContext.AddAuditHook(func(event string, args py.Tuple) error {
+		_, err := py.Call(hook, py.Tuple{py.String(event), args}, nil)
+		return err
+	})
+	return py.None, nil
 }
 
 const current_frames_doc = `_current_frames() -> dictionary
@@ -543,89 +600,103 @@ const flags__doc__ = `sys.flags
 
 Flags provided through command line arguments or environment vars.`
 
-//  PyTypeObject FlagsType;
-
-//  PyStructSequence_Field flags_fields[] = {
-//     {"debug",                   "-d"},
-//     {"inspect",                 "-i"},
-//     {"interactive",             "-i"},
-//     {"optimize",                "-O or -OO"},
-//     {"dont_write_bytecode",     "-B"},
-//     {"no_user_site",            "-s"},
-//     {"no_site",                 "-S"},
-//     {"ignore_environment",      "-E"},
-//     {"verbose",                 "-v"},
-//     /* {"unbuffered",                   "-u"}, */
-//     /* {"skip_first",                   "-x"}, */
-//     {"bytes_warning",           "-b"},
-//     {"quiet",                   "-q"},
-//     {"hash_randomization",      "-R"},
-//     {0}
-// };
-
-//  PyStructSequence_Desc flags_desc = {
-//     "sys.flags",        /* name */
-//     flags__doc__,       /* doc */
-//     flags_fields,       /* fields */
-// };
-
-//  PyObject*
-// make_flags(void) {
-//     int pos = 0;
-//     py.Object seq;
-
-//     seq = PyStructSequence_New(&FlagsType);
-//     if (seq == nil) {
-//         return nil;
-//     }
-
-// #define SetFlag(flag) \
-//     PyStructSequence_SET_ITEM(seq, pos++, PyLong_FromLong(flag))
-
-//     SetFlag(Py_DebugFlag);
-//     SetFlag(Py_InspectFlag);
-//     SetFlag(Py_InteractiveFlag);
-//     SetFlag(Py_OptimizeFlag);
-//     SetFlag(Py_DontWriteBytecodeFlag);
-//     SetFlag(Py_NoUserSiteDirectory);
-//     SetFlag(Py_NoSiteFlag);
-//     SetFlag(Py_IgnoreEnvironmentFlag);
-//     SetFlag(Py_VerboseFlag);
-//     /* SetFlag(saw_unbuffered_flag); */
-//     /* SetFlag(skipfirstline); */
-//     SetFlag(Py_BytesWarningFlag);
-//     SetFlag(Py_QuietFlag);
-//     SetFlag(Py_HashRandomizationFlag);
-
-//     if (PyErr_Occurred()) {
-//         return nil;
-//     }
-//     return seq;
-// }
+// Flags are the command line options which sys.flags reports
+type Flags struct {
+	Debug       int // -d
+	Inspect     int // -i
+	Interactive int // -i
+	Optimize    int // -O or -OO
+}
+
+// The type of sys.flags
+var flagsType = newStructSeqType("flags", flags__doc__, []string{
+	"debug", "inspect", "interactive", "optimize", "dont_write_bytecode",
+	"no_user_site", "no_site", "ignore_environment", "verbose",
+	"bytes_warning", "quiet", "hash_randomization", "isolated",
+	"dev_mode", "utf8_mode", "warn_default_encoding", "safe_path",
+	"int_max_str_digits",
+})
+
+// MakeFlags makes sys.flags from the command line options
+//
+// The flags for options gpython hasn't got are those of CPython run
+// without them, except utf8_mode which is always on.
+func MakeFlags(flags Flags) py.Object {
+	return newStructSeq(flagsType,
+		py.Int(flags.Debug),
+		py.Int(flags.Inspect),
+		py.Int(flags.Interactive),
+		py.Int(flags.Optimize),
+		py.Int(boolToInt(dontWriteBytecode())),
+		py.Int(0),
+		py.Int(0),
+		py.Int(0),
+		py.Int(0),
+		py.Int(0),
+		py.Int(0),
+		py.Int(boolToInt(py.HashRandomization)),
+		py.Int(0),
+		py.False,
+		py.Int(1),
+		py.Int(0),
+		py.False,
+		py.Int(-1),
+	)
+}
+
+// Returns 1 if b is set or 0 if it isn't
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Returns whether PYTHONDONTWRITEBYTECODE is set
+func dontWriteBytecode() bool {
+	return os.Getenv("PYTHONDONTWRITEBYTECODE") != ""
+}
 
 const version_info__doc__ = `sys.version_info
 
 Version information as a named tuple.`
 
-//  PyStructSequence_Field version_info_fields[] = {
-//     {"major", "Major release number"},
-//     {"minor", "Minor release number"},
-//     {"micro", "Patch release number"},
-//     {"releaselevel", "'alpha', 'beta', 'candidate', or 'release'"},
-//     {"serial", "Serial release number"},
-//     {0}
-// };
-
-//  PyStructSequence_Desc version_info_desc = {
-//     "sys.version_info",     /* name */
-//     version_info__doc__,    /* doc */
-//     version_info_fields,    /* fields */
-//     5
-// };
+// Returns the platform identifier as CPython names it
+func platform() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "win32"
+	case "linux", "android":
+		return "linux"
+	}
+	return runtime.GOOS
+}
+
+// Returns the byte order of the machine
+func byteorder() string {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 0 {
+		return "big"
+	}
+	return "little"
+}
+
+// Returns the path of the interpreter or "" if it isn't known
+func executable() string {
+	path, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return path
+}
 
 // Initialise the module
 func init() {
+	breakpointhook := py.MustNewMethod("breakpointhook", sys_breakpointhook, 0, breakpointhook_doc)
 	methods := []*py.Method{
+		breakpointhook,
+		py.MustNewMethod("addaudithook", sys_addaudithook, 0, addaudithook_doc),
+		py.MustNewMethod("audit", sys_audit, 0, audit_doc),
 		py.MustNewMethod("callstats", sys_callstats, 0, callstats_doc),
 		py.MustNewMethod("_clear_type_cache", sys_clear_type_cache, 0, sys_clear_type_cache__doc__),
 		py.MustNewMethod("_current_frames", sys_current_frames, 0, current_frames_doc),
@@ -653,9 +724,9 @@ func init() {
 		py.MustNewMethod("_debugmallocstats", sys_debugmallocstats, 0, debugmallocstats_doc),
 	}
 	argv := MakeArgv(os.Args[1:])
-	stdin, stdout, stderr := &py.File{os.Stdin, py.FileRead},
-		&py.File{os.Stdout, py.FileWrite},
-		&py.File{os.Stderr, py.FileWrite}
+	stdin, stdout, stderr := py.NewStdFile(os.Stdin, "<stdin>", py.FileRead),
+		py.NewStdFile(os.Stdout, "<stdout>", py.FileWrite),
+		py.NewStdFile(os.Stderr, "<stderr>", py.FileWrite)
 	globals := py.StringDict{
 		"argv":       argv,
 		"stdin":      stdin,
@@ -664,6 +735,21 @@ func init() {
 		"__stdin__":  stdin,
 		"__stdout__": stdout,
 		"__stderr__": stderr,
+
+		"__breakpointhook__": breakpointhook,
+
+		"dont_write_bytecode": py.Bool(dontWriteBytecode()),
+		"flags":               MakeFlags(Flags{}),
+		"warnoptions":         py.NewList(),
+		"version":             py.String(fmt.Sprintf("%s [Gpython %s]", Version, runtime.Version())),
+		"version_info":        newVersionInfo(),
+		"hexversion":          py.Int(VersionMajor<<24 | VersionMinor<<16 | VersionMicro<<8 | 0xf0 | VersionSerial),
+		"platform":            py.String(platform()),
+		"byteorder":           py.String(byteorder()),
+		"executable":          py.String(executable()),
+		"maxsize":             py.Int(py.IntMax),
+		"maxunicode":          py.Int(unicode.MaxRune),
+		"hash_info":           getHashInfo(),
 		//"version": py.Int(MARSHAL_VERSION),
 		//     /* stdin/stdout/stderr are now set by pythonrun.c */
 
@@ -671,10 +757,6 @@ func init() {
 		//                          PyDict_GetItemString(sysdict, "displayhook"));
 		//     PyDict_SetItemString(sysdict, "__excepthook__",
 		//                          PyDict_GetItemString(sysdict, "excepthook"));
-		//     SET_SYS_FROM_STRING("version",
-		//                         PyUnicode_FromString(Py_GetVersion()));
-		//     SET_SYS_FROM_STRING("hexversion",
-		//                         PyLong_FromLong(PY_VERSION_HEX));
 		//     SET_SYS_FROM_STRING("_mercurial",
 		//                         Py_BuildValue("(szz)", "CPython", _Py_hgidentifier(),
 		//                                       _Py_hgversion()));
@@ -684,11 +766,6 @@ func init() {
 		//                         PyLong_FromLong(PYTHON_API_VERSION));
 		//     SET_SYS_FROM_STRING("copyright",
 		//                         PyUnicode_FromString(Py_GetCopyright()));
-		//     SET_SYS_FROM_STRING("platform",
-		//                         PyUnicode_FromString(Py_GetPlatform()));
-		//     SET_SYS_FROM_STRING("executable",
-		//                         PyUnicode_FromWideChar(
-		//                             Py_GetProgramFullPath(), -1));
 		//     SET_SYS_FROM_STRING("prefix",
 		//                         PyUnicode_FromWideChar(Py_GetPrefix(), -1));
 		//     SET_SYS_FROM_STRING("exec_prefix",
@@ -697,37 +774,12 @@ func init() {
 		//                         PyUnicode_FromWideChar(Py_GetPrefix(), -1));
 		//     SET_SYS_FROM_STRING("base_exec_prefix",
 		//                         PyUnicode_FromWideChar(Py_GetExecPrefix(), -1));
-		//     SET_SYS_FROM_STRING("maxsize",
-		//                         PyLong_FromSsize_t(PY_SSIZE_T_MAX));
 		//     SET_SYS_FROM_STRING("float_info",
 		//                         PyFloat_GetInfo());
 		//     SET_SYS_FROM_STRING("int_info",
 		//                         PyLong_GetInfo());
-		//     /* initialize hash_info */
-		//     if (Hash_InfoType.tp_name == 0) {
-		//         PyStructSequence_InitType(&Hash_InfoType, &hash_info_desc);
-		//     }
-		//     SET_SYS_FROM_STRING("hash_info",
-		//                         get_hash_info());
-		//     SET_SYS_FROM_STRING("maxunicode",
-		//                         PyLong_FromLong(0x10FFFF));
 		//     SET_SYS_FROM_STRING("builtin_module_names",
 		//                         list_builtin_module_names());
-		//     {
-		//         /* Assumes that longs are at least 2 bytes long.
-		//            Should be safe! */
-		//         unsigned long number = 1;
-		//         char *value;
-
-		//         s = (char *) &number;
-		//         if (s[0] == 0) {
-		//             value = "big";
-		//         } else {
-		//             value = "little";
-		//         }
-		//         SET_SYS_FROM_STRING("byteorder",
-		//                             PyUnicode_FromString(value));
-		//     }
 		// #ifdef MS_COREDLL
 		//     SET_SYS_FROM_STRING("dllhandle",
 		//                         PyLong_FromVoidPtr(PyWin_DLLhModule));
@@ -738,42 +790,14 @@ func init() {
 		//     SET_SYS_FROM_STRING("abiflags",
 		//                         PyUnicode_FromString(ABIFLAGS));
 		// #endif
-		//     if (warnoptions == nil) {
-		//         warnoptions = PyList_New(0);
-		//     } else {
-		//         Py_INCREF(warnoptions);
-		//     }
-		//     if (warnoptions != nil) {
-		//         PyDict_SetItemString(sysdict, "warnoptions", warnoptions);
-		//     }
-
 		//     v = get_xoptions();
 		//     if (v != nil) {
 		//         PyDict_SetItemString(sysdict, "_xoptions", v);
 		//     }
 
-		//     /* version_info */
-		//     if (VersionInfoType.tp_name == 0) {
-		//         PyStructSequence_InitType(&VersionInfoType, &version_info_desc);
-		//     }
-		//     version_info = make_version_info();
-		//     SET_SYS_FROM_STRING("version_info", version_info);
-		//     /* prevent user from creating new instances */
-		//     VersionInfoType.tp_init = nil;
-		//     VersionInfoType.tp_new = nil;
-
 		//     /* implementation */
 		//     SET_SYS_FROM_STRING("implementation", make_impl_info(version_info));
 
-		//     /* flags */
-		//     if (FlagsType.tp_name == 0) {
-		//         PyStructSequence_InitType(&FlagsType, &flags_desc);
-		//     }
-		//     SET_SYS_FROM_STRING("flags", make_flags());
-		//     /* prevent user from creating new instances */
-		//     FlagsType.tp_init = nil;
-		//     FlagsType.tp_new = nil;
-
 		//     /* float repr style: 0.03 (short) vs 0.029999999999999999 (legacy) */
 		// #ifndef PY_NO_SHORT_FLOAT_REPR
 		//     SET_SYS_FROM_STRING("float_repr_style",
@@ -787,7 +811,22 @@ func init() {
 		//     SET_SYS_FROM_STRING("thread_info", PyThread_GetInfo());
 		// #endif
 	}
-	py.NewModule("sys", module_doc, methods, globals)
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "sys",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+		Init:    sysInit,
+	})
+}
+
+// Sets up the globals of the sys module which belong to the context
+func sysInit(ctx *py.Context, m *py.Module) error {
+	m.Globals["modules"] = ctx.Modules
+	m.Globals["path"] = ctx.Path
+	m.Globals["argv"] = m.Globals["argv"].(*py.List).Copy()
+	m.Globals["warnoptions"] = m.Globals["warnoptions"].(*py.List).Copy()
+	return nil
 }
 
 // Makes an argv into a tuple
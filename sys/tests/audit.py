@@ -0,0 +1,89 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# Audit hooks can't be removed so the ones added here stay for the
+# rest of the tests and only act while "recording" or "vetoing" is set.
+
+import sys
+from libtest import *
+
+recording = False
+events = []
+vetoing = set()
+
+def hook(event, args):
+    if recording:
+        events.append((event, args))
+    if event in vetoing:
+        raise PermissionError(event)
+
+doc="addaudithook raises an event"
+seen = []
+def first(event, args):
+    if not seen:
+        seen.append((event, args))
+sys.addaudithook(first)
+assertEqual(seen, [])
+sys.addaudithook(hook)
+assertEqual(seen, [("sys.addaudithook", ())])
+
+doc="audit"
+events = []
+recording = True
+sys.audit("test.event")
+sys.audit("test.args", 1, "two", None)
+recording = False
+assertEqual(events, [("test.event", ()), ("test.args", (1, "two", None))])
+
+doc="audit errors"
+assertRaises(TypeError, sys.audit)
+assertRaises(TypeError, sys.audit, 1)
+try:
+    sys.audit()
+except TypeError as e:
+    assertEqual(e.args[0], "audit() missing 1 required positional argument: 'event'")
+try:
+    sys.audit(b"x")
+except TypeError as e:
+    assertEqual(e.args[0], "expected str for argument 'event', not bytes")
+
+doc="compile and exec events"
+events = []
+recording = True
+code = compile("x = 1", "<audit>", "exec")
+exec(code, {})
+eval("1")
+recording = False
+names = [event for event, args in events]
+assertEqual(names, ["compile", "exec", "compile", "exec"])
+assertEqual(events[0][1][1], "<audit>")
+assertEqual(events[1][1], (code,))
+
+doc="import event"
+events = []
+recording = True
+import libaudit
+recording = False
+events = [(event, args) for event, args in events if event in ("import", "exec")]
+names = [event for event, args in events]
+assertEqual(names, ["import", "exec"])
+assertEqual(events[0][1][0], "libaudit")
+assertEqual(events[1][1][0].co_filename, libaudit.__file__)
+
+doc="veto"
+vetoing = {"exec", "test.veto"}
+assertRaises(PermissionError, exec, "x = 1")
+assertRaises(PermissionError, sys.audit, "test.veto")
+vetoing = set()
+exec("x = 1")
+
+doc="an exception stops a hook being added"
+vetoing = {"sys.addaudithook"}
+added = []
+sys.addaudithook(lambda event, args: added.append(event))
+vetoing = set()
+sys.audit("test.added")
+assertEqual(added, [])
+
+doc="finished"
@@ -0,0 +1,323 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+from libtest import *
+
+def trace(tracer, fn, *args):
+    sys.settrace(tracer)
+    try:
+        return fn(*args)
+    finally:
+        sys.settrace(None)
+
+doc="settrace events"
+events = []
+def tracer(frame, event, arg):
+    events.append((event, frame.f_code.co_name, frame.f_lineno - frame.f_code.co_firstlineno))
+    return tracer
+def traced():
+    a = 1
+    return a
+trace(tracer, traced)
+assertEqual(events, [
+    ("call", "traced", 0),
+    ("line", "traced", 1),
+    ("line", "traced", 2),
+    ("return", "traced", 2),
+])
+
+doc="settrace return value"
+returned = []
+def tracer(frame, event, arg):
+    if event == "return":
+        returned.append(arg)
+    return tracer
+trace(tracer, traced)
+assertEqual(returned, [1])
+
+doc="settrace exception event"
+events = []
+def tracer(frame, event, arg):
+    if event == "exception":
+        exc_type, exc_value, tb = arg
+        events.append((frame.f_code.co_name, exc_type, exc_value.args, tb.tb_frame is frame))
+    return tracer
+def raises():
+    raise ValueError("traced")
+def catches():
+    try:
+        raises()
+    except ValueError:
+        return "caught"
+assertEqual(trace(tracer, catches), "caught")
+assertEqual(events, [
+    ("raises", ValueError, ("traced",), True),
+    ("catches", ValueError, ("traced",), True),
+])
+
+doc="settrace local tracer"
+lines = []
+def local(frame, event, arg):
+    if event == "line":
+        lines.append(frame.f_lineno - frame.f_code.co_firstlineno)
+    return local
+def tracer(frame, event, arg):
+    if frame.f_code.co_name == "traced":
+        return local
+    return None
+def caller():
+    return traced()
+trace(tracer, caller)
+assertEqual(lines, [1, 2])
+
+doc="settrace f_trace"
+lines = []
+def tracer(frame, event, arg):
+    if frame.f_code.co_name == "traced":
+        frame.f_trace = local
+        assert frame.f_trace is local
+    return None
+trace(tracer, caller)
+assertEqual(lines, [1, 2])
+
+doc="settrace frame attributes"
+seen = []
+def tracer(frame, event, arg):
+    if event == "line" and frame.f_code.co_name == "traced":
+        seen.append((frame.f_back.f_code.co_name, "traced" in frame.f_globals, sorted(frame.f_locals.keys())))
+    return tracer
+trace(tracer, caller)
+assertEqual(seen, [("caller", True, []), ("caller", True, ["a"])])
+
+doc="settrace error turns off tracing"
+def tracer(frame, event, arg):
+    raise KeyError("tracer")
+assertRaises(KeyError, trace, tracer, traced)
+assertEqual(sys.gettrace(), None)
+
+def profile(profiler, fn, *args):
+    sys.setprofile(profiler)
+    try:
+        return fn(*args)
+    finally:
+        sys.setprofile(None)
+
+doc="setprofile events"
+events = []
+def profiler(frame, event, arg):
+    if frame.f_code.co_name == "profiled":
+        name = arg.__name__ if event.startswith("c_") else arg
+        events.append((event, name))
+def profiled():
+    n = len("ab")
+    try:
+        divmod(1, 0)
+    except ZeroDivisionError:
+        pass
+    return n
+assertEqual(profile(profiler, profiled), 2)
+assertEqual(events, [
+    ("call", None),
+    ("c_call", "len"),
+    ("c_return", "len"),
+    ("c_call", "divmod"),
+    ("c_exception", "divmod"),
+    ("return", 2),
+])
+assertEqual(sys.getprofile(), None)
+
+doc="setprofile getprofile"
+sys.setprofile(profiler)
+got = sys.getprofile()
+sys.setprofile(None)
+assert got is profiler
+
+doc="setprofile error turns off profiling"
+def profiler(frame, event, arg):
+    raise KeyError("profiler")
+assertRaises(KeyError, profile, profiler, traced)
+assertEqual(sys.getprofile(), None)
+
+doc="exc_info"
+assertEqual(sys.exc_info(), (None, None, None))
+try:
+    raise ValueError("handled")
+except ValueError as e:
+    exc_type, exc_value, tb = sys.exc_info()
+    assertEqual(exc_type, ValueError)
+    assert exc_value is e
+    assert tb is e.__traceback__
+assertEqual(sys.exc_info(), (None, None, None))
+
+def info():
+    return sys.exc_info()[1]
+try:
+    raise KeyError("outer")
+except KeyError as e:
+    assert info() is e
+
+doc="modules and path"
+assert sys.modules["sys"] is sys
+import builtins
+assert sys.modules["builtins"] is builtins
+assertEqual(type(sys.path), list)
+assert "" in sys.path
+assertEqual(type(sys.argv), list)
+
+doc="exit"
+try:
+    sys.exit()
+except SystemExit as e:
+    assertEqual(e.code, None)
+else:
+    assert False, "SystemExit not raised"
+try:
+    sys.exit(3)
+except SystemExit as e:
+    assertEqual(e.code, 3)
+    assertEqual(e.args, (3,))
+try:
+    sys.exit("message")
+except SystemExit as e:
+    assertEqual(e.code, "message")
+assertRaises(TypeError, sys.exit, 1, 2)
+assertEqual(SystemExit(1, 2).code, (1, 2))
+
+doc="version"
+assertEqual(len(sys.version_info), 5)
+major, minor, micro, releaselevel, serial = sys.version_info
+assertEqual(sys.version_info.major, major)
+assertEqual(sys.version_info.minor, minor)
+assertEqual(sys.version_info[2], micro)
+assertEqual(sys.version_info.releaselevel, releaselevel)
+assertEqual(major, 3)
+assert sys.version_info >= (3,)
+assert sys.version_info < (4,)
+assert (3,) <= sys.version_info
+assertEqual(sys.version_info[:2], (major, minor))
+assertEqual(repr(sys.version_info), "sys.version_info(major=%d, minor=%d, micro=%d, releaselevel=%s, serial=%d)" % (major, minor, micro, repr(releaselevel), serial))
+assert sys.version.startswith("%d.%d.%d" % (major, minor, micro))
+assertEqual(sys.hexversion >> 16, major << 8 | minor)
+
+doc="hash_info"
+assertEqual(len(sys.hash_info), 9)
+assertEqual(sys.hash_info.width, 64)
+assertEqual(sys.hash_info.modulus, 2**61 - 1)
+assertEqual(sys.hash_info[2], sys.hash_info.inf)
+assertEqual(hash(float("inf")), sys.hash_info.inf)
+assertEqual(hash(1j), sys.hash_info.imag)
+assertEqual(sys.hash_info.algorithm, "siphash13")
+assertEqual(tuple(sys.hash_info)[-4:], ("siphash13", 64, 128, 0))
+assert repr(sys.hash_info).startswith("sys.hash_info(width=64, modulus=2305843009213693951, ")
+assertEqual(type(sys.hash_info).__name__, "hash_info")
+
+doc="flags"
+assertEqual(len(sys.flags), 18)
+assertEqual(sys.flags.optimize, 0)
+assertEqual(sys.flags.inspect, 0)
+assertEqual(sys.flags[3], sys.flags.optimize)
+assertEqual(sys.flags.dev_mode, False)
+assertEqual(sys.flags.int_max_str_digits, -1)
+assert repr(sys.flags).startswith("sys.flags(debug=0, inspect=0, interactive=0, optimize=0, ")
+assertEqual(sys.warnoptions, [])
+
+doc="platform"
+assertEqual(sys.maxsize, 2**63 - 1)
+assertEqual(sys.maxunicode, 0x10FFFF)
+assert sys.byteorder in ("little", "big")
+assertEqual(type(sys.platform), str)
+assertEqual(type(sys.executable), str)
+
+doc="std streams"
+assertEqual(sys.stdout.name, "<stdout>")
+assertEqual(sys.stderr.name, "<stderr>")
+assertEqual(sys.stdin.name, "<stdin>")
+assert sys.__stdout__ is sys.stdout
+assertEqual(sys.stdout.mode, "w")
+
+class Output:
+    def __init__(self):
+        self.written = []
+    def write(self, s):
+        self.written.append(s)
+        return len(s)
+    def flush(self):
+        self.written.append("<flush>")
+
+class Input:
+    def __init__(self, *lines):
+        self.lines = list(lines)
+    def readline(self):
+        if self.lines:
+            return self.lines.pop(0)
+        return ""
+
+out = Output()
+sys.stdout = out
+try:
+    print("hello", 1)
+finally:
+    sys.stdout = sys.__stdout__
+assertEqual(out.written, ["hello", " ", "1", "\n"])
+
+out = Output()
+sys.stdout, sys.stdin = out, Input("first\n", "last")
+try:
+    assertEqual(input("prompt> "), "first")
+    assertEqual(input(), "last")
+    assertRaises(EOFError, input)
+finally:
+    sys.stdout, sys.stdin = sys.__stdout__, sys.__stdin__
+assertEqual(out.written, ["prompt> ", "<flush>"])
+
+del sys.stdin
+try:
+    assertRaises(RuntimeError, input)
+finally:
+    sys.stdin = sys.__stdin__
+
+doc="_getframe"
+def outer_frame():
+    x = 1
+    return inner_frame()
+def inner_frame():
+    y = 2
+    return sys._getframe(), sys._getframe(1), sys._getframe(2)
+inner, outer, top = outer_frame()
+assertEqual(inner.f_code.co_name, "inner_frame")
+assertEqual(inner.f_locals, {"y": 2})
+assertEqual(outer.f_code.co_name, "outer_frame")
+assertEqual(outer.f_locals["x"], 1)
+assertTrue(inner.f_back is outer)
+assertTrue(outer.f_back is top)
+assertTrue(top.f_globals is globals())
+assertEqual(top.f_code.co_name, "<module>")
+assertEqual(outer.f_lineno, outer_frame.__code__.co_firstlineno + 2)
+assertEqual(sys._getframe().f_code.co_name, "<module>")
+assertTrue(repr(inner).startswith("<frame at 0x"))
+assertTrue(repr(inner).endswith(", line %d, code inner_frame>" % inner.f_lineno))
+assertRaises(ValueError, sys._getframe, 1000)
+assertRaises(TypeError, sys._getframe, "1")
+
+doc="recursion limit"
+assertEqual(sys.getrecursionlimit(), 1000)
+def depth(n):
+    try:
+        return depth(n + 1)
+    except RecursionError:
+        return n
+sys.setrecursionlimit(100)
+try:
+    assertEqual(sys.getrecursionlimit(), 100)
+    reached = depth(0)
+    assertTrue(80 < reached < 100)
+finally:
+    sys.setrecursionlimit(1000)
+assertTrue(depth(0) > 900)
+assertTrue(issubclass(RecursionError, RuntimeError))
+assertRaises(ValueError, sys.setrecursionlimit, 0)
+assertRaises(TypeError, sys.setrecursionlimit, "1")
+
+doc="finished"
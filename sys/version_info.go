@@ -0,0 +1,132 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// sys.version_info
+
+package sys
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+// The version of python which gpython implements
+const (
+	VersionMajor        = 3
+	VersionMinor        = 4
+	VersionMicro        = 0
+	VersionReleaseLevel = "final"
+	VersionSerial       = 0
+)
+
+// Version is the version of python which gpython implements as a
+// string
+var Version = fmt.Sprintf("%d.%d.%d", VersionMajor, VersionMinor, VersionMicro)
+
+var VersionInfoType = py.NewType("version_info", version_info__doc__)
+
+// The names of the fields of version_info in order
+var versionInfoFields = []string{"major", "minor", "micro", "releaselevel", "serial"}
+
+// VersionInfo is sys.version_info which behaves as a tuple of its
+// fields
+type VersionInfo struct {
+	fields py.Tuple
+}
+
+// Type of this object
+func (v *VersionInfo) Type() *py.Type {
+	return VersionInfoType
+}
+
+// Makes the version_info of this interpreter
+func newVersionInfo() *VersionInfo {
+	return &VersionInfo{
+		fields: py.Tuple{
+			py.Int(VersionMajor),
+			py.Int(VersionMinor),
+			py.Int(VersionMicro),
+			py.String(VersionReleaseLevel),
+			py.Int(VersionSerial),
+		},
+	}
+}
+
+// Returns the fields of other if it is a version_info
+func versionTuple(other py.Object) py.Object {
+	if o, ok := other.(*VersionInfo); ok {
+		return o.fields
+	}
+	return other
+}
+
+func (v *VersionInfo) M__getitem__(key py.Object) (py.Object, error) {
+	return v.fields.M__getitem__(key)
+}
+
+func (v *VersionInfo) M__len__() (py.Object, error) {
+	return py.Int(len(v.fields)), nil
+}
+
+func (v *VersionInfo) M__iter__() (py.Object, error) {
+	return py.NewIterator(v.fields), nil
+}
+
+func (v *VersionInfo) M__hash__() (py.Object, error) {
+	return v.fields.M__hash__()
+}
+
+func (v *VersionInfo) M__eq__(other py.Object) (py.Object, error) {
+	return v.fields.M__eq__(versionTuple(other))
+}
+
+func (v *VersionInfo) M__ne__(other py.Object) (py.Object, error) {
+	return v.fields.M__ne__(versionTuple(other))
+}
+
+func (v *VersionInfo) M__lt__(other py.Object) (py.Object, error) {
+	return v.fields.M__lt__(versionTuple(other))
+}
+
+func (v *VersionInfo) M__le__(other py.Object) (py.Object, error) {
+	return v.fields.M__le__(versionTuple(other))
+}
+
+func (v *VersionInfo) M__gt__(other py.Object) (py.Object, error) {
+	return v.fields.M__gt__(versionTuple(other))
+}
+
+func (v *VersionInfo) M__ge__(other py.Object) (py.Object, error) {
+	return v.fields.M__ge__(versionTuple(other))
+}
+
+func (v *VersionInfo) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("sys.version_info(")
+	for i, name := range versionInfoFields {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := py.ReprAsString(v.fields[i])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(name + "=" + repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+func init() {
+	for i, name := range versionInfoFields {
+		i := i
+		VersionInfoType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return self.(*VersionInfo).fields[i], nil
+			},
+		}
+	}
+}
@@ -0,0 +1,218 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import threading
+import time
+from libtest import *
+
+doc="start and join"
+results = []
+def work(n):
+    results.append(n)
+t = threading.Thread(target=work, args=(1,))
+assertEqual(t.ident, None)
+assertFalse(t.is_alive())
+t.start()
+t.join()
+assertEqual(results, [1])
+assertFalse(t.is_alive())
+assertTrue(t.ident > 1)
+assertRaisesText(RuntimeError, "threads can only be started once", t.start)
+assertRaisesText(RuntimeError, "cannot join thread before it is started", threading.Thread().join)
+
+doc="kwargs and names"
+results = []
+t = threading.Thread(target=work, kwargs={"n": 2}, name="worker")
+assertEqual(t.name, "worker")
+t.name = "renamed"
+assertEqual(t.getName(), "renamed")
+t.start()
+t.join()
+assertEqual(results, [2])
+
+doc="daemon"
+t = threading.Thread(target=work, args=(3,), daemon=True)
+assertTrue(t.daemon)
+t.daemon = False
+assertFalse(t.isDaemon())
+t.start()
+def setDaemon():
+    t.daemon = True
+assertRaisesText(RuntimeError, "cannot set daemon status of active thread", setDaemon)
+t.join()
+
+doc="subclass"
+class Worker(threading.Thread):
+    def __init__(self, value):
+        super().__init__(name="sub")
+        self.value = value
+    def run(self):
+        self.seen = (threading.current_thread() is self, self.value)
+w = Worker(42)
+w.start()
+w.join()
+assertEqual(w.seen, (True, 42))
+assertEqual(w.name, "sub")
+assertTrue(isinstance(w, threading.Thread))
+
+doc="current and main thread"
+main = threading.main_thread()
+assertTrue(threading.current_thread() is main)
+assertEqual(main.name, "MainThread")
+assertEqual(threading.get_ident(), main.ident)
+assertRaisesText(RuntimeError, "cannot join current thread", main.join)
+idents = []
+t = threading.Thread(target=lambda: idents.append(threading.get_ident()))
+t.start()
+t.join()
+assertEqual(idents, [t.ident])
+
+doc="threads switch while running"
+flag = []
+def spin():
+    while not flag:
+        pass
+t = threading.Thread(target=spin)
+t.start()
+flag.append(True)
+t.join()
+assertFalse(t.is_alive())
+
+doc="join with timeout"
+ev = threading.Event()
+t = threading.Thread(target=ev.wait)
+t.start()
+t.join(0.01)
+assertTrue(t.is_alive())
+ev.set()
+t.join()
+assertFalse(t.is_alive())
+
+doc="lock"
+lock = threading.Lock()
+assertFalse(lock.locked())
+assertTrue(lock.acquire())
+assertTrue(lock.locked())
+assertFalse(lock.acquire(False))
+assertFalse(lock.acquire(timeout=0.01))
+lock.release()
+assertRaisesText(RuntimeError, "release unlocked lock", lock.release)
+assertRaisesText(ValueError, "can't specify a timeout for a non-blocking call", lock.acquire, False, 1)
+assertRaisesText(ValueError, "timeout value must be positive", lock.acquire, True, -2)
+with lock:
+    assertTrue(lock.locked())
+assertFalse(lock.locked())
+
+doc="lock protects a counter"
+counter = [0]
+def count():
+    for i in range(500):
+        with lock:
+            counter[0] = counter[0] + 1
+threads = [threading.Thread(target=count) for i in range(4)]
+for t in threads:
+    t.start()
+for t in threads:
+    t.join()
+assertEqual(counter[0], 2000)
+
+doc="lock released by another thread"
+lock.acquire()
+t = threading.Thread(target=lock.release)
+t.start()
+t.join()
+assertFalse(lock.locked())
+
+doc="rlock"
+rlock = threading.RLock()
+assertTrue(rlock.acquire())
+assertTrue(rlock.acquire())
+rlock.release()
+rlock.release()
+assertRaisesText(RuntimeError, "cannot release un-acquired lock", rlock.release)
+with rlock:
+    with rlock:
+        got = []
+        t = threading.Thread(target=lambda: got.append(rlock.acquire(False)))
+        t.start()
+        t.join()
+        assertEqual(got, [False])
+
+doc="event"
+ev = threading.Event()
+assertFalse(ev.is_set())
+assertFalse(ev.wait(0))
+assertFalse(ev.wait(0.01))
+woken = []
+def waiter():
+    woken.append(ev.wait())
+t = threading.Thread(target=waiter)
+t.start()
+time.sleep(0.01)
+ev.set()
+t.join()
+assertEqual(woken, [True])
+assertTrue(ev.isSet())
+assertTrue(ev.wait())
+ev.clear()
+assertFalse(ev.is_set())
+
+doc="condition"
+cond = threading.Condition()
+items = []
+got = []
+def consumer():
+    with cond:
+        while not items:
+            cond.wait()
+        got.append(items[0])
+t = threading.Thread(target=consumer)
+t.start()
+time.sleep(0.01)
+with cond:
+    items.append("item")
+    cond.notify()
+t.join()
+assertEqual(got, ["item"])
+assertRaisesText(RuntimeError, "cannot wait on un-acquired lock", cond.wait)
+assertRaisesText(RuntimeError, "cannot notify on un-acquired lock", cond.notify)
+with cond:
+    assertFalse(cond.wait(0.01))
+
+doc="condition notify_all and wait_for"
+cond = threading.Condition(threading.Lock())
+state = []
+done = []
+def waitFor():
+    with cond:
+        done.append(cond.wait_for(lambda: state))
+threads = [threading.Thread(target=waitFor) for i in range(3)]
+for t in threads:
+    t.start()
+time.sleep(0.01)
+with cond:
+    state.append(1)
+    cond.notify_all()
+for t in threads:
+    t.join()
+assertEqual(done, [[1], [1], [1]])
+with cond:
+    assertEqual(cond.wait_for(lambda: False, 0.01), False)
+
+doc="enumerate and active_count"
+def running(t):
+    for x in threading.enumerate():
+        if x is t:
+            return True
+    return False
+ev = threading.Event()
+t = threading.Thread(target=ev.wait)
+t.start()
+assertTrue(running(t))
+assertEqual(threading.active_count(), len(threading.enumerate()))
+ev.set()
+t.join()
+assertFalse(running(t))
+
+doc="finished"
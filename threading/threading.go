@@ -0,0 +1,933 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Threading module
+//
+// Threads run as goroutines which take turns holding the global
+// interpreter lock in the vm package.  Anything which blocks, like
+// acquiring a lock or waiting for an event, releases the GIL while it
+// waits so the other threads can carry on.
+//
+// All the state here is only touched with the GIL held so it needs
+// no locking of its own.
+
+package threading
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+const threading_doc = `Thread module emulating a subset of Java's threading model.`
+
+// The threads which are running indexed by ident
+var active = map[int64]*Thread{}
+
+// Counts the threads made to give them default names
+var threadCount int
+
+// For making the names of threads started without the threading module
+var dummyCount int
+
+// The thread which started the interpreter
+var mainThread *Thread
+
+// Receives from ch with the GIL released, giving up after timeout
+// unless it is negative.  It returns whether ch was received from.
+func receive(ch <-chan struct{}, timeout time.Duration) (ok bool) {
+	select {
+	case <-ch:
+		return true
+	default:
+	}
+	if timeout == 0 {
+		return false
+	}
+	vm.AllowThreads(func() {
+		if timeout < 0 {
+			<-ch
+			ok = true
+			return
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-ch:
+			ok = true
+		case <-timer.C:
+		}
+	})
+	return ok
+}
+
+// Converts a timeout in seconds which may be None for no timeout into
+// a duration which is negative for no timeout
+func timeoutArg(timeout py.Object) (time.Duration, error) {
+	if timeout == py.None {
+		return -1, nil
+	}
+	secs, err := py.FloatAsFloat64(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if secs < 0 {
+		secs = 0
+	}
+	return time.Duration(secs * 1e9), nil
+}
+
+// ------------------------------------------------------------
+// Thread
+
+const thread_doc = `A class that represents a thread of control.
+
+This class can be safely subclassed in a limited fashion. There are two ways
+to specify the activity: by passing a callable object to the constructor, or
+by overriding the run() method in a subclass.`
+
+// ThreadType is the type of Thread objects
+var ThreadType = py.ObjectType.NewTypeFlags("Thread", thread_doc, ThreadNew, ThreadInit, py.ObjectType.Flags|py.TPFLAGS_SUBCLASS_NEW)
+
+// Thread is a python thread
+type Thread struct {
+	Base    *py.Type
+	Dict    py.StringDict
+	Name    string
+	Daemon  bool
+	Ident   int64 // set when the thread is started
+	target  py.Object
+	args    py.Tuple
+	kwargs  py.StringDict
+	started bool
+	done    chan struct{} // closed when the thread finishes
+}
+
+// Type of this object
+func (t *Thread) Type() *py.Type {
+	return t.Base
+}
+
+// GetDict returns the attributes of the thread
+func (t *Thread) GetDict() py.StringDict {
+	return t.Dict
+}
+
+// ThreadNew makes a thread with the default name
+func ThreadNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	threadCount++
+	return &Thread{
+		Base:   metatype,
+		Dict:   py.NewStringDict(),
+		Name:   fmt.Sprintf("Thread-%d", threadCount),
+		Daemon: currentThread().Daemon,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// ThreadInit sets the target, name and daemon status of the thread
+func ThreadInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	t := self.(*Thread)
+	var group, target, name, targs, tkwargs, daemon py.Object = py.None, py.None, py.None, py.Tuple{}, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOO:Thread", []string{"group", "target", "name", "args", "kwargs", "daemon"}, &group, &target, &name, &targs, &tkwargs, &daemon)
+	if err != nil {
+		return err
+	}
+	if group != py.None {
+		return py.ExceptionNewf(py.AssertionError, "group argument must be None for now")
+	}
+	if name != py.None {
+		s, err := py.StrAsString(name)
+		if err != nil {
+			return err
+		}
+		t.Name = s
+	}
+	if t.args, err = py.SequenceTuple(targs); err != nil {
+		return err
+	}
+	t.kwargs = nil
+	if tkwargs != py.None {
+		if t.kwargs, err = py.AsStringDict(tkwargs); err != nil {
+			return err
+		}
+	}
+	if target != py.None {
+		t.target = target
+	}
+	if daemon != py.None {
+		t.Daemon = py.ObjectIsTrue(daemon)
+	}
+	return nil
+}
+
+// Returns whether the thread has started and not yet finished
+func (t *Thread) isAlive() bool {
+	if !t.started {
+		return false
+	}
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Start starts the thread running its run method
+func (t *Thread) Start() error {
+	if t.started {
+		return py.ExceptionNewf(py.RuntimeError, "threads can only be started once")
+	}
+	t.started = true
+	t.Ident = vm.StartThread(t.bootstrap, t.Daemon)
+	active[t.Ident] = t
+	return nil
+}
+
+// Runs the thread in its goroutine reporting any uncaught exception
+func (t *Thread) bootstrap() {
+	run, err := py.GetAttrString(t, "run")
+	if err == nil {
+		_, err = py.Call(run, nil, nil)
+	}
+	if err != nil && !py.IsException(py.SystemExit, err) {
+		fmt.Fprintf(os.Stderr, "Exception in thread %s:\n", t.Name)
+		py.TracebackDump(err)
+	}
+	delete(active, t.Ident)
+	close(t.done)
+}
+
+// Run calls the target of the thread
+func (t *Thread) Run() error {
+	if t.target == nil {
+		return nil
+	}
+	_, err := py.Call(t.target, t.args, t.kwargs)
+	// Don't keep references to the arguments once they are done with
+	t.target, t.args, t.kwargs = nil, nil, nil
+	return err
+}
+
+// Join waits for the thread to finish returning whether it did
+// before the timeout, which is ignored if negative
+func (t *Thread) Join(timeout time.Duration) (bool, error) {
+	if !t.started {
+		return false, py.ExceptionNewf(py.RuntimeError, "cannot join thread before it is started")
+	}
+	if t == currentThread() {
+		return false, py.ExceptionNewf(py.RuntimeError, "cannot join current thread")
+	}
+	if t.done == nil {
+		return false, nil
+	}
+	return receive(t.done, timeout), nil
+}
+
+func (t *Thread) M__repr__() (py.Object, error) {
+	status := "initial"
+	if t.started {
+		status = "started"
+		if !t.isAlive() {
+			status = "stopped"
+		}
+	}
+	if t.Daemon {
+		status += " daemon"
+	}
+	if t.started {
+		status += fmt.Sprintf(" %d", t.Ident)
+	}
+	return py.String(fmt.Sprintf("<%s(%s, %s)>", t.Base.Name, t.Name, status)), nil
+}
+
+// Returns the Thread for the running thread making a dummy one if it
+// wasn't started by this module
+func currentThread() *Thread {
+	ident := vm.ThreadIdent()
+	if t, ok := active[ident]; ok {
+		return t
+	}
+	dummyCount++
+	t := &Thread{
+		Base:    mainThread.Base, // ThreadType without an initialization loop
+		Dict:    py.NewStringDict(),
+		Name:    fmt.Sprintf("Dummy-%d", dummyCount),
+		Daemon:  true,
+		Ident:   ident,
+		started: true,
+	}
+	active[ident] = t
+	return t
+}
+
+// Properties and methods of Thread
+func init() {
+	ThreadType.Dict["name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*Thread).Name), nil
+		},
+		Fset: func(self, value py.Object) error {
+			name, err := py.StrAsString(value)
+			if err != nil {
+				return err
+			}
+			self.(*Thread).Name = name
+			return nil
+		},
+		Doc: "A string used for identification purposes only.",
+	}
+	ThreadType.Dict["daemon"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.NewBool(self.(*Thread).Daemon), nil
+		},
+		Fset: func(self, value py.Object) error {
+			t := self.(*Thread)
+			if t.started {
+				return py.ExceptionNewf(py.RuntimeError, "cannot set daemon status of active thread")
+			}
+			t.Daemon = py.ObjectIsTrue(value)
+			return nil
+		},
+		Doc: "A boolean value indicating whether this thread is a daemon thread.",
+	}
+	ThreadType.Dict["ident"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			t := self.(*Thread)
+			if !t.started {
+				return py.None, nil
+			}
+			return py.Int(t.Ident), nil
+		},
+		Doc: "Thread identifier of this thread or None if it has not been started.",
+	}
+	ThreadType.Dict["start"] = py.MustNewMethod("start", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*Thread).Start()
+	}, 0, "Start the thread's activity.\n\nIt must be called at most once per thread object.")
+	ThreadType.Dict["run"] = py.MustNewMethod("run", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*Thread).Run()
+	}, 0, "Method representing the thread's activity.\n\nYou may override this method in a subclass.")
+	ThreadType.Dict["join"] = py.MustNewMethod("join", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var timeout py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:join", []string{"timeout"}, &timeout)
+		if err != nil {
+			return nil, err
+		}
+		d, err := timeoutArg(timeout)
+		if err != nil {
+			return nil, err
+		}
+		_, err = self.(*Thread).Join(d)
+		return py.None, err
+	}, 0, "Wait until the thread terminates.\n\nWhen the timeout argument is present and not None, it should be a\nfloating point number specifying a timeout for the operation in seconds.")
+	isAlive := func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*Thread).isAlive()), nil
+	}
+	ThreadType.Dict["is_alive"] = py.MustNewMethod("is_alive", isAlive, 0, "Return whether the thread is alive.")
+	ThreadType.Dict["isAlive"] = py.MustNewMethod("isAlive", isAlive, 0, "Return whether the thread is alive.")
+	ThreadType.Dict["getName"] = py.MustNewMethod("getName", func(self py.Object) (py.Object, error) {
+		return py.String(self.(*Thread).Name), nil
+	}, 0, "")
+	ThreadType.Dict["setName"] = py.MustNewMethod("setName", func(self, name py.Object) (py.Object, error) {
+		_, err := py.SetAttrString(self, "name", name)
+		return py.None, err
+	}, 0, "")
+	ThreadType.Dict["isDaemon"] = py.MustNewMethod("isDaemon", func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*Thread).Daemon), nil
+	}, 0, "")
+	ThreadType.Dict["setDaemon"] = py.MustNewMethod("setDaemon", func(self, daemonic py.Object) (py.Object, error) {
+		_, err := py.SetAttrString(self, "daemon", daemonic)
+		return py.None, err
+	}, 0, "")
+}
+
+// ------------------------------------------------------------
+// Locks
+
+// The locks which a Condition can use
+type lock interface {
+	py.Object
+	acquire(blocking bool, timeout time.Duration) bool
+	release() error
+	isOwned() bool
+	releaseSave() (int, error)
+	acquireRestore(count int)
+}
+
+// Parses the arguments of acquire
+func acquireArgs(args py.Tuple, kwargs py.StringDict) (blocking bool, timeout time.Duration, err error) {
+	var blockingObj, timeoutObj py.Object = py.True, py.Float(-1)
+	err = py.ParseTupleAndKeywords(args, kwargs, "|OO:acquire", []string{"blocking", "timeout"}, &blockingObj, &timeoutObj)
+	if err != nil {
+		return false, 0, err
+	}
+	blocking = py.ObjectIsTrue(blockingObj)
+	secs, err := py.FloatAsFloat64(timeoutObj)
+	if err != nil {
+		return false, 0, err
+	}
+	if !blocking && secs != -1 {
+		return false, 0, py.ExceptionNewf(py.ValueError, "can't specify a timeout for a non-blocking call")
+	}
+	if secs < 0 && secs != -1 {
+		return false, 0, py.ExceptionNewf(py.ValueError, "timeout value must be positive")
+	}
+	if secs > math.MaxInt64/1e9 {
+		return false, 0, py.ExceptionNewf(py.OverflowError, "timeout value is too large")
+	}
+	if secs == -1 {
+		return blocking, -1, nil
+	}
+	return blocking, time.Duration(secs * 1e9), nil
+}
+
+// Adds the methods which all the locks have to t
+func addLockMethods(t *py.Type) {
+	t.Dict["acquire"] = py.MustNewMethod("acquire", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		blocking, timeout, err := acquireArgs(args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		if !blocking {
+			timeout = 0
+		}
+		return py.NewBool(self.(lock).acquire(true, timeout)), nil
+	}, 0, `acquire(blocking=True, timeout=-1) -> bool
+
+Lock the lock.  Without argument, this blocks if the lock is already
+locked (even by the same thread), waiting for another thread to release
+the lock, and return True once the lock is acquired.
+With an argument, this will only block if the argument is true,
+and the return value reflects whether the lock is acquired.
+The blocking operation is interruptible.`)
+	t.Dict["release"] = py.MustNewMethod("release", func(self py.Object) (py.Object, error) {
+		return py.None, self.(lock).release()
+	}, 0, `release()
+
+Release the lock, allowing another thread that is blocked waiting for
+the lock to acquire the lock.`)
+	t.Dict["__enter__"] = py.MustNewMethod("__enter__", func(self py.Object) (py.Object, error) {
+		self.(lock).acquire(true, -1)
+		return py.True, nil
+	}, 0, "")
+	t.Dict["__exit__"] = py.MustNewMethod("__exit__", func(self py.Object, args py.Tuple) (py.Object, error) {
+		return py.None, self.(lock).release()
+	}, 0, "")
+}
+
+const lock_doc = `A lock object is a synchronization primitive.  To create a lock,
+call threading.Lock().  Methods are:
+
+acquire() -- lock the lock, possibly blocking until it can be obtained
+release() -- unlock of the lock
+locked() -- test whether the lock is currently locked
+
+A lock is not owned by the thread that locked it; another thread may
+unlock it.  A thread attempting to lock a lock that it has already locked
+will block until another thread unlocks it.  Deadlocks may ensue.`
+
+// LockType is the type of Lock objects
+var LockType = py.NewType("lock", lock_doc)
+
+// Lock is a lock which any thread can release
+type Lock struct {
+	token chan struct{} // holds a token while unlocked
+}
+
+// Type of this object
+func (l *Lock) Type() *py.Type {
+	return LockType
+}
+
+// NewLock makes an unlocked Lock
+func NewLock() *Lock {
+	l := &Lock{token: make(chan struct{}, 1)}
+	l.token <- struct{}{}
+	return l
+}
+
+func (l *Lock) acquire(blocking bool, timeout time.Duration) bool {
+	if !blocking {
+		timeout = 0
+	}
+	return receive(l.token, timeout)
+}
+
+func (l *Lock) release() error {
+	select {
+	case l.token <- struct{}{}:
+		return nil
+	default:
+		return py.ExceptionNewf(py.RuntimeError, "release unlocked lock")
+	}
+}
+
+// Returns whether the lock is locked
+func (l *Lock) locked() bool {
+	return len(l.token) == 0
+}
+
+// A Lock has no owner so it counts as owned if it is locked
+func (l *Lock) isOwned() bool {
+	return l.locked()
+}
+
+func (l *Lock) releaseSave() (int, error) {
+	return 0, l.release()
+}
+
+func (l *Lock) acquireRestore(count int) {
+	l.acquire(true, -1)
+}
+
+const rlock_doc = `A reentrant lock must be released by the thread that acquired it. Once a
+thread has acquired a reentrant lock, the same thread may acquire it
+again without blocking; the thread must release it once for each time it
+has acquired it.`
+
+// RLockType is the type of RLock objects
+var RLockType = py.NewType("RLock", rlock_doc)
+
+// RLock is a lock which the thread holding it can acquire again
+type RLock struct {
+	lock  *Lock
+	owner int64 // ident of the thread holding it or 0
+	count int   // number of times owner has acquired it
+}
+
+// Type of this object
+func (r *RLock) Type() *py.Type {
+	return RLockType
+}
+
+// NewRLock makes an unlocked RLock
+func NewRLock() *RLock {
+	return &RLock{lock: NewLock()}
+}
+
+func (r *RLock) acquire(blocking bool, timeout time.Duration) bool {
+	me := vm.ThreadIdent()
+	if r.owner == me {
+		r.count++
+		return true
+	}
+	if !r.lock.acquire(blocking, timeout) {
+		return false
+	}
+	r.owner = me
+	r.count = 1
+	return true
+}
+
+func (r *RLock) release() error {
+	if r.owner != vm.ThreadIdent() {
+		return py.ExceptionNewf(py.RuntimeError, "cannot release un-acquired lock")
+	}
+	r.count--
+	if r.count == 0 {
+		r.owner = 0
+		return r.lock.release()
+	}
+	return nil
+}
+
+func (r *RLock) isOwned() bool {
+	return r.owner == vm.ThreadIdent()
+}
+
+// Releases the lock however many times it has been acquired
+func (r *RLock) releaseSave() (int, error) {
+	if r.count == 0 {
+		return 0, py.ExceptionNewf(py.RuntimeError, "cannot release un-acquired lock")
+	}
+	count := r.count
+	r.owner, r.count = 0, 0
+	return count, r.lock.release()
+}
+
+func (r *RLock) acquireRestore(count int) {
+	r.lock.acquire(true, -1)
+	r.owner = vm.ThreadIdent()
+	r.count = count
+}
+
+func (r *RLock) M__repr__() (py.Object, error) {
+	status := "unlocked"
+	if r.count > 0 {
+		status = "locked"
+	}
+	return py.String(fmt.Sprintf("<%s RLock object owner=%d count=%d>", status, r.owner, r.count)), nil
+}
+
+func init() {
+	addLockMethods(LockType)
+	LockType.Dict["locked"] = py.MustNewMethod("locked", func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*Lock).locked()), nil
+	}, 0, "locked() -> bool\n\nReturn whether the lock is in the locked state.")
+	addLockMethods(RLockType)
+}
+
+// ------------------------------------------------------------
+// Condition
+
+const condition_doc = `Class that implements a condition variable.
+
+A condition variable allows one or more threads to wait until they are
+notified by another thread.
+
+If the lock argument is given and not None, it must be a Lock or RLock
+object, and it is used as the underlying lock. Otherwise, a new RLock object
+is created and used as the underlying lock.`
+
+// ConditionType is the type of Condition objects
+var ConditionType = py.NewTypeX("Condition", condition_doc, ConditionNew, nil)
+
+// Condition is a condition variable
+type Condition struct {
+	lock    lock
+	waiters []chan struct{}
+}
+
+// Type of this object
+func (c *Condition) Type() *py.Type {
+	return ConditionType
+}
+
+// ConditionNew makes a Condition using the lock passed in or a new
+// RLock
+func ConditionNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var lockObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:Condition", []string{"lock"}, &lockObj)
+	if err != nil {
+		return nil, err
+	}
+	if lockObj == py.None {
+		return &Condition{lock: NewRLock()}, nil
+	}
+	l, ok := lockObj.(lock)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "Condition() lock must be a Lock or RLock, not %s", lockObj.Type().Name)
+	}
+	return &Condition{lock: l}, nil
+}
+
+// Wait releases the lock and waits to be notified, returning false if
+// the timeout, which is ignored if negative, expired first
+func (c *Condition) Wait(timeout time.Duration) (bool, error) {
+	if !c.lock.isOwned() {
+		return false, py.ExceptionNewf(py.RuntimeError, "cannot wait on un-acquired lock")
+	}
+	waiter := make(chan struct{}, 1)
+	c.waiters = append(c.waiters, waiter)
+	count, err := c.lock.releaseSave()
+	if err != nil {
+		return false, err
+	}
+	notified := receive(waiter, timeout)
+	c.lock.acquireRestore(count)
+	if !notified {
+		for i, w := range c.waiters {
+			if w == waiter {
+				c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+				break
+			}
+		}
+		// Notified after the timeout but before the lock was
+		// reacquired
+		notified = len(waiter) > 0
+	}
+	return notified, nil
+}
+
+// Notify wakes up to n of the threads waiting
+func (c *Condition) Notify(n int) error {
+	if !c.lock.isOwned() {
+		return py.ExceptionNewf(py.RuntimeError, "cannot notify on un-acquired lock")
+	}
+	for ; n > 0 && len(c.waiters) > 0; n-- {
+		c.waiters[0] <- struct{}{}
+		c.waiters = c.waiters[1:]
+	}
+	return nil
+}
+
+func (c *Condition) M__enter__() (py.Object, error) {
+	return py.NewBool(c.lock.acquire(true, -1)), nil
+}
+
+func (c *Condition) M__exit__(excType, excValue, traceback py.Object) (py.Object, error) {
+	return py.None, c.lock.release()
+}
+
+func init() {
+	ConditionType.Dict["acquire"] = py.MustNewMethod("acquire", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		blocking, timeout, err := acquireArgs(args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(self.(*Condition).lock.acquire(blocking, timeout)), nil
+	}, 0, "Acquire the underlying lock.")
+	ConditionType.Dict["release"] = py.MustNewMethod("release", func(self py.Object) (py.Object, error) {
+		return py.None, self.(*Condition).lock.release()
+	}, 0, "Release the underlying lock.")
+	ConditionType.Dict["wait"] = py.MustNewMethod("wait", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var timeout py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:wait", []string{"timeout"}, &timeout)
+		if err != nil {
+			return nil, err
+		}
+		d, err := timeoutArg(timeout)
+		if err != nil {
+			return nil, err
+		}
+		notified, err := self.(*Condition).Wait(d)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(notified), nil
+	}, 0, `Wait until notified or until a timeout occurs.
+
+If the calling thread has not acquired the lock when this method is
+called, a RuntimeError is raised.`)
+	ConditionType.Dict["wait_for"] = py.MustNewMethod("wait_for", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var predicate, timeout py.Object = nil, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:wait_for", []string{"predicate", "timeout"}, &predicate, &timeout)
+		if err != nil {
+			return nil, err
+		}
+		d, err := timeoutArg(timeout)
+		if err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(d)
+		for {
+			result, err := py.Call(predicate, nil, nil)
+			if err != nil || py.ObjectIsTrue(result) {
+				return result, err
+			}
+			wait := time.Duration(-1)
+			if d >= 0 {
+				wait = time.Until(deadline)
+				if wait <= 0 {
+					return result, nil
+				}
+			}
+			_, err = self.(*Condition).Wait(wait)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}, 0, `Wait until a condition evaluates to True.
+
+predicate should be a callable which result will be interpreted as a
+boolean value.  A timeout may be provided giving the maximum time to
+wait.`)
+	notify := func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var n py.Object = py.Int(1)
+		err := py.ParseTupleAndKeywords(args, kwargs, "|i:notify", []string{"n"}, &n)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, self.(*Condition).Notify(int(n.(py.Int)))
+	}
+	ConditionType.Dict["notify"] = py.MustNewMethod("notify", notify, 0, "Wake up one or more threads waiting on this condition, if any.")
+	notifyAll := func(self py.Object) (py.Object, error) {
+		c := self.(*Condition)
+		return py.None, c.Notify(len(c.waiters))
+	}
+	ConditionType.Dict["notify_all"] = py.MustNewMethod("notify_all", notifyAll, 0, "Wake up all threads waiting on this condition.")
+	ConditionType.Dict["notifyAll"] = py.MustNewMethod("notifyAll", notifyAll, 0, "Wake up all threads waiting on this condition.")
+}
+
+// ------------------------------------------------------------
+// Event
+
+const event_doc = `Class implementing event objects.
+
+Events manage a flag that can be set to true with the set() method and reset
+to false with the clear() method. The wait() method blocks until the flag is
+true.  The flag is initially false.`
+
+// EventType is the type of Event objects
+var EventType = py.NewTypeX("Event", event_doc, EventNew, nil)
+
+// Event is a flag which threads can wait to be set
+type Event struct {
+	flag bool
+	set  chan struct{} // closed when the flag is set
+}
+
+// Type of this object
+func (e *Event) Type() *py.Type {
+	return EventType
+}
+
+// EventNew makes an Event with its flag clear
+func EventNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "Event", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{set: make(chan struct{})}, nil
+}
+
+// Set sets the flag waking up the threads waiting for it
+func (e *Event) Set() {
+	if !e.flag {
+		e.flag = true
+		close(e.set)
+	}
+}
+
+// Clear clears the flag
+func (e *Event) Clear() {
+	if e.flag {
+		e.flag = false
+		e.set = make(chan struct{})
+	}
+}
+
+// Wait waits for the flag to be set, giving up after the timeout
+// unless it is negative, and returns the flag
+func (e *Event) Wait(timeout time.Duration) bool {
+	if !e.flag {
+		receive(e.set, timeout)
+	}
+	return e.flag
+}
+
+func init() {
+	isSet := func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*Event).flag), nil
+	}
+	EventType.Dict["is_set"] = py.MustNewMethod("is_set", isSet, 0, "Return true if and only if the internal flag is true.")
+	EventType.Dict["isSet"] = py.MustNewMethod("isSet", isSet, 0, "Return true if and only if the internal flag is true.")
+	EventType.Dict["set"] = py.MustNewMethod("set", func(self py.Object) (py.Object, error) {
+		self.(*Event).Set()
+		return py.None, nil
+	}, 0, "Set the internal flag to true.\n\nAll threads waiting for it to become true are awakened.")
+	EventType.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object) (py.Object, error) {
+		self.(*Event).Clear()
+		return py.None, nil
+	}, 0, "Reset the internal flag to false.")
+	EventType.Dict["wait"] = py.MustNewMethod("wait", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var timeout py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:wait", []string{"timeout"}, &timeout)
+		if err != nil {
+			return nil, err
+		}
+		d, err := timeoutArg(timeout)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(self.(*Event).Wait(d)), nil
+	}, 0, `Block until the internal flag is true.
+
+When the timeout argument is present and not None, it should be a
+floating point number specifying a timeout for the operation in seconds.
+Returns the internal flag on exit.`)
+}
+
+// Check interface is satisfied
+var (
+	_ py.IGetDict   = (*Thread)(nil)
+	_ py.I__repr__  = (*Thread)(nil)
+	_ lock          = (*Lock)(nil)
+	_ lock          = (*RLock)(nil)
+	_ py.I__repr__  = (*RLock)(nil)
+	_ py.I__enter__ = (*Condition)(nil)
+	_ py.I__exit__  = (*Condition)(nil)
+)
+
+// ------------------------------------------------------------
+// Module functions
+
+const current_thread_doc = `Return the current Thread object, corresponding to the caller's thread of control.`
+
+func threading_current_thread(self py.Object) (py.Object, error) {
+	return currentThread(), nil
+}
+
+const main_thread_doc = `Return the main thread object.`
+
+func threading_main_thread(self py.Object) (py.Object, error) {
+	return mainThread, nil
+}
+
+const active_count_doc = `Return the number of Thread objects currently alive.`
+
+func threading_active_count(self py.Object) (py.Object, error) {
+	return py.Int(len(active)), nil
+}
+
+const enumerate_doc = `Return a list of all Thread objects currently alive.`
+
+func threading_enumerate(self py.Object) (py.Object, error) {
+	res := py.NewList()
+	for _, t := range active {
+		res.Append(t)
+	}
+	return res, nil
+}
+
+const get_ident_doc = `Return a non-zero integer that uniquely identifies the current thread
+amongst other threads that exist simultaneously.`
+
+func threading_get_ident(self py.Object) (py.Object, error) {
+	return py.Int(vm.ThreadIdent()), nil
+}
+
+const Lock_doc = `Allocate a new lock object.`
+
+func threading_Lock(self py.Object) (py.Object, error) {
+	return NewLock(), nil
+}
+
+const RLock_doc = `Allocate a new reentrant lock object.`
+
+func threading_RLock(self py.Object) (py.Object, error) {
+	return NewRLock(), nil
+}
+
+// Initialise the module
+func init() {
+	mainThread = &Thread{
+		Base:    ThreadType,
+		Dict:    py.NewStringDict(),
+		Name:    "MainThread",
+		Ident:   vm.ThreadIdent(),
+		started: true,
+		done:    make(chan struct{}),
+	}
+	active[mainThread.Ident] = mainThread
+
+	methods := []*py.Method{
+		py.MustNewMethod("current_thread", threading_current_thread, 0, current_thread_doc),
+		py.MustNewMethod("currentThread", threading_current_thread, 0, current_thread_doc),
+		py.MustNewMethod("main_thread", threading_main_thread, 0, main_thread_doc),
+		py.MustNewMethod("active_count", threading_active_count, 0, active_count_doc),
+		py.MustNewMethod("activeCount", threading_active_count, 0, active_count_doc),
+		py.MustNewMethod("enumerate", threading_enumerate, 0, enumerate_doc),
+		py.MustNewMethod("get_ident", threading_get_ident, 0, get_ident_doc),
+		py.MustNewMethod("Lock", threading_Lock, 0, Lock_doc),
+		py.MustNewMethod("RLock", threading_RLock, 0, RLock_doc),
+	}
+	globals := py.StringDict{
+		"Thread":      ThreadType,
+		"Condition":   ConditionType,
+		"Event":       EventType,
+		"TIMEOUT_MAX": py.Float(math.MaxInt64 / 1e9),
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "threading",
+		Doc:     threading_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
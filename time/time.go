@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
 )
 
 const time_doc = `time() -> floating point number
@@ -141,7 +142,10 @@ func time_sleep(self py.Object, args py.Tuple) (py.Object, error) {
 	if secs < 0 {
 		return nil, py.ExceptionNewf(py.ValueError, "sleep length must be non-negative")
 	}
-	time.Sleep(time.Duration(secs * 1e9))
+	err = vm.Sleep(time.Duration(secs * 1e9))
+	if err != nil {
+		return nil, err
+	}
 	return py.None, nil
 }
 
@@ -649,9 +653,16 @@ Monotonic clock, cannot go backward.`
 // 	return py.Float(tp.tv_sec + tp.tv_nsec*1e-9)
 // }
 
+// The monotonic clock is measured from here
+var monotonicStart = time.Now()
+
+// Returns the seconds on the monotonic clock
+func monotonic() py.Float {
+	return py.Float(time.Since(monotonicStart).Seconds())
+}
+
 func time_monotonic(self py.Object) (py.Object, error) {
-	// return pymonotonic(nil)
-	return nil, py.NotImplementedError
+	return monotonic(), nil
 }
 
 // func perf_counter(_Py_clock_info_t *info) py.Object {
@@ -674,8 +685,7 @@ const perf_counter_doc = `perf_counter() -> float
 Performance counter for benchmarking.`
 
 func time_perf_counter(self py.Object) (py.Object, error) {
-	// return perf_counter(nil)
-	return nil, py.NotImplementedError
+	return monotonic(), nil
 }
 
 // func py_process_time(_Py_clock_info_t *info) py.Object {
@@ -1000,7 +1010,12 @@ func init() {
 	globals := py.StringDict{
 		//"version": py.Int(MARSHAL_VERSION),
 	}
-	py.NewModule("time", module_doc, methods, globals)
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "time",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
 
 }
 
@@ -0,0 +1,71 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import time
+import timeit
+from libtest import *
+
+ticks = [0]
+def timer():
+    ticks[0] += 1
+    return ticks[0]
+
+doc="default_timer"
+assert timeit.default_timer is time.perf_counter
+t0 = time.perf_counter()
+t1 = time.perf_counter()
+assert t1 >= t0
+assertEqual(timeit.default_number, 1000000)
+assertEqual(timeit.default_repeat, 5)
+
+doc="Timer"
+ns = {"count": 0}
+t = timeit.Timer("count += 1", setup="count = 0", globals=ns)
+taken = t.timeit(number=10)
+assert isinstance(taken, float)
+assert taken >= 0
+
+calls = []
+t = timeit.Timer(lambda: calls.append(1), setup=lambda: calls.append(0), timer=timer)
+assertEqual(t.timeit(5), 1)
+assertEqual(calls, [0, 1, 1, 1, 1, 1])
+
+doc="statements share the setup namespace"
+ns = {"seen": []}
+t = timeit.Timer("seen.append(x)\nx += 1", setup="x = 10\ny = 2", globals=ns)
+t.timeit(3)
+assertEqual(ns["seen"], [10, 11, 12])
+
+doc="repeat"
+calls = []
+times = timeit.Timer(lambda: calls.append(1), timer=timer).repeat(repeat=3, number=2)
+assertEqual(times, [1, 1, 1])
+assertEqual(len(calls), 6)
+assertEqual(len(timeit.repeat("pass", repeat=2, number=5)), 2)
+
+doc="autorange"
+clock = []
+def slow_timer():
+    return sum(clock)
+trials = []
+number, taken = timeit.Timer(lambda: clock.append(0.05), timer=slow_timer).autorange(lambda n, t: trials.append(n))
+assertEqual(trials, [1, 2, 5])
+assertEqual(number, 5)
+assert 0.2 <= taken
+
+doc="module functions"
+calls = []
+timeit.timeit(lambda: calls.append(1), number=4)
+assertEqual(len(calls), 4)
+assert timeit.timeit("x = 1", number=0) >= 0
+assertEqual(timeit.repeat("x = 1", repeat=-1), [])
+
+doc="errors"
+assertRaises(SyntaxError, timeit.Timer, "x = ")
+assertRaises(SyntaxError, timeit.Timer, "pass", "return")
+assertRaises(ValueError, timeit.Timer, 1)
+assertRaises(ValueError, timeit.Timer, "pass", 1)
+assertRaises(ZeroDivisionError, timeit.timeit, "1/0", number=1)
+
+doc="finished"
@@ -0,0 +1,387 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Timeit module
+//
+// The statement being timed is compiled into a function with the same
+// template as CPython uses so that it runs in a loop without the
+// overhead of calling it each time.
+
+package timeit
+
+import (
+	"strings"
+
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/time" // for default_timer
+)
+
+const timeit_doc = `Tool for measuring execution time of small code snippets.
+
+This module avoids a number of common traps for measuring execution
+times.
+
+Library usage: see the Timer class.
+
+Classes:
+
+    Timer
+
+Functions:
+
+    timeit(string, string) -> float
+    repeat(string, string) -> list
+    default_timer() -> float`
+
+const (
+	defaultNumber = 1000000
+	defaultRepeat = 5
+	dummySrcName  = "<timeit-src>"
+)
+
+// The function the statement is timed in
+const template = `
+def inner(_it, _timer{init}):
+    {setup}
+    _t0 = _timer()
+    for _i in _it:
+        {stmt}
+        pass
+    _t1 = _timer()
+    return _t1 - _t0
+`
+
+var TimerType = py.NewTypeX("Timer", timer_doc, TimerNew, nil)
+
+const timer_doc = `Class for timing execution speed of small code snippets.
+
+The constructor takes a statement to be timed, an additional
+statement used for setup, and a timer function.  Both statements
+default to 'pass'; the timer function defaults to time.perf_counter.
+There is also an optional globals dict in which the statements are
+run.
+
+The statements may contain newlines, as long as they don't contain
+multi-line string literals.  They may also be callables with no
+arguments.`
+
+// Timer times a statement
+type Timer struct {
+	timer py.Object // the timer function
+	inner py.Object // the function running the statement in a loop
+	src   string    // the source of inner
+}
+
+// Type of this object
+func (t *Timer) Type() *py.Type {
+	return TimerType
+}
+
+// Indents all the lines of src but the first by n spaces
+func reindent(src string, n int) string {
+	return strings.Replace(src, "\n", "\n"+strings.Repeat(" ", n), -1)
+}
+
+// Checks that src compiles outside of a function
+func checkCompiles(src string) error {
+	_, err := py.Compile(src, dummySrcName, "exec", 0, true)
+	return err
+}
+
+// Returns whether obj can be called
+func isCallable(obj py.Object) bool {
+	_, ok := obj.(py.I__call__)
+	return ok
+}
+
+// Returns the timer function for the timer argument
+func timerArg(timer py.Object) (py.Object, error) {
+	if timer != py.None {
+		return timer, nil
+	}
+	time, err := py.GetModule("time")
+	if err != nil {
+		return nil, err
+	}
+	return time.Globals["perf_counter"], nil
+}
+
+// NewTimer makes a Timer for stmt and setup which are either source
+// or callables.  The statements are run in globals or a new namespace
+// if it is nil.
+func NewTimer(stmt, setup, timer py.Object, globals py.StringDict) (*Timer, error) {
+	localNs := py.NewStringDict()
+	if globals == nil {
+		globals = py.NewStringDict()
+	}
+	init := ""
+	var setupSrc, stmtPrefix string
+	if s, ok := setup.(py.String); ok {
+		err := checkCompiles(string(s))
+		if err != nil {
+			return nil, err
+		}
+		stmtPrefix = string(s) + "\n"
+		setupSrc = reindent(string(s), 4)
+	} else if isCallable(setup) {
+		localNs["_setup"] = setup
+		init += ", _setup=_setup"
+		setupSrc = "_setup()"
+	} else {
+		return nil, py.ExceptionNewf(py.ValueError, "setup is neither a string nor callable")
+	}
+	var stmtSrc string
+	if s, ok := stmt.(py.String); ok {
+		err := checkCompiles(stmtPrefix + string(s))
+		if err != nil {
+			return nil, err
+		}
+		stmtSrc = reindent(string(s), 8)
+	} else if isCallable(stmt) {
+		localNs["_stmt"] = stmt
+		init += ", _stmt=_stmt"
+		stmtSrc = "_stmt()"
+	} else {
+		return nil, py.ExceptionNewf(py.ValueError, "stmt is neither a string nor callable")
+	}
+	src := strings.NewReplacer("{init}", init, "{setup}", setupSrc, "{stmt}", stmtSrc).Replace(template)
+	code, err := py.Compile(src, dummySrcName, "exec", 0, true)
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.VmRun(globals, localNs, code.(*py.Code), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Timer{
+		timer: timer,
+		inner: localNs["inner"],
+		src:   src,
+	}, nil
+}
+
+// Returns the namespace for the globals argument
+func globalsArg(globals py.Object) (py.StringDict, error) {
+	if globals == py.None {
+		return nil, nil
+	}
+	d, err := py.DictAsNamespace(globals)
+	if err != nil {
+		return nil, py.ExceptionNewf(py.TypeError, "globals must be a dict, not %s", globals.Type().Name)
+	}
+	return d, nil
+}
+
+// Makes a Timer from the arguments the Timer constructor and the
+// module functions have in common
+func newTimer(stmt, setup, timer, globals py.Object) (*Timer, error) {
+	t, err := timerArg(timer)
+	if err != nil {
+		return nil, err
+	}
+	g, err := globalsArg(globals)
+	if err != nil {
+		return nil, err
+	}
+	return NewTimer(stmt, setup, t, g)
+}
+
+// TimerNew makes a Timer from Python
+func TimerNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stmt, setup, timer, globals py.Object = py.String("pass"), py.String("pass"), py.None, py.None
+	kwlist := []string{"stmt", "setup", "timer", "globals"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOO:Timer", kwlist, &stmt, &setup, &timer, &globals)
+	if err != nil {
+		return nil, err
+	}
+	return newTimer(stmt, setup, timer, globals)
+}
+
+// Timeit runs the statement number times returning the time taken
+func (t *Timer) Timeit(number int) (py.Object, error) {
+	it, err := py.RangeNew(py.RangeType, py.Tuple{py.Int(number)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(t.inner, py.Tuple{it, t.timer}, nil)
+}
+
+// Repeat calls Timeit repeat times returning a list of the times
+func (t *Timer) Repeat(repeat, number int) (py.Object, error) {
+	times := py.NewList()
+	for i := 0; i < repeat; i++ {
+		taken, err := t.Timeit(number)
+		if err != nil {
+			return nil, err
+		}
+		times.Append(taken)
+	}
+	return times, nil
+}
+
+// Autorange calls Timeit with 1, 2, 5, 10, 20, 50, ... until it takes
+// at least 0.2 seconds, calling callback after each if it isn't None
+func (t *Timer) Autorange(callback py.Object) (py.Object, error) {
+	for i := 1; ; i *= 10 {
+		for _, j := range []int{1, 2, 5} {
+			number := i * j
+			taken, err := t.Timeit(number)
+			if err != nil {
+				return nil, err
+			}
+			if callback != py.None {
+				_, err = py.Call(callback, py.Tuple{py.Int(number), taken}, nil)
+				if err != nil {
+					return nil, err
+				}
+			}
+			seconds, err := py.FloatAsFloat64(taken)
+			if err != nil {
+				return nil, err
+			}
+			if seconds >= 0.2 {
+				return py.Tuple{py.Int(number), taken}, nil
+			}
+		}
+	}
+}
+
+// Runs Repeat with the repeat and number arguments
+func repeat(t *Timer, repeat, number py.Object) (py.Object, error) {
+	r, err := py.IndexInt(repeat)
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.IndexInt(number)
+	if err != nil {
+		return nil, err
+	}
+	return t.Repeat(r, n)
+}
+
+func init() {
+	TimerType.Dict["timeit"] = py.MustNewMethod("timeit", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var number py.Object = py.Int(defaultNumber)
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:timeit", []string{"number"}, &number)
+		if err != nil {
+			return nil, err
+		}
+		n, err := py.IndexInt(number)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Timer).Timeit(n)
+	}, 0, `timeit(number=1000000) -> float
+
+Time 'number' executions of the main statement.
+
+To be precise, this executes the setup statement once, and
+then returns the time it takes to execute the main statement
+a number of times, as a float measured in seconds.  The
+argument is the number of times through the loop, defaulting
+to one million.  The main statement, the setup statement and
+the timer function to be used are passed to the constructor.`)
+	TimerType.Dict["repeat"] = py.MustNewMethod("repeat", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var r, number py.Object = py.Int(defaultRepeat), py.Int(defaultNumber)
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:repeat", []string{"repeat", "number"}, &r, &number)
+		if err != nil {
+			return nil, err
+		}
+		return repeat(self.(*Timer), r, number)
+	}, 0, `repeat(repeat=5, number=1000000) -> list
+
+Call timeit() a few times.
+
+This is a convenience function that calls the timeit()
+repeatedly, returning a list of results.  The first argument
+specifies how many times to call timeit(), defaulting to 5;
+the second argument specifies the timer argument, defaulting
+to one million.`)
+	TimerType.Dict["autorange"] = py.MustNewMethod("autorange", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var callback py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:autorange", []string{"callback"}, &callback)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Timer).Autorange(callback)
+	}, 0, `autorange(callback=None) -> (number, time_taken)
+
+Return the number of loops and time taken so that total time >= 0.2.
+
+Calls the timeit method with increasing numbers from the sequence
+1, 2, 5, 10, 20, 50, ... until the time taken is at least 0.2
+second.
+
+If callback is given and is not None, it will be called after
+each trial with two arguments: callback(number, time_taken).`)
+	TimerType.Dict["src"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*Timer).src), nil
+		},
+	}
+}
+
+const timeit_func_doc = `timeit(stmt='pass', setup='pass', timer=default_timer, number=1000000, globals=None) -> float
+
+Convenience function to create Timer object and call timeit method.`
+
+func timeit_timeit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stmt, setup, timer, number, globals py.Object = py.String("pass"), py.String("pass"), py.None, py.Int(defaultNumber), py.None
+	kwlist := []string{"stmt", "setup", "timer", "number", "globals"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOO:timeit", kwlist, &stmt, &setup, &timer, &number, &globals)
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.IndexInt(number)
+	if err != nil {
+		return nil, err
+	}
+	t, err := newTimer(stmt, setup, timer, globals)
+	if err != nil {
+		return nil, err
+	}
+	return t.Timeit(n)
+}
+
+const repeat_doc = `repeat(stmt='pass', setup='pass', timer=default_timer, repeat=5, number=1000000, globals=None) -> list
+
+Convenience function to create Timer object and call repeat method.`
+
+func timeit_repeat(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stmt, setup, timer, r, number, globals py.Object = py.String("pass"), py.String("pass"), py.None, py.Int(defaultRepeat), py.Int(defaultNumber), py.None
+	kwlist := []string{"stmt", "setup", "timer", "repeat", "number", "globals"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOO:repeat", kwlist, &stmt, &setup, &timer, &r, &number, &globals)
+	if err != nil {
+		return nil, err
+	}
+	t, err := newTimer(stmt, setup, timer, globals)
+	if err != nil {
+		return nil, err
+	}
+	return repeat(t, r, number)
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "timeit",
+		Doc:  timeit_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("timeit", timeit_timeit, 0, timeit_func_doc),
+			py.MustNewMethod("repeat", timeit_repeat, 0, repeat_doc),
+		},
+		Globals: py.StringDict{
+			"Timer":          TimerType,
+			"default_number": py.Int(defaultNumber),
+			"default_repeat": py.Int(defaultRepeat),
+		},
+		Init: func(ctx *py.Context, m *py.Module) error {
+			time, err := ctx.GetModule("time")
+			if err != nil {
+				return err
+			}
+			m.Globals["default_timer"] = time.Globals["perf_counter"]
+			return nil
+		},
+	})
+}
@@ -0,0 +1,120 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import types
+from libtest import *
+
+doc="FunctionType"
+def f():
+    pass
+assert type(f) is types.FunctionType
+assert type(lambda: None) is types.LambdaType
+assert types.LambdaType is types.FunctionType
+
+doc="MethodType"
+class A:
+    def m(self):
+        pass
+assert type(A().m) is types.MethodType
+assert types.MethodType.__name__ == "method"
+assert repr(types.MethodType) == "<class 'method'>"
+
+doc="BuiltinFunctionType"
+assert type(len) is types.BuiltinFunctionType
+assert types.BuiltinFunctionType.__name__ == "builtin_function_or_method"
+
+doc="ModuleType"
+assert type(types) is types.ModuleType
+
+doc="GeneratorType"
+def g():
+    yield 1
+assert type(g()) is types.GeneratorType
+
+doc="CoroutineType"
+async def co():
+    pass
+c = co()
+assert type(c) is types.CoroutineType
+c.close()
+
+doc="CodeType"
+assert type(f.__code__) is types.CodeType
+
+doc="SimpleNamespace"
+ns = types.SimpleNamespace()
+assertEqual(repr(ns), "namespace()")
+ns = types.SimpleNamespace(b=2, a=1)
+assertEqual(ns.a, 1)
+assertEqual(ns.b, 2)
+assertEqual(repr(ns), "namespace(a=1, b=2)")
+assertEqual(str(ns), "namespace(a=1, b=2)")
+ns.c = "three"
+assertEqual(ns.c, "three")
+assertEqual(repr(ns), "namespace(a=1, b=2, c='three')")
+ns.a = 10
+assertEqual(ns.a, 10)
+del ns.a
+assertRaises(AttributeError, lambda: ns.a)
+assertEqual(ns.__dict__, {"b": 2, "c": "three"})
+assertEqual(types.SimpleNamespace(x=1, y=2), types.SimpleNamespace(y=2, x=1))
+assert types.SimpleNamespace(x=1) != types.SimpleNamespace(x=2)
+assert types.SimpleNamespace(x=1) != types.SimpleNamespace(x=1, y=2)
+assert types.SimpleNamespace(x=1) != 1
+assertRaises(TypeError, types.SimpleNamespace, 1)
+assert type(ns) is types.SimpleNamespace
+
+doc="MappingProxyType"
+d = {"a": 1, "b": 2}
+mp = types.MappingProxyType(d)
+assert type(mp) is types.MappingProxyType
+assertEqual(mp["a"], 1)
+assertRaises(KeyError, lambda: mp["z"])
+assertEqual(len(mp), 2)
+assert "a" in mp
+assert "z" not in mp
+assertEqual(sorted(mp), ["a", "b"])
+assertEqual(sorted(mp.keys()), ["a", "b"])
+assertEqual(sorted(mp.values()), [1, 2])
+items = list(mp.items())
+assertEqual(len(items), 2)
+assert ("a", 1) in items
+assert ("b", 2) in items
+assertEqual(mp.get("a"), 1)
+assertEqual(mp.get("z"), None)
+assertEqual(mp.get("z", 3), 3)
+assert mp == {"a": 1, "b": 2}
+assert mp != {"a": 1}
+
+doc="MappingProxyType reflects changes"
+d["c"] = 3
+assertEqual(len(mp), 3)
+assertEqual(mp["c"], 3)
+c = mp.copy()
+assertEqual(c, {"a": 1, "b": 2, "c": 3})
+c["d"] = 4
+assert "d" not in mp
+
+doc="MappingProxyType is read only"
+def setitem():
+    mp["a"] = 10
+assertRaises(TypeError, setitem)
+def delitem():
+    del mp["a"]
+assertRaises(TypeError, delitem)
+assertEqual(mp["a"], 1)
+assertRaises(AttributeError, lambda: mp.pop)
+assertRaises(AttributeError, lambda: mp.update)
+
+doc="MappingProxyType repr"
+assertEqual(repr(types.MappingProxyType({})), "mappingproxy({})")
+assertEqual(repr(types.MappingProxyType({"a": 1})), "mappingproxy({'a': 1})")
+
+doc="MappingProxyType needs a mapping"
+assertRaises(TypeError, types.MappingProxyType, [1, 2])
+assertRaises(TypeError, types.MappingProxyType, (1, 2))
+assertRaises(TypeError, types.MappingProxyType, 1)
+assertRaises(TypeError, types.MappingProxyType)
+
+doc="finished"
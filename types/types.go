@@ -0,0 +1,122 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Types module
+//
+// Define names for built-in types that aren't directly accessible as
+// a builtin.
+
+package types
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+// SimpleNamespaceType is the type of SimpleNamespace objects
+var SimpleNamespaceType = py.NewTypeX("types.SimpleNamespace", `A simple attribute-based namespace.
+
+SimpleNamespace(**kwargs)`, SimpleNamespaceNew, nil)
+
+// SimpleNamespace is a mutable bag of attributes
+type SimpleNamespace struct {
+	Dict py.StringDict
+}
+
+// Type of this SimpleNamespace object
+func (ns *SimpleNamespace) Type() *py.Type {
+	return SimpleNamespaceType
+}
+
+// GetDict returns the attributes of the namespace
+func (ns *SimpleNamespace) GetDict() py.StringDict {
+	return ns.Dict
+}
+
+// SimpleNamespaceNew creates a SimpleNamespace from its keyword
+// arguments
+func SimpleNamespaceNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) != 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "no positional arguments expected")
+	}
+	return &SimpleNamespace{Dict: kwargs.Copy()}, nil
+}
+
+func (ns *SimpleNamespace) M__repr__() (py.Object, error) {
+	keys := make([]string, 0, len(ns.Dict))
+	for key := range ns.Dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var out bytes.Buffer
+	out.WriteString("namespace(")
+	for i, key := range keys {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		valueStr, err := py.ReprAsString(ns.Dict[key])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(key)
+		out.WriteRune('=')
+		out.WriteString(valueStr)
+	}
+	out.WriteRune(')')
+	return py.String(out.String()), nil
+}
+
+func (ns *SimpleNamespace) M__str__() (py.Object, error) {
+	return ns.M__repr__()
+}
+
+func (ns *SimpleNamespace) M__eq__(other py.Object) (py.Object, error) {
+	b, ok := other.(*SimpleNamespace)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return ns.Dict.M__eq__(b.Dict)
+}
+
+func (ns *SimpleNamespace) M__ne__(other py.Object) (py.Object, error) {
+	b, ok := other.(*SimpleNamespace)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return ns.Dict.M__ne__(b.Dict)
+}
+
+// Check interface is satisfied
+var _ py.IGetDict = (*SimpleNamespace)(nil)
+var _ py.I__repr__ = (*SimpleNamespace)(nil)
+var _ py.I__eq__ = (*SimpleNamespace)(nil)
+var _ py.I__ne__ = (*SimpleNamespace)(nil)
+
+const types_doc = `Define names for built-in types that aren't directly accessible as a builtin.`
+
+// Initialise the module
+func init() {
+	globals := py.StringDict{
+		"BuiltinFunctionType": py.MethodType,
+		"BuiltinMethodType":   py.MethodType,
+		"CodeType":            py.CodeType,
+		"CoroutineType":       py.CoroutineType,
+		"FrameType":           py.FrameType,
+		"FunctionType":        py.FunctionType,
+		"GeneratorType":       py.GeneratorType,
+		"LambdaType":          py.FunctionType,
+		"MappingProxyType":    py.MappingProxyType,
+		"MethodType":          py.BoundMethodType,
+		"ModuleType":          py.ModuleType,
+		"SimpleNamespace":     SimpleNamespaceType,
+		"TracebackType":       py.TracebackType,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "types",
+		Doc:     types_doc,
+		Globals: globals,
+	})
+}
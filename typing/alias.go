@@ -0,0 +1,498 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Special forms and generic aliases
+
+package typing
+
+import (
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+var SpecialFormType = py.NewType("typing._SpecialForm", "")
+
+// SpecialForm is one of the special typing constructs such as Any
+// and Union which aren't classes
+type SpecialForm struct {
+	name    string
+	doc     string
+	getitem func(params py.Object) (py.Object, error) // nil if it can't be subscripted
+}
+
+// Type of this object
+func (f *SpecialForm) Type() *py.Type {
+	return SpecialFormType
+}
+
+func (f *SpecialForm) M__repr__() (py.Object, error) {
+	return py.String("typing." + f.name), nil
+}
+
+func (f *SpecialForm) M__getitem__(params py.Object) (py.Object, error) {
+	if f.getitem == nil {
+		return nil, py.ExceptionNewf(py.TypeError, "typing.%s is not subscriptable", f.name)
+	}
+	return f.getitem(params)
+}
+
+func (f *SpecialForm) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return nil, py.ExceptionNewf(py.TypeError, "Cannot instantiate typing.%s", f.name)
+}
+
+var AliasType = py.NewType("typing._GenericAlias", "The central part of internal API.")
+
+// Alias is a generic alias such as List or List[int], or a special
+// form subscripted such as Union[int, str]
+type Alias struct {
+	origin  py.Object // the class, special form or nil if there is no class
+	name    string    // the name in typing or "" for user generic classes
+	args    py.Tuple  // nil for an alias which hasn't been subscripted
+	params  py.Tuple  // the type variables in args
+	nparams int       // the number of args an alias takes, -1 for any
+}
+
+// Type of this object
+func (a *Alias) Type() *py.Type {
+	return AliasType
+}
+
+// Makes the alias origin[args]
+func newAlias(origin py.Object, name string, args py.Tuple) (*Alias, error) {
+	params, err := py.GenericParameters(args)
+	if err != nil {
+		return nil, err
+	}
+	return &Alias{origin: origin, name: name, args: args, params: params}, nil
+}
+
+// Makes the alias name for the class origin which isn't subscripted
+func newSpecialAlias(origin py.Object, name string, nparams int) *Alias {
+	return &Alias{origin: origin, name: name, nparams: nparams}
+}
+
+// Returns params as a tuple
+func paramsTuple(params py.Object) py.Tuple {
+	if t, ok := params.(py.Tuple); ok {
+		return t
+	}
+	return py.Tuple{params}
+}
+
+// Returns the __origin__ of a
+func (a *Alias) getOrigin() py.Object {
+	if a.origin == nil {
+		return py.None
+	}
+	return a.origin
+}
+
+// Returns true if a is a subscripted Callable taking any arguments
+func (a *Alias) isCallableEllipsis() bool {
+	return len(a.args) == 2 && a.args[0] == py.Ellipsis
+}
+
+func (a *Alias) M__repr__() (py.Object, error) {
+	if a.args == nil {
+		return py.String("typing." + a.name), nil
+	}
+	if a.origin == Union && len(a.args) == 2 {
+		for i, arg := range a.args {
+			if arg == py.NoneTypeType {
+				other, err := py.TypeRepr(a.args[1-i])
+				if err != nil {
+					return nil, err
+				}
+				return py.String("typing.Optional[" + other + "]"), nil
+			}
+		}
+	}
+	var name string
+	if a.name != "" {
+		name = "typing." + a.name
+	} else {
+		var err error
+		name, err = py.TypeRepr(a.origin)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(a.args) == 0 {
+		return py.String(name + "[()]"), nil
+	}
+	if a.name == "Callable" {
+		ret, err := py.TypeRepr(a.args[len(a.args)-1])
+		if err != nil {
+			return nil, err
+		}
+		if a.isCallableEllipsis() {
+			return py.String(name + "[..., " + ret + "]"), nil
+		}
+		args, err := py.TypeReprList(a.args[:len(a.args)-1])
+		if err != nil {
+			return nil, err
+		}
+		return py.String(name + "[[" + args + "], " + ret + "]"), nil
+	}
+	args, err := py.TypeReprList(a.args)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(name + "[" + args + "]"), nil
+}
+
+// Subscripts an alias which hasn't been subscripted yet
+func (a *Alias) subscript(key py.Object) (py.Object, error) {
+	switch a.name {
+	case "Tuple":
+		params := paramsTuple(key)
+		if len(params) == 2 && params[1] == py.Ellipsis {
+			return newAlias(a.origin, a.name, py.Tuple{params[0], py.Ellipsis})
+		}
+		for _, param := range params {
+			if param == py.Ellipsis {
+				return nil, py.ExceptionNewf(py.TypeError, "Tuple[t, ...]: t must be a type.")
+			}
+		}
+		return newAlias(a.origin, a.name, params.Copy())
+	case "Callable":
+		params, ok := key.(py.Tuple)
+		if !ok || len(params) != 2 {
+			return nil, py.ExceptionNewf(py.TypeError, "Callable must be used as Callable[[arg, ...], result].")
+		}
+		args, result := params[0], params[1]
+		if args == py.Ellipsis {
+			return newAlias(a.origin, a.name, py.Tuple{py.Ellipsis, result})
+		}
+		list, ok := args.(*py.List)
+		if !ok {
+			repr, err := py.ReprAsString(args)
+			if err != nil {
+				return nil, err
+			}
+			return nil, py.ExceptionNewf(py.TypeError, "Callable[args, result]: args must be a list. Got %s", repr)
+		}
+		return newAlias(a.origin, a.name, append(py.Tuple(list.Items).Copy(), result))
+	}
+	params := paramsTuple(key)
+	if a.nparams == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "typing.%s is not a generic class", a.name)
+	}
+	if a.nparams > 0 && len(params) != a.nparams {
+		many := "many"
+		if len(params) < a.nparams {
+			many = "few"
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "Too %s parameters for typing.%s; actual %d, expected %d", many, a.name, len(params), a.nparams)
+	}
+	return newAlias(a.origin, a.name, params.Copy())
+}
+
+func (a *Alias) M__getitem__(key py.Object) (py.Object, error) {
+	if a.args == nil {
+		return a.subscript(key)
+	}
+	args, err := py.GenericSubstitute(a, a.args, a.params, key)
+	if err != nil {
+		return nil, err
+	}
+	if a.origin == Union {
+		return makeUnion(args)
+	}
+	return newAlias(a.origin, a.name, args)
+}
+
+// Calling the alias makes an instance of its origin
+func (a *Alias) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	switch origin := a.origin.(type) {
+	case nil:
+		return nil, py.ExceptionNewf(py.TypeError, "Type %s cannot be instantiated", a.name)
+	case *SpecialForm:
+		return nil, py.ExceptionNewf(py.TypeError, "Cannot instantiate typing.%s", origin.name)
+	case *py.Type:
+		if a.args == nil {
+			return nil, py.ExceptionNewf(py.TypeError, "Type %s cannot be instantiated; use %s() instead", a.name, origin.Name)
+		}
+	}
+	res, err := py.Call(a.origin, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.SetAttrString(res, "__orig_class__", a)
+	if err != nil && !py.IsException(py.AttributeError, err) && !py.IsException(py.TypeError, err) {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Other attributes are those of the origin
+func (a *Alias) M__getattr__(name string) (py.Object, error) {
+	if origin, ok := a.origin.(*py.Type); ok && !(strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__")) {
+		return py.GetAttrString(origin, name)
+	}
+	return nil, py.ExceptionNewf(py.AttributeError, "'typing._GenericAlias' object has no attribute '%s'", name)
+}
+
+// Returns true if every item of a is equal to an item of b
+func containsAll(a, b py.Tuple) (bool, error) {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			eq, err := py.Eq(x, y)
+			if err != nil {
+				return false, err
+			}
+			if eq == py.True {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (a *Alias) M__eq__(other py.Object) (py.Object, error) {
+	b, ok := other.(*Alias)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if a.origin != b.origin || a.name != b.name || (a.args == nil) != (b.args == nil) {
+		return py.False, nil
+	}
+	if a.origin == Union {
+		// The order of the types in a Union doesn't matter
+		ok, err := containsAll(a.args, b.args)
+		if err != nil || !ok {
+			return py.False, err
+		}
+		ok, err = containsAll(b.args, a.args)
+		return py.NewBool(ok), err
+	}
+	return a.args.M__eq__(b.args)
+}
+
+func (a *Alias) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := a.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.Not(eq)
+}
+
+func (a *Alias) M__hash__() (py.Object, error) {
+	var hash int64
+	if a.origin != nil {
+		var err error
+		hash, err = py.Hash(a.origin)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Combine the hashes of the args so their order doesn't
+	// matter, as it doesn't for a Union
+	for _, arg := range a.args {
+		h, err := py.Hash(arg)
+		if err != nil {
+			return nil, err
+		}
+		hash ^= h
+	}
+	return py.Int(hash), nil
+}
+
+// Returns the classes an alias in the bases of a class stands for
+func aliasMroEntries(self, basesObj py.Object) (py.Object, error) {
+	a := self.(*Alias)
+	bases, _ := basesObj.(py.Tuple)
+	// Whether a generic alias or class comes after a in the bases
+	laterGeneric := false
+	seen := false
+	for _, base := range bases {
+		if base == self {
+			seen = true
+			continue
+		}
+		if !seen {
+			continue
+		}
+		if _, ok := base.(*Alias); ok {
+			laterGeneric = true
+		} else if t, ok := base.(*py.Type); ok && t.IsSubtype(Generic) {
+			laterGeneric = true
+		}
+	}
+	switch origin := a.origin.(type) {
+	case nil:
+		if laterGeneric {
+			return py.Tuple{}, nil
+		}
+		return py.Tuple{Generic}, nil
+	case *SpecialForm:
+		repr, err := py.ReprAsString(a)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "Cannot subclass %s", repr)
+	case *py.Type:
+		if origin == Generic || origin == Protocol {
+			if laterGeneric {
+				return py.Tuple{}, nil
+			}
+			return py.Tuple{origin}, nil
+		}
+		if len(a.params) > 0 && !laterGeneric && !origin.IsSubtype(Generic) {
+			return py.Tuple{origin, Generic}, nil
+		}
+	}
+	return py.Tuple{a.origin}, nil
+}
+
+// Properties
+func init() {
+	AliasType.Dict["__origin__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Alias).getOrigin(), nil
+		},
+	}
+	AliasType.Dict["__args__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			if args := self.(*Alias).args; args != nil {
+				return args, nil
+			}
+			return py.Tuple{}, nil
+		},
+	}
+	AliasType.Dict["__parameters__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			if params := self.(*Alias).params; params != nil {
+				return params, nil
+			}
+			return py.Tuple{}, nil
+		},
+	}
+	AliasType.Dict["_name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			if name := self.(*Alias).name; name != "" {
+				return py.String(name), nil
+			}
+			return py.None, nil
+		},
+	}
+	AliasType.Dict["__mro_entries__"] = py.MustNewMethod("__mro_entries__", aliasMroEntries, 0, "")
+}
+
+// Makes Union[args], flattening Unions in args and removing
+// duplicates
+func makeUnion(args py.Tuple) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "Cannot take a Union of no types.")
+	}
+	var flat py.Tuple
+	for _, arg := range args {
+		if arg == py.None {
+			arg = py.NoneTypeType
+		}
+		if a, ok := arg.(*Alias); ok && a.origin == Union && a.args != nil {
+			flat = append(flat, a.args...)
+		} else {
+			flat = append(flat, arg)
+		}
+	}
+	var unique py.Tuple
+	for _, arg := range flat {
+		found, err := containsAll(py.Tuple{arg}, unique)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			unique = append(unique, arg)
+		}
+	}
+	if len(unique) == 1 {
+		return unique[0], nil
+	}
+	return newAlias(Union, "Union", unique)
+}
+
+// Makes f[param] for a special form which takes one type
+func singleParam(f *SpecialForm, params py.Object) (py.Object, error) {
+	if _, ok := params.(py.Tuple); ok {
+		return nil, py.ExceptionNewf(py.TypeError, "typing.%s accepts only single type.", f.name)
+	}
+	return newAlias(f, f.name, py.Tuple{params})
+}
+
+// The special forms
+var (
+	Any = &SpecialForm{
+		name: "Any",
+		doc:  "Special type indicating an unconstrained type.",
+	}
+	NoReturn = &SpecialForm{
+		name: "NoReturn",
+		doc:  "Special type indicating functions that never return.",
+	}
+	ClassVar = &SpecialForm{
+		name: "ClassVar",
+		doc:  "Special type construct to mark class variables.",
+	}
+	Final = &SpecialForm{
+		name: "Final",
+		doc:  "Special typing construct to indicate final names to type checkers.",
+	}
+	Union = &SpecialForm{
+		name: "Union",
+		doc: `Union type; Union[X, Y] means either X or Y.
+
+Unions of unions are flattened, duplicates are removed and a union
+of a single type is that type.`,
+	}
+	Optional = &SpecialForm{
+		name: "Optional",
+		doc:  "Optional type; Optional[X] is equivalent to Union[X, None].",
+	}
+	Literal = &SpecialForm{
+		name: "Literal",
+		doc:  "Special typing form to define literal types (a.k.a. value types).",
+	}
+)
+
+// The special forms refer to themselves when subscripted so their
+// getitem is set here
+func init() {
+	ClassVar.getitem = func(params py.Object) (py.Object, error) {
+		return singleParam(ClassVar, params)
+	}
+	Final.getitem = func(params py.Object) (py.Object, error) {
+		return singleParam(Final, params)
+	}
+	Union.getitem = func(params py.Object) (py.Object, error) {
+		return makeUnion(paramsTuple(params))
+	}
+	Optional.getitem = func(params py.Object) (py.Object, error) {
+		if _, ok := params.(py.Tuple); ok {
+			return nil, py.ExceptionNewf(py.TypeError, "Optional[t] requires a single type.")
+		}
+		return makeUnion(py.Tuple{params, py.None})
+	}
+	Literal.getitem = func(params py.Object) (py.Object, error) {
+		return newAlias(Literal, "Literal", paramsTuple(params).Copy())
+	}
+}
+
+// Check interface is satisfied
+var _ py.I__repr__ = (*SpecialForm)(nil)
+var _ py.I__getitem__ = (*SpecialForm)(nil)
+var _ py.I__call__ = (*SpecialForm)(nil)
+var _ py.I__repr__ = (*Alias)(nil)
+var _ py.I__getitem__ = (*Alias)(nil)
+var _ py.I__call__ = (*Alias)(nil)
+var _ py.I__getattr__ = (*Alias)(nil)
+var _ py.I__eq__ = (*Alias)(nil)
+var _ py.I__ne__ = (*Alias)(nil)
+var _ py.I__hash__ = (*Alias)(nil)
@@ -0,0 +1,334 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Generic and Protocol
+
+package typing
+
+import (
+	"strings"
+
+	"github.com/go-python/gpython/abc"
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	// Generic is the base class of generic classes
+	Generic *py.Type
+
+	// Protocol is the base class of protocol classes
+	Protocol *py.Type
+)
+
+// Returns the names of the items of objs joined with commas
+func joinReprs(objs py.Tuple) (string, error) {
+	reprs := make([]string, len(objs))
+	for i, obj := range objs {
+		var err error
+		reprs[i], err = py.ReprAsString(obj)
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(reprs, ", "), nil
+}
+
+// Returns true if obj is one of objs
+func contains(objs py.Tuple, obj py.Object) bool {
+	for _, o := range objs {
+		if o == obj {
+			return true
+		}
+	}
+	return false
+}
+
+func generic_class_getitem(self, params py.Object) (py.Object, error) {
+	cls := self.(*py.Type)
+	args := paramsTuple(params)
+	if cls == Generic || cls == Protocol {
+		if len(args) == 0 {
+			return nil, py.ExceptionNewf(py.TypeError, "Parameter list to %s[...] cannot be empty", cls.Name)
+		}
+		for i, arg := range args {
+			if _, ok := arg.(*TypeVar); !ok {
+				return nil, py.ExceptionNewf(py.TypeError, "Parameters to %s[...] must all be type variables", cls.Name)
+			}
+			if contains(args[:i], arg) {
+				return nil, py.ExceptionNewf(py.TypeError, "Parameters to %s[...] must all be unique", cls.Name)
+			}
+		}
+		return newAlias(cls, cls.Name, args.Copy())
+	}
+	repr, err := py.ReprAsString(cls)
+	if err != nil {
+		return nil, err
+	}
+	clsParams, _ := cls.Lookup("__parameters__").(py.Tuple)
+	if len(clsParams) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "%s is not a generic class", repr)
+	}
+	if len(args) != len(clsParams) {
+		many := "many"
+		if len(args) < len(clsParams) {
+			many = "few"
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "Too %s parameters for %s; actual %d, expected %d", many, repr, len(args), len(clsParams))
+	}
+	return newAlias(cls, "", args.Copy())
+}
+
+// Sets the __parameters__ of a generic class to the type variables
+// in its bases
+func setParameters(cls *py.Type) error {
+	tvars := py.Tuple{}
+	if origBases, ok := cls.Dict["__orig_bases__"].(py.Tuple); ok {
+		var err error
+		tvars, err = py.GenericParameters(origBases)
+		if err != nil {
+			return err
+		}
+		var gvars py.Tuple
+		for _, base := range origBases {
+			a, ok := base.(*Alias)
+			if !ok || (a.origin != Generic && a.origin != Protocol) {
+				continue
+			}
+			if gvars != nil {
+				return py.ExceptionNewf(py.TypeError, "Cannot inherit from Generic[...] multiple types.")
+			}
+			gvars = a.args
+		}
+		if gvars != nil {
+			var missing py.Tuple
+			for _, tvar := range tvars {
+				if !contains(gvars, tvar) {
+					missing = append(missing, tvar)
+				}
+			}
+			if len(missing) > 0 {
+				missingRepr, err := joinReprs(missing)
+				if err != nil {
+					return err
+				}
+				gvarsRepr, err := joinReprs(gvars)
+				if err != nil {
+					return err
+				}
+				return py.ExceptionNewf(py.TypeError, "Some type variables (%s) are not listed in Generic[%s]", missingRepr, gvarsRepr)
+			}
+			tvars = gvars
+		}
+	}
+	cls.Dict["__parameters__"] = tvars
+	cls.Modified()
+	return nil
+}
+
+// Calls object.__init_subclass__ for cls
+func objectInitSubclass(cls *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	init := py.ObjectType.Dict["__init_subclass__"].(*py.ClassMethod)
+	return py.Call(py.NewBoundMethod(cls, init.Callable), args, kwargs)
+}
+
+func generic_init_subclass(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	cls := self.(*py.Type)
+	err := setParameters(cls)
+	if err != nil {
+		return nil, err
+	}
+	return objectInitSubclass(cls, args, kwargs)
+}
+
+const generic_doc = `Abstract base class for generic types.
+
+A generic type is typically declared by inheriting from
+this class parameterized with one or more type variables.
+For example, a generic mapping type might be defined as::
+
+  class Mapping(Generic[KT, VT]):
+      def __getitem__(self, key: KT) -> VT:
+          ...
+      # Etc.`
+
+func newGeneric() *py.Type {
+	dict := py.StringDict{
+		"__class_getitem__": &py.ClassMethod{
+			Callable: py.MustNewMethod("__class_getitem__", generic_class_getitem, 0, ""),
+			Dict:     py.NewStringDict(),
+		},
+		"__init_subclass__": &py.ClassMethod{
+			Callable: py.MustNewMethod("__init_subclass__", generic_init_subclass, 0, ""),
+			Dict:     py.NewStringDict(),
+		},
+		"__parameters__": py.Tuple{},
+	}
+	return py.NewClass(py.TypeType, "typing", "Generic", generic_doc, py.Tuple{py.ObjectType}, dict)
+}
+
+// Returns true if cls is a protocol class, one with Protocol in its
+// bases
+func isProtocol(cls *py.Type) bool {
+	return cls.Dict["_is_protocol"] == py.True
+}
+
+// Names in protocol classes which aren't part of the protocol
+var protocolExcluded = map[string]struct{}{
+	"__abstractmethods__":  {},
+	"__annotations__":      {},
+	"__weakref__":          {},
+	"_is_protocol":         {},
+	"_is_runtime_protocol": {},
+	"__dict__":             {},
+	"__args__":             {},
+	"__slots__":            {},
+	"__parameters__":       {},
+	"__origin__":           {},
+	"__orig_bases__":       {},
+	"__doc__":              {},
+	"__subclasshook__":     {},
+	"__init__":             {},
+	"__new__":              {},
+	"__module__":           {},
+	"__qualname__":         {},
+	"__init_subclass__":    {},
+	"__class_getitem__":    {},
+}
+
+// Returns the names which make up the protocol cls
+func protocolAttrs(cls *py.Type) []string {
+	var attrs []string
+	for _, baseObj := range cls.Mro {
+		base, ok := baseObj.(*py.Type)
+		if !ok || base == Protocol || base == Generic || !isProtocol(base) {
+			continue
+		}
+		names := make([]string, 0, len(base.Dict))
+		for name := range base.Dict {
+			names = append(names, name)
+		}
+		if annotations, ok := base.Dict["__annotations__"]; ok {
+			if keys, err := py.SequenceList(annotations); err == nil {
+				for _, key := range keys.Items {
+					if name, ok := key.(py.String); ok {
+						names = append(names, string(name))
+					}
+				}
+			}
+		}
+		for _, name := range names {
+			if _, ok := protocolExcluded[name]; ok || strings.HasPrefix(name, "_abc_") {
+				continue
+			}
+			found := false
+			for _, attr := range attrs {
+				if attr == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				attrs = append(attrs, name)
+			}
+		}
+	}
+	return attrs
+}
+
+func protocol_init_subclass(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	cls := self.(*py.Type)
+	// Only classes which list Protocol in their bases are protocols
+	cls.Dict["_is_protocol"] = py.False
+	for _, base := range cls.Bases {
+		if base == Protocol {
+			cls.Dict["_is_protocol"] = py.True
+		}
+	}
+	return generic_init_subclass(cls, args, kwargs)
+}
+
+func protocol_subclasshook(self, other py.Object) (py.Object, error) {
+	cls := self.(*py.Type)
+	if !isProtocol(cls) {
+		return py.NotImplemented, nil
+	}
+	if cls.Lookup("_is_runtime_protocol") != py.True {
+		return nil, py.ExceptionNewf(py.TypeError, "Instance and class checks can only be used with @runtime_checkable protocols")
+	}
+	t, ok := other.(*py.Type)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "issubclass() arg 1 must be a class")
+	}
+	for _, attr := range protocolAttrs(cls) {
+		found := false
+		for _, base := range t.Mro {
+			if value, ok := base.(*py.Type).Dict[attr]; ok {
+				if value == py.None {
+					return py.NotImplemented, nil
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return py.NotImplemented, nil
+		}
+	}
+	return py.True, nil
+}
+
+const protocol_doc = `Base class for protocol classes.
+
+Protocol classes are defined as::
+
+    class Proto(Protocol):
+        def meth(self) -> int:
+            ...
+
+Such classes are primarily used with static type checkers that
+recognize structural subtyping (static duck-typing).  Protocol
+classes decorated with @typing.runtime_checkable act as simple-minded
+runtime protocols that check only the presence of given attributes,
+ignoring their type signatures.`
+
+func newProtocol() *py.Type {
+	dict := py.StringDict{
+		"_is_protocol": py.True,
+		"__init_subclass__": &py.ClassMethod{
+			Callable: py.MustNewMethod("__init_subclass__", protocol_init_subclass, 0, ""),
+			Dict:     py.NewStringDict(),
+		},
+		"__subclasshook__": &py.ClassMethod{
+			Callable: py.MustNewMethod("__subclasshook__", protocol_subclasshook, 0, ""),
+			Dict:     py.NewStringDict(),
+		},
+	}
+	cls := py.NewClass(abc.ABCMeta, "typing", "Protocol", protocol_doc, py.Tuple{Generic}, dict)
+	err := abc.InitABC(cls)
+	if err != nil {
+		panic(err)
+	}
+	return cls
+}
+
+const runtime_checkable_doc = `Mark a protocol class as a runtime protocol.
+
+Such protocol can be used with isinstance() and issubclass().
+Raise TypeError if applied to a non-protocol class.
+This allows a simple-minded structural check very similar to
+one trick ponies in collections.abc such as Iterable.`
+
+func typing_runtime_checkable(self, arg py.Object) (py.Object, error) {
+	cls, ok := arg.(*py.Type)
+	if !ok || !isProtocol(cls) {
+		repr, err := py.ReprAsString(arg)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "@runtime_checkable can be only applied to protocol classes, got %s", repr)
+	}
+	cls.Dict["_is_runtime_protocol"] = py.True
+	cls.Modified()
+	return cls, nil
+}
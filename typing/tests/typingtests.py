@@ -0,0 +1,231 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import typing
+from typing import (Any, List, Dict, Set, FrozenSet, Tuple, Type, Callable,
+                    Optional, Union, Literal, ClassVar, Final, TypeVar,
+                    Generic, Protocol, Iterable, Mapping, Sequence,
+                    runtime_checkable, get_origin, get_args, get_type_hints,
+                    cast, overload, NewType)
+from libtest import *
+
+doc = "reprs"
+assert repr(Any) == "typing.Any"
+assert repr(List) == "typing.List"
+assert repr(List[int]) == "typing.List[int]"
+assert repr(Dict[str, List[int]]) == "typing.Dict[str, typing.List[int]]"
+assert repr(Tuple[int, ...]) == "typing.Tuple[int, ...]"
+assert repr(Tuple[()]) == "typing.Tuple[()]"
+assert repr(Callable[[int, str], bool]) == "typing.Callable[[int, str], bool]"
+assert repr(Callable[..., int]) == "typing.Callable[..., int]"
+assert repr(Optional[int]) == "typing.Optional[int]"
+assert repr(Union[int, str]) == "typing.Union[int, str]"
+assert repr(Literal["a", 1]) == "typing.Literal['a', 1]"
+assert repr(ClassVar[int]) == "typing.ClassVar[int]"
+assert repr(Iterable[int]) == "typing.Iterable[int]"
+
+doc = "equality"
+assert List[int] == List[int]
+assert List[int] != List[str]
+assert Dict[str, int] == Dict[str, int]
+assert hash(List[int]) == hash(List[int])
+d = {List[int]: 1}
+assert d[List[int]] == 1
+
+doc = "arity"
+assertRaisesText(TypeError, "Too many parameters for typing.List", lambda: List[int, str])
+assertRaisesText(TypeError, "Too few parameters for typing.Dict", lambda: Dict[int])
+assertRaisesText(TypeError, "typing.Any is not subscriptable", lambda: Any[int])
+
+doc = "union"
+assert Union[int] is int
+assert Union[int, str] == Union[str, int]
+assert Union[int, Union[str, float]] == Union[int, str, float]
+assert Union[int, int, str] == Union[int, str]
+assert Optional[int] == Union[int, None]
+assert get_args(Optional[int]) == (int, type(None))
+assertRaisesText(TypeError, "Cannot take a Union of no types.", lambda: Union[()])
+
+doc = "instantiate"
+assertRaisesText(TypeError, "Cannot instantiate typing.Any", Any)
+assertRaisesText(TypeError, "Type List cannot be instantiated; use list() instead", List)
+l = List[int]()
+assert l == []
+assert type(l) is list
+assert Dict[str, int](a=1) == {"a": 1}
+
+doc = "typevar"
+T = TypeVar("T")
+K = TypeVar("K", str, bytes)
+V_co = TypeVar("V_co", covariant=True)
+C_contra = TypeVar("C_contra", contravariant=True)
+B = TypeVar("B", bound=int)
+assert repr(T) == "~T"
+assert repr(V_co) == "+V_co"
+assert repr(C_contra) == "-C_contra"
+assert T.__name__ == "T"
+assert K.__constraints__ == (str, bytes)
+assert B.__bound__ is int
+assert T.__bound__ is None
+assert V_co.__covariant__
+assert not V_co.__contravariant__
+assertRaisesText(TypeError, "A single constraint is not allowed", TypeVar, "X", int)
+assertRaisesText(ValueError, "Bivariant types are not supported.", TypeVar, "X", covariant=True, contravariant=True)
+assert repr(List[T]) == "typing.List[~T]"
+assert List[T].__parameters__ == (T,)
+assert List[T][int] == List[int]
+assert Dict[K, List[T]].__parameters__ == (K, T)
+assert Dict[K, List[T]][str, int] == Dict[str, List[int]]
+assertRaisesText(TypeError, "Too many arguments", lambda: List[T][int, str])
+
+doc = "generic"
+class Stack(Generic[T]):
+    def __init__(self):
+        self.items = []
+    def push(self, item: T) -> None:
+        self.items.append(item)
+    def pop(self) -> T:
+        return self.items.pop()
+assert Stack.__parameters__ == (T,)
+s = Stack[int]()
+s.push(1)
+assert s.pop() == 1
+assert s.__orig_class__ == Stack[int]
+assert isinstance(s, Stack)
+assert Stack.__orig_bases__ == (Generic[T],)
+assert Generic in Stack.__mro__
+assert get_origin(Stack[int]) is Stack
+assert get_args(Stack[int]) == (int,)
+assertRaisesText(TypeError, "Too many parameters", lambda: Stack[int, str])
+assertRaisesText(TypeError, "Parameters to Generic[...] must all be type variables", lambda: Generic[int])
+assertRaisesText(TypeError, "Parameters to Generic[...] must all be unique", lambda: Generic[T, T])
+
+class Pair(Mapping[K, T]):
+    def __init__(self, k, v):
+        self.k, self.v = k, v
+    def __getitem__(self, k):
+        return self.v
+assert Pair.__parameters__ == (K, T)
+assert Generic in Pair.__mro__
+assert Pair[str, int]("a", 1)["a"] == 1
+
+class IntStack(Stack[int]):
+    pass
+assert IntStack.__parameters__ == ()
+assertRaisesText(TypeError, "is not a generic class", lambda: IntStack[int])
+
+def bad():
+    class Bad(Generic[T], List[K]):
+        pass
+assertRaisesText(TypeError, "Some type variables (~K) are not listed in Generic[~T]", bad)
+
+class Abstract(Iterable[T]):
+    pass
+assert Abstract.__parameters__ == (T,)
+
+doc = "protocol"
+@runtime_checkable
+class Closable(Protocol):
+    def close(self):
+        pass
+
+class File:
+    def close(self):
+        pass
+
+class NotFile:
+    pass
+
+assert isinstance(File(), Closable)
+assert not isinstance(NotFile(), Closable)
+assert issubclass(File, Closable)
+
+class Sized(Protocol):
+    def __len__(self):
+        pass
+assertRaisesText(TypeError, "@runtime_checkable protocols", isinstance, [], Sized)
+assertRaisesText(TypeError, "can be only applied to protocol classes", runtime_checkable, File)
+
+class Concrete(Closable):
+    pass
+assert isinstance(Concrete(), Closable)
+
+doc = "get_origin and get_args"
+assert get_origin(List[int]) is list
+assert get_origin(Dict[str, int]) is dict
+assert get_origin(Union[int, str]) is Union
+assert get_origin(Literal[1]) is Literal
+assert get_origin(Generic[T]) is Generic
+assert get_origin(int) is None
+assert get_origin(list[int]) is list
+assert get_args(Dict[str, int]) == (str, int)
+assert get_args(Callable[[int], str]) == ([int], str)
+assert get_args(Callable[..., str]) == (..., str)
+assert get_args(list[int]) == (int,)
+assert get_args(int) == ()
+
+doc = "get_type_hints"
+def f(a: int, b: "List[int]", c: None = None) -> str:
+    return str(a)
+assert f.__annotations__["b"] == "List[int]"
+hints = get_type_hints(f)
+assert hints["a"] is int
+assert hints["b"] == List[int]
+assert hints["c"] is type(None)
+assert hints["return"] is str
+
+class Base:
+    x: int
+class Derived(Base):
+    y: "Optional[str]"
+hints = get_type_hints(Derived)
+assert hints["x"] is int
+assert hints["y"] == Optional[str]
+assert get_type_hints(File) == {}
+
+doc = "helpers"
+assert cast(int, "x") == "x"
+assert cast("int", 1) == 1
+
+@overload
+def g(x: int) -> int: ...
+assertRaises(NotImplementedError, g, 1)
+
+UserId = NewType("UserId", int)
+assert UserId(5) == 5
+assert UserId.__name__ == "UserId"
+assert UserId.__supertype__ is int
+assert not typing.TYPE_CHECKING
+assert typing.Text is str
+assert repr(typing.AnyStr) == "~AnyStr"
+
+@typing.final
+class Leaf:
+    pass
+assert Leaf.__name__ == "Leaf"
+
+@typing.no_type_check
+def untyped(x: "not a type"):
+    return x
+assert untyped.__no_type_check__
+assert untyped(1) == 1
+
+doc = "annotated code runs"
+class Config:
+    name: str = "default"
+    limit: ClassVar[int] = 10
+    tags: List[str]
+    def lookup(self, key: str, default: Optional[int] = None) -> Union[int, None]:
+        return default
+    def items(self) -> Iterable[Tuple[str, int]]:
+        return [("a", 1)]
+c = Config()
+assert c.name == "default"
+assert Config.limit == 10
+assert c.lookup("x", 3) == 3
+assert list(c.items()) == [("a", 1)]
+x: Mapping[str, Sequence[int]] = {"a": [1]}
+assert x["a"] == [1]
+
+doc = "finished"
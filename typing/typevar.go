@@ -0,0 +1,125 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// TypeVar objects
+
+package typing
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const typevar_doc = `Type variable.
+
+Usage::
+
+  T = TypeVar('T')  # Can be anything
+  A = TypeVar('A', str, bytes)  # Must be str or bytes
+
+Type variables exist primarily for the benefit of static type
+checkers.  They serve as the parameters for generic types as well
+as for generic function definitions.`
+
+// The name of TypeVarType is what py uses to recognise type variables
+var TypeVarType = py.NewTypeX("typing.TypeVar", typevar_doc, TypeVarNew, nil)
+
+// TypeVar is a type variable which parameterises generic types
+type TypeVar struct {
+	Name          string
+	Constraints   py.Tuple
+	Bound         py.Object
+	Covariant     bool
+	Contravariant bool
+}
+
+// Type of this object
+func (v *TypeVar) Type() *py.Type {
+	return TypeVarType
+}
+
+// TypeVarNew makes a TypeVar from python
+func TypeVarNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "TypeVar() missing required argument 'name' (pos 1)")
+	}
+	name, err := py.StrAsString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	v := &TypeVar{
+		Name:        name,
+		Constraints: args[1:].Copy(),
+		Bound:       py.None,
+	}
+	for key, value := range kwargs {
+		switch key {
+		case "bound":
+			v.Bound = value
+		case "covariant", "contravariant":
+			b, err := py.MakeBool(value)
+			if err != nil {
+				return nil, err
+			}
+			if key == "covariant" {
+				v.Covariant = b == py.True
+			} else {
+				v.Contravariant = b == py.True
+			}
+		default:
+			return nil, py.ExceptionNewf(py.TypeError, "TypeVar() got an unexpected keyword argument '%s'", key)
+		}
+	}
+	if v.Covariant && v.Contravariant {
+		return nil, py.ExceptionNewf(py.ValueError, "Bivariant types are not supported.")
+	}
+	if len(v.Constraints) == 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "A single constraint is not allowed")
+	}
+	if len(v.Constraints) > 0 && v.Bound != py.None {
+		return nil, py.ExceptionNewf(py.TypeError, "Constraints cannot be combined with bound=...")
+	}
+	return v, nil
+}
+
+func (v *TypeVar) M__repr__() (py.Object, error) {
+	prefix := "~"
+	if v.Covariant {
+		prefix = "+"
+	} else if v.Contravariant {
+		prefix = "-"
+	}
+	return py.String(prefix + v.Name), nil
+}
+
+// Properties
+func init() {
+	TypeVarType.Dict["__name__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*TypeVar).Name), nil
+		},
+	}
+	TypeVarType.Dict["__constraints__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*TypeVar).Constraints, nil
+		},
+	}
+	TypeVarType.Dict["__bound__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*TypeVar).Bound, nil
+		},
+	}
+	TypeVarType.Dict["__covariant__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.NewBool(self.(*TypeVar).Covariant), nil
+		},
+	}
+	TypeVarType.Dict["__contravariant__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.NewBool(self.(*TypeVar).Contravariant), nil
+		},
+	}
+}
+
+// Check interface is satisfied
+var _ py.I__repr__ = (*TypeVar)(nil)
@@ -0,0 +1,390 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Typing module
+//
+// The types in annotations are made at runtime but not checked, so
+// List[int], Optional[X], TypeVar and Generic are objects which can
+// be made, compared and introspected but nothing more.  As there is
+// no collections.abc the aliases for the abstract collections such
+// as Iterable have no __origin__.
+
+package typing
+
+import (
+	"github.com/go-python/gpython/collections"
+	"github.com/go-python/gpython/py"
+)
+
+const module_doc = `The typing module: Support for gradual typing as defined by PEP 484.
+
+The types are made at runtime but aren't enforced.`
+
+const cast_doc = `Cast a value to a type.
+
+This returns the value unchanged.  To the type checker this
+signals that the return value has the designated type, but at
+runtime we intentionally don't check anything (we want this
+to be as fast as possible).`
+
+func typing_cast(self py.Object, args py.Tuple) (py.Object, error) {
+	var typ, val py.Object
+	err := py.UnpackTuple(args, nil, "cast", 2, 2, &typ, &val)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+const overload_doc = `Decorator for overloaded functions/methods.
+
+The overloads are for the type checker only, the function defined
+without the decorator replaces them.`
+
+const overload_dummy_doc = `Helper for @overload to raise when called.`
+
+var overloadDummy = py.MustNewMethod("_overload_dummy", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return nil, py.ExceptionNewf(py.NotImplementedError, "You should not call an overloaded function. A series of @overload-decorated functions outside a stub module should always be followed by an implementation that is not @overload-ed.")
+}, 0, overload_dummy_doc)
+
+func typing_overload(self, fn py.Object) (py.Object, error) {
+	return overloadDummy, nil
+}
+
+const final_doc = `A decorator to indicate final methods and final classes to type checkers.`
+
+func typing_final(self, fn py.Object) (py.Object, error) {
+	return fn, nil
+}
+
+const no_type_check_doc = `Decorator to indicate that annotations are not type hints.`
+
+func typing_no_type_check(self, arg py.Object) (py.Object, error) {
+	_, err := py.SetAttrString(arg, "__no_type_check__", py.True)
+	if err != nil && !py.IsException(py.AttributeError, err) && !py.IsException(py.TypeError, err) {
+		return nil, err
+	}
+	return arg, nil
+}
+
+const get_origin_doc = `Get the unsubscripted version of a type.
+
+This supports generic types, Callable, Tuple, Union, Literal, Final
+and ClassVar.  Return None for unsupported types.`
+
+func typing_get_origin(self, tp py.Object) (py.Object, error) {
+	switch x := tp.(type) {
+	case *Alias:
+		return x.getOrigin(), nil
+	case *py.GenericAlias:
+		return x.Origin, nil
+	}
+	if tp == Generic {
+		return Generic, nil
+	}
+	return py.None, nil
+}
+
+const get_args_doc = `Get type arguments with all substitutions performed.
+
+For unions, basic simplifications used by Union constructor are
+performed.  Return () for unsupported types.`
+
+func typing_get_args(self, tp py.Object) (py.Object, error) {
+	switch x := tp.(type) {
+	case *Alias:
+		if x.args == nil {
+			return py.Tuple{}, nil
+		}
+		if x.name == "Callable" && !x.isCallableEllipsis() {
+			n := len(x.args) - 1
+			return py.Tuple{py.NewListFromItems(x.args[:n].Copy()), x.args[n]}, nil
+		}
+		return x.args, nil
+	case *py.GenericAlias:
+		return x.Args, nil
+	}
+	return py.Tuple{}, nil
+}
+
+// Returns the annotation value as a type hint, evaluating it if it
+// is a string
+func evalHint(value py.Object, globals, locals py.StringDict) (py.Object, error) {
+	if value == py.None {
+		return py.NoneTypeType, nil
+	}
+	s, ok := value.(py.String)
+	if !ok {
+		return value, nil
+	}
+	code, err := py.Compile(string(s), "<string>", "eval", 0, true)
+	if err != nil {
+		return nil, err
+	}
+	if locals == nil {
+		locals = globals
+	}
+	return py.VmRun(globals, locals, code.(*py.Code), nil)
+}
+
+// Adds the annotations of obj to hints
+func addHints(hints *py.Dict, obj py.Object, globals, locals py.StringDict) error {
+	annotations, err := py.GetAttrString(obj, "__annotations__")
+	if err != nil {
+		if py.IsException(py.AttributeError, err) {
+			return nil
+		}
+		return err
+	}
+	keys, err := py.SequenceList(annotations)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys.Items {
+		value, err := py.GetItem(annotations, key)
+		if err != nil {
+			return err
+		}
+		value, err = evalHint(value, globals, locals)
+		if err != nil {
+			return err
+		}
+		_, err = hints.M__setitem__(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the globals of the module called name or an empty dict if
+// it can't be found
+func moduleGlobals(obj py.Object) py.StringDict {
+	name, err := py.GetAttrString(obj, "__module__")
+	if err != nil {
+		return py.StringDict{}
+	}
+	s, ok := name.(py.String)
+	if !ok {
+		return py.StringDict{}
+	}
+	module, err := py.GetModule(string(s))
+	if err != nil {
+		return py.StringDict{}
+	}
+	return module.Globals
+}
+
+const get_type_hints_doc = `Return type hints for an object.
+
+This is often the same as obj.__annotations__, but it handles
+forward references encoded as string literals and recursively
+replaces None with type(None).  For classes the annotations of
+the bases are included too.`
+
+func typing_get_type_hints(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj, globalnsObj, localnsObj py.Object = nil, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:get_type_hints", []string{"obj", "globalns", "localns"}, &obj, &globalnsObj, &localnsObj)
+	if err != nil {
+		return nil, err
+	}
+	var globalns, localns py.StringDict
+	if globalnsObj != py.None {
+		globalns, err = py.DictAsNamespace(globalnsObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if localnsObj != py.None {
+		localns, err = py.DictAsNamespace(localnsObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	hints := py.NewDict()
+	if cls, ok := obj.(*py.Type); ok && cls.Type().IsSubtype(py.TypeType) {
+		for i := len(cls.Mro) - 1; i >= 0; i-- {
+			base := cls.Mro[i]
+			globals := globalns
+			if globals == nil {
+				globals = moduleGlobals(base)
+			}
+			if _, ok := base.(*py.Type).Dict["__annotations__"]; !ok {
+				continue
+			}
+			err = addHints(hints, base, globals, localns)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return hints, nil
+	}
+	globals := globalns
+	if globals == nil {
+		switch x := obj.(type) {
+		case *py.Function:
+			globals = x.Globals
+		case *py.Module:
+			globals = x.Globals
+		default:
+			globals = moduleGlobals(obj)
+		}
+	}
+	err = addHints(hints, obj, globals, localns)
+	if err != nil {
+		return nil, err
+	}
+	return hints, nil
+}
+
+var NewTypeType = py.NewTypeX("typing.NewType", `NewType creates simple unique types with almost zero
+runtime overhead.  NewType(name, tp) is considered a subtype of tp
+by static type checkers.  At runtime, NewType(name, tp) returns
+a dummy callable that simply returns its argument.`, NewTypeNew, nil)
+
+// NewType is a distinct name for a type which at runtime returns what
+// it is called with
+type NewType struct {
+	Name      string
+	Supertype py.Object
+}
+
+// Type of this object
+func (t *NewType) Type() *py.Type {
+	return NewTypeType
+}
+
+// NewTypeNew makes a NewType from python
+func NewTypeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var name, tp py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO:NewType", []string{"name", "tp"}, &name, &tp)
+	if err != nil {
+		return nil, err
+	}
+	s, err := py.StrAsString(name)
+	if err != nil {
+		return nil, err
+	}
+	return &NewType{Name: s, Supertype: tp}, nil
+}
+
+func (t *NewType) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var x py.Object
+	err := py.UnpackTuple(args, kwargs, t.Name, 1, 1, &x)
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (t *NewType) M__repr__() (py.Object, error) {
+	return py.String("typing.NewType(" + t.Name + ")"), nil
+}
+
+// Properties
+func init() {
+	NewTypeType.Dict["__name__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*NewType).Name), nil
+		},
+	}
+	NewTypeType.Dict["__supertype__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*NewType).Supertype, nil
+		},
+	}
+}
+
+// Check interface is satisfied
+var _ py.I__call__ = (*NewType)(nil)
+var _ py.I__repr__ = (*NewType)(nil)
+
+// The aliases for classes, with the number of types they take
+var aliases = []struct {
+	name    string
+	origin  py.Object
+	nparams int
+}{
+	{"List", py.ListType, 1},
+	{"Dict", py.DictType, 2},
+	{"Set", py.SetType, 1},
+	{"FrozenSet", py.FrozenSetType, 1},
+	{"Tuple", py.TupleType, -1},
+	{"Type", py.TypeType, 1},
+	{"Deque", collections.DequeType, 1},
+	{"DefaultDict", collections.DefaultDictType, 2},
+	{"OrderedDict", collections.OrderedDictType, 2},
+	{"Counter", collections.CounterType, 1},
+	{"Callable", nil, -1},
+	{"Hashable", nil, 0},
+	{"Sized", nil, 0},
+	{"Awaitable", nil, 1},
+	{"Coroutine", nil, 3},
+	{"AsyncIterable", nil, 1},
+	{"AsyncIterator", nil, 1},
+	{"AsyncGenerator", nil, 2},
+	{"Iterable", nil, 1},
+	{"Iterator", nil, 1},
+	{"Reversible", nil, 1},
+	{"Generator", nil, 3},
+	{"Container", nil, 1},
+	{"Collection", nil, 1},
+	{"Sequence", nil, 1},
+	{"MutableSequence", nil, 1},
+	{"AbstractSet", nil, 1},
+	{"MutableSet", nil, 1},
+	{"Mapping", nil, 2},
+	{"MutableMapping", nil, 2},
+	{"MappingView", nil, 1},
+	{"KeysView", nil, 1},
+	{"ItemsView", nil, 2},
+	{"ValuesView", nil, 1},
+	{"ContextManager", nil, 1},
+	{"AsyncContextManager", nil, 1},
+}
+
+func init() {
+	Generic = newGeneric()
+	Protocol = newProtocol()
+
+	methods := []*py.Method{
+		py.MustNewMethod("cast", typing_cast, 0, cast_doc),
+		py.MustNewMethod("overload", typing_overload, 0, overload_doc),
+		py.MustNewMethod("final", typing_final, 0, final_doc),
+		py.MustNewMethod("no_type_check", typing_no_type_check, 0, no_type_check_doc),
+		py.MustNewMethod("runtime_checkable", typing_runtime_checkable, 0, runtime_checkable_doc),
+		py.MustNewMethod("get_origin", typing_get_origin, 0, get_origin_doc),
+		py.MustNewMethod("get_args", typing_get_args, 0, get_args_doc),
+		py.MustNewMethod("get_type_hints", typing_get_type_hints, 0, get_type_hints_doc),
+	}
+	globals := py.StringDict{
+		"Any":           Any,
+		"NoReturn":      NoReturn,
+		"ClassVar":      ClassVar,
+		"Final":         Final,
+		"Union":         Union,
+		"Optional":      Optional,
+		"Literal":       Literal,
+		"TypeVar":       TypeVarType,
+		"Generic":       Generic,
+		"Protocol":      Protocol,
+		"NewType":       NewTypeType,
+		"Text":          py.StringType,
+		"TYPE_CHECKING": py.False,
+		"AnyStr": &TypeVar{
+			Name:        "AnyStr",
+			Constraints: py.Tuple{py.BytesType, py.StringType},
+			Bound:       py.None,
+		},
+	}
+	for _, alias := range aliases {
+		globals[alias.name] = newSpecialAlias(alias.origin, alias.name, alias.nparams)
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "typing",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+}
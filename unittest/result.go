@@ -0,0 +1,552 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test results and the text runner
+
+package unittest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+const test_result_doc = `Holder for test result information.
+
+Test results are automatically managed by the TestCase and TestSuite
+classes, and do not need to be explicitly manipulated by writers of tests.
+
+Each instance holds the total number of tests run, and collections of
+failures and errors that occurred among those test runs. The collections
+contain tuples of (testcase, exceptioninfo), where exceptioninfo is the
+formatted traceback of the error that occurred.`
+
+// TestResultClass is the class holding the outcome of the tests
+var TestResultClass = py.NewClass(py.TypeType, "unittest.result", "TestResult", test_result_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__init__":     py.NewGoMethod("__init__", testResultInit),
+	"startTest":    py.NewGoMethod1("startTest", testResultStartTest),
+	"stopTest":     py.NewGoMethod1("stopTest", testResultNoop),
+	"startTestRun": py.NewGoMethod0("startTestRun", noop),
+	"stopTestRun":  py.NewGoMethod0("stopTestRun", noop),
+	"addSuccess":   py.NewGoMethod1("addSuccess", testResultNoop),
+	"addError": py.NewGoMethod("addError", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return testResultAddExcInfo(self, args, kwargs, "addError", "errors")
+	}),
+	"addFailure": py.NewGoMethod("addFailure", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return testResultAddExcInfo(self, args, kwargs, "addFailure", "failures")
+	}),
+	"addExpectedFailure": py.NewGoMethod("addExpectedFailure", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return testResultAddExcInfo(self, args, kwargs, "addExpectedFailure", "expectedFailures")
+	}),
+	"addSkip": py.NewGoMethod("addSkip", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var test, reason py.Object
+		err := py.UnpackTuple(args, kwargs, "addSkip", 2, 2, &test, &reason)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, appendTo(self, "skipped", py.Tuple{test, reason})
+	}),
+	"addUnexpectedSuccess": py.NewGoMethod1("addUnexpectedSuccess", func(self, test py.Object) (py.Object, error) {
+		if err := appendTo(self, "unexpectedSuccesses", test); err != nil {
+			return nil, err
+		}
+		return py.None, stopIfFailfast(self)
+	}),
+	"wasSuccessful": py.NewGoMethod0("wasSuccessful", testResultWasSuccessful),
+	"stop": py.NewGoMethod0("stop", func(self py.Object) (py.Object, error) {
+		_, err := py.SetAttrString(self, "shouldStop", py.True)
+		return py.None, err
+	}),
+	"__repr__": py.NewGoMethod0("__repr__", testResultRepr),
+})
+
+func testResultNoop(self, test py.Object) (py.Object, error) {
+	return py.None, nil
+}
+
+func testResultInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stream, descriptions, verbosity py.Object = py.None, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOO:TestResult", []string{"stream", "descriptions", "verbosity"}, &stream, &descriptions, &verbosity)
+	if err != nil {
+		return nil, err
+	}
+	attrs := py.StringDict{
+		"failfast":            py.False,
+		"failures":            py.NewList(),
+		"errors":              py.NewList(),
+		"testsRun":            py.Int(0),
+		"skipped":             py.NewList(),
+		"expectedFailures":    py.NewList(),
+		"unexpectedSuccesses": py.NewList(),
+		"shouldStop":          py.False,
+		"buffer":              py.False,
+		"_previousTestClass":  py.None,
+		"_testRunEntered":     py.False,
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+func testResultStartTest(self, test py.Object) (py.Object, error) {
+	run, err := py.IntAttr(self, "testsRun")
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.SetAttrString(self, "testsRun", py.Int(run+1))
+	return py.None, err
+}
+
+// Appends item to the list attribute name of obj
+func appendTo(obj py.Object, name string, item py.Object) error {
+	list, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return err
+	}
+	_, err = py.CallMethodByName(list, "append", item)
+	return err
+}
+
+// Stops the test run if the result is failfast
+func stopIfFailfast(self py.Object) error {
+	failfast, err := py.GetAttrString(self, "failfast")
+	if err != nil || !py.IsTrue(failfast) {
+		return err
+	}
+	_, err = py.CallMethodByName(self, "stop")
+	return err
+}
+
+// Records the test and its formatted exception given as a (type,
+// value, traceback) tuple in the list called name
+func testResultAddExcInfo(self py.Object, args py.Tuple, kwargs py.StringDict, method, name string) (py.Object, error) {
+	var test, errObj py.Object
+	err := py.UnpackTuple(args, kwargs, method, 2, 2, &test, &errObj)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := errObj.(py.Tuple)
+	if !ok || len(info) != 3 {
+		return nil, py.ExceptionNewf(py.TypeError, "%s() argument 2 must be a (type, value, traceback) tuple", method)
+	}
+	if err = appendTo(self, name, py.Tuple{test, py.String(formatException(info))}); err != nil {
+		return nil, err
+	}
+	if name == "expectedFailures" {
+		return py.None, nil
+	}
+	return py.None, stopIfFailfast(self)
+}
+
+// Returns the length of the list attribute name of obj
+func lenAttr(obj py.Object, name string) (int, error) {
+	value, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := py.Len(value)
+	if err != nil {
+		return 0, err
+	}
+	return py.MakeGoInt(n)
+}
+
+func testResultWasSuccessful(self py.Object) (py.Object, error) {
+	for _, name := range []string{"failures", "errors", "unexpectedSuccesses"} {
+		n, err := lenAttr(self, name)
+		if err != nil {
+			return nil, err
+		}
+		if n != 0 {
+			return py.False, nil
+		}
+	}
+	return py.True, nil
+}
+
+func testResultRepr(self py.Object) (py.Object, error) {
+	run, err := py.IntAttr(self, "testsRun")
+	if err != nil {
+		return nil, err
+	}
+	errors, err := lenAttr(self, "errors")
+	if err != nil {
+		return nil, err
+	}
+	failures, err := lenAttr(self, "failures")
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("<%s run=%d errors=%d failures=%d>", strclass(self.Type()), run, errors, failures)), nil
+}
+
+var (
+	separator1 = strings.Repeat("=", 70)
+	separator2 = strings.Repeat("-", 70)
+)
+
+const text_test_result_doc = `A test result class that can print formatted text results to a stream.
+
+Used by TextTestRunner.`
+
+// TextTestResultClass is the result which reports the progress of the
+// tests to a stream
+var TextTestResultClass = py.NewClass(py.TypeType, "unittest.runner", "TextTestResult", text_test_result_doc, py.Tuple{TestResultClass}, py.StringDict{
+	"separator1":     py.String(separator1),
+	"separator2":     py.String(separator2),
+	"__init__":       py.NewGoMethod("__init__", textTestResultInit),
+	"getDescription": py.NewGoMethod1("getDescription", textTestResultGetDescription),
+	"startTest":      py.NewGoMethod1("startTest", textTestResultStartTest),
+	"addSuccess": py.NewGoMethod1("addSuccess", func(self, test py.Object) (py.Object, error) {
+		return textTestResultReport(self, TestResultClass, "addSuccess", py.Tuple{test}, "ok", ".")
+	}),
+	"addError": py.NewGoMethod("addError", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return textTestResultReport(self, TestResultClass, "addError", args, "ERROR", "E")
+	}),
+	"addFailure": py.NewGoMethod("addFailure", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return textTestResultReport(self, TestResultClass, "addFailure", args, "FAIL", "F")
+	}),
+	"addSkip": py.NewGoMethod("addSkip", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if len(args) != 2 {
+			return nil, py.ExceptionNewf(py.TypeError, "addSkip() takes exactly 2 arguments (%d given)", len(args))
+		}
+		return textTestResultReport(self, TestResultClass, "addSkip", args, "skipped "+safeRepr(args[1]), "s")
+	}),
+	"addExpectedFailure": py.NewGoMethod("addExpectedFailure", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return textTestResultReport(self, TestResultClass, "addExpectedFailure", args, "expected failure", "x")
+	}),
+	"addUnexpectedSuccess": py.NewGoMethod1("addUnexpectedSuccess", func(self, test py.Object) (py.Object, error) {
+		return textTestResultReport(self, TestResultClass, "addUnexpectedSuccess", py.Tuple{test}, "unexpected success", "u")
+	}),
+	"printErrors": py.NewGoMethod0("printErrors", textTestResultPrintErrors),
+	"printErrorList": py.NewGoMethod("printErrorList", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var flavour, errors py.Object
+		err := py.UnpackTuple(args, kwargs, "printErrorList", 2, 2, &flavour, &errors)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, printErrorList(self, flavour, errors)
+	}),
+})
+
+// Returns the stream of sys called name or None
+func sysStream(name string) (py.Object, error) {
+	sys, err := py.GetModule("sys")
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := sys.Globals[name]
+	if !ok {
+		return py.None, nil
+	}
+	return stream, nil
+}
+
+// Writes s to the stream
+func write(stream py.Object, s string) error {
+	_, err := py.CallMethodByName(stream, "write", py.String(s))
+	return err
+}
+
+// Writes s to the stream attribute of obj, flushing it if flush is set
+func writeStream(obj py.Object, s string, flush bool) error {
+	stream, err := py.GetAttrString(obj, "stream")
+	if err != nil {
+		return err
+	}
+	if err = write(stream, s); err != nil {
+		return err
+	}
+	if flush {
+		if _, err = py.CallMethodByName(stream, "flush"); err != nil && !py.IsException(py.AttributeError, err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func textTestResultInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stream, descriptions, verbosity py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO:TextTestResult", []string{"stream", "descriptions", "verbosity"}, &stream, &descriptions, &verbosity)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = testResultInit(self, nil, nil); err != nil {
+		return nil, err
+	}
+	level, err := py.MakeGoInt(verbosity)
+	if err != nil {
+		return nil, err
+	}
+	attrs := py.StringDict{
+		"stream":       stream,
+		"showAll":      py.NewBool(level > 1),
+		"dots":         py.NewBool(level == 1),
+		"descriptions": descriptions,
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+func textTestResultGetDescription(self, test py.Object) (py.Object, error) {
+	str, err := py.StrAsString(test)
+	if err != nil {
+		return nil, err
+	}
+	if !hasTrueAttr(self, "descriptions") {
+		return py.String(str), nil
+	}
+	doc, err := py.CallMethodByName(test, "shortDescription")
+	if err != nil {
+		return nil, err
+	}
+	if docStr, ok := doc.(py.String); ok && docStr != "" {
+		return py.String(str + "\n" + string(docStr)), nil
+	}
+	return py.String(str), nil
+}
+
+// Returns the description of test as a Go string
+func description(self, test py.Object) (string, error) {
+	desc, err := py.CallMethodByName(self, "getDescription", test)
+	if err != nil {
+		return "", err
+	}
+	return py.StrAsString(desc)
+}
+
+func textTestResultStartTest(self, test py.Object) (py.Object, error) {
+	if _, err := testResultStartTest(self, test); err != nil {
+		return nil, err
+	}
+	if !hasTrueAttr(self, "showAll") {
+		return py.None, nil
+	}
+	desc, err := description(self, test)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, writeStream(self, desc+" ... ", true)
+}
+
+// Calls the method of the base class then reports the outcome as long
+// when verbose or short when printing dots
+func textTestResultReport(self py.Object, base *py.Type, method string, args py.Tuple, long, short string) (py.Object, error) {
+	fn, err := py.GetAttrString(base, method)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = py.Call(fn, append(py.Tuple{self}, args...), nil); err != nil {
+		return nil, err
+	}
+	if hasTrueAttr(self, "showAll") {
+		err = writeStream(self, long+"\n", true)
+	} else if hasTrueAttr(self, "dots") {
+		err = writeStream(self, short, true)
+	}
+	return py.None, err
+}
+
+func textTestResultPrintErrors(self py.Object) (py.Object, error) {
+	if hasTrueAttr(self, "dots") || hasTrueAttr(self, "showAll") {
+		if err := writeStream(self, "\n", true); err != nil {
+			return nil, err
+		}
+	}
+	for _, flavour := range []struct{ name, list string }{{"ERROR", "errors"}, {"FAIL", "failures"}} {
+		errors, err := py.GetAttrString(self, flavour.list)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.CallMethodByName(self, "printErrorList", py.String(flavour.name), errors); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Prints each (test, traceback) in errors under a heading
+func printErrorList(self, flavour, errors py.Object) error {
+	flavourStr, err := py.StrAsString(flavour)
+	if err != nil {
+		return err
+	}
+	list, err := py.SequenceList(errors)
+	if err != nil {
+		return err
+	}
+	for _, item := range list.Items {
+		pair, ok := item.(py.Tuple)
+		if !ok || len(pair) != 2 {
+			return py.ExceptionNewf(py.TypeError, "expecting a (test, traceback) tuple")
+		}
+		desc, err := description(self, pair[0])
+		if err != nil {
+			return err
+		}
+		tb, err := py.StrAsString(pair[1])
+		if err != nil {
+			return err
+		}
+		err = writeStream(self, fmt.Sprintf("%s\n%s: %s\n%s\n%s\n", separator1, flavourStr, desc, separator2, tb), true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const text_test_runner_doc = `A test runner class that displays results in textual form.
+
+It prints out the names of tests as they are run, errors as they
+occur, and a summary of the results at the end of the test run.`
+
+// TextTestRunnerClass is the runner which prints the results of the
+// tests to a stream
+var TextTestRunnerClass = py.NewClass(py.TypeType, "unittest.runner", "TextTestRunner", text_test_runner_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"resultclass": TextTestResultClass,
+	"__init__":    py.NewGoMethod("__init__", textTestRunnerInit),
+	"_makeResult": py.NewGoMethod0("_makeResult", func(self py.Object) (py.Object, error) {
+		var args [4]py.Object
+		for i, name := range []string{"resultclass", "stream", "descriptions", "verbosity"} {
+			value, err := py.GetAttrString(self, name)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		return py.Call(args[0], py.Tuple(args[1:]), nil)
+	}),
+	"run": py.NewGoMethod1("run", textTestRunnerRun),
+})
+
+func textTestRunnerInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var stream, descriptions, verbosity, failfast, buffer, resultclass py.Object = py.None, py.True, py.Int(1), py.False, py.False, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOO:TextTestRunner", []string{"stream", "descriptions", "verbosity", "failfast", "buffer", "resultclass"}, &stream, &descriptions, &verbosity, &failfast, &buffer, &resultclass)
+	if err != nil {
+		return nil, err
+	}
+	if stream == py.None {
+		if stream, err = sysStream("stderr"); err != nil {
+			return nil, err
+		}
+	}
+	attrs := py.StringDict{
+		"stream":       stream,
+		"descriptions": descriptions,
+		"verbosity":    verbosity,
+		"failfast":     failfast,
+		"buffer":       buffer,
+	}
+	if resultclass != py.None {
+		attrs["resultclass"] = resultclass
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Returns "s" unless n is 1
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+func textTestRunnerRun(self, test py.Object) (py.Object, error) {
+	result, err := py.CallMethodByName(self, "_makeResult")
+	if err != nil {
+		return nil, err
+	}
+	failfast, err := py.GetAttrString(self, "failfast")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = py.SetAttrString(result, "failfast", failfast); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if _, err = py.CallMethodByName(result, "startTestRun"); err != nil {
+		return nil, err
+	}
+	_, err = py.Call(test, py.Tuple{result}, nil)
+	if _, stopErr := py.CallMethodByName(result, "stopTestRun"); err == nil {
+		err = stopErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	taken := time.Since(start)
+	if _, err = py.CallMethodByName(result, "printErrors"); err != nil {
+		return nil, err
+	}
+	if sep, err := py.GetAttrString(result, "separator2"); err == nil {
+		if sepStr, err := py.StrAsString(sep); err == nil {
+			if err = writeStream(self, sepStr+"\n", false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	run, err := py.IntAttr(result, "testsRun")
+	if err != nil {
+		return nil, err
+	}
+	err = writeStream(self, fmt.Sprintf("Ran %d test%s in %.3fs\n\n", run, plural(run), taken.Seconds()), false)
+	if err != nil {
+		return nil, err
+	}
+	var counts [5]int
+	for i, name := range []string{"failures", "errors", "skipped", "expectedFailures", "unexpectedSuccesses"} {
+		if counts[i], err = lenAttr(result, name); err != nil {
+			return nil, err
+		}
+	}
+	failed, errored, skipped, expectedFails, unexpectedSuccesses := counts[0], counts[1], counts[2], counts[3], counts[4]
+	successful, err := py.CallMethodByName(result, "wasSuccessful")
+	if err != nil {
+		return nil, err
+	}
+	var infos []string
+	var outcome string
+	if py.IsTrue(successful) {
+		outcome = "OK"
+	} else {
+		outcome = "FAILED"
+		if failed != 0 {
+			infos = append(infos, fmt.Sprintf("failures=%d", failed))
+		}
+		if errored != 0 {
+			infos = append(infos, fmt.Sprintf("errors=%d", errored))
+		}
+	}
+	if skipped != 0 {
+		infos = append(infos, fmt.Sprintf("skipped=%d", skipped))
+	}
+	if expectedFails != 0 {
+		infos = append(infos, fmt.Sprintf("expected failures=%d", expectedFails))
+	}
+	if unexpectedSuccesses != 0 {
+		infos = append(infos, fmt.Sprintf("unexpected successes=%d", unexpectedSuccesses))
+	}
+	if len(infos) != 0 {
+		outcome += " (" + strings.Join(infos, ", ") + ")"
+	}
+	if err = writeStream(self, outcome+"\n", true); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,538 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test suites and the loader
+
+package unittest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const test_suite_doc = `A test suite is a composite test consisting of a number of TestCases.
+
+For use, create an instance of TestSuite, then add test case instances.
+When all tests have been added, the suite can be passed to a test
+runner, such as TextTestRunner. It will run the individual test cases
+in the order in which they were added, aggregating the results. When
+subclassing, do not forget to call the base class constructor.`
+
+// TestSuiteClass is the class of the collections of tests
+var TestSuiteClass = py.NewClass(py.TypeType, "unittest.suite", "TestSuite", test_suite_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__init__": py.NewGoMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var tests py.Object = py.Tuple{}
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:TestSuite", []string{"tests"}, &tests)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = py.SetAttrString(self, "_tests", py.NewList()); err != nil {
+			return nil, err
+		}
+		_, err = py.CallMethodByName(self, "addTests", tests)
+		return py.None, err
+	}),
+	"__repr__": py.NewGoMethod0("__repr__", func(self py.Object) (py.Object, error) {
+		tests, err := suiteTests(self)
+		if err != nil {
+			return nil, err
+		}
+		repr, err := py.ReprAsString(tests)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("<%s tests=%s>", strclass(self.Type()), repr)), nil
+	}),
+	"__eq__": py.NewGoMethod1("__eq__", func(self, other py.Object) (py.Object, error) {
+		if other.Type() != self.Type() {
+			return py.NotImplemented, nil
+		}
+		tests, err := suiteTests(self)
+		if err != nil {
+			return nil, err
+		}
+		otherTests, err := suiteTests(other)
+		if err != nil {
+			return nil, err
+		}
+		return py.Eq(tests, otherTests)
+	}),
+	"__iter__": py.NewGoMethod0("__iter__", func(self py.Object) (py.Object, error) {
+		tests, err := suiteTests(self)
+		if err != nil {
+			return nil, err
+		}
+		return py.Iter(tests)
+	}),
+	"countTestCases": py.NewGoMethod0("countTestCases", func(self py.Object) (py.Object, error) {
+		tests, err := suiteTests(self)
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		for _, test := range tests.Items {
+			count, err := py.CallMethodByName(test, "countTestCases")
+			if err != nil {
+				return nil, err
+			}
+			i, err := py.MakeGoInt(count)
+			if err != nil {
+				return nil, err
+			}
+			n += i
+		}
+		return py.Int(n), nil
+	}),
+	"addTests": py.NewGoMethod1("addTests", testSuiteAddTests),
+	"run":      py.NewGoMethod("run", testSuiteRun),
+	"__call__": py.NewGoMethod("__call__", testSuiteRun),
+	"debug": py.NewGoMethod0("debug", func(self py.Object) (py.Object, error) {
+		tests, err := suiteTests(self)
+		if err != nil {
+			return nil, err
+		}
+		for _, test := range tests.Items {
+			if _, err = py.CallMethodByName(test, "debug"); err != nil {
+				return nil, err
+			}
+		}
+		return py.None, nil
+	}),
+})
+
+func init() {
+	// addTest refers to TestSuiteClass so is added here
+	TestSuiteClass.Dict["addTest"] = py.NewGoMethod1("addTest", testSuiteAddTest)
+}
+
+// Returns the list of the tests in the suite
+func suiteTests(self py.Object) (*py.List, error) {
+	tests, err := py.GetAttrString(self, "_tests")
+	if err != nil {
+		return nil, err
+	}
+	list, ok := tests.(*py.List)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "TestSuite._tests must be a list")
+	}
+	return list, nil
+}
+
+// Returns whether obj is an instance of cls
+func isInstance(obj py.Object, cls *py.Type) bool {
+	return obj.Type().IsSubtype(cls)
+}
+
+// Returns true if obj can be called
+func callable(obj py.Object) bool {
+	if t, ok := obj.(*py.Type); ok {
+		cls := t.Type()
+		return cls.IsSubtype(py.TypeType) || cls.Lookup("__call__") != nil
+	}
+	_, ok := obj.(py.I__call__)
+	return ok
+}
+
+// Returns whether obj is a subclass of cls
+func isSubclass(obj py.Object, cls *py.Type) bool {
+	t, ok := obj.(*py.Type)
+	return ok && t.Type().IsSubtype(py.TypeType) && t.IsSubtype(cls)
+}
+
+func testSuiteAddTest(self, test py.Object) (py.Object, error) {
+	if !callable(test) {
+		return nil, py.ExceptionNewf(py.TypeError, "%s is not callable", safeRepr(test))
+	}
+	if isSubclass(test, TestCaseClass) || isSubclass(test, TestSuiteClass) {
+		return nil, py.ExceptionNewf(py.TypeError, "TestCases and TestSuites must be instantiated before passing them to addTest()")
+	}
+	tests, err := suiteTests(self)
+	if err != nil {
+		return nil, err
+	}
+	tests.Append(test)
+	return py.None, nil
+}
+
+func testSuiteAddTests(self, tests py.Object) (py.Object, error) {
+	if _, ok := tests.(py.String); ok {
+		return nil, py.ExceptionNewf(py.TypeError, "tests must be an iterable of tests, not a string")
+	}
+	list, err := py.SequenceList(tests)
+	if err != nil {
+		return nil, err
+	}
+	for _, test := range list.Items {
+		if _, err = py.CallMethodByName(self, "addTest", test); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+func testSuiteRun(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var result py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:run", []string{"result"}, &result)
+	if err != nil {
+		return nil, err
+	}
+	// The outermost suite tears down the class fixtures of the last test
+	topLevel := !hasTrueAttr(result, "_testRunEntered")
+	if topLevel {
+		if _, err = py.SetAttrString(result, "_testRunEntered", py.True); err != nil {
+			return nil, err
+		}
+	}
+	tests, err := suiteTests(self)
+	if err != nil {
+		return nil, err
+	}
+	for _, test := range append([]py.Object(nil), tests.Items...) {
+		if hasTrueAttr(result, "shouldStop") {
+			break
+		}
+		if isInstance(test, TestCaseClass) {
+			if err = tearDownPreviousClass(test.Type(), result); err != nil {
+				return nil, err
+			}
+			if err = handleClassSetUp(test.Type(), result); err != nil {
+				return nil, err
+			}
+			if _, err = py.SetAttrString(result, "_previousTestClass", test.Type()); err != nil {
+				return nil, err
+			}
+			if hasTrueAttr(test.Type(), "_classSetupFailed") {
+				continue
+			}
+		}
+		if _, err = py.Call(test, py.Tuple{result}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if topLevel {
+		if err = tearDownPreviousClass(nil, result); err != nil {
+			return nil, err
+		}
+		if _, err = py.SetAttrString(result, "_testRunEntered", py.False); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Returns the class of the test run before the current one or nil
+func previousTestClass(result py.Object) *py.Type {
+	previous, err := py.GetAttrString(result, "_previousTestClass")
+	if err != nil {
+		return nil
+	}
+	cls, _ := previous.(*py.Type)
+	return cls
+}
+
+// Reports an error in a class fixture to the result
+func addClassLevelException(result py.Object, err error, description string) error {
+	if py.IsException(py.KeyboardInterrupt, err) {
+		return err
+	}
+	holder, callErr := py.Call(errorHolderClass, py.Tuple{py.String(description)}, nil)
+	if callErr != nil {
+		return callErr
+	}
+	info := excInfo(err)
+	if py.IsException(SkipTest, err) {
+		reason, strErr := py.Str(info[1])
+		if strErr != nil {
+			return strErr
+		}
+		_, err = py.CallMethodByName(result, "addSkip", holder, reason)
+	} else {
+		_, err = py.CallMethodByName(result, "addError", holder, info)
+	}
+	return err
+}
+
+// Calls setUpClass of cls if the previous test was of a different
+// class
+func handleClassSetUp(cls *py.Type, result py.Object) error {
+	if cls == previousTestClass(result) || hasTrueAttr(cls, "__unittest_skip__") {
+		return nil
+	}
+	if _, err := py.SetAttrString(cls, "_classSetupFailed", py.False); err != nil {
+		return err
+	}
+	if _, err := py.CallMethodByName(cls, "setUpClass"); err != nil {
+		if _, attrErr := py.SetAttrString(cls, "_classSetupFailed", py.True); attrErr != nil {
+			return attrErr
+		}
+		return addClassLevelException(result, err, fmt.Sprintf("setUpClass (%s)", strclass(cls)))
+	}
+	return nil
+}
+
+// Calls tearDownClass of the class of the previous test if the current
+// test, of class cls, is of a different class
+func tearDownPreviousClass(cls *py.Type, result py.Object) error {
+	previous := previousTestClass(result)
+	if previous == nil || previous == cls {
+		return nil
+	}
+	if hasTrueAttr(previous, "_classSetupFailed") || hasTrueAttr(previous, "__unittest_skip__") {
+		return nil
+	}
+	if _, err := py.CallMethodByName(previous, "tearDownClass"); err != nil {
+		return addClassLevelException(result, err, fmt.Sprintf("tearDownClass (%s)", strclass(previous)))
+	}
+	return nil
+}
+
+const error_holder_doc = `Placeholder for a TestCase inside a result. As far as a TestResult
+is concerned, this looks exactly like a unit test. Used to insert
+arbitrary errors into a test suite run.`
+
+// errorHolderClass stands in for the test in the result when a class
+// fixture fails
+var errorHolderClass = py.NewClass(py.TypeType, "unittest.suite", "_ErrorHolder", error_holder_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"failureException": py.None,
+	"__init__": py.NewGoMethod1("__init__", func(self, description py.Object) (py.Object, error) {
+		_, err := py.SetAttrString(self, "description", description)
+		return py.None, err
+	}),
+	"id": py.NewGoMethod0("id", func(self py.Object) (py.Object, error) {
+		return py.GetAttrString(self, "description")
+	}),
+	"shortDescription": py.NewGoMethod0("shortDescription", noop),
+	"__repr__": py.NewGoMethod0("__repr__", func(self py.Object) (py.Object, error) {
+		description, err := py.GetAttrString(self, "description")
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("<ErrorHolder description=%s>", safeRepr(description))), nil
+	}),
+	"__str__": py.NewGoMethod0("__str__", func(self py.Object) (py.Object, error) {
+		return py.GetAttrString(self, "description")
+	}),
+	"countTestCases": py.NewGoMethod0("countTestCases", func(self py.Object) (py.Object, error) {
+		return py.Int(0), nil
+	}),
+})
+
+const test_loader_doc = `This class is responsible for loading tests according to various criteria
+and returning them wrapped in a TestSuite`
+
+// TestLoaderClass is the class which finds the tests in test cases and
+// modules
+var TestLoaderClass = py.NewClass(py.TypeType, "unittest.loader", "TestLoader", test_loader_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"testMethodPrefix": py.String("test"),
+	"suiteClass":       TestSuiteClass,
+	"__init__": py.NewGoMethod0("__init__", func(self py.Object) (py.Object, error) {
+		_, err := py.SetAttrString(self, "errors", py.NewList())
+		return py.None, err
+	}),
+	"getTestCaseNames":      py.NewGoMethod1("getTestCaseNames", testLoaderGetTestCaseNames),
+	"loadTestsFromTestCase": py.NewGoMethod1("loadTestsFromTestCase", testLoaderLoadTestsFromTestCase),
+	"loadTestsFromModule":   py.NewGoMethod("loadTestsFromModule", testLoaderLoadTestsFromModule),
+	"loadTestsFromName":     py.NewGoMethod("loadTestsFromName", testLoaderLoadTestsFromName),
+	"loadTestsFromNames":    py.NewGoMethod("loadTestsFromNames", testLoaderLoadTestsFromNames),
+})
+
+// Makes a suite of the tests using the suiteClass of the loader
+func makeSuite(self py.Object, tests py.Object) (py.Object, error) {
+	suiteClass, err := py.GetAttrString(self, "suiteClass")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(suiteClass, py.Tuple{tests}, nil)
+}
+
+func testLoaderGetTestCaseNames(self, testCaseClass py.Object) (py.Object, error) {
+	prefixObj, err := py.GetAttrString(self, "testMethodPrefix")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := py.StrAsString(prefixObj)
+	if err != nil {
+		return nil, err
+	}
+	names, err := py.Dir(testCaseClass)
+	if err != nil {
+		return nil, err
+	}
+	var found []string
+	for _, nameObj := range names.Items {
+		name, ok := nameObj.(py.String)
+		if !ok || !strings.HasPrefix(string(name), prefix) {
+			continue
+		}
+		attr, err := py.GetAttrString(testCaseClass, string(name))
+		if err != nil {
+			return nil, err
+		}
+		if callable(attr) {
+			found = append(found, string(name))
+		}
+	}
+	sort.Strings(found)
+	result := py.NewListSized(len(found))
+	for i, name := range found {
+		result.Items[i] = py.String(name)
+	}
+	return result, nil
+}
+
+func testLoaderLoadTestsFromTestCase(self, testCaseClass py.Object) (py.Object, error) {
+	if isSubclass(testCaseClass, TestSuiteClass) {
+		return nil, py.ExceptionNewf(py.TypeError, "Test cases should not be derived from TestSuite. Maybe you meant to derive from TestCase?")
+	}
+	namesObj, err := py.CallMethodByName(self, "getTestCaseNames", testCaseClass)
+	if err != nil {
+		return nil, err
+	}
+	names, err := py.SequenceList(namesObj)
+	if err != nil {
+		return nil, err
+	}
+	if len(names.Items) == 0 {
+		if _, err = py.GetAttrString(testCaseClass, "runTest"); err == nil {
+			names.Items = []py.Object{py.String("runTest")}
+		}
+	}
+	tests := py.NewListSized(len(names.Items))
+	for i, name := range names.Items {
+		if tests.Items[i], err = py.Call(testCaseClass, py.Tuple{name}, nil); err != nil {
+			return nil, err
+		}
+	}
+	return makeSuite(self, tests)
+}
+
+func testLoaderLoadTestsFromModule(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var module, pattern py.Object = nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:loadTestsFromModule", []string{"module", "pattern"}, &module, &pattern)
+	if err != nil {
+		return nil, err
+	}
+	names, err := py.Dir(module)
+	if err != nil {
+		return nil, err
+	}
+	tests := py.NewList()
+	for _, name := range names.Items {
+		obj, err := py.GetAttr(module, name)
+		if err != nil {
+			return nil, err
+		}
+		if isSubclass(obj, TestCaseClass) {
+			suite, err := py.CallMethodByName(self, "loadTestsFromTestCase", obj)
+			if err != nil {
+				return nil, err
+			}
+			tests.Append(suite)
+		}
+	}
+	suite, err := makeSuite(self, tests)
+	if err != nil {
+		return nil, err
+	}
+	// Let the module customise its tests as CPython does
+	if loadTests, err := py.GetAttrString(module, "load_tests"); err == nil {
+		return py.Call(loadTests, py.Tuple{self, suite, pattern}, nil)
+	}
+	return suite, nil
+}
+
+// Finds the object the dotted name refers to, importing the longest
+// prefix of it which is a module if module is None, and the object it
+// was found in
+func resolveName(name string, module py.Object) (obj, parent py.Object, err error) {
+	parts := strings.Split(name, ".")
+	if module == py.None {
+		for i := len(parts); i > 0; i-- {
+			modName := strings.Join(parts[:i], ".")
+			if _, err = py.ImportModuleLevelObject(modName, nil, nil, nil, 0); err == nil {
+				if module, err = py.CurrentContext.GetModule(modName); err == nil {
+					parts = parts[i:]
+					break
+				}
+			}
+			if i == 1 {
+				return nil, nil, err
+			}
+		}
+	}
+	obj = module
+	for _, part := range parts {
+		parent = obj
+		if obj, err = py.GetAttrString(obj, part); err != nil {
+			return nil, nil, err
+		}
+	}
+	return obj, parent, nil
+}
+
+func testLoaderLoadTestsFromName(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var nameObj, module py.Object = nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:loadTestsFromName", []string{"name", "module"}, &nameObj, &module)
+	if err != nil {
+		return nil, err
+	}
+	name, err := py.StrAsString(nameObj)
+	if err != nil {
+		return nil, err
+	}
+	obj, parent, err := resolveName(name, module)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case isInstance(obj, py.ModuleType):
+		return py.CallMethodByName(self, "loadTestsFromModule", obj)
+	case isSubclass(obj, TestCaseClass):
+		return py.CallMethodByName(self, "loadTestsFromTestCase", obj)
+	case isSubclass(parent, TestCaseClass) && callable(obj):
+		methodName := name[strings.LastIndex(name, ".")+1:]
+		test, err := py.Call(parent, py.Tuple{py.String(methodName)}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return makeSuite(self, py.Tuple{test})
+	case isInstance(obj, TestSuiteClass):
+		return obj, nil
+	case callable(obj):
+		test, err := py.Call(obj, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if isInstance(test, TestSuiteClass) {
+			return test, nil
+		}
+		if isInstance(test, TestCaseClass) {
+			return makeSuite(self, py.Tuple{test})
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "calling %s returned %s, not a test", safeRepr(obj), safeRepr(test))
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "don't know how to make test from: %s", safeRepr(obj))
+}
+
+func testLoaderLoadTestsFromNames(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var names, module py.Object = nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:loadTestsFromNames", []string{"names", "module"}, &names, &module)
+	if err != nil {
+		return nil, err
+	}
+	list, err := py.SequenceList(names)
+	if err != nil {
+		return nil, err
+	}
+	suites := py.NewListSized(len(list.Items))
+	for i, name := range list.Items {
+		if suites.Items[i], err = py.CallMethodByName(self, "loadTestsFromName", name, module); err != nil {
+			return nil, err
+		}
+	}
+	return makeSuite(self, suites)
+}
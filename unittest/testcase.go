@@ -0,0 +1,1017 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// TestCase
+
+package unittest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const test_case_doc = `A class whose instances are single test cases.
+
+By default, the test code itself should be placed in a method named
+'runTest'.
+
+If the fixture may be used for many test cases, create as
+many test methods as are needed. When instantiating such a TestCase
+subclass, specify in the constructor arguments the name of the test method
+that the instance is to execute.
+
+Test authors should subclass TestCase for their own tests. Construction
+and deconstruction of the test's environment ('fixture') can be
+implemented by overriding the 'setUp' and 'tearDown' methods respectively.
+
+If it is necessary to override the __init__ method, the base class
+__init__ method must always be called. It is important that subclasses
+should not change the signature of their __init__ method, since instances
+of the classes are instantiated automatically by parts of the framework
+in order to be run.
+
+When subclassing TestCase, you can set these attributes:
+* failureException: determines which exception will be raised when
+    the instance's assertion methods fail; test methods raising this
+    exception will be deemed to have 'failed' rather than 'errored'.
+* longMessage: determines whether long messages (including repr of
+    objects used in assert methods) will be printed on failure in *addition*
+    to any explicit message passed.
+* maxDiff: sets the maximum length of a diff in failure messages
+    by assert methods using difflib. It is looked up as an instance
+    attribute so can be configured by individual tests if required.`
+
+// TestCaseClass is the base class of the tests
+var TestCaseClass = py.NewClass(py.TypeType, "unittest.case", "TestCase", test_case_doc, py.Tuple{py.ObjectType}, testCaseDict())
+
+// Makes the dictionary of TestCase
+func testCaseDict() py.StringDict {
+	dict := py.StringDict{
+		"failureException": py.AssertionError,
+		"longMessage":      py.True,
+		"maxDiff":          py.Int(80 * 8),
+		"__init__":         py.NewGoMethod("__init__", testCaseInit),
+		"setUp":            py.NewGoMethod0("setUp", noop),
+		"tearDown":         py.NewGoMethod0("tearDown", noop),
+		"setUpClass":       &py.ClassMethod{Callable: py.NewGoMethod0("setUpClass", noop), Dict: py.NewStringDict()},
+		"tearDownClass":    &py.ClassMethod{Callable: py.NewGoMethod0("tearDownClass", noop), Dict: py.NewStringDict()},
+		"countTestCases": py.NewGoMethod0("countTestCases", func(self py.Object) (py.Object, error) {
+			return py.Int(1), nil
+		}),
+		"defaultTestResult": py.NewGoMethod0("defaultTestResult", func(self py.Object) (py.Object, error) {
+			return py.Call(TestResultClass, nil, nil)
+		}),
+		"id":               py.NewGoMethod0("id", testCaseId),
+		"shortDescription": py.NewGoMethod0("shortDescription", testCaseShortDescription),
+		"__str__":          py.NewGoMethod0("__str__", testCaseStr),
+		"__repr__":         py.NewGoMethod0("__repr__", testCaseRepr),
+		"run":              py.NewGoMethod("run", testCaseRun),
+		"__call__":         py.NewGoMethod("__call__", testCaseRun),
+		"debug":            py.NewGoMethod0("debug", testCaseDebug),
+		"skipTest": py.NewGoMethod1("skipTest", func(self, reason py.Object) (py.Object, error) {
+			return nil, raise(SkipTest, reason)
+		}),
+		"addCleanup": py.NewGoMethod("addCleanup", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			if len(args) < 1 {
+				return nil, py.ExceptionNewf(py.TypeError, "addCleanup() missing 1 required positional argument: 'function'")
+			}
+			cleanups, err := py.GetAttrString(self, "_cleanups")
+			if err != nil {
+				return nil, err
+			}
+			kw := py.NewDict()
+			for key, value := range kwargs {
+				kw.M__setitem__(py.String(key), value)
+			}
+			_, err = py.CallMethodByName(cleanups, "append", py.Tuple{args[0], args[1:], kw})
+			return py.None, err
+		}),
+		"doCleanups": py.NewGoMethod0("doCleanups", func(self py.Object) (py.Object, error) {
+			return py.None, doCleanups(self, nil)
+		}),
+		"fail": py.NewGoMethod("fail", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			var msg py.Object = py.None
+			err := py.ParseTupleAndKeywords(args, kwargs, "|O:fail", []string{"msg"}, &msg)
+			if err != nil {
+				return nil, err
+			}
+			return nil, failure(self, msg)
+		}),
+		"assertRaises":      py.NewGoMethod("assertRaises", testCaseAssertRaises),
+		"assertRaisesRegex": py.NewGoMethod("assertRaisesRegex", testCaseAssertRaisesRegex),
+		"assertRegex":       regexMethod("assertRegex", true),
+		"assertNotRegex":    regexMethod("assertNotRegex", false),
+		"assertAlmostEqual": py.NewGoMethod("assertAlmostEqual", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return almostEqual(self, args, kwargs, "assertAlmostEqual", true)
+		}),
+		"assertNotAlmostEqual": py.NewGoMethod("assertNotAlmostEqual", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return almostEqual(self, args, kwargs, "assertNotAlmostEqual", false)
+		}),
+		"assertCountEqual": py.NewGoMethod("assertCountEqual", testCaseAssertCountEqual),
+	}
+	for _, a := range unaryAssertions {
+		dict[a.name] = unaryMethod(a.name, a.test, a.message)
+	}
+	for _, a := range binaryAssertions {
+		dict[a.name] = binaryMethod(a.name, a.test, a.message)
+	}
+	// The type specific assertions which CPython gives more detailed
+	// messages for
+	for _, name := range []string{"assertMultiLineEqual", "assertSequenceEqual", "assertListEqual", "assertTupleEqual", "assertSetEqual", "assertDictEqual"} {
+		dict[name] = dict["assertEqual"]
+	}
+	// Deprecated aliases
+	dict["assertEquals"] = dict["assertEqual"]
+	dict["assertNotEquals"] = dict["assertNotEqual"]
+	dict["assertAlmostEquals"] = dict["assertAlmostEqual"]
+	dict["assert_"] = dict["assertTrue"]
+	return dict
+}
+
+// Does nothing, for the methods which subclasses may override
+func noop(self py.Object) (py.Object, error) {
+	return py.None, nil
+}
+
+// Makes an instance of the exception cls with the args and returns it
+// as an error to raise
+func raise(cls py.Object, args ...py.Object) error {
+	exc, err := py.Call(cls, py.Tuple(args), nil)
+	if err != nil {
+		return err
+	}
+	if e, ok := exc.(*py.Exception); ok {
+		return e
+	}
+	return py.ExceptionNewf(py.TypeError, "exceptions must derive from BaseException")
+}
+
+// Calls the builtin function name
+func callBuiltin(name string, args ...py.Object) (py.Object, error) {
+	fn, ok := py.CurrentContext.Builtins().Globals[name]
+	if !ok {
+		return nil, py.ExceptionNewf(py.NameError, "name '%s' is not defined", name)
+	}
+	return py.Call(fn, py.Tuple(args), nil)
+}
+
+// Returns the exception the test case raises for a failure with the
+// standard message and msg as longMessage says
+func failureMessage(self, msg py.Object, standard string) (py.Object, error) {
+	if msg == py.None {
+		return py.String(standard), nil
+	}
+	long, err := py.GetAttrString(self, "longMessage")
+	if err != nil {
+		return nil, err
+	}
+	if !py.IsTrue(long) {
+		return msg, nil
+	}
+	str, err := py.StrAsString(msg)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(standard + " : " + str), nil
+}
+
+// Returns the failureException of the test case made with msg
+func failure(self, msg py.Object) error {
+	failureException, err := py.GetAttrString(self, "failureException")
+	if err != nil {
+		return err
+	}
+	return raise(failureException, msg)
+}
+
+// Returns the failure with the standard message and msg
+func failWith(self, msg py.Object, standard string) error {
+	m, err := failureMessage(self, msg, standard)
+	if err != nil {
+		return err
+	}
+	return failure(self, m)
+}
+
+// Returns the repr of obj for a failure message
+func safeRepr(obj py.Object) string {
+	repr, err := py.ReprAsString(obj)
+	if err != nil {
+		return fmt.Sprintf("<%s object>", obj.Type().Name)
+	}
+	return repr
+}
+
+// An assertion about one value
+type unaryAssertion struct {
+	name    string
+	test    func(x py.Object) (bool, error)
+	message string // with %s for the repr of the value
+}
+
+var unaryAssertions = []unaryAssertion{
+	{"assertTrue", func(x py.Object) (bool, error) {
+		b, err := py.MakeBool(x)
+		return b == py.True, err
+	}, "%s is not true"},
+	{"assertFalse", func(x py.Object) (bool, error) {
+		b, err := py.MakeBool(x)
+		return b == py.False, err
+	}, "%s is not false"},
+	{"assertIsNone", func(x py.Object) (bool, error) {
+		return x == py.None, nil
+	}, "%s is not None"},
+	{"assertIsNotNone", func(x py.Object) (bool, error) {
+		return x != py.None, nil
+	}, "unexpectedly None"},
+}
+
+// Makes the method for an assertion about one value
+func unaryMethod(name string, test func(x py.Object) (bool, error), message string) *py.GoMethod {
+	return py.NewGoMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var x, msg py.Object = nil, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:"+name, []string{"expr", "msg"}, &x, &msg)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := test(x)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return py.None, nil
+		}
+		standard := message
+		if strings.Contains(message, "%s") {
+			standard = fmt.Sprintf(message, safeRepr(x))
+		}
+		return nil, failWith(self, msg, standard)
+	})
+}
+
+// An assertion about two values
+type binaryAssertion struct {
+	name    string
+	test    func(first, second py.Object) (bool, error)
+	message func(first, second string) string // given the reprs
+}
+
+// Makes the test of a binary assertion from a comparison
+func comparison(op func(a, b py.Object) (py.Object, error)) func(first, second py.Object) (bool, error) {
+	return func(first, second py.Object) (bool, error) {
+		res, err := op(first, second)
+		if err != nil {
+			return false, err
+		}
+		b, err := py.MakeBool(res)
+		return b == py.True, err
+	}
+}
+
+// Returns the message of a binary assertion
+func message(format string) func(first, second string) string {
+	return func(first, second string) string {
+		return fmt.Sprintf(format, first, second)
+	}
+}
+
+var binaryAssertions = []binaryAssertion{
+	{"assertEqual", comparison(py.Eq), message("%s != %s")},
+	{"assertNotEqual", comparison(py.Ne), message("%s == %s")},
+	{"assertGreater", comparison(py.Gt), message("%s not greater than %s")},
+	{"assertGreaterEqual", comparison(py.Ge), message("%s not greater than or equal to %s")},
+	{"assertLess", comparison(py.Lt), message("%s not less than %s")},
+	{"assertLessEqual", comparison(py.Le), message("%s not less than or equal to %s")},
+	{"assertIs", func(first, second py.Object) (bool, error) {
+		return first == second, nil
+	}, message("%s is not %s")},
+	{"assertIsNot", func(first, second py.Object) (bool, error) {
+		return first != second, nil
+	}, func(first, second string) string {
+		return "unexpectedly identical: " + first
+	}},
+	{"assertIn", func(member, container py.Object) (bool, error) {
+		return py.SequenceContains(container, member)
+	}, message("%s not found in %s")},
+	{"assertNotIn", func(member, container py.Object) (bool, error) {
+		found, err := py.SequenceContains(container, member)
+		return !found, err
+	}, message("%s unexpectedly found in %s")},
+	{"assertIsInstance", func(obj, cls py.Object) (bool, error) {
+		res, err := callBuiltin("isinstance", obj, cls)
+		return res == py.True, err
+	}, message("%s is not an instance of %s")},
+	{"assertNotIsInstance", func(obj, cls py.Object) (bool, error) {
+		res, err := callBuiltin("isinstance", obj, cls)
+		return res == py.False, err
+	}, message("%s is an instance of %s")},
+}
+
+// Makes the method for an assertion about two values
+func binaryMethod(name string, test func(first, second py.Object) (bool, error), message func(first, second string) string) *py.GoMethod {
+	return py.NewGoMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var first, second, msg py.Object = nil, nil, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:"+name, []string{"first", "second", "msg"}, &first, &second, &msg)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := test(first, second)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return py.None, nil
+		}
+		return nil, failWith(self, msg, message(safeRepr(first), safeRepr(second)))
+	})
+}
+
+// Returns whether regex matches somewhere in text, using the re
+// module so python regular expression syntax is understood
+func search(regex, text py.Object) (bool, error) {
+	re, err := py.CurrentContext.GetModule("re")
+	if err != nil {
+		return false, err
+	}
+	match, err := py.CallMethodByName(re, "search", regex, text)
+	if err != nil {
+		return false, err
+	}
+	return match != py.None, nil
+}
+
+// Returns the source of a regular expression given as a string or a
+// compiled pattern
+func patternOf(regex py.Object) py.Object {
+	if pattern, err := py.GetAttrString(regex, "pattern"); err == nil {
+		return pattern
+	}
+	return regex
+}
+
+// Makes assertRegex or assertNotRegex
+func regexMethod(name string, want bool) *py.GoMethod {
+	return py.NewGoMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var text, regex, msg py.Object = nil, nil, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:"+name, []string{"text", "expected_regex", "msg"}, &text, &regex, &msg)
+		if err != nil {
+			return nil, err
+		}
+		found, err := search(regex, text)
+		if err != nil {
+			return nil, err
+		}
+		if found == want {
+			return py.None, nil
+		}
+		pattern := safeRepr(patternOf(regex))
+		if want {
+			return nil, failWith(self, msg, fmt.Sprintf("Regex didn't match: %s not found in %s", pattern, safeRepr(text)))
+		}
+		return nil, failWith(self, msg, fmt.Sprintf("Regex matched: %s matches %s", safeRepr(text), pattern))
+	})
+}
+
+// Implements assertAlmostEqual and assertNotAlmostEqual
+func almostEqual(self py.Object, args py.Tuple, kwargs py.StringDict, name string, want bool) (py.Object, error) {
+	var first, second, places, msg, delta py.Object = nil, nil, py.None, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|OOO:"+name, []string{"first", "second", "places", "msg", "delta"}, &first, &second, &places, &msg, &delta)
+	if err != nil {
+		return nil, err
+	}
+	if places != py.None && delta != py.None {
+		return nil, py.ExceptionNewf(py.TypeError, "specify delta or places not both")
+	}
+	equal, err := comparison(py.Eq)(first, second)
+	if err != nil {
+		return nil, err
+	}
+	if equal {
+		if want {
+			return py.None, nil
+		}
+		if delta != py.None {
+			return nil, failWith(self, msg, fmt.Sprintf("%s == %s within %s delta (0 difference)", safeRepr(first), safeRepr(second), safeRepr(delta)))
+		}
+		if places == py.None {
+			places = py.Int(7)
+		}
+		return nil, failWith(self, msg, fmt.Sprintf("%s == %s within %s places", safeRepr(first), safeRepr(second), safeRepr(places)))
+	}
+	diff, err := py.Sub(first, second)
+	if err != nil {
+		return nil, err
+	}
+	if diff, err = py.Abs(diff); err != nil {
+		return nil, err
+	}
+	var close bool
+	if delta != py.None {
+		close, err = comparison(py.Le)(diff, delta)
+	} else {
+		if places == py.None {
+			places = py.Int(7)
+		}
+		var rounded py.Object
+		rounded, err = callBuiltin("round", diff, places)
+		if err == nil {
+			close, err = comparison(py.Eq)(rounded, py.Int(0))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if close == want {
+		return py.None, nil
+	}
+	if delta != py.None {
+		op := "!="
+		if !want {
+			op = "=="
+		}
+		return nil, failWith(self, msg, fmt.Sprintf("%s %s %s within %s delta (%s difference)", safeRepr(first), op, safeRepr(second), safeRepr(delta), safeRepr(diff)))
+	}
+	if !want {
+		return nil, failWith(self, msg, fmt.Sprintf("%s == %s within %s places", safeRepr(first), safeRepr(second), safeRepr(places)))
+	}
+	return nil, failWith(self, msg, fmt.Sprintf("%s != %s within %s places (%s difference)", safeRepr(first), safeRepr(second), safeRepr(places), safeRepr(diff)))
+}
+
+// An element of a sequence and how many times it appears
+type elementCount struct {
+	element py.Object
+	count   int
+}
+
+// Counts the elements of seq which are equal
+func countElements(seq py.Object) ([]elementCount, error) {
+	items, err := py.SequenceList(seq)
+	if err != nil {
+		return nil, err
+	}
+	var counts []elementCount
+	for _, item := range items.Items {
+		found := false
+		for i := range counts {
+			equal, err := comparison(py.Eq)(counts[i].element, item)
+			if err != nil {
+				return nil, err
+			}
+			if equal {
+				counts[i].count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			counts = append(counts, elementCount{item, 1})
+		}
+	}
+	return counts, nil
+}
+
+// Returns how many times x appears in counts
+func countOf(counts []elementCount, x py.Object) (int, error) {
+	for _, c := range counts {
+		equal, err := comparison(py.Eq)(c.element, x)
+		if err != nil {
+			return 0, err
+		}
+		if equal {
+			return c.count, nil
+		}
+	}
+	return 0, nil
+}
+
+func testCaseAssertCountEqual(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var first, second, msg py.Object = nil, nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:assertCountEqual", []string{"first", "second", "msg"}, &first, &second, &msg)
+	if err != nil {
+		return nil, err
+	}
+	firstCounts, err := countElements(first)
+	if err != nil {
+		return nil, err
+	}
+	secondCounts, err := countElements(second)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, c := range firstCounts {
+		n, err := countOf(secondCounts, c.element)
+		if err != nil {
+			return nil, err
+		}
+		if n != c.count {
+			lines = append(lines, fmt.Sprintf("First has %d, Second has %d:  %s", c.count, n, safeRepr(c.element)))
+		}
+	}
+	for _, c := range secondCounts {
+		n, err := countOf(firstCounts, c.element)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			lines = append(lines, fmt.Sprintf("First has 0, Second has %d:  %s", c.count, safeRepr(c.element)))
+		}
+	}
+	if len(lines) == 0 {
+		return py.None, nil
+	}
+	return nil, failWith(self, msg, "Element counts were not equal:\n"+strings.Join(lines, "\n"))
+}
+
+const assert_raises_context_doc = `A context manager used to implement TestCase.assertRaises* methods.`
+
+// assertRaisesContextClass is the context manager returned by
+// assertRaises when it isn't given a callable
+var assertRaisesContextClass = py.NewClass(py.TypeType, "unittest.case", "_AssertRaisesContext", assert_raises_context_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__enter__": py.NewGoMethod0("__enter__", func(self py.Object) (py.Object, error) {
+		return self, nil
+	}),
+	"__exit__": py.NewGoMethod("__exit__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var excType, excValue, tb py.Object
+		err := py.UnpackTuple(args, kwargs, "__exit__", 3, 3, &excType, &excValue, &tb)
+		if err != nil {
+			return nil, err
+		}
+		handled, err := assertRaisesExit(self, excType, excValue, "")
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(handled), nil
+	}),
+})
+
+// Returns the name of the expected exception or exceptions for a
+// failure message
+func expectedName(expected py.Object) string {
+	if name, err := py.GetAttrString(expected, "__name__"); err == nil {
+		if s, err := py.StrAsString(name); err == nil {
+			return s
+		}
+	}
+	str, err := py.StrAsString(expected)
+	if err != nil {
+		return safeRepr(expected)
+	}
+	return str
+}
+
+// Checks the exception which ended the with block or call, returning
+// whether it was expected so should be swallowed
+//
+// by is the name of the callable which should have raised it, if any.
+func assertRaisesExit(ctx, excType, excValue py.Object, by string) (bool, error) {
+	var attrs [4]py.Object
+	for i, name := range []string{"expected", "test_case", "expected_regex", "msg"} {
+		value, err := py.GetAttrString(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		attrs[i] = value
+	}
+	expected, testCase, regex, msg := attrs[0], attrs[1], attrs[2], attrs[3]
+	if excType == py.None {
+		standard := expectedName(expected) + " not raised"
+		if by != "" {
+			standard += " by " + by
+		}
+		return false, failWith(testCase, msg, standard)
+	}
+	subclass, err := callBuiltin("issubclass", excType, expected)
+	if err != nil {
+		return false, err
+	}
+	if subclass != py.True {
+		// Let an unexpected exception through
+		return false, nil
+	}
+	if _, err = py.SetAttrString(ctx, "exception", excValue); err != nil {
+		return false, err
+	}
+	if regex == py.None {
+		return true, nil
+	}
+	str, err := py.Str(excValue)
+	if err != nil {
+		return false, err
+	}
+	found, err := search(regex, str)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		pattern, err := py.StrAsString(patternOf(regex))
+		if err != nil {
+			return false, err
+		}
+		return false, failWith(testCase, msg, fmt.Sprintf("\"%s\" does not match \"%s\"", pattern, string(str.(py.String))))
+	}
+	return true, nil
+}
+
+// Implements assertRaises and assertRaisesRegex given the expected
+// exception, the regex or None and the arguments after them
+func assertRaises(self, expected, regex py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	ctx, err := py.Call(assertRaisesContextClass, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	kw := kwargs.Copy()
+	var msg py.Object = py.None
+	if len(args) == 0 {
+		if m, ok := kw["msg"]; ok {
+			msg = m
+			delete(kw, "msg")
+		}
+		if len(kw) != 0 {
+			for key := range kw {
+				return nil, py.ExceptionNewf(py.TypeError, "'%s' is an invalid keyword argument for this function", key)
+			}
+		}
+	}
+	attrs := py.StringDict{
+		"expected":       expected,
+		"test_case":      self,
+		"expected_regex": regex,
+		"msg":            msg,
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(ctx, name, value); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) == 0 {
+		return ctx, nil
+	}
+	callable := args[0]
+	by := safeRepr(callable)
+	if name, err := py.GetAttrString(callable, "__name__"); err == nil {
+		by, _ = py.StrAsString(name)
+	}
+	_, err = py.Call(callable, args[1:], kw)
+	if err == nil {
+		_, err = assertRaisesExit(ctx, py.None, py.None, by)
+		return nil, err
+	}
+	exc := excInfo(err)
+	handled, exitErr := assertRaisesExit(ctx, exc[0], exc[1], by)
+	if exitErr != nil {
+		return nil, exitErr
+	}
+	if !handled {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func testCaseAssertRaises(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "assertRaises() missing 1 required positional argument: 'expected_exception'")
+	}
+	return assertRaises(self, args[0], py.None, args[1:], kwargs)
+}
+
+func testCaseAssertRaisesRegex(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 2 {
+		return nil, py.ExceptionNewf(py.TypeError, "assertRaisesRegex() missing required positional arguments: 'expected_exception' and 'expected_regex'")
+	}
+	return assertRaises(self, args[0], args[1], args[2:], kwargs)
+}
+
+// Returns the type, value and traceback of the exception err as
+// sys.exc_info() would
+func excInfo(err error) py.Tuple {
+	exc, ok := err.(py.ExceptionInfo)
+	if !ok {
+		e := py.MakeException(err)
+		tb, _ := e.Traceback.(*py.Traceback)
+		exc = py.ExceptionInfo{Type: e.Base, Value: e, Traceback: tb}
+	}
+	var tb py.Object = py.None
+	if exc.Traceback != nil {
+		tb = exc.Traceback
+	}
+	return py.Tuple{exc.Type, exc.Value, tb}
+}
+
+// Returns the traceback of an exception given as a (type, value,
+// traceback) tuple as text
+func formatException(err py.Tuple) string {
+	exc := py.ExceptionInfo{Value: err[1]}
+	exc.Type, _ = err[0].(*py.Type)
+	exc.Traceback, _ = err[2].(*py.Traceback)
+	var buf bytes.Buffer
+	exc.TracebackDump(&buf)
+	return buf.String()
+}
+
+func testCaseInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var methodName py.Object = py.String("runTest")
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:TestCase", []string{"methodName"}, &methodName)
+	if err != nil {
+		return nil, err
+	}
+	name, err := py.StrAsString(methodName)
+	if err != nil {
+		return nil, err
+	}
+	var doc py.Object = py.None
+	method, err := py.GetAttrString(self, name)
+	if err == nil {
+		if doc, err = py.GetAttrString(method, "__doc__"); err != nil {
+			doc = py.None
+		}
+	} else if name != "runTest" {
+		return nil, py.ExceptionNewf(py.ValueError, "no such test method in %s: %s", safeRepr(self.Type()), name)
+	}
+	attrs := py.StringDict{
+		"_testMethodName": methodName,
+		"_testMethodDoc":  doc,
+		"_cleanups":       py.NewList(),
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// Returns the name of the test method
+func testMethodName(self py.Object) (string, error) {
+	name, err := py.GetAttrString(self, "_testMethodName")
+	if err != nil {
+		return "", err
+	}
+	return py.StrAsString(name)
+}
+
+// Returns the module and qualified name of a class joined by a dot
+func strclass(cls *py.Type) string {
+	name := cls.Name
+	if qualname, ok := cls.Dict["__qualname__"].(py.String); ok {
+		name = string(qualname)
+	}
+	if module, err := py.GetAttrString(cls, "__module__"); err == nil {
+		if s, ok := module.(py.String); ok {
+			return string(s) + "." + name
+		}
+	}
+	return name
+}
+
+func testCaseId(self py.Object) (py.Object, error) {
+	name, err := testMethodName(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(strclass(self.Type()) + "." + name), nil
+}
+
+func testCaseStr(self py.Object) (py.Object, error) {
+	name, err := testMethodName(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("%s (%s.%s)", name, strclass(self.Type()), name)), nil
+}
+
+func testCaseRepr(self py.Object) (py.Object, error) {
+	name, err := testMethodName(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("<%s testMethod=%s>", strclass(self.Type()), name)), nil
+}
+
+// Returns the first line of the docstring of the test method or None
+func testCaseShortDescription(self py.Object) (py.Object, error) {
+	doc, err := py.GetAttrString(self, "_testMethodDoc")
+	if err != nil {
+		return nil, err
+	}
+	s, ok := doc.(py.String)
+	if !ok {
+		return py.None, nil
+	}
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(string(s)), "\n", 2)[0])
+	if line == "" {
+		return py.None, nil
+	}
+	return py.String(line), nil
+}
+
+// Returns whether obj has a true attribute called name
+func hasTrueAttr(obj py.Object, name string) bool {
+	value, err := py.GetAttrString(obj, name)
+	return err == nil && py.IsTrue(value)
+}
+
+// Returns the reason a skipped class or method was skipped
+func skipReason(obj py.Object) py.Object {
+	if why, err := py.GetAttrString(obj, "__unittest_skip_why__"); err == nil {
+		return why
+	}
+	return py.String("")
+}
+
+// Passes the outcome of part of a test to the result, returning an
+// error if the test run should stop
+//
+// inBody is set for errors from the test method itself, which are
+// expected failures if the test is marked with expectedFailure.
+func addOutcome(self, result py.Object, err error, inBody, expecting bool) error {
+	if py.IsException(py.KeyboardInterrupt, err) {
+		return err
+	}
+	info := excInfo(err)
+	if py.IsException(SkipTest, err) {
+		reason, strErr := py.Str(info[1])
+		if strErr != nil {
+			return strErr
+		}
+		_, err = py.CallMethodByName(result, "addSkip", self, reason)
+		return err
+	}
+	if inBody && expecting {
+		_, err = py.CallMethodByName(result, "addExpectedFailure", self, info)
+		return err
+	}
+	failureException, attrErr := py.GetAttrString(self, "failureException")
+	if attrErr != nil {
+		return attrErr
+	}
+	if cls, ok := failureException.(*py.Type); ok && py.IsException(cls, err) {
+		_, err = py.CallMethodByName(result, "addFailure", self, info)
+	} else {
+		_, err = py.CallMethodByName(result, "addError", self, info)
+	}
+	return err
+}
+
+// Calls the cleanups of the test case in the reverse of the order they
+// were added, passing any errors to the result if it isn't nil
+func doCleanups(self, result py.Object) error {
+	cleanups, err := py.GetAttrString(self, "_cleanups")
+	if err != nil {
+		return err
+	}
+	list, ok := cleanups.(*py.List)
+	if !ok {
+		return nil
+	}
+	var firstErr error
+	for len(list.Items) > 0 {
+		n := len(list.Items) - 1
+		cleanup := list.Items[n].(py.Tuple)
+		list.Items = list.Items[:n]
+		kwargs, err := py.DictAsNamespace(cleanup[2])
+		if err != nil {
+			return err
+		}
+		if _, err = py.Call(cleanup[0], cleanup[1].(py.Tuple), kwargs); err != nil {
+			if result == nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if err = addOutcome(self, result, err, false, false); err != nil {
+				return err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Runs the test, returning whether it succeeded
+func runTest(self, result py.Object) error {
+	if _, err := py.CallMethodByName(result, "startTest", self); err != nil {
+		return err
+	}
+	err := runTestParts(self, result)
+	if _, stopErr := py.CallMethodByName(result, "stopTest", self); err == nil {
+		err = stopErr
+	}
+	return err
+}
+
+// Runs setUp, the test method, tearDown and the cleanups passing the
+// outcome to the result
+func runTestParts(self, result py.Object) error {
+	name, err := testMethodName(self)
+	if err != nil {
+		return err
+	}
+	method, err := py.GetAttrString(self, name)
+	if err != nil {
+		return err
+	}
+	// The decorators mark the function rather than the bound method
+	fn := method
+	if bound, ok := method.(*py.BoundMethod); ok {
+		fn = bound.Method
+	}
+	cls := self.Type()
+	if hasTrueAttr(cls, "__unittest_skip__") || hasTrueAttr(fn, "__unittest_skip__") {
+		reason := skipReason(fn)
+		if hasTrueAttr(cls, "__unittest_skip__") {
+			reason = skipReason(cls)
+		}
+		_, err = py.CallMethodByName(result, "addSkip", self, reason)
+		return err
+	}
+	expecting := hasTrueAttr(fn, "__unittest_expecting_failure__") || hasTrueAttr(self, "_expecting_failure")
+	success := true
+	if _, err = py.CallMethodByName(self, "setUp"); err != nil {
+		success = false
+		if err = addOutcome(self, result, err, false, expecting); err != nil {
+			return err
+		}
+	} else {
+		if _, err = py.Call(method, nil, nil); err != nil {
+			success = false
+			if err = addOutcome(self, result, err, true, expecting); err != nil {
+				return err
+			}
+		}
+		if _, err = py.CallMethodByName(self, "tearDown"); err != nil {
+			success = false
+			if err = addOutcome(self, result, err, false, expecting); err != nil {
+				return err
+			}
+		}
+	}
+	errors, err := py.GetAttrString(result, "errors")
+	if err != nil {
+		return err
+	}
+	before, err := py.Len(errors)
+	if err != nil {
+		return err
+	}
+	if err = doCleanups(self, result); err != nil {
+		return err
+	}
+	after, err := py.Len(errors)
+	if err != nil {
+		return err
+	}
+	if !success || after != before {
+		return nil
+	}
+	if expecting {
+		_, err = py.CallMethodByName(result, "addUnexpectedSuccess", self)
+	} else {
+		_, err = py.CallMethodByName(result, "addSuccess", self)
+	}
+	return err
+}
+
+func testCaseRun(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var result py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:run", []string{"result"}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result != py.None {
+		return result, runTest(self, result)
+	}
+	if result, err = py.CallMethodByName(self, "defaultTestResult"); err != nil {
+		return nil, err
+	}
+	if _, err = py.CallMethodByName(result, "startTestRun"); err != nil {
+		return nil, err
+	}
+	if err = runTest(self, result); err != nil {
+		return nil, err
+	}
+	_, err = py.CallMethodByName(result, "stopTestRun")
+	return result, err
+}
+
+// Runs the test without collecting the result so exceptions are raised
+func testCaseDebug(self py.Object) (py.Object, error) {
+	name, err := testMethodName(self)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = py.CallMethodByName(self, "setUp"); err != nil {
+		return nil, err
+	}
+	if _, err = py.CallMethodByName(self, name); err != nil {
+		return nil, err
+	}
+	if _, err = py.CallMethodByName(self, "tearDown"); err != nil {
+		return nil, err
+	}
+	return py.None, doCleanups(self, nil)
+}
@@ -0,0 +1,343 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import unittest
+import io
+from libtest import *
+
+def run(*classes, verbosity=1, failfast=False):
+    """Runs the tests in classes returning the result and the output"""
+    loader = unittest.TestLoader()
+    suite = unittest.TestSuite([loader.loadTestsFromTestCase(cls) for cls in classes])
+    stream = io.StringIO()
+    runner = unittest.TextTestRunner(stream=stream, verbosity=verbosity, failfast=failfast)
+    result = runner.run(suite)
+    return result, stream.getvalue()
+
+def failure(fn, *args, **kwargs):
+    """Returns the message of the AssertionError fn raises"""
+    try:
+        fn(*args, **kwargs)
+    except AssertionError as e:
+        return str(e)
+    assert False, "AssertionError not raised"
+
+doc = "module"
+assert unittest.TestCase.__module__ == "unittest.case"
+assert unittest.main is unittest.TestProgram
+assert issubclass(unittest.SkipTest, Exception)
+assert isinstance(unittest.defaultTestLoader, unittest.TestLoader)
+
+doc = "assertions which pass"
+class Dummy(unittest.TestCase):
+    def runTest(self):
+        pass
+t = Dummy()
+t.assertEqual(1, 1)
+t.assertNotEqual(1, 2)
+t.assertTrue([1])
+t.assertFalse({})
+t.assertIs(None, None)
+t.assertIsNot(1, None)
+t.assertIsNone(None)
+t.assertIsNotNone(0)
+t.assertIn(2, [1, 2])
+t.assertNotIn(3, (1, 2))
+t.assertIsInstance(1, int)
+t.assertIsInstance(1, (str, int))
+t.assertNotIsInstance(1, str)
+t.assertGreater(2, 1)
+t.assertGreaterEqual(2, 2)
+t.assertLess(1, 2)
+t.assertLessEqual(2, 2)
+t.assertAlmostEqual(1.0, 1.00000001)
+t.assertAlmostEqual(1.0, 1.1, places=0)
+t.assertAlmostEqual(1.0, 1.5, delta=0.5)
+t.assertNotAlmostEqual(1.0, 1.1)
+t.assertCountEqual([1, 2, 2, "a"], ["a", 2, 1, 2])
+t.assertRegex("hello world", r"w.r")
+t.assertNotRegex("hello", r"\d")
+t.assertListEqual([1], [1])
+t.assertDictEqual({"a": 1}, {"a": 1})
+t.assertEquals(1, 1)
+
+doc = "assertion failure messages"
+assert failure(t.assertEqual, 1, 2) == "1 != 2"
+assert failure(t.assertEqual, "a", "b") == "'a' != 'b'"
+assert failure(t.assertNotEqual, 1, 1) == "1 == 1"
+assert failure(t.assertTrue, 0) == "0 is not true"
+assert failure(t.assertFalse, [1]) == "[1] is not false"
+assert failure(t.assertIs, 1, None) == "1 is not None"
+assert failure(t.assertIsNot, None, None) == "unexpectedly identical: None"
+assert failure(t.assertIsNone, 1) == "1 is not None"
+assert failure(t.assertIsNotNone, None) == "unexpectedly None"
+assert failure(t.assertIn, 3, [1, 2]) == "3 not found in [1, 2]"
+assert failure(t.assertNotIn, 1, [1, 2]) == "1 unexpectedly found in [1, 2]"
+assert failure(t.assertIsInstance, 1, str) == "1 is not an instance of <class 'str'>"
+assert failure(t.assertNotIsInstance, 1, int) == "1 is an instance of <class 'int'>"
+assert failure(t.assertGreater, 1, 2) == "1 not greater than 2"
+assert failure(t.assertGreaterEqual, 1, 2) == "1 not greater than or equal to 2"
+assert failure(t.assertLess, 2, 1) == "2 not less than 1"
+assert failure(t.assertLessEqual, 2, 1) == "2 not less than or equal to 1"
+assert failure(t.assertAlmostEqual, 1.0, 1.1, places=3) == "1.0 != 1.1 within 3 places (0.10000000000000009 difference)"
+assert failure(t.assertAlmostEqual, 1, 3, delta=1) == "1 != 3 within 1 delta (2 difference)"
+assert failure(t.assertNotAlmostEqual, 1, 1) == "1 == 1 within 7 places"
+assert failure(t.assertRegex, "abc", "x") == "Regex didn't match: 'x' not found in 'abc'"
+assert failure(t.assertCountEqual, [1, 1], [1]).startswith("Element counts were not equal:")
+assert failure(t.fail) == "None"
+assert failure(t.fail, "boom") == "boom"
+assertRaises(TypeError, t.assertAlmostEqual, 1, 2, places=1, delta=1)
+
+doc = "long messages"
+assert failure(t.assertEqual, 1, 2, "numbers") == "1 != 2 : numbers"
+assert failure(t.assertTrue, False, msg="flag") == "False is not true : flag"
+t.longMessage = False
+assert failure(t.assertEqual, 1, 2, "numbers") == "numbers"
+assert failure(t.assertEqual, 1, 2) == "1 != 2"
+t.longMessage = True
+
+doc = "assertRaises"
+t.assertRaises(ZeroDivisionError, lambda: 1/0)
+t.assertRaises((KeyError, ZeroDivisionError), lambda: 1/0)
+def boom(x, y=0):
+    raise ValueError("bad %s %s" % (x, y))
+t.assertRaises(ValueError, boom, 1, y=2)
+assert failure(t.assertRaises, ValueError, len, "") == "ValueError not raised by len"
+assertRaises(KeyError, t.assertRaises, ValueError, lambda: {}[1])
+with t.assertRaises(KeyError) as cm:
+    {}["k"]
+assert isinstance(cm.exception, KeyError)
+assert cm.exception.args == ("k",)
+try:
+    with t.assertRaises(KeyError):
+        pass
+except AssertionError as e:
+    assert str(e) == "KeyError not raised"
+else:
+    assert False, "AssertionError not raised"
+try:
+    with t.assertRaises(KeyError, msg="lookup"):
+        pass
+except AssertionError as e:
+    assert str(e) == "KeyError not raised : lookup"
+else:
+    assert False, "AssertionError not raised"
+t.assertRaisesRegex(ValueError, r"bad 1 \d", boom, 1, 2)
+with t.assertRaisesRegex(ValueError, "^bad"):
+    boom(3)
+assert failure(t.assertRaisesRegex, ValueError, "good", boom, 1) == '"good" does not match "bad 1 0"'
+
+doc = "test case names"
+class Names(unittest.TestCase):
+    def test_one(self):
+        """First line of doc
+
+        More doc"""
+    def test_two(self):
+        pass
+n = Names("test_one")
+assert n.id() == "__main__.Names.test_one"
+assert str(n) == "test_one (__main__.Names.test_one)"
+assert repr(n) == "<__main__.Names testMethod=test_one>"
+assert n.shortDescription() == "First line of doc"
+assert Names("test_two").shortDescription() is None
+assert n.countTestCases() == 1
+assertRaisesText(ValueError, "no such test method in", Names, "test_three")
+
+doc = "loader"
+loader = unittest.TestLoader()
+assert loader.getTestCaseNames(Names) == ["test_one", "test_two"]
+suite = loader.loadTestsFromTestCase(Names)
+assert isinstance(suite, unittest.TestSuite)
+assert suite.countTestCases() == 2
+assert [test.id() for test in suite] == ["__main__.Names.test_one", "__main__.Names.test_two"]
+loader.testMethodPrefix = "test_t"
+assert loader.getTestCaseNames(Names) == ["test_two"]
+assertRaises(TypeError, loader.loadTestsFromTestCase, unittest.TestSuite)
+
+doc = "suite"
+suite = unittest.TestSuite()
+assert suite.countTestCases() == 0
+suite.addTest(Names("test_one"))
+suite.addTests([Names("test_two"), unittest.TestSuite([Names("test_one")])])
+assert suite.countTestCases() == 3
+assertRaisesText(TypeError, "must be instantiated", suite.addTest, Names)
+assertRaisesText(TypeError, "is not callable", suite.addTest, 1)
+assertRaisesText(TypeError, "not a string", suite.addTests, "test_one")
+
+doc = "running passing tests"
+calls = []
+class Passing(unittest.TestCase):
+    @classmethod
+    def setUpClass(cls):
+        calls.append("setUpClass")
+    @classmethod
+    def tearDownClass(cls):
+        calls.append("tearDownClass")
+    def setUp(self):
+        calls.append("setUp")
+        self.addCleanup(calls.append, "cleanup")
+    def tearDown(self):
+        calls.append("tearDown")
+    def test_a(self):
+        calls.append("a")
+    def test_b(self):
+        calls.append("b")
+result, output = run(Passing)
+assert calls == ["setUpClass", "setUp", "a", "tearDown", "cleanup", "setUp", "b", "tearDown", "cleanup", "tearDownClass"], calls
+assert result.testsRun == 2
+assert result.wasSuccessful()
+lines = output.split("\n")
+assert lines[0] == ".."
+assert lines[1] == "-" * 70
+assert lines[2].startswith("Ran 2 tests in ")
+assert lines[4] == "OK"
+assert repr(result) == "<unittest.runner.TextTestResult run=2 errors=0 failures=0>"
+
+doc = "running failing tests"
+class Mixed(unittest.TestCase):
+    def test_1_fail(self):
+        self.assertEqual(1, 2)
+    def test_2_error(self):
+        raise KeyError("oops")
+    @unittest.skip("not today")
+    def test_3_skip(self):
+        raise KeyError("should not run")
+    def test_4_skipTest(self):
+        self.skipTest("later")
+    @unittest.expectedFailure
+    def test_5_expected(self):
+        self.fail("known")
+    @unittest.expectedFailure
+    def test_6_unexpected(self):
+        pass
+    @unittest.skipIf(True, "if")
+    def test_7_skipIf(self):
+        pass
+    @unittest.skipUnless(True, "unless")
+    def test_8_skipUnless(self):
+        pass
+result, output = run(Mixed)
+assert result.testsRun == 8
+assert not result.wasSuccessful()
+assert len(result.failures) == 1
+assert len(result.errors) == 1
+assert [(test.id().split(".")[-1], reason) for test, reason in result.skipped] == [("test_3_skip", "not today"), ("test_4_skipTest", "later"), ("test_7_skipIf", "if")]
+assert len(result.expectedFailures) == 1
+assert [test.id().split(".")[-1] for test in result.unexpectedSuccesses] == ["test_6_unexpected"]
+test, tb = result.failures[0]
+assert test.id() == "__main__.Mixed.test_1_fail"
+assert tb.startswith("Traceback (most recent call last):\n")
+assert tb.endswith("AssertionError: 1 != 2\n"), tb
+test, tb = result.errors[0]
+assert tb.endswith("KeyError: 'oops'\n"), tb
+assert output.startswith("FEssxus.\n"), output
+assert "=" * 70 + "\nFAIL: test_1_fail (__main__.Mixed.test_1_fail)\n" + "-" * 70 + "\n" in output
+assert "=" * 70 + "\nERROR: test_2_error (__main__.Mixed.test_2_error)\n" + "-" * 70 + "\n" in output
+assert output.endswith("FAILED (failures=1, errors=1, skipped=3, expected failures=1, unexpected successes=1)\n"), output
+
+doc = "verbose output"
+class Verbose(unittest.TestCase):
+    def test_ok(self):
+        "Says ok"
+    @unittest.skip("no")
+    def test_skip(self):
+        pass
+result, output = run(Verbose, verbosity=2)
+lines = output.split("\n")
+assert lines[0] == "test_ok (__main__.Verbose.test_ok)", lines
+assert lines[1] == "Says ok ... ok", lines
+assert lines[2] == "test_skip (__main__.Verbose.test_skip) ... skipped 'no'", lines
+assert output.endswith("OK (skipped=1)\n"), output
+
+doc = "quiet output"
+result, output = run(Passing, verbosity=0)
+assert output.startswith("-" * 70 + "\nRan 2 tests in "), output
+
+doc = "failfast"
+class FailFast(unittest.TestCase):
+    def test_1(self):
+        self.fail()
+    def test_2(self):
+        pass
+result, output = run(FailFast, failfast=True)
+assert result.testsRun == 1
+assert result.shouldStop
+
+doc = "setUp errors and cleanups"
+calls = []
+class BadSetUp(unittest.TestCase):
+    def setUp(self):
+        self.addCleanup(calls.append, "cleanup")
+        raise ValueError("setUp")
+    def tearDown(self):
+        calls.append("tearDown")
+    def test_it(self):
+        calls.append("test")
+result, output = run(BadSetUp)
+assert calls == ["cleanup"], calls
+assert len(result.errors) == 1
+
+doc = "class skip and setUpClass failure"
+@unittest.skip("whole class")
+class Skipped(unittest.TestCase):
+    def test_a(self):
+        raise KeyError()
+class BadClass(unittest.TestCase):
+    @classmethod
+    def setUpClass(cls):
+        raise RuntimeError("class")
+    def test_a(self):
+        pass
+result, output = run(Skipped, BadClass)
+assert result.testsRun == 1
+assert len(result.skipped) == 1
+assert len(result.errors) == 1
+assert str(result.errors[0][0]) == "setUpClass (__main__.BadClass)"
+
+doc = "TestCase.run and debug"
+class Simple(unittest.TestCase):
+    def test_fail(self):
+        self.assertTrue(False)
+result = Simple("test_fail").run()
+assert isinstance(result, unittest.TestResult)
+assert result.testsRun == 1
+assert len(result.failures) == 1
+assertRaises(AssertionError, Simple("test_fail").debug)
+result = unittest.TestResult()
+Simple("test_fail")(result)
+assert len(result.failures) == 1
+assert repr(result) == "<unittest.result.TestResult run=1 errors=0 failures=1>"
+
+doc = "custom failureException"
+class MyFailure(Exception):
+    pass
+class Custom(unittest.TestCase):
+    failureException = MyFailure
+    def test_it(self):
+        self.assertEqual(1, 2)
+result, output = run(Custom)
+assert len(result.failures) == 1
+assert result.failures[0][1].endswith("MyFailure: 1 != 2\n")
+
+doc = "main"
+class MainTests(unittest.TestCase):
+    def test_pass(self):
+        pass
+    def test_fail(self):
+        self.fail("main")
+stream = io.StringIO()
+prog = unittest.main(argv=["prog", "MainTests.test_pass"], exit=False, testRunner=unittest.TextTestRunner(stream=stream))
+assert prog.result.testsRun == 1
+assert prog.result.wasSuccessful()
+try:
+    unittest.main(argv=["prog", "-q", "MainTests"], testRunner=unittest.TextTestRunner(stream=io.StringIO()))
+except SystemExit as e:
+    assert e.args == (True,), e.args
+else:
+    assert False, "SystemExit not raised"
+prog = unittest.main(defaultTest="MainTests.test_fail", argv=["prog"], exit=False, testRunner=unittest.TextTestRunner(stream=io.StringIO()))
+assert len(prog.result.failures) == 1
+
+doc = "finished"
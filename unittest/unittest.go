@@ -0,0 +1,421 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Unittest module
+//
+// TestCase, TestSuite, TestLoader, TestResult and the runner are
+// python classes made in Go so test suites written for CPython can
+// subclass them and override their methods as they would there.  Their
+// state is kept in the instance dictionaries under the same names as
+// CPython uses.
+
+package unittest
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/go-python/gpython/py"
+)
+
+const module_doc = `Python unit testing framework, based on Erich Gamma's JUnit and Kent Beck's
+Smalltalk testing framework.
+
+This module contains the core framework classes that form the basis of
+specific test cases and suites (TestCase, TestSuite etc.), and also a
+text-based utility class for running the tests and reporting the results
+ (TextTestRunner).
+
+Simple usage:
+
+    import unittest
+
+    class IntegerArithmeticTestCase(unittest.TestCase):
+        def testAdd(self):  # test method names begin with 'test'
+            self.assertEqual((1 + 2), 3)
+            self.assertEqual(0 + 1, 1)
+        def testMultiply(self):
+            self.assertEqual((0 * 10), 0)
+            self.assertEqual((5 * 8), 40)
+
+    if __name__ == '__main__':
+        unittest.main()`
+
+// SkipTest is raised to skip a test
+var SkipTest = py.ExceptionType.NewType("SkipTest", `Raise this exception in a test to skip it.
+
+Usually you can use TestCase.skipTest() or one of the skipping decorators
+instead of raising this directly.`, nil, nil)
+
+// ------------------------------------------------------------
+// Decorators
+
+// Makes a decorator which marks the test method or class it decorates
+// as skipped for reason
+func skipDecorator(reason py.Object) *py.Method {
+	return py.MustNewMethod("decorator", func(self, testItem py.Object) (py.Object, error) {
+		if _, err := py.SetAttrString(testItem, "__unittest_skip__", py.True); err != nil {
+			return nil, err
+		}
+		if _, err := py.SetAttrString(testItem, "__unittest_skip_why__", reason); err != nil {
+			return nil, err
+		}
+		return testItem, nil
+	}, 0, "Mark the test as skipped.")
+}
+
+// Returns a decorator which leaves the test method or class alone
+func identityDecorator() *py.Method {
+	return py.MustNewMethod("decorator", func(self, testItem py.Object) (py.Object, error) {
+		return testItem, nil
+	}, 0, "Leave the test alone.")
+}
+
+const skip_doc = `Unconditionally skip a test.`
+
+func unittest_skip(self py.Object, reason py.Object) (py.Object, error) {
+	// Used as @skip without a reason
+	if _, ok := reason.(*py.Function); ok {
+		return py.Call(skipDecorator(py.String("")), py.Tuple{reason}, nil)
+	}
+	return skipDecorator(reason), nil
+}
+
+const skip_if_doc = `Skip a test if the condition is true.`
+
+func unittest_skipIf(self py.Object, args py.Tuple) (py.Object, error) {
+	var condition, reason py.Object
+	err := py.UnpackTuple(args, nil, "skipIf", 2, 2, &condition, &reason)
+	if err != nil {
+		return nil, err
+	}
+	if py.IsTrue(condition) {
+		return skipDecorator(reason), nil
+	}
+	return identityDecorator(), nil
+}
+
+const skip_unless_doc = `Skip a test unless the condition is true.`
+
+func unittest_skipUnless(self py.Object, args py.Tuple) (py.Object, error) {
+	var condition, reason py.Object
+	err := py.UnpackTuple(args, nil, "skipUnless", 2, 2, &condition, &reason)
+	if err != nil {
+		return nil, err
+	}
+	if !py.IsTrue(condition) {
+		return skipDecorator(reason), nil
+	}
+	return identityDecorator(), nil
+}
+
+const expected_failure_doc = `Mark the test as expected to fail.
+
+The test is reported as an expected failure if it fails and as an
+unexpected success if it passes.`
+
+func unittest_expectedFailure(self py.Object, testItem py.Object) (py.Object, error) {
+	if _, err := py.SetAttrString(testItem, "__unittest_expecting_failure__", py.True); err != nil {
+		return nil, err
+	}
+	return testItem, nil
+}
+
+// ------------------------------------------------------------
+// TestProgram
+
+const test_program_doc = `A command-line program that runs a set of tests; this is primarily
+for making test modules conveniently executable.
+
+The tests are found in module, which may be a module or the name of
+one, '__main__' by default.  The names of the tests to run may be
+given on the command line in argv, otherwise defaultTest is run, or if
+that is None all the tests in the module.  Unless exit is False,
+SystemExit is raised when the tests have run with a status saying
+whether they succeeded.`
+
+// TestProgramClass is the class of unittest.main
+var TestProgramClass = py.NewClass(py.TypeType, "unittest.main", "TestProgram", test_program_doc, py.Tuple{py.ObjectType}, py.StringDict{
+	"__init__":    py.NewGoMethod("__init__", testProgramInit),
+	"parseArgs":   py.NewGoMethod1("parseArgs", testProgramParseArgs),
+	"createTests": py.NewGoMethod0("createTests", testProgramCreateTests),
+	"runTests":    py.NewGoMethod0("runTests", testProgramRunTests),
+	"usageExit": py.NewGoMethod("usageExit", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var msg py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:usageExit", []string{"msg"}, &msg)
+		if err != nil {
+			return nil, err
+		}
+		return nil, usageExit(self, msg)
+	}),
+})
+
+func testProgramInit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var module, defaultTest, argv, testRunner, testLoader, exit, verbosity, failfast py.Object = py.String("__main__"), py.None, py.None, py.None, py.None, py.True, py.Int(1), py.None
+	kwlist := []string{"module", "defaultTest", "argv", "testRunner", "testLoader", "exit", "verbosity", "failfast"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOOOO:TestProgram", kwlist, &module, &defaultTest, &argv, &testRunner, &testLoader, &exit, &verbosity, &failfast)
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := module.(py.String); ok {
+		if module, err = py.CurrentContext.GetModule(string(name)); err != nil {
+			if _, err = py.ImportModuleLevelObject(string(name), nil, nil, nil, 0); err != nil {
+				return nil, err
+			}
+			if module, err = py.CurrentContext.GetModule(string(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if argv == py.None {
+		if argv, err = sysStream("argv"); err != nil {
+			return nil, err
+		}
+		if argv == py.None {
+			argv = py.NewList()
+		}
+	}
+	if testLoader == py.None {
+		unittest, err := py.CurrentContext.GetModule("unittest")
+		if err != nil {
+			return nil, err
+		}
+		testLoader = unittest.Globals["defaultTestLoader"]
+	}
+	if _, ok := defaultTest.(py.String); ok {
+		defaultTest = py.Tuple{defaultTest}
+	}
+	attrs := py.StringDict{
+		"module":      module,
+		"exit":        exit,
+		"failfast":    failfast,
+		"verbosity":   verbosity,
+		"defaultTest": defaultTest,
+		"testRunner":  testRunner,
+		"testLoader":  testLoader,
+		"progName":    py.None,
+		"testNames":   py.None,
+		"result":      py.None,
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = py.CallMethodByName(self, "parseArgs", argv); err != nil {
+		return nil, err
+	}
+	_, err = py.CallMethodByName(self, "runTests")
+	return py.None, err
+}
+
+// Prints the usage, with msg if it isn't None, then exits with status 2
+func usageExit(self, msg py.Object) error {
+	progName, err := py.GetAttrString(self, "progName")
+	if err != nil {
+		return err
+	}
+	prog, err := py.StrAsString(progName)
+	if err != nil {
+		return err
+	}
+	stderr, err := sysStream("stderr")
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("usage: %s [-h] [-v] [-q] [-f] [tests ...]\n", prog)
+	if msg != py.None {
+		msgStr, err := py.StrAsString(msg)
+		if err != nil {
+			return err
+		}
+		text += fmt.Sprintf("%s: error: %s\n", prog, msgStr)
+	}
+	if err = write(stderr, text); err != nil {
+		return err
+	}
+	return raise(py.SystemExit, py.Int(2))
+}
+
+const usage = `positional arguments:
+  tests           a list of any number of test modules, classes and test
+                  methods.
+
+options:
+  -h, --help      show this help message and exit
+  -v, --verbose   Verbose output
+  -q, --quiet     Quiet output
+  -f, --failfast  Stop on first fail or error
+`
+
+func testProgramParseArgs(self, argv py.Object) (py.Object, error) {
+	args, err := py.SequenceList(argv)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(args.Items))
+	for i, arg := range args.Items {
+		if strs[i], err = py.StrAsString(arg); err != nil {
+			return nil, err
+		}
+	}
+	prog := "python -m unittest"
+	if len(strs) > 0 && strs[0] != "" {
+		prog = path.Base(strs[0])
+	}
+	if _, err = py.SetAttrString(self, "progName", py.String(prog)); err != nil {
+		return nil, err
+	}
+	attrs := py.StringDict{}
+	testNames := py.NewList()
+	if len(strs) > 0 {
+		strs = strs[1:]
+	}
+	for _, arg := range strs {
+		switch arg {
+		case "-v", "--verbose":
+			attrs["verbosity"] = py.Int(2)
+		case "-q", "--quiet":
+			attrs["verbosity"] = py.Int(0)
+		case "-f", "--failfast":
+			attrs["failfast"] = py.True
+		case "-h", "--help":
+			stdout, err := sysStream("stdout")
+			if err != nil {
+				return nil, err
+			}
+			err = write(stdout, fmt.Sprintf("usage: %s [-h] [-v] [-q] [-f] [tests ...]\n\n%s", prog, usage))
+			if err != nil {
+				return nil, err
+			}
+			return nil, raise(py.SystemExit, py.Int(0))
+		default:
+			if len(arg) > 1 && arg[0] == '-' {
+				return nil, usageExit(self, py.String("unrecognized arguments: "+arg))
+			}
+			testNames.Append(py.String(arg))
+		}
+	}
+	if len(testNames.Items) != 0 {
+		attrs["testNames"] = testNames
+	} else if defaultTest, err := py.GetAttrString(self, "defaultTest"); err != nil {
+		return nil, err
+	} else if defaultTest != py.None {
+		if attrs["testNames"], err = py.SequenceList(defaultTest); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range attrs {
+		if _, err = py.SetAttrString(self, name, value); err != nil {
+			return nil, err
+		}
+	}
+	_, err = py.CallMethodByName(self, "createTests")
+	return py.None, err
+}
+
+func testProgramCreateTests(self py.Object) (py.Object, error) {
+	var attrs [3]py.Object
+	for i, name := range []string{"testLoader", "testNames", "module"} {
+		value, err := py.GetAttrString(self, name)
+		if err != nil {
+			return nil, err
+		}
+		attrs[i] = value
+	}
+	loader, testNames, module := attrs[0], attrs[1], attrs[2]
+	var test py.Object
+	var err error
+	if testNames == py.None {
+		test, err = py.CallMethodByName(loader, "loadTestsFromModule", module)
+	} else {
+		test, err = py.CallMethodByName(loader, "loadTestsFromNames", testNames, module)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.SetAttrString(self, "test", test)
+	return py.None, err
+}
+
+func testProgramRunTests(self py.Object) (py.Object, error) {
+	testRunner, err := py.GetAttrString(self, "testRunner")
+	if err != nil {
+		return nil, err
+	}
+	if testRunner == py.None {
+		testRunner = TextTestRunnerClass
+	}
+	if _, ok := testRunner.(*py.Type); ok && testRunner.Type().IsSubtype(py.TypeType) {
+		kwargs := py.StringDict{}
+		for _, name := range []string{"verbosity", "failfast"} {
+			value, err := py.GetAttrString(self, name)
+			if err != nil {
+				return nil, err
+			}
+			if value != py.None {
+				kwargs[name] = value
+			}
+		}
+		if testRunner, err = py.Call(testRunner, nil, kwargs); err != nil {
+			return nil, err
+		}
+	}
+	test, err := py.GetAttrString(self, "test")
+	if err != nil {
+		return nil, err
+	}
+	result, err := py.CallMethodByName(testRunner, "run", test)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = py.SetAttrString(self, "result", result); err != nil {
+		return nil, err
+	}
+	if !hasTrueAttr(self, "exit") {
+		return py.None, nil
+	}
+	successful, err := py.CallMethodByName(result, "wasSuccessful")
+	if err != nil {
+		return nil, err
+	}
+	return nil, raise(py.SystemExit, py.NewBool(!py.IsTrue(successful)))
+}
+
+// Makes the default loader for each context
+func unittestInit(ctx *py.Context, m *py.Module) error {
+	loader, err := py.Call(TestLoaderClass, nil, nil)
+	if err != nil {
+		return err
+	}
+	m.Globals["defaultTestLoader"] = loader
+	return nil
+}
+
+func init() {
+	methods := []*py.Method{
+		py.MustNewMethod("skip", unittest_skip, 0, skip_doc),
+		py.MustNewMethod("skipIf", unittest_skipIf, 0, skip_if_doc),
+		py.MustNewMethod("skipUnless", unittest_skipUnless, 0, skip_unless_doc),
+		py.MustNewMethod("expectedFailure", unittest_expectedFailure, 0, expected_failure_doc),
+	}
+	globals := py.StringDict{
+		"TestCase":       TestCaseClass,
+		"TestSuite":      TestSuiteClass,
+		"TestLoader":     TestLoaderClass,
+		"TestResult":     TestResultClass,
+		"TextTestResult": TextTestResultClass,
+		"TextTestRunner": TextTestRunnerClass,
+		"TestProgram":    TestProgramClass,
+		"main":           TestProgramClass,
+		"SkipTest":       SkipTest,
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "unittest",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+		Init:    unittestInit,
+	})
+}
@@ -0,0 +1,17 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unittest_test
+
+import (
+	"testing"
+
+	_ "github.com/go-python/gpython/io"
+	"github.com/go-python/gpython/pytest"
+	_ "github.com/go-python/gpython/re"
+)
+
+func TestUnittest(t *testing.T) {
+	pytest.RunTests(t, "tests")
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package urllib
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+const error_doc = `Exception classes raised by urllib.
+
+URLError is raised when a URL can't be opened and HTTPError, a
+subclass of it, when the server returns an error status.  An
+HTTPError can be read like the response it was made from.`
+
+var (
+	URLError  = py.OSError.NewType("URLError", "Raised when a URL can't be opened.", nil, nil)
+	HTTPError = URLError.NewType("HTTPError", "Raised for a response with an error status.", nil, nil)
+)
+
+// Returns the exception field which is args[i] or None
+func errorField(self py.Object, i int) py.Object {
+	e := self.(*py.Exception)
+	if args, ok := e.Args.(py.Tuple); ok && i < len(args) {
+		return args[i]
+	}
+	return py.None
+}
+
+// Adds a property for the exception field which is args[i]
+func addErrorField(t *py.Type, i int, name string) {
+	t.Dict[name] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return errorField(self, i), nil
+		},
+	}
+}
+
+func init() {
+	addErrorField(URLError, 0, "reason")
+	URLError.Dict["__str__"] = py.MustNewMethod("__str__", func(self py.Object) (py.Object, error) {
+		reason, err := py.StrAsString(errorField(self, 0))
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("<urlopen error %s>", reason)), nil
+	}, 0, "Return str(self).")
+
+	// HTTPError(url, code, msg, hdrs, fp)
+	addErrorField(HTTPError, 0, "url")
+	addErrorField(HTTPError, 0, "filename")
+	addErrorField(HTTPError, 1, "code")
+	addErrorField(HTTPError, 2, "msg")
+	addErrorField(HTTPError, 2, "reason")
+	addErrorField(HTTPError, 3, "hdrs")
+	addErrorField(HTTPError, 3, "headers")
+	addErrorField(HTTPError, 4, "fp")
+	HTTPError.Dict["__str__"] = py.MustNewMethod("__str__", func(self py.Object) (py.Object, error) {
+		code, err := py.StrAsString(errorField(self, 1))
+		if err != nil {
+			return nil, err
+		}
+		msg, err := py.StrAsString(errorField(self, 2))
+		if err != nil {
+			return nil, err
+		}
+		return py.String(fmt.Sprintf("HTTP Error %s: %s", code, msg)), nil
+	}, 0, "Return str(self).")
+	// The response methods are passed to fp
+	for _, name := range []string{"read", "getcode", "geturl", "info", "close"} {
+		name := name
+		HTTPError.Dict[name] = py.MustNewMethod(name, func(self py.Object, args py.Tuple) (py.Object, error) {
+			fp := errorField(self, 4)
+			if fp == py.None {
+				return nil, py.ExceptionNewf(py.AttributeError, "'HTTPError' object has no attribute '%s'", name)
+			}
+			method, err := py.GetAttrString(fp, name)
+			if err != nil {
+				return nil, err
+			}
+			return py.Call(method, args, nil)
+		}, 0, "Call the method of the response.")
+	}
+}
+
+// Makes an HTTPError for the response fp to the request for url
+func newHTTPError(url string, code int, msg string, hdrs, fp py.Object) error {
+	e := py.ExceptionNewf(HTTPError, "")
+	e.Args = py.Tuple{py.String(url), py.Int(code), py.String(msg), hdrs, fp}
+	return e
+}
+
+// Makes a URLError with the reason, which may be an exception
+func newURLError(reason py.Object) error {
+	e := py.ExceptionNewf(URLError, "")
+	e.Args = py.Tuple{reason}
+	return e
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "urllib.error",
+		Doc:  error_doc,
+		Globals: py.StringDict{
+			"URLError":  URLError,
+			"HTTPError": HTTPError,
+		},
+	})
+}
@@ -0,0 +1,377 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Urllib modules
+//
+// urllib.request is a small subset of the python module made with
+// Go's net/http.  urlopen follows redirects and returns the
+// http.client.HTTPResponse from the http package.
+
+package urllib
+
+import (
+	"bytes"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/http"
+	"github.com/go-python/gpython/py"
+)
+
+const urllib_doc = `Functions for opening URLs.
+
+Only the urllib.request and urllib.error modules are available.`
+
+const request_doc = `An extensible library for opening URLs using a variety of protocols
+
+The simplest way to use this module is to call the urlopen function,
+which accepts a string containing a URL or a Request object (described
+below).  It opens the URL and returns the results as file-like
+object; the returned object has some extra methods described below.`
+
+// The User-agent header sent unless the request has one
+const userAgent = "Python-urllib/3.4"
+
+var RequestType = py.NewTypeX("Request", request_type_doc, RequestNew, nil)
+
+const request_type_doc = `Request(url, data=None, headers={}, method=None)
+
+A request for urlopen.  data is the bytes to send, which makes the
+method POST unless method is given.  headers is a dict of the headers
+to send.`
+
+// Request is a request for urlopen
+type Request struct {
+	fullURL string
+	data    py.Object
+	headers *py.Dict
+	method  py.Object
+}
+
+// Type of this object
+func (r *Request) Type() *py.Type {
+	return RequestType
+}
+
+// RequestNew makes a Request
+func RequestNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var urlObj, data, headers, originReqHost, unverifiable, method py.Object = nil, py.None, py.None, py.None, py.False, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOOOO:Request", []string{"url", "data", "headers", "origin_req_host", "unverifiable", "method"}, &urlObj, &data, &headers, &originReqHost, &unverifiable, &method)
+	if err != nil {
+		return nil, err
+	}
+	r := &Request{
+		data:    data,
+		headers: py.NewDict(),
+		method:  method,
+	}
+	if err = r.setURL(urlObj); err != nil {
+		return nil, err
+	}
+	if headers != py.None {
+		header := nethttp.Header{}
+		if err = http.AddHeaders(header, headers); err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(header))
+		for key := range header {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			r.addHeader(key, header.Get(key))
+		}
+	}
+	return r, nil
+}
+
+// Sets the URL of the request checking it has a scheme
+func (r *Request) setURL(obj py.Object) error {
+	s, err := py.StrAsString(obj)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return py.ExceptionNewf(py.ValueError, "unknown url type: '%s'", s)
+	}
+	r.fullURL = s
+	return nil
+}
+
+// Returns the name of a header as python stores it, eg Content-type
+func headerName(key string) string {
+	if key == "" {
+		return key
+	}
+	return strings.ToUpper(key[:1]) + strings.ToLower(key[1:])
+}
+
+// Adds a header to the request replacing any with the same name
+func (r *Request) addHeader(key, value string) {
+	_ = r.headers.Set(py.String(headerName(key)), py.String(value))
+}
+
+// Returns the value of a header and whether it is set
+func (r *Request) header(key string) (py.Object, bool) {
+	value, ok, _ := r.headers.Get(py.String(headerName(key)))
+	return value, ok
+}
+
+// Method returns the method of the request
+func (r *Request) Method() (string, error) {
+	if r.method != py.None {
+		return py.StrAsString(r.method)
+	}
+	if r.data != py.None {
+		return "POST", nil
+	}
+	return "GET", nil
+}
+
+func (r *Request) M__repr__() (py.Object, error) {
+	return py.String("<urllib.request.Request object for " + r.fullURL + ">"), nil
+}
+
+func init() {
+	RequestType.Dict["full_url"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*Request).fullURL), nil
+		},
+		Fset: func(self, value py.Object) error {
+			return self.(*Request).setURL(value)
+		},
+	}
+	RequestType.Dict["data"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Request).data, nil
+		},
+		Fset: func(self, value py.Object) error {
+			self.(*Request).data = value
+			return nil
+		},
+	}
+	RequestType.Dict["method"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Request).method, nil
+		},
+		Fset: func(self, value py.Object) error {
+			self.(*Request).method = value
+			return nil
+		},
+	}
+	RequestType.Dict["headers"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*Request).headers, nil
+		},
+	}
+	for name, get := range map[string]func(u *url.URL) string{
+		"type": func(u *url.URL) string { return u.Scheme },
+		"host": func(u *url.URL) string { return u.Host },
+		"selector": func(u *url.URL) string {
+			if u.RawQuery != "" {
+				return u.EscapedPath() + "?" + u.RawQuery
+			}
+			return u.EscapedPath()
+		},
+	} {
+		get := get
+		RequestType.Dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				u, err := url.Parse(self.(*Request).fullURL)
+				if err != nil {
+					return nil, py.ExceptionNewf(py.ValueError, "%v", err)
+				}
+				return py.String(get(u)), nil
+			},
+		}
+	}
+	RequestType.Dict["get_method"] = py.MustNewMethod("get_method", func(self py.Object) (py.Object, error) {
+		method, err := self.(*Request).Method()
+		if err != nil {
+			return nil, err
+		}
+		return py.String(method), nil
+	}, 0, `get_method() -> str
+
+Return the method of the request, POST if it has data and GET if not
+unless it was given.`)
+	RequestType.Dict["get_full_url"] = py.MustNewMethod("get_full_url", func(self py.Object) (py.Object, error) {
+		return py.String(self.(*Request).fullURL), nil
+	}, 0, `get_full_url() -> str`)
+	RequestType.Dict["add_header"] = py.MustNewMethod("add_header", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, value py.Object
+		err := py.UnpackTuple(args, nil, "add_header", 2, 2, &key, &value)
+		if err != nil {
+			return nil, err
+		}
+		k, err := py.StrAsString(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := py.StrAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		self.(*Request).addHeader(k, v)
+		return py.None, nil
+	}, 0, `add_header(key, val)
+
+Add a header to the request, replacing any with the same name.`)
+	RequestType.Dict["has_header"] = py.MustNewMethod("has_header", func(self, key py.Object) (py.Object, error) {
+		k, err := py.StrAsString(key)
+		if err != nil {
+			return nil, err
+		}
+		_, ok := self.(*Request).header(k)
+		return py.NewBool(ok), nil
+	}, 0, `has_header(header_name) -> bool`)
+	RequestType.Dict["get_header"] = py.MustNewMethod("get_header", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, def py.Object = nil, py.None
+		err := py.UnpackTuple(args, nil, "get_header", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		k, err := py.StrAsString(key)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := self.(*Request).header(k); ok {
+			return value, nil
+		}
+		return def, nil
+	}, 0, `get_header(header_name, default=None) -> str`)
+	RequestType.Dict["remove_header"] = py.MustNewMethod("remove_header", func(self, key py.Object) (py.Object, error) {
+		k, err := py.StrAsString(key)
+		if err != nil {
+			return nil, err
+		}
+		_, _, err = self.(*Request).headers.Delete(py.String(headerName(k)))
+		return py.None, err
+	}, 0, `remove_header(header_name)`)
+}
+
+const urlopen_doc = `urlopen(url, data=None, timeout=None) -> HTTPResponse
+
+Open the URL url, which can be either a string or a Request object.
+
+data must be bytes specifying additional data to be sent to the
+server, or None if no such data is needed.  If data is given the
+request is a POST rather than a GET.
+
+timeout is the number of seconds to wait for the server, None to wait
+forever.
+
+Raises URLError if the server can't be reached and HTTPError if it
+returns an error status.`
+
+func urllib_urlopen(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var urlObj, data, timeout py.Object = nil, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:urlopen", []string{"url", "data", "timeout"}, &urlObj, &data, &timeout)
+	if err != nil {
+		return nil, err
+	}
+	req, ok := urlObj.(*Request)
+	if !ok {
+		obj, err := RequestNew(RequestType, py.Tuple{urlObj}, nil)
+		if err != nil {
+			return nil, err
+		}
+		req = obj.(*Request)
+	}
+	if data != py.None {
+		req.data = data
+	}
+	t, err := http.TimeoutArg(timeout)
+	if err != nil {
+		return nil, err
+	}
+	return urlopen(req, t)
+}
+
+// Opens the request with a timeout in seconds, negative for none
+func urlopen(req *Request, timeout float64) (py.Object, error) {
+	u, err := url.Parse(req.fullURL)
+	if err != nil {
+		return nil, py.ExceptionNewf(py.ValueError, "%v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, newURLError(py.String("unknown url type: " + u.Scheme))
+	}
+	method, err := req.Method()
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if req.data != py.None {
+		if _, ok := req.data.(py.String); ok {
+			return nil, py.ExceptionNewf(py.TypeError, "POST data should be bytes, an iterable of bytes, or a file object. It cannot be of type str.")
+		}
+		data, err := http.BodyBytes(req.data)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	httpReq, err := nethttp.NewRequest(method, req.fullURL, body)
+	if err != nil {
+		return nil, newURLError(py.String(err.Error()))
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	httpReq.Header.Set("User-Agent", userAgent)
+	if err = http.AddHeaders(httpReq.Header, req.headers); err != nil {
+		return nil, err
+	}
+	if host := httpReq.Header.Get("Host"); host != "" {
+		httpReq.Host = host
+	}
+	client := &nethttp.Client{}
+	if timeout >= 0 {
+		client.Timeout = time.Duration(timeout * float64(time.Second))
+	}
+	resp, err := http.Do(client, httpReq)
+	if err != nil {
+		// Timeouts are raised as they are, other errors are the
+		// reason of a URLError
+		if exc, ok := err.(*py.Exception); ok && !py.IsException(py.TimeoutError, err) {
+			return nil, newURLError(exc)
+		}
+		return nil, err
+	}
+	if status := resp.Status(); status >= 400 {
+		return nil, newHTTPError(req.fullURL, status, resp.Reason(), resp.Headers(), resp)
+	}
+	return resp, nil
+}
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "urllib",
+		Doc:  urllib_doc,
+	})
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "urllib.request",
+		Doc:  request_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("urlopen", urllib_urlopen, 0, urlopen_doc),
+		},
+		Globals: py.StringDict{
+			"Request":   RequestType,
+			"URLError":  URLError,
+			"HTTPError": HTTPError,
+		},
+		Init: func(ctx *py.Context, m *py.Module) error {
+			// As in CPython importing urllib.request imports
+			// urllib.error, binding it in the urllib package
+			_, err := py.ImportModuleLevelObject("urllib.error", nil, nil, nil, 0)
+			return err
+		},
+	})
+}
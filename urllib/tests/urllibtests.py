@@ -0,0 +1,85 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+import urllib.request
+from urllib.request import urlopen, Request
+from libtest import *
+
+url = os.environ["GPYTHON_TEST_URL"]
+
+doc = "urllib.request imports urllib.error"
+assert urllib.error.__name__ == "urllib.error"
+assert urllib.error.URLError is urllib.request.URLError
+assert urllib.error.HTTPError is urllib.request.HTTPError
+
+doc = "request"
+req = Request(url + "/echo?q=1", headers={"x-test": "hi"})
+assert req.full_url == url + "/echo?q=1"
+assert req.get_full_url() == req.full_url
+assert req.type == "http"
+assert req.host == url[len("http://"):]
+assert req.selector == "/echo?q=1"
+assert req.get_method() == "GET"
+assert req.data is None
+assert req.headers == {"X-test": "hi"}
+assert req.has_header("X-test")
+assert req.get_header("X-test") == "hi"
+assert req.get_header("X-other") is None
+req.add_header("X-other", "1")
+assert req.get_header("X-other") == "1"
+req.remove_header("X-other")
+assert not req.has_header("X-other")
+assert Request(url, data=b"x").get_method() == "POST"
+assert Request(url, data=b"x", method="PUT").get_method() == "PUT"
+assertRaises(ValueError, Request, "no scheme")
+
+doc = "urlopen"
+with urlopen(url + "/echo") as resp:
+    assert resp.status == 200
+    assert resp.getcode() == 200
+    assert resp.geturl() == url + "/echo"
+    assert resp.info()["Content-Type"] == "text/plain"
+    assert resp.read() == b"GET /echo  "
+with urlopen(req, timeout=5) as resp:
+    assert resp.read() == b"GET /echo?q=1 hi "
+
+doc = "post"
+resp = urlopen(url + "/echo", data=b"a=1")
+assert resp.read() == b"POST /echo  a=1"
+resp = urlopen(Request(url + "/echo", data=b"a=2", headers={"X-Test": "t"}, method="PATCH"))
+assert resp.read() == b"PATCH /echo t a=2"
+assertRaises(TypeError, urlopen, url + "/echo", "str data")
+
+doc = "redirect"
+resp = urlopen(url + "/redirect")
+assert resp.geturl() == url + "/echo"
+assert resp.read() == b"GET /echo  "
+
+doc = "errors"
+try:
+    urlopen(url + "/missing")
+except urllib.error.HTTPError as e:
+    assert e.code == 404
+    assert e.reason == "Not Found"
+    assert e.headers["Content-Type"].startswith("text/plain")
+    assert e.read() == b"no such page\n"
+    assert str(e) == "HTTP Error 404: Not Found"
+    assert isinstance(e, urllib.error.URLError)
+    assert isinstance(e, OSError)
+else:
+    assert False, "HTTPError not raised"
+
+try:
+    urlopen("http://127.0.0.1:1/")
+except urllib.error.URLError as e:
+    assert isinstance(e.reason, ConnectionRefusedError)
+    assert str(e).startswith("<urlopen error ")
+else:
+    assert False, "URLError not raised"
+
+assertRaises(urllib.error.URLError, urlopen, "ftp://example.com/")
+assertRaises(TimeoutError, urlopen, url + "/slow", timeout=0.05)
+
+doc = "finished"
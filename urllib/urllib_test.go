@@ -0,0 +1,44 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package urllib_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/go-python/gpython/os"
+	"github.com/go-python/gpython/pytest"
+)
+
+// Serves the requests made by the tests
+func handler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/redirect":
+		http.Redirect(w, r, "/echo", http.StatusFound)
+		return
+	case "/missing":
+		http.Error(w, "no such page", http.StatusNotFound)
+		return
+	case "/slow":
+		time.Sleep(200 * time.Millisecond)
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Add("X-Multi", "a")
+	w.Header().Add("X-Multi", "b")
+	_, _ = w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("X-Test") + " " + string(body)))
+}
+
+func TestUrllib(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	os.Setenv("GPYTHON_TEST_URL", server.URL)
+	defer os.Unsetenv("GPYTHON_TEST_URL")
+	pytest.RunTests(t, "tests")
+}
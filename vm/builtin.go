@@ -12,7 +12,21 @@ import (
 	"github.com/go-python/gpython/py"
 )
 
-func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict, mode string) (py.Object, error) {
+// Returns whether obj can be used as the locals of eval or exec
+func isMapping(obj py.Object) bool {
+	switch obj.(type) {
+	case *py.List, py.Tuple, py.String:
+		return false
+	}
+	_, err := py.GetAttrString(obj, "__getitem__")
+	return err == nil
+}
+
+// Implements eval and exec
+//
+// The globals must be a dict but the locals may be any mapping, in
+// which case the names in the local scope are looked up in it.
+func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs py.StringDict, currentLocals py.Object, currentGlobals, builtins py.StringDict, mode string) (py.Object, error) {
 	var (
 		cmd     py.Object
 		globals py.Object = py.None
@@ -30,14 +44,27 @@ func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs, currentLocals, cur
 	} else if locals == py.None {
 		locals = globals
 	}
-	// FIXME this can be a mapping too
-	globalsDict, err := py.DictCheck(globals)
+	globalsDict, err := py.DictAsNamespace(globals)
 	if err != nil {
-		return nil, py.ExceptionNewf(py.TypeError, "globals must be a dict")
+		switch {
+		case mode == "exec":
+			return nil, py.ExceptionNewf(py.TypeError, "exec() globals must be a dict, not %s", globals.Type().Name)
+		case isMapping(globals):
+			return nil, py.ExceptionNewf(py.TypeError, "globals must be a real dict; try eval(expr, {}, mapping)")
+		default:
+			return nil, py.ExceptionNewf(py.TypeError, "globals must be a dict")
+		}
 	}
-	localsDict, err := py.DictCheck(locals)
+	var localsMapping py.Object
+	localsDict, err := py.DictAsNamespace(locals)
 	if err != nil {
-		return nil, py.ExceptionNewf(py.TypeError, "locals must be a dict")
+		if !isMapping(locals) {
+			if mode == "exec" {
+				return nil, py.ExceptionNewf(py.TypeError, "locals must be a mapping or None, not %s", locals.Type().Name)
+			}
+			return nil, py.ExceptionNewf(py.TypeError, "locals must be a mapping")
+		}
+		localsMapping = locals
 	}
 
 	// Set __builtins__ if not set
@@ -59,6 +86,10 @@ func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs, currentLocals, cur
 
 	}
 	if code == nil {
+		err = py.Audit("compile", cmd, py.String("<string>"))
+		if err != nil {
+			return nil, err
+		}
 		codeStr = strings.TrimLeft(codeStr, " \t")
 		obj, err := py.Compile(codeStr, "<string>", mode, 0, true)
 		if err != nil {
@@ -66,17 +97,26 @@ func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs, currentLocals, cur
 		}
 		code = obj.(*py.Code)
 	}
+	err = py.Audit("exec", code)
+	if err != nil {
+		return nil, err
+	}
 	if code.GetNumFree() > 0 {
 		return nil, py.ExceptionNewf(py.TypeError, "code passed to %s() may not contain free variables", mode)
 	}
+	if localsMapping != nil {
+		frame := py.NewFrame(globalsDict, nil, code, nil)
+		frame.LocalsMapping = localsMapping
+		return RunFrame(frame)
+	}
 	return EvalCode(code, globalsDict, localsDict)
 }
 
-func builtinEval(self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict) (py.Object, error) {
+func builtinEval(self py.Object, args py.Tuple, kwargs py.StringDict, currentLocals py.Object, currentGlobals, builtins py.StringDict) (py.Object, error) {
 	return builtinEvalOrExec(self, args, kwargs, currentLocals, currentGlobals, builtins, "eval")
 }
 
-func builtinExec(self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict) (py.Object, error) {
+func builtinExec(self py.Object, args py.Tuple, kwargs py.StringDict, currentLocals py.Object, currentGlobals, builtins py.StringDict) (py.Object, error) {
 	_, err := builtinEvalOrExec(self, args, kwargs, currentLocals, currentGlobals, builtins, "exec")
 	if err != nil {
 		return nil, err
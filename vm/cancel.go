@@ -0,0 +1,127 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Cancellation
+//
+// Python code can be run with a Go context.Context so that it is
+// interrupted when the context is cancelled or its deadline passes.
+// The vm checks the context every CancelCheckInterval instructions
+// and raises KeyboardInterrupt with the error of the context as its
+// message.  Once cancelled every instruction raises it again so the
+// code can't catch it and carry on.
+
+package vm
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// CancelCheckInterval is the number of instructions run between
+// checks that the context of the running code has been cancelled
+var CancelCheckInterval = 64
+
+// The cancellation state of a thread
+type cancelState struct {
+	ctx   context.Context
+	done  <-chan struct{} // ctx.Done() or nil if it can't be cancelled
+	err   error           // the error raised once cancelled
+	ticks int             // instructions since the last check
+}
+
+// The cancellation state of the thread holding the GIL
+var cancel cancelState
+
+// Returns the KeyboardInterrupt raised when ctx is cancelled
+func cancelError(ctx context.Context) error {
+	return py.ExceptionNewf(py.KeyboardInterrupt, "%v", ctx.Err())
+}
+
+// Called by the vm for each instruction returning an error if the
+// running code has been cancelled
+func checkCancel() error {
+	if cancel.done == nil {
+		return nil
+	}
+	if cancel.err != nil {
+		return cancel.err
+	}
+	cancel.ticks++
+	if cancel.ticks < CancelCheckInterval {
+		return nil
+	}
+	cancel.ticks = 0
+	select {
+	case <-cancel.done:
+		cancel.err = cancelError(cancel.ctx)
+	default:
+	}
+	return cancel.err
+}
+
+// WithContext calls fn so that the python code it runs in this thread
+// is interrupted by raising KeyboardInterrupt when ctx is cancelled or
+// its deadline passes.
+//
+// It must be called with the GIL held.  Calls may be nested, in
+// which case the innermost ctx is used.
+func WithContext(ctx context.Context, fn func()) {
+	old := cancel
+	cancel = cancelState{ctx: ctx, done: ctx.Done()}
+	defer func() {
+		cancel = old
+	}()
+	fn()
+}
+
+// RunContext runs the virtual machine on a Code object like Run, but
+// interrupts it by raising KeyboardInterrupt when ctx is cancelled or
+// its deadline passes.  The message of the exception is the error of
+// ctx.
+func RunContext(ctx context.Context, globals, locals py.StringDict, code *py.Code, closure py.Tuple) (res py.Object, err error) {
+	WithContext(ctx, func() {
+		res, err = Run(globals, locals, code, closure)
+	})
+	return res, err
+}
+
+// Sleep waits for d with the GIL released so other threads can run.
+//
+// It returns early with KeyboardInterrupt if the context of the
+// running code is cancelled.  In the main thread it runs the pending
+// calls as they arrive, returning early with the error of any which
+// fails, so a signal handler can interrupt it.
+func Sleep(d time.Duration) error {
+	done, ctx := cancel.done, cancel.ctx
+	var wake <-chan struct{}
+	if thread.ident == 1 {
+		wake = pendingWake
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		err := checkPending()
+		if err != nil {
+			return err
+		}
+		cancelled, woken := false, false
+		AllowThreads(func() {
+			select {
+			case <-timer.C:
+			case <-done:
+				cancelled = true
+			case <-wake:
+				woken = true
+			}
+		})
+		if cancelled {
+			return cancelError(ctx)
+		}
+		if !woken {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Decoded instructions
+//
+// The bytecode of a code object is decoded once, the first time it
+// is run, so the interpreter loop doesn't have to decode the
+// arguments and any EXTENDED_ARG of each instruction every time it
+// runs it.  The decoded instructions also hold the inline caches of
+// the instructions which have them.
+
+package vm
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+// An instruction decoded for the interpreter loop
+type instruction struct {
+	op   OpCode
+	arg  int32         // the argument including any EXTENDED_ARG
+	next int32         // offset of the next instruction
+	attr *py.AttrCache // inline cache for LOAD_ATTR
+}
+
+// The decoded instructions of a code object
+type decodedCode struct {
+	code   string        // the bytecode which was decoded
+	instrs []instruction // the instructions indexed by offset
+}
+
+// Returns the instructions of co indexed by their offset in the
+// bytecode, decoding them the first time co is run
+//
+// An instruction is decoded at every offset, not just those which
+// start an instruction, so jumping anywhere in the bytecode does the
+// same as it would if the bytecode were decoded as it ran.
+func decode(co *py.Code) []instruction {
+	if dc, ok := co.Cache.(*decodedCode); ok && dc.code == co.Code {
+		return dc.instrs
+	}
+	code := co.Code
+	instrs := make([]instruction, len(code))
+	for i := range instrs {
+		op, arg, next := decodeOp(code, i)
+		if op == EXTENDED_ARG && next < len(code) {
+			ext := arg << 16
+			op, arg, next = decodeOp(code, next)
+			if op.HAS_ARG() {
+				arg += ext
+			}
+		}
+		instr := &instrs[i]
+		instr.op = op
+		instr.arg = arg
+		instr.next = int32(next)
+		if op == LOAD_ATTR {
+			instr.attr = new(py.AttrCache)
+		}
+	}
+	co.Cache = &decodedCode{code: code, instrs: instrs}
+	return instrs
+}
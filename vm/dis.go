@@ -0,0 +1,207 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bytecode disassembler
+//
+// This decodes the bytecode of a code object into Instructions and
+// prints them in the same format as the dis module of CPython 3.4.
+
+package vm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+// Instruction is a decoded bytecode instruction
+type Instruction struct {
+	Op           OpCode
+	HasArg       bool      // set if the instruction has an argument
+	Arg          int32     // the argument including any EXTENDED_ARG
+	Argval       py.Object // the resolved argument, eg the constant loaded
+	Argrepr      string    // description of the argument
+	Offset       int32     // index of the instruction in the bytecode
+	StartsLine   int32     // line started by this instruction or 0
+	IsJumpTarget bool      // set if other code jumps here
+}
+
+// LineStarts returns the line numbers of code indexed by the offsets
+// of the instructions which start them, as worked out from the
+// lnotab.
+//
+// This is the equivalent of dis.findlinestarts
+func LineStarts(co *py.Code) map[int32]int32 {
+	starts := make(map[int32]int32)
+	lastLineno := int32(-1)
+	lineno := co.Firstlineno
+	addr := int32(0)
+	for i := 0; i+1 < len(co.Lnotab); i += 2 {
+		byteIncr, lineIncr := int32(co.Lnotab[i]), int32(co.Lnotab[i+1])
+		if byteIncr != 0 {
+			if lineno != lastLineno {
+				starts[addr] = lineno
+				lastLineno = lineno
+			}
+			addr += byteIncr
+		}
+		lineno += lineIncr
+	}
+	if lineno != lastLineno {
+		starts[addr] = lineno
+	}
+	return starts
+}
+
+// Decodes the opcode and argument at offset i of the bytecode,
+// returning the offset of the next instruction
+func decodeOp(code string, i int) (op OpCode, arg int32, next int) {
+	op = OpCode(code[i])
+	i++
+	if op.HAS_ARG() && i+1 < len(code) {
+		arg = int32(code[i]) | int32(code[i+1])<<8
+		i += 2
+	}
+	return op, arg, i
+}
+
+// JumpTargets returns the offsets of the instructions in code which
+// are jumped to
+//
+// This is the equivalent of dis.findlabels
+func JumpTargets(co *py.Code) map[int32]bool {
+	targets := make(map[int32]bool)
+	code := co.Code
+	ext := int32(0)
+	for i := 0; i < len(code); {
+		op, arg, next := decodeOp(code, i)
+		arg += ext
+		ext = 0
+		switch {
+		case op == EXTENDED_ARG:
+			ext = arg << 16
+		case op.HAS_JREL():
+			targets[int32(next)+arg] = true
+		case op.HAS_JABS():
+			targets[arg] = true
+		}
+		i = next
+	}
+	return targets
+}
+
+// GetInstructions decodes the bytecode of co into Instructions
+func GetInstructions(co *py.Code) []Instruction {
+	var instrs []Instruction
+	lineStarts := LineStarts(co)
+	targets := JumpTargets(co)
+	cellAndFreeVars := append(append([]string{}, co.Cellvars...), co.Freevars...)
+	code := co.Code
+	ext := int32(0)
+	for i := 0; i < len(code); {
+		op, arg, next := decodeOp(code, i)
+		instr := Instruction{
+			Op:           op,
+			Offset:       int32(i),
+			StartsLine:   lineStarts[int32(i)],
+			IsJumpTarget: targets[int32(i)],
+		}
+		if op.HAS_ARG() {
+			arg += ext
+			ext = 0
+			instr.HasArg = true
+			instr.Arg = arg
+			instr.Argval = py.Int(arg)
+			switch {
+			case op == EXTENDED_ARG:
+				ext = arg << 16
+			case op.HAS_CONST():
+				if int(arg) < len(co.Consts) {
+					instr.Argval = co.Consts[arg]
+					instr.Argrepr = py.DebugRepr(instr.Argval)
+				}
+			case op.HAS_NAME():
+				instr.Argval, instr.Argrepr = nameArg(co.Names, arg)
+			case op.HAS_JREL():
+				instr.Argval = py.Int(int32(next) + arg)
+				instr.Argrepr = fmt.Sprintf("to %d", int32(next)+arg)
+			case op.HAS_LOCAL():
+				instr.Argval, instr.Argrepr = nameArg(co.Varnames, arg)
+			case op.HAS_COMPARE():
+				if int(arg) < len(CmpOp) {
+					instr.Argval = py.String(CmpOp[arg])
+					instr.Argrepr = CmpOp[arg]
+				}
+			case op.HAS_FREE():
+				instr.Argval, instr.Argrepr = nameArg(cellAndFreeVars, arg)
+			case op.HAS_NARGS():
+				instr.Argrepr = fmt.Sprintf("%d positional, %d keyword pair", arg&0xff, arg>>8)
+			}
+		} else {
+			instr.Argval = py.None
+		}
+		instrs = append(instrs, instr)
+		i = next
+	}
+	return instrs
+}
+
+// Returns the name at index arg of names as an argument value and its
+// description
+func nameArg(names []string, arg int32) (py.Object, string) {
+	if int(arg) >= len(names) {
+		return py.Int(arg), ""
+	}
+	return py.String(names[arg]), names[arg]
+}
+
+// Format returns the instruction as a line of a disassembly, marking
+// it with --> if current is set
+func (instr *Instruction) Format(current bool) string {
+	var fields []string
+	if instr.StartsLine != 0 {
+		fields = append(fields, fmt.Sprintf("%3d", instr.StartsLine))
+	} else {
+		fields = append(fields, "   ")
+	}
+	if current {
+		fields = append(fields, "-->")
+	} else {
+		fields = append(fields, "   ")
+	}
+	if instr.IsJumpTarget {
+		fields = append(fields, ">>")
+	} else {
+		fields = append(fields, "  ")
+	}
+	fields = append(fields, fmt.Sprintf("%4d", instr.Offset))
+	fields = append(fields, fmt.Sprintf("%-20s", instr.Op.Name()))
+	if instr.HasArg {
+		fields = append(fields, fmt.Sprintf("%5d", instr.Arg))
+		if instr.Argrepr != "" {
+			fields = append(fields, "("+instr.Argrepr+")")
+		}
+	}
+	return strings.TrimRight(strings.Join(fields, " "), " ")
+}
+
+// Disassemble writes a disassembly of the bytecode of co to w
+//
+// Each line of source starts a new paragraph, and lasti, if not -1,
+// marks the instruction at that offset as the current one.
+func Disassemble(w io.Writer, co *py.Code, lasti int32) error {
+	for _, instr := range GetInstructions(co) {
+		if instr.StartsLine != 0 && instr.Offset > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, instr.Format(instr.Offset == lasti)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
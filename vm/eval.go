@@ -56,17 +56,38 @@ func (vm *Vm) SET_SECOND(v py.Object)       { vm.frame.Stack[len(vm.frame.Stack)
 func (vm *Vm) SET_THIRD(v py.Object)        { vm.frame.Stack[len(vm.frame.Stack)-3] = v }
 func (vm *Vm) SET_FOURTH(v py.Object)       { vm.frame.Stack[len(vm.frame.Stack)-4] = v }
 func (vm *Vm) SET_VALUE(n int, v py.Object) { vm.frame.Stack[len(vm.frame.Stack)-(n)] = (v) }
-func (vm *Vm) DROP()                        { vm.frame.Stack = vm.frame.Stack[:len(vm.frame.Stack)-1] }
-func (vm *Vm) DROPN(n int)                  { vm.frame.Stack = vm.frame.Stack[:len(vm.frame.Stack)-n] }
+func (vm *Vm) DROP()                        { vm.DROPN(1) }
+
+// Drop n items from the top of the vm stack
+func (vm *Vm) DROPN(n int) {
+	vm.frame.Stack = truncateStack(vm.frame.Stack, len(vm.frame.Stack)-n)
+}
 
 // Pop from top of vm stack
 func (vm *Vm) POP() py.Object {
 	// FIXME what if empty?
 	out := vm.frame.Stack[len(vm.frame.Stack)-1]
-	vm.frame.Stack = vm.frame.Stack[:len(vm.frame.Stack)-1]
+	vm.DROP()
 	return out
 }
 
+// Returns stack truncated to level items
+//
+// The items dropped are cleared so the stack doesn't keep them alive
+// after they have gone, which would stop their __del__ methods and
+// weakref callbacks being called.
+func truncateStack(stack []py.Object, level int) []py.Object {
+	clearObjects(stack[level:])
+	return stack[:level]
+}
+
+// Sets all the items of objs to nil
+func clearObjects(objs []py.Object) {
+	for i := range objs {
+		objs[i] = nil
+	}
+}
+
 // Push to top of vm stack
 func (vm *Vm) PUSH(obj py.Object) {
 	vm.frame.Stack = append(vm.frame.Stack, obj)
@@ -85,12 +106,19 @@ func (vm *Vm) EXTEND_REVERSED(items py.Tuple) {
 }
 
 // Adds a traceback to the exc passed in for the current vm state
+//
+// The frame's Lasti is past the instruction which was running so the
+// line is that of the byte before.  The traceback is stored in the
+// exception's __traceback__ too.
 func (vm *Vm) AddTraceback(exc *py.ExceptionInfo) {
 	exc.Traceback = &py.Traceback{
 		Next:   exc.Traceback,
 		Frame:  vm.frame,
 		Lasti:  vm.frame.Lasti,
-		Lineno: vm.frame.Code.Addr2Line(vm.frame.Lasti),
+		Lineno: vm.frame.Code.Addr2Line(vm.frame.Lasti - 1),
+	}
+	if exception, ok := exc.Value.(*py.Exception); ok {
+		exception.Traceback = exc.Traceback
 	}
 }
 
@@ -104,10 +132,34 @@ func (vm *Vm) SetException(exception py.Object) {
 	vm.curexc.Value = exception
 	vm.curexc.Type = exception.Type()
 	vm.curexc.Traceback = nil
+	// Carry on from the traceback of an exception being raised again
+	if exc, ok := exception.(*py.Exception); ok {
+		vm.curexc.Traceback, _ = exc.Traceback.(*py.Traceback)
+	}
 	vm.AddTraceback(&vm.curexc)
 	vm.why = whyException
 }
 
+// Set the exception in the VM from an error returned by an opcode
+//
+// A new exception is chained to the exception being handled, if any.
+func (vm *Vm) setError(err error) {
+	// FIXME shouldn't be doing this - just use err?
+	if errExcInfo, ok := err.(py.ExceptionInfo); ok {
+		vm.curexc = errExcInfo
+		vm.AddTraceback(&vm.curexc)
+		vm.why = whyException
+		return
+	}
+	exc := py.MakeException(err)
+	if handled := vm.frame.HandledException(); handled != nil {
+		if context, ok := handled.Value.(*py.Exception); ok {
+			exc.SetContext(context)
+		}
+	}
+	vm.SetException(exc)
+}
+
 // Check for an exception (panic)
 //
 // Should be called with the result of recover
@@ -142,6 +194,29 @@ func (vm *Vm) CheckException() {
 	}
 }
 
+// Runs the instruction instr
+//
+// Corrupt bytecode can make an instruction panic, eg by indexing past
+// the constants or popping an empty stack, so what it panicked with is
+// returned as the error, a SystemError unless it is an exception, for
+// the frame's except and finally blocks to handle as any other.
+func (vm *Vm) dispatch(instr *instruction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if debugging {
+				debugf("*** Panic recovered %v\n", r)
+				debug.PrintStack()
+			}
+			if exc, ok := r.(py.ExceptionInfo); ok {
+				err = exc
+			} else {
+				err = py.MakeException(r)
+			}
+		}
+	}()
+	return jumpTable[instr.op](vm, instr.arg)
+}
+
 // Illegal instruction
 func do_ILLEGAL(vm *Vm, arg int32) error {
 	return py.ExceptionNewf(py.SystemError, "Illegal opcode")
@@ -230,6 +305,88 @@ func do_GET_ITER(vm *Vm, arg int32) error {
 	return vm.setTopAndCheckErr(py.Iter(vm.TOP()))
 }
 
+// Implements TOS = get_yield_from_iter(TOS). Generators are left as
+// they are so that yield from can delegate to them directly,
+// coroutines are only allowed in iterable coroutines, and anything
+// else is replaced with iter(TOS).
+func do_GET_YIELD_FROM_ITER(vm *Vm, arg int32) error {
+	switch vm.TOP().(type) {
+	case *py.Generator:
+		return nil
+	case *py.Coroutine:
+		if vm.frame.Code.Flags&(py.CO_COROUTINE|py.CO_ITERABLE_COROUTINE) == 0 {
+			return py.ExceptionNewf(py.TypeError, "cannot 'yield from' a coroutine object in a non-coroutine generator")
+		}
+		return nil
+	}
+	return vm.setTopAndCheckErr(py.Iter(vm.TOP()))
+}
+
+// Implements TOS = TOS.__aiter__().
+func do_GET_AITER(vm *Vm, arg int32) error {
+	obj := vm.TOP()
+	var iter py.Object
+	var err error
+	if I, ok := obj.(py.I__aiter__); ok {
+		iter, err = I.M__aiter__()
+	} else if res, ok, e := py.TypeCall0(obj, "__aiter__"); ok {
+		iter, err = res, e
+	} else {
+		return py.ExceptionNewf(py.TypeError, "'async for' requires an object with __aiter__ method, got %s", obj.Type().Name)
+	}
+	if err != nil {
+		return err
+	}
+	vm.SET_TOP(iter)
+	return nil
+}
+
+// Implements PUSH(get_awaitable(TOS.__anext__())).
+func do_GET_ANEXT(vm *Vm, arg int32) error {
+	aiter := vm.TOP()
+	var next py.Object
+	var err error
+	if I, ok := aiter.(py.I__anext__); ok {
+		next, err = I.M__anext__()
+	} else if res, ok, e := py.TypeCall0(aiter, "__anext__"); ok {
+		next, err = res, e
+	} else {
+		return py.ExceptionNewf(py.TypeError, "'async for' requires an iterator with __anext__ method, got %s", aiter.Type().Name)
+	}
+	if err != nil {
+		return err
+	}
+	awaitable, err := py.GetAwaitableIter(next)
+	if err != nil {
+		return py.ExceptionNewf(py.TypeError, "'async for' received an invalid object from __anext__: %s", next.Type().Name)
+	}
+	vm.PUSH(awaitable)
+	return nil
+}
+
+// Resolves async with. Loads __aexit__ from the context manager
+// replacing it on the stack for later use by WITH_CLEANUP_START then
+// pushes the result of calling __aenter__ which the following
+// GET_AWAITABLE will await.
+func do_BEFORE_ASYNC_WITH(vm *Vm, arg int32) error {
+	mgr := vm.TOP()
+	exit, err := py.GetAttrString(mgr, "__aexit__")
+	if err != nil {
+		return err
+	}
+	vm.SET_TOP(exit)
+	enter, err := py.GetAttrString(mgr, "__aenter__")
+	if err != nil {
+		return err
+	}
+	res, err := py.Call(enter, nil, nil)
+	if err != nil {
+		return err
+	}
+	vm.PUSH(res)
+	return nil
+}
+
 // Binary operations remove the top of the stack (TOS) and the second
 // top-most stack item (TOS1) from the stack. They perform the
 // operation, and put the result back on the stack.
@@ -248,6 +405,13 @@ func do_BINARY_MULTIPLY(vm *Vm, arg int32) error {
 	return vm.setTopAndCheckErr(py.Mul(a, b))
 }
 
+// Implements TOS = TOS1 @ TOS.
+func do_BINARY_MATRIX_MULTIPLY(vm *Vm, arg int32) error {
+	b := vm.POP()
+	a := vm.TOP()
+	return vm.setTopAndCheckErr(py.MatMul(a, b))
+}
+
 // Implements TOS = TOS1 // TOS.
 func do_BINARY_FLOOR_DIVIDE(vm *Vm, arg int32) error {
 	b := vm.POP()
@@ -345,6 +509,13 @@ func do_INPLACE_MULTIPLY(vm *Vm, arg int32) error {
 	return vm.setTopAndCheckErr(py.IMul(a, b))
 }
 
+// Implements in-place TOS = TOS1 @ TOS.
+func do_INPLACE_MATRIX_MULTIPLY(vm *Vm, arg int32) error {
+	b := vm.POP()
+	a := vm.TOP()
+	return vm.setTopAndCheckErr(py.IMatMul(a, b))
+}
+
 // Implements in-place TOS = TOS1 // TOS.
 func do_INPLACE_FLOOR_DIVIDE(vm *Vm, arg int32) error {
 	b := vm.POP()
@@ -565,8 +736,7 @@ func do_UNPACK_EX(vm *Vm, counts int32) error {
 func do_SET_ADD(vm *Vm, i int32) error {
 	w := vm.POP()
 	v := vm.PEEK(int(i))
-	v.(*py.Set).Add(w)
-	return nil
+	return v.(*py.Set).Add(w)
 }
 
 // Calls list.append(TOS[-i], TOS). Used to implement list
@@ -586,11 +756,7 @@ func do_MAP_ADD(vm *Vm, i int32) error {
 	value := vm.SECOND()
 	vm.DROPN(2)
 	dictObj := vm.PEEK(int(i))
-	dict, err := py.DictCheckExact(dictObj)
-	if err != nil {
-		return err
-	}
-	_, err = dict.M__setitem__(key, value)
+	_, err := py.SetItem(dictObj, key, value)
 	return err
 }
 
@@ -610,7 +776,7 @@ func do_YIELD_FROM(vm *Vm, arg int32) error {
 	u := vm.POP()
 	x := vm.TOP()
 	// send u to x
-	if u == py.None {
+	if _, isSend := x.(py.I_send); u == py.None && !isSend {
 		retval, err = py.Next(x)
 	} else {
 		retval, err = py.Send(x, u)
@@ -620,6 +786,9 @@ func do_YIELD_FROM(vm *Vm, arg int32) error {
 		if !py.IsException(py.StopIteration, err) {
 			return err
 		}
+		// The value of the yield from is the sub-iterator's
+		// return value
+		vm.SET_TOP(py.StopIterationValue(err))
 		return nil
 	}
 	// x remains on stack, retval is value to be yielded
@@ -634,6 +803,13 @@ func do_YIELD_FROM(vm *Vm, arg int32) error {
 	return nil
 }
 
+// Implements TOS = get_awaitable(TOS), where get_awaitable(o) returns
+// o if o is a coroutine object or a generator object with the
+// CO_ITERABLE_COROUTINE flag, or resolves o.__await__.
+func do_GET_AWAITABLE(vm *Vm, arg int32) error {
+	return vm.setTopAndCheckErr(py.GetAwaitableIter(vm.TOP()))
+}
+
 // Pops TOS and yields it from a generator.
 func do_YIELD_VALUE(vm *Vm, arg int32) error {
 	vm.retval = vm.POP()
@@ -652,12 +828,15 @@ func do_IMPORT_STAR(vm *Vm, arg int32) error {
 	if all, ok := module.Globals["__all__"]; ok {
 		var loopErr error
 		iterErr := py.Iterate(all, func(item py.Object) bool {
-			name, err := py.AttributeName(item)
-			if err != nil {
-				loopErr = err
+			name, ok := item.(py.String)
+			if !ok {
+				loopErr = py.ExceptionNewf(py.TypeError, "Item in %s.__all__ must be str, not %s", module.Name, item.Type().Name)
 				return true
 			}
-			vm.frame.Locals[name], err = py.GetAttrString(module, name)
+			value, err := py.GetAttrString(module, string(name))
+			if err == nil {
+				err = vm.frame.StoreName(string(name), value)
+			}
 			if err != nil {
 				loopErr = err
 				return true
@@ -673,7 +852,10 @@ func do_IMPORT_STAR(vm *Vm, arg int32) error {
 	} else {
 		for name, value := range module.Globals {
 			if !strings.HasPrefix(name, "_") {
-				vm.frame.Locals[name] = value
+				err := vm.frame.StoreName(name, value)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -681,10 +863,30 @@ func do_IMPORT_STAR(vm *Vm, arg int32) error {
 	return nil
 }
 
+// Checks whether __annotations__ is defined in the local namespace
+// and sets it to an empty dict if not.  This opcode is only emitted
+// if a class or module body contains variable annotations.
+func do_SETUP_ANNOTATIONS(vm *Vm, arg int32) error {
+	if vm.frame.LocalsMapping != nil {
+		_, err := py.GetItem(vm.frame.LocalsMapping, py.String("__annotations__"))
+		if err == nil || !py.IsException(py.KeyError, err) {
+			return err
+		}
+		_, err = py.SetItem(vm.frame.LocalsMapping, py.String("__annotations__"), py.NewDict())
+		return err
+	}
+	if _, ok := vm.frame.Locals["__annotations__"]; !ok {
+		vm.frame.Locals["__annotations__"] = py.NewDict()
+	}
+	return nil
+}
+
 // Removes one block from the block stack. Per frame, there is a stack
 // of blocks, denoting nested loops, try statements, and such.
 func do_POP_BLOCK(vm *Vm, arg int32) error {
+	b := vm.frame.Block
 	vm.frame.PopBlock()
+	vm.UnwindBlock(vm.frame, b)
 	return nil
 }
 
@@ -725,9 +927,11 @@ func do_END_FINALLY(vm *Vm, arg int32) error {
 		}
 		switch vm.why {
 		case whyYield:
-			panic("vm: Unexpected whyYield in END_FINALLY")
+			vm.why = whyNot
+			return py.ExceptionNewf(py.SystemError, "vm: Unexpected whyYield in END_FINALLY")
 		case whyException:
-			panic("vm: Unexpected whyException in END_FINALLY")
+			vm.why = whyNot
+			return py.ExceptionNewf(py.SystemError, "vm: Unexpected whyException in END_FINALLY")
 		case whyReturn, whyContinue:
 			vm.retval = vm.POP()
 		case whySilenced:
@@ -739,7 +943,8 @@ func do_END_FINALLY(vm *Vm, arg int32) error {
 			b := vm.frame.Block
 			frame.PopBlock()
 			if b.Type != py.TryBlockExceptHandler {
-				panic("vm: Expecting EXCEPT_HANDLER in END_FINALLY")
+				vm.why = whyNot
+				return py.ExceptionNewf(py.SystemError, "vm: Expecting EXCEPT_HANDLER in END_FINALLY")
 			}
 			vm.UnwindExceptHandler(frame, b)
 			vm.why = whyNot
@@ -767,7 +972,11 @@ func do_END_FINALLY(vm *Vm, arg int32) error {
 // Loads the __build_class__ helper function to the stack which
 // creates a new class object.
 func do_LOAD_BUILD_CLASS(vm *Vm, arg int32) error {
-	vm.PUSH(py.Builtins.Globals["__build_class__"])
+	buildClass, ok := vm.frame.Builtins["__build_class__"]
+	if !ok {
+		return py.ExceptionNewf(py.NameError, "__build_class__ not found")
+	}
+	vm.PUSH(buildClass)
 	return nil
 }
 
@@ -802,6 +1011,16 @@ func do_SETUP_WITH(vm *Vm, delta int32) error {
 	return nil
 }
 
+// Sets up the finally block for async with once the result of
+// __aenter__ has been awaited, leaving that result on the top of the
+// stack.
+func do_SETUP_ASYNC_WITH(vm *Vm, delta int32) error {
+	res := vm.POP()
+	vm.frame.PushBlock(py.TryBlockSetupFinally, vm.frame.Lasti+delta, vm.STACK_LEVEL())
+	vm.PUSH(res)
+	return nil
+}
+
 // Cleans up the stack when a with statement block exits. On top of
 // the stack are 1–3 values indicating how/why the finally clause was
 // entered:
@@ -821,11 +1040,25 @@ func do_SETUP_WITH(vm *Vm, delta int32) error {
 // “zapped”, to prevent END_FINALLY from re-raising the
 // exception. (But non-local gotos should still be resumed.)
 func do_WITH_CLEANUP(vm *Vm, arg int32) error {
-	var exit_func py.Object
+	exit_func, exc, val, tb, err := vm.withCleanupStart()
+	if err != nil {
+		return err
+	}
+	/* XXX Not the fastest way to call it... */
+	res, err := py.Call(exit_func, []py.Object{exc, val, tb}, nil)
+	if err != nil {
+		return err
+	}
+	return vm.withCleanupFinish(exc, res)
+}
 
-	exc := vm.TOP()
-	var val py.Object = py.None
-	var tb py.Object = py.None
+// Removes the exit function from under the values describing why
+// the with block was exited, returning it along with the exception
+// (or None) to call it with
+func (vm *Vm) withCleanupStart() (exit_func, exc, val, tb py.Object, err error) {
+	exc = vm.TOP()
+	val = py.None
+	tb = py.None
 	if exc == py.None {
 		vm.DROP()
 		exit_func = vm.TOP()
@@ -860,27 +1093,57 @@ func do_WITH_CLEANUP(vm *Vm, arg int32) error {
 		   values are lower than it expects. */
 		block := vm.frame.Block
 		if block.Type != py.TryBlockExceptHandler {
-			panic("vm: WITH_CLEANUP expecting TryBlockExceptHandler")
+			return nil, nil, nil, nil, py.ExceptionNewf(py.SystemError, "vm: WITH_CLEANUP expecting TryBlockExceptHandler")
 		}
 		block.Level--
 	}
-	/* XXX Not the fastest way to call it... */
-	res, err := py.Call(exit_func, []py.Object{exc, val, tb}, nil)
+	return exit_func, exc, val, tb, nil
+}
+
+// Pushes whySilenced if there was an exception and the exit function
+// returned a true value so END_FINALLY doesn't re-raise it
+func (vm *Vm) withCleanupFinish(exc, res py.Object) error {
+	if exc == py.None {
+		return nil
+	}
+	silence, err := py.MakeBool(res)
 	if err != nil {
 		return err
 	}
+	if silence == py.True {
+		/* There was an exception and a true return */
+		vm.PUSH(py.Int(whySilenced))
+	}
+	return nil
+}
 
-	wasErr := false
-	if exc != py.None {
-		wasErr = res == py.True
+// The first half of WITH_CLEANUP for async with. This removes
+// __aexit__ from the stack as WITH_CLEANUP does and calls it, then
+// pushes the exception (or None) and the result of the call which is
+// awaited before WITH_CLEANUP_FINISH.
+func do_WITH_CLEANUP_START(vm *Vm, arg int32) error {
+	exit_func, exc, val, tb, err := vm.withCleanupStart()
+	if err != nil {
+		return err
 	}
-	if wasErr {
-		/* There was an exception and a True return */
-		vm.PUSH(py.Int(whySilenced))
+	res, err := py.Call(exit_func, []py.Object{exc, val, tb}, nil)
+	if err != nil {
+		return err
 	}
+	vm.PUSH(exc)
+	vm.PUSH(res)
 	return nil
 }
 
+// The second half of WITH_CLEANUP for async with. Pops the awaited
+// result of __aexit__ and the exception pushed by WITH_CLEANUP_START
+// then silences the exception if the result was True.
+func do_WITH_CLEANUP_FINISH(vm *Vm, arg int32) error {
+	res := vm.POP()
+	exc := vm.POP()
+	return vm.withCleanupFinish(exc, res)
+}
+
 // All of the following opcodes expect arguments. An argument is two bytes, with the more significant byte last.
 
 // Implements name = TOS. namei is the index of name in the attribute
@@ -890,18 +1153,19 @@ func do_STORE_NAME(vm *Vm, namei int32) error {
 	if debugging {
 		debugf("STORE_NAME %v\n", vm.frame.Code.Names[namei])
 	}
-	vm.frame.Locals[vm.frame.Code.Names[namei]] = vm.POP()
-	return nil
+	return vm.frame.StoreName(vm.frame.Code.Names[namei], vm.POP())
 }
 
 // Implements del name, where namei is the index into co_names
 // attribute of the code object.
 func do_DELETE_NAME(vm *Vm, namei int32) error {
 	name := vm.frame.Code.Names[namei]
-	if _, ok := vm.frame.Locals[name]; !ok {
+	ok, err := vm.frame.DeleteName(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
 		return py.ExceptionNewf(py.NameError, nameErrorMsg, name)
-	} else {
-		delete(vm.frame.Locals, name)
 	}
 	return nil
 }
@@ -930,6 +1194,12 @@ func do_STORE_ATTR(vm *Vm, namei int32) error {
 	v := vm.TOP()
 	u := vm.SECOND()
 	vm.DROPN(2)
+	if hook := evalHook(); hook != nil && hook.SetAttr != nil {
+		err := hook.SetAttr(v, w, u)
+		if err != nil {
+			return err
+		}
+	}
 	_, err := py.SetAttrString(v, w, u) /* v.w = u */
 	if err != nil {
 		return err
@@ -939,7 +1209,15 @@ func do_STORE_ATTR(vm *Vm, namei int32) error {
 
 // Implements del TOS.name, using namei as index into co_names.
 func do_DELETE_ATTR(vm *Vm, namei int32) error {
-	return py.DeleteAttrString(vm.POP(), vm.frame.Code.Names[namei])
+	obj := vm.POP()
+	name := vm.frame.Code.Names[namei]
+	if hook := evalHook(); hook != nil && hook.DelAttr != nil {
+		err := hook.DelAttr(obj, name)
+		if err != nil {
+			return err
+		}
+	}
+	return py.DeleteAttrString(obj, name)
 }
 
 // Works as STORE_NAME, but stores the name as a global.
@@ -972,7 +1250,10 @@ func do_LOAD_NAME(vm *Vm, namei int32) error {
 	if debugging {
 		debugf("LOAD_NAME %v\n", name)
 	}
-	obj, ok := vm.frame.Lookup(name)
+	obj, ok, err := vm.frame.LookupName(name)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return py.ExceptionNewf(py.NameError, nameErrorMsg, name)
 	} else {
@@ -991,9 +1272,60 @@ func do_BUILD_TUPLE(vm *Vm, count int32) error {
 	return nil
 }
 
+// Converts and formats the value for an f-string. The low two bits
+// of flags select the conversion applied first (none, str, repr or
+// ascii) and if flags&0x04 is set the format spec is on top of the
+// stack above the value.
+func do_FORMAT_VALUE(vm *Vm, flags int32) error {
+	var formatSpec py.Object = py.String("")
+	if flags&0x04 != 0 {
+		formatSpec = vm.POP()
+	}
+	value := vm.TOP()
+	var err error
+	switch flags & 0x03 {
+	case 1:
+		value, err = py.Str(value)
+	case 2:
+		value, err = py.Repr(value)
+	case 3:
+		value, err = py.Repr(value)
+		if err == nil {
+			value = py.String(py.StringEscape(value.(py.String), true))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	// Strings with no format spec are already formatted
+	if _, ok := value.(py.String); !ok || formatSpec != py.String("") {
+		value, err = py.Format(value, formatSpec)
+		if err != nil {
+			return err
+		}
+	}
+	vm.SET_TOP(value)
+	return nil
+}
+
+// Concatenates count strings from the stack and pushes the resulting
+// string.
+func do_BUILD_STRING(vm *Vm, count int32) error {
+	var out strings.Builder
+	for _, s := range vm.frame.Stack[len(vm.frame.Stack)-int(count):] {
+		out.WriteString(string(s.(py.String)))
+	}
+	vm.DROPN(int(count))
+	vm.PUSH(py.String(out.String()))
+	return nil
+}
+
 // Works as BUILD_TUPLE, but creates a set.
 func do_BUILD_SET(vm *Vm, count int32) error {
-	set := py.NewSetFromItems(vm.frame.Stack[len(vm.frame.Stack)-int(count):])
+	set, err := py.NewSetFromItems(vm.frame.Stack[len(vm.frame.Stack)-int(count):])
+	if err != nil {
+		return err
+	}
 	vm.DROPN(int(count))
 	vm.PUSH(set)
 	return nil
@@ -1010,13 +1342,386 @@ func do_BUILD_LIST(vm *Vm, count int32) error {
 // Pushes a new dictionary object onto the stack. The dictionary is
 // pre-sized to hold count entries.
 func do_BUILD_MAP(vm *Vm, count int32) error {
-	vm.PUSH(py.NewStringDictSized(int(count)))
+	vm.PUSH(py.NewDictSized(int(count)))
+	return nil
+}
+
+// Pops count iterables from the stack, joins them in a single tuple,
+// and pushes the result. Implements iterable unpacking in tuple
+// displays (*x, *y, *z).
+func do_BUILD_TUPLE_UNPACK(vm *Vm, count int32) error {
+	items, err := vm.unpackIterables(count)
+	if err != nil {
+		return err
+	}
+	vm.PUSH(py.Tuple(items))
+	return nil
+}
+
+// This is similar to BUILD_TUPLE_UNPACK, but pushes a list instead
+// of tuple. Implements iterable unpacking in list displays [*x, *y,
+// *z].
+func do_BUILD_LIST_UNPACK(vm *Vm, count int32) error {
+	items, err := vm.unpackIterables(count)
+	if err != nil {
+		return err
+	}
+	vm.PUSH(py.NewListFromItems(items))
+	return nil
+}
+
+// This is similar to BUILD_TUPLE_UNPACK, but pushes a set instead of
+// tuple. Implements iterable unpacking in set displays {*x, *y, *z}.
+func do_BUILD_SET_UNPACK(vm *Vm, count int32) error {
+	items, err := vm.unpackIterables(count)
+	if err != nil {
+		return err
+	}
+	set, err := py.NewSetFromItems(items)
+	if err != nil {
+		return err
+	}
+	vm.PUSH(set)
+	return nil
+}
+
+// Pops count iterables from the stack and returns their items
+func (vm *Vm) unpackIterables(count int32) ([]py.Object, error) {
+	var items []py.Object
+	for _, iterable := range vm.frame.Stack[len(vm.frame.Stack)-int(count):] {
+		err := py.Iterate(iterable, func(item py.Object) bool {
+			items = append(items, item)
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	vm.DROPN(int(count))
+	return items, nil
+}
+
+// Pops count mappings from the stack, merges them into a single
+// dictionary, and pushes the result. Implements dictionary unpacking
+// in dictionary displays {**x, **y, **z}.
+func do_BUILD_MAP_UNPACK(vm *Vm, count int32) error {
+	d := py.NewDict()
+	for _, mapping := range vm.frame.Stack[len(vm.frame.Stack)-int(count):] {
+		err := py.DictMerge(d, mapping)
+		if err != nil {
+			return err
+		}
+	}
+	vm.DROPN(int(count))
+	vm.PUSH(d)
+	return nil
+}
+
+// This is similar to BUILD_MAP_UNPACK, but is used for f(**x, **y,
+// **z) call syntax. The lowest byte of oparg is the count of
+// mappings, the relative position of the corresponding callable f is
+// encoded in the second byte of oparg.
+func do_BUILD_MAP_UNPACK_WITH_CALL(vm *Vm, oparg int32) error {
+	count := int(oparg & 0xFF)
+	fn := vm.PEEK(count + int((oparg>>8)&0xFF))
+	d := py.NewDict()
+	for _, mapping := range vm.frame.Stack[len(vm.frame.Stack)-count:] {
+		if _, ok := mapping.(*py.Dict); !ok {
+			if _, err := py.GetAttrString(mapping, "keys"); err != nil {
+				return py.ExceptionNewf(py.TypeError, "%s%s argument after ** must be a mapping, not %s", EvalGetFuncName(fn), EvalGetFuncDesc(fn), mapping.Type().Name)
+			}
+		}
+		items := py.NewDict()
+		err := py.DictMerge(items, mapping)
+		if err != nil {
+			return err
+		}
+		for _, item := range items.Items() {
+			_, found, err := d.Get(item[0])
+			if err != nil {
+				return err
+			}
+			if found {
+				return py.ExceptionNewf(py.TypeError, "%s%s got multiple values for keyword argument '%s'", EvalGetFuncName(fn), EvalGetFuncDesc(fn), item[0])
+			}
+			err = d.Set(item[0], item[1])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	vm.DROPN(count)
+	vm.PUSH(d)
+	return nil
+}
+
+// Pushes len(TOS) leaving TOS in place.
+func do_GET_LEN(vm *Vm, arg int32) error {
+	length, err := py.Len(vm.TOP())
+	if err != nil {
+		return err
+	}
+	vm.PUSH(length)
 	return nil
 }
 
+// Pushes True if TOS is a mapping which a mapping pattern can match
+// or False otherwise, leaving TOS in place.
+func do_MATCH_MAPPING(vm *Vm, arg int32) error {
+	subject := vm.TOP()
+	switch subject.(type) {
+	case *py.Dict, py.StringDict:
+		vm.PUSH(py.True)
+	default:
+		vm.PUSH(py.NewBool(subject.Type().IsSubtype(py.DictType)))
+	}
+	return nil
+}
+
+// Pushes True if TOS is a sequence which a sequence pattern can match
+// or False otherwise, leaving TOS in place.  Strings, bytes and
+// bytearrays are not matched as sequences.
+func do_MATCH_SEQUENCE(vm *Vm, arg int32) error {
+	subject := vm.TOP()
+	switch subject.(type) {
+	case *py.List, py.Tuple, *py.Range:
+		vm.PUSH(py.True)
+	default:
+		t := subject.Type()
+		vm.PUSH(py.NewBool(t.IsSubtype(py.ListType) || t.IsSubtype(py.TupleType)))
+	}
+	return nil
+}
+
+// Marks a missing key in do_MATCH_KEYS
+var matchKeysMissing = py.NewList()
+
+// TOS is a tuple of mapping keys and TOS1 is the match subject.
+// Pushes a tuple of the values of those keys in the subject, or None
+// if any of them are missing.
+func do_MATCH_KEYS(vm *Vm, arg int32) error {
+	keys := vm.TOP().(py.Tuple)
+	subject := vm.SECOND()
+	if len(keys) == 0 {
+		vm.PUSH(py.Tuple{})
+		return nil
+	}
+	var get py.Object
+	if _, ok := subject.(*py.Dict); !ok {
+		var err error
+		get, err = py.GetAttrString(subject, "get")
+		if err != nil {
+			return err
+		}
+	}
+	seen := py.NewDictSized(len(keys))
+	values := make(py.Tuple, len(keys))
+	for i, key := range keys {
+		_, found, err := seen.Get(key)
+		if err != nil {
+			return err
+		}
+		if found {
+			keyRepr, err := py.ReprAsString(key)
+			if err != nil {
+				return err
+			}
+			return py.ExceptionNewf(py.ValueError, "mapping pattern checks duplicate key (%s)", keyRepr)
+		}
+		err = seen.Set(key, py.None)
+		if err != nil {
+			return err
+		}
+		var value py.Object
+		if get == nil {
+			value, found, err = subject.(*py.Dict).Get(key)
+			if err != nil {
+				return err
+			}
+		} else {
+			value, err = py.Call(get, py.Tuple{key, matchKeysMissing}, nil)
+			if err != nil {
+				return err
+			}
+			found = value != matchKeysMissing
+		}
+		if !found {
+			vm.PUSH(py.None)
+			return nil
+		}
+		values[i] = value
+	}
+	vm.PUSH(values)
+	return nil
+}
+
+// TOS is a tuple of mapping keys and TOS1 is the match subject.
+// Replaces TOS with a dict of the items of the subject without those
+// keys.
+func do_COPY_DICT_WITHOUT_KEYS(vm *Vm, arg int32) error {
+	keys := vm.TOP().(py.Tuple)
+	rest := py.NewDict()
+	err := py.DictMerge(rest, vm.SECOND())
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		_, _, err = rest.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+	vm.SET_TOP(rest)
+	return nil
+}
+
+// The types whose instances match a single positional sub-pattern
+// against themselves in a class pattern, eg int(x)
+var matchSelfTypes = []*py.Type{
+	py.BoolType, py.ByteArrayType, py.BytesType, py.DictType, py.FloatType,
+	py.FrozenSetType, py.IntType, py.ListType, py.SetType, py.StringType, py.TupleType,
+}
+
+// TOS is a tuple of keyword attribute names, TOS1 the class being
+// matched against and TOS2 the match subject.  Pops all three and
+// pushes a tuple of the count positional attributes followed by the
+// keyword attributes, or None if the subject doesn't match.
+func do_MATCH_CLASS(vm *Vm, count int32) error {
+	names := vm.POP().(py.Tuple)
+	clsObj := vm.POP()
+	subject := vm.TOP()
+	cls, ok := clsObj.(*py.Type)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "called match pattern must be a type")
+	}
+	ok, err := matchIsInstance(subject, cls)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		vm.SET_TOP(py.None)
+		return nil
+	}
+	attrs := make(py.Tuple, 0, int(count)+len(names))
+	seen := map[string]bool{}
+	// Fetches attribute name from the subject returning ok false
+	// if it doesn't exist
+	getAttr := func(name py.Object) (ok bool, err error) {
+		attrName, isString := name.(py.String)
+		if !isString {
+			return false, py.ExceptionNewf(py.TypeError, "__match_args__ elements must be strings (got %s)", name.Type().Name)
+		}
+		if seen[string(attrName)] {
+			return false, py.ExceptionNewf(py.TypeError, "%s() got multiple sub-patterns for attribute '%s'", cls.Name, string(attrName))
+		}
+		seen[string(attrName)] = true
+		attr, err := py.GetAttrString(subject, string(attrName))
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				return false, nil
+			}
+			return false, err
+		}
+		attrs = append(attrs, attr)
+		return true, nil
+	}
+	if count > 0 {
+		matchArgs, err := py.GetAttrString(cls, "__match_args__")
+		if err != nil && !py.IsException(py.AttributeError, err) {
+			return err
+		}
+		if matchArgs == nil {
+			matchSelf := false
+			for _, t := range matchSelfTypes {
+				if cls.IsSubtype(t) {
+					matchSelf = true
+					break
+				}
+			}
+			if !matchSelf || count > 1 {
+				allowed := 0
+				if matchSelf {
+					allowed = 1
+				}
+				return matchClassArgsError(cls, allowed, count)
+			}
+			attrs = append(attrs, subject)
+		} else {
+			matchArgsTuple, ok := matchArgs.(py.Tuple)
+			if !ok {
+				return py.ExceptionNewf(py.TypeError, "%s.__match_args__ must be a tuple (got %s)", cls.Name, matchArgs.Type().Name)
+			}
+			if int(count) > len(matchArgsTuple) {
+				return matchClassArgsError(cls, len(matchArgsTuple), count)
+			}
+			for _, name := range matchArgsTuple[:count] {
+				ok, err := getAttr(name)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					vm.SET_TOP(py.None)
+					return nil
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		ok, err := getAttr(name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			vm.SET_TOP(py.None)
+			return nil
+		}
+	}
+	vm.SET_TOP(attrs)
+	return nil
+}
+
+// Returns the error for too many positional sub-patterns in a class pattern
+func matchClassArgsError(cls *py.Type, allowed int, given int32) error {
+	plural := "s"
+	if allowed == 1 {
+		plural = ""
+	}
+	return py.ExceptionNewf(py.TypeError, "%s() accepts %d positional sub-pattern%s (%d given)", cls.Name, allowed, plural, given)
+}
+
+// Reports whether obj is an instance of cls for a class pattern,
+// calling __instancecheck__ on the metaclass of cls if it has one
+func matchIsInstance(obj py.Object, cls *py.Type) (bool, error) {
+	if obj.Type() == cls {
+		return true, nil
+	}
+	if check := cls.Type().NativeGetAttrOrNil("__instancecheck__"); check != nil {
+		if I, ok := check.(py.I__get__); ok {
+			var err error
+			check, err = I.M__get__(cls, cls.Type())
+			if err != nil {
+				return false, err
+			}
+		}
+		res, err := py.Call(check, py.Tuple{obj}, nil)
+		if err != nil {
+			return false, err
+		}
+		return py.ObjectIsTrue(res), nil
+	}
+	return obj.Type().IsSubtype(cls), nil
+}
+
 // Replaces TOS with getattr(TOS, co_names[namei]).
 func do_LOAD_ATTR(vm *Vm, namei int32) error {
-	return vm.setTopAndCheckErr(py.GetAttrString(vm.TOP(), vm.frame.Code.Names[namei]))
+	name := vm.frame.Code.Names[namei]
+	if hook := evalHook(); hook != nil && hook.GetAttr != nil {
+		err := hook.GetAttr(vm.TOP(), name)
+		if err != nil {
+			return err
+		}
+	}
+	return vm.setTopAndCheckErr(py.GetAttrStringCached(vm.TOP(), name, vm.instr.attr))
 }
 
 // Performs a Boolean operation. The operation name can be found in
@@ -1048,9 +1753,9 @@ func do_COMPARE_OP(vm *Vm, opname int32) error {
 		in, err = py.SequenceContains(b, a)
 		r = py.NewBool(!in)
 	case PyCmp_IS:
-		r = py.NewBool(a == b)
+		r = py.NewBool(py.Is(a, b))
 	case PyCmp_IS_NOT:
-		r = py.NewBool(a != b)
+		r = py.NewBool(!py.Is(a, b))
 	case PyCmp_EXC_MATCH:
 		if bTuple, ok := b.(py.Tuple); ok {
 			for _, exc := range bTuple {
@@ -1065,7 +1770,7 @@ func do_COMPARE_OP(vm *Vm, opname int32) error {
 		}
 		r = py.NewBool(py.ExceptionGivenMatches(a, b))
 	default:
-		panic(fmt.Sprintf("vm: Unknown COMPARE_OP %v", opname))
+		err = py.ExceptionNewf(py.SystemError, "vm: Unknown COMPARE_OP %v", opname)
 	}
 	if err != nil {
 		return err
@@ -1087,8 +1792,20 @@ func do_IMPORT_NAME(vm *Vm, namei int32) error {
 	}
 	v := vm.POP()
 	u := vm.TOP()
+	if hook := evalHook(); hook != nil && hook.Import != nil {
+		level := 0
+		if i, ok := u.(py.Int); ok {
+			level = int(i)
+		}
+		err := hook.Import(string(name), level)
+		if err != nil {
+			return err
+		}
+	}
 	var locals py.Object = vm.frame.Locals
-	if locals == nil {
+	if vm.frame.LocalsMapping != nil {
+		locals = vm.frame.LocalsMapping
+	} else if vm.frame.Locals == nil {
 		locals = py.None
 	}
 	var args py.Tuple
@@ -1196,6 +1913,9 @@ func do_JUMP_ABSOLUTE(vm *Vm, target int32) error {
 func do_FOR_ITER(vm *Vm, delta int32) error {
 	r, finished := py.Next(vm.TOP())
 	if finished != nil {
+		if !py.IsException(py.StopIteration, finished) {
+			return finished
+		}
 		vm.DROP()
 		vm.frame.Lasti += delta
 	} else {
@@ -1247,11 +1967,7 @@ func do_STORE_MAP(vm *Vm, arg int32) error {
 	value := vm.SECOND()
 	dictObj := vm.THIRD()
 	vm.DROPN(2)
-	dict, err := py.DictCheckExact(dictObj)
-	if err != nil {
-		return err
-	}
-	_, err = dict.M__setitem__(key, value)
+	_, err := py.SetItem(dictObj, key, value)
 	return err
 }
 
@@ -1337,8 +2053,18 @@ func do_LOAD_CLASSDEREF(vm *Vm, i int32) error {
 	name, _ := _var_name(vm, i)
 
 	// Lookup in locals
-	if obj, ok := vm.frame.Locals[name]; ok {
+	if vm.frame.LocalsMapping != nil {
+		obj, err := py.GetItem(vm.frame.LocalsMapping, py.String(name))
+		if err == nil {
+			vm.PUSH(obj)
+			return nil
+		}
+		if !py.IsException(py.KeyError, err) {
+			return err
+		}
+	} else if obj, ok := vm.frame.Locals[name]; ok {
 		vm.PUSH(obj)
+		return nil
 	}
 	// If that failed look at the cell
 	res := vm.frame.CellAndFreeVars[i].(*py.Cell).Get()
@@ -1385,18 +2111,50 @@ func (vm *Vm) raise(exc, cause py.Object) error {
 	} else {
 		// raise <instance>
 		// raise <type>
-		excException := py.MakeException(exc)
+		excException, err := raiseException(exc)
+		if err != nil {
+			return err
+		}
 		if debugging {
 			debugf("raise: excException = %v\n", excException)
 		}
 		if cause != nil {
-			excException.Cause = py.MakeException(cause)
+			// raise <instance> from <cause>
+			if cause == py.None {
+				excException.Cause = nil
+			} else {
+				excException.Cause, err = raiseException(cause)
+				if err != nil {
+					return py.ExceptionNewf(py.TypeError, "exception causes must derive from BaseException")
+				}
+			}
+			excException.SuppressContext = true
 		}
 		return excException
 	}
 	return nil
 }
 
+// Returns the exception instance to raise for exc which may be an
+// exception class, which is called with no arguments, or instance
+func raiseException(exc py.Object) (*py.Exception, error) {
+	if py.ExceptionClassCheck(exc) && exc.Type().IsSubtype(py.TypeType) {
+		value, err := py.Call(exc, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		excException, ok := value.(*py.Exception)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "calling %s should have returned an instance of BaseException, not %s", exc.(*py.Type).Name, value.Type().Name)
+		}
+		return excException, nil
+	}
+	if excException, ok := exc.(*py.Exception); ok {
+		return excException, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "exceptions must derive from BaseException")
+}
+
 // Raises an exception. argc indicates the number of parameters to the
 // raise statement, ranging from 0 to 3. The handler will find the
 // traceback as TOS2, the parameter as TOS1, and the exception as TOS.
@@ -1410,7 +2168,7 @@ func do_RAISE_VARARGS(vm *Vm, argc int32) error {
 		exc = vm.POP()
 	case 0:
 	default:
-		panic("vm: Bad RAISE_VARARGS argc")
+		return py.ExceptionNewf(py.SystemError, "vm: Bad RAISE_VARARGS argc")
 	}
 	return vm.raise(exc, cause)
 }
@@ -1429,7 +2187,7 @@ func do_CALL_FUNCTION(vm *Vm, argc int32) error {
 }
 
 // Implementation for MAKE_FUNCTION and MAKE_CLOSURE
-func _make_function(vm *Vm, argc int32, opcode OpCode) {
+func _make_function(vm *Vm, argc int32, opcode OpCode) error {
 	posdefaults := argc & 0xff
 	kwdefaults := (argc >> 8) & 0xff
 	num_annotations := (argc >> 16) & 0x7fff
@@ -1446,7 +2204,7 @@ func _make_function(vm *Vm, argc int32, opcode OpCode) {
 		anns := py.NewStringDict()
 		name_ix := int32(len(names))
 		if num_annotations != name_ix+1 {
-			panic("vm: num_annotations wrong - corrupt bytecode?")
+			return py.ExceptionNewf(py.SystemError, "vm: num_annotations wrong - corrupt bytecode?")
 		}
 		for name_ix > 0 {
 			name_ix--
@@ -1476,6 +2234,7 @@ func _make_function(vm *Vm, argc int32, opcode OpCode) {
 	}
 
 	vm.PUSH(function)
+	return nil
 }
 
 // Pushes a new function object on the stack. TOS is the code
@@ -1484,8 +2243,7 @@ func _make_function(vm *Vm, argc int32, opcode OpCode) {
 //
 // FIXME these docs are slightly wrong.
 func do_MAKE_FUNCTION(vm *Vm, argc int32) error {
-	_make_function(vm, argc, MAKE_FUNCTION)
-	return nil
+	return _make_function(vm, argc, MAKE_FUNCTION)
 }
 
 // Creates a new function object, sets its func_closure slot, and
@@ -1494,8 +2252,7 @@ func do_MAKE_FUNCTION(vm *Vm, argc int32) error {
 // variables. The function also has argc default parameters, which are
 // found below the cells.
 func do_MAKE_CLOSURE(vm *Vm, argc int32) error {
-	_make_function(vm, argc, MAKE_CLOSURE)
-	return nil
+	return _make_function(vm, argc, MAKE_CLOSURE)
 }
 
 // Pushes a slice object on the stack. argc must be 2 or 3. If it is
@@ -1509,7 +2266,7 @@ func do_BUILD_SLICE(vm *Vm, argc int32) error {
 	case 3:
 		step = vm.POP()
 	default:
-		panic("vm: Bad value for argc in BUILD_SLICE")
+		return py.ExceptionNewf(py.SystemError, "vm: Bad value for argc in BUILD_SLICE")
 	}
 	stop := vm.POP()
 	start := vm.TOP()
@@ -1518,16 +2275,6 @@ func do_BUILD_SLICE(vm *Vm, argc int32) error {
 	return nil
 }
 
-// Prefixes any opcode which has an argument too big to fit into the
-// default two bytes. ext holds two additional bytes which, taken
-// together with the subsequent opcode’s argument, comprise a
-// four-byte argument, ext being the two most-significant bytes.
-func do_EXTENDED_ARG(vm *Vm, ext int32) error {
-	vm.ext = ext
-	vm.extended = true
-	return nil
-}
-
 // Calls a function. argc is interpreted as in CALL_FUNCTION. The top
 // element on the stack contains the variable argument list, followed
 // by keyword and positional arguments.
@@ -1589,16 +2336,13 @@ func callInternal(fn py.Object, args py.Tuple, kwargs py.StringDict, f *py.Frame
 		case py.InternalMethodGlobals:
 			return f.Globals, nil
 		case py.InternalMethodLocals:
-			f.FastToLocals()
-			return f.Locals, nil
+			return f.GetLocals(), nil
 		case py.InternalMethodImport:
 			return py.BuiltinImport(nil, args, kwargs, f.Globals)
 		case py.InternalMethodEval:
-			f.FastToLocals()
-			return builtinEval(nil, args, kwargs, f.Locals, f.Globals, f.Builtins)
+			return builtinEval(nil, args, kwargs, f.GetLocals(), f.Globals, f.Builtins)
 		case py.InternalMethodExec:
-			f.FastToLocals()
-			return builtinExec(nil, args, kwargs, f.Locals, f.Globals, f.Builtins)
+			return builtinExec(nil, args, kwargs, f.GetLocals(), f.Globals, f.Builtins)
 		default:
 			return nil, py.ExceptionNewf(py.SystemError, "Internal method %v not found", x)
 		}
@@ -1623,10 +2367,12 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 	p, q := len(vm.frame.Stack)-2*nkwargs, len(vm.frame.Stack)
 	kwargsTuple := vm.frame.Stack[p:q]
 	p, q = p-nargs, p
-	args := py.Tuple(vm.frame.Stack[p:q])
+	// Copy the args off the stack as the function may keep them
+	args := append(py.Tuple(nil), vm.frame.Stack[p:q]...)
 	p, q = p-1, p
 	fn := vm.frame.Stack[p]
 	// Drop everything off the stack
+	dropped := vm.frame.Stack[p:]
 	vm.frame.Stack = vm.frame.Stack[:p]
 
 	const multipleValues = "%s%s got multiple values for keyword argument '%s'"
@@ -1636,7 +2382,7 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 	if len(kwargsTuple) > 0 {
 		// Convert kwargsTuple into dictionary
 		if len(kwargsTuple)%2 != 0 {
-			panic("vm: Odd length kwargsTuple")
+			return py.ExceptionNewf(py.SystemError, "vm: Odd length kwargsTuple")
 		}
 		kwargs = py.NewStringDict()
 		for i := 0; i < len(kwargsTuple); i += 2 {
@@ -1658,10 +2404,12 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 		if kwargs == nil {
 			kwargs = py.NewStringDict()
 		}
-		// FIXME should be some sort of dictionary iterator...
-		starKwargsDict, ok := starKwargs.(py.StringDict)
-		if !ok {
-			return py.ExceptionNewf(py.SystemError, "FIXME can't use %T as **kwargs", starKwargs)
+		if starKwargs.Type() != py.DictType {
+			return py.ExceptionNewf(py.TypeError, "%s%s argument after ** must be a mapping, not %s", EvalGetFuncName(fn), EvalGetFuncDesc(fn), starKwargs.Type().Name)
+		}
+		starKwargsDict, err := py.AsStringDict(starKwargs)
+		if err != nil {
+			return py.ExceptionNewf(py.TypeError, "%s%s keywords must be strings", EvalGetFuncName(fn), EvalGetFuncDesc(fn))
 		}
 		for k, v := range starKwargsDict {
 			if _, ok := kwargs[k]; ok {
@@ -1673,8 +2421,6 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 
 	// Update with starArgs if any
 	if starArgs != nil {
-		// Copy the args off the stack if there are any
-		args = append([]py.Object(nil), args...)
 		err := py.Iterate(starArgs, func(item py.Object) bool {
 			args = append(args, item)
 			return false
@@ -1685,8 +2431,22 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 	}
 
 	// log.Printf("%s(args=%#v, kwargs=%#v)", EvalGetFuncName(fn), args, kwargs)
+	if hook := evalHook(); hook != nil && hook.Call != nil {
+		err := hook.Call(fn, args, kwargs)
+		if err != nil {
+			clearObjects(dropped)
+			return err
+		}
+	}
 	// Call the function pushing the return on the stack
-	obj, err := callInternal(fn, args, kwargs, vm.frame)
+	var obj py.Object
+	var err error
+	if profiling() && isBuiltin(fn) {
+		obj, err = vm.profileCall(fn, args, kwargs)
+	} else {
+		obj, err = callInternal(fn, args, kwargs, vm.frame)
+	}
+	clearObjects(dropped)
 	if err != nil {
 		return err
 	}
@@ -1697,7 +2457,7 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 // Unwinds the stack for a block
 func (vm *Vm) UnwindBlock(frame *py.Frame, block *py.TryBlock) {
 	if vm.STACK_LEVEL() > block.Level {
-		frame.Stack = frame.Stack[:block.Level]
+		frame.Stack = truncateStack(frame.Stack, block.Level)
 	}
 }
 
@@ -1709,7 +2469,7 @@ func (vm *Vm) UnwindExceptHandler(frame *py.Frame, block *py.TryBlock) {
 	if vm.STACK_LEVEL() < block.Level+3 {
 		panic("vm: Couldn't find traceback on stack")
 	} else {
-		frame.Stack = frame.Stack[:block.Level+3]
+		frame.Stack = truncateStack(frame.Stack, block.Level+3)
 	}
 	if debugging {
 		debugf("** UnwindExceptHandler stack depth now %v\n", vm.STACK_LEVEL())
@@ -1722,6 +2482,9 @@ func (vm *Vm) UnwindExceptHandler(frame *py.Frame, block *py.TryBlock) {
 	}
 }
 
+// The number of frames being run by the thread holding the GIL
+var recursionDepth int
+
 // Run the virtual machine on a Frame object
 //
 // FIXME figure out how we are going to signal exceptions!
@@ -1730,6 +2493,75 @@ func (vm *Vm) UnwindExceptHandler(frame *py.Frame, block *py.TryBlock) {
 //
 // This is the equivalent of PyEval_EvalFrame
 func RunFrame(frame *py.Frame) (res py.Object, err error) {
+	return runFrame(frame, nil)
+}
+
+// RunFrameThrow resumes a suspended generator frame by raising exc
+// at the point where it yielded
+//
+// If the frame is suspended in a yield from then exc is passed to
+// the sub-iterator first.  GeneratorExit closes the sub-iterator and
+// is then raised in the frame.
+//
+// This is the equivalent of the frame handling in gen_throw
+func RunFrameThrow(frame *py.Frame, exc *py.Exception) (res py.Object, err error) {
+	if yf := yieldFrom(frame); yf != nil {
+		if py.IsException(py.GeneratorExit, exc) {
+			err = closeIter(yf)
+			if err != nil {
+				return runFrame(frame, err)
+			}
+			return runFrame(frame, exc)
+		}
+		throw, err := py.GetAttrString(yf, "throw")
+		if err != nil {
+			if !py.IsException(py.AttributeError, err) {
+				return runFrame(frame, err)
+			}
+			return runFrame(frame, exc)
+		}
+		res, err = py.Call(throw, py.Tuple{exc}, nil)
+		if err == nil {
+			// Sub-iterator yielded so we yield its value
+			return res, nil
+		}
+		if !py.IsException(py.StopIteration, err) {
+			return runFrame(frame, err)
+		}
+		// Sub-iterator finished so replace it with its return
+		// value and carry on after the YIELD_FROM
+		frame.Stack[len(frame.Stack)-1] = py.StopIterationValue(err)
+		frame.Lasti++
+		return runFrame(frame, nil)
+	}
+	return runFrame(frame, exc)
+}
+
+// Returns the sub-iterator of a frame suspended in a yield from or
+// nil if it isn't
+func yieldFrom(frame *py.Frame) py.Object {
+	code := frame.Code.Code
+	if frame.Lasti == 0 || int(frame.Lasti) >= len(code) || OpCode(code[frame.Lasti]) != YIELD_FROM || len(frame.Stack) == 0 {
+		return nil
+	}
+	return frame.Stack[len(frame.Stack)-1]
+}
+
+// Calls the close method of iterator if it has one
+func closeIter(iterator py.Object) error {
+	method, err := py.GetAttrString(iterator, "close")
+	if err != nil {
+		if py.IsException(py.AttributeError, err) {
+			return nil
+		}
+		return err
+	}
+	_, err = py.Call(method, nil, nil)
+	return err
+}
+
+// Runs the frame, raising throw in it first if it is not nil
+func runFrame(frame *py.Frame, throw error) (res py.Object, err error) {
 	var vm = Vm{
 		frame: frame,
 	}
@@ -1755,42 +2587,102 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 	if int(frame.Lasti) >= len(frame.Code.Code) {
 		return nil, py.ExceptionNewf(py.SystemError, "vm: instruction out of range - code most likely finished already")
 	}
+	// Only set again if the frame yields before it returns
+	frame.Yielded = false
+
+	if recursionDepth >= py.CurrentContext.RecursionLimit {
+		return nil, py.ExceptionNewf(py.RecursionError, "maximum recursion depth exceeded")
+	}
+	recursionDepth++
+
+	// Link the frame into the stack of running frames
+	frame.Back = py.CurrentFrame
+	py.CurrentFrame = frame
+	frame.Exc = &vm.exc
+
+	// An instruction which panics raises the panic in the frame (see
+	// dispatch) but anything else which does is raised in the caller
+	// rather than crashing
+	defer func() {
+		if r := recover(); r != nil {
+			vm.CheckExceptionRecover(r)
+			py.CurrentFrame = frame.Back
+			recursionDepth--
+			res, err = nil, vm.curexc
+		}
+	}()
+	vm.instrLower, vm.instrUpper, vm.instrPrev = 0, -1, -1
+	if tracing() {
+		err = vm.traceCall()
+		if err != nil {
+			py.CurrentFrame = frame.Back
+			recursionDepth--
+			return nil, err
+		}
+	}
+	if profiling() {
+		err = vm.callProfile("call", py.None)
+		if err != nil {
+			py.CurrentFrame = frame.Back
+			recursionDepth--
+			return nil, err
+		}
+	}
 
-	var opcode OpCode
-	var arg int32
-	opcodes := frame.Code.Code
+	var instr *instruction
+	instrs := decode(frame.Code)
 	for vm.why == whyNot {
+		if throw != nil {
+			// Raise the exception passed in at the resume point
+			err, throw = throw, nil
+			goto on_error
+		}
+		// Let the other threads run if it is time
+		checkSwitch()
+		err = py.CurrentContext.CheckLimits()
+		if err == nil {
+			err = checkCancel()
+		}
+		if err == nil {
+			err = checkPending()
+		}
+		if err != nil {
+			goto on_error
+		}
+		if frame.Trace != nil && tracing() {
+			err = vm.traceLine()
+			if err != nil {
+				goto on_error
+			}
+		}
 		if debugging {
 			debugf("* %4d:", frame.Lasti)
 		}
-		opcode = OpCode(opcodes[frame.Lasti])
-		frame.Lasti++
-		if opcode.HAS_ARG() {
-			arg = int32(opcodes[frame.Lasti])
-			frame.Lasti++
-			arg += int32(opcodes[frame.Lasti]) << 8
-			frame.Lasti++
-			if vm.extended {
-				arg += vm.ext << 16
-			}
-			if debugging {
-				debugf(" %v(%d)\n", opcode, arg)
+		instr = &instrs[frame.Lasti]
+		frame.Lasti = instr.next
+		if debugging {
+			if instr.op.HAS_ARG() {
+				debugf(" %v(%d)\n", instr.op, instr.arg)
+			} else {
+				debugf(" %v\n", instr.op)
 			}
-		} else {
-			if debugging {
-				debugf(" %v\n", opcode)
+		}
+		if hook := evalHook(); hook != nil && hook.Instruction != nil {
+			err = hook.Instruction(frame, instr.op, instr.arg)
+			if err != nil {
+				goto on_error
 			}
 		}
-		vm.extended = false
-		err = jumpTable[opcode](&vm, arg)
+		vm.instr = instr
+		err = vm.dispatch(instr)
+	on_error:
 		if err != nil {
-			// FIXME shouldn't be doing this - just use err?
-			if errExcInfo, ok := err.(py.ExceptionInfo); ok {
-				vm.curexc = errExcInfo
-				vm.AddTraceback(&vm.curexc)
-				vm.why = whyException
-			} else {
-				vm.SetException(py.MakeException(err))
+			vm.setError(err)
+			if frame.Trace != nil && tracing() {
+				err = vm.traceException()
+				if err != nil {
+					vm.setError(err)
+				}
 			}
 		}
 		if debugging {
@@ -1927,6 +2819,23 @@ fast_yield:
 	//         swap_exc_state(tstate, f);
 	// }
 
+	if frame.Trace != nil && tracing() {
+		err = vm.traceReturn(vm.retval)
+		if err != nil && !vm.curexc.IsSet() {
+			vm.retval = nil
+			vm.setError(err)
+		}
+	}
+	if profiling() {
+		err = vm.profileReturn(vm.retval)
+		if err != nil && !vm.curexc.IsSet() {
+			vm.retval = nil
+			vm.setError(err)
+		}
+	}
+	py.CurrentFrame = frame.Back
+	recursionDepth--
+
 	if vm.curexc.IsSet() {
 		return vm.retval, vm.curexc
 	}
@@ -2033,7 +2942,38 @@ func tooManyPositional(co *py.Code, given, defcount int, fastlocals []py.Object)
 		chooseString(given == 1 && kwonly_given == 0, "was", "were"))
 }
 
+// Format an error for positional only arguments passed as keyword
+// arguments or return nil if there weren't any
+func positionalOnlyPassedAsKeyword(co *py.Code, kws py.StringDict) error {
+	var names []string
+	for _, name := range co.Varnames[:co.Posonlyargcount] {
+		if _, ok := kws[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return py.ExceptionNewf(py.TypeError,
+		"%s() got some positional-only arguments passed as keyword arguments: '%s'",
+		co.Name,
+		strings.Join(names, ", "))
+}
+
 func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kws py.StringDict, defs []py.Object, kwdefs py.StringDict, closure py.Tuple) (retval py.Object, err error) {
+	return evalCodeEx(co, globals, locals, nil, args, kws, defs, kwdefs, closure)
+}
+
+// EvalFunction calls the python function f with args and kws, running
+// its code with locals.  The builtins are those of its globals, or if
+// __builtins__ has been deleted from them those it was made with.
+func EvalFunction(f *py.Function, locals py.StringDict, args []py.Object, kws py.StringDict) (retval py.Object, err error) {
+	return evalCodeEx(f.Code, f.Globals, locals, f.Builtins, args, kws, f.Defaults, f.KwDefaults, f.Closure)
+}
+
+// Implements EvalCodeEx, running the code with builtins if globals
+// has no __builtins__ and builtins isn't nil
+func evalCodeEx(co *py.Code, globals, locals, builtins py.StringDict, args []py.Object, kws py.StringDict, defs []py.Object, kwdefs py.StringDict, closure py.Tuple) (retval py.Object, err error) {
 	total_args := int(co.Argcount + co.Kwonlyargcount)
 	n := len(args)
 	var kwdict py.StringDict
@@ -2046,6 +2986,9 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 	//assert(globals != nil)
 	// f = PyFrame_New(tstate, co, globals, locals)
 	f := py.NewFrame(globals, locals, co, closure) // FIXME extra closure parameter?
+	if _, ok := globals["__builtins__"]; !ok && builtins != nil {
+		f.Builtins = builtins
+	}
 
 	fastlocals := f.Localsplus
 	freevars := f.CellAndFreeVars
@@ -2073,13 +3016,17 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 		}
 	}
 	for keyword, value := range kws {
-		j := 0
+		// Positional only arguments can't be passed by keyword
+		j := int(co.Posonlyargcount)
 		for ; j < total_args; j++ {
 			if co.Varnames[j] == keyword {
 				goto kw_found
 			}
 		}
 		if j >= total_args && kwdict == nil {
+			if err := positionalOnlyPassedAsKeyword(co, kws); err != nil {
+				return nil, err
+			}
 			return nil, py.ExceptionNewf(py.TypeError, "%s() got an unexpected keyword argument '%s'", co.Name, keyword)
 		}
 		kwdict[keyword] = value
@@ -2153,6 +3100,12 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 		freevars[len(co.Cellvars)+i] = closure[i]
 	}
 
+	if co.Flags&py.CO_COROUTINE != 0 {
+		/* Create a new coroutine that owns the ready to run frame
+		 * and return that as the value. */
+		return py.NewCoroutine(f), nil
+	}
+
 	if co.Flags&py.CO_GENERATOR != 0 {
 		/* Create a new generator that owns the ready to run frame
 		 * and return that as the value. */
@@ -2187,9 +3140,24 @@ func Run(globals, locals py.StringDict, code *py.Code, closure py.Tuple) (res py
 		nil, closure)
 }
 
+// RunWithBuiltins runs the virtual machine on a Code object like Run
+// but with builtins as the builtins namespace rather than those of the
+// builtins module.
+//
+// This lets an embedder restrict what code can do, eg by running it
+// with py.SandboxBuiltins().  The builtins are stored as
+// __builtins__ in globals so functions the code defines see them too.
+func RunWithBuiltins(globals, locals, builtins py.StringDict, code *py.Code) (res py.Object, err error) {
+	globals["__builtins__"] = builtins
+	return Run(globals, locals, code, nil)
+}
+
 // Write the py global to avoid circular import
 func init() {
 	py.VmRun = Run
 	py.VmRunFrame = RunFrame
+	py.VmRunFrameThrow = RunFrameThrow
 	py.VmEvalCodeEx = EvalCodeEx
+	py.VmAddPendingCall = AddPendingCall
+	py.VmEvalFunction = EvalFunction
 }
@@ -0,0 +1,169 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Global interpreter lock
+//
+// Only one goroutine runs python code at a time - the one holding
+// the GIL.  The interpreter state which belongs to a thread, such as
+// the current frame and Context, is saved when the GIL is released and restored
+// when it is acquired again.
+//
+// The goroutine which starts the interpreter holds the GIL to begin
+// with, so programs which don't use threads need do nothing.  Go code
+// which wants to run python code concurrently must start a python
+// thread with StartThread or RunThread, and Go code which blocks must
+// release the GIL with AllowThreads so the other threads can run.
+//
+// The running thread gives up the GIL to any waiting threads every
+// SwitchInterval instructions.
+
+package vm
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-python/gpython/py"
+)
+
+// SwitchInterval is the number of instructions a thread runs before
+// it lets other threads waiting for the GIL run
+var SwitchInterval = 100
+
+// The interpreter state of a thread
+type threadState struct {
+	ident       int64
+	ctx         *py.Context
+	frame       *py.Frame
+	depth       int
+	insideTrace bool
+	cancel      cancelState
+	reprs       []py.Object
+}
+
+var (
+	gil        sync.Mutex               // held by the goroutine running python code
+	gilWaiting int32                    // number of threads waiting for the GIL
+	gilTicks   int                      // instructions since the GIL was last given up
+	thread     = &threadState{ident: 1} // the thread holding the GIL
+	lastIdent  = int64(1)               // the ident of the last thread made
+	threads    sync.WaitGroup           // non daemon threads still running
+)
+
+// The goroutine which starts the interpreter holds the GIL
+func init() {
+	gil.Lock()
+}
+
+// ThreadIdent returns the identifier of the python thread holding
+// the GIL.  The thread which started the interpreter is 1.
+func ThreadIdent() int64 {
+	return thread.ident
+}
+
+// Releases the GIL, returning the state of the thread to give to
+// acquireGIL
+func releaseGIL() *threadState {
+	ts := thread
+	ts.ctx = py.CurrentContext
+	ts.frame = py.CurrentFrame
+	ts.depth = recursionDepth
+	ts.insideTrace = insideTrace
+	ts.cancel = cancel
+	ts.reprs = py.ReprStack
+	gil.Unlock()
+	return ts
+}
+
+// Waits for the GIL then restores the state of the thread
+func acquireGIL(ts *threadState) {
+	atomic.AddInt32(&gilWaiting, 1)
+	gil.Lock()
+	atomic.AddInt32(&gilWaiting, -1)
+	thread = ts
+	py.CurrentContext = ts.ctx
+	py.CurrentFrame = ts.frame
+	recursionDepth = ts.depth
+	insideTrace = ts.insideTrace
+	cancel = ts.cancel
+	py.ReprStack = ts.reprs
+	gilTicks = 0
+}
+
+// Called by the vm for each instruction to give the other threads a
+// chance to run
+func checkSwitch() {
+	gilTicks++
+	if gilTicks < SwitchInterval {
+		return
+	}
+	gilTicks = 0
+	if atomic.LoadInt32(&gilWaiting) == 0 {
+		return
+	}
+	ts := releaseGIL()
+	runtime.Gosched()
+	acquireGIL(ts)
+}
+
+// AllowThreads calls fn with the GIL released so that other threads
+// can run python code while it blocks.
+//
+// It must be called with the GIL held and fn must not use any python
+// objects.
+func AllowThreads(fn func()) {
+	ts := releaseGIL()
+	defer acquireGIL(ts)
+	fn()
+}
+
+// Makes the state for a new thread running in ctx
+func newThreadState(ctx *py.Context) *threadState {
+	return &threadState{ident: atomic.AddInt64(&lastIdent, 1), ctx: ctx}
+}
+
+// RunThread calls fn as a new python thread running in ctx in the
+// calling goroutine, acquiring the GIL for it.
+//
+// It must be called without the GIL held, for instance from a
+// goroutine started by Go code.
+func RunThread(ctx *py.Context, fn func()) {
+	ts := newThreadState(ctx)
+	acquireGIL(ts)
+	defer releaseGIL()
+	fn()
+}
+
+// StartThread starts a goroutine running fn as a new python thread
+// in the CurrentContext and returns its identifier.  The thread is
+// interrupted when the context.Context of the calling thread, if any,
+// is cancelled.
+//
+// JoinThreads waits for all threads which aren't daemon threads to
+// finish.
+func StartThread(fn func(), daemon bool) int64 {
+	ts := newThreadState(py.CurrentContext)
+	ts.cancel = cancelState{ctx: cancel.ctx, done: cancel.done}
+	if !daemon {
+		threads.Add(1)
+	}
+	go func() {
+		if !daemon {
+			defer threads.Done()
+		}
+		acquireGIL(ts)
+		defer releaseGIL()
+		fn()
+	}()
+	return ts.ident
+}
+
+// JoinThreads waits for all the threads started by StartThread which
+// aren't daemon threads to finish.
+//
+// It must be called with the GIL held.
+func JoinThreads() {
+	AllowThreads(threads.Wait)
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Eval hooks
+//
+// A program embedding gpython can watch, and veto, the operations the
+// bytecode running in a py.Context does by setting an EvalHook for it
+// with SetEvalHook, eg to enforce a security policy, track tainted
+// values or audit in more detail than the audit events of py.Audit.
+//
+// The functions of the hook are called just before the operation they
+// are named after is done by an instruction.  An error returned by one
+// is raised in place of the operation.  Operations done by Go code,
+// such as the getattr builtin or the calls made by map, aren't seen.
+
+package vm
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+// EvalHook holds the functions which observe the operations done by
+// the bytecode running in a context.  Any of them may be nil.
+type EvalHook struct {
+	// Instruction is called before each instruction is run with the
+	// frame running it, its opcode and its argument.  frame.Lasti
+	// is the offset of the next instruction.
+	Instruction func(frame *py.Frame, op OpCode, arg int32) error
+
+	// GetAttr is called before reading obj.name
+	GetAttr func(obj py.Object, name string) error
+
+	// SetAttr is called before setting obj.name to value
+	SetAttr func(obj py.Object, name string, value py.Object) error
+
+	// DelAttr is called before deleting obj.name
+	DelAttr func(obj py.Object, name string) error
+
+	// Call is called before calling fn with args and kwargs
+	Call func(fn py.Object, args py.Tuple, kwargs py.StringDict) error
+
+	// Import is called before an import statement imports the
+	// module name, level being the number of leading dots of a
+	// relative import
+	Import func(name string, level int) error
+}
+
+// The hooks set with SetEvalHook indexed by context
+var evalHooks = map[*py.Context]*EvalHook{}
+
+// SetEvalHook sets hook as the EvalHook of the code running in ctx,
+// replacing any set before.  The hook is removed if hook is nil.
+//
+// It mustn't be called while python code is running in another
+// goroutine.
+func SetEvalHook(ctx *py.Context, hook *EvalHook) {
+	if hook == nil {
+		delete(evalHooks, ctx)
+		return
+	}
+	evalHooks[ctx] = hook
+}
+
+// GetEvalHook returns the EvalHook set for ctx or nil if there isn't
+// one
+func GetEvalHook(ctx *py.Context) *EvalHook {
+	return evalHooks[ctx]
+}
+
+// Returns the EvalHook of the CurrentContext or nil
+func evalHook() *EvalHook {
+	if len(evalHooks) == 0 {
+		return nil
+	}
+	return evalHooks[py.CurrentContext]
+}
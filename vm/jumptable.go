@@ -32,6 +32,7 @@ func init() {
 	jumpTable[BINARY_POWER] = do_BINARY_POWER
 
 	jumpTable[BINARY_MULTIPLY] = do_BINARY_MULTIPLY
+	jumpTable[BINARY_MATRIX_MULTIPLY] = do_BINARY_MATRIX_MULTIPLY
 
 	jumpTable[BINARY_MODULO] = do_BINARY_MODULO
 	jumpTable[BINARY_ADD] = do_BINARY_ADD
@@ -42,10 +43,21 @@ func init() {
 	jumpTable[INPLACE_FLOOR_DIVIDE] = do_INPLACE_FLOOR_DIVIDE
 	jumpTable[INPLACE_TRUE_DIVIDE] = do_INPLACE_TRUE_DIVIDE
 
+	jumpTable[GET_LEN] = do_GET_LEN
+	jumpTable[MATCH_MAPPING] = do_MATCH_MAPPING
+	jumpTable[MATCH_SEQUENCE] = do_MATCH_SEQUENCE
+	jumpTable[MATCH_KEYS] = do_MATCH_KEYS
+	jumpTable[COPY_DICT_WITHOUT_KEYS] = do_COPY_DICT_WITHOUT_KEYS
+
+	jumpTable[GET_AITER] = do_GET_AITER
+	jumpTable[GET_ANEXT] = do_GET_ANEXT
+	jumpTable[BEFORE_ASYNC_WITH] = do_BEFORE_ASYNC_WITH
+
 	jumpTable[STORE_MAP] = do_STORE_MAP
 	jumpTable[INPLACE_ADD] = do_INPLACE_ADD
 	jumpTable[INPLACE_SUBTRACT] = do_INPLACE_SUBTRACT
 	jumpTable[INPLACE_MULTIPLY] = do_INPLACE_MULTIPLY
+	jumpTable[INPLACE_MATRIX_MULTIPLY] = do_INPLACE_MATRIX_MULTIPLY
 
 	jumpTable[INPLACE_MODULO] = do_INPLACE_MODULO
 	jumpTable[STORE_SUBSCR] = do_STORE_SUBSCR
@@ -58,9 +70,13 @@ func init() {
 	jumpTable[BINARY_OR] = do_BINARY_OR
 	jumpTable[INPLACE_POWER] = do_INPLACE_POWER
 	jumpTable[GET_ITER] = do_GET_ITER
+	jumpTable[GET_YIELD_FROM_ITER] = do_GET_YIELD_FROM_ITER
 	jumpTable[PRINT_EXPR] = do_PRINT_EXPR
 	jumpTable[LOAD_BUILD_CLASS] = do_LOAD_BUILD_CLASS
 	jumpTable[YIELD_FROM] = do_YIELD_FROM
+	jumpTable[GET_AWAITABLE] = do_GET_AWAITABLE
+
+	jumpTable[WITH_CLEANUP_START] = do_WITH_CLEANUP_START
 
 	jumpTable[INPLACE_LSHIFT] = do_INPLACE_LSHIFT
 	jumpTable[INPLACE_RSHIFT] = do_INPLACE_RSHIFT
@@ -70,8 +86,11 @@ func init() {
 	jumpTable[BREAK_LOOP] = do_BREAK_LOOP
 	jumpTable[WITH_CLEANUP] = do_WITH_CLEANUP
 
+	jumpTable[WITH_CLEANUP_FINISH] = do_WITH_CLEANUP_FINISH
+
 	jumpTable[RETURN_VALUE] = do_RETURN_VALUE
 	jumpTable[IMPORT_STAR] = do_IMPORT_STAR
+	jumpTable[SETUP_ANNOTATIONS] = do_SETUP_ANNOTATIONS
 
 	jumpTable[YIELD_VALUE] = do_YIELD_VALUE
 	jumpTable[POP_BLOCK] = do_POP_BLOCK
@@ -135,11 +154,22 @@ func init() {
 
 	jumpTable[SETUP_WITH] = do_SETUP_WITH
 
-	jumpTable[EXTENDED_ARG] = do_EXTENDED_ARG
-
 	jumpTable[LIST_APPEND] = do_LIST_APPEND
 	jumpTable[SET_ADD] = do_SET_ADD
 	jumpTable[MAP_ADD] = do_MAP_ADD
 
 	jumpTable[LOAD_CLASSDEREF] = do_LOAD_CLASSDEREF
+
+	jumpTable[BUILD_LIST_UNPACK] = do_BUILD_LIST_UNPACK
+	jumpTable[BUILD_MAP_UNPACK] = do_BUILD_MAP_UNPACK
+	jumpTable[BUILD_MAP_UNPACK_WITH_CALL] = do_BUILD_MAP_UNPACK_WITH_CALL
+	jumpTable[BUILD_TUPLE_UNPACK] = do_BUILD_TUPLE_UNPACK
+	jumpTable[BUILD_SET_UNPACK] = do_BUILD_SET_UNPACK
+
+	jumpTable[SETUP_ASYNC_WITH] = do_SETUP_ASYNC_WITH
+
+	jumpTable[FORMAT_VALUE] = do_FORMAT_VALUE
+	jumpTable[BUILD_STRING] = do_BUILD_STRING
+
+	jumpTable[MATCH_CLASS] = do_MATCH_CLASS
 }
@@ -24,6 +24,9 @@ const (
 
 	UNARY_INVERT OpCode = 15
 
+	BINARY_MATRIX_MULTIPLY  OpCode = 16
+	INPLACE_MATRIX_MULTIPLY OpCode = 17
+
 	BINARY_POWER OpCode = 19
 
 	BINARY_MULTIPLY OpCode = 20
@@ -37,6 +40,16 @@ const (
 	INPLACE_FLOOR_DIVIDE OpCode = 28
 	INPLACE_TRUE_DIVIDE  OpCode = 29
 
+	GET_LEN                OpCode = 30 // New in Python 3.10
+	MATCH_MAPPING          OpCode = 31 // ""
+	MATCH_SEQUENCE         OpCode = 32 // ""
+	MATCH_KEYS             OpCode = 33 // ""
+	COPY_DICT_WITHOUT_KEYS OpCode = 34 // ""
+
+	GET_AITER         OpCode = 50
+	GET_ANEXT         OpCode = 51
+	BEFORE_ASYNC_WITH OpCode = 52
+
 	STORE_MAP        OpCode = 54
 	INPLACE_ADD      OpCode = 55
 	INPLACE_SUBTRACT OpCode = 56
@@ -46,16 +59,20 @@ const (
 	STORE_SUBSCR   OpCode = 60
 	DELETE_SUBSCR  OpCode = 61
 
-	BINARY_LSHIFT    OpCode = 62
-	BINARY_RSHIFT    OpCode = 63
-	BINARY_AND       OpCode = 64
-	BINARY_XOR       OpCode = 65
-	BINARY_OR        OpCode = 66
-	INPLACE_POWER    OpCode = 67
-	GET_ITER         OpCode = 68
-	PRINT_EXPR       OpCode = 70
-	LOAD_BUILD_CLASS OpCode = 71
-	YIELD_FROM       OpCode = 72
+	BINARY_LSHIFT       OpCode = 62
+	BINARY_RSHIFT       OpCode = 63
+	BINARY_AND          OpCode = 64
+	BINARY_XOR          OpCode = 65
+	BINARY_OR           OpCode = 66
+	INPLACE_POWER       OpCode = 67
+	GET_ITER            OpCode = 68
+	GET_YIELD_FROM_ITER OpCode = 69
+	PRINT_EXPR          OpCode = 70
+	LOAD_BUILD_CLASS    OpCode = 71
+	YIELD_FROM          OpCode = 72
+	GET_AWAITABLE       OpCode = 73
+
+	WITH_CLEANUP_START OpCode = 74 // 81 in Python 3.5 but that is WITH_CLEANUP here
 
 	INPLACE_LSHIFT OpCode = 75
 	INPLACE_RSHIFT OpCode = 76
@@ -65,8 +82,11 @@ const (
 	BREAK_LOOP     OpCode = 80
 	WITH_CLEANUP   OpCode = 81
 
-	RETURN_VALUE OpCode = 83
-	IMPORT_STAR  OpCode = 84
+	WITH_CLEANUP_FINISH OpCode = 82
+
+	RETURN_VALUE      OpCode = 83
+	IMPORT_STAR       OpCode = 84
+	SETUP_ANNOTATIONS OpCode = 85
 
 	YIELD_VALUE OpCode = 86
 	POP_BLOCK   OpCode = 87
@@ -143,6 +163,19 @@ const (
 	MAP_ADD     OpCode = 147
 
 	LOAD_CLASSDEREF OpCode = 148 // New in Python 3.4
+
+	BUILD_LIST_UNPACK          OpCode = 149 // Number of iterables, new in Python 3.5
+	BUILD_MAP_UNPACK           OpCode = 150 // Number of mappings, new in Python 3.5
+	BUILD_MAP_UNPACK_WITH_CALL OpCode = 151 // #mappings + (function location<<8), new in Python 3.5
+	BUILD_TUPLE_UNPACK         OpCode = 152 // Number of iterables, new in Python 3.5
+	BUILD_SET_UNPACK           OpCode = 153 // Number of iterables, new in Python 3.5
+
+	SETUP_ASYNC_WITH OpCode = 154 // New in Python 3.5
+
+	FORMAT_VALUE OpCode = 155 // Conversion and format spec flags, new in Python 3.6
+	BUILD_STRING OpCode = 157 // Number of string parts, new in Python 3.6
+
+	MATCH_CLASS OpCode = 159 // Number of positional sub-patterns, 152 in Python 3.10 but that is BUILD_TUPLE_UNPACK here
 )
 
 // Rich comparison opcodes
@@ -161,7 +194,84 @@ const (
 	PyCmp_BAD
 )
 
+// Names of the comparison operators used by COMPARE_OP
+var CmpOp = []string{"<", "<=", "==", "!=", ">", ">=", "in", "not in", "is", "is not", "exception match", "BAD"}
+
 // If op has an argument
 func (op OpCode) HAS_ARG() bool {
 	return op >= HAVE_ARGUMENT
 }
+
+// Name returns the name of the opcode
+func (op OpCode) Name() string {
+	// STORE_NAME has the same value as HAVE_ARGUMENT
+	if op == STORE_NAME {
+		return "STORE_NAME"
+	}
+	return op.String()
+}
+
+// If op has a relative jump target as its argument
+func (op OpCode) HAS_JREL() bool {
+	switch op {
+	case FOR_ITER, JUMP_FORWARD, SETUP_LOOP, SETUP_EXCEPT, SETUP_FINALLY, SETUP_WITH, SETUP_ASYNC_WITH:
+		return true
+	}
+	return false
+}
+
+// If op has an absolute jump target as its argument
+func (op OpCode) HAS_JABS() bool {
+	switch op {
+	case JUMP_IF_FALSE_OR_POP, JUMP_IF_TRUE_OR_POP, JUMP_ABSOLUTE, POP_JUMP_IF_FALSE, POP_JUMP_IF_TRUE, CONTINUE_LOOP:
+		return true
+	}
+	return false
+}
+
+// If op has an index into the constants as its argument
+func (op OpCode) HAS_CONST() bool {
+	return op == LOAD_CONST
+}
+
+// If op has an index into the names as its argument
+func (op OpCode) HAS_NAME() bool {
+	switch op {
+	case STORE_NAME, DELETE_NAME, STORE_ATTR, DELETE_ATTR, STORE_GLOBAL, DELETE_GLOBAL, LOAD_NAME, LOAD_ATTR, IMPORT_NAME, IMPORT_FROM, LOAD_GLOBAL:
+		return true
+	}
+	return false
+}
+
+// If op has an index into the local variables as its argument
+func (op OpCode) HAS_LOCAL() bool {
+	switch op {
+	case LOAD_FAST, STORE_FAST, DELETE_FAST:
+		return true
+	}
+	return false
+}
+
+// If op has a comparison operator as its argument
+func (op OpCode) HAS_COMPARE() bool {
+	return op == COMPARE_OP
+}
+
+// If op has an index into the cell and free variables as its argument
+func (op OpCode) HAS_FREE() bool {
+	switch op {
+	case LOAD_CLOSURE, LOAD_DEREF, STORE_DEREF, DELETE_DEREF, LOAD_CLASSDEREF:
+		return true
+	}
+	return false
+}
+
+// If op has the number of positional and keyword arguments of a call
+// as its argument
+func (op OpCode) HAS_NARGS() bool {
+	switch op {
+	case CALL_FUNCTION, CALL_FUNCTION_VAR, CALL_FUNCTION_KW, CALL_FUNCTION_VAR_KW:
+		return true
+	}
+	return false
+}
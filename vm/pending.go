@@ -0,0 +1,70 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Pending calls
+//
+// Go code which doesn't hold the GIL, such as the goroutine receiving
+// signals, can ask for a function to be run in the main thread with
+// AddPendingCall.  The main thread runs the pending calls before its
+// next instruction and an error they return is raised in the running
+// frame, which is how Ctrl-C raises KeyboardInterrupt.
+
+package vm
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	pendingMu    sync.Mutex
+	pendingCalls []func() error
+	pending      int32                    // set while there are pendingCalls
+	pendingWake  = make(chan struct{}, 1) // wakes Sleep in the main thread
+)
+
+// AddPendingCall arranges for fn to be called with the GIL held by
+// the main thread before it runs its next instruction.  If fn returns
+// an error it is raised in the frame which is running.
+//
+// It may be called from any goroutine, with or without the GIL.
+func AddPendingCall(fn func() error) {
+	pendingMu.Lock()
+	pendingCalls = append(pendingCalls, fn)
+	atomic.StoreInt32(&pending, 1)
+	pendingMu.Unlock()
+	select {
+	case pendingWake <- struct{}{}:
+	default:
+	}
+}
+
+// Called by the vm for each instruction to run the pending calls if
+// there are any and this is the main thread
+func checkPending() error {
+	if atomic.LoadInt32(&pending) == 0 || thread.ident != 1 {
+		return nil
+	}
+	return runPendingCalls()
+}
+
+// Runs the pending calls in the order they were added, stopping at the
+// first which returns an error and leaving the rest for later
+func runPendingCalls() error {
+	for {
+		pendingMu.Lock()
+		if len(pendingCalls) == 0 {
+			atomic.StoreInt32(&pending, 0)
+			pendingMu.Unlock()
+			return nil
+		}
+		fn := pendingCalls[0]
+		pendingCalls = pendingCalls[1:]
+		pendingMu.Unlock()
+		err := fn()
+		if err != nil {
+			return err
+		}
+	}
+}
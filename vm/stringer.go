@@ -19,7 +19,7 @@ func (i vmStatus) String() string {
 	return _vmStatus_name[_vmStatus_index[i]:_vmStatus_index[i+1]]
 }
 
-const _OpCode_name = "POP_TOPROT_TWOROT_THREEDUP_TOPDUP_TOP_TWONOPUNARY_POSITIVEUNARY_NEGATIVEUNARY_NOTUNARY_INVERTBINARY_POWERBINARY_MULTIPLYBINARY_MODULOBINARY_ADDBINARY_SUBTRACTBINARY_SUBSCRBINARY_FLOOR_DIVIDEBINARY_TRUE_DIVIDEINPLACE_FLOOR_DIVIDEINPLACE_TRUE_DIVIDESTORE_MAPINPLACE_ADDINPLACE_SUBTRACTINPLACE_MULTIPLYINPLACE_MODULOSTORE_SUBSCRDELETE_SUBSCRBINARY_LSHIFTBINARY_RSHIFTBINARY_ANDBINARY_XORBINARY_ORINPLACE_POWERGET_ITERPRINT_EXPRLOAD_BUILD_CLASSYIELD_FROMINPLACE_LSHIFTINPLACE_RSHIFTINPLACE_ANDINPLACE_XORINPLACE_ORBREAK_LOOPWITH_CLEANUPRETURN_VALUEIMPORT_STARYIELD_VALUEPOP_BLOCKEND_FINALLYPOP_EXCEPTHAVE_ARGUMENTDELETE_NAMEUNPACK_SEQUENCEFOR_ITERUNPACK_EXSTORE_ATTRDELETE_ATTRSTORE_GLOBALDELETE_GLOBALLOAD_CONSTLOAD_NAMEBUILD_TUPLEBUILD_LISTBUILD_SETBUILD_MAPLOAD_ATTRCOMPARE_OPIMPORT_NAMEIMPORT_FROMJUMP_FORWARDJUMP_IF_FALSE_OR_POPJUMP_IF_TRUE_OR_POPJUMP_ABSOLUTEPOP_JUMP_IF_FALSEPOP_JUMP_IF_TRUELOAD_GLOBALCONTINUE_LOOPSETUP_LOOPSETUP_EXCEPTSETUP_FINALLYLOAD_FASTSTORE_FASTDELETE_FASTRAISE_VARARGSCALL_FUNCTIONMAKE_FUNCTIONBUILD_SLICEMAKE_CLOSURELOAD_CLOSURELOAD_DEREFSTORE_DEREFDELETE_DEREFCALL_FUNCTION_VARCALL_FUNCTION_KWCALL_FUNCTION_VAR_KWSETUP_WITHEXTENDED_ARGLIST_APPENDSET_ADDMAP_ADDLOAD_CLASSDEREF"
+const _OpCode_name = "POP_TOPROT_TWOROT_THREEDUP_TOPDUP_TOP_TWONOPUNARY_POSITIVEUNARY_NEGATIVEUNARY_NOTUNARY_INVERTBINARY_MATRIX_MULTIPLYINPLACE_MATRIX_MULTIPLYBINARY_POWERBINARY_MULTIPLYBINARY_MODULOBINARY_ADDBINARY_SUBTRACTBINARY_SUBSCRBINARY_FLOOR_DIVIDEBINARY_TRUE_DIVIDEINPLACE_FLOOR_DIVIDEINPLACE_TRUE_DIVIDEGET_LENMATCH_MAPPINGMATCH_SEQUENCEMATCH_KEYSCOPY_DICT_WITHOUT_KEYSGET_AITERGET_ANEXTBEFORE_ASYNC_WITHSTORE_MAPINPLACE_ADDINPLACE_SUBTRACTINPLACE_MULTIPLYINPLACE_MODULOSTORE_SUBSCRDELETE_SUBSCRBINARY_LSHIFTBINARY_RSHIFTBINARY_ANDBINARY_XORBINARY_ORINPLACE_POWERGET_ITERGET_YIELD_FROM_ITERPRINT_EXPRLOAD_BUILD_CLASSYIELD_FROMGET_AWAITABLEWITH_CLEANUP_STARTINPLACE_LSHIFTINPLACE_RSHIFTINPLACE_ANDINPLACE_XORINPLACE_ORBREAK_LOOPWITH_CLEANUPWITH_CLEANUP_FINISHRETURN_VALUEIMPORT_STARSETUP_ANNOTATIONSYIELD_VALUEPOP_BLOCKEND_FINALLYPOP_EXCEPTHAVE_ARGUMENTDELETE_NAMEUNPACK_SEQUENCEFOR_ITERUNPACK_EXSTORE_ATTRDELETE_ATTRSTORE_GLOBALDELETE_GLOBALLOAD_CONSTLOAD_NAMEBUILD_TUPLEBUILD_LISTBUILD_SETBUILD_MAPLOAD_ATTRCOMPARE_OPIMPORT_NAMEIMPORT_FROMJUMP_FORWARDJUMP_IF_FALSE_OR_POPJUMP_IF_TRUE_OR_POPJUMP_ABSOLUTEPOP_JUMP_IF_FALSEPOP_JUMP_IF_TRUELOAD_GLOBALCONTINUE_LOOPSETUP_LOOPSETUP_EXCEPTSETUP_FINALLYLOAD_FASTSTORE_FASTDELETE_FASTRAISE_VARARGSCALL_FUNCTIONMAKE_FUNCTIONBUILD_SLICEMAKE_CLOSURELOAD_CLOSURELOAD_DEREFSTORE_DEREFDELETE_DEREFCALL_FUNCTION_VARCALL_FUNCTION_KWCALL_FUNCTION_VAR_KWSETUP_WITHEXTENDED_ARGLIST_APPENDSET_ADDMAP_ADDLOAD_CLASSDEREFBUILD_LIST_UNPACKBUILD_MAP_UNPACKBUILD_MAP_UNPACK_WITH_CALLBUILD_TUPLE_UNPACKBUILD_SET_UNPACKSETUP_ASYNC_WITHFORMAT_VALUEBUILD_STRINGMATCH_CLASS"
 
 var _OpCode_map = map[OpCode]string{
 	1:   _OpCode_name[0:7],
@@ -32,97 +32,121 @@ var _OpCode_map = map[OpCode]string{
 	11:  _OpCode_name[58:72],
 	12:  _OpCode_name[72:81],
 	15:  _OpCode_name[81:93],
-	19:  _OpCode_name[93:105],
-	20:  _OpCode_name[105:120],
-	22:  _OpCode_name[120:133],
-	23:  _OpCode_name[133:143],
-	24:  _OpCode_name[143:158],
-	25:  _OpCode_name[158:171],
-	26:  _OpCode_name[171:190],
-	27:  _OpCode_name[190:208],
-	28:  _OpCode_name[208:228],
-	29:  _OpCode_name[228:247],
-	54:  _OpCode_name[247:256],
-	55:  _OpCode_name[256:267],
-	56:  _OpCode_name[267:283],
-	57:  _OpCode_name[283:299],
-	59:  _OpCode_name[299:313],
-	60:  _OpCode_name[313:325],
-	61:  _OpCode_name[325:338],
-	62:  _OpCode_name[338:351],
-	63:  _OpCode_name[351:364],
-	64:  _OpCode_name[364:374],
-	65:  _OpCode_name[374:384],
-	66:  _OpCode_name[384:393],
-	67:  _OpCode_name[393:406],
-	68:  _OpCode_name[406:414],
-	70:  _OpCode_name[414:424],
-	71:  _OpCode_name[424:440],
-	72:  _OpCode_name[440:450],
-	75:  _OpCode_name[450:464],
-	76:  _OpCode_name[464:478],
-	77:  _OpCode_name[478:489],
-	78:  _OpCode_name[489:500],
-	79:  _OpCode_name[500:510],
-	80:  _OpCode_name[510:520],
-	81:  _OpCode_name[520:532],
-	83:  _OpCode_name[532:544],
-	84:  _OpCode_name[544:555],
-	86:  _OpCode_name[555:566],
-	87:  _OpCode_name[566:575],
-	88:  _OpCode_name[575:586],
-	89:  _OpCode_name[586:596],
-	90:  _OpCode_name[596:609],
-	91:  _OpCode_name[609:620],
-	92:  _OpCode_name[620:635],
-	93:  _OpCode_name[635:643],
-	94:  _OpCode_name[643:652],
-	95:  _OpCode_name[652:662],
-	96:  _OpCode_name[662:673],
-	97:  _OpCode_name[673:685],
-	98:  _OpCode_name[685:698],
-	100: _OpCode_name[698:708],
-	101: _OpCode_name[708:717],
-	102: _OpCode_name[717:728],
-	103: _OpCode_name[728:738],
-	104: _OpCode_name[738:747],
-	105: _OpCode_name[747:756],
-	106: _OpCode_name[756:765],
-	107: _OpCode_name[765:775],
-	108: _OpCode_name[775:786],
-	109: _OpCode_name[786:797],
-	110: _OpCode_name[797:809],
-	111: _OpCode_name[809:829],
-	112: _OpCode_name[829:848],
-	113: _OpCode_name[848:861],
-	114: _OpCode_name[861:878],
-	115: _OpCode_name[878:894],
-	116: _OpCode_name[894:905],
-	119: _OpCode_name[905:918],
-	120: _OpCode_name[918:928],
-	121: _OpCode_name[928:940],
-	122: _OpCode_name[940:953],
-	124: _OpCode_name[953:962],
-	125: _OpCode_name[962:972],
-	126: _OpCode_name[972:983],
-	130: _OpCode_name[983:996],
-	131: _OpCode_name[996:1009],
-	132: _OpCode_name[1009:1022],
-	133: _OpCode_name[1022:1033],
-	134: _OpCode_name[1033:1045],
-	135: _OpCode_name[1045:1057],
-	136: _OpCode_name[1057:1067],
-	137: _OpCode_name[1067:1078],
-	138: _OpCode_name[1078:1090],
-	140: _OpCode_name[1090:1107],
-	141: _OpCode_name[1107:1123],
-	142: _OpCode_name[1123:1143],
-	143: _OpCode_name[1143:1153],
-	144: _OpCode_name[1153:1165],
-	145: _OpCode_name[1165:1176],
-	146: _OpCode_name[1176:1183],
-	147: _OpCode_name[1183:1190],
-	148: _OpCode_name[1190:1205],
+	16:  _OpCode_name[93:115],
+	17:  _OpCode_name[115:138],
+	19:  _OpCode_name[138:150],
+	20:  _OpCode_name[150:165],
+	22:  _OpCode_name[165:178],
+	23:  _OpCode_name[178:188],
+	24:  _OpCode_name[188:203],
+	25:  _OpCode_name[203:216],
+	26:  _OpCode_name[216:235],
+	27:  _OpCode_name[235:253],
+	28:  _OpCode_name[253:273],
+	29:  _OpCode_name[273:292],
+	30:  _OpCode_name[292:299],
+	31:  _OpCode_name[299:312],
+	32:  _OpCode_name[312:326],
+	33:  _OpCode_name[326:336],
+	34:  _OpCode_name[336:358],
+	50:  _OpCode_name[358:367],
+	51:  _OpCode_name[367:376],
+	52:  _OpCode_name[376:393],
+	54:  _OpCode_name[393:402],
+	55:  _OpCode_name[402:413],
+	56:  _OpCode_name[413:429],
+	57:  _OpCode_name[429:445],
+	59:  _OpCode_name[445:459],
+	60:  _OpCode_name[459:471],
+	61:  _OpCode_name[471:484],
+	62:  _OpCode_name[484:497],
+	63:  _OpCode_name[497:510],
+	64:  _OpCode_name[510:520],
+	65:  _OpCode_name[520:530],
+	66:  _OpCode_name[530:539],
+	67:  _OpCode_name[539:552],
+	68:  _OpCode_name[552:560],
+	69:  _OpCode_name[560:579],
+	70:  _OpCode_name[579:589],
+	71:  _OpCode_name[589:605],
+	72:  _OpCode_name[605:615],
+	73:  _OpCode_name[615:628],
+	74:  _OpCode_name[628:646],
+	75:  _OpCode_name[646:660],
+	76:  _OpCode_name[660:674],
+	77:  _OpCode_name[674:685],
+	78:  _OpCode_name[685:696],
+	79:  _OpCode_name[696:706],
+	80:  _OpCode_name[706:716],
+	81:  _OpCode_name[716:728],
+	82:  _OpCode_name[728:747],
+	83:  _OpCode_name[747:759],
+	84:  _OpCode_name[759:770],
+	85:  _OpCode_name[770:787],
+	86:  _OpCode_name[787:798],
+	87:  _OpCode_name[798:807],
+	88:  _OpCode_name[807:818],
+	89:  _OpCode_name[818:828],
+	90:  _OpCode_name[828:841],
+	91:  _OpCode_name[841:852],
+	92:  _OpCode_name[852:867],
+	93:  _OpCode_name[867:875],
+	94:  _OpCode_name[875:884],
+	95:  _OpCode_name[884:894],
+	96:  _OpCode_name[894:905],
+	97:  _OpCode_name[905:917],
+	98:  _OpCode_name[917:930],
+	100: _OpCode_name[930:940],
+	101: _OpCode_name[940:949],
+	102: _OpCode_name[949:960],
+	103: _OpCode_name[960:970],
+	104: _OpCode_name[970:979],
+	105: _OpCode_name[979:988],
+	106: _OpCode_name[988:997],
+	107: _OpCode_name[997:1007],
+	108: _OpCode_name[1007:1018],
+	109: _OpCode_name[1018:1029],
+	110: _OpCode_name[1029:1041],
+	111: _OpCode_name[1041:1061],
+	112: _OpCode_name[1061:1080],
+	113: _OpCode_name[1080:1093],
+	114: _OpCode_name[1093:1110],
+	115: _OpCode_name[1110:1126],
+	116: _OpCode_name[1126:1137],
+	119: _OpCode_name[1137:1150],
+	120: _OpCode_name[1150:1160],
+	121: _OpCode_name[1160:1172],
+	122: _OpCode_name[1172:1185],
+	124: _OpCode_name[1185:1194],
+	125: _OpCode_name[1194:1204],
+	126: _OpCode_name[1204:1215],
+	130: _OpCode_name[1215:1228],
+	131: _OpCode_name[1228:1241],
+	132: _OpCode_name[1241:1254],
+	133: _OpCode_name[1254:1265],
+	134: _OpCode_name[1265:1277],
+	135: _OpCode_name[1277:1289],
+	136: _OpCode_name[1289:1299],
+	137: _OpCode_name[1299:1310],
+	138: _OpCode_name[1310:1322],
+	140: _OpCode_name[1322:1339],
+	141: _OpCode_name[1339:1355],
+	142: _OpCode_name[1355:1375],
+	143: _OpCode_name[1375:1385],
+	144: _OpCode_name[1385:1397],
+	145: _OpCode_name[1397:1408],
+	146: _OpCode_name[1408:1415],
+	147: _OpCode_name[1415:1422],
+	148: _OpCode_name[1422:1437],
+	149: _OpCode_name[1437:1454],
+	150: _OpCode_name[1454:1470],
+	151: _OpCode_name[1470:1496],
+	152: _OpCode_name[1496:1514],
+	153: _OpCode_name[1514:1530],
+	154: _OpCode_name[1530:1546],
+	155: _OpCode_name[1546:1558],
+	157: _OpCode_name[1558:1570],
+	159: _OpCode_name[1570:1581],
 }
 
 func (i OpCode) String() string {
@@ -0,0 +1,81 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="module"
+x: int = 3
+y: "str"
+assert x == 3
+assert __annotations__ == {"x": int, "y": "str"}
+try:
+    y
+except NameError:
+    pass
+else:
+    assert False, "NameError not raised"
+
+doc="class"
+class C:
+    a: int
+    b: list = [1]
+    if True:
+        c: float = 2.0
+    def f(self):
+        d: int = 4
+        return d
+assert C.__annotations__ == {"a": int, "b": list, "c": float}
+assert C.b == [1] and C.c == 2.0
+assert not hasattr(C, "a")
+assert C().f() == 4
+
+doc="no annotations"
+class D:
+    x = 1
+assert not hasattr(D, "__annotations__")
+
+doc="function"
+def f():
+    x: undefined_name
+    y: undefined_name = 5
+    return y
+assert f() == 5
+
+doc="attributes and subscripts"
+class E:
+    pass
+e = E()
+e.x: int = 1
+assert e.x == 1
+d = {}
+d["k"]: int = 4
+assert d == {"k": 4}
+d["j"]: int
+assert d == {"k": 4}
+try:
+    undefined_name.x: int
+except NameError:
+    pass
+else:
+    assert False, "NameError not raised"
+
+doc="parenthesized names"
+(p): int = 1
+assert p == 1
+assert "p" not in __annotations__
+
+doc="errors"
+for src, msg in [
+    ("a, b: int", "only single target (not tuple) can be annotated"),
+    ("[a]: int", "only single target (not list) can be annotated"),
+    ("f(): int", "illegal target for annotation"),
+    ("def f():\n    global x\n    x: int", "annotated name 'x' can't be global"),
+    ("def f():\n    x = 1\n    def g():\n        nonlocal x\n        x: int\n", "annotated name 'x' can't be nonlocal"),
+]:
+    try:
+        compile(src, "<string>", "exec")
+    except SyntaxError as e:
+        assert e.args[0] == msg, (src, e.args)
+    else:
+        assert False, "SyntaxError not raised for %r" % src
+
+doc="finished"
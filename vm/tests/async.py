@@ -0,0 +1,314 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# Drive a coroutine to completion collecting what it suspends with
+def run(coro, sends=None):
+    suspended = []
+    value = None
+    i = 0
+    while True:
+        try:
+            suspended.append(coro.send(value))
+        except StopIteration as e:
+            return e.value, suspended
+        value = sends[i] if sends else None
+        i += 1
+
+class Suspend:
+    def __init__(self, value):
+        self.value = value
+    def __await__(self):
+        result = yield self.value
+        return result
+
+doc="async def makes a coroutine"
+async def simple():
+    return 42
+c = simple()
+assert repr(type(c)) == "<class 'coroutine'>", repr(type(c))
+assert run(c) == (42, [])
+
+doc="coroutines are not iterable"
+c = simple()
+try:
+    iter(c)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    next(c)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+c.close()
+
+doc="await a coroutine"
+async def add(a, b):
+    return a + b
+async def use_add():
+    x = await add(1, 2)
+    y = await add(x, 3)
+    return y
+assert run(use_add()) == (6, [])
+
+doc="await an object with __await__"
+async def suspends():
+    a = await Suspend(1)
+    b = await Suspend(2)
+    return a, b
+assert run(suspends(), ["a", "b"]) == (("a", "b"), [1, 2])
+
+doc="await nested coroutines which suspend"
+async def outer():
+    r1 = await suspends()
+    r2 = await Suspend(3)
+    return r1, r2
+assert run(outer(), ["a", "b", "c"]) == ((("a", "b"), "c"), [1, 2, 3])
+
+doc="await in expressions"
+async def expr():
+    return (await add(1, 2)) * 10 + await add(3, 4)
+assert run(expr()) == (37, [])
+
+doc="await something not awaitable"
+async def bad_await():
+    await 1
+try:
+    run(bad_await())
+except TypeError as e:
+    assert e.args[0] == "object int can't be used in 'await' expression", e.args
+else:
+    assert False, "TypeError not raised"
+
+doc="__await__ returning a non iterator"
+class BadAwait:
+    def __await__(self):
+        return 1
+async def bad_await2():
+    await BadAwait()
+try:
+    run(bad_await2())
+except TypeError as e:
+    assert e.args[0] == "__await__() returned non-iterator of type 'int'", e.args
+else:
+    assert False, "TypeError not raised"
+
+doc="exceptions propagate through await"
+async def raises():
+    raise ValueError("boom")
+async def catches():
+    try:
+        await raises()
+    except ValueError as e:
+        return "caught " + e.args[0]
+assert run(catches()) == ("caught boom", [])
+
+doc="throw into a suspended coroutine"
+async def catch_thrown():
+    try:
+        await Suspend(1)
+    except KeyError:
+        return "caught"
+c = catch_thrown()
+assert c.send(None) == 1
+try:
+    c.throw(KeyError)
+except StopIteration as e:
+    assert e.value == "caught"
+else:
+    assert False, "StopIteration not raised"
+
+doc="close a suspended coroutine"
+state = "not closed"
+async def closing():
+    global state
+    try:
+        await Suspend(1)
+    finally:
+        state = "closed"
+c = closing()
+assert c.send(None) == 1
+c.close()
+assert state == "closed"
+
+doc="send a non-None value to a just-started coroutine"
+try:
+    simple().send(1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="coroutine __await__"
+w = simple().__await__()
+try:
+    next(w)
+except StopIteration as e:
+    assert e.value == 42
+else:
+    assert False, "StopIteration not raised"
+
+doc="async with"
+events = []
+class AsyncContext:
+    def __init__(self, name, suppress=False):
+        self.name = name
+        self.suppress = suppress
+    async def __aenter__(self):
+        events.append("enter " + self.name)
+        await Suspend("aenter " + self.name)
+        return self.name
+    async def __aexit__(self, typ, value, tb):
+        events.append(("exit " + self.name, typ))
+        await Suspend("aexit " + self.name)
+        return self.suppress
+async def use_with():
+    async with AsyncContext("a") as a:
+        events.append("body " + a)
+    return "done"
+assert run(use_with()) == ("done", ["aenter a", "aexit a"])
+assert events == ["enter a", "body a", ("exit a", None)], events
+
+doc="async with multiple items"
+events = []
+async def use_with2():
+    async with AsyncContext("a") as a, AsyncContext("b") as b:
+        events.append("body " + a + b)
+run(use_with2())
+assert events == ["enter a", "enter b", "body ab", ("exit b", None), ("exit a", None)], events
+
+doc="async with exception"
+events = []
+async def use_with3():
+    async with AsyncContext("a"):
+        raise ValueError("boom")
+try:
+    run(use_with3())
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+assert events == ["enter a", ("exit a", ValueError)], events
+
+doc="async with suppressing exception"
+events = []
+async def use_with4():
+    async with AsyncContext("a", suppress=True):
+        raise ValueError("boom")
+    return "suppressed"
+assert run(use_with4())[0] == "suppressed"
+assert events == ["enter a", ("exit a", ValueError)], events
+
+doc="async with return"
+events = []
+async def use_with5():
+    async with AsyncContext("a"):
+        return "returned"
+assert run(use_with5())[0] == "returned"
+assert events == ["enter a", ("exit a", None)], events
+
+doc="async for"
+class AsyncRange:
+    def __init__(self, n):
+        self.i = 0
+        self.n = n
+    def __aiter__(self):
+        return self
+    async def __anext__(self):
+        if self.i >= self.n:
+            raise StopAsyncIteration
+        await Suspend(self.i)
+        self.i += 1
+        return self.i - 1
+async def use_for():
+    out = []
+    async for i in AsyncRange(3):
+        out.append(i)
+    return out
+assert run(use_for()) == ([0, 1, 2], [0, 1, 2])
+
+doc="async for else"
+async def use_for_else():
+    out = []
+    async for i in AsyncRange(2):
+        out.append(i)
+    else:
+        out.append("else")
+    return out
+assert run(use_for_else())[0] == [0, 1, "else"]
+
+doc="async for break and continue"
+async def use_for_break():
+    out = []
+    async for i in AsyncRange(10):
+        if i == 1:
+            continue
+        if i == 3:
+            break
+        out.append(i)
+    else:
+        out.append("else")
+    return out
+assert run(use_for_break())[0] == [0, 2]
+
+doc="async for inside with"
+events = []
+async def use_for_with():
+    out = []
+    async with AsyncContext("a"):
+        async for i in AsyncRange(2):
+            out.append(i)
+    return out
+assert run(use_for_with())[0] == [0, 1]
+assert events == ["enter a", ("exit a", None)], events
+
+doc="async for exception"
+class AsyncRaises:
+    def __aiter__(self):
+        return self
+    async def __anext__(self):
+        raise KeyError("boom")
+async def use_for_raises():
+    async for i in AsyncRaises():
+        pass
+try:
+    run(use_for_raises())
+except KeyError:
+    pass
+else:
+    assert False, "KeyError not raised"
+
+doc="async for without __aiter__"
+async def use_for_bad():
+    async for i in [1, 2, 3]:
+        pass
+try:
+    run(use_for_bad())
+except TypeError as e:
+    assert e.args[0] == "'async for' requires an object with __aiter__ method, got list", e.args
+else:
+    assert False, "TypeError not raised"
+
+doc="syntax errors"
+for src, msg in (
+        ("await x", "'await' outside function"),
+        ("def f():\n    await x", "'await' outside async function"),
+        ("async def f():\n    yield 1", "'yield' inside async function"),
+        ("async def f():\n    yield from x", "'yield from' inside async function"),
+        ("def f():\n    async for x in y: pass", "'async for' outside async function"),
+        ("def f():\n    async with x: pass", "'async with' outside async function"),
+):
+    try:
+        compile(src, "<string>", "exec")
+    except SyntaxError as e:
+        assert e.args[0] == msg, (src, e.args)
+    else:
+        assert False, "SyntaxError not raised for %r" % src
+
+doc="StopAsyncIteration"
+assert issubclass(StopAsyncIteration, Exception)
+
+doc="finished"
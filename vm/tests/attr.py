@@ -34,4 +34,49 @@ assert C.attr1 == 42
 assert c.attr2 == 101
 assert c.attr3 == 102
 
+doc="Test LOAD_ATTR after the class changes"
+class D:
+    x = 1
+def get(o):
+    return o.x
+d = D()
+assert get(d) == 1
+D.x = 2
+assert get(d) == 2
+d.x = 3
+assert get(d) == 3
+del d.x
+assert get(d) == 2
+D.x = property(lambda self: 4)
+d.__dict__["x"] = 5
+assert get(d) == 4
+del D.x
+assert get(d) == 5
+del d.x
+try:
+    get(d)
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+D.__getattr__ = lambda self, name: name
+assert get(d) == "x"
+setattr(D, "x", 6)
+assert get(d) == 6
+
+doc="Test LOAD_ATTR on different types"
+class E:
+    x = "E"
+class F(E):
+    pass
+class G:
+    def __init__(self):
+        self.x = "G"
+class H(E):
+    x = "H"
+for i in range(3):
+    assert [get(o) for o in (E(), F(), G(), H(), E, D(), F)] == ["E", "E", "G", "H", "E", 6, "E"]
+E.x = "E2"
+assert [get(o) for o in (E(), F(), G(), H(), E, F)] == ["E2", "E2", "G", "H", "E2", "E2"]
+
 doc="finished"
@@ -65,4 +65,118 @@ except SyntaxError as e:
 else:
     assert False, "SyntaxError not raised"
 
+doc="eval and exec in functions"
+def f():
+    a = 1
+    return eval("a + 1")
+assert f() == 2
+def f():
+    a = 1
+    exec("b = a + 1")
+    return locals()["b"]
+assert f() == 2
+def f():
+    x = 1
+    class K:
+        x = 2
+        def g(self):
+            return x
+        y = x
+    return K.y, K().g()
+assert f() == (2, 1)
+
+doc="mapping locals"
+class Mapping:
+    def __init__(self):
+        self.d = {}
+    def __getitem__(self, k):
+        return self.d[k]
+    def __setitem__(self, k, v):
+        self.d[k] = v
+    def __delitem__(self, k):
+        del self.d[k]
+loc = Mapping()
+exec("a = 1\nb = a + 1\ndel a\nx: int = 3\nclass C:\n    y = 1\n", {}, loc)
+assert "a" not in loc.d
+assert loc.d["b"] == 2
+assert loc.d["__annotations__"] == {"x": int}
+assert loc.d["C"].y == 1
+assert eval("b + g", {"g": 10}, loc) == 12
+assert eval("locals()", {}, loc) is loc
+try:
+    exec("del a", {}, loc)
+except NameError as e:
+    assert e.args[0] == "name 'a' is not defined", e.args
+else:
+    assert False, "NameError not raised"
+try:
+    eval("exec(1, [])")
+except TypeError as e:
+    assert e.args[0] == "exec() globals must be a dict, not list", e.args
+else:
+    assert False, "TypeError not raised"
+try:
+    exec("1", {}, 1)
+except TypeError as e:
+    assert e.args[0] == "locals must be a mapping or None, not int", e.args
+else:
+    assert False, "TypeError not raised"
+
+doc="__builtins__ override"
+import builtins
+def only_len(x):
+    return len(x)
+glob = {"__builtins__": {"len": len}}
+assert eval("len('abc')", glob) == 3
+try:
+    eval("abs(-1)", glob)
+except NameError as e:
+    assert e.args[0] == "name 'abs' is not defined", e.args
+else:
+    assert False, "NameError not raised"
+
+doc="__builtins__ module"
+glob = {"__builtins__": builtins}
+assert eval("abs(-1)", glob) == 1
+
+doc="__builtins__ replaced in function globals"
+glob = {"__builtins__": {"len": lambda x: 42}}
+exec("def f(x):\n    return len(x)\n", glob)
+assert glob["f"]("abc") == 42
+glob["__builtins__"] = {"len": len}
+assert glob["f"]("abc") == 3
+del glob["__builtins__"]
+assert glob["f"]("abc") == 42
+
+doc="__builtins__ deleted or invalid"
+glob = {"__builtins__": {"len": len}}
+exec("def f(x):\n    return len(x)\ndef g():\n    return abs\n", glob)
+del glob["__builtins__"]
+assert glob["f"]("abc") == 3
+try:
+    glob["g"]()
+except NameError as e:
+    assert e.args[0] == "name 'abs' is not defined", e.args
+else:
+    assert False, "NameError not raised"
+glob["__builtins__"] = None
+try:
+    glob["f"]("abc")
+except NameError as e:
+    assert e.args[0] == "name 'len' is not defined", e.args
+else:
+    assert False, "NameError not raised"
+
+doc="globals shadow __builtins__"
+glob = {"__builtins__": {"len": len}, "len": lambda x: -1}
+assert eval("len('abc')", glob) == -1
+
+doc="no class building without __build_class__"
+try:
+    exec("class A: pass", {"__builtins__": {}})
+except NameError as e:
+    assert e.args[0] == "__build_class__ not found", e.args
+else:
+    assert False, "NameError not raised"
+
 doc="finished"
@@ -54,4 +54,49 @@ def fn4(a, b=None, *args, **kwargs):
     assert kwargs['d'] == 6
 fn4(1, 2, *(3, 4), **{'c':5, 'd':6})
 
+doc="BUILD_TUPLE_UNPACK"
+fn2(*(1,), *[2, 3], 4)
+fn2(1, *(), 2, *(3, 4))
+
+doc="BUILD_MAP_UNPACK_WITH_CALL"
+fn3(1, **{'b':2}, c=3, **{'d':4})
+fn4(*(1, 2), *(3, 4), **{'c':5}, **{'d':6})
+
+ok = False
+try:
+    fn3(1, **{'c':3}, **{'c':4})
+except TypeError as e:
+    assert e.args[0] == "fn3() got multiple values for keyword argument 'c'"
+    ok = True
+assert ok, "TypeError not raised"
+
+ok = False
+try:
+    fn3(1, **{'c':3}, **[4])
+except TypeError as e:
+    assert e.args[0] == "fn3() argument after ** must be a mapping, not list"
+    ok = True
+assert ok, "TypeError not raised"
+
+doc="BUILD_TUPLE_UNPACK with keywords"
+def collect(*args, **kwargs):
+    return args, kwargs
+a, b = [1, 2], [3]
+assert collect(*a, *b, x=1) == ((1, 2, 3), {'x': 1})
+assert collect(1, *a, 3, x=1) == ((1, 1, 2, 3), {'x': 1})
+assert collect(*a, 3, x=1, y=2) == ((1, 2, 3), {'x': 1, 'y': 2})
+assert collect(*a, *b, x=1, **{'y': 2}) == ((1, 2, 3), {'x': 1, 'y': 2})
+fn4(*(1, 2), *(3, 4), c=5, d=6)
+
+def join(*args, sep=' '):
+    return sep.join([str(arg) for arg in args])
+assert join(*a, *b, sep='-') == "1-2-3"
+
+order = []
+def value(x):
+    order.append(x)
+    return x
+collect(value(1), *[value(2)], value(3), x=value(4))
+assert order == [1, 2, 3, 4], order
+
 doc="finished"
@@ -60,4 +60,823 @@ assert c.method1(1) == 2
 # c = x()
 # assert c.method1(1) == 2
 
+doc="__class__"
+class A:
+    def f(self):
+        return "A"
+class B:
+    def f(self):
+        return "B"
+a = A()
+assert a.__class__ is A
+assert A.__class__ is type
+assert (1).__class__ is int
+assert "x".__class__ is str
+
+doc="__class__ assignment"
+a = A()
+a.x = 42
+a.__class__ = B
+assert a.__class__ is B
+assert type(a) is B
+assert isinstance(a, B)
+assert a.f() == "B"
+assert a.x == 42
+a.__class__ = A
+assert a.f() == "A"
+
+class Off:
+    def toggle(self):
+        self.__class__ = On
+        return "on"
+class On:
+    def toggle(self):
+        self.__class__ = Off
+        return "off"
+s = Off()
+assert s.toggle() == "on"
+assert s.toggle() == "off"
+assert s.toggle() == "on"
+assert type(s) is On
+
+def assertClassAssignFails(obj, cls):
+    try:
+        obj.__class__ = cls
+    except TypeError:
+        pass
+    else:
+        assert False, "TypeError not raised"
+
+assertClassAssignFails(A(), 1)
+assertClassAssignFails(A(), int)
+assertClassAssignFails(1, A)
+assertClassAssignFails(object(), A)
+assertClassAssignFails(A, B)
+class E(Exception):
+    pass
+assertClassAssignFails(A(), E)
+
+doc="instance __dict__"
+class A:
+    x = 1
+a = A()
+assert a.__dict__ == {}
+a.y = 2
+assert a.__dict__ == {"y": 2}
+a.__dict__["z"] = 3
+assert a.z == 3
+a.__dict__ = {"w": 4}
+assert a.w == 4
+try:
+    a.y
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+try:
+    a.__dict__ = 1
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="class __dict__ is a mappingproxy"
+class A:
+    x = 1
+    def f(self):
+        pass
+d = A.__dict__
+assert repr(type(d)) == "<class 'mappingproxy'>"
+assert d["x"] == 1
+assert "f" in d
+assert "y" not in d
+assert d.get("x") == 1
+A.y = 2
+assert d["y"] == 2
+setattr(A, "z", 3)
+assert A.__dict__["z"] == 3
+try:
+    A.__dict__["x"] = 10
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    del A.__dict__["x"]
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+assert A.x == 1
+try:
+    A.__dict__ = {}
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+assert type(int.__dict__) is type(A.__dict__)
+
+doc="__slots__"
+class A:
+    __slots__ = ("x", "y")
+a = A()
+a.x = 1
+assert a.x == 1
+try:
+    a.y
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+a.y = 2
+assert a.y == 2
+del a.y
+try:
+    a.y
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+try:
+    del a.y
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+try:
+    a.z = 3
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+try:
+    a.__dict__
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+try:
+    a.__weakref__
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+assert repr(A.x) == "<member 'x' of 'A' objects>"
+b = A()
+b.x = 10
+assert a.x == 1
+
+class S:
+    __slots__ = "x"
+s = S()
+s.x = 1
+assert s.x == 1
+
+doc="__slots__ with __dict__ and __weakref__"
+class A:
+    __slots__ = ("x", "__dict__")
+a = A()
+a.x = 1
+a.z = 2
+assert a.z == 2
+assert a.__dict__ == {"z": 2}
+try:
+    a.__weakref__
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+
+class W:
+    __slots__ = ("x", "__weakref__")
+w = W()
+assert w.__weakref__ is None
+try:
+    w.z = 2
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+
+class N:
+    pass
+assert N().__weakref__ is None
+
+doc="__slots__ errors"
+try:
+    class A:
+        __slots__ = ("x", 1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    class A:
+        __slots__ = ("not an identifier",)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    class A:
+        __slots__ = ("x",)
+        x = 1
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+class D:
+    pass
+try:
+    class E(D):
+        __slots__ = ("__dict__",)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    class E(D):
+        __slots__ = ("__weakref__",)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="__slots__ inheritance"
+class A:
+    __slots__ = ("x",)
+class B(A):
+    __slots__ = ("y",)
+b = B()
+b.x = 1
+b.y = 2
+assert b.x == 1 and b.y == 2
+try:
+    b.z = 3
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+class C(A):
+    pass
+c = C()
+c.x = 1
+c.z = 3
+assert c.x == 1 and c.z == 3
+assert c.__dict__ == {"z": 3}
+del b.x
+assert b.y == 2
+try:
+    b.x
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+class D:
+    __slots__ = ("d",)
+try:
+    class E(A, D):
+        pass
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+class F(A, object):
+    pass
+f = F()
+f.x = 1
+assert f.x == 1
+
+doc="__class__ assignment with __slots__"
+class P:
+    __slots__ = ("p",)
+class Q:
+    __slots__ = ("p",)
+class R:
+    __slots__ = ("r",)
+p = P()
+p.p = 1
+p.__class__ = Q
+assert type(p) is Q
+assert p.p == 1
+try:
+    p.__class__ = R
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+class X:
+    pass
+try:
+    p.__class__ = X
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="class attributes from base classes"
+class Base:
+    x = 1
+    def f(self):
+        return "f"
+class Derived(Base):
+    pass
+assert Derived.x == 1
+assert Derived().x == 1
+assert Derived.f(Derived()) == "f"
+
+doc="non data descriptor"
+class NonData:
+    def __get__(self, instance, owner):
+        return (instance, owner)
+class HasNonData:
+    d = NonData()
+h = HasNonData()
+assert h.d == (h, HasNonData)
+assert HasNonData.d == (None, HasNonData)
+h.d = 1
+assert h.d == 1
+del h.d
+assert h.d == (h, HasNonData)
+
+doc="data descriptor"
+class Data:
+    def __init__(self):
+        self.log = []
+    def __get__(self, instance, owner):
+        self.log.append("get")
+        if instance is None:
+            return self
+        return instance._value
+    def __set__(self, instance, value):
+        self.log.append("set")
+        instance._value = value * 2
+    def __delete__(self, instance):
+        self.log.append("delete")
+        instance._value = None
+class HasData:
+    d = Data()
+h = HasData()
+h.d = 2
+assert h.d == 4
+h.__dict__["d"] = "shadowed"
+assert h.d == 4
+del h.d
+assert h.d is None
+assert HasData.__dict__["d"].log == ["set", "get", "get", "delete", "get"]
+
+doc="descriptor inherited"
+class HasDataChild(HasData):
+    pass
+h = HasDataChild()
+h.d = 5
+assert h.d == 10
+
+doc="set only data descriptor"
+class SetOnly:
+    def __set__(self, instance, value):
+        instance.__dict__["seen"] = value
+class HasSetOnly:
+    s = SetOnly()
+h = HasSetOnly()
+h.s = 7
+assert h.seen == 7
+assert isinstance(h.s, SetOnly)
+
+doc="__setattr__ delegating to object"
+class Upper:
+    def __setattr__(self, name, value):
+        super().__setattr__(name, value.upper())
+u = Upper()
+u.x = "hi"
+assert u.x == "HI"
+assert u.__dict__ == {"x": "HI"}
+class Logged:
+    def __init__(self):
+        object.__setattr__(self, "log", [])
+    def __setattr__(self, name, value):
+        self.log.append(name)
+        object.__setattr__(self, name, value)
+    @property
+    def p(self):
+        return self._p
+    @p.setter
+    def p(self, value):
+        object.__setattr__(self, "_p", value * 2)
+l = Logged()
+l.a = 1
+l.p = 2
+assert l.a == 1
+assert l.p == 4
+assert l.log == ["a", "p"]
+ok = False
+try:
+    object.__setattr__(1, "x", 2)
+except AttributeError:
+    ok = True
+assert ok, "AttributeError not raised"
+
+doc="__delattr__ delegating to object"
+class NoDelete:
+    def __delattr__(self, name):
+        if name == "keep":
+            raise AttributeError("can't delete keep")
+        super().__delattr__(name)
+n = NoDelete()
+n.keep = 1
+n.gone = 2
+del n.gone
+assert not hasattr(n, "gone")
+ok = False
+try:
+    del n.keep
+except AttributeError:
+    ok = True
+assert ok, "AttributeError not raised"
+assert n.keep == 1
+
+doc="__getattribute__ delegating to object"
+class Counted:
+    def __init__(self):
+        self.x = 1
+        self.lookups = 0
+    def __getattribute__(self, name):
+        if name != "lookups":
+            object.__setattr__(self, "lookups", object.__getattribute__(self, "lookups") + 1)
+        return super().__getattribute__(name)
+    def method(self):
+        return "method"
+c = Counted()
+assert c.x == 1
+assert c.method() == "method"
+assert c.lookups == 2
+ok = False
+try:
+    c.missing
+except AttributeError:
+    ok = True
+assert ok, "AttributeError not raised"
+
+doc="type.__setattr__ from a metaclass"
+class Recording(type):
+    def __setattr__(cls, name, value):
+        cls.recorded.append(name)
+        super().__setattr__(name, value)
+    def __delattr__(cls, name):
+        cls.recorded.append("del " + name)
+        type.__delattr__(cls, name)
+class Recorded(metaclass=Recording):
+    recorded = []
+    def f(self):
+        return 1
+r = Recorded()
+results = []
+for i in range(2):
+    results.append(r.f())
+    Recorded.f = lambda self: 2
+assert results == [1, 2]
+Recorded.__name__ = "Renamed"
+assert Recorded.__name__ == "Renamed"
+del Recorded.f
+assert not hasattr(r, "f")
+assert Recorded.recorded == ["f", "f", "__name__", "del f"]
+ok = False
+try:
+    object.__setattr__(Recorded, "x", 1)
+except TypeError:
+    ok = True
+assert ok, "TypeError not raised"
+ok = False
+try:
+    type.__setattr__(r, "x", 1)
+except TypeError:
+    ok = True
+assert ok, "TypeError not raised"
+
+doc="str of classes uses the metaclass"
+class StrInstances:
+    def __str__(self):
+        return "instance"
+assert str(StrInstances()) == "instance"
+assert str(StrInstances) == "<class 'StrInstances'>"
+class StrError(Exception):
+    pass
+assert str(StrError) == "<class 'StrError'>"
+class ReprMeta(type):
+    def __repr__(cls):
+        return "class " + cls.__name__
+class WithReprMeta(metaclass=ReprMeta):
+    pass
+assert repr(WithReprMeta) == "class WithReprMeta"
+assert str(WithReprMeta) == "class WithReprMeta"
+
+doc="callable instances"
+class Adder:
+    def __init__(self, n):
+        self.n = n
+    def __call__(self, x, y=0):
+        return self.n + x + y
+add = Adder(1)
+assert add(2) == 3
+assert add(2, y=3) == 6
+class NotCallable:
+    pass
+try:
+    NotCallable()()
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="rich comparison"
+class Num:
+    def __init__(self, v):
+        self.v = v
+    def __lt__(self, other):
+        return self.v < other.v
+    def __eq__(self, other):
+        return isinstance(other, Num) and self.v == other.v
+assert Num(1) < Num(2)
+assert not (Num(2) < Num(1))
+assert Num(2) > Num(1)
+assert Num(1) == Num(1)
+assert Num(1) != Num(2)
+assert not (Num(1) != Num(1))
+assert Num(1) != 1
+assert [n.v for n in sorted([Num(3), Num(1), Num(2)])] == [1, 2, 3]
+assert min(Num(3), Num(1)).v == 1
+assert max([Num(3), Num(5), Num(4)]).v == 5
+try:
+    Num(1) <= Num(2)
+except TypeError as e:
+    assert str(e) == "'<=' not supported between instances of 'Num' and 'Num'"
+else:
+    assert False, "TypeError not raised"
+
+class Reflected:
+    def __gt__(self, other):
+        return "gt"
+    def __le__(self, other):
+        return NotImplemented
+assert (1 < Reflected()) == "gt"
+assert (Reflected() > 1) == "gt"
+try:
+    Reflected() <= 1
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+class Plain:
+    pass
+p = Plain()
+assert p == p
+assert not (p != p)
+assert p != Plain()
+assert not (p == Plain())
+try:
+    p < Plain()
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+class Base:
+    def __lt__(self, other):
+        return "Base.lt"
+    def __gt__(self, other):
+        return "Base.gt"
+class Derived(Base):
+    def __lt__(self, other):
+        return "Derived.lt"
+    def __gt__(self, other):
+        return "Derived.gt"
+assert (Base() < Derived()) == "Derived.gt"
+assert (Derived() < Base()) == "Derived.lt"
+
+class NotEq:
+    def __eq__(self, other):
+        return NotImplemented
+n = NotEq()
+assert n == n
+assert not (n == NotEq())
+assert not (n != n)
+
+class OnlyEq:
+    def __init__(self, v):
+        self.v = v
+    def __eq__(self, other):
+        return self.v == other.v
+assert OnlyEq(1) != OnlyEq(2)
+assert not (OnlyEq(1) != OnlyEq(1))
+
+doc="binary operators"
+class Num:
+    def __init__(self, v):
+        self.v = v
+    def __add__(self, other):
+        return Num(self.v + other)
+    def __radd__(self, other):
+        return Num(other + self.v)
+    def __sub__(self, other):
+        return self.v - other
+    def __rsub__(self, other):
+        return other - self.v
+    def __mul__(self, other):
+        return self.v * other
+    __rmul__ = __mul__
+    def __matmul__(self, other):
+        return ("matmul", self.v, other)
+    def __rmatmul__(self, other):
+        return ("rmatmul", other, self.v)
+    def __truediv__(self, other):
+        return self.v / other
+    def __rtruediv__(self, other):
+        return other / self.v
+    def __floordiv__(self, other):
+        return self.v // other
+    def __rfloordiv__(self, other):
+        return other // self.v
+    def __mod__(self, other):
+        return self.v % other
+    def __rmod__(self, other):
+        return other % self.v
+    def __divmod__(self, other):
+        return divmod(self.v, other)
+    def __rdivmod__(self, other):
+        return divmod(other, self.v)
+    def __pow__(self, other, modulo=None):
+        return pow(self.v, other, modulo)
+    def __rpow__(self, other):
+        return other ** self.v
+    def __lshift__(self, other):
+        return self.v << other
+    def __rlshift__(self, other):
+        return other << self.v
+    def __rshift__(self, other):
+        return self.v >> other
+    def __rrshift__(self, other):
+        return other >> self.v
+    def __and__(self, other):
+        return self.v & other
+    def __rand__(self, other):
+        return other & self.v
+    def __or__(self, other):
+        return self.v | other
+    def __ror__(self, other):
+        return other | self.v
+    def __xor__(self, other):
+        return self.v ^ other
+    def __rxor__(self, other):
+        return other ^ self.v
+    def __neg__(self):
+        return -self.v
+    def __pos__(self):
+        return "pos"
+    def __abs__(self):
+        return "abs"
+    def __invert__(self):
+        return ~self.v
+n = Num(7)
+assert (n + 1).v == 8
+assert (1 + n).v == 8
+assert n - 2 == 5
+assert 10 - n == 3
+assert n * 2 == 14
+assert 3 * n == 21
+assert n @ 2 == ("matmul", 7, 2)
+assert 2 @ n == ("rmatmul", 2, 7)
+assert n / 2 == 3.5
+assert 14 / n == 2.0
+assert n // 2 == 3
+assert 15 // n == 2
+assert n % 4 == 3
+assert 15 % n == 1
+assert divmod(n, 2) == (3, 1)
+assert divmod(20, n) == (2, 6)
+assert n ** 2 == 49
+assert pow(n, 2, 5) == 4
+assert 2 ** n == 128
+assert n << 1 == 14
+assert 1 << n == 128
+assert n >> 1 == 3
+assert 256 >> n == 2
+assert n & 3 == 3
+assert 3 & n == 3
+assert n | 8 == 15
+assert 8 | n == 15
+assert n ^ 1 == 6
+assert 1 ^ n == 6
+assert -n == -7
+assert +n == "pos"
+assert abs(n) == "abs"
+assert ~n == -8
+assert sum([1, 2], Num(0)).v == 3
+
+class Base:
+    def __add__(self, other):
+        return "Base.add"
+    def __radd__(self, other):
+        return "Base.radd"
+class Derived(Base):
+    def __radd__(self, other):
+        return "Derived.radd"
+assert Base() + Derived() == "Derived.radd"
+assert Derived() + Base() == "Base.add"
+
+class Plain:
+    pass
+try:
+    Plain() + 1
+except TypeError as e:
+    assert str(e) == "unsupported operand type(s) for +: 'Plain' and 'int'"
+else:
+    assert False, "TypeError not raised"
+try:
+    -Plain()
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    [] @ []
+except TypeError as e:
+    assert str(e) == "unsupported operand type(s) for @: 'list' and 'list'"
+else:
+    assert False, "TypeError not raised"
+
+doc="inplace operators"
+class Acc:
+    def __init__(self):
+        self.items = []
+    def __iadd__(self, other):
+        self.items.append(other)
+        return self
+    def __imatmul__(self, other):
+        self.items.append(("@", other))
+        return self
+    def __ipow__(self, other):
+        self.items.append(("**", other))
+        return self
+a = Acc()
+b = a
+a += 1
+a @= 2
+a **= 3
+assert a is b
+assert a.items == [1, ("@", 2), ("**", 3)]
+
+x = Num(1)
+y = x
+x += 1
+assert x.v == 2 and y.v == 1
+x = 5
+x += Num(1)
+assert x.v == 6
+x = Num(3)
+x @= 4
+assert x == ("matmul", 3, 4)
+
+class NotImpl:
+    def __iadd__(self, other):
+        return NotImplemented
+    def __add__(self, other):
+        return NotImplemented
+class Right:
+    def __radd__(self, other):
+        return "Right.radd"
+x = NotImpl()
+x += Right()
+assert x == "Right.radd"
+
+
+doc="__bool__, __len__ and conversions"
+class Truth:
+    def __init__(self, v):
+        self.v = v
+    def __bool__(self):
+        return self.v
+    def __neg__(self):
+        return "neg"
+    def __int__(self):
+        return 7
+    def __float__(self):
+        return 1.5
+assert Truth(True)
+assert not Truth(False)
+try:
+    if Truth(1):
+        pass
+except TypeError as e:
+    assert str(e) == "__bool__ should return bool, returned int", str(e)
+else:
+    assert False, "TypeError not raised"
+assert -Truth(1) == "neg"
+assert int(Truth(1)) == 7
+assert float(Truth(1)) == 1.5
+class Length:
+    def __len__(self):
+        return 0
+assert not Length()
+
 doc="finished"
@@ -29,4 +29,120 @@ A = {"a":1, "b":2, "c":3}
 B = { k:k for k in ("a","b","c") }
 assert B["b"] == "b"
 
+doc="Comprehension variables don't leak"
+x = 10
+L = [x for x in range(3)]
+assert L == [0, 1, 2]
+assert x == 10
+[leak for leak in range(3)]
+try:
+    leak
+except NameError:
+    pass
+else:
+    assert False, "comprehension variable leaked"
+try:
+    del leak
+except NameError:
+    pass
+else:
+    assert False, "comprehension variable leaked"
+{leak for leak in range(3)}
+{leak:leak for leak in ("a", "b")}
+tuple(leak for leak in range(3))
+try:
+    leak
+except NameError:
+    pass
+else:
+    assert False, "comprehension variable leaked"
+
+def fn():
+    [q for q in range(3)]
+    try:
+        del q
+    except NameError:
+        pass
+    else:
+        assert False, "comprehension variable leaked into function"
+    tuple(r for r in range(3))
+    try:
+        r
+    except NameError:
+        pass
+    else:
+        assert False, "generator variable leaked into function"
+fn()
+
+class C:
+    a = [i for i in range(3)]
+    b = {j for j in range(3)}
+assert C.a == [0, 1, 2]
+assert not hasattr(C, "i")
+assert not hasattr(C, "j")
+
+doc="Nested comprehensions"
+A = [[a*b for b in range(2)] for a in range(3)]
+assert A == [[0, 0], [0, 1], [0, 2]]
+try:
+    a
+except NameError:
+    pass
+else:
+    assert False, "nested comprehension variable leaked"
+
+def fn(n):
+    return [[(i, j) for j in range(n)] for i in range(n)]
+assert fn(2) == [[(0, 0), (0, 1)], [(1, 0), (1, 1)]]
+
+def fn(n):
+    return {i: {j * n for j in range(i)} for i in range(n)}
+assert fn(3) == {0: set(), 1: {0}, 2: {0, 3}}
+
+def fn(n):
+    fns = [[lambda: (i, j) for j in range(n)] for i in range(n)]
+    return [[f() for f in row] for row in fns]
+assert fn(2) == [[(1, 1), (1, 1)], [(1, 1), (1, 1)]]
+
+def fn():
+    a = 1
+    def inner():
+        return [[a + b + c for c in range(2)] for b in range(2)]
+    return inner()
+assert fn() == [[1, 2], [2, 3]]
+
+doc="Multiple for and if clauses"
+A = [(x, y) for x in range(3) if x for y in range(3) if y != x if y]
+assert A == [(1, 2), (2, 1)]
+B = {(x, y) for x in range(2) for y in range(2) if x <= y}
+assert B == {(0, 0), (0, 1), (1, 1)}
+C = {x: y for x, y in zip("abc", range(3)) if y}
+assert C == {"b": 1, "c": 2}
+D = list(x * y for x in range(3) for y in (z * 10 for z in range(2)))
+assert D == [0, 0, 0, 10, 0, 20]
+
+doc="Generator expression arguments"
+assert sum(x for x in range(5)) == 10
+assert sorted((x for x in [3, 1, 2]), reverse=True) == [3, 2, 1]
+assert "".join(c.upper() for c in "abc") == "ABC"
+for src in ("f(x for x in y, 1)", "f(1, x for x in y)", "f(x for x in y,)", "f(a=1, x for x in y)"):
+    try:
+        compile(src, "<string>", "exec")
+    except SyntaxError as e:
+        assert e.args[0] == "Generator expression must be parenthesized", e.args[0]
+    else:
+        assert False, "SyntaxError not raised for %s" % src
+
+doc="yield inside comprehension"
+for src, kind in (("[x for y in z for x in (yield)]", "list comprehension"),
+                  ("{(yield) for x in y}", "set comprehension"),
+                  ("{x: (yield) for x in y}", "dict comprehension"),
+                  ("((yield) for x in y)", "generator expression")):
+    try:
+        compile("def f():\n    " + src, "<string>", "exec")
+    except SyntaxError as e:
+        assert e.args[0] == "'yield' inside " + kind, e.args[0]
+    else:
+        assert False, "SyntaxError not raised for %s" % src
+
 doc="finished"
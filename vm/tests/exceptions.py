@@ -165,4 +165,216 @@ except ValueError:
     ok = True
 assert ok, "ValueError not raised"
 
+doc = "catch by base class"
+ok = False
+try:
+    print(1/0)
+except ArithmeticError:
+    ok = True
+assert ok, "ZeroDivisionError not caught as ArithmeticError"
+
+ok = False
+try:
+    [][1]
+except LookupError:
+    ok = True
+assert ok, "IndexError not caught as LookupError"
+
+ok = False
+try:
+    raise KeyError("x")
+except Exception:
+    ok = True
+assert ok, "KeyError not caught as Exception"
+
+doc = "subclassed exceptions"
+class MyError(ValueError):
+    pass
+ok = False
+try:
+    raise MyError("boom")
+except MyError as e:
+    assert type(e) is MyError
+    assert e.args == ("boom",)
+    ok = True
+assert ok, "MyError not caught"
+
+ok = False
+try:
+    raise MyError
+except ValueError:
+    ok = True
+assert ok, "MyError not caught as ValueError"
+
+doc = "exception attributes"
+e = ValueError("a", 2)
+assert e.args == ("a", 2)
+assert str(e) == "('a', 2)"
+assert str(ValueError()) == ""
+assert str(ValueError("msg")) == "msg"
+assert repr(ValueError("msg")) == "ValueError('msg')"
+assert str(KeyError("k")) == "'k'"
+assert e.__cause__ is None
+assert e.__context__ is None
+assert e.__traceback__ is None
+assert e.__suppress_context__ is False
+e.extra = 42
+assert e.extra == 42
+ok = False
+try:
+    e.missing
+except AttributeError:
+    ok = True
+assert ok, "AttributeError not raised"
+
+doc = "exception with user __init__ and __str__"
+class DetailError(Exception):
+    def __init__(self, code):
+        super().__init__("code", code)
+        self.code = code
+    def __str__(self):
+        return "error %d" % self.code
+try:
+    raise DetailError(7)
+except DetailError as e:
+    assert e.code == 7
+    assert e.args == ("code", 7)
+    assert str(e) == "error 7"
+
+doc = "raise class calls it"
+try:
+    raise DetailError
+except TypeError:
+    pass
+
+doc = "raise non exception"
+ok = False
+try:
+    raise 42
+except TypeError:
+    ok = True
+assert ok, "TypeError not raised"
+
+doc = "__traceback__"
+def raises():
+    raise ValueError("tb")
+try:
+    raises()
+except ValueError as e:
+    tb = e.__traceback__
+    assert tb is not None
+    assert tb.tb_frame is not None
+    assert tb.tb_next is not None
+    assert tb.tb_next.tb_next is None
+    assert tb.tb_next.tb_lineno == tb.tb_lineno - 2
+
+e = ValueError()
+assert e.with_traceback(tb) is e
+assert e.__traceback__ is tb
+e.__traceback__ = None
+assert e.__traceback__ is None
+ok = False
+try:
+    e.__traceback__ = 1
+except TypeError:
+    ok = True
+assert ok, "TypeError not raised"
+
+doc = "raise from"
+cause = KeyError("cause")
+try:
+    raise ValueError("effect") from cause
+except ValueError as e:
+    assert e.__cause__ is cause
+    assert e.__suppress_context__ is True
+
+try:
+    raise ValueError from KeyError
+except ValueError as e:
+    assert type(e.__cause__) is KeyError
+
+try:
+    try:
+        raise KeyError
+    except KeyError:
+        raise ValueError from None
+except ValueError as e:
+    assert e.__cause__ is None
+    assert type(e.__context__) is KeyError
+    assert e.__suppress_context__ is True
+
+ok = False
+try:
+    raise ValueError from 1
+except TypeError:
+    ok = True
+assert ok, "TypeError not raised"
+
+e = ValueError()
+e.__cause__ = KeyError()
+assert e.__suppress_context__ is True
+ok = False
+try:
+    e.__cause__ = 1
+except TypeError:
+    ok = True
+assert ok, "TypeError not raised"
+
+doc = "implicit chaining"
+try:
+    try:
+        raise KeyError("first")
+    except KeyError as e:
+        first = e
+        raise ValueError("second")
+except ValueError as e:
+    assert e.__context__ is first
+    assert e.__cause__ is None
+    assert e.__suppress_context__ is False
+
+def fails():
+    return 1/0
+try:
+    try:
+        raise KeyError("first")
+    except KeyError as e:
+        first = e
+        fails()
+except ZeroDivisionError as e:
+    assert e.__context__ is first
+
+try:
+    raise ValueError("unchained")
+except ValueError as e:
+    assert e.__context__ is None
+
+doc = "chaining across frames"
+def handler():
+    try:
+        raise KeyError("outer")
+    except KeyError:
+        inner()
+def inner():
+    raise ValueError("inner")
+try:
+    handler()
+except ValueError as e:
+    assert type(e.__context__) is KeyError
+
+doc = "no context cycle"
+try:
+    try:
+        raise KeyError
+    except KeyError as e:
+        a = e
+        try:
+            raise ValueError
+        except ValueError as e:
+            b = e
+            raise a
+except KeyError as e:
+    assert e is a
+    assert e.__context__ is b
+    assert b.__context__ is None
+
 doc = "finished"
@@ -0,0 +1,126 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="plain f-strings"
+assert f"" == ""
+assert f"abc" == "abc"
+assert F"abc" == "abc"
+assert f"{{}}" == "{}"
+assert f"{{{1}}}" == "{1}"
+
+doc="expressions"
+x = 42
+name = "world"
+assert f"hello {name}!" == "hello world!"
+assert f"{x}{x}" == "4242"
+assert f"{x + 1}" == "43"
+assert f"{[1, 2, 3][1]}" == "2"
+assert f"{ {'a': 1}['a'] }" == "1"
+assert f"{x if x else 0}" == "42"
+assert f"{x != 1}" == "True"
+assert f"{(lambda y: y * 2)(x)}" == "84"
+assert f"{'}'}" == "}"
+assert f"{len(name)}" == "5"
+
+doc="closures"
+def outer():
+    y = 3
+    def inner():
+        return f"{y}-{y * 2}"
+    return inner()
+assert outer() == "3-6"
+
+doc="conversions"
+assert f"{name!r}" == "'world'"
+assert f"{name!s}" == "world"
+cafe = "caf\xe9"
+assert f"{cafe!a}" == "'caf\\xe9'"
+assert f"{name!r:>9}" == "  'world'"
+
+doc="format specs"
+assert f"{x:>6}" == "    42"
+assert f"{x:<6}|" == "42    |"
+assert f"{x:^6}" == "  42  "
+assert f"{x:06}" == "000042"
+assert f"{x:+}" == "+42"
+assert f"{x:x}" == "2a"
+assert f"{x:#o}" == "0o52"
+assert f"{x:b}" == "101010"
+assert f"{1234567:,}" == "1,234,567"
+assert f"{1234567:_}" == "1_234_567"
+assert f"{0xdeadbeef:#_x}" == "0xdead_beef"
+assert f"{3.14159:.2f}" == "3.14"
+assert f"{3.14159:>10.2f}" == "      3.14"
+assert f"{-3.5:08.2f}" == "-0003.50"
+assert f"{2.5:e}" == "2.500000e+00"
+assert f"{0.5:%}" == "50.000000%"
+assert f"{123456789.0:g}" == "1.23457e+08"
+assert f"{'abc':.2}" == "ab"
+
+doc="nested format specs"
+width = 10
+precision = 3
+assert f"{3.14159:{width}.{precision}}" == "      3.14"
+assert f"{'x':{'*'}^{width}}" == "****x*****"
+
+doc="__format__"
+class A:
+    def __format__(self, spec):
+        return "A(" + spec + ")"
+assert f"{A()}" == "A()"
+assert f"{A():xyz}" == "A(xyz)"
+assert f"{A():{x}}" == "A(42)"
+
+doc="raw and escapes"
+assert f"\t{x}\x41" == "\t42A"
+assert rf"\n{x}" == "\\n42"
+assert fR"\n{x}" == "\\n42"
+
+doc="concatenation"
+assert "a" f"{x}" "b" == "a42b"
+assert f"{x}" f"{x}" == "4242"
+assert f"""multi
+{x}
+line""" == "multi\n42\nline"
+
+doc="self documenting expressions"
+x = 42
+name = "fred"
+assert f"{x=}" == "x=42"
+assert f"{name=}" == "name='fred'"
+assert f"{name=!s}" == "name=fred"
+assert f"{x = }" == "x = 42"
+assert f"{x=:>5}" == "x=   42"
+assert f"{x*2=}" == "x*2=84"
+assert f"a{x=}b" == "ax=42b"
+assert f"{x==42}" == "True"
+assert f"{x!=42=}" == "x!=42=False"
+assert f"{x>=42=}" == "x>=42=True"
+
+doc="errors"
+try:
+    f"{name:d}"
+except ValueError as e:
+    assert e.args[0] == "Unknown format code 'd' for object of type 'str'"
+else:
+    assert False, "ValueError not raised"
+
+doc="syntax errors"
+for src, msg in (
+        ("f'{}'", "f-string: empty expression not allowed"),
+        ("f'{x!z}'", "f-string: invalid conversion character: expected 's', 'r', or 'a'"),
+        ("f'}'", "f-string: single '}' is not allowed"),
+        ("f'{x'", "f-string: expecting '}'"),
+        ("f'{x#}'", "f-string expression part cannot include '#'"),
+        ("f'{3:{4:{5}}}'", "f-string: expressions nested too deeply"),
+        ("b'' f''", "cannot mix bytes and nonbytes literals"),
+):
+    try:
+        compile(src, "<string>", "exec")
+    except SyntaxError as e:
+        assert e.args[0] == msg, (src, e.args)
+    else:
+        assert False, "SyntaxError not raised for %r" % src
+
+doc="finished"
@@ -238,6 +238,35 @@ ck(fn16_4, "fn16_4() missing 1 required keyword-only argument: 'a'")
 ck(fn16_5, "fn16_5() missing 2 required keyword-only arguments: 'a' and 'b'")
 ck(fn16_6, "fn16_6() missing 3 required keyword-only arguments: 'a', 'b', and 'c'")
 
+doc="fn17 keyword only defaults"
+def fn17(a, *, b, c=3, d):
+    return (a, b, c, d)
+assert fn17(1, b=2, d=4) == (1, 2, 3, 4)
+assert fn17(1, d=4, c=5, b=2) == (1, 2, 5, 4)
+ck(fn17, "fn17() missing 2 required keyword-only arguments: 'b' and 'd'", 1)
+
+doc="fn18 positional only"
+def fn18(a, b=2, /, c=3, *, d=4, **kwargs):
+    return (a, b, c, d, kwargs)
+assert fn18(1) == (1, 2, 3, 4, {})
+assert fn18(1, 5, 6, d=7) == (1, 5, 6, 7, {})
+assert fn18(1, c=6) == (1, 2, 6, 4, {})
+assert fn18(1, a=8, b=9) == (1, 2, 3, 4, {'a': 8, 'b': 9})
+assert fn18.__code__.co_argcount == 3
+assert fn18.__code__.co_posonlyargcount == 2
+assert fn18.__code__.co_kwonlyargcount == 1
+fn18_lambda = lambda a, /, b=2: (a, b)
+assert fn18_lambda(1) == (1, 2)
+assert fn18_lambda(1, b=3) == (1, 3)
+
+doc="Calling errors fn19"
+def fn19(a, b, /, c):
+    pass
+ck(fn19, "fn19() got some positional-only arguments passed as keyword arguments: 'a, b'", a=1, b=2, c=3)
+ck(fn19, "fn19() got some positional-only arguments passed as keyword arguments: 'b'", 1, b=2, c=3)
+ck(fn19, "fn19() missing 1 required positional argument: 'c'", 1, 2)
+ck(fn19, "fn19() takes 3 positional arguments but 4 were given", 1, 2, 3, 4)
+
 #FIXME decorators
 
 doc="finished"
@@ -76,15 +76,243 @@ assert state == "started"
 assert next(generator) == None
 assert state == "started"
 
-# FIXME not implemented
-# err = ValueError("potato")
-# e = generator.throw(ValueError, "potato")
-# assert isinstance(e, ValueError)
-# assert state == "started"
+e = generator.throw(ValueError, "potato")
+assert isinstance(e, ValueError)
+assert e.args == ("potato",)
+assert state == "started"
+
+e = generator.throw(ValueError("chips"))
+assert isinstance(e, ValueError)
+assert state == "started"
+
+generator.close()
+assert state == "finally"
+
+try:
+    next(generator)
+except StopIteration:
+    pass
+else:
+    assert False, "StopIteration not raised"
+
+doc="close not started"
+generator = echo(1)
+state = "not started"
+generator.close()
+assert state == "not started"
+try:
+    next(generator)
+except StopIteration:
+    pass
+else:
+    assert False, "StopIteration not raised"
+
+doc="throw uncaught"
+def g4():
+    yield 1
+    yield 2
+g = g4()
+assert next(g) == 1
+try:
+    g.throw(KeyError, "sausage")
+except KeyError as e:
+    assert e.args == ("sausage",)
+else:
+    assert False, "KeyError not raised"
+try:
+    next(g)
+except StopIteration:
+    pass
+else:
+    assert False, "StopIteration not raised"
+
+doc="throw into finished"
+try:
+    g.throw(KeyError)
+except KeyError:
+    pass
+else:
+    assert False, "KeyError not raised"
+
+doc="throw bad arguments"
+g = g4()
+next(g)
+try:
+    g.throw(ValueError("potato"), "chips")
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    g.throw(1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    g.throw(ValueError, None, 1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+assert next(g) == 2
+
+doc="close ignored"
+def g5():
+    try:
+        yield 1
+    except GeneratorExit:
+        pass
+    yield 2
+g = g5()
+next(g)
+try:
+    g.close()
+except RuntimeError:
+    pass
+else:
+    assert False, "RuntimeError not raised"
+
+doc="close propagates other exceptions"
+def g6():
+    try:
+        yield 1
+    except GeneratorExit:
+        raise ValueError("potato")
+g = g6()
+next(g)
+try:
+    g.close()
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+
+doc="close finished"
+g = g4()
+assert list(g) == [1, 2]
+g.close()
+
+doc="return value"
+def g7():
+    yield 1
+    return "potato"
+g = g7()
+next(g)
+try:
+    next(g)
+except StopIteration as e:
+    assert e.value == "potato"
+else:
+    assert False, "StopIteration not raised"
+
+doc="yield from return value"
+results = []
+def g8():
+    x = yield from g7()
+    results.append(x)
+    yield x
+assert list(g8()) == [1, "potato"]
+assert results == ["potato"]
+
+doc="throw through yield from"
+def inner():
+    try:
+        yield 1
+    except ValueError as e:
+        yield "caught " + e.args[0]
+    yield 2
+def outer():
+    yield from inner()
+    yield 3
+g = outer()
+assert next(g) == 1
+assert g.throw(ValueError, "potato") == "caught potato"
+assert next(g) == 2
+assert next(g) == 3
+
+doc="throw through yield from finishing sub-generator"
+def inner2():
+    try:
+        yield 1
+    except ValueError:
+        return "done"
+def outer2():
+    x = yield from inner2()
+    yield x
+g = outer2()
+assert next(g) == 1
+assert g.throw(ValueError) == "done"
+
+doc="close through yield from"
+state = None
+def inner3():
+    global state
+    try:
+        yield 1
+    finally:
+        state = "inner closed"
+def outer3():
+    yield from inner3()
+g = outer3()
+next(g)
+g.close()
+assert state == "inner closed"
+
+doc="send through yield from"
+received = []
+def inner4():
+    x = yield 1
+    received.append(x)
+    y = yield 2
+    received.append(y)
+    return x + y
+def outer4():
+    r = yield from inner4()
+    yield r
+g = outer4()
+assert next(g) == 1
+assert g.send("a") == 2
+assert g.send("b") == "ab"
+assert received == ["a", "b"]
+
+doc="yield from iterables"
+def g9():
+    yield from [1, 2]
+    yield from (3,)
+    yield from "ab"
+    yield from range(2)
+    yield from (i*i for i in range(3))
+assert list(g9()) == [1, 2, 3, "a", "b", 0, 1, 0, 1, 4]
+
+doc="yield from non iterable"
+def g10():
+    yield from 5
+try:
+    list(g10())
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
 
-# FIXME not implemented
-# generator.close()
-# assert state == "finally"
+doc="iterate generator with return value"
+assert list(g7()) == [1]
+assert tuple(g7()) == (1,)
 
+doc="coroutine"
+def averager():
+    total = 0
+    count = 0
+    average = None
+    while True:
+        value = yield average
+        total += value
+        count += 1
+        average = total / count
+avg = averager()
+next(avg)
+assert avg.send(10) == 10
+assert avg.send(20) == 15
+assert avg.send(30) == 20
 
 doc="finished"
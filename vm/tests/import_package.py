@@ -0,0 +1,104 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+
+doc="import package"
+import libpkg
+assert libpkg.subfn() == 1
+assert libpkg.__name__ == "libpkg"
+assert libpkg.__package__ == "libpkg"
+assert libpkg.__path__[0].endswith("libpkg")
+assert libpkg.sub.__package__ == "libpkg"
+assert libpkg.__file__.endswith("__init__.py")
+
+doc="import dotted name"
+import libpkg.nested.deep
+assert libpkg.nested.deep.deepvar == 4
+import libpkg.nested.deep as deep
+assert deep is libpkg.nested.deep
+assert sys.modules["libpkg.nested.deep"] is deep
+assert sys.modules["libpkg.nested"] is libpkg.nested
+
+doc="from package import submodule"
+from libpkg import other
+assert other.othervar == 2
+from libpkg.nested import deep as deep2
+assert deep2 is deep
+
+doc="relative imports"
+assert deep.up is other
+assert deep.subfn is libpkg.subfn
+
+doc="relative import beyond the top level package"
+ok = False
+try:
+    import libpkg.beyond
+except ImportError:
+    ok = True
+assert ok
+assert "libpkg.beyond" not in sys.modules
+
+doc="relative import outside a package"
+ok = False
+try:
+    from . import lib
+except ImportError:
+    ok = True
+assert ok
+
+doc="circular import"
+import libpkg.circ_a
+assert libpkg.circ_b.f() == 6
+
+doc="from package import *"
+def star():
+    exec("from libpkg import *", globals())
+star()
+assert lazy.lazyvar == 5
+assert sub is libpkg.sub
+
+doc="namespace package"
+import libns.nsmod
+assert libns.nsmod.nsvar == 3
+assert libns.__path__[0].endswith("libns")
+assert getattr(libns, "__file__", None) is None
+
+doc="missing modules"
+ok = False
+try:
+    import libpkg.missing
+except ImportError:
+    ok = True
+assert ok
+ok = False
+try:
+    import libpkg.other.missing
+except ImportError:
+    ok = True
+assert ok
+
+doc="sys.path"
+ok = False
+try:
+    import pathmod
+except ImportError:
+    ok = True
+assert ok
+sys.path.append(libpkg.__path__[0][:-len("libpkg")] + "libpath")
+import pathmod
+assert pathmod.pathvar == 7
+del sys.path[-1]
+
+doc="None in sys.modules"
+sys.modules["libnone"] = None
+ok = False
+try:
+    import libnone
+except ImportError:
+    ok = True
+assert ok
+del sys.modules["libnone"]
+
+doc="finished"
@@ -38,4 +38,36 @@ except NameError:
     ok = True
 assert ok
 
+doc="IMPORT_STAR __all__ served by __getattr__"
+import sys
+ModuleType = type(sys)
+m = ModuleType("libdyn")
+m.present = 1
+m.__getattr__ = lambda name: "lazy " + name
+m.__all__ = ["present", "lazy"]
+sys.modules["libdyn"] = m
+from libdyn import *
+assert present == 1
+assert lazy == "lazy lazy"
+
+doc="IMPORT_STAR bad __all__"
+m.__all__ = ["present", 1]
+try:
+    exec("from libdyn import *", {})
+except TypeError as e:
+    assert e.args[0] == "Item in libdyn.__all__ must be str, not int", e.args
+else:
+    assert False, "TypeError not raised"
+
+doc="IMPORT_STAR missing name in __all__"
+m.__all__ = ["present", "missing"]
+del m.__getattr__
+try:
+    exec("from libdyn import *", {})
+except AttributeError as e:
+    assert e.args[0] == "module 'libdyn' has no attribute 'missing'", e.args
+else:
+    assert False, "AttributeError not raised"
+del sys.modules["libdyn"]
+
 doc="finished"
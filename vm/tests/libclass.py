@@ -0,0 +1,19 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# A module which customises its attributes with a subclass of module
+
+import sys
+
+class LazyModule(type(sys)):
+    def __getattr__(self, name):
+        return "lazy " + name
+
+    @property
+    def prop(self):
+        return "property"
+
+sys.modules[__name__].__class__ = LazyModule
+
+present = 1
@@ -0,0 +1,17 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# A module with a module level __getattr__ and __dir__
+
+present = 1
+
+def __getattr__(name):
+    if name == "lazy":
+        return "loaded " + name
+    if name == "old_name":
+        return present
+    raise AttributeError("module 'libgetattr' has no attribute '%s'" % name)
+
+def __dir__():
+    return ["present", "lazy", "old_name"]
@@ -0,0 +1,7 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# A module in a namespace package
+
+nsvar = 3
@@ -0,0 +1,9 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# A package to be imported
+
+__all__ = ["sub", "other", "lazy"]
+
+from .sub import subfn
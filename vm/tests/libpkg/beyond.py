@@ -0,0 +1,7 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# Relative imports reaching past the top level package fail
+
+from ... import other
@@ -0,0 +1,8 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import libpkg.circ_a
+
+def f():
+    return libpkg.circ_a.x
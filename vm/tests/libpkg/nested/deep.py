@@ -0,0 +1,8 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from .. import other as up
+from ..sub import subfn
+
+deepvar = 4
@@ -34,6 +34,27 @@ doc="UNPACK_EX"
 a, *b = L
 assert a == 1
 assert b == [2,3]
+*a, b = "abc"
+assert a == ["a", "b"]
+assert b == "c"
+a, *b, c = range(5)
+assert a == 0
+assert b == [1, 2, 3]
+assert c == 4
+
+doc="BUILD_LIST_UNPACK"
+assert [*L, *"ab"] == [1, 2, 3, "a", "b"]
+assert [0, *L, 4, 5, *()] == [0, 1, 2, 3, 4, 5]
+assert (*L, 4) == (1, 2, 3, 4)
+assert {*L, *[3, 4]} == {1, 2, 3, 4}
+assert {**{'a': 1, 'b': 2}, 'b': 3, **{'c': 4}} == {'a': 1, 'b': 3, 'c': 4}
+ok = False
+try:
+    {**[1]}
+except TypeError as e:
+    assert e.args[0] == "'list' object is not a mapping"
+    ok = True
+assert ok
 
 doc="SETITEM"
 LL = [1,2,3]
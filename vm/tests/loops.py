@@ -88,4 +88,18 @@ for i in (1,2,3,4,5):
 assert a == 12
 assert ok
 
+doc="For with an iterator raising an error"
+def failing():
+    yield 1
+    raise ValueError("failed")
+ok = False
+a = 0
+try:
+    for i in failing():
+        a += i
+except ValueError:
+    ok = True
+assert ok
+assert a == 1
+
 doc="finished"
@@ -0,0 +1,296 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="literals"
+def lit(x):
+    match x:
+        case None:
+            return "None"
+        case True:
+            return "True"
+        case False:
+            return "False"
+        case 1:
+            return "one"
+        case -2:
+            return "minus two"
+        case 1.5:
+            return "float"
+        case 1+2j:
+            return "complex"
+        case "hello" "world":
+            return "string"
+        case b"bytes":
+            return "bytes"
+    return "no match"
+assert lit(1) == "one"
+assert lit(1.0) == "one"
+assert lit(-2) == "minus two"
+assert lit(1.5) == "float"
+assert lit(1+2j) == "complex"
+assert lit("helloworld") == "string"
+assert lit(b"bytes") == "bytes"
+assert lit(None) == "None"
+assert lit(True) == "True"
+assert lit(False) == "False"
+assert lit(0) == "no match"
+assert lit("hello") == "no match"
+
+doc="capture and wildcard"
+match 42:
+    case x:
+        pass
+assert x == 42
+hit = False
+match 42:
+    case _:
+        hit = True
+assert hit
+match 3:
+    case 1 | 2:
+        y = "small"
+    case _:
+        y = "other"
+assert y == "other"
+
+doc="value patterns"
+class Color:
+    RED = 0
+    GREEN = 1
+def colour(c):
+    match c:
+        case Color.RED:
+            return "red"
+        case Color.GREEN:
+            return "green"
+    return "unknown"
+assert colour(0) == "red"
+assert colour(1) == "green"
+assert colour(2) == "unknown"
+
+doc="sequences"
+def seq(x):
+    match x:
+        case []:
+            return "empty"
+        case [a]:
+            return ("one", a)
+        case (a, b):
+            return ("two", a, b)
+        case [a, *rest, b]:
+            return ("many", a, rest, b)
+    return "no match"
+assert seq([]) == "empty"
+assert seq(()) == "empty"
+assert seq([1]) == ("one", 1)
+assert seq((1, 2)) == ("two", 1, 2)
+assert seq([1, 2, 3, 4]) == ("many", 1, [2, 3], 4)
+assert seq(range(3)) == ("many", 0, [1], 2)
+assert seq("ab") == "no match"
+assert seq(b"ab") == "no match"
+assert seq({1: 2}) == "no match"
+assert seq(5) == "no match"
+match [1, 2, 3]:
+    case [*_, last]:
+        pass
+assert last == 3
+match [1, [2, 3]]:
+    case [a, [b, c]]:
+        pass
+assert (a, b, c) == (1, 2, 3)
+
+doc="mappings"
+def mapping(x):
+    match x:
+        case {"a": 1, "b": b}:
+            return ("ab", b)
+        case {"x": x, **rest}:
+            return ("x", x, rest)
+        case {}:
+            return "empty"
+    return "no match"
+assert mapping({"a": 1, "b": 2}) == ("ab", 2)
+assert mapping({"a": 1, "b": 2, "c": 3}) == ("ab", 2)
+assert mapping({"a": 2, "b": 2}) == "empty"
+assert mapping({"x": 1, "y": 2}) == ("x", 1, {"y": 2})
+assert mapping({"y": 2}) == "empty"
+assert mapping([]) == "no match"
+match {"a": 1}:
+    case {**rest}:
+        pass
+assert rest == {"a": 1}
+
+doc="class patterns"
+class Point:
+    __match_args__ = ("x", "y")
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+def where(p):
+    match p:
+        case Point(0, 0):
+            return "origin"
+        case Point(x=0, y=y):
+            return ("y axis", y)
+        case Point(x, 0):
+            return ("x axis", x)
+        case Point():
+            return "point"
+    return "not a point"
+assert where(Point(0, 0)) == "origin"
+assert where(Point(0, 5)) == ("y axis", 5)
+assert where(Point(3, 0)) == ("x axis", 3)
+assert where(Point(1, 1)) == "point"
+assert where(1) == "not a point"
+match Point(1, 2):
+    case Point(z=1):
+        hit = False
+    case _:
+        hit = True
+assert hit
+
+doc="builtin class patterns"
+def kind(x):
+    match x:
+        case bool(b):
+            return ("bool", b)
+        case int(i):
+            return ("int", i)
+        case str() | bytes():
+            return "text"
+        case list([a, b]):
+            return ("list", a, b)
+        case dict({"k": v}):
+            return ("dict", v)
+    return "other"
+assert kind(True) == ("bool", True)
+assert kind(3) == ("int", 3)
+assert kind("s") == "text"
+assert kind(b"s") == "text"
+assert kind([1, 2]) == ("list", 1, 2)
+assert kind({"k": 1}) == ("dict", 1)
+assert kind(1.5) == "other"
+
+doc="class pattern errors"
+ok = False
+try:
+    match Point(1, 2):
+        case Point(1, 2, 3):
+            pass
+except TypeError as e:
+    ok = True
+    assert e.args[0] == "Point() accepts 2 positional sub-patterns (3 given)", e.args
+assert ok
+ok = False
+try:
+    match 1.0:
+        case float(1, 2):
+            pass
+except TypeError as e:
+    ok = True
+    assert e.args[0] == "float() accepts 1 positional sub-pattern (2 given)", e.args
+assert ok
+ok = False
+notatype = 1
+try:
+    match 1:
+        case notatype():
+            pass
+except TypeError as e:
+    ok = True
+    assert e.args[0] == "called match pattern must be a type", e.args
+assert ok
+
+doc="or and as patterns"
+def orp(x):
+    match x:
+        case 1 | 2 | 3 as n:
+            return ("small", n)
+        case [1, (2 | 3) as y]:
+            return ("pair", y)
+        case (1, a) | (a, 1):
+            return ("one", a)
+    return "no match"
+assert orp(2) == ("small", 2)
+assert orp([1, 3]) == ("pair", 3)
+assert orp((1, 5)) == ("one", 5)
+assert orp((5, 1)) == ("one", 5)
+assert orp((5, 5)) == "no match"
+
+doc="guards"
+def guard(x):
+    match x:
+        case [a, b] if a == b:
+            return "same"
+        case [a, b] if a > b:
+            return "bigger"
+        case [a, b]:
+            return "smaller"
+    return "no match"
+assert guard([1, 1]) == "same"
+assert guard([2, 1]) == "bigger"
+assert guard([1, 2]) == "smaller"
+assert guard(1) == "no match"
+
+doc="match and case as names"
+match = 1
+case = 2
+assert match + case == 3
+match = [1, 2]
+match[0] = 3
+assert match == [3, 2]
+def case(x):
+    return x
+assert case(4) == 4
+match (1, 2):
+    case (1, x):
+        pass
+assert x == 2
+
+doc="nested match"
+def nested(x, y):
+    match x:
+        case 1:
+            match y:
+                case 1:
+                    return "1, 1"
+                case _:
+                    return "1, _"
+        case _:
+            return "_"
+assert nested(1, 1) == "1, 1"
+assert nested(1, 2) == "1, _"
+assert nested(2, 1) == "_"
+
+doc="loops and match"
+out = []
+for i in range(5):
+    match i:
+        case 0:
+            continue
+        case 3:
+            break
+    out.append(i)
+assert out == [1, 2]
+
+doc="syntax errors"
+for src, msg in (
+        ("match x:\n case y:\n  pass\n case 1:\n  pass", "name capture 'y' makes remaining patterns unreachable"),
+        ("match x:\n case _:\n  pass\n case 1:\n  pass", "wildcard makes remaining patterns unreachable"),
+        ("match x:\n case [a, a]:\n  pass", "multiple assignments to name 'a' in pattern"),
+        ("match x:\n case [*a, *b]:\n  pass", "multiple starred names in sequence pattern"),
+        ("match x:\n case 1 | a:\n  pass", "alternative patterns bind different names"),
+        ("match x:\n case {1: a, 1: b}:\n  pass", "mapping pattern checks duplicate key (1)"),
+        ("match x:\n case C(a=1, a=2):\n  pass", "attribute name repeated in class pattern: a"),
+        ("match x:\n case C(a=1, 2):\n  pass", "positional patterns follow keyword patterns"),
+        ("match x:\n case _ as _:\n  pass", "cannot use '_' as a target"),
+):
+    try:
+        compile(src, "<string>", "exec")
+    except SyntaxError as e:
+        assert e.args[0] == msg, (src, e.args)
+    else:
+        assert False, "SyntaxError not raised for %r" % src
+
+doc="finished"
@@ -0,0 +1,211 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="metaclass"
+log = []
+class Meta(type):
+    @classmethod
+    def __prepare__(mcs, name, bases, **kwargs):
+        log.append(("prepare", name, kwargs))
+        return {"prepared": True}
+    def __new__(mcs, name, bases, ns, **kwargs):
+        log.append(("new", name, sorted(ns.keys())))
+        ns["added"] = 1
+        return type.__new__(mcs, name, bases, ns)
+    def __init__(cls, name, bases, ns, **kwargs):
+        log.append(("init", name, kwargs))
+    def hello(cls):
+        return "hello " + cls.__name__
+
+class C(metaclass=Meta, flag=1):
+    x = 1
+assert log == [
+    ("prepare", "C", {"flag": 1}),
+    ("new", "C", ["__module__", "__qualname__", "prepared", "x"]),
+    ("init", "C", {"flag": 1}),
+]
+assert C.added == 1
+assert C.prepared == True
+assert C.x == 1
+assert type(C) is Meta
+assert isinstance(C, Meta)
+assert C.hello() == "hello C"
+assert C().x == 1
+
+doc="metaclass inherited"
+class D(C):
+    pass
+assert type(D) is Meta
+assert D.hello() == "hello D"
+assert D.added == 1
+
+doc="metaclass conflict"
+class Meta2(type):
+    pass
+class E(metaclass=Meta2):
+    pass
+try:
+    class F(C, E):
+        pass
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="metaclass which is a function"
+def fmeta(name, bases, ns):
+    return (name, bases, ns["y"])
+class G(metaclass=fmeta):
+    y = 2
+assert G == ("G", (), 2)
+
+doc="type with three arguments"
+H = type("H", (C,), {"z": 3})
+assert H.z == 3
+assert H.x == 1
+assert H.__name__ == "H"
+assert type(H) is Meta
+
+doc="class attributes"
+class I:
+    "I doc"
+assert I.__name__ == "I"
+assert I.__qualname__ == "I"
+assert I.__doc__ == "I doc"
+assert I.__module__ == "__main__"
+assert I.__bases__ == (object,)
+assert I.__base__ is object
+assert I.__mro__ == (I, object)
+class J:
+    pass
+assert J.__doc__ is None
+J.__name__ = "JJ"
+assert J.__name__ == "JJ"
+try:
+    J.__name__ = 1
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+assert int.__name__ == "int"
+assert int.__module__ == "builtins"
+
+doc="__init_subclass__"
+class Base:
+    subclasses = []
+    def __init_subclass__(cls, tag=None, **kwargs):
+        Base.subclasses.append((cls.__name__, tag))
+class S1(Base, tag="one"):
+    pass
+class S2(S1):
+    pass
+assert Base.subclasses == [("S1", "one"), ("S2", None)]
+try:
+    class Bad(kw=1):
+        pass
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="__set_name__"
+class Named:
+    def __set_name__(self, owner, name):
+        self.owner = owner
+        self.name = name
+class K:
+    attr = Named()
+    other = Named()
+assert K.attr.name == "attr"
+assert K.attr.owner is K
+assert K.other.name == "other"
+
+doc="__new__"
+class N:
+    def __new__(cls, x):
+        o = object.__new__(cls)
+        o.x = x * 2
+        return o
+    def __init__(self, x):
+        self.y = x
+n = N(3)
+assert n.x == 6
+assert n.y == 3
+class N2(N):
+    pass
+n = N2(4)
+assert n.x == 8
+assert type(n) is N2
+
+class Singleton:
+    inst = None
+    def __new__(cls):
+        if cls.inst is None:
+            cls.inst = object.__new__(cls)
+        return cls.inst
+assert Singleton() is Singleton()
+
+class Other:
+    def __new__(cls):
+        return 42
+    def __init__(self):
+        assert False, "__init__ should not be called"
+assert Other() == 42
+
+try:
+    object.__new__(1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="__init__ returning a value"
+class Ret:
+    def __init__(self):
+        return 1
+try:
+    Ret()
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="metaclass __call__"
+class Counting(type):
+    calls = 0
+    def __call__(cls, *args):
+        Counting.calls += 1
+        return super().__call__(*args)
+class Counted(metaclass=Counting):
+    def __init__(self, x):
+        self.x = x
+c = Counted(3)
+assert c.x == 3
+assert Counting.calls == 1
+
+doc="class body order"
+class Ordered(type):
+    def __new__(mcs, name, bases, ns):
+        cls = super().__new__(mcs, name, bases, ns)
+        cls.names = [k for k in ns if not k.startswith("__")]
+        return cls
+class Body(metaclass=Ordered):
+    zebra = 1
+    apple = 2
+    def mango(self):
+        pass
+assert Body.names == ["zebra", "apple", "mango"], Body.names
+
+doc="special methods of metaclasses"
+class Sized(type):
+    def __len__(cls):
+        return 0
+    def __iter__(cls):
+        return iter([1, 2])
+class Empty(metaclass=Sized):
+    pass
+assert not Empty
+assert list(Empty) == [1, 2]
+
+doc="finished"
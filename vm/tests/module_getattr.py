@@ -0,0 +1,102 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="module __getattr__"
+import libgetattr
+assert libgetattr.present == 1
+assert libgetattr.lazy == "loaded lazy"
+assert libgetattr.old_name == 1
+try:
+    libgetattr.missing
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+assert getattr(libgetattr, "missing", 2) == 2
+assert hasattr(libgetattr, "lazy")
+assert not hasattr(libgetattr, "missing")
+
+doc="from import uses __getattr__"
+from libgetattr import lazy
+assert lazy == "loaded lazy"
+try:
+    from libgetattr import missing
+except ImportError:
+    pass
+else:
+    assert False, "ImportError not raised"
+
+doc="module __dir__"
+assert dir(libgetattr) == ["lazy", "old_name", "present"]
+
+doc="module without __getattr__"
+import lib
+try:
+    lib.missing
+except AttributeError as e:
+    assert e.args[0] == "module 'lib' has no attribute 'missing'", e.args
+else:
+    assert False, "AttributeError not raised"
+assert "libfn" in dir(lib)
+assert "__name__" in dir(lib)
+
+doc="module subclass as __class__"
+import libclass
+assert type(libclass).__name__ == "LazyModule"
+assert isinstance(libclass, type(lib))
+assert libclass.present == 1
+assert libclass.foo == "lazy foo"
+assert libclass.prop == "property"
+libclass.__class__ = type(lib)
+assert type(libclass) is type(lib)
+try:
+    libclass.foo
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+try:
+    libclass.__class__ = int
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="module constructor"
+ModuleType = type(lib)
+m = ModuleType("dyn", "doc")
+assert m.__name__ == "dyn"
+assert m.__doc__ == "doc"
+assert sorted(m.__dict__) == ["__doc__", "__loader__", "__name__", "__package__", "__spec__"]
+assert m.__spec__ is None
+assert repr(m) == "<module 'dyn'>"
+assert ModuleType(name="kw").__doc__ is None
+m.q = 1
+assert m.__dict__["q"] == 1
+del m.q
+assert not hasattr(m, "q")
+try:
+    ModuleType()
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    ModuleType(1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="module subclass"
+class Sub(ModuleType):
+    def hello(self):
+        return "hello " + self.__name__
+s = Sub("sub")
+assert isinstance(s, ModuleType)
+assert type(s) is Sub
+assert s.hello() == "hello sub"
+assert s.__name__ == "sub"
+
+doc="finished"
@@ -0,0 +1,87 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="simple"
+assert (x := 1) == 1
+assert x == 1
+y = (z := 2) + 1
+assert y == 3 and z == 2
+
+doc="if and while"
+if (n := len([1, 2, 3])) > 2:
+    assert n == 3
+else:
+    assert False, "not reached"
+if a := 0:
+    assert False, "not reached"
+elif b := 5:
+    assert a == 0 and b == 5
+data = [1, 2, 3]
+out = []
+while item := (data.pop() if data else None):
+    out.append(item)
+assert out == [3, 2, 1]
+
+doc="calls and displays"
+def f(*args):
+    return args
+assert f(c := 3, c + 1) == (3, 4)
+assert [d := 4, d * 2] == [4, 8]
+assert (e := 5, e) == (5, 5)
+
+doc="function scope"
+def fn():
+    assert (v := 10) == 10
+    return v
+assert fn() == 10
+
+doc="comprehensions bind in the enclosing scope"
+values = [1, 2, 3, 4]
+doubled = [last := v * 2 for v in values]
+assert doubled == [2, 4, 6, 8]
+assert last == 8
+assert any((hit := v) > 2 for v in values)
+assert hit == 3
+def total(xs):
+    acc = 0
+    sums = [acc := acc + x for x in xs]
+    return acc, sums
+assert total([1, 2, 3]) == (6, [1, 3, 6])
+def filtered(xs):
+    ys = [y for x in xs if (y := x * 10) > 10]
+    return y, ys
+assert filtered([1, 2, 3]) == (30, [20, 30])
+def nested():
+    [[inner := (i, j) for j in range(2)] for i in range(2)]
+    return inner
+assert nested() == (1, 1)
+def closure():
+    [w := 1 for _ in range(1)]
+    def get():
+        return w
+    return get()
+assert closure() == 1
+def glob():
+    global g
+    [g := 7 for _ in range(1)]
+glob()
+assert g == 7
+
+doc="syntax errors"
+for src, msg in (
+        ("x := 1", "invalid syntax"),
+        ("(a.b := 1)", "cannot use named assignment with attribute"),
+        ("[i := 0 for i in range(5)]", "assignment expression cannot rebind comprehension iteration variable 'i'"),
+        ("[i for i in (j := range(5))]", "assignment expression cannot be used in a comprehension iterable expression"),
+        ("[i for i in range(2) for k in (j := range(5))]", "assignment expression cannot be used in a comprehension iterable expression"),
+        ("class C:\n    [j := i for i in range(5)]", "assignment expression within a comprehension cannot be used in a class body"),
+):
+    try:
+        compile(src, "<string>", "exec")
+    except SyntaxError as e:
+        assert e.args[0] == msg, (src, e.args)
+    else:
+        assert False, "SyntaxError not raised for %r" % src
+
+doc="finished"
@@ -0,0 +1,111 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="constant folding"
+def f():
+    return 2 * 3 + 1
+assert f() == 7
+assert f.__code__.co_consts == (None, 7)
+def f():
+    "doc"
+    return (1 + 2 + 3) * 2
+assert f() == 12
+assert f.__doc__ == "doc"
+assert f.__code__.co_consts == ("doc", 12)
+assert (lambda: -(4 * 5)).__code__.co_consts == (None, -20)
+assert 6 in compile("x = 2 * 3", "<test>", "exec").co_consts
+assert 2 not in compile("x = 2 * 3", "<test>", "exec").co_consts
+assert 2 ** 10 == 1024
+assert -(5) == -5
+assert "ab" * 3 == "ababab"
+assert (1, 2) + (3,) == (1, 2, 3)
+
+doc="large results are not folded"
+def f():
+    return "x" * 1000
+assert len(f()) == 1000
+assert "x" * 1000 not in f.__code__.co_consts
+
+doc="folding errors are raised at run time"
+def f():
+    return 1 / 0
+try:
+    f()
+except ZeroDivisionError:
+    pass
+else:
+    assert False, "ZeroDivisionError not raised"
+
+doc="constant tuples"
+def f():
+    return (1, 2, (3, 4))
+assert f() == (1, 2, (3, 4))
+assert (1, 2, (3, 4)) in f.__code__.co_consts
+
+doc="swap"
+a, b = 1, 2
+a, b = b, a
+assert a == 2 and b == 1
+a, b, c = 1, 2, 3
+a, b, c = c, b, a
+assert (a, b, c) == (3, 2, 1)
+
+doc="containment in constant lists and sets"
+x = 2
+assert x in [1, 2, 3]
+assert x not in {4, 5}
+total = 0
+for i in [1, 2, 3]:
+    total += i
+assert total == 6
+
+doc="inverted comparisons"
+assert not (x is None)
+assert not (x in [4, 5])
+
+doc="dead code"
+def f():
+    return 1
+    x = 2
+    return x
+assert f() == 1
+assert f.__code__.co_consts == (None, 1)
+def f():
+    raise ValueError
+    return 2
+try:
+    f()
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+
+doc="jump chains"
+def f(a, b):
+    if a:
+        if b:
+            return 1
+    else:
+        return 2
+    return 3
+assert f(1, 1) == 1
+assert f(1, 0) == 3
+assert f(0, 1) == 2
+n = 0
+while n < 10:
+    if n % 2:
+        n += 3
+    else:
+        n += 1
+assert n == 12
+
+doc="constant condition at the start of the code"
+def f():
+    if 1:
+        return 1
+assert f() == 1
+while 1:
+    break
+
+doc="finished"
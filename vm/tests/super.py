@@ -0,0 +1,120 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="super with no arguments"
+class A:
+    def __init__(self, x):
+        self.x = x
+    def who(self):
+        return ["A"]
+class B(A):
+    def __init__(self, x):
+        super().__init__(x * 2)
+        self.b = True
+    def who(self):
+        return ["B"] + super().who()
+b = B(2)
+assert b.x == 4
+assert b.b
+assert b.who() == ["B", "A"]
+
+doc="super follows the MRO"
+class C(A):
+    def who(self):
+        return ["C"] + super().who()
+class D(B, C):
+    def who(self):
+        return ["D"] + super().who()
+assert D(1).who() == ["D", "B", "C", "A"]
+assert [k.__name__ for k in D.__mro__] == ["D", "B", "C", "A", "object"]
+
+doc="super with two arguments"
+assert super(B, D(1)).who() == ["C", "A"]
+assert super(C, D(1)).who() == ["A"]
+assert super(D, D(1)).who() == ["B", "C", "A"]
+
+doc="super with __init__ of object"
+class E:
+    def __init__(self):
+        super().__init__()
+        self.e = 1
+assert E().e == 1
+
+doc="super in a classmethod"
+class F:
+    @classmethod
+    def make(cls):
+        return ("F", cls)
+class G(F):
+    @classmethod
+    def make(cls):
+        return ("G",) + super().make()
+assert G.make() == ("G", "F", G)
+
+doc="super in __new__"
+class H:
+    def __new__(cls, x):
+        o = super().__new__(cls)
+        o.x = x
+        return o
+assert H(3).x == 3
+
+doc="super with a property"
+class I:
+    @property
+    def p(self):
+        return "I.p"
+class J(I):
+    @property
+    def p(self):
+        return "J." + super().p
+assert J().p == "J.I.p"
+
+doc="super when the first argument is a cell"
+class K(A):
+    def who(self):
+        def inner():
+            return self
+        return ["K"] + super().who() + [inner() is self]
+assert K(0).who() == ["K", "A", True]
+
+doc="super attributes"
+d = D(1)
+s = super(B, d)
+assert s.__thisclass__ is B
+assert s.__self__ is d
+assert s.__self_class__ is D
+s = super(B)
+assert s.__self__ is None
+assert s.__self_class__ is None
+
+doc="super errors"
+try:
+    super(B, 1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+try:
+    super(1, b)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+def nosuper():
+    return super()
+try:
+    nosuper()
+except RuntimeError:
+    pass
+else:
+    assert False, "RuntimeError not raised"
+try:
+    super(B, b).missing
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+
+doc="finished"
@@ -109,4 +109,91 @@ def return_in_with():
 assert return_in_with() == "potato"
 assert c.state == "__exit__"
 
+doc="exception silenced by a true value"
+class Silence():
+    def __init__(self, result):
+        self.result = result
+        self.exc = None
+    def __enter__(self):
+        return self
+    def __exit__(self, type, value, traceback):
+        self.exc = type
+        return self.result
+s = Silence(1)
+with s:
+    raise ValueError("silenced")
+assert s.exc is ValueError
+s = Silence("yes")
+with s:
+    raise ValueError("silenced")
+assert s.exc is ValueError
+s = Silence(0)
+ok = False
+try:
+    with s:
+        raise ValueError("not silenced")
+except ValueError:
+    ok = True
+assert ok
+assert s.exc is ValueError
+
+doc="multiple items"
+order = []
+class Ordered():
+    def __init__(self, name):
+        self.name = name
+    def __enter__(self):
+        order.append("enter " + self.name)
+        return self.name
+    def __exit__(self, type, value, traceback):
+        order.append("exit " + self.name)
+with Ordered("a") as a, Ordered("b") as b:
+    assert a == "a"
+    assert b == "b"
+    order.append("body")
+assert order == ["enter a", "enter b", "body", "exit b", "exit a"]
+
+doc="__enter__ raising does not call __exit__"
+class BadEnter():
+    def __init__(self):
+        self.exited = False
+    def __enter__(self):
+        raise KeyError("enter")
+    def __exit__(self, type, value, traceback):
+        self.exited = True
+c = BadEnter()
+ok = False
+try:
+    with c:
+        pass
+except KeyError:
+    ok = True
+assert ok
+assert not c.exited
+
+doc="__exit__ raising replaces the exception"
+class BadExit():
+    def __enter__(self):
+        return self
+    def __exit__(self, type, value, traceback):
+        raise KeyError("exit")
+ok = False
+try:
+    with BadExit():
+        raise ValueError("body")
+except KeyError:
+    ok = True
+assert ok
+
+doc="decorated function with defaults"
+def decorator(fn):
+    def wrapper(*args):
+        return ("wrapped", fn(*args))
+    return wrapper
+@decorator
+def add(a, b=1):
+    return a + b
+assert add(1) == ("wrapped", 2)
+assert add(1, 2) == ("wrapped", 3)
+
 doc="finished"
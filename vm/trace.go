@@ -0,0 +1,241 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tracing support for sys.settrace
+//
+// The global trace function gets a "call" event as each frame starts
+// running.  What it returns becomes the trace function for that frame
+// which then gets the "line", "exception" and "return" events for the
+// frame.
+//
+// Trace functions can be written in Go as a TraceFunc.
+
+package vm
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+// TraceFunc is a trace function written in Go
+//
+// It is called with the frame, the event and its argument like a
+// trace function set with sys.settrace.  The TraceFunc returned is
+// used to trace the rest of the frame, or if it is nil the trace
+// function of the frame is left as it was.
+type TraceFunc func(frame *py.Frame, event string, arg py.Object) (TraceFunc, error)
+
+// NewTraceFunc makes a python callable which calls fn so that it can
+// be used as a trace function
+func NewTraceFunc(fn TraceFunc) py.Object {
+	return py.MustNewMethod("trace", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var frame, event, arg py.Object
+		err := py.UnpackTuple(args, nil, "trace", 3, 3, &frame, &event, &arg)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := frame.(*py.Frame)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "trace() expects a frame, not %s", frame.Type().Name)
+		}
+		e, ok := event.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "trace() expects a str event, not %s", event.Type().Name)
+		}
+		next, err := fn(f, string(e), arg)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return py.None, nil
+		}
+		return NewTraceFunc(next), nil
+	}, 0, "trace(frame, event, arg) -> trace function")
+}
+
+// SetTrace sets fn as the global trace function like sys.settrace.
+// Tracing is turned off if fn is nil.
+func SetTrace(fn TraceFunc) {
+	if fn == nil {
+		py.TraceFunc = nil
+		return
+	}
+	py.TraceFunc = NewTraceFunc(fn)
+}
+
+// Set while a trace function is running so that the code it runs
+// isn't traced itself
+var insideTrace bool
+
+// Returns whether tracing is active
+func tracing() bool {
+	return py.TraceFunc != nil && !insideTrace
+}
+
+// Calls the trace function fn with the frame, event and arg.
+//
+// If it returns something other than None then that becomes the
+// trace function of the frame.  If it raises an exception then
+// tracing is turned off.
+func (vm *Vm) callTrace(fn py.Object, event string, arg py.Object) error {
+	frame := vm.frame
+	insideTrace = true
+	res, err := py.Call(fn, py.Tuple{frame, py.String(event), arg}, nil)
+	insideTrace = false
+	if err != nil {
+		py.TraceFunc = nil
+		frame.Trace = nil
+		return err
+	}
+	if res != py.None {
+		frame.Trace = res
+	}
+	return nil
+}
+
+// Called as the frame starts running
+func (vm *Vm) traceCall() error {
+	vm.frame.Lineno = vm.frame.Code.Firstlineno
+	return vm.callTrace(py.TraceFunc, "call", py.None)
+}
+
+// Called before each instruction when the frame is being traced.
+//
+// A "line" event happens when the instruction is the first of a line
+// or when a jump goes backwards.
+func (vm *Vm) traceLine() error {
+	frame := vm.frame
+	lasti := frame.Lasti
+	if lasti < vm.instrLower || lasti >= vm.instrUpper {
+		frame.Lineno, vm.instrLower, vm.instrUpper = frame.Code.LineBounds(lasti)
+	}
+	var err error
+	if lasti == vm.instrLower || lasti < vm.instrPrev {
+		err = vm.callTrace(frame.Trace, "line", py.None)
+	}
+	vm.instrPrev = lasti
+	return err
+}
+
+// Called as the frame returns or yields, with the value returned
+// or nil for an exception
+func (vm *Vm) traceReturn(retval py.Object) error {
+	if retval == nil {
+		retval = py.None
+	}
+	return vm.callTrace(vm.frame.Trace, "return", retval)
+}
+
+// Called as an exception is raised in or passes through the frame
+// with the (type, value, traceback) of the exception
+func (vm *Vm) traceException() error {
+	var traceback py.Object = py.None
+	if vm.curexc.Traceback != nil {
+		traceback = vm.curexc.Traceback
+	}
+	return vm.callTrace(vm.frame.Trace, "exception", py.Tuple{vm.curexc.Type, vm.curexc.Value, traceback})
+}
+
+// ProfileFunc is a profile function written in Go
+//
+// It is called with the frame, the event and its argument like a
+// profile function set with sys.setprofile.
+type ProfileFunc func(frame *py.Frame, event string, arg py.Object) error
+
+// NewProfileFunc makes a python callable which calls fn so that it
+// can be used as a profile function
+func NewProfileFunc(fn ProfileFunc) py.Object {
+	return py.MustNewMethod("profile", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var frame, event, arg py.Object
+		err := py.UnpackTuple(args, nil, "profile", 3, 3, &frame, &event, &arg)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := frame.(*py.Frame)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "profile() expects a frame, not %s", frame.Type().Name)
+		}
+		e, ok := event.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "profile() expects a str event, not %s", event.Type().Name)
+		}
+		return py.None, fn(f, string(e), arg)
+	}, 0, "profile(frame, event, arg) -> None")
+}
+
+// SetProfile sets fn as the global profile function like
+// sys.setprofile.  Profiling is turned off if fn is nil.
+//
+// The profile function gets "call" and "return" events as each frame
+// starts and finishes, and "c_call" followed by "c_return" or
+// "c_exception" around calls of built in functions with the function
+// as the argument.
+func SetProfile(fn ProfileFunc) {
+	if fn == nil {
+		py.ProfileFunc = nil
+		return
+	}
+	py.ProfileFunc = NewProfileFunc(fn)
+}
+
+// Returns whether profiling is active
+func profiling() bool {
+	return py.ProfileFunc != nil && !insideTrace
+}
+
+// Calls the profile function with the frame, event and arg.
+//
+// Unlike a trace function what it returns is ignored.  If it raises
+// an exception then profiling is turned off.
+func (vm *Vm) callProfile(event string, arg py.Object) error {
+	insideTrace = true
+	_, err := py.Call(py.ProfileFunc, py.Tuple{vm.frame, py.String(event), arg}, nil)
+	insideTrace = false
+	if err != nil {
+		py.ProfileFunc = nil
+	}
+	return err
+}
+
+// Called as the frame returns or yields, with the value returned
+// or nil for an exception
+func (vm *Vm) profileReturn(retval py.Object) error {
+	if retval == nil {
+		retval = py.None
+	}
+	return vm.callProfile("return", retval)
+}
+
+// Returns whether fn is a function or method written in Go
+func isBuiltin(fn py.Object) bool {
+	switch f := fn.(type) {
+	case *py.Method:
+		return true
+	case *py.BoundMethod:
+		_, ok := f.Method.(*py.Method)
+		return ok
+	}
+	return false
+}
+
+// Calls the built in fn surrounded by "c_call" and "c_return" or
+// "c_exception" profile events
+func (vm *Vm) profileCall(fn py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := vm.callProfile("c_call", fn)
+	if err != nil {
+		return nil, err
+	}
+	res, err := callInternal(fn, args, kwargs, vm.frame)
+	event := "c_return"
+	if err != nil {
+		event = "c_exception"
+	}
+	// The profile function may have been turned off by the call
+	if profiling() {
+		perr := vm.callProfile(event, fn)
+		if err == nil {
+			err = perr
+		}
+	}
+	return res, err
+}
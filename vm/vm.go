@@ -29,10 +29,8 @@ const (
 type Vm struct {
 	// Current frame
 	frame *py.Frame
-	// Whether ext should be added to the next arg
-	extended bool
-	// 16 bit extension for argument for next opcode
-	ext int32
+	// Instruction being run
+	instr *instruction
 	// Return value
 	retval py.Object
 	// VM Status code for main loop
@@ -41,4 +39,7 @@ type Vm struct {
 	curexc py.ExceptionInfo
 	// Previous exception type, value and traceback
 	exc py.ExceptionInfo
+	// Bytecode range of the current line and the previous
+	// instruction, used for line tracing
+	instrLower, instrUpper, instrPrev int32
 }
@@ -5,9 +5,18 @@
 package vm_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/pytest"
+	"github.com/go-python/gpython/vm"
 )
 
 func TestVm(t *testing.T) {
@@ -17,3 +26,908 @@ func TestVm(t *testing.T) {
 func BenchmarkVM(b *testing.B) {
 	pytest.RunBenchmarks(b, "benchmarks")
 }
+
+func TestRunWithBuiltins(t *testing.T) {
+	for _, test := range []struct {
+		src     string
+		wantErr *py.Type
+		wantMsg string
+	}{
+		{src: "x = len([1, 2]) + abs(-1)"},
+		{src: "def f():\n    return len('ab')\nx = f()"},
+		{src: "open('/etc/passwd')", wantErr: py.NameError, wantMsg: "name 'open' is not defined"},
+		{src: "exec('x = 1')", wantErr: py.NameError, wantMsg: "name 'exec' is not defined"},
+		{src: "def f():\n    return eval('1')\nf()", wantErr: py.NameError, wantMsg: "name 'eval' is not defined"},
+		{src: "import os", wantErr: py.ImportError, wantMsg: "__import__ not found"},
+		{src: "__builtins__['open']", wantErr: py.KeyError},
+		// Removing __builtins__ doesn't bring the others back
+		{src: "del __builtins__\ndef f():\n    return open\nf()", wantErr: py.NameError, wantMsg: "name 'open' is not defined"},
+		{src: "def f():\n    return open\ndel __builtins__\nf()", wantErr: py.NameError, wantMsg: "name 'open' is not defined"},
+		{src: "del __builtins__\ndef f():\n    return len('ab')\nx = f()"},
+		{src: "__builtins__ = None\ndef f():\n    return __import__('os')\nf()", wantErr: py.NameError, wantMsg: "name '__import__' is not defined"},
+		{src: "__builtins__ = 1\n(lambda: open)()", wantErr: py.NameError, wantMsg: "name 'open' is not defined"},
+	} {
+		obj, err := compile.Compile(test.src, "<test>", "exec", 0, true)
+		if err != nil {
+			t.Fatalf("%q: compile failed: %v", test.src, err)
+		}
+		globals := py.NewStringDict()
+		_, err = vm.RunWithBuiltins(globals, globals, py.SandboxBuiltins(), obj.(*py.Code))
+		if test.wantErr == nil {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", test.src, err)
+			}
+			continue
+		}
+		if !py.IsException(test.wantErr, err) {
+			t.Errorf("%q: want %s got %v", test.src, test.wantErr.Name, err)
+			continue
+		}
+		if test.wantMsg != "" {
+			exc := err.(py.ExceptionInfo).Value.(*py.Exception)
+			if got := exc.Args.(py.Tuple)[0]; got != py.String(test.wantMsg) {
+				t.Errorf("%q: want message %q got %q", test.src, test.wantMsg, got)
+			}
+		}
+	}
+
+	// Removing a builtin only affects the copy
+	builtins := py.BuiltinsWithout("len")
+	if _, ok := builtins["len"]; ok {
+		t.Errorf("len not removed")
+	}
+	if _, ok := builtins["abs"]; !ok {
+		t.Errorf("abs removed")
+	}
+	if _, ok := py.CurrentContext.Builtins().Globals["len"]; !ok {
+		t.Errorf("len removed from the builtins module")
+	}
+}
+
+func TestCorruptBytecode(t *testing.T) {
+	op := func(op vm.OpCode, arg int) string {
+		return string([]byte{byte(op), byte(arg), byte(arg >> 8)})
+	}
+	for _, test := range []struct {
+		name string
+		code string
+	}{
+		{"BUILD_SLICE", op(vm.LOAD_CONST, 0) + op(vm.LOAD_CONST, 0) + op(vm.BUILD_SLICE, 5)},
+		{"RAISE_VARARGS", op(vm.RAISE_VARARGS, 4)},
+		{"COMPARE_OP", op(vm.LOAD_CONST, 0) + op(vm.LOAD_CONST, 0) + op(vm.COMPARE_OP, 99)},
+		{"END_FINALLY", op(vm.LOAD_CONST, 1) + string([]byte{byte(vm.END_FINALLY)})},
+		{"LOAD_CONST", op(vm.LOAD_CONST, 9) + string([]byte{byte(vm.POP_TOP)})},
+		{"LOAD_NAME", op(vm.LOAD_NAME, 3) + string([]byte{byte(vm.POP_TOP)})},
+		{"LOAD_FAST", op(vm.LOAD_FAST, 7) + string([]byte{byte(vm.POP_TOP)})},
+		{"POP_TOP", string([]byte{byte(vm.POP_TOP)})},
+	} {
+		code := &py.Code{
+			Stacksize: 4,
+			Code:      test.code + string([]byte{byte(vm.LOAD_CONST), 0, 0, byte(vm.RETURN_VALUE)}),
+			Consts:    py.Tuple{py.None, py.Int(5)},
+			Name:      "<module>",
+			Filename:  "<test>",
+		}
+		globals := py.NewStringDict()
+		_, err := vm.Run(globals, globals, code, nil)
+		if !py.IsException(py.SystemError, err) {
+			t.Errorf("%s: want SystemError got %v", test.name, err)
+		}
+		if py.CurrentFrame != nil {
+			t.Errorf("%s: frame left running", test.name)
+		}
+	}
+}
+
+func TestPanicHandledInFrame(t *testing.T) {
+	src := `
+ran = []
+try:
+    try:
+        boom()
+    finally:
+        ran.append("finally")
+except SystemError as e:
+    ran.append("except")
+    assert "index out of range" in str(e), str(e)
+`
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globals := py.NewStringDict()
+	globals["boom"] = py.MustNewMethod("boom", func(self py.Object) (py.Object, error) {
+		var empty py.Tuple
+		return empty[len(globals)], nil
+	}, 0, "")
+	_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran, err := py.Str(globals["ran"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := py.String("['finally', 'except']"); ran != want {
+		t.Errorf("want %s got %s", want, ran)
+	}
+}
+
+type goPoint struct {
+	X, Y int
+}
+
+func (p goPoint) Sum() int {
+	return p.X + p.Y
+}
+
+func (p *goPoint) Move(dx, dy int) {
+	p.X += dx
+	p.Y += dy
+}
+
+type goShape struct {
+	goPoint
+	Name   string
+	Tags   []string
+	Origin goPoint
+	Scale  float64
+	secret int
+}
+
+func (s *goShape) Describe(prefix string, extra ...string) (string, error) {
+	if prefix == "" {
+		return "", errors.New("empty prefix")
+	}
+	return prefix + s.Name + strings.Join(extra, ""), nil
+}
+
+func (s *goShape) Split() (string, int) {
+	return s.Name, len(s.Tags)
+}
+
+func TestGoValue(t *testing.T) {
+	shape := &goShape{goPoint: goPoint{1, 2}, Name: "square", Tags: []string{"a", "b"}, Scale: 1.5, secret: 7}
+	shapeObj, err := py.WrapGoValue(shape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := `
+assert shape.Name == "square"
+assert shape.X == 1 and shape.Y == 2
+assert shape.Tags == ["a", "b"]
+assert shape.Scale == 1.5
+assert shape.Sum() == 3
+shape.Move(10, 20)
+assert shape.Sum() == 33
+shape.Name = "circle"
+shape.Tags = ["c"]
+shape.Origin.X = 5
+assert shape.Origin.X == 5
+assert shape.Describe("a ") == "a circle"
+assert shape.Describe("a ", "!", "?") == "a circle!?"
+assert shape.Split() == ("circle", 1)
+try:
+    shape.Describe("")
+except RuntimeError as e:
+    assert e.args[0] == "empty prefix"
+else:
+    assert False, "RuntimeError not raised"
+try:
+    shape.Name = 1
+except TypeError as e:
+    assert e.args[0] == "can't convert 'int' object to Go string", e.args
+else:
+    assert False, "TypeError not raised"
+try:
+    shape.secret
+except AttributeError:
+    pass
+else:
+    assert False, "AttributeError not raised"
+try:
+    shape.Move(1)
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+p = Point(X=3, Y=4)
+assert p.Sum() == 7
+assert type(p) is Point
+moved = move(p)
+assert moved.X == 4 and p.X == 3
+`
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	move, err := py.WrapGoValue(func(p goPoint) goPoint {
+		p.Move(1, 1)
+		return p
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	globals := py.StringDict{
+		"shape": shapeObj,
+		"Point": py.NewGoType(reflect.TypeOf(goPoint{})),
+		"move":  move,
+	}
+	_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if shape.X != 11 || shape.Y != 22 || shape.Name != "circle" || shape.Origin.X != 5 {
+		t.Errorf("changes not made to the Go value: %+v", shape)
+	}
+	if len(shape.Tags) != 1 || shape.Tags[0] != "c" {
+		t.Errorf("Tags not set: %v", shape.Tags)
+	}
+}
+
+func TestSetTrace(t *testing.T) {
+	src := `def f(x):
+    y = x + 1
+    return y
+try:
+    f(None)
+except TypeError:
+    pass
+`
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	var events []string
+	var tracer vm.TraceFunc
+	tracer = func(frame *py.Frame, event string, arg py.Object) (vm.TraceFunc, error) {
+		if frame.Code.Name == "f" {
+			events = append(events, fmt.Sprintf("%s %d", event, frame.LineNumber()))
+		}
+		return tracer, nil
+	}
+	vm.SetTrace(tracer)
+	globals := py.NewStringDict()
+	_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+	vm.SetTrace(nil)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	want := []string{"call 1", "line 2", "exception 2", "return 2"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("want %q got %q", want, events)
+	}
+	if py.TraceFunc != nil {
+		t.Errorf("tracing not turned off")
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	src := `while x:
+    x = x - 1
+`
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	var buf strings.Builder
+	err = vm.Disassemble(&buf, obj.(*py.Code), 3)
+	if err != nil {
+		t.Fatalf("disassemble failed: %v", err)
+	}
+	want := `  1           0 SETUP_LOOP              20 (to 23)
+    --> >>    3 LOAD_NAME                0 (x)
+              6 POP_JUMP_IF_FALSE       22
+
+  2           9 LOAD_NAME                0 (x)
+             12 LOAD_CONST               0 (1)
+             15 BINARY_SUBTRACT
+             16 STORE_NAME               0 (x)
+             19 JUMP_ABSOLUTE            3
+        >>   22 POP_BLOCK
+        >>   23 LOAD_CONST               1 (None)
+             26 RETURN_VALUE
+`
+	if got := buf.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestThreads(t *testing.T) {
+	src := `for i in range(200):
+    out.append(i)
+`
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	code := obj.(*py.Code)
+	out := py.NewList()
+	errs := make([]error, 3)
+	idents := make([]int64, 3)
+	for i := range errs {
+		i := i
+		vm.StartThread(func() {
+			idents[i] = vm.ThreadIdent()
+			globals := py.StringDict{"out": out}
+			_, errs[i] = vm.Run(globals, globals, code, nil)
+		}, false)
+	}
+	vm.JoinThreads()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("thread %d failed: %v", i, err)
+		}
+	}
+	if len(out.Items) != 600 {
+		t.Errorf("want 600 items got %d", len(out.Items))
+	}
+	if idents[0] == idents[1] || idents[1] == idents[2] || idents[0] == vm.ThreadIdent() {
+		t.Errorf("threads should have different idents: %v", idents)
+	}
+
+	// A goroutine which isn't a python thread can run python code
+	// with RunThread
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		vm.RunThread(py.DefaultContext, func() {
+			globals := py.StringDict{"out": out}
+			_, err = vm.Run(globals, globals, code, nil)
+		})
+	}()
+	vm.AllowThreads(func() {
+		<-done
+	})
+	if err != nil {
+		t.Errorf("RunThread failed: %v", err)
+	}
+	if len(out.Items) != 800 {
+		t.Errorf("want 800 items got %d", len(out.Items))
+	}
+}
+
+func TestContexts(t *testing.T) {
+	src := `
+import sys
+seen = hasattr(sys, "ctx")
+sys.ctx = name
+sys.path.append(name)
+`
+	obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	code := obj.(*py.Code)
+	ctxs := []*py.Context{py.NewContext(), py.NewContext()}
+	results := make([]py.StringDict, len(ctxs))
+	errs := make([]error, len(ctxs))
+	for i, ctx := range ctxs {
+		i := i
+		py.RunInContext(ctx, func() {
+			vm.StartThread(func() {
+				globals := py.StringDict{"name": py.String(fmt.Sprintf("ctx%d", i))}
+				_, errs[i] = vm.Run(globals, globals, code, nil)
+				results[i] = globals
+			}, false)
+		})
+	}
+	vm.JoinThreads()
+	for i, ctx := range ctxs {
+		if errs[i] != nil {
+			t.Fatalf("ctx%d failed: %v", i, errs[i])
+		}
+		if results[i]["seen"] != py.False {
+			t.Errorf("ctx%d saw sys.ctx set by another context", i)
+		}
+		sys, err := ctx.GetModule("sys")
+		if err != nil {
+			t.Fatalf("ctx%d has no sys: %v", i, err)
+		}
+		want := py.String(fmt.Sprintf("ctx%d", i))
+		if got := sys.Globals["ctx"]; got != want {
+			t.Errorf("ctx%d: want sys.ctx %q got %v", i, want, got)
+		}
+		if got := len(ctx.Path.Items); got != len(py.DefaultPath)+1 {
+			t.Errorf("ctx%d: want %d items in sys.path got %d", i, len(py.DefaultPath)+1, got)
+		}
+		if py.DefaultContext.Modules["sys"] == py.Object(sys) {
+			t.Errorf("ctx%d shares sys with the default context", i)
+		}
+	}
+	if ctxs[0].Modules["sys"] == ctxs[1].Modules["sys"] {
+		t.Errorf("contexts share the sys module")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	py.RegisterModuleFunc("limits_test", func(m *py.Module) error {
+		return nil
+	})
+	for _, test := range []struct {
+		name    string
+		src     string
+		limits  py.Limits
+		wantErr *py.Type
+		wantMsg string
+	}{
+		{name: "no limits", src: "x = [i for i in range(1000)]"},
+		{name: "within limits", src: "x = 1", limits: py.Limits{MaxInstructions: 100, Timeout: time.Minute, MaxMemory: 1 << 40}},
+		{
+			name:    "instructions",
+			src:     "while True:\n    pass",
+			limits:  py.Limits{MaxInstructions: 1000},
+			wantErr: py.RuntimeError,
+			wantMsg: "instruction limit of 1000 exceeded",
+		},
+		{
+			name:    "can't be caught",
+			src:     "while True:\n    try:\n        pass\n    except BaseException:\n        pass",
+			limits:  py.Limits{MaxInstructions: 1000},
+			wantErr: py.RuntimeError,
+		},
+		{
+			name:    "timeout",
+			src:     "while True:\n    pass",
+			limits:  py.Limits{Timeout: 10 * time.Millisecond},
+			wantErr: py.TimeoutError,
+			wantMsg: "time limit of 10ms exceeded",
+		},
+		{
+			name:    "memory",
+			src:     "x = 'x' * 10**12",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+			wantMsg: "memory limit of 1073741824 bytes exceeded",
+		},
+		{
+			name:    "memory list",
+			src:     "x = [None] * 10**11",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory bytearray",
+			src:     "x = bytearray(10**10)",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory bytes",
+			src:     "x = bytes(10**10)",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory ljust",
+			src:     "x = 'ab'.ljust(10**10)",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory rjust",
+			src:     "x = 'ab'.rjust(10**10, '*')",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory center",
+			src:     "x = 'ab'.center(10**10)",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory zfill",
+			src:     "x = '12'.zfill(10**10)",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory expandtabs",
+			src:     "x = '\\t\\t'.expandtabs(10**10)",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory list from range",
+			src:     "x = list(range(10**10))",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:    "memory tuple from range",
+			src:     "x = tuple(range(10**10))",
+			limits:  py.Limits{MaxMemory: 1 << 30},
+			wantErr: py.MemoryError,
+		},
+		{
+			name:   "memory small range",
+			src:    "x = list(range(1000)) + list(tuple(range(1000))) + [bytes(range(200))]",
+			limits: py.Limits{MaxMemory: 1 << 40},
+		},
+		{
+			name:    "repr recursion",
+			src:     "x = []\nfor i in range(100000):\n    x = [x]\nrepr(x)",
+			wantErr: py.RecursionError,
+			wantMsg: "maximum recursion depth exceeded while getting the repr of an object",
+		},
+		{
+			name:    "recursion",
+			src:     "def f():\n    return f()\nf()",
+			wantErr: py.RecursionError,
+			wantMsg: "maximum recursion depth exceeded",
+		},
+		{
+			name: "recursion is a RuntimeError",
+			src:  "def f():\n    return f()\ntry:\n    f()\nexcept RuntimeError:\n    pass",
+		},
+		{
+			name:    "deny module",
+			src:     "import limits_test",
+			limits:  py.Limits{DenyModules: []string{"limits_test"}},
+			wantErr: py.ImportError,
+			wantMsg: "import of 'limits_test' is not allowed",
+		},
+		{
+			name:    "deny submodule",
+			src:     "import limits_test.sub",
+			limits:  py.Limits{DenyModules: []string{"limits_test"}},
+			wantErr: py.ImportError,
+			wantMsg: "import of 'limits_test.sub' is not allowed",
+		},
+		{
+			name:   "allowed module",
+			src:    "import limits_test",
+			limits: py.Limits{DenyModules: []string{"limits"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			obj, err := compile.Compile(test.src, "<test>", "exec", 0, true)
+			if err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			ctx := py.NewContext()
+			ctx.SetLimits(test.limits)
+			if got := ctx.Limits(); !reflect.DeepEqual(got, test.limits) {
+				t.Errorf("Limits: want %+v got %+v", test.limits, got)
+			}
+			py.RunInContext(ctx, func() {
+				globals := py.NewStringDict()
+				_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+			})
+			if test.wantErr == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !py.IsException(test.wantErr, err) {
+				t.Fatalf("want %s got %v", test.wantErr.Name, err)
+			}
+			if test.wantMsg != "" {
+				exc := err.(py.ExceptionInfo).Value.(*py.Exception)
+				if got := exc.Args.(py.Tuple)[0]; got != py.String(test.wantMsg) {
+					t.Errorf("want message %q got %q", test.wantMsg, got)
+				}
+			}
+		})
+	}
+
+	// Setting the limits again lets code run after they were exceeded
+	ctx := py.NewContext()
+	ctx.SetLimits(py.Limits{MaxInstructions: 10})
+	obj, err := compile.Compile("x = 0\nwhile x < 100:\n    x += 1", "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	run := func() (err error) {
+		py.RunInContext(ctx, func() {
+			globals := py.NewStringDict()
+			_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+		})
+		return err
+	}
+	if err := run(); !py.IsException(py.RuntimeError, err) {
+		t.Errorf("want RuntimeError got %v", err)
+	}
+	ctx.SetLimits(py.Limits{})
+	if err := run(); err != nil {
+		t.Errorf("unexpected error after removing limits: %v", err)
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	for _, test := range []struct {
+		src     string
+		wantErr *py.Type
+		wantMsg string
+	}{
+		{src: "import os", wantErr: py.ImportError, wantMsg: "import of 'os' is not allowed"},
+		{src: "del __builtins__\nimport os", wantErr: py.ImportError, wantMsg: "import of 'os' is not allowed"},
+		{src: "del __builtins__\ndef f():\n    return __import__('os')\nf()", wantErr: py.ImportError, wantMsg: "import of 'os' is not allowed"},
+		{src: "del __builtins__\ndef f():\n    return open\nf()", wantErr: py.NameError, wantMsg: "name 'open' is not defined"},
+		{src: "__builtins__ = None\nimport os", wantErr: py.ImportError, wantMsg: "import of 'os' is not allowed"},
+		{src: "__builtins__ = {}\ndef f():\n    import os\nf()", wantErr: py.ImportError, wantMsg: "__import__ not found"},
+	} {
+		obj, err := compile.Compile(test.src, "<test>", "exec", 0, true)
+		if err != nil {
+			t.Fatalf("%q: compile failed: %v", test.src, err)
+		}
+		ctx := py.NewContext()
+		ctx.SetLimits(py.Limits{DenyModules: []string{"os"}})
+		py.RunInContext(ctx, func() {
+			globals := py.NewStringDict()
+			_, err = vm.RunWithBuiltins(globals, globals, py.BuiltinsWithout("open"), obj.(*py.Code))
+		})
+		if !py.IsException(test.wantErr, err) {
+			t.Errorf("%q: want %s got %v", test.src, test.wantErr.Name, err)
+			continue
+		}
+		exc := err.(py.ExceptionInfo).Value.(*py.Exception)
+		if got := exc.Args.(py.Tuple)[0]; got != py.String(test.wantMsg) {
+			t.Errorf("%q: want message %q got %q", test.src, test.wantMsg, got)
+		}
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	compileCode := func(src string) *py.Code {
+		obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+		if err != nil {
+			t.Fatalf("%q: compile failed: %v", src, err)
+		}
+		return obj.(*py.Code)
+	}
+	forever := compileCode("while True:\n    try:\n        pass\n    except BaseException:\n        pass")
+	wantInterrupt := func(what string, err error, wantMsg string) {
+		t.Helper()
+		if !py.IsException(py.KeyboardInterrupt, err) {
+			t.Fatalf("%s: want KeyboardInterrupt got %v", what, err)
+		}
+		exc, ok := err.(*py.Exception)
+		if !ok {
+			exc = err.(py.ExceptionInfo).Value.(*py.Exception)
+		}
+		if got := exc.Args.(py.Tuple)[0]; got != py.String(wantMsg) {
+			t.Errorf("%s: want message %q got %q", what, wantMsg, got)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	globals := py.NewStringDict()
+	_, err := vm.RunContext(ctx, globals, globals, forever, nil)
+	wantInterrupt("cancelled", err, "context canceled")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = vm.RunContext(ctx, globals, globals, forever, nil)
+	wantInterrupt("timeout", err, "context deadline exceeded")
+
+	// Code which finishes in time isn't affected and the context
+	// doesn't apply once RunContext returns
+	ctx, cancel = context.WithCancel(context.Background())
+	_, err = vm.RunContext(ctx, globals, globals, compileCode("x = sum(range(1000))"), nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	cancel()
+	if globals["x"] != py.Int(499500) {
+		t.Errorf("want x = 499500 got %v", globals["x"])
+	}
+	_, err = vm.Run(globals, globals, compileCode("y = 1"), nil)
+	if err != nil {
+		t.Errorf("cancelled context still applies: %v", err)
+	}
+
+	// Sleep is interrupted
+	start := time.Now()
+	vm.WithContext(ctx, func() {
+		err = vm.Sleep(time.Hour)
+	})
+	wantInterrupt("sleep", err, "context canceled")
+	if time.Since(start) > time.Minute {
+		t.Errorf("sleep wasn't interrupted")
+	}
+	if err := vm.Sleep(time.Millisecond); err != nil {
+		t.Errorf("sleep failed: %v", err)
+	}
+}
+
+func TestPendingCalls(t *testing.T) {
+	compileCode := func(src string) *py.Code {
+		obj, err := compile.Compile(src, "<test>", "exec", 0, true)
+		if err != nil {
+			t.Fatalf("%q: compile failed: %v", src, err)
+		}
+		return obj.(*py.Code)
+	}
+
+	// A pending call runs with the GIL in the main thread
+	globals := py.NewStringDict()
+	globals["flag"] = py.False
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		vm.AddPendingCall(func() error {
+			globals["flag"] = py.True
+			return nil
+		})
+	}()
+	_, err := vm.Run(globals, globals, compileCode("n = 0\nwhile not flag:\n    n += 1"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An error from a pending call is raised in the running code
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		vm.AddPendingCall(func() error {
+			return py.ExceptionNewf(py.KeyboardInterrupt, "pending")
+		})
+	}()
+	_, err = vm.Run(globals, globals, compileCode("try:\n    while True:\n        pass\nexcept KeyboardInterrupt as e:\n    msg = e.args[0]"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if globals["msg"] != py.String("pending") {
+		t.Errorf("want msg = 'pending' got %v", globals["msg"])
+	}
+
+	// Sleep runs the pending calls and is interrupted by an error
+	calls := 0
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		vm.AddPendingCall(func() error {
+			calls++
+			return nil
+		})
+		time.Sleep(10 * time.Millisecond)
+		vm.AddPendingCall(func() error {
+			calls++
+			return py.ExceptionNewf(py.KeyboardInterrupt, "")
+		})
+	}()
+	start := time.Now()
+	err = vm.Sleep(time.Hour)
+	if !py.IsException(py.KeyboardInterrupt, err) {
+		t.Fatalf("want KeyboardInterrupt got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("want 2 calls got %d", calls)
+	}
+	if time.Since(start) > time.Minute {
+		t.Errorf("sleep wasn't interrupted")
+	}
+}
+
+func TestEvalHook(t *testing.T) {
+	py.RegisterModuleFunc("hook_test", func(m *py.Module) error {
+		return nil
+	})
+	obj, err := compile.Compile(`
+class C:
+    pass
+c = C()
+c.x = 1
+y = c.x
+del c.x
+n = len([1, 2])
+try:
+    c.secret
+except PermissionError:
+    vetoed = True
+try:
+    import hook_test
+except PermissionError:
+    blocked = True
+`, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	var events []string
+	instructions := 0
+	ctx := py.NewContext()
+	vm.SetEvalHook(ctx, &vm.EvalHook{
+		Instruction: func(frame *py.Frame, op vm.OpCode, arg int32) error {
+			instructions++
+			return nil
+		},
+		GetAttr: func(obj py.Object, name string) error {
+			events = append(events, "get "+name)
+			if name == "secret" {
+				return py.ExceptionNewf(py.PermissionError, "can't read %s", name)
+			}
+			return nil
+		},
+		SetAttr: func(obj py.Object, name string, value py.Object) error {
+			events = append(events, fmt.Sprintf("set %s %v", name, value))
+			return nil
+		},
+		DelAttr: func(obj py.Object, name string) error {
+			events = append(events, "del "+name)
+			return nil
+		},
+		Call: func(fn py.Object, args py.Tuple, kwargs py.StringDict) error {
+			if m, ok := fn.(*py.Method); ok && m.Name == "len" {
+				events = append(events, fmt.Sprintf("call len %d", len(args)))
+			}
+			return nil
+		},
+		Import: func(name string, level int) error {
+			events = append(events, fmt.Sprintf("import %s %d", name, level))
+			return py.ExceptionNewf(py.PermissionError, "can't import %s", name)
+		},
+	})
+	if vm.GetEvalHook(ctx) == nil {
+		t.Fatalf("hook not set")
+	}
+	globals := py.NewStringDict()
+	py.RunInContext(ctx, func() {
+		_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+	})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	want := []string{"set x 1", "get x", "del x", "call len 1", "get secret", "import hook_test 0"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("want %q got %q", want, events)
+	}
+	if instructions == 0 {
+		t.Errorf("no instructions seen")
+	}
+	if globals["vetoed"] != py.True || globals["blocked"] != py.True {
+		t.Errorf("operations not vetoed")
+	}
+
+	// Other contexts aren't hooked, nor is ctx once it is removed
+	vm.SetEvalHook(ctx, nil)
+	if vm.GetEvalHook(ctx) != nil {
+		t.Errorf("hook not removed")
+	}
+	events = nil
+	for _, c := range []*py.Context{py.NewContext(), ctx} {
+		py.RunInContext(c, func() {
+			globals := py.NewStringDict()
+			_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+		})
+		if !py.IsException(py.AttributeError, err) {
+			t.Errorf("want AttributeError got %v", err)
+		}
+	}
+	if len(events) != 0 {
+		t.Errorf("hook still called: %q", events)
+	}
+}
+
+func TestAuditHook(t *testing.T) {
+	py.RegisterModuleFunc("hook_test", func(m *py.Module) error {
+		return nil
+	})
+	obj, err := compile.Compile(`
+import hook_test
+exec("x = 1")
+`, "<test>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	var events []string
+	ctx := py.NewContext()
+	ctx.AddAuditHook(func(event string, args py.Tuple) error {
+		events = append(events, event)
+		if event == "exec" {
+			return py.ExceptionNewf(py.PermissionError, "exec not allowed")
+		}
+		return nil
+	})
+	py.RunInContext(ctx, func() {
+		globals := py.NewStringDict()
+		_, err = vm.Run(globals, globals, obj.(*py.Code), nil)
+	})
+	if !py.IsException(py.PermissionError, err) {
+		t.Errorf("want PermissionError got %v", err)
+	}
+	want := []string{"import", "compile", "exec"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("want %q got %q", want, events)
+	}
+}
@@ -0,0 +1,594 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// WeakValueDictionary, WeakKeyDictionary and WeakSet
+//
+// These keep weak references in a dict or set, with callbacks which
+// remove the entries when their objects are collected.  As the
+// callbacks run a little after the objects go, dead entries are
+// skipped when the contents are read.
+
+package weakref
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+const weak_value_dictionary_doc = `Mapping class that references values weakly.
+
+Entries in the dictionary will be discarded when no strong
+reference to the value exists anymore`
+
+const weak_key_dictionary_doc = `Mapping class that references keys weakly.
+
+Entries in the dictionary will be discarded when there is no
+longer a strong reference to the key. This can be used to
+associate additional data with an object owned by other parts of
+an application without adding attributes to those objects. This
+can be especially useful with objects that override attribute
+accesses.`
+
+const weak_set_doc = `Set class that references its items weakly.
+
+Items are discarded when no strong reference to them exists anymore.`
+
+var (
+	WeakValueDictionaryType = py.ObjectType.NewTypeFlags("WeakValueDictionary", weak_value_dictionary_doc, WeakValueDictionaryNew, weakDictInit, py.ObjectType.Flags|py.TPFLAGS_BASETYPE)
+	WeakKeyDictionaryType   = py.ObjectType.NewTypeFlags("WeakKeyDictionary", weak_key_dictionary_doc, WeakKeyDictionaryNew, weakDictInit, py.ObjectType.Flags|py.TPFLAGS_BASETYPE)
+	WeakSetType             = py.ObjectType.NewTypeFlags("WeakSet", weak_set_doc, WeakSetNew, WeakSetInit, py.ObjectType.Flags|py.TPFLAGS_BASETYPE)
+)
+
+// Makes a callback for a reference which calls fn with it
+func newCallback(fn func(ref *Ref)) *py.Method {
+	return py.MustNewMethod("_remove", func(self, arg py.Object) (py.Object, error) {
+		if ref, ok := arg.(*Ref); ok {
+			fn(ref)
+		}
+		return py.None, nil
+	}, 0, "")
+}
+
+// Collects the items of a mapping or iterable of pairs and kwargs as
+// dict.update does
+func updateItems(args py.Tuple, kwargs py.StringDict) ([]py.Tuple, error) {
+	if len(args) > 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "expected at most 1 arguments, got %d", len(args))
+	}
+	d := py.NewDict()
+	_, err := py.DictType.Dict["update"].(*py.Method).CallWithKeywords(d, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return d.Items(), nil
+}
+
+// The part shared by WeakValueDictionary and WeakKeyDictionary
+type weakDict struct {
+	Base   *py.Type
+	Dict   py.StringDict
+	data   *py.Dict
+	remove *py.Method // the callback of the references
+}
+
+// Type of this object
+func (d *weakDict) Type() *py.Type {
+	return d.Base
+}
+
+// GetDict returns the attributes of the dictionary
+func (d *weakDict) GetDict() py.StringDict {
+	return d.Dict
+}
+
+func (d *weakDict) M__hash__() (py.Object, error) {
+	return nil, py.ExceptionNewf(py.TypeError, "unhashable type: '%s'", d.Base.Name)
+}
+
+func (d *weakDict) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<%s at %p>", d.Base.Name, d)), nil
+}
+
+// Adds the items of a mapping or iterable of pairs and kwargs to d
+func weakDictUpdate(d py.Object, args py.Tuple, kwargs py.StringDict) error {
+	items, err := updateItems(args, kwargs)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		_, err = py.SetItem(d, item[0], item[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Initialises a WeakValueDictionary or WeakKeyDictionary from a
+// mapping or iterable of pairs and kwargs
+func weakDictInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	return weakDictUpdate(self, args, kwargs)
+}
+
+// WeakValueDictionary is a dict whose values are weak references
+type WeakValueDictionary struct {
+	weakDict
+}
+
+// WeakValueDictionaryNew makes an empty WeakValueDictionary
+func WeakValueDictionaryNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newWeakValueDictionary(metatype), nil
+}
+
+// Makes an empty WeakValueDictionary of type t
+func newWeakValueDictionary(t *py.Type) *WeakValueDictionary {
+	d := &WeakValueDictionary{weakDict{Base: t, Dict: py.NewStringDict(), data: py.NewDict()}}
+	d.remove = newCallback(func(ref *Ref) {
+		// Only remove the key if it hasn't been set again
+		key := ref.Dict["key"]
+		if value, found, _ := d.data.Get(key); found && value == ref {
+			_, _, _ = d.data.Delete(key)
+		}
+	})
+	return d
+}
+
+// Returns the live items as (key, value) pairs
+func (d *WeakValueDictionary) items() []py.Tuple {
+	var items []py.Tuple
+	for _, item := range d.data.Items() {
+		if value := item[1].(*Ref).Get(); value != nil {
+			items = append(items, py.Tuple{item[0], value})
+		}
+	}
+	return items
+}
+
+// Get returns the value of key if it is there and still alive
+func (d *WeakValueDictionary) Get(key py.Object) (py.Object, bool, error) {
+	ref, found, err := d.data.Get(key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	value := ref.(*Ref).Get()
+	return value, value != nil, nil
+}
+
+func (d *WeakValueDictionary) M__getitem__(key py.Object) (py.Object, error) {
+	value, found, err := d.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+	}
+	return value, nil
+}
+
+func (d *WeakValueDictionary) M__setitem__(key, value py.Object) (py.Object, error) {
+	ref, err := newRef(RefType, value, d.remove, nil)
+	if err != nil {
+		return nil, err
+	}
+	ref.Dict["key"] = key
+	return py.None, d.data.Set(key, ref)
+}
+
+func (d *WeakValueDictionary) M__delitem__(key py.Object) (py.Object, error) {
+	_, found, err := d.data.Delete(key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+	}
+	return py.None, nil
+}
+
+func (d *WeakValueDictionary) M__contains__(key py.Object) (py.Object, error) {
+	_, found, err := d.Get(key)
+	return py.NewBool(found), err
+}
+
+func (d *WeakValueDictionary) M__len__() (py.Object, error) {
+	return py.Int(len(d.items())), nil
+}
+
+func (d *WeakValueDictionary) M__iter__() (py.Object, error) {
+	return d.keys(), nil
+}
+
+// Returns an iterator over the keys whose values are alive
+func (d *WeakValueDictionary) keys() *py.Iterator {
+	var keys []py.Object
+	for _, item := range d.items() {
+		keys = append(keys, item[0])
+	}
+	return py.NewIterator(keys)
+}
+
+// WeakKeyDictionary is a dict whose keys are weak references
+type WeakKeyDictionary struct {
+	weakDict
+}
+
+// WeakKeyDictionaryNew makes an empty WeakKeyDictionary
+func WeakKeyDictionaryNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newWeakKeyDictionary(metatype), nil
+}
+
+// Makes an empty WeakKeyDictionary of type t
+func newWeakKeyDictionary(t *py.Type) *WeakKeyDictionary {
+	d := &WeakKeyDictionary{weakDict{Base: t, Dict: py.NewStringDict(), data: py.NewDict()}}
+	d.remove = newCallback(func(ref *Ref) {
+		_, _, _ = d.data.Delete(ref)
+	})
+	return d
+}
+
+// Returns the live items as (key, value) pairs
+func (d *WeakKeyDictionary) items() []py.Tuple {
+	var items []py.Tuple
+	for _, item := range d.data.Items() {
+		if key := item[0].(*Ref).Get(); key != nil {
+			items = append(items, py.Tuple{key, item[1]})
+		}
+	}
+	return items
+}
+
+// Get returns the value of key if it is there
+func (d *WeakKeyDictionary) Get(key py.Object) (py.Object, bool, error) {
+	ref, err := NewRef(key, nil)
+	if err != nil {
+		// Objects which can't be weakly referenced can't be in it
+		if py.IsException(py.TypeError, err) {
+			err = nil
+		}
+		return nil, false, err
+	}
+	return d.data.Get(ref)
+}
+
+func (d *WeakKeyDictionary) M__getitem__(key py.Object) (py.Object, error) {
+	value, found, err := d.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+	}
+	return value, nil
+}
+
+func (d *WeakKeyDictionary) M__setitem__(key, value py.Object) (py.Object, error) {
+	// Replace the value of an existing key keeping its reference
+	ref, err := NewRef(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, found, err := d.data.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		ref, err = newRef(RefType, key, d.remove, nil)
+		if err != nil {
+			return nil, err
+		}
+		// Remember the hash for removing the key once it has gone
+		_, err = ref.M__hash__()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.None, d.data.Set(ref, value)
+}
+
+func (d *WeakKeyDictionary) M__delitem__(key py.Object) (py.Object, error) {
+	ref, err := NewRef(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, found, err := d.data.Delete(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+	}
+	return py.None, nil
+}
+
+func (d *WeakKeyDictionary) M__contains__(key py.Object) (py.Object, error) {
+	_, found, err := d.Get(key)
+	return py.NewBool(found), err
+}
+
+func (d *WeakKeyDictionary) M__len__() (py.Object, error) {
+	return py.Int(len(d.items())), nil
+}
+
+func (d *WeakKeyDictionary) M__iter__() (py.Object, error) {
+	return d.keys(), nil
+}
+
+// Returns an iterator over the keys which are alive
+func (d *WeakKeyDictionary) keys() *py.Iterator {
+	var keys []py.Object
+	for _, item := range d.items() {
+		keys = append(keys, item[0])
+	}
+	return py.NewIterator(keys)
+}
+
+// The methods shared by the weak dictionaries
+type weakMapping interface {
+	py.Object
+	py.I__getitem__
+	py.I__setitem__
+	py.I__delitem__
+	Get(key py.Object) (py.Object, bool, error)
+	items() []py.Tuple
+	keys() *py.Iterator
+}
+
+// Adds the dict methods to the type of a weak dictionary
+func addMappingMethods(t *py.Type, refs string) {
+	t.Dict["get"] = py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, def py.Object = nil, py.None
+		err := py.UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		value, found, err := self.(weakMapping).Get(key)
+		if err != nil || !found {
+			return def, err
+		}
+		return value, nil
+	}, 0, "D.get(k[,d]) -> D[k] if k in D, else d.  d defaults to None.")
+	t.Dict["keys"] = py.MustNewMethod("keys", func(self py.Object) (py.Object, error) {
+		return self.(weakMapping).keys(), nil
+	}, 0, "D.keys() -> an iterator over the keys of D")
+	t.Dict["values"] = py.MustNewMethod("values", func(self py.Object) (py.Object, error) {
+		var values []py.Object
+		for _, item := range self.(weakMapping).items() {
+			values = append(values, item[1])
+		}
+		return py.NewIterator(values), nil
+	}, 0, "D.values() -> an iterator over the values of D")
+	t.Dict["items"] = py.MustNewMethod("items", func(self py.Object) (py.Object, error) {
+		var items []py.Object
+		for _, item := range self.(weakMapping).items() {
+			items = append(items, item)
+		}
+		return py.NewIterator(items), nil
+	}, 0, "D.items() -> an iterator over the (key, value) items of D")
+	t.Dict["pop"] = py.MustNewMethod("pop", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, def py.Object
+		err := py.UnpackTuple(args, nil, "pop", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(weakMapping)
+		value, found, err := d.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if def != nil {
+				return def, nil
+			}
+			return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{key}}
+		}
+		_, err = d.M__delitem__(key)
+		return value, err
+	}, 0, "D.pop(k[,d]) -> v, remove specified key and return the corresponding value.\nIf key is not found, d is returned if given, otherwise KeyError is raised")
+	t.Dict["setdefault"] = py.MustNewMethod("setdefault", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, def py.Object = nil, py.None
+		err := py.UnpackTuple(args, nil, "setdefault", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(weakMapping)
+		value, found, err := d.Get(key)
+		if err != nil || found {
+			return value, err
+		}
+		_, err = d.M__setitem__(key, def)
+		return def, err
+	}, 0, "D.setdefault(k[,d]) -> D.get(k,d), also set D[k]=d if k not in D")
+	t.Dict["update"] = py.MustNewMethod("update", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return py.None, weakDictUpdate(self, args, kwargs)
+	}, 0, "D.update([E, ]**F) -> None.  Update D from mapping/iterable E and F.")
+	t.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object) (py.Object, error) {
+		d := self.(weakMapping)
+		for _, item := range d.items() {
+			_, err := d.M__delitem__(item[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return py.None, nil
+	}, 0, "D.clear() -> None.  Remove all items from D.")
+	t.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		d := self.(weakMapping)
+		c, err := py.Call(d.Type(), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range d.items() {
+			_, err = py.SetItem(c, item[0], item[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
+	}, 0, "D.copy() -> a shallow copy of D")
+	t.Dict[refs] = py.MustNewMethod(refs, func(self py.Object) (py.Object, error) {
+		list := py.NewList()
+		for _, item := range self.(weakMapping).items() {
+			var ref *Ref
+			var err error
+			if refs == "keyrefs" {
+				ref, err = NewRef(item[0], nil)
+			} else {
+				ref, err = NewRef(item[1], nil)
+			}
+			if err != nil {
+				return nil, err
+			}
+			list.Append(ref)
+		}
+		return list, nil
+	}, 0, "Return a list of weak references to the "+refs[:len(refs)-4]+"s.")
+}
+
+// WeakSet is a set of weak references
+type WeakSet struct {
+	Base   *py.Type
+	Dict   py.StringDict
+	data   *py.Set
+	remove *py.Method // the callback of the references
+}
+
+// WeakSetNew makes an empty WeakSet
+func WeakSetNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := &WeakSet{Base: metatype, Dict: py.NewStringDict(), data: py.NewSet()}
+	s.remove = newCallback(func(ref *Ref) {
+		_, _ = s.data.Discard(ref)
+	})
+	return s, nil
+}
+
+// WeakSetInit adds the items of an iterable
+func WeakSetInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	var iterable py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, "WeakSet", 0, 1, &iterable)
+	if err != nil || iterable == py.None {
+		return err
+	}
+	s := self.(*WeakSet)
+	return py.Iterate(iterable, func(item py.Object) bool {
+		err = s.Add(item)
+		return err != nil
+	})
+}
+
+// Type of this object
+func (s *WeakSet) Type() *py.Type {
+	return s.Base
+}
+
+// GetDict returns the attributes of the set
+func (s *WeakSet) GetDict() py.StringDict {
+	return s.Dict
+}
+
+// Add adds a weak reference to item
+func (s *WeakSet) Add(item py.Object) error {
+	ref, err := NewRef(item, nil)
+	if err != nil {
+		return err
+	}
+	found, err := s.data.Contains(ref)
+	if err != nil || found {
+		return err
+	}
+	ref, err = newRef(RefType, item, s.remove, nil)
+	if err != nil {
+		return err
+	}
+	return s.data.Add(ref)
+}
+
+// Discard removes item returning whether it was there
+func (s *WeakSet) Discard(item py.Object) (bool, error) {
+	ref, err := NewRef(item, nil)
+	if err != nil {
+		if py.IsException(py.TypeError, err) {
+			err = nil
+		}
+		return false, err
+	}
+	return s.data.Discard(ref)
+}
+
+// Items returns the items which are alive
+func (s *WeakSet) Items() []py.Object {
+	var items []py.Object
+	for _, ref := range s.data.Items() {
+		if item := ref.(*Ref).Get(); item != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (s *WeakSet) M__hash__() (py.Object, error) {
+	return nil, py.ExceptionNewf(py.TypeError, "unhashable type: '%s'", s.Base.Name)
+}
+
+func (s *WeakSet) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<%s at %p>", s.Base.Name, s)), nil
+}
+
+func (s *WeakSet) M__contains__(item py.Object) (py.Object, error) {
+	ref, err := NewRef(item, nil)
+	if err != nil {
+		if py.IsException(py.TypeError, err) {
+			return py.False, nil
+		}
+		return nil, err
+	}
+	found, err := s.data.Contains(ref)
+	return py.NewBool(found), err
+}
+
+func (s *WeakSet) M__len__() (py.Object, error) {
+	return py.Int(len(s.Items())), nil
+}
+
+func (s *WeakSet) M__iter__() (py.Object, error) {
+	return py.NewIterator(s.Items()), nil
+}
+
+func init() {
+	addMappingMethods(WeakValueDictionaryType, "valuerefs")
+	addMappingMethods(WeakKeyDictionaryType, "keyrefs")
+
+	WeakSetType.Dict["add"] = py.MustNewMethod("add", func(self, item py.Object) (py.Object, error) {
+		return py.None, self.(*WeakSet).Add(item)
+	}, 0, "Add an element to the set.")
+	WeakSetType.Dict["discard"] = py.MustNewMethod("discard", func(self, item py.Object) (py.Object, error) {
+		_, err := self.(*WeakSet).Discard(item)
+		return py.None, err
+	}, 0, "Remove an element from the set if it is a member.")
+	WeakSetType.Dict["remove"] = py.MustNewMethod("remove", func(self, item py.Object) (py.Object, error) {
+		found, err := self.(*WeakSet).Discard(item)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, &py.Exception{Base: py.KeyError, Args: py.Tuple{item}}
+		}
+		return py.None, nil
+	}, 0, "Remove an element from the set; it must be a member.")
+	WeakSetType.Dict["pop"] = py.MustNewMethod("pop", func(self py.Object) (py.Object, error) {
+		s := self.(*WeakSet)
+		for _, item := range s.Items() {
+			_, err := s.Discard(item)
+			return item, err
+		}
+		return nil, py.ExceptionNewf(py.KeyError, "pop from empty WeakSet")
+	}, 0, "Remove and return an arbitrary set element.")
+	WeakSetType.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object) (py.Object, error) {
+		self.(*WeakSet).data.Clear()
+		return py.None, nil
+	}, 0, "Remove all elements from the set.")
+	WeakSetType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return py.Call(self.Type(), py.Tuple{py.NewListFromItems(self.(*WeakSet).Items())}, nil)
+	}, 0, "Return a shallow copy of the set.")
+}
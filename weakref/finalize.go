@@ -0,0 +1,130 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Finalizers
+
+package weakref
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+const finalize_doc = `finalize(obj, func, *args, **kwargs) -> finalizer
+
+Class for finalization of weakrefable objects
+
+finalize(obj, func, *args, **kwargs) returns a callable finalizer
+object which will be called when obj is garbage collected. The
+first time the finalizer is called it evaluates func(*arg, **kwargs)
+and returns the result. After this the finalizer is dead, and
+calling it just returns None.`
+
+// FinalizeType is the type of finalizers
+var FinalizeType = py.ObjectType.NewTypeFlags("finalize", finalize_doc, FinalizeNew, nil, py.ObjectType.Flags|py.TPFLAGS_BASETYPE)
+
+// Finalize calls a function when an object is collected
+type Finalize struct {
+	Base   *py.Type
+	Dict   py.StringDict
+	ref    *Ref
+	fn     py.Object
+	args   py.Tuple
+	kwargs py.StringDict
+	alive  bool
+}
+
+// Type of this object
+func (f *Finalize) Type() *py.Type {
+	return f.Base
+}
+
+// GetDict returns the attributes of the finalizer
+func (f *Finalize) GetDict() py.StringDict {
+	return f.Dict
+}
+
+// FinalizeNew makes a finalizer
+func FinalizeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 2 {
+		return nil, py.ExceptionNewf(py.TypeError, "finalize expected at least 2 arguments, got %d", len(args))
+	}
+	f := &Finalize{
+		Base:   metatype,
+		Dict:   py.NewStringDict(),
+		fn:     args[1],
+		args:   append(py.Tuple(nil), args[2:]...),
+		kwargs: kwargs,
+		alive:  true,
+	}
+	callback := newCallback(func(ref *Ref) {
+		_, err := f.call()
+		if err != nil {
+			py.WriteUnraisable(err, f)
+		}
+	})
+	ref, err := newRef(RefType, args[0], callback, nil)
+	if err != nil {
+		return nil, err
+	}
+	f.ref = ref
+	return f, nil
+}
+
+// Calls the function if the finalizer is alive, returning None if not
+func (f *Finalize) call() (py.Object, error) {
+	if !f.alive {
+		return py.None, nil
+	}
+	f.alive = false
+	return py.Call(f.fn, f.args, f.kwargs)
+}
+
+// Returns (obj, func, args, kwargs) if the finalizer is alive or None
+func (f *Finalize) info() py.Object {
+	obj := f.ref.Get()
+	if obj == nil || !f.alive {
+		return py.None
+	}
+	kwargs := py.NewDict()
+	for k, v := range f.kwargs {
+		_ = kwargs.Set(py.String(k), v)
+	}
+	return py.Tuple{obj, f.fn, f.args, kwargs}
+}
+
+func (f *Finalize) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "finalize", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return f.call()
+}
+
+func (f *Finalize) M__repr__() (py.Object, error) {
+	obj := f.ref.Get()
+	if obj == nil || !f.alive {
+		return py.String(fmt.Sprintf("<finalize object at %p; dead>", f)), nil
+	}
+	return py.String(fmt.Sprintf("<finalize object at %p; for '%s' at %p>", f, obj.Type().Name, obj)), nil
+}
+
+func init() {
+	FinalizeType.Dict["alive"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.NewBool(self.(*Finalize).alive), nil
+		},
+		Doc: "Whether finalizer is alive",
+	}
+	FinalizeType.Dict["detach"] = py.MustNewMethod("detach", func(self py.Object) (py.Object, error) {
+		f := self.(*Finalize)
+		info := f.info()
+		f.alive = false
+		return info, nil
+	}, 0, "If alive then mark as dead and return (obj, func, args, kwargs);\notherwise return None")
+	FinalizeType.Dict["peek"] = py.MustNewMethod("peek", func(self py.Object) (py.Object, error) {
+		return self.(*Finalize).info(), nil
+	}, 0, "If alive then return (obj, func, args, kwargs);\notherwise return None")
+}
@@ -0,0 +1,288 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Weak reference proxies
+//
+// A proxy passes the operations done on it on to the object it
+// refers to, raising ReferenceError once the object has gone.
+
+package weakref
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	ProxyType         = py.NewType("weakproxy", "")
+	CallableProxyType = py.NewType("weakcallableproxy", "")
+)
+
+// Proxy is a weak reference which acts as the object it refers to
+type Proxy struct {
+	ref *Ref
+}
+
+// CallableProxy is a Proxy to an object which can be called
+type CallableProxy struct {
+	*Proxy
+}
+
+// Type of this object
+func (p *Proxy) Type() *py.Type {
+	return ProxyType
+}
+
+// Type of this object
+func (p *CallableProxy) Type() *py.Type {
+	return CallableProxyType
+}
+
+const proxy_doc = `proxy(object[, callback]) -- create a proxy object that weakly
+references 'object'.  'callback', if given, is called with a
+reference to the proxy when 'object' is about to be finalized.`
+
+func weakref_proxy(self py.Object, args py.Tuple) (py.Object, error) {
+	var obj, callback py.Object = nil, py.None
+	err := py.UnpackTuple(args, nil, "proxy", 1, 2, &obj, &callback)
+	if err != nil {
+		return nil, err
+	}
+	return NewProxy(obj, callback)
+}
+
+// NewProxy makes a proxy for obj, which is called with the proxy when
+// obj is collected if callback isn't nil or None.  If obj is callable
+// the proxy is a *CallableProxy.
+func NewProxy(obj, callback py.Object) (py.Object, error) {
+	p := &Proxy{}
+	var res py.Object = p
+	if callable(obj) {
+		res = &CallableProxy{p}
+	}
+	ref, err := newRef(RefType, obj, callback, res)
+	if err != nil {
+		return nil, err
+	}
+	p.ref = ref
+	return res, nil
+}
+
+// Returns true if obj can be called
+func callable(obj py.Object) bool {
+	if t, ok := obj.(*py.Type); ok {
+		cls := t.Type()
+		return cls.IsSubtype(py.TypeType) || cls.Lookup("__call__") != nil
+	}
+	_, ok := obj.(py.I__call__)
+	return ok
+}
+
+// Returns the object referred to or a ReferenceError
+func (p *Proxy) get() (py.Object, error) {
+	obj := p.ref.Get()
+	if obj == nil {
+		return nil, py.ExceptionNewf(py.ReferenceError, "weakly-referenced object no longer exists")
+	}
+	return obj, nil
+}
+
+// Calls fn with the object or returns a ReferenceError
+func (p *Proxy) with(fn func(obj py.Object) (py.Object, error)) (py.Object, error) {
+	obj, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	return fn(obj)
+}
+
+func (p *CallableProxy) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		return py.Call(obj, args, kwargs)
+	})
+}
+
+func (p *Proxy) M__getattribute__(name string) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		return py.GetAttrString(obj, name)
+	})
+}
+
+func (p *Proxy) M__setattr__(name string, value py.Object) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		return py.SetAttrString(obj, name, value)
+	})
+}
+
+func (p *Proxy) M__delattr__(name string) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		return py.None, py.DeleteAttrString(obj, name)
+	})
+}
+
+func (p *Proxy) M__repr__() (py.Object, error) {
+	obj := p.ref.Get()
+	if obj == nil {
+		return py.String(fmt.Sprintf("<weakproxy at %p; dead>", p)), nil
+	}
+	return py.String(fmt.Sprintf("<weakproxy at %p to %s at %p>", p, obj.Type().Name, obj)), nil
+}
+
+func (p *Proxy) M__str__() (py.Object, error) {
+	return p.with(py.Str)
+}
+
+func (p *Proxy) M__hash__() (py.Object, error) {
+	return nil, py.ExceptionNewf(py.TypeError, "unhashable type: 'weakproxy'")
+}
+
+func (p *Proxy) M__bool__() (py.Object, error) {
+	return p.with(py.MakeBool)
+}
+
+func (p *Proxy) M__len__() (py.Object, error) {
+	return p.with(py.Len)
+}
+
+func (p *Proxy) M__iter__() (py.Object, error) {
+	return p.with(py.Iter)
+}
+
+func (p *Proxy) M__getitem__(key py.Object) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		return py.GetItem(obj, key)
+	})
+}
+
+func (p *Proxy) M__setitem__(key, value py.Object) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		return py.SetItem(obj, key, value)
+	})
+}
+
+func (p *Proxy) M__delitem__(key py.Object) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		return py.DelItem(obj, key)
+	})
+}
+
+func (p *Proxy) M__contains__(item py.Object) (py.Object, error) {
+	return p.with(func(obj py.Object) (py.Object, error) {
+		found, err := py.SequenceContains(obj, item)
+		return py.NewBool(found), err
+	})
+}
+
+// Applies op to the object and other, or other and the object if
+// reflected, unwrapping other if it is a proxy too
+func (p *Proxy) binary(op func(a, b py.Object) (py.Object, error), other py.Object, reflected bool) (py.Object, error) {
+	obj, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	other, err = unwrap(other)
+	if err != nil {
+		return nil, err
+	}
+	if reflected {
+		return op(other, obj)
+	}
+	return op(obj, other)
+}
+
+// Returns the object a proxy refers to or obj if it isn't a proxy
+func unwrap(obj py.Object) (py.Object, error) {
+	switch x := obj.(type) {
+	case *Proxy:
+		return x.get()
+	case *CallableProxy:
+		return x.get()
+	}
+	return obj, nil
+}
+
+func (p *Proxy) M__eq__(other py.Object) (py.Object, error) {
+	return p.binary(py.Eq, other, false)
+}
+
+func (p *Proxy) M__ne__(other py.Object) (py.Object, error) {
+	return p.binary(py.Ne, other, false)
+}
+
+func (p *Proxy) M__lt__(other py.Object) (py.Object, error) {
+	return p.binary(py.Lt, other, false)
+}
+
+func (p *Proxy) M__le__(other py.Object) (py.Object, error) {
+	return p.binary(py.Le, other, false)
+}
+
+func (p *Proxy) M__gt__(other py.Object) (py.Object, error) {
+	return p.binary(py.Gt, other, false)
+}
+
+func (p *Proxy) M__ge__(other py.Object) (py.Object, error) {
+	return p.binary(py.Ge, other, false)
+}
+
+func (p *Proxy) M__add__(other py.Object) (py.Object, error) {
+	return p.binary(py.Add, other, false)
+}
+
+func (p *Proxy) M__radd__(other py.Object) (py.Object, error) {
+	return p.binary(py.Add, other, true)
+}
+
+func (p *Proxy) M__sub__(other py.Object) (py.Object, error) {
+	return p.binary(py.Sub, other, false)
+}
+
+func (p *Proxy) M__rsub__(other py.Object) (py.Object, error) {
+	return p.binary(py.Sub, other, true)
+}
+
+func (p *Proxy) M__mul__(other py.Object) (py.Object, error) {
+	return p.binary(py.Mul, other, false)
+}
+
+func (p *Proxy) M__rmul__(other py.Object) (py.Object, error) {
+	return p.binary(py.Mul, other, true)
+}
+
+func (p *Proxy) M__truediv__(other py.Object) (py.Object, error) {
+	return p.binary(py.TrueDiv, other, false)
+}
+
+func (p *Proxy) M__rtruediv__(other py.Object) (py.Object, error) {
+	return p.binary(py.TrueDiv, other, true)
+}
+
+func (p *Proxy) M__floordiv__(other py.Object) (py.Object, error) {
+	return p.binary(py.FloorDiv, other, false)
+}
+
+func (p *Proxy) M__rfloordiv__(other py.Object) (py.Object, error) {
+	return p.binary(py.FloorDiv, other, true)
+}
+
+func (p *Proxy) M__mod__(other py.Object) (py.Object, error) {
+	return p.binary(py.Mod, other, false)
+}
+
+func (p *Proxy) M__rmod__(other py.Object) (py.Object, error) {
+	return p.binary(py.Mod, other, true)
+}
+
+func (p *Proxy) M__neg__() (py.Object, error) {
+	return p.with(py.Neg)
+}
+
+func (p *Proxy) M__pos__() (py.Object, error) {
+	return p.with(py.Pos)
+}
+
+func (p *Proxy) M__abs__() (py.Object, error) {
+	return p.with(py.Abs)
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Weak reference objects
+
+package weakref
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+const ref_doc = `ref(object[, callback]) -- create a weak reference to object
+
+Calling the reference returns the object, or None once it has been
+collected.  If callback is given it is called with the reference when
+the object is about to be collected.`
+
+// RefType is the type of weak references
+var RefType = py.ObjectType.NewTypeFlags("weakref", ref_doc, nil, nil, py.ObjectType.Flags|py.TPFLAGS_BASETYPE|py.TPFLAGS_SUBCLASS_NEW)
+
+// Ref is a weak reference to an object
+type Ref struct {
+	Base     *py.Type
+	Dict     py.StringDict
+	target   *referent
+	callback py.Object // called with the reference when the object goes
+	proxy    py.Object // the proxy if this is the reference of one
+	hash     int64
+	hashed   bool
+}
+
+// Type of this object
+func (r *Ref) Type() *py.Type {
+	return r.Base
+}
+
+// GetDict returns the attributes of the reference
+func (r *Ref) GetDict() py.StringDict {
+	return r.Dict
+}
+
+// Makes a weak reference of type t to obj, reusing the existing plain
+// reference if there is one and no callback is wanted
+func newRef(t *py.Type, obj, callback, proxy py.Object) (*Ref, error) {
+	if callback == py.None {
+		callback = nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	target, err := register(obj)
+	if err != nil {
+		return nil, err
+	}
+	if t == RefType && callback == nil && proxy == nil {
+		for _, ref := range target.refs {
+			if ref.Base == RefType && ref.callback == nil && ref.proxy == nil {
+				return ref, nil
+			}
+		}
+	}
+	ref := &Ref{Base: t, Dict: py.NewStringDict(), target: target, callback: callback, proxy: proxy}
+	target.refs = append(target.refs, ref)
+	return ref, nil
+}
+
+// NewRef makes a weak reference to obj, which is called with the
+// reference when obj is collected if callback isn't nil or None
+func NewRef(obj, callback py.Object) (*Ref, error) {
+	return newRef(RefType, obj, callback, nil)
+}
+
+// RefNew makes a weak reference
+func RefNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj, callback py.Object = nil, py.None
+	err := py.UnpackTuple(args, nil, "__new__", 1, 2, &obj, &callback)
+	if err != nil {
+		return nil, err
+	}
+	return newRef(metatype, obj, callback, nil)
+}
+
+// Get returns the object referred to or nil if it has been collected
+func (r *Ref) Get() py.Object {
+	mu.Lock()
+	defer mu.Unlock()
+	return r.target.object()
+}
+
+// Returns the object passed to the callback and given out by
+// getweakrefs for the reference
+func (r *Ref) self() py.Object {
+	if r.proxy != nil {
+		return r.proxy
+	}
+	return r
+}
+
+func (r *Ref) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "weakref", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if obj := r.Get(); obj != nil {
+		return obj, nil
+	}
+	return py.None, nil
+}
+
+// The hash is that of the object, remembered so it can still be used
+// once the object has gone
+func (r *Ref) M__hash__() (py.Object, error) {
+	if !r.hashed {
+		obj := r.Get()
+		if obj == nil {
+			return nil, py.ExceptionNewf(py.TypeError, "weak object has gone away")
+		}
+		hash, err := py.Hash(obj)
+		if err != nil {
+			return nil, err
+		}
+		r.hash, r.hashed = hash, true
+	}
+	return py.Int(r.hash), nil
+}
+
+// References compare equal if their objects do while they are both
+// alive, and otherwise only if they are the same reference
+func (r *Ref) M__eq__(other py.Object) (py.Object, error) {
+	o, ok := other.(*Ref)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	a, b := r.Get(), o.Get()
+	if a == nil || b == nil {
+		return py.NewBool(r == o), nil
+	}
+	return py.Eq(a, b)
+}
+
+func (r *Ref) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := r.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.Not(eq)
+}
+
+func (r *Ref) M__repr__() (py.Object, error) {
+	obj := r.Get()
+	if obj == nil {
+		return py.String(fmt.Sprintf("<weakref at %p; dead>", r)), nil
+	}
+	return py.String(fmt.Sprintf("<weakref at %p; to '%s' at %p>", r, obj.Type().Name, obj)), nil
+}
+
+func init() {
+	// Set here to avoid an initialization loop
+	RefType.New = RefNew
+	RefType.Dict["__callback__"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			if callback := self.(*Ref).callback; callback != nil {
+				return callback, nil
+			}
+			return py.None, nil
+		},
+	}
+}
@@ -0,0 +1,186 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import weakref
+from libtest import *
+
+class C:
+    def method(self):
+        return 42
+    def __call__(self, x):
+        return x * 2
+    def __len__(self):
+        return 3
+
+doc="ref"
+c = C()
+r = weakref.ref(c)
+assertTrue(r() is c)
+assertTrue(weakref.ref(c) is r)
+assertTrue(weakref.ReferenceType is weakref.ref)
+assertTrue(isinstance(r, weakref.ref))
+assertEqual(r.__callback__, None)
+assertTrue(repr(r).startswith("<weakref at "))
+assertTrue("to 'C'" in repr(r))
+assertRaises(TypeError, r, 1)
+
+doc="ref with callback"
+def callback(ref):
+    pass
+r2 = weakref.ref(c, callback)
+assertTrue(r2 is not r)
+assertTrue(r2.__callback__ is callback)
+assertEqual(r, r2)
+assertEqual(hash(r), hash(c))
+assertEqual(weakref.getweakrefcount(c), 2)
+assertEqual(weakref.getweakrefs(c), [r, r2])
+assertEqual(weakref.getweakrefcount(C()), 0)
+
+doc="__weakref__"
+assertTrue(c.__weakref__ is r)
+assertEqual(C().__weakref__, None)
+
+doc="ref subclass"
+class KeyedRef(weakref.ref):
+    pass
+kr = KeyedRef(c)
+assertTrue(kr is not r)
+assertTrue(kr() is c)
+kr.key = "k"
+assertEqual(kr.key, "k")
+
+doc="what can be weakly referenced"
+for obj in (C, len.__class__, lambda: 1, c.method, {1}, frozenset(), weakref):
+    assertTrue(weakref.ref(obj)() is obj)
+for obj in (1, "s", (), [], {}, None, object()):
+    assertRaises(TypeError, weakref.ref, obj)
+class Slotted:
+    __slots__ = ("x",)
+assertRaises(TypeError, weakref.ref, Slotted())
+class SlottedWeak:
+    __slots__ = ("x", "__weakref__")
+assertTrue(weakref.ref(SlottedWeak())() is not None)
+sw = SlottedWeak()
+swr = weakref.ref(sw)
+assertTrue(sw.__weakref__ is swr)
+
+doc="proxy"
+p = weakref.proxy(c)
+assertEqual(type(p), weakref.CallableProxyType)
+assertEqual(p.method(), 42)
+assertEqual(p(4), 8)
+assertEqual(len(p), 3)
+p.attr = 1
+assertEqual(c.attr, 1)
+del p.attr
+assertFalse(hasattr(c, "attr"))
+assertTrue(p == c)
+assertRaises(TypeError, hash, p)
+class N:
+    def __init__(self, n):
+        self.n = n
+    def __add__(self, other):
+        return self.n + other
+    def __radd__(self, other):
+        return other - self.n
+n = N(10)
+pn = weakref.proxy(n)
+assertEqual(type(pn), weakref.ProxyType)
+assertEqual(pn + 1, 11)
+assertEqual(1 + pn, -9)
+assertEqual(pn.n, 10)
+assertTrue(weakref.ProxyTypes == (weakref.ProxyType, weakref.CallableProxyType))
+
+doc="WeakValueDictionary"
+d = weakref.WeakValueDictionary()
+a, b = C(), C()
+d["a"] = a
+d["b"] = b
+assertTrue(d["a"] is a)
+assertEqual(len(d), 2)
+assertEqual(sorted(d), ["a", "b"])
+assertEqual(sorted(d.keys()), ["a", "b"])
+assertTrue("a" in d)
+assertFalse("z" in d)
+assertEqual(d.get("z"), None)
+assertTrue(d.get("a") is a)
+assertEqual(len(list(d.values())), 2)
+assertEqual(sorted(k for k, v in d.items()), ["a", "b"])
+assertRaises(KeyError, lambda: d["z"])
+assertTrue(d.pop("b") is b)
+assertEqual(d.pop("b", 5), 5)
+assertRaises(KeyError, d.pop, "b")
+assertTrue(d.setdefault("a", b) is a)
+assertTrue(d.setdefault("c", b) is b)
+assertEqual(len(d.valuerefs()), 2)
+e = d.copy()
+assertEqual(sorted(e), ["a", "c"])
+del d["a"]
+assertEqual(sorted(d), ["c"])
+d.clear()
+assertEqual(len(d), 0)
+d.update({"x": a}, y=b)
+assertEqual(sorted(d), ["x", "y"])
+d = weakref.WeakValueDictionary([("q", a)])
+assertTrue(d["q"] is a)
+assertRaises(TypeError, d.__setitem__, "n", 1)
+
+doc="WeakKeyDictionary"
+k = weakref.WeakKeyDictionary()
+k[a] = 1
+k[b] = 2
+assertEqual(k[a], 1)
+k[a] = 3
+assertEqual(k[a], 3)
+assertEqual(len(k), 2)
+assertTrue(a in k)
+assertFalse(1 in k)
+assertFalse(C() in k)
+assertEqual(sorted(k.values()), [2, 3])
+assertEqual(k.get(C(), 7), 7)
+assertEqual(len(k.keyrefs()), 2)
+del k[b]
+assertEqual(list(k.items()), [(a, 3)])
+assertRaises(KeyError, lambda: k[b])
+assertRaises(TypeError, k.__setitem__, 1, 1)
+
+doc="WeakSet"
+s = weakref.WeakSet([a, b])
+assertEqual(len(s), 2)
+assertTrue(a in s)
+assertFalse(1 in s)
+s.add(a)
+assertEqual(len(s), 2)
+s.discard(a)
+assertFalse(a in s)
+s.discard(a)
+assertRaises(KeyError, s.remove, a)
+assertEqual(list(s), [b])
+assertEqual(list(s.copy()), [b])
+assertTrue(s.pop() is b)
+assertEqual(len(s), 0)
+assertRaises(KeyError, s.pop)
+assertRaises(TypeError, s.add, 1)
+
+doc="finalize"
+calls = []
+f = weakref.finalize(a, calls.append, "x")
+assertTrue(f.alive)
+info = f.peek()
+assertTrue(info[0] is a)
+assertEqual(info[2], ("x",))
+assertEqual(f(), None)
+assertEqual(calls, ["x"])
+assertFalse(f.alive)
+assertEqual(f(), None)
+assertEqual(calls, ["x"])
+assertEqual(f.peek(), None)
+g = weakref.finalize(a, calls.append, "y")
+assertEqual(g.detach()[2], ("y",))
+assertFalse(g.alive)
+assertEqual(g(), None)
+assertEqual(calls, ["x"])
+assertRaises(TypeError, weakref.finalize, 1, print)
+
+doc="finished"
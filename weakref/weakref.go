@@ -0,0 +1,228 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Weakref module
+//
+// Go has no weak pointers so the objects weakly referenced are kept
+// in a registry by address with a finalizer set on them.  The address
+// is kept as a uintptr so the garbage collector doesn't see it, and
+// since the Go heap doesn't move objects it can be turned back into the
+// object for as long as the finalizer hasn't run.
+//
+// When the finalizer runs the object is unreachable, unless it has
+// been fetched through a weak reference since the collector found it
+// unreachable.  So fetching it marks it as accessed, and the finalizer
+// of an accessed object is called again at the next collection rather
+// than the references being cleared.  Once they are cleared the
+// callbacks are queued with py.QueueFinalization for the main thread
+// or gc.collect to run.
+//
+// Objects are only collected when Go's collector finds them, so an
+// object can outlive the last reference to it for a while, and as with
+// all finalizers, objects in a reference cycle are never collected.
+// The registry holds the weak references to an object until it is
+// collected so their callbacks are always run.  See py/finalize.go
+// for more about when that happens.
+
+package weakref
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/go-python/gpython/py"
+)
+
+const module_doc = `Weak reference support for Python.
+
+This module is an implementation of PEP 205:
+
+http://www.python.org/dev/peps/pep-0205/`
+
+// The state of an object which is weakly referenced
+type referent struct {
+	addr     uintptr                        // the address of the object
+	make     func(unsafe.Pointer) py.Object // turns the address back into the object
+	refs     []*Ref                         // the references to it in the order made
+	accessed bool                           // set when fetched through a reference
+	dead     bool                           // set once the object is collected
+}
+
+var (
+	mu        sync.Mutex                // protects the referents
+	referents = map[uintptr]*referent{} // indexed by address
+)
+
+// Returns the address of obj and a function to turn it back into
+// obj, or false if it can't be weakly referenced
+func addressOf(obj py.Object) (uintptr, func(unsafe.Pointer) py.Object, bool) {
+	switch x := obj.(type) {
+	case *py.Type:
+		// Classes can be weakly referenced and so can their
+		// instances unless __slots__ leaves out __weakref__
+		if cls := x.Type(); !cls.IsSubtype(py.TypeType) && (cls.Flags&py.TPFLAGS_HEAPTYPE == 0 || cls.NoWeakref) {
+			return 0, nil, false
+		}
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.Type)(p) }, true
+	case *py.Function:
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.Function)(p) }, true
+	case *py.BoundMethod:
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.BoundMethod)(p) }, true
+	case *py.Set:
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.Set)(p) }, true
+	case *py.FrozenSet:
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.FrozenSet)(p) }, true
+	case *py.Module:
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.Module)(p) }, true
+	case *py.Code:
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.Code)(p) }, true
+	case *py.Generator:
+		return uintptr(unsafe.Pointer(x)), func(p unsafe.Pointer) py.Object { return (*py.Generator)(p) }, true
+	}
+	return 0, nil, false
+}
+
+// Returns the referent for obj, registering it if it isn't already
+//
+// It must be called with mu held.
+func register(obj py.Object) (*referent, error) {
+	addr, make, ok := addressOf(obj)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "cannot create weak reference to '%s' object", obj.Type().Name)
+	}
+	r := referents[addr]
+	if r == nil {
+		r = &referent{addr: addr, make: make}
+		referents[addr] = r
+		py.AddFinalizer(obj, finalize)
+	}
+	return r, nil
+}
+
+// Returns the referent for obj or nil if it isn't weakly referenced
+//
+// It must be called with mu held.
+func lookup(obj py.Object) *referent {
+	addr, _, ok := addressOf(obj)
+	if !ok {
+		return nil
+	}
+	return referents[addr]
+}
+
+// Returns the object or nil if it has been collected
+//
+// It must be called with mu held.
+func (r *referent) object() py.Object {
+	if r.dead {
+		return nil
+	}
+	r.accessed = true
+	// Reading the address as a pointer rather than converting
+	// it keeps vet happy - it is safe as the object is still
+	// there until the finalizer has marked it dead
+	return r.make(*(*unsafe.Pointer)(unsafe.Pointer(&r.addr)))
+}
+
+// Called by the garbage collector when obj is unreachable, returning
+// true to be called again at the next collection
+func finalize(obj py.Object) bool {
+	addr, _, _ := addressOf(obj)
+	mu.Lock()
+	r := referents[addr]
+	if r.accessed {
+		// It may have been fetched after it was found to be
+		// unreachable so wait for the next collection
+		r.accessed = false
+		mu.Unlock()
+		return true
+	}
+	r.dead = true
+	delete(referents, addr)
+	refs := r.refs
+	r.refs = nil
+	mu.Unlock()
+	py.QueueFinalization(func() {
+		runCallbacks(refs)
+	})
+	return false
+}
+
+// Calls the callbacks of the references to an object which has been
+// collected, most recently made first
+func runCallbacks(refs []*Ref) {
+	for i := len(refs) - 1; i >= 0; i-- {
+		ref := refs[i]
+		callback := ref.callback
+		if callback == nil {
+			continue
+		}
+		ref.callback = nil
+		_, err := py.Call(callback, py.Tuple{ref.self()}, nil)
+		if err != nil {
+			py.WriteUnraisable(err, callback)
+		}
+	}
+}
+
+// Returns the references to obj
+func refsTo(obj py.Object) []*Ref {
+	mu.Lock()
+	defer mu.Unlock()
+	if r := lookup(obj); r != nil {
+		return append([]*Ref(nil), r.refs...)
+	}
+	return nil
+}
+
+// Returns the first weak reference to obj, or None if there isn't
+// one, for the __weakref__ attribute of instances
+func firstWeakref(obj py.Object) py.Object {
+	if refs := refsTo(obj); len(refs) > 0 {
+		return refs[0].self()
+	}
+	return py.None
+}
+
+const getweakrefcount_doc = `getweakrefcount(object) -- return the number of weak references
+to 'object'.`
+
+func weakref_getweakrefcount(self py.Object, obj py.Object) (py.Object, error) {
+	return py.Int(len(refsTo(obj))), nil
+}
+
+const getweakrefs_doc = `getweakrefs(object) -- return a list of all weak reference objects
+that point to 'object'.`
+
+func weakref_getweakrefs(self py.Object, obj py.Object) (py.Object, error) {
+	list := py.NewList()
+	for _, ref := range refsTo(obj) {
+		list.Append(ref)
+	}
+	return list, nil
+}
+
+func init() {
+	py.FirstWeakref = firstWeakref
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "weakref",
+		Doc:  module_doc,
+		Methods: []*py.Method{
+			py.MustNewMethod("getweakrefcount", weakref_getweakrefcount, 0, getweakrefcount_doc),
+			py.MustNewMethod("getweakrefs", weakref_getweakrefs, 0, getweakrefs_doc),
+			py.MustNewMethod("proxy", weakref_proxy, 0, proxy_doc),
+		},
+		Globals: py.StringDict{
+			"ref":                 RefType,
+			"ReferenceType":       RefType,
+			"ProxyType":           ProxyType,
+			"CallableProxyType":   CallableProxyType,
+			"ProxyTypes":          py.Tuple{ProxyType, CallableProxyType},
+			"WeakValueDictionary": WeakValueDictionaryType,
+			"WeakKeyDictionary":   WeakKeyDictionaryType,
+			"WeakSet":             WeakSetType,
+			"finalize":            FinalizeType,
+		},
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package weakref_test
+
+import (
+	"testing"
+
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/pytest"
+	"github.com/go-python/gpython/vm"
+	_ "github.com/go-python/gpython/weakref"
+)
+
+func TestWeakref(t *testing.T) {
+	pytest.RunTests(t, "tests")
+}
+
+func TestCollect(t *testing.T) {
+	globals := py.NewStringDict()
+	run := func(src string) {
+		t.Helper()
+		code, err := compile.Compile(src, "<test>", "exec", 0, true)
+		if err != nil {
+			t.Fatalf("compile failed: %v", err)
+		}
+		_, err = vm.Run(globals, globals, code.(*py.Code), nil)
+		if err != nil {
+			py.TracebackDump(err)
+			t.Fatalf("run failed: %v", err)
+		}
+	}
+	run(`
+import weakref
+class C:
+    pass
+events = []
+d = weakref.WeakValueDictionary()
+k = weakref.WeakKeyDictionary()
+s = weakref.WeakSet()
+def make():
+    o = C()
+    refs = [weakref.ref(o, lambda ref: events.append("ref")), weakref.proxy(o)]
+    weakref.finalize(o, events.append, "finalize")
+    d["gone"] = o
+    k[o] = 1
+    s.add(o)
+    return refs
+refs = make()
+alive = C()
+d["alive"] = alive
+k[alive] = 2
+s.add(alive)
+`)
+	py.GarbageCollect()
+	run(`
+assert sorted(events) == ["finalize", "ref"], events
+assert refs[0]() is None
+assert "dead" in repr(refs[0])
+try:
+    refs[1].x
+except ReferenceError:
+    pass
+else:
+    assert False, "ReferenceError not raised"
+assert list(d) == ["alive"]
+assert list(k.values()) == [2]
+assert list(s) == [alive]
+`)
+}